@@ -33,10 +33,12 @@ import (
 func main() {
 	// Parse command line flags
 	addr := flag.String("addr", ":8080", "Address to listen on")
+	drainTimeout := flag.Duration("drain-timeout", server.DefaultDrainTimeout, "How long to wait for in-flight requests when shutting down")
 	flag.Parse()
 
 	// Create and start the server
 	server := server.NewServer("")
+	server.DrainTimeout = *drainTimeout
 
 	// Start the token cleanup goroutine
 	go server.CleanupExpiredTokens()
@@ -46,7 +48,6 @@ func main() {
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 
 	go func() {
-		log.Printf("Starting error report server on %s", *addr)
 		if err := server.Start(*addr); err != nil {
 			log.Fatalf("Server error: %v", err)
 		}
@@ -55,4 +56,7 @@ func main() {
 	// Wait for shutdown signal
 	<-sigChan
 	log.Println("Shutting down server...")
+	if err := server.Shutdown(); err != nil {
+		log.Printf("Error during shutdown: %v", err)
+	}
 }
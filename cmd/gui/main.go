@@ -17,6 +17,8 @@
 // Description: Main entry point for the Pi-Apps GUI implementation
 // SPDX-License-Identifier: GPL-3.0-or-later
 
+//go:build !nogui
+
 package main
 
 import (
@@ -74,12 +76,17 @@ func main() {
 			}
 		}()
 	}
+
+	// Refuse to run as root before anything is written to the user's home
+	api.GuardRoot()
+
 	var (
-		directory      = flag.String("directory", "", "Pi-Apps directory (defaults to PI_APPS_DIR env var)")
+		directory      = flag.String("directory", "", "Pi-Apps directory (defaults to PI_APPS_DIR, DIRECTORY, the program's directory, then ~/pi-apps)")
 		mode           = flag.String("mode", "", "GUI mode: gtk, xlunch-dark, etc.")
 		help           = flag.Bool("help", false, "Show help message")
 		version        = flag.Bool("version", false, "Show version information")
 		showAppDetails = flag.Bool("show-app-details", false, "Show app details dialog (internal use)")
+		remote         = flag.String("remote", "", "Manage the Pi-Apps of another machine over SSH: user@host[:path]")
 	)
 	api.Init()
 	flag.Parse()
@@ -130,6 +137,11 @@ func main() {
 		fmt.Println("Environment Variables:")
 		fmt.Println("  PI_APPS_DIR  Path to Pi-Apps directory")
 		fmt.Println()
+		fmt.Println("Remote mode:")
+		fmt.Println("  --remote user@host[:path] shows the apps of the Pi-Apps in path (default ~/pi-apps) on another")
+		fmt.Println("  machine and installs them there. It connects with ssh and runs api-go serve on that machine,")
+		fmt.Println("  which does not need a desktop: a nogui build of Pi-Apps is enough.")
+		fmt.Println()
 		fmt.Println("GUI Modes:")
 		fmt.Println("  default      Auto-detect best interface (GTK3 if available, fallback to Gio or TUI mode)")
 		fmt.Println("  gtk          Native GTK3 interface")
@@ -161,13 +173,12 @@ func main() {
 		return
 	}
 
-	// Set default directory if not provided
-	if *directory == "" {
-		*directory = api.GetPiAppsDir()
-		if *directory == "" {
-			logger.Fatal("PI_APPS_DIR environment variable not set and no directory specified")
-		}
+	// An explicit directory takes precedence for the whole process, so the GUI and the api calls it makes
+	// use the same Pi-Apps directory
+	if *directory != "" {
+		api.SetPiAppsDir(*directory)
 	}
+	*directory = api.GetPiAppsDir()
 
 	// Set default mode
 	if *mode == "" {
@@ -184,6 +195,27 @@ func main() {
 		GuiMode:   *mode,
 	}
 
+	// In remote mode the GUI runs on a local mirror of the catalog of the remote machine
+	if *remote != "" {
+		target, err := api.ParseRemoteTarget(*remote)
+		if err != nil {
+			logger.Fatal(err)
+		}
+		logger.Info(fmt.Sprintf("Connecting to %s...", target))
+		session, err := api.ConnectRemote(target, *directory)
+		if err != nil {
+			logger.Fatal(err)
+		}
+		defer session.Close()
+		mirrorDir, err := session.Mirror()
+		if err != nil {
+			session.Close()
+			logger.Fatal(err)
+		}
+		config.Directory = mirrorDir
+		config.Remote = session
+	}
+
 	// Create and initialize GUI
 	app, err := gui.NewGUI(config)
 	if err != nil {
@@ -29,14 +29,17 @@ import (
 	"os/signal"
 	"path/filepath"
 	"runtime"
-	"strconv"
+	"slices"
 	"strings"
 	"sync"
 	"syscall"
 	"time"
 
 	"github.com/pi-apps-go/pi-apps/pkg/api"
+	"github.com/pi-apps-go/pi-apps/pkg/api/errs"
 	"github.com/pi-apps-go/pi-apps/pkg/gui"
+	"github.com/pi-apps-go/pi-apps/pkg/tui"
+	"golang.org/x/term"
 )
 
 // Build-time variables
@@ -44,6 +47,9 @@ var (
 	BuildDate string
 	GitCommit string
 	logger    = log.New(os.Stderr, "pi-apps-manage: ", log.LstdFlags)
+
+	// runReport is the run report of the queue running in this process, nil while none does
+	runReport *gui.RunReporter
 )
 
 func main() {
@@ -79,6 +85,9 @@ func main() {
 		}()
 	}
 
+	// Refuse to run as root before anything is written to the user's home
+	api.GuardRoot()
+
 	// Define flags
 	// compatibility layer to allow the use of the same flags as the original Pi-Apps manage script (either without a dash or with a dash)
 
@@ -94,7 +103,10 @@ func main() {
 	refreshFlag := flag.Bool("refresh", false, "Refresh the specified apps")
 	updateFileFlag := flag.Bool("update-file", false, "Update the specified files")
 	daemonFlag := flag.Bool("daemon", false, "Run in daemon mode")
+	onCompleteFlag := flag.String("on-complete", "", "What the daemon terminal does when the queue is finished: keep, close, close-on-success or timeout:<seconds>")
 	versionFlag := flag.Bool("version", false, "Show version information")
+	noInteractiveFlag := flag.Bool("no-interactive", false, "Print the usage instead of starting the interactive mode when no operation is given")
+	reportFileFlag := flag.String("report-file", "", "Write the JSON report of the run to this file instead of data/manage-report.json")
 
 	// Custom error handling for undefined flags
 	flag.Usage = printUsage
@@ -115,6 +127,7 @@ func main() {
 		"update-file":              true,
 		"daemon":                   true,
 		"version":                  true,
+		"no-interactive":           true,
 	}
 	for _, arg := range os.Args[1:] {
 		if strings.HasPrefix(arg, "-") {
@@ -135,6 +148,10 @@ func main() {
 		printUsage()
 		os.Exit(1)
 	}
+	// The daemon terminal finds the run report through the environment too
+	if *reportFileFlag != "" {
+		os.Setenv(gui.RunReportEnv, *reportFileFlag)
+	}
 
 	// Check for version flag first
 	if *versionFlag {
@@ -166,11 +183,19 @@ func main() {
 		if len(args) > 0 {
 			queueStr = args[0]
 		}
-		err := runDaemon(queueStr)
+		policy, err := gui.ResolveOnCompletePolicy(*onCompleteFlag)
+		if err != nil {
+			api.ErrorNoExit("Error: " + err.Error())
+			os.Exit(1)
+		}
+		exitCode, err := runDaemon(queueStr, policy)
 		if err != nil {
 			api.ErrorNoExit("Daemon error: " + err.Error())
 			os.Exit(1)
 		}
+		if exitCode != 0 {
+			os.Exit(exitCode)
+		}
 		return
 	}
 
@@ -186,7 +211,17 @@ func main() {
 		if len(args) > 3 {
 			queuePipe = args[3]
 		}
-		err := daemonTerminal(queueStr, statusFile, queuePipe)
+		// The daemon passes the policy it resolved through the environment of the terminal
+		onComplete := *onCompleteFlag
+		if onComplete == "" {
+			onComplete = os.Getenv("PI_APPS_ON_COMPLETE")
+		}
+		policy, err := gui.ResolveOnCompletePolicy(onComplete)
+		if err != nil {
+			api.ErrorNoExit("Error: " + err.Error())
+			os.Exit(1)
+		}
+		err = daemonTerminal(queueStr, statusFile, queuePipe, policy)
 		if err != nil {
 			api.ErrorNoExit("Daemon terminal error: " + err.Error())
 			os.Exit(1)
@@ -237,40 +272,20 @@ func main() {
 		}
 	}
 
-	// Ensure PI_APPS_DIR environment variable is set (DIRECTORY takes precedence to match bash behavior)
-	piAppsDir := ""
-	if dirEnv := os.Getenv("DIRECTORY"); dirEnv != "" {
-		if _, err := os.Stat(dirEnv); err == nil {
-			piAppsDir = dirEnv
-			os.Setenv("PI_APPS_DIR", piAppsDir)
-		}
-	}
-	if piAppsDir == "" {
-		if env := api.GetPiAppsDir(); env != "" {
-			piAppsDir = env
-		}
-	}
-	if piAppsDir == "" {
-		// Try to find pi-apps directory
-		homeDir, err := os.UserHomeDir()
-		if err == nil {
-			possiblePath := filepath.Join(homeDir, "pi-apps")
-			if _, err := os.Stat(possiblePath); err == nil {
-				piAppsDir = possiblePath
-				os.Setenv("PI_APPS_DIR", piAppsDir)
-			}
-		}
-
-		if piAppsDir == "" {
-			api.Error("Error: PI_APPS_DIR environment variable not set")
-		}
-	} else {
-		// Keep PI_APPS_DIR in sync when overridden
-		os.Setenv("PI_APPS_DIR", piAppsDir)
+	// Use the Pi-Apps directory every other Pi-Apps program uses, see api.GetPiAppsDir
+	piAppsDir := api.GetPiAppsDir()
+	if err := api.ValidatePiAppsDir(piAppsDir); err != nil {
+		api.ErrorTf("Error: no Pi-Apps directory found, %s: %v", piAppsDir, err)
 	}
+	os.Setenv("PI_APPS_DIR", piAppsDir)
 
 	// If no flags are provided, print usage and exit
 	if !*installFlag && !*uninstallFlag && !*updateFlag && !*updateSelfFlag && !*installIfNotInstalledFlag && !*refreshFlag && !*updateFileFlag {
+		// Someone at a terminal gets the interactive mode instead, scripts and --no-interactive keep the usage
+		if !*noInteractiveFlag && !*guiFlag && len(args) == 0 && interactiveTerminal() {
+			runInteractive(piAppsDir, *onCompleteFlag)
+			return
+		}
 		api.ErrorNoExit("Error: You need to specify an operation, and in most cases, which app to operate on.")
 		printUsage()
 		os.Exit(0)
@@ -284,21 +299,13 @@ func main() {
 	// Create a queue of operations
 	var queue []gui.QueueItem
 
+	// Scripts that provision systems unattended read what the run did from its report
+	runReport = gui.NewRunReporter(gui.RunReportFile(), gui.RunModeDirect, GitCommit)
+	finishReportOnSignal()
+
 	// Process each requested operation
 	if *updateSelfFlag {
-		// Update Pi-Apps itself
-		// Make it show a warning considering on the original Pi-Apps manage script, this would redirect to the updater script if you ran update-all or check-all
-		api.Warning("The manage package ONLY updates apps, and this mode redirects to the updater package.\nIf you want to update Pi-Apps Go from the command-line, please use:\n" + fmt.Sprintf("%s/updater cli-yes", piAppsDir))
-		api.Status("Updating \u001b[1mPi-Apps\u001b[22m...")
-
-		cmd := exec.Command(fmt.Sprintf("%s/updater", piAppsDir), "cli-yes")
-		cmd.Stdout = os.Stdout
-		cmd.Stderr = os.Stderr
-
-		err := cmd.Run()
-		if err != nil {
-			api.ErrorNoExit("Error updating Pi-Apps: " + err.Error())
-		}
+		updatePiApps(piAppsDir)
 	}
 
 	// Add apps to the queue based on requested operations
@@ -374,34 +381,24 @@ func main() {
 				api.ErrorNoExit("Error validating apps: " + err.Error())
 			}
 		} else {
-			// Use command-line validation - convert types
-			internalQueue := make([]QueueItem, len(queue))
-			for i, item := range queue {
-				internalQueue[i] = QueueItem{
-					Action:   item.Action,
-					AppName:  item.AppName,
-					Status:   item.Status,
-					IconPath: item.IconPath,
-				}
-			}
-
-			validatedQueue, err := validateQueue(internalQueue)
-			if err != nil {
-				api.ErrorNoExit("Error validating apps: " + err.Error())
-			}
-
-			// Convert back to gui.QueueItem
-			queue = make([]gui.QueueItem, len(validatedQueue))
-			for i, item := range validatedQueue {
-				queue[i] = gui.QueueItem{
-					Action:   item.Action,
-					AppName:  item.AppName,
-					Status:   item.Status,
-					IconPath: item.IconPath,
-				}
+			// Use command-line validation
+			queue = gui.ValidateQueue(queue, false)
+		}
+	}
+	if *forceFlag {
+		// Forced uninstalls don't wait for the apps that depend on them, and forced updates unhold held packages
+		for _, item := range queue {
+			switch item.Action {
+			case "uninstall":
+				api.AllowUninstallWithDependents(item.AppName)
+			case "update":
+				api.AllowUpdateHeld(item.AppName)
 			}
 		}
 	}
+	if summary := gui.QueueEstimateSummary(queue); summary != "" {
+		api.Status(summary)
+	}
 
 	// If multi flag is set, execute all operations at once
 	if *multiFlag {
@@ -419,6 +416,8 @@ func main() {
 		for i := range queue {
 			// Update status to in-progress
 			queue[i].Status = "in-progress"
+			queue[i].Started = time.Now()
+			runReport.Update(queue)
 
 			// Execute the operation
 			var err error
@@ -426,12 +425,14 @@ func main() {
 			case "install":
 				// Check if already installed, unless ForceReinstall flag is set
 				if api.IsAppInstalled(queue[i].AppName) && !queue[i].ForceReinstall {
-					err = fmt.Errorf("app '%s' is already installed", queue[i].AppName)
+					err = errs.New(errs.ErrAlreadyInstalled, "app '%s' is already installed", queue[i].AppName)
 				} else {
 					// Force uninstall first if reinstalling
 					if queue[i].ForceReinstall && api.IsAppInstalled(queue[i].AppName) {
+						// The app is installed again right away, so the apps that depend on it keep working
+						api.AllowUninstallWithDependents(queue[i].AppName)
 						if uninstallErr := api.UninstallApp(queue[i].AppName); uninstallErr != nil {
-							err = fmt.Errorf("failed to uninstall before reinstall: %v", uninstallErr)
+							err = fmt.Errorf("failed to uninstall before reinstall: %w", uninstallErr)
 						}
 					}
 
@@ -447,6 +448,9 @@ func main() {
 					err = fmt.Errorf("failed to get app status: %w", statusErr)
 				case appStatus == "uninstalled":
 					api.Status(fmt.Sprintf("App '%s' is already uninstalled, skipping", queue[i].AppName))
+					queue[i].Status = "success"
+					queue[i].Finished = time.Now()
+					runReport.Update(queue)
 					continue
 				default:
 					err = api.UninstallApp(queue[i].AppName)
@@ -461,17 +465,23 @@ func main() {
 
 			// Update status based on result
 			if err != nil {
-				api.ErrorNoExit("Error with " + queue[i].Action + " for " + queue[i].AppName + ": " + err.Error())
+				rendered := api.RenderError(err)
+				api.ErrorNoExit("Error with " + queue[i].Action + " for " + queue[i].AppName + ": " + rendered.Message)
+				if rendered.Hint != "" {
+					api.Status(rendered.Hint)
+				}
 				queue[i].Status = "failure"
 				// Add error message to the queue item so it can be displayed in the summary
-				queue[i].ErrorMessage = err.Error()
+				queue[i].ErrorMessage = rendered.Message
+				queue[i].ExitCode = api.FailureExitCode(err)
 
-				// If GUI is enabled, show error dialog and ask for retry
-				if *guiFlag {
-					if gui.ShowErrorDialogWithRetry(queue[i].AppName, queue[i].Action, err.Error()) {
+				// If GUI is enabled, show error dialog and ask for retry, unless retrying can't help
+				if *guiFlag && errs.Retryable(err) {
+					if gui.ShowErrorDialogWithRetry(queue[i].AppName, queue[i].Action, strings.TrimSpace(rendered.Message+"\n\n"+rendered.Hint)) {
 						// User chose to retry, reset status and continue
 						queue[i].Status = "waiting"
 						queue[i].ErrorMessage = ""
+						queue[i].ExitCode = 0
 						i-- // Retry this item
 						continue
 					}
@@ -480,6 +490,8 @@ func main() {
 				queue[i].Status = "success"
 				api.StatusGreen(queue[i].Action + " completed successfully for " + queue[i].AppName)
 			}
+			queue[i].Finished = time.Now()
+			runReport.Update(queue)
 		}
 
 		// Wait a brief moment for progress dialog to auto-close
@@ -498,6 +510,10 @@ func main() {
 	} else {
 		// Execute operations one by one
 		for i := range queue {
+			queue[i].Status = "in-progress"
+			queue[i].Started = time.Now()
+			runReport.Update(queue)
+
 			// Execute the operation
 			var err error
 			switch queue[i].Action {
@@ -511,6 +527,9 @@ func main() {
 					err = fmt.Errorf("failed to get app status: %w", statusErr)
 				case appStatus == "uninstalled":
 					api.Status(fmt.Sprintf("App '%s' is already uninstalled, skipping", queue[i].AppName))
+					queue[i].Status = "success"
+					queue[i].Finished = time.Now()
+					runReport.Update(queue)
 					continue
 				default:
 					err = api.UninstallApp(queue[i].AppName)
@@ -523,13 +542,17 @@ func main() {
 				err = api.UpdateFile(queue[i].AppName)
 			}
 
-			// Check result
+			// Check result, the error and the Need help? section were already shown by the manage package
 			if err != nil {
-				// Do nothing here considering the error handling and display of the Need help? section is already handled in the manage package
+				queue[i].Status = "failure"
+				queue[i].ErrorMessage = err.Error()
+				queue[i].ExitCode = api.FailureExitCode(err)
 			} else {
 				api.StatusGreen("Operation completed successfully")
 				queue[i].Status = "success"
 			}
+			queue[i].Finished = time.Now()
+			runReport.Update(queue)
 		}
 		// Show summary dialog after single operations if GUI flag is set
 		if *guiFlag && len(queue) > 0 {
@@ -539,30 +562,144 @@ func main() {
 			}
 		}
 	}
+
+	if exitCode := runReport.Finish(queue); exitCode != 0 {
+		os.Exit(exitCode)
+	}
 }
 
-// QueueItem represents an item in the daemon queue
-type QueueItem struct {
-	Action   string
-	AppName  string
-	Status   string // "waiting", "in-progress", "success", "failure", "diagnosed"
-	IconPath string
-	ExitCode int
+// finishReportOnSignal finishes the run report when the process is interrupted, terminated or loses its terminal,
+// then exits like the signal killed it
+func finishReportOnSignal() {
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+	go func() {
+		sig := <-c
+		os.Exit(runReport.Interrupt(sig))
+	}()
 }
 
 // runDaemon implements the daemon functionality for managing app operations
-func runDaemon(queueStr string) error {
+// policy is what the terminal does once the queue is finished
+// updatePiApps updates Pi-Apps itself with the updater
+func updatePiApps(piAppsDir string) {
+	// Make it show a warning considering on the original Pi-Apps manage script, this would redirect to the updater script if you ran update-all or check-all
+	api.Warning("The manage package ONLY updates apps, and this mode redirects to the updater package.\nIf you want to update Pi-Apps Go from the command-line, please use:\n" + fmt.Sprintf("%s/updater cli-yes", piAppsDir))
+	api.Status("Updating \u001b[1mPi-Apps\u001b[22m...")
+
+	cmd := exec.Command(fmt.Sprintf("%s/updater", piAppsDir), "cli-yes")
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	err := cmd.Run()
+	if err != nil {
+		api.ErrorNoExit("Error updating Pi-Apps: " + err.Error())
+	}
+}
+
+// interactiveTerminal reports whether both stdin and stdout are terminals, the interactive mode needs both
+func interactiveTerminal() bool {
+	return term.IsTerminal(int(os.Stdin.Fd())) && term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// runInteractive lets the user pick an operation and apps with tui.RunManageWizard, then hands the queue to the daemon
+// like -daemon does
+func runInteractive(piAppsDir, onComplete string) {
+	policy, err := gui.ResolveOnCompletePolicy(onComplete)
+	if err != nil {
+		api.ErrorNoExit("Error: " + err.Error())
+		os.Exit(1)
+	}
+
+	plan, ok, err := tui.RunManageWizard()
+	if err != nil {
+		api.ErrorNoExit("Error: " + err.Error())
+		os.Exit(1)
+	}
+	if !ok {
+		return
+	}
+	if plan.UpdateSelf {
+		updatePiApps(piAppsDir)
+		return
+	}
+
+	queue := make([]gui.QueueItem, len(plan.Queue))
+	lines := make([]string, len(plan.Queue))
+	for i, entry := range plan.Queue {
+		queue[i] = gui.QueueItem{Action: entry.Action, AppName: entry.AppName, Status: "waiting"}
+		lines[i] = api.QueueLine(entry.Action, entry.AppName)
+	}
+	if !confirmQueue(queue) {
+		return
+	}
+
+	exitCode, err := runDaemon(strings.Join(lines, "\n"), policy)
+	if err != nil {
+		api.ErrorNoExit("Daemon error: " + err.Error())
+		os.Exit(1)
+	}
+	if exitCode != 0 {
+		os.Exit(exitCode)
+	}
+}
+
+// confirmQueue lists what the queue does with the time its installs take and the space its uninstalls free,
+// then asks whether to go ahead
+func confirmQueue(queue []gui.QueueItem) bool {
+	fmt.Println(api.T("The following operations will be performed:"))
+	for _, item := range queue {
+		fmt.Printf("  %s %s\n", item.Action, item.AppName)
+	}
+	if summary := gui.QueueEstimateSummary(queue); summary != "" {
+		api.Status(summary)
+	}
+	if freed := queueFreedSpace(queue); freed > 0 {
+		api.Status(api.Tf("Uninstalling these apps frees about %s", api.FormatSize(uint64(freed))))
+	}
+
+	fmt.Print(api.T("Continue? [Y/n] "))
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "" || answer == "y" || answer == "yes"
+}
+
+// queueFreedSpace returns the disk space the uninstalls of the queue free, 0 if it is unknown
+func queueFreedSpace(queue []gui.QueueItem) int64 {
+	uninstalls := make(map[string]bool)
+	for _, item := range queue {
+		if item.Action == "uninstall" {
+			uninstalls[item.AppName] = true
+		}
+	}
+	if len(uninstalls) == 0 {
+		return 0
+	}
+	usage, err := api.AllAppsDiskUsage()
+	if err != nil {
+		return 0
+	}
+	var freed int64
+	for _, app := range usage {
+		if uninstalls[app.App] {
+			freed += app.Total
+		}
+	}
+	return freed
+}
+
+func runDaemon(queueStr string, policy gui.OnCompletePolicy) (int, error) {
 	// Get PI_APPS_DIR environment variable
 	piAppsDir := api.GetPiAppsDir()
 	if piAppsDir == "" {
-		return fmt.Errorf("PI_APPS_DIR environment variable not set")
+		return 0, fmt.Errorf("PI_APPS_DIR environment variable not set")
 	}
 
 	// Create daemon directory
-	daemonDir := filepath.Join(piAppsDir, "data", "manage-daemon")
-	err := os.MkdirAll(daemonDir, 0755)
+	daemonDir := filepath.Join(api.DataDirOf(piAppsDir), "manage-daemon")
+	err := api.EnsureDirOwned(daemonDir, api.InvokingUser, api.InvokingUser)
 	if err != nil {
-		return fmt.Errorf("failed to create daemon directory: %w", err)
+		return 0, fmt.Errorf("failed to create daemon directory: %w", err)
 	}
 
 	pidFile := filepath.Join(daemonDir, "pid")
@@ -575,24 +712,14 @@ func runDaemon(queueStr string) error {
 	if _, err := os.Stat(pidFile); err == nil {
 		// Check if queue pipe also exists (indicates a real daemon)
 		if info, err := os.Stat(queueFile); err == nil && (info.Mode()&os.ModeNamedPipe) != 0 {
-			// Read existing PID
-			pidBytes, err := os.ReadFile(pidFile)
-			if err == nil {
-				if pid, err := strconv.Atoi(strings.TrimSpace(string(pidBytes))); err == nil {
-					// Check if process exists
-					if process, err := os.FindProcess(pid); err == nil {
-						if err := process.Signal(syscall.Signal(0)); err == nil {
-							// All checks passed - daemon is actually running
-							daemonRunning = true
-						}
-					}
-				}
-			}
+			// The start time in the PID file tells the daemon apart from a process that got its PID later
+			daemonRunning = api.PIDFileRunning(pidFile)
 		}
 	}
 
 	if daemonRunning {
-		return addToExistingDaemon(queueFile, queueStr)
+		// The running daemon reports the queue it was sent
+		return 0, addToExistingDaemon(queueFile, queueStr)
 	}
 
 	// Clean up stale files if they exist but daemon isn't running
@@ -600,7 +727,7 @@ func runDaemon(queueStr string) error {
 	os.Remove(queueFile)
 
 	// No existing daemon, start new one
-	return startNewDaemon(piAppsDir, queueStr)
+	return startNewDaemon(piAppsDir, queueStr, policy)
 }
 
 // addToExistingDaemon adds a queue to an already running daemon
@@ -616,8 +743,16 @@ func addToExistingDaemon(queueFile, queueStr string) error {
 	}
 	defer file.Close()
 
+	// A daemon from the previous release only understands the queue string itself
+	message := queueStr + "\n"
+	if gui.DaemonProtocolVersion(filepath.Join(filepath.Dir(queueFile), "status")) >= 2 {
+		if message, err = gui.FormatQueueAddMessage(gui.ParseQueue(queueStr)); err != nil {
+			return err
+		}
+	}
+
 	// Write the queue items to the pipe
-	_, err = file.WriteString(queueStr + "\n")
+	_, err = file.WriteString(message)
 	if err != nil {
 		return fmt.Errorf("failed to write to queue pipe: %w", err)
 	}
@@ -626,81 +761,74 @@ func addToExistingDaemon(queueFile, queueStr string) error {
 	return nil
 }
 
-// startNewDaemon starts a new daemon process
-func startNewDaemon(piAppsDir, queueStr string) error {
-	// Parse initial queue
-	queue := parseQueue(queueStr)
+// startNewDaemon starts a new daemon process and returns the exit code for its queue, see gui.QueueResult
+func startNewDaemon(piAppsDir, queueStr string, policy gui.OnCompletePolicy) (int, error) {
+	// Parse the initial queue, an operation queued twice runs once
+	_, queue := gui.NormalizeQueue(nil, gui.ParseQueue(queueStr))
 
 	// Validate the queue
-	if len(queue) > 0 {
-		validatedQueue, err := validateQueue(queue)
-		if err != nil {
-			return fmt.Errorf("failed to validate queue: %w", err)
-		}
-		queue = validatedQueue
-	}
+	queue = gui.ValidateQueue(queue, false)
 
 	if len(queue) == 0 {
-		return nil
+		// No daemon terminal starts to report the run
+		runReport = gui.NewRunReporter(gui.RunReportFile(), gui.RunModeDaemon, GitCommit)
+		return runReport.Finish(nil), nil
 	}
 
-	// Convert internal QueueItem to gui.QueueItem
-	guiQueue := make([]gui.QueueItem, len(queue))
-	for i, item := range queue {
-		guiQueue[i] = gui.QueueItem{
-			Action:   item.Action,
-			AppName:  item.AppName,
-			Status:   item.Status,
-			IconPath: item.IconPath,
-		}
+	// Give every item its queue ID
+	guiQueue := slices.Clone(queue)
+	for i := range guiQueue {
+		guiQueue[i].ID = i + 1
 	}
 
 	// Add mutex for queue synchronization
 	var queueMutex sync.Mutex
 
 	// Write PID file
-	pidFile := filepath.Join(piAppsDir, "data", "manage-daemon", "pid")
-	err := os.WriteFile(pidFile, []byte(strconv.Itoa(os.Getpid())), 0644)
+	pidFile := filepath.Join(api.DataDirOf(piAppsDir), "manage-daemon", "pid")
+	err := api.WritePIDFile(pidFile, os.Getpid())
 	if err != nil {
-		return fmt.Errorf("failed to write PID file: %w", err)
+		return 0, fmt.Errorf("failed to write PID file: %w", err)
 	}
 
 	// Create named pipe for IPC (like the bash version)
-	queuePipe := filepath.Join(piAppsDir, "data", "manage-daemon", "queue")
+	queuePipe := filepath.Join(api.DataDirOf(piAppsDir), "manage-daemon", "queue")
 	if _, err := os.Stat(queuePipe); os.IsNotExist(err) {
 		err = syscall.Mkfifo(queuePipe, 0644)
 		if err != nil {
-			return fmt.Errorf("failed to create queue pipe: %w", err)
+			return 0, fmt.Errorf("failed to create queue pipe: %w", err)
 		}
 	}
 
 	// Create status file for IPC between GUI and terminal processes
-	statusFile := filepath.Join(piAppsDir, "data", "manage-daemon", "status")
+	statusFile := filepath.Join(api.DataDirOf(piAppsDir), "manage-daemon", "status")
 
 	// Write initial status
 	queueMutex.Lock()
 	err = writeQueueStatus(statusFile, guiQueue)
 	queueMutex.Unlock()
 	if err != nil {
-		return fmt.Errorf("failed to write initial status: %w", err)
+		return 0, fmt.Errorf("failed to write initial status: %w", err)
 	}
 
 	// Set up cleanup
 	defer func() {
 		os.Remove(pidFile)
-		os.Remove(statusFile)
+		gui.RemoveQueueStatus(statusFile)
+		gui.RemoveDaemonTerminalState(statusFile)
 		os.Remove(queuePipe)
 	}()
 
-	// Handle signals
+	// Handle signals, the report is only this process's when the queue runs in the current shell
 	c := make(chan os.Signal, 1)
 	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
 	go func() {
-		<-c
+		sig := <-c
 		os.Remove(pidFile)
-		os.Remove(statusFile)
+		gui.RemoveQueueStatus(statusFile)
+		gui.RemoveDaemonTerminalState(statusFile)
 		os.Remove(queuePipe)
-		os.Exit(0)
+		os.Exit(runReport.Interrupt(sig))
 	}()
 
 	// Start progress monitor with initial queue only (it will show static progress)
@@ -719,53 +847,20 @@ func startNewDaemon(piAppsDir, queueStr string) error {
 		progressDone <- true
 	}()
 
-	// Start queue listener for new incoming requests
-	queueUpdate := make(chan string, 10) // Buffered channel for new queue items
-	go func() {
-		for {
-			// Open the named pipe for reading (this will block until something writes to it)
-			file, err := os.OpenFile(queuePipe, os.O_RDONLY, 0644)
-			if err != nil {
-				fmt.Printf("Warning: failed to open queue pipe for reading: %v\n", err)
-				time.Sleep(1 * time.Second)
-				continue
-			}
-
-			scanner := bufio.NewScanner(file)
-			for scanner.Scan() {
-				line := strings.TrimSpace(scanner.Text())
-				if line != "" {
-					fmt.Printf("Received new queue request: %s\n", line)
-					queueUpdate <- line
-				}
-			}
-			file.Close()
-
-			if err := scanner.Err(); err != nil {
-				fmt.Printf("Warning: error reading from queue pipe: %v\n", err)
-			}
-		}
-	}()
-
 	// Simplified status monitoring - just wait for terminal process to complete
 	statusMonitorDone := make(chan bool, 1)
 	go func() {
 		for {
 			// Check if terminal process is still running by checking PID file
-			pidBytes, err := os.ReadFile(pidFile)
-			if err != nil {
+			if _, err := os.Stat(pidFile); err != nil {
 				// PID file doesn't exist, terminal process is done
 				statusMonitorDone <- true
 				break
 			}
-
-			if pid, err := strconv.Atoi(strings.TrimSpace(string(pidBytes))); err == nil {
-				// Check if the process still exists
-				if process, err := os.FindProcess(pid); err != nil || process.Signal(syscall.Signal(0)) != nil {
-					// Process doesn't exist anymore, terminal process is done
-					statusMonitorDone <- true
-					break
-				}
+			if _, _, err := api.ReadPIDFile(pidFile); err == nil && !api.PIDFileRunning(pidFile) {
+				// Process doesn't exist anymore, terminal process is done
+				statusMonitorDone <- true
+				break
 			}
 
 			time.Sleep(1 * time.Second)
@@ -775,25 +870,11 @@ func startNewDaemon(piAppsDir, queueStr string) error {
 	// Get absolute path to current executable
 	execPath, err := os.Executable()
 	if err != nil {
-		return fmt.Errorf("failed to get executable path: %w", err)
+		return 0, fmt.Errorf("failed to get executable path: %w", err)
 	}
 
 	// Prepare the terminal script content that will run in terminal-run
-	// This matches the original bash implementation more closely
-	terminalScript := fmt.Sprintf(`
-# Set up environment variables
-export PI_APPS_DIR="%s"
-export DIRECTORY="%s"
-
-# Update daemon pid to that of the terminal
-echo $$ > "%s"
-
-# Change to the directory containing the manage binary for consistency
-cd "%s"
-
-# Run the daemon terminal operations with logo and proper setup
-"%s" daemon-terminal "%s" "%s" "%s"
-`, piAppsDir, piAppsDir, pidFile, filepath.Dir(execPath), execPath, queueStr, statusFile, queuePipe)
+	terminalScript := daemonTerminalScript(piAppsDir, execPath, pidFile, statusFile, queuePipe, queue, policy)
 
 	// Start terminal-run with the daemon processing
 	// Use Go implementation for reliable cross-terminal wait handling
@@ -806,7 +887,7 @@ cd "%s"
 		gui.ShowMessageDialog("Error occurred when calling terminal-run", errorText, 3) // MessageType 3 is ERROR
 
 		// Fall back to running in current shell if terminal-run fails
-		return runDaemonInCurrentShell(guiQueue, statusFile)
+		return runDaemonInCurrentShell(guiQueue, statusFile, policy)
 	}
 
 	// Wait for status monitor to detect completion
@@ -829,438 +910,88 @@ cd "%s"
 		fmt.Printf("Error showing summary dialog: %v\n", err)
 	}
 
-	return nil
-}
-
-// runDaemonInCurrentShell is a fallback when terminal-run fails
-func runDaemonInCurrentShell(guiQueue []gui.QueueItem, statusFile string) error {
-	fmt.Println("Falling back to running in current shell...")
-
-	// Display Pi-Apps logo
-	fmt.Print(api.GenerateLogo())
-
-	// Process the queue with retry loop for failed apps
-	for {
-		currentIndex := 0
-		// Find next unprocessed item
-		for i := range guiQueue {
-			if guiQueue[i].Status == "waiting" {
-				currentIndex = i
-				break
-			}
-		}
-
-		// Check if all items are processed
-		allProcessed := true
-		for _, item := range guiQueue {
-			if item.Status == "waiting" {
-				allProcessed = false
-				break
-			}
-		}
-
-		if allProcessed {
-			// Check for failed apps and run diagnosis
-			var failedApps []string
-			for _, item := range guiQueue {
-				if item.Status == "failure" {
-					failedApps = append(failedApps, fmt.Sprintf("%s;%s", item.Action, item.AppName))
-				}
-			}
-
-			if len(failedApps) > 0 {
-				// Run diagnosis on failed apps
-				fmt.Println("\nDiagnosing failed operations...")
-				failureList := strings.Join(failedApps, "\n")
-
-				// Call the diagnose_apps function from the API
-				results := api.DiagnoseApps(failureList)
-
-				// Process the diagnosis results
-				var retryApps []string
-				for _, result := range results {
-					if result.Action == "retry" {
-						retryApps = append(retryApps, result.ActionStr)
-					}
-				}
-
-				if len(retryApps) > 0 {
-					// User chose to retry some operations
-					// Mark failed apps as "diagnosed" to avoid repeated diagnosis
-					for i := range guiQueue {
-						if guiQueue[i].Status == "failure" {
-							guiQueue[i].Status = "diagnosed"
-						}
-					}
-
-					// Add retry operations to the queue
-					retryQueue := parseQueue(strings.Join(retryApps, "\n"))
-					for _, retryItem := range retryQueue {
-						// Ensure icon path is properly set for retry items
-						iconPath := filepath.Join(api.GetPiAppsDir(), "apps", retryItem.AppName, "icon-64.png")
-						if _, err := os.Stat(iconPath); os.IsNotExist(err) {
-							iconPath = filepath.Join(api.GetPiAppsDir(), "icons", "none-64.png")
-						}
-
-						newGuiItem := gui.QueueItem{
-							Action:   retryItem.Action,
-							AppName:  retryItem.AppName,
-							Status:   "waiting",
-							IconPath: iconPath,
-						}
-						guiQueue = append(guiQueue, newGuiItem)
-					}
-
-					// Reorder the queue to prioritize updates and refreshes
-					guiQueue = reorderList(guiQueue)
-
-					// Write status update to show diagnosed items
-					err := writeQueueStatus(statusFile, guiQueue)
-					if err != nil {
-						fmt.Printf("Warning: failed to write updated status: %v\n", err)
-					}
-
-					// Add a small delay before starting retries (like in original implementation)
-					fmt.Println("Preparing to retry operations...")
-					time.Sleep(2 * time.Second)
-
-					// Continue processing the new items
-					continue
-				} else {
-					// No retries requested, we're done
-					break
-				}
-			} else {
-				// No failed apps, we're done
-				break
-			}
-		}
-
-		// Process next waiting item
-		if currentIndex < len(guiQueue) && guiQueue[currentIndex].Status == "waiting" {
-			// Update status to in-progress
-			guiQueue[currentIndex].Status = "in-progress"
-			err := writeQueueStatus(statusFile, guiQueue)
-			if err != nil {
-				fmt.Printf("Warning: failed to write status: %v\n", err)
-			}
-
-			// Set terminal title
-			fmt.Printf("\033]0;%sing %s\007", strings.ToUpper(guiQueue[currentIndex].Action[:1])+guiQueue[currentIndex].Action[1:], guiQueue[currentIndex].AppName)
-
-			// Execute the action - let API functions handle their own status messaging
-			var actionErr error
-			switch guiQueue[currentIndex].Action {
-			case "install":
-				actionErr = api.InstallApp(guiQueue[currentIndex].AppName)
-			case "uninstall":
-				actionErr = api.UninstallApp(guiQueue[currentIndex].AppName)
-			case "update":
-				actionErr = api.UpdateApp(guiQueue[currentIndex].AppName)
-			case "refresh":
-				actionErr = api.RefreshApp(guiQueue[currentIndex].AppName)
-			case "update-file":
-				actionErr = api.UpdateFile(guiQueue[currentIndex].AppName)
-			}
-
-			// Update status based on result
-			if actionErr != nil {
-				guiQueue[currentIndex].Status = "failure"
-				guiQueue[currentIndex].ErrorMessage = actionErr.Error()
-
-				// Format the log file to add device information for failed operations
-				logFile := api.GetLogfile(guiQueue[currentIndex].AppName)
-				if api.FileExists(logFile) {
-					err := api.FormatLogfile(logFile)
-					if err != nil {
-						fmt.Printf("Warning: failed to format log file %s: %v\n", logFile, err)
-					}
-				}
-			} else {
-				guiQueue[currentIndex].Status = "success"
-
-				// Format the log file for successful operations too (consistent with bash version)
-				logFile := api.GetLogfile(guiQueue[currentIndex].AppName)
-				if api.FileExists(logFile) {
-					err := api.FormatLogfile(logFile)
-					if err != nil {
-						fmt.Printf("Warning: failed to format log file %s: %v\n", logFile, err)
-					}
-				}
-			}
-
-			// Write updated status
-			err = writeQueueStatus(statusFile, guiQueue)
-			if err != nil {
-				fmt.Printf("Warning: failed to write status: %v\n", err)
-			}
-		}
-	}
-
-	// Signal the progress monitor that daemon processing is complete
-	// Add a special completion marker to the queue
-	guiQueue = append(guiQueue, gui.QueueItem{
-		Action:   "daemon",
-		AppName:  "completed",
-		Status:   "daemon-complete",
-		IconPath: "",
-	})
-	err := writeQueueStatus(statusFile, guiQueue)
-	if err != nil {
-		fmt.Printf("Warning: failed to write completion status: %v\n", err)
-	}
-
-	return nil
+	// The daemon terminal wrote the report, exit the way it says
+	_, exitCode := gui.QueueResult(finalQueue)
+	return exitCode, nil
 }
 
-// parseQueue parses the queue string into QueueItem structs
-func parseQueue(queueStr string) []QueueItem {
-	if queueStr == "" {
-		return nil
-	}
-
-	var queue []QueueItem
-	lines := strings.Split(strings.TrimSpace(queueStr), "\n")
-
-	for _, line := range lines {
-		if line == "" {
-			continue
-		}
-
-		var action, appName string
-
-		// Check if line contains semicolon delimiter
-		if strings.Contains(line, ";") {
-			// Format: "action;appname" - split on semicolon
-			parts := strings.SplitN(line, ";", 2)
-			if len(parts) >= 2 {
-				action = strings.TrimSpace(parts[0])
-				appName = strings.TrimSpace(parts[1])
-			}
-		} else {
-			// Format: "action appname" - split on space but preserve app name with spaces
-			parts := strings.SplitN(strings.TrimSpace(line), " ", 2)
-			if len(parts) >= 2 {
-				action = parts[0]
-				appName = parts[1]
-			}
-		}
-
-		if action != "" && appName != "" {
-			// Get icon path - check for deprecated apps first
-			var iconPath string
-			if api.IsDeprecatedApp(appName) {
-				iconPath = api.GetDeprecatedAppIcon(appName)
-				if iconPath == "" {
-					iconPath = filepath.Join(api.GetPiAppsDir(), "icons", "none-64.png")
-				}
-			} else {
-				iconPath = filepath.Join(api.GetPiAppsDir(), "apps", appName, "icon-64.png")
-				if _, err := os.Stat(iconPath); os.IsNotExist(err) {
-					iconPath = filepath.Join(api.GetPiAppsDir(), "icons", "none-64.png")
-				}
-			}
+// daemonTerminalScript returns the shell script the daemon terminal runs. It matches the original bash
+// implementation closely. Every value is quoted, app names and paths may contain spaces, quotes and $.
+func daemonTerminalScript(piAppsDir, execPath, pidFile, statusFile, queuePipe string, queue []gui.QueueItem, policy gui.OnCompletePolicy) string {
+	return fmt.Sprintf(`
+# Set up environment variables
+export PI_APPS_DIR=%s
+export DIRECTORY=%s
+export PI_APPS_ON_COMPLETE=%s
+export PI_APPS_REPORT_FILE=%s
 
-			queue = append(queue, QueueItem{
-				Action:   action,
-				AppName:  appName,
-				Status:   "waiting",
-				IconPath: iconPath,
-				ExitCode: -1,
-			})
-		}
-	}
+# Update daemon pid to that of the terminal
+echo $$ > %s
 
-	return queue
-}
+# Change to the directory containing the manage binary for consistency
+cd %s
 
-// validateQueue validates the queue items and shows GUI dialogs for errors if in GUI mode
-func validateQueue(queue []QueueItem) ([]QueueItem, error) {
-	return validateQueueWithGUI(queue, false)
+# Run the daemon terminal operations with logo and proper setup
+%s daemon-terminal %s %s %s
+`, api.ShellQuote(piAppsDir), api.ShellQuote(piAppsDir), api.ShellQuote(policy.String()), api.ShellQuote(gui.RunReportFile()), api.ShellQuote(pidFile),
+		api.ShellQuote(filepath.Dir(execPath)), api.ShellQuote(execPath), api.ShellQuote(gui.QueueLines(queue)),
+		api.ShellQuote(statusFile), api.ShellQuote(queuePipe))
 }
 
-// validateQueueWithGUI validates the queue items with optional GUI error dialogs
-func validateQueueWithGUI(queue []QueueItem, useGUI bool) ([]QueueItem, error) {
-	piAppsDir := api.GetPiAppsDir()
-	var validQueue []QueueItem
-
-	for _, item := range queue {
-		// Check if action is valid
-		validActions := []string{"install", "uninstall", "update", "refresh", "update-file"}
-		isValidAction := false
-		for _, validAction := range validActions {
-			if item.Action == validAction {
-				isValidAction = true
-				break
-			}
-		}
-
-		if !isValidAction {
-			errorMsg := fmt.Sprintf("Invalid action '%s' for app '%s', skipping", item.Action, item.AppName)
-			if useGUI {
-				gui.ShowMessageDialog("Error", fmt.Sprintf("Invalid action: <b>%s</b>", item.Action), 3)
-			} else {
-				fmt.Println(errorMsg)
-			}
-			continue
-		}
-
-		// For update-file actions, skip app validation
-		if item.Action == "update-file" {
-			validQueue = append(validQueue, item)
-			continue
-		}
-
-		// Check if app exists
-		var appDir string
-		appExists := false
-		if item.Action == "update" || item.Action == "refresh" {
-			appDir = filepath.Join(piAppsDir, "update", "pi-apps", "apps", item.AppName)
-			if _, err := os.Stat(appDir); err == nil {
-				appExists = true
-			}
-		} else {
-			appDir = filepath.Join(piAppsDir, "apps", item.AppName)
-			if _, err := os.Stat(appDir); err == nil {
-				appExists = true
-			} else if api.IsDeprecatedApp(item.AppName) {
-				// For deprecated apps, check if they have stored data
-				deprecatedDir := filepath.Join(piAppsDir, "data", "deprecated-apps", item.AppName)
-				if _, err := os.Stat(deprecatedDir); err == nil {
-					appExists = true
-					// For deprecated apps, only allow uninstall action
-					if item.Action != "uninstall" {
-						errorMsg := fmt.Sprintf("App '%s' is deprecated and can only be uninstalled, skipping", item.AppName)
-						if useGUI {
-							gui.ShowMessageDialog("Error", fmt.Sprintf("App \"<b>%s</b>\" is deprecated and can only be uninstalled.", item.AppName), 3)
-						} else {
-							fmt.Println(errorMsg)
-						}
-						continue
-					}
-				}
-			}
-		}
-
-		if !appExists {
-			errorMsg := fmt.Sprintf("App '%s' does not exist, skipping", item.AppName)
-			if useGUI {
-				gui.ShowMessageDialog("Error", fmt.Sprintf("Invalid app \"<b>%s</b>\". Cannot %s it.", item.AppName, item.Action), 3)
-			} else {
-				fmt.Println(errorMsg)
-			}
-			continue
-		}
-
-		// Check for redundant operations
-		appStatus, err := api.GetAppStatus(item.AppName)
-		if err != nil {
-			// If we can't get status, continue with the operation
-			validQueue = append(validQueue, item)
-			continue
-		}
-
-		if (item.Action == "install" && appStatus == "installed") ||
-			(item.Action == "uninstall" && appStatus == "uninstalled") {
-			infoMsg := fmt.Sprintf("App '%s' is already %sed, skipping", item.AppName, item.Action)
-			if useGUI {
-				// In GUI mode, this would typically be handled by ValidateAppsGUI, so just inform
-				fmt.Println(infoMsg)
-			} else {
-				fmt.Println(infoMsg)
-			}
-			continue
-		}
-		// Note: corrupted apps are allowed to be both installed and uninstalled
-
-		validQueue = append(validQueue, item)
-	}
+// runDaemonInCurrentShell is a fallback when terminal-run fails, it returns the exit code for the queue
+func runDaemonInCurrentShell(guiQueue []gui.QueueItem, statusFile string, policy gui.OnCompletePolicy) (int, error) {
+	fmt.Println("Falling back to running in current shell...")
+	runReport = gui.NewRunReporter(gui.RunReportFile(), gui.RunModeDaemon, GitCommit)
 
-	return validQueue, nil
+	var queueMutex sync.Mutex
+	return gui.ProcessQueue(&guiQueue, &queueMutex, statusFile, policy, runReport), nil
 }
 
-// reorderList reorders the queue to prioritize app refreshes and file updates over installs/uninstalls
-func reorderList(queue []gui.QueueItem) []gui.QueueItem {
-	// Split queue into completed and pending items
-	var completedItems []gui.QueueItem
-	var pendingItems []gui.QueueItem
-
-	for _, item := range queue {
-		// Check if item is completed (has a numeric status code)
-		if _, err := strconv.Atoi(item.Status); err == nil {
-			completedItems = append(completedItems, item)
-		} else {
-			pendingItems = append(pendingItems, item)
-		}
-	}
+// daemonTerminal processes the queue in the terminal window spawned by terminal-run
+func daemonTerminal(queueStr, statusFile, queuePipe string, policy gui.OnCompletePolicy) error {
+	// Output is copied to a log here, but it is still shown in a terminal window, so keep the colors
+	api.KeepTerminalColors()
 
-	// Split pending items by type
-	var pendingRefreshes []gui.QueueItem
-	var pendingFileUpdates []gui.QueueItem
-	var pendingOther []gui.QueueItem
-
-	for _, item := range pendingItems {
-		switch item.Action {
-		case "refresh":
-			pendingRefreshes = append(pendingRefreshes, item)
-		case "update-file":
-			pendingFileUpdates = append(pendingFileUpdates, item)
-		default:
-			pendingOther = append(pendingOther, item)
+	// The terminal script recorded the PID of its shell, record the own one with its start time instead
+	if queuePipe != "" {
+		if err := api.WritePIDFile(filepath.Join(filepath.Dir(queuePipe), "pid"), os.Getpid()); err != nil {
+			fmt.Printf("Warning: failed to write PID file: %v\n", err)
 		}
 	}
 
-	// Reconstruct queue in priority order:
-	// 1. Completed items (unchanged)
-	// 2. File updates
-	// 3. App refreshes
-	// 4. Other operations (installs/uninstalls)
-	var reorderedQueue []gui.QueueItem
-	reorderedQueue = append(reorderedQueue, completedItems...)
-	reorderedQueue = append(reorderedQueue, pendingFileUpdates...)
-	reorderedQueue = append(reorderedQueue, pendingRefreshes...)
-	reorderedQueue = append(reorderedQueue, pendingOther...)
-
-	return reorderedQueue
-}
+	// The queue runs here, so this process writes the run report
+	runReport = gui.NewRunReporter(gui.RunReportFile(), gui.RunModeDaemon, GitCommit)
+	finishReportOnSignal()
 
-// daemonTerminal processes the queue in the terminal window spawned by terminal-run
-func daemonTerminal(queueStr, statusFile, queuePipe string) error {
-	// Display Pi-Apps logo first
-	fmt.Print(api.GenerateLogo())
-
-	// Parse initial queue
-	queue := parseQueue(queueStr)
+	// Parse the initial queue, an operation queued twice runs once
+	_, queue := gui.NormalizeQueue(nil, gui.ParseQueue(queueStr))
 
 	// Validate the queue
-	if len(queue) > 0 {
-		validatedQueue, err := validateQueue(queue)
-		if err != nil {
-			return fmt.Errorf("failed to validate queue: %w", err)
-		}
-		queue = validatedQueue
-	}
+	queue = gui.ValidateQueue(queue, false)
 
 	if len(queue) == 0 {
+		runReport.Finish(nil)
 		return nil
 	}
 
-	// Convert internal QueueItem to gui.QueueItem
-	guiQueue := make([]gui.QueueItem, len(queue))
-	for i, item := range queue {
-		guiQueue[i] = gui.QueueItem{
-			Action:   item.Action,
-			AppName:  item.AppName,
-			Status:   item.Status,
-			IconPath: item.IconPath,
-		}
+	// Give every item its queue ID
+	guiQueue := slices.Clone(queue)
+	for i := range guiQueue {
+		guiQueue[i].ID = i + 1
 	}
 
+	// The queue listener adds, moves and removes items while the queue is processed
+	var queueMutex sync.Mutex
+
 	// Write initial status
 	err := writeQueueStatus(statusFile, guiQueue)
 	if err != nil {
 		fmt.Printf("Warning: failed to write initial status: %v\n", err)
 	}
+	err = gui.WriteDaemonTerminalState(statusFile, gui.DaemonTerminalState{OnComplete: policy.String(), TerminalOpen: true})
+	if err != nil {
+		fmt.Printf("Warning: failed to write terminal state: %v\n", err)
+	}
 
 	// Start queue listener for new incoming requests (if pipe is provided)
 	if queuePipe != "" {
@@ -1277,300 +1008,89 @@ func daemonTerminal(queueStr, statusFile, queuePipe string) error {
 				scanner := bufio.NewScanner(file)
 				for scanner.Scan() {
 					line := strings.TrimSpace(scanner.Text())
-					if line != "" {
-						fmt.Printf("Received new queue request: %s\n", line)
-
-						// Parse new queue items
-						newQueue := parseQueue(line)
+					if line == "" {
+						continue
+					}
 
-						// Validate new queue items
-						validatedNewQueue, err := validateQueue(newQueue)
-						if err != nil {
-							fmt.Printf("Warning: failed to validate new queue items: %v\n", err)
-							continue
-						}
+					request, legacy, err := gui.ParseQueuePipeLine(line)
+					if err != nil {
+						fmt.Printf("Warning: ignoring malformed queue message '%s': %v\n", line, err)
+						continue
+					}
 
-						// Add new items to the existing queue
-						for _, newItem := range validatedNewQueue {
-							newGuiItem := gui.QueueItem{
-								Action:   newItem.Action,
-								AppName:  newItem.AppName,
-								Status:   "waiting",
-								IconPath: newItem.IconPath,
+					// Reorder or remove a waiting item, or cancel the item in progress, sent by the progress monitor
+					if request.Command != nil {
+						queueMutex.Lock()
+						if request.Command.Verb == "cancel" {
+							err = gui.CancelQueueItem(guiQueue, request.Command.ID)
+						} else {
+							guiQueue, err = gui.ApplyQueueCommand(guiQueue, *request.Command)
+							if err == nil {
+								err = writeQueueStatus(statusFile, guiQueue)
 							}
-							guiQueue = append(guiQueue, newGuiItem)
 						}
-
-						// Update status file with new items
-						err = writeQueueStatus(statusFile, guiQueue)
+						queueMutex.Unlock()
 						if err != nil {
-							fmt.Printf("Warning: failed to write updated status: %v\n", err)
+							fmt.Printf("Warning: queue command '%s' failed: %v\n", line, err)
 						}
+						continue
 					}
-				}
-				file.Close()
-
-				if err := scanner.Err(); err != nil {
-					fmt.Printf("Warning: error reading from queue pipe: %v\n", err)
-				}
-			}
-		}()
-	}
-
-	// Process the queue with retry loop for failed apps
-	for {
-		currentIndex := 0
-		// Find next unprocessed item
-		for i := range guiQueue {
-			if guiQueue[i].Status == "waiting" {
-				currentIndex = i
-				break
-			}
-		}
-
-		// Check if all items are processed
-		allProcessed := true
-		for _, item := range guiQueue {
-			if item.Status == "waiting" {
-				allProcessed = false
-				break
-			}
-		}
-
-		if allProcessed {
-			// Check for failed apps and run diagnosis
-			var failedApps []string
-			for _, item := range guiQueue {
-				if item.Status == "failure" {
-					failedApps = append(failedApps, fmt.Sprintf("%s;%s", item.Action, item.AppName))
-				}
-			}
-
-			if len(failedApps) > 0 {
-				// Run diagnosis on failed apps
-				fmt.Println("\nDiagnosing failed operations...")
-				failureList := strings.Join(failedApps, "\n")
-
-				// Call the diagnose_apps function from the API
-				results := api.DiagnoseApps(failureList)
-
-				// Process the diagnosis results
-				var retryApps []string
-				for _, result := range results {
-					if result.Action == "retry" {
-						retryApps = append(retryApps, result.ActionStr)
-					}
-				}
 
-				if len(retryApps) > 0 {
-					// User chose to retry some operations
-					// Mark failed apps as "diagnosed" to avoid repeated diagnosis
-					for i := range guiQueue {
-						if guiQueue[i].Status == "failure" {
-							guiQueue[i].Status = "diagnosed"
+					// Parse new queue items, sent one per record or as an old-format queue string
+					var newQueue []gui.QueueItem
+					switch {
+					case request.Item != nil:
+						fmt.Printf("Received new queue request: %s %s\n", request.Item.Action, request.Item.AppName)
+						if item, ok := gui.ParseQueueEntry(request.Item.Action, request.Item.AppName); ok {
+							newQueue = append(newQueue, item)
 						}
+					case legacy:
+						fmt.Printf("Received new queue request: %s\n", line)
+						newQueue = gui.ParseQueue(line)
+					default:
+						// Header records only announce the protocol version
+						continue
 					}
 
-					// Add retry operations to the queue
-					retryQueue := parseQueue(strings.Join(retryApps, "\n"))
-					for _, retryItem := range retryQueue {
-						// Ensure icon path is properly set for retry items
-						iconPath := filepath.Join(api.GetPiAppsDir(), "apps", retryItem.AppName, "icon-64.png")
-						if _, err := os.Stat(iconPath); os.IsNotExist(err) {
-							iconPath = filepath.Join(api.GetPiAppsDir(), "icons", "none-64.png")
-						}
+					// Skip operations that already wait or run, and cancel out an install and an uninstall that both wait,
+					// before validation drops the uninstall of an app that is not installed yet
+					queueMutex.Lock()
+					guiQueue, newQueue = gui.NormalizeQueue(guiQueue, newQueue)
+					queueMutex.Unlock()
 
-						newGuiItem := gui.QueueItem{
-							Action:   retryItem.Action,
-							AppName:  retryItem.AppName,
-							Status:   "waiting",
-							IconPath: iconPath,
-						}
-						guiQueue = append(guiQueue, newGuiItem)
-					}
+					// Validate new queue items
+					newItems := gui.ValidateQueue(newQueue, false)
 
-					// Reorder the queue to prioritize updates and refreshes
-					guiQueue = reorderList(guiQueue)
-
-					// Write status update to show diagnosed items
-					err := writeQueueStatus(statusFile, guiQueue)
+					// Update status file with new items
+					queueMutex.Lock()
+					guiQueue = gui.AddToQueue(guiQueue, newItems)
+					err = writeQueueStatus(statusFile, guiQueue)
+					queueMutex.Unlock()
 					if err != nil {
 						fmt.Printf("Warning: failed to write updated status: %v\n", err)
 					}
-
-					// Add a small delay before starting retries (like in original implementation)
-					fmt.Println("Preparing to retry operations...")
-					time.Sleep(2 * time.Second)
-
-					// Continue processing the new items
-					continue
-				} else {
-					// No retries requested, we're done
-					break
-				}
-			} else {
-				// No failed apps, we're done
-				break
-			}
-		}
-
-		// Process next waiting item
-		if currentIndex < len(guiQueue) && guiQueue[currentIndex].Status == "waiting" {
-			// Update status to in-progress
-			guiQueue[currentIndex].Status = "in-progress"
-			err := writeQueueStatus(statusFile, guiQueue)
-			if err != nil {
-				fmt.Printf("Warning: failed to write status: %v\n", err)
-			}
-
-			// Set terminal title
-			fmt.Printf("\033]0;%sing %s\007", strings.ToUpper(guiQueue[currentIndex].Action[:1])+guiQueue[currentIndex].Action[1:], guiQueue[currentIndex].AppName)
-
-			// Execute the action - let API functions handle their own status messaging
-			var actionErr error
-			switch guiQueue[currentIndex].Action {
-			case "install":
-				actionErr = api.InstallApp(guiQueue[currentIndex].AppName)
-			case "uninstall":
-				actionErr = api.UninstallApp(guiQueue[currentIndex].AppName)
-			case "update":
-				actionErr = api.UpdateApp(guiQueue[currentIndex].AppName)
-			case "refresh":
-				actionErr = api.RefreshApp(guiQueue[currentIndex].AppName)
-			case "update-file":
-				actionErr = api.UpdateFile(guiQueue[currentIndex].AppName)
-			}
-
-			// Update status based on result
-			if actionErr != nil {
-				guiQueue[currentIndex].Status = "failure"
-				guiQueue[currentIndex].ErrorMessage = actionErr.Error()
-
-				// Format the log file to add device information for failed operations
-				logFile := api.GetLogfile(guiQueue[currentIndex].AppName)
-				if api.FileExists(logFile) {
-					err := api.FormatLogfile(logFile)
-					if err != nil {
-						fmt.Printf("Warning: failed to format log file %s: %v\n", logFile, err)
-					}
 				}
-			} else {
-				guiQueue[currentIndex].Status = "success"
+				file.Close()
 
-				// Format the log file for successful operations too (consistent with bash version)
-				logFile := api.GetLogfile(guiQueue[currentIndex].AppName)
-				if api.FileExists(logFile) {
-					err := api.FormatLogfile(logFile)
-					if err != nil {
-						fmt.Printf("Warning: failed to format log file %s: %v\n", logFile, err)
-					}
+				if err := scanner.Err(); err != nil {
+					fmt.Printf("Warning: error reading from queue pipe: %v\n", err)
 				}
 			}
-
-			// Write updated status
-			err = writeQueueStatus(statusFile, guiQueue)
-			if err != nil {
-				fmt.Printf("Warning: failed to write status: %v\n", err)
-			}
-		}
-	}
-
-	fmt.Println("\nAll operations completed. Press Enter to close...")
-	fmt.Scanln()
-
-	// Signal the progress monitor that daemon processing is complete
-	// Add a special completion marker to the queue
-	guiQueue = append(guiQueue, gui.QueueItem{
-		Action:   "daemon",
-		AppName:  "completed",
-		Status:   "daemon-complete",
-		IconPath: "",
-	})
-	err = writeQueueStatus(statusFile, guiQueue)
-	if err != nil {
-		fmt.Printf("Warning: failed to write completion status: %v\n", err)
+		}()
 	}
 
+	gui.ProcessQueue(&guiQueue, &queueMutex, statusFile, policy, runReport)
 	return nil
 }
 
-// writeQueueStatus writes the queue status to a file for IPC
+// writeQueueStatus writes the queue status to a file for IPC, and to the run report of a queue running in this process
 func writeQueueStatus(statusFile string, queue []gui.QueueItem) error {
-	if statusFile == "" {
-		return nil
-	}
-
-	file, err := os.Create(statusFile)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-
-	for _, item := range queue {
-		// Ensure icon path is valid (not empty or a directory)
-		iconPath := item.IconPath
-		if iconPath == "" || iconPath == api.GetPiAppsDir() {
-			// Fix invalid icon paths - check for deprecated apps first
-			if api.IsDeprecatedApp(item.AppName) {
-				iconPath = api.GetDeprecatedAppIcon(item.AppName)
-				if iconPath == "" {
-					iconPath = filepath.Join(api.GetPiAppsDir(), "icons", "none-64.png")
-				}
-			} else {
-				iconPath = filepath.Join(api.GetPiAppsDir(), "apps", item.AppName, "icon-64.png")
-				if _, err := os.Stat(iconPath); os.IsNotExist(err) {
-					iconPath = filepath.Join(api.GetPiAppsDir(), "icons", "none-64.png")
-				}
-			}
-		}
-
-		line := fmt.Sprintf("%s;%s;%s;%s;%s\n",
-			item.Action, item.AppName, item.Status, iconPath, item.ErrorMessage)
-		_, err := file.WriteString(line)
-		if err != nil {
-			return err
-		}
-	}
-
-	return nil
+	return gui.WriteDaemonStatus(statusFile, runReport, queue)
 }
 
 // readQueueStatus reads the queue status from a file for IPC
 func readQueueStatus(statusFile string) ([]gui.QueueItem, error) {
-	if statusFile == "" {
-		return nil, fmt.Errorf("no status file specified")
-	}
-
-	file, err := os.Open(statusFile)
-	if err != nil {
-		return nil, err
-	}
-	defer file.Close()
-
-	var queue []gui.QueueItem
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if line == "" {
-			continue
-		}
-
-		parts := strings.SplitN(line, ";", 5)
-		if len(parts) >= 4 {
-			item := gui.QueueItem{
-				Action:   parts[0],
-				AppName:  parts[1],
-				Status:   parts[2],
-				IconPath: parts[3],
-			}
-			if len(parts) >= 5 {
-				item.ErrorMessage = parts[4]
-			}
-			queue = append(queue, item)
-		}
-	}
-
-	return queue, scanner.Err()
+	return gui.ReadQueueStatus(statusFile)
 }
 
 // printUsage prints usage information
@@ -1592,7 +1112,10 @@ func printUsage() {
 	fmt.Println("  -refresh                  Refresh the specified apps")
 	fmt.Println("  -update-file              Update the specified files")
 	fmt.Println("  -daemon                   Run in daemon mode")
+	fmt.Println("  -on-complete <policy>     With -daemon: keep, close, close-on-success or timeout:<seconds> (default: setting, else keep)")
 	fmt.Println("  -version                  Show version information")
+	fmt.Println("  -no-interactive           Print this usage instead of the interactive mode when no operation is given")
+	fmt.Println("  -report-file <path>       Write the JSON report of the run to this file (default: $PI_APPS_REPORT_FILE, else data/manage-report.json)")
 	fmt.Println()
 	fmt.Println("Examples:")
 	fmt.Println("  manage -install Firefox LibreOffice")
@@ -1600,4 +1123,8 @@ func printUsage() {
 	fmt.Println("  manage -update-self")
 	fmt.Println("  manage -install-if-not-installed Firefox")
 	fmt.Println("  manage -install -gui -multi Firefox LibreOffice")
+	fmt.Println()
+	fmt.Println("A running daemon also accepts \"move <id> up|down|top\" and \"remove <id>\" for waiting items,")
+	fmt.Println("the ID is the first field of data/manage-daemon/status:")
+	fmt.Println("  manage -daemon \"move 3 top\"")
 }
@@ -0,0 +1,125 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/pi-apps-go/pi-apps/pkg/gui"
+)
+
+// testAppNames are app names that used to be mangled somewhere between the queue and the script
+var testAppNames = []string{"Better Chromium", "Box64 (x86_64)", "Rock 'n' Roll", "Café", "日本語入力"}
+
+// newTestPiAppsDir creates a Pi-Apps directory with the test apps and points PI_APPS_DIR at it
+func newTestPiAppsDir(t *testing.T) string {
+	t.Helper()
+	directory := t.TempDir()
+	for _, name := range []string{"api", "gui"} {
+		if err := os.WriteFile(filepath.Join(directory, name), []byte("#!/bin/bash\n"), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, dir := range []string{"data", "etc"} {
+		if err := os.MkdirAll(filepath.Join(directory, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, app := range testAppNames {
+		if err := os.MkdirAll(filepath.Join(directory, "apps", app), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(directory, "apps", app, "description"), []byte(app+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PI_APPS_DIR", directory)
+	return directory
+}
+
+func TestWriteQueueStatus(t *testing.T) {
+	newTestPiAppsDir(t)
+	statusFile := filepath.Join(t.TempDir(), "status")
+	var queue []gui.QueueItem
+	for i, app := range testAppNames {
+		queue = append(queue, gui.QueueItem{ID: i + 1, Action: "install", AppName: app, Status: "waiting"})
+	}
+	if err := writeQueueStatus(statusFile, queue); err != nil {
+		t.Fatal(err)
+	}
+
+	read, err := readQueueStatus(statusFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(read) != len(queue) {
+		t.Fatalf("read %d queue items, want %d", len(read), len(queue))
+	}
+	for i, item := range read {
+		if item.AppName != queue[i].AppName || item.Action != "install" || item.Status != "waiting" {
+			t.Errorf("queue item %d = %+v, want %s of %q", i, item, "install", queue[i].AppName)
+		}
+		if item.IconPath == "" {
+			t.Errorf("queue item %d has no icon", i)
+		}
+	}
+}
+
+func TestDaemonTerminalScript(t *testing.T) {
+	directory := newTestPiAppsDir(t)
+	// A manage binary in a folder whose name needs quoting, which prints what it was started with
+	binDir := filepath.Join(t.TempDir(), "Pi Apps 'ü' $HOME")
+	if err := os.MkdirAll(binDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	output := filepath.Join(t.TempDir(), "arguments")
+	execPath := filepath.Join(binDir, "manage")
+	fakeManage := "#!/bin/bash\n{ echo \"$PWD\"; echo \"$PI_APPS_DIR\"; echo \"$PI_APPS_REPORT_FILE\"; printf '%s\\n' \"$@\"; } > '" + output + "'\n"
+	if err := os.WriteFile(execPath, []byte(fakeManage), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	var queue []gui.QueueItem
+	for _, app := range testAppNames {
+		queue = append(queue, gui.QueueItem{Action: "install", AppName: app})
+	}
+	pidFile := filepath.Join(binDir, "pid")
+	statusFile := filepath.Join(binDir, "status")
+	queuePipe := filepath.Join(binDir, "queue")
+	// The daemon terminal writes the run report where the daemon was told to
+	reportFile := filepath.Join(binDir, "report $1.json")
+	t.Setenv(gui.RunReportEnv, reportFile)
+	script := daemonTerminalScript(directory, execPath, pidFile, statusFile, queuePipe, queue, gui.OnCompletePolicy{Mode: gui.OnCompleteKeep})
+
+	if out, err := exec.Command("bash", "-c", script).CombinedOutput(); err != nil {
+		t.Fatalf("the terminal script failed: %v\n%s", err, out)
+	}
+	content, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := binDir + "\n" + directory + "\n" + reportFile + "\ndaemon-terminal\n" + gui.QueueLines(queue) + "\n" + statusFile + "\n" + queuePipe + "\n"
+	if string(content) != want {
+		t.Errorf("the manage binary was started with\n%s\nwant\n%s", content, want)
+	}
+	if _, err := os.Stat(pidFile); err != nil {
+		t.Errorf("the terminal script didn't write its pid: %v", err)
+	}
+}
@@ -21,17 +21,26 @@
 package main
 
 import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
 	"runtime/debug"
+	"sort"
 	"strconv"
 	"strings"
+	"text/tabwriter"
+	"time"
 
 	"github.com/pi-apps-go/pi-apps/pkg/api"
+	"github.com/pi-apps-go/pi-apps/pkg/testharness"
 )
 
 func runAPI() {
@@ -66,6 +75,7 @@ func runAPI() {
 	}
 	// initialize variables required for api to function
 	api.Init()
+	defer api.FlushDownloadLedger()
 
 	// Parse command line flags
 	debugFlag := flag.Bool("debug", false, "Enable debug mode")
@@ -121,6 +131,11 @@ func runAPI() {
 	command := flag.Arg(0)
 	args := flag.Args()[1:]
 
+	// Reject unsafe app names before they are joined into paths
+	if err := validateAPIAppNameArgs(strings.ToLower(command), args); err != nil {
+		api.ErrorT(api.Tf("Error: %v", err))
+	}
+
 	// Execute the requested command
 	switch strings.ToLower(command) {
 	case "package_info":
@@ -183,6 +198,35 @@ func runAPI() {
 			fmt.Println(deps[0])
 		}
 
+	case "package_hold", "package_unhold":
+		if len(args) < 1 {
+			api.ErrorNoExitT("Error: No package specified")
+			api.StatusTf("Usage: api %s <package-name>", strings.ToLower(command))
+			os.Exit(1)
+		}
+		hold := api.PackageHold
+		if strings.ToLower(command) == "package_unhold" {
+			hold = api.PackageUnhold
+		}
+		if err := hold(args[0]); err != nil {
+			api.ErrorNoExit(err.Error())
+			os.Exit(1)
+		}
+
+	case "package_is_held":
+		if len(args) < 1 {
+			api.ErrorNoExitT("Error: No package specified")
+			api.StatusT("Usage: api package_is_held <package-name>")
+			os.Exit(1)
+		}
+		if api.PackageIsHeld(args[0]) {
+			fmt.Println("true")
+			os.Exit(0)
+		} else {
+			fmt.Println("false")
+			os.Exit(1)
+		}
+
 	case "package_installed_version":
 		if len(args) < 1 {
 			api.ErrorNoExitT("Error: No package specified")
@@ -229,14 +273,33 @@ func runAPI() {
 		}
 
 	case "download_file":
+		// Options in front: --limit-rate=200k overrides the "Download speed limit" setting for this download,
+		// --sha256=<hash> checks the file and --no-cache skips the asset cache
+		var options api.DownloadOptions
+	flags:
+		for len(args) > 0 {
+			switch arg := args[0]; {
+			case strings.HasPrefix(arg, "--limit-rate="):
+				if err := api.SetDownloadLimitOverride(strings.TrimPrefix(arg, "--limit-rate=")); err != nil {
+					api.ErrorT(api.Tf("Error: %v", err))
+				}
+			case strings.HasPrefix(arg, "--sha256="):
+				options.SHA256 = strings.TrimPrefix(arg, "--sha256=")
+			case arg == "--no-cache":
+				options.Cache = api.CacheNever
+			default:
+				break flags
+			}
+			args = args[1:]
+		}
 		if len(args) < 2 {
 			api.ErrorNoExitT("Error: Missing arguments")
-			api.StatusT("Usage: api download_file <url> <destination>")
+			api.StatusT("Usage: api download_file [--limit-rate=<rate>] [--sha256=<hash>] [--no-cache] <url> <destination>")
 			os.Exit(1)
 		}
 
-		if err := api.DownloadFile(args[0], args[1]); err != nil {
-			api.ErrorT(api.Tf("Error: %v", err))
+		if err := api.DownloadFileWithOptions(args[0], args[1], options); err != nil {
+			api.ErrorExit(err)
 		}
 
 	case "file_exists":
@@ -270,15 +333,8 @@ func runAPI() {
 		}
 
 	case "ensure_dir":
-		if len(args) < 1 {
-			api.ErrorNoExitT("Error: No directory specified")
-			api.StatusT("Usage: api ensure_dir <directory-path>")
-			os.Exit(1)
-		}
-
-		if err := api.EnsureDir(args[0]); err != nil {
-			api.ErrorT(api.Tf("Error: %v", err))
-		}
+		// Create a directory, in an app script recording it so uninstalling the app removes it: api ensure_dir --mode 700 ~/.config/foo
+		apiEnsureDirCommand(args)
 
 	case "copy_file":
 		if len(args) < 2 {
@@ -319,7 +375,7 @@ func runAPI() {
 		}
 
 		if err := api.TextEditor(args[0]); err != nil {
-			api.ErrorT(api.Tf("Error: %v", err))
+			api.ErrorExit(err)
 		}
 
 	case "view_file":
@@ -341,22 +397,7 @@ func runAPI() {
 		}
 
 	case "view_log":
-		if len(args) < 1 {
-			api.ErrorNoExitT("Error: No file specified")
-			api.StatusT("Usage: api view_log <file>")
-			os.Exit(1)
-		}
-
-		// Check if the file exists
-		if _, err := os.Stat(args[0]); os.IsNotExist(err) {
-			api.ErrorT(api.Tf("Error: File does not exist: %s", args[0]))
-		}
-
-		// Open file viewer
-		err := api.ViewFile(args[0])
-		if err != nil {
-			api.ErrorT(api.Tf("Error viewing file: %v", err))
-		}
+		apiViewLog(args)
 
 	case "logviewer":
 		if len(args) >= 1 {
@@ -378,9 +419,20 @@ func runAPI() {
 		}
 
 	case "categoryedit":
+		// --create allows a category that is not in the official lists
+		create := false
+		var categoryArgs []string
+		for _, arg := range args {
+			if arg == "--create" {
+				create = true
+			} else {
+				categoryArgs = append(categoryArgs, arg)
+			}
+		}
+		args = categoryArgs
 		if len(args) == 2 {
-			// Command line usage: categoryedit <app> <category>
-			err := api.EditAppCategory(args[0], args[1])
+			// Command line usage: categoryedit <app> <category> [--create]
+			err := api.EditAppCategory(args[0], args[1], create)
 			if err != nil {
 				api.ErrorT(api.Tf("Error editing app category: %v", err))
 			}
@@ -392,12 +444,17 @@ func runAPI() {
 			}
 		} else {
 			api.ErrorNoExitT("Error: Invalid number of arguments")
-			api.StatusT("Usage: api categoryedit [<app-name> <category>]")
+			api.StatusT("Usage: api categoryedit [<app-name> <category> [--create]]")
 			api.StatusT("  Without arguments: Shows GUI category editor")
-			api.StatusT("  With arguments: Sets category for specific app")
+			api.StatusT("  With arguments: Sets category for specific app, 'hidden' hides it and '' removes its category")
+			api.StatusT("  --create: Allow a category that is not in the official lists, 'api categories' lists them")
 			os.Exit(1)
 		}
 
+	case "categories":
+		// Categories apps can be moved to, with their number of apps: api categories --json
+		apiCategoriesCommand(args)
+
 	case "get_device_info":
 		// Call GetDeviceInfo and output the result
 		info, err := api.GetDeviceInfo()
@@ -407,8 +464,21 @@ func runAPI() {
 		fmt.Print(info)
 
 	case "diagnose_apps":
+		// --json prints the analysis for scripts instead of asking what to do
+		jsonOutput := false
+		var listArgs []string
+		for _, arg := range args {
+			switch arg {
+			case "--json", "-json":
+				jsonOutput = true
+			default:
+				listArgs = append(listArgs, arg)
+			}
+		}
+		args = listArgs
 		if len(args) < 1 {
 			api.ErrorNoExitT("Error: diagnose_apps requires a failure list")
+			api.StatusT("Usage: api diagnose_apps <failure-list> [--json]")
 			os.Exit(1)
 		}
 
@@ -440,7 +510,7 @@ func runAPI() {
 			}
 
 			// Format a proper failure list
-			failureList = fmt.Sprintf("%s;%s", action, appName)
+			failureList = api.QueueLine(action, appName)
 		} else {
 			// Input is already a failure list
 			failureList = input
@@ -451,6 +521,22 @@ func runAPI() {
 			api.ErrorT(api.T("Error: Invalid failure list format. Expected 'action;app'"))
 		}
 
+		if jsonOutput {
+			diagnoses, err := api.DiagnoseFailures(failureList)
+			if err != nil {
+				api.WarningTf("Some failures were not diagnosed: %v", err)
+			}
+			if diagnoses == nil {
+				diagnoses = []api.Diagnosis{}
+			}
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(diagnoses); err != nil {
+				api.ErrorT(api.Tf("Error: %v", err))
+			}
+			return
+		}
+
 		// Run the diagnostic UI
 		results := api.DiagnoseApps(failureList)
 
@@ -462,7 +548,11 @@ func runAPI() {
 			case "send":
 				logfilePath := api.GetLogfile(result.AppName)
 				fmt.Printf("Sending error report for %s...\n", result.AppName)
-				response, err := api.SendErrorReport(logfilePath)
+				response, err := api.SendErrorReportWithAttachments(logfilePath, result.Screenshot)
+				// Screenshots may contain private information, so don't keep them around after sending
+				if result.Screenshot != "" {
+					os.Remove(result.Screenshot)
+				}
 				if err != nil {
 					api.ErrorT(api.Tf("Error sending report: %s", err))
 				} else {
@@ -472,26 +562,42 @@ func runAPI() {
 		}
 
 	case "anything_installed_from_uri_suite_component":
-		if len(args) < 2 {
+		// --verbose lists the installed packages instead of only setting the exit code
+		verbose := false
+		var repoArgs []string
+		for _, arg := range args {
+			if arg == "--verbose" || arg == "-verbose" {
+				verbose = true
+				continue
+			}
+			repoArgs = append(repoArgs, arg)
+		}
+		if len(repoArgs) < 2 {
 			api.ErrorNoExitT("Error: Missing required arguments")
-			api.StatusT("Usage: api anything_installed_from_uri_suite_component <uri> <suite> [component]")
+			api.StatusT("Usage: api anything_installed_from_uri_suite_component <uri> <suite> [component] [--verbose]")
 			os.Exit(1)
 		}
 
-		uri := args[0]
-		suite := args[1]
+		uri := repoArgs[0]
+		suite := repoArgs[1]
 		component := ""
-		if len(args) > 2 {
-			component = args[2]
+		if len(repoArgs) > 2 {
+			component = repoArgs[2]
 		}
 
-		result, err := api.AnythingInstalledFromURISuiteComponent(uri, suite, component)
+		packages, err := api.PackagesInstalledFromRepo(uri, suite, component)
 		if err != nil {
 			api.ErrorT(api.Tf("Error: %v", err))
 		}
 
+		if verbose {
+			for _, pkg := range packages {
+				fmt.Println(pkg.String())
+			}
+		}
+
 		// Exit with code 0 if packages are installed, 1 if not
-		if !result {
+		if len(packages) == 0 {
 			os.Exit(1)
 		}
 
@@ -571,7 +677,7 @@ func runAPI() {
 		}
 
 		if err := api.InstallPackages(appName, args...); err != nil {
-			api.ErrorT(api.Tf("Error: %v", err))
+			api.ErrorExit(err)
 		}
 
 	case "purge_packages":
@@ -676,6 +782,12 @@ func runAPI() {
 		}
 
 	case "list_apps":
+		// Structured filters: api list_apps --installed --category Games --arch-compatible
+		if len(args) > 0 && strings.HasPrefix(args[0], "--") {
+			apiListAppsQuery(args)
+			break
+		}
+
 		var filter string
 		if len(args) > 0 {
 			filter = args[0]
@@ -1063,6 +1175,24 @@ func runAPI() {
 			api.ErrorT(api.Tf("Error: %v", err))
 		}
 
+	case "install_metrics":
+		// Shows the install statistics waiting to be sent, or removes them with --clear
+		if len(args) > 0 && (args[0] == "--clear" || args[0] == "-clear") {
+			if err := api.ClearInstallMetrics(); err != nil {
+				api.ErrorT(api.Tf("Error: %v", err))
+			}
+			api.StatusT("Removed the install statistics waiting to be sent.")
+			break
+		}
+		pending, err := api.PendingInstallMetrics()
+		if err != nil {
+			api.ErrorT(api.Tf("Error: %v", err))
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		for _, metric := range pending {
+			encoder.Encode(metric)
+		}
+
 	case "usercount":
 		var app string
 		if len(args) > 0 {
@@ -1132,6 +1262,30 @@ func runAPI() {
 
 		fmt.Println(appType)
 
+	case "app_info":
+		// App metadata bundle: api app_info Zoom --json
+		apiAppInfoCommand(args)
+
+	case "app_conflicts":
+		// Declared and detected conflicts: api app_conflicts Box64 --json
+		apiAppConflictsCommand(args)
+
+	case "user_data":
+		// Settings and data an app keeps after it is uninstalled: api user_data Zoom --json
+		apiUserDataCommand(args)
+
+	case "install_manifest":
+		// What the installation of an app recorded: api install_manifest Zoom --json
+		apiInstallManifestCommand(args)
+
+	case "app_repo":
+		// Extra app repositories: api app_repo add acme https://git.example.com/acme/apps.git
+		apiAppRepoCommand(args)
+
+	case "serve":
+		// Read-only app catalog for companion web UIs: api serve --addr :8080 [--allow-actions]
+		apiServeCommand(args)
+
 	case "pkgapp_packages_required":
 		if len(args) < 1 {
 			api.ErrorNoExitT("Error: No app specified")
@@ -1157,7 +1311,82 @@ func runAPI() {
 			fmt.Println(app)
 		}
 
+	case "rebuild_dummy_debs":
+		// Rebuild the given apps' dummy debs, or every app that is missing one
+		rebuilt, err := api.RebuildDummyDebs(args...)
+		if len(args) == 0 && len(rebuilt) == 0 && err == nil {
+			api.StatusT("No apps are missing their dummy deb.")
+		}
+		if err != nil {
+			api.ErrorT(api.Tf("Error: %v", err))
+		}
+
+	case "os_upgrade_check":
+		// Check whether the OS release changed since Pi-Apps last ran
+		apply := len(args) > 0 && args[0] == "--apply"
+		report, err := api.DetectOSUpgrade()
+		if err != nil {
+			api.ErrorT(api.Tf("Error: %v", err))
+		}
+		if report == nil {
+			api.StatusT("The OS release has not changed since Pi-Apps last ran.")
+			break
+		}
+
+		api.WarningTf("Your OS was upgraded from %s to %s.", report.OldCodename, report.NewCodename)
+		printAppGroup := func(title string, apps []string) {
+			fmt.Println(title)
+			if len(apps) == 0 {
+				fmt.Println("  " + api.T("(none)"))
+			}
+			for _, app := range apps {
+				fmt.Println("  " + app)
+			}
+		}
+		printAppGroup(api.T("Needs reinstall:"), report.NeedsReinstall)
+		printAppGroup(api.T("Probably fine:"), report.ProbablyFine)
+		printAppGroup(api.T("No longer supported on this release:"), report.Unsupported)
+		printAppGroup(api.T("Repos still pointing at an old release:"), report.StaleRepos)
+
+		if !apply {
+			api.StatusT("Run 'api os_upgrade_check --apply' to reinstall these apps and remove unused repos.")
+			break
+		}
+
+		if err := api.RemoveStaleOSRepos(report); err != nil {
+			api.WarningTf("Error: %v", err)
+		}
+		if queue := api.OSUpgradeQueue(report); queue != "" {
+			if err := api.TerminalManageMulti(queue); err != nil {
+				api.ErrorT(api.Tf("Error: %v", err))
+			}
+		}
+		if err := api.AcknowledgeOSUpgrade(); err != nil {
+			api.ErrorT(api.Tf("Error: %v", err))
+		}
+
 	case "runonce":
+		// Script on stdin: api runonce [--attempts N] [--env NAME]... <<"EOF"
+		var options api.RunonceOptions
+		for i := 0; i < len(args); i++ {
+			switch {
+			case args[i] == "--attempts" && i+1 < len(args):
+				attempts, err := strconv.Atoi(args[i+1])
+				if err != nil || attempts < 1 {
+					api.ErrorT(api.Tf("Error: invalid number of attempts: %s", args[i+1]))
+				}
+				options.MaxAttempts = attempts
+				i++
+			case args[i] == "--env" && i+1 < len(args):
+				options.Env = append(options.Env, args[i+1])
+				i++
+			default:
+				api.ErrorNoExitT(api.Tf("Error: unknown option %s", args[i]))
+				api.StatusT("Usage: api runonce [--attempts N] [--env NAME]... < script")
+				os.Exit(1)
+			}
+		}
+
 		// Read script from stdin
 		bytes, err := io.ReadAll(os.Stdin)
 		if err != nil {
@@ -1165,7 +1394,7 @@ func runAPI() {
 		}
 		script := string(bytes)
 
-		if err := api.Runonce(script); err != nil {
+		if err := api.RunonceWithOptions(script, options); err != nil {
 			api.ErrorT(api.Tf("Error: %v", err))
 		}
 
@@ -1232,6 +1461,10 @@ func runAPI() {
 			api.ErrorT(api.Tf("Error: %v", err))
 		}
 
+	case "regenerate_icons":
+		// Generates the icon sizes apps are missing, after they changed: api regenerate_icons --force
+		apiRegenerateIconsCommand(args)
+
 	case "refresh_pkgapp_status":
 		if len(args) < 1 {
 			api.ErrorNoExitT("Error: No app specified")
@@ -1302,10 +1535,238 @@ func runAPI() {
 			api.ErrorT(api.Tf("Error: %v", err))
 		}
 
+	case "fetch_script":
+		if len(args) < 2 {
+			api.ErrorNoExitT("Error: No URL or sha256 specified")
+			api.StatusT("Usage: api fetch_script <url> <sha256>")
+			os.Exit(1)
+		}
+		// Only the path goes to stdout, so scripts can run bash "$(fetch_script <url> <sha256>)"
+		path, err := api.FetchScriptVerified(args[0], args[1])
+		if err != nil {
+			api.ErrorT(api.Tf("Error: %v", err))
+		}
+		fmt.Println(path)
+
+	case "prompt_secret":
+		if len(args) < 1 {
+			api.ErrorNoExitT("Error: No prompt specified")
+			api.StatusT("Usage: api prompt_secret <prompt>")
+			os.Exit(1)
+		}
+		// Only the secret goes to stdout, so scripts can run token="$(prompt_secret "API token:")"
+		secret, err := api.PromptSecret(args[0])
+		if err != nil {
+			api.ErrorNoExitT(api.Tf("Error: %v", err))
+			os.Exit(api.RenderError(err).ExitCode)
+		}
+		fmt.Println(secret)
+
+	case "app_secret":
+		// Per-app secrets: api app_secret Zoom token [value|-]
+		apiAppSecretCommand(args)
+
+	case "unverified_scripts":
+		// Apps whose scripts still pipe downloads into a shell: api unverified_scripts --json
+		apiUnverifiedScriptsCommand(args)
+
+	case "downloads":
+		// Download ledger: api downloads --app Zoom --since 7d --json
+		apiDownloadLedgerCommand(args)
+
+	case "cache":
+		// Asset cache shared by the downloads of all apps: api cache stats, api cache prune --all
+		apiAssetCacheCommand(args)
+
+	case "clear_download_ledger":
+		if err := api.ClearDownloadLedger(); err != nil {
+			api.ErrorT(api.Tf("Error: %v", err))
+		}
+		api.StatusGreenT("Download ledger cleared")
+
+	case "export_bundle":
+		if len(args) < 2 {
+			api.ErrorNoExitT("Error: No app or bundle file specified")
+			api.StatusT("Usage: api export_bundle <app> <file>")
+			os.Exit(1)
+		}
+		if err := api.ExportAppBundle(args[0], args[1]); err != nil {
+			api.ErrorT(api.Tf("Error: %v", err))
+		}
+
+	case "install_bundle":
+		if len(args) < 1 {
+			api.ErrorNoExitT("Error: No bundle file specified")
+			api.StatusT("Usage: api install_bundle <file>")
+			os.Exit(1)
+		}
+		if err := api.InstallAppBundle(args[0]); err != nil {
+			api.ErrorT(api.Tf("Error: %v", err))
+		}
+
+	case "create_desktop_entry":
+		// Menu entry for an app: api create_desktop_entry name=Foo 'exec="/opt/My App/foo" %U' icon=/opt/foo/icon.png
+		apiCreateDesktopEntryCommand(args)
+
+	case "remove_desktop_entries":
+		if len(args) < 1 {
+			api.ErrorNoExitT("Error: No app specified")
+			api.StatusT("Usage: api remove_desktop_entries <app-name>")
+			os.Exit(1)
+		}
+		removed, err := api.RemoveDesktopEntries(args[0])
+		for _, path := range removed {
+			fmt.Println(path)
+		}
+		if err != nil {
+			api.ErrorT(api.Tf("Error: %v", err))
+		}
+
+	case "create_user_service":
+		// Background service of an app, one key=value pair per line on stdin:
+		// printf '%s\n' name=foo-sync "exec=$HOME/foo/foo --sync" enable=true start=true | api create_user_service
+		apiCreateUserServiceCommand(args)
+
+	case "remove_user_services":
+		if len(args) < 1 {
+			api.ErrorNoExitT("Error: No app specified")
+			api.StatusT("Usage: api remove_user_services <app-name>")
+			os.Exit(1)
+		}
+		removed, err := api.RemoveUserServices(args[0])
+		for _, path := range removed {
+			fmt.Println(path)
+		}
+		if err != nil {
+			api.ErrorT(api.Tf("Error: %v", err))
+		}
+
+	case "set_installed_version":
+		// Install scripts record the upstream version they installed: api set_installed_version 1.4.1
+		app := os.Getenv("app")
+		if len(args) > 1 {
+			app = args[1]
+		}
+		if len(args) < 1 || app == "" {
+			api.ErrorNoExitT("Error: No version or app specified")
+			api.StatusT("Usage: api set_installed_version <version> [app-name]")
+			os.Exit(1)
+		}
+		if err := api.SetInstalledUpstreamVersion(app, args[0]); err != nil {
+			api.ErrorT(api.Tf("Error: %v", err))
+		}
+
+	case "github_asset":
+		// Download URL and version of the release asset for this architecture: api github_asset ruffle-rs/ruffle
+		apiGithubAssetCommand(args)
+
+	case "outdated":
+		// Installed apps with a newer upstream release: api outdated --json
+		apiOutdatedCommand(args)
+
+	case "export_catalog":
+		// App metadata for the website: api export_catalog --directory . --include-icons catalog.json
+		apiExportCatalogCommand(args)
+
+	case "export_state":
+		// Installed apps, versions and channels for fleet checks: api export_state state.json
+		apiExportStateCommand(args)
+
+	case "state_hash":
+		// One hash over the installed apps to compare machines: api state_hash
+		apiStateHashCommand(args)
+
+	case "state_diff":
+		// Drift from an exported state: api state_diff state.json --json
+		apiStateDiffCommand(args)
+
+	case "audit_status":
+		// Status audit: api audit_status --fix
+		apiAuditStatusCommand(args)
+
+	case "clean_logs":
+		// Removes logs older than 6 days, or all but the running ones with --all
+		removed, err := api.CleanLogFiles(len(args) > 0 && args[0] == "--all")
+		if err != nil {
+			api.ErrorT(api.Tf("Error: %v", err))
+		}
+		api.StatusGreenTf("Removed %d log files", removed)
+
+	case "clean":
+		// Removes junk from the data directory: api clean --dry-run --json
+		apiCleanCommand(args)
+
+	case "import_legacy":
+		// Imports the app statuses, settings and category overrides of the bash version: api import_legacy ~/pi-apps --dry-run
+		apiImportLegacyCommand(args)
+
+	case "disk_usage":
+		// Disk space per installed app: api disk_usage Zoom --json
+		apiDiskUsageCommand(args)
+
+	case "owns":
+		// The app that installed a file: api owns /usr/local/bin/foo, --source also prints the record proving it
+		source := len(args) > 0 && args[0] == "--source"
+		if source {
+			args = args[1:]
+		}
+		if len(args) < 1 {
+			api.ErrorNoExitT("Error: No path specified")
+			api.StatusT("Usage: api owns [--source] <path>")
+			os.Exit(1)
+		}
+		app, record, err := api.WhichAppOwnsPath(args[0])
+		if errors.Is(err, api.ErrNoOwningApp) {
+			api.ErrorNoExit(api.Tf("No app owns %s", args[0]))
+			os.Exit(1)
+		} else if err != nil {
+			api.ErrorT(api.Tf("Error: %v", err))
+		}
+		if source {
+			fmt.Printf("%s\t%s\n", app, record)
+		} else {
+			fmt.Println(app)
+		}
+
+	case "doctor":
+		// Checks the Pi-Apps directory for problems that make installs fail
+		apiDoctorCommand()
+
+	case "healthcheck":
+		// Reruns the healthchecks of installed apps: api healthcheck Zoom --json
+		apiHealthCheckCommand(args)
+
+	case "repair":
+		// Finds out what is wrong with a broken app and repairs it: api repair Zoom --auto
+		apiRepairCommand(args)
+
+	case "repo_keys":
+		// Signing keys of the APT repositories and when they expire: api repo_keys --renew
+		apiRepoKeysCommand(args)
+
+	case "restore_apt_sources":
+		// Enables the repositories disabled after they made apt update fail
+		restored, err := api.RestoreAptSources()
+		if err != nil {
+			api.ErrorT(api.Tf("Error: %v", err))
+		}
+		api.StatusGreenTf("Restored %d sources files", restored)
+
+	case "test_app":
+		// Smoke-tests the scripts of an app in a container: api test_app <app> --os bookworm-arm64 --quick
+		apiTestAppCommand(args)
+
+	case "clear_caches":
+		freed, err := api.ClearCaches()
+		if err != nil {
+			api.ErrorT(api.Tf("Error: %v", err))
+		}
+		api.StatusGreenTf("Cleared the caches, %s freed", api.FormatSize(uint64(freed)))
+
 	case "chmod":
 		if len(args) < 2 {
 			api.ErrorNoExitT("Error: No mode specified")
-			api.StatusT("Usage: api chmod <mode> <file>")
+			api.StatusT("Usage: api chmod [-R] [--files-only|--dirs-only] [--system] <mode> <file>...")
 			os.Exit(1)
 		}
 
@@ -1325,11 +1786,8 @@ func runAPI() {
 		}
 
 	case "nproc":
-		nprocs, err := api.Nproc()
-		if err != nil {
-			api.ErrorT(api.Tf("Error: %v", err))
-		}
-		fmt.Println(nprocs)
+		// Parallel jobs for a compile: api nproc --per-job-mem 800
+		apiNprocCommand(args)
 
 	case "sudo_popup":
 		if len(args) < 1 {
@@ -1348,7 +1806,7 @@ func runAPI() {
 	case "process_exists":
 		if len(args) < 1 {
 			api.ErrorNoExitT("Error: No PID specified")
-			api.StatusT("Usage: api process_exists <pid>")
+			api.StatusT("Usage: api process_exists <pid> [name]")
 			os.Exit(1)
 		}
 
@@ -1357,7 +1815,13 @@ func runAPI() {
 			api.ErrorT(api.Tf("Error: Invalid PID '%s': %v", args[0], err))
 		}
 
-		if api.ProcessExists(pid) {
+		// With a name, a PID reused by another program doesn't count
+		exists := api.ProcessExists(pid)
+		if len(args) > 1 {
+			exists = api.ProcessExistsWithName(pid, args[1])
+		}
+
+		if exists {
 			fmt.Println("true")
 			os.Exit(0)
 		} else {
@@ -1376,6 +1840,10 @@ func runAPI() {
 			api.ErrorT(api.Tf("Error: %v", err))
 		}
 
+	case "kiosk":
+		// Kiosk mode of /etc/pi-apps/kiosk.conf: api kiosk, or api kiosk hash_pin for its admin_pin line
+		apiKioskCommand(args)
+
 	// UI/Output functions
 	case "status":
 		if len(args) < 1 {
@@ -1431,7 +1899,17 @@ func runAPI() {
 		fmt.Print(api.GenerateLogo())
 
 	case "add_english":
-		api.AddEnglish()
+		result := api.AddEnglish()
+		switch result.State {
+		case api.EnglishLocaleAlreadyPresent:
+			api.StatusGreenT("The en_US.UTF-8 locale is already generated")
+		case api.EnglishLocaleGenerated:
+			api.StatusGreenT("Generated the en_US.UTF-8 locale")
+		case api.EnglishLocaleSkipped:
+			api.StatusTf("Skipped adding the en_US.UTF-8 locale: %s", result.Reason)
+		default:
+			os.Exit(1)
+		}
 
 	case "createapp":
 		// Call with app name argument to edit existing app, or without to create new app
@@ -1459,7 +1937,7 @@ func runAPI() {
 		api.StatusT("Note: This command may require sudo privileges for system operations.")
 		api.StatusT("You may be prompted for your password during execution.")
 		if err := api.InstallApp(args[0]); err != nil {
-			api.ErrorT(api.Tf("Error: %v", err))
+			api.ErrorExit(err)
 		}
 		api.StatusGreenT("Installation completed successfully")
 
@@ -1472,7 +1950,7 @@ func runAPI() {
 		api.StatusT("Note: This command may require sudo privileges for system operations.")
 		api.StatusT("You may be prompted for your password during execution.")
 		if err := api.UninstallApp(args[0]); err != nil {
-			api.ErrorT(api.Tf("Error: %v", err))
+			api.ErrorExit(err)
 		}
 		api.StatusGreenT("Uninstallation completed successfully")
 
@@ -1485,7 +1963,7 @@ func runAPI() {
 		api.StatusT("Note: This command may require sudo privileges for system operations.")
 		api.StatusT("You may be prompted for your password during execution.")
 		if err := api.UpdateApp(args[0]); err != nil {
-			api.ErrorT(api.Tf("Error: %v", err))
+			api.ErrorExit(err)
 		}
 		api.StatusGreenT("Update completed successfully")
 
@@ -1498,7 +1976,7 @@ func runAPI() {
 		api.StatusT("Note: This command may require sudo privileges for system operations.")
 		api.StatusT("You may be prompted for your password during execution.")
 		if err := api.InstallIfNotInstalled(args[0]); err != nil {
-			api.ErrorT(api.Tf("Error: %v", err))
+			api.ErrorExit(err)
 		}
 		api.StatusGreenT("Command completed successfully")
 
@@ -1553,6 +2031,14 @@ func runAPI() {
 			fmt.Println(caption)
 		}
 
+	case "diagnose_replay":
+		// Checks the diagnosis of a corpus of failure logs against their expectations: api diagnose_replay pkg/api/testdata/log-corpus/apt
+		apiDiagnoseReplayCommand(args)
+
+	case "anonymize_log":
+		// Prints a log without the user and host names of this system: api anonymize_log failed-install.log
+		apiAnonymizeLogCommand(args)
+
 	case "format_logfile":
 		if len(args) < 1 {
 			api.ErrorNoExitT("Error: No log file specified")
@@ -1564,6 +2050,10 @@ func runAPI() {
 			api.ErrorT(api.Tf("Error: %v", err))
 		}
 
+	case "suggest_app":
+		// Suggests an app for Pi-Apps: api suggest_app Zoom https://zoom.us "Video calls" --send
+		apiSuggestAppCommand(args)
+
 	case "send_error_report":
 		if len(args) < 1 {
 			api.ErrorNoExitT("Error: No log file specified")
@@ -1623,6 +2113,9 @@ func printAPIUsage() {
 	fmt.Println("  package_available <package-name> [arch]      - " + api.T("Check if a package is available"))
 	fmt.Println("  package_dependencies <package-name>          - " + api.T("List package dependencies"))
 	fmt.Println("  package_installed_version <package-name>     - " + api.T("Get installed package version"))
+	fmt.Println("  package_hold <package-name>                  - " + api.T("Hold a package at its installed version"))
+	fmt.Println("  package_unhold <package-name>                - " + api.T("Remove the hold of a package"))
+	fmt.Println("  package_is_held <package-name>               - " + api.T("Check if a package is held"))
 	fmt.Println("  package_latest_version <package-name> [-t <repo>] - " + api.T("Get latest available package version"))
 	fmt.Println("  package_is_new_enough <package-name> <version> - " + api.T("Check if package meets version requirement"))
 	fmt.Println("  install_packages <package1> [package2] ... [-t repo] - " + api.T("Install packages (requires $app environment variable)"))
@@ -1639,30 +2132,64 @@ func printAPIUsage() {
 	fmt.Println("  ubuntu_ppa_installer <ppa-name>              - " + api.UbuntuPPAInstallerMessage)
 	fmt.Println("  debian_ppa_installer <ppa> <dist> <key>      - " + api.DebianPPAInstallerMessage)
 	fmt.Println("  remove_repofile_if_unused <file> [test] [key] - " + api.T("Remove repository file if not used"))
-	fmt.Println("  anything_installed_from_uri_suite_component <uri> <suite> [component] - " + api.T("Check if packages from a repo are installed"))
+	fmt.Println("  anything_installed_from_uri_suite_component <uri> <suite> [component] [--verbose] - " + api.T("Check if packages from a repo are installed, --verbose lists them"))
 	fmt.Println("  apt_lock_wait                                - " + api.AptLockWaitMessage)
 	fmt.Println("  apt_update                                   - " + api.T("Update package lists"))
 	fmt.Println("")
 	fmt.Println(api.T("File Operations:"))
-	fmt.Println("  download_file <url> <destination>            - " + api.T("Download file from URL"))
+	fmt.Println("  download_file [--limit-rate=<rate>] [--sha256=<hash>] [--no-cache] <url> <destination> - " + api.T("Download file from URL, --limit-rate=200k overrides the download speed limit, --sha256 checks the file"))
 	fmt.Println("  file_exists <file-path>                      - " + api.T("Check if file exists"))
 	fmt.Println("  dir_exists <directory-path>                  - " + api.T("Check if directory exists"))
-	fmt.Println("  ensure_dir <directory-path>                  - " + api.T("Create directory if it doesn't exist"))
+	fmt.Println("  ensure_dir [--mode <octal>] <directory-path> - " + api.T("Create directory if it doesn't exist, with the given permissions"))
 	fmt.Println("  copy_file <source> <destination>             - " + api.T("Copy file"))
 	fmt.Println("  view_file <file-path>                        - " + api.T("View file contents"))
 	fmt.Println("  files_match <file1> <file2>                  - " + api.T("Check if two files have identical content"))
 	fmt.Println("  text_editor <file-path>                      - " + api.T("Open file in preferred text editor"))
-	fmt.Println("  wget [options] <url>                         - " + api.T("Download files with progress display"))
+	fmt.Println("  wget [options] <url>                         - " + api.T("Download files with progress display, --limit-rate=200k overrides the download speed limit"))
 	fmt.Println("  unzip [options] <zipfile> [destination]      - " + api.T("Extract zip archives with standard options"))
-	fmt.Println("  chmod <mode> <file>                          - " + api.T("Change file permissions with logging"))
+	fmt.Println("  chmod [-R] [--system] <mode> <file>...       - " + api.T("Change file permissions with logging, --files-only/--dirs-only filter -R"))
 	fmt.Println("  git_clone <url> [dir] [options]              - " + api.T("Clone git repositories with status display"))
-	fmt.Println("  nproc                                        - " + api.T("Get optimal thread count based on available RAM"))
+	fmt.Println("  fetch_script <url> <sha256>                  - " + api.T("Download a script, check its sha256 and print the path to run it from"))
+	fmt.Println("  prompt_secret <prompt>                       - " + api.T("Ask for a secret without showing it and print it"))
+	fmt.Println("  app_secret <app-name> <key> [value|-]        - " + api.T("Print a stored secret of an app, or store one (- reads it from stdin)"))
+	fmt.Println("  unverified_scripts [--json]                  - " + api.T("List app scripts that pipe downloaded scripts into a shell without checking them"))
+	fmt.Println("  downloads [--app X] [--since T] [--json]     - " + api.T("List the URLs Pi-Apps downloaded content from"))
+	fmt.Println("  cache stats|prune [--all] [--json]           - " + api.T("Show the size of the cache of downloads shared by apps, or shrink it to the download cache size"))
+	fmt.Println("  clear_download_ledger                        - " + api.T("Remove all entries from the download ledger"))
+	fmt.Println("  export_bundle <app> <file>                   - " + api.T("Pack an app and its downloads into a bundle for offline installs"))
+	fmt.Println("  install_bundle <file>                        - " + api.T("Install an app from a bundle without network access"))
+	fmt.Println("  create_desktop_entry [key=value ...]         - " + api.T("Create a menu entry for $app from key=value arguments or lines on stdin"))
+	fmt.Println("  remove_desktop_entries <app-name>            - " + api.T("Remove the menu entries created with create_desktop_entry"))
+	fmt.Println("  create_user_service [key=value ...]          - " + api.T("Create a systemd user service for $app from key=value arguments or lines on stdin"))
+	fmt.Println("  remove_user_services <app-name>              - " + api.T("Stop and remove the user services created with create_user_service"))
+	fmt.Println("  set_installed_version <version> [app-name]   - " + api.T("Record the upstream version the install script of $app installed"))
+	fmt.Println("  github_asset <owner/repo> [tag] [--pattern <arch>=<glob>]... - " + api.T("Print the download URL and version of the release asset for this architecture"))
+	fmt.Println("  outdated [--json]                            - " + api.T("List installed apps with a newer upstream release"))
+	fmt.Println("  export_catalog [--directory <dir>] [--include-icons] <file> - " + api.T("Save the metadata of every app as JSON for the website"))
+	fmt.Println("  export_state [file]                          - " + api.T("Save the installed apps with their versions and channels as JSON"))
+	fmt.Println("  state_hash                                   - " + api.T("Print a hash of the installed apps, equal on machines with the same apps"))
+	fmt.Println("  state_diff [file] [--json]                   - " + api.T("Compare the installed apps with an exported state, exits 1 on drift"))
+	fmt.Println("  audit_status [--fix] [--json]                - " + api.T("Find app statuses that don't match the system"))
+	fmt.Println("  clean_logs [--all]                           - " + api.T("Remove old log files, or all of them"))
+	fmt.Println("  clean [--dry-run] [--json]                   - " + api.T("Remove stale status files, caches and leftovers from the data directory"))
+	fmt.Println("  import_legacy [dir] [--dry-run]              - " + api.T("Import the app statuses, settings and category overrides of the bash version of Pi-Apps in dir or ~/pi-apps"))
+	fmt.Println("  disk_usage [app] [--json]                    - " + api.T("Show the disk space of the packages, files, logs and downloads of installed apps"))
+	fmt.Println("  owns [--source] <path>                       - " + api.T("Print the app that installed a file, exits 1 if no app did"))
+	fmt.Println("  doctor                                       - " + api.T("Check the Pi-Apps folder and the package repositories for problems that make installs fail"))
+	fmt.Println("  healthcheck [app...] [--mark] [--json]       - " + api.T("Run the health checks of installed apps, --mark marks the failed ones as corrupted"))
+	fmt.Println("  repair <app> [--auto] [--dry-run] [--json]  - " + api.T("Find out what is wrong with a broken app and repair it, --auto takes the safest repair without asking"))
+	fmt.Println("  repo_keys [--json] [--renew [repo...]]       - " + api.T("List the signing keys of the APT repositories with their expiry dates, --renew downloads expiring ones again"))
+	fmt.Println("  restore_apt_sources                          - " + api.T("Enable the repositories disabled after they made apt update fail"))
+	fmt.Println("  test_app <app> [--os <target>] [--quick]     - " + api.T("Test the install and uninstall scripts of an app in a container"))
+	fmt.Println("  clear_caches                                 - " + api.T("Remove the app list and app hash caches"))
+	fmt.Println("  nproc [--per-job-mem <MB>] [--json]          - " + api.T("Get optimal thread count based on available RAM and swap"))
 	fmt.Println("")
 	fmt.Println(api.T("App Management:"))
 	fmt.Println("  flatpak_install <app-id>                     - " + api.T("Install Flatpak application"))
 	fmt.Println("  flatpak_uninstall <app-id>                   - " + api.T("Uninstall Flatpak application"))
 	fmt.Println("  app_to_pkgname <app-name>                    - " + api.T("Convert app name to package name"))
 	fmt.Println("  list_apps [filter]                           - " + api.T("List apps with optional filter"))
+	fmt.Println("  list_apps [--installed|--uninstalled|...]    - " + api.T("List apps matching combined filters (see list_apps --help)"))
 	fmt.Println("  read_category_files                          - " + api.T("Read category assignments"))
 	fmt.Println("  app_prefix_category [category]               - " + api.T("List apps with category prefix"))
 	fmt.Println("  terminal_manage <action> <app>               - " + api.T("Manage app via terminal"))
@@ -1672,13 +2199,23 @@ func printAPIUsage() {
 	fmt.Println("  script_name_cpu <app-name>                   - " + api.T("Show appropriate install script for CPU architecture"))
 	fmt.Println("  app_status <app-name>                        - " + api.T("Get app status (installed, uninstalled, etc.)"))
 	fmt.Println("  app_type <app-name>                          - " + api.T("Get app type (standard or package)"))
+	fmt.Println("  app_info <app-name> [--json]                 - " + api.T("Show the description, website, credits, icons and status of an app"))
+	fmt.Println("  app_conflicts <app-name> [--json]            - " + api.T("List the apps an app conflicts with, declared or detected from installed files"))
+	fmt.Println("  user_data <app-name> [--json]                - " + api.T("List the settings and data paths an app declares, with their size"))
+	fmt.Println("  install_manifest <app-name> [--json]         - " + api.T("Show the files, components and settings an app's installation recorded"))
+	fmt.Println("  app_repo add|remove|list [...]               - " + api.T("Manage extra app repositories, see api app_repo for the arguments"))
+	fmt.Println("  serve [--addr <host:port>] [--allow-actions] - " + api.T("Serve the app catalog as JSON, on localhost unless a host is given"))
 	fmt.Println("  pkgapp_packages_required <app-name>          - " + api.T("Get packages required for installation"))
+	fmt.Println("  list_apps_missing_dummy_debs                 - " + api.T("List installed apps whose dummy deb was removed"))
+	fmt.Println("  rebuild_dummy_debs [app-name] [...]          - " + api.RebuildDummyDebsMessage)
+	fmt.Println("  os_upgrade_check [--apply]                   - " + api.T("Detect an OS release upgrade and reinstall the apps it broke"))
 	fmt.Println("  will_reinstall <app-name>                    - " + api.T("Check if app will be reinstalled during update"))
 	fmt.Println("  app_search <query> [file1 file2 ...]         - " + api.T("Search for apps matching query in specified files"))
 	fmt.Println("  app_search_gui                               - " + api.T("Open graphical interface to search for apps"))
 	fmt.Println("  multi_install_gui                            - " + api.T("Open graphical interface to install multiple apps"))
 	fmt.Println("  multi_uninstall_gui                          - " + api.T("Open graphical interface to uninstall multiple apps"))
 	fmt.Println("  generate_app_icons <icon-path> <app-name>    - " + api.T("Generate 24x24 and 64x64 icons for an app"))
+	fmt.Println("  regenerate_icons [--force]                   - " + api.T("Generate the icon sizes all apps are missing into the icon cache"))
 	fmt.Println("  refresh_pkgapp_status <app-name> [pkg-name]  - " + api.T("Update status of a package-app"))
 	fmt.Println("  refresh_all_pkgapp_status                    - " + api.T("Update status of all package-apps"))
 	fmt.Println("  refresh_app_list                             - " + api.T("Force regeneration of the app list"))
@@ -1686,7 +2223,8 @@ func printAPIUsage() {
 	fmt.Println("  importapp                                    - " + api.T("Launch the Import App wizard"))
 	fmt.Println("  manage                                       - " + api.T("Manage apps"))
 	fmt.Println("  logviewer                                    - " + api.T("View log files in a graphical interface"))
-	fmt.Println("  categoryedit [<app-name> <category>]         - " + api.T("Edit app categories (GUI without args, CLI with args)"))
+	fmt.Println("  categoryedit [<app> <category> [--create]]   - " + api.T("Edit app categories (GUI without args, CLI with args)"))
+	fmt.Println("  categories [--json]                          - " + api.T("List the categories apps can be moved to, with their number of apps"))
 	fmt.Println("")
 	fmt.Println(api.T("List Operations:"))
 	fmt.Println("  list_intersect <list2> (list1 from stdin)    - " + api.T("Show items in both lists"))
@@ -1697,14 +2235,18 @@ func printAPIUsage() {
 	fmt.Println(api.T("Analytics and Statistics:"))
 	fmt.Println("  bitly_link <app> <trigger>                   - " + api.T("Send anonymous app usage analytics (legacy)"))
 	fmt.Println("  shlink_link <app> <trigger>                  - " + api.T("Send anonymous app usage analytics"))
+	fmt.Println("  install_metrics [--clear]                    - " + api.T("Show the opt-in install statistics waiting to be sent, or remove them"))
 	fmt.Println("  usercount [app-name]                         - " + api.T("Show number of users for an app or all apps"))
 	fmt.Println("")
 	fmt.Println(api.T("Diagnostic Tools:"))
 	fmt.Println("  log_diagnose <logfile> [--allow-write]       - " + api.T("Diagnose app error logs"))
+	fmt.Println("  diagnose_replay <dir> [--update]             - " + api.T("Check the diagnosis of a corpus of failure logs against the expected one, --update saves the current one"))
+	fmt.Println("  anonymize_log <logfile> [name]...            - " + api.T("Print a log without the user name, host name and the other names given, to add it to a corpus"))
 	fmt.Println("  format_logfile <logfile>                     - " + api.T("Format log file for readability"))
 	fmt.Println("  send_error_report <logfile>                  - " + api.T("Send error log to Pi-Apps developers"))
-	fmt.Println("  view_log <logfile>                           - " + api.T("View log contents"))
-	fmt.Println("  diagnose_apps <failure-list>                 - " + api.T("Diagnose app failures"))
+	fmt.Println("  suggest_app <name> <url> [why] [--send] [--force] - " + api.T("Suggest an app, as a GitHub issue or sent to the Pi-Apps team with --send"))
+	fmt.Println("  view_log <logfile> [--errors-only [--context <n>]] - " + api.T("View log contents, or print only the error lines"))
+	fmt.Println("  diagnose_apps <failure-list> [--json]        - " + api.T("Diagnose app failures, --json prints the analysis without asking anything"))
 	fmt.Println("  get_device_info                              - " + api.T("Show device information"))
 	fmt.Println("  less_apt <command>                           - " + api.LessAptMessage)
 	fmt.Println("")
@@ -1722,13 +2264,14 @@ func printAPIUsage() {
 	fmt.Println("  adoptium_installer                           - " + api.AdoptiumInstallerMessage)
 	fmt.Println("  pipx_install <package-name> [package2]       - " + api.T("Install Python packages with pipx"))
 	fmt.Println("  pipx_uninstall <package-name> [package2]     - " + api.T("Uninstall Python packages with pipx"))
-	fmt.Println("  runonce                                      - " + api.T("Run script only if it's never been run before"))
+	fmt.Println("  runonce [--attempts N] [--env NAME]...       - " + api.T("Run a script from stdin until it succeeded once, with a minimal environment"))
 	fmt.Println("  is_supported_system                          - " + api.T("Check if the current system is supported by Pi-Apps"))
 	fmt.Println("  sudo_popup <command> [args...]               - " + api.T("Run command with elevated privileges, using graphical auth if needed"))
 	fmt.Println("")
 	fmt.Println(api.T("System Operations:"))
-	fmt.Println("  process_exists <pid>                         - " + api.T("Check if a process with the given PID exists"))
+	fmt.Println("  process_exists <pid> [name]                  - " + api.T("Check if a process with the given PID, and name if given, exists"))
 	fmt.Println("  enable_module <module-name>                  - " + api.T("Ensure a kernel module is loaded and configured to load on startup"))
+	fmt.Println("  kiosk [hash_pin]                             - " + api.T("Show the state of kiosk mode, hash_pin prints the admin_pin line of /etc/pi-apps/kiosk.conf for a PIN"))
 	fmt.Println("")
 	fmt.Println(api.T("Plugin System:"))
 	fmt.Println("  " + api.T("Plugins are now build-time only. Use 'xpi-apps build --with <plugin>' to build pi-apps with plugins."))
@@ -1740,3 +2283,1961 @@ func printAPIUsage() {
 	fmt.Println("  --debug                                      - " + api.T("Enable debug mode"))
 
 }
+
+// apiCommandAppNameArgs maps commands that take app names to the index of their app name argument, -1 meaning every argument
+var apiCommandAppNameArgs = map[string]int{
+	"app_to_pkgname":           0,
+	"get_pi_app_icon":          0,
+	"remove_deprecated_app":    0,
+	"terminal_manage":          1,
+	"script_name":              0,
+	"script_name_cpu":          0,
+	"app_status":               0,
+	"app_type":                 0,
+	"app_info":                 0,
+	"app_conflicts":            0,
+	"user_data":                0,
+	"install_manifest":         0,
+	"app_secret":               0,
+	"remove_desktop_entries":   0,
+	"remove_user_services":     0,
+	"set_installed_version":    1,
+	"pkgapp_packages_required": 0,
+	"rebuild_dummy_debs":       -1,
+	"will_reinstall":           0,
+	"refresh_pkgapp_status":    0,
+	"install":                  0,
+	"uninstall":                0,
+	"update":                   0,
+	"install-if-not-installed": 0,
+}
+
+// validateAPIAppNameArgs validates the app name arguments of a command
+func validateAPIAppNameArgs(command string, args []string) error {
+	index, ok := apiCommandAppNameArgs[command]
+	if command == "categoryedit" && len(args) >= 2 {
+		index, ok = 0, true
+	}
+	if !ok {
+		return nil
+	}
+
+	for i, arg := range args {
+		if index == -1 || i == index {
+			if err := api.ValidateAppName(arg); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// apiListAppsQuery lists apps matching the structured filters of list_apps
+func apiListAppsQuery(args []string) {
+	flags := flag.NewFlagSet("list_apps", flag.ExitOnError)
+	installed := flags.Bool("installed", false, api.T("Only installed apps"))
+	uninstalled := flags.Bool("uninstalled", false, api.T("Only uninstalled apps"))
+	corrupted := flags.Bool("corrupted", false, api.T("Only corrupted apps"))
+	disabled := flags.Bool("disabled", false, api.T("Only disabled apps"))
+	category := flags.String("category", "", api.T("Only apps in this category"))
+	hidden := flags.Bool("hidden", false, api.T("Only hidden apps"))
+	visible := flags.Bool("visible", false, api.T("Only apps that are not hidden"))
+	packageApps := flags.Bool("package", false, api.T("Only package-apps"))
+	standardApps := flags.Bool("standard", false, api.T("Only apps with install scripts"))
+	flatpakApps := flags.Bool("flatpak", false, api.T("Only flatpak apps"))
+	archCompatible := flags.Bool("arch-compatible", false, api.T("Only apps that can be installed on this architecture"))
+	available := flags.Bool("available", false, api.T("Only apps that can be installed on this architecture, OS and device"))
+	offset := flags.Int("offset", 0, api.T("Number of matching apps to skip"))
+	limit := flags.Int("limit", 0, api.T("Maximum number of apps to list (0 for no limit)"))
+	jsonOutput := flags.Bool("json", false, api.T("Print name, status, category and type of each app as JSON"))
+	flags.Parse(args)
+
+	query := api.AppListQuery{
+		Category:       *category,
+		ArchCompatible: *archCompatible,
+		Available:      *available,
+		Offset:         *offset,
+		Limit:          *limit,
+	}
+
+	// Each group of flags selects a single value
+	pick := func(field *string, options map[string]bool) {
+		for value, set := range options {
+			if !set {
+				continue
+			}
+			if *field != "" {
+				api.ErrorT(api.Tf("Error: the %s and %s filters cannot be combined", *field, value))
+			}
+			*field = value
+		}
+	}
+	pick(&query.Status, map[string]bool{"installed": *installed, "uninstalled": *uninstalled, "corrupted": *corrupted, "disabled": *disabled})
+	pick(&query.Visibility, map[string]bool{"hidden": *hidden, "visible": *visible})
+	pick(&query.Type, map[string]bool{"package": *packageApps, "standard": *standardApps, "flatpak_package": *flatpakApps})
+
+	entries, err := api.QueryApps(query)
+	if err != nil {
+		api.ErrorT(api.Tf("Error: %v", err))
+	}
+
+	if *jsonOutput {
+		if entries == nil {
+			entries = []api.AppListEntry{}
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(entries); err != nil {
+			api.ErrorT(api.Tf("Error: %v", err))
+		}
+		return
+	}
+
+	for _, entry := range entries {
+		fmt.Println(entry.Name)
+	}
+}
+
+// apiAppRepoCommand manages the extra app repositories: api app_repo add|remove|list
+func apiAppRepoCommand(args []string) {
+	usage := func() {
+		api.StatusT("Usage: api app_repo add <name> <git-url> [branch]")
+		api.StatusT("       api app_repo remove <name> [--uninstall|--orphan]")
+		api.StatusT("       api app_repo list [--json]")
+		os.Exit(1)
+	}
+	if len(args) == 0 {
+		usage()
+	}
+
+	switch args[0] {
+	case "add":
+		if len(args) < 3 || len(args) > 4 {
+			usage()
+		}
+		repo := api.AppRepo{Name: args[1], URL: args[2]}
+		if len(args) == 4 {
+			repo.Branch = args[3]
+		}
+		if err := api.AddAppRepo(repo); err != nil {
+			api.ErrorT(api.Tf("Error: %v", err))
+		}
+		api.StatusTf("Downloading app repository %s...", repo.Name)
+		if err := api.SyncAppRepo(context.Background(), repo); err != nil {
+			api.RemoveAppRepo(repo.Name)
+			api.ErrorT(api.Tf("Error: %v", err))
+		}
+		if err := api.SyncAppRepos(context.Background()); err != nil {
+			api.Warning(err.Error())
+		}
+		api.StatusGreenTf("Added app repository %s with %d app(s), run the updater to get them", repo.Name, len(api.AppSourceApps(repo.Name)))
+
+	case "remove":
+		if len(args) < 2 || len(args) > 3 {
+			usage()
+		}
+		name := args[1]
+		mode := ""
+		if len(args) == 3 {
+			mode = strings.TrimLeft(args[2], "-")
+			if mode != "uninstall" && mode != "orphan" {
+				usage()
+			}
+		}
+
+		var installed []string
+		apps := api.AppSourceApps(name)
+		for _, app := range apps {
+			if status, _ := api.GetAppStatus(app); status == "installed" {
+				installed = append(installed, app)
+			}
+		}
+		if len(installed) > 0 && mode == "" {
+			if stat, err := os.Stdin.Stat(); err != nil || (stat.Mode()&os.ModeCharDevice) == 0 {
+				api.ErrorT(api.Tf("Error: the app(s) %s from app repository %s are installed, pass --uninstall or --orphan", strings.Join(installed, ", "), name))
+			}
+			fmt.Print(api.Tf("The app(s) %s from app repository %s are installed. (u)ninstall them or keep them as (o)rphaned local apps? (u/o): ", strings.Join(installed, ", "), name))
+			var response string
+			fmt.Scanln(&response)
+			switch strings.ToLower(response) {
+			case "u", "uninstall":
+				mode = "uninstall"
+			case "o", "orphan":
+				mode = "orphan"
+			default:
+				api.ErrorT("Error: canceled by user")
+			}
+		}
+
+		if mode == "uninstall" {
+			for _, app := range installed {
+				if err := api.ManageApp(api.ActionUninstall, app, false); err != nil {
+					api.WarningTf("Failed to uninstall %s, keeping it as an orphaned local app: %v", app, err)
+				}
+			}
+		}
+		// Apps that aren't installed anymore are deleted, the installed ones stay as local apps
+		for _, app := range apps {
+			if status, _ := api.GetAppStatus(app); status != "installed" {
+				os.RemoveAll(filepath.Join(api.GetPiAppsDir(), "apps", app))
+			}
+		}
+		if err := api.RemoveAppRepo(name); err != nil {
+			api.ErrorT(api.Tf("Error: %v", err))
+		}
+		api.StatusGreenTf("Removed app repository %s", name)
+
+	case "list":
+		repos, err := api.ReadAppRepos()
+		if err != nil {
+			api.ErrorT(api.Tf("Error: %v", err))
+		}
+		if len(args) > 1 && (args[1] == "--json" || args[1] == "-json") {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(repos); err != nil {
+				api.ErrorT(api.Tf("Error: %v", err))
+			}
+			return
+		}
+		for _, repo := range repos {
+			line := repo.Name + " " + repo.URL
+			if repo.Branch != "" {
+				line += " " + repo.Branch
+			}
+			fmt.Printf("%s (%s)\n", line, api.Tf("%d app(s)", len(api.AppSourceApps(repo.Name))))
+		}
+
+	default:
+		usage()
+	}
+}
+
+// apiAppConflictsCommand lists the declared and detected conflicts of an app
+func apiAppConflictsCommand(args []string) {
+	var app string
+	jsonOutput := false
+	for _, arg := range args {
+		switch arg {
+		case "--json", "-json":
+			jsonOutput = true
+		default:
+			app = arg
+		}
+	}
+	if app == "" {
+		api.ErrorNoExitT("Error: No app specified")
+		api.StatusT("Usage: api app_conflicts <app-name> [--json]")
+		os.Exit(1)
+	}
+
+	conflicts, err := api.AppConflicts(app)
+	if err != nil {
+		api.ErrorT(api.Tf("Error: %v", err))
+	}
+
+	if jsonOutput {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(conflicts); err != nil {
+			api.ErrorT(api.Tf("Error: %v", err))
+		}
+		return
+	}
+
+	for _, conflict := range conflicts {
+		var details []string
+		if conflict.Declared {
+			details = append(details, api.T("declared"))
+		}
+		if conflict.Installed {
+			details = append(details, api.T("installed"))
+		}
+		fmt.Printf("%s (%s)\n", conflict.App, strings.Join(details, ", "))
+		for _, file := range conflict.Files {
+			fmt.Printf("  %s\n", file)
+		}
+	}
+}
+
+// apiUserDataCommand lists the paths of an app's user-data file, whether they exist and their size
+func apiUserDataCommand(args []string) {
+	var app string
+	jsonOutput := false
+	for _, arg := range args {
+		switch arg {
+		case "--json", "-json":
+			jsonOutput = true
+		default:
+			app = arg
+		}
+	}
+	if app == "" {
+		api.ErrorNoExitT("Error: No app specified")
+		api.StatusT("Usage: api user_data <app-name> [--json]")
+		os.Exit(1)
+	}
+	if !api.IsValidApp(app) {
+		api.ErrorTf("Error: app '%s' does not exist", app)
+	}
+
+	paths, err := api.AppUserData(app)
+	if err != nil {
+		api.WarningTf("Invalid lines in the user-data file of %s: %v", app, err)
+	}
+
+	if jsonOutput {
+		if paths == nil {
+			paths = []api.UserDataPath{}
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(paths); err != nil {
+			api.ErrorT(api.Tf("Error: %v", err))
+		}
+		return
+	}
+
+	if len(paths) == 0 {
+		api.StatusTf("%s declares no user data", app)
+		return
+	}
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, path := range paths {
+		size := api.T("missing")
+		if path.Exists {
+			size = api.FormatSize(uint64(path.Size))
+		}
+		fmt.Fprintf(writer, "%s\t%s\n", size, path.Path)
+	}
+	writer.Flush()
+}
+
+// apiCategoriesCommand lists the official categories, those the user created and the special ones, with their number of apps
+func apiCategoriesCommand(args []string) {
+	jsonOutput := false
+	for _, arg := range args {
+		switch arg {
+		case "--json", "-json":
+			jsonOutput = true
+		}
+	}
+
+	counts, err := api.CategoryCounts(api.GetPiAppsDir())
+	if err != nil {
+		api.ErrorT(api.Tf("Error: %v", err))
+	}
+
+	if jsonOutput {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(counts); err != nil {
+			api.ErrorT(api.Tf("Error: %v", err))
+		}
+		return
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, count := range counts {
+		name := count.Category
+		switch {
+		case name == api.UnlistedCategory:
+			name = api.T("(no category)")
+		case name != api.HiddenCategory && !count.Official:
+			name += " " + api.T("(created)")
+		}
+		fmt.Fprintf(writer, "%d\t%s\n", count.Apps, name)
+	}
+	writer.Flush()
+}
+
+// apiAppSecretCommand prints a secret of an app, or stores one. A value of - is read from stdin, keeping it out of the
+// process list.
+func apiAppSecretCommand(args []string) {
+	if len(args) < 2 || len(args) > 3 {
+		api.ErrorNoExitT("Error: No app or key specified")
+		api.StatusT("Usage: api app_secret <app-name> <key> [value|-]")
+		os.Exit(1)
+	}
+	app, key := args[0], args[1]
+
+	if len(args) == 2 {
+		value, err := api.GetAppSecret(app, key)
+		if err != nil {
+			api.ErrorT(api.Tf("Error: %v", err))
+		}
+		fmt.Println(value)
+		return
+	}
+
+	value := args[2]
+	if value == "-" {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			api.ErrorT(api.Tf("Error: %v", err))
+		}
+		value = strings.TrimRight(string(data), "\r\n")
+	}
+	if err := api.StoreAppSecret(app, key, value); err != nil {
+		api.ErrorT(api.Tf("Error: %v", err))
+	}
+}
+
+// apiAppInfoCommand prints the metadata of an app, as JSON with --json
+func apiAppInfoCommand(args []string) {
+	var app string
+	jsonOutput := false
+	for _, arg := range args {
+		switch arg {
+		case "--json", "-json":
+			jsonOutput = true
+		default:
+			app = arg
+		}
+	}
+	if app == "" {
+		api.ErrorNoExitT("Error: No app specified")
+		api.StatusT("Usage: api app_info <app-name> [--json]")
+		os.Exit(1)
+	}
+
+	metadata, err := api.GetAppMetadata(app)
+	if err != nil {
+		api.ErrorT(api.Tf("Error: %v", err))
+	}
+
+	if jsonOutput {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(metadata); err != nil {
+			api.ErrorT(api.Tf("Error: %v", err))
+		}
+		return
+	}
+
+	sizes := make([]int, 0, len(metadata.IconPaths))
+	for size := range metadata.IconPaths {
+		sizes = append(sizes, size)
+	}
+	sort.Ints(sizes)
+
+	fmt.Printf("%s: %s\n", api.T("Name"), metadata.Name)
+	fmt.Printf("%s: %s\n", api.T("Status"), metadata.Status)
+	fmt.Printf("%s: %s\n", api.T("Type"), metadata.Type)
+	fmt.Printf("%s: %s\n", api.T("Category"), metadata.Category)
+	fmt.Printf("%s: %s\n", api.T("Architectures"), strings.Join(metadata.Architectures, " "))
+	fmt.Printf("%s: %s\n", api.T("Website"), metadata.Website)
+	for _, size := range sizes {
+		fmt.Printf("%s: %s\n", api.Tf("Icon (%dpx)", size), metadata.IconPaths[size])
+	}
+	fmt.Printf("\n%s:\n%s\n", api.T("Description"), metadata.LongDescription)
+	if metadata.Credits != "" {
+		fmt.Printf("\n%s:\n%s\n", api.T("Credits"), metadata.Credits)
+	}
+}
+
+// apiUnverifiedScriptsCommand lists the lines of app scripts that run downloaded scripts without checking their sha256
+func apiUnverifiedScriptsCommand(args []string) {
+	jsonOutput := len(args) > 0 && (args[0] == "--json" || args[0] == "-json")
+
+	pipes, err := api.UnverifiedScriptPipes()
+	if err != nil {
+		api.ErrorT(api.Tf("Error: %v", err))
+	}
+
+	if jsonOutput {
+		if pipes == nil {
+			pipes = []api.UnverifiedScriptPipe{}
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(pipes); err != nil {
+			api.ErrorT(api.Tf("Error: %v", err))
+		}
+		return
+	}
+
+	for _, pipe := range pipes {
+		fmt.Printf("%s\t%s:%d\t%s\n", pipe.App, pipe.Script, pipe.Line, pipe.Text)
+	}
+}
+
+// apiDownloadLedgerCommand lists the downloads recorded in the download ledger
+func apiDownloadLedgerCommand(args []string) {
+	flags := flag.NewFlagSet("downloads", flag.ExitOnError)
+	app := flags.String("app", "", api.T("Only downloads made while installing this app"))
+	since := flags.String("since", "", api.T("Only downloads after this date (2006-01-02), time (RFC 3339) or age (12h, 7d)"))
+	jsonOutput := flags.Bool("json", false, api.T("Print the entries as JSON"))
+	flags.Parse(args)
+
+	filter := api.DownloadLedgerFilter{App: *app}
+	if *since != "" {
+		t, err := api.ParseLedgerSince(*since)
+		if err != nil {
+			api.ErrorT(api.Tf("Error: %v", err))
+		}
+		filter.Since = t
+	}
+
+	entries, err := api.DownloadLedger(filter)
+	if err != nil {
+		api.ErrorT(api.Tf("Error: %v", err))
+	}
+
+	if *jsonOutput {
+		if entries == nil {
+			entries = []api.LedgerEntry{}
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(entries); err != nil {
+			api.ErrorT(api.Tf("Error: %v", err))
+		}
+		return
+	}
+
+	for _, entry := range entries {
+		app := entry.App
+		if app == "" {
+			app = "-"
+		}
+		checksum := entry.SHA256
+		if entry.Commit != "" {
+			checksum = "commit " + entry.Commit
+		}
+		if entry.CacheHit {
+			checksum += " " + api.T("(cached)")
+		}
+		fmt.Printf("%s\t%s\t%s\t%s\t%d\t%s\n", entry.Time.Local().Format("2006-01-02 15:04:05"), app, entry.URL, entry.Destination, entry.Bytes, checksum)
+	}
+}
+
+// apiAssetCacheCommand shows the statistics of the asset cache or prunes it
+func apiAssetCacheCommand(args []string) {
+	if len(args) < 1 || (args[0] != "stats" && args[0] != "prune") {
+		api.ErrorNoExitT("Error: No cache command specified")
+		api.StatusT("Usage: api cache stats [--json] | api cache prune [--all]")
+		os.Exit(1)
+	}
+	flags := flag.NewFlagSet("cache "+args[0], flag.ExitOnError)
+	jsonOutput := flags.Bool("json", false, api.T("Print the statistics as JSON"))
+	all := flags.Bool("all", false, api.T("Remove every cached download no installed app uses"))
+	flags.Parse(args[1:])
+
+	if args[0] == "prune" {
+		limit := api.AssetCacheLimit()
+		if *all {
+			limit = 0
+		}
+		removed, freed, err := api.PruneAssetCache(limit)
+		if err != nil {
+			api.ErrorT(api.Tf("Error: %v", err))
+		}
+		api.StatusGreenTf("Removed %d cached downloads, %s freed", removed, api.FormatSize(uint64(freed)))
+		return
+	}
+
+	stats, err := api.AssetCacheStatistics()
+	if err != nil {
+		api.ErrorT(api.Tf("Error: %v", err))
+	}
+	if *jsonOutput {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(stats); err != nil {
+			api.ErrorT(api.Tf("Error: %v", err))
+		}
+		return
+	}
+	limit := api.T("off")
+	if stats.Limit > 0 {
+		limit = api.FormatSize(uint64(stats.Limit))
+	}
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(writer, "%s\t%d (%s)\n", api.T("Cached downloads:"), stats.Assets, api.FormatSize(uint64(stats.Bytes)))
+	fmt.Fprintf(writer, "%s\t%d (%s)\n", api.T("Used by installed apps:"), stats.Referenced, api.FormatSize(uint64(stats.ReferencedBytes)))
+	fmt.Fprintf(writer, "%s\t%s\n", api.T("Download cache size:"), limit)
+	writer.Flush()
+}
+
+// apiServeCommand serves the app catalog over HTTP until it fails
+func apiServeCommand(args []string) {
+	addr := ""
+	allowActions, tokenStdin := false, false
+	for i := 0; i < len(args); i++ {
+		switch arg := args[i]; {
+		case arg == "--allow-actions" || arg == "-allow-actions":
+			allowActions = true
+		case arg == "--token-stdin" || arg == "-token-stdin":
+			// The GUI's remote mode passes the token through the SSH connection, so it never shows up in ps
+			tokenStdin = true
+		case arg == "--addr" || arg == "-addr":
+			if i+1 >= len(args) {
+				api.ErrorNoExitT("Error: --addr requires an address")
+				api.StatusT("Usage: api serve [--addr <host:port>] [--allow-actions [--token-stdin]]")
+				os.Exit(1)
+			}
+			i++
+			addr = args[i]
+		case strings.HasPrefix(arg, "--addr="):
+			addr = strings.TrimPrefix(arg, "--addr=")
+		default:
+			api.ErrorNoExitTf("Error: unknown argument %s", arg)
+			api.StatusT("Usage: api serve [--addr <host:port>] [--allow-actions [--token-stdin]]")
+			os.Exit(1)
+		}
+	}
+
+	if allowActions && tokenStdin {
+		token, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && token == "" {
+			api.ErrorT(api.Tf("Error: failed to read the token from stdin: %v", err))
+		}
+		if err := api.ServeCatalogWithToken(addr, strings.TrimSpace(token)); err != nil {
+			api.ErrorT(api.Tf("Error: %v", err))
+		}
+		return
+	}
+	if err := api.ServeCatalog(addr, allowActions); err != nil {
+		api.ErrorT(api.Tf("Error: %v", err))
+	}
+}
+
+// apiViewLog opens a log file in the file viewer, or with --errors-only prints its error lines with the lines around them
+func apiViewLog(args []string) {
+	var file string
+	errorsOnly := false
+	context := 2
+	for i := 0; i < len(args); i++ {
+		switch arg := args[i]; {
+		case arg == "--errors-only" || arg == "-errors-only":
+			errorsOnly = true
+		case arg == "--context" || arg == "-context":
+			if i+1 >= len(args) {
+				api.ErrorT("Error: --context needs a number of lines")
+			}
+			i++
+			n, err := strconv.Atoi(args[i])
+			if err != nil || n < 0 {
+				api.ErrorT(api.Tf("Error: invalid number of context lines: %s", args[i]))
+			}
+			context = n
+		case strings.HasPrefix(arg, "--context="):
+			n, err := strconv.Atoi(strings.TrimPrefix(arg, "--context="))
+			if err != nil || n < 0 {
+				api.ErrorT(api.Tf("Error: invalid number of context lines: %s", strings.TrimPrefix(arg, "--context=")))
+			}
+			context = n
+		default:
+			file = arg
+		}
+	}
+	if file == "" {
+		api.ErrorNoExitT("Error: No file specified")
+		api.StatusT("Usage: api view_log <file> [--errors-only [--context <n>]]")
+		os.Exit(1)
+	}
+
+	// Check if the file exists
+	if _, err := os.Stat(file); os.IsNotExist(err) {
+		api.ErrorT(api.Tf("Error: File does not exist: %s", file))
+	}
+
+	if errorsOnly {
+		logFile, err := os.Open(file)
+		if err != nil {
+			api.ErrorT(api.Tf("Error viewing file: %v", err))
+		}
+		defer logFile.Close()
+		found, err := api.PrintLogErrors(os.Stdout, logFile, context)
+		if err != nil {
+			api.ErrorT(api.Tf("Error viewing file: %v", err))
+		}
+		if found == 0 {
+			api.StatusT("No error lines found.")
+		}
+		return
+	}
+
+	// Open file viewer
+	if err := api.ViewFile(file); err != nil {
+		api.ErrorT(api.Tf("Error viewing file: %v", err))
+	}
+}
+
+// apiAuditStatusCommand prints the app statuses that don't match the system, and fixes them with --fix
+func apiAuditStatusCommand(args []string) {
+	flags := flag.NewFlagSet("audit_status", flag.ExitOnError)
+	fix := flags.Bool("fix", false, api.T("Fix the problems that can be fixed safely"))
+	jsonOutput := flags.Bool("json", false, api.T("Print the problems as JSON"))
+	flags.Parse(args)
+
+	problems, err := api.AuditAppStatuses(*fix)
+	if err != nil {
+		api.ErrorT(api.Tf("Error: %v", err))
+	}
+
+	if *jsonOutput {
+		if problems == nil {
+			problems = []api.StatusProblem{}
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(problems); err != nil {
+			api.ErrorT(api.Tf("Error: %v", err))
+		}
+		return
+	}
+
+	if len(problems) == 0 {
+		api.StatusGreenT("All app statuses match the system.")
+		return
+	}
+	unfixed := 0
+	for _, problem := range problems {
+		if problem.Fixed {
+			fmt.Println(api.Tf("%s: %s (fixed)", problem.App, problem.Problem))
+		} else {
+			fmt.Println(api.Tf("%s: %s", problem.App, problem.Problem))
+			unfixed++
+		}
+	}
+	if unfixed > 0 && !*fix {
+		api.StatusT("Run api audit_status --fix to fix them.")
+	}
+}
+
+// apiReadKeyValueArgs returns the key=value pairs given as arguments, or the ones on stdin, one per line, if there are none.
+// Empty lines and lines starting with # are skipped.
+func apiReadKeyValueArgs(args []string) ([]string, error) {
+	if len(args) > 0 {
+		return args, nil
+	}
+	input, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return nil, err
+	}
+	var pairs []string
+	for _, line := range strings.Split(string(input), "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+			pairs = append(pairs, line)
+		}
+	}
+	return pairs, nil
+}
+
+// apiCreateDesktopEntryCommand creates a menu entry from key=value pairs given as arguments, or one per line on stdin
+// if there are none. The entry is for the app given with app=, or $app when scripts call it.
+func apiCreateDesktopEntryCommand(args []string) {
+	pairs, err := apiReadKeyValueArgs(args)
+	if err != nil {
+		api.ErrorT(api.Tf("Error: %v", err))
+	}
+
+	entry, app, err := api.ParseDesktopEntryPairs(pairs)
+	if err != nil {
+		api.ErrorT(api.Tf("Error: %v", err))
+	}
+	if app == "" {
+		app = os.Getenv("app")
+	}
+	if app == "" {
+		api.ErrorNoExitT("Error: No app specified")
+		api.StatusT("Usage: api create_desktop_entry app=<app-name> name=<name> exec=<command> [icon=<icon>] [categories=<a;b>] [wmclass=<class>] [terminal=true] [system=true]")
+		os.Exit(1)
+	}
+
+	path, err := api.CreateDesktopEntry(app, entry)
+	if err != nil {
+		api.ErrorT(api.Tf("Error: %v", err))
+	}
+	fmt.Println(path)
+}
+
+// apiCreateUserServiceCommand creates a systemd user service from key=value pairs given as arguments, or one per line on stdin
+// if there are none. The service is for the app given with app=, or $app when scripts call it.
+func apiCreateUserServiceCommand(args []string) {
+	pairs, err := apiReadKeyValueArgs(args)
+	if err != nil {
+		api.ErrorT(api.Tf("Error: %v", err))
+	}
+
+	spec, app, err := api.ParseUserServicePairs(pairs)
+	if err != nil {
+		api.ErrorT(api.Tf("Error: %v", err))
+	}
+	if app == "" {
+		app = os.Getenv("app")
+	}
+	if app == "" {
+		api.ErrorNoExitT("Error: No app specified")
+		api.StatusT("Usage: api create_user_service app=<app-name> name=<name> exec=<command> [on_calendar=<schedule>] [enable=true] [start=true]")
+		os.Exit(1)
+	}
+
+	path, err := api.CreateUserService(app, spec)
+	if err != nil {
+		api.ErrorT(api.Tf("Error: %v", err))
+	}
+	fmt.Println(path)
+}
+
+// apiOutdatedCommand lists the installed apps whose upstream software has a newer release than the installed one
+func apiOutdatedCommand(args []string) {
+	jsonOutput := len(args) > 0 && (args[0] == "--json" || args[0] == "-json")
+
+	outdated, err := api.OutdatedApps()
+	if err != nil {
+		api.WarningTf("Some upstream checks failed: %v", err)
+	}
+
+	if jsonOutput {
+		if outdated == nil {
+			outdated = []api.UpstreamVersion{}
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(outdated); err != nil {
+			api.ErrorT(api.Tf("Error: %v", err))
+		}
+		return
+	}
+
+	for _, version := range outdated {
+		fmt.Printf("%s: %s -> %s\n", version.App, version.Installed, version.Latest)
+	}
+}
+
+// apiExportCatalogCommand saves the metadata of every app as JSON to a file, for the website. --directory exports the apps of
+// another Pi-Apps directory, like a checkout of the repository in CI.
+func apiExportCatalogCommand(args []string) {
+	const usage = "Usage: api export_catalog [--directory <dir>] [--include-icons] <file>"
+	var directory, output string
+	includeIcons := false
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--directory", "-directory":
+			if i+1 >= len(args) {
+				api.ErrorNoExitT(api.Tf("Error: %s needs a value", args[i]))
+				api.StatusT(usage)
+				os.Exit(1)
+			}
+			i++
+			directory = args[i]
+		case "--include-icons", "-include-icons":
+			includeIcons = true
+		default:
+			if strings.HasPrefix(args[i], "-") || output != "" {
+				api.ErrorNoExitT(api.Tf("Error: Unknown option %s", args[i]))
+				api.StatusT(usage)
+				os.Exit(1)
+			}
+			output = args[i]
+		}
+	}
+	if output == "" {
+		api.ErrorNoExitT("Error: No output file specified")
+		api.StatusT(usage)
+		os.Exit(1)
+	}
+	if directory == "" {
+		directory = api.GetPiAppsDir()
+	}
+
+	export, err := api.ExportCatalog(directory)
+	if err != nil {
+		api.ErrorT(api.Tf("Error: %v", err))
+	}
+	if err := api.WriteCatalogExport(export, directory, output, includeIcons); err != nil {
+		api.ErrorT(api.Tf("Error: %v", err))
+	}
+	api.StatusGreenTf("Saved %d apps to %s", len(export.Apps), output)
+}
+
+// apiExportStateCommand saves the installed apps as JSON to a file, or prints it
+func apiExportStateCommand(args []string) {
+	state, err := api.CurrentAppState()
+	if err != nil {
+		api.ErrorT(api.Tf("Error: %v", err))
+	}
+	if len(args) == 0 || args[0] == "-" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(state); err != nil {
+			api.ErrorT(api.Tf("Error: %v", err))
+		}
+		return
+	}
+	if err := api.WriteStateFile(args[0], state); err != nil {
+		api.ErrorT(api.Tf("Error: %v", err))
+	}
+	api.StatusGreenTf("Saved %d installed apps to %s", len(state.Apps), args[0])
+}
+
+// apiStateHashCommand prints the hash of the installed apps
+func apiStateHashCommand(args []string) {
+	state, err := api.CurrentAppState()
+	if err != nil {
+		api.ErrorT(api.Tf("Error: %v", err))
+	}
+	fmt.Println(state.Hash)
+}
+
+// apiStateDiffCommand compares the installed apps with an exported state, data/desired-state.json without a file.
+// It exits with 1 when they differ, so fleet scripts can check the exit code.
+func apiStateDiffCommand(args []string) {
+	path, jsonOutput := "", false
+	for _, arg := range args {
+		switch {
+		case arg == "--json" || arg == "-json":
+			jsonOutput = true
+		case path == "" && !strings.HasPrefix(arg, "-"):
+			path = arg
+		default:
+			api.ErrorNoExitTf("Error: unknown argument %s", arg)
+			api.StatusT("Usage: api state_diff [exported-state.json] [--json]")
+			os.Exit(1)
+		}
+	}
+	if path == "" {
+		path = api.DesiredStatePath()
+	}
+
+	desired, err := api.ReadStateFile(path)
+	if err != nil {
+		api.ErrorT(api.Tf("Error: %v", err))
+	}
+	current, err := api.CurrentAppState()
+	if err != nil {
+		api.ErrorT(api.Tf("Error: %v", err))
+	}
+	diff := api.DiffAppState(current, desired)
+
+	if jsonOutput {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(diff); err != nil {
+			api.ErrorT(api.Tf("Error: %v", err))
+		}
+	} else if diff.Empty() {
+		api.StatusGreenT("The installed apps match the exported state.")
+	} else {
+		for _, app := range diff.Add {
+			fmt.Println("+ " + strings.TrimSpace(app.Name+" "+app.Version))
+		}
+		for _, app := range diff.Remove {
+			fmt.Println("- " + strings.TrimSpace(app.Name+" "+app.Version))
+		}
+		for _, drift := range diff.Drift {
+			fmt.Printf("~ %s %s -> %s\n", drift.Name, apiStateVersion(drift.Version, drift.Channel), apiStateVersion(drift.DesiredVersion, drift.DesiredChannel))
+		}
+	}
+	if !diff.Empty() {
+		os.Exit(1)
+	}
+}
+
+// apiStateVersion formats a version and the channel it comes from for state_diff
+func apiStateVersion(version, channel string) string {
+	if version == "" {
+		version = "?"
+	}
+	if channel != "" {
+		return version + " (" + channel + ")"
+	}
+	return version
+}
+
+// apiNprocCommand prints how many parallel jobs a compile can run. Without --per-job-mem, the
+// per_job_mem requirement of $app is used when an install script calls it.
+func apiNprocCommand(args []string) {
+	perJobMem, jsonOutput := 0, false
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--per-job-mem", "-per-job-mem":
+			if i+1 < len(args) {
+				i++
+				if mb, err := strconv.Atoi(args[i]); err == nil && mb > 0 {
+					perJobMem = mb
+					continue
+				}
+			}
+			api.ErrorNoExitT("Error: --per-job-mem needs a number of MB")
+			api.StatusT("Usage: api nproc [--per-job-mem <MB>] [--json]")
+			os.Exit(1)
+		case "--json", "-json":
+			jsonOutput = true
+		default:
+			api.ErrorNoExitT(api.Tf("Error: Unknown option %s", args[i]))
+			api.StatusT("Usage: api nproc [--per-job-mem <MB>] [--json]")
+			os.Exit(1)
+		}
+	}
+	if perJobMem == 0 {
+		perJobMem = api.DefaultPerJobMemMB
+		if app := os.Getenv("app"); app != "" && api.ValidateAppName(app) == nil {
+			perJobMem = api.AppPerJobMemMB(app)
+		}
+	}
+
+	if jsonOutput {
+		advice, err := api.BuildResourceAdvice(perJobMem)
+		if err != nil {
+			api.ErrorT(api.Tf("Error: %v", err))
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(advice); err != nil {
+			api.ErrorT(api.Tf("Error: %v", err))
+		}
+		return
+	}
+
+	nprocs, err := api.NprocPerJob(perJobMem)
+	if err != nil {
+		api.ErrorT(api.Tf("Error: %v", err))
+	}
+	fmt.Println(nprocs)
+}
+
+// apiDoctorCommand checks the mount of the Pi-Apps directory and the package repositories,
+// exiting with 1 when app scripts can't run from the Pi-Apps directory
+func apiDoctorCommand() {
+	// How the Pi-Apps folder was found, every Pi-Apps program finds it the same way
+	resolution := api.StartPiAppsDirResolution()
+	if resolution.Source != "" {
+		fmt.Println(api.Tf("Pi-Apps folder: %s (from %s)", resolution.Dir, resolution.Source))
+	} else {
+		api.Warning(api.Tf("No valid Pi-Apps folder found, using %s", resolution.Dir))
+	}
+	for _, candidate := range resolution.Candidates {
+		switch {
+		case candidate.Path == "":
+			fmt.Printf("  %s: %s\n", candidate.Source, candidate.Problem)
+		case candidate.Problem != "":
+			fmt.Printf("  %s: %s (%s)\n", candidate.Source, candidate.Path, candidate.Problem)
+		default:
+			fmt.Printf("  %s: %s\n", candidate.Source, candidate.Path)
+		}
+	}
+	for _, duplicate := range resolution.Duplicates {
+		api.Warning(api.Tf("Another copy of Pi-Apps is in %s. Programs started from there don't see the apps installed with %s, remove the copy you don't use.", duplicate, resolution.Dir))
+	}
+
+	mount, problem, blocking := api.PiAppsDirMountProblem()
+	if mount.MountPoint != "" {
+		fmt.Println(api.Tf("Mount: %s", mount))
+	}
+	switch {
+	case problem == "":
+		api.StatusGreenT("The Pi-Apps folder has no problems")
+	case blocking:
+		api.ErrorNoExit(problem)
+	default:
+		api.Warning(problem)
+	}
+
+	// A wrong clock makes downloads fail with certificate errors and apt reject the package lists
+	if skew, err := api.CheckClockSkew(); err != nil {
+		api.Warning(api.Tf("Failed to check the system clock: %v", err))
+	} else if skew.Skewed() {
+		api.Warning(api.Tf("The system clock is %s. Turn on network time synchronization with: sudo timedatectl set-ntp true", skew))
+	} else {
+		api.StatusGreenTf("The system clock is %s", skew)
+	}
+
+	// A single broken repository fails apt update, and with it every app that installs packages
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	sources, err := api.CheckAptSources(ctx)
+	if err != nil {
+		api.Warning(api.Tf("Failed to check the package repositories: %v", err))
+	}
+	broken := 0
+	for _, source := range sources {
+		if source.Status != api.SourceOK {
+			broken++
+			api.Warning(source.String())
+		}
+	}
+	if broken > 0 && broken == len(sources) {
+		api.StatusT("No package repository is reachable. Check your internet connection.")
+	} else if broken > 0 {
+		api.StatusT("Fix or remove the broken repositories in /etc/apt/sources.list.d. When one makes an install fail, Pi-Apps offers to disable it.")
+	} else if len(sources) > 0 && err == nil {
+		api.StatusGreenTf("All %d package repositories are reachable", len(sources))
+	}
+
+	// apt rejects a repository with EXPKEYSIG once its signing key expired
+	if keys, err := api.ListExternalRepoKeys(); err != nil {
+		api.Warning(api.Tf("Failed to check the repository keys: %v", err))
+	} else if expiring := api.ExpiringRepoKeys(keys, time.Now()); len(expiring) > 0 {
+		for _, key := range expiring {
+			api.Warning(key.String())
+		}
+		api.StatusT("Run api repo_keys --renew to download the keys of repositories added by apps again.")
+	} else if len(keys) > 0 {
+		api.StatusGreenTf("None of the %d repository keys expires within %d days", len(keys), api.RepoKeyWarnDays)
+	}
+
+	// Apps that installed fine can stop working later, like when an OS upgrade removed a library they need
+	if results, err := api.RunHealthChecks(nil); err != nil {
+		api.Warning(api.Tf("Failed to run the health checks of the installed apps: %v", err))
+	} else {
+		unhealthy := 0
+		for _, result := range results {
+			if !result.Healthy {
+				unhealthy++
+				api.Warning(api.Tf("%s doesn't work: %s", result.App, result.Problem()))
+			}
+		}
+		if unhealthy > 0 {
+			api.StatusT("Reinstall the apps that don't work, or run api healthcheck --mark to mark them as corrupted.")
+		} else if len(results) > 0 {
+			api.StatusGreenTf("All %d apps with a health check work", len(results))
+		}
+	}
+
+	if blocking {
+		os.Exit(1)
+	}
+}
+
+// apiTestAppCommand runs the install and uninstall scripts of an app in a container and reports whether they passed
+func apiTestAppCommand(args []string) {
+	usage := "Usage: api test_app <app> [--os <target>] [--quick] [--image <image> --platform <platform>] [--install-only] [--api-bin <path>] [--runtime podman|docker] [--output <dir>] [--list]"
+	var app, targetName, image, platform string
+	options := testharness.Options{Output: os.Stdout}
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		value := func() string {
+			if i+1 >= len(args) {
+				api.ErrorNoExitT(api.Tf("Error: %s needs a value", arg))
+				api.StatusT(usage)
+				os.Exit(1)
+			}
+			i++
+			return args[i]
+		}
+		switch arg {
+		case "--os", "-os":
+			targetName = value()
+		case "--image", "-image":
+			image = value()
+		case "--platform", "-platform":
+			platform = value()
+		case "--api-bin", "-api-bin":
+			options.APIBinary = value()
+		case "--runtime", "-runtime":
+			options.Runtime = value()
+		case "--output", "-output":
+			options.OutputDir = value()
+		case "--quick", "-quick":
+			options.Quick = true
+		case "--install-only", "-install-only":
+			options.SkipUninstall = true
+		case "--list", "-list":
+			for _, target := range testharness.Targets() {
+				fmt.Printf("%-24s %-52s %s\n", target.Name, target.Image, target.Platform)
+			}
+			return
+		default:
+			if strings.HasPrefix(arg, "-") || app != "" {
+				api.ErrorNoExitT(api.Tf("Error: Unknown option %s", arg))
+				api.StatusT(usage)
+				os.Exit(1)
+			}
+			app = arg
+		}
+	}
+	if app == "" {
+		api.ErrorNoExitT("Error: test_app requires an app name")
+		api.StatusT(usage)
+		os.Exit(1)
+	}
+
+	switch {
+	case image != "":
+		if platform == "" {
+			platform = "linux/" + runtime.GOARCH
+		}
+		options.Target = testharness.Target{Name: image, Image: image, Platform: platform}
+	case targetName != "":
+		target, err := testharness.FindTarget(targetName)
+		if err != nil {
+			api.ErrorT(api.Tf("Error: %v", err))
+		}
+		options.Target = target
+	default:
+		options.Target = testharness.DefaultTarget()
+	}
+
+	api.StatusTf("Testing %s on %s", app, options.Target.Name)
+	result, err := testharness.Run(app, options)
+	if err != nil {
+		api.ErrorT(api.Tf("Error: %v", err))
+	}
+
+	fmt.Println()
+	for _, step := range result.Steps {
+		switch {
+		case !step.Passed:
+			api.ErrorNoExit(api.Tf("FAIL %s (exit code %d, %s) - %s", step.Script, step.ExitCode, step.Duration, step.LogPath))
+			if step.ErrorType != "" {
+				fmt.Println("  " + api.Tf("Diagnosis: %s", step.ErrorType))
+			}
+			for _, caption := range step.Captions {
+				fmt.Println("  " + caption)
+			}
+		case step.StoppedAt != "":
+			api.StatusGreen(api.Tf("PASS %s (%s, stopped at: %s) - %s", step.Script, step.Duration, step.StoppedAt, step.LogPath))
+		default:
+			api.StatusGreen(api.Tf("PASS %s (%s) - %s", step.Script, step.Duration, step.LogPath))
+		}
+	}
+	if !result.Passed {
+		os.Exit(1)
+	}
+}
+
+// apiImportLegacyCommand imports the state of an install of the bash version of Pi-Apps, the one in ~/pi-apps unless
+// a directory is given. With --dry-run it only lists what would be imported.
+func apiImportLegacyCommand(args []string) {
+	const usage = "Usage: api import_legacy [dir] [--dry-run]"
+	legacyDir, dryRun := "", false
+	for _, arg := range args {
+		switch {
+		case arg == "--dry-run" || arg == "-dry-run" || arg == "-n":
+			dryRun = true
+		case !strings.HasPrefix(arg, "-") && legacyDir == "":
+			legacyDir = arg
+		default:
+			api.ErrorNoExitT(api.Tf("Error: Unknown option %s", arg))
+			api.StatusT(usage)
+			os.Exit(1)
+		}
+	}
+	if legacyDir == "" {
+		if legacyDir = api.LegacyPiAppsDir(); legacyDir == "" {
+			api.ErrorT("Error: No install of the bash version of Pi-Apps found in ~/pi-apps")
+		}
+	}
+
+	report, err := api.ImportLegacyState(legacyDir, dryRun)
+	if err != nil {
+		api.ErrorT(api.Tf("Error: %v", err))
+	}
+	fmt.Print(report.Summary())
+	switch {
+	case report.Changes() == 0:
+		api.StatusTf("Nothing to import from %s", legacyDir)
+	case dryRun:
+		api.StatusTf("%d changes would be imported from %s", report.Changes(), legacyDir)
+	default:
+		api.StatusGreenTf("Imported %d changes from %s", report.Changes(), legacyDir)
+	}
+}
+
+// apiDiagnoseReplayCommand replays a corpus of failure logs through log_diagnose and lists the logs classified
+// differently than their expectation. With --update the current classifications become the expectations.
+func apiDiagnoseReplayCommand(args []string) {
+	const usage = "Usage: api diagnose_replay <dir> [--update]"
+	dir, update := "", false
+	for _, arg := range args {
+		switch {
+		case arg == "--update" || arg == "-update":
+			update = true
+		case !strings.HasPrefix(arg, "-") && dir == "":
+			dir = arg
+		default:
+			api.ErrorNoExitT(api.Tf("Error: Unknown option %s", arg))
+			api.StatusT(usage)
+			os.Exit(1)
+		}
+	}
+	if dir == "" {
+		api.ErrorNoExitT("Error: No corpus folder specified")
+		api.StatusT(usage)
+		os.Exit(1)
+	}
+
+	// Like in the test of the corpus, rules that look at this system or repair it find no commands to run
+	os.Setenv("PATH", "")
+	results, err := api.ReplayLogCorpus(dir)
+	if err != nil {
+		api.ErrorT(api.Tf("Error: %v", err))
+	}
+	differing := 0
+	for _, result := range results {
+		diff := result.Diff()
+		if len(diff) == 0 {
+			continue
+		}
+		if update && result.Err == nil {
+			if err := api.WriteLogCorpusExpectation(dir, result); err != nil {
+				api.ErrorT(api.Tf("Error: %v", err))
+			}
+			fmt.Printf("%s: %s\n", result.Name, api.T("expectation updated"))
+			continue
+		}
+		differing++
+		fmt.Printf("%s:\n", result.Name)
+		for _, line := range diff {
+			fmt.Println("  " + line)
+		}
+	}
+	if differing > 0 {
+		api.ErrorNoExitT(api.Tf("%d of %d logs are not classified as expected", differing, len(results)))
+		os.Exit(1)
+	}
+	api.StatusGreenTf("All %d logs are classified as expected", len(results))
+}
+
+// apiAnonymizeLogCommand prints a log without the user name and host name of this system and the other names given
+func apiAnonymizeLogCommand(args []string) {
+	if len(args) < 1 {
+		api.ErrorNoExitT("Error: No log file specified")
+		api.StatusT("Usage: api anonymize_log <logfile> [name]...")
+		os.Exit(1)
+	}
+	content, err := os.ReadFile(args[0])
+	if err != nil {
+		api.ErrorT(api.Tf("Error: %v", err))
+	}
+	fmt.Print(api.AnonymizeLog(string(content), args[1:]...))
+}
+
+// apiKioskCommand shows the state of kiosk mode, or prints the admin_pin line for a PIN
+func apiKioskCommand(args []string) {
+	if len(args) > 0 {
+		if args[0] != "hash_pin" {
+			api.ErrorNoExitT(api.Tf("Error: Unknown option %s", args[0]))
+			api.StatusT("Usage: api kiosk [hash_pin]")
+			os.Exit(1)
+		}
+		pin, err := api.PromptSecret(api.T("Admin PIN for kiosk mode"))
+		if err != nil {
+			api.ErrorT(api.Tf("Error: %v", err))
+		}
+		hash, err := api.HashKioskPIN(pin)
+		if err != nil {
+			api.ErrorT(api.Tf("Error: %v", err))
+		}
+		fmt.Println("admin_pin = " + hash)
+		return
+	}
+
+	fmt.Println(api.Tf("Kiosk mode: %s", api.KioskState()))
+	kiosk := api.ActiveKiosk()
+	if kiosk == nil {
+		return
+	}
+	if len(kiosk.AllowApps) > 0 {
+		fmt.Println(api.Tf("Allowed apps: %s", strings.Join(kiosk.AllowApps, ", ")))
+	}
+	if len(kiosk.AllowCategories) > 0 {
+		fmt.Println(api.Tf("Allowed categories: %s", strings.Join(kiosk.AllowCategories, ", ")))
+	}
+	if len(kiosk.HideApps) > 0 {
+		fmt.Println(api.Tf("Hidden apps: %s", strings.Join(kiosk.HideApps, ", ")))
+	}
+	for _, feature := range []api.KioskFeature{api.KioskUninstall, api.KioskSettings, api.KioskCreateApp, api.KioskImportApp} {
+		allowed := api.T("no")
+		if kiosk.Allows(feature) {
+			allowed = api.T("yes")
+		}
+		fmt.Printf("%s: %s\n", feature, allowed)
+	}
+}
+
+// apiGithubAssetCommand prints the download URL of the GitHub release asset for this architecture, and the release version
+func apiGithubAssetCommand(args []string) {
+	var positional []string
+	patterns := make(map[string]string)
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--pattern" || arg == "-pattern" {
+			if i+1 >= len(args) {
+				api.ErrorNoExitT("Error: --pattern needs an <arch>=<glob> value")
+				os.Exit(1)
+			}
+			i++
+			arg = "--pattern=" + args[i]
+		}
+		if value, ok := strings.CutPrefix(arg, "--pattern="); ok {
+			arch, glob, found := strings.Cut(value, "=")
+			if !found || glob == "" || !api.KnownAssetArch(arch) {
+				api.ErrorNoExitT(api.Tf("Error: Invalid pattern %s, use <arch>=<glob> with arm64, armhf, amd64, i386 or *", value))
+				os.Exit(1)
+			}
+			patterns[arch] = glob
+			continue
+		}
+		positional = append(positional, arg)
+	}
+	var owner, repo string
+	if len(positional) == 1 || len(positional) == 2 {
+		owner, repo, _ = strings.Cut(positional[0], "/")
+	}
+	if owner == "" || repo == "" {
+		api.ErrorNoExitT("Error: No GitHub repository specified")
+		api.StatusT("Usage: api github_asset <owner/repo> [tag] [--pattern <arch>=<glob>]...")
+		os.Exit(1)
+	}
+	tag := "latest"
+	if len(positional) > 1 {
+		tag = positional[1]
+	}
+
+	url, version, err := api.SelectGitHubReleaseAsset(owner, repo, tag, patterns)
+	if err != nil {
+		api.ErrorT(api.Tf("Error: %v", err))
+	}
+	fmt.Println(url)
+	fmt.Println(version)
+}
+
+// apiInstallManifestCommand shows what the install manifest of an app records: the upstream version, resource limits, chosen
+// components, user services, created directories and installed files
+func apiInstallManifestCommand(args []string) {
+	var app string
+	jsonOutput := false
+	for _, arg := range args {
+		switch arg {
+		case "--json", "-json":
+			jsonOutput = true
+		default:
+			app = arg
+		}
+	}
+	if app == "" {
+		api.ErrorNoExitT("Error: No app specified")
+		api.StatusT("Usage: api install_manifest <app-name> [--json]")
+		os.Exit(1)
+	}
+	if !api.IsValidApp(app) {
+		api.ErrorTf("Error: app '%s' does not exist", app)
+	}
+
+	manifest, err := api.ReadInstallManifest(app)
+	if err != nil {
+		api.ErrorT(api.Tf("Error: %v", err))
+	}
+
+	if jsonOutput {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(manifest); err != nil {
+			api.ErrorT(api.Tf("Error: %v", err))
+		}
+		return
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	if manifest.UpstreamVersion != "" {
+		fmt.Fprintf(writer, "%s\t%s\n", api.T("Upstream version:"), manifest.UpstreamVersion)
+	}
+	if manifest.Limits != "" {
+		fmt.Fprintf(writer, "%s\t%s\n", api.T("Resource limits:"), manifest.Limits)
+	}
+	if manifest.RanAsRoot {
+		fmt.Fprintf(writer, "%s\t%s\n", api.T("Ran as root:"), api.T("yes"))
+	}
+	if len(manifest.Components) > 0 {
+		var chosen, skipped []string
+		for _, component := range manifest.Components {
+			if component.Selected {
+				chosen = append(chosen, component.ID)
+			} else {
+				skipped = append(skipped, component.ID)
+			}
+		}
+		fmt.Fprintf(writer, "%s\t%s\n", api.T("Components:"), strings.Join(chosen, ", "))
+		if len(skipped) > 0 {
+			fmt.Fprintf(writer, "%s\t%s\n", api.T("Not installed components:"), strings.Join(skipped, ", "))
+		}
+	}
+	for _, service := range manifest.UserServices {
+		fmt.Fprintf(writer, "%s\t%s\n", api.T("User service:"), service)
+	}
+	for _, dir := range manifest.CreatedDirs {
+		fmt.Fprintf(writer, "%s\t%s\n", api.T("Created directory:"), dir)
+	}
+	for _, asset := range manifest.CachedAssets {
+		fmt.Fprintf(writer, "%s\t%s\n", api.T("Cached download:"), asset)
+	}
+	writer.Flush()
+	if len(manifest.Files) == 0 {
+		api.StatusTf("%s recorded no installed files", app)
+		return
+	}
+	for _, file := range manifest.Files {
+		fmt.Println(file)
+	}
+}
+
+// apiEnsureDirCommand creates a directory with its missing parents. In an app script, where $app is set, the directories
+// it created are recorded in the install manifest of the app so uninstalling it removes them when they are empty.
+func apiEnsureDirCommand(args []string) {
+	var path string
+	var mode os.FileMode
+	for i := 0; i < len(args); i++ {
+		switch arg := args[i]; {
+		case arg == "--mode" || arg == "-m":
+			if i+1 >= len(args) {
+				api.ErrorT("Error: --mode requires a value")
+			}
+			i++
+			value, err := strconv.ParseUint(args[i], 8, 32)
+			if err != nil || value > 07777 || value == 0 {
+				api.ErrorTf("Error: invalid mode '%s', expected an octal mode like 700", args[i])
+			}
+			mode = os.FileMode(value & 0777)
+			if value&04000 != 0 {
+				mode |= os.ModeSetuid
+			}
+			if value&02000 != 0 {
+				mode |= os.ModeSetgid
+			}
+			if value&01000 != 0 {
+				mode |= os.ModeSticky
+			}
+		default:
+			path = arg
+		}
+	}
+	if path == "" {
+		api.ErrorNoExitT("Error: No directory specified")
+		api.StatusT("Usage: api ensure_dir [--mode <octal>] <directory-path>")
+		os.Exit(1)
+	}
+
+	if _, err := api.EnsureAppDir(os.Getenv("app"), path, mode); err != nil {
+		api.ErrorT(api.Tf("Error: %v", err))
+	}
+}
+
+// apiRepairCommand finds out what is wrong with an app and repairs it, asking how unless --auto is given or nobody can
+// answer. --dry-run only prints what was found and the applicable repairs.
+func apiRepairCommand(args []string) {
+	var app string
+	auto, dryRun, jsonOutput := false, false, false
+	for _, arg := range args {
+		switch {
+		case arg == "--auto" || arg == "-auto":
+			auto = true
+		case arg == "--dry-run" || arg == "-dry-run":
+			dryRun = true
+		case arg == "--json" || arg == "-json":
+			jsonOutput = true
+		case !strings.HasPrefix(arg, "-") && app == "":
+			app = arg
+		default:
+			api.ErrorNoExitTf("Error: unknown argument %s", arg)
+			api.StatusT("Usage: api repair <app> [--auto] [--dry-run] [--json]")
+			os.Exit(1)
+		}
+	}
+	if app == "" {
+		api.ErrorNoExitT("Error: repair: requires an app name")
+		api.StatusT("Usage: api repair <app> [--auto] [--dry-run] [--json]")
+		os.Exit(1)
+	}
+
+	var report api.RepairReport
+	var err error
+	if dryRun {
+		report, err = api.DiagnoseRepair(app)
+	} else {
+		// The choice is asked in a dialog if there is a display, otherwise on the terminal
+		interactive := !auto && !jsonOutput
+		if interactive && os.Getenv("DISPLAY") == "" && os.Getenv("WAYLAND_DISPLAY") == "" {
+			stat, statErr := os.Stdin.Stat()
+			interactive = statErr == nil && stat.Mode()&os.ModeCharDevice != 0
+		}
+		report, err = api.RepairApp(app, interactive)
+	}
+	if report.App == "" && err != nil {
+		api.ErrorExit(err)
+	}
+
+	if jsonOutput {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if encodeErr := encoder.Encode(report); encodeErr != nil {
+			api.ErrorT(api.Tf("Error: %v", encodeErr))
+		}
+	} else {
+		fmt.Println(api.RepairSummary(report))
+		switch {
+		case len(report.Actions) == 0:
+			api.StatusGreenTf("%s doesn't need repairing.", app)
+		case dryRun:
+			fmt.Println(api.T("Applicable repairs, the safest first:"))
+			for _, action := range report.Actions {
+				fmt.Println("  " + action.Label())
+			}
+		case report.Outcome == "cancelled":
+			api.StatusT("Nothing was changed.")
+		case err == nil && report.Outcome == "queued":
+			api.StatusGreenTf("%s: %s was added to the queue.", app, report.Chosen.Label())
+		case err == nil:
+			api.StatusGreenTf("%s: %s, it is now %s.", app, report.Chosen.Label(), report.Outcome)
+		}
+	}
+	if err != nil && report.Outcome != "cancelled" {
+		api.ErrorExit(err)
+	}
+}
+
+// apiCleanCommand removes the junk in the data directory, or only lists it with --dry-run
+func apiCleanCommand(args []string) {
+	dryRun, jsonOutput := false, false
+	for _, arg := range args {
+		switch arg {
+		case "--dry-run", "-dry-run", "-n":
+			dryRun = true
+		case "--json", "-json":
+			jsonOutput = true
+		default:
+			api.ErrorNoExitT(api.Tf("Error: Unknown option %s", arg))
+			api.StatusT("Usage: api clean [--dry-run] [--json]")
+			os.Exit(1)
+		}
+	}
+
+	report, err := api.CleanDataDir(dryRun)
+	if jsonOutput {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			api.ErrorT(api.Tf("Error: %v", err))
+		}
+	} else {
+		for _, category := range report.Categories {
+			fmt.Printf("%s: %s\n", api.CleanupCategoryName(category.Name), api.FormatSize(uint64(max(category.Bytes, 0))))
+			for _, item := range category.Items {
+				fmt.Printf("  %s (%s)\n", item.Path, item.Reason)
+			}
+		}
+		switch {
+		case len(report.Categories) == 0:
+			api.StatusT("Nothing to clean up")
+		case dryRun:
+			api.StatusTf("Cleaning up would free %s", api.FormatSize(uint64(max(report.Bytes, 0))))
+		default:
+			api.StatusGreenTf("Cleaned up, %s freed", api.FormatSize(uint64(max(report.Bytes, 0))))
+		}
+	}
+	if err != nil {
+		api.ErrorT(api.Tf("Error: %v", err))
+	}
+}
+
+// apiHealthCheckCommand runs the healthchecks of the given apps, or of every installed app that has one, and exits 1
+// if one failed. With --mark, the apps whose check failed are marked as corrupted with the output in their log.
+func apiHealthCheckCommand(args []string) {
+	var apps []string
+	jsonOutput, mark := false, false
+	for _, arg := range args {
+		switch {
+		case arg == "--json" || arg == "-json":
+			jsonOutput = true
+		case arg == "--mark" || arg == "-mark":
+			mark = true
+		case !strings.HasPrefix(arg, "-"):
+			apps = append(apps, arg)
+		default:
+			api.ErrorNoExitTf("Error: unknown argument %s", arg)
+			api.StatusT("Usage: api healthcheck [app...] [--mark] [--json]")
+			os.Exit(1)
+		}
+	}
+
+	results, err := api.RunHealthChecks(apps)
+	if err != nil {
+		api.ErrorExit(err)
+	}
+	failed := 0
+	for _, result := range results {
+		if result.Healthy {
+			continue
+		}
+		failed++
+		if mark {
+			if err := api.MarkAppUnhealthy(result); err != nil {
+				api.WarningTf("Failed to mark %s as corrupted: %v", result.App, err)
+			}
+		}
+	}
+
+	if jsonOutput {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(results); err != nil {
+			api.ErrorT(api.Tf("Error: %v", err))
+		}
+	} else {
+		if len(results) == 0 {
+			api.StatusT("No installed app has a health check.")
+		}
+		for _, result := range results {
+			switch {
+			case result.Skipped:
+				fmt.Println(api.Tf("%s: no health check", result.App))
+			case result.Healthy:
+				api.StatusGreenTf("%s: works", result.App)
+			case mark:
+				api.ErrorNoExitTf("%s: %s (marked as corrupted)", result.App, result.Problem())
+			default:
+				api.ErrorNoExitTf("%s: %s", result.App, result.Problem())
+			}
+		}
+		if failed > 0 && !mark {
+			api.StatusT("Run api healthcheck --mark to mark them as corrupted, so they can be reinstalled.")
+		}
+	}
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// apiRepoKeysCommand lists the signing keys of the APT repositories with their expiry dates. With --renew, the keys of the given
+// repositories are downloaded again, or those of the repositories whose keys expire within api.RepoKeyWarnDays.
+func apiRepoKeysCommand(args []string) {
+	var repos []string
+	jsonOutput, renew := false, false
+	for _, arg := range args {
+		switch {
+		case arg == "--json" || arg == "-json":
+			jsonOutput = true
+		case arg == "--renew" || arg == "-renew":
+			renew = true
+		case !strings.HasPrefix(arg, "-"):
+			repos = append(repos, arg)
+		default:
+			api.ErrorNoExitTf("Error: unknown argument %s", arg)
+			api.StatusT("Usage: api repo_keys [--json] [--renew [repo...]]")
+			os.Exit(1)
+		}
+	}
+
+	keys, err := api.ListExternalRepoKeys()
+	if err != nil {
+		api.ErrorExit(err)
+	}
+	expiring := api.ExpiringRepoKeys(keys, time.Now())
+
+	if renew {
+		// Only the repositories added with a recorded key URL can be renewed
+		if len(repos) == 0 {
+			for _, key := range expiring {
+				if key.PubkeyURL != "" {
+					repos = append(repos, key.Repo)
+				}
+			}
+			if len(repos) == 0 {
+				api.StatusGreenT("No repository key can be renewed")
+				return
+			}
+		}
+		failed := false
+		for _, repo := range repos {
+			if err := api.RenewExternalRepoKey(repo); err != nil {
+				api.ErrorNoExit(err.Error())
+				failed = true
+			}
+		}
+		if failed {
+			os.Exit(1)
+		}
+		return
+	}
+
+	if jsonOutput {
+		if keys == nil {
+			keys = []api.RepoKey{}
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(keys); err != nil {
+			api.ErrorT(api.Tf("Error: %v", err))
+		}
+		return
+	}
+
+	if len(keys) == 0 {
+		api.StatusT("No repository in /etc/apt/sources.list.d has a signing key.")
+	}
+	for _, key := range keys {
+		fmt.Println(key.String())
+	}
+	for _, key := range expiring {
+		if key.PubkeyURL != "" {
+			api.WarningTf("The key of %s expires soon or expired, run api repo_keys --renew %s to download it again", key.Repo, key.Repo)
+		} else {
+			api.WarningTf("The key of %s expires soon or expired, get a new one from the website of the repository", key.Repo)
+		}
+	}
+}
+
+// apiRegenerateIconsCommand generates the icon sizes the apps are missing into the icon cache and regenerates the app list,
+// so it shows them. With --force, the icons generated before are generated again.
+func apiRegenerateIconsCommand(args []string) {
+	force := false
+	for _, arg := range args {
+		switch arg {
+		case "--force", "-force":
+			force = true
+		default:
+			api.ErrorNoExitTf("Error: unknown argument %s", arg)
+			api.StatusT("Usage: api regenerate_icons [--force]")
+			os.Exit(1)
+		}
+	}
+
+	generated, failed := 0, 0
+	_, err := api.RegenerateAllAppIcons(nil, force, func(done, total int, result api.IconResult) {
+		switch {
+		case result.Err != nil:
+			failed++
+			api.WarningTf("[%d/%d] %s: %v", done, total, result.App, result.Err)
+		case len(result.Generated) > 0:
+			generated++
+			api.StatusTf("[%d/%d] %s: generated %v", done, total, result.App, result.Generated)
+		}
+	})
+	if err != nil {
+		api.ErrorExit(err)
+	}
+	if generated > 0 {
+		if err := api.RefreshAppList(); err != nil {
+			api.WarningTf("Failed to regenerate the app list: %v", err)
+		}
+	}
+	api.StatusGreenTf("Generated the icons of %d apps", generated)
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// apiDiskUsageCommand prints the disk space used by one installed app or all of them, the largest first
+func apiDiskUsageCommand(args []string) {
+	app, jsonOutput := "", false
+	for _, arg := range args {
+		switch {
+		case arg == "--json" || arg == "-json":
+			jsonOutput = true
+		case app == "" && !strings.HasPrefix(arg, "-"):
+			app = arg
+		default:
+			api.ErrorNoExitTf("Error: unknown argument %s", arg)
+			api.StatusT("Usage: api disk_usage [app] [--json]")
+			os.Exit(1)
+		}
+	}
+
+	var usages []api.Breakdown
+	if app != "" {
+		usage, err := api.AppDiskUsage(app)
+		if err != nil {
+			api.ErrorExit(err)
+		}
+		usages = []api.Breakdown{usage}
+	} else {
+		var err error
+		if usages, err = api.AllAppsDiskUsage(); err != nil {
+			api.ErrorExit(err)
+		}
+	}
+
+	if jsonOutput {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		var value any = usages
+		if app != "" {
+			value = usages[0]
+		}
+		if err := encoder.Encode(value); err != nil {
+			api.ErrorT(api.Tf("Error: %v", err))
+		}
+		return
+	}
+
+	size := func(bytes int64) string { return api.FormatSize(uint64(max(bytes, 0))) }
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(writer, strings.Join([]string{api.T("Packages"), api.T("Files"), api.T("Logs"), api.T("Downloads"), api.T("Total"), ""}, "\t")+"\t"+api.T("App"))
+	var total int64
+	shared := false
+	for _, usage := range usages {
+		name := usage.App
+		if len(usage.Shared) > 0 {
+			name += " *"
+			shared = true
+		}
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\t\t%s\n", size(usage.Packages), size(usage.Files), size(usage.Logs), size(usage.Downloads), size(usage.Total), name)
+		total += usage.Total
+	}
+	writer.Flush()
+	if app == "" {
+		api.StatusTf("Total: %s", size(total))
+	}
+	if shared {
+		api.StatusT("* Shares packages with other apps, their size is divided between the apps using them.")
+	}
+}
+
+// apiSuggestAppCommand suggests an app for Pi-Apps, as a GitHub issue or sent to the Pi-Apps team with --send
+func apiSuggestAppCommand(args []string) {
+	var positional []string
+	send, force := false, false
+	for _, arg := range args {
+		switch arg {
+		case "--send", "-send":
+			send = true
+		case "--force", "-force":
+			force = true
+		default:
+			if strings.HasPrefix(arg, "-") {
+				api.ErrorNoExitTf("Error: unknown argument %s", arg)
+				api.StatusT("Usage: api suggest_app <name> <url> [why] [--send] [--force]")
+				os.Exit(1)
+			}
+			positional = append(positional, arg)
+		}
+	}
+	if len(positional) < 2 {
+		api.ErrorNoExitT("Error: Missing required arguments")
+		api.StatusT("Usage: api suggest_app <name> <url> [why] [--send] [--force]")
+		os.Exit(1)
+	}
+	suggestion := api.AppSuggestion{Name: positional[0], URL: positional[1], Why: strings.Join(positional[2:], " ")}
+	if err := api.ValidateAppSuggestion(suggestion); err != nil {
+		api.ErrorT(api.Tf("Error: %v", err))
+	}
+
+	if !force {
+		similar, err := api.SimilarApps(suggestion.Name)
+		if err != nil {
+			api.ErrorExit(err)
+		}
+		if len(similar) > 0 {
+			api.WarningTf("%s may already be in Pi-Apps or suggested:", suggestion.Name)
+			for _, name := range similar {
+				fmt.Println("  " + name)
+			}
+			api.StatusT("Use --force to suggest it anyway.")
+			os.Exit(1)
+		}
+	}
+
+	link, err := api.SubmitAppSuggestion(suggestion, send)
+	if err != nil {
+		api.ErrorT(api.Tf("Error: %v", err))
+	}
+	if send {
+		api.StatusGreenTf("Sent the suggestion of %s to the Pi-Apps team, thank you!", suggestion.Name)
+	} else {
+		api.StatusTf("Finish the suggestion of %s on GitHub: %s", suggestion.Name, link)
+	}
+}
@@ -18,6 +18,8 @@
 // Description: Main entry point for the Pi-Apps GUI implementation
 // SPDX-License-Identifier: GPL-3.0-or-later
 
+//go:build !nogui
+
 package main
 
 import (
@@ -25,20 +27,12 @@ import (
 	"fmt"
 	"os"
 	"runtime/debug"
-	"time"
 
-	"charm.land/log/v2"
 	"github.com/gotk3/gotk3/gtk"
 	"github.com/pi-apps-go/pi-apps/pkg/api"
 	"github.com/pi-apps-go/pi-apps/pkg/gui"
 )
 
-var logger = log.NewWithOptions(os.Stderr, log.Options{
-	ReportCaller:    true,
-	ReportTimestamp: true,
-	TimeFormat:      time.Kitchen,
-})
-
 func runGUI() {
 	// Set environment variable to indicate we're using multi-call binary
 	// This will be used by the GUI to determine which binary to call for terminal_manage
@@ -81,11 +75,12 @@ func runGUI() {
 	}
 
 	var (
-		directory      = flag.String("directory", "", "Pi-Apps directory (defaults to PI_APPS_DIR env var)")
+		directory      = flag.String("directory", "", "Pi-Apps directory (defaults to PI_APPS_DIR, DIRECTORY, the program's directory, then ~/pi-apps)")
 		mode           = flag.String("mode", "", "GUI mode: gtk, xlunch-dark, etc.")
 		help           = flag.Bool("help", false, "Show help message")
 		version        = flag.Bool("version", false, "Show version information")
 		showAppDetails = flag.Bool("show-app-details", false, "Show app details dialog (internal use)")
+		remote         = flag.String("remote", "", "Manage the Pi-Apps of another machine over SSH: user@host[:path]")
 	)
 	api.Init()
 	flag.Parse()
@@ -136,6 +131,11 @@ func runGUI() {
 		fmt.Println("Environment Variables:")
 		fmt.Println("  PI_APPS_DIR  Path to Pi-Apps directory")
 		fmt.Println()
+		fmt.Println("Remote mode:")
+		fmt.Println("  --remote user@host[:path] shows the apps of the Pi-Apps in path (default ~/pi-apps) on another")
+		fmt.Println("  machine and installs them there. It connects with ssh and runs api-go serve on that machine,")
+		fmt.Println("  which does not need a desktop: a nogui build of Pi-Apps is enough.")
+		fmt.Println()
 		fmt.Println("GUI Modes:")
 		fmt.Println("  default      Auto-detect best interface (GTK3 if available, fallback to Gio or TUI mode)")
 		fmt.Println("  gtk          Native GTK3 interface")
@@ -167,13 +167,12 @@ func runGUI() {
 		return
 	}
 
-	// Set default directory if not provided
-	if *directory == "" {
-		*directory = api.GetPiAppsDir()
-		if *directory == "" {
-			logger.Fatal("PI_APPS_DIR environment variable not set and no directory specified")
-		}
+	// An explicit directory takes precedence for the whole process, so the GUI and the api calls it makes
+	// use the same Pi-Apps directory
+	if *directory != "" {
+		api.SetPiAppsDir(*directory)
 	}
+	*directory = api.GetPiAppsDir()
 
 	// Set default mode
 	if *mode == "" {
@@ -190,6 +189,27 @@ func runGUI() {
 		GuiMode:   *mode,
 	}
 
+	// In remote mode the GUI runs on a local mirror of the catalog of the remote machine
+	if *remote != "" {
+		target, err := api.ParseRemoteTarget(*remote)
+		if err != nil {
+			logger.Fatal(err)
+		}
+		logger.Info(fmt.Sprintf("Connecting to %s...", target))
+		session, err := api.ConnectRemote(target, *directory)
+		if err != nil {
+			logger.Fatal(err)
+		}
+		defer session.Close()
+		mirrorDir, err := session.Mirror()
+		if err != nil {
+			session.Close()
+			logger.Fatal(err)
+		}
+		config.Directory = mirrorDir
+		config.Remote = session
+	}
+
 	// Create and initialize GUI
 	app, err := gui.NewGUI(config)
 	if err != nil {
@@ -0,0 +1,122 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: links.go
+// Description: The install-links and verify-links modes, which manage the symlinks the binary runs as the Pi-Apps programs through.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/pi-apps-go/pi-apps/pkg/api"
+)
+
+// Exit codes of verify-links, for packaging scripts
+const (
+	linksOK      = 0
+	linksWrong   = 1 // a link is missing or wrong, install-links repairs it
+	linksUnknown = 2 // the links can't be checked, like without a multi-call binary in the folder
+)
+
+// resolvedExecutable returns the real path of the running binary, with the symlink it was started through resolved
+func resolvedExecutable() (string, error) {
+	executable, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	return filepath.EvalSymlinks(executable)
+}
+
+// linksDir returns the folder the links are in: the argument, or the folder of the running binary
+func linksDir(args []string) (string, error) {
+	if len(args) > 1 {
+		return "", fmt.Errorf("too many arguments, expected at most a folder")
+	}
+	if len(args) == 1 {
+		return filepath.Abs(args[0])
+	}
+	executable, err := resolvedExecutable()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Dir(executable), nil
+}
+
+// installLinksCommand creates or repairs the symlinks and the menu entries starting them, returning the exit code
+func installLinksCommand(args []string) int {
+	dir, err := linksDir(args)
+	if err != nil {
+		api.ErrorNoExit(fmt.Sprintf("Error: %v", err))
+		api.Status("Usage: multi-call-pi-apps install-links [dir]")
+		return linksUnknown
+	}
+
+	installed, err := api.InstallMultiCallLinks(dir)
+	for _, path := range installed {
+		api.Status(fmt.Sprintf("Linked %s to %s", path, api.MultiCallBinary))
+	}
+	if err != nil {
+		api.ErrorNoExit(fmt.Sprintf("Error: %v", err))
+		return linksUnknown
+	}
+
+	// The menu entries belong to the user, packaging scripts running as root leave them alone
+	if os.Geteuid() != 0 {
+		updated, err := api.UpdateMultiCallDesktopEntries(dir)
+		for _, path := range updated {
+			api.Status("Updated the Exec line of " + path)
+		}
+		if err != nil {
+			api.ErrorNoExit(fmt.Sprintf("Error: %v", err))
+			return linksUnknown
+		}
+		installed = append(installed, updated...)
+	}
+
+	if len(installed) == 0 {
+		api.StatusGreen("The links in " + dir + " are up to date")
+	}
+	return linksOK
+}
+
+// verifyLinksCommand reports the missing and wrong symlinks, returning the exit code
+func verifyLinksCommand(args []string) int {
+	dir, err := linksDir(args)
+	if err != nil {
+		api.ErrorNoExit(fmt.Sprintf("Error: %v", err))
+		api.Status("Usage: multi-call-pi-apps verify-links [dir]")
+		return linksUnknown
+	}
+
+	issues, err := api.VerifyMultiCallLinks(dir)
+	if err != nil {
+		api.ErrorNoExit(fmt.Sprintf("Error: %v", err))
+		return linksUnknown
+	}
+	for _, issue := range issues {
+		fmt.Println(issue)
+	}
+	if len(issues) > 0 {
+		api.ErrorNoExit(fmt.Sprintf("%d of the links to %s are missing or wrong, run multi-call-pi-apps install-links %s to repair them", len(issues), api.MultiCallBinary, dir))
+		return linksWrong
+	}
+	api.StatusGreen("All links in " + dir + " point to " + api.MultiCallBinary)
+	return linksOK
+}
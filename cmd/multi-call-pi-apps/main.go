@@ -25,8 +25,11 @@ import (
 	"os"
 	"path/filepath"
 	"runtime/debug"
+	"slices"
 	"strings"
+	"time"
 
+	"charm.land/log/v2"
 	"github.com/pi-apps-go/pi-apps/pkg/api"
 )
 
@@ -36,6 +39,12 @@ var (
 	GitCommit string
 )
 
+var logger = log.NewWithOptions(os.Stderr, log.Options{
+	ReportCaller:    true,
+	ReportTimestamp: true,
+	TimeFormat:      time.Kitchen,
+})
+
 func main() {
 	// runtime crashes can happen (keep in mind Pi-Apps Go is ALPHA software)
 	// so add a handler to log those runtime errors to save them to a log file
@@ -69,9 +78,23 @@ func main() {
 
 	// Determine which binary we're emulating based on argv[0]
 	programName := filepath.Base(os.Args[0])
+	mode := strings.ToLower(programName)
+
+	// Packaging scripts manage the links as root, so these modes run before the root guard
+	if !slices.Contains(programNames, mode) && len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "install-links":
+			os.Exit(installLinksCommand(os.Args[2:]))
+		case "verify-links":
+			os.Exit(verifyLinksCommand(os.Args[2:]))
+		}
+	}
+
+	// Refuse to run as root before anything is written to the user's home
+	api.GuardRoot()
 
 	// Handle symlinks and different calling conventions
-	switch strings.ToLower(programName) {
+	switch mode {
 	case "api", "api-go", "pi-apps-api":
 		runAPI()
 	case "gui", "pi-apps-gui":
@@ -82,59 +105,83 @@ func main() {
 		runSettings()
 	case "updater", "pi-apps-updater":
 		runUpdater()
-	case "multi-call-pi-apps":
-		// If called directly, check first argument for mode
-		if len(os.Args) > 1 {
-			mode := strings.ToLower(os.Args[1])
-			// Set up os.Args properly for each sub-function
-			// Each sub-function expects os.Args[0] to be the binary name
-			originalArgs := os.Args
-			switch mode {
-			case "api":
-				os.Args = append([]string{"api"}, originalArgs[2:]...)
-				runAPI()
-			case "gui":
-				os.Args = append([]string{"gui"}, originalArgs[2:]...)
-				runGUI()
-			case "manage":
-				os.Args = append([]string{"manage"}, originalArgs[2:]...)
-				runManage()
-			case "daemon-terminal":
-				// Special case for daemon-terminal mode - pass all args to manage
-				os.Args = append([]string{"manage"}, originalArgs[1:]...)
-				runManage()
-			case "settings":
-				os.Args = append([]string{"settings"}, originalArgs[2:]...)
-				runSettings()
-			case "updater":
-				os.Args = append([]string{"updater"}, originalArgs[2:]...)
-				runUpdater()
-			default:
-				printUsage()
-				os.Exit(1)
-			}
-		} else {
+	default:
+		// Called directly, or through a name that is no program like a renamed copy: the first argument is the mode
+		if len(os.Args) < 2 || !runMode(strings.ToLower(os.Args[1])) {
 			printUsage()
 			os.Exit(1)
 		}
+	}
+}
+
+// programNames are the names the binary runs one of the programs as, through a symlink or a copy
+var programNames = []string{"api", "api-go", "pi-apps-api", "gui", "pi-apps-gui", "manage", "pi-apps-manage", "settings", "pi-apps-settings", "updater", "pi-apps-updater"}
+
+// runMode runs the program of a mode given as the first argument, returning false for an unknown mode
+func runMode(mode string) bool {
+	// Set up os.Args properly for each sub-function
+	// Each sub-function expects os.Args[0] to be the binary name
+	originalArgs := os.Args
+	switch mode {
+	case "api":
+		os.Args = append([]string{"api"}, originalArgs[2:]...)
+		runAPI()
+	case "gui":
+		os.Args = append([]string{"gui"}, originalArgs[2:]...)
+		runGUI()
+	case "manage":
+		os.Args = append([]string{"manage"}, originalArgs[2:]...)
+		runManage()
+	case "daemon-terminal":
+		// Special case for daemon-terminal mode - pass all args to manage
+		os.Args = append([]string{"manage"}, originalArgs[1:]...)
+		runManage()
+	case "settings":
+		os.Args = append([]string{"settings"}, originalArgs[2:]...)
+		runSettings()
+	case "updater":
+		os.Args = append([]string{"updater"}, originalArgs[2:]...)
+		runUpdater()
 	default:
-		printUsage()
-		os.Exit(1)
+		return false
 	}
+	return true
 }
 
 func printUsage() {
-	// for debugging add the passed in args
-	api.Status("Passed in args: " + strings.Join(os.Args, " "))
+	// Name the executable, a link to the binary of another install shows up here
+	executable, err := resolvedExecutable()
+	if err != nil {
+		executable = os.Args[0]
+	}
+	programName := filepath.Base(os.Args[0])
+	switch {
+	case len(os.Args) > 1:
+		api.ErrorNoExit(fmt.Sprintf("Unknown mode %q of %s, started as %s", os.Args[1], executable, programName))
+	case programName != api.MultiCallBinary:
+		api.ErrorNoExit(fmt.Sprintf("%s was started as %s, which is not a Pi-Apps program", executable, programName))
+	}
 	api.Status("Pi-Apps Multi-Call Binary")
 	api.Status("Usage:")
 	api.Status("  multi-call-pi-apps <mode> [args...]")
-	api.Status("  Or create symlinks: api, gui, manage, settings, updater")
+	api.Status("  Or create symlinks: api-go, gui, manage, settings, updater")
+	api.Status("  multi-call-pi-apps install-links [dir]  - create or repair the symlinks next to the binary or in dir")
+	api.Status("  multi-call-pi-apps verify-links [dir]   - check the symlinks, exits 1 if one is missing or wrong")
 	api.Status("")
 	api.Status("Available modes:")
 	api.Status("  api      - Pi-Apps API interface")
-	api.Status("  gui      - Pi-Apps GUI")
+	if api.GUISupported {
+		api.Status("  gui      - Pi-Apps GUI")
+	}
 	api.Status("  manage   - Pi-Apps management tool")
-	api.Status("  settings - Pi-Apps settings")
+	if api.GUISupported {
+		api.Status("  settings - Pi-Apps settings")
+	} else {
+		api.Status("  settings - Pi-Apps settings (terminal interface)")
+	}
 	api.Status("  updater  - Pi-Apps updater")
+	if !api.GUISupported {
+		api.Status("")
+		api.Status("This binary was built without GUI support (nogui): GUI commands use the terminal or are unavailable.")
+	}
 }
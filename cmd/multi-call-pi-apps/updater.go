@@ -74,12 +74,6 @@ func runUpdater() {
 		}()
 	}
 
-	// Check if running as root
-	if os.Getuid() == 0 {
-		fmt.Fprintf(os.Stderr, "Pi-Apps is not designed to be run as root! Please try again as a regular user.\n")
-		os.Exit(1)
-	}
-
 	// Set environment variable to indicate we're running from multi-call binary
 	if executable, err := os.Executable(); err == nil {
 		os.Setenv("PI_APPS_MULTI_CALL_BINARY", executable)
@@ -123,6 +117,8 @@ func runUpdater() {
 		execErr = handleGUIMode(updater, mode, extraArgs)
 	case updaterPkg.ModeCLI, updaterPkg.ModeCLIYes:
 		execErr = handleCLIMode(updater, mode, useTerminal, extraArgs)
+	case updaterPkg.ModePlan:
+		execErr = handlePlanMode(updater, extraArgs)
 	default:
 		// Fallback to the new ExecuteMode for any unhandled modes
 		execErr = updater.ExecuteMode(ctx)
@@ -160,6 +156,9 @@ func handleAutostartedMode(u *updaterPkg.Updater) error {
 		return nil
 	}
 
+	// Apps can stop working without an update, like when an OS upgrade removed a library they need
+	u.RecheckAppHealth()
+
 	// Wait for internet connection
 	if err := waitForInternet(); err != nil {
 		fmt.Printf("No internet connection available: %v\n", err)
@@ -276,9 +275,21 @@ func handleCLIMode(u *updaterPkg.Updater, mode updaterPkg.UpdateMode, useTermina
 		fmt.Printf("Extra arguments: %v\n", extraArgs)
 	}
 
+	planFile, allowDrift, err := parsePlanArgs(extraArgs)
+	if err != nil {
+		return err
+	}
+	if planFile != "" && mode != updaterPkg.ModeCLIYes {
+		return fmt.Errorf("--from-plan is only supported by cli-yes")
+	}
+
 	// Create CLI instance and run
 	cli := updaterPkg.NewUpdaterCLI(u)
-	err := cli.RunCLI()
+	if planFile != "" {
+		err = cli.RunFromPlan(planFile, allowDrift)
+	} else {
+		err = cli.RunCLI()
+	}
 
 	// After CLI update, refresh status if successful
 	if err == nil && (mode == updaterPkg.ModeCLI || mode == updaterPkg.ModeCLIYes) {
@@ -291,6 +302,43 @@ func handleCLIMode(u *updaterPkg.Updater, mode updaterPkg.UpdateMode, useTermina
 	return err
 }
 
+// handlePlanMode prints what cli-yes would do without changing anything
+func handlePlanMode(u *updaterPkg.Updater, extraArgs []string) error {
+	jsonOutput := false
+	for _, arg := range extraArgs {
+		switch arg {
+		case "--json", "-json":
+			jsonOutput = true
+		default:
+			return fmt.Errorf("unknown argument for plan: %s", arg)
+		}
+	}
+	cli := updaterPkg.NewUpdaterCLI(u)
+	return cli.RunPlan(jsonOutput)
+}
+
+// parsePlanArgs returns the plan file of --from-plan and whether --allow-drift was passed
+func parsePlanArgs(args []string) (planFile string, allowDrift bool, err error) {
+	for i := 0; i < len(args); i++ {
+		switch arg := args[i]; {
+		case arg == "--allow-drift":
+			allowDrift = true
+		case arg == "--from-plan":
+			if i+1 >= len(args) {
+				return "", false, fmt.Errorf("--from-plan requires a plan file")
+			}
+			i++
+			planFile = args[i]
+		case strings.HasPrefix(arg, "--from-plan="):
+			planFile = strings.TrimPrefix(arg, "--from-plan=")
+		}
+	}
+	if allowDrift && planFile == "" {
+		return "", false, fmt.Errorf("--allow-drift requires --from-plan")
+	}
+	return planFile, allowDrift, nil
+}
+
 // Helper functions
 
 func showUsage() {
@@ -306,57 +354,35 @@ func showUsage() {
 	fmt.Println("  gui-yes      - Show GUI and auto-confirm updates")
 	fmt.Println("  cli          - Interactive command-line interface")
 	fmt.Println("  cli-yes      - Automatic command-line update")
+	fmt.Println("  plan         - Show what cli-yes would update without changing anything")
 	fmt.Println()
 	fmt.Println("Speed:")
 	fmt.Println("  fast         - Use cached results (faster, may be outdated)")
 	fmt.Println("  (default)    - Check repository for latest updates")
 	fmt.Println()
+	fmt.Println("Options:")
+	fmt.Println("  --json                   - plan: print the plan as JSON")
+	fmt.Println("  --from-plan <file>       - cli-yes: apply exactly a plan saved with 'plan --json'")
+	fmt.Println("  --allow-drift            - cli-yes: apply the plan even if the repository moved since it was made")
+	fmt.Println()
 	fmt.Println("Examples:")
 	fmt.Println("  updater gui")
 	fmt.Println("  updater cli fast")
 	fmt.Println("  updater get-status")
+	fmt.Println("  updater plan --json > plan.json && updater cli-yes --from-plan plan.json")
 }
 
+// getPiAppsDirectory returns the Pi-Apps directory every other Pi-Apps program uses, see api.GetPiAppsDir
 func getPiAppsDirectory() (string, error) {
-	// Check DIRECTORY environment variable first
-	if dir := os.Getenv("DIRECTORY"); dir != "" {
-		return dir, nil
+	directory := api.GetPiAppsDir()
+	if err := api.ValidatePiAppsDir(directory); err != nil {
+		return "", fmt.Errorf("invalid pi-apps directory %s: %w", directory, err)
 	}
-
-	// Get directory from executable path
-	executable, err := os.Executable()
-	if err != nil {
-		return "", err
-	}
-
-	directory := filepath.Dir(executable)
-
-	// Validate directory
-	if err := validatePiAppsDirectory(directory); err != nil {
-		// Try parent directory
-		directory = filepath.Dir(directory)
-		if err := validatePiAppsDirectory(directory); err != nil {
-			return "", fmt.Errorf("invalid pi-apps directory: %w", err)
-		}
-	}
-
 	return directory, nil
 }
 
-func validatePiAppsDirectory(directory string) error {
-	// Check for required files/directories
-	required := []string{"apps", "data", "etc"}
-	for _, item := range required {
-		path := filepath.Join(directory, item)
-		if _, err := os.Stat(path); os.IsNotExist(err) {
-			return fmt.Errorf("missing %s directory", item)
-		}
-	}
-	return nil
-}
-
 func hasInstalledApps(directory string) bool {
-	statusDir := filepath.Join(directory, "data", "status")
+	statusDir := filepath.Join(api.DataDirOf(directory), "status")
 	if entries, err := os.ReadDir(statusDir); err == nil {
 		return len(entries) > 0
 	}
@@ -365,7 +391,7 @@ func hasInstalledApps(directory string) bool {
 
 func getInstalledApps(directory string) []string {
 	var installed []string
-	statusDir := filepath.Join(directory, "data", "status")
+	statusDir := filepath.Join(api.DataDirOf(directory), "status")
 
 	if entries, err := os.ReadDir(statusDir); err == nil {
 		for _, entry := range entries {
@@ -462,7 +488,7 @@ func performBackgroundUpdates(u *updaterPkg.Updater, files []updaterPkg.FileChan
 }
 
 func saveUpdateStatus(directory string, files []updaterPkg.FileChange, apps []string) error {
-	statusDir := filepath.Join(directory, "data", "update-status")
+	statusDir := filepath.Join(api.DataDirOf(directory), "update-status")
 	if err := os.MkdirAll(statusDir, 0755); err != nil {
 		return err
 	}
@@ -641,6 +667,7 @@ func parseArgs() (updaterPkg.UpdateMode, updaterPkg.UpdateSpeed, bool, []string,
 		updaterPkg.ModeGUIYes:      true,
 		updaterPkg.ModeCLI:         true,
 		updaterPkg.ModeCLIYes:      true,
+		updaterPkg.ModePlan:        true,
 	}
 
 	if !validModes[mode] {
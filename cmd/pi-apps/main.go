@@ -31,6 +31,9 @@ import (
 )
 
 func main() {
+	// Refuse to run as root before anything is written to the user's home
+	api.GuardRoot()
+
 	// Parse command line flags
 	debugFlag := flag.Bool("debug", false, "Enable debug mode")
 	helpFlag := flag.Bool("help", false, "Show help message")
@@ -225,40 +228,25 @@ func main() {
 
 		api.Init()
 		appName := args[0]
-		piAppsDir := api.GetPiAppsDir()
 
-		// Check if app exists
-		appDir := piAppsDir + "/apps/" + appName
-		if _, err := os.Stat(appDir); os.IsNotExist(err) {
+		metadata, err := api.GetAppMetadata(appName)
+		if err != nil {
 			fmt.Printf("Error: App '%s' not found\n", appName)
 			os.Exit(1)
 		}
 
-		// Get app status
-		status, err := api.GetAppStatus(appName)
-		if err != nil {
-			status = "unknown"
+		description := metadata.LongDescription
+		if description == "" {
+			description = "Description unavailable"
 		}
-
-		// Get app description
-		descFile := piAppsDir + "/apps/" + appName + "/description"
-		description := "Description unavailable"
-		if content, err := os.ReadFile(descFile); err == nil {
-			description = string(content)
-		}
-
-		// Get app website
-		websiteFile := piAppsDir + "/apps/" + appName + "/website"
-		website := "Not specified"
-		if content, err := os.ReadFile(websiteFile); err == nil {
-			if w := strings.TrimSpace(string(content)); w != "" {
-				website = w
-			}
+		website := metadata.Website
+		if website == "" {
+			website = "Not specified"
 		}
 
 		// Display app details
 		fmt.Printf("App: %s\n", appName)
-		fmt.Printf("Status: %s\n", status)
+		fmt.Printf("Status: %s\n", metadata.Status)
 		fmt.Printf("Website: %s\n", website)
 		fmt.Printf("\nDescription:\n%s\n", description)
 		return
@@ -379,20 +367,13 @@ func main() {
 
 		api.Init()
 		appName := args[0]
-		piAppsDir := api.GetPiAppsDir()
-		descFile := piAppsDir + "/apps/" + appName + "/description"
 
-		if _, err := os.Stat(descFile); os.IsNotExist(err) {
+		metadata, err := api.GetAppMetadata(appName)
+		if err != nil || metadata.LongDescription == "" {
 			fmt.Printf("Error: App '%s' not found or has no description\n", appName)
 			os.Exit(1)
 		}
-
-		content, err := os.ReadFile(descFile)
-		if err != nil {
-			fmt.Printf("Error reading description: %v\n", err)
-			os.Exit(1)
-		}
-		fmt.Printf("Description for %s:\n%s\n", appName, string(content))
+		fmt.Printf("Description for %s:\n%s\n", appName, metadata.LongDescription)
 		return
 
 	case "website":
@@ -405,20 +386,13 @@ func main() {
 
 		api.Init()
 		appName := args[0]
-		piAppsDir := api.GetPiAppsDir()
-		websiteFile := piAppsDir + "/apps/" + appName + "/website"
 
-		if _, err := os.Stat(websiteFile); os.IsNotExist(err) {
-			fmt.Printf("Error: App '%s' not found or has no website specified\n", appName)
-			os.Exit(1)
-		}
-
-		content, err := os.ReadFile(websiteFile)
+		metadata, err := api.GetAppMetadata(appName)
 		if err != nil {
-			fmt.Printf("Error reading website: %v\n", err)
+			fmt.Printf("Error: App '%s' not found or has no website specified\n", appName)
 			os.Exit(1)
 		}
-		website := strings.TrimSpace(string(content))
+		website := metadata.Website
 		if website == "" {
 			fmt.Printf("No website specified for %s\n", appName)
 		} else {
@@ -26,6 +26,7 @@ import (
 	"os"
 	"runtime"
 
+	"github.com/pi-apps-go/pi-apps/pkg/api"
 	"github.com/pi-apps-go/pi-apps/pkg/settings"
 )
 
@@ -62,6 +63,10 @@ func main() {
 			}
 		}()
 	}
+
+	// Refuse to run as root before anything is written to the user's home
+	api.GuardRoot()
+
 	if err := settings.Main(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
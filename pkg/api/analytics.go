@@ -27,7 +27,6 @@ import (
 	"net/http"
 	"os"
 	"os/exec"
-	"path/filepath"
 	"regexp"
 	"strings"
 	"time"
@@ -45,9 +44,7 @@ func ShlinkLink(app, trigger string) error {
 			return
 		}
 
-		settingsPath := filepath.Join(directory, "data", "settings", "Enable analytics")
-		settingsData, err := os.ReadFile(settingsPath)
-		if err == nil && strings.TrimSpace(string(settingsData)) == "No" {
+		if ReadSettingIn(directory, "Enable analytics") == "No" {
 			// Analytics are disabled
 			return
 		}
@@ -22,16 +22,19 @@
 package api
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
 
+	"github.com/pi-apps-go/pi-apps/pkg/api/errs"
 	"github.com/schollz/progressbar/v3"
 )
 
@@ -41,21 +44,22 @@ var piAppsDebug = false
 // Error displays an error message in red and exits the program
 func Error(msg string) {
 	// Use the exact same ANSI sequence as the original bash script
-	fmt.Fprintln(os.Stderr, "\033[91m"+msg+"\033[0m")
+	colorPrintln(os.Stderr, "\033[91m", statusPrefix(SymbolFailure)+msg)
+	FlushDownloadLedger()
 	os.Exit(1)
 }
 
 // ErrorNoExit displays an error message in red but does not exit the program
 func ErrorNoExit(msg string) {
 	// Use the exact same ANSI sequence as the original bash script
-	fmt.Fprintln(os.Stderr, "\033[91m"+msg+"\033[0m")
+	colorPrintln(os.Stderr, "\033[91m", statusPrefix(SymbolFailure)+msg)
 }
 
 // Warning displays a warning message in yellow with a flashing icon
 func Warning(msg string) {
 	// Use the exact same ANSI sequence as the original bash script
-	// \e[93m = yellow, \e[5m = blink, \e[25m = no blink
-	fmt.Fprintln(os.Stderr, "\033[93m\033[5m◢◣\033[25m WARNING: "+msg+"\033[0m")
+	// \e[93m = yellow, \e[5m = blink, \e[25m = no blink, the blinking icon is replaced by ⚠ when status symbols are on
+	colorPrintln(os.Stderr, "\033[93m", warningIcon()+" WARNING: "+msg)
 }
 
 // Status displays a status message in cyan
@@ -63,17 +67,17 @@ func Status(msg string, args ...string) {
 	// Use the exact same ANSI sequence as the original bash script
 	if len(args) > 0 && strings.HasPrefix(msg, "-") {
 		// Handle flags passed to echo
-		fmt.Fprintln(os.Stderr, msg, "\033[96m"+args[0]+"\033[0m")
+		colorPrintf(os.Stderr, "%s \033[96m%s\033[0m\n", msg, args[0])
 	} else {
 		// Regular status message
-		fmt.Fprintln(os.Stderr, "\033[96m"+msg+"\033[0m")
+		colorPrintln(os.Stderr, "\033[96m", msg)
 	}
 }
 
 // StatusGreen announces the success of a major action in green
 func StatusGreen(msg string) {
 	// Use the exact same ANSI sequence as the original bash script
-	fmt.Fprintln(os.Stderr, "\033[92m"+msg+"\033[0m")
+	colorPrintln(os.Stderr, "\033[92m", statusPrefix(SymbolSuccess)+msg)
 }
 
 // Debug outputs debug information when debug mode is enabled
@@ -94,152 +98,6 @@ func SetDebugMode(enabled bool) {
 	piAppsDebug = enabled
 }
 
-// GenerateLogo displays colorized Pi-Apps logo in terminal
-//
-// To use this function, you must call it like this:
-//
-//	fmt.Println(api.GenerateLogo())
-func GenerateLogo() string {
-	// Check if old logo should be forced
-	forceOldLogo := os.Getenv("PI-APPS_FORCE_OLD_LOGO") == "true"
-
-	// Exact ANSI color codes from the original bash script
-	// Foreground colors
-	blue1 := "\033[38;5;75m"
-	blue2 := "\033[38;5;26m"
-	blue3 := "\033[38;5;21m"
-	blue4 := "\033[38;5;93m"
-
-	green := "\033[38;5;46m"
-	darkgreen := "\033[38;5;34m"
-	red := "\033[38;5;197m"
-	white := "\033[97m"
-	black := "\033[30m"
-	default_ := "\033[39m"
-
-	// Background colors
-	bg_default := "\033[49m"
-	bg_black := "\033[40m"
-
-	var logoStr string
-
-	if forceOldLogo {
-		// Simple logo if environment variable is set
-		logoStr = white + bg_default + "    " + green + "▅" + darkgreen + "▅▅▅" + green + "▅" + default_ + "                                          " + darkgreen + "                " + default_ + "\n" +
-			" " + blue1 + "▂▂▂" + green + "\033[48;5;26m\033[7m▂\033[27m" + bg_default + blue2 + "▂▂▂" + blue3 + green + "\033[48;5;26m\033[7m▂\033[27m" + bg_default + blue3 + "▂▂▂" + white + default_ + "                                       " + darkgreen + "                " + default_ + "\n" +
-			" " + bg_black + blue1 + "▌  " + red + "▄ ▄ ▄" + blue3 + "  ▐" + bg_default + default_ + "   █▀▀◣ ▄    ◢▀▀◣                      " + darkgreen + "  " + black + "    " + darkgreen + "    " + black + "    " + darkgreen + "  " + default_ + "\n" +
-			" " + bg_black + blue2 + "▌  " + red + "▄ ▄ ▄" + blue3 + "  ▐" + bg_default + default_ + "   █▄▄◤ ▄ " + blue3 + "▄▄" + default_ + " █▄▄█ █▀▀◣ █▀▀◣ ◢\033[7m━━━\033[27m       " + darkgreen + "  " + black + "    " + darkgreen + "    " + black + "    " + darkgreen + "  " + default_ + "\n" +
-			" " + bg_black + blue2 + "▌  " + red + "▄ ▄ ▄" + blue4 + "  ▐" + bg_default + default_ + "   █    █    █  █ █▄▄◤ █▄▄◤ ▄▄▄◤       " + darkgreen + "      " + black + "    " + darkgreen + "      " + default_ + "\n" +
-			" " + blue3 + "◥" + bg_black + "▃▃▃▃" + blue4 + "▃▃▃▃▃" + bg_default + "◤" + default_ + "                  █    █               " + darkgreen + "    " + black + "        " + darkgreen + "    " + default_ + "\n" +
-			"\033[0m                                                   " + darkgreen + "    " + black + "        " + darkgreen + "    " + default_ + "\n" +
-			"                                                   " + darkgreen + "    " + black + "  " + darkgreen + "    " + black + "  " + darkgreen + "    " + default_
-
-	} else {
-		// Complex logo requires Unicode 13 support (libicu66+)
-		// This does not matter as Pi-Apps Go only supports systems with atleast libicu66+
-		bg_black = "\033[48;2;10;10;10m"
-
-		logoStr = bg_default + "    \033[38;2;5;220;75m🭊\033[38;2;4;150;29m🬹🬹🬹\033[38;2;6;188;64m🬿" + default_ + "                                          " + darkgreen + "                " + default_ + "\n" +
-			" \033[38;2;83;213;255m🭈🬭\033[38;2;83;214;255m🬭\033[38;2;5;220;75m\033[48;2;83;212;255m🬎" + bg_default + "\033[38;2;84;201;251m🬭\033[38;2;84;190;248m🬭\033[38;2;85;178;244m🬭\033[38;2;6;188;64m\033[48;2;86;168;241m🬎" + bg_default + "\033[38;2;87;154;237m🬭🬭\033[38;2;87;136;231m🬽" + default_ + "                                       " + darkgreen + "                " + default_ + "\n" +
-			" \033[38;2;83;213;255m" + bg_black + "▋  \033[38;2;255;38;101m▄ \033[38;2;255;28;92m▄ \033[38;2;255;13;83m▄\033[38;2;89;114;225m  🮉" + bg_default + default_ + "   █▀▀🭍 ▄    🭋🭡🭖🭀                      " + darkgreen + "  " + black + "    " + darkgreen + "    " + black + "    " + darkgreen + "  " + default_ + "\n" +
-			" \033[38;2;85;191;249m" + bg_black + "▋  \033[38;2;255;13;85m▄ \033[38;2;255;0;75m▄ \033[38;2;246;0;73m▄\033[38;2;90;83;215m  🮉" + bg_default + default_ + "   █▄▄🭞 ▄ " + blue3 + "▄▄" + default_ + " 🭅▙▟🭐 █▀▀🭍 █▀▀🭍 🭂🬰🬰🬰       " + darkgreen + "  " + black + "    " + darkgreen + "    " + black + "    " + darkgreen + "  " + default_ + "\n" +
-			" \033[38;2;86;164;240m" + bg_black + "▋  \033[38;2;249;0;73m▄ \033[38;2;239;0;69m▄ \033[38;2;229;0;66m▄\033[38;2;92;58;207m  🮉" + bg_default + default_ + "   █    █   🭋🭡  🭖🭀█▄▄🭞 █▄▄🭞 ▄▄▄🭞       " + darkgreen + "      " + black + "    " + darkgreen + "      " + default_ + "\n" +
-			" \033[38;2;87;137;232m🭕" + bg_black + "🭏\033[38;2;89;111;224m🬭\033[38;2;89;100;220m🬭\033[38;2;90;89;217m🬭\033[38;2;91;76;213m🬭\033[38;2;92;68;211m🬭\033[38;2;92;59;208m🬭\033[38;2;92;56;207m🬭🭄" + bg_default + "🭠" + default_ + "                  █    █               " + darkgreen + "    " + black + "        " + darkgreen + "    " + default_ + "\n" +
-			"\033[0m                                                   " + darkgreen + "    " + black + "        " + darkgreen + "    " + default_ + "\n" +
-			"                                                   " + darkgreen + "    " + black + "  " + darkgreen + "    " + black + "  " + darkgreen + "    " + default_
-	}
-
-	return logoStr + "\n"
-}
-
-// AddEnglish adds en_US locale or fixes the locale to prevent application crashes
-func AddEnglish() {
-	// Check if en_US.UTF-8 is supported
-	supported, err := os.ReadFile("/usr/share/i18n/SUPPORTED")
-	if err != nil {
-		WarningT("Could not read /usr/share/i18n/SUPPORTED")
-		return
-	}
-
-	if !strings.Contains(string(supported), "en_US.UTF-8") {
-		WarningT("en_US locale is not available on your system. This may cause bad logging experience.")
-		return
-	}
-
-	// Check if en_US.utf8 locale is generated
-	cmd := exec.Command("locale", "-a")
-	output, err := cmd.Output()
-	if err != nil {
-		WarningT("Could not check available locales")
-		return
-	}
-
-	if !strings.Contains(string(output), "en_US.utf8") {
-		StatusT("Adding en_US locale for better logging...")
-
-		// Uncomment en_US.UTF-8 in /etc/locale.gen
-		sedCmd := exec.Command("sudo", "sed", "-i", "/en_US.UTF-8/s/^#[ ]//g", "/etc/locale.gen")
-		if err := sedCmd.Run(); err != nil {
-			WarningT("Failed to edit /etc/locale.gen: %s", err.Error())
-			return
-		}
-
-		// Generate the locale
-		genCmd := exec.Command("sudo", "locale-gen")
-		if err := genCmd.Run(); err != nil {
-			WarningT("Failed to generate locale: %s", err.Error())
-			return
-		}
-	}
-
-	// Check if user's locale is problematic (C, POSIX, or non-UTF-8)
-	// and fix it to prevent application crashes
-	lang := os.Getenv("LANG")
-	lcAll := os.Getenv("LC_ALL")
-
-	// Check if the current locale is problematic
-	needsFixing := false
-	fixedLang := lang
-	fixedLcAll := lcAll
-
-	// If the user's locale has the ISO-8859-1 encoding associated with it, keep the language by converting it to UTF-8 version
-	if strings.Contains(lang, "ISO-8859-1") {
-		fixedLang = strings.Replace(lang, "ISO-8859-1", "UTF-8", 1)
-		StatusT("Converting locale from ISO-8859-1 to UTF-8: %s", fixedLang)
-		os.Setenv("LANG", fixedLang)
-		needsFixing = false
-	}
-
-	if strings.Contains(lcAll, "ISO-8859-1") {
-		fixedLcAll = strings.Replace(lcAll, "ISO-8859-1", "UTF-8", 1)
-		StatusT("Converting LC_ALL from ISO-8859-1 to UTF-8: %s", fixedLcAll)
-		os.Setenv("LC_ALL", fixedLcAll)
-		needsFixing = false
-	}
-
-	// If LC_ALL is set and problematic, it overrides everything else
-	if fixedLcAll != "" {
-		if fixedLcAll == "C" || fixedLcAll == "POSIX" || !strings.Contains(fixedLcAll, "UTF-8") {
-			needsFixing = true
-		}
-	} else if fixedLang != "" {
-		// Check LANG if LC_ALL is not set
-		if fixedLang == "C" || fixedLang == "POSIX" || !strings.Contains(fixedLang, "UTF-8") {
-			needsFixing = true
-		}
-	} else {
-		// No locale set at all, default to English UTF-8
-		needsFixing = true
-	}
-
-	// Only override if the current locale would cause problems
-	if needsFixing {
-		StatusT("Setting locale to en_US.UTF-8 to prevent application crashes...")
-		os.Setenv("LANG", "en_US.UTF-8")
-		os.Setenv("LC_ALL", "en_US.UTF-8")
-	}
-}
-
 // PackageIsNewEnough checks if the package has an available version greater than or equal to compareVersion
 //
 //	false - package is not new enough
@@ -308,55 +166,134 @@ func extractNumber(s string) int {
 	return num
 }
 
-// DownloadFile downloads a file from URL to destination
+// DownloadFile downloads a file from URL to destination, see DownloadFileWithOptions
 func DownloadFile(url, destination string) error {
+	return DownloadFileWithOptions(url, destination, DownloadOptions{})
+}
+
+// DownloadFileWithOptions downloads a file from URL to destination. The downloads of app scripts go through the
+// asset cache shared by all apps unless options turn it off, so a file several apps download is only fetched once.
+func DownloadFileWithOptions(url, destination string, options DownloadOptions) error {
+	options.SHA256 = strings.ToLower(strings.TrimSpace(options.SHA256))
+	if options.SHA256 != "" && !sha256Regex.MatchString(options.SHA256) {
+		return fmt.Errorf("invalid sha256 %q, expected 64 hexadecimal characters", options.SHA256)
+	}
+
 	// Create the destination directory if it doesn't exist
 	dir := filepath.Dir(destination)
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return fmt.Errorf("failed to create directory %s: %w", dir, err)
 	}
 
+	// Installing from an app bundle, the file is in it
+	if staged, ok := stagedAsset(url); ok {
+		return copyStagedAsset(staged, destination)
+	}
+
+	if options.cached() {
+		return downloadCached(url, destination, options)
+	}
+
+	// The destination file is opened once the server answered, so a failed request leaves nothing behind
+	var out *os.File
+	size, sum, err := fetchDownload(url, filepath.Base(destination), nil, func() (io.Writer, error) {
+		var err error
+		if out, err = os.Create(destination); err != nil {
+			return nil, fmt.Errorf("failed to create file: %w", err)
+		}
+		return out, nil
+	})
+	if out != nil {
+		if closeErr := out.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if err == nil {
+		err = checkDownloadSum(url, hex.EncodeToString(sum), options.SHA256)
+		if err != nil {
+			os.Remove(destination)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	recordDownload(url, destination, size, sum)
+
+	StatusGreenT("Download completed: %s", destination)
+	return nil
+}
+
+// downloadValidators identify the version of a file a server sent, for conditional requests
+type downloadValidators struct {
+	ETag         string
+	LastModified string
+}
+
+// errNotModified is returned by fetchDownload when the file didn't change since the validators it was given
+var errNotModified = errors.New("not modified")
+
+// fetchDownload downloads a URL into the writer open returns once the server answered, with a progress bar named
+// after the file and limited to the download speed limit. It returns the size and sha256 of what it downloaded.
+// With validators, the download is conditional on the file having changed, errNotModified being returned when it
+// didn't, and the validators are replaced with the ones of the downloaded file.
+func fetchDownload(url, name string, validators *downloadValidators, open func() (io.Writer, error)) (int64, []byte, error) {
 	// Issue the HTTP request
 	StatusT("Downloading %s", url)
-	resp, err := http.Get(url)
+	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
-		return fmt.Errorf("failed to initiate download: %w", err)
+		return 0, nil, errs.New(errs.ErrNetwork, "failed to initiate download: %w", err)
+	}
+	if validators != nil {
+		if validators.ETag != "" {
+			req.Header.Set("If-None-Match", validators.ETag)
+		}
+		if validators.LastModified != "" {
+			req.Header.Set("If-Modified-Since", validators.LastModified)
+		}
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, nil, errs.New(errs.ErrNetwork, "failed to initiate download: %w", err)
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusNotModified && validators != nil {
+		return 0, nil, errNotModified
+	}
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("failed to download file: HTTP %d", resp.StatusCode)
+		return 0, nil, errs.New(errs.ErrNetwork, "failed to download file: HTTP %d", resp.StatusCode)
 	}
-
-	// Open the destination file
-	out, err := os.Create(destination)
+	if validators != nil {
+		*validators = downloadValidators{ETag: resp.Header.Get("ETag"), LastModified: resp.Header.Get("Last-Modified")}
+	}
+	out, err := open()
 	if err != nil {
-		return fmt.Errorf("failed to create file: %w", err)
+		return 0, nil, err
 	}
-	defer out.Close()
 
-	// Setup the progress bar
+	// Setup the progress bar, with the download speed limit if there is one
+	limit := DownloadLimit()
+	description := Tf("downloading %s", name)
+	if limit > 0 {
+		description = Tf("downloading %s (limited to %s)", name, formatRateLimit(limit))
+	}
 	var bar *progressbar.ProgressBar
 	if resp.ContentLength > 0 {
-		bar = progressbar.DefaultBytes(
-			resp.ContentLength,
-			Tf("downloading %s", filepath.Base(destination)),
-		)
+		bar = progressbar.DefaultBytes(resp.ContentLength, description)
 	} else {
 		// Unknown length: show spinner style
-		bar = progressbar.DefaultBytes(
-			-1,
-			Tf("downloading %s", filepath.Base(destination)),
-		)
+		bar = progressbar.DefaultBytes(-1, description)
 	}
 
-	// Copy with progress bar
-	if _, err := io.Copy(io.MultiWriter(out, bar), resp.Body); err != nil {
-		return fmt.Errorf("download failed: %w", err)
+	// Copy with progress bar, hashing the file for the download ledger on the way
+	phase, endPhase := startDownloadPhase(resp.ContentLength)
+	defer endPhase()
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(out, bar, hash, phase), newRateLimitedReader(resp.Body, limit))
+	if err != nil {
+		return 0, nil, errs.New(errs.ErrNetwork, "download failed: %w", err)
 	}
-
-	StatusGreenT("Download completed: %s", destination)
-	return nil
+	return size, hash.Sum(nil), nil
 }
 
 // FileExists checks if a file exists
@@ -402,14 +339,6 @@ func CopyFile(src, dst string) error {
 //
 //	error - error if path is not specified
 func EnsureDir(path string) error {
-	if DirExists(path) {
-		return nil
-	}
-
-	err := os.MkdirAll(path, 0755)
-	if err != nil {
-		return fmt.Errorf("failed to create directory %s: %w", path, err)
-	}
-
-	return nil
+	_, err := EnsureParents(path, 0755)
+	return err
 }
@@ -43,6 +43,8 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/pi-apps-go/pi-apps/pkg/api/errs"
 )
 
 // getApkArchitecture gets the current system architecture from apk
@@ -275,7 +277,11 @@ func AptLockWait() error {
 	go func() {
 		select {
 		case <-time.After(5 * time.Second):
-			fmt.Print(T("Waiting until APK locks are released... "))
+			if holders := lockHolders("apk"); holders != "" {
+				fmt.Print(Tf("Waiting until APK locks held by %s are released... ", holders))
+			} else {
+				fmt.Print(T("Waiting until APK locks are released... "))
+			}
 			notificationShown <- true
 		case <-notificationDone:
 			return
@@ -293,7 +299,7 @@ func AptLockWait() error {
 		cmd.Stdin = os.Stdin
 		if err := cmd.Run(); err != nil {
 			close(notificationDone)
-			return fmt.Errorf("failed to get sudo permissions: %w", err)
+			return errs.New(errs.ErrNeedsRoot, "failed to get sudo permissions: %w", err)
 		}
 	}
 
@@ -383,7 +389,7 @@ func stripAnsiCodes(s string) string {
 // AptUpdate runs an apk update with error-checking and minimal output
 func AptUpdate(args ...string) error {
 	// Use cyan color with reverse video styling
-	fmt.Fprintf(os.Stderr, "\033[96m%s \033[7m sudo apk update\033[27m...\033[0m\n", T("Running"))
+	colorPrintf(os.Stderr, "\033[96m%s \033[7m sudo apk update\033[27m...\033[0m\n", T("Running"))
 
 	// Build command with optional arguments (like --allow-untrusted)
 	cmdArgs := []string{"apk", "update"}
@@ -397,12 +403,12 @@ func AptUpdate(args ...string) error {
 		errorMessageTitle := T("Failed to run")
 		errorMessageCommand := "sudo apk update"
 		errorMessageErrors := T("APK reported these errors:")
-		fmt.Fprint(os.Stderr, fmt.Sprintf("\033[91m%s \033[4m%s\033[0m\033[39m!\n", errorMessageTitle, errorMessageCommand))
-		fmt.Fprintf(os.Stderr, fmt.Sprintf("%s\n\033[91m%s\033[39m\n", errorMessageErrors, err.Error()))
+		colorPrintf(os.Stderr, "\033[91m%s \033[4m%s\033[0m\033[39m!\n", errorMessageTitle, errorMessageCommand)
+		colorPrintf(os.Stderr, "%s\n\033[91m%s\033[39m\n", errorMessageErrors, err.Error())
 		return fmt.Errorf("apk update failed: %w", err)
 	}
 
-	fmt.Fprintf(os.Stderr, "\033[96m%s\033[0m\n", T("apk update complete."))
+	colorPrintf(os.Stderr, "\033[96m%s\033[0m\n", T("apk update complete."))
 	return nil
 }
 
@@ -476,6 +482,9 @@ func AppToPkgName(app string) (string, error) {
 
 // InstallPackages installs packages using APK
 func InstallPackages(app string, args ...string) error {
+	// The progress monitor shows the packages being installed until the script goes on
+	defer startPhase(PhaseInstallingPackages, -1)()
+
 	// Process arguments
 	var packages []string
 	usingLocalPackages := false
@@ -729,17 +738,17 @@ func InstallPackages(app string, args ...string) error {
 		errorStr := strings.Join(errorLines, "\n")
 
 		if len(errorLines) > 0 {
-			fmt.Printf("\033[91m%s\033[39m\n", T("Failed to install the packages!"))
-			fmt.Printf(T("%s\n\033[91m%s\033[39m\n"), T("APK reported these errors:"), errorStr)
+			colorPrintf(os.Stdout, "\033[91m%s\033[39m\n", T("Failed to install the packages!"))
+			colorPrintf(os.Stdout, T("%s\n\033[91m%s\033[39m\n"), T("APK reported these errors:"), errorStr)
 		} else {
-			fmt.Printf("\033[91m%s\033[39m\n", T("Failed to install the packages!"))
+			colorPrintf(os.Stdout, "\033[91m%s\033[39m\n", T("Failed to install the packages!"))
 			fmt.Printf(T("APK exited with error code %d\n"), cmd.ProcessState.ExitCode())
 		}
 
 		fmt.Println(combinedOutput)
 
 		if len(errorLines) > 0 {
-			return fmt.Errorf("apk reported errors: %s", errorStr)
+			return packageManagerError("apk", errorStr)
 		}
 		return fmt.Errorf("apk add failed with exit code %d", cmd.ProcessState.ExitCode())
 	}
@@ -748,7 +757,7 @@ func InstallPackages(app string, args ...string) error {
 	// This helps with uninstallation later
 	piAppsDir := GetPiAppsDir()
 
-	trackingDir := filepath.Join(piAppsDir, "data", "installed-packages")
+	trackingDir := filepath.Join(DataDirOf(piAppsDir), "installed-packages")
 	if err := os.MkdirAll(trackingDir, 0755); err != nil {
 		WarningTf("Failed to create tracking directory: %v", err)
 	} else {
@@ -771,7 +780,7 @@ func PurgePackages(app string, isUpdate bool) error {
 	piAppsDir := GetPiAppsDir()
 
 	// Check for tracking file
-	trackingFile := filepath.Join(piAppsDir, "data", "installed-packages", app)
+	trackingFile := filepath.Join(DataDirOf(piAppsDir), "installed-packages", app)
 
 	if !FileExists(trackingFile) {
 		// No tracking file, nothing to purge
@@ -915,17 +924,17 @@ func PurgePackages(app string, isUpdate bool) error {
 		errorStr := strings.Join(errorLines, "\n")
 
 		if len(errorLines) > 0 {
-			fmt.Printf("\033[91m%s\033[39m\n", T("Failed to uninstall the packages!"))
-			fmt.Printf("%s\n\033[91m%s\033[39m\n", T("APK reported these errors:"), errorStr)
+			colorPrintf(os.Stdout, "\033[91m%s\033[39m\n", T("Failed to uninstall the packages!"))
+			colorPrintf(os.Stdout, "%s\n\033[91m%s\033[39m\n", T("APK reported these errors:"), errorStr)
 		} else {
-			fmt.Printf("\033[91m%s\033[39m\n", T("Failed to uninstall the packages!"))
+			colorPrintf(os.Stdout, "\033[91m%s\033[39m\n", T("Failed to uninstall the packages!"))
 			fmt.Printf(T("APK exited with error code %d\n"), cmd.ProcessState.ExitCode())
 		}
 
 		fmt.Println(combinedOutput)
 
 		if len(errorLines) > 0 {
-			return fmt.Errorf("apk reported errors: %s", errorStr)
+			return packageManagerError("apk", errorStr)
 		}
 		return fmt.Errorf("apk del failed with exit code %d", cmd.ProcessState.ExitCode())
 	}
@@ -1315,6 +1324,38 @@ func PackageInstalled(packageName string) bool {
 	return err == nil
 }
 
+// PackageHold holds a package so upgrades leave it at its installed version
+func PackageHold(packageName string) error {
+	// apk has no package holds, packages are pinned in /etc/apk/world instead
+	return fmt.Errorf("holding packages is not supported with apk")
+}
+
+// PackageUnhold removes the hold of a package
+func PackageUnhold(packageName string) error {
+	// apk has no package holds, packages are pinned in /etc/apk/world instead
+	return fmt.Errorf("holding packages is not supported with apk")
+}
+
+// PackageIsHeld checks if a package is installed and held
+func PackageIsHeld(packageName string) bool {
+	// apk has no package holds
+	return false
+}
+
+// InstalledPackages returns the names of all installed packages with a single apk call
+func InstalledPackages() (map[string]bool, error) {
+	output, err := exec.Command("apk", "info").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list installed packages: %w", err)
+	}
+
+	packages := make(map[string]bool)
+	for _, name := range strings.Fields(string(output)) {
+		packages[name] = true
+	}
+	return packages, nil
+}
+
 // PackageAvailable determines if the specified package exists in a repository
 func PackageAvailable(packageName string, dpkgArch string) bool {
 	// Special handling for "init" package check
@@ -1675,7 +1716,7 @@ func refreshPackageAppStatusWithCache(appName, apkListOutput, apkInstalledStatus
 		// If the package is installed, mark the app as installed
 		if status != "installed" {
 			Debug(fmt.Sprintf("Marking %s as installed", appName))
-			statusDir := filepath.Join(directory, "data", "status")
+			statusDir := filepath.Join(DataDirOf(directory), "status")
 			if err := os.MkdirAll(statusDir, 0755); err != nil {
 				return fmt.Errorf("error creating status directory: %w", err)
 			}
@@ -1696,7 +1737,7 @@ func refreshPackageAppStatusWithCache(appName, apkListOutput, apkInstalledStatus
 		if status != "uninstalled" {
 			Debug(fmt.Sprintf("Marking %s as uninstalled", appName))
 			// Remove the status file to mark it as uninstalled
-			statusFile := filepath.Join(directory, "data", "status", appName)
+			statusFile := filepath.Join(DataDirOf(directory), "status", appName)
 			_ = os.Remove(statusFile) // Ignore error if file doesn't exist
 
 			// Send analytics
@@ -1707,7 +1748,7 @@ func refreshPackageAppStatusWithCache(appName, apkListOutput, apkInstalledStatus
 	}
 
 	// Check if the app is currently hidden but should be unhidden
-	categoryOverridesFile := filepath.Join(directory, "data", "category-overrides")
+	categoryOverridesFile := filepath.Join(DataDirOf(directory), "category-overrides")
 	if FileExists(categoryOverridesFile) {
 		// Check if app is marked as hidden in the category-overrides file
 		file, err := os.Open(categoryOverridesFile)
@@ -0,0 +1,36 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: apk_bundle.go
+// Description: Provides dummy functions for the package part of app bundles, packages are only bundled when using APT.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+//go:build apk
+
+package api
+
+import (
+	"fmt"
+)
+
+// bundleAppPackages would download the packages of an app into the pool of a bundle, which is not supported with apk
+func bundleAppPackages(app, appType, poolDir string) ([]string, error) {
+	if appType == "package" {
+		return nil, fmt.Errorf("bundling packages is not supported with apk")
+	}
+	WarningT("The packages the install script installs are not bundled, the device the bundle is installed on needs them already")
+	return nil, nil
+}
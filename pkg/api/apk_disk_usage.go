@@ -0,0 +1,66 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: apk_disk_usage.go
+// Description: Reads the package sizes of the disk usage attribution from the apk database.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+//go:build apk
+
+package api
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// apkInstalledDB is the apk database of installed packages
+const apkInstalledDB = "/lib/apk/db/installed"
+
+// appPackageNames returns the packages recorded when the app was installed
+func appPackageNames(app string) []string {
+	return trackedPackageNames(app)
+}
+
+// installedPackageSizes returns the installed size of every installed package in bytes, read from the
+// P: and I: fields of the apk database
+func installedPackageSizes() (map[string]int64, error) {
+	file, err := os.Open(apkInstalledDB)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the apk database: %w", err)
+	}
+	defer file.Close()
+
+	sizes := make(map[string]int64)
+	var name string
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			name = ""
+		case strings.HasPrefix(line, "P:"):
+			name = line[2:]
+		case strings.HasPrefix(line, "I:") && name != "":
+			sizes[name], _ = strconv.ParseInt(line[2:], 10, 64)
+		}
+	}
+	return sizes, scanner.Err()
+}
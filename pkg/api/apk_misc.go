@@ -30,9 +30,6 @@ import (
 	"path/filepath"
 	"strings"
 	"time"
-
-	"github.com/gotk3/gotk3/glib"
-	"github.com/gotk3/gotk3/gtk"
 )
 
 // variables for APK related messages
@@ -47,34 +44,17 @@ var (
 	UbuntuPPAInstallerMessage  = T("Install Ubuntu PPA - ignored, not supported by APK")
 	DebianPPAInstallerMessage  = T("Install Debian PPA - ignored, not supported by APK")
 	PatchDebSedMessage         = T("Modify the control file of a deb file to fix the dependencies following a sed pattern - ignored, not supported by APK")
+	RebuildDummyDebsMessage    = T("Rebuild missing dummy debs of installed apps - ignored, not supported by APK")
 )
 
-// checkShellcheck checks if shellcheck is installed and installs it if it isn't
-func checkShellcheck() error {
-	// Initialize application name
-	glib.SetPrgname("Pi-Apps-Settings")
-	glib.SetApplicationName(T("Pi-Apps Settings (app creation wizard)"))
-
-	// Initialize GTK
-	gtk.Init(nil)
-
-	// Check if shellcheck is installed
-	if !commandExists("shellcheck") {
-		// Ask if they want to install shellcheck
-		dialog := gtk.MessageDialogNew(nil, gtk.DIALOG_MODAL, gtk.MESSAGE_QUESTION, gtk.BUTTONS_YES_NO,
-			T("Shellcheck is not installed, but it's useful for finding errors in shell scripts. Install it now?"))
-		response := dialog.Run()
-		dialog.Destroy()
-
-		if response == gtk.RESPONSE_YES {
-			// Install shellcheck using APK
-			cmd := exec.Command("sudo", "apk", "add", "shellcheck")
-			cmd.Stdout = os.Stdout
-			cmd.Stderr = os.Stderr
-			if err := cmd.Run(); err != nil {
-				return fmt.Errorf("failed to install shellcheck: %w", err)
-			}
-		}
+// installShellcheck installs shellcheck with the package manager
+func installShellcheck() error {
+	// Install shellcheck using APK
+	cmd := exec.Command("sudo", "apk", "add", "shellcheck")
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to install shellcheck: %w", err)
 	}
 	return nil
 }
@@ -517,8 +497,8 @@ func installPackageApp(appName string) error {
 	piAppsDir := GetPiAppsDir()
 
 	// Set up logging
-	logDir := filepath.Join(piAppsDir, "logs")
-	os.MkdirAll(logDir, 0755)
+	logDir := LogsDirOf(piAppsDir)
+	EnsureDirOwned(logDir, InvokingUser, InvokingUser)
 	logFilename := fmt.Sprintf("install-%s-incomplete-%d.log", appName, time.Now().Unix())
 	logPath := filepath.Join(logDir, logFilename)
 
@@ -609,7 +589,7 @@ func installPackageApp(appName string) error {
 		FormatLogfile(logPath)
 
 		// Rename log file to indicate failure
-		newLogPath := strings.Replace(logPath, "-incomplete-", "-fail-", 1)
+		newLogPath := logPathWithResult(logPath, "fail")
 		os.Rename(logPath, newLogPath)
 
 		return fmt.Errorf("failed to install packages: %v", err)
@@ -623,7 +603,7 @@ func installPackageApp(appName string) error {
 	FormatLogfile(logPath)
 
 	// Rename log file to indicate success
-	newLogPath := strings.Replace(logPath, "-incomplete-", "-success-", 1)
+	newLogPath := logPathWithResult(logPath, "success")
 	os.Rename(logPath, newLogPath)
 
 	return nil
@@ -634,8 +614,8 @@ func uninstallPackageApp(appName string) error {
 	piAppsDir := GetPiAppsDir()
 
 	// Set up logging
-	logDir := filepath.Join(piAppsDir, "logs")
-	os.MkdirAll(logDir, 0755)
+	logDir := LogsDirOf(piAppsDir)
+	EnsureDirOwned(logDir, InvokingUser, InvokingUser)
 	logFilename := fmt.Sprintf("uninstall-%s-incomplete-%d.log", appName, time.Now().Unix())
 	logPath := filepath.Join(logDir, logFilename)
 
@@ -698,7 +678,7 @@ func uninstallPackageApp(appName string) error {
 		FormatLogfile(logPath)
 
 		// Rename log file to indicate failure
-		newLogPath := strings.Replace(logPath, "-incomplete-", "-fail-", 1)
+		newLogPath := logPathWithResult(logPath, "fail")
 		os.Rename(logPath, newLogPath)
 
 		return fmt.Errorf("failed to uninstall packages: APK reported errors")
@@ -713,7 +693,7 @@ func uninstallPackageApp(appName string) error {
 		FormatLogfile(logPath)
 
 		// Rename log file to indicate failure
-		newLogPath := strings.Replace(logPath, "-incomplete-", "-fail-", 1)
+		newLogPath := logPathWithResult(logPath, "fail")
 		os.Rename(logPath, newLogPath)
 
 		return fmt.Errorf("failed to uninstall packages: %v", err)
@@ -727,7 +707,7 @@ func uninstallPackageApp(appName string) error {
 	FormatLogfile(logPath)
 
 	// Rename log file to indicate success
-	newLogPath := strings.Replace(logPath, "-incomplete-", "-success-", 1)
+	newLogPath := logPathWithResult(logPath, "success")
 	os.Rename(logPath, newLogPath)
 
 	return nil
@@ -767,3 +747,13 @@ func uninstallPackageAppDependencies(dependencies ...string) error {
 func PatchDebSed(debFile, sedString string) error {
 	return fmt.Errorf("only supported on Debian-based systems")
 }
+
+// RebuildDummyDeb regenerates and installs the dummy deb of an app whose dummy deb was removed
+func RebuildDummyDeb(app string) error {
+	return fmt.Errorf("only supported on Debian-based systems")
+}
+
+// RebuildDummyDebs rebuilds the dummy debs of the given apps, or of every app reported by ListAppsMissingDummyDebs
+func RebuildDummyDebs(apps ...string) ([]string, error) {
+	return nil, fmt.Errorf("only supported on Debian-based systems")
+}
@@ -0,0 +1,48 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: apk_path_owner.go
+// Description: Asks apk which package installed a path, for finding the app that owns it.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+//go:build apk
+
+package api
+
+import (
+	"os/exec"
+	"regexp"
+	"strings"
+)
+
+// apkPackageVersionPattern matches the version and release apk appends to package names, like -1.36.1-r15
+var apkPackageVersionPattern = regexp.MustCompile(`-[^-]+-r[0-9]+$`)
+
+// packagesOwningPath returns the package apk installed a path with, none if no package did
+func packagesOwningPath(path string) []string {
+	output, err := exec.Command("apk", "info", "--who-owns", path).Output()
+	if err != nil {
+		return nil
+	}
+	var packages []string
+	for _, line := range strings.Split(string(output), "\n") {
+		// "/path is owned by busybox-1.36.1-r15"
+		if _, owner, ok := strings.Cut(line, " is owned by "); ok {
+			packages = append(packages, apkPackageVersionPattern.ReplaceAllString(strings.TrimSpace(owner), ""))
+		}
+	}
+	return packages
+}
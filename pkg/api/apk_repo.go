@@ -23,11 +23,13 @@ package api
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"os/exec"
+	"slices"
 	"strings"
 
 	"gitlab.alpinelinux.org/alpine/go/repository"
@@ -46,15 +48,35 @@ func AnythingInstalledFromURISuiteComponent(uri, suite, component string) (bool,
 
 	Debug(fmt.Sprintf("Checking if anything is installed from %s", uri))
 
+	packages, err := PackagesInstalledFromRepo(uri, suite, component)
+	if err != nil {
+		return false, err
+	}
+	return len(packages) > 0, nil
+}
+
+// PackagesInstalledFromRepo lists the installed packages that come from a specific repository.
+//
+// Note: APK uses a different repository structure than APT, so suite and component
+// parameters are ignored for APK (they're APT-specific).
+//
+//	[]InstalledPackage - installed packages from the repository, sorted by name
+//	error - error if repository URI is not specified or the APK database cannot be read
+func PackagesInstalledFromRepo(uri, suite, component string) ([]InstalledPackage, error) {
+	if uri == "" {
+		return nil, fmt.Errorf("repository uri must be specified")
+	}
+
+	Debug(fmt.Sprintf("Checking what is installed from %s", uri))
+
 	// Get all installed packages
 	installedPackages, err := getInstalledPackages()
 	if err != nil {
-		return false, fmt.Errorf("failed to get installed packages: %w", err)
+		return nil, fmt.Errorf("failed to get installed packages: %w", err)
 	}
 
-	// Check if any installed packages are from this repository
 	// Note: APK doesn't use suite/component, so we ignore those parameters
-	return checkIfPackagesInstalledFromRepo(installedPackages, uri, suite, component)
+	return packagesInstalledFromRepo(installedPackages, uri)
 }
 
 // RemoveRepofileIfUnused removes a repository file if nothing from that repository is currently installed.
@@ -216,8 +238,17 @@ func getPackagesFromRepoURL(repoURL string) ([]string, error) {
 
 // Helper function to check if any packages are installed from a specific repo
 func checkIfPackagesInstalledFromRepo(packages []string, uri, suite, component string) (bool, error) {
+	installed, err := packagesInstalledFromRepo(packages, uri)
+	if err != nil {
+		return false, err
+	}
+	return len(installed) > 0, nil
+}
+
+// Helper function to list which of the given packages are installed from a specific repo
+func packagesInstalledFromRepo(packages []string, uri string) ([]InstalledPackage, error) {
 	if len(packages) == 0 {
-		return false, nil
+		return nil, nil
 	}
 
 	// APK stores package origin in /lib/apk/db/installed
@@ -225,16 +256,18 @@ func checkIfPackagesInstalledFromRepo(packages []string, uri, suite, component s
 
 	installedFile, err := os.Open("/lib/apk/db/installed")
 	if err != nil {
-		return false, fmt.Errorf("failed to open APK database: %w", err)
+		return nil, fmt.Errorf("failed to open APK database: %w", err)
 	}
 	defer installedFile.Close()
 
-	// Build a map of package names to their origins
+	// Build maps of package names to their origins and versions
 	packageOrigins := make(map[string]string)
+	packageVersions := make(map[string]string)
 
 	scanner := bufio.NewScanner(installedFile)
 	var currentPackage string
 	var currentOrigin string
+	var currentVersion string
 
 	for scanner.Scan() {
 		line := scanner.Text()
@@ -243,9 +276,11 @@ func checkIfPackagesInstalledFromRepo(packages []string, uri, suite, component s
 		if line == "" {
 			if currentPackage != "" && currentOrigin != "" {
 				packageOrigins[currentPackage] = currentOrigin
+				packageVersions[currentPackage] = currentVersion
 			}
 			currentPackage = ""
 			currentOrigin = ""
+			currentVersion = ""
 			continue
 		}
 
@@ -254,6 +289,11 @@ func checkIfPackagesInstalledFromRepo(packages []string, uri, suite, component s
 			currentPackage = strings.TrimPrefix(line, "P:")
 		}
 
+		// V: Package version
+		if strings.HasPrefix(line, "V:") {
+			currentVersion = strings.TrimPrefix(line, "V:")
+		}
+
 		// o: Origin (repository name)
 		if strings.HasPrefix(line, "o:") {
 			currentOrigin = strings.TrimPrefix(line, "o:")
@@ -261,9 +301,11 @@ func checkIfPackagesInstalledFromRepo(packages []string, uri, suite, component s
 	}
 
 	if err := scanner.Err(); err != nil {
-		return false, fmt.Errorf("error reading APK database: %w", err)
+		return nil, fmt.Errorf("error reading APK database: %w", err)
 	}
 
+	var matching []string
+
 	// Try to fetch packages from the repository using Alpine library
 	// This gives us accurate package availability for the specific repo
 	repoPackages, err := getPackagesFromRepoURL(uri)
@@ -277,32 +319,77 @@ func checkIfPackagesInstalledFromRepo(packages []string, uri, suite, component s
 		for _, pkg := range packages {
 			if origin, exists := packageOrigins[pkg]; exists {
 				if strings.Contains(cleanURI, origin) || strings.Contains(origin, "pi-apps") {
-					return true, nil
+					matching = append(matching, pkg)
 				}
 			}
 		}
+	} else {
+		// Build a map of packages in this repository
+		repoPackageMap := make(map[string]bool)
+		for _, repoPackage := range repoPackages {
+			repoPackageMap[repoPackage] = true
+		}
 
-		return false, nil
-	}
-
-	// Build a map of packages in this repository
-	repoPackageMap := make(map[string]bool)
-	for _, repoPackage := range repoPackages {
-		repoPackageMap[repoPackage] = true
+		// Keep the requested packages that are both:
+		// 1. Available in the repository
+		// 2. Actually installed on the system
+		for _, pkg := range packages {
+			if _, exists := packageOrigins[pkg]; exists && repoPackageMap[pkg] {
+				matching = append(matching, pkg)
+			}
+		}
 	}
 
-	// Check if any of the requested packages are both:
-	// 1. Available in the repository
-	// 2. Actually installed on the system
-	for _, pkg := range packages {
-		if _, exists := packageOrigins[pkg]; exists {
-			// Package is installed
-			if repoPackageMap[pkg] {
-				// Package is also in the repository
-				return true, nil
+	// Packages in /etc/apk/world were installed explicitly, everything else is a dependency
+	explicit := make(map[string]bool)
+	if world, err := os.ReadFile("/etc/apk/world"); err == nil {
+		for _, entry := range strings.Fields(string(world)) {
+			// Strip version constraints and repository tags, e.g. "foo>=1.0" or "foo@testing"
+			name := strings.FieldsFunc(entry, func(r rune) bool {
+				return strings.ContainsRune("<>=~@", r)
+			})
+			if len(name) > 0 {
+				explicit[name[0]] = true
 			}
 		}
 	}
 
-	return false, nil
+	var result []InstalledPackage
+	for _, pkg := range matching {
+		result = append(result, InstalledPackage{
+			Name:      pkg,
+			Version:   packageVersions[pkg],
+			Automatic: !explicit[pkg],
+		})
+	}
+	slices.SortFunc(result, func(a, b InstalledPackage) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return result, nil
+}
+
+// CheckAptSources checks the configured APT repositories. It finds none, as APK repositories are not checked.
+func CheckAptSources(ctx context.Context) ([]SourceHealth, error) {
+	return nil, ctx.Err()
+}
+
+// DisableAptSource disables a broken APT repository, there are none without apt
+func DisableAptSource(source SourceHealth) error {
+	return fmt.Errorf("%s is not an APT repository file", source.File)
+}
+
+// RestoreAptSources enables the APT repositories DisableAptSource disabled again, there are none without apt
+func RestoreAptSources() (int, error) {
+	return 0, nil
+}
+
+// ListExternalRepoKeys lists the signing keys of the APT repositories. It finds none, as APK repositories are signed by the keys in /etc/apk/keys.
+func ListExternalRepoKeys() ([]RepoKey, error) {
+	return nil, nil
+}
+
+// RenewExternalRepoKey downloads the key of an APT repository again, there are none without apt
+func RenewExternalRepoKey(repo string) error {
+	return fmt.Errorf("%s is not an APT repository", repo)
 }
@@ -25,9 +25,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
-	"strconv"
 	"strings"
-	"syscall"
 	"unsafe"
 )
 
@@ -61,7 +59,7 @@ func AppStatus(app string) (string, error) {
 		// If app doesn't exist, check if it's a deprecated app
 		if IsDeprecatedApp(app) {
 			// For deprecated apps, check status file
-			statusFile := filepath.Join(directory, "data", "status", app)
+			statusFile := filepath.Join(DataDirOf(directory), "status", app)
 			if _, err := os.Stat(statusFile); os.IsNotExist(err) {
 				return "uninstalled", nil
 			}
@@ -77,7 +75,7 @@ func AppStatus(app string) (string, error) {
 	}
 
 	// Check if the app has a status file
-	statusFile := filepath.Join(directory, "data", "status", app)
+	statusFile := filepath.Join(DataDirOf(directory), "status", app)
 	if _, err := os.Stat(statusFile); os.IsNotExist(err) {
 		return "uninstalled", nil
 	}
@@ -105,7 +103,7 @@ func storeDeprecatedAppData(app, removalArch, message string) error {
 	}
 
 	appDir := filepath.Join(directory, "apps", app)
-	deprecatedDir := filepath.Join(directory, "data", "deprecated-apps", app)
+	deprecatedDir := filepath.Join(DataDirOf(directory), "deprecated-apps", app)
 
 	// Create deprecated app directory
 	if err := os.MkdirAll(deprecatedDir, 0755); err != nil {
@@ -157,7 +155,7 @@ func IsDeprecatedApp(app string) bool {
 	if directory == "" {
 		return false
 	}
-	metadataFile := filepath.Join(directory, "data", "deprecated-apps", app, "metadata")
+	metadataFile := filepath.Join(DataDirOf(directory), "deprecated-apps", app, "metadata")
 	_, err := os.Stat(metadataFile)
 	return err == nil
 }
@@ -172,7 +170,7 @@ func GetDeprecatedAppUninstallScript(app string) (string, error) {
 		}
 		directory = filepath.Dir(filepath.Dir(currentDir))
 	}
-	uninstallScript := filepath.Join(directory, "data", "deprecated-apps", app, "uninstall")
+	uninstallScript := filepath.Join(DataDirOf(directory), "deprecated-apps", app, "uninstall")
 	if _, err := os.Stat(uninstallScript); os.IsNotExist(err) {
 		return "", fmt.Errorf("uninstall script not found for deprecated app %s", app)
 	}
@@ -190,11 +188,11 @@ func GetDeprecatedAppIcon(app string) string {
 		}
 		directory = filepath.Dir(filepath.Dir(currentDir))
 	}
-	icon64 := filepath.Join(directory, "data", "deprecated-apps", app, "icon-64.png")
+	icon64 := filepath.Join(DataDirOf(directory), "deprecated-apps", app, "icon-64.png")
 	if _, err := os.Stat(icon64); err == nil {
 		return icon64
 	}
-	icon24 := filepath.Join(directory, "data", "deprecated-apps", app, "icon-24.png")
+	icon24 := filepath.Join(DataDirOf(directory), "deprecated-apps", app, "icon-24.png")
 	if _, err := os.Stat(icon24); err == nil {
 		return icon24
 	}
@@ -213,7 +211,7 @@ func removeDeprecatedAppEntries(app string) error {
 		directory = filepath.Dir(filepath.Dir(currentDir))
 	}
 
-	deprecatedDir := filepath.Join(directory, "data", "deprecated-apps", app)
+	deprecatedDir := filepath.Join(DataDirOf(directory), "deprecated-apps", app)
 	if _, err := os.Stat(deprecatedDir); os.IsNotExist(err) {
 		// Directory doesn't exist, nothing to clean up
 		return nil
@@ -338,7 +336,36 @@ func RemoveDeprecatedApp(app, removalArch, message string) error {
 // This is a Go implementation of the original bash terminal_manage function
 func TerminalManage(action, app string) error {
 	// Forward to the multi-version with a single action
-	return TerminalManageMulti(fmt.Sprintf("%s %s", action, app))
+	return TerminalManageMulti(QueueLine(action, app))
+}
+
+// QueueLine returns the line of a manage queue for an action on an app, in the "action;app" form.
+// App names can contain spaces but no semicolons, so the line can always be split again.
+func QueueLine(action, app string) string {
+	return action + ";" + app
+}
+
+// FailureLine returns the line of a failure list for DiagnoseFailures, the QueueLine of the failed action
+// followed by a tab and the log file it wrote. Without a log file it is the QueueLine.
+func FailureLine(action, app, logFile string) string {
+	if logFile == "" {
+		return QueueLine(action, app)
+	}
+	return QueueLine(action, app) + "\t" + logFile
+}
+
+// ParseQueueLine splits a line of a manage queue into its action and app. It accepts the "action;app" form
+// of QueueLine and the "action app" form users type, where the app name may contain spaces.
+// ok is false if the action or the app is missing.
+func ParseQueueLine(line string) (action, app string, ok bool) {
+	line = strings.TrimSpace(line)
+	// A semicolon after a space is part of the names of an "update-file a;b" line, not the separator
+	if before, after, found := strings.Cut(line, ";"); found && !strings.ContainsAny(before, " \t") {
+		action, app = before, strings.TrimSpace(after)
+	} else if before, after, found := strings.Cut(line, " "); found {
+		action, app = before, strings.TrimSpace(after)
+	}
+	return action, app, action != "" && app != ""
 }
 
 // TerminalManageMulti executes multiple app management actions in the Pi-Apps environment
@@ -357,29 +384,15 @@ func TerminalManageMulti(queue string) error {
 
 	// Check if a daemon is already running by checking the pid file AND queue pipe
 	// Just checking PID isn't enough because that PID might belong to a different process after reboot
-	daemonPidFile := filepath.Join(directory, "data", "manage-daemon", "pid")
-	daemonQueuePipe := filepath.Join(directory, "data", "manage-daemon", "queue")
+	daemonPidFile := filepath.Join(DataDirOf(directory), "manage-daemon", "pid")
+	daemonQueuePipe := filepath.Join(DataDirOf(directory), "manage-daemon", "queue")
 
 	daemonRunning := false
 	if _, err := os.Stat(daemonPidFile); err == nil {
 		// Check if queue pipe also exists (indicates a real daemon)
 		if info, err := os.Stat(daemonQueuePipe); err == nil && (info.Mode()&os.ModeNamedPipe) != 0 {
-			// Read the PID from the file
-			pidBytes, err := os.ReadFile(daemonPidFile)
-			if err == nil {
-				pid, err := strconv.Atoi(strings.TrimSpace(string(pidBytes)))
-				if err == nil {
-					// Check if the process exists using os.FindProcess
-					process, err := os.FindProcess(pid)
-					if err == nil {
-						// Send signal 0 to check if process is running
-						if err := process.Signal(syscall.Signal(0)); err == nil {
-							// All checks passed - daemon is actually running
-							daemonRunning = true
-						}
-					}
-				}
-			}
+			// The start time in the PID file tells the daemon apart from a process that got its PID later
+			daemonRunning = PIDFileRunning(daemonPidFile)
 		}
 	}
 
@@ -429,7 +442,7 @@ func TerminalManageMulti(queue string) error {
 
 			// Get the app list style
 			prefix := os.Getenv("prefix")
-			styleFile := filepath.Join(directory, "data", "settings", "App List Style")
+			styleFile := filepath.Join(DataDirOf(directory), "settings", "App List Style")
 			styleBytes, err := os.ReadFile(styleFile)
 			if err != nil {
 				return fmt.Errorf("terminal_manage_multi: failed to read app list style: %w", err)
@@ -461,54 +474,28 @@ func RefreshApp(app string) error {
 		return fmt.Errorf("PI_APPS_DIR environment variable not set")
 	}
 
+	lock, err := LockState("refresh " + app)
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
 	// Check if app exists in update directory
-	updateAppDir := filepath.Join(directory, "update", "pi-apps", "apps", app)
-	if !FileExists(updateAppDir) {
+	updateAppDir := AppUpdateDir(app)
+	if !DirExists(updateAppDir) {
 		return fmt.Errorf("app '%s' not found in update directory", app)
 	}
 
 	// Check if app exists in main directory
 	mainAppDir := filepath.Join(directory, "apps", app)
-	if !FileExists(mainAppDir) {
+	if !DirExists(mainAppDir) {
 		return fmt.Errorf("app '%s' not found in main directory", app)
 	}
 
-	// Copy all files from update directory to main directory
-	err := filepath.Walk(updateAppDir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
+	defer InvalidateAppHash(app)
 
-		// Skip the root directory
-		if path == updateAppDir {
-			return nil
-		}
-
-		// Get relative path from update directory
-		relPath, err := filepath.Rel(updateAppDir, path)
-		if err != nil {
-			return fmt.Errorf("error getting relative path: %w", err)
-		}
-
-		// Construct destination path
-		destPath := filepath.Join(mainAppDir, relPath)
-
-		if info.IsDir() {
-			// Create directory if it doesn't exist
-			if err := os.MkdirAll(destPath, info.Mode()); err != nil {
-				return fmt.Errorf("error creating directory %s: %w", destPath, err)
-			}
-		} else {
-			// Copy file
-			if err := copyFile(path, destPath); err != nil {
-				return fmt.Errorf("error copying file %s: %w", path, err)
-			}
-		}
-
-		return nil
-	})
-
-	if err != nil {
+	// Copy all files from update directory to main directory, verifying each one
+	if err := copyTreeVerified(updateAppDir, mainAppDir); err != nil {
 		return fmt.Errorf("error refreshing app: %w", err)
 	}
 
@@ -534,10 +521,11 @@ func UpdateFile(filePath string) error {
 		return fmt.Errorf("file '%s' not found in main directory", filePath)
 	}
 
-	// Copy file from update directory to main directory
-	if err := copyFile(updateFilePath, mainFilePath); err != nil {
+	// Copy file from update directory to main directory, only replacing it once the copy is verified
+	if err := copyFileVerified(updateFilePath, mainFilePath); err != nil {
 		return fmt.Errorf("error updating file: %w", err)
 	}
+	reportFileProgress(FileProgress{Done: 1, Total: 1, File: mainFilePath})
 
 	return nil
 }
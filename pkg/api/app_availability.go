@@ -0,0 +1,194 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: app_availability.go
+// Description: Tells which apps can't be installed on this system because of its architecture, OS or device, so the app list can hide them.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package api
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+)
+
+const (
+	// unavailableAppsFile is the per-device blacklist shipped in etc, one "<device model pattern>\t<app>" per line.
+	// Patterns are shell globs matched against the device model without regard to case.
+	unavailableAppsFile = "unavailable-apps"
+	// unavailableAppsSetting decides whether the app list hides unavailable apps or shows them with a badge
+	unavailableAppsSetting = "Unavailable apps"
+)
+
+// deviceModel is the device model, read once because it doesn't change while Pi-Apps runs
+var deviceModel = sync.OnceValue(func() string {
+	model, _ := GetDeviceModel()
+	return model
+})
+
+// userlandArch returns the architecture of the userland in Debian naming, like armhf for a 32-bit
+// Raspberry Pi OS running on a 64-bit kernel
+func userlandArch() string {
+	bits := getSystemArchitecture()
+	switch HostSystemArch {
+	case "arm64", "armhf", "arm":
+		if bits == "64" {
+			return "arm64"
+		}
+		return "armhf"
+	case "amd64", "i386":
+		if bits == "64" {
+			return "amd64"
+		}
+		return "i386"
+	}
+	return HostSystemArch
+}
+
+// isRaspberryPi reports whether this device is a Raspberry Pi
+func isRaspberryPi() bool {
+	return strings.Contains(strings.ToLower(deviceModel()), "raspberry pi")
+}
+
+// matchesOS reports whether one of the OS names in a requirement matches this system's OS ID or codename
+func matchesOS(names []string) bool {
+	for _, name := range names {
+		if strings.EqualFold(name, ID) || strings.EqualFold(name, HostSystemID) || strings.EqualFold(name, VERSION_CODENAME) {
+			return true
+		}
+	}
+	return false
+}
+
+// requirementsUnavailableReason returns why the requirements of an app exclude this system, "" if they don't
+func requirementsUnavailableReason(requirements AppRequirements) string {
+	if len(requirements.Arch) > 0 && !slices.ContainsFunc(requirements.Arch, func(arch string) bool {
+		return strings.EqualFold(arch, userlandArch())
+	}) {
+		return Tf("only available for %s", strings.Join(requirements.Arch, ", "))
+	}
+	if len(requirements.OS) > 0 && !matchesOS(requirements.OS) {
+		return Tf("only available on %s", strings.Join(requirements.OS, ", "))
+	}
+	if len(requirements.NotOS) > 0 && matchesOS(requirements.NotOS) {
+		return Tf("not available on %s", ID)
+	}
+	if requirements.RequiresRaspberryPi && !isRaspberryPi() {
+		return T("requires a Raspberry Pi")
+	}
+	return ""
+}
+
+// readUnavailableApps reads the apps the shipped blacklist excludes on this device
+func readUnavailableApps(directory string) map[string]bool {
+	apps := make(map[string]bool)
+	file, err := os.Open(filepath.Join(directory, "etc", unavailableAppsFile))
+	if err != nil {
+		return apps
+	}
+	defer file.Close()
+
+	model := strings.ToLower(deviceModel())
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		pattern, app, ok := strings.Cut(line, "\t")
+		if !ok {
+			continue
+		}
+		if matched, err := filepath.Match(strings.ToLower(strings.TrimSpace(pattern)), model); err == nil && matched {
+			apps[strings.TrimSpace(app)] = true
+		}
+	}
+	return apps
+}
+
+// UnavailableApps returns the local apps that can't be installed on this system, with the reason for each.
+// An app is unavailable if it has no install script or package for the userland architecture, if its
+// requirements file excludes this OS or device, or if etc/unavailable-apps lists it for this device.
+//
+// The apps stay in the apps folder and keep getting updated, so they show up as soon as the system changes.
+func UnavailableApps() (map[string]string, error) {
+	directory := GetPiAppsDir()
+	if directory == "" {
+		return nil, fmt.Errorf("PI_APPS_DIR environment variable not set")
+	}
+	apps, err := listLocalApps(directory)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list local apps: %w", err)
+	}
+	installable, err := getCPUInstallableApps(directory)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get installable apps: %w", err)
+	}
+	blacklisted := readUnavailableApps(directory)
+
+	unavailable := make(map[string]string)
+	for _, app := range apps {
+		if reason := appUnavailableReason(app, installable, blacklisted); reason != "" {
+			unavailable[app] = reason
+		}
+	}
+	return unavailable, nil
+}
+
+// AppUnavailableReason returns why an app can't be installed on this system, "" if it can
+func AppUnavailableReason(app string) string {
+	directory := GetPiAppsDir()
+	installable, err := getCPUInstallableApps(directory)
+	if err != nil {
+		return ""
+	}
+	return appUnavailableReason(app, installable, readUnavailableApps(directory))
+}
+
+// appUnavailableReason checks one app against the installable apps and the blacklist read for all apps
+func appUnavailableReason(app string, installable []string, blacklisted map[string]bool) string {
+	if !slices.Contains(installable, app) {
+		return Tf("no install script for %s", userlandArch())
+	}
+	if requirements, err := ReadAppRequirements(app); err == nil {
+		if reason := requirementsUnavailableReason(requirements); reason != "" {
+			return reason
+		}
+	}
+	if blacklisted[app] {
+		return Tf("not available on %s", deviceModel())
+	}
+	return ""
+}
+
+// ShowUnavailableApps reports whether the app list shows unavailable apps with a badge instead of hiding them
+func ShowUnavailableApps() bool {
+	return ReadSetting(unavailableAppsSetting) == "Show"
+}
+
+// SetShowUnavailableApps saves whether the app list shows unavailable apps
+func SetShowUnavailableApps(show bool) error {
+	value := "Hide"
+	if show {
+		value = "Show"
+	}
+	return WriteSetting(unavailableAppsSetting, value)
+}
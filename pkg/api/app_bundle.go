@@ -0,0 +1,617 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: app_bundle.go
+// Description: Exports an app with everything its install downloads into a bundle, and installs apps from such bundles without network access.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package api
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+)
+
+// appBundleFormat is the version of the bundle layout written by ExportAppBundle
+const appBundleFormat = 1
+
+// BundleDirEnv is set to the unpacked bundle while InstallAppBundle installs an app. wget, git_clone and
+// install_packages take the files recorded in the bundle from there, and install scripts can check it to
+// know their downloads are pre-staged in $PI_APPS_BUNDLE_DIR/assets.
+const BundleDirEnv = "PI_APPS_BUNDLE_DIR"
+
+// Folders of an unpacked bundle
+const (
+	bundleManifestFile = "manifest.json"
+	bundleAppDir       = "app"     // the app folder
+	bundlePoolDir      = "pool"    // the packages the app needs, with all their dependencies
+	bundleAssetsDir    = "assets"  // the files and repositories the install script downloads
+	bundleFlatpakDir   = "flatpak" // flatpak bundles of the app and its runtime
+)
+
+// BundleManifest describes the content of an app bundle
+type BundleManifest struct {
+	Format   int               `json:"format"`
+	App      string            `json:"app"`
+	AppType  string            `json:"app_type"`
+	Arch     string            `json:"arch"`
+	Packager string            `json:"package_manager"`
+	Created  time.Time         `json:"created"`
+	Packages []string          `json:"packages,omitempty"` // packages the app installs, their dependencies are in the pool too
+	Assets   []BundleAsset     `json:"assets,omitempty"`
+	Flatpaks []BundleFlatpak   `json:"flatpaks,omitempty"`
+	Files    map[string]string `json:"files"` // sha256 of every file of the bundle, by its path in the bundle
+}
+
+// BundleAsset is a download of the install script recorded in the download ledger
+type BundleAsset struct {
+	URL  string `json:"url"`
+	File string `json:"file"`          // path in the bundle
+	Git  bool   `json:"git,omitempty"` // a bare clone of a git repository instead of a file
+}
+
+// BundleFlatpak is a flatpak bundle of an app's flatpak or of the runtime it needs
+type BundleFlatpak struct {
+	Ref     string `json:"ref"`
+	File    string `json:"file"`
+	Runtime bool   `json:"runtime,omitempty"`
+}
+
+// commandStart matches where a command can start in a line of shell script
+const commandStart = "(^|[\\s;&|(`])"
+
+// uncacheablePatterns match install script lines that reach the network without going through wget or
+// git_clone, so the download ledger doesn't know what they fetch and a bundle can't replay it
+var uncacheablePatterns = []*regexp.Regexp{
+	regexp.MustCompile(commandStart + `curl\s`),
+	regexp.MustCompile(commandStart + `wget\s.*(-O\s*-|-qO-|--output-document=-)(\s|$)`),
+	regexp.MustCompile(commandStart + `(command\s+)?git\s+(clone|pull|fetch|submodule)\b`),
+	regexp.MustCompile(commandStart + `(pip3?|pipx|python3?\s+-m\s+pip)\s+install\b`),
+	regexp.MustCompile(commandStart + `(npm|yarn|pnpm)\s+(install|i|add|ci)\b`),
+	regexp.MustCompile(commandStart + `(cargo|go)\s+(install|build|get|fetch)\b`),
+	regexp.MustCompile(commandStart + `(flatpak|snap|docker|podman)\s+(install|pull)\b`),
+	regexp.MustCompile(commandStart + `(apt|apt-get)\s+(update|install|download)\b`),
+	regexp.MustCompile(commandStart + `(add_external_repo|ubuntu_ppa_installer|debian_ppa_installer|adoptium_installer|rustup)\b`),
+}
+
+// ledgerPatterns match install script lines that download through wget or git_clone, which the ledger records
+var ledgerPatterns = []*regexp.Regexp{
+	regexp.MustCompile(commandStart + `(wget|git_clone)\s`),
+	regexp.MustCompile(commandStart + `install_packages\s.*://`),
+}
+
+// scanInstallScript returns the lines of an install script that reach the network in a way a bundle can't
+// replay, and whether the script downloads anything through wget or git_clone
+func scanInstallScript(path string) ([]string, bool, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, false, err
+	}
+	defer file.Close()
+
+	var uncacheable []string
+	downloads := false
+	scanner := bufio.NewScanner(file)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		for _, pattern := range uncacheablePatterns {
+			if pattern.MatchString(line) {
+				uncacheable = append(uncacheable, fmt.Sprintf("%d: %s", lineNumber, line))
+				break
+			}
+		}
+		for _, pattern := range ledgerPatterns {
+			if pattern.MatchString(line) {
+				downloads = true
+			}
+		}
+	}
+	return uncacheable, downloads, scanner.Err()
+}
+
+// bundleLedgerAssets returns the downloads of the last install of an app, one per URL, and fails if the
+// install script downloads things the ledger can't account for
+func bundleLedgerAssets(app string) ([]LedgerEntry, error) {
+	scriptName := GetScriptNameForCPU(app)
+	if scriptName == "" {
+		return nil, fmt.Errorf("no suitable install script found for %s", app)
+	}
+	uncacheable, downloads, err := scanInstallScript(filepath.Join(GetPiAppsDir(), "apps", app, scriptName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the install script of %s: %w", app, err)
+	}
+	if len(uncacheable) > 0 {
+		return nil, fmt.Errorf("the %s app can't be bundled: its install script reaches the network without wget or git_clone, so the download ledger doesn't cover it:\n%s", app, strings.Join(uncacheable, "\n"))
+	}
+
+	FlushDownloadLedger()
+	entries, err := DownloadLedger(DownloadLedgerFilter{App: app})
+	if err != nil {
+		return nil, err
+	}
+	if downloads && len(entries) == 0 {
+		return nil, fmt.Errorf("the download ledger has no downloads of the %s app: install it once with the download ledger enabled, then export it again", app)
+	}
+
+	// The last download of a URL is the one the current install script uses
+	latest := make(map[string]int)
+	var assets []LedgerEntry
+	for _, entry := range entries {
+		if index, ok := latest[entry.URL]; ok {
+			assets[index] = entry
+			continue
+		}
+		latest[entry.URL] = len(assets)
+		assets = append(assets, entry)
+	}
+	return assets, nil
+}
+
+// downloadBundleAsset downloads a file of the ledger into the bundle, taking it from the asset cache when the cache
+// has it. The ledger isn't written, the download was recorded when the app was installed.
+func downloadBundleAsset(entry LedgerEntry, destination string) error {
+	if entry.Commit != "" {
+		cmd := exec.Command("git", "clone", "--quiet", "--bare", entry.URL, destination)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("failed to clone %s: %w\n%s", entry.URL, err, output)
+		}
+		return nil
+	}
+
+	// The asset cache has the file the app was installed with, if it wasn't evicted
+	if cached, _, ok := cachedAsset(entry.SHA256); ok {
+		return placeAsset(cached, destination)
+	}
+
+	resp, err := http.Get(entry.URL)
+	if err != nil {
+		return fmt.Errorf("failed to download %s: %w", entry.URL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to download %s: HTTP %d", entry.URL, resp.StatusCode)
+	}
+	file, err := os.Create(destination)
+	if err != nil {
+		return err
+	}
+	hash := sha256.New()
+	_, err = io.Copy(io.MultiWriter(file, hash), resp.Body)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to download %s: %w", entry.URL, err)
+	}
+	if sum := hex.EncodeToString(hash.Sum(nil)); entry.SHA256 != "" && sum != entry.SHA256 {
+		WarningTf("%s changed since the app was installed, bundling the current version", entry.URL)
+	}
+	return nil
+}
+
+// flatpakInstallationRepo returns the repository of the flatpak installation an app or runtime is installed in
+func flatpakInstallationRepo(ref string) (string, error) {
+	if exec.Command("flatpak", "info", "--system", ref).Run() == nil {
+		return "/var/lib/flatpak/repo", nil
+	}
+	if exec.Command("flatpak", "info", "--user", ref).Run() == nil {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(home, ".local", "share", "flatpak", "repo"), nil
+	}
+	return "", fmt.Errorf("flatpak %s is not installed", ref)
+}
+
+// buildFlatpakBundle writes a flatpak bundle of an installed app, or of a runtime given as id/arch/branch
+func buildFlatpakBundle(ref, destination string, isRuntime bool) error {
+	parts := strings.Split(ref, "/")
+	repo, err := flatpakInstallationRepo(parts[0])
+	if err != nil {
+		return err
+	}
+	args := []string{"build-bundle"}
+	if isRuntime {
+		args = append(args, "--runtime")
+	}
+	if len(parts) == 3 {
+		args = append(args, "--arch="+parts[1])
+	}
+	args = append(args, repo, destination, parts[0])
+	if len(parts) == 3 {
+		args = append(args, parts[2])
+	}
+	if output, err := exec.Command("flatpak", args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to bundle the %s flatpak: %w\n%s", ref, err, output)
+	}
+	return nil
+}
+
+// bundleFlatpaks bundles the flatpaks of a flatpak app and the runtimes they need
+func bundleFlatpaks(app, staging string) ([]BundleFlatpak, error) {
+	data, err := os.ReadFile(filepath.Join(GetPiAppsDir(), "apps", app, "flatpak_packages"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read flatpak packages list: %w", err)
+	}
+	if _, err := exec.LookPath("flatpak"); err != nil {
+		return nil, fmt.Errorf("flatpak is not installed")
+	}
+	if err := os.MkdirAll(filepath.Join(staging, bundleFlatpakDir), 0755); err != nil {
+		return nil, err
+	}
+
+	var flatpaks []BundleFlatpak
+	for _, id := range strings.Fields(string(data)) {
+		output, err := exec.Command("flatpak", "info", "--show-runtime", id).Output()
+		if err != nil {
+			return nil, fmt.Errorf("flatpak %s is not installed, install the %s app before exporting it", id, app)
+		}
+		runtimeRef := strings.TrimSpace(string(output))
+		if runtimeRef != "" && !slices.ContainsFunc(flatpaks, func(f BundleFlatpak) bool { return f.Ref == runtimeRef }) {
+			file := filepath.Join(bundleFlatpakDir, strings.ReplaceAll(runtimeRef, "/", "_")+".flatpak")
+			if err := buildFlatpakBundle(runtimeRef, filepath.Join(staging, file), true); err != nil {
+				return nil, err
+			}
+			flatpaks = append(flatpaks, BundleFlatpak{Ref: runtimeRef, File: file, Runtime: true})
+		}
+		file := filepath.Join(bundleFlatpakDir, id+".flatpak")
+		if err := buildFlatpakBundle(id, filepath.Join(staging, file), false); err != nil {
+			return nil, err
+		}
+		flatpaks = append(flatpaks, BundleFlatpak{Ref: id, File: file})
+	}
+	return flatpaks, nil
+}
+
+// hashBundleFiles returns the sha256 of every file of an unpacked bundle except the manifest
+func hashBundleFiles(dir string) (map[string]string, error) {
+	files := make(map[string]string)
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		if rel == bundleManifestFile {
+			return nil
+		}
+		_, sum := hashFile(path)
+		if sum == "" {
+			return fmt.Errorf("failed to read %s", rel)
+		}
+		files[filepath.ToSlash(rel)] = sum
+		return nil
+	})
+	return files, err
+}
+
+// checkBundleTools fails if tar or zstd, which pack and unpack bundles, are missing
+func checkBundleTools() error {
+	for _, tool := range []string{"tar", "zstd"} {
+		if _, err := exec.LookPath(tool); err != nil {
+			return fmt.Errorf("%s is needed for app bundles but is not installed", tool)
+		}
+	}
+	return nil
+}
+
+// ExportAppBundle packs an app into a tar.zst bundle that InstallAppBundle installs without network access.
+// The bundle has the app folder, the packages it installs with all their dependencies, its flatpaks and
+// every file its install script downloaded according to the download ledger, with a manifest of checksums.
+// Apps whose install script downloads things the ledger doesn't record can't be bundled.
+func ExportAppBundle(app, outPath string) error {
+	directory := GetPiAppsDir()
+	if directory == "" {
+		return fmt.Errorf("PI_APPS_DIR environment variable not set")
+	}
+	if err := ValidateAppName(app); err != nil {
+		return err
+	}
+	if !DirExists(filepath.Join(directory, "apps", app)) {
+		return fmt.Errorf("app %s does not exist", app)
+	}
+	if err := checkBundleTools(); err != nil {
+		return err
+	}
+	appType, err := AppType(app)
+	if err != nil {
+		return err
+	}
+
+	// Check what the script downloads before spending time on the packages
+	var ledgerAssets []LedgerEntry
+	if appType == "standard" {
+		ledgerAssets, err = bundleLedgerAssets(app)
+		if err != nil {
+			return err
+		}
+	}
+
+	staging, err := os.MkdirTemp("", "pi-apps-bundle-")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(staging)
+
+	manifest := BundleManifest{
+		Format:   appBundleFormat,
+		App:      app,
+		AppType:  appType,
+		Arch:     runtime.GOARCH,
+		Packager: PackageManager,
+		Created:  time.Now().UTC(),
+	}
+
+	StatusTf("Copying the %s app folder...", app)
+	if err := copyTreeVerified(filepath.Join(directory, "apps", app), filepath.Join(staging, bundleAppDir)); err != nil {
+		return err
+	}
+
+	if appType == "flatpak_package" {
+		StatusT("Bundling flatpaks...")
+		if manifest.Flatpaks, err = bundleFlatpaks(app, staging); err != nil {
+			return err
+		}
+	} else {
+		StatusT("Downloading packages and their dependencies...")
+		if manifest.Packages, err = bundleAppPackages(app, appType, filepath.Join(staging, bundlePoolDir)); err != nil {
+			return err
+		}
+	}
+
+	if len(ledgerAssets) > 0 {
+		if err := os.MkdirAll(filepath.Join(staging, bundleAssetsDir), 0755); err != nil {
+			return err
+		}
+	}
+	for i, entry := range ledgerAssets {
+		StatusTf("Downloading %s", entry.URL)
+		name := fmt.Sprintf("%03d-%s", i+1, filepath.Base(entry.Destination))
+		if entry.Commit != "" {
+			name += ".git"
+		}
+		file := filepath.Join(bundleAssetsDir, name)
+		if err := downloadBundleAsset(entry, filepath.Join(staging, file)); err != nil {
+			return err
+		}
+		manifest.Assets = append(manifest.Assets, BundleAsset{URL: entry.URL, File: filepath.ToSlash(file), Git: entry.Commit != ""})
+	}
+
+	if manifest.Files, err = hashBundleFiles(staging); err != nil {
+		return fmt.Errorf("failed to hash the bundle: %w", err)
+	}
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(staging, bundleManifestFile), data, 0644); err != nil {
+		return err
+	}
+
+	StatusTf("Packing %s...", outPath)
+	tmp := outPath + ".tmp"
+	if output, err := exec.Command("tar", "--zstd", "-cf", tmp, "-C", staging, ".").CombinedOutput(); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to pack the bundle: %w\n%s", err, output)
+	}
+	if err := os.Rename(tmp, outPath); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	StatusGreenTf("Exported %s to %s", app, outPath)
+	return nil
+}
+
+// readBundleManifest reads the manifest of an unpacked bundle
+func readBundleManifest(dir string) (BundleManifest, error) {
+	var manifest BundleManifest
+	data, err := os.ReadFile(filepath.Join(dir, bundleManifestFile))
+	if err != nil {
+		return manifest, fmt.Errorf("not an app bundle: %w", err)
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return manifest, fmt.Errorf("the bundle manifest is damaged: %w", err)
+	}
+	return manifest, nil
+}
+
+// verifyBundle checks the files of an unpacked bundle against the checksums of its manifest
+func verifyBundle(dir string, manifest BundleManifest) error {
+	files, err := hashBundleFiles(dir)
+	if err != nil {
+		return fmt.Errorf("failed to hash the bundle: %w", err)
+	}
+	for path, sum := range manifest.Files {
+		actual, ok := files[path]
+		if !ok {
+			return fmt.Errorf("the bundle is incomplete, %s is missing", path)
+		}
+		if actual != sum {
+			return fmt.Errorf("the bundle is damaged, the checksum of %s doesn't match", path)
+		}
+	}
+	for path := range files {
+		if _, ok := manifest.Files[path]; !ok {
+			return fmt.Errorf("the bundle has a file its manifest doesn't list: %s", path)
+		}
+	}
+	return nil
+}
+
+// stagedAsset returns the copy of a download in the bundle InstallAppBundle is installing from, if any
+func stagedAsset(url string) (string, bool) {
+	dir := os.Getenv(BundleDirEnv)
+	if dir == "" {
+		return "", false
+	}
+	manifest, err := readBundleManifest(dir)
+	if err != nil {
+		return "", false
+	}
+	for _, asset := range manifest.Assets {
+		if asset.URL == url {
+			return filepath.Join(dir, filepath.FromSlash(asset.File)), true
+		}
+	}
+	Debug("The bundle has no copy of " + url)
+	return "", false
+}
+
+// copyStagedAsset copies a download from the bundle to where it was asked for, or to stdout
+func copyStagedAsset(staged, destination string) error {
+	input, err := os.Open(staged)
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+	var output io.Writer = os.Stdout
+	if destination != "" {
+		file, err := os.Create(destination)
+		if err != nil {
+			return fmt.Errorf("failed to create output file: %w", err)
+		}
+		defer file.Close()
+		output = file
+	}
+	_, err = io.Copy(output, input)
+	return err
+}
+
+// bundleStaged reports whether an app is being installed from a bundle, so nothing is downloaded
+func bundleStaged() bool {
+	return os.Getenv(BundleDirEnv) != ""
+}
+
+// installBundledFlatpaks installs the flatpak bundles of a bundle, runtimes first
+func installBundledFlatpaks(dir string, manifest BundleManifest) error {
+	if _, err := exec.LookPath("flatpak"); err != nil {
+		return fmt.Errorf("flatpak is not installed")
+	}
+	flatpaks := slices.Clone(manifest.Flatpaks)
+	slices.SortStableFunc(flatpaks, func(a, b BundleFlatpak) int {
+		switch {
+		case a.Runtime == b.Runtime:
+			return 0
+		case a.Runtime:
+			return -1
+		}
+		return 1
+	})
+	for _, flatpak := range flatpaks {
+		if flatpak.Runtime && exec.Command("flatpak", "info", flatpak.Ref).Run() == nil {
+			continue
+		}
+		StatusTf("Installing %s...", flatpak.Ref)
+		if err := execCommand("sudo", "flatpak", "install", "-y", "--noninteractive", "--bundle", filepath.Join(dir, filepath.FromSlash(flatpak.File))); err != nil {
+			return fmt.Errorf("flatpak failed to install %s: %w", flatpak.Ref, err)
+		}
+	}
+	return nil
+}
+
+// InstallAppBundle installs an app from a bundle made by ExportAppBundle without network access. The checksums
+// of the bundle are verified, the bundled app folder replaces the app's folder, and the install script runs
+// with $PI_APPS_BUNDLE_DIR set so its downloads and packages come from the bundle.
+func InstallAppBundle(path string) error {
+	directory := GetPiAppsDir()
+	if directory == "" {
+		return fmt.Errorf("PI_APPS_DIR environment variable not set")
+	}
+	if err := checkBundleTools(); err != nil {
+		return err
+	}
+
+	dir, err := os.MkdirTemp("", "pi-apps-bundle-")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+	// The install script doesn't run as root, but apt reads the pool as the _apt user
+	if err := os.Chmod(dir, 0755); err != nil {
+		return err
+	}
+
+	StatusTf("Unpacking %s...", path)
+	if output, err := exec.Command("tar", "--zstd", "-xf", path, "-C", dir).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to unpack the bundle: %w\n%s", err, output)
+	}
+	manifest, err := readBundleManifest(dir)
+	if err != nil {
+		return err
+	}
+	if manifest.Format > appBundleFormat {
+		return fmt.Errorf("the bundle has format %d, this version of Pi-Apps reads up to format %d", manifest.Format, appBundleFormat)
+	}
+	if err := ValidateAppName(manifest.App); err != nil {
+		return err
+	}
+	if manifest.Arch != runtime.GOARCH {
+		return fmt.Errorf("the bundle is for %s, this device is %s", manifest.Arch, runtime.GOARCH)
+	}
+	if len(manifest.Packages) > 0 && manifest.Packager != PackageManager {
+		return fmt.Errorf("the bundle has %s packages, this device uses %s", manifest.Packager, PackageManager)
+	}
+	StatusT("Verifying checksums...")
+	if err := verifyBundle(dir, manifest); err != nil {
+		return err
+	}
+
+	app := manifest.App
+	if IsAppInstalled(app) {
+		return fmt.Errorf("app '%s' is already installed", app)
+	}
+	appDir := filepath.Join(directory, "apps", app)
+	if err := os.RemoveAll(appDir); err != nil {
+		return fmt.Errorf("failed to replace the %s app folder: %w", app, err)
+	}
+	if err := copyTreeVerified(filepath.Join(dir, bundleAppDir), appDir); err != nil {
+		return err
+	}
+
+	os.Setenv(BundleDirEnv, dir)
+	defer os.Unsetenv(BundleDirEnv)
+
+	if manifest.AppType == "flatpak_package" {
+		if err := installBundledFlatpaks(dir, manifest); err != nil {
+			return err
+		}
+		return RefreshFlatpakAppStatus(app)
+	}
+	return ManageApp(ActionInstall, app, false)
+}
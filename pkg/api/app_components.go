@@ -0,0 +1,275 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: app_components.go
+// Description: Reads the optional components apps declare in their components file, and asks which of them to
+// install before the install script runs.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package api
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/pi-apps-go/pi-apps/pkg/api/errs"
+)
+
+// componentEnvPrefix starts the environment variables telling the install script which components were chosen
+const componentEnvPrefix = "PI_APPS_COMPONENT_"
+
+// componentIDPattern matches the component ids that are safe to use in the name of an environment variable
+var componentIDPattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
+
+// AppComponent is an optional part of an app that users choose to install or not, declared in
+// apps/<app>/components as one "<id>|<label>|<on|off>|<size>" line per component, the size being optional
+type AppComponent struct {
+	ID      string `json:"id"`
+	Label   string `json:"label"`
+	Default bool   `json:"default"` // chosen unless the user unchecks it
+	Size    string `json:"size,omitempty"`
+}
+
+// InstalledComponent is a component with whether it was chosen, as recorded in the install manifest
+type InstalledComponent struct {
+	ID       string `json:"id"`
+	Selected bool   `json:"selected"`
+}
+
+// parseAppComponents parses the content of a components file. Invalid lines are reported together, the valid
+// components are returned along with the error.
+func parseAppComponents(content string) ([]AppComponent, error) {
+	var components []AppComponent
+	var problems []error
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Split(text, "|")
+		for i := range fields {
+			fields[i] = strings.TrimSpace(fields[i])
+		}
+		if len(fields) < 3 || len(fields) > 4 {
+			problems = append(problems, fmt.Errorf("components line %d: expected <id>|<label>|<on|off>|<size>", line))
+			continue
+		}
+		component := AppComponent{ID: fields[0], Label: fields[1]}
+		if len(fields) == 4 {
+			component.Size = fields[3]
+		}
+		if !componentIDPattern.MatchString(component.ID) {
+			problems = append(problems, fmt.Errorf("components line %d: id %q must start with a letter and only contain letters, digits and _", line, component.ID))
+			continue
+		}
+		// The ids are upper-cased in the environment variable names, so foo and FOO would be the same variable
+		if slices.ContainsFunc(components, func(other AppComponent) bool { return strings.EqualFold(other.ID, component.ID) }) {
+			problems = append(problems, fmt.Errorf("components line %d: duplicate id %q", line, component.ID))
+			continue
+		}
+		if component.Label == "" {
+			problems = append(problems, fmt.Errorf("components line %d: %s has no label", line, component.ID))
+			continue
+		}
+		switch strings.ToLower(fields[2]) {
+		case "on":
+			component.Default = true
+		case "off":
+		default:
+			problems = append(problems, fmt.Errorf("components line %d: default of %s must be on or off, not %q", line, component.ID, fields[2]))
+			continue
+		}
+		components = append(components, component)
+	}
+	return components, errors.Join(problems...)
+}
+
+// validateComponentsFile checks the components file of an app folder, if it has one
+func validateComponentsFile(appDir string) error {
+	content, err := os.ReadFile(filepath.Join(appDir, "components"))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	_, err = parseAppComponents(string(content))
+	return err
+}
+
+// ReadAppComponents returns the optional components an app declares in apps/<app>/components. An app without the
+// file has none.
+//
+//	[]AppComponent - the valid components in the order of the file
+//	error - error if the app name is not valid, the file can't be read or has invalid lines, the valid components
+//	are returned along with it
+func ReadAppComponents(app string) ([]AppComponent, error) {
+	path, err := AppPath(app, "components")
+	if err != nil {
+		return nil, err
+	}
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return parseAppComponents(string(content))
+}
+
+// ReadInstalledComponents returns the components recorded in an app's install manifest, with whether they were
+// chosen, nil if no components were recorded
+func ReadInstalledComponents(app string) ([]InstalledComponent, error) {
+	lines, err := readInstallManifest(app)
+	if err != nil {
+		return nil, err
+	}
+	for _, line := range lines {
+		if recorded, ok := strings.CutPrefix(line, installComponentsPrefix); ok {
+			return parseInstalledComponents(recorded), nil
+		}
+	}
+	return nil, nil
+}
+
+// parseInstalledComponents parses the "id=1,id=0" list of the components line of an install manifest
+func parseInstalledComponents(recorded string) []InstalledComponent {
+	var components []InstalledComponent
+	for _, field := range strings.Split(recorded, ",") {
+		id, selected, ok := strings.Cut(strings.TrimSpace(field), "=")
+		if ok && componentIDPattern.MatchString(id) {
+			components = append(components, InstalledComponent{ID: id, Selected: selected == "1"})
+		}
+	}
+	return components
+}
+
+// formatInstalledComponents formats components for the components line of an install manifest
+func formatInstalledComponents(components []InstalledComponent) string {
+	fields := make([]string, len(components))
+	for i, component := range components {
+		selected := "0"
+		if component.Selected {
+			selected = "1"
+		}
+		fields[i] = component.ID + "=" + selected
+	}
+	return strings.Join(fields, ",")
+}
+
+// setInstalledComponents records the chosen components in an app's install manifest, removing the record if
+// the app has no components
+func setInstalledComponents(app string, components []InstalledComponent) error {
+	lines, err := readInstallManifest(app)
+	if err != nil {
+		return err
+	}
+	var updated []string
+	if len(components) > 0 {
+		updated = append(updated, installComponentsPrefix+formatInstalledComponents(components))
+	}
+	for _, line := range lines {
+		if !strings.HasPrefix(line, installComponentsPrefix) {
+			updated = append(updated, line)
+		}
+	}
+	return writeInstallManifest(app, updated)
+}
+
+// sameComponents reports whether the recorded components are the ones an app declares now
+func sameComponents(declared []AppComponent, recorded []InstalledComponent) bool {
+	if len(declared) != len(recorded) {
+		return false
+	}
+	for _, component := range declared {
+		if !slices.ContainsFunc(recorded, func(other InstalledComponent) bool { return other.ID == component.ID }) {
+			return false
+		}
+	}
+	return true
+}
+
+// chooseAppComponents decides which optional components of an app to install and records them in its install
+// manifest. Updates reuse the previous choice unless the app's components changed, otherwise the user is asked,
+// with the previous choice or the declared defaults checked. Without a display or a terminal to ask on, those
+// are used as they are.
+func chooseAppComponents(app string, isUpdate bool) ([]InstalledComponent, error) {
+	declared, err := ReadAppComponents(app)
+	if err != nil {
+		WarningTf("Ignoring invalid lines of the components file of %s: %v", app, err)
+	}
+	if len(declared) == 0 {
+		return nil, setInstalledComponents(app, nil)
+	}
+	recorded, err := ReadInstalledComponents(app)
+	if err != nil {
+		return nil, err
+	}
+
+	chosen := make([]InstalledComponent, len(declared))
+	for i, component := range declared {
+		chosen[i] = InstalledComponent{ID: component.ID, Selected: component.Default}
+		if index := slices.IndexFunc(recorded, func(other InstalledComponent) bool { return other.ID == component.ID }); index != -1 {
+			chosen[i].Selected = recorded[index].Selected
+		}
+	}
+
+	if !(isUpdate && sameComponents(declared, recorded)) && (canUseGTK() || stdinIsTerminal()) {
+		labels := make([]string, len(declared))
+		defaults := make([]bool, len(declared))
+		for i, component := range declared {
+			labels[i] = component.Label
+			if component.Size != "" {
+				labels[i] += " (" + component.Size + ")"
+			}
+			defaults[i] = chosen[i].Selected
+		}
+		selected, err := UserMultiSelect(Tf("%s has optional components. Choose the ones to install:", app), labels, defaults)
+		if errors.Is(err, errs.ErrCancelled) {
+			return nil, errs.New(errs.ErrCancelled, "the installation of %s was cancelled", app)
+		}
+		if err != nil {
+			WarningTf("Failed to ask which components of %s to install, installing the default ones: %v", app, err)
+			selected = defaults
+		}
+		for i := range chosen {
+			chosen[i].Selected = selected[i]
+		}
+	}
+	return chosen, setInstalledComponents(app, chosen)
+}
+
+// withComponentEnv returns env with a PI_APPS_COMPONENT_<ID>=1 variable for every chosen component, and without
+// the component variables inherited from the environment of Pi-Apps
+func withComponentEnv(env []string, components []InstalledComponent) []string {
+	env = slices.DeleteFunc(slices.Clone(env), func(variable string) bool {
+		return strings.HasPrefix(variable, componentEnvPrefix)
+	})
+	for _, component := range components {
+		if component.Selected {
+			env = append(env, componentEnvPrefix+strings.ToUpper(component.ID)+"=1")
+		}
+	}
+	return env
+}
@@ -0,0 +1,129 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseAppComponents(t *testing.T) {
+	components, err := parseAppComponents("# Extras of the IDE\nexamples | Sample projects | on | 120 MB\nlocales|Extra languages|off\n\n" +
+		"bad-id|Dashes|on\nEXAMPLES|Duplicate|off\nmodels|No default\nmodels|Models|maybe\n9lives|Digit first|on\nnolabel||on\n")
+	want := []AppComponent{
+		{ID: "examples", Label: "Sample projects", Default: true, Size: "120 MB"},
+		{ID: "locales", Label: "Extra languages"},
+	}
+	if !slices.Equal(components, want) {
+		t.Errorf("parseAppComponents = %+v, want %+v", components, want)
+	}
+	for _, line := range []string{"line 5", "line 6", "line 7", "line 8", "line 9", "line 10"} {
+		if err == nil || !strings.Contains(err.Error(), line) {
+			t.Errorf("parseAppComponents error = %v, want it to report %s", err, line)
+		}
+	}
+}
+
+func TestValidateAppComponents(t *testing.T) {
+	directory := newTestPiAppsDir(t, "IDE", "Plain")
+	if err := ValidateApp("Plain"); err != nil {
+		t.Errorf("ValidateApp(Plain) = %v, want no error without a components file", err)
+	}
+	writeTestFile(t, filepath.Join(directory, "apps", "IDE", "components"), "examples|Samples|on\nexamples|Samples again|off\n")
+	if err := ValidateApp("IDE"); err == nil || !strings.Contains(err.Error(), "duplicate") {
+		t.Errorf("ValidateApp(IDE) = %v, want the duplicate id", err)
+	}
+}
+
+func TestWithComponentEnv(t *testing.T) {
+	env := withComponentEnv([]string{"HOME=/home/pi", "PI_APPS_COMPONENT_STALE=1"}, []InstalledComponent{
+		{ID: "examples", Selected: true},
+		{ID: "locales"},
+		{ID: "ml_models", Selected: true},
+	})
+	want := []string{"HOME=/home/pi", "PI_APPS_COMPONENT_EXAMPLES=1", "PI_APPS_COMPONENT_ML_MODELS=1"}
+	if !slices.Equal(env, want) {
+		t.Errorf("withComponentEnv = %q, want %q", env, want)
+	}
+}
+
+func TestChooseAppComponents(t *testing.T) {
+	directory := newTestPiAppsDir(t, "IDE")
+	t.Setenv("DISPLAY", "")
+	t.Setenv("WAYLAND_DISPLAY", "")
+	components := filepath.Join(directory, "apps", "IDE", "components")
+	writeTestFile(t, components, "examples|Samples|on\nlocales|Languages|off\n")
+
+	// Without a display or a terminal to ask on, the defaults are used and recorded
+	chosen, err := chooseAppComponents("IDE", false)
+	want := []InstalledComponent{{ID: "examples", Selected: true}, {ID: "locales"}}
+	if err != nil || !slices.Equal(chosen, want) {
+		t.Fatalf("chooseAppComponents = %+v, %v, want %+v", chosen, err, want)
+	}
+
+	// Updates reuse the recorded choice, not the defaults
+	if err := setInstalledComponents("IDE", []InstalledComponent{{ID: "examples"}, {ID: "locales", Selected: true}}); err != nil {
+		t.Fatal(err)
+	}
+	chosen, err = chooseAppComponents("IDE", true)
+	want = []InstalledComponent{{ID: "examples"}, {ID: "locales", Selected: true}}
+	if err != nil || !slices.Equal(chosen, want) {
+		t.Errorf("chooseAppComponents on update = %+v, %v, want %+v", chosen, err, want)
+	}
+
+	// The install manifest keeps the choice when the installed files are written, and after an uninstall
+	if err := writeInstalledFiles("IDE", []string{"/opt/ide"}, ResourceLimits{}, false); err != nil {
+		t.Fatal(err)
+	}
+	manifest, err := ReadInstallManifest("IDE")
+	if err != nil || !slices.Equal(manifest.Components, want) || !slices.Equal(manifest.Files, []string{"/opt/ide"}) {
+		t.Errorf("ReadInstallManifest = %+v, %v, want the components and /opt/ide", manifest, err)
+	}
+	if err := clearInstallManifest("IDE"); err != nil {
+		t.Fatal(err)
+	}
+	if recorded, err := ReadInstalledComponents("IDE"); err != nil || !slices.Equal(recorded, want) {
+		t.Errorf("ReadInstalledComponents after an uninstall = %+v, %v, want %+v", recorded, err, want)
+	}
+
+	// A new component keeps the recorded choice of the others and gets its default
+	writeTestFile(t, components, "examples|Samples|on\nlocales|Languages|off\nmodels|Models|on\n")
+	chosen, err = chooseAppComponents("IDE", true)
+	want = []InstalledComponent{{ID: "examples"}, {ID: "locales", Selected: true}, {ID: "models", Selected: true}}
+	if err != nil || !slices.Equal(chosen, want) {
+		t.Errorf("chooseAppComponents with a new component = %+v, %v, want %+v", chosen, err, want)
+	}
+}
+
+func TestParseMultiSelection(t *testing.T) {
+	if checked, ok := parseMultiSelection(" 1, 3 ", 3); !ok || !slices.Equal(checked, []bool{true, false, true}) {
+		t.Errorf("parseMultiSelection(1, 3) = %v, %v", checked, ok)
+	}
+	if checked, ok := parseMultiSelection("none", 2); !ok || !slices.Equal(checked, []bool{false, false}) {
+		t.Errorf("parseMultiSelection(none) = %v, %v", checked, ok)
+	}
+	if checked, ok := parseMultiSelection("\n", 2); !ok || checked != nil {
+		t.Errorf("parseMultiSelection(empty) = %v, %v, want nil to keep the checked ones", checked, ok)
+	}
+	for _, answer := range []string{"0", "4", "x"} {
+		if _, ok := parseMultiSelection(answer, 3); ok {
+			t.Errorf("parseMultiSelection(%q) is valid, want invalid", answer)
+		}
+	}
+}
@@ -0,0 +1,310 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: app_conflicts.go
+// Description: Detects apps that conflict with each other, from declared conflicts files and from the files apps install.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package api
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// AppConflict is an app that conflicts with another app
+type AppConflict struct {
+	App       string   `json:"app"`
+	Declared  bool     `json:"declared"`        // listed in either app's conflicts file
+	Installed bool     `json:"installed"`       // the conflicting app is currently installed
+	Files     []string `json:"files,omitempty"` // files both apps install, found in the install manifests
+}
+
+// AppConflictError is returned when an app can't be installed because a conflicting app is installed
+type AppConflictError struct {
+	App       string
+	Conflicts []string
+}
+
+func (e *AppConflictError) Error() string {
+	return fmt.Sprintf("app '%s' conflicts with the installed app(s) %s, uninstall them first", e.App, strings.Join(e.Conflicts, ", "))
+}
+
+// installedFileDirs are the directories whose files are recorded in install manifests.
+// These are the places where two apps installing the same command or menu entry overwrite each other.
+func installedFileDirs() []string {
+	home := os.Getenv("HOME")
+	return []string{
+		"/usr/local/bin",
+		"/usr/local/share/applications",
+		"/usr/share/applications",
+		filepath.Join(home, ".local", "bin"),
+		filepath.Join(home, ".local", "share", "applications"),
+		filepath.Join(home, "Desktop"),
+	}
+}
+
+// scriptPathPattern matches absolute paths in the installed file directories written in an install script
+var scriptPathPattern = regexp.MustCompile(`(?:/usr/local/bin|/usr/local/share/applications|/usr/share/applications|(?:~|\$HOME|\$\{HOME\})/(?:\.local/bin|\.local/share/applications|Desktop))/[A-Za-z0-9._+-]+`)
+
+// DeclaredConflicts returns the apps an app declares a conflict with in its conflicts file,
+// and the apps that declare a conflict with it in theirs
+//
+//	[]string - sorted app names
+//	error - error if the app name is not valid
+func DeclaredConflicts(app string) ([]string, error) {
+	conflicts, err := readConflictsFile(app)
+	if err != nil {
+		return nil, err
+	}
+
+	// Conflicts go both ways, so an app doesn't need to be listed in both files
+	apps, err := ListApps("local")
+	if err != nil {
+		return nil, err
+	}
+	for _, other := range apps {
+		if other == app || slices.Contains(conflicts, other) {
+			continue
+		}
+		otherConflicts, err := readConflictsFile(other)
+		if err != nil {
+			continue
+		}
+		if slices.Contains(otherConflicts, app) {
+			conflicts = append(conflicts, other)
+		}
+	}
+
+	sort.Strings(conflicts)
+	return conflicts, nil
+}
+
+// readConflictsFile reads the app names from apps/<app>/conflicts, one per line, ignoring comments
+func readConflictsFile(app string) ([]string, error) {
+	path, err := AppPath(app, "conflicts")
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	var conflicts []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		name := strings.TrimSpace(scanner.Text())
+		if name == "" || strings.HasPrefix(name, "#") || name == app {
+			continue
+		}
+		if ValidateAppName(name) != nil || slices.Contains(conflicts, name) {
+			continue
+		}
+		conflicts = append(conflicts, name)
+	}
+	return conflicts, scanner.Err()
+}
+
+// InstalledConflicts returns the declared conflicts of an app that are installed
+//
+//	[]string - sorted app names
+//	error - error if the app name is not valid
+func InstalledConflicts(app string) ([]string, error) {
+	declared, err := DeclaredConflicts(app)
+	if err != nil {
+		return nil, err
+	}
+	var installed []string
+	for _, other := range declared {
+		if isConflictInstalled(other) {
+			installed = append(installed, other)
+		}
+	}
+	return installed, nil
+}
+
+// isConflictInstalled reports whether an app is installed, counting corrupted installs as installed
+// since they may have left their files behind
+func isConflictInstalled(app string) bool {
+	status, err := GetAppStatus(app)
+	if err != nil {
+		return false
+	}
+	return status == "installed" || status == "corrupted"
+}
+
+// DetectedConflicts returns the installed apps whose install manifest contains files the app is expected to install
+//
+// The files an app installs are taken from its own manifest when it was installed before,
+// otherwise from the paths written in its install script.
+func DetectedConflicts(app string) ([]AppConflict, error) {
+	files, err := expectedInstalledFiles(app)
+	if err != nil || len(files) == 0 {
+		return nil, err
+	}
+
+	manifests, err := filepath.Glob(filepath.Join(GetDataDir(), "install-files", "*"))
+	if err != nil {
+		return nil, err
+	}
+
+	var conflicts []AppConflict
+	for _, manifest := range manifests {
+		other := filepath.Base(manifest)
+		if other == app || !isConflictInstalled(other) {
+			continue
+		}
+		otherFiles, err := ReadInstalledFiles(other)
+		if err != nil {
+			continue
+		}
+		var shared []string
+		for _, file := range files {
+			if slices.Contains(otherFiles, file) {
+				shared = append(shared, file)
+			}
+		}
+		if len(shared) > 0 {
+			conflicts = append(conflicts, AppConflict{App: other, Installed: true, Files: shared})
+		}
+	}
+	return conflicts, nil
+}
+
+// AppConflicts returns the declared and detected conflicts of an app, sorted by app name
+func AppConflicts(app string) ([]AppConflict, error) {
+	declared, err := DeclaredConflicts(app)
+	if err != nil {
+		return nil, err
+	}
+	detected, err := DetectedConflicts(app)
+	if err != nil {
+		return nil, err
+	}
+
+	conflicts := make([]AppConflict, 0, len(declared)+len(detected))
+	for _, other := range declared {
+		conflicts = append(conflicts, AppConflict{App: other, Declared: true, Installed: isConflictInstalled(other)})
+	}
+	for _, conflict := range detected {
+		if i := slices.IndexFunc(conflicts, func(c AppConflict) bool { return c.App == conflict.App }); i >= 0 {
+			conflicts[i].Files = conflict.Files
+			continue
+		}
+		conflicts = append(conflicts, conflict)
+	}
+	sort.Slice(conflicts, func(i, j int) bool {
+		return conflicts[i].App < conflicts[j].App
+	})
+	return conflicts, nil
+}
+
+// warnDetectedConflicts warns about installed apps whose files an app is about to overwrite
+func warnDetectedConflicts(app string) {
+	detected, err := DetectedConflicts(app)
+	if err != nil {
+		Debug(fmt.Sprintf("Failed to detect conflicts of %s: %v", app, err))
+		return
+	}
+	for _, conflict := range detected {
+		WarningTf("Installing %s may overwrite these files installed by %s: %s", app, conflict.App, strings.Join(conflict.Files, ", "))
+	}
+}
+
+// expectedInstalledFiles returns the files an app installs, from its manifest or its install script
+func expectedInstalledFiles(app string) ([]string, error) {
+	files, err := ReadInstalledFiles(app)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) > 0 {
+		return files, nil
+	}
+
+	scriptName := GetScriptNameForCPU(app)
+	if scriptName == "" {
+		return nil, nil
+	}
+	scriptPath, err := AppPath(app, scriptName)
+	if err != nil {
+		return nil, err
+	}
+	content, err := os.ReadFile(scriptPath)
+	if err != nil {
+		return nil, nil
+	}
+
+	home := os.Getenv("HOME")
+	for _, match := range scriptPathPattern.FindAllString(string(content), -1) {
+		for _, prefix := range []string{"~", "$HOME", "${HOME}"} {
+			if rest, ok := strings.CutPrefix(match, prefix+"/"); ok {
+				match = filepath.Join(home, rest)
+				break
+			}
+		}
+		if !slices.Contains(files, match) {
+			files = append(files, match)
+		}
+	}
+	return files, nil
+}
+
+// installedFilesSnapshot is the modification time of every file in the installed file directories
+type installedFilesSnapshot map[string]time.Time
+
+// takeInstalledFilesSnapshot records the files in the installed file directories before an install
+func takeInstalledFilesSnapshot() installedFilesSnapshot {
+	snapshot := make(installedFilesSnapshot)
+	for _, dir := range installedFileDirs() {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if entry.IsDir() {
+				continue
+			}
+			if info, err := entry.Info(); err == nil {
+				snapshot[filepath.Join(dir, entry.Name())] = info.ModTime()
+			}
+		}
+	}
+	return snapshot
+}
+
+// changedFiles returns the files that were created or modified since the snapshot was taken
+func (snapshot installedFilesSnapshot) changedFiles() []string {
+	var changed []string
+	for path, modTime := range takeInstalledFilesSnapshot() {
+		if before, ok := snapshot[path]; !ok || !before.Equal(modTime) {
+			changed = append(changed, path)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
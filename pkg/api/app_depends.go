@@ -0,0 +1,176 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: app_depends.go
+// Description: Reads the apps an app depends on from its depends file and finds the installed apps that depend on an app.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package api
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// AppDependentsError is returned when an app can't be uninstalled because installed apps depend on it
+type AppDependentsError struct {
+	App        string
+	Dependents []string
+}
+
+func (e *AppDependentsError) Error() string {
+	return fmt.Sprintf("app '%s' is needed by the installed app(s) %s, uninstall them first", e.App, strings.Join(e.Dependents, ", "))
+}
+
+// uninstallWithDependents holds the apps the user agreed to uninstall even though installed apps depend on them
+var (
+	uninstallWithDependents      = make(map[string]bool)
+	uninstallWithDependentsMutex sync.Mutex
+)
+
+// AllowUninstallWithDependents lets UninstallApp uninstall an app in this process even if installed apps depend on it
+func AllowUninstallWithDependents(app string) {
+	uninstallWithDependentsMutex.Lock()
+	defer uninstallWithDependentsMutex.Unlock()
+	uninstallWithDependents[app] = true
+}
+
+// uninstallWithDependentsAllowed reports whether AllowUninstallWithDependents was called for an app
+func uninstallWithDependentsAllowed(app string) bool {
+	uninstallWithDependentsMutex.Lock()
+	defer uninstallWithDependentsMutex.Unlock()
+	return uninstallWithDependents[app]
+}
+
+// AppDepends returns the apps an app depends on, listed in apps/<app>/depends one per line
+//
+//	[]string - app names in the order of the file
+//	error - error if the app name is not valid or the file can't be read
+func AppDepends(app string) ([]string, error) {
+	path, err := AppPath(app, "depends")
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	var depends []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		name := strings.TrimSpace(scanner.Text())
+		if name == "" || strings.HasPrefix(name, "#") || name == app {
+			continue
+		}
+		if ValidateAppName(name) != nil || slices.Contains(depends, name) {
+			continue
+		}
+		depends = append(depends, name)
+	}
+	return depends, scanner.Err()
+}
+
+// reverseDepends maps every local app to the sorted apps that list it in their depends file
+func reverseDepends() (map[string][]string, error) {
+	apps, err := ListApps("local")
+	if err != nil {
+		return nil, err
+	}
+	reverse := make(map[string][]string)
+	for _, app := range apps {
+		depends, err := AppDepends(app)
+		if err != nil {
+			Debug(fmt.Sprintf("Failed to read depends file of %s: %v", app, err))
+			continue
+		}
+		for _, dependency := range depends {
+			reverse[dependency] = append(reverse[dependency], app)
+		}
+	}
+	for dependency := range reverse {
+		sort.Strings(reverse[dependency])
+	}
+	return reverse, nil
+}
+
+// appReverseDepends walks the apps that depend on an app, directly or through other installed apps.
+// Installed dependents are returned in the order to uninstall them in, so each app comes before the apps it depends on.
+// Corrupted dependents are returned separately, they don't need the app anymore but may have left files behind.
+//
+// Apps that depend on each other in a cycle are visited once, the cycle is broken where it is found.
+func appReverseDepends(app string) (installed []string, corrupted []string, err error) {
+	if err := ValidateAppName(app); err != nil {
+		return nil, nil, err
+	}
+	reverse, err := reverseDepends()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	visited := map[string]bool{app: true}
+	var visit func(current string)
+	visit = func(current string) {
+		for _, dependent := range reverse[current] {
+			if visited[dependent] {
+				continue
+			}
+			visited[dependent] = true
+
+			status, err := GetAppStatus(dependent)
+			if err != nil {
+				continue
+			}
+			switch status {
+			case "installed":
+				// Apps depending on this dependent have to go first
+				visit(dependent)
+				installed = append(installed, dependent)
+			case "corrupted":
+				corrupted = append(corrupted, dependent)
+			}
+		}
+	}
+	visit(app)
+
+	sort.Strings(corrupted)
+	return installed, corrupted, nil
+}
+
+// AppReverseDepends returns the installed apps that depend on an app, directly or through other installed apps
+//
+//	[]string - app names in the order to uninstall them in, each app before the apps it depends on
+//	error - error if the app name is not valid or the apps can't be listed
+func AppReverseDepends(app string) ([]string, error) {
+	installed, _, err := appReverseDepends(app)
+	return installed, err
+}
+
+// CorruptedReverseDepends returns the corrupted apps that depend on an app.
+// They don't block uninstalling the app, but should be mentioned since they may still use it.
+func CorruptedReverseDepends(app string) ([]string, error) {
+	_, corrupted, err := appReverseDepends(app)
+	return corrupted, err
+}
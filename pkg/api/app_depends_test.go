@@ -0,0 +1,143 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// newTestDependsDir creates standard apps with the given depends files and statuses
+func newTestDependsDir(t *testing.T, depends map[string]string, statuses map[string]string) string {
+	t.Helper()
+	directory := newTestPiAppsDir(t)
+	for app, content := range depends {
+		writeTestFile(t, filepath.Join(directory, "apps", app, "install"), "#!/bin/bash\n")
+		if content != "" {
+			writeTestFile(t, filepath.Join(directory, "apps", app, "depends"), content)
+		}
+	}
+	for app, status := range statuses {
+		writeTestFile(t, filepath.Join(directory, "data", "status", app), status+"\n")
+	}
+	return directory
+}
+
+func TestAppDepends(t *testing.T) {
+	newTestDependsDir(t, map[string]string{
+		"Game": "# Needs the emulators\nBox64\n\n  Box86  \nBox64\nGame\n../etc\n",
+	}, nil)
+
+	depends, err := AppDepends("Game")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(depends, []string{"Box64", "Box86"}) {
+		t.Errorf("AppDepends = %v, want [Box64 Box86] without comments, duplicates, itself and invalid names", depends)
+	}
+
+	if depends, err := AppDepends("Missing"); err != nil || depends != nil {
+		t.Errorf("AppDepends of an app without a depends file = %v, %v", depends, err)
+	}
+	if _, err := AppDepends("../Game"); err == nil {
+		t.Error("AppDepends accepted an invalid app name")
+	}
+}
+
+func TestAppReverseDepends(t *testing.T) {
+	newTestDependsDir(t, map[string]string{
+		"Box64":     "",
+		"Wine":      "Box64\n",
+		"Game":      "Wine\nBox64\n",
+		"Launcher":  "Game\n",
+		"Old Game":  "Box64\n",
+		"Broken":    "Box64\n",
+		"Unrelated": "",
+	}, map[string]string{
+		"Box64":    "installed",
+		"Wine":     "installed",
+		"Game":     "installed",
+		"Launcher": "installed",
+		"Old Game": "uninstalled",
+		"Broken":   "corrupted",
+	})
+
+	dependents, err := AppReverseDepends("Box64")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Every app has to come before the apps it depends on
+	want := []string{"Launcher", "Game", "Wine"}
+	if !slices.Equal(dependents, want) {
+		t.Errorf("AppReverseDepends(Box64) = %v, want %v", dependents, want)
+	}
+	for i, app := range dependents {
+		depends, _ := AppDepends(app)
+		for _, later := range dependents[:i] {
+			if slices.Contains(depends, later) {
+				t.Errorf("%s is uninstalled after %s, which it depends on", later, app)
+			}
+		}
+	}
+
+	corrupted, err := CorruptedReverseDepends("Box64")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(corrupted, []string{"Broken"}) {
+		t.Errorf("CorruptedReverseDepends(Box64) = %v, want [Broken]", corrupted)
+	}
+
+	if dependents, err := AppReverseDepends("Unrelated"); err != nil || len(dependents) != 0 {
+		t.Errorf("AppReverseDepends(Unrelated) = %v, %v, want none", dependents, err)
+	}
+}
+
+func TestAppReverseDependsCycle(t *testing.T) {
+	newTestDependsDir(t, map[string]string{
+		"A": "C\n",
+		"B": "A\n",
+		"C": "B\n",
+	}, map[string]string{"A": "installed", "B": "installed", "C": "installed"})
+
+	dependents, err := AppReverseDepends("A")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The cycle is broken at A, the app being uninstalled
+	if !slices.Equal(dependents, []string{"C", "B"}) {
+		t.Errorf("AppReverseDepends(A) = %v, want [C B]", dependents)
+	}
+}
+
+func TestUninstallAppWithDependents(t *testing.T) {
+	newTestDependsDir(t, map[string]string{
+		"Box64": "",
+		"Wine":  "Box64\n",
+	}, map[string]string{"Box64": "installed", "Wine": "installed"})
+
+	err := UninstallApp("Box64")
+	var dependentsErr *AppDependentsError
+	if !errors.As(err, &dependentsErr) {
+		t.Fatalf("UninstallApp(Box64) = %v, want an AppDependentsError", err)
+	}
+	if dependentsErr.App != "Box64" || !slices.Equal(dependentsErr.Dependents, []string{"Wine"}) {
+		t.Errorf("AppDependentsError = %+v, want Wine depending on Box64", dependentsErr)
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: app_description.go
+// Description: Picks the translation of an app description for the locale of the user from the description.<locale> files of its app folder.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package api
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxShortDescriptionLength is the most characters the first line of a translated description may have. Translations
+// tend to be longer than the English description, past this the list rows and tooltips cut them off.
+const MaxShortDescriptionLength = 150
+
+// descriptionLocalePattern matches the locales of description files: a language like fr, optionally with a country
+// like pt_BR
+var descriptionLocalePattern = regexp.MustCompile(`^[a-z]{2,3}(_[A-Z]{2})?$`)
+
+// ValidDescriptionLocale reports whether a locale can be the suffix of a translated description file, like pt_BR
+func ValidDescriptionLocale(locale string) bool {
+	return descriptionLocalePattern.MatchString(locale)
+}
+
+// DescriptionLocale returns the locale app descriptions are shown in, the one the messages are translated to. It
+// follows SetApiLocale, so a language switched at runtime switches the descriptions too.
+func DescriptionLocale() string {
+	return formatLocale()
+}
+
+// descriptionLocales returns the locales whose description files are tried for a locale, most specific first:
+// pt_BR.UTF-8 gives pt_BR, then pt. The default description comes after them.
+func descriptionLocales(locale string) []string {
+	locale, _, _ = strings.Cut(locale, ".")
+	locale, _, _ = strings.Cut(locale, "@")
+	locale = strings.ReplaceAll(locale, "-", "_")
+
+	var locales []string
+	if ValidDescriptionLocale(locale) {
+		locales = append(locales, locale)
+	}
+	if language, _, found := strings.Cut(locale, "_"); found && ValidDescriptionLocale(language) {
+		locales = append(locales, language)
+	}
+	return locales
+}
+
+// LocalizedDescriptionPath returns the description file of an app folder to show in the locale of the user, see
+// localizedDescriptionPath
+func LocalizedDescriptionPath(appDir string) string {
+	return localizedDescriptionPath(appDir, DescriptionLocale())
+}
+
+// localizedDescriptionPath returns the description file of an app folder to show in a locale: description.pt_BR,
+// then description.pt, then the description. Empty translations are skipped.
+func localizedDescriptionPath(appDir, locale string) string {
+	for _, candidate := range descriptionLocales(locale) {
+		path := filepath.Join(appDir, "description."+candidate)
+		if info, err := os.Stat(path); err == nil && info.Mode().IsRegular() && info.Size() > 0 {
+			return path
+		}
+	}
+	return filepath.Join(appDir, "description")
+}
+
+// shortDescription returns the first line of a description, shown in list rows and tooltips
+func shortDescription(description string) string {
+	first, _, _ := strings.Cut(description, "\n")
+	return first
+}
+
+// appDescriptionTranslations returns the translated descriptions of an app folder keyed by locale, nil if it has
+// none. Files whose suffix isn't a locale, like description.orig, and empty ones are left out.
+func appDescriptionTranslations(appDir string) map[string]string {
+	paths, _ := filepath.Glob(filepath.Join(appDir, "description.*"))
+	var translations map[string]string
+	for _, path := range paths {
+		locale := strings.TrimPrefix(filepath.Base(path), "description.")
+		if !ValidDescriptionLocale(locale) {
+			continue
+		}
+		if description := readAppFile(appDir, filepath.Base(path)); strings.TrimSpace(description) != "" {
+			if translations == nil {
+				translations = make(map[string]string)
+			}
+			translations[locale] = description
+		}
+	}
+	return translations
+}
+
+// validateDescriptionTranslations checks the translated descriptions of an app folder: they can't be empty and
+// their first line has to fit MaxShortDescriptionLength
+func validateDescriptionTranslations(appDir string) error {
+	paths, err := filepath.Glob(filepath.Join(appDir, "description.*"))
+	if err != nil {
+		return err
+	}
+	sort.Strings(paths)
+
+	var errs []error
+	for _, path := range paths {
+		name := filepath.Base(path)
+		if !ValidDescriptionLocale(strings.TrimPrefix(name, "description.")) {
+			continue
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		first := strings.TrimSpace(shortDescription(string(content)))
+		switch {
+		case strings.TrimSpace(string(content)) == "":
+			errs = append(errs, fmt.Errorf("%s is empty", name))
+		case first == "":
+			errs = append(errs, fmt.Errorf("%s: the first line is empty", name))
+		case utf8.RuneCountInString(first) > MaxShortDescriptionLength:
+			errs = append(errs, fmt.Errorf("%s: the first line has %d characters, at most %d fit", name, utf8.RuneCountInString(first), MaxShortDescriptionLength))
+		}
+	}
+	return errors.Join(errs...)
+}
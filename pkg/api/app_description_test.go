@@ -0,0 +1,141 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestDescriptionLocales(t *testing.T) {
+	tests := map[string][]string{
+		"pt_BR":           {"pt_BR", "pt"},
+		"pt_BR.UTF-8":     {"pt_BR", "pt"},
+		"de_DE@euro":      {"de_DE", "de"},
+		"fr":              {"fr"},
+		"zh-TW":           {"zh_TW", "zh"},
+		"C":               nil,
+		"../../etc":       nil,
+		"":                nil,
+		"sr_RS@latin.x_y": {"sr_RS", "sr"},
+	}
+	for locale, want := range tests {
+		if got := descriptionLocales(locale); !slices.Equal(got, want) {
+			t.Errorf("descriptionLocales(%q) = %q, want %q", locale, got, want)
+		}
+	}
+}
+
+func TestLocalizedDescriptionPath(t *testing.T) {
+	directory := newTestPiAppsDir(t, "Zoom")
+	appDir := filepath.Join(directory, "apps", "Zoom")
+	writeTestFile(t, filepath.Join(appDir, "description.pt"), "Videochamadas\n")
+	writeTestFile(t, filepath.Join(appDir, "description.fr_CA"), "Appels vidéo\n")
+	// An empty translation is skipped, like a missing one
+	writeTestFile(t, filepath.Join(appDir, "description.de"), "")
+
+	tests := map[string]string{
+		"pt_BR": "description.pt",
+		"pt_PT": "description.pt",
+		"fr_CA": "description.fr_CA",
+		"fr_FR": "description",
+		"de_DE": "description",
+		"en_US": "description",
+	}
+	for locale, want := range tests {
+		if got := filepath.Base(localizedDescriptionPath(appDir, locale)); got != want {
+			t.Errorf("description for %s = %s, want %s", locale, got, want)
+		}
+	}
+}
+
+func TestAppMetadataLocalized(t *testing.T) {
+	directory := newTestPiAppsDir(t, "Zoom")
+	appDir := filepath.Join(directory, "apps", "Zoom")
+	writeTestFile(t, filepath.Join(appDir, "description.pl"), "Wideorozmowy\nDruga linia\n")
+	writeTestFile(t, filepath.Join(appDir, "description.orig"), "not a translation\n")
+
+	metadata, err := readAppMetadata(directory, "Zoom", &CategoryData{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(metadata.Translations) != 1 || metadata.Translations["pl"] != "Wideorozmowy\nDruga linia" {
+		t.Fatalf("translations = %q", metadata.Translations)
+	}
+	if metadata.ShortDescription != "Zoom description" {
+		t.Errorf("default short description = %q", metadata.ShortDescription)
+	}
+
+	english := *metadata
+	english.localize("en_US")
+	if english.LongDescription != "Zoom description" {
+		t.Errorf("description in en_US = %q", english.LongDescription)
+	}
+	metadata.localize("pl_PL.UTF-8")
+	if metadata.ShortDescription != "Wideorozmowy" || metadata.LongDescription != "Wideorozmowy\nDruga linia" {
+		t.Errorf("description in pl_PL = %q, %q", metadata.ShortDescription, metadata.LongDescription)
+	}
+}
+
+func TestAppDescriptionFollowsLocale(t *testing.T) {
+	directory := newTestPiAppsDir(t, "Zoom")
+	writeTestFile(t, filepath.Join(directory, "apps", "Zoom", "description.fr"), "Appels vidéo\nPlus de détails\n")
+
+	// Switching the language at runtime switches the descriptions
+	setTestLocale(t, "fr_FR")
+	if got := AppDescription("Zoom"); got != "Appels vidéo" {
+		t.Errorf("AppDescription in fr_FR = %q", got)
+	}
+	setTestLocale(t, "en_US")
+	if got := AppDescription("Zoom"); got != "Zoom description" {
+		t.Errorf("AppDescription in en_US = %q", got)
+	}
+}
+
+func TestValidateDescriptionTranslations(t *testing.T) {
+	directory := newTestPiAppsDir(t, "Zoom")
+	appDir := filepath.Join(directory, "apps", "Zoom")
+	writeTestFile(t, filepath.Join(appDir, "description.fr"), "Appels vidéo\n")
+	writeTestFile(t, filepath.Join(appDir, "description.orig"), "")
+	if err := ValidateApp("Zoom"); err != nil {
+		t.Fatalf("ValidateApp with a valid translation: %v", err)
+	}
+
+	writeTestFile(t, filepath.Join(appDir, "description.de"), "\n \n")
+	writeTestFile(t, filepath.Join(appDir, "description.es"), "\nLlamadas de vídeo\n")
+	writeTestFile(t, filepath.Join(appDir, "description.pl"), strings.Repeat("ż", MaxShortDescriptionLength+1)+"\n")
+	writeTestFile(t, filepath.Join(appDir, "description.pt"), strings.Repeat("é", MaxShortDescriptionLength)+"\n")
+	err := ValidateApp("Zoom")
+	if err == nil {
+		t.Fatal("ValidateApp accepted invalid translations")
+	}
+	for _, want := range []string{"description.de is empty", "description.es: the first line is empty", "description.pl: the first line has 151 characters"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q doesn't mention %q", err, want)
+		}
+	}
+	if strings.Contains(err.Error(), "description.pt") || strings.Contains(err.Error(), "description.fr") {
+		t.Errorf("error %q mentions a valid translation", err)
+	}
+
+	// Translations don't keep the app from being installed
+	if apps, _ := ValidateApps(ActionInstall, []string{"Zoom"}); !slices.Equal(apps, []string{"Zoom"}) {
+		t.Errorf("ValidateApps = %q, want Zoom", apps)
+	}
+}
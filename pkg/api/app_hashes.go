@@ -0,0 +1,262 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: app_hashes.go
+// Description: Computes and caches content hashes of app folders so update checks don't compare every file.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package api
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// App folder hashes are built from git blob hashes, so the hash of a local folder can be compared
+// with the hash of the same folder computed from the tree objects of the update clone.
+
+// appHashCacheEntry is a cached app folder hash, valid as long as the folder's newest mtime and file count stay the same
+type appHashCacheEntry struct {
+	modTime int64
+	count   int
+	hash    string
+}
+
+// appHashCacheMutex serializes reads and writes of the app hash cache within a process
+var appHashCacheMutex sync.Mutex
+
+// appHashCachePath returns the path of the app hash cache file
+func appHashCachePath() string {
+	return filepath.Join(GetDataDir(), "cache", "app-hashes")
+}
+
+// GitBlobHash returns the hash git gives a file with this content
+func GitBlobHash(content []byte) string {
+	h := sha1.New()
+	fmt.Fprintf(h, "blob %d\x00", len(content))
+	h.Write(content)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// AppTreeHash combines the blob hashes of an app folder's files, keyed by path relative to the folder, into one hash
+func AppTreeHash(blobs map[string]string) string {
+	paths := make([]string, 0, len(blobs))
+	for path := range blobs {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	h := sha1.New()
+	for _, path := range paths {
+		fmt.Fprintf(h, "%s %s\n", blobs[path], path)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// AppFolderHash hashes the files of a folder the same way AppTreeHash hashes a git tree
+func AppFolderHash(dir string) (string, error) {
+	blobs := make(map[string]string)
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+
+		// git stores the target of a symlink as its content
+		var content []byte
+		if entry.Type()&fs.ModeSymlink != 0 {
+			target, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			content = []byte(target)
+		} else if content, err = os.ReadFile(path); err != nil {
+			return err
+		}
+		blobs[filepath.ToSlash(rel)] = GitBlobHash(content)
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return AppTreeHash(blobs), nil
+}
+
+// appFolderStamp returns the newest mtime and the number of files and folders in a folder, without reading any file
+func appFolderStamp(dir string) (int64, int, error) {
+	var modTime int64
+	count := 0
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		if t := info.ModTime().UnixNano(); t > modTime {
+			modTime = t
+		}
+		count++
+		return nil
+	})
+	return modTime, count, err
+}
+
+// LocalAppHashes returns the folder hashes of local apps, keyed by app name
+//
+// Hashes of folders that didn't change since the last check are taken from the cache in data/cache/app-hashes,
+// the others are computed in parallel and written back to the cache. Apps whose folder can't be read are left out.
+func LocalAppHashes(apps []string) map[string]string {
+	appHashCacheMutex.Lock()
+	defer appHashCacheMutex.Unlock()
+
+	cache := readAppHashCache()
+	hashes := make(map[string]string, len(apps))
+	var mu sync.Mutex
+	changed := false
+
+	jobs := make(chan string)
+	var wg sync.WaitGroup
+	for i := 0; i < runtime.NumCPU(); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for app := range jobs {
+				dir := filepath.Join(GetPiAppsDir(), "apps", app)
+				modTime, count, err := appFolderStamp(dir)
+				if err != nil {
+					continue
+				}
+
+				mu.Lock()
+				entry, ok := cache[app]
+				mu.Unlock()
+				if ok && entry.modTime == modTime && entry.count == count {
+					mu.Lock()
+					hashes[app] = entry.hash
+					mu.Unlock()
+					continue
+				}
+
+				hash, err := AppFolderHash(dir)
+				if err != nil {
+					Debug(fmt.Sprintf("Failed to hash app folder %s: %v", dir, err))
+					continue
+				}
+				mu.Lock()
+				hashes[app] = hash
+				cache[app] = appHashCacheEntry{modTime: modTime, count: count, hash: hash}
+				changed = true
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, app := range apps {
+		jobs <- app
+	}
+	close(jobs)
+	wg.Wait()
+
+	if changed {
+		if err := writeAppHashCache(cache); err != nil {
+			Debug(fmt.Sprintf("Failed to write app hash cache: %v", err))
+		}
+	}
+	return hashes
+}
+
+// InvalidateAppHash removes an app from the app hash cache after its folder was modified
+//
+// The cache would notice most changes by itself, but copied files can keep the mtime of their source.
+func InvalidateAppHash(app string) {
+	appHashCacheMutex.Lock()
+	defer appHashCacheMutex.Unlock()
+
+	cache := readAppHashCache()
+	if _, ok := cache[app]; !ok {
+		return
+	}
+	delete(cache, app)
+	if err := writeAppHashCache(cache); err != nil {
+		Debug(fmt.Sprintf("Failed to write app hash cache: %v", err))
+	}
+}
+
+// readAppHashCache reads the app hash cache, lines are formatted as app;mtime;count;hash
+func readAppHashCache() map[string]appHashCacheEntry {
+	cache := make(map[string]appHashCacheEntry)
+	content, err := os.ReadFile(appHashCachePath())
+	if err != nil {
+		return cache
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		parts := strings.Split(line, ";")
+		if len(parts) != 4 {
+			continue
+		}
+		modTime, err := strconv.ParseInt(parts[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		count, err := strconv.Atoi(parts[2])
+		if err != nil {
+			continue
+		}
+		cache[parts[0]] = appHashCacheEntry{modTime: modTime, count: count, hash: parts[3]}
+	}
+	return cache
+}
+
+// writeAppHashCache writes the app hash cache, replacing the file so a reader never sees half of it
+func writeAppHashCache(cache map[string]appHashCacheEntry) error {
+	apps := make([]string, 0, len(cache))
+	for app := range cache {
+		apps = append(apps, app)
+	}
+	sort.Strings(apps)
+
+	var content strings.Builder
+	for _, app := range apps {
+		entry := cache[app]
+		fmt.Fprintf(&content, "%s;%d;%d;%s\n", app, entry.modTime, entry.count, entry.hash)
+	}
+
+	path := appHashCachePath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(content.String()), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,145 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGitBlobHash(t *testing.T) {
+	// Hashes from git hash-object
+	tests := map[string]string{
+		"":        "e69de29bb2d1d6434b8b29ae775ad8c2e48c5391",
+		"hello\n": "ce013625030ba8dba906f756967f9e9ca394464a",
+	}
+	for content, want := range tests {
+		if got := GitBlobHash([]byte(content)); got != want {
+			t.Errorf("GitBlobHash(%q) = %s, want %s", content, got, want)
+		}
+	}
+}
+
+func TestAppFolderHashMatchesTreeHash(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "install"), "#!/bin/bash\n")
+	writeTestFile(t, filepath.Join(dir, "sub", "file"), "hello\n")
+
+	got, err := AppFolderHash(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := AppTreeHash(map[string]string{
+		"install":  GitBlobHash([]byte("#!/bin/bash\n")),
+		"sub/file": GitBlobHash([]byte("hello\n")),
+	})
+	if got != want {
+		t.Errorf("AppFolderHash = %s, want the tree hash %s", got, want)
+	}
+}
+
+func TestLocalAppHashesCache(t *testing.T) {
+	directory := newTestPiAppsDir(t, "Alpha", "Beta")
+
+	hashes := LocalAppHashes([]string{"Alpha", "Beta", "Missing"})
+	if len(hashes) != 2 {
+		t.Fatalf("LocalAppHashes = %v, want the hashes of Alpha and Beta", hashes)
+	}
+	for app, hash := range hashes {
+		want, err := AppFolderHash(filepath.Join(directory, "apps", app))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hash != want {
+			t.Errorf("hash of %s = %s, want %s", app, hash, want)
+		}
+	}
+	content, err := os.ReadFile(appHashCachePath())
+	if err != nil {
+		t.Fatalf("the cache was not written: %v", err)
+	}
+	if !strings.HasPrefix(string(content), "Alpha;") {
+		t.Errorf("unexpected cache content:\n%s", content)
+	}
+
+	// A changed folder is noticed by its newer mtime
+	description := filepath.Join(directory, "apps", "Alpha", "description")
+	writeTestFile(t, description, "Alpha changed\n")
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(description, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if LocalAppHashes([]string{"Alpha"})["Alpha"] == hashes["Alpha"] {
+		t.Error("the hash of Alpha did not change after its description changed")
+	}
+
+	// A copied file can keep an old mtime, InvalidateAppHash makes the next check rehash the folder
+	writeTestFile(t, description, "Alpha changed again\n")
+	if err := os.Chtimes(description, later, later); err != nil {
+		t.Fatal(err)
+	}
+	stale := LocalAppHashes([]string{"Alpha"})["Alpha"]
+	InvalidateAppHash("Alpha")
+	if fresh := LocalAppHashes([]string{"Alpha"})["Alpha"]; fresh == stale {
+		t.Error("InvalidateAppHash did not make LocalAppHashes rehash Alpha")
+	}
+}
+
+// newBenchmarkApps creates 400 apps with a few files each, about the size of the Pi-Apps catalog
+func newBenchmarkApps(b *testing.B) []string {
+	b.Helper()
+	directory := newTestPiAppsDir(b)
+	apps := make([]string, 400)
+	for i := range apps {
+		apps[i] = fmt.Sprintf("App %d", i)
+		appDir := filepath.Join(directory, "apps", apps[i])
+		for _, name := range []string{"description", "website", "credits", "install", "uninstall"} {
+			writeTestFile(b, filepath.Join(appDir, name), strings.Repeat(apps[i]+" "+name+"\n", 50))
+		}
+		writeTestFile(b, filepath.Join(appDir, "icon-64.png"), string(make([]byte, 8<<10)))
+	}
+	return apps
+}
+
+// BenchmarkLocalAppHashesWarm measures a repeat update check, where every hash comes from the cache.
+// It should stay well under the 2 second budget of an update check on a Pi 4.
+func BenchmarkLocalAppHashesWarm(b *testing.B) {
+	apps := newBenchmarkApps(b)
+	if len(LocalAppHashes(apps)) != len(apps) {
+		b.Fatal("not every app was hashed")
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		LocalAppHashes(apps)
+	}
+}
+
+// BenchmarkLocalAppHashesCold measures the first update check, where every app folder is hashed
+func BenchmarkLocalAppHashesCold(b *testing.B) {
+	apps := newBenchmarkApps(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		os.Remove(appHashCachePath())
+		b.StartTimer()
+		LocalAppHashes(apps)
+	}
+}
@@ -0,0 +1,342 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: app_icons.go
+// Description: Generates the app icon sizes an app doesn't ship into the icon cache, for all apps at once after
+// the required sizes changed.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package api
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	"image/png"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang.org/x/image/draw"
+)
+
+// AppIconSizes are the icon sizes the GUI expects of every app, as icon-<size>.png. 48 and 128 are the 24 and 64
+// pixel icons for screens scaled by 2, apps only ship 24 and 64 so the others are generated into the icon cache.
+var AppIconSizes = []int{24, 48, 64, 128}
+
+const (
+	// iconCacheDir is the folder in data holding the generated icons, one folder per app. They are kept out of the
+	// app folders so the app hashes the updater compares stay those of the app repository.
+	iconCacheDir = "icon-cache"
+	// iconProgressFile records the apps RegenerateAllAppIcons finished, so an interrupted run resumes where it stopped
+	iconProgressFile = ".regenerate-progress"
+)
+
+// IconResult is what RegenerateAllAppIcons did for an app
+type IconResult struct {
+	App       string
+	Source    string // the icon the others were generated from, "" when the app has none
+	Generated []int  // the sizes generated
+	Resumed   bool   // the app was done by an interrupted run already
+	Err       error
+}
+
+// AppIconPath returns the icon of an app in the given size, the one the app ships or else the generated one.
+// It returns "" when there is neither.
+func AppIconPath(app string, size int) string {
+	name := "icon-" + strconv.Itoa(size) + ".png"
+	directory := GetPiAppsDir()
+	for _, path := range []string{filepath.Join(directory, "apps", app, name), filepath.Join(DataDirOf(directory), iconCacheDir, app, name)} {
+		if FileExists(path) {
+			return path
+		}
+	}
+	return ""
+}
+
+// RegenerateAllAppIcons generates the icon sizes every app is missing into the icon cache, from the largest
+// icon the app ships or its icon.svg. Sizes generated before are only generated again when the source changed
+// since, or when force is set. nil sizes stands for AppIconSizes.
+//
+// Apps are processed one after another with the same image buffers. The apps done are recorded, so running it
+// again after it was interrupted skips them. progress, when not nil, is called after each app with the number
+// of apps done and the total.
+func RegenerateAllAppIcons(sizes []int, force bool, progress func(done, total int, result IconResult)) ([]IconResult, error) {
+	directory := GetPiAppsDir()
+	if directory == "" {
+		return nil, fmt.Errorf("PI_APPS_DIR environment variable not set")
+	}
+	return regenerateAppIcons(directory, sizes, force, progress)
+}
+
+// regenerateAppIcons is RegenerateAllAppIcons for the Pi-Apps directory directory
+func regenerateAppIcons(directory string, sizes []int, force bool, progress func(done, total int, result IconResult)) ([]IconResult, error) {
+	if sizes == nil {
+		sizes = AppIconSizes
+	}
+	sizes = slices.Clone(sizes)
+	slices.Sort(sizes)
+	sizes = slices.Compact(sizes)
+	for _, size := range sizes {
+		if size <= 0 {
+			return nil, fmt.Errorf("invalid icon size %d", size)
+		}
+	}
+
+	entries, err := os.ReadDir(filepath.Join(directory, "apps"))
+	if err != nil {
+		return nil, fmt.Errorf("error reading apps directory: %w", err)
+	}
+	var apps []string
+	for _, entry := range entries {
+		if entry.IsDir() && ValidateAppName(entry.Name()) == nil {
+			apps = append(apps, entry.Name())
+		}
+	}
+
+	cacheDir := filepath.Join(DataDirOf(directory), iconCacheDir)
+	if err := EnsureDirOwned(cacheDir, InvokingUser, InvokingUser); err != nil {
+		return nil, fmt.Errorf("error creating icon cache: %w", err)
+	}
+	run := iconRun{path: filepath.Join(cacheDir, iconProgressFile), key: iconRunKey(sizes, force)}
+	done, err := run.load()
+	if err != nil {
+		return nil, err
+	}
+
+	scaler := &iconScaler{}
+	results := make([]IconResult, 0, len(apps))
+	for i, app := range apps {
+		result := IconResult{App: app}
+		if done[app] {
+			result.Resumed = true
+		} else {
+			result.Source, result.Generated, result.Err = scaler.generate(directory, app, sizes, force)
+			if err := run.record(app); err != nil {
+				return results, fmt.Errorf("error recording the icons of %s as done: %w", app, err)
+			}
+			if len(result.Generated) > 0 {
+				if err := RefreshAppEntry(app); err != nil {
+					Debug(fmt.Sprintf("Failed to refresh the app list entry of %s: %v", app, err))
+				}
+			}
+		}
+		results = append(results, result)
+		if progress != nil {
+			progress(i+1, len(apps), result)
+		}
+	}
+
+	// The run is complete, the next one starts over
+	if err := os.Remove(run.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return results, fmt.Errorf("error removing %s: %w", run.path, err)
+	}
+	return results, nil
+}
+
+// iconRun records the apps a run of RegenerateAllAppIcons finished. Its file starts with the sizes and force flag
+// of the run, a run with others starts over.
+type iconRun struct {
+	path string
+	key  string
+}
+
+// iconRunKey returns the first line of the progress file of a run
+func iconRunKey(sizes []int, force bool) string {
+	fields := make([]string, len(sizes))
+	for i, size := range sizes {
+		fields[i] = strconv.Itoa(size)
+	}
+	return "sizes=" + strings.Join(fields, ",") + " force=" + strconv.FormatBool(force)
+}
+
+// load returns the apps an interrupted run with the same sizes finished, and starts the progress file otherwise
+func (r iconRun) load() (map[string]bool, error) {
+	done := make(map[string]bool)
+	content, err := os.ReadFile(r.path)
+	if err == nil {
+		lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+		if lines[0] == r.key {
+			for _, app := range lines[1:] {
+				done[app] = true
+			}
+			return done, nil
+		}
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("error reading %s: %w", r.path, err)
+	}
+	if err := os.WriteFile(r.path, []byte(r.key+"\n"), 0644); err != nil {
+		return nil, fmt.Errorf("error writing %s: %w", r.path, err)
+	}
+	return done, nil
+}
+
+// record adds an app to the progress file
+func (r iconRun) record(app string) error {
+	file, err := os.OpenFile(r.path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := file.WriteString(app + "\n"); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// iconScaler scales app icons, reusing its image and encoding buffers from one app to the next
+type iconScaler struct {
+	canvases map[int]*image.NRGBA
+	encoded  bytes.Buffer
+	encoder  png.Encoder
+	pool     iconBufferPool
+}
+
+// iconBufferPool keeps the buffers of the PNG encoder for the next icon
+type iconBufferPool struct {
+	buffer *png.EncoderBuffer
+}
+
+func (p *iconBufferPool) Get() *png.EncoderBuffer  { return p.buffer }
+func (p *iconBufferPool) Put(b *png.EncoderBuffer) { p.buffer = b }
+
+// generate writes the sizes of an app that neither the app ships nor the cache has up to date into the cache.
+// It returns the source icon and the sizes it generated.
+func (s *iconScaler) generate(directory, app string, sizes []int, force bool) (string, []int, error) {
+	appDir := filepath.Join(directory, "apps", app)
+	source, sourceSize := appIconSource(appDir)
+	if source == "" {
+		return "", nil, nil
+	}
+	sourceInfo, err := os.Stat(source)
+	if err != nil {
+		return source, nil, err
+	}
+
+	cacheDir := filepath.Join(DataDirOf(directory), iconCacheDir, app)
+	var missing []int
+	for _, size := range sizes {
+		name := "icon-" + strconv.Itoa(size) + ".png"
+		if FileExists(filepath.Join(appDir, name)) {
+			continue
+		}
+		if info, err := os.Stat(filepath.Join(cacheDir, name)); err == nil && !force && !info.ModTime().Before(sourceInfo.ModTime()) {
+			continue
+		}
+		missing = append(missing, size)
+	}
+	if len(missing) == 0 {
+		return source, nil, nil
+	}
+
+	var src image.Image
+	if sourceSize == 0 {
+		src, err = renderSVGIcon(source, missing[len(missing)-1])
+	} else {
+		src, err = decodeIcon(source)
+	}
+	if err != nil {
+		return source, nil, fmt.Errorf("error reading %s: %w", source, err)
+	}
+
+	if err := EnsureDirOwned(cacheDir, InvokingUser, InvokingUser); err != nil {
+		return source, nil, err
+	}
+	var generated []int
+	for _, size := range missing {
+		if err := s.writeIcon(src, size, filepath.Join(cacheDir, "icon-"+strconv.Itoa(size)+".png")); err != nil {
+			return source, generated, err
+		}
+		generated = append(generated, size)
+	}
+	return source, generated, nil
+}
+
+// writeIcon scales src to fit a size by size square, keeping its aspect ratio, and saves it as a PNG
+func (s *iconScaler) writeIcon(src image.Image, size int, path string) error {
+	if s.canvases == nil {
+		s.canvases = make(map[int]*image.NRGBA)
+		s.encoder.BufferPool = &s.pool
+	}
+	canvas, ok := s.canvases[size]
+	if !ok {
+		canvas = image.NewNRGBA(image.Rect(0, 0, size, size))
+		s.canvases[size] = canvas
+	}
+	clear(canvas.Pix)
+
+	bounds := src.Bounds()
+	width, height := size, size
+	if bounds.Dx() > bounds.Dy() {
+		height = max(1, size*bounds.Dy()/bounds.Dx())
+	} else if bounds.Dy() > bounds.Dx() {
+		width = max(1, size*bounds.Dx()/bounds.Dy())
+	}
+	target := image.Rect((size-width)/2, (size-height)/2, (size-width)/2+width, (size-height)/2+height)
+	draw.CatmullRom.Scale(canvas, target, src, bounds, draw.Src, nil)
+
+	s.encoded.Reset()
+	if err := s.encoder.Encode(&s.encoded, canvas); err != nil {
+		return fmt.Errorf("error encoding %dpx icon: %w", size, err)
+	}
+	if err := os.WriteFile(path+".tmp", s.encoded.Bytes(), 0644); err != nil {
+		return fmt.Errorf("error saving %dpx icon: %w", size, err)
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// appIconSource returns the icon of an app folder the other sizes are generated from and its size: icon.svg
+// (size 0) when rsvg-convert can render it, otherwise the largest icon-<size>.png
+func appIconSource(appDir string) (string, int) {
+	if svg := filepath.Join(appDir, "icon.svg"); FileExists(svg) && commandExists("rsvg-convert") {
+		return svg, 0
+	}
+	matches, _ := filepath.Glob(filepath.Join(appDir, "icon-*.png"))
+	source, sourceSize := "", 0
+	for _, match := range matches {
+		size, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(filepath.Base(match), "icon-"), ".png"))
+		if err == nil && size > sourceSize {
+			source, sourceSize = match, size
+		}
+	}
+	return source, sourceSize
+}
+
+// decodeIcon reads a PNG or JPEG icon
+func decodeIcon(path string) (image.Image, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	img, _, err := image.Decode(file)
+	return img, err
+}
+
+// renderSVGIcon renders an SVG icon at the given size with rsvg-convert
+func renderSVGIcon(path string, size int) (image.Image, error) {
+	output, err := exec.Command("rsvg-convert", "-w", strconv.Itoa(size), "-h", strconv.Itoa(size), "--keep-aspect-ratio", path).Output()
+	if err != nil {
+		return nil, fmt.Errorf("rsvg-convert failed: %w", err)
+	}
+	return png.Decode(bytes.NewReader(output))
+}
@@ -0,0 +1,207 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+// writeTestPNG writes an opaque width by height PNG
+func writeTestPNG(t *testing.T, path string, width, height int) {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for i := range img.Pix {
+		img.Pix[i] = 0xff
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	if err := png.Encode(file, img); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// readTestPNG decodes a PNG written by RegenerateAllAppIcons
+func readTestPNG(t *testing.T, path string) image.Image {
+	t.Helper()
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	img, err := png.Decode(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return img
+}
+
+// generatedSizes returns the sizes RegenerateAllAppIcons generated per app
+func generatedSizes(results []IconResult) map[string][]int {
+	sizes := make(map[string][]int)
+	for _, result := range results {
+		if result.Err != nil {
+			sizes[result.App] = nil
+		}
+		if len(result.Generated) > 0 {
+			sizes[result.App] = result.Generated
+		}
+	}
+	return sizes
+}
+
+func TestRegenerateAllAppIcons(t *testing.T) {
+	dir := newTestPiAppsDir(t)
+	writeTestPNG(t, filepath.Join(dir, "apps", "Zoom", "icon-24.png"), 24, 24)
+	writeTestPNG(t, filepath.Join(dir, "apps", "Zoom", "icon-64.png"), 64, 64)
+	writeTestPNG(t, filepath.Join(dir, "apps", "Wide", "icon-64.png"), 64, 32)
+	writeTestFile(t, filepath.Join(dir, "apps", "NoIcon", "description"), "No icon\n")
+
+	var calls []int
+	results, err := RegenerateAllAppIcons(nil, false, func(done, total int, result IconResult) {
+		if total != 3 {
+			t.Errorf("progress reported %d apps, want 3", total)
+		}
+		calls = append(calls, done)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(calls, []int{1, 2, 3}) {
+		t.Errorf("progress was called with %v", calls)
+	}
+	want := map[string][]int{"Zoom": {48, 128}, "Wide": {24, 48, 128}}
+	if got := generatedSizes(results); len(got) != len(want) || !slices.Equal(got["Zoom"], want["Zoom"]) || !slices.Equal(got["Wide"], want["Wide"]) {
+		t.Errorf("generated %v, want %v", got, want)
+	}
+
+	// The shipped sizes are used as they are, the generated ones come from the icon cache
+	if got := AppIconPath("Zoom", 24); got != filepath.Join(dir, "apps", "Zoom", "icon-24.png") {
+		t.Errorf("AppIconPath(Zoom, 24) = %s", got)
+	}
+	if got := AppIconPath("Zoom", 128); got != filepath.Join(dir, "data", "icon-cache", "Zoom", "icon-128.png") {
+		t.Errorf("AppIconPath(Zoom, 128) = %s", got)
+	}
+	if got := AppIconPath("NoIcon", 24); got != "" {
+		t.Errorf("AppIconPath(NoIcon, 24) = %s, want none", got)
+	}
+
+	// Icons are square, a wide one keeps its aspect ratio with transparent bars above and below it
+	wide := readTestPNG(t, AppIconPath("Wide", 128))
+	if wide.Bounds().Dx() != 128 || wide.Bounds().Dy() != 128 {
+		t.Fatalf("the 128px icon is %v", wide.Bounds())
+	}
+	if _, _, _, a := wide.At(64, 4).RGBA(); a != 0 {
+		t.Errorf("the bar above the wide icon isn't transparent")
+	}
+	if got := color.NRGBAModel.Convert(wide.At(64, 64)).(color.NRGBA); got.A != 0xff {
+		t.Errorf("the middle of the wide icon is %v", got)
+	}
+
+	if FileExists(filepath.Join(dir, "data", "icon-cache", iconProgressFile)) {
+		t.Error("the progress file of a finished run was kept")
+	}
+
+	// Up to date icons are kept, until the source changes or force is set
+	results, err = RegenerateAllAppIcons(nil, false, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := generatedSizes(results); len(got) != 0 {
+		t.Errorf("a second run generated %v", got)
+	}
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(filepath.Join(dir, "apps", "Wide", "icon-64.png"), later, later); err != nil {
+		t.Fatal(err)
+	}
+	results, err = RegenerateAllAppIcons(nil, false, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := generatedSizes(results); len(got) != 1 || !slices.Equal(got["Wide"], []int{24, 48, 128}) {
+		t.Errorf("after the source of Wide changed, generated %v", got)
+	}
+	results, err = RegenerateAllAppIcons([]int{48}, true, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := generatedSizes(results); len(got) != 2 || !slices.Equal(got["Zoom"], []int{48}) || !slices.Equal(got["Wide"], []int{48}) {
+		t.Errorf("with force, generated %v", got)
+	}
+}
+
+// TestRegenerateAllAppIconsResumes checks that a run skips the apps an interrupted run with the same sizes finished
+func TestRegenerateAllAppIconsResumes(t *testing.T) {
+	dir := newTestPiAppsDir(t)
+	writeTestPNG(t, filepath.Join(dir, "apps", "Wide", "icon-64.png"), 64, 32)
+	writeTestPNG(t, filepath.Join(dir, "apps", "Zoom", "icon-64.png"), 64, 64)
+	progressPath := filepath.Join(dir, "data", "icon-cache", iconProgressFile)
+	writeTestFile(t, progressPath, iconRunKey([]int{24, 48}, false)+"\nWide\n")
+
+	results, err := RegenerateAllAppIcons([]int{48, 24}, false, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !results[0].Resumed || results[0].App != "Wide" || results[1].Resumed {
+		t.Errorf("results = %+v, want Wide resumed", results)
+	}
+	if got := generatedSizes(results); len(got) != 1 || !slices.Equal(got["Zoom"], []int{24, 48}) {
+		t.Errorf("generated %v, want only the icons of Zoom", got)
+	}
+
+	// A run with other sizes starts over
+	writeTestFile(t, progressPath, iconRunKey([]int{24, 48}, false)+"\nWide\nZoom\n")
+	results, err = RegenerateAllAppIcons([]int{128}, false, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := generatedSizes(results); len(got) != 2 {
+		t.Errorf("a run with other sizes generated %v, want the icons of both apps", got)
+	}
+}
+
+func TestCacheCleanupsRemovesIconsOfRemovedApps(t *testing.T) {
+	dir := newTestPiAppsDir(t, "Zoom")
+	writeTestPNG(t, filepath.Join(dir, "data", "icon-cache", "Zoom", "icon-48.png"), 48, 48)
+	writeTestPNG(t, filepath.Join(dir, "data", "icon-cache", "Removed", "icon-48.png"), 48, 48)
+
+	var removed []string
+	for _, cleanup := range cacheCleanups(dir) {
+		removed = append(removed, cleanup.item.Path)
+		if err := cleanup.apply(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(removed) != 1 || removed[0] != filepath.Join(dir, "data", "icon-cache", "Removed") {
+		t.Errorf("removed %v, want only the icons of the removed app", removed)
+	}
+	if !FileExists(filepath.Join(dir, "data", "icon-cache", "Zoom", "icon-48.png")) || DirExists(filepath.Join(dir, "data", "icon-cache", "Removed")) {
+		t.Error("the wrong icons were removed")
+	}
+}
@@ -74,11 +74,14 @@ func RefreshPkgAppStatus(appName string, packageName string) error {
 		Debug(fmt.Sprintf("Error getting app status: %v", err))
 	}
 
+	// Held packages stay installed, the hold is only recorded next to the status
+	setAppHeld(appName, installed && PackageIsHeld(packageName))
+
 	if installed {
 		// If the package is installed, mark the app as installed
 		if status != "installed" {
 			DebugTf("Marking %s as installed", appName)
-			statusDir := filepath.Join(directory, "data", "status")
+			statusDir := filepath.Join(DataDirOf(directory), "status")
 			if err := os.MkdirAll(statusDir, 0755); err != nil {
 				return fmt.Errorf("error creating status directory: %w", err)
 			}
@@ -99,7 +102,7 @@ func RefreshPkgAppStatus(appName string, packageName string) error {
 		if status != "uninstalled" {
 			DebugTf("Marking %s as uninstalled", appName)
 			// Remove the status file to mark it as uninstalled
-			statusFile := filepath.Join(directory, "data", "status", appName)
+			statusFile := filepath.Join(DataDirOf(directory), "status", appName)
 			_ = os.Remove(statusFile) // Ignore error if file doesn't exist
 
 			// Send analytics
@@ -110,7 +113,7 @@ func RefreshPkgAppStatus(appName string, packageName string) error {
 	}
 
 	// Check if the app is currently hidden but should be unhidden
-	categoryOverridesFile := filepath.Join(directory, "data", "category-overrides")
+	categoryOverridesFile := filepath.Join(DataDirOf(directory), "category-overrides")
 	if FileExists(categoryOverridesFile) {
 		// Check if app is marked as hidden in the category-overrides file
 		file, err := os.Open(categoryOverridesFile)
@@ -152,8 +155,8 @@ func RunCategoryEdit(appName, category string) error {
 		return fmt.Errorf("PI_APPS_DIR environment variable not set")
 	}
 
-	// Edit the category
-	err := EditAppCategory(appName, category)
+	// Edit the category, without pinning it so the app follows the upstream lists again once unhidden
+	err := setAppCategory(appName, category, false)
 
 	if err != nil {
 		return fmt.Errorf("error running categoryedit: %w", err)
@@ -183,7 +186,7 @@ func RefreshAppList() error {
 	}
 
 	// Get the current GUI mode
-	guiModeFile := filepath.Join(directory, "data", "settings", "App List Style")
+	guiModeFile := filepath.Join(DataDirOf(directory), "settings", "App List Style")
 	if !FileExists(guiModeFile) {
 		return fmt.Errorf("app list style setting not found")
 	}
@@ -194,7 +197,7 @@ func RefreshAppList() error {
 	}
 
 	// Delete preload directory
-	preloadDir := filepath.Join(directory, "data", "preload")
+	preloadDir := filepath.Join(DataDirOf(directory), "preload")
 	err = os.RemoveAll(preloadDir)
 	if err != nil {
 		return fmt.Errorf("error removing preload directory: %w", err)
@@ -215,3 +218,32 @@ func RefreshAppList() error {
 
 	return nil
 }
+
+// appEntryRefreshers update the cached app list entries of a single app, see RegisterAppEntryRefresher
+var appEntryRefreshers []func(app string) error
+
+// RegisterAppEntryRefresher registers a function that RefreshAppEntry calls to update the cached
+// app list entry of an app. The GUI package registers the refresher of its preloaded app lists.
+func RegisterAppEntryRefresher(refresher func(app string) error) {
+	appEntryRefreshers = append(appEntryRefreshers, refresher)
+}
+
+// RefreshAppEntry updates only the cached app list entry and status of an app after its status changed,
+// instead of regenerating the whole app list like RefreshAppList
+//
+// RefreshAppList is still needed when categories change or apps are added or removed.
+func RefreshAppEntry(app string) error {
+	if err := ValidateAppName(app); err != nil {
+		return err
+	}
+
+	// The next status lookup has to see the new status of this app
+	invalidateStatusSnapshot()
+
+	for _, refresher := range appEntryRefreshers {
+		if err := refresher(app); err != nil {
+			return fmt.Errorf("error refreshing app list entry of %s: %w", app, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,198 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: app_metadata.go
+// Description: Provides the metadata of an app (description, website, credits, icons...) as a single bundle.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package api
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// AppMetadata is everything Pi-Apps knows about an app, read from the files in its app folder
+//
+// Optional files that are missing leave their fields empty.
+type AppMetadata struct {
+	Name             string            `json:"name"`
+	ShortDescription string            `json:"short_description"` // first line of the description
+	LongDescription  string            `json:"long_description"`
+	Translations     map[string]string `json:"translations,omitempty"` // locale -> translated description, see DescriptionLocale
+	Website          string            `json:"website"`
+	Credits          string            `json:"credits"`
+	IconPaths        map[int]string    `json:"icon_paths"` // icon size in pixels -> path, e.g. 24 and 64
+	Category         string            `json:"category"`
+	Type             string            `json:"type"`          // "standard", "package" or "flatpak_package"
+	Architectures    []string          `json:"architectures"` // "32" and/or "64" from the install scripts, empty for package apps
+	Status           string            `json:"status"`
+}
+
+// GetAppMetadata reads the metadata of an app
+//
+//	*AppMetadata - metadata of the app
+//	error - error if the app name is invalid or the app does not exist
+func GetAppMetadata(app string) (*AppMetadata, error) {
+	categories, err := ReadCategoryData()
+	if err != nil {
+		return nil, err
+	}
+	metadata, err := readAppMetadata(GetPiAppsDir(), app, categories)
+	if err != nil {
+		return nil, err
+	}
+	status, err := GetAppStatus(app)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get app status: %w", err)
+	}
+	metadata.Status = status
+	metadata.localize(DescriptionLocale())
+	return metadata, nil
+}
+
+// GetAllAppMetadata reads the metadata of every local app, reading the categories and statuses only once
+//
+//	[]*AppMetadata - metadata of every app, sorted by name like ListApps("local")
+//	error - error if PI_APPS_DIR environment variable is not set or the apps cannot be listed
+func GetAllAppMetadata() ([]*AppMetadata, error) {
+	categories, err := ReadCategoryData()
+	if err != nil {
+		return nil, err
+	}
+	all, err := readAllAppMetadata(GetPiAppsDir(), categories)
+	if err != nil {
+		return nil, err
+	}
+	statuses, err := GetAllAppStatuses()
+	if err != nil {
+		return nil, err
+	}
+	locale := DescriptionLocale()
+	for _, metadata := range all {
+		metadata.localize(locale)
+		metadata.Status = string(statuses[metadata.Name])
+		if metadata.Status == "" {
+			metadata.Status = string(AppStateUninstalled)
+		}
+	}
+	return all, nil
+}
+
+// localize shows the descriptions of an app in a locale, if it has a translation for the locale or its language
+func (m *AppMetadata) localize(locale string) {
+	for _, candidate := range descriptionLocales(locale) {
+		if translation, ok := m.Translations[candidate]; ok {
+			m.LongDescription = translation
+			m.ShortDescription = shortDescription(translation)
+			return
+		}
+	}
+}
+
+// readAllAppMetadata reads everything but the status of every app in a Pi-Apps directory, sorted by name.
+// Apps whose metadata can't be read are skipped.
+func readAllAppMetadata(directory string, categories *CategoryData) ([]*AppMetadata, error) {
+	if directory == "" {
+		return nil, fmt.Errorf("PI_APPS_DIR environment variable not set")
+	}
+	apps, err := listLocalApps(directory)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list apps: %w", err)
+	}
+	sort.Strings(apps)
+
+	all := make([]*AppMetadata, 0, len(apps))
+	for _, app := range apps {
+		metadata, err := readAppMetadata(directory, app, categories)
+		if err != nil {
+			Debug(fmt.Sprintf("skipping metadata of %s: %v", app, err))
+			continue
+		}
+		all = append(all, metadata)
+	}
+	return all, nil
+}
+
+// readAppMetadata reads everything but the status of an app from its app folder in a Pi-Apps directory. The
+// descriptions are the default ones, the translations are only listed.
+func readAppMetadata(directory, app string, categories *CategoryData) (*AppMetadata, error) {
+	if err := ValidateAppName(app); err != nil {
+		return nil, err
+	}
+	if directory == "" {
+		return nil, fmt.Errorf("PI_APPS_DIR environment variable not set")
+	}
+	appDir, err := SafeJoin(directory, "apps", app)
+	if err != nil {
+		return nil, err
+	}
+	if !isDir(appDir) {
+		return nil, fmt.Errorf("app '%s' does not exist", app)
+	}
+
+	metadata := &AppMetadata{
+		Name:            app,
+		LongDescription: readAppFile(appDir, "description"),
+		Translations:    appDescriptionTranslations(appDir),
+		Website:         strings.TrimSpace(readAppFile(appDir, "website")),
+		Credits:         readAppFile(appDir, "credits"),
+		IconPaths:       make(map[int]string),
+		Category:        categories.GetAppCategory(app),
+	}
+	metadata.ShortDescription = shortDescription(metadata.LongDescription)
+
+	if icons, err := filepath.Glob(filepath.Join(appDir, "icon-*.png")); err == nil {
+		for _, icon := range icons {
+			size := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(icon), "icon-"), ".png")
+			if n, err := strconv.Atoi(size); err == nil {
+				metadata.IconPaths[n] = icon
+			}
+		}
+	}
+
+	if appType, err := appTypeIn(directory, app); err == nil {
+		metadata.Type = appType
+	}
+	if metadata.Type == "standard" {
+		switch {
+		case FileExists(filepath.Join(appDir, "install")):
+			metadata.Architectures = []string{"32", "64"}
+		default:
+			if FileExists(filepath.Join(appDir, "install-32")) {
+				metadata.Architectures = append(metadata.Architectures, "32")
+			}
+			if FileExists(filepath.Join(appDir, "install-64")) {
+				metadata.Architectures = append(metadata.Architectures, "64")
+			}
+		}
+	}
+
+	return metadata, nil
+}
+
+// readAppFile returns the contents of a file in an app folder, or "" if it does not exist
+func readAppFile(appDir, name string) string {
+	data, err := os.ReadFile(filepath.Join(appDir, name))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimRight(string(data), "\n")
+}
@@ -0,0 +1,114 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: app_name.go
+// Description: Provides validation of app names and path helpers that keep app paths inside the Pi-Apps directory.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package api
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// maxAppNameLength is the longest app name accepted, in bytes
+const maxAppNameLength = 100
+
+// appNamePunctuation lists the characters besides letters, digits and spaces that app names may contain.
+// Path separators, the ; of manage queue lines and control characters are never allowed.
+const appNamePunctuation = "-_.+()[]&,'!"
+
+// ValidateAppName checks that an app name is safe to use as a file name in the Pi-Apps directory
+//
+// App names may contain letters and digits of any script, combining marks, spaces and the characters -_.+()[]&,'!
+// They may not start with a dot, contain "..", start or end with a space, or be longer than 100 bytes.
+//
+//	error - error describing why the name is not valid
+func ValidateAppName(name string) error {
+	if name == "" {
+		return fmt.Errorf("app name is empty")
+	}
+	if len(name) > maxAppNameLength {
+		return fmt.Errorf("app name is longer than %d characters", maxAppNameLength)
+	}
+	if strings.HasPrefix(name, ".") {
+		return fmt.Errorf("app name '%s' starts with a dot", name)
+	}
+	if strings.Contains(name, "..") {
+		return fmt.Errorf("app name '%s' contains '..'", name)
+	}
+	if strings.TrimSpace(name) != name {
+		return fmt.Errorf("app name '%s' starts or ends with a space", name)
+	}
+
+	for _, r := range name {
+		switch {
+		case unicode.IsLetter(r), unicode.IsDigit(r), unicode.IsMark(r), r == ' ':
+		case strings.ContainsRune(appNamePunctuation, r):
+		default:
+			return fmt.Errorf("app name %q contains the invalid character %q", name, r)
+		}
+	}
+	return nil
+}
+
+// SafeJoin joins path elements to base and makes sure the result does not escape base
+//
+//	string - the joined path
+//	error - error if the joined path is outside of base
+func SafeJoin(base string, elem ...string) (string, error) {
+	base = filepath.Clean(base)
+	joined := filepath.Join(append([]string{base}, elem...)...)
+
+	rel, err := filepath.Rel(base, joined)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("path %s is outside of %s", joined, base)
+	}
+	return joined, nil
+}
+
+// AppPath returns the path of an app's directory, or of a file in it, after validating the app name
+//
+//	string - path inside PI_APPS_DIR/apps/<app>
+//	error - error if the app name is not valid or PI_APPS_DIR environment variable is not set
+func AppPath(app string, elem ...string) (string, error) {
+	if err := ValidateAppName(app); err != nil {
+		return "", err
+	}
+	directory := GetPiAppsDir()
+	if directory == "" {
+		return "", fmt.Errorf("PI_APPS_DIR environment variable not set")
+	}
+	return SafeJoin(directory, append([]string{"apps", app}, elem...)...)
+}
+
+// AppDataPath returns the path of an app's file in a data subdirectory (e.g. data/status/<app>) after validating the app name
+//
+//	string - path of PI_APPS_DIR/data/<kind>/<app>
+//	error - error if the app name is not valid or PI_APPS_DIR environment variable is not set
+func AppDataPath(kind, app string) (string, error) {
+	if err := ValidateAppName(app); err != nil {
+		return "", err
+	}
+	directory := GetPiAppsDir()
+	if directory == "" {
+		return "", fmt.Errorf("PI_APPS_DIR environment variable not set")
+	}
+	return SafeJoin(DataDirOf(directory), kind, app)
+}
@@ -0,0 +1,148 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidateAppName(t *testing.T) {
+	valid := []string{
+		"Zoom",
+		"Better Chromium",
+		"Box64 (x86_64 emulator)",
+		"Rock 'n' Roll",
+		"C++ Compiler",
+		"Café",
+		"日本語入力",
+		"Ελληνικά",
+		"हिन्दी Keyboard",
+		"v1.2",
+	}
+	for _, name := range valid {
+		if err := ValidateAppName(name); err != nil {
+			t.Errorf("ValidateAppName(%q) = %v, want it to be valid", name, err)
+		}
+	}
+
+	invalid := []string{
+		"",
+		".hidden",
+		"..",
+		"apps/../etc",
+		"a/b",
+		"install;Zoom",
+		"a..b",
+		" Zoom",
+		"Zoom ",
+		"tab\tname",
+		"new\nline",
+		"nul\x00byte",
+		"bad\xffutf8",
+		"right‮left",
+		"$(reboot)",
+		strings.Repeat("a", maxAppNameLength+1),
+	}
+	for _, name := range invalid {
+		if err := ValidateAppName(name); err == nil {
+			t.Errorf("ValidateAppName(%q) succeeded, want an error", name)
+		}
+	}
+}
+
+func TestSafeJoin(t *testing.T) {
+	base := filepath.Join(t.TempDir(), "pi-apps")
+	tests := []struct {
+		elem    []string
+		wantErr bool
+	}{
+		{[]string{"apps", "Zoom"}, false},
+		{[]string{"apps", "Box64 (x86_64 emulator)", "install"}, false},
+		{[]string{"apps", "a/../b"}, false},
+		{[]string{"apps", "../../etc/passwd"}, true},
+		{[]string{"..", "pi-apps-evil"}, true},
+		{[]string{"logs", "..", ".."}, true},
+	}
+	for _, tt := range tests {
+		path, err := SafeJoin(base, tt.elem...)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("SafeJoin(%q) = %s, want an error", tt.elem, path)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("SafeJoin(%q): %v", tt.elem, err)
+		} else if !strings.HasPrefix(path, base+string(filepath.Separator)) {
+			t.Errorf("SafeJoin(%q) = %s, want a path under %s", tt.elem, path, base)
+		}
+	}
+}
+
+func TestAppNameEntryPoints(t *testing.T) {
+	directory := newTestPiAppsDir(t, "Box64 (x86_64 emulator)")
+	outside := filepath.Join(filepath.Dir(directory), "outside")
+	writeTestFile(t, filepath.Join(directory, "apps", "Box64 (x86_64 emulator)", "install"), "#!/bin/bash\n")
+
+	// Legitimate names with spaces and parentheses keep working
+	legit := "Box64 (x86_64 emulator)"
+	if _, err := AppPath(legit, "install"); err != nil {
+		t.Errorf("AppPath(%q): %v", legit, err)
+	}
+	if err := SetAppStatus(legit, "installed"); err != nil {
+		t.Errorf("SetAppStatus(%q): %v", legit, err)
+	}
+	if status, err := GetAppStatus(legit); err != nil || status != "installed" {
+		t.Errorf("GetAppStatus(%q) = %q, %v, want installed", legit, status, err)
+	}
+	if err := EditAppCategory(legit, "Tools", false); err != nil {
+		t.Errorf("EditAppCategory(%q): %v", legit, err)
+	}
+
+	for _, name := range []string{"../../outside", "../status/x", "new\nline", ""} {
+		if _, err := AppPath(name); err == nil {
+			t.Errorf("AppPath(%q) succeeded", name)
+		}
+		if _, err := AppDataPath("status", name); err == nil {
+			t.Errorf("AppDataPath(%q) succeeded", name)
+		}
+		if err := SetAppStatus(name, "installed"); err == nil {
+			t.Errorf("SetAppStatus(%q) succeeded", name)
+		}
+		if _, err := GetAppStatus(name); err == nil {
+			t.Errorf("GetAppStatus(%q) succeeded", name)
+		}
+		if err := EditAppCategory(name, "Tools", false); err == nil {
+			t.Errorf("EditAppCategory(%q) succeeded", name)
+		}
+		if logfile := GetLogfile(name); logfile != "" {
+			t.Errorf("GetLogfile(%q) = %s, want \"\"", name, logfile)
+		}
+	}
+	if err := EditAppCategory(legit, "Tools\nEvil|hidden", true); err == nil {
+		t.Error("EditAppCategory accepted a category with a newline")
+	}
+	if err := SetAppStatus(legit, "installed\ncorrupted"); err == nil {
+		t.Error("SetAppStatus accepted a status with a newline")
+	}
+	if _, err := os.Stat(outside); !os.IsNotExist(err) {
+		t.Errorf("a file was written outside of the Pi-Apps directory: %v", err)
+	}
+}
@@ -0,0 +1,313 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: app_repair.go
+// Description: Provides the repair assistant of broken apps: finds out what is left of an app and proposes how to repair it.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package api
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+
+	"github.com/pi-apps-go/pi-apps/pkg/api/errs"
+)
+
+// RepairAction is a way RepairApp can repair an app
+type RepairAction string
+
+const (
+	// RepairMarkUninstalled marks the app as uninstalled without running anything, for apps of which nothing is installed
+	RepairMarkUninstalled RepairAction = "mark-uninstalled"
+	// RepairReinstall runs the uninstall script, then installs the app again
+	RepairReinstall RepairAction = "reinstall"
+	// RepairInstallOver installs the app again over what is installed, like the Install button of a corrupted app
+	RepairInstallOver RepairAction = "install-over"
+)
+
+// RepairReport is what RepairApp found out about an app and how it proposes to repair it
+type RepairReport struct {
+	App    string `json:"app"`
+	Status string `json:"status"`
+	// StatusProblems are the problems the status audit found for the app
+	StatusProblems []string `json:"status_problems"`
+	// Health is the result of the app's healthcheck, nil if it has none
+	Health *HealthResult `json:"health,omitempty"`
+	// UninstallProblems are what would make the uninstall script fail, like a syntax error
+	UninstallProblems []string `json:"uninstall_problems"`
+	// MissingFiles are the files of the install manifest that are gone
+	MissingFiles []string `json:"missing_files"`
+	// NothingPresent is true when none of what the app installed is left: no recorded file, dummy deb or package
+	NothingPresent bool `json:"nothing_present"`
+	// Actions are the applicable ways to repair the app, the safest first. It is empty if nothing needs repairing.
+	Actions []RepairAction `json:"actions"`
+	// Chosen is the action RepairApp ran, "" if it ran none
+	Chosen RepairAction `json:"chosen,omitempty"`
+	// Outcome is what was recorded in the history log: the status of the app afterwards, "queued" if the running
+	// manage daemon got the actions, or "cancelled"
+	Outcome string `json:"outcome,omitempty"`
+}
+
+// Label returns the description of a repair action shown when choosing it
+func (a RepairAction) Label() string {
+	switch a {
+	case RepairMarkUninstalled:
+		return T("Mark as uninstalled (nothing of it is installed)")
+	case RepairReinstall:
+		return T("Uninstall, then install again")
+	case RepairInstallOver:
+		return T("Install again over what is installed")
+	}
+	return string(a)
+}
+
+// queue returns the manage daemon queue of a repair action, "" for an action that doesn't run a script
+func (a RepairAction) queue(app string) string {
+	switch a {
+	case RepairReinstall:
+		return QueueLine("uninstall", app) + "\n" + QueueLine("install", app)
+	case RepairInstallOver:
+		return QueueLine("install", app)
+	}
+	return ""
+}
+
+// DiagnoseRepair runs the checks of RepairApp without repairing anything: the status audit for the app, its
+// healthcheck if it has one, and a dry run of its uninstall script that looks for files that are missing
+//
+//	RepairReport - what was found, with the applicable actions
+//	error - error if the app doesn't exist or is disabled
+func DiagnoseRepair(app string) (RepairReport, error) {
+	appDir, err := AppPath(app)
+	if err != nil {
+		return RepairReport{}, err
+	}
+	if !isDir(appDir) {
+		return RepairReport{}, fmt.Errorf("app '%s' does not exist", app)
+	}
+	status, err := GetAppStatus(app)
+	if err != nil {
+		return RepairReport{}, err
+	}
+	if status == string(AppStateDisabled) {
+		return RepairReport{}, fmt.Errorf("%s is disabled, enable it before repairing it", app)
+	}
+	report := RepairReport{App: app, Status: status, StatusProblems: []string{}, UninstallProblems: []string{}, MissingFiles: []string{}, Actions: []RepairAction{}}
+
+	StatusTf("Auditing the status of %s...", app)
+	if problems, err := AuditAppStatuses(false); err == nil {
+		for _, problem := range problems {
+			if problem.App == app {
+				report.StatusProblems = append(report.StatusProblems, problem.Problem)
+			}
+		}
+	} else {
+		Debug(fmt.Sprintf("Failed to audit the app statuses: %v", err))
+	}
+
+	// A healthcheck of an app that isn't installed would only report what the status already says
+	if status != string(AppStateUninstalled) && HasHealthcheck(app) {
+		StatusTf("Checking that %s works...", app)
+		result := runHealthcheck(app)
+		if err := recordHealthResult(result); err != nil {
+			Debug(fmt.Sprintf("Failed to record the health check of %s: %v", app, err))
+		}
+		report.Health = &result
+	}
+
+	StatusTf("Checking what the uninstall script of %s would remove...", app)
+	report.UninstallProblems, report.MissingFiles, report.NothingPresent = uninstallDryRun(app)
+	report.Actions = repairActions(report)
+	return report, nil
+}
+
+// uninstallDryRun checks what uninstalling an app would run into without running its uninstall script: whether the
+// script exists and parses, and which of the files it should remove are there. nothingPresent is only true when
+// something records what the app installed and none of it is left.
+func uninstallDryRun(app string) (problems, missing []string, nothingPresent bool) {
+	problems, missing = []string{}, []string{}
+	appType, err := AppType(app)
+	if err != nil {
+		return append(problems, err.Error()), missing, false
+	}
+
+	switch appType {
+	case "package":
+		packagesFile, _ := AppPath(app, "packages")
+		installed, err := InstalledPackages()
+		if err != nil {
+			Debug(fmt.Sprintf("failed to get installed packages: %v", err))
+			return problems, missing, false
+		}
+		return problems, missing, !pkgAppInstalled(packagesFile, installed)
+	case "standard":
+	default:
+		// Nothing records what a flatpak-app installed outside of flatpak
+		return problems, missing, false
+	}
+
+	script, _ := AppPath(app, "uninstall")
+	if !FileExists(script) {
+		problems = append(problems, T("the app has no uninstall script"))
+	} else if output, err := exec.Command("bash", "-n", script).CombinedOutput(); err != nil {
+		problems = append(problems, Tf("the uninstall script has a syntax error: %s", lastLine(string(output))))
+	}
+
+	manifest, err := ReadInstallManifest(app)
+	if err != nil {
+		Debug(fmt.Sprintf("Failed to read the install manifest of %s: %v", app, err))
+	}
+	present := 0
+	for _, file := range manifest.Files {
+		if _, err := os.Lstat(file); err == nil {
+			present++
+		} else {
+			missing = append(missing, file)
+		}
+	}
+	dummyDeb := false
+	if pkg, err := AppToPkgName(app); err == nil {
+		dummyDeb = PackageInstalled(pkg)
+	}
+	return problems, missing, len(manifest.Files) > 0 && present == 0 && !dummyDeb
+}
+
+// repairActions returns the actions that apply to what DiagnoseRepair found, the safest first: marking an app of
+// which nothing is left as uninstalled changes nothing on the system, installing over the top keeps the files
+// and data of the app, and uninstalling first is only offered when the uninstall script looks like it can run.
+// It returns no actions for an app that looks fine.
+func repairActions(report RepairReport) []RepairAction {
+	unhealthy := report.Health != nil && !report.Health.Healthy
+	broken := report.Status == string(AppStateCorrupted) || unhealthy || len(report.StatusProblems) > 0 || len(report.MissingFiles) > 0
+	if !broken || (report.Status == string(AppStateUninstalled) && report.NothingPresent) {
+		return []RepairAction{}
+	}
+
+	var actions []RepairAction
+	if report.NothingPresent && !unhealthy {
+		actions = append(actions, RepairMarkUninstalled)
+	}
+	actions = append(actions, RepairInstallOver)
+	if !report.NothingPresent && len(report.UninstallProblems) == 0 {
+		actions = append(actions, RepairReinstall)
+	}
+	return actions
+}
+
+// RepairApp finds out what is wrong with an app like DiagnoseRepair and repairs it. Interactively, the user
+// chooses one of the applicable actions, otherwise the safest one is taken. Installs and uninstalls run through
+// the manage daemon queue, so they get their logs and summary like any other. The outcome is recorded in
+// the history log.
+//
+//	RepairReport - what was found, the chosen action and its outcome
+//	error - error if the app can't be checked, the user cancelled (errs.ErrCancelled) or the action failed
+func RepairApp(app string, interactive bool) (RepairReport, error) {
+	report, err := DiagnoseRepair(app)
+	if err != nil {
+		return report, err
+	}
+	if len(report.Actions) == 0 {
+		return report, nil
+	}
+
+	report.Chosen = report.Actions[0]
+	if interactive {
+		options := make([]string, 0, len(report.Actions)+1)
+		for _, action := range report.Actions {
+			options = append(options, action.Label())
+		}
+		options = append(options, T("Cancel"))
+		answer, err := UserInputFunc(RepairSummary(report)+"\n\n"+Tf("How should %s be repaired?", app), options...)
+		index := slices.Index(options[:len(report.Actions)], answer)
+		if err != nil || index < 0 {
+			report.Chosen = ""
+			report.Outcome = "cancelled"
+			recordRepairOutcome(report)
+			return report, errs.New(errs.ErrCancelled, "repairing %s was cancelled", app)
+		}
+		report.Chosen = report.Actions[index]
+	}
+
+	err = runRepairAction(app, report.Chosen, &report)
+	recordRepairOutcome(report)
+	return report, err
+}
+
+// runRepairAction runs a repair action of an app and sets the outcome of the report
+func runRepairAction(app string, action RepairAction, report *RepairReport) error {
+	if action == RepairMarkUninstalled {
+		lock, err := LockState("mark " + app + " uninstalled")
+		if err != nil {
+			return err
+		}
+		defer lock.Release()
+		if err := SetAppStatus(app, string(AppStateUninstalled)); err != nil {
+			return err
+		}
+		// The manifest only lists files that are gone
+		if err := clearInstallManifest(app); err != nil {
+			Debug(fmt.Sprintf("Failed to clear the install manifest of %s: %v", app, err))
+		}
+		report.Outcome = string(AppStateUninstalled)
+		return nil
+	}
+
+	// A running daemon only adds the actions to its queue, it runs them after this returns
+	queued := localQueueRunning(GetPiAppsDir())
+	err := TerminalManageMulti(action.queue(app))
+	if queued && err == nil {
+		report.Outcome = "queued"
+	} else {
+		report.Outcome, _ = GetAppStatus(app)
+	}
+	return err
+}
+
+// RepairSummary describes what DiagnoseRepair found, one finding per line
+func RepairSummary(report RepairReport) string {
+	lines := []string{Tf("%s is %s.", report.App, report.Status)}
+	for _, problem := range report.StatusProblems {
+		lines = append(lines, Tf("Status: %s", problem))
+	}
+	if report.Health != nil {
+		if report.Health.Healthy {
+			lines = append(lines, T("Its health check passed."))
+		} else {
+			lines = append(lines, Tf("Its health check failed: %s", report.Health.Problem()))
+		}
+	}
+	for _, problem := range report.UninstallProblems {
+		lines = append(lines, Tf("Uninstall: %s", problem))
+	}
+	if len(report.MissingFiles) > 0 {
+		lines = append(lines, Tf("%d of the files it installed are missing, like %s", len(report.MissingFiles), report.MissingFiles[0]))
+	}
+	if report.NothingPresent {
+		lines = append(lines, T("Nothing it installed is left."))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// recordRepairOutcome adds the outcome of a repair to the history log, warning when it can't
+func recordRepairOutcome(report RepairReport) {
+	if err := recordHistory(report.App, historyRepair, string(report.Chosen), report.Outcome); err != nil {
+		WarningTf("Failed to record the repair of %s: %v", report.App, err)
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestRepairActions(t *testing.T) {
+	failed := &HealthResult{ExitCode: 1}
+	tests := []struct {
+		name   string
+		report RepairReport
+		want   []RepairAction
+	}{
+		{"fine", RepairReport{Status: "installed"}, []RepairAction{}},
+		{"uninstalled and gone", RepairReport{Status: "uninstalled", NothingPresent: true}, []RepairAction{}},
+		{"corrupted and gone", RepairReport{Status: "corrupted", NothingPresent: true}, []RepairAction{RepairMarkUninstalled, RepairInstallOver}},
+		{"corrupted", RepairReport{Status: "corrupted"}, []RepairAction{RepairInstallOver, RepairReinstall}},
+		{"broken uninstall script", RepairReport{Status: "corrupted", UninstallProblems: []string{"syntax error"}}, []RepairAction{RepairInstallOver}},
+		{"failed healthcheck", RepairReport{Status: "installed", Health: failed}, []RepairAction{RepairInstallOver, RepairReinstall}},
+		{"missing files", RepairReport{Status: "installed", MissingFiles: []string{"/usr/bin/x"}}, []RepairAction{RepairInstallOver, RepairReinstall}},
+	}
+	for _, test := range tests {
+		if got := repairActions(test.report); !slices.Equal(got, test.want) {
+			t.Errorf("%s: repairActions = %q, want %q", test.name, got, test.want)
+		}
+	}
+}
+
+func TestUninstallDryRun(t *testing.T) {
+	directory := newTestPiAppsDir(t, "Game")
+	writeTestFile(t, filepath.Join(directory, "apps", "Game", "install"), "#!/bin/bash\n")
+	writeTestFile(t, filepath.Join(directory, "apps", "Game", "uninstall"), "#!/bin/bash\nif true; then\n")
+	present := filepath.Join(t.TempDir(), "game")
+	writeTestFile(t, present, "")
+	gone := filepath.Join(t.TempDir(), "game.desktop")
+	if err := writeInstalledFiles("Game", []string{present, gone}, ResourceLimits{}, false); err != nil {
+		t.Fatal(err)
+	}
+
+	problems, missing, nothingPresent := uninstallDryRun("Game")
+	if len(problems) != 1 || !strings.Contains(problems[0], "syntax error") {
+		t.Errorf("uninstall problems = %q, want the syntax error", problems)
+	}
+	if !slices.Equal(missing, []string{gone}) || nothingPresent {
+		t.Errorf("missing = %q, nothing present = %v, want %q and false", missing, nothingPresent, gone)
+	}
+
+	os.Remove(present)
+	if _, _, nothingPresent := uninstallDryRun("Game"); !nothingPresent {
+		t.Error("nothing present = false after every recorded file was removed")
+	}
+
+	// Without a manifest, nothing tells what the install script left behind
+	if err := removeInstalledFiles("Game"); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, nothingPresent := uninstallDryRun("Game"); nothingPresent {
+		t.Error("nothing present = true without an install manifest")
+	}
+}
+
+func TestRepairAppMarksUninstalled(t *testing.T) {
+	directory := newTestPiAppsDir(t, "Game")
+	writeTestFile(t, filepath.Join(directory, "apps", "Game", "install"), "#!/bin/bash\n")
+	writeTestFile(t, filepath.Join(directory, "apps", "Game", "uninstall"), "#!/bin/bash\n")
+	if err := SetAppStatus("Game", "corrupted"); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeInstalledFiles("Game", []string{filepath.Join(t.TempDir(), "game")}, ResourceLimits{}, false); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := RepairApp("Game", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Chosen != RepairMarkUninstalled || report.Outcome != "uninstalled" {
+		t.Errorf("RepairApp chose %q with outcome %q, want mark-uninstalled with outcome uninstalled", report.Chosen, report.Outcome)
+	}
+	if status, _ := GetAppStatus("Game"); status != "uninstalled" {
+		t.Errorf("status after the repair = %q, want uninstalled", status)
+	}
+	if files, _ := ReadInstalledFiles("Game"); len(files) != 0 {
+		t.Errorf("install manifest after the repair = %q, want no files", files)
+	}
+
+	if entries := readHistory("Game", historyRepair); len(entries) != 1 || !slices.Equal(entries[0].Details, []string{"mark-uninstalled", "uninstalled"}) {
+		t.Errorf("repair history = %+v", entries)
+	}
+
+	// Once marked, there is nothing left to repair
+	if report, err := RepairApp("Game", false); err != nil || len(report.Actions) != 0 || report.Chosen != "" {
+		t.Errorf("second RepairApp = %+v, %v, want nothing to repair", report, err)
+	}
+}
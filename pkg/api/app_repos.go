@@ -0,0 +1,366 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: app_repos.go
+// Description: Manages extra app repositories, like private ones of an organization, whose apps are merged into the catalog next to the official ones.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// appReposFile lists the extra app repositories in data/settings, one "<name> <git-url> [branch]" per line
+const appReposFile = "extra-app-repos"
+
+// appRepoCredentialsFile holds the access tokens of private app repositories in data/settings, one "<name> <token>" per line
+const appRepoCredentialsFile = "extra-app-repos-credentials"
+
+// appRepoNamePattern limits source names to what is safe as a folder name and an environment variable suffix
+var appRepoNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// AppRepo is an extra app repository whose apps/ folder is merged into the catalog
+type AppRepo struct {
+	Name   string `json:"name"`             // namespace of the source, shown as a badge next to its apps
+	URL    string `json:"url"`              // git URL to clone
+	Branch string `json:"branch,omitempty"` // branch to clone, the default branch of the remote if empty
+}
+
+// AppRepoDir returns the folder the clone of an extra app repository is kept in
+func AppRepoDir(name string) string {
+	return filepath.Join(GetPiAppsDir(), "update", "repos", name)
+}
+
+// validateAppRepoName checks that a source name can be used as a folder name and doesn't shadow the official repository
+func validateAppRepoName(name string) error {
+	if !appRepoNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid app repository name '%s': only letters, digits, '.', '_' and '-' are allowed", name)
+	}
+	if strings.EqualFold(name, "pi-apps") || strings.EqualFold(name, "official") {
+		return fmt.Errorf("the app repository name '%s' is reserved", name)
+	}
+	return nil
+}
+
+// ReadAppRepos returns the extra app repositories in the order of data/settings/extra-app-repos.
+// Earlier sources win name collisions over later ones.
+func ReadAppRepos() ([]AppRepo, error) {
+	file, err := os.Open(filepath.Join(GetDataDir(), "settings", appReposFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	var repos []AppRepo
+	scanner := bufio.NewScanner(file)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 2 || len(fields) > 3 {
+			return nil, fmt.Errorf("%s line %d: expected '<name> <git-url> [branch]'", appReposFile, lineNumber)
+		}
+		repo := AppRepo{Name: fields[0], URL: fields[1]}
+		if len(fields) == 3 {
+			repo.Branch = fields[2]
+		}
+		if err := validateAppRepoName(repo.Name); err != nil {
+			return nil, fmt.Errorf("%s line %d: %w", appReposFile, lineNumber, err)
+		}
+		repos = append(repos, repo)
+	}
+	return repos, scanner.Err()
+}
+
+// writeAppRepos replaces data/settings/extra-app-repos with the given sources
+func writeAppRepos(repos []AppRepo) error {
+	var content strings.Builder
+	content.WriteString("# Extra app repositories: <name> <git-url> [branch]\n")
+	for _, repo := range repos {
+		line := repo.Name + " " + repo.URL
+		if repo.Branch != "" {
+			line += " " + repo.Branch
+		}
+		content.WriteString(line + "\n")
+	}
+	path := filepath.Join(GetDataDir(), "settings", appReposFile)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(content.String()), 0644)
+}
+
+// AddAppRepo adds an extra app repository, it is cloned on the next update check or with SyncAppRepo
+func AddAppRepo(repo AppRepo) error {
+	if err := validateAppRepoName(repo.Name); err != nil {
+		return err
+	}
+	if repo.URL == "" {
+		return fmt.Errorf("no git URL given for app repository '%s'", repo.Name)
+	}
+	repos, err := ReadAppRepos()
+	if err != nil {
+		return err
+	}
+	for _, existing := range repos {
+		if existing.Name == repo.Name {
+			return fmt.Errorf("app repository '%s' already exists", repo.Name)
+		}
+	}
+	return writeAppRepos(append(repos, repo))
+}
+
+// RemoveAppRepo removes an extra app repository and its clone. The apps copied from it stay in the apps folder,
+// use AppSourceApps before removing it to uninstall or delete them.
+func RemoveAppRepo(name string) error {
+	repos, err := ReadAppRepos()
+	if err != nil {
+		return err
+	}
+	index := slices.IndexFunc(repos, func(repo AppRepo) bool { return repo.Name == name })
+	if index == -1 {
+		return fmt.Errorf("app repository '%s' does not exist", name)
+	}
+	if err := writeAppRepos(slices.Delete(repos, index, index+1)); err != nil {
+		return err
+	}
+	return os.RemoveAll(AppRepoDir(name))
+}
+
+// appRepoToken returns the access token of a private app repository, from the PI_APPS_REPO_TOKEN_<NAME> environment
+// variable or else from data/settings/extra-app-repos-credentials. Public repositories have none.
+func appRepoToken(name string) string {
+	envName := "PI_APPS_REPO_TOKEN_" + strings.ToUpper(strings.NewReplacer(".", "_", "-", "_").Replace(name))
+	if token := os.Getenv(envName); token != "" {
+		return token
+	}
+
+	path := filepath.Join(GetDataDir(), "settings", appRepoCredentialsFile)
+	file, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+	if info, err := file.Stat(); err == nil && info.Mode().Perm()&0077 != 0 {
+		WarningTf("%s can be read by other users, restrict it with: chmod 600 %s", path, path)
+	}
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && fields[0] == name {
+			return fields[1]
+		}
+	}
+	return ""
+}
+
+// appRepoGit runs git for an extra app repository. The access token is passed as an HTTP header through the
+// environment, so it neither shows up in the process list nor gets saved in the clone's config. The header is
+// scoped to the repository URL, so redirects and submodules on other hosts never receive the token.
+func appRepoGit(ctx context.Context, repo AppRepo, args ...string) error {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	if token := appRepoToken(repo.Name); token != "" {
+		credentials := base64.StdEncoding.EncodeToString([]byte("pi-apps:" + token))
+		cmd.Env = append(cmd.Env,
+			"GIT_CONFIG_COUNT=1",
+			"GIT_CONFIG_KEY_0=http."+repo.URL+".extraHeader",
+			"GIT_CONFIG_VALUE_0=Authorization: Basic "+credentials,
+		)
+	}
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git %s failed: %w\n%s", args[0], err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// SyncAppRepo clones or pulls an extra app repository. A clone whose remote or branch doesn't match the settings
+// anymore, or that can't be pulled, is cloned again.
+func SyncAppRepo(ctx context.Context, repo AppRepo) error {
+	dir := AppRepoDir(repo.Name)
+	if DirExists(filepath.Join(dir, ".git")) {
+		remote, _ := exec.Command("git", "-C", dir, "remote", "get-url", "origin").Output()
+		branch, _ := exec.Command("git", "-C", dir, "rev-parse", "--abbrev-ref", "HEAD").Output()
+		if strings.TrimSpace(string(remote)) == repo.URL && (repo.Branch == "" || strings.TrimSpace(string(branch)) == repo.Branch) {
+			if err := appRepoGit(ctx, repo, "-C", dir, "pull", "-q", "--ff-only"); err == nil {
+				return nil
+			}
+			Debug(fmt.Sprintf("Pulling app repository %s failed, cloning it again", repo.Name))
+		}
+	}
+
+	if err := os.RemoveAll(dir); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dir), 0755); err != nil {
+		return err
+	}
+	args := []string{"clone", "-q", "--depth=1"}
+	if repo.Branch != "" {
+		args = append(args, "-b", repo.Branch)
+	}
+	if err := appRepoGit(ctx, repo, append(args, repo.URL, dir)...); err != nil {
+		os.RemoveAll(dir)
+		return fmt.Errorf("failed to clone app repository %s: %w", repo.Name, err)
+	}
+	return nil
+}
+
+// SyncAppRepos clones or pulls every extra app repository, removes the clones of sources that are gone and checks
+// the apps of every source for name collisions. A source that fails to sync or has colliding apps doesn't stop the
+// others, the returned error lists every problem. Colliding apps are left out of the catalog.
+func SyncAppRepos(ctx context.Context) error {
+	repos, err := ReadAppRepos()
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, repo := range repos {
+		if err := SyncAppRepo(ctx, repo); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	entries, _ := os.ReadDir(filepath.Join(GetPiAppsDir(), "update", "repos"))
+	for _, entry := range entries {
+		if !slices.ContainsFunc(repos, func(repo AppRepo) bool { return repo.Name == entry.Name() }) {
+			os.RemoveAll(AppRepoDir(entry.Name()))
+		}
+	}
+
+	_, collisions := appSources(repos)
+	errs = append(errs, collisions...)
+	return errors.Join(errs...)
+}
+
+// appRepoApps lists the app folders in the clone of an extra app repository
+func appRepoApps(name string) []string {
+	entries, err := os.ReadDir(filepath.Join(AppRepoDir(name), "apps"))
+	if err != nil {
+		return nil
+	}
+	var apps []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			apps = append(apps, entry.Name())
+		}
+	}
+	return apps
+}
+
+// appSources maps the apps of the extra app repositories to their source. Apps named like an official app or like
+// an app of an earlier source are left out and returned as collision errors.
+func appSources(repos []AppRepo) (map[string]string, []error) {
+	directory := GetPiAppsDir()
+	sources := make(map[string]string)
+	var collisions []error
+	for _, repo := range repos {
+		for _, app := range appRepoApps(repo.Name) {
+			if officialAppExists(directory, app) {
+				collisions = append(collisions, fmt.Errorf("app repository %s: app '%s' has the name of an official app and is ignored, rename it in the repository", repo.Name, app))
+				continue
+			}
+			if source, ok := sources[app]; ok {
+				collisions = append(collisions, fmt.Errorf("app repository %s: app '%s' is already provided by app repository %s and is ignored", repo.Name, app, source))
+				continue
+			}
+			sources[app] = repo.Name
+		}
+	}
+	return sources, collisions
+}
+
+// AppSource returns the extra app repository an app comes from, or "" for official and local apps
+func AppSource(app string) string {
+	if officialAppExists(GetPiAppsDir(), app) {
+		return ""
+	}
+	repos, err := ReadAppRepos()
+	if err != nil {
+		return ""
+	}
+	for _, repo := range repos {
+		if DirExists(filepath.Join(AppRepoDir(repo.Name), "apps", app)) {
+			return repo.Name
+		}
+	}
+	return ""
+}
+
+// AppSourceApps returns the apps an extra app repository provides, without the ones that collide
+func AppSourceApps(name string) []string {
+	repos, err := ReadAppRepos()
+	if err != nil {
+		return nil
+	}
+	sources, _ := appSources(repos)
+	var apps []string
+	for app, source := range sources {
+		if source == name {
+			apps = append(apps, app)
+		}
+	}
+	slices.Sort(apps)
+	return apps
+}
+
+// sourceOnlineApps lists the apps of all extra app repositories that don't collide with official ones
+func sourceOnlineApps() []string {
+	repos, err := ReadAppRepos()
+	if err != nil {
+		Debug(fmt.Sprintf("Failed to read the extra app repositories: %v", err))
+		return nil
+	}
+	sources, _ := appSources(repos)
+	apps := make([]string, 0, len(sources))
+	for app := range sources {
+		apps = append(apps, app)
+	}
+	slices.Sort(apps)
+	return apps
+}
+
+// AppUpdateRoot returns the clone an app is updated from: the official update clone, or the clone of its extra
+// app repository
+func AppUpdateRoot(app string) string {
+	if source := AppSource(app); source != "" {
+		return AppRepoDir(source)
+	}
+	return filepath.Join(GetPiAppsDir(), "update", "pi-apps")
+}
+
+// AppUpdateDir returns the folder of an app in the clone it is updated from
+func AppUpdateDir(app string) string {
+	return filepath.Join(AppUpdateRoot(app), "apps", app)
+}
@@ -0,0 +1,217 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: app_requirements.go
+// Description: Reads the requirements apps declare in their requirements file and checks them before an install.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package api
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/pi-apps-go/pi-apps/pkg/api/errs"
+)
+
+// AppRequirements are the requirements an app declares in apps/<app>/requirements
+//
+// The file has one requirement per line, either "key" or "key=value", and # starts a comment.
+// Unknown keys are ignored so older versions of Pi-Apps can read newer files.
+type AppRequirements struct {
+	RequiresX11     bool `json:"requires_x11"`     // the app needs an X11 session or XWayland
+	RequiresWayland bool `json:"requires_wayland"` // the app needs a Wayland session
+
+	// Systems the app can be installed on, the app list hides the app elsewhere, see UnavailableApps
+	Arch                []string `json:"arch,omitempty"`                  // e.g. arch=arm64,armhf for the userland architectures
+	OS                  []string `json:"os,omitempty"`                    // e.g. os=debian,raspbian or os=bookworm, OS IDs or codenames
+	NotOS               []string `json:"not_os,omitempty"`                // e.g. not_os=ubuntu
+	RequiresRaspberryPi bool     `json:"requires_raspberry_pi,omitempty"` // the app only works on Raspberry Pi hardware
+
+	// Resource limits for the install script, see ResourceLimits
+	CPUQuota  string `json:"cpu_quota,omitempty"`  // e.g. cpu_quota=200%
+	MemoryMax string `json:"memory_max,omitempty"` // e.g. memory_max=1G or memory_max=75%
+	IOWeight  string `json:"io_weight,omitempty"`  // e.g. io_weight=50
+
+	// Memory one compile job needs in MB, see BuildResourceAdvice
+	PerJobMemMB int `json:"per_job_mem_mb,omitempty"` // e.g. per_job_mem=800
+
+	// Graphics stack the app needs, see GetGPUInfo
+	MinGPUMemMB    int  `json:"min_gpu_mem_mb,omitempty"`  // e.g. min_gpu_mem_mb=128, only checked on Raspberry Pi
+	RequiresVulkan bool `json:"requires_vulkan,omitempty"` // the app needs a Vulkan driver
+	RequiresKMS    bool `json:"requires_kms,omitempty"`    // the app needs the full KMS driver
+}
+
+// ReadAppRequirements reads the requirements file of an app
+//
+//	AppRequirements - the declared requirements, empty if the app has no requirements file
+//	error - error if the app name is not valid or the file can't be read
+func ReadAppRequirements(app string) (AppRequirements, error) {
+	var requirements AppRequirements
+	path, err := AppPath(app, "requirements")
+	if err != nil {
+		return requirements, err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return requirements, nil
+		}
+		return requirements, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		key, value, hasValue := strings.Cut(strings.TrimSpace(line), "=")
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+		enabled := !hasValue || isTrueValue(value)
+
+		switch key {
+		case "requires_x11":
+			requirements.RequiresX11 = enabled
+		case "requires_wayland":
+			requirements.RequiresWayland = enabled
+		case "arch":
+			requirements.Arch = splitRequirementList(value)
+		case "os":
+			requirements.OS = splitRequirementList(value)
+		case "not_os":
+			requirements.NotOS = splitRequirementList(value)
+		case "requires_raspberry_pi":
+			requirements.RequiresRaspberryPi = enabled
+		case "cpu_quota":
+			requirements.CPUQuota = value
+		case "memory_max":
+			requirements.MemoryMax = value
+		case "io_weight":
+			requirements.IOWeight = value
+		case "per_job_mem":
+			if mb, err := strconv.Atoi(strings.TrimSuffix(strings.TrimSuffix(value, "MB"), "M")); err == nil && mb > 0 {
+				requirements.PerJobMemMB = mb
+			}
+		case "min_gpu_mem_mb":
+			if mb, err := strconv.Atoi(strings.TrimSuffix(strings.TrimSuffix(value, "MB"), "M")); err == nil && mb > 0 {
+				requirements.MinGPUMemMB = mb
+			}
+		case "requires_vulkan":
+			requirements.RequiresVulkan = enabled
+		case "requires_kms":
+			requirements.RequiresKMS = enabled
+		}
+	}
+	return requirements, scanner.Err()
+}
+
+// isTrueValue reports whether a requirement value enables it
+func isTrueValue(value string) bool {
+	switch strings.ToLower(value) {
+	case "1", "true", "yes", "y", "on":
+		return true
+	}
+	return false
+}
+
+// splitRequirementList splits a comma separated requirement value
+func splitRequirementList(value string) []string {
+	var list []string
+	for item := range strings.SplitSeq(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// PreflightCheck checks the requirements of an app against the system before it is installed
+//
+// Requirements that can't be met return an error, requirements that may not be met print a warning.
+func PreflightCheck(app string) error {
+	requirements, err := ReadAppRequirements(app)
+	if err != nil {
+		return fmt.Errorf("failed to read requirements of %s: %w", app, err)
+	}
+	if reason := requirementsUnavailableReason(requirements); reason != "" {
+		return errs.New(errs.ErrUnsupportedArch, "%s can't be installed on this system: %s", app, reason)
+	}
+	if requirements.MinGPUMemMB > 0 || requirements.RequiresVulkan || requirements.RequiresKMS {
+		if err := checkGPURequirements(app, requirements, GetGPUInfo()); err != nil {
+			return err
+		}
+	}
+	if !requirements.RequiresX11 && !requirements.RequiresWayland {
+		return nil
+	}
+
+	session := DisplaySessionInfo()
+	switch session.Type {
+	case SessionWayland:
+		if requirements.RequiresX11 {
+			if !session.XWayland {
+				return fmt.Errorf("%s requires an X11 session, but this is a Wayland session without XWayland. Log in to an X11 session to install it", app)
+			}
+			WarningTf("%s requires X11 and will run through XWayland. If it misbehaves, log in to an X11 session instead.", app)
+		}
+	case SessionX11:
+		if requirements.RequiresWayland {
+			return fmt.Errorf("%s requires a Wayland session, but this is an X11 session. Log in to a Wayland session to install it", app)
+		}
+	default:
+		// Installs from a TTY or SSH are allowed, the app may be used from a desktop session later
+		if requirements.RequiresX11 {
+			WarningTf("%s requires an X11 session, but no display session was detected.", app)
+		} else {
+			WarningTf("%s requires a Wayland session, but no display session was detected.", app)
+		}
+	}
+	return nil
+}
+
+// checkGPURequirements checks the graphics requirements of an app against the graphics stack
+//
+// Settings that can't be detected only print a warning.
+func checkGPURequirements(app string, requirements AppRequirements, gpu GPUInfo) error {
+	if requirements.MinGPUMemMB > 0 && isRaspberryPi() {
+		switch {
+		case gpu.GPUMemMB == 0:
+			WarningTf("%s requires %d MB of GPU memory, but the GPU memory split could not be read.", app, requirements.MinGPUMemMB)
+		case gpu.DriverMode == DriverModeKMS:
+			// The KMS driver allocates GPU memory from CMA, gpu_mem only matters for the firmware
+		case gpu.GPUMemMB < requirements.MinGPUMemMB:
+			return fmt.Errorf("%s requires %d MB of GPU memory, but only %d MB are assigned to the GPU. Set gpu_mem=%d in config.txt and reboot to install it", app, requirements.MinGPUMemMB, gpu.GPUMemMB, requirements.MinGPUMemMB)
+		}
+	}
+	if requirements.RequiresVulkan && !gpu.HasVulkan() {
+		if gpu.VulkanTool {
+			return fmt.Errorf("%s requires Vulkan, but no Vulkan device was found. Install the Vulkan driver for your GPU (mesa-vulkan-drivers) to install it", app)
+		}
+		WarningTf("%s requires Vulkan, but no Vulkan driver was found.", app)
+	}
+	if requirements.RequiresKMS {
+		switch gpu.DriverMode {
+		case DriverModeFakeKMS, DriverModeLegacy:
+			return fmt.Errorf("%s requires the KMS graphics driver, but the %s driver is used. Set dtoverlay=vc4-kms-v3d in config.txt and reboot to install it", app, gpu.DriverMode)
+		case DriverModeUnknown:
+			WarningTf("%s requires the KMS graphics driver, but the graphics driver mode could not be detected.", app)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,267 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: app_secrets.go
+// Description: Asks for and stores the secrets of apps, like the API tokens their install scripts need, in the
+// keyring when one runs and in data/secrets otherwise, and keeps them out of logs.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package api
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/pi-apps-go/pi-apps/pkg/api/errs"
+	"golang.org/x/term"
+)
+
+const (
+	// secretsDataDir is the data subdirectory of the file store, one folder per app and one file per key
+	secretsDataDir = "secrets"
+	// minScrubbedSecretLength is the length below which stored values are not scrubbed from logs, as replacing
+	// every "1" or "yes" would make a log unreadable without hiding anything worth hiding
+	minScrubbedSecretLength = 4
+	// scrubbedSecret replaces stored secrets in logs
+	scrubbedSecret = "[secret]"
+)
+
+// ErrSecretNotFound is returned by GetAppSecret for a secret that was never stored
+var ErrSecretNotFound = errors.New("secret not found")
+
+// secretKeyPattern is what a secret key can look like, it names a file of the file store
+var secretKeyPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// validateSecretKey checks a secret key can be used as a file name
+func validateSecretKey(key string) error {
+	if len(key) > 255 || !secretKeyPattern.MatchString(key) {
+		return fmt.Errorf("invalid secret key '%s': use letters, digits, '.', '_' and '-'", key)
+	}
+	return nil
+}
+
+// appSecretsDir returns the folder of the file store that has the secrets of an app
+func appSecretsDir(app string) (string, error) {
+	return AppDataPath(secretsDataDir, app)
+}
+
+// StoreAppSecret stores a secret of an app, in the keyring when a Secret Service daemon (GNOME Keyring, KWallet,
+// KeePassXC) runs and in data/secrets/<app>/<key>, readable only by the user, otherwise
+//
+//	app - the app the secret belongs to, it is removed when the app is uninstalled without keeping its data
+//	key - the name of the secret, letters, digits, '.', '_' and '-'
+//	value - the secret
+func StoreAppSecret(app, key, value string) error {
+	dir, err := appSecretsDir(app)
+	if err != nil {
+		return err
+	}
+	if err := validateSecretKey(key); err != nil {
+		return err
+	}
+	if value == "" {
+		return fmt.Errorf("the secret %s of %s is empty", key, app)
+	}
+
+	if service := connectSecretService(); service != nil {
+		defer service.Close()
+		err := service.set(app, key, value)
+		if err == nil {
+			// A value stored in a file before the keyring was set up would otherwise be left behind
+			os.Remove(filepath.Join(dir, key))
+			return nil
+		}
+		Debug(fmt.Sprintf("Failed to store the secret %s of %s in the keyring, using a file instead: %v", key, app, err))
+	}
+	return writeSecretFile(dir, key, value)
+}
+
+// writeSecretFile writes a secret to the file store, replacing the file so it is never readable by others
+func writeSecretFile(dir, key, value string) error {
+	for _, path := range []string{filepath.Dir(dir), dir} {
+		if err := EnsureDirMode(path, 0700); err != nil {
+			return err
+		}
+	}
+	file, err := os.CreateTemp(dir, "."+key+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	if _, err := file.WriteString(value); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), filepath.Join(dir, key))
+}
+
+// GetAppSecret returns a secret StoreAppSecret stored, an error wrapping ErrSecretNotFound if there is none
+func GetAppSecret(app, key string) (string, error) {
+	dir, err := appSecretsDir(app)
+	if err != nil {
+		return "", err
+	}
+	if err := validateSecretKey(key); err != nil {
+		return "", err
+	}
+
+	if service := connectSecretService(); service != nil {
+		defer service.Close()
+		value, found, err := service.get(app, key)
+		if err != nil {
+			Debug(fmt.Sprintf("Failed to read the secret %s of %s from the keyring: %v", key, app, err))
+		} else if found {
+			return value, nil
+		}
+	}
+	value, err := os.ReadFile(filepath.Join(dir, key))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("%w: %s has no secret %s", ErrSecretNotFound, app, key)
+	}
+	if err != nil {
+		return "", err
+	}
+	return string(value), nil
+}
+
+// AppSecretKeys returns the keys of the secrets stored for an app, sorted
+func AppSecretKeys(app string) ([]string, error) {
+	dir, err := appSecretsDir(app)
+	if err != nil {
+		return nil, err
+	}
+	var keys []string
+	if service := connectSecretService(); service != nil {
+		defer service.Close()
+		if keys, err = service.keys(app); err != nil {
+			Debug(fmt.Sprintf("Failed to list the secrets of %s in the keyring: %v", app, err))
+		}
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if entry.Type().IsRegular() && validateSecretKey(entry.Name()) == nil {
+			keys = append(keys, entry.Name())
+		}
+	}
+	slices.Sort(keys)
+	return slices.Compact(keys), nil
+}
+
+// DeleteAppSecrets removes every secret stored for an app, from the keyring and the file store
+func DeleteAppSecrets(app string) error {
+	dir, err := appSecretsDir(app)
+	if err != nil {
+		return err
+	}
+	var problems []error
+	if service := connectSecretService(); service != nil {
+		defer service.Close()
+		if err := service.delete(app); err != nil {
+			problems = append(problems, fmt.Errorf("keyring: %w", err))
+		}
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		problems = append(problems, err)
+	}
+	return errors.Join(problems...)
+}
+
+// storedSecretValues returns the values of all stored secrets that can be read without asking the user to unlock
+// the keyring, longest first so a secret containing another is scrubbed whole
+func storedSecretValues() []string {
+	var values []string
+	if service := connectSecretService(); service != nil {
+		for _, secret := range service.unlockedSecrets() {
+			values = append(values, secret.value)
+		}
+		service.Close()
+	}
+	if piAppsDir := GetPiAppsDir(); piAppsDir != "" {
+		files, _ := filepath.Glob(filepath.Join(DataDirOf(piAppsDir), secretsDataDir, "*", "*"))
+		for _, file := range files {
+			if validateSecretKey(filepath.Base(file)) != nil {
+				continue
+			}
+			if value, err := os.ReadFile(file); err == nil {
+				values = append(values, string(value))
+			}
+		}
+	}
+	values = slices.DeleteFunc(values, func(value string) bool { return len(value) < minScrubbedSecretLength })
+	slices.SortFunc(values, func(a, b string) int { return len(b) - len(a) })
+	return slices.Compact(values)
+}
+
+// scrubSecrets replaces every occurrence of the secret values in content
+func scrubSecrets(content string, values []string) string {
+	for _, value := range values {
+		content = strings.ReplaceAll(content, value, scrubbedSecret)
+	}
+	return content
+}
+
+// PromptSecret asks the user for a secret, like an API token, without showing what they type: in a masked GTK entry
+// when a display is available and on the terminal with echo turned off otherwise. When stdin is neither a terminal
+// nor has a display to ask on, the secret is read from its first line, so it can be piped in.
+//
+//	string - the secret
+//	error - error wrapping errs.ErrCancelled if the user cancelled or entered nothing
+func PromptSecret(prompt string) (string, error) {
+	if prompt == "" {
+		return "", fmt.Errorf("prompt_secret(): requires a prompt")
+	}
+	if GUISupported && canUseGTK() {
+		secret, err := gtkPromptSecret(prompt)
+		if err == nil || errors.Is(err, errs.ErrCancelled) {
+			return secret, err
+		}
+		fmt.Fprintf(os.Stderr, "GTK dialog error: %v, falling back to CLI\n", err)
+	}
+
+	var secret string
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		fmt.Fprint(os.Stderr, prompt+" ")
+		value, err := term.ReadPassword(int(os.Stdin.Fd()))
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return "", err
+		}
+		secret = string(value)
+	} else {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return "", err
+		}
+		secret = strings.TrimRight(line, "\r\n")
+	}
+	if secret == "" {
+		return "", errs.New(errs.ErrCancelled, "no secret was entered for: %s", prompt)
+	}
+	return secret, nil
+}
@@ -0,0 +1,153 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// useSecretFileStore keeps the keyring of the user running the tests out of them
+func useSecretFileStore(t *testing.T) {
+	t.Helper()
+	connect := connectSecretService
+	connectSecretService = func() *secretService { return nil }
+	t.Cleanup(func() { connectSecretService = connect })
+}
+
+func TestAppSecretFileStore(t *testing.T) {
+	directory := newTestPiAppsDir(t, "Zoom")
+	useSecretFileStore(t)
+
+	if _, err := GetAppSecret("Zoom", "token"); !errors.Is(err, ErrSecretNotFound) {
+		t.Fatalf("GetAppSecret before storing = %v, want ErrSecretNotFound", err)
+	}
+	if err := StoreAppSecret("Zoom", "token", "first-token"); err != nil {
+		t.Fatal(err)
+	}
+	if err := StoreAppSecret("Zoom", "token", "s3cr3t-token"); err != nil {
+		t.Fatal(err)
+	}
+	if err := StoreAppSecret("Zoom", "api.key", "another-secret"); err != nil {
+		t.Fatal(err)
+	}
+	if value, err := GetAppSecret("Zoom", "token"); err != nil || value != "s3cr3t-token" {
+		t.Errorf("GetAppSecret = %q, %v, want the value stored last", value, err)
+	}
+
+	dir := filepath.Join(directory, "data", "secrets", "Zoom")
+	for path, want := range map[string]os.FileMode{
+		filepath.Dir(dir):             0700,
+		dir:                           0700,
+		filepath.Join(dir, "token"):   0600,
+		filepath.Join(dir, "api.key"): 0600,
+	} {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != want {
+			t.Errorf("%s has permissions %v, want %v", path, info.Mode().Perm(), want)
+		}
+	}
+	if keys, err := AppSecretKeys("Zoom"); err != nil || !slices.Equal(keys, []string{"api.key", "token"}) {
+		t.Errorf("AppSecretKeys = %v, %v, want the temporary files left out", keys, err)
+	}
+
+	for _, key := range []string{"", "../token", ".hidden", "a/b"} {
+		if err := StoreAppSecret("Zoom", key, "value"); err == nil {
+			t.Errorf("StoreAppSecret accepted the key %q", key)
+		}
+	}
+	if err := StoreAppSecret("../Zoom", "token", "value"); err == nil {
+		t.Error("StoreAppSecret accepted an invalid app name")
+	}
+
+	if err := DeleteAppSecrets("Zoom"); err != nil {
+		t.Fatal(err)
+	}
+	if keys, _ := AppSecretKeys("Zoom"); len(keys) != 0 {
+		t.Errorf("secrets left after DeleteAppSecrets: %v", keys)
+	}
+}
+
+func TestFormatLogfileScrubsSecrets(t *testing.T) {
+	directory := newTestPiAppsDir(t, "Zoom")
+	useSecretFileStore(t)
+	if err := StoreAppSecret("Zoom", "token", "s3cr3t-token"); err != nil {
+		t.Fatal(err)
+	}
+	if err := StoreAppSecret("Zoom", "pin", "42"); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(directory, "logs", "install-fail-Zoom.log")
+	writeTestFile(t, path, "OS: Debian GNU/Linux 12 (bookworm)\n\ncurl -H 'Authorization: s3cr3t-token' https://example.com\nretrying 42 times\n")
+	if err := FormatLogfile(path); err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(content), "s3cr3t-token") || !strings.Contains(string(content), "Authorization: "+scrubbedSecret) {
+		t.Errorf("the secret was not scrubbed:\n%s", content)
+	}
+	if !strings.Contains(string(content), "retrying 42 times") {
+		t.Errorf("a value too short to be scrubbed was replaced:\n%s", content)
+	}
+}
+
+func TestUninstallRemovesSecrets(t *testing.T) {
+	newTestPiAppsDir(t, "Zoom")
+	useSecretFileStore(t)
+	t.Setenv("DISPLAY", "")
+	t.Setenv("WAYLAND_DISPLAY", "")
+	if err := StoreAppSecret("Zoom", "token", "s3cr3t-token"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Without a display or a terminal to ask on, the secrets are kept like the rest of the data
+	stdin := os.Stdin
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	writer.Close()
+	defer reader.Close()
+	os.Stdin = reader
+	defer func() { os.Stdin = stdin }()
+
+	promptUserData("Zoom")
+	if _, err := GetAppSecret("Zoom", "token"); err != nil {
+		t.Errorf("the secret was not kept: %v", err)
+	}
+	if choice := lastUserDataChoice("Zoom"); choice != userDataKept {
+		t.Errorf("recorded choice = %q, want %q", choice, userDataKept)
+	}
+
+	if choice := deleteUserData("Zoom", nil, true); choice != userDataDeleted {
+		t.Errorf("deleteUserData = %q, want %q", choice, userDataDeleted)
+	}
+	if _, err := GetAppSecret("Zoom", "token"); !errors.Is(err, ErrSecretNotFound) {
+		t.Errorf("the secret is left after deleting the data: %v", err)
+	}
+}
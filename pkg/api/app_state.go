@@ -0,0 +1,181 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: app_state.go
+// Description: Snapshots of the installed apps, their hash and the difference between two of them,
+// so administrators can check that a fleet of machines has the same apps installed.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// StateApp is an installed app in a State
+type StateApp struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"` // installed upstream version, see SetInstalledUpstreamVersion
+	Channel string `json:"channel,omitempty"` // extra app repository the app comes from, "" for official and local apps
+}
+
+// State is the set of installed apps of a system, sorted by name
+type State struct {
+	Hash string     `json:"hash"`
+	Apps []StateApp `json:"apps"`
+}
+
+// VersionDrift is an app that is installed in a different version or from a different channel than desired
+type VersionDrift struct {
+	Name           string `json:"name"`
+	Version        string `json:"version,omitempty"`
+	DesiredVersion string `json:"desired_version,omitempty"`
+	Channel        string `json:"channel,omitempty"`
+	DesiredChannel string `json:"desired_channel,omitempty"`
+}
+
+// StateDiff is what has to change for a system to have the desired apps installed. Every list is sorted by name.
+type StateDiff struct {
+	Add    []StateApp     `json:"add"`    // desired apps that are not installed
+	Remove []StateApp     `json:"remove"` // installed apps that are not desired
+	Drift  []VersionDrift `json:"drift"`  // apps installed in another version or from another channel
+}
+
+// Empty reports whether the system already has the desired apps installed
+func (d StateDiff) Empty() bool {
+	return len(d.Add) == 0 && len(d.Remove) == 0 && len(d.Drift) == 0
+}
+
+// DesiredStatePath returns the state GET /state/diff of the catalog server and `api state_diff` without a file compare against
+func DesiredStatePath() string {
+	return filepath.Join(GetDataDir(), "desired-state.json")
+}
+
+// CurrentAppState returns the installed apps of this system with their hash
+func CurrentAppState() (State, error) {
+	statuses, err := GetAllAppStatuses()
+	if err != nil {
+		return State{}, err
+	}
+	var apps []StateApp
+	for app, status := range statuses {
+		if status != AppStateInstalled {
+			continue
+		}
+		version, err := ReadInstalledUpstreamVersion(app)
+		if err != nil {
+			Debug(fmt.Sprintf("Failed to read the installed version of %s: %v", app, err))
+		}
+		apps = append(apps, StateApp{Name: app, Version: version, Channel: AppSource(app)})
+	}
+	return NewState(apps), nil
+}
+
+// NewState returns the state of a set of apps. The apps are normalized and sorted, so the same set of apps
+// always gives the same state and hash, however it was listed. An app listed twice is kept once.
+func NewState(apps []StateApp) State {
+	normalized := make([]StateApp, 0, len(apps))
+	for _, app := range apps {
+		app = StateApp{
+			Name:    strings.TrimSpace(app.Name),
+			Version: normalizeUpstreamVersion(app.Version),
+			Channel: strings.TrimSpace(app.Channel),
+		}
+		if app.Name != "" {
+			normalized = append(normalized, app)
+		}
+	}
+	slices.SortFunc(normalized, func(a, b StateApp) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	normalized = slices.CompactFunc(normalized, func(a, b StateApp) bool {
+		return a.Name == b.Name
+	})
+	return State{Hash: stateHash(normalized), Apps: normalized}
+}
+
+// stateHash returns the SHA-256 of sorted apps as "name\tversion\tchannel" lines.
+// App names, versions and channels never contain tabs or newlines, so different states can't give the same lines.
+func stateHash(apps []StateApp) string {
+	hash := sha256.New()
+	for _, app := range apps {
+		fmt.Fprintf(hash, "%s\t%s\t%s\n", app.Name, app.Version, app.Channel)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// DiffAppState returns what has to change for current to become desired.
+// A desired app without a version or channel matches any installed version or channel.
+func DiffAppState(current, desired State) StateDiff {
+	current, desired = NewState(current.Apps), NewState(desired.Apps)
+	diff := StateDiff{Add: []StateApp{}, Remove: []StateApp{}, Drift: []VersionDrift{}}
+
+	// Both lists are sorted by name, so walk them side by side
+	i, j := 0, 0
+	for i < len(current.Apps) || j < len(desired.Apps) {
+		switch {
+		case j == len(desired.Apps) || i < len(current.Apps) && current.Apps[i].Name < desired.Apps[j].Name:
+			diff.Remove = append(diff.Remove, current.Apps[i])
+			i++
+		case i == len(current.Apps) || desired.Apps[j].Name < current.Apps[i].Name:
+			diff.Add = append(diff.Add, desired.Apps[j])
+			j++
+		default:
+			have, want := current.Apps[i], desired.Apps[j]
+			if want.Version != "" && have.Version != want.Version || want.Channel != "" && have.Channel != want.Channel {
+				diff.Drift = append(diff.Drift, VersionDrift{
+					Name:           have.Name,
+					Version:        have.Version,
+					DesiredVersion: want.Version,
+					Channel:        have.Channel,
+					DesiredChannel: want.Channel,
+				})
+			}
+			i++
+			j++
+		}
+	}
+	return diff
+}
+
+// ReadStateFile reads a state written by WriteStateFile. The hash in the file is ignored and computed again.
+func ReadStateFile(path string) (State, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return State{}, err
+	}
+	var state State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return State{}, fmt.Errorf("invalid state file %s: %w", path, err)
+	}
+	return NewState(state.Apps), nil
+}
+
+// WriteStateFile writes a state as indented JSON
+func WriteStateFile(path string, state State) error {
+	data, err := json.MarshalIndent(NewState(state.Apps), "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
@@ -0,0 +1,195 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestNewStateNormalizes(t *testing.T) {
+	state := NewState([]StateApp{
+		{Name: "Zoom", Version: "v6.0.2"},
+		{Name: " Box64 ", Version: " 0.3.0 ", Channel: " community "},
+		{Name: ""},
+		{Name: "Zoom", Version: "6.0.2"},
+		{Name: "Arduino"},
+	})
+	want := []StateApp{
+		{Name: "Arduino"},
+		{Name: "Box64", Version: "0.3.0", Channel: "community"},
+		{Name: "Zoom", Version: "6.0.2"},
+	}
+	if !reflect.DeepEqual(state.Apps, want) {
+		t.Errorf("NewState apps = %+v, want %+v", state.Apps, want)
+	}
+
+	// The same apps listed differently give the same hash
+	same := NewState([]StateApp{{Name: "Zoom", Version: "6.0.2"}, {Name: "Arduino"}, {Name: "Box64", Version: "V0.3.0", Channel: "community"}})
+	if same.Hash != state.Hash {
+		t.Errorf("hash of the same apps in another order = %s, want %s", same.Hash, state.Hash)
+	}
+	if len(state.Hash) != 64 {
+		t.Errorf("hash = %q, want a SHA-256", state.Hash)
+	}
+
+	// Any difference in names, versions or channels changes it
+	for _, apps := range [][]StateApp{
+		{{Name: "Arduino"}, {Name: "Box64", Version: "0.3.0", Channel: "community"}},
+		{{Name: "Arduino"}, {Name: "Box64", Version: "0.3.1", Channel: "community"}, {Name: "Zoom", Version: "6.0.2"}},
+		{{Name: "Arduino"}, {Name: "Box64", Version: "0.3.0"}, {Name: "Zoom", Version: "6.0.2"}},
+		{{Name: "Arduino"}, {Name: "Box64", Version: "0.3.0", Channel: "community"}, {Name: "Zoom", Version: "6.0.2"}, {Name: "Zzz"}},
+	} {
+		if NewState(apps).Hash == state.Hash {
+			t.Errorf("NewState(%+v) has the same hash as %+v", apps, state.Apps)
+		}
+	}
+	if NewState(nil).Hash != NewState([]StateApp{{Name: " "}}).Hash {
+		t.Error("empty states have different hashes")
+	}
+}
+
+func TestDiffAppState(t *testing.T) {
+	current := State{Apps: []StateApp{
+		{Name: "Zoom", Version: "6.0.2"},
+		{Name: "Box64", Version: "0.3.0", Channel: "community"},
+		{Name: "Chromium", Version: "120"},
+		{Name: "Arduino", Version: "2.3"},
+		{Name: "Steam"},
+	}}
+	desired := State{Apps: []StateApp{
+		{Name: "Zoom", Version: "v6.0.3"},
+		{Name: "Scratch 3"},
+		{Name: "Box64", Version: "0.3.0"},
+		{Name: "Chromium"},
+		{Name: "Arduino", Channel: "beta"},
+		{Name: "Minecraft Java"},
+	}}
+	want := StateDiff{
+		Add:    []StateApp{{Name: "Minecraft Java"}, {Name: "Scratch 3"}},
+		Remove: []StateApp{{Name: "Steam"}},
+		Drift: []VersionDrift{
+			{Name: "Arduino", Version: "2.3", DesiredChannel: "beta"},
+			{Name: "Zoom", Version: "6.0.2", DesiredVersion: "6.0.3"},
+		},
+	}
+	diff := DiffAppState(current, desired)
+	if !reflect.DeepEqual(diff, want) {
+		t.Errorf("DiffAppState = %+v, want %+v", diff, want)
+	}
+	if diff.Empty() {
+		t.Error("a diff with changes is empty")
+	}
+
+	// Ordering is stable whatever the order of the input
+	reversedCurrent, reversedDesired := slices.Clone(current.Apps), slices.Clone(desired.Apps)
+	slices.Reverse(reversedCurrent)
+	slices.Reverse(reversedDesired)
+	if again := DiffAppState(State{Apps: reversedCurrent}, State{Apps: reversedDesired}); !reflect.DeepEqual(again, want) {
+		t.Errorf("DiffAppState of reversed states = %+v, want %+v", again, want)
+	}
+
+	same := DiffAppState(current, current)
+	if !same.Empty() {
+		t.Errorf("DiffAppState of a state with itself = %+v", same)
+	}
+	// Empty lists encode as [] rather than null for monitoring scripts
+	data, err := json.Marshal(same)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"add":[],"remove":[],"drift":[]}` {
+		t.Errorf("JSON of an empty diff = %s", data)
+	}
+}
+
+func TestStateFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	state := NewState([]StateApp{{Name: "Zoom", Version: "6.0.2"}, {Name: "Box64", Channel: "community"}})
+	if err := WriteStateFile(path, state); err != nil {
+		t.Fatal(err)
+	}
+	read, err := ReadStateFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(read, state) {
+		t.Errorf("ReadStateFile = %+v, want %+v", read, state)
+	}
+
+	// A hand-edited file gets its hash computed again
+	writeTestFile(t, path, `{"hash": "stale", "apps": [{"name": "Zoom", "version": "v6.0.2"}, {"name": "Box64", "channel": "community"}]}`)
+	if read, err := ReadStateFile(path); err != nil || read.Hash != state.Hash {
+		t.Errorf("ReadStateFile of an edited file = %+v, %v, want hash %s", read, err, state.Hash)
+	}
+
+	writeTestFile(t, path, "not json")
+	if _, err := ReadStateFile(path); err == nil {
+		t.Error("ReadStateFile of an invalid file succeeded")
+	}
+}
+
+func TestCurrentAppState(t *testing.T) {
+	directory := newTestPiAppsDir(t, "Zoom", "Box64", "Steam")
+	for app, status := range map[string]string{"Zoom": "installed", "Box64": "installed", "Steam": "uninstalled"} {
+		writeTestFile(t, filepath.Join(directory, "apps", app, "install"), "#!/bin/bash\n")
+		writeTestFile(t, filepath.Join(directory, "data", "status", app), status)
+	}
+	if err := SetInstalledUpstreamVersion("Zoom", "v6.0.2"); err != nil {
+		t.Fatal(err)
+	}
+
+	state, err := CurrentAppState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []StateApp{{Name: "Box64"}, {Name: "Zoom", Version: "6.0.2"}}; !reflect.DeepEqual(state.Apps, want) {
+		t.Errorf("CurrentAppState = %+v, want %+v", state.Apps, want)
+	}
+}
+
+func TestCatalogServerState(t *testing.T) {
+	server := newTestCatalog(t, false, "")
+
+	var state State
+	getCatalog(t, server, "/state", http.StatusOK, &state)
+	if want := NewState([]StateApp{{Name: "Alpha Tool"}}); !reflect.DeepEqual(state, want) {
+		t.Errorf("GET /state = %+v, want %+v", state, want)
+	}
+
+	getCatalog(t, server, "/state/diff", http.StatusNotFound, nil)
+
+	if err := WriteStateFile(DesiredStatePath(), NewState([]StateApp{{Name: "Beta"}})); err != nil {
+		t.Fatal(err)
+	}
+	var diff StateDiff
+	getCatalog(t, server, "/state/diff", http.StatusOK, &diff)
+	want := StateDiff{Add: []StateApp{{Name: "Beta"}}, Remove: []StateApp{{Name: "Alpha Tool"}}, Drift: []VersionDrift{}}
+	if !reflect.DeepEqual(diff, want) {
+		t.Errorf("GET /state/diff = %+v, want %+v", diff, want)
+	}
+
+	if err := os.WriteFile(DesiredStatePath(), []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	getCatalog(t, server, "/state/diff", http.StatusInternalServerError, nil)
+}
@@ -42,9 +42,20 @@ func GetAppStatus(app string) (string, error) {
 	if directory == "" {
 		return "", fmt.Errorf("PI_APPS_DIR environment variable not set")
 	}
+	if err := ValidateAppName(app); err != nil {
+		return "", fmt.Errorf("app_status: %w", err)
+	}
+
+	// Use the snapshot of GetAllAppStatuses if one was taken recently
+	if status, ok := cachedAppStatus(directory, app); ok {
+		return string(status), nil
+	}
 
 	// Check if app status file exists
-	statusFile := filepath.Join(directory, "data", "status", app)
+	statusFile, err := AppDataPath("status", app)
+	if err != nil {
+		return "", fmt.Errorf("app_status: %w", err)
+	}
 	if FileExists(statusFile) {
 		// Read the status file
 		statusData, err := os.ReadFile(statusFile)
@@ -62,7 +73,10 @@ func GetAppStatus(app string) (string, error) {
 	}
 
 	// Check if app directory exists (for non-deprecated apps)
-	appDir := filepath.Join(directory, "apps", app)
+	appDir, err := AppPath(app)
+	if err != nil {
+		return "", fmt.Errorf("app_status: %w", err)
+	}
 	if _, err := os.Stat(appDir); os.IsNotExist(err) {
 		return "", fmt.Errorf("app_status: app %s does not exist", app)
 	}
@@ -85,7 +99,11 @@ func AppType(app string) (string, error) {
 	if directory == "" {
 		return "", fmt.Errorf("PI_APPS_DIR environment variable not set")
 	}
+	return appTypeIn(directory, app)
+}
 
+// appTypeIn is AppType for an app of the Pi-Apps directory directory
+func appTypeIn(directory, app string) (string, error) {
 	appDir := filepath.Join(directory, "apps", app)
 
 	// Check if it's a package app (has packages file)
@@ -306,7 +324,7 @@ func ListAppsMissingDummyDebs() ([]string, error) {
 			}
 
 			// Get the app installation timestamp from the status file
-			statusFile := filepath.Join(directory, "data", "status", app)
+			statusFile := filepath.Join(DataDirOf(directory), "status", app)
 			fileInfo, err := os.Stat(statusFile)
 			if err != nil {
 				continue
@@ -0,0 +1,288 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: app_status_bulk.go
+// Description: Provides functions for getting the status of all apps at once and watching for status changes.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package api
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+// AppState is the status of an app as stored in the data/status directory
+//
+// It is named AppState because AppStatus is already taken by the function of the same name.
+type AppState string
+
+const (
+	AppStateInstalled   AppState = "installed"
+	AppStateUninstalled AppState = "uninstalled"
+	AppStateCorrupted   AppState = "corrupted"
+	AppStateDisabled    AppState = "disabled"
+)
+
+// StatusEvent is sent by WatchAppStatuses when the status of an app changes
+type StatusEvent struct {
+	App    string
+	Status AppState
+}
+
+// statusSnapshotTTL is how long a snapshot taken by GetAllAppStatuses is used by GetAppStatus
+const statusSnapshotTTL = 5 * time.Second
+
+// statusSnapshot holds the statuses of all apps at a point in time
+type statusSnapshot struct {
+	taken    time.Time
+	statuses map[string]AppState
+}
+
+var (
+	statusSnapshotMutex sync.Mutex
+	lastStatusSnapshot  *statusSnapshot
+)
+
+// GetAllAppStatuses gets the status of every app in one pass over the data/status directory
+//
+// Package-apps are resolved against a single list of installed packages instead of querying the package manager per app.
+// Apps without a status file are reported as uninstalled.
+//
+//	map[string]AppState - status of every app, keyed by app name
+//	error - error if PI_APPS_DIR environment variable is not set or the apps directory cannot be read
+func GetAllAppStatuses() (map[string]AppState, error) {
+	directory := GetPiAppsDir()
+	if directory == "" {
+		return nil, fmt.Errorf("PI_APPS_DIR environment variable not set")
+	}
+
+	taken := time.Now()
+	statuses := make(map[string]AppState)
+
+	appEntries, err := os.ReadDir(filepath.Join(directory, "apps"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read apps directory: %w", err)
+	}
+	var packageApps []string
+	for _, entry := range appEntries {
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		statuses[entry.Name()] = AppStateUninstalled
+		if FileExists(filepath.Join(directory, "apps", entry.Name(), "packages")) {
+			packageApps = append(packageApps, entry.Name())
+		}
+	}
+
+	// Status files also exist for deprecated apps that are no longer in the apps directory
+	statusDir := filepath.Join(DataDirOf(directory), "status")
+	statusEntries, err := os.ReadDir(statusDir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read status directory: %w", err)
+	}
+	for _, entry := range statusEntries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(statusDir, entry.Name()))
+		if err != nil {
+			Debug(fmt.Sprintf("failed to read status file of %s: %v", entry.Name(), err))
+			continue
+		}
+		if status := strings.TrimSpace(string(data)); status != "" {
+			statuses[entry.Name()] = AppState(status)
+		}
+	}
+
+	if len(packageApps) > 0 {
+		installed, err := InstalledPackages()
+		if err != nil {
+			// Keep the statuses from the status files
+			Debug(fmt.Sprintf("failed to get installed packages: %v", err))
+		} else {
+			for _, app := range packageApps {
+				status := statuses[app]
+				if status != AppStateInstalled && status != AppStateUninstalled {
+					continue
+				}
+				if pkgAppInstalled(filepath.Join(directory, "apps", app, "packages"), installed) {
+					statuses[app] = AppStateInstalled
+				} else {
+					statuses[app] = AppStateUninstalled
+				}
+			}
+		}
+	}
+
+	statusSnapshotMutex.Lock()
+	lastStatusSnapshot = &statusSnapshot{taken: taken, statuses: statuses}
+	statusSnapshotMutex.Unlock()
+
+	result := make(map[string]AppState, len(statuses))
+	for app, status := range statuses {
+		result[app] = status
+	}
+	return result, nil
+}
+
+// pkgAppInstalled reports whether a package-app is installed, using the same rule as RefreshPkgAppStatus:
+// the first package (or any of its alternatives) listed in the packages file must be installed
+func pkgAppInstalled(packagesFile string, installed map[string]bool) bool {
+	data, err := os.ReadFile(packagesFile)
+	if err != nil {
+		return false
+	}
+
+	// Alternatives may be written as "a | b" or "a|b"
+	content := strings.ReplaceAll(string(data), " | ", "|")
+	words := strings.Fields(content)
+	if len(words) == 0 {
+		return false
+	}
+	for _, pkg := range strings.Split(words[0], "|") {
+		if installed[pkg] {
+			return true
+		}
+	}
+	return false
+}
+
+// cachedAppStatus returns the status of an app from the last snapshot if it is still fresh
+//
+// A snapshot is stale once it is older than statusSnapshotTTL or the status directory or the app's status file
+// has been modified since it was taken.
+func cachedAppStatus(directory, app string) (AppState, bool) {
+	statusSnapshotMutex.Lock()
+	snapshot := lastStatusSnapshot
+	statusSnapshotMutex.Unlock()
+
+	if snapshot == nil || time.Since(snapshot.taken) > statusSnapshotTTL {
+		return "", false
+	}
+	status, ok := snapshot.statuses[app]
+	if !ok {
+		return "", false
+	}
+
+	statusDir := filepath.Join(DataDirOf(directory), "status")
+	if info, err := os.Stat(statusDir); err == nil && info.ModTime().After(snapshot.taken) {
+		return "", false
+	}
+	if info, err := os.Stat(filepath.Join(statusDir, app)); err == nil && info.ModTime().After(snapshot.taken) {
+		return "", false
+	}
+	return status, true
+}
+
+// invalidateStatusSnapshot makes GetAppStatus read the status files again
+func invalidateStatusSnapshot() {
+	statusSnapshotMutex.Lock()
+	lastStatusSnapshot = nil
+	statusSnapshotMutex.Unlock()
+}
+
+// WatchAppStatuses watches the data/status directory and sends an event every time the status of an app changes,
+// so the GUI can update its status badges without polling
+//
+// The returned channel is closed when ctx is cancelled or the directory cannot be watched.
+func WatchAppStatuses(ctx context.Context) <-chan StatusEvent {
+	events := make(chan StatusEvent)
+
+	directory := GetPiAppsDir()
+	if directory == "" {
+		Debug("WatchAppStatuses: PI_APPS_DIR environment variable not set")
+		close(events)
+		return events
+	}
+	statusDir := filepath.Join(DataDirOf(directory), "status")
+	if err := os.MkdirAll(statusDir, 0755); err != nil {
+		Debug(fmt.Sprintf("WatchAppStatuses: failed to create status directory: %v", err))
+		close(events)
+		return events
+	}
+
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		Debug(fmt.Sprintf("WatchAppStatuses: failed to initialize inotify: %v", err))
+		close(events)
+		return events
+	}
+	mask := uint32(syscall.IN_CLOSE_WRITE | syscall.IN_MOVED_TO | syscall.IN_MOVED_FROM | syscall.IN_DELETE)
+	if _, err := syscall.InotifyAddWatch(fd, statusDir, mask); err != nil {
+		Debug(fmt.Sprintf("WatchAppStatuses: failed to watch %s: %v", statusDir, err))
+		syscall.Close(fd)
+		close(events)
+		return events
+	}
+
+	// A non-blocking descriptor goes through the runtime poller, so closing the file interrupts a pending read
+	watcher := os.NewFile(uintptr(fd), "inotify")
+	go func() {
+		<-ctx.Done()
+		watcher.Close()
+	}()
+
+	go func() {
+		defer close(events)
+
+		buf := make([]byte, 64*(syscall.SizeofInotifyEvent+syscall.NAME_MAX+1))
+		for {
+			n, err := watcher.Read(buf)
+			if err != nil {
+				if ctx.Err() == nil {
+					Debug(fmt.Sprintf("WatchAppStatuses: failed to read inotify events: %v", err))
+				}
+				return
+			}
+
+			for offset := 0; offset+syscall.SizeofInotifyEvent <= n; {
+				raw := (*syscall.InotifyEvent)(unsafe.Pointer(&buf[offset]))
+				nameBytes := buf[offset+syscall.SizeofInotifyEvent : offset+syscall.SizeofInotifyEvent+int(raw.Len)]
+				offset += syscall.SizeofInotifyEvent + int(raw.Len)
+
+				app := string(bytes.TrimRight(nameBytes, "\x00"))
+				if app == "" || strings.HasPrefix(app, ".") {
+					continue
+				}
+				invalidateStatusSnapshot()
+
+				event := StatusEvent{App: app, Status: AppStateUninstalled}
+				if raw.Mask&(syscall.IN_CLOSE_WRITE|syscall.IN_MOVED_TO) != 0 {
+					if status, err := GetAppStatus(app); err == nil && status != "" {
+						event.Status = AppState(status)
+					}
+				}
+
+				select {
+				case events <- event:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return events
+}
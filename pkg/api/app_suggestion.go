@@ -0,0 +1,366 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: app_suggestion.go
+// Description: Suggesting an app for Pi-Apps: validation, duplicate checks against the catalog and open suggestions,
+// submission as a GitHub issue or to the error report server, and the suggestions this user made before.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// AppSuggestion is an app a user would like to see in Pi-Apps
+type AppSuggestion struct {
+	Name   string    `json:"name"`
+	URL    string    `json:"url"`              // where the app is hosted, like its GitHub page or website
+	Why    string    `json:"why,omitempty"`    // what the app does and why it should be added
+	Search string    `json:"search,omitempty"` // the search that found nothing, remembered with the suggestion
+	Time   time.Time `json:"time"`
+}
+
+// openSuggestionsMaxAge is how long the cached titles of the open app requests are used before they are fetched again
+const openSuggestionsMaxAge = 24 * time.Hour
+
+// openSuggestionsFile caches the titles of the open app requests on GitHub
+func openSuggestionsFile() string {
+	return filepath.Join(GetDataDir(), "open-suggestions.json")
+}
+
+// appSuggestionsFile records the suggestions this user made
+func appSuggestionsFile() string {
+	return filepath.Join(GetDataDir(), "app-suggestions.json")
+}
+
+// ValidateAppSuggestion checks that a suggestion names an app and links to where it is hosted
+func ValidateAppSuggestion(suggestion AppSuggestion) error {
+	if strings.TrimSpace(suggestion.Name) == "" {
+		return fmt.Errorf("the app name is missing")
+	}
+	link, err := url.Parse(strings.TrimSpace(suggestion.URL))
+	if err != nil || (link.Scheme != "http" && link.Scheme != "https") || link.Host == "" {
+		return fmt.Errorf("'%s' is not a web address, it should look like https://github.com/owner/app", suggestion.URL)
+	}
+	return nil
+}
+
+// normalizeSuggestionName lowercases a name and drops everything but letters and digits, so
+// "Visual Studio Code" and "visual-studio-code" compare equal
+func normalizeSuggestionName(name string) string {
+	var normalized strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			normalized.WriteRune(r)
+		}
+	}
+	return normalized.String()
+}
+
+// editDistance returns the number of inserted, removed, changed or swapped letters between two strings
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	distance := make([][]int, len(ra)+1)
+	for i := range distance {
+		distance[i] = make([]int, len(rb)+1)
+		distance[i][0] = i
+	}
+	for j := range distance[0] {
+		distance[0][j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			distance[i][j] = min(distance[i-1][j]+1, distance[i][j-1]+1, distance[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				distance[i][j] = min(distance[i][j], distance[i-2][j-2]+1)
+			}
+		}
+	}
+	return distance[len(ra)][len(rb)]
+}
+
+// namesSimilar reports whether two app names are probably the same app: one contains the other,
+// or they differ by a typo or two
+func namesSimilar(a, b string) bool {
+	a, b = normalizeSuggestionName(a), normalizeSuggestionName(b)
+	if a == "" || b == "" {
+		return false
+	}
+	if len(a) >= 3 && len(b) >= 3 && (strings.Contains(a, b) || strings.Contains(b, a)) {
+		return true
+	}
+	return editDistance(a, b) <= max(1, min(len(a), len(b))/4)
+}
+
+// SimilarApps returns the apps of the catalog and the open app requests whose name looks like name,
+// so an app isn't suggested again. The open app requests are fetched from GitHub at most once a day.
+func SimilarApps(name string) ([]string, error) {
+	apps, err := ListApps("all")
+	if err != nil {
+		return nil, err
+	}
+	var similar []string
+	for _, app := range apps {
+		if namesSimilar(app, name) {
+			similar = append(similar, app)
+		}
+	}
+	for _, title := range openSuggestions() {
+		if titleMentions(title, name) {
+			similar = append(similar, Tf("%s (open request)", title))
+		}
+	}
+	return slices.Compact(similar), nil
+}
+
+// titleMentions reports whether the title of an app request, free text like "Please add Zoom",
+// contains a run of up to four words similar to name
+func titleMentions(title, name string) bool {
+	words := strings.Fields(title)
+	for i := range words {
+		for j := i + 1; j <= len(words) && j <= i+4; j++ {
+			if namesSimilar(strings.Join(words[i:j], " "), name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// openSuggestions returns the titles of the open app requests, from the cache if it is recent
+func openSuggestions() []string {
+	var titles []string
+	if info, err := os.Stat(openSuggestionsFile()); err == nil && time.Since(info.ModTime()) < openSuggestionsMaxAge {
+		if data, err := os.ReadFile(openSuggestionsFile()); err == nil && json.Unmarshal(data, &titles) == nil {
+			return titles
+		}
+	}
+
+	titles, err := fetchOpenSuggestions()
+	if err != nil {
+		Debug(fmt.Sprintf("failed to fetch the open app requests: %v", err))
+		// An old cache is better than none
+		if data, err := os.ReadFile(openSuggestionsFile()); err == nil {
+			json.Unmarshal(data, &titles)
+		}
+		return titles
+	}
+	if data, err := json.Marshal(titles); err == nil {
+		os.WriteFile(openSuggestionsFile(), data, 0644)
+	}
+	return titles
+}
+
+// suggestionRepo returns the GitHub account and repository app requests are made in
+func suggestionRepo() (account, repo string) {
+	account, repo = GetGitUrl()
+	if account == "" || repo == "" {
+		return "pi-apps-go", "pi-apps"
+	}
+	return account, repo
+}
+
+// fetchOpenSuggestions returns the titles of the open issues with the App Request label
+func fetchOpenSuggestions() ([]string, error) {
+	account, repo := suggestionRepo()
+	query := url.Values{"q": {fmt.Sprintf(`repo:%s/%s is:issue is:open label:"App Request"`, account, repo)}, "per_page": {"100"}}
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Get("https://api.github.com/search/issues?" + query.Encode())
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GitHub returned %s", resp.Status)
+	}
+
+	var result struct {
+		Items []struct {
+			Title string `json:"title"`
+		} `json:"items"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+	titles := make([]string, 0, len(result.Items))
+	for _, item := range result.Items {
+		titles = append(titles, item.Title)
+	}
+	return titles, nil
+}
+
+// AppSuggestionIssueURL returns the URL of a new GitHub issue with the app suggestion form filled in
+func AppSuggestionIssueURL(suggestion AppSuggestion) string {
+	account, repo := suggestionRepo()
+	query := url.Values{
+		"template": {"app-suggestion.yml"},
+		"labels":   {"App Request"},
+		"title":    {"App request: " + suggestion.Name},
+		"app-name": {suggestion.Name},
+		"host":     {suggestion.URL},
+		"about":    {suggestion.Why},
+	}
+	return fmt.Sprintf("https://github.com/%s/%s/issues/new?%s", account, repo, query.Encode())
+}
+
+// OpenURL opens a web page in the default browser
+func OpenURL(link string) error {
+	if err := exec.Command("xdg-open", link).Start(); err != nil {
+		return fmt.Errorf("failed to open %s: %w", link, err)
+	}
+	return nil
+}
+
+// SendAppSuggestion sends a suggestion to the error report server. Like error reports, only call it
+// after the user chose to send it.
+func SendAppSuggestion(suggestion AppSuggestion) error {
+	client := &http.Client{Timeout: 30 * time.Second}
+	tokenResp, err := client.Get(errorReportServer + "/token")
+	if err != nil {
+		return fmt.Errorf("failed to get a token: %w", err)
+	}
+	defer tokenResp.Body.Close()
+	if tokenResp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to get a token: server returned %d", tokenResp.StatusCode)
+	}
+	var tokenData struct {
+		Token string `json:"token"`
+	}
+	if err := json.NewDecoder(tokenResp.Body).Decode(&tokenData); err != nil {
+		return fmt.Errorf("failed to decode token response: %w", err)
+	}
+
+	body, err := json.Marshal(struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+		Why  string `json:"why"`
+	}{suggestion.Name, suggestion.URL, suggestion.Why})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", errorReportServer+"/suggestions", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Error-Report-Token", tokenData.Token)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send the suggestion: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to send the suggestion: server returned %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// SubmitAppSuggestion validates a suggestion and sends it to the Pi-Apps team when send is true,
+// or opens the GitHub issue for it in the browser. The suggestion is remembered either way.
+//
+//	string - the GitHub issue URL when send is false
+//	error - error if the suggestion is not valid or could not be submitted
+func SubmitAppSuggestion(suggestion AppSuggestion, send bool) (string, error) {
+	suggestion.Name = strings.TrimSpace(suggestion.Name)
+	suggestion.URL = strings.TrimSpace(suggestion.URL)
+	suggestion.Why = strings.TrimSpace(suggestion.Why)
+	if err := ValidateAppSuggestion(suggestion); err != nil {
+		return "", err
+	}
+
+	var link string
+	if send {
+		if err := SendAppSuggestion(suggestion); err != nil {
+			return "", err
+		}
+	} else {
+		link = AppSuggestionIssueURL(suggestion)
+		if err := OpenURL(link); err != nil {
+			Debug(err.Error())
+		}
+	}
+
+	if err := rememberAppSuggestion(suggestion); err != nil {
+		Debug(fmt.Sprintf("failed to remember the app suggestion: %v", err))
+	}
+	return link, nil
+}
+
+// AppSuggestions returns the suggestions this user made, oldest first
+func AppSuggestions() ([]AppSuggestion, error) {
+	data, err := os.ReadFile(appSuggestionsFile())
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var suggestions []AppSuggestion
+	if err := json.Unmarshal(data, &suggestions); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", appSuggestionsFile(), err)
+	}
+	return suggestions, nil
+}
+
+// rememberAppSuggestion adds a suggestion to the ones this user made
+func rememberAppSuggestion(suggestion AppSuggestion) error {
+	suggestions, err := AppSuggestions()
+	if err != nil {
+		return err
+	}
+	if suggestion.Time.IsZero() {
+		suggestion.Time = time.Now()
+	}
+	data, err := json.MarshalIndent(append(suggestions, suggestion), "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(appSuggestionsFile()), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(appSuggestionsFile(), data, 0644)
+}
+
+// SuggestedBefore returns the suggestion this user made for a search term or an app with a similar name,
+// so the user isn't asked to suggest it again after searching for it
+func SuggestedBefore(term string) (AppSuggestion, bool) {
+	suggestions, err := AppSuggestions()
+	if err != nil {
+		Debug(err.Error())
+	}
+	for _, suggestion := range suggestions {
+		if strings.EqualFold(strings.TrimSpace(suggestion.Search), strings.TrimSpace(term)) || namesSimilar(suggestion.Name, term) {
+			return suggestion, true
+		}
+	}
+	return AppSuggestion{}, false
+}
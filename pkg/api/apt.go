@@ -40,6 +40,7 @@ import (
 
 	"github.com/ProtonMail/gopenpgp/v3/armor"
 	"github.com/ProtonMail/gopenpgp/v3/crypto"
+	"github.com/pi-apps-go/pi-apps/pkg/api/errs"
 )
 
 // RepoAdd adds local package files to the /var/cache/pi-apps/pi-apps-local-packages repository
@@ -173,7 +174,11 @@ func AptLockWait() error {
 	go func() {
 		select {
 		case <-time.After(5 * time.Second):
-			fmt.Print(T("Waiting until APT locks are released... "))
+			if holders := lockHolders("apt", "apt-get", "aptitude", "dpkg", "unattended-upgrades", "synaptic"); holders != "" {
+				fmt.Print(Tf("Waiting until APT locks held by %s are released... ", holders))
+			} else {
+				fmt.Print(T("Waiting until APT locks are released... "))
+			}
 			notificationShown <- true
 		case <-notificationDone:
 			return
@@ -191,7 +196,7 @@ func AptLockWait() error {
 		cmd.Stdin = os.Stdin
 		if err := cmd.Run(); err != nil {
 			close(notificationDone)
-			return fmt.Errorf("failed to get sudo permissions: %w", err)
+			return errs.New(errs.ErrNeedsRoot, "failed to get sudo permissions: %w", err)
 		}
 	}
 
@@ -437,7 +442,7 @@ func AptUpdate(args ...string) error {
 
 	// Use cyan color with reverse video styling to match the original implementation
 	// \033[96m for cyan, \033[7m for reverse video, \033[27m to end reverse, \033[0m to reset all formatting
-	fmt.Fprintf(os.Stderr, "\033[96m%s \033[7msudo apt update\033[27m...\033[0m\n", T("Running"))
+	colorPrintf(os.Stderr, "\033[96m%s \033[7msudo apt update\033[27m...\033[0m\n", T("Running"))
 
 	// Prepare the apt update command with provided arguments
 	// Use the original LANG that was set by the user, not the one modified by i18n
@@ -447,10 +452,12 @@ func AptUpdate(args ...string) error {
 
 	if lang != "" {
 		// Set locale variables before sudo for proper APT localization
-		aptArgs = append([]string{"-E", "LANG=" + lang, "LC_ALL=" + lang, "LC_MESSAGES=" + lang, "apt-get", "update", "--allow-releaseinfo-change"}, args...)
+		aptArgs = []string{"-E", "LANG=" + lang, "LC_ALL=" + lang, "LC_MESSAGES=" + lang, "apt-get", "update", "--allow-releaseinfo-change"}
 	} else {
-		aptArgs = append([]string{"-E", "apt-get", "update", "--allow-releaseinfo-change"}, args...)
+		aptArgs = []string{"-E", "apt-get", "update", "--allow-releaseinfo-change"}
 	}
+	aptArgs = append(aptArgs, aptDownloadLimitFlags()...)
+	aptArgs = append(aptArgs, args...)
 
 	cmd := exec.Command("sudo", aptArgs...)
 
@@ -522,13 +529,13 @@ func AptUpdate(args ...string) error {
 	strippedOutput := stripAnsiCodes(completeOutput)
 
 	// Show completion message in cyan to match the original
-	fmt.Fprintf(os.Stderr, "\033[96m%s\033[0m\n", T("apt update complete."))
+	colorPrintf(os.Stderr, "\033[96m%s\033[0m\n", T("apt update complete."))
 
 	// Check for autoremovable packages messages (both APT 2.x and 3.0 formats)
 	if strings.Contains(strippedOutput, "autoremove to remove them") ||
 		strings.Contains(strippedOutput, "can be autoremoved") {
 		// Use direct ANSI codes for exact matching with the original
-		fmt.Printf("\033[33m%s\033[39m %s \033[4msudo a\033[0mp\033[4mt autoremove\033[0m.\n",
+		colorPrintf(os.Stdout, "\033[33m%s\033[39m %s \033[4msudo a\033[0mp\033[4mt autoremove\033[0m.\n",
 			T("Some packages are unnecessary."), T("Please consider running"))
 	}
 
@@ -536,11 +543,11 @@ func AptUpdate(args ...string) error {
 	if strings.Contains(strippedOutput, "packages can be upgraded") ||
 		strings.Contains(strippedOutput, "can be upgraded") ||
 		strings.Contains(strippedOutput, "upgradable") {
-		fmt.Printf("\033[33m%s\033[39m %s \033[4msudo a\033[0mp\033[4mt full-u\033[0mpg\033[4mrade\033[0m.\n",
+		colorPrintf(os.Stdout, "\033[33m%s\033[39m %s \033[4msudo a\033[0mp\033[4mt full-u\033[0mpg\033[4mrade\033[0m.\n",
 			T("Some packages can be upgraded."), T("Please consider running"))
 	} else if strings.Contains(strippedOutput, "package can be upgraded") ||
 		strings.Contains(strippedOutput, "is upgradable") {
-		fmt.Printf("\033[33m%s\033[39m %s \033[4msudo a\033[0mp\033[4mt full-u\033[0mpg\033[4mrade\033[0m.\n",
+		colorPrintf(os.Stdout, "\033[33m%s\033[39m %s \033[4msudo a\033[0mp\033[4mt full-u\033[0mpg\033[4mrade\033[0m.\n",
 			T("One package can be upgraded."), T("Please consider running"))
 	}
 
@@ -556,8 +563,8 @@ func AptUpdate(args ...string) error {
 		}
 
 		errorMessage := strings.Join(errorLines, "\n")
-		fmt.Fprintf(os.Stderr, "\033[91m%s \033[4msudo apt update\033[0m\033[39m!\n", T("Failed to run"))
-		fmt.Fprintf(os.Stderr, "%s\n\033[91m%s\033[39m\n", T("APT reported these errors:"), errorMessage)
+		colorPrintf(os.Stderr, "\033[91m%s \033[4msudo apt update\033[0m\033[39m!\n", T("Failed to run"))
+		colorPrintf(os.Stderr, "%s\n\033[91m%s\033[39m\n", T("APT reported these errors:"), errorMessage)
 
 		// Print the full output for diagnosis
 		fmt.Fprintln(os.Stderr, completeOutput)
@@ -624,8 +631,11 @@ func AppToPkgName(app string) (string, error) {
 //	"" - error if app is not specified
 //	error - error if app is not specified
 func InstallPackages(app string, args ...string) error {
-	// Extract apt flags and process package list
-	var aptFlags []string
+	// The progress monitor shows the packages being installed until the script goes on
+	defer startPhase(PhaseInstallingPackages, -1)()
+
+	// Extract apt flags and process package list, apt downloads no faster than the download speed limit
+	aptFlags := aptDownloadLimitFlags()
 	var packages []string
 	var repoSelection string
 
@@ -650,6 +660,11 @@ func InstallPackages(app string, args ...string) error {
 	// Flag to track if we're using the local packages repository
 	usingLocalPackages := false
 
+	// Package requests with a version, release or architecture, and where they are in packages
+	var requests []PackageRequest
+	var requestIndexes []int
+	var invalidRequests []string
+
 	// Process packages to handle local files, URLs, and regex
 	for i := 0; i < len(packages); i++ {
 		pkg := packages[i]
@@ -715,9 +730,12 @@ func InstallPackages(app string, args ...string) error {
 				filename = filename + ".deb"
 			}
 
-			// Download the file with retry
+			// Download the file with retry, or take it from the app bundle being installed
 			success := false
-			for attempt := 1; attempt <= 3; attempt++ {
+			if staged, ok := stagedAsset(pkg); ok {
+				success = copyStagedAsset(staged, filename) == nil
+			}
+			for attempt := 1; attempt <= 3 && !success; attempt++ {
 				cmd := exec.Command("wget", "-O", filename, pkg)
 				cmd.Stdout = os.Stdout
 				cmd.Stderr = os.Stderr
@@ -725,6 +743,7 @@ func InstallPackages(app string, args ...string) error {
 
 				if err == nil {
 					if _, statErr := os.Stat(filename); statErr == nil {
+						recordDownloadedFile(pkg, filename)
 						success = true
 						break
 					}
@@ -818,6 +837,16 @@ func InstallPackages(app string, args ...string) error {
 				return fmt.Errorf(T("no packages found matching pattern: %s"), pkg)
 			}
 
+		} else if IsPackageRequest(pkg) && strings.ContainsAny(pkg, "=/:") {
+			// Handle name=version, name/release and name:arch, resolved together below
+			request, err := ParsePackageRequest(pkg)
+			if err != nil {
+				invalidRequests = append(invalidRequests, err.Error())
+				continue
+			}
+			requests = append(requests, request)
+			requestIndexes = append(requestIndexes, i)
+
 		} else if repoSelection != "" {
 			// Handle packages from specific repo with version
 			cmd := exec.Command("apt-cache", "policy", "-t", repoSelection, pkg)
@@ -839,6 +868,31 @@ func InstallPackages(app string, args ...string) error {
 		}
 	}
 
+	// Check every constrained request before anything is installed, so all problems are reported at once
+	if len(invalidRequests) > 0 {
+		return fmt.Errorf("%s\n  %s", T("these package requests can't be satisfied:"), strings.Join(invalidRequests, "\n  "))
+	}
+	resolved, err := resolvePackageRequests(requests, repoSelection)
+	if err != nil {
+		return err
+	}
+	autoInstalled, err := getAutoInstalledPackages()
+	if err != nil {
+		Debug(err.Error())
+	}
+	var requestArgs, markAuto []string
+	for i, request := range resolved {
+		packages[requestIndexes[i]] = request.dependency()
+		if arg := request.aptArg(); arg != "" {
+			requestArgs = append(requestArgs, arg)
+			// Given on the command line, apt marks it as manually installed, which only stays so if the user did it
+			if !PackageInstalled(request.Package()) || autoInstalled[request.Name] {
+				markAuto = append(markAuto, request.Package())
+			}
+			Status(Tf("%s: installing version %s", request, request.Resolved))
+		}
+	}
+
 	// Verify no regex, URLs, or file paths remain
 	for _, pkg := range packages {
 		if strings.Contains(pkg, "*") {
@@ -852,14 +906,31 @@ func InstallPackages(app string, args ...string) error {
 		}
 	}
 
+	// Installing from an app bundle, the packages come from its pool
+	if bundleStaged() {
+		added, err := addBundlePool()
+		if err != nil {
+			return fmt.Errorf("failed to add the bundled packages to the local repository: %w", err)
+		}
+		usingLocalPackages = usingLocalPackages || added
+	}
+
 	// Initialize local repository if needed
 	if usingLocalPackages {
 		if err := RepoRefresh(); err != nil {
 			return fmt.Errorf("failed to refresh local repository: %w", err)
 		}
 
-		// Add source list to apt flags
-		aptFlags = append(aptFlags, "-o", "Dir::Etc::SourceList=/var/cache/pi-apps/pi-apps-local-packages/source.list")
+		// Add source list to apt flags, one with only the local repository if installing from an app bundle
+		if bundleStaged() {
+			flags, err := bundleSourceFlags()
+			if err != nil {
+				return err
+			}
+			aptFlags = append(aptFlags, flags...)
+		} else {
+			aptFlags = append(aptFlags, "-o", "Dir::Etc::SourceList=/var/cache/pi-apps/pi-apps-local-packages/source.list")
+		}
 	}
 
 	// Create a unique package name using app_to_pkgname
@@ -887,6 +958,10 @@ func InstallPackages(app string, args ...string) error {
 		if existingDeps != "" {
 			packages = append(packages, strings.Split(strings.ReplaceAll(existingDeps, ", ", ","), ",")...)
 		}
+		// The last entry of a package wins, so a version asked for now replaces the one of the existing package
+		for _, request := range resolved {
+			packages = append(packages, request.dependency())
+		}
 	}
 
 	// Create temporary directory for the dummy package
@@ -959,6 +1034,8 @@ Package: %s
 		// Compare
 		if strings.Join(filteredPkgInfo, "\n") == strings.Join(controlLines, "\n") {
 			fmt.Printf(T("%s is already installed and no changes would be made. Skipping...\n"), pkgName)
+			saveDummyDebManifest(app, uniquePkgs)
+			savePinnedPackages(app, resolved)
 
 			// Clean up
 			os.RemoveAll(pkgDir)
@@ -990,9 +1067,18 @@ Package: %s
 	}
 
 	// Run apt update and install with retry loop
+	sourcesChecked := false
 	for i := range 5 {
-		// Run apt update
-		if err := AptUpdate(aptFlags...); err != nil {
+		// Run apt update. A single broken third-party repository fails it for every app,
+		// so the user is offered to disable the broken ones once and try again.
+		err := AptUpdate(aptFlags...)
+		if err != nil && !sourcesChecked {
+			sourcesChecked = true
+			if offerToDisableBrokenSources() {
+				err = AptUpdate(aptFlags...)
+			}
+		}
+		if err != nil {
 			return err
 		}
 
@@ -1015,6 +1101,7 @@ Package: %s
 		}
 		installArgs = append(installArgs, aptFlags...)
 		installArgs = append(installArgs, pkgDir+".deb")
+		installArgs = append(installArgs, requestArgs...)
 
 		cmd = exec.Command("sudo", installArgs...)
 
@@ -1106,13 +1193,13 @@ Package: %s
 			errorStr := strings.Join(errorLines, "\n")
 
 			if len(errorLines) == 0 {
-				fmt.Printf("\033[91m%s\033[39m\n", T("Failed to install the packages!"))
+				colorPrintf(os.Stdout, "\033[91m%s\033[39m\n", T("Failed to install the packages!"))
 				fmt.Printf(T("User error: Apt exited with a failed exitcode (%d) and no error (E/Err) output. "+
 					"This could indicate system corruption (eg: storage corruption or unstable overclocking).\n"), cmd.ProcessState.ExitCode())
 				return fmt.Errorf(T("apt exited with error code %d and no error output"), cmd.ProcessState.ExitCode())
 			} else {
-				fmt.Printf("\033[91m%s\033[39m\n", T("Failed to install the packages!"))
-				fmt.Printf("%s\n\033[91m%s\033[39m\n", T("The APT reported these errors:"), errorStr)
+				colorPrintf(os.Stdout, "\033[91m%s\033[39m\n", T("Failed to install the packages!"))
+				colorPrintf(os.Stdout, "%s\n\033[91m%s\033[39m\n", T("The APT reported these errors:"), errorStr)
 
 				// Debug output for local repository issues
 				if usingLocalPackages && !FileExists("/var/cache/pi-apps/pi-apps-local-packages/Packages") {
@@ -1122,7 +1209,7 @@ Package: %s
 					strings.Contains(combinedOutput, "but it is not going to be installed") ||
 					strings.Contains(combinedOutput, "but .* is to be installed")) {
 
-					fmt.Printf("\033[91m%s\033[39m", T("The Pi-Apps Local Repository was being used, and a package seemed to not be available. Here's the Packages file:"))
+					colorPrintf(os.Stdout, "\033[91m%s\033[39m", T("The Pi-Apps Local Repository was being used, and a package seemed to not be available. Here's the Packages file:"))
 					packagesContent, _ := os.ReadFile("/var/cache/pi-apps/pi-apps-local-packages/Packages")
 					fmt.Println(string(packagesContent))
 
@@ -1156,13 +1243,18 @@ Package: %s
 					fmt.Println(string(policyOutput))
 				}
 
-				return fmt.Errorf("apt reported errors: %s", errorStr)
+				return packageManagerError("apt", errorStr)
 			}
 		}
 
 		break // If we get here, installation succeeded
 	}
 
+	// Record the dependencies so the dummy deb can be rebuilt if it goes missing
+	saveDummyDebManifest(app, uniquePkgs)
+	markPackagesAuto(markAuto)
+	savePinnedPackages(app, resolved)
+
 	// Clean up
 	os.Remove(pkgDir + ".deb")
 	os.RemoveAll(pkgDir)
@@ -1390,12 +1482,15 @@ func PurgePackages(app string, isUpdate bool) error {
 			// Handle error cases
 			errorStr := strings.Join(errorLines, "\n")
 
-			fmt.Printf("\033[91m%s\033[39m\n", T("Failed to uninstall the packages!"))
-			fmt.Printf("%s\n\033[91m%s\033[39m\n", T("The APT reported these errors:"), errorStr)
+			colorPrintf(os.Stdout, "\033[91m%s\033[39m\n", T("Failed to uninstall the packages!"))
+			colorPrintf(os.Stdout, "%s\n\033[91m%s\033[39m\n", T("The APT reported these errors:"), errorStr)
 			fmt.Println(combinedOutput)
 
-			return fmt.Errorf("apt reported errors: %s", errorStr)
+			return packageManagerError("apt", errorStr)
 		}
+
+		os.Remove(dummyDebManifest(app))
+		os.Remove(pinnedPackagesFile(app))
 	} else {
 		// Check for legacy installed-packages file
 		installDataDir := GetPiAppsDir()
@@ -1403,7 +1498,7 @@ func PurgePackages(app string, isUpdate bool) error {
 			return fmt.Errorf("failed to get PI_APPS_DIR")
 		}
 
-		legacyPkgFile := filepath.Join(installDataDir, "data", "installed-packages", app)
+		legacyPkgFile := filepath.Join(DataDirOf(installDataDir), "installed-packages", app)
 
 		if FileExists(legacyPkgFile) {
 			WarningT(T("Using the old implementation - an installed-packages file instead of a dummy deb"))
@@ -1532,11 +1627,11 @@ func PurgePackages(app string, isUpdate bool) error {
 
 				errorStr := strings.Join(errorLines, "\n")
 
-				fmt.Printf("\033[91m%s\033[39m\n", T("Failed to uninstall the packages!"))
-				fmt.Printf("%s\n\033[91m%s\033[39m\n", T("The APT reported these errors:"), errorStr)
+				colorPrintf(os.Stdout, "\033[91m%s\033[39m\n", T("Failed to uninstall the packages!"))
+				colorPrintf(os.Stdout, "%s\n\033[91m%s\033[39m\n", T("The APT reported these errors:"), errorStr)
 				fmt.Println(combinedOutput)
 
-				return fmt.Errorf("apt reported errors: %s", errorStr)
+				return packageManagerError("apt", errorStr)
 			}
 		} else {
 			StatusT(Tf("The %s package is not installed so there's nothing to do.", pkgName))
@@ -1549,7 +1644,7 @@ func PurgePackages(app string, isUpdate bool) error {
 		return fmt.Errorf("failed to get PI_APPS_DIR")
 	}
 
-	legacyPkgFile := filepath.Join(installDataDir, "data", "installed-packages", app)
+	legacyPkgFile := filepath.Join(DataDirOf(installDataDir), "installed-packages", app)
 	if FileExists(legacyPkgFile) {
 		os.Remove(legacyPkgFile)
 	}
@@ -2022,6 +2117,11 @@ func AddExternalRepo(reponame, pubkeyurl, uris, suites, components string, addit
 		fmt.Fprintf(os.Stderr, "Warning: failed to set permissions of sources file: %v\n", err)
 	}
 
+	// Record where the key came from, so RenewExternalRepoKey can download it again once it expires
+	if err := writeRepoKeyMetadata(reponame, repoKeyMetadata{Keyring: keyringFile, PubkeyURL: pubkeyurl}); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to record the key URL of the repository: %v\n", err)
+	}
+
 	return nil
 }
 
@@ -2063,11 +2163,32 @@ func RmExternalRepo(reponame string, force bool) error {
 		if err := rmSourcesCmd.Run(); err != nil {
 			return fmt.Errorf("rm_external_repo: removal of %s.sources failed: %w", reponame, err)
 		}
+		exec.Command("sudo", "rm", "-f", repoKeyMetadataFile(reponame)).Run()
 	} else {
 		// Check if repository is still in use before removing
 		if err := RemoveRepofileIfUnused(sourcesFile, "", keyringFile); err != nil {
 			return fmt.Errorf("rm_external_repo: %w", err)
 		}
+		if _, err := os.Stat(sourcesFile); os.IsNotExist(err) {
+			exec.Command("sudo", "rm", "-f", repoKeyMetadataFile(reponame)).Run()
+		}
+
+		// Tell the user what keeps the repository from being removed
+		if stanzas, err := readSourcesFile(sourcesFile); err == nil {
+			var blocking []InstalledPackage
+			for _, stanza := range stanzas {
+				if !stanza.enabled {
+					continue
+				}
+				packages, err := stanza.packages()
+				if err != nil {
+					Debug(fmt.Sprintf("rm_external_repo: %v", err))
+					continue
+				}
+				blocking = append(blocking, packages...)
+			}
+			printBlockingPackages(reponame, blocking)
+		}
 	}
 
 	return nil
@@ -2140,6 +2261,67 @@ func PackageInstalled(packageName string) bool {
 	return true
 }
 
+// PackageHold holds a package with apt-mark, so upgrades leave it at its installed version
+func PackageHold(packageName string) error {
+	if packageName == "" {
+		return fmt.Errorf("no package name specified")
+	}
+	output, err := exec.Command("sudo", "apt-mark", "hold", packageName).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to hold %s: %w\n%s", packageName, err, strings.TrimSpace(string(output)))
+	}
+	if err := recordPackageHold(packageName, true); err != nil {
+		Debug(fmt.Sprintf("Failed to record the hold of %s: %v", packageName, err))
+	}
+	return nil
+}
+
+// PackageUnhold removes the hold of a package with apt-mark
+func PackageUnhold(packageName string) error {
+	if packageName == "" {
+		return fmt.Errorf("no package name specified")
+	}
+	output, err := exec.Command("sudo", "apt-mark", "unhold", packageName).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to unhold %s: %w\n%s", packageName, err, strings.TrimSpace(string(output)))
+	}
+	if err := recordPackageHold(packageName, false); err != nil {
+		Debug(fmt.Sprintf("Failed to record the unhold of %s: %v", packageName, err))
+	}
+	return nil
+}
+
+// PackageIsHeld checks if a package is installed and held
+func PackageIsHeld(packageName string) bool {
+	cmd := exec.Command("dpkg-query", "-W", "-f=${Status}", packageName)
+	cmd.Env = append(os.Environ(), "LANG=en_US.UTF-8", "LC_ALL=en_US.UTF-8")
+	output, err := cmd.Output()
+	if err != nil {
+		return false
+	}
+	return strings.HasPrefix(string(output), "hold ") && strings.HasSuffix(string(output), " installed")
+}
+
+// InstalledPackages returns the names of all installed packages with a single dpkg-query call
+func InstalledPackages() (map[string]bool, error) {
+	cmd := exec.Command("dpkg-query", "-W", "-f=${Package}\t${db:Status-Abbrev}\n")
+	cmd.Env = append(os.Environ(), "LANG=en_US.UTF-8", "LC_ALL=en_US.UTF-8")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list installed packages: %w", err)
+	}
+
+	packages := make(map[string]bool)
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.Fields(line)
+		// Only packages in the "ii" (or held "hi") state are fully installed
+		if len(fields) == 2 && (fields[1] == "ii" || fields[1] == "hi") {
+			packages[fields[0]] = true
+		}
+	}
+	return packages, nil
+}
+
 // PackageAvailable determines if the specified package exists in a local repository
 func PackageAvailable(packageName string, dpkgArch string) bool {
 	// If dpkgArch is not specified, get the current architecture
@@ -2457,6 +2639,7 @@ func refreshPackageAppStatusWithCache(appName, aptCacheOutput, dpkgStatus, direc
 		if isPackageInstalledFromStatus(pkg, dpkgStatus) {
 			installed = true
 			availablePackage = pkg
+			setAppHeld(appName, isPackageHeldFromStatus(pkg, dpkgStatus))
 			break
 		}
 
@@ -2487,7 +2670,7 @@ func refreshPackageAppStatusWithCache(appName, aptCacheOutput, dpkgStatus, direc
 		// If the package is installed, mark the app as installed
 		if status != "installed" {
 			Debug(fmt.Sprintf("Marking %s as installed", appName))
-			statusDir := filepath.Join(directory, "data", "status")
+			statusDir := filepath.Join(DataDirOf(directory), "status")
 			if err := os.MkdirAll(statusDir, 0755); err != nil {
 				return fmt.Errorf("error creating status directory: %w", err)
 			}
@@ -2504,11 +2687,12 @@ func refreshPackageAppStatusWithCache(appName, aptCacheOutput, dpkgStatus, direc
 			}()
 		}
 	} else {
+		setAppHeld(appName, false)
 		// The package is not installed but available
 		if status != "uninstalled" {
 			Debug(fmt.Sprintf("Marking %s as uninstalled", appName))
 			// Remove the status file to mark it as uninstalled
-			statusFile := filepath.Join(directory, "data", "status", appName)
+			statusFile := filepath.Join(DataDirOf(directory), "status", appName)
 			_ = os.Remove(statusFile) // Ignore error if file doesn't exist
 
 			// Send analytics
@@ -2519,7 +2703,7 @@ func refreshPackageAppStatusWithCache(appName, aptCacheOutput, dpkgStatus, direc
 	}
 
 	// Check if the app is currently hidden but should be unhidden
-	categoryOverridesFile := filepath.Join(directory, "data", "category-overrides")
+	categoryOverridesFile := filepath.Join(DataDirOf(directory), "category-overrides")
 	if FileExists(categoryOverridesFile) {
 		// Check if app is marked as hidden in the category-overrides file
 		file, err := os.Open(categoryOverridesFile)
@@ -2569,8 +2753,27 @@ func isPackageInstalledFromStatus(packageName, dpkgStatus string) bool {
 	}
 
 	// Check the status in the few lines after the package name
-	statusSection := dpkgStatus[index : index+200] // Look at a reasonable section after the package name
-	return strings.Contains(statusSection, "Status: install ok installed")
+	sectionEnd := index + 200 // Look at a reasonable section after the package name
+	if sectionEnd > len(dpkgStatus) {
+		sectionEnd = len(dpkgStatus)
+	}
+	statusSection := dpkgStatus[index:sectionEnd]
+	// Held packages are installed too, their status starts with hold instead of install
+	return strings.Contains(statusSection, "Status: install ok installed") ||
+		strings.Contains(statusSection, "Status: hold ok installed")
+}
+
+// isPackageHeldFromStatus checks if a package is installed and held by looking at the dpkg status
+func isPackageHeldFromStatus(packageName, dpkgStatus string) bool {
+	index := strings.Index(dpkgStatus, fmt.Sprintf("Package: %s\n", packageName))
+	if index == -1 {
+		return false
+	}
+	sectionEnd := index + 200
+	if sectionEnd > len(dpkgStatus) {
+		sectionEnd = len(dpkgStatus)
+	}
+	return strings.Contains(dpkgStatus[index:sectionEnd], "Status: hold ok installed")
 }
 
 // isPackageAvailableFromPolicy checks if a package is available in repositories
@@ -0,0 +1,186 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: apt_bundle.go
+// Description: Provides the package part of app bundles when using the APT package manager.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+//go:build apt
+
+package api
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// bundleSourceList is the apt source list that names only the local repository, used while installing from a bundle
+const bundleSourceList = "/var/cache/pi-apps/pi-apps-local-packages/bundle.list"
+
+// dependsPackageNames returns the package names of a Depends field, the first one of every alternative
+func dependsPackageNames(depends string) []string {
+	var names []string
+	for dependency := range strings.SplitSeq(depends, ",") {
+		first, _, _ := strings.Cut(dependency, "|")
+		if fields := strings.Fields(first); len(fields) > 0 && !slices.Contains(names, fields[0]) {
+			names = append(names, fields[0])
+		}
+	}
+	return names
+}
+
+// packageClosure returns packages with everything they depend on, recursively
+func packageClosure(packages []string) ([]string, error) {
+	args := append([]string{"depends", "--recurse", "--no-recommends", "--no-suggests", "--no-conflicts",
+		"--no-breaks", "--no-replaces", "--no-enhances"}, packages...)
+	output, err := exec.Command("apt-cache", args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve the dependencies of %s: %w", strings.Join(packages, " "), err)
+	}
+	var closure []string
+	for line := range strings.SplitSeq(string(output), "\n") {
+		// Dependencies are indented, virtual packages are in angle brackets
+		if line == "" || strings.HasPrefix(line, " ") || strings.HasPrefix(line, "<") {
+			continue
+		}
+		if name := strings.TrimSpace(line); !slices.Contains(closure, name) {
+			closure = append(closure, name)
+		}
+	}
+	slices.Sort(closure)
+	return closure, nil
+}
+
+// bundleAppPackages downloads the packages an app installs, with everything they depend on, into the pool of a
+// bundle. The whole dependency tree is bundled because the offline device may have less installed.
+//
+//	[]string - the packages the app installs
+func bundleAppPackages(app, appType, poolDir string) ([]string, error) {
+	var depends string
+	switch appType {
+	case "package":
+		packages, err := PkgAppPackagesRequired(app)
+		if err != nil {
+			return nil, err
+		}
+		depends = strings.Join(strings.Fields(packages), ",")
+	case "standard":
+		// An app whose script installs no packages has no dummy deb dependencies
+		var err error
+		if depends, err = dummyDebDependencies(app); err != nil {
+			Debug(fmt.Sprintf("Not bundling packages for %s: %v", app, err))
+			return nil, nil
+		}
+	}
+	packages := dependsPackageNames(depends)
+	if len(packages) == 0 {
+		return nil, nil
+	}
+
+	closure, err := packageClosure(packages)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(poolDir, 0755); err != nil {
+		return nil, err
+	}
+	cmd := exec.Command("apt-get", append([]string{"download"}, closure...)...)
+	cmd.Dir = poolDir
+	if cmd.Run() == nil {
+		return packages, nil
+	}
+
+	// apt-get download stops at the first package it can't download, like local packages that aren't in a
+	// repository anymore. Those come from the download ledger if the install script downloaded them.
+	var missing []string
+	for _, pkg := range closure {
+		cmd := exec.Command("apt-get", "download", pkg)
+		cmd.Dir = poolDir
+		if cmd.Run() != nil {
+			missing = append(missing, pkg)
+		}
+	}
+	if len(missing) > 0 {
+		WarningTf("These packages could not be downloaded and are not in the bundle: %s", strings.Join(missing, " "))
+	}
+	return packages, nil
+}
+
+// addBundlePool adds the packages of the bundle being installed to the local repository
+//
+//	bool - true if the bundle has packages
+func addBundlePool() (bool, error) {
+	debs, err := filepath.Glob(filepath.Join(os.Getenv(BundleDirEnv), bundlePoolDir, "*.deb"))
+	if err != nil || len(debs) == 0 {
+		return false, err
+	}
+
+	// RepoAdd moves the files it adds, and a script can call install_packages more than once
+	tmp, err := os.MkdirTemp("", "pi-apps-bundle-pool-")
+	if err != nil {
+		return false, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tmp)
+	copies := make([]string, 0, len(debs))
+	for _, deb := range debs {
+		dst := filepath.Join(tmp, filepath.Base(deb))
+		if err := CopyFile(deb, dst); err != nil {
+			return false, fmt.Errorf("failed to copy %s: %w", deb, err)
+		}
+		copies = append(copies, dst)
+	}
+	if err := RepoAdd(copies...); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// bundleSourceFlags writes a source list with only the local repository and returns the apt flags that use it,
+// so installing from a bundle downloads nothing. The lists of the other repositories are kept.
+func bundleSourceFlags() ([]string, error) {
+	if err := os.WriteFile(bundleSourceList, []byte("deb [trusted=yes] file:/var/cache/pi-apps/pi-apps-local-packages/ ./\n"), 0644); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", bundleSourceList, err)
+	}
+	return []string{"-o", "Dir::Etc::SourceList=" + bundleSourceList, "-o", "Dir::Etc::SourceParts=-", "-o", "APT::Get::List-Cleanup=0"}, nil
+}
+
+// stageBundlePackages makes the packages of the bundle being installed available to apt. The caller removes
+// the local repository with RepoRm afterwards.
+//
+//	[]string - apt flags that install from the bundle only
+func stageBundlePackages() ([]string, error) {
+	if err := RepoRm(); err != nil {
+		return nil, fmt.Errorf("failed to remove existing local repository: %w", err)
+	}
+	if _, err := addBundlePool(); err != nil {
+		return nil, err
+	}
+	if err := RepoRefresh(); err != nil {
+		return nil, fmt.Errorf("failed to refresh local repository: %w", err)
+	}
+	flags, err := bundleSourceFlags()
+	if err != nil {
+		return nil, err
+	}
+	if err := AptUpdate(flags...); err != nil {
+		return nil, err
+	}
+	return flags, nil
+}
@@ -0,0 +1,73 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: apt_disk_usage.go
+// Description: Reads the package sizes of the disk usage attribution from dpkg.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+//go:build apt
+
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// appPackageNames returns the packages an app's dummy deb depends on, the first one of alternatives.
+// Their own dependencies are not followed, apt may have installed them for other packages too.
+func appPackageNames(app string) []string {
+	depends, err := dummyDebDependencies(app)
+	if err != nil {
+		return nil
+	}
+	var packages []string
+	for _, dependency := range strings.Split(depends, ",") {
+		alternative, _, _ := strings.Cut(dependency, "|")
+		fields := strings.Fields(alternative)
+		if len(fields) == 0 {
+			continue
+		}
+		name, _, _ := strings.Cut(fields[0], ":")
+		packages = append(packages, name)
+	}
+	return packages
+}
+
+// installedPackageSizes returns the installed size of every installed package in bytes, in one dpkg-query pass.
+// The sizes of the architectures of a multiarch package are added up.
+func installedPackageSizes() (map[string]int64, error) {
+	output, err := exec.Command("dpkg-query", "-W", "-f", "${Package}\t${Installed-Size}\t${db:Status-Status}\n").Output()
+	if err != nil {
+		return nil, fmt.Errorf("dpkg-query failed: %w", err)
+	}
+	sizes := make(map[string]int64)
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), "\t")
+		if len(fields) != 3 || fields[2] != "installed" {
+			continue
+		}
+		// Installed-Size is in KiB
+		kib, _ := strconv.ParseInt(fields[1], 10, 64)
+		sizes[fields[0]] += kib * 1024
+	}
+	return sizes, nil
+}
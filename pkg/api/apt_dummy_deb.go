@@ -0,0 +1,338 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: apt_dummy_deb.go
+// Description: Provides functions for rebuilding the dummy debs of installed apps natively in Go (without equivs or dpkg-deb).
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+//go:build apt
+
+package api
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// dummyDebManifest returns the path of the file recording the dependencies of an app's dummy deb
+func dummyDebManifest(app string) string {
+	return filepath.Join(GetDataDir(), "dummy-debs", app)
+}
+
+// saveDummyDebManifest records the dependencies of an app's dummy deb so it can be rebuilt later
+func saveDummyDebManifest(app, depends string) {
+	manifest := dummyDebManifest(app)
+	if err := os.MkdirAll(filepath.Dir(manifest), 0755); err != nil {
+		Debug(fmt.Sprintf("failed to create dummy deb manifest directory: %v", err))
+		return
+	}
+	if err := os.WriteFile(manifest, []byte(depends+"\n"), 0644); err != nil {
+		Debug(fmt.Sprintf("failed to write dummy deb manifest for %s: %v", app, err))
+	}
+}
+
+// dummyDebDependencies reconstructs the Depends field of an app's dummy deb
+//
+// The following sources are tried in order:
+//   - the manifest recorded by InstallPackages
+//   - the legacy installed-packages file
+//   - the packages file of a package-app
+//   - literal install_packages calls in the app's install script
+func dummyDebDependencies(app string) (string, error) {
+	directory := GetPiAppsDir()
+	if directory == "" {
+		return "", fmt.Errorf("PI_APPS_DIR environment variable not set")
+	}
+
+	if data, err := os.ReadFile(dummyDebManifest(app)); err == nil {
+		if depends := strings.TrimSpace(string(data)); depends != "" {
+			return depends, nil
+		}
+	}
+
+	if data, err := os.ReadFile(filepath.Join(DataDirOf(directory), "installed-packages", app)); err == nil {
+		if packages := strings.Fields(string(data)); len(packages) > 0 {
+			return sortAndDeduplicate(packages), nil
+		}
+	}
+
+	if FileExists(filepath.Join(directory, "apps", app, "packages")) {
+		packages, err := PkgAppPackagesRequired(app)
+		if err != nil {
+			return "", err
+		}
+		if packages != "" {
+			return sortAndDeduplicate(strings.Fields(packages)), nil
+		}
+	}
+
+	scriptName, err := ScriptNameCPU(app)
+	if err == nil && scriptName != "" {
+		packages, err := installScriptPackages(filepath.Join(directory, "apps", app, scriptName))
+		if err != nil {
+			return "", err
+		}
+		if len(packages) > 0 {
+			return sortAndDeduplicate(packages), nil
+		}
+	}
+
+	return "", fmt.Errorf("could not determine which packages the %s app depends on", app)
+}
+
+// installScriptPackages returns the package names passed literally to install_packages in an install script
+//
+// Arguments that depend on shell variables, local files or URLs cannot be resolved and are skipped.
+func installScriptPackages(scriptPath string) ([]string, error) {
+	file, err := os.Open(scriptPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open install script: %w", err)
+	}
+	defer file.Close()
+
+	var packages []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "#") || !strings.Contains(line, "install_packages ") {
+			continue
+		}
+
+		fields := strings.Fields(line[strings.Index(line, "install_packages ")+len("install_packages "):])
+		for i := 0; i < len(fields); i++ {
+			field := fields[i]
+			if field == "||" || field == "&&" || field == ";" || strings.HasPrefix(field, "#") {
+				break
+			}
+			if field == "-t" {
+				i++
+				continue
+			}
+			if strings.ContainsAny(field, "$`/*\"'") || strings.Contains(field, "://") {
+				continue
+			}
+			packages = append(packages, field)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read install script: %w", err)
+	}
+
+	return packages, nil
+}
+
+// buildDummyDeb writes a binary deb package with the given control file and no data files
+//
+// The package is an ar archive containing debian-binary, control.tar.gz and data.tar.gz, as produced by dpkg-deb.
+func buildDummyDeb(path, control string) error {
+	modTime := time.Now()
+
+	controlTar, err := tarGz(modTime, map[string]string{"./control": control})
+	if err != nil {
+		return fmt.Errorf("failed to create control archive: %w", err)
+	}
+	dataTar, err := tarGz(modTime, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create data archive: %w", err)
+	}
+
+	var deb bytes.Buffer
+	deb.WriteString("!<arch>\n")
+	members := []struct {
+		name    string
+		content []byte
+	}{
+		{"debian-binary", []byte("2.0\n")},
+		{"control.tar.gz", controlTar},
+		{"data.tar.gz", dataTar},
+	}
+	for _, member := range members {
+		// ar header: name(16) mtime(12) uid(6) gid(6) mode(8) size(10) magic(2)
+		fmt.Fprintf(&deb, "%-16s%-12d%-6d%-6d%-8s%-10d`\n", member.name, modTime.Unix(), 0, 0, "100644", len(member.content))
+		deb.Write(member.content)
+		if len(member.content)%2 != 0 {
+			deb.WriteByte('\n')
+		}
+	}
+
+	return os.WriteFile(path, deb.Bytes(), 0644)
+}
+
+// tarGz creates a gzip compressed tar archive containing the root directory and the given files
+func tarGz(modTime time.Time, files map[string]string) ([]byte, error) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+
+	if err := tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeDir,
+		Name:     "./",
+		Mode:     0755,
+		ModTime:  modTime,
+		Uname:    "root",
+		Gname:    "root",
+	}); err != nil {
+		return nil, err
+	}
+
+	for name, content := range files {
+		if err := tw.WriteHeader(&tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     name,
+			Mode:     0644,
+			Size:     int64(len(content)),
+			ModTime:  modTime,
+			Uname:    "root",
+			Gname:    "root",
+		}); err != nil {
+			return nil, err
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// RebuildDummyDeb regenerates and installs the dummy deb of an app that is still installed
+// but whose dummy deb was removed (for example by an aggressive apt autoremove)
+//
+//	error - error if the app is not installed, its dependencies cannot be determined or dpkg fails
+func RebuildDummyDeb(app string) error {
+	if app == "" {
+		return fmt.Errorf("rebuild_dummy_deb(): requires an app argument")
+	}
+
+	status, err := GetAppStatus(app)
+	if err != nil {
+		return err
+	}
+	if status != "installed" {
+		return fmt.Errorf(T("the %s app is not installed"), app)
+	}
+
+	pkgName, err := AppToPkgName(app)
+	if err != nil {
+		return fmt.Errorf("failed to create package name for app %s: %w", app, err)
+	}
+
+	if PackageInstalled(pkgName) {
+		StatusTf("The %s package of the %s app is already installed. Skipping...", pkgName, app)
+		return nil
+	}
+
+	depends, err := dummyDebDependencies(app)
+	if err != nil {
+		return err
+	}
+
+	StatusTf("Rebuilding the %s package for the %s app...", pkgName, app)
+	fmt.Println("Depends: " + depends)
+
+	// Same control file as InstallPackages, so a later install_packages run recognizes it as unchanged
+	control := fmt.Sprintf(`Maintainer: Pi-Apps Go team
+Name: %s
+Description: %s
+Version: 1.0
+Architecture: all
+Priority: optional
+Section: custom
+Depends: %s
+Package: %s
+`, app, Tf("Dummy package created by pi-apps go to install dependencies for the '%s' app", app), depends, pkgName)
+
+	debPath := filepath.Join(os.TempDir(), pkgName+".deb")
+	if err := buildDummyDeb(debPath, control); err != nil {
+		return fmt.Errorf("failed to create dummy deb %s: %w", pkgName, err)
+	}
+	defer os.Remove(debPath)
+
+	if err := AptLockWait(); err != nil {
+		return fmt.Errorf("failed to wait for APT locks: %w", err)
+	}
+
+	// The dependencies should still be installed, so dpkg alone is enough
+	cmd := exec.Command("sudo", "dpkg", "-i", debPath)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		// Some dependencies went missing too, let apt fetch them
+		WarningTf("dpkg could not configure %s, installing its missing dependencies with apt...", pkgName)
+		aptArgs := append([]string{"-E", "apt-get", "-o", "DPkg::Lock::Timeout=-1"}, aptDownloadLimitFlags()...)
+		cmd = exec.Command("sudo", append(aptArgs, "install", "-fy", "--no-install-recommends")...)
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("failed to install dummy deb %s: %w", pkgName, err)
+		}
+	}
+
+	saveDummyDebManifest(app, depends)
+
+	// The dummy deb is now newer than the app's status, so make sure ListAppsMissingDummyDebs stops reporting it
+	statusFile := filepath.Join(GetDataDir(), "status", app)
+	now := time.Now()
+	os.Chtimes(statusFile, now, now)
+
+	StatusGreenT("Rebuilt the dummy deb of %s", app)
+	return nil
+}
+
+// RebuildDummyDebs rebuilds the dummy debs of the given apps, or of every app
+// reported by ListAppsMissingDummyDebs if no apps are given
+//
+//	[]string - apps whose dummy deb was rebuilt
+//	error - error listing apps, or the combined errors of apps that failed
+func RebuildDummyDebs(apps ...string) ([]string, error) {
+	if len(apps) == 0 {
+		missing, err := ListAppsMissingDummyDebs()
+		if err != nil {
+			return nil, err
+		}
+		apps = missing
+	}
+
+	var rebuilt []string
+	var failures []string
+	for _, app := range apps {
+		if err := RebuildDummyDeb(app); err != nil {
+			ErrorNoExitTf("Failed to rebuild the dummy deb of %s: %v", app, err)
+			failures = append(failures, app+": "+err.Error())
+			continue
+		}
+		rebuilt = append(rebuilt, app)
+	}
+
+	if len(failures) > 0 {
+		return rebuilt, fmt.Errorf("failed to rebuild %d dummy debs:\n%s", len(failures), strings.Join(failures, "\n"))
+	}
+	return rebuilt, nil
+}
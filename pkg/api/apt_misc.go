@@ -33,8 +33,6 @@ import (
 	"strings"
 	"time"
 
-	"github.com/gotk3/gotk3/glib"
-	"github.com/gotk3/gotk3/gtk"
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
 )
@@ -51,33 +49,16 @@ var (
 	UbuntuPPAInstallerMessage  = T("Install Ubuntu PPA")
 	DebianPPAInstallerMessage  = T("Install Debian PPA")
 	PatchDebSedMessage         = T("Modify the control file of a deb file to fix the dependencies following a sed pattern")
+	RebuildDummyDebsMessage    = T("Rebuild missing dummy debs of installed apps (all apps reported by list_apps_missing_dummy_debs by default)")
 )
 
-// checkShellcheck checks if shellcheck is installed and installs it if it isn't
-func checkShellcheck() error {
-	// Initialize application name
-	glib.SetPrgname("Pi-Apps-Settings")
-	glib.SetApplicationName("Pi-Apps Settings (app creation wizard)")
-
-	// Initialize GTK
-	gtk.Init(nil)
-
-	// Check if shellcheck is installed
-	if !commandExists("shellcheck") {
-		// Ask if they want to install shellcheck
-		dialog := gtk.MessageDialogNew(nil, gtk.DIALOG_MODAL, gtk.MESSAGE_QUESTION, gtk.BUTTONS_YES_NO,
-			"Shellcheck is not installed, but it's useful for finding errors in shell scripts. Install it now?")
-		response := dialog.Run()
-		dialog.Destroy()
-
-		if response == gtk.RESPONSE_YES {
-			cmd := exec.Command("sudo", "apt-get", "install", "-y", "shellcheck")
-			cmd.Stdout = os.Stdout
-			cmd.Stderr = os.Stderr
-			if err := cmd.Run(); err != nil {
-				return fmt.Errorf("failed to install shellcheck: %v", err)
-			}
-		}
+// installShellcheck installs shellcheck with the package manager
+func installShellcheck() error {
+	cmd := exec.Command("sudo", "apt-get", "install", "-y", "shellcheck")
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to install shellcheck: %v", err)
 	}
 	return nil
 }
@@ -680,8 +661,8 @@ func installPackageApp(appName string) error {
 	piAppsDir := GetPiAppsDir()
 
 	// Set up logging
-	logDir := filepath.Join(piAppsDir, "logs")
-	os.MkdirAll(logDir, 0755)
+	logDir := LogsDirOf(piAppsDir)
+	EnsureDirOwned(logDir, InvokingUser, InvokingUser)
 	logFilename := fmt.Sprintf("install-%s-incomplete-%d.log", appName, time.Now().Unix())
 	logPath := filepath.Join(logDir, logFilename)
 
@@ -772,7 +753,7 @@ func installPackageApp(appName string) error {
 		FormatLogfile(logPath)
 
 		// Rename log file to indicate failure
-		newLogPath := strings.Replace(logPath, "-incomplete-", "-fail-", 1)
+		newLogPath := logPathWithResult(logPath, "fail")
 		os.Rename(logPath, newLogPath)
 
 		return fmt.Errorf("failed to install packages: %v", err)
@@ -786,7 +767,7 @@ func installPackageApp(appName string) error {
 	FormatLogfile(logPath)
 
 	// Rename log file to indicate success
-	newLogPath := strings.Replace(logPath, "-incomplete-", "-success-", 1)
+	newLogPath := logPathWithResult(logPath, "success")
 	os.Rename(logPath, newLogPath)
 
 	// Mark app as installed
@@ -798,8 +779,8 @@ func uninstallPackageApp(appName string) error {
 	piAppsDir := GetPiAppsDir()
 
 	// Set up logging
-	logDir := filepath.Join(piAppsDir, "logs")
-	os.MkdirAll(logDir, 0755)
+	logDir := LogsDirOf(piAppsDir)
+	EnsureDirOwned(logDir, InvokingUser, InvokingUser)
 	logFilename := fmt.Sprintf("uninstall-%s-incomplete-%d.log", appName, time.Now().Unix())
 	logPath := filepath.Join(logDir, logFilename)
 
@@ -890,7 +871,7 @@ func uninstallPackageApp(appName string) error {
 		FormatLogfile(logPath)
 
 		// Rename log file to indicate failure
-		newLogPath := strings.Replace(logPath, "-incomplete-", "-fail-", 1)
+		newLogPath := logPathWithResult(logPath, "fail")
 		os.Rename(logPath, newLogPath)
 
 		return fmt.Errorf("failed to uninstall packages: %v", err)
@@ -904,7 +885,7 @@ func uninstallPackageApp(appName string) error {
 	FormatLogfile(logPath)
 
 	// Rename log file to indicate success
-	newLogPath := strings.Replace(logPath, "-incomplete-", "-success-", 1)
+	newLogPath := logPathWithResult(logPath, "success")
 	os.Rename(logPath, newLogPath)
 
 	// Mark app as uninstalled
@@ -913,8 +894,20 @@ func uninstallPackageApp(appName string) error {
 
 // installPackageAppDependencies installs the dependencies for a package-based app without pi-apps having to create a new virtual package
 func installPackageAppDependencies(dependencies ...string) error {
+	args := []string{"apt-get", "install", "-yf", "--no-install-recommends"}
+
+	// Installing from an app bundle, the packages come from its pool
+	if bundleStaged() {
+		flags, err := stageBundlePackages()
+		if err != nil {
+			return err
+		}
+		defer RepoRm()
+		args = append(args, flags...)
+	}
+
 	// Install packages with sudo
-	cmd := exec.Command("sudo", append([]string{"apt-get", "install", "-yf", "--no-install-recommends"}, dependencies...)...)
+	cmd := exec.Command("sudo", append(args, dependencies...)...)
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 
@@ -0,0 +1,181 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: apt_package_request.go
+// Description: Resolves the version-pinned, release-targeted and foreign-architecture package requests of InstallPackages with apt.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+//go:build apt
+
+package api
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// resolvedPackageRequest is a package request with the concrete version apt will install for it
+type resolvedPackageRequest struct {
+	PackageRequest
+	Resolved string
+}
+
+// dependency returns the request as an entry of the Depends field of a dummy deb. A pinned version is required
+// exactly, a release only sets the minimum, so later upgrades from that release aren't blocked by the dummy deb.
+func (r resolvedPackageRequest) dependency() string {
+	switch {
+	case r.Version != "":
+		return fmt.Sprintf("%s (= %s)", r.Package(), r.Resolved)
+	case r.Release != "":
+		return fmt.Sprintf("%s (>= %s)", r.Package(), r.Resolved)
+	}
+	return r.Package()
+}
+
+// aptArg returns the argument apt-get install needs next to the dummy deb to pick the requested version,
+// "" if the Depends field is enough. apt only considers the candidate version when resolving dependencies.
+func (r resolvedPackageRequest) aptArg() string {
+	if r.Version == "" && r.Release == "" {
+		return ""
+	}
+	return r.String()
+}
+
+// aptPolicyVersion is a version of a package in the version table of apt-cache policy
+type aptPolicyVersion struct {
+	Version string
+	Suites  []string // suites it is available from, like bookworm-backports
+}
+
+// parseAptPolicyVersions returns the version table of the apt-cache policy output of a single package, newest first
+func parseAptPolicyVersions(output string) []aptPolicyVersion {
+	var versions []aptPolicyVersion
+	inTable := false
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "Version table:" {
+			inTable = true
+			continue
+		}
+		fields := strings.Fields(strings.TrimPrefix(line, " ***"))
+		if !inTable || len(fields) == 0 {
+			continue
+		}
+		// Version lines are indented by 5 spaces, the sources of a version by 8
+		if indent := len(line) - len(strings.TrimLeft(line, " *")); indent < 8 {
+			versions = append(versions, aptPolicyVersion{Version: fields[0]})
+		} else if len(versions) > 0 && len(fields) >= 3 {
+			suite, _, _ := strings.Cut(fields[2], "/")
+			versions[len(versions)-1].Suites = append(versions[len(versions)-1].Suites, suite)
+		}
+	}
+	return versions
+}
+
+// resolvePackageRequests finds the version apt will install for every request. Requests without their own
+// version or release get defaultRelease, the -t argument of install_packages.
+// Every request that can't be satisfied is listed in the error, not only the first one.
+func resolvePackageRequests(requests []PackageRequest, defaultRelease string) ([]resolvedPackageRequest, error) {
+	var resolved []resolvedPackageRequest
+	var problems []string
+	for _, request := range requests {
+		if request.Version == "" && request.Release == "" {
+			request.Release = defaultRelease
+		}
+
+		output, err := exec.Command("apt-cache", "policy", request.Package()).Output()
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: apt-cache policy failed: %v", request, err))
+			continue
+		}
+		versions := parseAptPolicyVersions(string(output))
+		if len(versions) == 0 {
+			if request.Arch != "" {
+				problems = append(problems, Tf("%s: the package is not available for the %s architecture", request, request.Arch))
+			} else {
+				problems = append(problems, Tf("%s: the package is not available", request))
+			}
+			continue
+		}
+
+		var available []string
+		match := ""
+		for _, version := range versions {
+			if request.Release != "" && !slices.Contains(version.Suites, request.Release) {
+				continue
+			}
+			available = append(available, version.Version)
+			if match == "" && (request.Version == "" || version.Version == request.Version) {
+				match = version.Version
+			}
+		}
+		switch {
+		case match != "":
+			resolved = append(resolved, resolvedPackageRequest{PackageRequest: request, Resolved: match})
+		case request.Release != "":
+			problems = append(problems, Tf("%s: the package is not available from %s", request, request.Release))
+		default:
+			problems = append(problems, Tf("%s: version %s is not available, available versions: %s", request, request.Version, strings.Join(available, ", ")))
+		}
+	}
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("%s\n  %s", T("these package requests can't be satisfied:"), strings.Join(problems, "\n  "))
+	}
+	return resolved, nil
+}
+
+// markPackagesAuto marks packages as automatically installed. apt marks the packages given on its command line
+// as manually installed, which would keep them installed after the dummy deb that needs them is purged.
+func markPackagesAuto(packages []string) {
+	if len(packages) == 0 {
+		return
+	}
+	if output, err := exec.Command("sudo", append([]string{"apt-mark", "auto"}, packages...)...).CombinedOutput(); err != nil {
+		Warning(Tf("Failed to mark %s as automatically installed: %s", strings.Join(packages, " "), strings.TrimSpace(string(output))))
+	}
+}
+
+// pinnedPackagesFile returns the file recording the concrete versions of the constrained package requests of an app
+func pinnedPackagesFile(app string) string {
+	return filepath.Join(GetDataDir(), "pinned-packages", app)
+}
+
+// savePinnedPackages records the request and the version installed for it of every constrained package
+// request of an app, one "request version" line per package, so it is known which versions the app installed
+func savePinnedPackages(app string, resolved []resolvedPackageRequest) {
+	file := pinnedPackagesFile(app)
+	if len(resolved) == 0 {
+		os.Remove(file)
+		return
+	}
+	var content strings.Builder
+	for _, request := range resolved {
+		fmt.Fprintf(&content, "%s %s\n", request, request.Resolved)
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		Debug(fmt.Sprintf("failed to create the pinned packages directory: %v", err))
+		return
+	}
+	if err := os.WriteFile(file, []byte(content.String()), 0644); err != nil {
+		Debug(fmt.Sprintf("failed to record the pinned packages of %s: %v", app, err))
+	}
+}
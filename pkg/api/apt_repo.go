@@ -25,7 +25,6 @@ import (
 	"bufio"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"regexp"
 	"slices"
@@ -39,24 +38,43 @@ import (
 //	true - at least one package is installed from the repository
 //	error - error if repository URI, suite, or component is not specified
 func AnythingInstalledFromURISuiteComponent(uri, suite, component string) (bool, error) {
-	Debug(fmt.Sprintf("Checking if anything is installed from %s %s %s", uri, suite, component))
+	packages, err := PackagesInstalledFromRepo(uri, suite, component)
+	if err != nil {
+		return false, err
+	}
+	return len(packages) > 0, nil
+}
+
+// PackagesInstalledFromRepo lists the installed packages whose installed version is provided by a specific
+// APT repository (identified by URI, suite, and optional component).
+//
+// The package indexes in /var/lib/apt/lists, the dpkg status and apt's extended_states are read directly
+// instead of running apt-cache or apt-mark for every package.
+//
+//	[]InstalledPackage - installed packages from the repository, sorted by name
+//	error - error if the package lists or the dpkg status cannot be read
+func PackagesInstalledFromRepo(uri, suite, component string) ([]InstalledPackage, error) {
+	Debug(fmt.Sprintf("Checking what is installed from %s %s %s", uri, suite, component))
 
-	// Clean URI by removing protocol and trailing slashes
+	// Clean URI by removing protocol and trailing slashes, apt escapes underscores in list file names as %5f
 	cleanURI := strings.TrimSuffix(regexp.MustCompile(`.*://`).ReplaceAllString(uri, ""), "/")
+	cleanURI = strings.ReplaceAll(cleanURI, "_", "%5f")
 	cleanURI = strings.ReplaceAll(cleanURI, "/", "_")
 
 	// Clean suite by removing trailing slashes
 	cleanSuite := strings.TrimSuffix(suite, "/")
+	cleanSuite = strings.ReplaceAll(cleanSuite, "_", "%5f")
 	cleanSuite = strings.ReplaceAll(cleanSuite, "/", "_")
 
 	// Construct filepath pattern based on presence of component
 	var filepathPattern string
 	if component == "" {
-		filepathPattern = fmt.Sprintf("/var/lib/apt/lists/%s_%s_", cleanURI, cleanSuite)
+		filepathPattern = filepath.Join(aptListsDir, fmt.Sprintf("%s_%s_", cleanURI, cleanSuite))
 	} else {
 		cleanComponent := strings.TrimSuffix(component, "/")
+		cleanComponent = strings.ReplaceAll(cleanComponent, "_", "%5f")
 		cleanComponent = strings.ReplaceAll(cleanComponent, "/", "_")
-		filepathPattern = fmt.Sprintf("/var/lib/apt/lists/%s_dists_%s_%s_", cleanURI, cleanSuite, cleanComponent)
+		filepathPattern = filepath.Join(aptListsDir, fmt.Sprintf("%s_dists_%s_%s_", cleanURI, cleanSuite, cleanComponent))
 	}
 
 	Debug(filepathPattern)
@@ -64,56 +82,79 @@ func AnythingInstalledFromURISuiteComponent(uri, suite, component string) (bool,
 	// Find all relevant package list files
 	matches, err := filepath.Glob(filepathPattern + "*_Packages")
 	if err != nil {
-		return false, fmt.Errorf("failed to find package lists: %w", err)
+		return nil, fmt.Errorf("failed to find package lists: %w", err)
 	}
 
 	Debug(strings.Join(matches, "\n"))
 
 	if len(matches) == 0 {
-		return false, nil
-	}
-
-	// Get list of installed packages
-	installedPackages, err := getInstalledPackages()
-	if err != nil {
-		return false, fmt.Errorf("failed to get installed packages: %w", err)
+		return nil, nil
 	}
 
-	// For each repo file, check if any packages are installed from it
+	// Versions of every package the repository provides
+	repoVersions := make(map[string]map[string]bool)
 	for _, repoFile := range matches {
-		packagesInRepo, err := getPackagesInRepo(repoFile)
+		err := readDebStanzas(repoFile, func(fields map[string]string) {
+			name, version := fields["Package"], fields["Version"]
+			if name == "" || version == "" {
+				return
+			}
+			if repoVersions[name] == nil {
+				repoVersions[name] = make(map[string]bool)
+			}
+			repoVersions[name][version] = true
+		})
 		if err != nil {
-			return false, fmt.Errorf("failed to get packages in repo %s: %w", repoFile, err)
+			return nil, fmt.Errorf("failed to get packages in repo %s: %w", repoFile, err)
 		}
+	}
 
-		// Find intersection of installed packages and packages in repo
-		var packagesToCheck []string
-		for _, pkg := range packagesInRepo {
-			if slices.Contains(installedPackages, pkg) {
-				packagesToCheck = append(packagesToCheck, pkg)
-			}
-		}
+	installedPackages, err := getInstalledPackages()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get installed packages: %w", err)
+	}
+	automatic, err := getAutoInstalledPackages()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get automatically installed packages: %w", err)
+	}
 
-		if len(packagesToCheck) == 0 {
+	// A package is installed from the repository if its installed version is one the repository provides
+	var result []InstalledPackage
+	seen := make(map[string]bool)
+	for _, pkg := range installedPackages {
+		if !repoVersions[pkg.Name][pkg.Version] || seen[pkg.Name+"="+pkg.Version] {
 			continue
 		}
-
-		// Check if any of these packages are installed from this repo
-		isInstalled, err := checkIfPackagesInstalledFromRepo(packagesToCheck, uri, suite, component)
-		if err != nil {
-			return false, fmt.Errorf("failed to check if packages are installed from repo: %w", err)
-		}
-
-		if isInstalled {
-			return true, nil
-		}
+		seen[pkg.Name+"="+pkg.Version] = true
+		pkg.Automatic = automatic[pkg.Name]
+		result = append(result, pkg)
 	}
+	slices.SortFunc(result, func(a, b InstalledPackage) int {
+		return strings.Compare(a.Name, b.Name)
+	})
 
-	return false, nil
+	return result, nil
 }
 
+// Where apt and dpkg keep their state, variables so tests can point them at fixtures
+var (
+	// aptKeyringDirs are the directories Signed-By keyrings are removed from once no repository uses them
+	aptKeyringDirs = []string{"/usr/share/keyrings", "/etc/apt/keyrings", "/etc/apt/trusted.gpg.d"}
+	// aptSourcesList is the main repository file, the others are in aptSourcesDir
+	aptSourcesList = "/etc/apt/sources.list"
+	// aptListsDir holds the downloaded package indexes of every repository
+	aptListsDir = "/var/lib/apt/lists"
+	// dpkgStatusFile and aptExtendedStatesFile list the installed and the automatically installed packages
+	dpkgStatusFile        = "/var/lib/dpkg/status"
+	aptExtendedStatesFile = "/var/lib/apt/extended_states"
+)
+
 // RemoveRepofileIfUnused removes a sources.list.d file if nothing from that repository is currently installed.
 //
+// Stanzas of a deb822 .sources file are checked one by one: unused stanzas are removed from the file,
+// and the file is only removed once none of its stanzas are in use. Keyrings referenced with Signed-By
+// by the removed entries, and the given key, are removed when no other repository file points at them.
+//
 // If testMode is "test", it only outputs the status without removing anything.
 //
 //	error - error if file is not specified or testMode is not "test"
@@ -123,6 +164,9 @@ func RemoveRepofileIfUnused(file, testMode, key string) error {
 		return nil
 	}
 
+	// Keyrings to remove if nothing points at them anymore
+	var keyrings []string
+
 	// Determine file type and process accordingly
 	fileExt := filepath.Ext(file)
 	switch fileExt {
@@ -138,18 +182,70 @@ func RemoveRepofileIfUnused(file, testMode, key string) error {
 			}
 			return nil
 		}
+		keyrings, err = listFileKeyrings(file)
+		if err != nil {
+			return fmt.Errorf("failed to process list file: %w", err)
+		}
 	case ".sources":
-		inUse, err := handleSourcesFile(file)
+		stanzas, err := readSourcesFile(file)
 		if err != nil {
 			return fmt.Errorf("failed to process sources file: %w", err)
 		}
 
+		var kept, unused []sourcesStanza
+		inUse := false
+		for _, stanza := range stanzas {
+			// Comment blocks and disabled stanzas neither keep the file nor get removed on their own
+			if !stanza.enabled || len(stanza.uris) == 0 {
+				kept = append(kept, stanza)
+				continue
+			}
+			used, err := stanza.inUse()
+			if err != nil {
+				return fmt.Errorf("failed to process sources file: %w", err)
+			}
+			if used {
+				inUse = true
+				kept = append(kept, stanza)
+			} else {
+				unused = append(unused, stanza)
+			}
+		}
+
 		if inUse {
+			if len(unused) == 0 {
+				if testMode == "test" {
+					fmt.Fprintln(os.Stderr, "At least one package is preventing the repo from being removed")
+				}
+				return nil
+			}
+
 			if testMode == "test" {
-				fmt.Fprintln(os.Stderr, "At least one package is preventing the repo from being removed")
+				fmt.Fprintf(os.Stderr, "At least one package is preventing the repo from being removed, but these entries of %s are not in use and can be deleted:\n", file)
+				for _, stanza := range unused {
+					fmt.Fprintf(os.Stderr, "%s %s\n", strings.Join(stanza.uris, " "), strings.Join(stanza.suites, " "))
+				}
+				return nil
+			}
+
+			Status(fmt.Sprintf("Removing %d unused entries from %s", len(unused), file))
+			if err := writeSourcesFile(file, kept); err != nil {
+				return fmt.Errorf("failed to rewrite sources file: %w", err)
+			}
+			for _, stanza := range unused {
+				if stanza.signedBy != "" {
+					keyrings = append(keyrings, stanza.signedBy)
+				}
 			}
+			removeUnusedKeyrings(keyrings, "")
 			return nil
 		}
+
+		for _, stanza := range stanzas {
+			if stanza.signedBy != "" {
+				keyrings = append(keyrings, stanza.signedBy)
+			}
+		}
 	default:
 		return fmt.Errorf("%s was not of apt list or sources type", file)
 	}
@@ -167,17 +263,56 @@ func RemoveRepofileIfUnused(file, testMode, key string) error {
 		return fmt.Errorf("failed to remove repo file: %w", err)
 	}
 
-	// Remove key file if it exists
-	if key != "" {
-		if _, err := os.Stat(key); err == nil {
-			if err := os.Remove(key); err != nil {
-				// Not returning error as this is not critical
-				Warning(fmt.Sprintf("Failed to remove key file %s: %s", key, err))
+	removeUnusedKeyrings(keyrings, key)
+	return nil
+}
+
+// removeUnusedKeyrings removes the keyrings no remaining repository file points at
+//
+// Keyrings found in repository files are only removed from the standard keyring directories,
+// the key passed to RemoveRepofileIfUnused is removed wherever it is.
+func removeUnusedKeyrings(keyrings []string, key string) {
+	candidates := make(map[string]bool)
+	for _, keyring := range keyrings {
+		for _, dir := range aptKeyringDirs {
+			if filepath.Dir(filepath.Clean(keyring)) == dir {
+				candidates[filepath.Clean(keyring)] = true
 			}
 		}
 	}
+	if key != "" {
+		candidates[filepath.Clean(key)] = true
+	}
 
-	return nil
+	for keyring := range candidates {
+		if _, err := os.Stat(keyring); err != nil {
+			continue
+		}
+		if file := repofileUsingKeyring(keyring); file != "" {
+			Debug(fmt.Sprintf("Keeping %s as it is still used by %s", keyring, file))
+			continue
+		}
+		if err := os.Remove(keyring); err != nil {
+			// Not returning error as this is not critical
+			Warning(fmt.Sprintf("Failed to remove key file %s: %s", keyring, err))
+		}
+	}
+}
+
+// repofileUsingKeyring returns the first apt repository file that mentions a keyring, or ""
+func repofileUsingKeyring(keyring string) string {
+	files := []string{aptSourcesList}
+	for _, pattern := range []string{filepath.Join(aptSourcesDir, "*.list"), filepath.Join(aptSourcesDir, "*.sources")} {
+		matches, _ := filepath.Glob(pattern)
+		files = append(files, matches...)
+	}
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err == nil && strings.Contains(string(content), keyring) {
+			return file
+		}
+	}
+	return ""
 }
 
 // Helper function to handle .list files
@@ -241,208 +376,246 @@ func handleListFile(file string) (bool, error) {
 	return false, nil
 }
 
-// Helper function to handle .sources files
-func handleSourcesFile(file string) (bool, error) {
+// listFileKeyrings returns the signed-by keyrings of the deb lines of a .list file
+func listFileKeyrings(file string) ([]string, error) {
 	fileContent, err := os.ReadFile(file)
 	if err != nil {
-		return false, fmt.Errorf("failed to read file %s: %w", file, err)
+		return nil, fmt.Errorf("failed to read file %s: %w", file, err)
 	}
 
-	lines := strings.Split(string(fileContent), "\n")
-
-	// Find empty lines that separate stanzas
-	emptyLines := []int{}
-	for i, line := range lines {
-		if line == "" || regexp.MustCompile(`^\s+$`).MatchString(line) || regexp.MustCompile(`^\t+$`).MatchString(line) {
-			emptyLines = append(emptyLines, i)
-		}
+	var keyrings []string
+	for _, match := range signedByOptionRegex.FindAllStringSubmatch(string(fileContent), -1) {
+		keyrings = append(keyrings, strings.Split(match[1], ",")...)
 	}
+	return keyrings, nil
+}
 
-	// Always add the last line
-	emptyLines = append(emptyLines, len(lines))
+var (
+	signedByOptionRegex = regexp.MustCompile(`(?m)^deb(?:-src)?\s+\[[^\]]*\bsigned-by=([^\s\]]+)`)
+	deb822FieldRegex    = regexp.MustCompile(`^([A-Za-z0-9-]+):\s*(.*)$`)
+)
 
-	Debug(fmt.Sprintf("Empty lines: %v", emptyLines))
+// sourcesStanza is one stanza of a deb822 .sources file
+type sourcesStanza struct {
+	lines      []string // original lines, comments included, so the stanza can be written back unchanged
+	enabled    bool
+	uris       []string
+	suites     []string
+	components []string
+	signedBy   string // keyring file, "" if there is none or the key is embedded in the stanza
+	signedKey  string // armored key embedded in the stanza, "" if there is none
+}
 
-	// Parse each stanza
-	lineStart := 0
-	for _, lineEnd := range emptyLines {
-		stanza := lines[lineStart:lineEnd]
+// readSourcesFile splits a deb822 .sources file into its stanzas
+func readSourcesFile(file string) ([]sourcesStanza, error) {
+	fileContent, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file %s: %w", file, err)
+	}
 
-		// Skip if Enabled: no
-		enabled := true
-		for _, line := range stanza {
-			if regexp.MustCompile(`(?i)^Enabled:\s*no`).MatchString(line) {
-				enabled = false
-				break
+	var stanzas []sourcesStanza
+	var lines []string
+	for _, line := range strings.Split(string(fileContent), "\n") {
+		if strings.TrimSpace(line) == "" {
+			if len(lines) > 0 {
+				stanzas = append(stanzas, parseSourcesStanza(lines))
+				lines = nil
 			}
-		}
-
-		if !enabled {
-			lineStart = lineEnd + 1
 			continue
 		}
+		lines = append(lines, line)
+	}
+	if len(lines) > 0 {
+		stanzas = append(stanzas, parseSourcesStanza(lines))
+	}
+
+	Debug(fmt.Sprintf("Found %d stanzas in %s", len(stanzas), file))
+	return stanzas, nil
+}
 
-		// Extract URIs, Suites, and Components
-		var uris, suites, components []string
+// parseSourcesStanza reads the fields of a stanza, joining continuation lines to their field
+func parseSourcesStanza(lines []string) sourcesStanza {
+	stanza := sourcesStanza{lines: lines, enabled: true}
 
-		for _, line := range stanza {
-			if strings.HasPrefix(line, "#") {
+	fields := make(map[string]string)
+	var current string
+	for _, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "#"):
+			continue
+		case strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t"):
+			if current != "" {
+				fields[current] += "\n" + strings.TrimSpace(line)
+			}
+		default:
+			match := deb822FieldRegex.FindStringSubmatch(line)
+			if match == nil {
+				current = ""
 				continue
 			}
+			current = strings.ToLower(match[1])
+			fields[current] = strings.TrimSpace(match[2])
+		}
+	}
 
-			if regexp.MustCompile(`(?i)^URIs:`).MatchString(line) {
-				uriLine := regexp.MustCompile(`(?i)^URIs:`).ReplaceAllString(line, "")
-				uriLine = strings.TrimSpace(uriLine)
-				uris = strings.Fields(uriLine)
-			} else if regexp.MustCompile(`(?i)^Suites:`).MatchString(line) {
-				suiteLine := regexp.MustCompile(`(?i)^Suites:`).ReplaceAllString(line, "")
-				suiteLine = strings.TrimSpace(suiteLine)
-				suites = strings.Fields(suiteLine)
-			} else if regexp.MustCompile(`(?i)^Components:`).MatchString(line) {
-				componentLine := regexp.MustCompile(`(?i)^Components:`).ReplaceAllString(line, "")
-				componentLine = strings.TrimSpace(componentLine)
-				components = strings.Fields(componentLine)
+	stanza.enabled = !strings.EqualFold(fields["enabled"], "no")
+	stanza.uris = strings.Fields(fields["uris"])
+	stanza.suites = strings.Fields(fields["suites"])
+	stanza.components = strings.Fields(fields["components"])
+	// An embedded key spans several lines, a keyring is a single path
+	if signedBy := fields["signed-by"]; signedBy != "" && !strings.Contains(signedBy, "\n") && strings.HasPrefix(signedBy, "/") {
+		stanza.signedBy = signedBy
+	} else if strings.Contains(signedBy, "-----BEGIN PGP PUBLIC KEY BLOCK-----") {
+		// Empty lines of the key are written as a single dot in a deb822 field
+		keyLines := strings.Split(signedBy, "\n")
+		for i, line := range keyLines {
+			if line == "." {
+				keyLines[i] = ""
 			}
 		}
+		stanza.signedKey = strings.Join(keyLines, "\n")
+	}
+	return stanza
+}
 
-		// Check if anything is installed from any combination
-		for _, uri := range uris {
-			for _, suite := range suites {
-				if len(components) == 0 {
-					inUse, err := AnythingInstalledFromURISuiteComponent(uri, suite, "")
-					if err != nil {
-						return false, fmt.Errorf("failed to check if anything is installed from %s %s: %w", uri, suite, err)
-					}
-
-					if inUse {
-						return true, nil
-					}
-				} else {
-					for _, component := range components {
-						inUse, err := AnythingInstalledFromURISuiteComponent(uri, suite, component)
-						if err != nil {
-							return false, fmt.Errorf("failed to check if anything is installed from %s %s %s: %w", uri, suite, component, err)
-						}
-
-						if inUse {
-							return true, nil
-						}
-					}
+// inUse reports whether anything is installed from any URI, suite and component of the stanza
+func (stanza sourcesStanza) inUse() (bool, error) {
+	for _, uri := range stanza.uris {
+		for _, suite := range stanza.suites {
+			if len(stanza.components) == 0 {
+				inUse, err := AnythingInstalledFromURISuiteComponent(uri, suite, "")
+				if err != nil {
+					return false, fmt.Errorf("failed to check if anything is installed from %s %s: %w", uri, suite, err)
+				}
+
+				if inUse {
+					return true, nil
 				}
+				continue
 			}
-		}
+			for _, component := range stanza.components {
+				inUse, err := AnythingInstalledFromURISuiteComponent(uri, suite, component)
+				if err != nil {
+					return false, fmt.Errorf("failed to check if anything is installed from %s %s %s: %w", uri, suite, component, err)
+				}
 
-		lineStart = lineEnd + 1
+				if inUse {
+					return true, nil
+				}
+			}
+		}
 	}
-
 	return false, nil
 }
 
-// Helper function to get the list of all installed packages
-func getInstalledPackages() ([]string, error) {
-	statusFile, err := os.Open("/var/lib/dpkg/status")
-	if err != nil {
-		return nil, fmt.Errorf("failed to open status file: %w", err)
-	}
-	defer statusFile.Close()
-
-	var installedPackages []string
-	var packageName string
-	var isInstalled bool
-
-	scanner := bufio.NewScanner(statusFile)
-	for scanner.Scan() {
-		line := scanner.Text()
-
-		switch {
-		case line == "":
-			// End of package entry
-			if isInstalled && packageName != "" {
-				installedPackages = append(installedPackages, packageName)
+// packages returns the installed packages from the repositories of a stanza
+func (stanza sourcesStanza) packages() ([]InstalledPackage, error) {
+	var result []InstalledPackage
+	for _, uri := range stanza.uris {
+		for _, suite := range stanza.suites {
+			components := stanza.components
+			if len(components) == 0 {
+				components = []string{""}
+			}
+			for _, component := range components {
+				packages, err := PackagesInstalledFromRepo(uri, suite, component)
+				if err != nil {
+					return nil, fmt.Errorf("failed to list packages installed from %s %s %s: %w", uri, suite, component, err)
+				}
+				result = append(result, packages...)
 			}
-
-			packageName = ""
-			isInstalled = false
-			continue
-		case strings.HasPrefix(line, "Package: "):
-			packageName = strings.TrimPrefix(line, "Package: ")
-		case line == "Status: install ok installed":
-			isInstalled = true
 		}
 	}
+	return result, nil
+}
 
-	// Handle the last package entry
-	if isInstalled && packageName != "" {
-		installedPackages = append(installedPackages, packageName)
+// writeSourcesFile replaces a .sources file with the given stanzas, keeping its permissions
+func writeSourcesFile(file string, stanzas []sourcesStanza) error {
+	info, err := os.Stat(file)
+	if err != nil {
+		return err
 	}
 
-	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("error reading status file: %w", err)
+	paragraphs := make([]string, 0, len(stanzas))
+	for _, stanza := range stanzas {
+		paragraphs = append(paragraphs, strings.Join(stanza.lines, "\n"))
 	}
 
-	return installedPackages, nil
+	// Write next to the file and rename it, so apt never reads a half written file
+	tempFile := file + ".tmp"
+	if err := os.WriteFile(tempFile, []byte(strings.Join(paragraphs, "\n\n")+"\n"), info.Mode().Perm()); err != nil {
+		return err
+	}
+	if err := os.Rename(tempFile, file); err != nil {
+		os.Remove(tempFile)
+		return err
+	}
+	return nil
 }
 
-// Helper function to get the list of packages in a repo file
-func getPackagesInRepo(repoFile string) ([]string, error) {
-	file, err := os.Open(repoFile)
+// readDebStanzas calls handle with the fields of every stanza of a dpkg status or Packages index file
+//
+// Continuation lines are skipped, only the single line fields are needed here.
+func readDebStanzas(file string, handle func(fields map[string]string)) error {
+	f, err := os.Open(file)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open repo file: %w", err)
+		return err
 	}
-	defer file.Close()
-
-	var packages []string
-	scanner := bufio.NewScanner(file)
+	defer f.Close()
 
+	fields := make(map[string]string)
+	scanner := bufio.NewScanner(f)
+	// Some fields of a Packages index (e.g. long descriptions) are longer than the default buffer
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
 	for scanner.Scan() {
 		line := scanner.Text()
-		if strings.HasPrefix(line, "Package: ") {
-			packageName := strings.TrimSpace(strings.TrimPrefix(line, "Package: "))
-			packages = append(packages, packageName)
+		switch {
+		case line == "":
+			if len(fields) > 0 {
+				handle(fields)
+				fields = make(map[string]string)
+			}
+		case line[0] == ' ' || line[0] == '\t':
+			continue
+		default:
+			if key, value, found := strings.Cut(line, ":"); found {
+				fields[key] = strings.TrimSpace(value)
+			}
 		}
 	}
-
-	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("error reading repo file: %w", err)
+	if len(fields) > 0 {
+		handle(fields)
 	}
-
-	return packages, nil
+	return scanner.Err()
 }
 
-// Helper function to check if any packages are installed from a specific repo
-func checkIfPackagesInstalledFromRepo(packages []string, uri, suite, component string) (bool, error) {
-	if len(packages) == 0 {
-		return false, nil
-	}
-
-	// Prepare the apt-cache policy command
-	cmd := exec.Command("apt-cache", append([]string{"policy"}, packages...)...)
-	output, err := cmd.Output()
+// Helper function to get the name and version of all installed packages
+func getInstalledPackages() ([]InstalledPackage, error) {
+	var installedPackages []InstalledPackage
+	err := readDebStanzas(dpkgStatusFile, func(fields map[string]string) {
+		if fields["Status"] == "install ok installed" && fields["Package"] != "" {
+			installedPackages = append(installedPackages, InstalledPackage{
+				Name:    fields["Package"],
+				Version: fields["Version"],
+			})
+		}
+	})
 	if err != nil {
-		return false, fmt.Errorf("failed to run apt-cache policy: %w", err)
-	}
-
-	// Clean URI for comparison
-	cleanURI := strings.TrimSuffix(regexp.MustCompile(`.*://`).ReplaceAllString(uri, ""), "/")
-
-	// Prepare the pattern to look for in the apt-cache output
-	var pattern string
-	if component == "" {
-		pattern = fmt.Sprintf("%s %s", cleanURI, suite)
-	} else {
-		pattern = fmt.Sprintf("%s %s/%s", cleanURI, suite, component)
+		return nil, fmt.Errorf("failed to read status file: %w", err)
 	}
+	return installedPackages, nil
+}
 
-	// Look for lines matching the pattern and check if they are installed versions
-	lines := strings.Split(string(output), "\n")
-	for i, line := range lines {
-		if strings.Contains(line, pattern) && i > 0 {
-			// Check if the previous line indicates this is the installed version
-			if strings.Contains(lines[i-1], "***") {
-				return true, nil
-			}
+// Helper function to get the packages apt marked as automatically installed, like apt-mark showauto
+func getAutoInstalledPackages() (map[string]bool, error) {
+	automatic := make(map[string]bool)
+	err := readDebStanzas(aptExtendedStatesFile, func(fields map[string]string) {
+		if fields["Auto-Installed"] == "1" {
+			automatic[fields["Package"]] = true
 		}
+	})
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read extended states: %w", err)
 	}
-
-	return false, nil
+	return automatic, nil
 }
@@ -0,0 +1,192 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: apt_repo_keys.go
+// Description: Lists the signing keys of the APT repositories with their expiry dates, and downloads the keys of
+// repositories added by AddExternalRepo again from where they came from.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+//go:build apt
+
+package api
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// repoKeyMetadata records where AddExternalRepo downloaded the key of a repository from
+type repoKeyMetadata struct {
+	Keyring   string
+	PubkeyURL string
+}
+
+// repoKeyMetadataFile returns the file next to the .sources file of a repository that records where its key came from.
+// apt skips files ending with .disabled silently, any other unknown file in sources.list.d makes apt update print a notice.
+func repoKeyMetadataFile(repo string) string {
+	return filepath.Join(aptSourcesDir, repo+".pi-apps-key.disabled")
+}
+
+// readRepoKeyMetadata reads the key metadata of a repository, ok is false if none was recorded
+func readRepoKeyMetadata(repo string) (repoKeyMetadata, bool) {
+	content, err := os.ReadFile(repoKeyMetadataFile(repo))
+	if err != nil {
+		return repoKeyMetadata{}, false
+	}
+	var meta repoKeyMetadata
+	for _, line := range strings.Split(string(content), "\n") {
+		match := deb822FieldRegex.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		switch strings.ToLower(match[1]) {
+		case "keyring":
+			meta.Keyring = strings.TrimSpace(match[2])
+		case "pubkey-url":
+			meta.PubkeyURL = strings.TrimSpace(match[2])
+		}
+	}
+	return meta, meta.Keyring != "" && meta.PubkeyURL != ""
+}
+
+// writeRepoKeyMetadata records where the key of a repository was downloaded from, readable by every user
+func writeRepoKeyMetadata(repo string, meta repoKeyMetadata) error {
+	file := repoKeyMetadataFile(repo)
+	content := fmt.Sprintf("Keyring: %s\nPubkey-URL: %s\nAdded: %s\n", meta.Keyring, meta.PubkeyURL, time.Now().UTC().Format(time.RFC3339))
+	if err := writeRootFile(file, content); err != nil {
+		return err
+	}
+	return SudoPopup("chmod", "644", file)
+}
+
+// ListExternalRepoKeys lists the signing keys of the repositories in sources.list.d with their expiry dates.
+// Keyrings a sources file refers to with signed-by are read, as are keys embedded in .sources files.
+//
+//	[]RepoKey - keys sorted by repository, in the order of their keyring
+//	error - error if there is no sources.list.d directory
+func ListExternalRepoKeys() ([]RepoKey, error) {
+	if _, err := os.Stat(aptSourcesDir); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+
+	// The repositories of the distribution are in sources.list, external ones have a file of their own
+	files, _ := filepath.Glob(filepath.Join(aptSourcesDir, "*.list"))
+	sourcesFiles, _ := filepath.Glob(filepath.Join(aptSourcesDir, "*.sources"))
+	var keys []RepoKey
+	for _, file := range append(files, sourcesFiles...) {
+		repo := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+		meta, _ := readRepoKeyMetadata(repo)
+
+		add := func(keyring string, data []byte) {
+			parsed, err := parseRepoKeys(data)
+			if err != nil {
+				Debug(fmt.Sprintf("Failed to read the keys of %s from %s: %v", repo, keyring, err))
+			}
+			for _, key := range parsed {
+				key.Repo, key.Keyring = repo, keyring
+				key.DaysLeft = repoKeyDaysLeft(key.Expires, now)
+				if keyring == meta.Keyring {
+					key.PubkeyURL = meta.PubkeyURL
+				}
+				keys = append(keys, key)
+			}
+		}
+
+		var keyrings []string
+		if strings.HasSuffix(file, ".sources") {
+			stanzas, err := readSourcesFile(file)
+			if err != nil {
+				continue
+			}
+			for _, stanza := range stanzas {
+				if stanza.signedKey != "" {
+					add(file, []byte(stanza.signedKey))
+				} else if stanza.signedBy != "" {
+					keyrings = append(keyrings, stanza.signedBy)
+				}
+			}
+		} else if listed, err := listFileKeyrings(file); err == nil {
+			keyrings = listed
+		}
+
+		slices.Sort(keyrings)
+		for _, keyring := range slices.Compact(keyrings) {
+			data, err := os.ReadFile(keyring)
+			if err != nil {
+				Debug(fmt.Sprintf("Failed to read the keyring of %s: %v", repo, err))
+				continue
+			}
+			add(keyring, data)
+		}
+	}
+
+	slices.SortStableFunc(keys, func(a, b RepoKey) int { return strings.Compare(a.Repo, b.Repo) })
+	return keys, nil
+}
+
+// RenewExternalRepoKey downloads the key of a repository added by AddExternalRepo again from the pubkeyurl it was added
+// with, and replaces its keyring. Repositories usually publish a new key at the same URL before the old one expires.
+//
+//	error - error if no key URL was recorded for the repository, the download fails or the new key expired as well
+func RenewExternalRepoKey(repo string) error {
+	meta, ok := readRepoKeyMetadata(repo)
+	if !ok {
+		return fmt.Errorf(T("no key URL was recorded for %s, only the keys of repositories added by Pi-Apps apps can be renewed"), repo)
+	}
+
+	resp, err := http.Get(meta.PubkeyURL)
+	if err != nil {
+		return fmt.Errorf(T("failed to download the key of %s: %w"), repo, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf(T("failed to download the key of %s, %s returned status %d"), repo, meta.PubkeyURL, resp.StatusCode)
+	}
+	keyData, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf(T("failed to download the key of %s: %w"), repo, err)
+	}
+	keyData, err = dearmorGPGKey(keyData)
+	if err != nil {
+		return err
+	}
+
+	keys, err := parseRepoKeys(keyData)
+	if err != nil {
+		return fmt.Errorf(T("the key downloaded from %s can't be read: %w"), meta.PubkeyURL, err)
+	}
+	now := time.Now()
+	if !slices.ContainsFunc(keys, func(key RepoKey) bool { return !key.Expired(now) }) {
+		return fmt.Errorf(T("the key at %s expired as well, the repository has to publish a new one"), meta.PubkeyURL)
+	}
+
+	if err := writeRootFile(meta.Keyring, string(keyData)); err != nil {
+		return err
+	}
+	// apt reads the keyring as an unprivileged user
+	if err := SudoPopup("chmod", "644", meta.Keyring); err != nil {
+		return err
+	}
+	StatusGreenTf("Renewed the key of %s", repo)
+	return nil
+}
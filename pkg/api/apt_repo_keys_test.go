@@ -0,0 +1,93 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+//go:build apt
+
+package api
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+)
+
+func TestListExternalRepoKeys(t *testing.T) {
+	newTestAptRoot(t)
+	now := time.Now()
+
+	keyring := filepath.Join(aptKeyringDirs[0], "ext-archive-keyring.gpg")
+	created, expires := now.AddDate(-1, 0, 0), now.AddDate(0, 0, 10).Add(time.Hour)
+	expiring := testRepoKey(t, created, expires.Sub(created))
+	if err := os.MkdirAll(filepath.Dir(keyring), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyring, serializeRepoKeys(t, expiring), 0644); err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, filepath.Join(aptSourcesDir, "ext.sources"), "Types: deb\nURIs: https://ext.example.org\nSuites: stable\nComponents: main\nSigned-By: "+keyring+"\n")
+	writeTestFile(t, repoKeyMetadataFile("ext"), "Keyring: "+keyring+"\nPubkey-URL: https://ext.example.org/key.asc\n")
+	writeTestFile(t, filepath.Join(aptSourcesDir, "old.list"), "deb [signed-by="+keyring+"] https://old.example.org stable main\n")
+
+	// add-apt-repository embeds the key of a PPA in its .sources file, with its empty lines as a dot
+	var armored bytes.Buffer
+	writer, err := armor.Encode(&armored, openpgp.PublicKeyType, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writer.Write(serializeRepoKeys(t, testRepoKey(t, now.AddDate(-1, 0, 0), 0)))
+	writer.Close()
+	var embedded []string
+	for _, line := range strings.Split(strings.TrimSpace(armored.String()), "\n") {
+		if line == "" {
+			line = "."
+		}
+		embedded = append(embedded, " "+line)
+	}
+	ppa := filepath.Join(aptSourcesDir, "ppa.sources")
+	writeTestFile(t, ppa, "Types: deb\nURIs: https://ppa.launchpadcontent.net/owner/ppa/ubuntu\nSuites: noble\nComponents: main\nSigned-By:\n"+strings.Join(embedded, "\n")+"\n")
+
+	keys, err := ListExternalRepoKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]RepoKey)
+	for _, key := range keys {
+		got[key.Repo] = key
+	}
+	if len(keys) != 3 {
+		t.Fatalf("got %d keys, want one of ext, old and ppa each: %+v", len(keys), keys)
+	}
+	if key := got["ext"]; key.PubkeyURL != "https://ext.example.org/key.asc" || key.Keyring != keyring || key.DaysLeft != 10 {
+		t.Errorf("ext key = %+v, want the recorded URL and 10 days left", key)
+	}
+	if key := got["old"]; key.PubkeyURL != "" || key.Keyring != keyring {
+		t.Errorf("old key = %+v, want its keyring without a URL", key)
+	}
+	if key := got["ppa"]; key.Keyring != ppa || !key.Expires.IsZero() {
+		t.Errorf("ppa key = %+v, want the embedded key that never expires", key)
+	}
+
+	expiringRepos := ExpiringRepoKeys(keys, now)
+	if len(expiringRepos) != 2 || expiringRepos[0].Repo != "ext" || expiringRepos[1].Repo != "old" {
+		t.Errorf("expiring = %+v, want ext and old", expiringRepos)
+	}
+}
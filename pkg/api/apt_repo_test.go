@@ -0,0 +1,174 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+//go:build apt
+
+package api
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newTestAptRoot points the apt and dpkg state at a temporary directory in which foo 1.0
+// from https://deb.example.org/debian bookworm-backports main is installed
+func newTestAptRoot(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+
+	keyringDirs, sourcesList, sourcesDir := aptKeyringDirs, aptSourcesList, aptSourcesDir
+	listsDir, statusFile, extendedStates := aptListsDir, dpkgStatusFile, aptExtendedStatesFile
+	t.Cleanup(func() {
+		aptKeyringDirs, aptSourcesList, aptSourcesDir = keyringDirs, sourcesList, sourcesDir
+		aptListsDir, dpkgStatusFile, aptExtendedStatesFile = listsDir, statusFile, extendedStates
+	})
+	aptKeyringDirs = []string{filepath.Join(root, "keyrings")}
+	aptSourcesList = filepath.Join(root, "sources.list")
+	aptSourcesDir = filepath.Join(root, "sources.list.d")
+	aptListsDir = filepath.Join(root, "lists")
+	dpkgStatusFile = filepath.Join(root, "status")
+	aptExtendedStatesFile = filepath.Join(root, "extended_states")
+
+	writeTestFile(t, aptSourcesList, "")
+	writeTestFile(t, dpkgStatusFile, "Package: foo\nStatus: install ok installed\nVersion: 1.0\n\nPackage: bar\nStatus: install ok installed\nVersion: 2.0\n")
+	writeTestFile(t, filepath.Join(aptListsDir, "deb.example.org_debian_dists_bookworm-backports_main_binary-arm64_Packages"),
+		"Package: foo\nVersion: 1.0\n\nPackage: baz\nVersion: 3.0\n")
+	writeTestFile(t, filepath.Join(aptListsDir, "deb.example.org_debian_dists_bookworm_main_binary-arm64_Packages"),
+		"Package: foo\nVersion: 0.9\n")
+	writeTestFile(t, filepath.Join(aptListsDir, "unused.example.org_dists_stable_main_binary-arm64_Packages"),
+		"Package: baz\nVersion: 3.0\n")
+	return root
+}
+
+// writeTestKeyring creates a keyring in the fixture keyring directory and returns its path
+func writeTestKeyring(t *testing.T, name string) string {
+	t.Helper()
+	path := filepath.Join(aptKeyringDirs[0], name)
+	writeTestFile(t, path, "key")
+	return path
+}
+
+func TestRemoveRepofileIfUnusedMultiStanza(t *testing.T) {
+	newTestAptRoot(t)
+	used := writeTestKeyring(t, "used.gpg")
+	unused := writeTestKeyring(t, "unused.gpg")
+
+	// foo is installed from the second suite of the first stanza, so only the second stanza is unused
+	usedStanza := "# Used\nTypes: deb\nURIs: https://mirror.example.org/debian https://deb.example.org/debian\nSuites: bookworm bookworm-backports\nComponents: main contrib\nSigned-By: " + used
+	unusedStanza := "Types: deb\nURIs: https://unused.example.org\nSuites: stable\nComponents: main\nSigned-By: " + unused
+	disabledStanza := "Types: deb\nURIs: https://disabled.example.org\nSuites: stable\nComponents: main\nEnabled: no"
+	file := filepath.Join(aptSourcesDir, "example.sources")
+	writeTestFile(t, file, usedStanza+"\n\n"+unusedStanza+"\n\n"+disabledStanza+"\n")
+
+	// Test mode only reports
+	if err := RemoveRepofileIfUnused(file, "test", ""); err != nil {
+		t.Fatal(err)
+	}
+	if content, _ := os.ReadFile(file); !strings.Contains(string(content), "unused.example.org") {
+		t.Fatal("test mode changed the sources file")
+	}
+
+	if err := RemoveRepofileIfUnused(file, "", ""); err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("the sources file was removed although a stanza is in use: %v", err)
+	}
+	if want := usedStanza + "\n\n" + disabledStanza + "\n"; string(content) != want {
+		t.Errorf("sources file =\n%s\nwant\n%s", content, want)
+	}
+	if _, err := os.Stat(unused); !os.IsNotExist(err) {
+		t.Error("the keyring of the removed stanza was kept")
+	}
+	if _, err := os.Stat(used); err != nil {
+		t.Error("the keyring of the used stanza was removed")
+	}
+}
+
+func TestRemoveRepofileIfUnusedSharedKeyring(t *testing.T) {
+	newTestAptRoot(t)
+	shared := writeTestKeyring(t, "shared.gpg")
+
+	first := filepath.Join(aptSourcesDir, "first.sources")
+	second := filepath.Join(aptSourcesDir, "second.sources")
+	writeTestFile(t, first, "Types: deb\nURIs: https://unused.example.org\nSuites: stable\nComponents: main\nSigned-By: "+shared+"\n")
+	writeTestFile(t, second, "Types: deb\nURIs: https://other.example.org\nSuites: stable\nComponents: main\nSigned-By: "+shared+"\n")
+
+	if err := RemoveRepofileIfUnused(first, "", ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(first); !os.IsNotExist(err) {
+		t.Fatal("the unused sources file was kept")
+	}
+	if _, err := os.Stat(shared); err != nil {
+		t.Fatal("the keyring was removed although another sources file uses it")
+	}
+
+	if err := RemoveRepofileIfUnused(second, "", ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(shared); !os.IsNotExist(err) {
+		t.Error("the keyring was kept after the last sources file using it was removed")
+	}
+}
+
+func TestRemoveRepofileIfUnusedListFile(t *testing.T) {
+	newTestAptRoot(t)
+	keyring := writeTestKeyring(t, "list.gpg")
+	key := filepath.Join(t.TempDir(), "extra.asc")
+	writeTestFile(t, key, "key")
+
+	used := filepath.Join(aptSourcesDir, "used.list")
+	writeTestFile(t, used, "deb [arch=arm64] https://deb.example.org/debian bookworm-backports main\n")
+	if err := RemoveRepofileIfUnused(used, "", ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(used); err != nil {
+		t.Error("a list file in use was removed")
+	}
+
+	unused := filepath.Join(aptSourcesDir, "unused.list")
+	writeTestFile(t, unused, "deb [arch=arm64 signed-by="+keyring+"] https://unused.example.org stable main\n")
+	if err := RemoveRepofileIfUnused(unused, "", key); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{unused, keyring, key} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("%s was kept", path)
+		}
+	}
+}
+
+func TestPackagesInstalledFromRepo(t *testing.T) {
+	newTestAptRoot(t)
+	writeTestFile(t, aptExtendedStatesFile, "Package: foo\nArchitecture: arm64\nAuto-Installed: 1\n")
+
+	packages, err := PackagesInstalledFromRepo("https://deb.example.org/debian/", "bookworm-backports", "main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(packages) != 1 || packages[0].Name != "foo" || packages[0].Version != "1.0" || !packages[0].Automatic {
+		t.Errorf("PackagesInstalledFromRepo = %+v, want the automatically installed foo 1.0", packages)
+	}
+
+	// foo 0.9 of bookworm is not the installed version
+	if inUse, err := AnythingInstalledFromURISuiteComponent("https://deb.example.org/debian", "bookworm", "main"); err != nil || inUse {
+		t.Errorf("AnythingInstalledFromURISuiteComponent(bookworm) = %v, %v, want false", inUse, err)
+	}
+}
@@ -0,0 +1,504 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: apt_source_health.go
+// Description: Checks every configured APT repository for being reachable and current, and temporarily
+// disables the broken ones so a single dead third-party repository doesn't fail every install.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+//go:build apt
+
+package api
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// sourceCheckTimeout limits each request, a repository slower than this would stall apt update as well
+	sourceCheckTimeout = 8 * time.Second
+	// sourceCheckWorkers is how many repositories are checked at the same time
+	sourceCheckWorkers = 8
+
+	// disabledLinePrefix comments out the lines of a .list file Pi-Apps disabled
+	disabledLinePrefix = "# pi-apps-disabled: "
+	// disabledStanzaMarker precedes the Enabled: no line Pi-Apps adds to a stanza of a .sources file
+	disabledStanzaMarker = "# pi-apps-disabled-stanza"
+	// disabledSuitePrefix precedes the Suites: line of a .sources stanza Pi-Apps removed a suite from
+	disabledSuitePrefix = "# pi-apps-disabled-suite: "
+)
+
+// aptSource is a repository of a sources file, one per URI and suite
+type aptSource struct {
+	file  string
+	uri   string
+	suite string
+}
+
+// inReleaseURL returns the URL of the InRelease file of a source. Flat repositories have
+// a suite ending with a slash and keep it next to their packages instead of under dists/.
+func (s aptSource) inReleaseURL() string {
+	base := strings.TrimSuffix(s.uri, "/")
+	if strings.HasSuffix(s.suite, "/") {
+		return base + "/" + strings.TrimPrefix(s.suite, "./") + "InRelease"
+	}
+	return base + "/dists/" + s.suite + "/InRelease"
+}
+
+// aptSourceFiles returns sources.list and the files in sources.list.d
+func aptSourceFiles() []string {
+	files := []string{"/etc/apt/sources.list"}
+	for _, pattern := range []string{"/etc/apt/sources.list.d/*.list", "/etc/apt/sources.list.d/*.sources"} {
+		matches, _ := filepath.Glob(pattern)
+		files = append(files, matches...)
+	}
+	return files
+}
+
+// listAptSources returns the enabled repositories with an http or https URI, each URI and suite once.
+// Local repositories like file: and cdrom: have nothing to check.
+func listAptSources() []aptSource {
+	var sources []aptSource
+	seen := make(map[string]bool)
+	add := func(file, uri, suite string) {
+		if !strings.HasPrefix(uri, "http://") && !strings.HasPrefix(uri, "https://") {
+			return
+		}
+		if key := uri + " " + suite; !seen[key] {
+			seen[key] = true
+			sources = append(sources, aptSource{file: file, uri: uri, suite: suite})
+		}
+	}
+
+	for _, file := range aptSourceFiles() {
+		if strings.HasSuffix(file, ".sources") {
+			stanzas, err := readSourcesFile(file)
+			if err != nil {
+				continue
+			}
+			for _, stanza := range stanzas {
+				if !stanza.enabled {
+					continue
+				}
+				for _, uri := range stanza.uris {
+					for _, suite := range stanza.suites {
+						add(file, uri, suite)
+					}
+				}
+			}
+			continue
+		}
+
+		content, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		for _, line := range strings.Split(string(content), "\n") {
+			if uri, suite, ok := parseSourcesListLine(line); ok {
+				add(file, uri, suite)
+			}
+		}
+	}
+	return sources
+}
+
+// parseSourcesListLine returns the URI and suite of a deb or deb-src line of a .list file
+func parseSourcesListLine(line string) (string, string, bool) {
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, "deb ") && !strings.HasPrefix(line, "deb-src ") {
+		return "", "", false
+	}
+	_, line, _ = strings.Cut(line, " ")
+	line = strings.TrimSpace(line)
+	// Remove apt options like [arch=amd64 signed-by=/etc/apt/keyrings/key.gpg]
+	if strings.HasPrefix(line, "[") {
+		if _, rest, found := strings.Cut(line, "]"); found {
+			line = rest
+		}
+	}
+	fields := strings.Fields(line)
+	if len(fields) < 2 {
+		return "", "", false
+	}
+	return fields[0], fields[1], true
+}
+
+// CheckAptSources checks the InRelease file of every configured repository at the same time, with a short
+// timeout each, so a broken third-party repository is found without waiting for a full apt update.
+//
+//	[]SourceHealth - one entry per URI and suite, in the order of the sources files
+//	error - error if ctx was cancelled before every repository was checked
+func CheckAptSources(ctx context.Context) ([]SourceHealth, error) {
+	sources := listAptSources()
+	results := make([]SourceHealth, len(sources))
+
+	client := &http.Client{Timeout: sourceCheckTimeout}
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range min(sourceCheckWorkers, len(sources)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = checkAptSource(ctx, client, sources[i])
+			}
+		}()
+	}
+	for i := range sources {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results, ctx.Err()
+}
+
+// checkAptSource classifies one repository. A HEAD request finds unreachable and forbidden repositories,
+// and the start of the file is read to compare its Valid-Until date.
+func checkAptSource(ctx context.Context, client *http.Client, source aptSource) SourceHealth {
+	health := SourceHealth{File: source.file, URI: source.uri, Suite: source.suite, URL: source.inReleaseURL()}
+
+	status, err := sourceRequest(ctx, client, http.MethodHead, health.URL, nil)
+	if err == nil && status == http.StatusNotFound {
+		// Some repositories only have an unsigned Release file
+		health.URL = strings.TrimSuffix(health.URL, "InRelease") + "Release"
+		status, err = sourceRequest(ctx, client, http.MethodHead, health.URL, nil)
+	}
+	switch {
+	case err != nil:
+		health.Status, health.Detail = SourceUnreachable, err.Error()
+		return health
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		health.Status, health.Detail = SourceForbidden, fmt.Sprintf("HTTP %d", status)
+		return health
+	case status == http.StatusMethodNotAllowed:
+		// A few servers don't answer HEAD, the ranged GET below checks them instead
+	case status >= 400:
+		health.Status, health.Detail = SourceUnreachable, fmt.Sprintf("HTTP %d", status)
+		return health
+	}
+
+	headWorked := status != http.StatusMethodNotAllowed
+	var head []byte
+	status, err = sourceRequest(ctx, client, http.MethodGet, health.URL, &head)
+	if err != nil || status >= 400 {
+		// Don't call the repository broken over the second request when HEAD worked
+		if headWorked {
+			health.Status = SourceOK
+		} else if err != nil {
+			health.Status, health.Detail = SourceUnreachable, err.Error()
+		} else {
+			health.Status, health.Detail = SourceUnreachable, fmt.Sprintf("HTTP %d", status)
+		}
+		return health
+	}
+	if validUntil, ok := releaseValidUntil(head); ok && validUntil.Before(time.Now()) {
+		health.Status, health.Detail = SourceExpired, fmt.Sprintf("valid until %s", validUntil.Format(time.DateOnly))
+		return health
+	}
+	health.Status = SourceOK
+	return health
+}
+
+// sourceRequest sends a request and returns the HTTP status. With a non-nil head, only the
+// first 4 KiB of the file are requested and read into it, enough for the fields of a Release file.
+func sourceRequest(ctx context.Context, client *http.Client, method, url string, head *[]byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("User-Agent", "Pi-Apps/1.0")
+	if head != nil {
+		req.Header.Set("Range", "bytes=0-4095")
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if head != nil {
+		*head, _ = io.ReadAll(io.LimitReader(resp.Body, 4096))
+	}
+	return resp.StatusCode, nil
+}
+
+// releaseValidUntil returns the Valid-Until date of a Release file, false if it has none
+func releaseValidUntil(content []byte) (time.Time, bool) {
+	scanner := bufio.NewScanner(strings.NewReader(string(content)))
+	for scanner.Scan() {
+		value, found := strings.CutPrefix(scanner.Text(), "Valid-Until:")
+		if !found {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		for _, layout := range []string{time.RFC1123, time.RFC1123Z} {
+			if t, err := time.Parse(layout, value); err == nil {
+				return t, true
+			}
+		}
+		return time.Time{}, false
+	}
+	return time.Time{}, false
+}
+
+// disabledSourcesFile records the sources Pi-Apps disabled, so RestoreAptSources can enable them again
+func disabledSourcesFile() string {
+	return filepath.Join(GetDataDir(), "disabled-apt-sources")
+}
+
+// DisableAptSource disables a broken repository until RestoreAptSources is run. Its lines in a .list
+// file are commented out, its suite is removed from a stanza of a .sources file, or the stanza gets
+// Enabled: no when it was the only suite. The file stays where it is, so apt and the app that added
+// it still find it.
+func DisableAptSource(source SourceHealth) error {
+	content, err := os.ReadFile(source.File)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", source.File, err)
+	}
+
+	var updated string
+	if strings.HasSuffix(source.File, ".sources") {
+		updated, err = disableSourcesStanza(source.File, source.URI, source.Suite)
+		if err != nil {
+			return err
+		}
+	} else {
+		lines := strings.Split(string(content), "\n")
+		for i, line := range lines {
+			if uri, suite, ok := parseSourcesListLine(line); ok && uri == source.URI && suite == source.Suite {
+				lines[i] = disabledLinePrefix + line
+			}
+		}
+		updated = strings.Join(lines, "\n")
+	}
+	if updated == string(content) {
+		return fmt.Errorf("%s %s was not found in %s", source.URI, source.Suite, source.File)
+	}
+
+	if err := writeRootFile(source.File, updated); err != nil {
+		return err
+	}
+	Status(Tf("Disabled %s %s in %s", source.URI, source.Suite, source.File))
+
+	record, err := os.OpenFile(disabledSourcesFile(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to record the disabled source: %w", err)
+	}
+	defer record.Close()
+	_, err = fmt.Fprintf(record, "%s\t%s\t%s\n", source.File, source.URI, source.Suite)
+	return err
+}
+
+// disableSourcesStanza returns a .sources file with a suite removed from the stanza holding a URI and suite,
+// so the other suites of the stanza keep working. A stanza with no other suite is disabled instead; an
+// Enabled field of its own is commented out, apt refuses a stanza with the field twice.
+func disableSourcesStanza(file, uri, suite string) (string, error) {
+	stanzas, err := readSourcesFile(file)
+	if err != nil {
+		return "", err
+	}
+	paragraphs := make([]string, 0, len(stanzas))
+	for _, stanza := range stanzas {
+		if stanza.enabled && slices.Contains(stanza.uris, uri) && slices.Contains(stanza.suites, suite) {
+			if len(stanza.suites) > 1 {
+				stanza.lines = removeStanzaSuite(stanza.lines, suite)
+			} else {
+				lines := []string{disabledStanzaMarker, "Enabled: no"}
+				for _, line := range stanza.lines {
+					if strings.HasPrefix(strings.ToLower(line), "enabled:") {
+						line = disabledLinePrefix + line
+					}
+					lines = append(lines, line)
+				}
+				stanza.lines = lines
+			}
+		}
+		paragraphs = append(paragraphs, strings.Join(stanza.lines, "\n"))
+	}
+	return strings.Join(paragraphs, "\n\n") + "\n", nil
+}
+
+// removeStanzaSuite rewrites the Suites field of a stanza without a suite, on one line preceded by a
+// disabledSuitePrefix comment naming the removed suite
+func removeStanzaSuite(lines []string, suite string) []string {
+	var updated []string
+	for i := 0; i < len(lines); i++ {
+		key, value, found := strings.Cut(lines[i], ":")
+		if !found || !strings.EqualFold(strings.TrimSpace(key), "suites") || strings.HasPrefix(key, "#") {
+			updated = append(updated, lines[i])
+			continue
+		}
+		// Continuation lines belong to the field
+		suites := strings.Fields(value)
+		for i+1 < len(lines) && (strings.HasPrefix(lines[i+1], " ") || strings.HasPrefix(lines[i+1], "\t")) {
+			i++
+			suites = append(suites, strings.Fields(lines[i])...)
+		}
+		suites = slices.DeleteFunc(suites, func(s string) bool { return s == suite })
+		updated = append(updated, disabledSuitePrefix+suite, key+": "+strings.Join(suites, " "))
+	}
+	return updated
+}
+
+// restoreSourcesContent returns the content of a sources file with everything DisableAptSource
+// disabled in it enabled again
+func restoreSourcesContent(content string) string {
+	var lines, suites []string
+	skipEnabled := false
+	for _, line := range strings.Split(content, "\n") {
+		switch {
+		case line == disabledStanzaMarker:
+			skipEnabled = true
+			continue
+		case skipEnabled && line == "Enabled: no":
+			skipEnabled = false
+			continue
+		case strings.HasPrefix(line, disabledSuitePrefix):
+			suites = append(suites, strings.TrimPrefix(line, disabledSuitePrefix))
+			continue
+		case len(suites) > 0 && strings.HasPrefix(strings.ToLower(line), "suites:"):
+			line += " " + strings.Join(suites, " ")
+			suites = nil
+		}
+		skipEnabled = false
+		lines = append(lines, strings.TrimPrefix(line, disabledLinePrefix))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// RestoreAptSources enables the repositories DisableAptSource disabled again
+//
+//	int - number of sources files that were restored
+//	error - error if a sources file could not be written
+func RestoreAptSources() (int, error) {
+	content, err := os.ReadFile(disabledSourcesFile())
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+
+	var files []string
+	for _, line := range strings.Split(strings.TrimSpace(string(content)), "\n") {
+		if file, _, _ := strings.Cut(line, "\t"); file != "" && !slices.Contains(files, file) {
+			files = append(files, file)
+		}
+	}
+
+	restored := 0
+	for _, file := range files {
+		original, err := os.ReadFile(file)
+		if errors.Is(err, os.ErrNotExist) {
+			// Removed since, e.g. by uninstalling the app that added it
+			continue
+		} else if err != nil {
+			return restored, err
+		}
+
+		if updated := restoreSourcesContent(string(original)); updated != string(original) {
+			if err := writeRootFile(file, updated); err != nil {
+				return restored, err
+			}
+			Status(Tf("Restored %s", file))
+			restored++
+		}
+	}
+	return restored, os.Remove(disabledSourcesFile())
+}
+
+// writeRootFile replaces the content of a root-owned file through SudoPopup. cp keeps the owner and
+// permissions of an existing file.
+func writeRootFile(file, content string) error {
+	temp, err := os.CreateTemp("", "pi-apps-sources-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+	if _, err := temp.WriteString(content); err != nil {
+		temp.Close()
+		return err
+	}
+	temp.Close()
+
+	if err := SudoPopup("cp", temp.Name(), file); err != nil {
+		return fmt.Errorf("failed to write %s: %w", file, err)
+	}
+	return nil
+}
+
+// offerToDisableBrokenSources runs after apt update failed. It checks the repositories, and when
+// some are broken it asks the user whether to disable them and retry.
+//
+//	true - broken sources were disabled, apt update is worth running again
+func offerToDisableBrokenSources() bool {
+	StatusT("Checking which repository made apt update fail...")
+	ctx, cancel := context.WithTimeout(context.Background(), 2*sourceCheckTimeout)
+	defer cancel()
+	results, err := CheckAptSources(ctx)
+	if err != nil {
+		Debug(fmt.Sprintf("Failed to check the apt sources: %v", err))
+		return false
+	}
+	var broken []SourceHealth
+	for _, result := range results {
+		if result.Status != SourceOK {
+			broken = append(broken, result)
+		}
+	}
+	// When no repository at all is reachable, the internet connection is the problem, not the repositories
+	if len(broken) == 0 || len(broken) == len(results) {
+		return false
+	}
+
+	var list strings.Builder
+	for _, source := range broken {
+		list.WriteString("\n  - " + source.String())
+	}
+	Warning(T("These repositories are broken:") + list.String())
+
+	// Without a display or a terminal nobody can answer, and the default answer of the CLI prompt would disable them
+	if !canUseGTK() && !stdinIsTerminal() {
+		StatusT("To disable them, fix or remove them in /etc/apt/sources.list.d and try again.")
+		return false
+	}
+	answer, err := UserInputFunc(T("These repositories are broken, so apt update failed:")+list.String()+"\n\n"+
+		T("Pi-Apps can disable them and try again. Run 'api restore_apt_sources' to enable them again later."),
+		T("Disable them and retry"), T("Cancel"))
+	if err != nil || answer != T("Disable them and retry") {
+		return false
+	}
+
+	disabled := false
+	for _, source := range broken {
+		if err := DisableAptSource(source); err != nil {
+			Warning(Tf("Failed to disable %s: %v", source.URI, err))
+			continue
+		}
+		disabled = true
+	}
+	return disabled
+}
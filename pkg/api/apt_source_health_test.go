@@ -0,0 +1,82 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+//go:build apt
+
+package api
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+const testSourcesFile = `Types: deb
+URIs: https://deb.example.org/debian
+Suites: bookworm bookworm-backports
+Components: main
+
+Types: deb
+URIs: https://other.example.org/debian
+Suites: stable
+Components: main
+`
+
+func TestDisableSourcesStanzaRemovesOnlyTheBrokenSuite(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "example.sources")
+	writeTestFile(t, file, testSourcesFile)
+
+	disabled, err := disableSourcesStanza(file, "https://deb.example.org/debian", "bookworm-backports")
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, file, disabled)
+	stanzas, err := readSourcesFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stanzas) != 2 || !stanzas[0].enabled || len(stanzas[0].suites) != 1 || stanzas[0].suites[0] != "bookworm" {
+		t.Fatalf("stanza with the broken suite removed = %+v, want only bookworm left enabled", stanzas)
+	}
+	if !stanzas[1].enabled {
+		t.Error("the other stanza was disabled")
+	}
+
+	if restored := restoreSourcesContent(disabled); restored != testSourcesFile {
+		t.Errorf("restored sources file =\n%s\nwant\n%s", restored, testSourcesFile)
+	}
+}
+
+func TestDisableSourcesStanzaDisablesTheLastSuite(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "example.sources")
+	writeTestFile(t, file, testSourcesFile)
+
+	disabled, err := disableSourcesStanza(file, "https://other.example.org/debian", "stable")
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, file, disabled)
+	stanzas, err := readSourcesFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stanzas) != 2 || !stanzas[0].enabled || stanzas[1].enabled {
+		t.Fatalf("stanzas after disabling their only suite = %+v, want the second one disabled", stanzas)
+	}
+
+	if restored := restoreSourcesContent(disabled); restored != testSourcesFile {
+		t.Errorf("restored sources file =\n%s\nwant\n%s", restored, testSourcesFile)
+	}
+}
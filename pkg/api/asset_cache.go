@@ -0,0 +1,528 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: asset_cache.go
+// Description: Keeps the files app scripts download in a cache shared by all apps, so a file several apps need is downloaded once.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// assetCacheSizeSetting is the setting with the most megabytes the asset cache may use, 0 turns it off
+	assetCacheSizeSetting = "Download cache size"
+	// installCachedAssetPrefix starts the lines of an install manifest recording the cached assets an app
+	// downloaded, so they are never evicted while the app is installed
+	installCachedAssetPrefix = "# cached asset: "
+)
+
+// DownloadCache is whether a download goes through the shared asset cache
+type DownloadCache int
+
+const (
+	// CacheAppDownloads caches the downloads of app scripts, the ones made with $app set, and no others
+	CacheAppDownloads DownloadCache = iota
+	// CacheAlways caches the download wherever it is made from
+	CacheAlways
+	// CacheNever always downloads the file
+	CacheNever
+)
+
+// DownloadOptions are the options of DownloadFileWithOptions, the zero value caches the downloads of app scripts
+type DownloadOptions struct {
+	Cache DownloadCache
+	// SHA256 is the sha256 the file must have, a file with another one is an error. The cache finds a file with a
+	// known sha256 even when another app downloaded it from another URL.
+	SHA256 string
+}
+
+// cached reports whether a download with these options goes through the asset cache
+func (o DownloadOptions) cached() bool {
+	switch o.Cache {
+	case CacheNever:
+		return false
+	case CacheAppDownloads:
+		if os.Getenv("app") == "" {
+			return false
+		}
+	}
+	return GetPiAppsDir() != "" && AssetCacheLimit() > 0
+}
+
+// AssetCacheStats describes the asset cache, see AssetCacheStatistics
+type AssetCacheStats struct {
+	Assets          int   `json:"assets"`
+	Bytes           int64 `json:"bytes"`
+	Referenced      int   `json:"referenced"`
+	ReferencedBytes int64 `json:"referenced_bytes"`
+	Limit           int64 `json:"limit"`
+}
+
+// assetCacheDir returns the folder of the asset cache. Assets are named by their sha256, the urls folder maps the
+// sha256 of a URL to the asset it served and the locks folder has a lock file for every asset being downloaded.
+func assetCacheDir() string {
+	return filepath.Join(GetDataDir(), "cache", "assets")
+}
+
+// AssetCacheLimit returns the most bytes the asset cache may use, read from the "Download cache size" setting
+// in megabytes. 0 turns the cache off.
+func AssetCacheLimit() int64 {
+	directory := GetPiAppsDir()
+	if directory == "" {
+		return 0
+	}
+	megabytes, err := strconv.ParseInt(strings.TrimSpace(ReadSettingIn(directory, assetCacheSizeSetting)), 10, 64)
+	if err != nil || megabytes <= 0 {
+		return 0
+	}
+	return megabytes * 1024 * 1024
+}
+
+// urlKey returns the name a URL is stored under in the urls folder of the cache
+func urlKey(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return hex.EncodeToString(sum[:])
+}
+
+// lockAssetKey takes the lock of an asset, by its sha256 or the sha256 of its URL, and returns the function
+// releasing it. flock locks of separate opens conflict in one process too, so parallel downloads of the same asset
+// wait for each other whether they run in goroutines or in other processes.
+func lockAssetKey(key string) (func(), error) {
+	dir := filepath.Join(assetCacheDir(), "locks")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create the asset cache: %w", err)
+	}
+	f, err := os.OpenFile(filepath.Join(dir, key), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the lock of the asset cache: %w", err)
+	}
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to lock the asset cache: %w", err)
+	}
+	return func() {
+		syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+		f.Close()
+	}, nil
+}
+
+// cachedURLAsset returns the sha256 of the asset a URL served the last time it was cached and the validators the
+// server sent with it, "" if it wasn't cached. The urls file has the sha256 on its first line, then the ETag and
+// Last-Modified headers as "etag <value>" and "last-modified <value>" lines.
+func cachedURLAsset(url string) (string, downloadValidators) {
+	content, err := os.ReadFile(filepath.Join(assetCacheDir(), "urls", urlKey(url)))
+	if err != nil {
+		return "", downloadValidators{}
+	}
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	sum := strings.TrimSpace(lines[0])
+	if !sha256Regex.MatchString(sum) {
+		return "", downloadValidators{}
+	}
+	var validators downloadValidators
+	for _, line := range lines[1:] {
+		name, value, _ := strings.Cut(line, " ")
+		switch name {
+		case "etag":
+			validators.ETag = value
+		case "last-modified":
+			validators.LastModified = value
+		}
+	}
+	return sum, validators
+}
+
+// recordURLAsset records the asset a URL served and the validators the server sent with it, see cachedURLAsset
+func recordURLAsset(url, sum string, validators downloadValidators) error {
+	content := sum + "\n"
+	if validators.ETag != "" {
+		content += "etag " + validators.ETag + "\n"
+	}
+	if validators.LastModified != "" {
+		content += "last-modified " + validators.LastModified + "\n"
+	}
+	return WriteFileAtomic(filepath.Join(assetCacheDir(), "urls", urlKey(url)), []byte(content), 0644)
+}
+
+// cachedAsset returns the path and size of the cached asset with a sha256, checking its content first. An asset
+// that was changed on disk is removed from the cache.
+func cachedAsset(sum string) (string, int64, bool) {
+	if !sha256Regex.MatchString(sum) {
+		return "", 0, false
+	}
+	path := filepath.Join(assetCacheDir(), sum)
+	size, actual := hashFile(path)
+	if actual == "" {
+		return "", 0, false
+	}
+	if actual != sum {
+		Debug(fmt.Sprintf("The cached asset %s changed, removing it", sum))
+		os.Remove(path)
+		return "", 0, false
+	}
+	// Eviction removes the assets used least recently first
+	now := time.Now()
+	os.Chtimes(path, now, now)
+	return path, size, true
+}
+
+// placeAsset copies a cached asset to destination, sharing its blocks on filesystems that can clone files. Scripts
+// change the files they download, like patching them, which must change neither the cache nor the files of other
+// apps, so the asset is never linked. The file appears at destination at once, replacing what was there.
+func placeAsset(path, destination string) error {
+	input, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+	output, err := os.CreateTemp(filepath.Dir(destination), "."+filepath.Base(destination)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	if unix.IoctlFileClone(int(output.Fd()), int(input.Fd())) != nil {
+		_, err = io.Copy(output, input)
+	}
+	if closeErr := output.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		// Temporary files are private, the copy gets the mode a downloaded file has
+		err = os.Chmod(output.Name(), 0644)
+	}
+	if err == nil {
+		err = os.Rename(output.Name(), destination)
+	}
+	if err != nil {
+		os.Remove(output.Name())
+		return err
+	}
+	return nil
+}
+
+// downloadCached downloads a URL to destination through the asset cache: an asset that is cached already is
+// placed at destination without downloading it, anything else is downloaded into the cache first. The asset is
+// recorded in the install manifest of the app downloading it.
+func downloadCached(url, destination string, options DownloadOptions) error {
+	key := options.SHA256
+	if key == "" {
+		key = urlKey(url)
+	}
+	unlock, err := lockAssetKey(key)
+	if err != nil {
+		return err
+	}
+	sum, stored, err := fetchCachedAsset(url, destination, options)
+	unlock()
+	if err != nil {
+		return err
+	}
+
+	if app := os.Getenv("app"); app != "" {
+		if err := recordCachedAsset(app, sum); err != nil {
+			Debug(fmt.Sprintf("Failed to record the cached asset in the install manifest of %s: %v", app, err))
+		}
+	}
+	if stored {
+		if _, _, err := PruneAssetCache(AssetCacheLimit()); err != nil {
+			Debug(fmt.Sprintf("Failed to prune the asset cache: %v", err))
+		}
+	}
+	StatusGreenT("Download completed: %s", destination)
+	return nil
+}
+
+// fetchCachedAsset places the cached asset of a URL at destination, downloading it into the cache if it isn't
+// cached yet. It is called with the lock of the asset held.
+//
+//	string - sha256 of the asset
+//	bool - true if the asset was downloaded and stored in the cache, false if it was cached already
+//	error - error if the asset can't be downloaded or placed at destination
+func fetchCachedAsset(url, destination string, options DownloadOptions) (string, bool, error) {
+	sum := options.SHA256
+	var validators downloadValidators
+	if sum == "" {
+		sum, validators = cachedURLAsset(url)
+	}
+	path, cachedSize, cached := cachedAsset(sum)
+	// A URL can serve another file any time, like the latest release. Without a sha256 to check, the asset is only
+	// used once the server says the file didn't change, which it can't without validators.
+	revalidate := options.SHA256 == ""
+	if revalidate && validators == (downloadValidators{}) {
+		cached = false
+	}
+	if !cached {
+		validators = downloadValidators{}
+	}
+	if cached && !revalidate {
+		if done, err := useCachedAsset(url, destination, path, cachedSize, sum); done || err != nil {
+			return sum, false, err
+		}
+	}
+
+	dir := assetCacheDir()
+	if err := os.MkdirAll(filepath.Join(dir, "urls"), 0755); err != nil {
+		return "", false, fmt.Errorf("failed to create the asset cache: %w", err)
+	}
+	tmp, err := os.CreateTemp(dir, ".download-*")
+	if err != nil {
+		return "", false, fmt.Errorf("failed to create file: %w", err)
+	}
+	size, hash, err := fetchDownload(url, filepath.Base(destination), &validators, func() (io.Writer, error) { return tmp, nil })
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if errors.Is(err, errNotModified) {
+		os.Remove(tmp.Name())
+		if done, err := useCachedAsset(url, destination, path, cachedSize, sum); done || err != nil {
+			return sum, false, err
+		}
+		// The asset was evicted meanwhile, without it the URL is downloaded without condition
+		return fetchCachedAsset(url, destination, options)
+	}
+	if err == nil {
+		sum = hex.EncodeToString(hash)
+		err = checkDownloadSum(url, sum, options.SHA256)
+	}
+	if err == nil {
+		// Temporary files are private, the asset gets the mode a downloaded file has
+		err = os.Chmod(tmp.Name(), 0644)
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), filepath.Join(dir, sum))
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return "", false, err
+	}
+	if err := recordURLAsset(url, sum, validators); err != nil {
+		Debug(fmt.Sprintf("Failed to record the asset of %s in the cache: %v", url, err))
+	}
+	if err := placeAsset(filepath.Join(dir, sum), destination); err != nil {
+		return "", false, err
+	}
+	recordDownload(url, destination, size, hash)
+	return sum, true, nil
+}
+
+// useCachedAsset places a cached asset at destination and records the cache hit, returning false if the asset was
+// evicted meanwhile and has to be downloaded again
+func useCachedAsset(url, destination, path string, size int64, sum string) (bool, error) {
+	err := placeAsset(path, destination)
+	if os.IsNotExist(err) {
+		Debug(fmt.Sprintf("The cached asset %s is gone: %v", sum, err))
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	StatusT("Using the cached download of %s", url)
+	recordCachedDownload(url, destination, size, sum)
+	return true, nil
+}
+
+// checkDownloadSum checks the sha256 of a download against the expected one, if there is one
+func checkDownloadSum(url, sum, expected string) error {
+	if expected != "" && sum != expected {
+		return fmt.Errorf("the download of %s doesn't have the expected sha256: expected %s, got %s", url, expected, sum)
+	}
+	return nil
+}
+
+// recordCachedAsset adds a cached asset to an app's install manifest
+func recordCachedAsset(app, sum string) error {
+	lines, err := readInstallManifest(app)
+	if err != nil {
+		return err
+	}
+	line := installCachedAssetPrefix + sum
+	if slices.Contains(lines, line) {
+		return nil
+	}
+	return writeInstallManifest(app, append(lines, line))
+}
+
+// readCachedAssetLines returns the lines of an app's install manifest recording the cached assets it downloaded
+func readCachedAssetLines(app string) ([]string, error) {
+	lines, err := readInstallManifest(app)
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(lines, func(line string) bool { return !strings.HasPrefix(line, installCachedAssetPrefix) }), nil
+}
+
+// referencedCachedAssets returns the sha256 of the cached assets the install manifests of installed apps record
+func referencedCachedAssets() (map[string]bool, error) {
+	entries, err := os.ReadDir(filepath.Join(GetDataDir(), "install-files"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	referenced := make(map[string]bool)
+	for _, entry := range entries {
+		if entry.IsDir() || ValidateAppName(entry.Name()) != nil {
+			continue
+		}
+		lines, err := readCachedAssetLines(entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		for _, line := range lines {
+			referenced[strings.TrimPrefix(line, installCachedAssetPrefix)] = true
+		}
+	}
+	return referenced, nil
+}
+
+// cachedAssetFile is an asset in the cache folder
+type cachedAssetFile struct {
+	sum     string
+	size    int64
+	modTime time.Time
+}
+
+// listCachedAssets returns the assets in the cache, the least recently used first
+func listCachedAssets() ([]cachedAssetFile, error) {
+	entries, err := os.ReadDir(assetCacheDir())
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var assets []cachedAssetFile
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() || !sha256Regex.MatchString(entry.Name()) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		assets = append(assets, cachedAssetFile{sum: entry.Name(), size: info.Size(), modTime: info.ModTime()})
+	}
+	slices.SortFunc(assets, func(a, b cachedAssetFile) int { return a.modTime.Compare(b.modTime) })
+	return assets, nil
+}
+
+// AssetCacheStatistics returns how many assets the cache has, their size, how many of them installed apps use and
+// the "Download cache size" limit
+//
+//	AssetCacheStats - the statistics of the cache
+//	error - error if PI_APPS_DIR environment variable is not set or the cache can't be read
+func AssetCacheStatistics() (AssetCacheStats, error) {
+	if GetPiAppsDir() == "" {
+		return AssetCacheStats{}, fmt.Errorf("PI_APPS_DIR environment variable not set")
+	}
+	assets, err := listCachedAssets()
+	if err != nil {
+		return AssetCacheStats{}, err
+	}
+	referenced, err := referencedCachedAssets()
+	if err != nil {
+		return AssetCacheStats{}, err
+	}
+	stats := AssetCacheStats{Limit: AssetCacheLimit()}
+	for _, asset := range assets {
+		stats.Assets++
+		stats.Bytes += asset.size
+		if referenced[asset.sum] {
+			stats.Referenced++
+			stats.ReferencedBytes += asset.size
+		}
+	}
+	return stats, nil
+}
+
+// PruneAssetCache removes the least recently used assets until the cache fits in limit bytes. Assets the install
+// manifest of an installed app records are never removed, so the cache can stay above the limit.
+//
+//	int - number of assets removed
+//	int64 - bytes freed
+//	error - error if PI_APPS_DIR environment variable is not set or the cache can't be read
+func PruneAssetCache(limit int64) (int, int64, error) {
+	if GetPiAppsDir() == "" {
+		return 0, 0, fmt.Errorf("PI_APPS_DIR environment variable not set")
+	}
+	assets, err := listCachedAssets()
+	if err != nil {
+		return 0, 0, err
+	}
+	var total int64
+	for _, asset := range assets {
+		total += asset.size
+	}
+	if total <= limit {
+		return 0, 0, nil
+	}
+	referenced, err := referencedCachedAssets()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var removed int
+	var freed int64
+	var errs []error
+	for _, asset := range assets {
+		if total <= limit {
+			break
+		}
+		if referenced[asset.sum] {
+			continue
+		}
+		if err := os.Remove(filepath.Join(assetCacheDir(), asset.sum)); err != nil && !os.IsNotExist(err) {
+			errs = append(errs, err)
+			continue
+		}
+		removed++
+		freed += asset.size
+		total -= asset.size
+	}
+	if removed > 0 {
+		pruneAssetURLs()
+	}
+	return removed, freed, errors.Join(errs...)
+}
+
+// pruneAssetURLs forgets the URLs whose asset is no longer cached
+func pruneAssetURLs() {
+	dir := filepath.Join(assetCacheDir(), "urls")
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		content, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			continue
+		}
+		if !FileExists(filepath.Join(assetCacheDir(), strings.TrimSpace(string(content)))) {
+			os.Remove(filepath.Join(dir, entry.Name()))
+		}
+	}
+}
@@ -0,0 +1,314 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newAssetServer serves content at every path and counts the downloads, see serveAsset
+func newAssetServer(t *testing.T, content string, delay time.Duration) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var downloads atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(delay)
+		serveAsset(w, r, content, &downloads)
+	}))
+	t.Cleanup(server.Close)
+	return server, &downloads
+}
+
+// serveAsset serves content with its sha256 as ETag, answering the requests for the same ETag with 304. Only the
+// requests getting the content count as downloads.
+func serveAsset(w http.ResponseWriter, r *http.Request, content string, downloads *atomic.Int32) {
+	etag := `"` + testSHA256(content) + `"`
+	w.Header().Set("ETag", etag)
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	downloads.Add(1)
+	w.Write([]byte(content))
+}
+
+func testSHA256(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestDownloadFileSharesCachedAssets(t *testing.T) {
+	newTestPiAppsDir(t, "Zoom", "Teams")
+	content := "codec library"
+	sum := testSHA256(content)
+	server, downloads := newAssetServer(t, content, 0)
+	tmp := t.TempDir()
+
+	t.Setenv("app", "Zoom")
+	if err := DownloadFile(server.URL+"/codec.deb", filepath.Join(tmp, "zoom", "codec.deb")); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("app", "Teams")
+	if err := DownloadFile(server.URL+"/codec.deb", filepath.Join(tmp, "teams", "codec.deb")); err != nil {
+		t.Fatal(err)
+	}
+	// A known sha256 finds the asset whatever URL it was downloaded from
+	if err := DownloadFileWithOptions(server.URL+"/mirror/codec.deb", filepath.Join(tmp, "teams", "mirror.deb"), DownloadOptions{SHA256: sum}); err != nil {
+		t.Fatal(err)
+	}
+	if got := downloads.Load(); got != 1 {
+		t.Errorf("the asset was downloaded %d times, want once", got)
+	}
+	for _, name := range []string{"zoom/codec.deb", "teams/codec.deb", "teams/mirror.deb"} {
+		if data, err := os.ReadFile(filepath.Join(tmp, name)); err != nil || string(data) != content {
+			t.Errorf("%s = %q, %v, want %q", name, data, err, content)
+		}
+	}
+
+	for _, app := range []string{"Zoom", "Teams"} {
+		manifest, err := ReadInstallManifest(app)
+		if err != nil || !slices.Equal(manifest.CachedAssets, []string{sum}) {
+			t.Errorf("cached assets of %s = %q, %v, want %s", app, manifest.CachedAssets, err, sum)
+		}
+	}
+	FlushDownloadLedger()
+	entries, err := DownloadLedger(DownloadLedgerFilter{})
+	if err != nil || len(entries) != 3 {
+		t.Fatalf("download ledger = %+v, %v, want 3 entries", entries, err)
+	}
+	if entries[0].CacheHit || !entries[1].CacheHit || entries[1].App != "Teams" || entries[1].SHA256 != sum || entries[1].Bytes != int64(len(content)) {
+		t.Errorf("download ledger = %+v, want a download then cache hits", entries)
+	}
+
+	// Downloads outside of app scripts aren't cached
+	t.Setenv("app", "")
+	if err := DownloadFile(server.URL+"/codec.deb", filepath.Join(tmp, "codec.deb")); err != nil {
+		t.Fatal(err)
+	}
+	if got := downloads.Load(); got != 2 {
+		t.Errorf("a download outside of an app script made %d downloads in total, want 2", got)
+	}
+}
+
+func TestDownloadFileRevalidatesURLs(t *testing.T) {
+	newTestPiAppsDir(t)
+	var mutex sync.Mutex
+	content := "release 1.0"
+	var downloads atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		current := content
+		mutex.Unlock()
+		if r.URL.Path == "/unversioned" {
+			// Without ETag or Last-Modified the file can't be revalidated
+			downloads.Add(1)
+			w.Write([]byte(current))
+			return
+		}
+		serveAsset(w, r, current, &downloads)
+	}))
+	t.Cleanup(server.Close)
+	tmp := t.TempDir()
+	options := DownloadOptions{Cache: CacheAlways}
+
+	download := func(path, name, want string) {
+		t.Helper()
+		if err := DownloadFileWithOptions(server.URL+path, filepath.Join(tmp, name), options); err != nil {
+			t.Fatal(err)
+		}
+		if data, _ := os.ReadFile(filepath.Join(tmp, name)); string(data) != want {
+			t.Errorf("%s = %q, want %q", name, data, want)
+		}
+	}
+	download("/releases/latest", "first", "release 1.0")
+	download("/releases/latest", "second", "release 1.0")
+	if got := downloads.Load(); got != 1 {
+		t.Errorf("an unchanged URL was downloaded %d times, want once", got)
+	}
+
+	// The latest release changed, the cached asset of the URL is stale
+	mutex.Lock()
+	content = "release 2.0"
+	mutex.Unlock()
+	download("/releases/latest", "third", "release 2.0")
+	download("/releases/latest", "fourth", "release 2.0")
+	if got := downloads.Load(); got != 2 {
+		t.Errorf("the URL was downloaded %d times in total, want twice", got)
+	}
+
+	download("/unversioned", "fifth", "release 2.0")
+	download("/unversioned", "sixth", "release 2.0")
+	if got := downloads.Load(); got != 4 {
+		t.Errorf("the URL without validators was downloaded %d times, want twice", got-2)
+	}
+}
+
+func TestDownloadFileKeepsTheCacheApart(t *testing.T) {
+	newTestPiAppsDir(t)
+	content := "original"
+	server, downloads := newAssetServer(t, content, 0)
+	options := DownloadOptions{Cache: CacheAlways, SHA256: testSHA256(content)}
+	first := filepath.Join(t.TempDir(), "first")
+	if err := DownloadFileWithOptions(server.URL+"/file", first, options); err != nil {
+		t.Fatal(err)
+	}
+
+	// A script patching its download changes neither the cache nor the downloads of other apps
+	second := filepath.Join(t.TempDir(), "second")
+	if err := DownloadFileWithOptions(server.URL+"/file", second, options); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(first, []byte("patched by the app"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	third := filepath.Join(t.TempDir(), "third")
+	if err := DownloadFileWithOptions(server.URL+"/file", third, options); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{second, third} {
+		if data, _ := os.ReadFile(path); string(data) != content {
+			t.Errorf("%s = %q, want %q", filepath.Base(path), data, content)
+		}
+		if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0644 {
+			t.Errorf("%s has mode %v, %v, want 0644", filepath.Base(path), info.Mode().Perm(), err)
+		}
+	}
+	if got := downloads.Load(); got != 1 {
+		t.Errorf("the asset was downloaded %d times, want once", got)
+	}
+}
+
+func TestDownloadFileReplacesChangedAsset(t *testing.T) {
+	directory := newTestPiAppsDir(t)
+	content := "original"
+	sum := testSHA256(content)
+	server, downloads := newAssetServer(t, content, 0)
+	options := DownloadOptions{Cache: CacheAlways, SHA256: sum}
+	if err := DownloadFileWithOptions(server.URL+"/file", filepath.Join(t.TempDir(), "first"), options); err != nil {
+		t.Fatal(err)
+	}
+
+	// A cached asset that changed on disk is downloaded again
+	if err := os.WriteFile(filepath.Join(directory, "data", "cache", "assets", sum), []byte("changed"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	second := filepath.Join(t.TempDir(), "second")
+	if err := DownloadFileWithOptions(server.URL+"/file", second, options); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(second); string(data) != content {
+		t.Errorf("download after the asset changed = %q, want %q", data, content)
+	}
+	if got := downloads.Load(); got != 2 {
+		t.Errorf("the changed asset was downloaded %d times in total, want 2", got)
+	}
+}
+
+func TestDownloadFileSingleFlight(t *testing.T) {
+	newTestPiAppsDir(t)
+	server, downloads := newAssetServer(t, "large archive", 100*time.Millisecond)
+	tmp := t.TempDir()
+
+	var wg sync.WaitGroup
+	errs := make([]error, 8)
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = DownloadFileWithOptions(server.URL+"/archive.tar.xz", filepath.Join(tmp, fmt.Sprint(i)), DownloadOptions{Cache: CacheAlways})
+		}()
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("download %d: %v", i, err)
+		}
+	}
+	if got := downloads.Load(); got != 1 {
+		t.Errorf("parallel downloads of one asset downloaded it %d times, want once", got)
+	}
+}
+
+func TestDownloadFileChecksSHA256(t *testing.T) {
+	newTestPiAppsDir(t)
+	server, _ := newAssetServer(t, "tampered", 0)
+	destination := filepath.Join(t.TempDir(), "file")
+	expected := testSHA256("expected")
+
+	for _, cache := range []DownloadCache{CacheAlways, CacheNever} {
+		if err := DownloadFileWithOptions(server.URL+"/file", destination, DownloadOptions{Cache: cache, SHA256: expected}); err == nil {
+			t.Errorf("download with cache %d accepted a file with another sha256", cache)
+		}
+		if _, err := os.Stat(destination); !os.IsNotExist(err) {
+			t.Errorf("download with cache %d left the rejected file behind", cache)
+		}
+	}
+	if stats, err := AssetCacheStatistics(); err != nil || stats.Assets != 0 {
+		t.Errorf("asset cache = %+v, %v, want the rejected file left out", stats, err)
+	}
+	if err := DownloadFileWithOptions(server.URL+"/file", destination, DownloadOptions{SHA256: "abc"}); err == nil {
+		t.Error("DownloadFileWithOptions accepted an invalid sha256")
+	}
+}
+
+func TestPruneAssetCache(t *testing.T) {
+	directory := newTestPiAppsDir(t, "Zoom")
+	cacheDir := filepath.Join(directory, "data", "cache", "assets")
+	now := time.Now()
+	var sums []string
+	for i, content := range []string{"oldest asset", "referenced asset", "recent asset"} {
+		sum := testSHA256(content)
+		path := filepath.Join(cacheDir, sum)
+		writeTestFile(t, path, content)
+		modTime := now.Add(time.Duration(i-3) * time.Hour)
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+		sums = append(sums, sum)
+	}
+	writeTestFile(t, filepath.Join(directory, "data", "install-files", "Zoom"), "/opt/zoom/zoom\n"+installCachedAssetPrefix+sums[1]+"\n")
+
+	stats, err := AssetCacheStatistics()
+	if err != nil || stats.Assets != 3 || stats.Referenced != 1 || stats.ReferencedBytes != int64(len("referenced asset")) {
+		t.Errorf("statistics = %+v, %v", stats, err)
+	}
+
+	// The least recently used asset goes first, the one of an installed app is kept
+	removed, freed, err := PruneAssetCache(int64(len("referenced asset") + len("recent asset")))
+	if err != nil || removed != 1 || freed != int64(len("oldest asset")) {
+		t.Errorf("PruneAssetCache = %d, %d, %v, want the oldest asset removed", removed, freed, err)
+	}
+	if _, err := os.Stat(filepath.Join(cacheDir, sums[2])); err != nil {
+		t.Errorf("the recent asset was removed: %v", err)
+	}
+	if removed, _, err := PruneAssetCache(0); err != nil || removed != 1 {
+		t.Errorf("PruneAssetCache(0) removed %d, %v, want the unreferenced asset", removed, err)
+	}
+	if _, err := os.Stat(filepath.Join(cacheDir, sums[1])); err != nil {
+		t.Errorf("the asset of an installed app was removed: %v", err)
+	}
+}
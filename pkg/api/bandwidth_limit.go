@@ -0,0 +1,159 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: bandwidth_limit.go
+// Description: Limits the download speed of Pi-Apps and apt to the "Download speed limit" setting, for metered or shared connections.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package api
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// downloadLimitSetting is the setting with the download speed limit in KB/s, 0 for unlimited
+	downloadLimitSetting = "Download speed limit"
+	// DownloadLimitEnvVar overrides the "Download speed limit" setting in KB/s. The --limit-rate option of the download
+	// commands sets it, so the scripts and apt commands they start are limited too.
+	DownloadLimitEnvVar = "PI_APPS_DOWNLOAD_LIMIT"
+)
+
+// DownloadLimit returns the download speed limit in bytes per second, 0 for unlimited. PI_APPS_DOWNLOAD_LIMIT wins
+// over the "Download speed limit" setting, which is read again for every download, so a changed setting applies to
+// the next one. git clones are not limited, git has no option for a transfer rate.
+func DownloadLimit() int64 {
+	value, ok := os.LookupEnv(DownloadLimitEnvVar)
+	if !ok {
+		directory := GetPiAppsDir()
+		if directory == "" {
+			return 0
+		}
+		value = ReadSettingIn(directory, downloadLimitSetting)
+	}
+	kilobytes, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+	if err != nil || kilobytes <= 0 {
+		return 0
+	}
+	return kilobytes * 1024
+}
+
+// ParseRateLimit parses a rate like the --limit-rate option of wget: bytes per second, or kilobytes and megabytes
+// per second with a k or m suffix, like 200k. It returns the rate in bytes per second.
+func ParseRateLimit(rate string) (int64, error) {
+	rate = strings.TrimSpace(rate)
+	multiplier := int64(1)
+	switch {
+	case strings.HasSuffix(rate, "k") || strings.HasSuffix(rate, "K"):
+		multiplier, rate = 1024, rate[:len(rate)-1]
+	case strings.HasSuffix(rate, "m") || strings.HasSuffix(rate, "M"):
+		multiplier, rate = 1024*1024, rate[:len(rate)-1]
+	}
+	value, err := strconv.ParseFloat(rate, 64)
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("invalid rate %q, use bytes per second or a k or m suffix like 200k", rate)
+	}
+	return int64(value * float64(multiplier)), nil
+}
+
+// SetDownloadLimitOverride limits the downloads of this process and the ones it starts to a rate like the --limit-rate
+// option of wget, see ParseRateLimit. A rate of 0 lifts the limit of the setting.
+func SetDownloadLimitOverride(rate string) error {
+	limit, err := ParseRateLimit(rate)
+	if err != nil {
+		return err
+	}
+	// The variable is in KB/s like the setting, a limit under 1 KB/s is rounded up to it
+	return os.Setenv(DownloadLimitEnvVar, strconv.FormatInt((limit+1023)/1024, 10))
+}
+
+// aptDownloadLimitFlags returns the apt options limiting its downloads to the download speed limit, none without one
+func aptDownloadLimitFlags() []string {
+	limit := DownloadLimit()
+	if limit <= 0 {
+		return nil
+	}
+	kilobytes := strconv.FormatInt(limit/1024, 10)
+	return []string{"-o", "Acquire::http::Dl-Limit=" + kilobytes, "-o", "Acquire::https::Dl-Limit=" + kilobytes}
+}
+
+// formatRateLimit returns a rate limit for the progress of downloads, like "512.0 KiB/s"
+func formatRateLimit(limit int64) string {
+	return FormatSize(uint64(limit)) + "/s"
+}
+
+// tokenBucket lets through rate bytes per second on average, and up to burst bytes at once after a pause
+type tokenBucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+
+	// now and sleep are the clock, tests replace them
+	now   func() time.Time
+	sleep func(time.Duration)
+}
+
+// newTokenBucket returns a full bucket for rate bytes per second. The burst is a tenth of a second of the rate,
+// at least 1 KiB, so the speed stays even while small reads still get through.
+func newTokenBucket(rate int64) *tokenBucket {
+	b := &tokenBucket{rate: float64(rate), burst: max(float64(rate)/10, 1024), now: time.Now, sleep: time.Sleep}
+	b.tokens = b.burst
+	b.last = b.now()
+	return b
+}
+
+// take removes n bytes from the bucket, sleeping until the rate allows them once the bucket runs dry
+func (b *tokenBucket) take(n int) {
+	now := b.now()
+	b.tokens = min(b.tokens+now.Sub(b.last).Seconds()*b.rate, b.burst)
+	b.last = now
+	b.tokens -= float64(n)
+	if b.tokens < 0 {
+		b.sleep(time.Duration(-b.tokens / b.rate * float64(time.Second)))
+	}
+}
+
+// rateLimitedReader reads from a reader no faster than its token bucket allows
+type rateLimitedReader struct {
+	reader io.Reader
+	bucket *tokenBucket
+}
+
+// newRateLimitedReader limits reading from reader to limit bytes per second. A limit of 0 returns reader itself.
+func newRateLimitedReader(reader io.Reader, limit int64) io.Reader {
+	if limit <= 0 {
+		return reader
+	}
+	return &rateLimitedReader{reader: reader, bucket: newTokenBucket(limit)}
+}
+
+// Read implements io.Reader, reading at most a burst at a time
+func (r *rateLimitedReader) Read(p []byte) (int, error) {
+	if len(p) > int(r.bucket.burst) {
+		p = p[:int(r.bucket.burst)]
+	}
+	n, err := r.reader.Read(p)
+	if n > 0 {
+		r.bucket.take(n)
+	}
+	return n, err
+}
@@ -0,0 +1,120 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+// fakeClock is a clock for token buckets that only moves when they sleep
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time        { return c.now }
+func (c *fakeClock) Sleep(d time.Duration) { c.now = c.now.Add(d) }
+
+// endlessReader returns as many bytes as asked for, like a fast network
+type endlessReader struct{}
+
+func (endlessReader) Read(p []byte) (int, error) { return len(p), nil }
+
+func TestRateLimitedReaderThroughput(t *testing.T) {
+	for _, limit := range []int64{64 * 1024, 512 * 1024, 5 * 1024 * 1024} {
+		clock := &fakeClock{now: time.Unix(0, 0)}
+		reader := newRateLimitedReader(endlessReader{}, limit).(*rateLimitedReader)
+		reader.bucket.now, reader.bucket.sleep = clock.Now, clock.Sleep
+		reader.bucket.last = clock.now
+
+		// Ten seconds worth of data, read with a buffer larger than the burst like io.Copy does
+		total := limit * 10
+		start := clock.now
+		copied, err := io.CopyBuffer(io.Discard, io.LimitReader(reader, total), make([]byte, 256*1024))
+		if err != nil || copied != total {
+			t.Fatalf("limit %d: copied %d bytes, %v", limit, copied, err)
+		}
+		throughput := float64(total) / clock.now.Sub(start).Seconds()
+		if math.Abs(throughput-float64(limit))/float64(limit) > 0.05 {
+			t.Errorf("limit %d B/s: measured %.0f B/s, want within 5%%", limit, throughput)
+		}
+	}
+}
+
+func TestRateLimitedReaderUnlimited(t *testing.T) {
+	reader := endlessReader{}
+	if newRateLimitedReader(reader, 0) != io.Reader(reader) {
+		t.Error("newRateLimitedReader with no limit wrapped the reader")
+	}
+}
+
+func TestDownloadLimit(t *testing.T) {
+	directory := newTestPiAppsDir(t)
+	t.Setenv(DownloadLimitEnvVar, "")
+	if limit := DownloadLimit(); limit != 0 {
+		t.Errorf("DownloadLimit with an empty override = %d, want unlimited", limit)
+	}
+
+	// Without the override the setting counts, it is read again for every download
+	os.Unsetenv(DownloadLimitEnvVar)
+	if limit := DownloadLimit(); limit != 0 {
+		t.Errorf("DownloadLimit without the setting = %d, want unlimited", limit)
+	}
+	writeTestFile(t, filepath.Join(directory, "data", "settings", downloadLimitSetting), "512")
+	if limit := DownloadLimit(); limit != 512*1024 {
+		t.Errorf("DownloadLimit with the setting at 512 = %d, want %d", limit, 512*1024)
+	}
+	want := []string{"-o", "Acquire::http::Dl-Limit=512", "-o", "Acquire::https::Dl-Limit=512"}
+	if flags := aptDownloadLimitFlags(); !slices.Equal(flags, want) {
+		t.Errorf("aptDownloadLimitFlags = %q, want %q", flags, want)
+	}
+
+	// --limit-rate overrides it, 0 lifts the limit
+	if err := SetDownloadLimitOverride("200k"); err != nil {
+		t.Fatal(err)
+	}
+	if limit := DownloadLimit(); limit != 200*1024 {
+		t.Errorf("DownloadLimit with --limit-rate=200k = %d, want %d", limit, 200*1024)
+	}
+	if err := SetDownloadLimitOverride("0"); err != nil {
+		t.Fatal(err)
+	}
+	if limit := DownloadLimit(); limit != 0 || aptDownloadLimitFlags() != nil {
+		t.Errorf("DownloadLimit with --limit-rate=0 = %d, want unlimited", limit)
+	}
+	if err := SetDownloadLimitOverride("fast"); err == nil {
+		t.Error("SetDownloadLimitOverride accepted an invalid rate")
+	}
+}
+
+func TestParseRateLimit(t *testing.T) {
+	for rate, want := range map[string]int64{"1000": 1000, "200k": 200 * 1024, "1.5M": 1536 * 1024, "0": 0} {
+		if got, err := ParseRateLimit(rate); err != nil || got != want {
+			t.Errorf("ParseRateLimit(%q) = %d, %v, want %d", rate, got, err, want)
+		}
+	}
+	for _, rate := range []string{"", "k", "-5", "fast"} {
+		if _, err := ParseRateLimit(rate); err == nil {
+			t.Errorf("ParseRateLimit(%q) accepted an invalid rate", rate)
+		}
+	}
+}
@@ -114,6 +114,8 @@ exit $?
 
 	// Copy working directory
 	wrapperCmd.Dir = cmd.Dir
+	// Don't wait for commands the script left running in the background forever
+	wrapperCmd.WaitDelay = cmd.WaitDelay
 
 	// Ensure we preserve all environment variables from the original command
 	if cmd.Env != nil {
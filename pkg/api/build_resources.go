@@ -0,0 +1,203 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: build_resources.go
+// Description: Advises install scripts how many parallel compile jobs the free memory and swap allow,
+// and points at the job count when a compile was killed for lack of memory.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package api
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// DefaultPerJobMemMB is the memory a compile job is expected to need when the app doesn't say.
+// It matches the thresholds of the original nproc function: 2000MB free for 4 jobs.
+const DefaultPerJobMemMB = 500
+
+// compileJobsFormat is written to the install log by nproc, applyCompileJobsHint looks for it.
+// It is not translated so the diagnosis finds it in every language.
+const compileJobsFormat = "Compiling with %d parallel jobs, %dMB of memory per job"
+
+var compileJobsRegex = regexp.MustCompile(`Compiling with (\d+) parallel jobs, (\d+)MB of memory per job`)
+
+// compileOOMRegex matches compilers and build tools that were killed for lack of memory
+var compileOOMRegex = regexp.MustCompile(`(?i)(Killed signal terminated program|signal: 9, SIGKILL|LLVM ERROR: out of memory|virtual memory exhausted|killed by the OOM killer|out of memory allocating)`)
+
+// BuildAdvice is what BuildResourceAdvice recommends for a compile, with the figures it is based on
+type BuildAdvice struct {
+	Jobs        int `json:"jobs"`           // recommended number of parallel jobs, at least 1
+	CPUs        int `json:"cpus"`           // number of processor threads
+	PerJobMemMB int `json:"per_job_mem_mb"` // memory counted for each job
+
+	MemTotalMB     int  `json:"mem_total_mb"`
+	MemAvailableMB int  `json:"mem_available_mb"`
+	SwapTotalMB    int  `json:"swap_total_mb"`
+	SwapFreeMB     int  `json:"swap_free_mb"`
+	Zram           bool `json:"zram"` // a zram swap device is active
+
+	// SuggestMoreRAM is set when memory keeps the job count below the CPU count and zram isn't set up yet,
+	// the More RAM app would let the compile use more threads
+	SuggestMoreRAM bool `json:"suggest_more_ram"`
+}
+
+// BuildResourceAdvice recommends how many parallel jobs a compile can run with the free memory and swap.
+// Free swap counts for half, since a compile that swaps heavily is slow but doesn't get killed.
+//
+//	perJobMemMB - memory each job needs in MB, DefaultPerJobMemMB when 0 or less
+//	BuildAdvice - the recommendation and the figures from /proc/meminfo
+//	error - error if /proc/meminfo can't be read
+func BuildResourceAdvice(perJobMemMB int) (BuildAdvice, error) {
+	if perJobMemMB <= 0 {
+		perJobMemMB = DefaultPerJobMemMB
+	}
+	advice := BuildAdvice{CPUs: runtime.NumCPU(), PerJobMemMB: perJobMemMB}
+
+	meminfo, err := readMeminfoMB()
+	if err != nil {
+		advice.Jobs = 1
+		return advice, fmt.Errorf("failed to read memory information: %w", err)
+	}
+	advice.MemTotalMB = meminfo["MemTotal"]
+	advice.MemAvailableMB = meminfo["MemAvailable"]
+	advice.SwapTotalMB = meminfo["SwapTotal"]
+	advice.SwapFreeMB = meminfo["SwapFree"]
+	advice.Zram = zramSwapActive()
+
+	// CI runners have plenty of memory for their CPUs
+	if os.Getenv("GITHUB_ACTIONS") == "true" {
+		advice.Jobs = advice.CPUs
+		return advice, nil
+	}
+
+	budget := advice.MemAvailableMB + advice.SwapFreeMB/2
+	advice.Jobs = max(1, min(advice.CPUs, budget/perJobMemMB))
+	advice.SuggestMoreRAM = advice.Jobs < advice.CPUs && !advice.Zram
+	return advice, nil
+}
+
+// Nproc returns the optimal number of processor threads to use based on available memory
+// It mimics the behavior of the original bash nproc function
+func Nproc() (int, error) {
+	return NprocPerJob(DefaultPerJobMemMB)
+}
+
+// NprocPerJob returns the number of parallel jobs for a compile whose jobs need perJobMemMB each.
+// It warns when memory limits the job count and writes the count to the log for the error diagnosis.
+func NprocPerJob(perJobMemMB int) (int, error) {
+	advice, err := BuildResourceAdvice(perJobMemMB)
+	if err != nil {
+		return advice.Jobs, err
+	}
+
+	if advice.Jobs < advice.CPUs {
+		WarningTf("Your system has %dMB of available RAM and %dMB of free swap, so this will compile with only %d of %d threads.",
+			advice.MemAvailableMB, advice.SwapFreeMB, advice.Jobs, advice.CPUs)
+		if advice.SuggestMoreRAM {
+			StatusT("Installing the More RAM app from the Tools category would allow more threads.")
+		}
+	}
+	Status(fmt.Sprintf(compileJobsFormat, advice.Jobs, advice.PerJobMemMB))
+	return advice.Jobs, nil
+}
+
+// AppPerJobMemMB returns the memory per compile job an app declares with per_job_mem in its requirements file,
+// DefaultPerJobMemMB if it declares none
+func AppPerJobMemMB(app string) int {
+	requirements, err := ReadAppRequirements(app)
+	if err != nil {
+		Debug(fmt.Sprintf("Failed to read requirements of %s: %v", app, err))
+	}
+	if requirements.PerJobMemMB > 0 {
+		return requirements.PerJobMemMB
+	}
+	return DefaultPerJobMemMB
+}
+
+// readMeminfoMB reads /proc/meminfo, converting the kB values to MB
+func readMeminfoMB() (map[string]int, error) {
+	file, err := os.Open("/proc/meminfo")
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	meminfo := make(map[string]int)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(value)
+		if len(fields) == 0 {
+			continue
+		}
+		kB, err := strconv.Atoi(fields[0])
+		if err != nil {
+			continue
+		}
+		meminfo[key] = kB / 1024
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if _, ok := meminfo["MemAvailable"]; !ok {
+		return nil, fmt.Errorf("couldn't find MemAvailable in /proc/meminfo")
+	}
+	return meminfo, nil
+}
+
+// zramSwapActive reports whether a zram device is used as swap
+func zramSwapActive() bool {
+	content, err := os.ReadFile("/proc/swaps")
+	if err != nil {
+		return false
+	}
+	return strings.Contains(string(content), "/dev/zram")
+}
+
+// applyCompileJobsHint adds the parallel job count nproc chose to the diagnosis of a compile that ran out of memory
+func applyCompileJobsHint(diagnosis *ErrorDiagnosis, log string) {
+	if !compileOOMRegex.MatchString(log) {
+		return
+	}
+	matches := compileJobsRegex.FindAllStringSubmatch(log, -1)
+	if len(matches) == 0 {
+		return
+	}
+	last := matches[len(matches)-1]
+	jobs, _ := strconv.Atoi(last[1])
+	perJob, _ := strconv.Atoi(last[2])
+
+	caption := Tf("The compile that ran out of memory used %d parallel jobs, counting %dMB of memory per job.", jobs, perJob)
+	if jobs > 1 {
+		caption += "\n" + T("App maintainers can raise per_job_mem in the requirements file of the app so fewer jobs are started.")
+	} else {
+		caption += "\n" + T("Even a single job didn't fit, so more swap is needed. Try installing the More RAM app from the Tools category.")
+	}
+	diagnosis.Captions = append(diagnosis.Captions, caption)
+	if diagnosis.ErrorType == "" || diagnosis.ErrorType == "unknown" {
+		diagnosis.ErrorType = "system"
+	}
+}
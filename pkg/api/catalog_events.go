@@ -0,0 +1,314 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: catalog_events.go
+// Description: Provides the Prometheus metrics and the Server-Sent Events stream of the catalog server, for home automation.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package api
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+const (
+	// catalogEventPoll is how often GET /events checks the status file of the manage daemon
+	catalogEventPoll = time.Second
+	// catalogEventKeepAlive is how often GET /events sends a comment so proxies keep an idle stream open
+	catalogEventKeepAlive = 15 * time.Second
+)
+
+// DaemonQueueItem is an operation in the queue of the manage daemon
+type DaemonQueueItem struct {
+	ID       int    `json:"id,omitempty"`
+	Action   string `json:"action"`
+	App      string `json:"app"`
+	Status   string `json:"status"` // waiting, in-progress, success, failure, diagnosed, interrupted, skipped or superseded
+	ExitCode int    `json:"exit_code,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// Pending reports whether the operation still has to run or is running
+func (item DaemonQueueItem) Pending() bool {
+	return item.Status == "waiting" || item.Status == "in-progress"
+}
+
+// ManageRun is the result of the last manage run, from its run report
+type ManageRun struct {
+	Finished *time.Time        `json:"finished,omitempty"` // unset while the run goes on
+	Success  bool              `json:"success"`
+	ExitCode int               `json:"exit_code"`
+	Queue    []DaemonQueueItem `json:"queue"`
+}
+
+// daemonStatusFile returns the JSON-lines status file of the manage daemon, see gui.QueueStatusJSONFile
+func daemonStatusFile() string {
+	return filepath.Join(GetDataDir(), "manage-daemon", "status.jsonl")
+}
+
+// manageReportFile returns the run report of manage, the same file as gui.RunReportFile
+func manageReportFile() string {
+	if path := os.Getenv("PI_APPS_REPORT_FILE"); path != "" {
+		return path
+	}
+	return filepath.Join(GetDataDir(), "manage-report.json")
+}
+
+// ReadDaemonQueue reads the queue of the manage daemon from its status file, nil if no daemon runs
+func ReadDaemonQueue() ([]DaemonQueueItem, error) {
+	file, err := os.Open(daemonStatusFile())
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	queue := []DaemonQueueItem{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var record struct {
+			Type string `json:"type"`
+			DaemonQueueItem
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil || record.Type != "item" {
+			// The header and records of newer protocol versions are skipped
+			continue
+		}
+		queue = append(queue, record.DaemonQueueItem)
+	}
+	return queue, scanner.Err()
+}
+
+// ReadLastManageRun reads the result of the last manage run, nil if manage never ran
+func ReadLastManageRun() (*ManageRun, error) {
+	data, err := os.ReadFile(manageReportFile())
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var run ManageRun
+	if err := json.Unmarshal(data, &run); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", manageReportFile(), err)
+	}
+	return &run, nil
+}
+
+// handleMetrics exposes the app statuses and the manage daemon in the Prometheus text exposition format
+func (s *CatalogServer) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	statuses, err := GetAllAppStatuses()
+	if err != nil {
+		writeCatalogError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	apps := make([]string, 0, len(statuses))
+	for app := range statuses {
+		apps = append(apps, app)
+	}
+	sort.Strings(apps)
+
+	pendingOperations := 0
+	daemonRunning := localQueueRunning(GetPiAppsDir())
+	if daemonRunning {
+		queue, err := ReadDaemonQueue()
+		if err != nil {
+			Debug(fmt.Sprintf("Failed to read the manage daemon queue: %v", err))
+		}
+		for _, item := range queue {
+			if item.Pending() {
+				pendingOperations++
+			}
+		}
+	}
+	lastRun, err := ReadLastManageRun()
+	if err != nil {
+		Debug(fmt.Sprintf("Failed to read the last manage run: %v", err))
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+
+	fmt.Fprintln(w, "# HELP pi_apps_app_installed Whether an app is installed.")
+	fmt.Fprintln(w, "# TYPE pi_apps_app_installed gauge")
+	for _, app := range apps {
+		installed := 0
+		if statuses[app] == AppStateInstalled {
+			installed = 1
+		}
+		fmt.Fprintf(w, "pi_apps_app_installed{app=%q} %d\n", app, installed)
+	}
+
+	fmt.Fprintln(w, "# HELP pi_apps_updatable_apps Apps the updater last found updates for.")
+	fmt.Fprintln(w, "# TYPE pi_apps_updatable_apps gauge")
+	fmt.Fprintf(w, "pi_apps_updatable_apps %d\n", len(PendingAppUpdates()))
+
+	fmt.Fprintln(w, "# HELP pi_apps_daemon_running Whether the manage daemon runs.")
+	fmt.Fprintln(w, "# TYPE pi_apps_daemon_running gauge")
+	fmt.Fprintf(w, "pi_apps_daemon_running %d\n", boolMetric(daemonRunning))
+
+	fmt.Fprintln(w, "# HELP pi_apps_daemon_queue_length Operations of the manage daemon that wait or run.")
+	fmt.Fprintln(w, "# TYPE pi_apps_daemon_queue_length gauge")
+	fmt.Fprintf(w, "pi_apps_daemon_queue_length %d\n", pendingOperations)
+
+	if lastRun == nil || lastRun.Finished == nil {
+		return
+	}
+	fmt.Fprintln(w, "# HELP pi_apps_last_run_success Whether every operation of the last manage run succeeded.")
+	fmt.Fprintln(w, "# TYPE pi_apps_last_run_success gauge")
+	fmt.Fprintf(w, "pi_apps_last_run_success %d\n", boolMetric(lastRun.Success))
+
+	fmt.Fprintln(w, "# HELP pi_apps_last_run_exit_code Exit code of the last manage run.")
+	fmt.Fprintln(w, "# TYPE pi_apps_last_run_exit_code gauge")
+	fmt.Fprintf(w, "pi_apps_last_run_exit_code %d\n", lastRun.ExitCode)
+
+	fmt.Fprintln(w, "# HELP pi_apps_last_run_timestamp_seconds When the last manage run finished.")
+	fmt.Fprintln(w, "# TYPE pi_apps_last_run_timestamp_seconds gauge")
+	fmt.Fprintf(w, "pi_apps_last_run_timestamp_seconds %d\n", lastRun.Finished.Unix())
+}
+
+// boolMetric returns 1 for true and 0 for false
+func boolMetric(value bool) int {
+	if value {
+		return 1
+	}
+	return 0
+}
+
+// handleEvents streams the status transitions of the manage daemon queue as Server-Sent Events
+//
+// The stream starts with a "queue" event holding the current queue, an empty list if no daemon runs.
+// Every operation that changes its status sends a "status" event, and a "finished" event with the
+// run report follows when the daemon exits.
+func (s *CatalogServer) handleEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeCatalogError(w, http.StatusInternalServerError, "streaming is not supported")
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	queue, err := ReadDaemonQueue()
+	if err != nil {
+		Debug(fmt.Sprintf("Failed to read the manage daemon queue: %v", err))
+	}
+	if queue == nil {
+		queue = []DaemonQueueItem{}
+	}
+	if err := writeEvent(w, flusher, "queue", queue); err != nil {
+		return
+	}
+	last := queue
+
+	poll := time.NewTicker(s.eventPoll)
+	defer poll.Stop()
+	keepAlive := time.NewTicker(catalogEventKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-poll.C:
+			queue, err := ReadDaemonQueue()
+			if err != nil {
+				Debug(fmt.Sprintf("Failed to read the manage daemon queue: %v", err))
+				continue
+			}
+			if queue == nil && len(last) > 0 {
+				// The daemon removes its status file when it exits, the run report has the final statuses
+				if err := s.writeFinishedEvents(w, flusher, last); err != nil {
+					return
+				}
+			}
+			for _, item := range queue {
+				if i := queueItemIndex(last, item.ID); i >= 0 && last[i].Status == item.Status {
+					continue
+				}
+				if err := writeEvent(w, flusher, "status", item); err != nil {
+					return
+				}
+			}
+			last = queue
+		}
+	}
+}
+
+// writeFinishedEvents sends the final status of the operations a daemon left pending, and the "finished" event
+func (s *CatalogServer) writeFinishedEvents(w http.ResponseWriter, flusher http.Flusher, last []DaemonQueueItem) error {
+	run, err := ReadLastManageRun()
+	if err != nil {
+		Debug(fmt.Sprintf("Failed to read the last manage run: %v", err))
+	}
+	if run == nil {
+		return writeEvent(w, flusher, "finished", nil)
+	}
+	used := make([]bool, len(run.Queue))
+	for _, item := range last {
+		if !item.Pending() {
+			continue
+		}
+		for i, final := range run.Queue {
+			if used[i] || final.Action != item.Action || final.App != item.App {
+				continue
+			}
+			used[i] = true
+			if final.Status != item.Status {
+				final.ID = item.ID
+				if err := writeEvent(w, flusher, "status", final); err != nil {
+					return err
+				}
+			}
+			break
+		}
+	}
+	return writeEvent(w, flusher, "finished", run)
+}
+
+// queueItemIndex returns the index of the queue item with an ID, -1 if there is none
+func queueItemIndex(queue []DaemonQueueItem, id int) int {
+	for i, item := range queue {
+		if item.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// writeEvent sends v as the JSON data of a Server-Sent Event
+func writeEvent(w http.ResponseWriter, flusher http.Flusher, event string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data); err != nil {
+		return err
+	}
+	flusher.Flush()
+	return nil
+}
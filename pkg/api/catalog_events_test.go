@@ -0,0 +1,221 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// catalogEvent is a Server-Sent Event read from GET /events
+type catalogEvent struct {
+	name string
+	data string
+}
+
+// writeDaemonStatus writes the JSON-lines status file of the manage daemon like gui.WriteQueueStatus
+func writeDaemonStatus(t *testing.T, directory string, items ...DaemonQueueItem) {
+	t.Helper()
+	lines := []string{`{"type":"header","protocol":2}`}
+	for _, item := range items {
+		data, err := json.Marshal(struct {
+			Type string `json:"type"`
+			DaemonQueueItem
+		}{"item", item})
+		if err != nil {
+			t.Fatal(err)
+		}
+		lines = append(lines, string(data))
+	}
+	writeTestFile(t, filepath.Join(directory, "data", "manage-daemon", "status.jsonl"), strings.Join(lines, "\n")+"\n")
+}
+
+// readEvents sends the events of a stream to a channel until the stream ends
+func readEvents(body io.Reader) <-chan catalogEvent {
+	events := make(chan catalogEvent)
+	go func() {
+		defer close(events)
+		var event catalogEvent
+		scanner := bufio.NewScanner(body)
+		for scanner.Scan() {
+			line := scanner.Text()
+			switch {
+			case strings.HasPrefix(line, "event: "):
+				event.name = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				event.data = strings.TrimPrefix(line, "data: ")
+			case line == "" && event.name != "":
+				events <- event
+				event = catalogEvent{}
+			}
+		}
+	}()
+	return events
+}
+
+// nextEvent returns the next event of a stream, failing the test if none arrives in time
+func nextEvent(t *testing.T, events <-chan catalogEvent) catalogEvent {
+	t.Helper()
+	select {
+	case event, ok := <-events:
+		if !ok {
+			t.Fatal("event stream ended")
+		}
+		return event
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for an event")
+	}
+	return catalogEvent{}
+}
+
+func TestCatalogServerEvents(t *testing.T) {
+	directory := newTestPiAppsDir(t, "Alpha", "Beta")
+	t.Setenv("PI_APPS_REPORT_FILE", "")
+	writeDaemonStatus(t, directory,
+		DaemonQueueItem{ID: 1, Action: "install", App: "Alpha", Status: "waiting"},
+		DaemonQueueItem{ID: 2, Action: "install", App: "Beta", Status: "waiting"})
+
+	catalog, err := newCatalogServer(false, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	catalog.eventPoll = 10 * time.Millisecond
+	server := httptest.NewServer(catalog)
+	t.Cleanup(server.Close)
+
+	resp, err := http.Get(server.URL + "/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if got := resp.Header.Get("Content-Type"); got != "text/event-stream" {
+		t.Fatalf("Content-Type = %q, want text/event-stream", got)
+	}
+	events := readEvents(resp.Body)
+
+	var queue []DaemonQueueItem
+	event := nextEvent(t, events)
+	if err := json.Unmarshal([]byte(event.data), &queue); event.name != "queue" || err != nil || len(queue) != 2 {
+		t.Fatalf("first event = %+v, want the queue of 2 operations", event)
+	}
+
+	// Simulate the daemon running the queue, every status change is one event
+	wantStatus := func(id int, status string) {
+		t.Helper()
+		event := nextEvent(t, events)
+		var item DaemonQueueItem
+		if err := json.Unmarshal([]byte(event.data), &item); event.name != "status" || err != nil {
+			t.Fatalf("event = %+v, want a status event", event)
+		}
+		if item.ID != id || item.Status != status {
+			t.Fatalf("status event = %+v, want operation %d %s", item, id, status)
+		}
+	}
+	writeDaemonStatus(t, directory,
+		DaemonQueueItem{ID: 1, Action: "install", App: "Alpha", Status: "in-progress"},
+		DaemonQueueItem{ID: 2, Action: "install", App: "Beta", Status: "waiting"})
+	wantStatus(1, "in-progress")
+
+	writeDaemonStatus(t, directory,
+		DaemonQueueItem{ID: 1, Action: "install", App: "Alpha", Status: "success"},
+		DaemonQueueItem{ID: 2, Action: "install", App: "Beta", Status: "in-progress"})
+	wantStatus(1, "success")
+	wantStatus(2, "in-progress")
+
+	// The daemon exits before the stream saw the last operation finish, the run report has its result
+	writeTestFile(t, filepath.Join(directory, "data", "manage-report.json"), `{"schema_version":1,"finished":"2026-10-15T12:00:00Z","success":false,"exit_code":1,"queue":[
+		{"action":"install","app":"Alpha","status":"success","exit_code":0},
+		{"action":"install","app":"Beta","status":"failure","exit_code":1,"error":"install script failed"}]}`)
+	if err := os.Remove(filepath.Join(directory, "data", "manage-daemon", "status.jsonl")); err != nil {
+		t.Fatal(err)
+	}
+	wantStatus(2, "failure")
+
+	event = nextEvent(t, events)
+	var run ManageRun
+	if err := json.Unmarshal([]byte(event.data), &run); event.name != "finished" || err != nil {
+		t.Fatalf("event = %+v, want the finished event", event)
+	}
+	if run.Success || run.ExitCode != 1 {
+		t.Errorf("finished event = %+v, want a failed run with exit code 1", run)
+	}
+}
+
+func TestCatalogServerMetrics(t *testing.T) {
+	server := newTestCatalog(t, false, "")
+	directory := GetPiAppsDir()
+	t.Setenv("PI_APPS_REPORT_FILE", "")
+	writeTestFile(t, filepath.Join(directory, "data", "update-status", "updatable-apps"), "Beta\nSecret\n")
+	writeDaemonStatus(t, directory,
+		DaemonQueueItem{ID: 1, Action: "install", App: "Beta", Status: "success"},
+		DaemonQueueItem{ID: 2, Action: "uninstall", App: "Alpha Tool", Status: "in-progress"},
+		DaemonQueueItem{ID: 3, Action: "install", App: "Secret", Status: "waiting"})
+	// The test process stands in for the running daemon
+	if err := WritePIDFile(filepath.Join(directory, "data", "manage-daemon", "pid"), os.Getpid()); err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, filepath.Join(directory, "data", "manage-report.json"), `{"finished":"2026-10-15T12:00:00Z","success":true,"exit_code":0,"queue":[]}`)
+
+	resp, err := http.Get(server.URL + "/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("GET /metrics = %d: %s", resp.StatusCode, body)
+	}
+	for _, want := range []string{
+		`pi_apps_app_installed{app="Alpha Tool"} 1`,
+		`pi_apps_app_installed{app="Beta"} 0`,
+		"pi_apps_updatable_apps 2",
+		"pi_apps_daemon_running 1",
+		"pi_apps_daemon_queue_length 2",
+		"pi_apps_last_run_success 1",
+		"pi_apps_last_run_timestamp_seconds 1792065600",
+	} {
+		if !strings.Contains(string(body), want+"\n") {
+			t.Errorf("GET /metrics misses %q:\n%s", want, body)
+		}
+	}
+}
+
+func TestCatalogServerReadOnlyEndpoints(t *testing.T) {
+	server := newTestCatalog(t, true, "0123456789abcdef0123")
+	for _, path := range []string{"/metrics", "/events"} {
+		request, _ := http.NewRequest(http.MethodPost, server.URL+path, nil)
+		resp, err := http.DefaultClient.Do(request)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusMethodNotAllowed {
+			t.Errorf("POST %s = %d, want %d", path, resp.StatusCode, http.StatusMethodNotAllowed)
+		}
+	}
+}
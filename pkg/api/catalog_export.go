@@ -0,0 +1,273 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: catalog_export.go
+// Description: Exports the metadata of every app as versioned JSON for the Pi-Apps website, optionally with the icons.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package api
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// CatalogExportSchema is the version of the JSON written by ExportCatalog. It is bumped when a field is removed
+// or changes its meaning, readers can ignore fields added without a bump.
+const CatalogExportSchema = 1
+
+// catalogIconsSuffix is added to the name of the export, without .json, for the folder of the copied icons
+const catalogIconsSuffix = "-icons"
+
+// CatalogExport is the app catalog as published for the website. It holds nothing that depends on the machine
+// it was exported on, like the app statuses or the export time, so exports of the same checkout are identical.
+type CatalogExport struct {
+	Schema         int                `json:"schema"`          // CatalogExportSchema
+	CatalogVersion int                `json:"catalog_version"` // schema version of the app folders, see ReadCatalogVersion
+	Apps           []CatalogExportApp `json:"apps"`            // sorted by name
+}
+
+// CatalogExportApp is an app of a CatalogExport
+type CatalogExportApp struct {
+	Name               string                              `json:"name"`
+	ShortDescription   string                              `json:"short_description"` // first line of the description
+	LongDescription    string                              `json:"long_description"`
+	Translations       map[string]CatalogExportTranslation `json:"translations,omitempty"` // by locale, like fr or pt_BR
+	Website            string                              `json:"website"`                // upstream website, "" if the app has none
+	Credits            string                              `json:"credits"`
+	Category           string                              `json:"category"`
+	Type               string                              `json:"type"`          // "standard", "package" or "flatpak_package", "" for deprecated apps
+	Architectures      []string                            `json:"architectures"` // "32" and/or "64" from the install scripts, empty for package apps
+	Icons              []CatalogExportIcon                 `json:"icons"`         // sorted by size
+	Deprecated         bool                                `json:"deprecated"`
+	DeprecatedArch     string                              `json:"deprecated_arch,omitempty"` // architecture the app was removed on, "" for all of them
+	DeprecationMessage string                              `json:"deprecation_message,omitempty"`
+}
+
+// CatalogExportTranslation is the description of an app translated to a locale
+type CatalogExportTranslation struct {
+	ShortDescription string `json:"short_description"` // first line of the description
+	LongDescription  string `json:"long_description"`
+}
+
+// CatalogExportIcon is an icon file of an app
+type CatalogExportIcon struct {
+	Size   int    `json:"size"`           // in pixels, e.g. 24 or 64
+	Path   string `json:"path"`           // relative to the Pi-Apps directory, with forward slashes
+	SHA256 string `json:"sha256"`         // of the icon file
+	File   string `json:"file,omitempty"` // copy published next to the export, relative to it, see WriteCatalogExport
+}
+
+// ExportCatalog returns the metadata of every app in a Pi-Apps directory, including the deprecated apps whose
+// folders were removed. The directory may also be a checkout of the Pi-Apps repository, only its apps and etc
+// folders and data/deprecated-apps are read. The categories are the ones of the catalog, without the overrides
+// of this machine.
+func ExportCatalog(directory string) (*CatalogExport, error) {
+	if !DirExists(filepath.Join(directory, "apps")) {
+		return nil, fmt.Errorf("%s has no apps folder", directory)
+	}
+	catalogVersion, err := ReadCatalogVersion(directory)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the catalog version: %w", err)
+	}
+	categories := &CategoryData{GlobalCategories: make(map[string]string), LocalCategories: make(map[string]string)}
+	parseCategoryAssignments(embeddedGlobalCategories, categories.GlobalCategories)
+	all, err := readAllAppMetadata(directory, categories)
+	if err != nil {
+		return nil, err
+	}
+
+	export := &CatalogExport{Schema: CatalogExportSchema, CatalogVersion: catalogVersion, Apps: []CatalogExportApp{}}
+	exported := make(map[string]bool)
+	for _, metadata := range all {
+		app := CatalogExportApp{
+			Name:             metadata.Name,
+			ShortDescription: metadata.ShortDescription,
+			LongDescription:  metadata.LongDescription,
+			Website:          metadata.Website,
+			Credits:          metadata.Credits,
+			Category:         metadata.Category,
+			Type:             metadata.Type,
+			Architectures:    metadata.Architectures,
+		}
+		if app.Architectures == nil {
+			app.Architectures = []string{}
+		}
+		for locale, description := range metadata.Translations {
+			if app.Translations == nil {
+				app.Translations = make(map[string]CatalogExportTranslation)
+			}
+			app.Translations[locale] = CatalogExportTranslation{ShortDescription: shortDescription(description), LongDescription: description}
+		}
+		if app.Icons, err = catalogExportIcons(directory, metadata.IconPaths); err != nil {
+			return nil, err
+		}
+		readDeprecation(directory, &app)
+		export.Apps = append(export.Apps, app)
+		exported[app.Name] = true
+	}
+
+	// Deprecated apps only have the icons and uninstall script that were kept when their folder was removed
+	entries, err := os.ReadDir(filepath.Join(directory, "data", "deprecated-apps"))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() || exported[entry.Name()] || ValidateAppName(entry.Name()) != nil {
+			continue
+		}
+		app := CatalogExportApp{Name: entry.Name(), Architectures: []string{}}
+		if !readDeprecation(directory, &app) {
+			continue
+		}
+		iconPaths := make(map[int]string)
+		for _, size := range []int{24, 64} {
+			icon := filepath.Join(directory, "data", "deprecated-apps", app.Name, fmt.Sprintf("icon-%d.png", size))
+			if FileExists(icon) {
+				iconPaths[size] = icon
+			}
+		}
+		if app.Icons, err = catalogExportIcons(directory, iconPaths); err != nil {
+			return nil, err
+		}
+		export.Apps = append(export.Apps, app)
+	}
+
+	slices.SortFunc(export.Apps, func(a, b CatalogExportApp) int { return strings.Compare(a.Name, b.Name) })
+	return export, nil
+}
+
+// catalogExportIcons returns the icons of an app by size, with their paths relative to the Pi-Apps directory
+func catalogExportIcons(directory string, iconPaths map[int]string) ([]CatalogExportIcon, error) {
+	icons := []CatalogExportIcon{}
+	for size, path := range iconPaths {
+		relative, err := filepath.Rel(directory, path)
+		if err != nil {
+			return nil, err
+		}
+		sum, err := fileSHA256(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to hash icon %s: %w", path, err)
+		}
+		icons = append(icons, CatalogExportIcon{Size: size, Path: filepath.ToSlash(relative), SHA256: hex.EncodeToString(sum[:])})
+	}
+	slices.SortFunc(icons, func(a, b CatalogExportIcon) int { return a.Size - b.Size })
+	return icons, nil
+}
+
+// readDeprecation fills in the deprecation of an app from the metadata RemoveDeprecatedApp stored.
+// It returns whether the app is deprecated.
+func readDeprecation(directory string, app *CatalogExportApp) bool {
+	data, err := os.ReadFile(filepath.Join(directory, "data", "deprecated-apps", app.Name, "metadata"))
+	if err != nil {
+		return false
+	}
+	app.Deprecated = true
+	for _, line := range strings.Split(string(data), "\n") {
+		if arch, ok := strings.CutPrefix(line, "removalArch="); ok {
+			app.DeprecatedArch = arch
+		} else if message, ok := strings.CutPrefix(line, "message="); ok {
+			app.DeprecationMessage = message
+		}
+	}
+	return true
+}
+
+// ValidateCatalogExport checks that an export follows CatalogExportSchema: every app has a valid unique name,
+// the apps and their icons are sorted and every icon path stays inside the Pi-Apps directory
+func ValidateCatalogExport(export *CatalogExport) error {
+	if export.Schema != CatalogExportSchema {
+		return fmt.Errorf("unsupported catalog export schema %d, expected %d", export.Schema, CatalogExportSchema)
+	}
+	if export.CatalogVersion < 1 {
+		return fmt.Errorf("invalid catalog version %d", export.CatalogVersion)
+	}
+	if export.Apps == nil {
+		return fmt.Errorf("the export has no apps list")
+	}
+	for i, app := range export.Apps {
+		if err := ValidateAppName(app.Name); err != nil {
+			return err
+		}
+		if i > 0 && strings.Compare(export.Apps[i-1].Name, app.Name) >= 0 {
+			return fmt.Errorf("app %q is not sorted after %q or listed twice", app.Name, export.Apps[i-1].Name)
+		}
+		if app.Architectures == nil || app.Icons == nil {
+			return fmt.Errorf("app %q has no architectures or icons list", app.Name)
+		}
+		for _, arch := range app.Architectures {
+			if arch != "32" && arch != "64" {
+				return fmt.Errorf("app %q has the invalid architecture %q", app.Name, arch)
+			}
+		}
+		for locale, translation := range app.Translations {
+			if !ValidDescriptionLocale(locale) || translation.LongDescription == "" {
+				return fmt.Errorf("app %q has an invalid or empty translation %q", app.Name, locale)
+			}
+		}
+		if !app.Deprecated && (app.DeprecatedArch != "" || app.DeprecationMessage != "") {
+			return fmt.Errorf("app %q has a deprecation but is not deprecated", app.Name)
+		}
+		for j, icon := range app.Icons {
+			if icon.Size <= 0 || j > 0 && app.Icons[j-1].Size >= icon.Size {
+				return fmt.Errorf("icon sizes of app %q are not positive and sorted", app.Name)
+			}
+			if !filepath.IsLocal(icon.Path) || strings.Contains(icon.Path, "\\") {
+				return fmt.Errorf("icon path %q of app %q is not a relative path inside the Pi-Apps directory", icon.Path, app.Name)
+			}
+			if icon.File != "" && !filepath.IsLocal(icon.File) {
+				return fmt.Errorf("published icon %q of app %q is not a relative path", icon.File, app.Name)
+			}
+			if sum, err := hex.DecodeString(icon.SHA256); err != nil || len(sum) != 32 {
+				return fmt.Errorf("icon %q of app %q has an invalid sha256", icon.Path, app.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// WriteCatalogExport writes an export of directory as indented JSON to path. With includeIcons the icons are copied to a folder
+// next to it, named after the export with -icons, under the first 16 hex digits of their sha256 so a CDN can cache
+// them forever. The File of every icon is set to its copy.
+func WriteCatalogExport(export *CatalogExport, directory, path string, includeIcons bool) error {
+	if includeIcons {
+		iconsDir := strings.TrimSuffix(path, ".json") + catalogIconsSuffix
+		if err := os.MkdirAll(iconsDir, 0755); err != nil {
+			return err
+		}
+		for i := range export.Apps {
+			for j := range export.Apps[i].Icons {
+				icon := &export.Apps[i].Icons[j]
+				name := icon.SHA256[:16] + filepath.Ext(icon.Path)
+				if err := CopyFile(filepath.Join(directory, filepath.FromSlash(icon.Path)), filepath.Join(iconsDir, name)); err != nil {
+					return fmt.Errorf("failed to copy icon %s: %w", icon.Path, err)
+				}
+				icon.File = filepath.Base(iconsDir) + "/" + name
+			}
+		}
+	}
+	data, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
@@ -0,0 +1,194 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// newTestCatalogCheckout creates a checkout of the apps repository, without the files of an installed Pi-Apps
+func newTestCatalogCheckout(t *testing.T) string {
+	t.Helper()
+	directory := t.TempDir()
+	writeTestFile(t, filepath.Join(directory, "apps", "Eagle CAD", "description"), "Design circuit boards\nWith schematics.\n")
+	writeTestFile(t, filepath.Join(directory, "apps", "Eagle CAD", "description.de"), "Leiterplatten entwerfen\nMit Schaltplänen.\n")
+	writeTestFile(t, filepath.Join(directory, "apps", "Eagle CAD", "website"), "https://www.autodesk.com/eagle\n")
+	writeTestFile(t, filepath.Join(directory, "apps", "Eagle CAD", "install-64"), "#!/bin/bash\n")
+	writeTestFile(t, filepath.Join(directory, "apps", "Eagle CAD", "icon-64.png"), "big icon")
+	writeTestFile(t, filepath.Join(directory, "apps", "Eagle CAD", "icon-24.png"), "small icon")
+	writeTestFile(t, filepath.Join(directory, "apps", "Btop", "description"), "Resource monitor\n")
+	writeTestFile(t, filepath.Join(directory, "apps", "Btop", "packages"), "btop\n")
+	// Not an app, it has neither an install script nor a packages file
+	writeTestFile(t, filepath.Join(directory, "apps", "Notes", "description"), "notes\n")
+	writeTestFile(t, filepath.Join(directory, "data", "deprecated-apps", "Old App", "metadata"), "app=Old App\nremovalArch=32\nmessage=It moved to flatpak\n")
+	writeTestFile(t, filepath.Join(directory, "data", "deprecated-apps", "Old App", "icon-24.png"), "old icon")
+	writeTestFile(t, filepath.Join(directory, "etc", "catalog-version"), "1\n")
+	return directory
+}
+
+func TestExportCatalog(t *testing.T) {
+	directory := newTestCatalogCheckout(t)
+
+	export, err := ExportCatalog(directory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ValidateCatalogExport(export); err != nil {
+		t.Fatalf("export does not follow its schema: %v", err)
+	}
+	var names []string
+	for _, app := range export.Apps {
+		names = append(names, app.Name)
+	}
+	if !slices.Equal(names, []string{"Btop", "Eagle CAD", "Old App"}) {
+		t.Fatalf("exported apps = %q", names)
+	}
+
+	btop, eagle, old := export.Apps[0], export.Apps[1], export.Apps[2]
+	if btop.Type != "package" || len(btop.Architectures) != 0 || len(btop.Icons) != 0 {
+		t.Errorf("Btop = %+v", btop)
+	}
+	if eagle.ShortDescription != "Design circuit boards" || eagle.Website != "https://www.autodesk.com/eagle" ||
+		eagle.Category != "Engineering" || !slices.Equal(eagle.Architectures, []string{"64"}) || eagle.Deprecated {
+		t.Errorf("Eagle CAD = %+v", eagle)
+	}
+	german := CatalogExportTranslation{ShortDescription: "Leiterplatten entwerfen", LongDescription: "Leiterplatten entwerfen\nMit Schaltplänen."}
+	if len(eagle.Translations) != 1 || eagle.Translations["de"] != german {
+		t.Errorf("translations of Eagle CAD = %+v, want de: %+v", eagle.Translations, german)
+	}
+	if btop.Translations != nil {
+		t.Errorf("Btop without translations has %+v", btop.Translations)
+	}
+	if len(eagle.Icons) != 2 || eagle.Icons[0].Size != 24 || eagle.Icons[0].Path != "apps/Eagle CAD/icon-24.png" {
+		t.Errorf("icons of Eagle CAD = %+v", eagle.Icons)
+	}
+	if !old.Deprecated || old.DeprecatedArch != "32" || old.DeprecationMessage != "It moved to flatpak" ||
+		len(old.Icons) != 1 || old.Icons[0].Path != "data/deprecated-apps/Old App/icon-24.png" {
+		t.Errorf("Old App = %+v", old)
+	}
+}
+
+func TestWriteCatalogExport(t *testing.T) {
+	directory := newTestCatalogCheckout(t)
+	output := t.TempDir()
+
+	// Exports of the same checkout are identical, so their diffs only show what changed in the catalog
+	var written [][]byte
+	for _, name := range []string{"first.json", "second.json"} {
+		export, err := ExportCatalog(directory)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := WriteCatalogExport(export, directory, filepath.Join(output, name), false); err != nil {
+			t.Fatal(err)
+		}
+		data, err := os.ReadFile(filepath.Join(output, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		written = append(written, data)
+	}
+	if !bytes.Equal(written[0], written[1]) {
+		t.Errorf("two exports differ:\n%s\n%s", written[0], written[1])
+	}
+	if strings.Contains(string(written[0]), `"file"`) {
+		t.Error("an export without icons refers to published icons")
+	}
+
+	// Every field written is part of the schema
+	decoder := json.NewDecoder(bytes.NewReader(written[0]))
+	decoder.DisallowUnknownFields()
+	var decoded CatalogExport
+	if err := decoder.Decode(&decoded); err != nil {
+		t.Fatalf("export has fields outside of the schema: %v", err)
+	}
+	if err := ValidateCatalogExport(&decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	export, err := ExportCatalog(directory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(output, "catalog.json")
+	if err := WriteCatalogExport(export, directory, path, true); err != nil {
+		t.Fatal(err)
+	}
+	for _, app := range export.Apps {
+		for _, icon := range app.Icons {
+			if icon.File != "catalog-icons/"+icon.SHA256[:16]+".png" {
+				t.Errorf("published icon of %s = %q", icon.Path, icon.File)
+			}
+			data, err := os.ReadFile(filepath.Join(output, icon.File))
+			if err != nil {
+				t.Fatal(err)
+			}
+			original, _ := os.ReadFile(filepath.Join(directory, icon.Path))
+			if !bytes.Equal(data, original) {
+				t.Errorf("published icon %s differs from %s", icon.File, icon.Path)
+			}
+		}
+	}
+	if err := ValidateCatalogExport(export); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestValidateCatalogExport(t *testing.T) {
+	icon := CatalogExportIcon{Size: 24, Path: "apps/Zoom/icon-24.png", SHA256: strings.Repeat("ab", 32)}
+	valid := func() *CatalogExport {
+		return &CatalogExport{Schema: CatalogExportSchema, CatalogVersion: 1, Apps: []CatalogExportApp{
+			{Name: "Arduino", Architectures: []string{}, Icons: []CatalogExportIcon{}},
+			{Name: "Zoom", Architectures: []string{"64"}, Icons: []CatalogExportIcon{icon}},
+		}}
+	}
+	if err := ValidateCatalogExport(valid()); err != nil {
+		t.Fatalf("valid export: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*CatalogExport)
+	}{
+		{"newer schema", func(e *CatalogExport) { e.Schema++ }},
+		{"no catalog version", func(e *CatalogExport) { e.CatalogVersion = 0 }},
+		{"no apps list", func(e *CatalogExport) { e.Apps = nil }},
+		{"unsorted apps", func(e *CatalogExport) { e.Apps[0], e.Apps[1] = e.Apps[1], e.Apps[0] }},
+		{"duplicate app", func(e *CatalogExport) { e.Apps[0].Name = "Zoom" }},
+		{"invalid name", func(e *CatalogExport) { e.Apps[0].Name = "../Arduino" }},
+		{"invalid architecture", func(e *CatalogExport) { e.Apps[1].Architectures = []string{"arm64"} }},
+		{"null icons", func(e *CatalogExport) { e.Apps[0].Icons = nil }},
+		{"deprecation of a current app", func(e *CatalogExport) { e.Apps[0].DeprecationMessage = "gone" }},
+		{"unsorted icons", func(e *CatalogExport) { e.Apps[1].Icons = append(e.Apps[1].Icons, icon) }},
+		{"absolute icon path", func(e *CatalogExport) { e.Apps[1].Icons[0].Path = "/usr/share/icons/zoom.png" }},
+		{"icon outside the directory", func(e *CatalogExport) { e.Apps[1].Icons[0].Path = "../icon.png" }},
+		{"invalid sha256", func(e *CatalogExport) { e.Apps[1].Icons[0].SHA256 = "abc" }},
+	}
+	for _, tt := range tests {
+		export := valid()
+		tt.modify(export)
+		if err := ValidateCatalogExport(export); err == nil {
+			t.Errorf("%s: ValidateCatalogExport = nil, want an error", tt.name)
+		}
+	}
+}
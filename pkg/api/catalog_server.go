@@ -0,0 +1,441 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: catalog_server.go
+// Description: Provides a small REST server exposing the app catalog as JSON, for browsing Pi-Apps from another device.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package api
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+const (
+	// DefaultCatalogAddr is where the catalog server listens when no address is given
+	DefaultCatalogAddr = "127.0.0.1:8080"
+	// catalogActionRate is how often an action request is accepted once the burst is used up
+	catalogActionRate = 10 * time.Second
+	// catalogActionBurst is how many action requests are accepted at once
+	catalogActionBurst = 3
+	// catalogIconMaxAge is how long clients may cache app icons
+	catalogIconMaxAge = 24 * time.Hour
+	// maxQueueRequestSize is the maximum size of a POST /queue body
+	maxQueueRequestSize = 4 << 10
+)
+
+// CatalogServer serves the app catalog as read-only JSON, and optionally accepts
+// install and uninstall requests that are passed on to the manage daemon
+type CatalogServer struct {
+	mux          *http.ServeMux
+	allowActions bool
+	token        string
+	limiter      *rate.Limiter
+	eventPoll    time.Duration // how often GET /events checks the manage daemon
+}
+
+// QueueRequest is the body of a POST /queue request
+type QueueRequest struct {
+	Action string `json:"action"` // install or uninstall
+	App    string `json:"app"`
+}
+
+// CategoryEntry is a category and the apps in it, as returned by GET /categories
+type CategoryEntry struct {
+	Name string   `json:"name"`
+	Apps []string `json:"apps"`
+}
+
+// NewCatalogServer creates a catalog server for the current Pi-Apps directory
+//
+// If allowActions is true, POST /queue is enabled and protected by a random token, see Token.
+func NewCatalogServer(allowActions bool) (*CatalogServer, error) {
+	if !allowActions {
+		return newCatalogServer(false, "")
+	}
+	token, err := newCatalogToken()
+	if err != nil {
+		return nil, err
+	}
+	return newCatalogServer(true, token)
+}
+
+// NewCatalogServerWithToken creates a catalog server accepting POST /queue requests with the given token,
+// for clients that chose the token themselves like the remote mode of the GUI
+func NewCatalogServerWithToken(token string) (*CatalogServer, error) {
+	if len(token) < 16 {
+		return nil, fmt.Errorf("the token must be at least 16 characters long")
+	}
+	return newCatalogServer(true, token)
+}
+
+// newCatalogToken returns a random token for POST /queue requests
+func newCatalogToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// newCatalogServer creates a catalog server, accepting POST /queue requests with token if allowActions is true
+func newCatalogServer(allowActions bool, token string) (*CatalogServer, error) {
+	if GetPiAppsDir() == "" {
+		return nil, fmt.Errorf("PI_APPS_DIR environment variable not set")
+	}
+
+	s := &CatalogServer{
+		mux:          http.NewServeMux(),
+		allowActions: allowActions,
+		token:        token,
+		limiter:      rate.NewLimiter(rate.Every(catalogActionRate), catalogActionBurst),
+		eventPoll:    catalogEventPoll,
+	}
+
+	s.mux.HandleFunc("GET /apps", s.handleApps)
+	s.mux.HandleFunc("GET /apps/{name}", s.handleApp)
+	s.mux.HandleFunc("GET /apps/{name}/icon", s.handleAppIcon)
+	s.mux.HandleFunc("GET /search", s.handleSearch)
+	s.mux.HandleFunc("GET /status", s.handleStatus)
+	s.mux.HandleFunc("GET /categories", s.handleCategories)
+	s.mux.HandleFunc("GET /state", s.handleState)
+	s.mux.HandleFunc("GET /state/diff", s.handleStateDiff)
+	s.mux.HandleFunc("GET /metrics", s.handleMetrics)
+	s.mux.HandleFunc("GET /events", s.handleEvents)
+	if allowActions {
+		s.mux.HandleFunc("POST /queue", s.handleQueue)
+	}
+	return s, nil
+}
+
+// Token returns the token POST /queue requests need in their "Authorization: Bearer" header,
+// or "" if actions are not allowed
+func (s *CatalogServer) Token() string {
+	return s.token
+}
+
+// ServeHTTP implements http.Handler
+func (s *CatalogServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// CatalogListenAddr returns the address the catalog server listens on for addr,
+// binding to localhost when no host is given (e.g. ":8080")
+//
+// Use 0.0.0.0:<port> to make the catalog reachable from other devices.
+func CatalogListenAddr(addr string) (string, error) {
+	if addr == "" {
+		return DefaultCatalogAddr, nil
+	}
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", fmt.Errorf("invalid address %q: %w", addr, err)
+	}
+	if host == "" {
+		host = "127.0.0.1"
+	}
+	return net.JoinHostPort(host, port), nil
+}
+
+// ServeCatalog runs a catalog server on addr until it fails
+func ServeCatalog(addr string, allowActions bool) error {
+	server, err := NewCatalogServer(allowActions)
+	if err != nil {
+		return err
+	}
+	return serveCatalog(addr, server, true)
+}
+
+// ServeCatalogWithToken runs a catalog server accepting POST /queue requests with the given token on addr
+// until it fails. The token is not printed, the client already knows it.
+func ServeCatalogWithToken(addr, token string) error {
+	server, err := NewCatalogServerWithToken(token)
+	if err != nil {
+		return err
+	}
+	return serveCatalog(addr, server, false)
+}
+
+// serveCatalog runs a catalog server on addr until it fails, printing its token if printToken is true
+func serveCatalog(addr string, server *CatalogServer, printToken bool) error {
+	listenAddr, err := CatalogListenAddr(addr)
+	if err != nil {
+		return err
+	}
+
+	StatusTf("Serving the app catalog on http://%s", listenAddr)
+	if server.allowActions && printToken {
+		StatusTf("Actions are enabled, POST /queue requires the header: Authorization: Bearer %s", server.Token())
+	}
+
+	httpServer := &http.Server{
+		Addr:              listenAddr,
+		Handler:           server,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// handleApps lists the metadata of every app that is not hidden
+func (s *CatalogServer) handleApps(w http.ResponseWriter, r *http.Request) {
+	all, err := GetAllAppMetadata()
+	if err != nil {
+		writeCatalogError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	apps := make([]*AppMetadata, 0, len(all))
+	for _, metadata := range all {
+		if metadata.Category != "hidden" {
+			apps = append(apps, metadata)
+		}
+	}
+	writeCatalogJSON(w, apps)
+}
+
+// handleSearch lists the apps that are not hidden and whose name, description, website or credits
+// contain the q query parameter, ignoring case like AppSearch
+func (s *CatalogServer) handleSearch(w http.ResponseWriter, r *http.Request) {
+	query := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("q")))
+	if query == "" {
+		writeCatalogError(w, http.StatusBadRequest, "missing search query, use ?q=")
+		return
+	}
+	all, err := GetAllAppMetadata()
+	if err != nil {
+		writeCatalogError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	apps := make([]*AppMetadata, 0)
+	for _, metadata := range all {
+		if metadata.Category == "hidden" {
+			continue
+		}
+		for _, field := range []string{metadata.Name, metadata.LongDescription, metadata.Website, metadata.Credits} {
+			if strings.Contains(strings.ToLower(field), query) {
+				apps = append(apps, metadata)
+				break
+			}
+		}
+	}
+	writeCatalogJSON(w, apps)
+}
+
+// handleApp returns the metadata of a single app
+func (s *CatalogServer) handleApp(w http.ResponseWriter, r *http.Request) {
+	metadata, ok := s.lookupApp(w, r.PathValue("name"))
+	if !ok {
+		return
+	}
+	writeCatalogJSON(w, metadata)
+}
+
+// handleAppIcon serves an icon of an app, the size defaults to 64
+func (s *CatalogServer) handleAppIcon(w http.ResponseWriter, r *http.Request) {
+	metadata, ok := s.lookupApp(w, r.PathValue("name"))
+	if !ok {
+		return
+	}
+
+	size := 64
+	if value := r.URL.Query().Get("size"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			writeCatalogError(w, http.StatusBadRequest, "invalid icon size")
+			return
+		}
+		size = n
+	}
+	iconPath, ok := metadata.IconPaths[size]
+	if !ok {
+		writeCatalogError(w, http.StatusNotFound, fmt.Sprintf("no %dpx icon for %s", size, metadata.Name))
+		return
+	}
+
+	icon, err := os.Open(iconPath)
+	if err != nil {
+		writeCatalogError(w, http.StatusNotFound, fmt.Sprintf("no %dpx icon for %s", size, metadata.Name))
+		return
+	}
+	defer icon.Close()
+	info, err := icon.Stat()
+	if err != nil {
+		writeCatalogError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	contentType := mime.TypeByExtension(filepath.Ext(iconPath))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(catalogIconMaxAge.Seconds())))
+	w.Header().Set("ETag", fmt.Sprintf(`"%x-%x"`, info.ModTime().Unix(), info.Size()))
+	// ServeContent answers If-None-Match and If-Modified-Since with 304 Not Modified
+	http.ServeContent(w, r, filepath.Base(iconPath), info.ModTime(), icon)
+}
+
+// handleStatus returns the status of every app
+func (s *CatalogServer) handleStatus(w http.ResponseWriter, r *http.Request) {
+	statuses, err := GetAllAppStatuses()
+	if err != nil {
+		writeCatalogError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeCatalogJSON(w, statuses)
+}
+
+// handleState returns the installed apps and their hash, so a fleet of machines can be compared at a glance
+func (s *CatalogServer) handleState(w http.ResponseWriter, r *http.Request) {
+	state, err := CurrentAppState()
+	if err != nil {
+		writeCatalogError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeCatalogJSON(w, state)
+}
+
+// handleStateDiff returns what differs from the desired state in data/desired-state.json
+func (s *CatalogServer) handleStateDiff(w http.ResponseWriter, r *http.Request) {
+	desired, err := ReadStateFile(DesiredStatePath())
+	if errors.Is(err, os.ErrNotExist) {
+		writeCatalogError(w, http.StatusNotFound, "no desired state, copy an exported state to data/desired-state.json")
+		return
+	} else if err != nil {
+		writeCatalogError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	current, err := CurrentAppState()
+	if err != nil {
+		writeCatalogError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeCatalogJSON(w, DiffAppState(current, desired))
+}
+
+// handleCategories lists every category with the apps in it, sorted by name
+func (s *CatalogServer) handleCategories(w http.ResponseWriter, r *http.Request) {
+	all, err := GetAllAppMetadata()
+	if err != nil {
+		writeCatalogError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	apps := make(map[string][]string)
+	for _, metadata := range all {
+		if metadata.Category == "hidden" {
+			continue
+		}
+		apps[metadata.Category] = append(apps[metadata.Category], metadata.Name)
+	}
+	categories := make([]CategoryEntry, 0, len(apps))
+	for name, list := range apps {
+		sort.Strings(list)
+		categories = append(categories, CategoryEntry{Name: name, Apps: list})
+	}
+	sort.Slice(categories, func(i, j int) bool {
+		return categories[i].Name < categories[j].Name
+	})
+	writeCatalogJSON(w, categories)
+}
+
+// handleQueue passes an install or uninstall request on to the manage daemon
+func (s *CatalogServer) handleQueue(w http.ResponseWriter, r *http.Request) {
+	token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !found || subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+		writeCatalogError(w, http.StatusUnauthorized, "invalid or missing token")
+		return
+	}
+	if !s.limiter.Allow() {
+		writeCatalogError(w, http.StatusTooManyRequests, "rate limit exceeded")
+		return
+	}
+
+	var request QueueRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxQueueRequestSize)).Decode(&request); err != nil {
+		writeCatalogError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if request.Action != "install" && request.Action != "uninstall" {
+		writeCatalogError(w, http.StatusBadRequest, "action must be install or uninstall")
+		return
+	}
+	if _, ok := s.lookupApp(w, request.App); !ok {
+		return
+	}
+
+	// The daemon client blocks while it starts a new daemon, so don't make the client wait for it
+	queue := QueueLine(request.Action, request.App)
+	go func() {
+		if err := TerminalManageMulti(queue); err != nil {
+			WarningTf("Failed to queue %s: %v", queue, err)
+		}
+	}()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(request)
+}
+
+// lookupApp returns the metadata of an app, or writes an error response if the app is invalid or does not exist
+func (s *CatalogServer) lookupApp(w http.ResponseWriter, app string) (*AppMetadata, bool) {
+	if err := ValidateAppName(app); err != nil {
+		writeCatalogError(w, http.StatusBadRequest, err.Error())
+		return nil, false
+	}
+	if !isDir(filepath.Join(GetPiAppsDir(), "apps", app)) {
+		writeCatalogError(w, http.StatusNotFound, fmt.Sprintf("app '%s' does not exist", app))
+		return nil, false
+	}
+	metadata, err := GetAppMetadata(app)
+	if err != nil {
+		writeCatalogError(w, http.StatusInternalServerError, err.Error())
+		return nil, false
+	}
+	return metadata, true
+}
+
+// writeCatalogJSON writes v as a JSON response
+func writeCatalogJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeCatalogError writes a JSON error response
+func writeCatalogError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
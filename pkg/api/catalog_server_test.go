@@ -0,0 +1,234 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newTestCatalog serves a catalog of a fixture Pi-Apps directory with a standard app, an app with credits and a hidden app
+func newTestCatalog(t *testing.T, allowActions bool, token string) *httptest.Server {
+	t.Helper()
+	directory := newTestPiAppsDir(t, "Alpha Tool", "Beta", "Secret")
+	alpha := filepath.Join(directory, "apps", "Alpha Tool")
+	writeTestFile(t, filepath.Join(alpha, "description"), "A handy tool\nWith a longer description\n")
+	writeTestFile(t, filepath.Join(alpha, "website"), "https://example.com/alpha\n")
+	writeTestFile(t, filepath.Join(alpha, "install"), "#!/bin/bash\n")
+	writeTestFile(t, filepath.Join(alpha, "icon-64.png"), "png")
+	writeTestFile(t, filepath.Join(directory, "apps", "Beta", "credits"), "Made by Gamma\n")
+	writeTestFile(t, filepath.Join(directory, "apps", "Beta", "install-64"), "#!/bin/bash\n")
+	writeTestFile(t, filepath.Join(directory, "apps", "Secret", "packages"), "secret\n")
+	writeTestFile(t, filepath.Join(directory, "data", "status", "Alpha Tool"), "installed\n")
+	writeTestFile(t, filepath.Join(directory, "data", "category-overrides"), "Alpha Tool|Tools\nBeta|Games\nSecret|hidden\n")
+
+	catalog, err := newCatalogServer(allowActions, token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(catalog)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// getCatalog requests path from the catalog, checks the status code and decodes the JSON response into v
+func getCatalog(t *testing.T, server *httptest.Server, path string, status int, v any) {
+	t.Helper()
+	resp, err := http.Get(server.URL + path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != status {
+		t.Fatalf("GET %s = %d, want %d", path, resp.StatusCode, status)
+	}
+	if v != nil {
+		if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+			t.Fatalf("GET %s: %v", path, err)
+		}
+	}
+}
+
+// appNames returns the names of apps
+func appNames(apps []*AppMetadata) []string {
+	names := make([]string, 0, len(apps))
+	for _, app := range apps {
+		names = append(names, app.Name)
+	}
+	return names
+}
+
+func TestCatalogServerApps(t *testing.T) {
+	server := newTestCatalog(t, false, "")
+
+	var apps []*AppMetadata
+	getCatalog(t, server, "/apps", http.StatusOK, &apps)
+	if got := strings.Join(appNames(apps), ","); got != "Alpha Tool,Beta" {
+		t.Errorf("GET /apps = %s, want Alpha Tool,Beta", got)
+	}
+
+	var alpha AppMetadata
+	getCatalog(t, server, "/apps/"+url.PathEscape("Alpha Tool"), http.StatusOK, &alpha)
+	if alpha.ShortDescription != "A handy tool" || alpha.Website != "https://example.com/alpha" {
+		t.Errorf("unexpected descriptions: %+v", alpha)
+	}
+	if alpha.Category != "Tools" || alpha.Status != "installed" || alpha.Type != "standard" {
+		t.Errorf("unexpected category, status or type: %+v", alpha)
+	}
+	if strings.Join(alpha.Architectures, ",") != "32,64" {
+		t.Errorf("architectures = %v, want [32 64]", alpha.Architectures)
+	}
+
+	var categories []CategoryEntry
+	getCatalog(t, server, "/categories", http.StatusOK, &categories)
+	if len(categories) != 2 || categories[0].Name != "Games" || categories[1].Name != "Tools" {
+		t.Errorf("GET /categories = %+v, want Games and Tools", categories)
+	}
+
+	var statuses map[string]string
+	getCatalog(t, server, "/status", http.StatusOK, &statuses)
+	if statuses["Alpha Tool"] != "installed" || statuses["Beta"] != "uninstalled" {
+		t.Errorf("GET /status = %v", statuses)
+	}
+}
+
+func TestCatalogServerSearch(t *testing.T) {
+	server := newTestCatalog(t, false, "")
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"alpha", "Alpha Tool"},            // name
+		{"HANDY", "Alpha Tool"},            // description, ignoring case
+		{"example.com", "Alpha Tool"},      // website
+		{"gamma", "Beta"},                  // credits
+		{"description", "Alpha Tool,Beta"}, // the hidden app is never listed
+		{"nothing matches", ""},
+	}
+	for _, tt := range tests {
+		var apps []*AppMetadata
+		getCatalog(t, server, "/search?q="+url.QueryEscape(tt.query), http.StatusOK, &apps)
+		if got := strings.Join(appNames(apps), ","); got != tt.want {
+			t.Errorf("GET /search?q=%s = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestCatalogServerErrors(t *testing.T) {
+	server := newTestCatalog(t, false, "")
+
+	tests := []struct {
+		path   string
+		status int
+	}{
+		{"/apps/Missing", http.StatusNotFound},
+		{"/apps/.hidden", http.StatusBadRequest},
+		{"/apps/" + url.PathEscape("a;b") + "/icon", http.StatusBadRequest},
+		{"/apps/Beta/icon", http.StatusNotFound},
+		{"/apps/" + url.PathEscape("Alpha Tool") + "/icon?size=abc", http.StatusBadRequest},
+		{"/apps/" + url.PathEscape("Alpha Tool") + "/icon?size=-1", http.StatusBadRequest},
+		{"/apps/" + url.PathEscape("Alpha Tool") + "/icon?size=24", http.StatusNotFound},
+		{"/search", http.StatusBadRequest},
+		{"/search?q=++", http.StatusBadRequest},
+		{"/state/diff", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		var body map[string]string
+		getCatalog(t, server, tt.path, tt.status, &body)
+		if body["error"] == "" {
+			t.Errorf("GET %s returned no error message", tt.path)
+		}
+	}
+
+	// POST /queue only exists when actions are allowed
+	resp, err := http.Post(server.URL+"/queue", "application/json", strings.NewReader(`{"action":"install","app":"Beta"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed && resp.StatusCode != http.StatusNotFound {
+		t.Errorf("POST /queue without actions = %d, want 404 or 405", resp.StatusCode)
+	}
+}
+
+func TestCatalogServerIcon(t *testing.T) {
+	server := newTestCatalog(t, false, "")
+
+	resp, err := http.Get(server.URL + "/apps/" + url.PathEscape("Alpha Tool") + "/icon")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "image/png" {
+		t.Fatalf("GET icon = %d %s, want 200 image/png", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	etag := resp.Header.Get("ETag")
+	if etag == "" {
+		t.Fatal("GET icon returned no ETag")
+	}
+
+	request, _ := http.NewRequest(http.MethodGet, resp.Request.URL.String(), nil)
+	request.Header.Set("If-None-Match", etag)
+	resp, err = http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotModified {
+		t.Errorf("GET icon with a matching ETag = %d, want 304", resp.StatusCode)
+	}
+}
+
+func TestCatalogServerQueueAuth(t *testing.T) {
+	token := "0123456789abcdef0123"
+	server := newTestCatalog(t, true, token)
+
+	tests := []struct {
+		name   string
+		auth   string
+		body   string
+		status int
+	}{
+		{"missing token", "", `{"action":"install","app":"Beta"}`, http.StatusUnauthorized},
+		{"wrong token", "Bearer wrong", `{"action":"install","app":"Beta"}`, http.StatusUnauthorized},
+		{"bad body", "Bearer " + token, `not json`, http.StatusBadRequest},
+		{"bad action", "Bearer " + token, `{"action":"reinstall","app":"Beta"}`, http.StatusBadRequest},
+		{"missing app", "Bearer " + token, `{"action":"install","app":"Missing"}`, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request, _ := http.NewRequest(http.MethodPost, server.URL+"/queue", strings.NewReader(tt.body))
+			if tt.auth != "" {
+				request.Header.Set("Authorization", tt.auth)
+			}
+			resp, err := http.DefaultClient.Do(request)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.status {
+				t.Errorf("POST /queue = %d, want %d", resp.StatusCode, tt.status)
+			}
+		})
+	}
+}
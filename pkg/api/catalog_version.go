@@ -0,0 +1,90 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: catalog_version.go
+// Description: Tells which schema version an apps catalog uses, so app folders needing a newer Pi-Apps aren't applied by older builds.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package api
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// CatalogVersion is the newest apps catalog schema version this build of Pi-Apps understands.
+// Bump it together with etc/catalog-version when app folders start using files older builds can't handle.
+const CatalogVersion = 1
+
+// catalogVersionFile is the file in etc recording the schema version of the apps catalog next to it
+const catalogVersionFile = "catalog-version"
+
+// CatalogVersionError is returned when an apps catalog needs a newer build of Pi-Apps than the running one
+type CatalogVersionError struct {
+	Required int
+}
+
+func (e *CatalogVersionError) Error() string {
+	return fmt.Sprintf("the apps catalog uses schema version %d, but this version of Pi-Apps only understands up to version %d", e.Required, CatalogVersion)
+}
+
+// ReadCatalogVersion returns the schema version of the apps catalog in a Pi-Apps directory, like the update clone.
+// Catalogs from before the version was recorded have no etc/catalog-version and are version 1.
+func ReadCatalogVersion(directory string) (int, error) {
+	file, err := os.Open(filepath.Join(directory, "etc", catalogVersionFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return 1, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		version, err := strconv.Atoi(line)
+		if err != nil || version < 1 {
+			return 0, fmt.Errorf("invalid catalog version %q", line)
+		}
+		return version, nil
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+	return 1, nil
+}
+
+// CheckCatalogVersion returns a *CatalogVersionError if the apps catalog in a Pi-Apps directory needs a newer build of Pi-Apps
+func CheckCatalogVersion(directory string) error {
+	version, err := ReadCatalogVersion(directory)
+	if err != nil {
+		return err
+	}
+	if version > CatalogVersion {
+		return &CatalogVersionError{Required: version}
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"errors"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+func TestReadCatalogVersion(t *testing.T) {
+	tests := []struct {
+		content string // "" for no etc/catalog-version
+		want    int
+		wantErr bool
+	}{
+		{content: "", want: 1},
+		{content: "# Schema version\n\n2\n", want: 2},
+		{content: " 3 \n4\n", want: 3},
+		{content: "# only comments\n", want: 1},
+		{content: "two\n", wantErr: true},
+		{content: "0\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		dir := t.TempDir()
+		if tt.content != "" {
+			writeTestFile(t, filepath.Join(dir, "etc", catalogVersionFile), tt.content)
+		}
+		got, err := ReadCatalogVersion(dir)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ReadCatalogVersion(%q) = %d, want an error", tt.content, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("ReadCatalogVersion(%q) = %d, %v, want %d", tt.content, got, err, tt.want)
+		}
+	}
+}
+
+func TestCheckCatalogVersion(t *testing.T) {
+	// The catalog in this repository must be one this build understands
+	if version, err := ReadCatalogVersion(filepath.Join("..", "..")); err != nil || version != CatalogVersion {
+		t.Errorf("etc/catalog-version = %d, %v, want CatalogVersion %d", version, err, CatalogVersion)
+	}
+
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "etc", catalogVersionFile), strconv.Itoa(CatalogVersion)+"\n")
+	if err := CheckCatalogVersion(dir); err != nil {
+		t.Errorf("CheckCatalogVersion of the current version = %v", err)
+	}
+
+	writeTestFile(t, filepath.Join(dir, "etc", catalogVersionFile), strconv.Itoa(CatalogVersion+1)+"\n")
+	var versionErr *CatalogVersionError
+	if err := CheckCatalogVersion(dir); !errors.As(err, &versionErr) || versionErr.Required != CatalogVersion+1 {
+		t.Errorf("CheckCatalogVersion of a newer version = %v, want a CatalogVersionError", err)
+	}
+}
@@ -25,12 +25,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
-
-	"github.com/gotk3/gotk3/gdk"
-	"github.com/gotk3/gotk3/glib"
-	"github.com/gotk3/gotk3/gtk"
 )
 
 // Embedded default category data - structured Go-native configuration
@@ -380,7 +377,7 @@ func ReadCategoryData() (*CategoryData, error) {
 	parseCategoryAssignments(embeddedGlobalCategories, data.GlobalCategories)
 
 	// Read local category overrides file (user overrides)
-	localFile := filepath.Join(piAppsDir, "data", "category-overrides")
+	localFile := filepath.Join(DataDirOf(piAppsDir), "category-overrides")
 	if FileExists(localFile) {
 		if err := readCategoryFile(localFile, data.LocalCategories); err != nil {
 			return nil, fmt.Errorf("failed to read local categories: %w", err)
@@ -448,7 +445,7 @@ func (cd *CategoryData) SaveLocalCategories() error {
 		return fmt.Errorf("PI_APPS_DIR environment variable not set")
 	}
 
-	localFile := filepath.Join(piAppsDir, "data", "category-overrides")
+	localFile := filepath.Join(DataDirOf(piAppsDir), "category-overrides")
 
 	// Ensure the data directory exists
 	if err := EnsureDir(filepath.Dir(localFile)); err != nil {
@@ -506,405 +503,179 @@ func (cd *CategoryData) ClearAllCategories() {
 	cd.LocalCategories = newLocal
 }
 
-// ShowCategoryEditor displays the category editor GUI
-func ShowCategoryEditor() error {
-	return showCategoryEditorGUI()
-}
-
-// EditAppCategory edits a specific app's category (command line interface)
-func EditAppCategory(app, category string) error {
-	piAppsDir := GetPiAppsDir()
-	if piAppsDir == "" {
-		return fmt.Errorf("PI_APPS_DIR environment variable not set")
-	}
+// Special categories apps can be moved to besides the official ones
+const (
+	// HiddenCategory hides an app from the app browser and search. It can still be installed from the command line
+	// and is still updated.
+	HiddenCategory = "hidden"
+	// UnlistedCategory removes an app from every category, it is only shown in All Apps
+	UnlistedCategory = ""
+)
 
-	// Get list of apps
-	apps, err := ListApps("local")
-	if err != nil {
-		return fmt.Errorf("failed to get app list: %w", err)
-	}
+// OfficialCategories returns the categories of the upstream category lists: the global and device-specific
+// assignments and the category files in data/categories. The special categories are left out.
+func OfficialCategories(directory string) []string {
+	var assignments []CategoryAssignment
+	assignments = append(assignments, embeddedGlobalCategories...)
+	assignments = append(assignments, embeddedCategoryOverridesNonRaspberry...)
+	assignments = append(assignments, embeddedCategoryOverridesJetsonGeneric...)
+	assignments = append(assignments, readCategoryDir(directory)...)
 
-	// Check if app exists
-	appExists := false
-	for _, existingApp := range apps {
-		if existingApp == app {
-			appExists = true
-			break
+	var categories []string
+	for _, assignment := range assignments {
+		if assignment.Category != HiddenCategory && assignment.Category != UnlistedCategory {
+			categories = append(categories, assignment.Category)
 		}
 	}
-	if !appExists {
-		return fmt.Errorf("the '%s' app does not exist", app)
-	}
-
-	// Read category data
-	data, err := ReadCategoryData()
-	if err != nil {
-		return fmt.Errorf("failed to read category data: %w", err)
-	}
-
-	// Set the category
-	data.SetAppCategory(app, category)
-
-	// Save changes
-	if err := data.SaveLocalCategories(); err != nil {
-		return fmt.Errorf("failed to save category changes: %w", err)
-	}
-
-	return nil
+	slices.Sort(categories)
+	return slices.Compact(categories)
 }
 
-// showCategoryEditorGUI displays the category editor using GTK
-func showCategoryEditorGUI() error {
-	piAppsDir := GetPiAppsDir()
-	if piAppsDir == "" {
-		return fmt.Errorf("PI_APPS_DIR environment variable not set")
-	}
-
-	// Initialize GTK
-	glib.SetPrgname("Category editor")
-	gtk.Init(nil)
-
-	for {
-		// Read current category data
-		data, err := ReadCategoryData()
-		if err != nil {
-			return fmt.Errorf("failed to read category data: %w", err)
-		}
-
-		// Get list of apps
-		apps, err := ListApps("local")
-		if err != nil {
-			return fmt.Errorf("failed to get app list: %w", err)
-		}
-
-		// Show the dialog
-		action, newData, err := showCategoryDialog(data, apps)
-		if err != nil {
-			return fmt.Errorf("failed to show category dialog: %w", err)
-		}
-
-		switch action {
-		case "save":
-			// Apply changes from the dialog
-			*data = *newData
-			if err := data.SaveLocalCategories(); err != nil {
-				showErrorDialog("Failed to save category changes: " + err.Error())
-				continue
-			}
-
-			// Refresh app list in background
-			go func() {
-				_ = RefreshAppList()
-			}()
-
-			return nil
-
-		case "reset":
-			// Reset to global categories
-			data.ResetToGlobalCategories()
-			continue // Show dialog again with reset data
-
-		case "clear":
-			// Clear all categories
-			data.ClearAllCategories()
-			continue // Show dialog again with cleared data
-
-		case "cancel":
-			return nil // Exit without saving
-
-		default:
-			return nil // Exit
-		}
+// ValidateCategory checks an app can be moved to a category. Unless create is true, it must be an official
+// category or a special one, so a typo doesn't make up a category that never gets new apps.
+func ValidateCategory(directory, category string, create bool) error {
+	// The categories file uses one "app|category" entry per line
+	if strings.ContainsAny(category, "|\r\n") {
+		return fmt.Errorf("invalid category %q", category)
 	}
-}
-
-// showCategoryDialog shows the main category editing dialog
-func showCategoryDialog(data *CategoryData, apps []string) (string, *CategoryData, error) {
-	piAppsDir := GetPiAppsDir()
-	if piAppsDir == "" {
-		return "", nil, fmt.Errorf("PI_APPS_DIR environment variable not set")
+	if category == HiddenCategory || category == UnlistedCategory {
+		return nil
 	}
-
-	// Create main dialog
-	dialog, err := gtk.DialogNew()
-	if err != nil {
-		return "", nil, fmt.Errorf("failed to create dialog: %w", err)
+	official := OfficialCategories(directory)
+	if slices.Contains(official, category) {
+		return nil
 	}
-	defer dialog.Destroy()
-
-	dialog.SetTitle("Category editor")
-	dialog.SetDefaultSize(600, 400)
-	dialog.SetPosition(gtk.WIN_POS_CENTER)
-	dialog.SetModal(true)
-
-	// Set window icon
-	iconPath := filepath.Join(piAppsDir, "icons", "settings.png")
-	if FileExists(iconPath) {
-		pixbuf, err := gdk.PixbufNewFromFile(iconPath)
-		if err == nil {
-			dialog.SetIcon(pixbuf)
+	if create {
+		if strings.TrimSpace(category) != category || strings.Contains(category, "//") ||
+			strings.HasPrefix(category, "/") || strings.HasSuffix(category, "/") {
+			return fmt.Errorf("invalid category %q", category)
 		}
+		return nil
 	}
 
-	// Get content area
-	contentArea, err := dialog.GetContentArea()
-	if err != nil {
-		return "", nil, fmt.Errorf("failed to get content area: %w", err)
-	}
-
-	// Create header label
-	headerText := "Changes saved to: " + strings.Replace(filepath.Join(piAppsDir, "data", "category-overrides"), os.Getenv("HOME"), "~", 1)
-	headerLabel, err := gtk.LabelNew(headerText)
-	if err != nil {
-		return "", nil, fmt.Errorf("failed to create header label: %w", err)
-	}
-	headerLabel.SetHAlign(gtk.ALIGN_START)
-	contentArea.PackStart(headerLabel, false, false, 8)
-
-	// Create scrolled window
-	scrolledWindow, err := gtk.ScrolledWindowNew(nil, nil)
-	if err != nil {
-		return "", nil, fmt.Errorf("failed to create scrolled window: %w", err)
+	message := fmt.Sprintf("unknown category %q", category)
+	if suggestions := similarCategories(category, official); len(suggestions) > 0 {
+		message += fmt.Sprintf(" (did you mean %s?)", strings.Join(suggestions, " or "))
 	}
-	scrolledWindow.SetPolicy(gtk.POLICY_AUTOMATIC, gtk.POLICY_AUTOMATIC)
-	scrolledWindow.SetShadowType(gtk.SHADOW_IN)
-	contentArea.PackStart(scrolledWindow, true, true, 0)
+	return fmt.Errorf("%s, use --create to create a new category", message)
+}
 
-	// Create tree view and model
-	treeView, listStore, err := createCategoryTreeView()
-	if err != nil {
-		return "", nil, fmt.Errorf("failed to create tree view: %w", err)
+// similarCategories returns the official categories a mistyped category may have meant: the same but for case or
+// a plural, or a subcategory with that name
+func similarCategories(category string, official []string) []string {
+	lower := strings.ToLower(category)
+	var similar []string
+	for _, candidate := range official {
+		name := strings.ToLower(candidate)
+		_, subcategory, _ := strings.Cut(name, "/")
+		if name == lower || strings.TrimSuffix(name, "s") == strings.TrimSuffix(lower, "s") || subcategory == lower {
+			similar = append(similar, candidate)
+		}
 	}
-	scrolledWindow.Add(treeView)
-
-	// Populate the list with apps and their categories
-	populateCategoryList(listStore, data, apps)
+	return similar
+}
 
-	// Create buttons manually so we have direct access to them
-	resetBtn, err := gtk.ButtonNewWithLabel("Reset")
-	if err != nil {
-		return "", nil, fmt.Errorf("failed to create reset button: %w", err)
-	}
-	setButtonIcon(resetBtn, filepath.Join(piAppsDir, "icons", "backup.png"))
-	resetBtn.SetTooltipText("Removes all category overrides.")
-	dialog.AddActionWidget(resetBtn, 4)
+// CategoryCount is a category with the number of local apps in it
+type CategoryCount struct {
+	Category string `json:"category"` // "hidden" for hidden apps, "" for apps without a category
+	Apps     int    `json:"apps"`
+	Official bool   `json:"official"` // false for the special categories and those created with --create
+}
 
-	allBtn, err := gtk.ButtonNewWithLabel("All")
+// CategoryCounts returns the official categories, those the user created and the special ones, with how many
+// local apps each has. Official and created categories come first by name, then hidden and unlisted apps.
+func CategoryCounts(directory string) ([]CategoryCount, error) {
+	categories, err := readCategoryFiles(directory)
 	if err != nil {
-		return "", nil, fmt.Errorf("failed to create all button: %w", err)
+		return nil, err
 	}
-	setButtonIcon(allBtn, filepath.Join(piAppsDir, "icons", "trash.png"))
-	allBtn.SetTooltipText("Clears categories so all apps are in one list.")
-	dialog.AddActionWidget(allBtn, 2)
-
-	cancelBtn, err := gtk.ButtonNewWithLabel("Cancel")
-	if err != nil {
-		return "", nil, fmt.Errorf("failed to create cancel button: %w", err)
+	counts := make(map[string]int)
+	for app, category := range categories {
+		if checkFileExists(filepath.Join(directory, "apps", app)) {
+			counts[category]++
+		}
 	}
-	setButtonIcon(cancelBtn, filepath.Join(piAppsDir, "icons", "exit.png"))
-	cancelBtn.SetTooltipText("Don't save any changes.")
-	dialog.AddActionWidget(cancelBtn, gtk.RESPONSE_CANCEL)
 
-	saveBtn, err := gtk.ButtonNewWithLabel("Save")
-	if err != nil {
-		return "", nil, fmt.Errorf("failed to create save button: %w", err)
+	official := OfficialCategories(directory)
+	var result []CategoryCount
+	for _, category := range official {
+		result = append(result, CategoryCount{Category: category, Apps: counts[category], Official: true})
 	}
-	setButtonIcon(saveBtn, filepath.Join(piAppsDir, "icons", "check.png"))
-	dialog.AddActionWidget(saveBtn, gtk.RESPONSE_OK)
-
-	// Show all widgets
-	dialog.ShowAll()
-
-	// Run dialog
-	response := dialog.Run()
-
-	// Extract modified data from the tree view
-	newData := extractCategoryData(listStore, data)
-
-	switch response {
-	case gtk.RESPONSE_OK:
-		return "save", newData, nil
-	case 2:
-		return "clear", newData, nil
-	case 4:
-		return "reset", newData, nil
-	default:
-		return "cancel", newData, nil
+	for category, count := range counts {
+		if category != HiddenCategory && category != UnlistedCategory && !slices.Contains(official, category) {
+			result = append(result, CategoryCount{Category: category, Apps: count})
+		}
 	}
+	slices.SortFunc(result, func(a, b CategoryCount) int { return strings.Compare(a.Category, b.Category) })
+	result = append(result,
+		CategoryCount{Category: HiddenCategory, Apps: counts[HiddenCategory]},
+		CategoryCount{Category: UnlistedCategory, Apps: counts[UnlistedCategory]})
+	return result, nil
 }
 
-// createCategoryTreeView creates and configures the tree view for displaying apps and categories
-func createCategoryTreeView() (*gtk.TreeView, *gtk.ListStore, error) {
-	// Create list store with columns: Icon(pixbuf), Name(string), Category(string)
-	listStore, err := gtk.ListStoreNew(gdk.PixbufGetType(), glib.TYPE_STRING, glib.TYPE_STRING)
-	if err != nil {
-		return nil, nil, fmt.Errorf("unable to create list store: %w", err)
-	}
-
-	// Create tree view
-	treeView, err := gtk.TreeViewNewWithModel(listStore)
-	if err != nil {
-		return nil, nil, fmt.Errorf("unable to create tree view: %w", err)
+// EditAppCategory moves an app to a category from the command line. The category must be an official one,
+// "hidden" or "" (no category), or create must be true. The choice is kept in the user's category-overrides file
+// even when it matches the upstream category, so a later change of the upstream lists doesn't undo it.
+func EditAppCategory(app, category string, create bool) error {
+	piAppsDir := GetPiAppsDir()
+	if piAppsDir == "" {
+		return fmt.Errorf("PI_APPS_DIR environment variable not set")
 	}
-
-	// Create icon column
-	iconRenderer, err := gtk.CellRendererPixbufNew()
-	if err != nil {
-		return nil, nil, fmt.Errorf("unable to create icon renderer: %w", err)
+	if err := ValidateAppName(app); err != nil {
+		return err
 	}
-	iconColumn, err := gtk.TreeViewColumnNew()
-	if err != nil {
-		return nil, nil, fmt.Errorf("unable to create icon column: %w", err)
+	if err := ValidateCategory(piAppsDir, category, create); err != nil {
+		return err
 	}
-	iconColumn.PackStart(iconRenderer, false)
-	iconColumn.AddAttribute(iconRenderer, "pixbuf", 0)
-	iconColumn.SetSizing(gtk.TREE_VIEW_COLUMN_FIXED)
-	iconColumn.SetFixedWidth(30)
-	treeView.AppendColumn(iconColumn)
+	return setAppCategory(app, category, true)
+}
 
-	// Create name column
-	nameRenderer, err := gtk.CellRendererTextNew()
-	if err != nil {
-		return nil, nil, fmt.Errorf("unable to create name renderer: %w", err)
+// setAppCategory saves the category of an app in the category-overrides file. Unless pin is true, an override
+// matching the global category is removed instead, as the automatic hiding of apps does.
+func setAppCategory(app, category string, pin bool) error {
+	if err := ValidateAppName(app); err != nil {
+		return err
 	}
-	nameColumn, err := gtk.TreeViewColumnNewWithAttribute("Name", nameRenderer, "text", 1)
-	if err != nil {
-		return nil, nil, fmt.Errorf("unable to create name column: %w", err)
+	// The categories file uses one "app|category" entry per line
+	if strings.ContainsAny(category, "|\r\n") {
+		return fmt.Errorf("invalid category %q", category)
 	}
-	treeView.AppendColumn(nameColumn)
 
-	// Create category column (editable)
-	categoryRenderer, err := gtk.CellRendererTextNew()
-	if err != nil {
-		return nil, nil, fmt.Errorf("unable to create category renderer: %w", err)
-	}
-	categoryRenderer.SetProperty("editable", true)
-	categoryColumn, err := gtk.TreeViewColumnNewWithAttribute("Category", categoryRenderer, "text", 2)
+	// Get list of apps
+	apps, err := ListApps("local")
 	if err != nil {
-		return nil, nil, fmt.Errorf("unable to create category column: %w", err)
+		return fmt.Errorf("failed to get app list: %w", err)
 	}
-	treeView.AppendColumn(categoryColumn)
-
-	// Handle category editing
-	categoryRenderer.Connect("edited", func(renderer *gtk.CellRendererText, pathStr string, newText string) {
-		path, err := gtk.TreePathNewFromString(pathStr)
-		if err != nil {
-			return
-		}
 
-		iter, err := listStore.GetIter(path)
-		if err != nil {
-			return
+	// Check if app exists
+	appExists := false
+	for _, existingApp := range apps {
+		if existingApp == app {
+			appExists = true
+			break
 		}
-
-		// Update the category in the model
-		listStore.SetValue(iter, 2, newText)
-	})
-
-	return treeView, listStore, nil
-}
-
-// populateCategoryList adds apps and their categories to the list store
-func populateCategoryList(listStore *gtk.ListStore, data *CategoryData, apps []string) {
-	piAppsDir := GetPiAppsDir()
-	if piAppsDir == "" {
-		return
 	}
-
-	for _, app := range apps {
-		iter := listStore.Append()
-
-		// Load app icon
-		var appPixbuf *gdk.Pixbuf
-		iconPath := filepath.Join(piAppsDir, "apps", app, "icon-24.png")
-		if FileExists(iconPath) {
-			appPixbuf, _ = gdk.PixbufNewFromFile(iconPath)
-		}
-
-		// Get current category
-		category := data.GetAppCategory(app)
-
-		// Set values
-		if appPixbuf != nil {
-			listStore.SetValue(iter, 0, appPixbuf)
-		}
-		listStore.SetValue(iter, 1, app)
-		listStore.SetValue(iter, 2, category)
+	if !appExists {
+		return fmt.Errorf("the '%s' app does not exist", app)
 	}
-}
 
-// extractCategoryData extracts the modified category data from the tree view
-func extractCategoryData(listStore *gtk.ListStore, originalData *CategoryData) *CategoryData {
-	newData := &CategoryData{
-		GlobalCategories: make(map[string]string),
-		LocalCategories:  make(map[string]string),
+	// Read category data
+	data, err := ReadCategoryData()
+	if err != nil {
+		return fmt.Errorf("failed to read category data: %w", err)
 	}
 
-	// Copy global categories
-	for app, category := range originalData.GlobalCategories {
-		newData.GlobalCategories[app] = category
+	// Set the category
+	if pin {
+		data.LocalCategories[app] = category
+	} else {
+		data.SetAppCategory(app, category)
 	}
 
-	// Extract categories from the tree view
-	iter, valid := listStore.GetIterFirst()
-	for valid {
-		// Get app name
-		appVal, err := listStore.GetValue(iter, 1)
-		if err != nil {
-			valid = listStore.IterNext(iter)
-			continue
-		}
-		appInterface, err := appVal.GoValue()
-		if err != nil {
-			valid = listStore.IterNext(iter)
-			continue
-		}
-
-		var app string
-		switch appValue := appInterface.(type) {
-		case string:
-			app = appValue
-		default:
-			valid = listStore.IterNext(iter)
-			continue
-		}
-
-		// Get category
-		categoryVal, err := listStore.GetValue(iter, 2)
-		if err != nil {
-			valid = listStore.IterNext(iter)
-			continue
-		}
-		categoryInterface, err := categoryVal.GoValue()
-		if err != nil {
-			valid = listStore.IterNext(iter)
-			continue
-		}
-
-		var category string
-		switch categoryValue := categoryInterface.(type) {
-		case string:
-			category = categoryValue
-		default:
-			category = ""
-		}
-
-		// Set the category in new data
-		newData.SetAppCategory(app, category)
-
-		valid = listStore.IterNext(iter)
+	// Save changes
+	if err := data.SaveLocalCategories(); err != nil {
+		return fmt.Errorf("failed to save category changes: %w", err)
 	}
 
-	return newData
-}
-
-// setButtonIcon sets an icon for a button if the icon file exists
-func setButtonIcon(button *gtk.Button, iconPath string) {
-	if FileExists(iconPath) {
-		icon, err := gtk.ImageNewFromFile(iconPath)
-		if err == nil {
-			button.SetImage(icon)
-			button.SetAlwaysShowImage(true)
-		}
-	}
+	return nil
 }
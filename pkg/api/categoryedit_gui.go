@@ -0,0 +1,395 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: categoryedit_gui.go
+// Description: Provides the GTK category editor.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+//go:build !nogui
+
+package api
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gotk3/gotk3/gdk"
+	"github.com/gotk3/gotk3/glib"
+	"github.com/gotk3/gotk3/gtk"
+)
+
+// ShowCategoryEditor displays the category editor GUI
+func ShowCategoryEditor() error {
+	return showCategoryEditorGUI()
+}
+
+// showCategoryEditorGUI displays the category editor using GTK
+func showCategoryEditorGUI() error {
+	piAppsDir := GetPiAppsDir()
+	if piAppsDir == "" {
+		return fmt.Errorf("PI_APPS_DIR environment variable not set")
+	}
+
+	// Initialize GTK
+	glib.SetPrgname("Category editor")
+	gtk.Init(nil)
+
+	for {
+		// Read current category data
+		data, err := ReadCategoryData()
+		if err != nil {
+			return fmt.Errorf("failed to read category data: %w", err)
+		}
+
+		// Get list of apps
+		apps, err := ListApps("local")
+		if err != nil {
+			return fmt.Errorf("failed to get app list: %w", err)
+		}
+
+		// Show the dialog
+		action, newData, err := showCategoryDialog(data, apps)
+		if err != nil {
+			return fmt.Errorf("failed to show category dialog: %w", err)
+		}
+
+		switch action {
+		case "save":
+			// Apply changes from the dialog
+			*data = *newData
+			if err := data.SaveLocalCategories(); err != nil {
+				showErrorDialog("Failed to save category changes: " + err.Error())
+				continue
+			}
+
+			// Refresh app list in background
+			go func() {
+				_ = RefreshAppList()
+			}()
+
+			return nil
+
+		case "reset":
+			// Reset to global categories
+			data.ResetToGlobalCategories()
+			continue // Show dialog again with reset data
+
+		case "clear":
+			// Clear all categories
+			data.ClearAllCategories()
+			continue // Show dialog again with cleared data
+
+		case "cancel":
+			return nil // Exit without saving
+
+		default:
+			return nil // Exit
+		}
+	}
+}
+
+// showCategoryDialog shows the main category editing dialog
+func showCategoryDialog(data *CategoryData, apps []string) (string, *CategoryData, error) {
+	piAppsDir := GetPiAppsDir()
+	if piAppsDir == "" {
+		return "", nil, fmt.Errorf("PI_APPS_DIR environment variable not set")
+	}
+
+	// Create main dialog
+	dialog, err := gtk.DialogNew()
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create dialog: %w", err)
+	}
+	defer dialog.Destroy()
+
+	dialog.SetTitle("Category editor")
+	dialog.SetDefaultSize(600, 400)
+	dialog.SetPosition(gtk.WIN_POS_CENTER)
+	dialog.SetModal(true)
+
+	// Set window icon
+	iconPath := filepath.Join(piAppsDir, "icons", "settings.png")
+	if FileExists(iconPath) {
+		pixbuf, err := gdk.PixbufNewFromFile(iconPath)
+		if err == nil {
+			dialog.SetIcon(pixbuf)
+		}
+	}
+
+	// Get content area
+	contentArea, err := dialog.GetContentArea()
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to get content area: %w", err)
+	}
+
+	// Create header label
+	headerText := "Changes saved to: " + strings.Replace(filepath.Join(DataDirOf(piAppsDir), "category-overrides"), os.Getenv("HOME"), "~", 1)
+	headerLabel, err := gtk.LabelNew(headerText)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create header label: %w", err)
+	}
+	headerLabel.SetHAlign(gtk.ALIGN_START)
+	contentArea.PackStart(headerLabel, false, false, 8)
+
+	// Create scrolled window
+	scrolledWindow, err := gtk.ScrolledWindowNew(nil, nil)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create scrolled window: %w", err)
+	}
+	scrolledWindow.SetPolicy(gtk.POLICY_AUTOMATIC, gtk.POLICY_AUTOMATIC)
+	scrolledWindow.SetShadowType(gtk.SHADOW_IN)
+	contentArea.PackStart(scrolledWindow, true, true, 0)
+
+	// Create tree view and model
+	treeView, listStore, err := createCategoryTreeView()
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create tree view: %w", err)
+	}
+	scrolledWindow.Add(treeView)
+
+	// Populate the list with apps and their categories
+	populateCategoryList(listStore, data, apps)
+
+	// Create buttons manually so we have direct access to them
+	resetBtn, err := gtk.ButtonNewWithLabel("Reset")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create reset button: %w", err)
+	}
+	setButtonIcon(resetBtn, filepath.Join(piAppsDir, "icons", "backup.png"))
+	resetBtn.SetTooltipText("Removes all category overrides.")
+	dialog.AddActionWidget(resetBtn, 4)
+
+	allBtn, err := gtk.ButtonNewWithLabel("All")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create all button: %w", err)
+	}
+	setButtonIcon(allBtn, filepath.Join(piAppsDir, "icons", "trash.png"))
+	allBtn.SetTooltipText("Clears categories so all apps are in one list.")
+	dialog.AddActionWidget(allBtn, 2)
+
+	cancelBtn, err := gtk.ButtonNewWithLabel("Cancel")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create cancel button: %w", err)
+	}
+	setButtonIcon(cancelBtn, filepath.Join(piAppsDir, "icons", "exit.png"))
+	cancelBtn.SetTooltipText("Don't save any changes.")
+	dialog.AddActionWidget(cancelBtn, gtk.RESPONSE_CANCEL)
+
+	saveBtn, err := gtk.ButtonNewWithLabel("Save")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create save button: %w", err)
+	}
+	setButtonIcon(saveBtn, filepath.Join(piAppsDir, "icons", "check.png"))
+	dialog.AddActionWidget(saveBtn, gtk.RESPONSE_OK)
+
+	// Show all widgets
+	dialog.ShowAll()
+
+	// Run dialog
+	response := dialog.Run()
+
+	// Extract modified data from the tree view
+	newData := extractCategoryData(listStore, data)
+
+	switch response {
+	case gtk.RESPONSE_OK:
+		return "save", newData, nil
+	case 2:
+		return "clear", newData, nil
+	case 4:
+		return "reset", newData, nil
+	default:
+		return "cancel", newData, nil
+	}
+}
+
+// createCategoryTreeView creates and configures the tree view for displaying apps and categories
+func createCategoryTreeView() (*gtk.TreeView, *gtk.ListStore, error) {
+	// Create list store with columns: Icon(pixbuf), Name(string), Category(string)
+	listStore, err := gtk.ListStoreNew(gdk.PixbufGetType(), glib.TYPE_STRING, glib.TYPE_STRING)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to create list store: %w", err)
+	}
+
+	// Create tree view
+	treeView, err := gtk.TreeViewNewWithModel(listStore)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to create tree view: %w", err)
+	}
+
+	// Create icon column
+	iconRenderer, err := gtk.CellRendererPixbufNew()
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to create icon renderer: %w", err)
+	}
+	iconColumn, err := gtk.TreeViewColumnNew()
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to create icon column: %w", err)
+	}
+	iconColumn.PackStart(iconRenderer, false)
+	iconColumn.AddAttribute(iconRenderer, "pixbuf", 0)
+	iconColumn.SetSizing(gtk.TREE_VIEW_COLUMN_FIXED)
+	iconColumn.SetFixedWidth(30)
+	treeView.AppendColumn(iconColumn)
+
+	// Create name column
+	nameRenderer, err := gtk.CellRendererTextNew()
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to create name renderer: %w", err)
+	}
+	nameColumn, err := gtk.TreeViewColumnNewWithAttribute("Name", nameRenderer, "text", 1)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to create name column: %w", err)
+	}
+	treeView.AppendColumn(nameColumn)
+
+	// Create category column (editable)
+	categoryRenderer, err := gtk.CellRendererTextNew()
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to create category renderer: %w", err)
+	}
+	categoryRenderer.SetProperty("editable", true)
+	categoryColumn, err := gtk.TreeViewColumnNewWithAttribute("Category", categoryRenderer, "text", 2)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to create category column: %w", err)
+	}
+	treeView.AppendColumn(categoryColumn)
+
+	// Handle category editing
+	categoryRenderer.Connect("edited", func(renderer *gtk.CellRendererText, pathStr string, newText string) {
+		path, err := gtk.TreePathNewFromString(pathStr)
+		if err != nil {
+			return
+		}
+
+		iter, err := listStore.GetIter(path)
+		if err != nil {
+			return
+		}
+
+		// Update the category in the model
+		listStore.SetValue(iter, 2, newText)
+	})
+
+	return treeView, listStore, nil
+}
+
+// populateCategoryList adds apps and their categories to the list store
+func populateCategoryList(listStore *gtk.ListStore, data *CategoryData, apps []string) {
+	piAppsDir := GetPiAppsDir()
+	if piAppsDir == "" {
+		return
+	}
+
+	for _, app := range apps {
+		iter := listStore.Append()
+
+		// Load app icon
+		var appPixbuf *gdk.Pixbuf
+		iconPath := filepath.Join(piAppsDir, "apps", app, "icon-24.png")
+		if FileExists(iconPath) {
+			appPixbuf, _ = gdk.PixbufNewFromFile(iconPath)
+		}
+
+		// Get current category
+		category := data.GetAppCategory(app)
+
+		// Set values
+		if appPixbuf != nil {
+			listStore.SetValue(iter, 0, appPixbuf)
+		}
+		listStore.SetValue(iter, 1, app)
+		listStore.SetValue(iter, 2, category)
+	}
+}
+
+// extractCategoryData extracts the modified category data from the tree view
+func extractCategoryData(listStore *gtk.ListStore, originalData *CategoryData) *CategoryData {
+	newData := &CategoryData{
+		GlobalCategories: make(map[string]string),
+		LocalCategories:  make(map[string]string),
+	}
+
+	// Copy global categories
+	for app, category := range originalData.GlobalCategories {
+		newData.GlobalCategories[app] = category
+	}
+
+	// Extract categories from the tree view
+	iter, valid := listStore.GetIterFirst()
+	for valid {
+		// Get app name
+		appVal, err := listStore.GetValue(iter, 1)
+		if err != nil {
+			valid = listStore.IterNext(iter)
+			continue
+		}
+		appInterface, err := appVal.GoValue()
+		if err != nil {
+			valid = listStore.IterNext(iter)
+			continue
+		}
+
+		var app string
+		switch appValue := appInterface.(type) {
+		case string:
+			app = appValue
+		default:
+			valid = listStore.IterNext(iter)
+			continue
+		}
+
+		// Get category
+		categoryVal, err := listStore.GetValue(iter, 2)
+		if err != nil {
+			valid = listStore.IterNext(iter)
+			continue
+		}
+		categoryInterface, err := categoryVal.GoValue()
+		if err != nil {
+			valid = listStore.IterNext(iter)
+			continue
+		}
+
+		var category string
+		switch categoryValue := categoryInterface.(type) {
+		case string:
+			category = categoryValue
+		default:
+			category = ""
+		}
+
+		// Set the category in new data
+		newData.SetAppCategory(app, category)
+
+		valid = listStore.IterNext(iter)
+	}
+
+	return newData
+}
+
+// setButtonIcon sets an icon for a button if the icon file exists
+func setButtonIcon(button *gtk.Button, iconPath string) {
+	if FileExists(iconPath) {
+		icon, err := gtk.ImageNewFromFile(iconPath)
+		if err == nil {
+			button.SetImage(icon)
+			button.SetAlwaysShowImage(true)
+		}
+	}
+}
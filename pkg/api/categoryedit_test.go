@@ -0,0 +1,139 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// newTestCategoryDir creates a Pi-Apps directory with apps that have an install script, as only those are listed
+func newTestCategoryDir(t *testing.T, apps ...string) string {
+	t.Helper()
+	directory := newTestPiAppsDir(t, apps...)
+	for _, app := range apps {
+		writeTestFile(t, filepath.Join(directory, "apps", app, "install"), "#!/bin/bash\n")
+	}
+	return directory
+}
+
+func TestReadCategoryFilesConflicts(t *testing.T) {
+	directory := newTestCategoryDir(t, "Audacity", "AbiWord", "Brave", "My App")
+	overrides := filepath.Join(directory, "data", "category-overrides")
+	writeTestFile(t, overrides, "Audacity|Games\nAbiWord|hidden\nGone|Games\nAudacity|Office\n")
+	writeTestFile(t, filepath.Join(directory, "data", "categories", "Beta"), "My App\n")
+	writeTestFile(t, filepath.Join(directory, "data", "categories", "Alpha"), "My App\nBrave\n")
+
+	first, err := ReadCategoryFiles(directory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"Audacity|Office",         // the last override line wins
+		"AbiWord|hidden",          // overrides win over the global list
+		"Brave|Internet/Browsers", // the global list wins over data/categories
+		"My App|Alpha",            // the first data/categories file by name wins
+	} {
+		if !slices.Contains(first, want) {
+			t.Errorf("ReadCategoryFiles has no %q", want)
+		}
+	}
+	if slices.ContainsFunc(first, func(entry string) bool { return strings.HasPrefix(entry, "Gone|") }) {
+		t.Error("the override of an app that doesn't exist was kept")
+	}
+	if content, _ := os.ReadFile(overrides); strings.Contains(string(content), "Gone") {
+		t.Errorf("the override of an app that doesn't exist was not removed:\n%s", content)
+	}
+
+	// categoryedit reads the overrides the same way
+	data, err := ReadCategoryData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if category := data.GetAppCategory("Audacity"); category != "Office" {
+		t.Errorf("ReadCategoryData category of Audacity = %q, want Office", category)
+	}
+
+	again, err := ReadCategoryFiles(directory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(first, again) {
+		t.Errorf("ReadCategoryFiles changed between calls:\n%v\n%v", first, again)
+	}
+}
+
+func TestEditAppCategory(t *testing.T) {
+	directory := newTestCategoryDir(t, "Audacity", "AbiWord", "Brave")
+	writeTestFile(t, filepath.Join(directory, "data", "categories", "Alpha"), "Brave\n")
+
+	err := EditAppCategory("Audacity", "Game", false)
+	if err == nil || !strings.Contains(err.Error(), "Games") {
+		t.Errorf("EditAppCategory accepted an unknown category or didn't suggest Games: %v", err)
+	}
+	if err := ValidateCategory(directory, "Alpha", false); err != nil {
+		t.Errorf("a category of data/categories is not official: %v", err)
+	}
+	if err := EditAppCategory("Audacity", "Game", true); err != nil {
+		t.Fatal(err)
+	}
+	counts, err := CategoryCounts(directory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Contains(counts, CategoryCount{Category: "Game", Apps: 1}) {
+		t.Errorf("CategoryCounts has no created Game category with one app: %v", counts)
+	}
+	if !slices.Contains(counts, CategoryCount{Category: "Office", Apps: 1, Official: true}) {
+		t.Errorf("CategoryCounts has no Office category with AbiWord: %v", counts)
+	}
+
+	// A choice matching the upstream category is kept, so an upstream change doesn't undo it
+	if err := EditAppCategory("AbiWord", "Office", false); err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(filepath.Join(directory, "data", "category-overrides"))
+	if err != nil || !strings.Contains(string(content), "AbiWord|Office\n") {
+		t.Errorf("the category of AbiWord was not kept in the overrides: %q, %v", content, err)
+	}
+
+	// Hidden apps leave the app browser but can still be installed and updated
+	if err := EditAppCategory("Brave", HiddenCategory, false); err != nil {
+		t.Fatal(err)
+	}
+	hidden, err := ListApps("hidden")
+	if err != nil || !slices.Contains(hidden, "Brave") {
+		t.Errorf("ListApps(hidden) = %v, %v, want Brave", hidden, err)
+	}
+	browser, err := AppPrefixCategory(directory, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if slices.ContainsFunc(browser, func(entry string) bool { return strings.HasSuffix(entry, "/Brave") }) {
+		t.Errorf("the hidden app is still in the app browser: %v", browser)
+	}
+	if local, err := ListApps("local"); err != nil || !slices.Contains(local, "Brave") {
+		t.Errorf("ListApps(local) = %v, %v, want the hidden app", local, err)
+	}
+
+	if err := EditAppCategory("Brave", UnlistedCategory, false); err != nil {
+		t.Errorf("EditAppCategory rejected removing the category: %v", err)
+	}
+}
@@ -0,0 +1,412 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: chmod.go
+// Description: Implements the chmod command of app scripts natively, with octal and symbolic modes,
+// recursion limited to the Pi-Apps folder and the home folder, and a --system escape hatch.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package api
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// ChmodOptions controls how ChmodPaths walks and filters the paths it is given
+type ChmodOptions struct {
+	Recursive bool
+	// FilesOnly and DirsOnly limit the change to one kind of path, both false changes everything
+	FilesOnly bool
+	DirsOnly  bool
+	// System allows recursive changes outside the Pi-Apps folder and the home folder.
+	// The change is then made as root through SudoPopup.
+	System bool
+}
+
+// chmodClause is one operation of a symbolic mode, like the "+x" of "u+x"
+type chmodClause struct {
+	who   uint32 // mask of the bits the clause may touch
+	op    byte   // '+', '-' or '='
+	perms string // any of "rwxXst"
+	copy  byte   // 'u', 'g' or 'o' when the clause copies another class, like "g=u"
+	umask uint32 // bits the clause doesn't set or clear because no user class was given, like the "+x" of "+x"
+}
+
+// chmodMode is a parsed mode, either an absolute octal one or a list of symbolic clauses
+type chmodMode struct {
+	octal   bool
+	value   uint32
+	digits  int
+	clauses []chmodClause
+}
+
+// chmodWhoBits maps the user classes of a symbolic mode to the bits they own.
+// The setuid, setgid and sticky bits belong to the user, group and others classes.
+var chmodWhoBits = map[byte]uint32{
+	'u': 04700,
+	'g': 02070,
+	'o': 01007,
+	'a': 07777,
+}
+
+// chmodUmask returns the umask of the process
+var chmodUmask = func() uint32 {
+	// Reading the umask means setting it, so set it straight back
+	umask := syscall.Umask(0)
+	syscall.Umask(umask)
+	return uint32(umask)
+}
+
+// parseChmodMode parses an octal mode like "755" or a symbolic one like "u+x,go-w" or "a=rX"
+func parseChmodMode(modeStr string) (chmodMode, error) {
+	if modeStr == "" {
+		return chmodMode{}, fmt.Errorf("empty mode")
+	}
+	if strings.Trim(modeStr, "01234567") == "" {
+		if len(modeStr) > 4 {
+			return chmodMode{}, fmt.Errorf("octal mode out of range: %s", modeStr)
+		}
+		value, err := strconv.ParseUint(modeStr, 8, 32)
+		if err != nil {
+			return chmodMode{}, err
+		}
+		return chmodMode{octal: true, value: uint32(value), digits: len(modeStr)}, nil
+	}
+
+	var mode chmodMode
+	for _, part := range strings.Split(modeStr, ",") {
+		// who is optional, then one or more operators each followed by its permissions
+		i := 0
+		var who, umask uint32
+		for i < len(part) && strings.IndexByte("ugoa", part[i]) >= 0 {
+			who |= chmodWhoBits[part[i]]
+			i++
+		}
+		if who == 0 {
+			// Like chmod, leaving out the user class means all of them minus the bits of the umask
+			who = chmodWhoBits['a']
+			umask = chmodUmask()
+		}
+		if i == len(part) {
+			return chmodMode{}, fmt.Errorf("missing operator in %q", part)
+		}
+		for i < len(part) {
+			op := part[i]
+			if op != '+' && op != '-' && op != '=' {
+				return chmodMode{}, fmt.Errorf("invalid operator %q in %q", op, part)
+			}
+			i++
+			clause := chmodClause{who: who, op: op, umask: umask}
+			if i < len(part) && strings.IndexByte("ugo", part[i]) >= 0 {
+				clause.copy = part[i]
+				i++
+			} else {
+				start := i
+				for i < len(part) && strings.IndexByte("rwxXst", part[i]) >= 0 {
+					i++
+				}
+				clause.perms = part[start:i]
+			}
+			if i < len(part) && strings.IndexByte("+-=", part[i]) < 0 {
+				return chmodMode{}, fmt.Errorf("invalid permission %q in %q", part[i], part)
+			}
+			mode.clauses = append(mode.clauses, clause)
+		}
+	}
+	return mode, nil
+}
+
+// apply returns the permission bits a path with the old bits ends up with
+func (m chmodMode) apply(old uint32, isDir bool) uint32 {
+	if m.octal {
+		// Like coreutils, a short octal mode keeps the setuid and setgid bits of a directory
+		if isDir && m.digits <= 3 {
+			return m.value | old&06000
+		}
+		return m.value
+	}
+
+	mode := old
+	for _, clause := range m.clauses {
+		var bits uint32
+		if clause.copy != 0 {
+			var class uint32
+			switch clause.copy {
+			case 'u':
+				class = mode >> 6 & 7
+			case 'g':
+				class = mode >> 3 & 7
+			case 'o':
+				class = mode & 7
+			}
+			bits = class<<6 | class<<3 | class
+		}
+		for _, perm := range clause.perms {
+			switch perm {
+			case 'r':
+				bits |= 0444
+			case 'w':
+				bits |= 0222
+			case 'x':
+				bits |= 0111
+			case 'X':
+				// Execute only for directories and for files someone can already execute
+				if isDir || mode&0111 != 0 {
+					bits |= 0111
+				}
+			case 's':
+				bits |= 06000
+			case 't':
+				bits |= 01000
+			}
+		}
+		bits &= clause.who &^ clause.umask
+
+		switch clause.op {
+		case '+':
+			mode |= bits
+		case '-':
+			mode &^= bits
+		case '=':
+			cleared := clause.who
+			if isDir {
+				// Unmentioned setuid and setgid bits of a directory are left alone
+				cleared &^= 06000 &^ bits
+			}
+			mode = mode&^cleared | bits
+		}
+	}
+	return mode
+}
+
+// unixModeBits converts an os.FileMode to the 12 permission bits chmod works with
+func unixModeBits(mode os.FileMode) uint32 {
+	bits := uint32(mode.Perm())
+	if mode&os.ModeSetuid != 0 {
+		bits |= 04000
+	}
+	if mode&os.ModeSetgid != 0 {
+		bits |= 02000
+	}
+	if mode&os.ModeSticky != 0 {
+		bits |= 01000
+	}
+	return bits
+}
+
+// fileModeFromUnix converts chmod permission bits back to an os.FileMode
+func fileModeFromUnix(bits uint32) os.FileMode {
+	mode := os.FileMode(bits & 0777)
+	if bits&04000 != 0 {
+		mode |= os.ModeSetuid
+	}
+	if bits&02000 != 0 {
+		mode |= os.ModeSetgid
+	}
+	if bits&01000 != 0 {
+		mode |= os.ModeSticky
+	}
+	return mode
+}
+
+// checkChmodTarget refuses recursive changes on / and, without --system, outside the Pi-Apps folder and the home folder
+func checkChmodTarget(path string, options ChmodOptions) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	// A symlink in the home folder must not lead the walk somewhere else
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+		abs = resolved
+	}
+	if abs == "/" {
+		return fmt.Errorf("refusing to change permissions recursively on /")
+	}
+	if options.System {
+		return nil
+	}
+
+	var roots []string
+	if dir := GetPiAppsDir(); dir != "" {
+		roots = append(roots, dir)
+	}
+	if home, err := os.UserHomeDir(); err == nil && home != "/" {
+		roots = append(roots, home)
+	}
+	for _, root := range roots {
+		if resolved, err := filepath.EvalSymlinks(root); err == nil {
+			root = resolved
+		}
+		if rel, err := filepath.Rel(root, abs); err == nil && rel != ".." && !strings.HasPrefix(rel, "../") {
+			return nil
+		}
+	}
+	return fmt.Errorf("refusing to change permissions recursively outside the Pi-Apps folder and the home folder: %s (use --system to do it as root)", path)
+}
+
+// chmodOne changes the mode of one path if it passes the type filter, and logs the change in debug mode.
+// It returns the mode the path has afterwards, and false if the type filter skipped it.
+func chmodOne(path string, mode chmodMode, options ChmodOptions) (uint32, bool, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, false, err
+	}
+	if (options.FilesOnly && info.IsDir()) || (options.DirsOnly && !info.IsDir()) {
+		return 0, false, nil
+	}
+	old := unixModeBits(info.Mode())
+	changed := mode.apply(old, info.IsDir())
+	if changed == old {
+		return old, true, nil
+	}
+	if err := os.Chmod(path, fileModeFromUnix(changed)); err != nil {
+		return 0, false, err
+	}
+	Debug(fmt.Sprintf("chmod: %s: %04o -> %04o", path, old, changed))
+	return changed, true, nil
+}
+
+// ChmodPaths changes the mode of each path, walking directories when options.Recursive is set.
+// Symlinks found while walking are skipped, like chmod -R does.
+func ChmodPaths(modeStr string, paths []string, options ChmodOptions) error {
+	if options.FilesOnly && options.DirsOnly {
+		return fmt.Errorf("chmod: --files-only and --dirs-only can't be used together")
+	}
+	mode, err := parseChmodMode(modeStr)
+	if err != nil {
+		return fmt.Errorf("chmod: invalid mode: %s: %v", modeStr, err)
+	}
+	if len(paths) == 0 {
+		return fmt.Errorf("chmod: missing operand after %s", modeStr)
+	}
+	if options.Recursive {
+		for _, path := range paths {
+			if err := checkChmodTarget(path, options); err != nil {
+				return fmt.Errorf("chmod: %v", err)
+			}
+		}
+	}
+	if options.System {
+		return chmodAsRoot(modeStr, paths, options)
+	}
+
+	for _, path := range paths {
+		if !options.Recursive {
+			bits, ok, err := chmodOne(path, mode, options)
+			if err != nil {
+				return err
+			}
+			if ok {
+				Status(fmt.Sprintf("Mode of %s: %04o (%s)", path, bits, modeStr))
+			}
+			continue
+		}
+
+		Status("Changing permissions recursively: " + path)
+		err := filepath.WalkDir(path, func(walked string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if walked != path && entry.Type()&fs.ModeSymlink != 0 {
+				return nil
+			}
+			_, _, err = chmodOne(walked, mode, options)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// chmodAsRoot makes the change with the system chmod through SudoPopup, using find for the type filters
+func chmodAsRoot(modeStr string, paths []string, options ChmodOptions) error {
+	absPaths := make([]string, 0, len(paths))
+	for _, path := range paths {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return err
+		}
+		absPaths = append(absPaths, abs)
+	}
+
+	chmodArgs := []string{}
+	if GetDebugMode() {
+		chmodArgs = append(chmodArgs, "-c")
+	}
+
+	if !options.FilesOnly && !options.DirsOnly {
+		if options.Recursive {
+			chmodArgs = append(chmodArgs, "-R")
+		}
+		chmodArgs = append(chmodArgs, "--", modeStr)
+		return SudoPopup("chmod", append(chmodArgs, absPaths...)...)
+	}
+
+	findArgs := append([]string{}, absPaths...)
+	if !options.Recursive {
+		findArgs = append(findArgs, "-maxdepth", "0")
+	}
+	fileType := "f"
+	if options.DirsOnly {
+		fileType = "d"
+	}
+	findArgs = append(findArgs, "-type", fileType, "-exec", "chmod")
+	findArgs = append(findArgs, chmodArgs...)
+	findArgs = append(findArgs, "--", modeStr, "{}", "+")
+	return SudoPopup("find", findArgs...)
+}
+
+// ChmodWithArgs wraps ChmodPaths to handle command-line style arguments:
+// [-R|--recursive] [--files-only|--dirs-only] [--system] <mode> <file>...
+func ChmodWithArgs(args ...string) error {
+	var options ChmodOptions
+	var rest []string
+	flagsDone := false
+	for _, arg := range args {
+		if flagsDone || len(rest) > 0 {
+			rest = append(rest, arg)
+			continue
+		}
+		switch arg {
+		case "-R", "--recursive":
+			options.Recursive = true
+		case "--files-only":
+			options.FilesOnly = true
+		case "--dirs-only":
+			options.DirsOnly = true
+		case "--system":
+			options.System = true
+		case "--":
+			flagsDone = true
+		default:
+			// Anything else, including modes like "-x", starts the mode and file list
+			rest = append(rest, arg)
+		}
+	}
+
+	if len(rest) < 2 {
+		return fmt.Errorf("chmod: missing operand")
+	}
+	return ChmodPaths(rest[0], rest[1:], options)
+}
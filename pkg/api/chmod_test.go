@@ -0,0 +1,101 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseChmodMode(t *testing.T) {
+	umask := chmodUmask
+	chmodUmask = func() uint32 { return 022 }
+	t.Cleanup(func() { chmodUmask = umask })
+
+	tests := []struct {
+		mode  string
+		old   uint32
+		isDir bool
+		want  uint32
+	}{
+		{"755", 0644, false, 0755},
+		{"0600", 0644, false, 0600},
+		{"4755", 0644, false, 04755},
+		{"755", 02775, true, 02755},
+		{"0755", 02775, true, 0755},
+		{"u+x", 0644, false, 0744},
+		{"+x", 0644, false, 0755},
+		{"+w", 0444, false, 0644},
+		{"a+w", 0444, false, 0666},
+		{"-w", 0666, false, 0466},
+		{"=r", 0777, false, 0444},
+		{"go-w", 0666, false, 0644},
+		{"u+x,go-w", 0666, false, 0744},
+		{"a=rX", 0644, false, 0444},
+		{"a=rX", 0744, false, 0555},
+		{"a=rX", 0700, true, 0555},
+		{"g=u", 0740, false, 0770},
+		{"o=g", 0750, false, 0755},
+		{"u+s", 0755, false, 04755},
+		{"g-s", 02755, true, 0755},
+		{"a=rwx", 02755, true, 02777},
+		{"+t", 0777, true, 01777},
+		{"u+x-w", 0644, false, 0544},
+	}
+	for _, tt := range tests {
+		mode, err := parseChmodMode(tt.mode)
+		if err != nil {
+			t.Errorf("parseChmodMode(%q) failed: %v", tt.mode, err)
+			continue
+		}
+		if got := mode.apply(tt.old, tt.isDir); got != tt.want {
+			t.Errorf("%q on %04o (directory: %v) = %04o, want %04o", tt.mode, tt.old, tt.isDir, got, tt.want)
+		}
+	}
+}
+
+func TestParseChmodModeInvalid(t *testing.T) {
+	for _, mode := range []string{"", "8", "77777", "u", "u*x", "u+q", "+x,", "a=rw!"} {
+		if _, err := parseChmodMode(mode); err == nil {
+			t.Errorf("parseChmodMode(%q) succeeded, want an error", mode)
+		}
+	}
+}
+
+func TestChmodWithArgs(t *testing.T) {
+	umask := chmodUmask
+	chmodUmask = func() uint32 { return 077 }
+	t.Cleanup(func() { chmodUmask = umask })
+
+	dir := newTestPiAppsDir(t)
+	script := filepath.Join(dir, "script.sh")
+	writeTestFile(t, script, "#!/bin/bash\n")
+	if err := os.Chmod(script, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ChmodWithArgs("+x", script); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(script)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := info.Mode().Perm(); got != 0744 {
+		t.Errorf("+x with umask 077 gave %04o, want 0744", got)
+	}
+}
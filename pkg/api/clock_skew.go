@@ -0,0 +1,197 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: clock_skew.go
+// Description: Checks the system clock against the time of a web server before downloads, as a wrong clock makes
+// TLS certificates and package lists look invalid.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package api
+
+import (
+	"bufio"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/term"
+)
+
+const (
+	// clockSkewTolerance is how far the clock may be off before it's warned about. apt already rejects package
+	// lists from a few minutes in the future, TLS certificates only fail once the clock is off by days.
+	clockSkewTolerance = 5 * time.Minute
+	// clockCheckTimeout limits each request for the time
+	clockCheckTimeout = 5 * time.Second
+)
+
+// clockCheckURLs are asked for the time in order, the next one is the fallback when one doesn't answer
+var clockCheckURLs = []string{"https://github.com", "https://www.cloudflare.com"}
+
+// ClockSkew is how far the system clock is off from the time a web server sent
+type ClockSkew struct {
+	Offset    time.Duration `json:"offset"` // the system clock minus the server time, positive when the clock is ahead
+	Host      string        `json:"host"`
+	CheckedAt time.Time     `json:"checked_at"`
+}
+
+// Skewed reports whether the clock is off by more than the tolerance
+func (c ClockSkew) Skewed() bool {
+	return c.Offset > clockSkewTolerance || c.Offset < -clockSkewTolerance
+}
+
+// String describes the offset for logs, like "3h5m0s ahead of github.com"
+func (c ClockSkew) String() string {
+	switch {
+	case !c.Skewed():
+		return fmt.Sprintf("in sync with %s", c.Host)
+	case c.Offset > 0:
+		return fmt.Sprintf("%s ahead of %s", c.Offset, c.Host)
+	default:
+		return fmt.Sprintf("%s behind %s", -c.Offset, c.Host)
+	}
+}
+
+// clockSkewCache keeps the result of the first check for the rest of the process
+var clockSkewCache struct {
+	sync.Mutex
+	checked bool
+	warned  bool
+	skew    ClockSkew
+	err     error
+}
+
+// CheckClockSkew compares the system clock with the Date header of an HTTPS HEAD request, trying the fallback host
+// when the first one doesn't answer. The result is cached for the rest of the process.
+func CheckClockSkew() (ClockSkew, error) {
+	clockSkewCache.Lock()
+	defer clockSkewCache.Unlock()
+	if !clockSkewCache.checked {
+		clockSkewCache.skew, clockSkewCache.err = measureClockSkew(clockCheckURLs)
+		clockSkewCache.checked = true
+	}
+	return clockSkewCache.skew, clockSkewCache.err
+}
+
+// measureClockSkew returns the clock skew against the first of the URLs that answers
+func measureClockSkew(urls []string) (ClockSkew, error) {
+	if len(urls) == 0 {
+		return ClockSkew{}, errors.New("no server to ask for the time")
+	}
+	var failures []error
+	for _, rawURL := range urls {
+		skew, err := clockSkewFrom(rawURL, false)
+		var certErr x509.CertificateInvalidError
+		if errors.As(err, &certErr) && certErr.Reason == x509.Expired {
+			// To a clock that is far off every certificate looks expired or not valid yet, the Date header
+			// still tells by how much
+			skew, err = clockSkewFrom(rawURL, true)
+		}
+		if err == nil {
+			return skew, nil
+		}
+		failures = append(failures, err)
+	}
+	return ClockSkew{}, errors.Join(failures...)
+}
+
+// clockSkewFrom sends a HEAD request to rawURL and compares its Date header with the system clock
+func clockSkewFrom(rawURL string, skipVerify bool) (ClockSkew, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return ClockSkew{}, err
+	}
+	client := &http.Client{Timeout: clockCheckTimeout}
+	if skipVerify {
+		client.Transport = &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}
+	}
+
+	start := time.Now()
+	resp, err := client.Head(rawURL)
+	if err != nil {
+		return ClockSkew{}, err
+	}
+	resp.Body.Close()
+	end := time.Now()
+
+	serverTime, err := http.ParseTime(resp.Header.Get("Date"))
+	if err != nil {
+		return ClockSkew{}, fmt.Errorf("%s sent no valid Date header", parsed.Host)
+	}
+	// The server read its clock somewhere during the request and cut off the fraction of the second
+	local := start.Add(end.Sub(start) / 2)
+	offset := local.Sub(serverTime.Add(500 * time.Millisecond))
+	return ClockSkew{Offset: offset.Round(time.Second), Host: parsed.Host, CheckedAt: end}, nil
+}
+
+// WarnClockSkew warns once per process when the clock is off before something is downloaded, telling by how much
+// and how to fix it. In a terminal it offers to turn on network time synchronization. It never stops the download,
+// a failed check is only logged.
+func WarnClockSkew() {
+	skew, err := CheckClockSkew()
+	if err != nil {
+		Debug(fmt.Sprintf("Failed to check the system clock: %v", err))
+		return
+	}
+	clockSkewCache.Lock()
+	warned := clockSkewCache.warned
+	clockSkewCache.warned = true
+	clockSkewCache.Unlock()
+	if !skew.Skewed() || warned {
+		return
+	}
+
+	WarningTf("The system clock is %s. Downloads and package installs can fail with certificate errors or files that are \"not valid yet\" until it is set right.", skew)
+	if !commandExists("timedatectl") {
+		StatusT("Set the date and time of this system, or turn on network time synchronization in its settings.")
+		return
+	}
+	if !confirmClockSync() {
+		StatusT("To set the clock from the internet, run: sudo timedatectl set-ntp true")
+		return
+	}
+	if err := SudoPopup("timedatectl", "set-ntp", "true"); err != nil {
+		WarningTf("Failed to turn on network time synchronization: %v", err)
+		return
+	}
+	StatusGreenT("Turned on network time synchronization, the clock is set within a minute")
+
+	// The next check has to measure the corrected clock
+	clockSkewCache.Lock()
+	clockSkewCache.checked = false
+	clockSkewCache.Unlock()
+}
+
+// confirmClockSync asks whether to turn on network time synchronization. Without a terminal there is nobody to ask.
+func confirmClockSync() bool {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return false
+	}
+	fmt.Fprint(os.Stderr, T("Turn on network time synchronization now? [y/N] "))
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return false
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
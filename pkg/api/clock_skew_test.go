@@ -0,0 +1,131 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func init() {
+	// No test asks the internet for the time, the clock tests start their own servers
+	clockCheckURLs = nil
+}
+
+// dateServer starts a server whose Date header is offset from the system clock, or missing when offset is nil
+func dateServer(t *testing.T, offset *time.Duration, requests *atomic.Int32) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests != nil {
+			requests.Add(1)
+		}
+		if r.Method != http.MethodHead {
+			t.Errorf("got a %s request, want HEAD", r.Method)
+		}
+		if offset == nil {
+			w.Header()["Date"] = nil
+		} else {
+			w.Header().Set("Date", time.Now().Add(*offset).UTC().Format(http.TimeFormat))
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// resetClockSkewCache makes the next CheckClockSkew measure again and WarnClockSkew warn again
+func resetClockSkewCache(t *testing.T, urls ...string) {
+	reset := func() {
+		clockSkewCache.Lock()
+		clockSkewCache.checked, clockSkewCache.warned = false, false
+		clockSkewCache.Unlock()
+	}
+	reset()
+	clockCheckURLs = urls
+	t.Cleanup(func() {
+		reset()
+		clockCheckURLs = nil
+	})
+}
+
+func TestMeasureClockSkew(t *testing.T) {
+	behind := -2 * time.Hour
+	inSync := 20 * time.Second
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+
+	// The server is 2 hours behind, so the clock is 2 hours ahead
+	server := dateServer(t, &behind, nil)
+	skew, err := measureClockSkew([]string{closed.URL, server.URL})
+	if err != nil {
+		t.Fatalf("the fallback server wasn't used: %v", err)
+	}
+	if skew.Offset < 2*time.Hour-5*time.Second || skew.Offset > 2*time.Hour+5*time.Second || !skew.Skewed() {
+		t.Errorf("Offset = %s, want about 2h", skew.Offset)
+	}
+	if host := strings.TrimPrefix(server.URL, "http://"); skew.Host != host || !strings.HasSuffix(skew.String(), " ahead of "+host) {
+		t.Errorf("String() = %q", skew)
+	}
+
+	// Small differences are tolerated
+	skew, err = measureClockSkew([]string{dateServer(t, &inSync, nil).URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if skew.Skewed() || !strings.HasPrefix(skew.String(), "in sync with ") {
+		t.Errorf("a clock %s off is %s", -inSync, skew)
+	}
+
+	if _, err := measureClockSkew([]string{dateServer(t, nil, nil).URL}); err == nil || !strings.Contains(err.Error(), "Date header") {
+		t.Errorf("a server without a Date header gave %v", err)
+	}
+	if _, err := measureClockSkew(nil); err == nil {
+		t.Error("measuring without a server succeeded")
+	}
+}
+
+// TestCheckClockSkewCached checks that the clock is measured once per process and warned about once
+func TestCheckClockSkewCached(t *testing.T) {
+	ahead := 3 * time.Hour
+	var requests atomic.Int32
+	resetClockSkewCache(t, dateServer(t, &ahead, &requests).URL)
+
+	for range 2 {
+		skew, err := CheckClockSkew()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if skew.Offset > -3*time.Hour+5*time.Second {
+			t.Errorf("Offset = %s, want about -3h", skew.Offset)
+		}
+	}
+	if requests.Load() != 1 {
+		t.Errorf("the time was requested %d times, want once", requests.Load())
+	}
+
+	setColorEnv(t, "")
+	output := captureStderr(t, WarnClockSkew)
+	if !strings.Contains(output, "The system clock is ") || !strings.Contains(output, " behind 127.0.0.1") {
+		t.Errorf("WarnClockSkew printed %q, want a warning with the offset", output)
+	}
+	if output := captureStderr(t, WarnClockSkew); output != "" {
+		t.Errorf("the second WarnClockSkew printed %q", output)
+	}
+}
@@ -0,0 +1,142 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: color_output.go
+// Description: Decides whether status, warning and error messages are colored, following NO_COLOR, PI_APPS_COLOR,
+// the "Color output" setting and whether the output is a terminal.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package api
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+
+	"golang.org/x/term"
+)
+
+// ColorOutput is the preference for when messages are colored
+type ColorOutput string
+
+const (
+	ColorOutputAuto   ColorOutput = "auto"   // color when the output is a terminal
+	ColorOutputAlways ColorOutput = "always" // color even when the output is piped or redirected
+	ColorOutputNever  ColorOutput = "never"  // never color
+)
+
+// colorEnvVar overrides NO_COLOR and the "Color output" setting, it is also how child processes inherit a preference
+const colorEnvVar = "PI_APPS_COLOR"
+
+// colorEscapePattern matches the SGR and cursor escape sequences the output helpers and flows print
+var colorEscapePattern = regexp.MustCompile(`\x1b\[[0-9;?]*[a-zA-Z]`)
+
+// parseColorOutput returns the preference a PI_APPS_COLOR value or "Color output" setting stands for
+func parseColorOutput(value string) (ColorOutput, bool) {
+	switch ColorOutput(strings.ToLower(strings.TrimSpace(value))) {
+	case ColorOutputAuto:
+		return ColorOutputAuto, true
+	case ColorOutputAlways:
+		return ColorOutputAlways, true
+	case ColorOutputNever:
+		return ColorOutputNever, true
+	}
+	return "", false
+}
+
+// colorOutputSetting reads the "Color output" setting once, as every message asks for it
+var colorOutputSetting = sync.OnceValue(readColorOutputSetting)
+
+// readColorOutputSetting returns the preference of the "Color output" setting, auto when it is not set
+func readColorOutputSetting() ColorOutput {
+	directory := GetPiAppsDir()
+	if directory == "" {
+		return ColorOutputAuto
+	}
+	if preference, ok := parseColorOutput(ReadSettingIn(directory, "Color output")); ok {
+		return preference
+	}
+	return ColorOutputAuto
+}
+
+// ColorOutputPreference returns when messages are colored. PI_APPS_COLOR wins over NO_COLOR (https://no-color.org),
+// which wins over the "Color output" setting.
+func ColorOutputPreference() ColorOutput {
+	if preference, ok := parseColorOutput(os.Getenv(colorEnvVar)); ok {
+		return preference
+	}
+	if os.Getenv("NO_COLOR") != "" {
+		return ColorOutputNever
+	}
+	return colorOutputSetting()
+}
+
+// ColorEnabled reports whether output written to f is colored. On auto that is when f is a terminal that is not dumb.
+func ColorEnabled(f *os.File) bool {
+	switch ColorOutputPreference() {
+	case ColorOutputAlways:
+		return true
+	case ColorOutputNever:
+		return false
+	}
+	if f == nil || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	return term.IsTerminal(int(f.Fd()))
+}
+
+// KeepTerminalColors keeps messages colored in a process whose output is piped but still shown in a terminal,
+// like the daemon terminal that copies its output to a log. Scripts it runs inherit the preference.
+func KeepTerminalColors() {
+	if ColorOutputPreference() == ColorOutputAuto {
+		os.Setenv(colorEnvVar, string(ColorOutputAlways))
+	}
+}
+
+// scriptColorEnv returns the PI_APPS_COLOR entry for a script whose output goes to f, so the api calls in it color
+// their messages like the process that runs it even though their own output is a pipe
+func scriptColorEnv(f *os.File) string {
+	if ColorEnabled(f) {
+		return colorEnvVar + "=" + string(ColorOutputAlways)
+	}
+	return colorEnvVar + "=" + string(ColorOutputNever)
+}
+
+// StripColors removes escape sequences from s
+func StripColors(s string) string {
+	return colorEscapePattern.ReplaceAllString(s, "")
+}
+
+// colorPrintln writes msg to f in the color of the escape sequence code, or without any escape sequences when f is
+// not colored
+func colorPrintln(f *os.File, code, msg string) {
+	if ColorEnabled(f) {
+		fmt.Fprintln(f, code+msg+"\033[0m")
+		return
+	}
+	fmt.Fprintln(f, StripColors(msg))
+}
+
+// colorPrintf is fmt.Fprintf for formats with escape sequences in them, which are left out when f is not colored
+func colorPrintf(f *os.File, format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	if !ColorEnabled(f) {
+		msg = StripColors(msg)
+	}
+	fmt.Fprint(f, msg)
+}
@@ -0,0 +1,233 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// setColorEnv clears the color environment variables and points the "Color output" setting at a fresh
+// Pi-Apps directory with the given value, "" leaving the setting unset
+func setColorEnv(t *testing.T, setting string) {
+	t.Helper()
+	dir := newTestPiAppsDir(t)
+	if setting != "" {
+		writeTestFile(t, filepath.Join(dir, "data", "settings", "Color output"), setting+"\n")
+	}
+	t.Setenv(colorEnvVar, "")
+	t.Setenv("NO_COLOR", "")
+	t.Setenv("TERM", "xterm-256color")
+	colorOutputSetting = sync.OnceValue(readColorOutputSetting)
+	t.Cleanup(func() { colorOutputSetting = sync.OnceValue(readColorOutputSetting) })
+}
+
+// openPTY opens a pseudo terminal, skipping the test where none is available
+func openPTY(t *testing.T) (master, slave *os.File) {
+	t.Helper()
+	// Opened non-blocking so reads from it honor a deadline
+	fd, err := unix.Open("/dev/ptmx", unix.O_RDWR|unix.O_NOCTTY|unix.O_NONBLOCK|unix.O_CLOEXEC, 0)
+	if err != nil {
+		t.Skipf("no pseudo terminal available: %v", err)
+	}
+	master = os.NewFile(uintptr(fd), "/dev/ptmx")
+	t.Cleanup(func() { master.Close() })
+	if err := unix.IoctlSetPointerInt(int(master.Fd()), unix.TIOCSPTLCK, 0); err != nil {
+		t.Skipf("failed to unlock the pseudo terminal: %v", err)
+	}
+	number, err := unix.IoctlGetInt(int(master.Fd()), unix.TIOCGPTN)
+	if err != nil {
+		t.Skipf("failed to get the pseudo terminal number: %v", err)
+	}
+	slave, err = os.OpenFile("/dev/pts/"+strconv.Itoa(number), os.O_RDWR|unix.O_NOCTTY, 0)
+	if err != nil {
+		t.Skipf("failed to open the pseudo terminal: %v", err)
+	}
+	t.Cleanup(func() { slave.Close() })
+	return master, slave
+}
+
+// printMessages prints a message with each output helper to stderr
+func printMessages() {
+	Status("Installing \033[1mZoom\033[22m...")
+	Status("-n", "Downloading")
+	StatusGreen("Installed Zoom")
+	Warning("low memory")
+	ErrorNoExit("Failed to install Zoom!")
+	StatusT("Reading the app list")
+	WarningT("the download is slow")
+}
+
+// captureStderr returns what fn printed to stderr through a pipe
+func captureStderr(t *testing.T, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stderr := os.Stderr
+	os.Stderr = w
+	fn()
+	os.Stderr = stderr
+	w.Close()
+	output, _ := io.ReadAll(r)
+	return string(output)
+}
+
+// captureTerminal returns what fn printed to stderr when stderr is a pseudo terminal
+func captureTerminal(t *testing.T, fn func()) string {
+	t.Helper()
+	master, slave := openPTY(t)
+	stderr := os.Stderr
+	os.Stderr = slave
+	fn()
+	os.Stderr = stderr
+
+	var output strings.Builder
+	buf := make([]byte, 4096)
+	master.SetReadDeadline(time.Now().Add(200 * time.Millisecond))
+	for {
+		n, err := master.Read(buf)
+		output.Write(buf[:n])
+		if err != nil {
+			break
+		}
+	}
+	return output.String()
+}
+
+func TestColorOutputPreference(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      string
+		noColor  string
+		setting  string
+		expected ColorOutput
+	}{
+		{"default", "", "", "", ColorOutputAuto},
+		{"setting", "", "", "Never", ColorOutputNever},
+		{"invalid setting", "", "", "Sometimes", ColorOutputAuto},
+		{"NO_COLOR over the setting", "", "1", "Always", ColorOutputNever},
+		{"PI_APPS_COLOR over NO_COLOR", "always", "1", "Never", ColorOutputAlways},
+		{"PI_APPS_COLOR auto", "AUTO", "", "Never", ColorOutputAuto},
+		{"invalid PI_APPS_COLOR", "rainbow", "", "Always", ColorOutputAlways},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setColorEnv(t, tt.setting)
+			t.Setenv(colorEnvVar, tt.env)
+			t.Setenv("NO_COLOR", tt.noColor)
+			if got := ColorOutputPreference(); got != tt.expected {
+				t.Errorf("ColorOutputPreference() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestColorOutputPiped checks that nothing piped to another command carries escape sequences unless asked for
+func TestColorOutputPiped(t *testing.T) {
+	setColorEnv(t, "")
+	output := captureStderr(t, printMessages)
+	if strings.Contains(output, "\x1b") {
+		t.Errorf("piped output has escape sequences:\n%q", output)
+	}
+	for _, want := range []string{"Installing Zoom...\n", "-n Downloading\n", "Installed Zoom\n", "WARNING: low memory\n", "Failed to install Zoom!\n"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("piped output is missing %q:\n%s", want, output)
+		}
+	}
+
+	t.Setenv(colorEnvVar, "always")
+	output = captureStderr(t, printMessages)
+	for _, want := range []string{"\033[96mInstalling \033[1mZoom\033[22m...\033[0m\n", "-n \033[96mDownloading\033[0m\n", "\033[93m"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output with PI_APPS_COLOR=always is missing %q:\n%q", want, output)
+		}
+	}
+}
+
+// TestColorOutputTerminal checks that a terminal gets colors, unless NO_COLOR, the setting or a dumb terminal turn them off
+func TestColorOutputTerminal(t *testing.T) {
+	setColorEnv(t, "")
+	output := captureTerminal(t, printMessages)
+	for _, want := range []string{"\033[96mInstalling \033[1mZoom\033[22m...\033[0m", "\033[91m", "\033[93m"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("terminal output is missing %q:\n%q", want, output)
+		}
+	}
+
+	turnOff := map[string]func(t *testing.T){
+		"NO_COLOR": func(t *testing.T) { t.Setenv("NO_COLOR", "1") },
+		"setting":  func(t *testing.T) { setColorEnv(t, "Never") },
+		"TERM":     func(t *testing.T) { t.Setenv("TERM", "dumb") },
+	}
+	for name, apply := range turnOff {
+		t.Run(name, func(t *testing.T) {
+			setColorEnv(t, "")
+			apply(t)
+			output := captureTerminal(t, printMessages)
+			if strings.Contains(output, "\x1b") {
+				t.Errorf("terminal output has escape sequences:\n%q", output)
+			}
+			if !strings.Contains(output, "Installing Zoom...") {
+				t.Errorf("terminal output is missing the status:\n%q", output)
+			}
+		})
+	}
+}
+
+func TestColorOutputLogo(t *testing.T) {
+	setColorEnv(t, "")
+	t.Setenv("NO_COLOR", "1")
+	if mode := DetectColorMode(); mode != ColorNone {
+		t.Errorf("DetectColorMode() with NO_COLOR = %v, want ColorNone", mode)
+	}
+	if mode := TerminalColorMode(); mode != ColorNone {
+		t.Errorf("TerminalColorMode() with NO_COLOR = %v, want ColorNone", mode)
+	}
+
+	t.Setenv(colorEnvVar, "always")
+	t.Setenv("COLORTERM", "")
+	if mode := DetectColorMode(); mode != Color256 {
+		t.Errorf("DetectColorMode() with PI_APPS_COLOR=always = %v, want Color256", mode)
+	}
+}
+
+// TestKeepTerminalColors checks that the daemon terminal keeps its colors and hands them to the scripts it runs,
+// while an explicit preference is left alone
+func TestKeepTerminalColors(t *testing.T) {
+	setColorEnv(t, "")
+	KeepTerminalColors()
+	if !ColorEnabled(os.Stderr) || scriptColorEnv(os.Stdout) != colorEnvVar+"=always" {
+		t.Errorf("KeepTerminalColors didn't keep the colors, %s=%q", colorEnvVar, os.Getenv(colorEnvVar))
+	}
+
+	setColorEnv(t, "")
+	t.Setenv("NO_COLOR", "1")
+	KeepTerminalColors()
+	if ColorEnabled(os.Stderr) || scriptColorEnv(os.Stdout) != colorEnvVar+"=never" {
+		t.Errorf("KeepTerminalColors overrode NO_COLOR, %s=%q", colorEnvVar, os.Getenv(colorEnvVar))
+	}
+}
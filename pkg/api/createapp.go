@@ -18,15 +18,18 @@
 // Description: Provides functions for creating new apps.
 // SPDX-License-Identifier: GPL-3.0-or-later
 
+//go:build !nogui
+
 package api
 
 import (
 	"bufio"
 	"fmt"
-	"io"
+	"maps"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
@@ -37,16 +40,13 @@ import (
 	"github.com/toqueteos/webbrowser"
 )
 
-// commandExists checks if a command is available in the system PATH
-func commandExists(cmd string) bool {
-	_, err := exec.LookPath(cmd)
-	return err == nil
-}
-
 // CreateApp provides a graphical interface for creating new apps in Pi-Apps Go
 //
 //	appName - the name of the app to edit, or empty to create a new app
 func CreateApp(appName string) error {
+	if err := CheckKioskFeature(KioskCreateApp); err != nil {
+		return err
+	}
 
 	// Initialize application name
 	glib.SetPrgname("Pi-Apps-Settings")
@@ -218,6 +218,23 @@ func CreateApp(appName string) error {
 					}
 				}
 
+				// Save the translated descriptions, removing those that were emptied
+				for locale, translation := range appDetails.Translations {
+					translationFile := filepath.Join(piAppsDir, "apps", appName, "description."+locale)
+					switch {
+					case !ValidDescriptionLocale(locale):
+						if strings.TrimSpace(translation) != "" {
+							Warning(fmt.Sprintf("Not saving the description translated to %q, it is not a locale like fr or pt_BR\n", locale))
+						}
+					case strings.TrimSpace(translation) == "":
+						os.Remove(translationFile)
+					default:
+						if err := os.WriteFile(translationFile, []byte(translation), 0644); err != nil {
+							Warning(fmt.Sprintf("Failed to save the %s description: %v\n", locale, err))
+						}
+					}
+				}
+
 				// Save credits if provided
 				if appDetails.Credits != "" {
 					creditsFile := filepath.Join(piAppsDir, "apps", appName, "credits")
@@ -1198,6 +1215,7 @@ type AppDetails struct {
 	Packages        string
 	FlatpakPackages string
 	Description     string
+	Translations    map[string]string // locale -> translated description, saved as description.<locale>
 	Credits         string
 	Compatibility   string
 }
@@ -1790,6 +1808,65 @@ func showAppDetailsDialog(appName, appType string) (string, *AppDetails, error)
 
 	row++
 
+	// Add the translated description field, showing the translation to the locale entered next to its label
+	translationLabel, err := gtk.LabelNew("Translated description (locale, e.g. fr or pt_BR):")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create translation label: %v", err)
+	}
+	translationLabel.SetHAlign(gtk.ALIGN_START)
+	grid.Attach(translationLabel, 0, row, 1, 1)
+
+	localeEntry, err := gtk.EntryNew()
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create locale entry: %v", err)
+	}
+	grid.Attach(localeEntry, 1, row, 1, 1)
+	row++
+
+	translationScrolled, err := gtk.ScrolledWindowNew(nil, nil)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create scrolled window: %v", err)
+	}
+	translationScrolled.SetHExpand(true)
+	translationScrolled.SetVExpand(true)
+	translationScrolled.SetShadowType(gtk.SHADOW_IN)
+	grid.Attach(translationScrolled, 0, row, 2, 1)
+
+	translationTextView, err := gtk.TextViewNew()
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create text view: %v", err)
+	}
+	translationTextView.SetWrapMode(gtk.WRAP_WORD)
+	translationScrolled.Add(translationTextView)
+
+	// Read existing translations, entering a locale shows its translation
+	details.Translations = appDescriptionTranslations(filepath.Join(piAppsDir, "apps", appName))
+	if details.Translations == nil {
+		details.Translations = make(map[string]string)
+	}
+	translationBuffer, _ := translationTextView.GetBuffer()
+	showingTranslation := false
+	localeEntry.Connect("changed", func() {
+		locale, _ := localeEntry.GetText()
+		showingTranslation = true
+		translationBuffer.SetText(details.Translations[strings.TrimSpace(locale)])
+		showingTranslation = false
+	})
+	translationBuffer.Connect("changed", func() {
+		locale, _ := localeEntry.GetText()
+		locale = strings.TrimSpace(locale)
+		if showingTranslation || locale == "" {
+			return
+		}
+		text, _ := translationBuffer.GetText(translationBuffer.GetStartIter(), translationBuffer.GetEndIter(), true)
+		details.Translations[locale] = text
+	})
+	if locales := slices.Sorted(maps.Keys(details.Translations)); len(locales) > 0 {
+		localeEntry.SetText(locales[0])
+	}
+
+	row++
+
 	// Add credits field (common to both app types)
 	creditsLabel, err := gtk.LabelNew("Credits:")
 	if err != nil {
@@ -2003,22 +2080,28 @@ func createAppDirectory(appName, appType string) error {
 	return nil
 }
 
-// copyFile copies a file from src to dst
-func copyFile(src, dst string) error {
-	sourceFile, err := os.Open(src)
-	if err != nil {
-		return err
-	}
-	defer sourceFile.Close()
+// checkShellcheck checks if shellcheck is installed and offers to install it if it isn't
+func checkShellcheck() error {
+	// Initialize application name
+	glib.SetPrgname("Pi-Apps-Settings")
+	glib.SetApplicationName("Pi-Apps Settings (app creation wizard)")
 
-	destFile, err := os.Create(dst)
-	if err != nil {
-		return err
-	}
-	defer destFile.Close()
+	// Initialize GTK
+	gtk.Init(nil)
+
+	// Check if shellcheck is installed
+	if !commandExists("shellcheck") {
+		// Ask if they want to install shellcheck
+		dialog := gtk.MessageDialogNew(nil, gtk.DIALOG_MODAL, gtk.MESSAGE_QUESTION, gtk.BUTTONS_YES_NO,
+			"Shellcheck is not installed, but it's useful for finding errors in shell scripts. Install it now?")
+		response := dialog.Run()
+		dialog.Destroy()
 
-	_, err = io.Copy(destFile, sourceFile)
-	return err
+		if response == gtk.RESPONSE_YES {
+			return installShellcheck()
+		}
+	}
+	return nil
 }
 
 // runShellcheck executes shellcheck on the given script file
@@ -0,0 +1,450 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: data_cleanup.go
+// Description: Finds and removes the junk long-lived installs accumulate in the data directory.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Categories of the files CleanDataDir removes, in the order they are reported
+const (
+	CleanupOrphanedStatus = "orphaned-status" // status files of apps that no longer exist
+	CleanupUpdateStatus   = "update-status"   // update-status entries of apps and files that no longer exist
+	CleanupCache          = "cache"           // cache files and entries older than their policy
+	CleanupDaemon         = "daemon"          // files the manage daemon left behind
+	CleanupEmptyLogs      = "empty-logs"      // log files without content
+	CleanupDuplicateIcons = "duplicate-icons" // icons in the caches identical to another one
+	CleanupLegacy         = "legacy"          // files written by the bash version of Pi-Apps
+)
+
+// cleanupCategories lists the cleanup categories in the order they are reported
+var cleanupCategories = []string{CleanupOrphanedStatus, CleanupUpdateStatus, CleanupCache, CleanupDaemon, CleanupEmptyLogs, CleanupDuplicateIcons, CleanupLegacy}
+
+// unfinishedPreloadMaxAge is how old an app list being written has to be before it counts as abandoned
+const unfinishedPreloadMaxAge = time.Hour
+
+// CleanupItem is a file CleanDataDir removes, or shrinks by removing stale entries
+type CleanupItem struct {
+	Path   string `json:"path"`
+	Reason string `json:"reason"`
+	Bytes  int64  `json:"bytes"` // bytes freed
+}
+
+// CleanupCategory is what CleanDataDir removes in one category
+type CleanupCategory struct {
+	Name  string        `json:"name"`
+	Items []CleanupItem `json:"items"`
+	Bytes int64         `json:"bytes"`
+}
+
+// CleanupReport is what CleanDataDir removed, or would remove in a dry run
+type CleanupReport struct {
+	DryRun     bool              `json:"dry_run"`
+	Categories []CleanupCategory `json:"categories"` // only the categories with items
+	Bytes      int64             `json:"bytes"`
+}
+
+// dataCleanup is a file to remove or rewrite without its stale entries
+type dataCleanup struct {
+	category string
+	item     CleanupItem
+	apply    func() error
+}
+
+// CleanupCategoryName returns the translated name of a cleanup category
+func CleanupCategoryName(category string) string {
+	switch category {
+	case CleanupOrphanedStatus:
+		return T("Status files of removed apps")
+	case CleanupUpdateStatus:
+		return T("Stale update status")
+	case CleanupCache:
+		return T("Outdated caches")
+	case CleanupDaemon:
+		return T("Manage daemon leftovers")
+	case CleanupEmptyLogs:
+		return T("Empty log files")
+	case CleanupDuplicateIcons:
+		return T("Duplicate icons")
+	case CleanupLegacy:
+		return T("Files of the bash version of Pi-Apps")
+	}
+	return category
+}
+
+// CleanDataDir looks for junk in the data directory: status files of apps that no longer exist, update-status
+// entries of apps and files that are gone, outdated caches, files of a manage daemon that isn't running, empty
+// log files, duplicate icons in the caches and app lists of the bash version. Without dryRun they are removed.
+//
+// Only files that are positively recognized are touched, unknown files are always left alone.
+//
+//	CleanupReport - what was removed, or would be removed in a dry run
+//	error - error if PI_APPS_DIR environment variable is not set, or the files that couldn't be removed
+func CleanDataDir(dryRun bool) (CleanupReport, error) {
+	report := CleanupReport{DryRun: dryRun}
+	directory := GetPiAppsDir()
+	if directory == "" {
+		return report, fmt.Errorf("PI_APPS_DIR environment variable not set")
+	}
+
+	var cleanups []dataCleanup
+	cleanups = append(cleanups, orphanedStatusCleanups(directory)...)
+	cleanups = append(cleanups, updateStatusCleanups(directory)...)
+	cleanups = append(cleanups, cacheCleanups(directory)...)
+	cleanups = append(cleanups, daemonCleanups(directory)...)
+	cleanups = append(cleanups, emptyLogCleanups(directory)...)
+	cleanups = append(cleanups, duplicateIconCleanups(directory)...)
+	cleanups = append(cleanups, legacyCleanups(directory)...)
+
+	var errs []error
+	categories := make(map[string]*CleanupCategory)
+	for _, cleanup := range cleanups {
+		if !dryRun {
+			if err := cleanup.apply(); err != nil && !os.IsNotExist(err) {
+				errs = append(errs, fmt.Errorf("failed to clean %s: %w", cleanup.item.Path, err))
+				continue
+			}
+		}
+		category, ok := categories[cleanup.category]
+		if !ok {
+			category = &CleanupCategory{Name: cleanup.category}
+			categories[cleanup.category] = category
+		}
+		category.Items = append(category.Items, cleanup.item)
+		category.Bytes += cleanup.item.Bytes
+		report.Bytes += cleanup.item.Bytes
+	}
+	for _, name := range cleanupCategories {
+		if category, ok := categories[name]; ok {
+			report.Categories = append(report.Categories, *category)
+		}
+	}
+	return report, errors.Join(errs...)
+}
+
+// removeCleanup returns the cleanup removing a file
+func removeCleanup(category, path, reason string) dataCleanup {
+	var size int64
+	if info, err := os.Lstat(path); err == nil && info.Mode().IsRegular() {
+		size = info.Size()
+	}
+	return dataCleanup{
+		category: category,
+		item:     CleanupItem{Path: path, Reason: reason, Bytes: size},
+		apply:    func() error { return os.Remove(path) },
+	}
+}
+
+// rewriteCleanup returns the cleanup replacing the content of a file with the lines that are still valid,
+// removing it if none are
+func rewriteCleanup(category, path, reason string, size int64, lines []string) dataCleanup {
+	var content string
+	if len(lines) > 0 {
+		content = strings.Join(lines, "\n") + "\n"
+	}
+	return dataCleanup{
+		category: category,
+		item:     CleanupItem{Path: path, Reason: reason, Bytes: size - int64(len(content))},
+		apply: func() error {
+			if content == "" {
+				return os.Remove(path)
+			}
+			tmp := path + ".tmp"
+			if err := os.WriteFile(tmp, []byte(content), 0644); err != nil {
+				return err
+			}
+			return os.Rename(tmp, path)
+		},
+	}
+}
+
+// orphanedStatusCleanups finds the status files of apps whose folder is gone. Deprecated apps keep their status
+// so they can still be uninstalled, and so do apps marked installed or corrupted, their files may still be around.
+func orphanedStatusCleanups(directory string) []dataCleanup {
+	statusDir := filepath.Join(DataDirOf(directory), "status")
+	entries, err := os.ReadDir(statusDir)
+	if err != nil {
+		return nil
+	}
+	var cleanups []dataCleanup
+	for _, entry := range entries {
+		app := entry.Name()
+		if !entry.Type().IsRegular() || strings.HasPrefix(app, ".") || ValidateAppName(app) != nil {
+			continue
+		}
+		if DirExists(filepath.Join(directory, "apps", app)) || DirExists(filepath.Join(DataDirOf(directory), "deprecated-apps", app)) || IsDeprecatedApp(app) {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(statusDir, app))
+		if err != nil {
+			continue
+		}
+		status := strings.TrimSpace(string(data))
+		if AppState(status) != AppStateUninstalled && AppState(status) != AppStateDisabled {
+			continue
+		}
+		cleanups = append(cleanups, removeCleanup(CleanupOrphanedStatus, filepath.Join(statusDir, app), Tf("status %s of an app that no longer exists", status)))
+	}
+	return cleanups
+}
+
+// updateStatusCleanups finds the entries of the update status that name apps and files that exist neither in
+// the update folder nor in the Pi-Apps folder. Without an update folder, the entries can't be checked.
+func updateStatusCleanups(directory string) []dataCleanup {
+	updateDir := filepath.Join(directory, "update", "pi-apps")
+	if !DirExists(updateDir) {
+		return nil
+	}
+
+	var cleanups []dataCleanup
+	for _, status := range []struct {
+		name   string
+		exists func(entry string) bool
+	}{
+		{"updatable-apps", func(app string) bool {
+			return ValidateAppName(app) == nil && (officialAppExists(directory, app) || DirExists(filepath.Join(directory, "apps", app)))
+		}},
+		{"updatable-files", func(file string) bool {
+			return !filepath.IsAbs(file) && (fileOrDirExists(filepath.Join(updateDir, file)) || fileOrDirExists(filepath.Join(directory, file)))
+		}},
+	} {
+		path := filepath.Join(DataDirOf(directory), "update-status", status.name)
+		content, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		var kept, stale []string
+		for _, line := range strings.Split(string(content), "\n") {
+			if line = strings.TrimSpace(line); line == "" {
+				continue
+			}
+			if status.exists(line) {
+				kept = append(kept, line)
+			} else {
+				stale = append(stale, line)
+			}
+		}
+		if len(stale) > 0 {
+			cleanups = append(cleanups, rewriteCleanup(CleanupUpdateStatus, path, Tf("entries that no longer exist: %s", strings.Join(stale, ", ")), int64(len(content)), kept))
+		}
+	}
+	return cleanups
+}
+
+// cacheCleanups finds the caches that outlived their policy: app hashes of apps that were removed, upstream
+// versions older than a day, app lists that were never finished, timestamps of app lists that are gone and icons
+// generated for apps that were removed
+func cacheCleanups(directory string) []dataCleanup {
+	var cleanups []dataCleanup
+
+	hashesPath := filepath.Join(DataDirOf(directory), "cache", "app-hashes")
+	if content, err := os.ReadFile(hashesPath); err == nil {
+		var kept, stale []string
+		for _, line := range strings.Split(string(content), "\n") {
+			if line == "" {
+				continue
+			}
+			app, _, _ := strings.Cut(line, ";")
+			if ValidateAppName(app) == nil && !DirExists(filepath.Join(directory, "apps", app)) {
+				stale = append(stale, app)
+			} else {
+				kept = append(kept, line)
+			}
+		}
+		if len(stale) > 0 {
+			cleanup := rewriteCleanup(CleanupCache, hashesPath, Tf("hashes of removed apps: %s", strings.Join(stale, ", ")), int64(len(content)), kept)
+			rewrite := cleanup.apply
+			cleanup.apply = func() error {
+				appHashCacheMutex.Lock()
+				defer appHashCacheMutex.Unlock()
+				return rewrite()
+			}
+			cleanups = append(cleanups, cleanup)
+		}
+	}
+
+	upstreamPath := filepath.Join(DataDirOf(directory), "cache", "upstream-versions")
+	if info, err := os.Stat(upstreamPath); err == nil && time.Since(info.ModTime()) > upstreamCacheMaxAge {
+		cleanups = append(cleanups, removeCleanup(CleanupCache, upstreamPath, T("upstream versions checked more than a day ago")))
+	}
+
+	preloadDir := filepath.Join(DataDirOf(directory), "preload")
+	entries, _ := os.ReadDir(preloadDir)
+	for _, entry := range entries {
+		name := entry.Name()
+		path := filepath.Join(preloadDir, name)
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		switch {
+		case strings.HasPrefix(name, "LIST-") && strings.HasSuffix(name, "-tmp"):
+			if info, err := entry.Info(); err == nil && time.Since(info.ModTime()) > unfinishedPreloadMaxAge {
+				cleanups = append(cleanups, removeCleanup(CleanupCache, path, T("app list that was never finished")))
+			}
+		case strings.HasPrefix(name, "timestamps-") && name != "timestamps-preload-daemon" && name != "timestamps-dpkg-status":
+			if !FileExists(filepath.Join(preloadDir, "LIST-"+strings.TrimPrefix(name, "timestamps-"))) {
+				cleanups = append(cleanups, removeCleanup(CleanupCache, path, T("timestamps of an app list that no longer exists")))
+			}
+		}
+	}
+
+	iconCache := filepath.Join(DataDirOf(directory), iconCacheDir)
+	iconDirs, _ := os.ReadDir(iconCache)
+	for _, entry := range iconDirs {
+		appIcons := filepath.Join(iconCache, entry.Name())
+		if !entry.IsDir() || DirExists(filepath.Join(directory, "apps", entry.Name())) {
+			continue
+		}
+		cleanup := removeCleanup(CleanupCache, appIcons, T("icons generated for a removed app"))
+		cleanup.item.Bytes = dirSize(appIcons)
+		cleanup.apply = func() error { return os.RemoveAll(appIcons) }
+		cleanups = append(cleanups, cleanup)
+	}
+	return cleanups
+}
+
+// daemonCleanups finds the pid file, queue pipe and status file of a manage daemon that is no longer running
+func daemonCleanups(directory string) []dataCleanup {
+	daemonDir := filepath.Join(DataDirOf(directory), "manage-daemon")
+	pidFile := filepath.Join(daemonDir, "pid")
+	if FileExists(pidFile) && PIDFileRunning(pidFile) {
+		return nil
+	}
+	var cleanups []dataCleanup
+	for _, name := range []string{"pid", "queue", "status", "status.jsonl"} {
+		path := filepath.Join(daemonDir, name)
+		if info, err := os.Lstat(path); err == nil && !info.IsDir() {
+			cleanups = append(cleanups, removeCleanup(CleanupDaemon, path, T("the manage daemon is not running")))
+		}
+	}
+	return cleanups
+}
+
+// emptyLogCleanups finds the log files without content. Incomplete logs may belong to an install that is
+// running right now, so they are left alone.
+func emptyLogCleanups(directory string) []dataCleanup {
+	var cleanups []dataCleanup
+	filepath.WalkDir(LogsDirOf(directory), func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() || strings.Contains(d.Name(), "-incomplete-") {
+			return nil
+		}
+		if info, err := d.Info(); err == nil && info.Size() == 0 {
+			cleanups = append(cleanups, removeCleanup(CleanupEmptyLogs, path, T("empty log file")))
+		}
+		return nil
+	})
+	return cleanups
+}
+
+// duplicateIconCleanups finds the icons in the caches that have the same content as another one, keeping
+// the first one by path
+func duplicateIconCleanups(directory string) []dataCleanup {
+	var icons []string
+	for _, cacheDir := range []string{filepath.Join(DataDirOf(directory), "cache"), filepath.Join(DataDirOf(directory), "preload")} {
+		filepath.WalkDir(cacheDir, func(path string, d fs.DirEntry, err error) error {
+			if err == nil && d.Type().IsRegular() {
+				switch strings.ToLower(filepath.Ext(path)) {
+				case ".png", ".svg", ".xpm", ".jpg", ".jpeg", ".ico":
+					icons = append(icons, path)
+				}
+			}
+			return nil
+		})
+	}
+	sort.Strings(icons)
+
+	var cleanups []dataCleanup
+	first := make(map[[sha256.Size]byte]string)
+	for _, icon := range icons {
+		hash, err := fileSHA256(icon)
+		if err != nil {
+			continue
+		}
+		if kept, ok := first[hash]; ok {
+			cleanups = append(cleanups, removeCleanup(CleanupDuplicateIcons, icon, Tf("same icon as %s", kept)))
+		} else {
+			first[hash] = icon
+		}
+	}
+	return cleanups
+}
+
+// fileSHA256 returns the SHA-256 hash of the content of a file
+func fileSHA256(path string) ([sha256.Size]byte, error) {
+	var sum [sha256.Size]byte
+	file, err := os.Open(path)
+	if err != nil {
+		return sum, err
+	}
+	defer file.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return sum, err
+	}
+	copy(sum[:], hash.Sum(nil))
+	return sum, nil
+}
+
+// legacyCleanups finds the app lists the bash version of Pi-Apps cached for yad. Their entries are a field
+// per line, unlike the "Type|Name|Path|Description|IconPath|Status" lines of the app lists cached now,
+// which are written again when they are shown.
+func legacyCleanups(directory string) []dataCleanup {
+	preloadDir := filepath.Join(DataDirOf(directory), "preload")
+	listFiles, _ := filepath.Glob(filepath.Join(preloadDir, "LIST-*"))
+
+	var cleanups []dataCleanup
+	for _, listFile := range listFiles {
+		if strings.HasSuffix(listFile, "-tmp") {
+			continue
+		}
+		content, err := os.ReadFile(listFile)
+		if err != nil || len(bytes.TrimSpace(content)) == 0 {
+			continue
+		}
+		legacy := true
+		for _, line := range strings.Split(string(content), "\n") {
+			if len(strings.Split(line, "|")) >= 6 {
+				legacy = false
+				break
+			}
+		}
+		if legacy {
+			cleanups = append(cleanups, removeCleanup(CleanupLegacy, listFile, T("app list cached by the bash version of Pi-Apps")))
+		}
+	}
+	return cleanups
+}
+
+// fileOrDirExists reports whether anything exists at a path
+func fileOrDirExists(path string) bool {
+	_, err := os.Lstat(path)
+	return err == nil
+}
@@ -0,0 +1,45 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestDaemonCleanupsRemovesStaleDaemonFiles(t *testing.T) {
+	directory := newTestPiAppsDir(t)
+	daemonDir := filepath.Join(directory, "data", "manage-daemon")
+	for _, name := range []string{"pid", "queue", "status", "status.jsonl"} {
+		content := ""
+		if name == "pid" {
+			// no process has this PID, so the daemon counts as stopped
+			content = "2147483646\n"
+		}
+		writeTestFile(t, filepath.Join(daemonDir, name), content)
+	}
+
+	var removed []string
+	for _, cleanup := range daemonCleanups(directory) {
+		removed = append(removed, filepath.Base(cleanup.item.Path))
+	}
+	slices.Sort(removed)
+	if want := []string{"pid", "queue", "status", "status.jsonl"}; !slices.Equal(removed, want) {
+		t.Errorf("daemonCleanups removed %v, want %v", removed, want)
+	}
+}
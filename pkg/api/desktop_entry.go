@@ -0,0 +1,636 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: desktop_entry.go
+// Description: Creates and removes the menu entries (.desktop files) of apps, escaped and checked against the desktop entry specification.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package api
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// DesktopEntry is the menu entry of an app, written as a .desktop file by CreateDesktopEntry
+type DesktopEntry struct {
+	Name           string   // shown in the menu, required
+	GenericName    string   // generic name of the app, like "Web Browser"
+	Comment        string   // tooltip of the menu entry
+	Exec           []string // command and its arguments, unescaped. Field codes like %U are passed through.
+	Path           string   // working directory of the command
+	Icon           string   // absolute path of an icon file, or the name of an icon in the icon theme
+	Categories     []string // menu categories, like Game or Utility
+	StartupWMClass string   // window class of the app, lets docks group its windows with the entry
+	Terminal       bool     // run the command in a terminal
+	NoDisplay      bool     // hide the entry from the menu, for file associations
+	FileName       string   // name of the .desktop file without extension, defaults to the app name
+	System         bool     // write to /usr/share/applications for all users instead of ~/.local/share/applications
+}
+
+// desktopEntryAppKey marks the .desktop files Pi-Apps created for an app, so uninstalling it only removes those
+const desktopEntryAppKey = "X-Pi-Apps-App"
+
+// systemApplicationsDir is where menu entries for all users are written
+const systemApplicationsDir = "/usr/share/applications"
+
+var (
+	// desktopFileNamePattern matches valid desktop file IDs, without the .desktop extension
+	desktopFileNamePattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+	// desktopKeyPattern matches a key of a desktop entry, with an optional locale
+	desktopKeyPattern = regexp.MustCompile(`^[A-Za-z0-9-]+(\[[A-Za-z0-9_.@-]+\])?$`)
+)
+
+// execFieldCodes are the field codes the desktop entry specification allows in Exec
+var execFieldCodes = []string{"%f", "%F", "%u", "%U", "%i", "%c", "%k"}
+
+// execReservedChars are the characters an Exec argument has to be quoted for
+const execReservedChars = " \t\n\"'\\><~|&;$*?#()`"
+
+// userApplicationsDir returns where the menu entries of the user are written
+func userApplicationsDir() string {
+	return filepath.Join(os.Getenv("HOME"), ".local", "share", "applications")
+}
+
+// desktopFileName returns the name of an app's .desktop file without extension
+func (entry DesktopEntry) desktopFileName(app string) string {
+	if entry.FileName != "" {
+		return entry.FileName
+	}
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r == ' ':
+			return '-'
+		case r < 128 && desktopFileNamePattern.MatchString(string(r)):
+			return r
+		}
+		return -1
+	}, app)
+	if name == "" {
+		name = "pi-apps-app"
+	}
+	return name
+}
+
+// Validate returns an error if the entry can't be written as a working menu entry
+func (entry DesktopEntry) Validate() error {
+	if strings.TrimSpace(entry.Name) == "" {
+		return fmt.Errorf("desktop entry has no name")
+	}
+	if len(entry.Exec) == 0 || entry.Exec[0] == "" {
+		return fmt.Errorf("desktop entry has no command to run")
+	}
+	if slices.Contains(execFieldCodes, entry.Exec[0]) {
+		return fmt.Errorf("the command of a desktop entry can't be the field code %s", entry.Exec[0])
+	}
+	if entry.FileName != "" && !desktopFileNamePattern.MatchString(entry.FileName) {
+		return fmt.Errorf("desktop file name %q may only contain letters, digits, '-', '_' and '.'", entry.FileName)
+	}
+	if entry.Icon != "" && strings.Contains(entry.Icon, "/") {
+		if !filepath.IsAbs(entry.Icon) {
+			return fmt.Errorf("icon path %q is not absolute", entry.Icon)
+		}
+		if !FileExists(entry.Icon) {
+			return fmt.Errorf("icon %s does not exist", entry.Icon)
+		}
+	}
+	if entry.Path != "" && !filepath.IsAbs(entry.Path) {
+		return fmt.Errorf("working directory %q is not absolute", entry.Path)
+	}
+	for _, category := range entry.Categories {
+		if category == "" || strings.ContainsAny(category, ";\n") {
+			return fmt.Errorf("invalid menu category %q", category)
+		}
+	}
+	return nil
+}
+
+// Render returns the content of the entry's .desktop file, marked as created for an app
+func (entry DesktopEntry) Render(app string) (string, error) {
+	if err := entry.Validate(); err != nil {
+		return "", err
+	}
+
+	var content strings.Builder
+	content.WriteString("[Desktop Entry]\n")
+	content.WriteString("Type=Application\n")
+	content.WriteString("Name=" + escapeDesktopString(entry.Name) + "\n")
+	if entry.GenericName != "" {
+		content.WriteString("GenericName=" + escapeDesktopString(entry.GenericName) + "\n")
+	}
+	if entry.Comment != "" {
+		content.WriteString("Comment=" + escapeDesktopString(entry.Comment) + "\n")
+	}
+	content.WriteString("Exec=" + escapeDesktopString(execLine(entry.Exec)) + "\n")
+	if entry.Path != "" {
+		content.WriteString("Path=" + escapeDesktopString(entry.Path) + "\n")
+	}
+	if entry.Icon != "" {
+		content.WriteString("Icon=" + escapeDesktopString(entry.Icon) + "\n")
+	}
+	content.WriteString("Terminal=" + strconv.FormatBool(entry.Terminal) + "\n")
+	if entry.NoDisplay {
+		content.WriteString("NoDisplay=true\n")
+	}
+	if len(entry.Categories) > 0 {
+		content.WriteString("Categories=" + escapeDesktopString(strings.Join(entry.Categories, ";")) + ";\n")
+	}
+	if entry.StartupWMClass != "" {
+		content.WriteString("StartupWMClass=" + escapeDesktopString(entry.StartupWMClass) + "\n")
+	}
+	content.WriteString(desktopEntryAppKey + "=" + escapeDesktopString(app) + "\n")
+
+	if problems := CheckDesktopEntry(content.String()); len(problems) > 0 {
+		return "", fmt.Errorf("desktop entry is not valid: %s", strings.Join(problems, "; "))
+	}
+	return content.String(), nil
+}
+
+// execLine quotes the arguments of a command for the Exec key, before the string escaping of its value.
+// Arguments containing reserved characters are put in double quotes, and a literal % is written as %%.
+func execLine(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		if slices.Contains(execFieldCodes, arg) {
+			quoted[i] = arg
+			continue
+		}
+		arg = strings.ReplaceAll(arg, "%", "%%")
+		if arg == "" || strings.ContainsAny(arg, execReservedChars) {
+			var builder strings.Builder
+			builder.WriteByte('"')
+			for _, r := range arg {
+				if strings.ContainsRune("\"`$\\", r) {
+					builder.WriteByte('\\')
+				}
+				builder.WriteRune(r)
+			}
+			builder.WriteByte('"')
+			arg = builder.String()
+		}
+		quoted[i] = arg
+	}
+	return strings.Join(quoted, " ")
+}
+
+// escapeDesktopString escapes a value of the string type of the desktop entry specification
+func escapeDesktopString(value string) string {
+	return strings.NewReplacer("\\", "\\\\", "\n", "\\n", "\t", "\\t", "\r", "\\r").Replace(value)
+}
+
+// CheckDesktopEntry checks the content of a .desktop file against the desktop entry specification
+// and returns the problems it finds, like the ones desktop-file-validate reports that break menu entries
+func CheckDesktopEntry(content string) []string {
+	var problems []string
+	values := make(map[string]string)
+	group := ""
+	seen := make(map[string]bool)
+
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") {
+				problems = append(problems, fmt.Sprintf("line %d: invalid group header %q", lineNumber, line))
+				continue
+			}
+			if group == "" && line != "[Desktop Entry]" {
+				problems = append(problems, "the first group is not [Desktop Entry]")
+			}
+			group = line
+			seen = make(map[string]bool)
+			continue
+		}
+		if group == "" {
+			problems = append(problems, fmt.Sprintf("line %d: key outside of a group", lineNumber))
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			problems = append(problems, fmt.Sprintf("line %d: %q is not a key=value pair", lineNumber, line))
+			continue
+		}
+		key = strings.TrimSpace(key)
+		if !desktopKeyPattern.MatchString(key) {
+			problems = append(problems, fmt.Sprintf("line %d: invalid key %q", lineNumber, key))
+			continue
+		}
+		if seen[key] {
+			problems = append(problems, fmt.Sprintf("line %d: key %s is set twice", lineNumber, key))
+		}
+		seen[key] = true
+		if group == "[Desktop Entry]" {
+			values[key] = strings.TrimSpace(value)
+		}
+	}
+	if group == "" {
+		return append(problems, "the [Desktop Entry] group is missing")
+	}
+
+	entryType := values["Type"]
+	switch entryType {
+	case "":
+		problems = append(problems, "the required key Type is missing")
+	case "Application", "Link", "Directory":
+	default:
+		problems = append(problems, fmt.Sprintf("unknown Type %q", entryType))
+	}
+	if values["Name"] == "" {
+		problems = append(problems, "the required key Name is missing")
+	}
+	for _, key := range []string{"Terminal", "NoDisplay", "Hidden", "StartupNotify", "DBusActivatable"} {
+		if value, ok := values[key]; ok && value != "true" && value != "false" {
+			problems = append(problems, fmt.Sprintf("%s is %q instead of true or false", key, value))
+		}
+	}
+	if icon := values["Icon"]; strings.Contains(icon, "/") && !filepath.IsAbs(icon) {
+		problems = append(problems, fmt.Sprintf("Icon %q is a relative path", icon))
+	}
+	if categories, ok := values["Categories"]; ok && categories != "" && !strings.HasSuffix(categories, ";") {
+		problems = append(problems, "Categories doesn't end with a semicolon")
+	}
+	if entryType == "Application" {
+		if _, ok := values["Exec"]; !ok && values["DBusActivatable"] != "true" {
+			problems = append(problems, "the key Exec is required for applications")
+		} else if ok {
+			if _, err := parseExecLine(unescapeDesktopString(values["Exec"])); err != nil {
+				problems = append(problems, fmt.Sprintf("invalid Exec: %v", err))
+			}
+		}
+	}
+	return problems
+}
+
+// unescapeDesktopString reverses escapeDesktopString
+func unescapeDesktopString(value string) string {
+	var builder strings.Builder
+	escaped := false
+	for _, r := range value {
+		if escaped {
+			switch r {
+			case 'n':
+				builder.WriteByte('\n')
+			case 't':
+				builder.WriteByte('\t')
+			case 'r':
+				builder.WriteByte('\r')
+			case 's':
+				builder.WriteByte(' ')
+			default:
+				builder.WriteRune(r)
+			}
+			escaped = false
+			continue
+		}
+		if r == '\\' {
+			escaped = true
+			continue
+		}
+		builder.WriteRune(r)
+	}
+	return builder.String()
+}
+
+// parseExecLine splits an Exec value, after string unescaping, into its arguments following the quoting rules
+// of the desktop entry specification. Field codes are kept as arguments, %% becomes %.
+func parseExecLine(line string) ([]string, error) {
+	var args []string
+	var current strings.Builder
+	inArg, inQuotes := false, false
+	runes := []rune(line)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case inQuotes && r == '\\':
+			if i+1 >= len(runes) || !strings.ContainsRune("\"`$\\", runes[i+1]) {
+				return nil, fmt.Errorf("invalid escape in quoted argument")
+			}
+			i++
+			current.WriteRune(runes[i])
+		case inQuotes && r == '"':
+			inQuotes = false
+		case inQuotes && r == '%':
+			if i+1 >= len(runes) || runes[i+1] != '%' {
+				return nil, fmt.Errorf("field code in a quoted argument")
+			}
+			i++
+			current.WriteRune('%')
+		case inQuotes:
+			current.WriteRune(r)
+		case r == '"':
+			if inArg {
+				return nil, fmt.Errorf("quote in the middle of an argument")
+			}
+			inQuotes, inArg = true, true
+		case r == ' ' || r == '\t':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		case r == '%':
+			if i+1 >= len(runes) {
+				return nil, fmt.Errorf("%% at the end of the command")
+			}
+			i++
+			code := "%" + string(runes[i])
+			switch {
+			case runes[i] == '%':
+				current.WriteRune('%')
+			case slices.Contains(execFieldCodes, code):
+				current.WriteString(code)
+			default:
+				return nil, fmt.Errorf("unknown field code %s", code)
+			}
+			inArg = true
+		case strings.ContainsRune(execReservedChars, r):
+			return nil, fmt.Errorf("reserved character %q outside of quotes", r)
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+	if inQuotes {
+		return nil, fmt.Errorf("unterminated quote")
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	if len(args) == 0 {
+		return nil, fmt.Errorf("empty command")
+	}
+	return args, nil
+}
+
+// CreateDesktopEntry writes the menu entry of an app to ~/.local/share/applications, or to /usr/share/applications
+// with administrator rights if entry.System is set, and records it in the app's install manifest so it is
+// removed when the app is uninstalled, even if the uninstall script forgets to.
+//
+//	string - path of the written .desktop file
+//	error - error if the entry is not valid or can't be written
+func CreateDesktopEntry(app string, entry DesktopEntry) (string, error) {
+	if err := ValidateAppName(app); err != nil {
+		return "", err
+	}
+	content, err := entry.Render(app)
+	if err != nil {
+		return "", err
+	}
+
+	dir := userApplicationsDir()
+	if entry.System {
+		dir = systemApplicationsDir
+	}
+	path := filepath.Join(dir, entry.desktopFileName(app)+".desktop")
+	if owner := desktopEntryOwner(path); owner != "" && owner != app {
+		return "", fmt.Errorf("%s belongs to the app %s", path, owner)
+	}
+
+	if entry.System {
+		temp, err := os.CreateTemp("", "pi-apps-*.desktop")
+		if err != nil {
+			return "", err
+		}
+		defer os.Remove(temp.Name())
+		if _, err := temp.WriteString(content); err != nil {
+			temp.Close()
+			return "", err
+		}
+		if err := temp.Close(); err != nil {
+			return "", err
+		}
+		if err := SudoPopup("install", "-D", "-m", "644", temp.Name(), path); err != nil {
+			return "", fmt.Errorf("failed to write %s: %w", path, err)
+		}
+	} else {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return "", err
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			return "", err
+		}
+	}
+
+	updateDesktopDatabase(dir)
+	if err := recordInstalledFile(app, path); err != nil {
+		return path, fmt.Errorf("failed to record %s in the install manifest: %w", path, err)
+	}
+	return path, nil
+}
+
+// RemoveDesktopEntries removes the menu entries CreateDesktopEntry created for an app that are still there
+//
+//	[]string - paths of the removed .desktop files
+//	error - error if the app name is not valid or an entry can't be removed
+func RemoveDesktopEntries(app string) ([]string, error) {
+	files, err := ReadInstalledFiles(app)
+	if err != nil {
+		return nil, err
+	}
+
+	var removed []string
+	dirs := make(map[string]bool)
+	for _, path := range files {
+		if !strings.HasSuffix(path, ".desktop") || desktopEntryOwner(path) != app {
+			continue
+		}
+		if strings.HasPrefix(path, systemApplicationsDir+"/") {
+			err = SudoPopup("rm", "-f", path)
+		} else {
+			err = os.Remove(path)
+		}
+		if err != nil && FileExists(path) {
+			return removed, fmt.Errorf("failed to remove %s: %w", path, err)
+		}
+		removed = append(removed, path)
+		dirs[filepath.Dir(path)] = true
+		if err := forgetInstalledFile(app, path); err != nil {
+			Debug(fmt.Sprintf("Failed to remove %s from the install manifest of %s: %v", path, app, err))
+		}
+	}
+	for dir := range dirs {
+		updateDesktopDatabase(dir)
+	}
+	return removed, nil
+}
+
+// removeLeftoverDesktopEntries removes the menu entries of an app its uninstall script left behind
+func removeLeftoverDesktopEntries(app string, logFile *os.File) {
+	removed, err := RemoveDesktopEntries(app)
+	if err != nil {
+		Debug(fmt.Sprintf("Failed to remove the menu entries of %s: %v", app, err))
+	}
+	if len(removed) > 0 {
+		fmt.Fprintf(logFile, "Removed menu entries the uninstall script left behind: %s\n", strings.Join(removed, ", "))
+	}
+}
+
+// desktopEntryOwner returns the app a .desktop file was created for by CreateDesktopEntry, "" if there is none
+func desktopEntryOwner(path string) string {
+	file, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if app, ok := strings.CutPrefix(scanner.Text(), desktopEntryAppKey+"="); ok {
+			return unescapeDesktopString(strings.TrimSpace(app))
+		}
+	}
+	return ""
+}
+
+// updateDesktopDatabase refreshes the cache of MIME types handled by the menu entries in a directory, if the tool is installed
+func updateDesktopDatabase(dir string) {
+	if !CommandExists("update-desktop-database") {
+		return
+	}
+	var err error
+	if strings.HasPrefix(dir, "/usr/") {
+		err = SudoPopup("update-desktop-database", dir)
+	} else {
+		err = exec.Command("update-desktop-database", dir).Run()
+	}
+	if err != nil {
+		Debug(fmt.Sprintf("update-desktop-database %s failed: %v", dir, err))
+	}
+}
+
+// ParseDesktopEntryPairs reads a menu entry from key=value pairs, like the arguments of `api create_desktop_entry`.
+// Keys are name, generic_name, comment, exec, path, icon, categories, wmclass, terminal, nodisplay, file, system
+// and app. exec is split into arguments like a shell does, categories are separated by ';' or ','.
+//
+//	DesktopEntry - the menu entry
+//	string - the app the entry is for, "" if no app key was given
+//	error - error if a pair is not valid
+func ParseDesktopEntryPairs(pairs []string) (DesktopEntry, string, error) {
+	var entry DesktopEntry
+	var app string
+	for _, pair := range pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return entry, "", fmt.Errorf("%q is not a key=value pair", pair)
+		}
+		var err error
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "app":
+			app = value
+		case "name":
+			entry.Name = value
+		case "generic_name", "genericname":
+			entry.GenericName = value
+		case "comment":
+			entry.Comment = value
+		case "exec":
+			entry.Exec, err = splitCommandLine(value)
+		case "path":
+			entry.Path = value
+		case "icon":
+			entry.Icon = value
+		case "categories":
+			entry.Categories = strings.FieldsFunc(value, func(r rune) bool { return r == ';' || r == ',' })
+			for i := range entry.Categories {
+				entry.Categories[i] = strings.TrimSpace(entry.Categories[i])
+			}
+		case "wmclass", "startupwmclass", "startup_wm_class":
+			entry.StartupWMClass = value
+		case "terminal":
+			entry.Terminal, err = strconv.ParseBool(value)
+		case "nodisplay", "no_display":
+			entry.NoDisplay, err = strconv.ParseBool(value)
+		case "file", "filename":
+			entry.FileName = strings.TrimSuffix(value, ".desktop")
+		case "system":
+			entry.System, err = strconv.ParseBool(value)
+		default:
+			return entry, "", fmt.Errorf("unknown key %q", key)
+		}
+		if err != nil {
+			return entry, "", fmt.Errorf("invalid %s: %w", key, err)
+		}
+	}
+	return entry, app, nil
+}
+
+// splitCommandLine splits a command line into arguments like a shell does, with single and double quotes
+// and backslash escapes, without expanding anything
+func splitCommandLine(line string) ([]string, error) {
+	var args []string
+	var current strings.Builder
+	inArg := false
+	var quote rune
+	runes := []rune(line)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case quote == '"':
+			switch {
+			case r == '"':
+				quote = 0
+			case r == '\\' && i+1 < len(runes) && strings.ContainsRune("\"\\$`", runes[i+1]):
+				i++
+				current.WriteRune(runes[i])
+			default:
+				current.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote, inArg = r, true
+		case r == '\\':
+			if i+1 >= len(runes) {
+				return nil, fmt.Errorf("backslash at the end of the command")
+			}
+			i++
+			current.WriteRune(runes[i])
+			inArg = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated quote")
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
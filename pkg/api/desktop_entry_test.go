@@ -0,0 +1,230 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestExecLineRoundTrip(t *testing.T) {
+	tests := [][]string{
+		{"zoom"},
+		{"/opt/My App/run", "%U"},
+		{"env", "GDK_SCALE=2", "/usr/bin/app", "--name=a b"},
+		{"app", "100%", "$HOME", "`id`", `quote"d`, `back\slash`, "semi;colon", ""},
+		{"box64", "/home/pi/.wine/drive_c/Program Files/Game.exe", "%F"},
+	}
+	for _, args := range tests {
+		line := execLine(args)
+		parsed, err := parseExecLine(line)
+		if err != nil {
+			t.Errorf("parseExecLine(execLine(%q)) = %v", args, err)
+			continue
+		}
+		if !slices.Equal(parsed, args) {
+			t.Errorf("Exec=%s parses to %q, want %q", line, parsed, args)
+		}
+	}
+}
+
+func TestParseExecLineErrors(t *testing.T) {
+	for _, line := range []string{
+		"",
+		"app \"unterminated",
+		"app \"%U\"",
+		"app %z",
+		"app 100%",
+		"app $HOME",
+		"app a\"b\"",
+		`app "bad \escape"`,
+	} {
+		if args, err := parseExecLine(line); err == nil {
+			t.Errorf("parseExecLine(%q) = %q, want an error", line, args)
+		}
+	}
+}
+
+func TestDesktopEntryRender(t *testing.T) {
+	icon := filepath.Join(t.TempDir(), "icon.png")
+	writeTestFile(t, icon, "png")
+
+	entry := DesktopEntry{
+		Name:           "My App",
+		Comment:        "Line one\nLine two",
+		Exec:           []string{"/opt/My App/run", "--scale=100%", "%U"},
+		Icon:           icon,
+		Categories:     []string{"Game", "Emulator"},
+		StartupWMClass: "my-app",
+	}
+	content, err := entry.Render("My App (beta)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"[Desktop Entry]\nType=Application\nName=My App\n",
+		"Comment=Line one\\nLine two\n",
+		`Exec="/opt/My App/run" --scale=100%% %U` + "\n",
+		"Icon=" + icon + "\n",
+		"Terminal=false\n",
+		"Categories=Game;Emulator;\n",
+		"StartupWMClass=my-app\n",
+		desktopEntryAppKey + "=My App (beta)\n",
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("rendered entry is missing %q:\n%s", want, content)
+		}
+	}
+	if problems := CheckDesktopEntry(content); len(problems) > 0 {
+		t.Errorf("CheckDesktopEntry of a rendered entry = %q", problems)
+	}
+	if got := entry.desktopFileName("My App (beta)"); got != "My-App-beta" {
+		t.Errorf("desktopFileName = %q, want My-App-beta", got)
+	}
+
+	invalid := []DesktopEntry{
+		{Exec: []string{"app"}},
+		{Name: "App"},
+		{Name: "App", Exec: []string{"%U"}},
+		{Name: "App", Exec: []string{"app"}, FileName: "bad name"},
+		{Name: "App", Exec: []string{"app"}, Icon: "icons/app.png"},
+		{Name: "App", Exec: []string{"app"}, Icon: "/does/not/exist.png"},
+		{Name: "App", Exec: []string{"app"}, Path: "relative"},
+		{Name: "App", Exec: []string{"app"}, Categories: []string{"Game;Utility"}},
+	}
+	for _, entry := range invalid {
+		if _, err := entry.Render("App"); err == nil {
+			t.Errorf("Render(%+v) succeeded, want an error", entry)
+		}
+	}
+}
+
+func TestCheckDesktopEntry(t *testing.T) {
+	tests := []struct {
+		content string
+		problem string // part of the expected problem, "" if the entry is valid
+	}{
+		{"[Desktop Entry]\nType=Application\nName=App\nExec=app %U\nIcon=app\nCategories=Game;\n", ""},
+		{"# comment\n\n[Desktop Entry]\nType=Link\nName=Site\nURL=https://example.com\n", ""},
+		{"Type=Application\n", "outside of a group"},
+		{"", "group is missing"},
+		{"[Other]\nName=App\n[Desktop Entry]\nType=Application\nName=App\nExec=app\n", "first group"},
+		{"[Desktop Entry]\nName=App\nExec=app\n", "Type is missing"},
+		{"[Desktop Entry]\nType=Program\nName=App\n", "unknown Type"},
+		{"[Desktop Entry]\nType=Application\nExec=app\n", "Name is missing"},
+		{"[Desktop Entry]\nType=Application\nName=App\n", "Exec is required"},
+		{"[Desktop Entry]\nType=Application\nName=App\nName=Other\nExec=app\n", "set twice"},
+		{"[Desktop Entry]\nType=Application\nName=App\nExec=app\nTerminal=yes\n", "Terminal"},
+		{"[Desktop Entry]\nType=Application\nName=App\nExec=app\nIcon=icons/app.png\n", "relative path"},
+		{"[Desktop Entry]\nType=Application\nName=App\nExec=app\nCategories=Game\n", "semicolon"},
+		{"[Desktop Entry]\nType=Application\nName=App\nExec=app \"%U\"\n", "invalid Exec"},
+		{"[Desktop Entry]\nType=Application\nName=App\nExec=app\nbroken line\n", "not a key=value pair"},
+		{"[Desktop Entry]\nType=Application\nName=App\nExec=app\nBad Key=1\n", "invalid key"},
+	}
+	for _, tt := range tests {
+		problems := CheckDesktopEntry(tt.content)
+		if tt.problem == "" {
+			if len(problems) > 0 {
+				t.Errorf("CheckDesktopEntry(%q) = %q, want no problems", tt.content, problems)
+			}
+			continue
+		}
+		if !slices.ContainsFunc(problems, func(problem string) bool { return strings.Contains(problem, tt.problem) }) {
+			t.Errorf("CheckDesktopEntry(%q) = %q, want a problem about %q", tt.content, problems, tt.problem)
+		}
+	}
+}
+
+func TestParseDesktopEntryPairs(t *testing.T) {
+	entry, app, err := ParseDesktopEntryPairs([]string{
+		"app=Box64 (x86_64)",
+		"name=Box64 Shell",
+		`exec=box64 "/opt/My Game/game" --level='a b' \$HOME`,
+		"categories=Game; Emulator,System",
+		"terminal=true",
+		"file=box64-shell.desktop",
+		"wmclass=box64",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if app != "Box64 (x86_64)" || entry.Name != "Box64 Shell" || !entry.Terminal || entry.FileName != "box64-shell" || entry.StartupWMClass != "box64" {
+		t.Errorf("ParseDesktopEntryPairs = %+v for %q", entry, app)
+	}
+	if want := []string{"box64", "/opt/My Game/game", "--level=a b", "$HOME"}; !slices.Equal(entry.Exec, want) {
+		t.Errorf("exec = %q, want %q", entry.Exec, want)
+	}
+	if want := []string{"Game", "Emulator", "System"}; !slices.Equal(entry.Categories, want) {
+		t.Errorf("categories = %q, want %q", entry.Categories, want)
+	}
+
+	for _, pairs := range [][]string{{"name"}, {"color=red"}, {"terminal=maybe"}, {"exec=app 'unterminated"}} {
+		if _, _, err := ParseDesktopEntryPairs(pairs); err == nil {
+			t.Errorf("ParseDesktopEntryPairs(%q) succeeded, want an error", pairs)
+		}
+	}
+}
+
+func TestCreateAndRemoveDesktopEntries(t *testing.T) {
+	newTestPiAppsDir(t, "Zoom", "Other")
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("PATH", "") // no update-desktop-database
+
+	path, err := CreateDesktopEntry("Zoom", DesktopEntry{Name: "Zoom", Exec: []string{"/opt/zoom/ZoomLauncher", "%U"}, Icon: "zoom"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(home, ".local", "share", "applications", "Zoom.desktop"); path != want {
+		t.Errorf("CreateDesktopEntry wrote %s, want %s", path, want)
+	}
+	if files, _ := ReadInstalledFiles("Zoom"); !slices.Contains(files, path) {
+		t.Errorf("the install manifest of Zoom = %q, want it to list %s", files, path)
+	}
+
+	// Another app can't take over the entry
+	if _, err := CreateDesktopEntry("Other", DesktopEntry{Name: "Other", Exec: []string{"other"}, FileName: "Zoom"}); err == nil {
+		t.Error("CreateDesktopEntry overwrote the entry of another app")
+	}
+
+	// Entries the user replaced in the meantime are not Pi-Apps' to remove
+	manual := filepath.Join(home, ".local", "share", "applications", "zoom-manual.desktop")
+	writeTestFile(t, manual, "[Desktop Entry]\nType=Application\nName=Zoom\nExec=zoom\n")
+	if err := recordInstalledFile("Zoom", manual); err != nil {
+		t.Fatal(err)
+	}
+
+	removed, err := RemoveDesktopEntries("Zoom")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(removed, []string{path}) {
+		t.Errorf("RemoveDesktopEntries = %q, want only %s", removed, path)
+	}
+	if FileExists(path) || !FileExists(manual) {
+		t.Error("RemoveDesktopEntries removed the wrong files")
+	}
+	if files, _ := ReadInstalledFiles("Zoom"); slices.Contains(files, path) {
+		t.Errorf("%s is still in the install manifest", path)
+	}
+	if _, err := os.Stat(filepath.Dir(path)); err != nil {
+		t.Error("the applications directory was removed")
+	}
+}
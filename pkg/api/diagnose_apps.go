@@ -21,17 +21,14 @@
 package api
 
 import (
+	"bufio"
+	"errors"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"sort"
 	"strings"
 	"time"
-
-	"github.com/gotk3/gotk3/gdk"
-	"github.com/gotk3/gotk3/glib"
-	"github.com/gotk3/gotk3/gtk"
 )
 
 // AppError represents an application action that failed
@@ -43,12 +40,154 @@ type AppError struct {
 
 // DiagnoseResult contains the user's choice after diagnosis
 type DiagnoseResult struct {
-	Action    string // "send", "retry", "next", "close"
-	AppName   string // The name of the app that was diagnosed
-	ActionStr string // The original action string (e.g., "install;appname")
+	Action     string // "send", "retry", "next", "close"
+	AppName    string // The name of the app that was diagnosed
+	ActionStr  string // The original action string (e.g., "install;appname")
+	Screenshot string // Path to an opt-in screenshot to attach to the error report, empty if none
+}
+
+// Diagnosis is what Pi-Apps found out about a failed action from its log, without asking the user anything
+type Diagnosis struct {
+	Action        string   `json:"action"`
+	App           string   `json:"app"`
+	ErrorType     string   `json:"error_type"` // system, package, internet, user or unknown
+	Captions      []string `json:"captions"`   // explanations of what went wrong and how to fix it
+	LogFile       string   `json:"log_file"`
+	CanReport     bool     `json:"can_report"`               // an error report can be sent for the failure
+	ReportBlocked string   `json:"report_blocked,omitempty"` // why no error report can be sent
+}
+
+// ActionStr returns the failure as the "action;app" line of the failure list
+func (d Diagnosis) ActionStr() string {
+	return QueueLine(d.Action, d.App)
+}
+
+// DiagnoseFailures analyses the logs of failed actions without any UI, for scripts and headless runs
+// failureList format: "action;app" entries separated by newlines, optionally followed by a tab and the log file
+// of the failure, see FailureLine. Without one the newest failed log of the app is diagnosed.
+//
+//	[]Diagnosis - one per valid entry, in the order of the list
+//	error - the entries that are not valid or whose log can't be read, the others are diagnosed anyway
+func DiagnoseFailures(failureList string) ([]Diagnosis, error) {
+	return diagnoseFailures(failureList, false)
+}
+
+// diagnoseFailures is DiagnoseFailures, allowWrite lets LogDiagnose append details for developers to the logs
+// before they are sent in an error report
+func diagnoseFailures(failureList string, allowWrite bool) ([]Diagnosis, error) {
+	var diagnoses []Diagnosis
+	var problems []error
+	for _, failure := range strings.Split(failureList, "\n") {
+		if strings.TrimSpace(failure) == "" {
+			continue
+		}
+		failure, logFile, _ := strings.Cut(failure, "\t")
+		action, app, ok := ParseQueueLine(failure)
+		if !ok {
+			problems = append(problems, fmt.Errorf("invalid failure %q, expected 'action;app'", failure))
+			continue
+		}
+		if ValidateAppName(app) != nil {
+			problems = append(problems, fmt.Errorf("invalid app name in failure %q", failure))
+			continue
+		}
+		if logFile = strings.TrimSpace(logFile); logFile == "" {
+			logFile = GetLogfile(app)
+		}
+		diagnosis := Diagnosis{Action: action, App: app, Captions: []string{}, LogFile: logFile}
+
+		if FileExists(diagnosis.LogFile) {
+			result, err := LogDiagnose(diagnosis.LogFile, allowWrite)
+			if err != nil {
+				problems = append(problems, fmt.Errorf("failed to diagnose the log of %s: %w", app, err))
+				continue
+			}
+			diagnosis.ErrorType = result.ErrorType
+			diagnosis.Captions = append(diagnosis.Captions, result.Captions...)
+			diagnosis.CanReport, diagnosis.ReportBlocked = CheckCanSendErrorReport(app, action, diagnosis.ErrorType)
+		} else {
+			diagnosis.ErrorType = "unknown"
+			diagnosis.ReportBlocked = "Error report cannot be sent because there is no log file."
+		}
+		if diagnosis.CanReport {
+			diagnosis.ReportBlocked = ""
+		}
+		diagnoses = append(diagnoses, diagnosis)
+	}
+	return diagnoses, errors.Join(problems...)
+}
+
+// printDiagnosis explains a failed action in the terminal
+func printDiagnosis(diagnosis Diagnosis, failure, failures int) {
+	fmt.Fprintln(os.Stderr)
+	ErrorNoExit(ActionErrorTitle(diagnosis.Action, diagnosis.App, failure, failures))
+	if diagnosis.ErrorType != "" {
+		fmt.Fprintln(os.Stderr, Tf("Error type: %s", diagnosis.ErrorType))
+	}
+	for _, caption := range diagnosis.Captions {
+		fmt.Fprintln(os.Stderr, caption)
+	}
+	fmt.Fprintln(os.Stderr, Tf("Log file: %s", diagnosis.LogFile))
+	if diagnosis.ReportBlocked != "" {
+		fmt.Fprintln(os.Stderr, diagnosis.ReportBlocked)
+	}
 }
 
-// GetLogfile returns the path to the log file for an app
+// DiagnoseAppsInTerminal explains each failed action in the terminal and asks once whether to retry them. It is
+// used where no dialog can be shown and nobody may be watching, so nothing is retried when there is no answer
+// within timeout or no terminal on stdin to answer on.
+// failureList format: "action;app" entries separated by newlines, optionally followed by a tab and the log file
+// of the failure, see FailureLine. Without one the newest failed log of the app is diagnosed.
+func DiagnoseAppsInTerminal(failureList string, timeout time.Duration) []DiagnoseResult {
+	diagnoses, err := diagnoseFailures(failureList, true)
+	if err != nil {
+		Warning(err.Error())
+	}
+	for i, diagnosis := range diagnoses {
+		printDiagnosis(diagnosis, i+1, len(diagnoses))
+	}
+	if len(diagnoses) == 0 {
+		return nil
+	}
+
+	action := "next"
+	if stdinIsTerminal() {
+		fmt.Fprintln(os.Stderr)
+		answer, answered := readTerminalLineWithTimeout(Tf("Retry the failed actions? [y/N] (no in %d seconds) ", int(timeout.Seconds())), timeout)
+		if !answered {
+			fmt.Fprintln(os.Stderr)
+			StatusT("No answer, not retrying.")
+		}
+		if strings.EqualFold(answer, "y") || strings.EqualFold(answer, "yes") {
+			action = "retry"
+		}
+	}
+
+	results := make([]DiagnoseResult, 0, len(diagnoses))
+	for _, diagnosis := range diagnoses {
+		results = append(results, DiagnoseResult{Action: action, AppName: diagnosis.App, ActionStr: diagnosis.ActionStr()})
+	}
+	return results
+}
+
+// readTerminalLineWithTimeout asks a question on stderr and reads the answer from stdin, answered is false when
+// there was none within timeout. The read is left waiting then, the process is about to finish anyway.
+func readTerminalLineWithTimeout(prompt string, timeout time.Duration) (answer string, answered bool) {
+	fmt.Fprint(os.Stderr, prompt)
+	lines := make(chan string, 1)
+	go func() {
+		line, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		lines <- strings.TrimSpace(line)
+	}()
+	select {
+	case line := <-lines:
+		return line, true
+	case <-time.After(timeout):
+		return "", false
+	}
+}
+
+// GetLogfile returns the path to the log file for an app, or "" if the app name is not valid
 func GetLogfile(appName string) string {
 	// Default location where Pi-Apps stores logs
 	piAppsDir := GetPiAppsDir()
@@ -56,13 +195,19 @@ func GetLogfile(appName string) string {
 		piAppsDir = "."
 	}
 
-	logsDir := filepath.Join(piAppsDir, "logs")
+	logsDir := LogsDirOf(piAppsDir)
+
+	// An invalid name could point outside of the logs directory
+	if err := ValidateAppName(appName); err != nil {
+		Debug(fmt.Sprintf("GetLogfile: %v", err))
+		return ""
+	}
 
 	// Read the logs directory
 	files, err := os.ReadDir(logsDir)
 	if err != nil {
 		fmt.Printf("Error reading logs directory: %v\n", err)
-		return filepath.Join(piAppsDir, "logs", appName)
+		return filepath.Join(LogsDirOf(piAppsDir), appName)
 	}
 
 	// Create a slice to store file info with modification times
@@ -80,30 +225,9 @@ func GetLogfile(appName string) string {
 
 		fileName := file.Name()
 
-		// Skip success logs (matching the bash version's grep -v 'success')
-		if strings.Contains(fileName, "success") {
-			continue
-		}
-
-		// Look for files matching log file patterns:
-		// - "action-fail-appname.log" (e.g., "install-fail-Eagle CAD.log")
-		// - "action-incomplete-appname.log" (e.g., "install-incomplete-Eagle CAD.log")
-		// - Files containing "-appname-" and ending with ".log"
-		// - Files ending with "-appname.log"
-		matches := false
-		if strings.HasSuffix(fileName, ".log") {
-			// Check for patterns: action-fail-appname.log or action-incomplete-appname.log
-			if strings.HasSuffix(fileName, fmt.Sprintf("-%s.log", appName)) {
-				matches = true
-			} else if strings.Contains(fileName, fmt.Sprintf("-fail-%s.log", appName)) {
-				matches = true
-			} else if strings.Contains(fileName, fmt.Sprintf("-incomplete-%s.log", appName)) {
-				matches = true
-			} else if strings.Contains(fileName, fmt.Sprintf("-%s-", appName)) {
-				// Pattern with dashes on both sides
-				matches = true
-			}
-		}
+		// Only failed and incomplete logs of exactly this app match, not the ones of "Better <app>" or "<app>-Extra"
+		logApp, result, ok := ParseLogFileName(fileName)
+		matches := ok && logApp == appName && result != "success"
 
 		if matches {
 			filePath := filepath.Join(logsDir, fileName)
@@ -128,7 +252,107 @@ func GetLogfile(appName string) string {
 	}
 
 	// Return the default path if no matching logs found
-	return filepath.Join(piAppsDir, "logs", appName)
+	return filepath.Join(LogsDirOf(piAppsDir), appName)
+}
+
+// AppLogfileSince returns the newest log file of an app, whatever its result, that was written at or after since,
+// or "" if there is none
+func AppLogfileSince(appName string, since time.Time) string {
+	if ValidateAppName(appName) != nil {
+		return ""
+	}
+	logsDir := GetLogsDir()
+	files, err := os.ReadDir(logsDir)
+	if err != nil {
+		return ""
+	}
+	// File systems with a coarse time resolution record the time of a log written right away a bit earlier
+	since = since.Truncate(time.Second)
+	var newest string
+	var newestTime time.Time
+	for _, file := range files {
+		if file.IsDir() {
+			continue
+		}
+		if logApp, _, ok := ParseLogFileName(file.Name()); !ok || logApp != appName {
+			continue
+		}
+		info, err := file.Info()
+		if err != nil || info.ModTime().Before(since) || !info.ModTime().After(newestTime) {
+			continue
+		}
+		newest = filepath.Join(logsDir, file.Name())
+		newestTime = info.ModTime()
+	}
+	return newest
+}
+
+// logResults are the results a log file name records, from the moment its script starts until it finishes
+var logResults = []string{"incomplete", "fail", "success"}
+
+// Every run of an app script gets its own log file, named after the time it started so a later run
+// doesn't append to or replace it: install-incomplete-Zoom@20261015-153012.000.log.
+// App names can't contain the separator, see ValidateAppName.
+const (
+	logTimeSeparator = "@"
+	logTimeFormat    = "20060102-150405.000"
+)
+
+// newLogfilePath returns the path of a new log file for an app script that starts now
+func newLogfilePath(logDir, action, app string) string {
+	return filepath.Join(logDir, fmt.Sprintf("%s-incomplete-%s%s%s.log", action, app, logTimeSeparator, time.Now().Format(logTimeFormat)))
+}
+
+// trimLogTime removes the start time from the base name of a log file, older logs don't have one
+func trimLogTime(base string) string {
+	if i := strings.LastIndex(base, logTimeSeparator); i != -1 {
+		return base[:i]
+	}
+	return base
+}
+
+// ParseLogFileName returns the app and result of a log file name like "install-fail-Better Chromium.log"
+// or "install-fail-Better Chromium@20261015-153012.000.log", ok is false if the name is not one of an app log.
+// The action may contain dashes, like install-64, and the app name may contain spaces and dashes.
+func ParseLogFileName(name string) (app, result string, ok bool) {
+	base, found := strings.CutSuffix(name, ".log")
+	if !found {
+		return "", "", false
+	}
+	base = trimLogTime(base)
+	for _, result := range logResults {
+		if _, app, found := strings.Cut(base, "-"+result+"-"); found && app != "" {
+			return app, result, true
+		}
+	}
+	return "", "", false
+}
+
+// LatestAppLog returns the most recently changed log of an app in logsDir, "" if it has none.
+// With failedOnly, logs of successful actions are skipped.
+func LatestAppLog(logsDir, app string, failedOnly bool) string {
+	entries, err := os.ReadDir(logsDir)
+	if err != nil {
+		return ""
+	}
+	var latest string
+	var latestTime time.Time
+	for _, entry := range entries {
+		logApp, result, ok := ParseLogFileName(entry.Name())
+		if !ok || logApp != app || failedOnly && result == "success" {
+			continue
+		}
+		if info, err := entry.Info(); err == nil && info.ModTime().After(latestTime) {
+			latest, latestTime = filepath.Join(logsDir, entry.Name()), info.ModTime()
+		}
+	}
+	return latest
+}
+
+// logPathWithResult returns the path of a log file after its script finished with result, fail or success.
+// Only the file name changes, so a Pi-Apps directory with "-incomplete-" in its path is left alone.
+func logPathWithResult(logPath, result string) string {
+	return filepath.Join(filepath.Dir(logPath), strings.Replace(filepath.Base(logPath), "-incomplete-", "-"+result+"-", 1))
 }
 
 // CapitalizeFirst capitalizes the first letter of a string
@@ -156,7 +380,10 @@ func CheckCanSendErrorReport(app, action, errorType string) (bool, string) {
 		return false, PackageAppNoErrorReporting
 	}
 
-	// Check 2: Check error type - cannot send reports for system, internet, or package errors
+	// Check 2: Check error type - cannot send reports for declined prompts, system, internet, or package errors
+	if errorType == "user" {
+		return false, "Error report cannot be sent because you declined a prompt of the script."
+	}
 	if errorType == "system" || errorType == "internet" || errorType == "package" {
 		return false, "Error report cannot be sent because this is not an issue with Pi-Apps."
 	}
@@ -221,367 +448,3 @@ func CheckCanSendErrorReport(app, action, errorType string) (bool, string) {
 
 	return true, "" // Can send error report
 }
-
-// loadImage loads an image from a file path and returns a new GtkImage
-func loadImage(path string) (*gtk.Image, error) {
-	pixbuf, err := gdk.PixbufNewFromFile(path)
-	if err != nil {
-		return nil, err
-	}
-
-	// Scale the image if it's too large
-	width := pixbuf.GetWidth()
-	height := pixbuf.GetHeight()
-
-	if width > 64 || height > 64 {
-		scale := 64.0 / float64(width)
-		if height > width {
-			scale = 64.0 / float64(height)
-		}
-
-		newWidth := int(float64(width) * scale)
-		newHeight := int(float64(height) * scale)
-
-		pixbuf, err = pixbuf.ScaleSimple(newWidth, newHeight, gdk.INTERP_BILINEAR)
-		if err != nil {
-			return nil, err
-		}
-	}
-
-	image, err := gtk.ImageNewFromPixbuf(pixbuf)
-	if err != nil {
-		return nil, err
-	}
-
-	return image, nil
-}
-
-// DiagnoseApps presents GTK3-based error diagnosis dialogs for a list of failed actions
-// failureList format: "action;app" entries separated by newlines
-func DiagnoseApps(failureList string) []DiagnoseResult {
-	// Debug output
-	fmt.Printf("Diagnosing app failures: %s\n", failureList)
-
-	// Set program name
-	glib.SetPrgname("Pi-Apps")
-
-	// Initialize GTK
-	gtk.Init(nil)
-
-	// Split the failure list into lines
-	failures := strings.Split(strings.TrimSpace(failureList), "\n")
-	numFailures := len(failures)
-	fmt.Printf("Found %d failures to diagnose\n", numFailures)
-
-	var results []DiagnoseResult
-
-	// Process each failure
-	for i, failure := range failures {
-		if failure == "" {
-			continue
-		}
-
-		// Parse action and app name
-		parts := strings.SplitN(failure, ";", 2)
-		if len(parts) != 2 {
-			WarningT("Invalid failure format: %s (expected 'action;app')\n", failure)
-			continue
-		}
-		action := parts[0]
-		appName := parts[1]
-
-		fmt.Printf("Diagnosing %s action for app: %s\n", action, appName)
-
-		// Get log file path
-		logFile := GetLogfile(appName)
-		fmt.Printf("Using logfile: %s\n", logFile)
-
-		if !FileExists(logFile) {
-			WarningT("Log file does not exist: %s\n", logFile)
-			// Attempt to create a blank log file for diagnosis
-			os.WriteFile(logFile, []byte("No log file found for this app."), 0644)
-		}
-
-		// Diagnose the error
-		diagnosis, err := LogDiagnose(logFile, true)
-		if err != nil {
-			fmt.Printf("Error diagnosing log: %v\n", err)
-			continue // Skip if diagnosis fails
-		}
-
-		errorType := diagnosis.ErrorType
-		errorCaption := strings.Join(diagnosis.Captions, "\n")
-		fmt.Printf("Diagnosis found error type: %s\n", errorType)
-
-		// Create the dialog window
-		dialog, err := gtk.DialogNew()
-		if err != nil {
-			fmt.Printf("Error creating dialog: %v\n", err)
-			continue // Skip if dialog creation fails
-		}
-		dialog.SetTitle(fmt.Sprintf("Error occurred when %sing %s (%d/%d)",
-			strings.Replace(action, "update", "updat", 1), appName, i+1, numFailures))
-		dialog.SetModal(true)
-		dialog.SetDefaultSize(700, 400)
-
-		// Set dialog class - for proper styling
-		dialog.SetName("Pi-Apps")
-
-		// Get the content area
-		contentArea, err := dialog.GetContentArea()
-		if err != nil {
-			dialog.Destroy()
-			continue
-		}
-		contentArea.SetSpacing(12)
-		contentArea.SetMarginStart(12)
-		contentArea.SetMarginEnd(12)
-		contentArea.SetMarginTop(12)
-		contentArea.SetMarginBottom(12)
-
-		// Create header box
-		headerBox, err := gtk.BoxNew(gtk.ORIENTATION_HORIZONTAL, 12)
-		if err != nil {
-			dialog.Destroy()
-			continue
-		}
-
-		// Add error icon
-		iconPath := filepath.Join(GetPiAppsDir(), "icons", "error.png")
-		if icon, err := loadImage(iconPath); err == nil {
-			headerBox.PackStart(icon, false, false, 0)
-		}
-
-		// Prepare header text
-		var headerText string
-		if errorType == "unknown" {
-			headerText = fmt.Sprintf("<b>%s</b> failed to %s for an <b>unknown</b> reason.",
-				CapitalizeFirst(appName), action)
-		} else {
-			article := "a"
-			if strings.Contains("aeiou", string(errorType[0])) {
-				article = "an"
-			}
-			headerText = fmt.Sprintf("<b>%s</b> failed to %s because Pi-Apps encountered %s <b>%s</b> error.",
-				CapitalizeFirst(appName), action, article, errorType)
-		}
-
-		// Check if we can send an error report
-		canSend, reason := CheckCanSendErrorReport(appName, action, errorType)
-		if !canSend {
-			headerText += "\n" + reason
-		}
-
-		// Add support links
-		appType, _ := AppType(appName)
-
-		// TODO: Change this below message depending on the package manager being used.
-		if appType == "package" {
-			headerText += "\n" + PackageAppErrorMessage
-		} else {
-			account, repo := GetGitUrl()
-			if account == "" || repo == "" {
-				headerText += "\nSupport is available on <a href=\"https://discord.gg/RXSTvaUvuu\">Discord</a> and <a href=\"https://github.com/pi-apps-go/pi-apps-go/issues/new/choose\">Github</a>."
-			} else {
-				headerText += fmt.Sprintf("\nSupport is available on <a href=\"https://discord.gg/RXSTvaUvuu\">Discord</a> and <a href=\"https://github.com/%s/%s/issues/new/choose\">Github</a>.", account, repo)
-			}
-		}
-
-		// If we have no error caption, tell user to view the log
-		if errorCaption == "" {
-			headerText += "\nYou can view the terminal output below. (scroll down)"
-			// Get content of log file
-			content, err := os.ReadFile(logFile)
-			if err == nil {
-				errorCaption = string(content)
-			}
-		} else {
-			headerText += "\nBelow, Pi-Apps explains what went wrong and how you can fix it."
-		}
-
-		// Create the header label with rich text
-		headerLabel, err := gtk.LabelNew("")
-		if err != nil {
-			dialog.Destroy()
-			continue
-		}
-		headerLabel.SetMarkup(headerText)
-		headerLabel.SetLineWrap(true)
-		headerLabel.SetMaxWidthChars(80)
-		headerLabel.SetJustify(gtk.JUSTIFY_LEFT)
-		headerLabel.SetHAlign(gtk.ALIGN_START)
-		headerLabel.SetVAlign(gtk.ALIGN_START)
-		headerBox.PackStart(headerLabel, true, true, 0)
-
-		contentArea.PackStart(headerBox, false, false, 0)
-
-		// Create scrolled window for log text
-		scrollWin, err := gtk.ScrolledWindowNew(nil, nil)
-		if err != nil {
-			dialog.Destroy()
-			continue
-		}
-		scrollWin.SetHExpand(true)
-		scrollWin.SetVExpand(true)
-		scrollWin.SetPolicy(gtk.POLICY_AUTOMATIC, gtk.POLICY_AUTOMATIC)
-
-		// Create text view for error caption
-		textView, err := gtk.TextViewNew()
-		if err != nil {
-			dialog.Destroy()
-			continue
-		}
-		textView.SetEditable(false)
-		textView.SetWrapMode(gtk.WRAP_WORD_CHAR)
-
-		buffer, err := textView.GetBuffer()
-		if err != nil {
-			dialog.Destroy()
-			continue
-		}
-		buffer.SetText(errorCaption)
-
-		scrollWin.Add(textView)
-		contentArea.PackStart(scrollWin, true, true, 0)
-
-		// Add View Log button as action widget with custom response ID
-		viewLogButton, err := gtk.ButtonNewWithLabel("View Log")
-		if err != nil {
-			dialog.Destroy()
-			continue
-		}
-		// Try to add icon
-		iconPath = filepath.Join(GetPiAppsDir(), "icons", "log-file.png")
-		if icon, err := gtk.ImageNewFromFile(iconPath); err == nil {
-			viewLogButton.SetImage(icon)
-			viewLogButton.SetAlwaysShowImage(true)
-		}
-		// Add to dialog with custom response ID (we'll handle this specially)
-		dialog.AddActionWidget(viewLogButton, 100) // Custom response ID for View Log
-
-		// Send Report button (if applicable)
-		if canSend {
-			sendReportButton, err := gtk.ButtonNewWithLabel("Send Report")
-			if err != nil {
-				dialog.Destroy()
-				continue
-			}
-			// Try to add icon
-			iconPath = filepath.Join(GetPiAppsDir(), "icons", "send-error-report.png")
-			if icon, err := gtk.ImageNewFromFile(iconPath); err == nil {
-				sendReportButton.SetImage(icon)
-				sendReportButton.SetAlwaysShowImage(true)
-			}
-			dialog.AddActionWidget(sendReportButton, gtk.RESPONSE_APPLY) // Custom response for Send Report
-		}
-
-		// Retry button
-		retryButton, err := gtk.ButtonNewWithLabel("Retry")
-		if err != nil {
-			dialog.Destroy()
-			continue
-		}
-		// Try to add icon
-		iconPath = filepath.Join(GetPiAppsDir(), "icons", "refresh.png")
-		if icon, err := gtk.ImageNewFromFile(iconPath); err == nil {
-			retryButton.SetImage(icon)
-			retryButton.SetAlwaysShowImage(true)
-		}
-		dialog.AddActionWidget(retryButton, gtk.RESPONSE_OK) // RESPONSE_OK for Retry
-
-		// Close/Next button
-		var closeButton *gtk.Button
-		if i < numFailures-1 {
-			closeButton, err = gtk.ButtonNewWithLabel("Next Error")
-			if err != nil {
-				dialog.Destroy()
-				continue
-			}
-			// Try to add icon
-			iconPath = filepath.Join(GetPiAppsDir(), "icons", "forward.png")
-			if icon, err := gtk.ImageNewFromFile(iconPath); err == nil {
-				closeButton.SetImage(icon)
-				closeButton.SetAlwaysShowImage(true)
-			}
-		} else {
-			closeButton, err = gtk.ButtonNewWithLabel("Close")
-			if err != nil {
-				dialog.Destroy()
-				continue
-			}
-			// Try to add icon
-			iconPath = filepath.Join(GetPiAppsDir(), "icons", "exit.png")
-			if icon, err := gtk.ImageNewFromFile(iconPath); err == nil {
-				closeButton.SetImage(icon)
-				closeButton.SetAlwaysShowImage(true)
-			}
-		}
-		dialog.AddActionWidget(closeButton, gtk.RESPONSE_CANCEL) // RESPONSE_CANCEL for Close/Next
-
-		// Show all widgets
-		dialog.ShowAll()
-
-		// Run the dialog and process the response in a loop to handle View Log
-		for {
-			response := dialog.Run()
-
-			// Process response
-			switch response {
-			case 100: // View Log - handle without closing dialog
-				// Get the directory where the binary is running from
-				exePath, err := os.Executable()
-				if err != nil {
-					fmt.Printf("Error getting executable path: %v\n", err)
-					continue // Stay in loop, don't close dialog
-				}
-
-				// Launch a separate process for viewing the log file
-				// to avoid conflicts with the current GTK main loop
-				cmd := exec.Command(exePath, "view_file", logFile)
-				cmd.Env = append(os.Environ(), "DISPLAY="+os.Getenv("DISPLAY"))
-				err = cmd.Start()
-				if err != nil {
-					fmt.Printf("Error opening log viewer: %v\n", err)
-				}
-				// Continue the loop to keep dialog open
-				continue
-			case gtk.RESPONSE_OK: // Retry
-				results = append(results, DiagnoseResult{
-					Action:    "retry",
-					AppName:   appName,
-					ActionStr: failure,
-				})
-			case gtk.RESPONSE_APPLY: // Send Report
-				results = append(results, DiagnoseResult{
-					Action:    "send",
-					AppName:   appName,
-					ActionStr: failure,
-				})
-			case gtk.RESPONSE_CANCEL: // Close/Next
-				results = append(results, DiagnoseResult{
-					Action:    "next",
-					AppName:   appName,
-					ActionStr: failure,
-				})
-			default: // Any other response (e.g., window closed)
-				results = append(results, DiagnoseResult{
-					Action:    "close",
-					AppName:   appName,
-					ActionStr: failure,
-				})
-			}
-			// Exit the loop to close dialog
-			break
-		}
-
-		// Destroy the dialog
-		dialog.Destroy()
-	}
-
-	// Process GTK events to ensure proper cleanup
-	for gtk.EventsPending() {
-		gtk.MainIteration()
-	}
-
-	return results
-}
@@ -0,0 +1,504 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: diagnose_apps_gui.go
+// Description: Provides the GTK error diagnosis dialogs shown after failed app actions.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+//go:build !nogui
+
+package api
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gotk3/gotk3/gdk"
+	"github.com/gotk3/gotk3/glib"
+	"github.com/gotk3/gotk3/gtk"
+)
+
+// loadImage loads an image from a file path and returns a new GtkImage
+func loadImage(path string) (*gtk.Image, error) {
+	pixbuf, err := gdk.PixbufNewFromFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// Scale the image if it's too large
+	width := pixbuf.GetWidth()
+	height := pixbuf.GetHeight()
+
+	if width > 64 || height > 64 {
+		scale := 64.0 / float64(width)
+		if height > width {
+			scale = 64.0 / float64(height)
+		}
+
+		newWidth := int(float64(width) * scale)
+		newHeight := int(float64(height) * scale)
+
+		pixbuf, err = pixbuf.ScaleSimple(newWidth, newHeight, gdk.INTERP_BILINEAR)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	image, err := gtk.ImageNewFromPixbuf(pixbuf)
+	if err != nil {
+		return nil, err
+	}
+
+	return image, nil
+}
+
+// confirmScreenshot asks the user whether the whole screen may be captured for an error report
+func confirmScreenshot(parent *gtk.Dialog) bool {
+	dialog := gtk.MessageDialogNew(
+		parent,
+		gtk.DIALOG_MODAL,
+		gtk.MESSAGE_QUESTION,
+		gtk.BUTTONS_YES_NO,
+		"%s",
+		T("Pi-Apps will take a screenshot of your entire screen and attach it to the error report.\n\nPlease make sure no private information is visible before continuing.\n\nDo you want to continue?"),
+	)
+	if dialog == nil {
+		return false
+	}
+	dialog.SetTitle(T("Attach screenshot"))
+
+	response := dialog.Run()
+	dialog.Destroy()
+
+	return response == gtk.RESPONSE_YES
+}
+
+// captureScreenshot grabs the contents of the whole screen and saves it as a PNG file in the logs directory
+//
+// The diagnosis dialog is hidden while capturing so that the terminal window behind it is visible.
+func captureScreenshot(dialog *gtk.Dialog, appName string) (string, error) {
+	dialog.Hide()
+	defer dialog.Show()
+
+	// Let the window manager repaint the area that was covered by the dialog
+	for gtk.EventsPending() {
+		gtk.MainIteration()
+	}
+	time.Sleep(500 * time.Millisecond)
+	for gtk.EventsPending() {
+		gtk.MainIteration()
+	}
+
+	logsDir := GetLogsDir()
+	if err := EnsureDirOwned(logsDir, InvokingUser, InvokingUser); err != nil {
+		return "", fmt.Errorf("failed to create logs directory: %w", err)
+	}
+	path := filepath.Join(logsDir, fmt.Sprintf("screenshot-%s-%d.png", appName, time.Now().Unix()))
+
+	// Wayland compositors don't let apps read the root window, so a screenshot tool has to take it
+	if DisplaySessionInfo().IsWayland() {
+		if err := captureWaylandScreenshot(path); err != nil {
+			return "", err
+		}
+		return path, nil
+	}
+
+	screen, err := gdk.ScreenGetDefault()
+	if err != nil {
+		return "", fmt.Errorf("failed to get default screen: %w", err)
+	}
+	root, err := screen.GetRootWindow()
+	if err != nil {
+		return "", fmt.Errorf("failed to get root window: %w", err)
+	}
+
+	pixbuf, err := root.PixbufGetFromWindow(0, 0, root.WindowGetWidth(), root.WindowGetHeight())
+	if err != nil {
+		return "", fmt.Errorf("failed to capture screen: %w", err)
+	}
+
+	if err := pixbuf.SavePNG(path, 9); err != nil {
+		return "", fmt.Errorf("failed to save screenshot: %w", err)
+	}
+
+	return path, nil
+}
+
+// waylandScreenshotTools are the screenshot tools that work on Wayland, with the arguments to save to a file.
+// grim covers wlroots compositors like labwc and wayfire used by Raspberry Pi OS.
+var waylandScreenshotTools = [][]string{
+	{"grim"},
+	{"gnome-screenshot", "-f"},
+	{"spectacle", "-b", "-n", "-o"},
+}
+
+// captureWaylandScreenshot saves a screenshot to path with the first screenshot tool that is installed
+func captureWaylandScreenshot(path string) error {
+	for _, tool := range waylandScreenshotTools {
+		if !commandExists(tool[0]) {
+			continue
+		}
+		args := append(append([]string{}, tool[1:]...), path)
+		if output, err := exec.Command(tool[0], args...).CombinedOutput(); err != nil {
+			return fmt.Errorf("%s failed to capture screen: %w: %s", tool[0], err, strings.TrimSpace(string(output)))
+		}
+		return nil
+	}
+	return errors.New(T("no screenshot tool for Wayland found, install grim to attach screenshots"))
+}
+
+// DiagnoseApps presents GTK3-based error diagnosis dialogs for a list of failed actions
+// failureList format: "action;app" entries separated by newlines
+func DiagnoseApps(failureList string) []DiagnoseResult {
+	// Debug output
+	fmt.Printf("Diagnosing app failures: %s\n", failureList)
+
+	// Set program name
+	glib.SetPrgname("Pi-Apps")
+
+	// Initialize GTK
+	gtk.Init(nil)
+
+	// Analyse the failures before showing any dialog
+	diagnoses, err := diagnoseFailures(failureList, true)
+	if err != nil {
+		Warning(err.Error())
+	}
+	numFailures := len(diagnoses)
+	fmt.Printf("Found %d failures to diagnose\n", numFailures)
+
+	var results []DiagnoseResult
+
+	// Process each failure
+	for i, diagnosis := range diagnoses {
+		action := diagnosis.Action
+		appName := diagnosis.App
+		failure := diagnosis.ActionStr()
+		logFile := diagnosis.LogFile
+		fmt.Printf("Diagnosing %s action for app: %s\n", action, appName)
+		fmt.Printf("Using logfile: %s\n", logFile)
+
+		if !FileExists(logFile) {
+			WarningT("Log file does not exist: %s\n", logFile)
+			// Create a blank log file so the View Log button has something to show
+			os.WriteFile(logFile, []byte("No log file found for this app."), 0644)
+		}
+
+		errorType := diagnosis.ErrorType
+		errorCaption := strings.Join(diagnosis.Captions, "\n")
+		fmt.Printf("Diagnosis found error type: %s\n", errorType)
+
+		// Create the dialog window
+		dialog, err := gtk.DialogNew()
+		if err != nil {
+			fmt.Printf("Error creating dialog: %v\n", err)
+			continue // Skip if dialog creation fails
+		}
+		dialog.SetTitle(ActionErrorTitle(action, appName, i+1, numFailures))
+		dialog.SetModal(true)
+		dialog.SetDefaultSize(700, 400)
+
+		// Set dialog class - for proper styling
+		dialog.SetName("Pi-Apps")
+
+		// Get the content area
+		contentArea, err := dialog.GetContentArea()
+		if err != nil {
+			dialog.Destroy()
+			continue
+		}
+		contentArea.SetSpacing(12)
+		contentArea.SetMarginStart(12)
+		contentArea.SetMarginEnd(12)
+		contentArea.SetMarginTop(12)
+		contentArea.SetMarginBottom(12)
+
+		// Create header box
+		headerBox, err := gtk.BoxNew(gtk.ORIENTATION_HORIZONTAL, 12)
+		if err != nil {
+			dialog.Destroy()
+			continue
+		}
+
+		// Add error icon
+		iconPath := filepath.Join(GetPiAppsDir(), "icons", "error.png")
+		if icon, err := loadImage(iconPath); err == nil {
+			headerBox.PackStart(icon, false, false, 0)
+		}
+
+		// Prepare header text
+		var headerText string
+		if errorType == "user" {
+			headerText = fmt.Sprintf("<b>%s</b> did not %s because you declined a prompt of the script.",
+				CapitalizeFirst(appName), action)
+		} else if errorType == "unknown" {
+			headerText = fmt.Sprintf("<b>%s</b> failed to %s for an <b>unknown</b> reason.",
+				CapitalizeFirst(appName), action)
+		} else {
+			article := "a"
+			if strings.Contains("aeiou", string(errorType[0])) {
+				article = "an"
+			}
+			headerText = fmt.Sprintf("<b>%s</b> failed to %s because Pi-Apps encountered %s <b>%s</b> error.",
+				CapitalizeFirst(appName), action, article, errorType)
+		}
+
+		// Check if we can send an error report
+		canSend := diagnosis.CanReport
+		if !canSend {
+			headerText += "\n" + diagnosis.ReportBlocked
+		}
+
+		// Add support links
+		appType, _ := AppType(appName)
+
+		// TODO: Change this below message depending on the package manager being used.
+		if appType == "package" {
+			headerText += "\n" + PackageAppErrorMessage
+		} else {
+			account, repo := GetGitUrl()
+			if account == "" || repo == "" {
+				headerText += "\nSupport is available on <a href=\"https://discord.gg/RXSTvaUvuu\">Discord</a> and <a href=\"https://github.com/pi-apps-go/pi-apps-go/issues/new/choose\">Github</a>."
+			} else {
+				headerText += fmt.Sprintf("\nSupport is available on <a href=\"https://discord.gg/RXSTvaUvuu\">Discord</a> and <a href=\"https://github.com/%s/%s/issues/new/choose\">Github</a>.", account, repo)
+			}
+		}
+
+		// If we have no error caption, tell user to view the log
+		if errorCaption == "" {
+			headerText += "\nYou can view the terminal output below. (scroll down)"
+			// Get content of log file
+			content, err := os.ReadFile(logFile)
+			if err == nil {
+				errorCaption = string(content)
+			}
+		} else {
+			headerText += "\nBelow, Pi-Apps explains what went wrong and how you can fix it."
+		}
+
+		// Create the header label with rich text
+		headerLabel, err := gtk.LabelNew("")
+		if err != nil {
+			dialog.Destroy()
+			continue
+		}
+		headerLabel.SetMarkup(headerText)
+		headerLabel.SetLineWrap(true)
+		headerLabel.SetMaxWidthChars(80)
+		headerLabel.SetJustify(gtk.JUSTIFY_LEFT)
+		headerLabel.SetHAlign(gtk.ALIGN_START)
+		headerLabel.SetVAlign(gtk.ALIGN_START)
+		headerBox.PackStart(headerLabel, true, true, 0)
+
+		contentArea.PackStart(headerBox, false, false, 0)
+
+		// Create scrolled window for log text
+		scrollWin, err := gtk.ScrolledWindowNew(nil, nil)
+		if err != nil {
+			dialog.Destroy()
+			continue
+		}
+		scrollWin.SetHExpand(true)
+		scrollWin.SetVExpand(true)
+		scrollWin.SetPolicy(gtk.POLICY_AUTOMATIC, gtk.POLICY_AUTOMATIC)
+
+		// Create text view for error caption
+		textView, err := gtk.TextViewNew()
+		if err != nil {
+			dialog.Destroy()
+			continue
+		}
+		textView.SetEditable(false)
+		textView.SetWrapMode(gtk.WRAP_WORD_CHAR)
+
+		buffer, err := textView.GetBuffer()
+		if err != nil {
+			dialog.Destroy()
+			continue
+		}
+		buffer.SetText(errorCaption)
+
+		scrollWin.Add(textView)
+		contentArea.PackStart(scrollWin, true, true, 0)
+
+		// Screenshots are strictly opt-in and have to be requested for every report
+		var screenshotCheck *gtk.CheckButton
+		if canSend {
+			screenshotCheck, err = gtk.CheckButtonNewWithLabel(T("Attach a screenshot of my screen to the error report"))
+			if err != nil {
+				dialog.Destroy()
+				continue
+			}
+			screenshotCheck.SetActive(false)
+			screenshotCheck.SetTooltipText(T("A screenshot of the terminal often shows what went wrong. You will be asked to confirm before it is taken."))
+			contentArea.PackStart(screenshotCheck, false, false, 0)
+		}
+
+		// Add View Log button as action widget with custom response ID
+		viewLogButton, err := gtk.ButtonNewWithLabel("View Log")
+		if err != nil {
+			dialog.Destroy()
+			continue
+		}
+		// Try to add icon
+		iconPath = filepath.Join(GetPiAppsDir(), "icons", "log-file.png")
+		if icon, err := gtk.ImageNewFromFile(iconPath); err == nil {
+			viewLogButton.SetImage(icon)
+			viewLogButton.SetAlwaysShowImage(true)
+		}
+		// Add to dialog with custom response ID (we'll handle this specially)
+		dialog.AddActionWidget(viewLogButton, 100) // Custom response ID for View Log
+
+		// Send Report button (if applicable)
+		if canSend {
+			sendReportButton, err := gtk.ButtonNewWithLabel("Send Report")
+			if err != nil {
+				dialog.Destroy()
+				continue
+			}
+			// Try to add icon
+			iconPath = filepath.Join(GetPiAppsDir(), "icons", "send-error-report.png")
+			if icon, err := gtk.ImageNewFromFile(iconPath); err == nil {
+				sendReportButton.SetImage(icon)
+				sendReportButton.SetAlwaysShowImage(true)
+			}
+			dialog.AddActionWidget(sendReportButton, gtk.RESPONSE_APPLY) // Custom response for Send Report
+		}
+
+		// Retry button
+		retryButton, err := gtk.ButtonNewWithLabel("Retry")
+		if err != nil {
+			dialog.Destroy()
+			continue
+		}
+		// Try to add icon
+		iconPath = filepath.Join(GetPiAppsDir(), "icons", "refresh.png")
+		if icon, err := gtk.ImageNewFromFile(iconPath); err == nil {
+			retryButton.SetImage(icon)
+			retryButton.SetAlwaysShowImage(true)
+		}
+		dialog.AddActionWidget(retryButton, gtk.RESPONSE_OK) // RESPONSE_OK for Retry
+
+		// Close/Next button
+		var closeButton *gtk.Button
+		if i < numFailures-1 {
+			closeButton, err = gtk.ButtonNewWithLabel("Next Error")
+			if err != nil {
+				dialog.Destroy()
+				continue
+			}
+			// Try to add icon
+			iconPath = filepath.Join(GetPiAppsDir(), "icons", "forward.png")
+			if icon, err := gtk.ImageNewFromFile(iconPath); err == nil {
+				closeButton.SetImage(icon)
+				closeButton.SetAlwaysShowImage(true)
+			}
+		} else {
+			closeButton, err = gtk.ButtonNewWithLabel("Close")
+			if err != nil {
+				dialog.Destroy()
+				continue
+			}
+			// Try to add icon
+			iconPath = filepath.Join(GetPiAppsDir(), "icons", "exit.png")
+			if icon, err := gtk.ImageNewFromFile(iconPath); err == nil {
+				closeButton.SetImage(icon)
+				closeButton.SetAlwaysShowImage(true)
+			}
+		}
+		dialog.AddActionWidget(closeButton, gtk.RESPONSE_CANCEL) // RESPONSE_CANCEL for Close/Next
+
+		// Show all widgets
+		dialog.ShowAll()
+
+		// Run the dialog and process the response in a loop to handle View Log
+		for {
+			response := dialog.Run()
+
+			// Process response
+			switch response {
+			case 100: // View Log - handle without closing dialog
+				// Get the directory where the binary is running from
+				exePath, err := os.Executable()
+				if err != nil {
+					fmt.Printf("Error getting executable path: %v\n", err)
+					continue // Stay in loop, don't close dialog
+				}
+
+				// Launch a separate process for viewing the log file
+				// to avoid conflicts with the current GTK main loop
+				cmd := exec.Command(exePath, "view_file", logFile)
+				cmd.Env = append(os.Environ(), "DISPLAY="+os.Getenv("DISPLAY"))
+				err = cmd.Start()
+				if err != nil {
+					fmt.Printf("Error opening log viewer: %v\n", err)
+				}
+				// Continue the loop to keep dialog open
+				continue
+			case gtk.RESPONSE_OK: // Retry
+				results = append(results, DiagnoseResult{
+					Action:    "retry",
+					AppName:   appName,
+					ActionStr: failure,
+				})
+			case gtk.RESPONSE_APPLY: // Send Report
+				screenshotPath := ""
+				if screenshotCheck != nil && screenshotCheck.GetActive() && confirmScreenshot(dialog) {
+					screenshotPath, err = captureScreenshot(dialog, appName)
+					if err != nil {
+						WarningT("Failed to capture screenshot, sending the log only: %v", err)
+						screenshotPath = ""
+					}
+				}
+				results = append(results, DiagnoseResult{
+					Action:     "send",
+					AppName:    appName,
+					ActionStr:  failure,
+					Screenshot: screenshotPath,
+				})
+			case gtk.RESPONSE_CANCEL: // Close/Next
+				results = append(results, DiagnoseResult{
+					Action:    "next",
+					AppName:   appName,
+					ActionStr: failure,
+				})
+			default: // Any other response (e.g., window closed)
+				results = append(results, DiagnoseResult{
+					Action:    "close",
+					AppName:   appName,
+					ActionStr: failure,
+				})
+			}
+			// Exit the loop to close dialog
+			break
+		}
+
+		// Destroy the dialog
+		dialog.Destroy()
+	}
+
+	// Process GTK events to ensure proper cleanup
+	for gtk.EventsPending() {
+		gtk.MainIteration()
+	}
+
+	return results
+}
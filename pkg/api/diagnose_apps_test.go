@@ -0,0 +1,206 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGetLogfile(t *testing.T) {
+	directory := newTestPiAppsDir(t)
+	logs := filepath.Join(directory, "logs")
+	old := time.Now().Add(-time.Hour)
+	for name, modTime := range map[string]time.Time{
+		"install-fail-Café (beta).log":              old,
+		"install-incomplete-Café (beta).log":        time.Now(),
+		"install-fail-Better Café (beta).log":       time.Now().Add(time.Hour),
+		"install-success-Rock 'n' Roll.log":         time.Now(),
+		"install-64-fail-日本語入力.log":                 time.Now(),
+		"install-fail-Zoom.log":                     old,
+		"install-fail-Zoom@20261015-153012.000.log": time.Now(),
+	} {
+		path := filepath.Join(logs, name)
+		writeTestFile(t, path, "log\n")
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		app, want string
+	}{
+		{"Café (beta)", "install-incomplete-Café (beta).log"},
+		{"日本語入力", "install-64-fail-日本語入力.log"},
+		// Logs named after the time their script started are found like the ones without
+		{"Zoom", "install-fail-Zoom@20261015-153012.000.log"},
+		// A successful log is not returned, the default path is
+		{"Rock 'n' Roll", "Rock 'n' Roll"},
+	}
+	for _, tt := range tests {
+		if got := GetLogfile(tt.app); got != filepath.Join(logs, tt.want) {
+			t.Errorf("GetLogfile(%q) = %q, want %q", tt.app, got, filepath.Join(logs, tt.want))
+		}
+	}
+
+	if got := GetLogfile("../etc"); got != "" {
+		t.Errorf("GetLogfile of an invalid name = %q, want \"\"", got)
+	}
+}
+
+func TestDiagnoseFailures(t *testing.T) {
+	directory := newTestPiAppsDir(t, "Zoom", "Better Chromium", "No Log")
+	writeTestFile(t, filepath.Join(directory, "logs", "install-fail-Zoom.log"),
+		"Cloning into 'zoom'...\nerror: RPC failed\nfetch-pack: unexpected disconnect while reading sideband packet\n")
+	writeTestFile(t, filepath.Join(directory, "logs", "update-fail-Better Chromium.log"),
+		"Updating Better Chromium...\nfetch-pack: unexpected disconnect while reading sideband packet\n")
+
+	diagnoses, err := DiagnoseFailures("install;Zoom\n\n  \nupdate Better Chromium\nnonsense\nuninstall;No Log\n")
+	if err == nil || !strings.Contains(err.Error(), `"nonsense"`) {
+		t.Errorf("DiagnoseFailures error = %v, want the invalid line", err)
+	}
+	if len(diagnoses) != 3 {
+		t.Fatalf("DiagnoseFailures = %+v, want 3 diagnoses", diagnoses)
+	}
+
+	for i, want := range []struct{ action, app, log string }{
+		{"install", "Zoom", "install-fail-Zoom.log"},
+		{"update", "Better Chromium", "update-fail-Better Chromium.log"},
+	} {
+		diagnosis := diagnoses[i]
+		if diagnosis.Action != want.action || diagnosis.App != want.app {
+			t.Errorf("diagnosis %d is of %s;%s, want %s;%s", i, diagnosis.Action, diagnosis.App, want.action, want.app)
+		}
+		if diagnosis.LogFile != filepath.Join(directory, "logs", want.log) {
+			t.Errorf("diagnosis of %s has log %q, want %q", want.app, diagnosis.LogFile, want.log)
+		}
+		if diagnosis.ErrorType != "internet" {
+			t.Errorf("diagnosis of %s has error type %q, want internet", want.app, diagnosis.ErrorType)
+		}
+		if !slices.ContainsFunc(diagnosis.Captions, func(caption string) bool { return strings.Contains(caption, "fetch-pack") }) {
+			t.Errorf("diagnosis of %s has captions %q, want the fetch-pack explanation", want.app, diagnosis.Captions)
+		}
+		if diagnosis.CanReport || diagnosis.ReportBlocked == "" {
+			t.Errorf("diagnosis of %s allows an error report for an internet error", want.app)
+		}
+	}
+
+	noLog := diagnoses[2]
+	if noLog.Action != "uninstall" || noLog.App != "No Log" || noLog.ErrorType != "unknown" ||
+		noLog.CanReport || !strings.Contains(noLog.ReportBlocked, "no log file") || noLog.Captions == nil {
+		t.Errorf("diagnosis without a log = %+v", noLog)
+	}
+
+	// Diagnosing the failures leaves the logs as they were
+	content, err := os.ReadFile(filepath.Join(directory, "logs", "install-fail-Zoom.log"))
+	if err != nil || strings.Count(string(content), "\n") != 3 {
+		t.Errorf("the log of Zoom changed to %q, %v", content, err)
+	}
+}
+
+func TestDiagnoseAppsInTerminalWithoutTerminal(t *testing.T) {
+	directory := newTestPiAppsDir(t, "Zoom")
+	writeTestFile(t, filepath.Join(directory, "logs", "install-fail-Zoom.log"), "fetch-pack: unexpected disconnect while reading sideband packet\n")
+
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	writer.Close()
+	defer reader.Close()
+	stdin := os.Stdin
+	os.Stdin = reader
+	defer func() { os.Stdin = stdin }()
+
+	start := time.Now()
+	results := DiagnoseAppsInTerminal("install;Zoom\nremove Zoom", time.Minute)
+	if time.Since(start) > 10*time.Second {
+		t.Errorf("DiagnoseAppsInTerminal waited for an answer without a terminal")
+	}
+	if len(results) != 2 {
+		t.Fatalf("DiagnoseAppsInTerminal = %+v, want 2 results", results)
+	}
+	for _, result := range results {
+		if result.Action != "next" {
+			t.Errorf("DiagnoseAppsInTerminal retries %+v without a terminal", result)
+		}
+	}
+}
+
+func TestFailureLineLogFile(t *testing.T) {
+	directory := newTestPiAppsDir(t, "Zoom")
+	older := filepath.Join(directory, "logs", "install-fail-Zoom@20261015-153012.000.log")
+	newer := filepath.Join(directory, "logs", "install-fail-Zoom@20261015-153514.000.log")
+	writeTestFile(t, older, "fetch-pack: unexpected disconnect while reading sideband packet\n")
+	writeTestFile(t, newer, "something else\n")
+
+	// The log of the failed queue item is diagnosed, not the newest log of the app
+	diagnoses, err := DiagnoseFailures(FailureLine("install", "Zoom", older))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diagnoses) != 1 || diagnoses[0].LogFile != older || diagnoses[0].ActionStr() != "install;Zoom" {
+		t.Fatalf("DiagnoseFailures = %+v, want the log %s", diagnoses, older)
+	}
+	if diagnoses[0].ErrorType != "internet" {
+		t.Errorf("error type = %q, want internet", diagnoses[0].ErrorType)
+	}
+	if line := FailureLine("install", "Zoom", ""); line != "install;Zoom" {
+		t.Errorf("FailureLine without a log = %q", line)
+	}
+}
+
+func TestLatestAppLog(t *testing.T) {
+	logsDir := t.TempDir()
+	now := time.Now()
+	logs := []struct {
+		name string
+		age  time.Duration
+	}{
+		{"install-fail-Zoom.log", 3 * time.Hour},
+		{"install-success-Zoom.log", 2 * time.Hour},
+		{"uninstall-fail-Zoom.log", time.Hour},
+		{"install-success-Zoom Web.log", 0},
+		{"notes.txt", 0},
+	}
+	for _, log := range logs {
+		path := filepath.Join(logsDir, log.name)
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, now.Add(-log.age), now.Add(-log.age)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if got := LatestAppLog(logsDir, "Zoom", false); got != filepath.Join(logsDir, "uninstall-fail-Zoom.log") {
+		t.Errorf("LatestAppLog(Zoom) = %q", got)
+	}
+	if got := LatestAppLog(logsDir, "Zoom Web", true); got != "" {
+		t.Errorf("LatestAppLog(Zoom Web, failedOnly) = %q, want none", got)
+	}
+	if got := LatestAppLog(logsDir, "Zoom Web", false); got != filepath.Join(logsDir, "install-success-Zoom Web.log") {
+		t.Errorf("LatestAppLog(Zoom Web) = %q", got)
+	}
+	if got := LatestAppLog(filepath.Join(logsDir, "missing"), "Zoom", false); got != "" {
+		t.Errorf("LatestAppLog() of a missing directory = %q", got)
+	}
+}
@@ -0,0 +1,228 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: disk_usage.go
+// Description: Attributes the disk space of packages, installed files, logs and downloads to the installed apps.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package api
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/pi-apps-go/pi-apps/pkg/api/errs"
+)
+
+// Breakdown is the disk space used by an installed app, in bytes
+type Breakdown struct {
+	App       string   `json:"app"`
+	Packages  int64    `json:"packages"`         // the packages the app depends on, shared packages divided between the apps depending on them
+	Shared    []string `json:"shared,omitempty"` // packages other installed apps depend on too
+	Files     int64    `json:"files"`            // the files recorded in the app's install manifest
+	Logs      int64    `json:"logs"`             // the app's log files
+	Downloads int64    `json:"downloads"`        // downloads recorded in the download ledger that are still on disk
+	Total     int64    `json:"total"`
+}
+
+// diskUsageContext holds what is read once for every app: the package sizes, which apps depend on which package,
+// the log files and the download ledger
+type diskUsageContext struct {
+	packageSizes map[string]int64
+	packages     map[string][]string // app -> packages it depends on
+	users        map[string]int      // package -> number of installed apps depending on it
+	logs         map[string]int64    // app -> size of its log files
+	downloads    map[string][]string // app -> destinations of its downloads
+}
+
+// newDiskUsageContext reads the package database, the logs and the download ledger for the installed apps
+func newDiskUsageContext(installed []string) (*diskUsageContext, error) {
+	directory := GetPiAppsDir()
+	if directory == "" {
+		return nil, fmt.Errorf("PI_APPS_DIR environment variable not set")
+	}
+
+	sizes, err := installedPackageSizes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the sizes of the installed packages: %w", err)
+	}
+	ctx := &diskUsageContext{
+		packageSizes: sizes,
+		packages:     make(map[string][]string),
+		users:        make(map[string]int),
+		logs:         make(map[string]int64),
+		downloads:    make(map[string][]string),
+	}
+
+	for _, app := range installed {
+		packages := appPackageNames(app)
+		slices.Sort(packages)
+		packages = slices.Compact(packages)
+		ctx.packages[app] = packages
+		for _, pkg := range packages {
+			ctx.users[pkg]++
+		}
+	}
+
+	if entries, err := os.ReadDir(LogsDirOf(directory)); err == nil {
+		for _, entry := range entries {
+			app, _, ok := ParseLogFileName(entry.Name())
+			if !ok || !entry.Type().IsRegular() {
+				continue
+			}
+			if info, err := entry.Info(); err == nil {
+				ctx.logs[app] += info.Size()
+			}
+		}
+	}
+
+	ledger, err := DownloadLedger(DownloadLedgerFilter{})
+	if err != nil {
+		Debug(fmt.Sprintf("disk usage: %v", err))
+	}
+	for _, entry := range ledger {
+		if entry.App != "" && !slices.Contains(ctx.downloads[entry.App], entry.Destination) {
+			ctx.downloads[entry.App] = append(ctx.downloads[entry.App], entry.Destination)
+		}
+	}
+	return ctx, nil
+}
+
+// breakdown returns the disk usage of an app
+func (ctx *diskUsageContext) breakdown(app string) Breakdown {
+	usage := Breakdown{App: app, Logs: ctx.logs[app]}
+
+	for _, pkg := range ctx.packages[app] {
+		size, installed := ctx.packageSizes[pkg]
+		if !installed {
+			continue
+		}
+		// A package several apps depend on is counted once, divided between them
+		if users := ctx.users[pkg]; users > 1 {
+			size /= int64(users)
+			usage.Shared = append(usage.Shared, pkg)
+		}
+		usage.Packages += size
+	}
+
+	files, err := ReadInstalledFiles(app)
+	if err != nil {
+		Debug(fmt.Sprintf("disk usage of %s: %v", app, err))
+	}
+	for _, file := range files {
+		usage.Files += pathSize(file)
+	}
+
+	for _, destination := range ctx.downloads[app] {
+		// Downloads that became installed files are counted as files already
+		if slices.ContainsFunc(files, func(file string) bool { return pathWithin(destination, file) }) {
+			continue
+		}
+		usage.Downloads += pathSize(destination)
+	}
+
+	usage.Total = usage.Packages + usage.Files + usage.Logs + usage.Downloads
+	return usage
+}
+
+// pathSize returns the size of a file, or of the regular files in a directory, 0 if it doesn't exist
+func pathSize(path string) int64 {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return 0
+	}
+	if info.IsDir() {
+		return dirSize(path)
+	}
+	if !info.Mode().IsRegular() {
+		return 0
+	}
+	return info.Size()
+}
+
+// pathWithin reports whether path is dir or inside it
+func pathWithin(path, dir string) bool {
+	return path == dir || strings.HasPrefix(path, strings.TrimSuffix(dir, "/")+"/")
+}
+
+// AppDiskUsage returns the disk space used by an installed app: its packages, the files its install script
+// installed, its logs and its downloads that are still on disk
+//
+//	Breakdown - disk usage of the app
+//	error - error if the app is not installed or the package database cannot be read
+func AppDiskUsage(app string) (Breakdown, error) {
+	if !IsAppInstalled(app) {
+		return Breakdown{}, errs.New(errs.ErrNotInstalled, "app '%s' is not installed", app)
+	}
+	installed, err := ListApps("installed")
+	if err != nil {
+		return Breakdown{}, err
+	}
+	ctx, err := newDiskUsageContext(installed)
+	if err != nil {
+		return Breakdown{}, err
+	}
+	return ctx.breakdown(app), nil
+}
+
+// AllAppsDiskUsage returns the disk usage of every installed app, the largest first.
+// The package database, the logs and the download ledger are only read once.
+func AllAppsDiskUsage() ([]Breakdown, error) {
+	installed, err := ListApps("installed")
+	if err != nil {
+		return nil, err
+	}
+	ctx, err := newDiskUsageContext(installed)
+	if err != nil {
+		return nil, err
+	}
+
+	usages := make([]Breakdown, 0, len(installed))
+	for _, app := range installed {
+		usages = append(usages, ctx.breakdown(app))
+	}
+	slices.SortStableFunc(usages, func(a, b Breakdown) int {
+		if a.Total != b.Total {
+			if a.Total > b.Total {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a.App, b.App)
+	})
+	return usages, nil
+}
+
+// trackedPackageNames returns the packages recorded in data/installed-packages/<app> when the app was installed,
+// or the packages of a package-app
+func trackedPackageNames(app string) []string {
+	path, err := AppDataPath("installed-packages", app)
+	if err != nil {
+		return nil
+	}
+	if data, err := os.ReadFile(path); err == nil {
+		return strings.Fields(string(data))
+	}
+	if FileExists(filepath.Join(GetPiAppsDir(), "apps", app, "packages")) {
+		if packages, err := PkgAppPackagesRequired(app); err == nil {
+			return strings.Fields(packages)
+		}
+	}
+	return nil
+}
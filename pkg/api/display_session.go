@@ -0,0 +1,128 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: display_session.go
+// Description: Detects whether the desktop runs a Wayland or X11 session and whether XWayland is available.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package api
+
+import (
+	"os"
+	"strings"
+)
+
+// Display session types returned by DisplaySessionInfo
+const (
+	SessionWayland = "wayland"
+	SessionX11     = "x11"
+	SessionTTY     = "tty"
+	SessionUnknown = "unknown"
+)
+
+// DisplaySession describes the graphical session Pi-Apps runs in
+type DisplaySession struct {
+	Type           string `json:"type"`            // wayland, x11, tty or unknown
+	Desktop        string `json:"desktop"`         // XDG_CURRENT_DESKTOP, e.g. LXDE-pi-labwc
+	WaylandDisplay string `json:"wayland_display"` // WAYLAND_DISPLAY, e.g. wayland-0
+	X11Display     string `json:"x11_display"`     // DISPLAY, e.g. :0
+	XWayland       bool   `json:"xwayland"`        // X11 apps can run through XWayland
+}
+
+// DisplaySessionInfo detects the display session from XDG_SESSION_TYPE, WAYLAND_DISPLAY and DISPLAY
+//
+// XDG_SESSION_TYPE is not set when Pi-Apps runs through sudo or from some terminals,
+// so the display variables are used when it is missing.
+func DisplaySessionInfo() DisplaySession {
+	session := DisplaySession{
+		Desktop:        os.Getenv("XDG_CURRENT_DESKTOP"),
+		WaylandDisplay: os.Getenv("WAYLAND_DISPLAY"),
+		X11Display:     os.Getenv("DISPLAY"),
+	}
+
+	switch strings.ToLower(os.Getenv("XDG_SESSION_TYPE")) {
+	case "wayland":
+		session.Type = SessionWayland
+	case "x11":
+		session.Type = SessionX11
+	case "tty":
+		session.Type = SessionTTY
+	}
+	if session.Type == "" || session.Type == SessionTTY {
+		switch {
+		case session.WaylandDisplay != "":
+			session.Type = SessionWayland
+		case session.X11Display != "":
+			session.Type = SessionX11
+		case session.Type == "":
+			session.Type = SessionUnknown
+		}
+	}
+
+	if session.Type == SessionWayland {
+		// Wayland compositors only set DISPLAY when they started XWayland
+		session.XWayland = session.X11Display != "" || xwaylandRunning()
+	}
+	return session
+}
+
+// String returns the session as shown in device info, e.g. "wayland (LXDE-pi-labwc, XWayland available)"
+func (s DisplaySession) String() string {
+	var details []string
+	if s.Desktop != "" {
+		details = append(details, s.Desktop)
+	}
+	if s.Type == SessionWayland {
+		if s.XWayland {
+			details = append(details, "XWayland available")
+		} else {
+			details = append(details, "no XWayland")
+		}
+	}
+	if len(details) == 0 {
+		return s.Type
+	}
+	return s.Type + " (" + strings.Join(details, ", ") + ")"
+}
+
+// HasDisplay reports whether windows can be shown, there is a Wayland or an X11 display
+func (s DisplaySession) HasDisplay() bool {
+	return s.WaylandDisplay != "" || s.X11Display != ""
+}
+
+// IsWayland reports whether the session is a Wayland session
+func (s DisplaySession) IsWayland() bool {
+	return s.Type == SessionWayland
+}
+
+// CanRunX11 reports whether X11 apps can open a window in the session
+func (s DisplaySession) CanRunX11() bool {
+	return s.Type == SessionX11 || s.XWayland
+}
+
+// xwaylandRunning reports whether an Xwayland process is running
+func xwaylandRunning() bool {
+	processes, err := FindProcesses(func(info *ProcInfo) bool { return info.Name == "Xwayland" })
+	return err == nil && len(processes) > 0
+}
+
+// xwaylandNote explains in a diagnosis caption whether XWayland was available to run the program
+func xwaylandNote(session DisplaySession) string {
+	if session.XWayland {
+		return " and XWayland could not run it"
+	}
+	return " without XWayland"
+}
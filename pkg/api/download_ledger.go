@@ -0,0 +1,335 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: download_ledger.go
+// Description: Provides a ledger of every URL Pi-Apps downloaded content from, for supply-chain auditing.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// LedgerEntry is one download recorded in data/download-ledger.jsonl
+type LedgerEntry struct {
+	Time        time.Time `json:"time"`
+	App         string    `json:"app,omitempty"`
+	URL         string    `json:"url"`
+	Destination string    `json:"destination"`
+	Bytes       int64     `json:"bytes"`
+	SHA256      string    `json:"sha256,omitempty"`
+	Commit      string    `json:"commit,omitempty"`    // HEAD of a cloned git repository
+	CacheHit    bool      `json:"cache_hit,omitempty"` // taken from the asset cache instead of downloaded
+}
+
+// DownloadLedgerFilter selects the entries returned by DownloadLedger, empty fields match every entry
+type DownloadLedgerFilter struct {
+	App   string
+	Since time.Time
+}
+
+// downloadLedgerQueueSize is how many downloads can wait to be written before new ones are dropped
+const downloadLedgerQueueSize = 256
+
+// downloadLedgerFlushTimeout is how long FlushDownloadLedger waits for pending entries
+const downloadLedgerFlushTimeout = 10 * time.Second
+
+// ledgerRecord is a download waiting to be written, hashed by the writer if the downloader did not hash it already
+type ledgerRecord struct {
+	entry LedgerEntry
+	hash  bool
+	git   bool
+}
+
+var (
+	ledgerOnce    sync.Once
+	ledgerRecords chan ledgerRecord
+	ledgerPending sync.WaitGroup
+)
+
+// downloadLedgerFile returns the path of the download ledger
+func downloadLedgerFile() string {
+	return filepath.Join(GetDataDir(), "download-ledger.jsonl")
+}
+
+// downloadLedgerEnabled reports whether the "Enable download ledger" setting is not set to No
+func downloadLedgerEnabled() bool {
+	directory := GetPiAppsDir()
+	if directory == "" {
+		return false
+	}
+	return ReadSettingIn(directory, "Enable download ledger") != "No"
+}
+
+// recordDownload queues a finished download of a file whose size and sha256 are already known
+func recordDownload(url, destination string, size int64, sum []byte) {
+	entry := LedgerEntry{URL: url, Destination: destination, Bytes: size}
+	if sum != nil {
+		entry.SHA256 = hex.EncodeToString(sum)
+	}
+	queueLedgerRecord(ledgerRecord{entry: entry})
+}
+
+// recordCachedDownload queues a download that was taken from the asset cache
+func recordCachedDownload(url, destination string, size int64, sum string) {
+	queueLedgerRecord(ledgerRecord{entry: LedgerEntry{URL: url, Destination: destination, Bytes: size, SHA256: sum, CacheHit: true}})
+}
+
+// recordDownloadedFile queues a finished download that the writer still has to measure and hash
+func recordDownloadedFile(url, destination string) {
+	queueLedgerRecord(ledgerRecord{entry: LedgerEntry{URL: url, Destination: destination}, hash: true})
+}
+
+// recordGitClone queues a finished git clone, recorded with the size of the checkout and its HEAD commit
+func recordGitClone(url, destination string) {
+	queueLedgerRecord(ledgerRecord{entry: LedgerEntry{URL: url, Destination: destination}, git: true})
+}
+
+// queueLedgerRecord hands a download to the ledger writer without ever blocking the download path
+func queueLedgerRecord(record ledgerRecord) {
+	if !downloadLedgerEnabled() {
+		return
+	}
+	record.entry.Time = time.Now().UTC()
+	record.entry.App = os.Getenv("app")
+
+	ledgerOnce.Do(func() {
+		ledgerRecords = make(chan ledgerRecord, downloadLedgerQueueSize)
+		go ledgerWriter()
+	})
+
+	ledgerPending.Add(1)
+	select {
+	case ledgerRecords <- record:
+	default:
+		ledgerPending.Done()
+		Debug(fmt.Sprintf("download ledger queue is full, not recording %s", record.entry.URL))
+	}
+}
+
+// ledgerWriter writes queued downloads to the ledger, batching whatever is waiting into one locked append
+func ledgerWriter() {
+	for record := range ledgerRecords {
+		batch := []ledgerRecord{record}
+	drain:
+		for len(batch) < downloadLedgerQueueSize {
+			select {
+			case record := <-ledgerRecords:
+				batch = append(batch, record)
+			default:
+				break drain
+			}
+		}
+
+		var lines bytes.Buffer
+		for _, record := range batch {
+			entry := record.entry
+			switch {
+			case record.git:
+				entry.Bytes = dirSize(entry.Destination)
+				entry.Commit = gitHeadCommit(entry.Destination)
+			case record.hash:
+				entry.Bytes, entry.SHA256 = hashFile(entry.Destination)
+			}
+			line, err := json.Marshal(entry)
+			if err != nil {
+				Debug(fmt.Sprintf("failed to encode download ledger entry: %v", err))
+				continue
+			}
+			lines.Write(append(line, '\n'))
+		}
+
+		if err := appendDownloadLedger(lines.Bytes()); err != nil {
+			Debug(fmt.Sprintf("failed to write download ledger: %v", err))
+		}
+		for range batch {
+			ledgerPending.Done()
+		}
+	}
+}
+
+// appendDownloadLedger appends lines to the ledger under an exclusive lock,
+// so parallel queue items in other processes never interleave their entries
+func appendDownloadLedger(lines []byte) error {
+	if len(lines) == 0 {
+		return nil
+	}
+	file := downloadLedgerFile()
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return fmt.Errorf("failed to create data directory: %w", err)
+	}
+
+	f, err := os.OpenFile(file, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open download ledger: %w", err)
+	}
+	defer f.Close()
+
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX); err != nil {
+		return fmt.Errorf("failed to lock download ledger: %w", err)
+	}
+	defer syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+
+	_, err = f.Write(lines)
+	return err
+}
+
+// FlushDownloadLedger waits until queued downloads are written to the ledger
+//
+// It is called before the process exits; entries still pending after 10 seconds are lost.
+func FlushDownloadLedger() {
+	done := make(chan struct{})
+	go func() {
+		ledgerPending.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(downloadLedgerFlushTimeout):
+		Debug("timed out waiting for the download ledger to be written")
+	}
+}
+
+// hashFile returns the size and sha256 of a file, or 0 and "" if it is gone
+func hashFile(path string) (int64, string) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, ""
+	}
+	defer f.Close()
+
+	hash := sha256.New()
+	size, err := io.Copy(hash, f)
+	if err != nil {
+		return 0, ""
+	}
+	return size, hex.EncodeToString(hash.Sum(nil))
+}
+
+// dirSize returns the total size of the regular files in a directory
+func dirSize(path string) int64 {
+	var size int64
+	filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err == nil && d.Type().IsRegular() {
+			if info, err := d.Info(); err == nil {
+				size += info.Size()
+			}
+		}
+		return nil
+	})
+	return size
+}
+
+// gitHeadCommit returns the commit checked out in a git repository, or ""
+func gitHeadCommit(path string) string {
+	output, err := exec.Command("git", "-C", path, "rev-parse", "HEAD").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// DownloadLedger reads the recorded downloads matching a filter, oldest first
+//
+//	[]LedgerEntry - matching downloads
+//	error - error if PI_APPS_DIR environment variable is not set or the ledger cannot be read
+func DownloadLedger(filter DownloadLedgerFilter) ([]LedgerEntry, error) {
+	if GetPiAppsDir() == "" {
+		return nil, fmt.Errorf("PI_APPS_DIR environment variable not set")
+	}
+
+	f, err := os.Open(downloadLedgerFile())
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to open download ledger: %w", err)
+	}
+	defer f.Close()
+
+	var entries []LedgerEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var entry LedgerEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			Debug(fmt.Sprintf("skipping malformed download ledger line %d: %v", lineNumber, err))
+			continue
+		}
+		if filter.App != "" && entry.App != filter.App {
+			continue
+		}
+		if !filter.Since.IsZero() && entry.Time.Before(filter.Since) {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read download ledger: %w", err)
+	}
+	return entries, nil
+}
+
+// ClearDownloadLedger removes all recorded downloads
+func ClearDownloadLedger() error {
+	if GetPiAppsDir() == "" {
+		return fmt.Errorf("PI_APPS_DIR environment variable not set")
+	}
+	if err := os.Remove(downloadLedgerFile()); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove download ledger: %w", err)
+	}
+	return nil
+}
+
+// ParseLedgerSince parses the --since value of the downloads command:
+// a date (2006-01-02), an RFC 3339 time, or an age like 12h or 7d
+func ParseLedgerSince(value string) (time.Time, error) {
+	if days, found := strings.CutSuffix(value, "d"); found {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return time.Now().AddDate(0, 0, -n), nil
+		}
+	}
+	if age, err := time.ParseDuration(value); err == nil {
+		return time.Now().Add(-age), nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q, expected a date (2006-01-02), an RFC 3339 time or an age like 12h or 7d", value)
+}
@@ -33,6 +33,8 @@ import (
 	"sort"
 	"strings"
 	"time"
+
+	"github.com/pi-apps-go/pi-apps/pkg/api/errs"
 )
 
 // RepoAdd adds local package files to the /tmp/pi-apps-local-packages repository
@@ -81,7 +83,7 @@ func AptLockWait() error {
 		cmd.Stdin = os.Stdin
 		if err := cmd.Run(); err != nil {
 			close(notificationDone)
-			return fmt.Errorf("failed to get sudo permissions: %w", err)
+			return errs.New(errs.ErrNeedsRoot, "failed to get sudo permissions: %w", err)
 		}
 	}
 
@@ -171,8 +173,8 @@ func dearmorGPGKey(armoredData []byte) ([]byte, error) {
 func AptUpdate(args ...string) error {
 	// Use cyan color with reverse video styling to match the original implementation
 	// \033[96m for cyan, \033[7m for reverse video, \033[27m to end reverse, \033[0m to reset all formatting
-	fmt.Fprintf(os.Stderr, "\033[96m%s \033[7m<package manager update command>\033[27m...\033[0m\n", T("Running"))
-	fmt.Fprintf(os.Stderr, "\033[96m%s\033[0m\n", T("<package manager update command> complete."))
+	colorPrintf(os.Stderr, "\033[96m%s \033[7m<package manager update command>\033[27m...\033[0m\n", T("Running"))
+	colorPrintf(os.Stderr, "\033[96m%s\033[0m\n", T("<package manager update command> complete."))
 	// return success if no package manager build tag is set
 	return nil
 }
@@ -341,6 +343,30 @@ func PackageInstalled(packageName string) bool {
 	return false
 }
 
+// PackageHold holds a package so upgrades leave it at its installed version
+func PackageHold(packageName string) error {
+	// return an error if no package manager build tag is set
+	return fmt.Errorf("holding packages is not supported without a package manager")
+}
+
+// PackageUnhold removes the hold of a package
+func PackageUnhold(packageName string) error {
+	// return an error if no package manager build tag is set
+	return fmt.Errorf("holding packages is not supported without a package manager")
+}
+
+// PackageIsHeld checks if a package is installed and held
+func PackageIsHeld(packageName string) bool {
+	// return false if no package manager build tag is set
+	return false
+}
+
+// InstalledPackages returns the names of all installed packages
+func InstalledPackages() (map[string]bool, error) {
+	// return an empty set if no package manager build tag is set
+	return map[string]bool{}, nil
+}
+
 // PackageAvailable determines if the specified package exists in a local repository
 func PackageAvailable(packageName string, dpkgArch string) bool {
 	// return false if no package manager build tag is set
@@ -25,9 +25,6 @@ import (
 	"os"
 	"os/exec"
 	"strings"
-
-	"github.com/gotk3/gotk3/glib"
-	"github.com/gotk3/gotk3/gtk"
 )
 
 // variables for dummy related messages
@@ -42,31 +39,13 @@ var (
 	UbuntuPPAInstallerMessage  = T("Install Ubuntu PPA - ignored, not supported by dummy")
 	DebianPPAInstallerMessage  = T("Install Debian PPA - ignored, not supported by dummy")
 	PatchDebSedMessage         = T("Modify the control file of a deb file to fix the dependencies following a sed pattern - ignored, not supported by dummy")
+	RebuildDummyDebsMessage    = T("Rebuild missing dummy debs of installed apps - ignored, not supported by dummy")
 )
 
-// checkShellcheck checks if shellcheck is installed and installs it if it isn't
-func checkShellcheck() error {
-	// Initialize application name
-	glib.SetPrgname("Pi-Apps-Settings")
-	glib.SetApplicationName("Pi-Apps Settings (app creation wizard)")
-
-	// Initialize GTK
-	gtk.Init(nil)
-
-	// Check if shellcheck is installed
-	if !commandExists("shellcheck") {
-		// Ask if they want to install shellcheck
-		dialog := gtk.MessageDialogNew(nil, gtk.DIALOG_MODAL, gtk.MESSAGE_QUESTION, gtk.BUTTONS_YES_NO,
-			"Shellcheck is not installed, but it's useful for finding errors in shell scripts. Install it now?")
-		response := dialog.Run()
-		dialog.Destroy()
-
-		if response == gtk.RESPONSE_YES {
-			// assume failure if no package manager build tag is set
-			return fmt.Errorf("failed to install shellcheck: no package manager build tag is set")
-		}
-	}
-	return nil
+// installShellcheck installs shellcheck with the package manager
+func installShellcheck() error {
+	// assume failure if no package manager build tag is set
+	return fmt.Errorf("failed to install shellcheck: no package manager build tag is set")
 }
 
 // readPackagesFile reads and parses packages from a packages file
@@ -319,3 +298,13 @@ func uninstallPackageAppDependencies(dependencies ...string) error {
 func PatchDebSed(debFile, sedString string) error {
 	return fmt.Errorf("only supported on Debian-based systems")
 }
+
+// RebuildDummyDeb regenerates and installs the dummy deb of an app whose dummy deb was removed
+func RebuildDummyDeb(app string) error {
+	return fmt.Errorf("only supported on Debian-based systems")
+}
+
+// RebuildDummyDebs rebuilds the dummy debs of the given apps, or of every app reported by ListAppsMissingDummyDebs
+func RebuildDummyDebs(apps ...string) ([]string, error) {
+	return nil, fmt.Errorf("only supported on Debian-based systems")
+}
@@ -0,0 +1,28 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: dummy_path_owner.go
+// Description: Provides a dummy package lookup for finding the app that owns a path, there are no packages without a package manager.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+//go:build dummy
+
+package api
+
+// packagesOwningPath returns no packages, there is no package manager
+func packagesOwningPath(path string) []string {
+	return nil
+}
@@ -23,6 +23,7 @@
 package api
 
 import (
+	"context"
 	"fmt"
 )
 
@@ -39,6 +40,16 @@ func AnythingInstalledFromURISuiteComponent(uri, suite, component string) (bool,
 	return false, nil
 }
 
+// PackagesInstalledFromRepo lists the installed packages that come from a specific repository
+// (identified by URI, suite, and optional component).
+//
+//	[]InstalledPackage - installed packages from the repository, sorted by name
+//	error - error if the installed packages cannot be listed
+func PackagesInstalledFromRepo(uri, suite, component string) ([]InstalledPackage, error) {
+	// assume nothing is installed if no package manager build tag is set
+	return nil, nil
+}
+
 // RemoveRepofileIfUnused removes a sources.list.d file if nothing from that repository is currently installed.
 //
 // If testMode is "test", it only outputs the status without removing anything.
@@ -78,3 +89,28 @@ func checkIfPackagesInstalledFromRepo(packages []string, uri, suite, component s
 	// return false if no package manager build tag is set
 	return false, nil
 }
+
+// CheckAptSources checks the configured APT repositories. It finds none, as no package manager build tag is set.
+func CheckAptSources(ctx context.Context) ([]SourceHealth, error) {
+	return nil, ctx.Err()
+}
+
+// DisableAptSource disables a broken APT repository, there are none without apt
+func DisableAptSource(source SourceHealth) error {
+	return fmt.Errorf("%s is not an APT repository file", source.File)
+}
+
+// RestoreAptSources enables the APT repositories DisableAptSource disabled again, there are none without apt
+func RestoreAptSources() (int, error) {
+	return 0, nil
+}
+
+// ListExternalRepoKeys lists the signing keys of the APT repositories. It finds none, as no package manager build tag is set.
+func ListExternalRepoKeys() ([]RepoKey, error) {
+	return nil, nil
+}
+
+// RenewExternalRepoKey downloads the key of an APT repository again, there are none without apt
+func RenewExternalRepoKey(repo string) error {
+	return fmt.Errorf("%s is not an APT repository", repo)
+}
@@ -0,0 +1,270 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: english_locale.go
+// Description: Generates the en_US.UTF-8 locale Pi-Apps needs to read the output of package managers,
+// without prompting and without touching the system when the locale is already there.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package api
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+const (
+	// englishLocale is the locale generated by AddEnglish, as written in /etc/locale.gen
+	englishLocale = "en_US.UTF-8"
+	// localeGenFile lists the locales locale-gen generates
+	localeGenFile = "/etc/locale.gen"
+	// addEnglishLocaleSetting decides whether install flows may generate the English locale
+	addEnglishLocaleSetting = "Add English locale"
+)
+
+// EnglishLocaleState is the outcome of AddEnglish
+type EnglishLocaleState string
+
+const (
+	EnglishLocaleAlreadyPresent EnglishLocaleState = "already-present" // the locale was already generated, nothing was changed
+	EnglishLocaleGenerated      EnglishLocaleState = "generated"       // the locale was added to /etc/locale.gen and generated
+	EnglishLocaleSkipped        EnglishLocaleState = "skipped"         // the "Add English locale" setting is set to No
+	EnglishLocaleFailed         EnglishLocaleState = "failed"          // the locale is missing and could not be generated
+)
+
+// EnglishLocaleResult tells what AddEnglish did, and why when the locale was skipped or could not be generated
+type EnglishLocaleResult struct {
+	State  EnglishLocaleState
+	Reason string
+}
+
+// AddEnglish makes sure the en_US.UTF-8 locale is generated, so the output of package managers can be
+// parsed, and fixes LANG and LC_ALL if they are set to a locale that makes applications crash.
+// If the "Add English locale" setting is set to No, the system locales are left alone.
+func AddEnglish() EnglishLocaleResult {
+	var result EnglishLocaleResult
+	if addEnglishLocaleEnabled() {
+		result = EnsureEnglishLocale()
+	} else {
+		result = EnglishLocaleResult{State: EnglishLocaleSkipped, Reason: T("disabled in the settings")}
+	}
+	if result.State == EnglishLocaleFailed {
+		WarningTf("Could not add the en_US.UTF-8 locale: %s", result.Reason)
+	}
+
+	fixLocaleEnvironment()
+	return result
+}
+
+// addEnglishLocaleEnabled reports whether the "Add English locale" setting is not set to No
+func addEnglishLocaleEnabled() bool {
+	directory := GetPiAppsDir()
+	if directory == "" {
+		return true
+	}
+	return ReadSettingIn(directory, addEnglishLocaleSetting) != "No"
+}
+
+// EnsureEnglishLocale generates the en_US.UTF-8 locale if it isn't generated yet. It returns right away
+// if the locale exists, and never asks questions: locale-gen runs with DEBIAN_FRONTEND=noninteractive,
+// and without a terminal or a display to ask for the password it fails instead of waiting.
+func EnsureEnglishLocale() EnglishLocaleResult {
+	if englishLocaleGenerated() {
+		return EnglishLocaleResult{State: EnglishLocaleAlreadyPresent}
+	}
+
+	// Systems without /usr/share/i18n/SUPPORTED may still generate it, so only a list without it is a failure
+	if supported, err := os.ReadFile("/usr/share/i18n/SUPPORTED"); err == nil && !strings.Contains(string(supported), englishLocale) {
+		return EnglishLocaleResult{State: EnglishLocaleFailed, Reason: T("en_US.UTF-8 is not supported by this system")}
+	}
+	if !commandExists("locale-gen") {
+		return EnglishLocaleResult{State: EnglishLocaleFailed, Reason: T("locale-gen is not installed")}
+	}
+	if !canElevate() {
+		return EnglishLocaleResult{State: EnglishLocaleFailed, Reason: T("administrator rights are needed, but there is no terminal or display to ask for the password")}
+	}
+
+	StatusT("Adding en_US locale for better logging...")
+
+	content, err := os.ReadFile(localeGenFile)
+	if err != nil && !os.IsNotExist(err) {
+		return EnglishLocaleResult{State: EnglishLocaleFailed, Reason: Tf("failed to read %s: %v", localeGenFile, err)}
+	}
+	if updated, changed := enableLocaleGenEntry(string(content), englishLocale); changed {
+		if err := installLocaleGen(updated); err != nil {
+			return EnglishLocaleResult{State: EnglishLocaleFailed, Reason: err.Error()}
+		}
+	}
+
+	if err := SudoPopup("env", "DEBIAN_FRONTEND=noninteractive", "locale-gen"); err != nil {
+		return EnglishLocaleResult{State: EnglishLocaleFailed, Reason: Tf("locale-gen failed: %v", err)}
+	}
+	if !englishLocaleGenerated() {
+		return EnglishLocaleResult{State: EnglishLocaleFailed, Reason: T("locale-gen finished, but en_US.UTF-8 is still not generated")}
+	}
+	return EnglishLocaleResult{State: EnglishLocaleGenerated}
+}
+
+// englishLocaleGenerated reports whether en_US.UTF-8 is among the generated locales. `locale -a` writes
+// the codeset as utf8 or UTF-8 depending on the libc, so both are accepted.
+func englishLocaleGenerated() bool {
+	if output, err := exec.Command("locale", "-a").Output(); err == nil {
+		for _, line := range strings.Split(string(output), "\n") {
+			if normalizeLocaleName(line) == normalizeLocaleName(englishLocale) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, name := range []string{"en_US.utf8", "en_US.UTF-8"} {
+		if DirExists(filepath.Join("/usr/lib/locale", name)) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeLocaleName lowercases a locale name and drops the dash of its codeset, so en_US.UTF-8 matches en_US.utf8
+func normalizeLocaleName(name string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(name), "-", ""))
+}
+
+// enableLocaleGenEntry enables a locale in the content of a locale.gen file. A commented entry is
+// uncommented, a missing one is appended, and duplicate enabled entries left by earlier runs are
+// removed, so running it again never changes the file.
+//
+//	string - the new content of the file
+//	bool - whether the content changed
+func enableLocaleGenEntry(content, locale string) (string, bool) {
+	entry := locale + " " + strings.TrimPrefix(filepath.Ext(locale), ".")
+	lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	if content == "" {
+		lines = nil
+	}
+
+	enabled := false
+	commented := -1
+	var result []string
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.Join(strings.Fields(trimmed), " ") == entry {
+			if enabled {
+				continue
+			}
+			enabled = true
+		} else if commented < 0 && strings.HasPrefix(trimmed, "#") &&
+			strings.Join(strings.Fields(strings.TrimLeft(trimmed, "# ")), " ") == entry {
+			commented = len(result)
+		}
+		result = append(result, line)
+	}
+
+	switch {
+	case enabled:
+	case commented >= 0:
+		result[commented] = entry
+	default:
+		result = append(result, entry)
+	}
+
+	updated := strings.Join(result, "\n") + "\n"
+	return updated, updated != content
+}
+
+// installLocaleGen replaces /etc/locale.gen with the given content, through a temporary file so a
+// failed copy can't leave it half written
+func installLocaleGen(content string) error {
+	tmp, err := os.CreateTemp("", "locale.gen-*")
+	if err != nil {
+		return fmt.Errorf(T("failed to create a temporary file: %w"), err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.WriteString(content); err != nil {
+		tmp.Close()
+		return fmt.Errorf(T("failed to write the temporary file: %w"), err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf(T("failed to write the temporary file: %w"), err)
+	}
+	if err := SudoPopup("install", "-m", "0644", tmp.Name(), localeGenFile); err != nil {
+		return fmt.Errorf(T("failed to edit %s: %w"), localeGenFile, err)
+	}
+	return nil
+}
+
+// canElevate reports whether SudoPopup can get administrator rights without hanging: sudo works
+// without a password, or there is a terminal or a display to ask for it on
+func canElevate() bool {
+	if os.Geteuid() == 0 || exec.Command("sudo", "-n", "true").Run() == nil {
+		return true
+	}
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		return true
+	}
+	return os.Getenv("DISPLAY") != "" || os.Getenv("WAYLAND_DISPLAY") != ""
+}
+
+// fixLocaleEnvironment replaces a LANG or LC_ALL that would make applications crash (C, POSIX or a
+// non-UTF-8 locale) with en_US.UTF-8, and converts ISO-8859-1 locales to their UTF-8 version
+func fixLocaleEnvironment() {
+	lang := os.Getenv("LANG")
+	lcAll := os.Getenv("LC_ALL")
+
+	// Check if the current locale is problematic
+	needsFixing := false
+	fixedLang := lang
+	fixedLcAll := lcAll
+
+	// If the user's locale has the ISO-8859-1 encoding associated with it, keep the language by converting it to UTF-8 version
+	if strings.Contains(lang, "ISO-8859-1") {
+		fixedLang = strings.Replace(lang, "ISO-8859-1", "UTF-8", 1)
+		StatusT("Converting locale from ISO-8859-1 to UTF-8: %s", fixedLang)
+		os.Setenv("LANG", fixedLang)
+	}
+
+	if strings.Contains(lcAll, "ISO-8859-1") {
+		fixedLcAll = strings.Replace(lcAll, "ISO-8859-1", "UTF-8", 1)
+		StatusT("Converting LC_ALL from ISO-8859-1 to UTF-8: %s", fixedLcAll)
+		os.Setenv("LC_ALL", fixedLcAll)
+	}
+
+	// If LC_ALL is set and problematic, it overrides everything else
+	if fixedLcAll != "" {
+		if fixedLcAll == "C" || fixedLcAll == "POSIX" || !strings.Contains(fixedLcAll, "UTF-8") {
+			needsFixing = true
+		}
+	} else if fixedLang != "" {
+		// Check LANG if LC_ALL is not set
+		if fixedLang == "C" || fixedLang == "POSIX" || !strings.Contains(fixedLang, "UTF-8") {
+			needsFixing = true
+		}
+	} else {
+		// No locale set at all, default to English UTF-8
+		needsFixing = true
+	}
+
+	// Only override if the current locale would cause problems
+	if needsFixing {
+		StatusT("Setting locale to en_US.UTF-8 to prevent application crashes...")
+		os.Setenv("LANG", "en_US.UTF-8")
+		os.Setenv("LC_ALL", "en_US.UTF-8")
+	}
+}
@@ -0,0 +1,154 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// testLocaleGen is the start of the /etc/locale.gen of Raspberry Pi OS, where every locale is commented out
+const testLocaleGen = `# This file lists locales that you wish to have built. You can find a list
+# of valid supported locales at /usr/share/i18n/SUPPORTED, and you can add
+# user defined locales to /usr/local/share/i18n/SUPPORTED. If you change
+# this file, you need to rerun locale-gen.
+
+# en_GB.UTF-8 UTF-8
+# en_US ISO-8859-1
+# en_US.ISO-8859-15 ISO-8859-15
+# en_US.UTF-8 UTF-8
+# fr_FR.UTF-8 UTF-8
+`
+
+func TestEnableLocaleGenEntry(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			name:    "commented entry",
+			content: testLocaleGen,
+			want:    testLocaleGen[:len(testLocaleGen)-len("# en_US.UTF-8 UTF-8\n# fr_FR.UTF-8 UTF-8\n")] + "en_US.UTF-8 UTF-8\n# fr_FR.UTF-8 UTF-8\n",
+		},
+		{
+			name:    "commented entry without a space after the hash",
+			content: "#en_US.UTF-8 UTF-8\n",
+			want:    "en_US.UTF-8 UTF-8\n",
+		},
+		{
+			name:    "missing entry",
+			content: "de_DE.UTF-8 UTF-8\n",
+			want:    "de_DE.UTF-8 UTF-8\nen_US.UTF-8 UTF-8\n",
+		},
+		{
+			name:    "missing final newline",
+			content: "de_DE.UTF-8 UTF-8",
+			want:    "de_DE.UTF-8 UTF-8\nen_US.UTF-8 UTF-8\n",
+		},
+		{
+			name:    "empty file",
+			content: "",
+			want:    "en_US.UTF-8 UTF-8\n",
+		},
+		{
+			name:    "duplicates left by earlier runs",
+			content: "en_US.UTF-8 UTF-8\nde_DE.UTF-8 UTF-8\nen_US.UTF-8  UTF-8\nen_US.UTF-8 UTF-8\n",
+			want:    "en_US.UTF-8 UTF-8\nde_DE.UTF-8 UTF-8\n",
+		},
+		{
+			name:    "other encodings of en_US are left alone",
+			content: "en_US ISO-8859-1\n# en_US.ISO-8859-15 ISO-8859-15\n",
+			want:    "en_US ISO-8859-1\n# en_US.ISO-8859-15 ISO-8859-15\nen_US.UTF-8 UTF-8\n",
+		},
+	}
+	for _, tt := range tests {
+		// Run against a file like AddEnglish does, then again on the result, which must not change it
+		path := filepath.Join(t.TempDir(), "locale.gen")
+		writeTestFile(t, path, tt.content)
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got, changed := enableLocaleGenEntry(string(content), englishLocale)
+		if got != tt.want {
+			t.Errorf("%s: enableLocaleGenEntry =\n%s\nwant\n%s", tt.name, got, tt.want)
+		}
+		if changed != (tt.want != tt.content) {
+			t.Errorf("%s: changed = %v", tt.name, changed)
+		}
+		writeTestFile(t, path, got)
+		again, _ := os.ReadFile(path)
+		if twice, changed := enableLocaleGenEntry(string(again), englishLocale); changed || twice != got {
+			t.Errorf("%s: running again changed the file to\n%s", tt.name, twice)
+		}
+	}
+}
+
+func TestNormalizeLocaleName(t *testing.T) {
+	for _, name := range []string{"en_US.UTF-8", "en_US.utf8", " en_US.UTF8\n"} {
+		if normalizeLocaleName(name) != normalizeLocaleName(englishLocale) {
+			t.Errorf("%q does not match %s", name, englishLocale)
+		}
+	}
+	if normalizeLocaleName("en_GB.UTF-8") == normalizeLocaleName(englishLocale) {
+		t.Error("en_GB.UTF-8 matches en_US.UTF-8")
+	}
+}
+
+func TestAddEnglishSkipped(t *testing.T) {
+	directory := newTestPiAppsDir(t)
+	if !addEnglishLocaleEnabled() {
+		t.Error("the English locale is disabled without the setting")
+	}
+	writeTestFile(t, filepath.Join(directory, "data", "settings", addEnglishLocaleSetting), "No\n")
+	t.Setenv("LANG", "C")
+	t.Setenv("LC_ALL", "")
+
+	result := AddEnglish()
+	if result.State != EnglishLocaleSkipped || result.Reason == "" {
+		t.Errorf("AddEnglish with the setting on No = %+v, want skipped with a reason", result)
+	}
+	// The environment is still fixed, that doesn't touch the system
+	if os.Getenv("LANG") != "en_US.UTF-8" {
+		t.Errorf("LANG = %q, want en_US.UTF-8", os.Getenv("LANG"))
+	}
+}
+
+func TestFixLocaleEnvironment(t *testing.T) {
+	tests := []struct {
+		lang, lcAll         string
+		wantLang, wantLcAll string
+	}{
+		{"de_DE.UTF-8", "", "de_DE.UTF-8", ""},
+		{"C", "", "en_US.UTF-8", "en_US.UTF-8"},
+		{"", "", "en_US.UTF-8", "en_US.UTF-8"},
+		{"de_DE.UTF-8", "POSIX", "en_US.UTF-8", "en_US.UTF-8"},
+		{"fr_FR.ISO-8859-1", "", "fr_FR.UTF-8", ""},
+	}
+	for _, tt := range tests {
+		t.Setenv("LANG", tt.lang)
+		t.Setenv("LC_ALL", tt.lcAll)
+		fixLocaleEnvironment()
+		if lang, lcAll := os.Getenv("LANG"), os.Getenv("LC_ALL"); lang != tt.wantLang || lcAll != tt.wantLcAll {
+			t.Errorf("LANG=%q LC_ALL=%q became LANG=%q LC_ALL=%q, want LANG=%q LC_ALL=%q",
+				tt.lang, tt.lcAll, lang, lcAll, tt.wantLang, tt.wantLcAll)
+		}
+	}
+}
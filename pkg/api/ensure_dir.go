@@ -0,0 +1,230 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: ensure_dir.go
+// Description: Creates directories with a given mode or owner, reporting which ones were created, and records the
+// directories an install script created so uninstalling the app removes them again.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package api
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// InvokingUser passed as the uid or gid of EnsureDirOwned stands for the user who started Pi-Apps, the one sudo
+// was run by when Pi-Apps runs elevated
+const InvokingUser = -1
+
+// installCreatedDirPrefix starts the install manifest lines recording the directories `api ensure_dir` created
+// for an app, the only directories uninstalling it removes
+const installCreatedDirPrefix = "# created dir: "
+
+// EnsureParents creates a directory and its missing parents with mode, before the umask, like mkdir -p.
+//
+//	[]string - the directories that were created, outermost first, empty if the directory existed
+//	error - error if a directory can't be created or a path on the way is not a directory
+func EnsureParents(path string, mode os.FileMode) ([]string, error) {
+	path = filepath.Clean(path)
+	var missing []string
+	for dir := path; ; dir = filepath.Dir(dir) {
+		info, err := os.Stat(dir)
+		if err == nil {
+			if !info.IsDir() {
+				return nil, fmt.Errorf("failed to create directory %s: %s is not a directory", path, dir)
+			}
+			break
+		}
+		if !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to create directory %s: %w", path, err)
+		}
+		missing = append(missing, dir)
+		if parent := filepath.Dir(dir); parent == dir {
+			break
+		}
+	}
+
+	var created []string
+	for _, dir := range slices.Backward(missing) {
+		if err := os.Mkdir(dir, mode); err != nil {
+			// Someone else created it meanwhile, then it isn't ours to remove
+			if errors.Is(err, os.ErrExist) && DirExists(dir) {
+				continue
+			}
+			return created, fmt.Errorf("failed to create directory %s: %w", path, err)
+		}
+		created = append(created, dir)
+	}
+	return created, nil
+}
+
+// dirModeBits are the bits of a mode EnsureDirMode sets
+const dirModeBits = os.ModePerm | os.ModeSetuid | os.ModeSetgid | os.ModeSticky
+
+// EnsureDirMode creates a directory and its missing parents, and gives the directory the permissions of mode
+// whatever the umask is, also when it existed with other permissions
+func EnsureDirMode(path string, mode os.FileMode) error {
+	if _, err := EnsureParents(path, 0755); err != nil {
+		return err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if info.Mode()&dirModeBits == mode&dirModeBits {
+		return nil
+	}
+	if err := os.Chmod(path, mode); err != nil {
+		return fmt.Errorf("failed to change the permissions of %s: %w", path, err)
+	}
+	return nil
+}
+
+// EnsureDirOwned creates a directory and its missing parents owned by uid and gid. Running as root, it also gives
+// the directory that owner when it existed with another one, like after an earlier run with sudo. InvokingUser
+// stands for the user who started Pi-Apps, which is the one in SUDO_UID and SUDO_GID when Pi-Apps runs elevated.
+func EnsureDirOwned(path string, uid, gid int) error {
+	invokingUID, invokingGID := invokingUserIDs()
+	if uid < 0 {
+		uid = invokingUID
+	}
+	if gid < 0 {
+		gid = invokingGID
+	}
+	created, err := EnsureParents(path, 0755)
+	if err != nil {
+		return err
+	}
+	path = filepath.Clean(path)
+	if !slices.Contains(created, path) && os.Geteuid() == 0 {
+		created = append(created, path)
+	}
+	for _, dir := range created {
+		info, err := os.Stat(dir)
+		if err != nil {
+			return err
+		}
+		if stat, ok := info.Sys().(*syscall.Stat_t); ok && int(stat.Uid) == uid && int(stat.Gid) == gid {
+			continue
+		}
+		if err := os.Chown(dir, uid, gid); err != nil {
+			return fmt.Errorf("failed to change the owner of %s: %w", dir, err)
+		}
+	}
+	return nil
+}
+
+// invokingUserIDs returns the uid and gid of the user who started Pi-Apps: the one sudo was run by when Pi-Apps
+// runs elevated, otherwise the real user, which is the invoking one for a setuid binary too
+var invokingUserIDs = func() (int, int) {
+	if os.Geteuid() == 0 {
+		uid, uidErr := strconv.Atoi(os.Getenv("SUDO_UID"))
+		gid, gidErr := strconv.Atoi(os.Getenv("SUDO_GID"))
+		if uidErr == nil && gidErr == nil {
+			return uid, gid
+		}
+	}
+	return os.Getuid(), os.Getgid()
+}
+
+// EnsureAppDir creates a directory for an app script like `api ensure_dir` does: with its missing parents, and
+// with the permissions of mode if it isn't 0. The directories it created are recorded in the install manifest of
+// app, so uninstalling the app removes them when they are empty, no app records nothing.
+//
+//	[]string - the directories that were created, outermost first
+//	error - error if a directory can't be created or recorded
+func EnsureAppDir(app, path string, mode os.FileMode) ([]string, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	created, err := EnsureParents(path, 0755)
+	if err == nil && mode != 0 {
+		err = EnsureDirMode(path, mode)
+	}
+	if app != "" && len(created) > 0 {
+		if recordErr := recordCreatedDirs(app, created); recordErr != nil {
+			err = errors.Join(err, fmt.Errorf("failed to record the created directories in the install manifest: %w", recordErr))
+		}
+	}
+	return created, err
+}
+
+// recordCreatedDirs adds directories to the created directories of an app's install manifest
+func recordCreatedDirs(app string, dirs []string) error {
+	lines, err := readInstallManifest(app)
+	if err != nil {
+		return err
+	}
+	for _, dir := range dirs {
+		if line := installCreatedDirPrefix + dir; !slices.Contains(lines, line) {
+			lines = append(lines, line)
+		}
+	}
+	return writeInstallManifest(app, lines)
+}
+
+// readCreatedDirLines returns the lines of an app's install manifest recording the directories created for it
+func readCreatedDirLines(app string) ([]string, error) {
+	lines, err := readInstallManifest(app)
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(lines, func(line string) bool { return !strings.HasPrefix(line, installCreatedDirPrefix) }), nil
+}
+
+// removeCreatedDirs removes the directories created for an app that are empty after its uninstall script ran,
+// the deepest first, and forgets them in the install manifest. Directories with files left in them are kept.
+func removeCreatedDirs(app string, logFile *os.File) {
+	lines, err := readCreatedDirLines(app)
+	if err != nil || len(lines) == 0 {
+		return
+	}
+	dirs := make([]string, len(lines))
+	for i, line := range lines {
+		dirs[i] = strings.TrimPrefix(line, installCreatedDirPrefix)
+	}
+	slices.SortFunc(dirs, func(a, b string) int { return strings.Count(b, "/") - strings.Count(a, "/") })
+
+	var removed []string
+	for _, dir := range dirs {
+		if err := os.Remove(dir); err == nil || os.IsNotExist(err) {
+			removed = append(removed, dir)
+		}
+	}
+	if len(removed) == 0 {
+		return
+	}
+	fmt.Fprintf(logFile, "Removed the empty directories created for %s: %s\n", app, strings.Join(removed, ", "))
+
+	manifest, err := readInstallManifest(app)
+	if err == nil {
+		err = writeInstallManifest(app, slices.DeleteFunc(manifest, func(line string) bool {
+			dir, ok := strings.CutPrefix(line, installCreatedDirPrefix)
+			return ok && slices.Contains(removed, dir)
+		}))
+	}
+	if err != nil {
+		Debug(fmt.Sprintf("Failed to forget the removed directories in the install manifest of %s: %v", app, err))
+	}
+}
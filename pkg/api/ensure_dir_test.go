@@ -0,0 +1,210 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"syscall"
+	"testing"
+)
+
+// setUmask sets the umask of the test process until the test ends
+func setUmask(t *testing.T, umask int) {
+	t.Helper()
+	previous := syscall.Umask(umask)
+	t.Cleanup(func() { syscall.Umask(previous) })
+}
+
+func dirPerm(t *testing.T, path string) os.FileMode {
+	t.Helper()
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return info.Mode().Perm()
+}
+
+func TestEnsureParents(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, "a", "b", "c")
+	created, err := EnsureParents(path, 0755)
+	want := []string{filepath.Join(root, "a"), filepath.Join(root, "a", "b"), path}
+	if err != nil || !slices.Equal(created, want) {
+		t.Fatalf("EnsureParents = %q, %v, want %q", created, err, want)
+	}
+
+	// Directories that exist are not reported, they aren't Pi-Apps' to remove
+	created, err = EnsureParents(filepath.Join(path, "d"), 0755)
+	if err != nil || !slices.Equal(created, []string{filepath.Join(path, "d")}) {
+		t.Errorf("EnsureParents below existing directories = %q, %v", created, err)
+	}
+	if created, err = EnsureParents(path+"/", 0755); err != nil || len(created) != 0 {
+		t.Errorf("EnsureParents of an existing directory = %q, %v, want nothing created", created, err)
+	}
+
+	writeTestFile(t, filepath.Join(root, "file"), "")
+	if _, err := EnsureParents(filepath.Join(root, "file", "sub"), 0755); err == nil {
+		t.Error("EnsureParents below a file succeeded, want an error")
+	}
+}
+
+func TestEnsureDirModeUmask(t *testing.T) {
+	root := t.TempDir()
+
+	// The umask applies to the directories EnsureParents creates, like it does for mkdir -p
+	setUmask(t, 077)
+	parents := filepath.Join(root, "parents")
+	if _, err := EnsureParents(parents, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if perm := dirPerm(t, parents); perm != 0700 {
+		t.Errorf("EnsureParents with umask 077 created %o, want 700", perm)
+	}
+
+	// but not to the mode EnsureDirMode gives
+	shared := filepath.Join(root, "shared", "dir")
+	if err := EnsureDirMode(shared, 0775); err != nil {
+		t.Fatal(err)
+	}
+	if perm := dirPerm(t, shared); perm != 0775 {
+		t.Errorf("EnsureDirMode(0775) with umask 077 = %o, want 775", perm)
+	}
+
+	setUmask(t, 0)
+	private := filepath.Join(root, "private")
+	if err := EnsureDirMode(private, 0700); err != nil {
+		t.Fatal(err)
+	}
+	if perm := dirPerm(t, private); perm != 0700 {
+		t.Errorf("EnsureDirMode(0700) with umask 0 = %o, want 700", perm)
+	}
+}
+
+func TestEnsureDirModeExisting(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secrets")
+	if err := os.Mkdir(path, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(path, 0777); err != nil {
+		t.Fatal(err)
+	}
+	if err := EnsureDirMode(path, 0700); err != nil {
+		t.Fatal(err)
+	}
+	if perm := dirPerm(t, path); perm != 0700 {
+		t.Errorf("EnsureDirMode of a directory with mode 777 = %o, want 700", perm)
+	}
+
+	writeTestFile(t, filepath.Join(path, "file"), "")
+	if err := EnsureDirMode(filepath.Join(path, "file"), 0700); err == nil {
+		t.Error("EnsureDirMode of a file succeeded, want an error")
+	}
+}
+
+func TestEnsureDirOwned(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("changing the owner of a directory needs root")
+	}
+	root := t.TempDir()
+	existing := filepath.Join(root, "existing")
+	if err := os.Mkdir(existing, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	// Running elevated, InvokingUser is the user sudo was run by
+	t.Setenv("SUDO_UID", "12345")
+	t.Setenv("SUDO_GID", "23456")
+	path := filepath.Join(existing, "a", "b")
+	if err := EnsureDirOwned(path, InvokingUser, InvokingUser); err != nil {
+		t.Fatal(err)
+	}
+	for dir, want := range map[string][2]uint32{
+		filepath.Join(existing, "a"): {12345, 23456},
+		path:                         {12345, 23456},
+		existing:                     {0, 0},
+	} {
+		info, err := os.Stat(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		stat := info.Sys().(*syscall.Stat_t)
+		if stat.Uid != want[0] || stat.Gid != want[1] {
+			t.Errorf("owner of %s = %d:%d, want %d:%d", dir, stat.Uid, stat.Gid, want[0], want[1])
+		}
+	}
+
+	// A directory an earlier run with sudo left owned by root gets the right owner
+	if err := EnsureDirOwned(existing, 34567, 45678); err != nil {
+		t.Fatal(err)
+	}
+	info, _ := os.Stat(existing)
+	if stat := info.Sys().(*syscall.Stat_t); stat.Uid != 34567 || stat.Gid != 45678 {
+		t.Errorf("owner of the existing directory = %d:%d, want 34567:45678", stat.Uid, stat.Gid)
+	}
+}
+
+func TestEnsureAppDir(t *testing.T) {
+	newTestPiAppsDir(t, "Game")
+	home := t.TempDir()
+	config := filepath.Join(home, ".config")
+	if err := os.Mkdir(config, 0755); err != nil {
+		t.Fatal(err)
+	}
+	saves := filepath.Join(config, "game", "saves")
+	created, err := EnsureAppDir("Game", saves, 0700)
+	want := []string{filepath.Join(config, "game"), saves}
+	if err != nil || !slices.Equal(created, want) {
+		t.Fatalf("EnsureAppDir = %q, %v, want %q", created, err, want)
+	}
+	if perm := dirPerm(t, saves); perm != 0700 {
+		t.Errorf("EnsureAppDir(0700) = %o", perm)
+	}
+
+	// The created directories survive the rewrite of the manifest after the install script ran
+	if err := writeInstalledFiles("Game", []string{"/usr/local/bin/game"}, ResourceLimits{}, false); err != nil {
+		t.Fatal(err)
+	}
+	manifest, err := ReadInstallManifest("Game")
+	if err != nil || !slices.Equal(manifest.CreatedDirs, want) {
+		t.Fatalf("created directories in the manifest = %q, %v, want %q", manifest.CreatedDirs, err, want)
+	}
+
+	// Uninstalling removes them, but not while the user still has files in them, and never the ones that existed
+	logFile, err := os.CreateTemp(t.TempDir(), "log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logFile.Close()
+	writeTestFile(t, filepath.Join(saves, "slot1"), "progress")
+	removeCreatedDirs("Game", logFile)
+	if !DirExists(saves) {
+		t.Error("removeCreatedDirs removed a directory with files in it")
+	}
+	if err := clearInstallManifest("Game"); err != nil {
+		t.Fatal(err)
+	}
+	os.Remove(filepath.Join(saves, "slot1"))
+	removeCreatedDirs("Game", logFile)
+	if DirExists(filepath.Join(config, "game")) || !DirExists(config) {
+		t.Error("removeCreatedDirs did not remove exactly the directories it created")
+	}
+	if manifest, err := ReadInstallManifest("Game"); err != nil || len(manifest.CreatedDirs) != 0 {
+		t.Errorf("created directories after removing them = %q, %v, want none", manifest.CreatedDirs, err)
+	}
+}
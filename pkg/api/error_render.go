@@ -0,0 +1,123 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: error_render.go
+// Description: Turns the kinds of errors in pkg/api/errs into the message, hint and exit code the CLI and GUI show.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package api
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+
+	"github.com/pi-apps-go/pi-apps/pkg/api/errs"
+)
+
+// ExitNeedsRoot is the exit code for errors that need administrative privileges, EX_NOPERM of sysexits.h
+const ExitNeedsRoot = 77
+
+// RenderedError is an error as the user sees it
+type RenderedError struct {
+	Message  string // what failed, the error message itself
+	Hint     string // translated advice on what to do about it, "" if there is none
+	ExitCode int    // exit code for the CLI
+}
+
+// RenderError returns the message, hint and exit code for an error, based on its kind.
+// Failed app scripts keep their own exit code, so the convention of exit_codes.go reaches the caller.
+func RenderError(err error) RenderedError {
+	if err == nil {
+		return RenderedError{}
+	}
+	rendered := RenderedError{Message: err.Error(), ExitCode: 1}
+
+	switch errs.Kind(err) {
+	case errs.ErrCancelled:
+		rendered.Hint = T("The action was cancelled, nothing else failed.")
+		rendered.ExitCode = ExitUserDeclined
+		return rendered
+	case errs.ErrNeedsRoot:
+		rendered.Hint = T("This needs administrative privileges. Make sure your user can use sudo, then try again.")
+		rendered.ExitCode = ExitNeedsRoot
+		return rendered
+	case errs.ErrKioskRestricted:
+		rendered.Hint = T("This computer is in kiosk mode. Ask its administrator, or unlock kiosk mode with the admin PIN.")
+		rendered.ExitCode = ExitNeedsRoot
+		return rendered
+	case errs.ErrNoExec:
+		rendered.Hint = T("Nothing was installed. Fix the Pi-Apps folder as explained above, then try again.")
+		return rendered
+	case errs.ErrAppNotFound:
+		rendered.Hint = T("Check the spelling of the app name. Its exact name is shown in the app list, and 'api list_apps' lists every app.")
+		return rendered
+	case errs.ErrAlreadyInstalled:
+		rendered.Hint = T("Uninstall it first to install it again.")
+		return rendered
+	case errs.ErrNotInstalled:
+		rendered.Hint = T("Only installed apps can be uninstalled or updated.")
+		return rendered
+	case errs.ErrUnsupportedArch:
+		rendered.Hint = T("This app does not support your system, so there is nothing to retry.")
+		rendered.ExitCode = ExitUnsupportedSystem
+		return rendered
+	case errs.ErrUnhealthy:
+		rendered.Hint = T("The app was installed, but its health check says it doesn't work. The output of the check is at the end of its log, reinstall the app or ask for help with the log.")
+		return rendered
+	case errs.ErrAptLocked:
+		rendered.Hint = T("Another program is installing or removing packages. Wait until it finishes, then try again.")
+		rendered.ExitCode = ExitPackageFailed
+		return rendered
+	case errs.ErrNetwork:
+		rendered.Hint = T("Check your internet connection, then try again.")
+		rendered.ExitCode = ExitDownloadFailed
+		return rendered
+	}
+
+	if scriptErr := errs.ScriptFailed(err); scriptErr != nil {
+		rendered.ExitCode = scriptErr.ExitCode
+		if reason := ExitCodeReason(scriptErr.ExitCode); reason != "" {
+			rendered.Hint = reason
+		} else if scriptErr.App != "" {
+			rendered.Hint = Tf("The log of %s explains what went wrong, open it from the app's details to see the errors.", scriptErr.App)
+		}
+	}
+	return rendered
+}
+
+// ErrorExit prints an error with its hint and exits with the exit code RenderError picks for it
+func ErrorExit(err error) {
+	rendered := RenderError(err)
+	ErrorNoExit(Tf("Error: %v", rendered.Message))
+	if rendered.Hint != "" {
+		Status(rendered.Hint)
+	}
+	os.Exit(rendered.ExitCode)
+}
+
+// packageLockPattern matches the messages of apt, pacman and apk when another process holds their lock
+var packageLockPattern = regexp.MustCompile(`(?i)(could not get lock|unable to lock|is locked by another process)`)
+
+// packageManagerError returns the error for the errors a package manager printed, marked with
+// errs.ErrAptLocked when it failed because another process held its lock
+func packageManagerError(manager, errorStr string) error {
+	err := fmt.Errorf("%s reported errors: %s", manager, errorStr)
+	if packageLockPattern.MatchString(errorStr) {
+		return errs.Wrap(errs.ErrAptLocked, err)
+	}
+	return err
+}
@@ -0,0 +1,139 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pi-apps-go/pi-apps/pkg/api/errs"
+)
+
+func TestRenderError(t *testing.T) {
+	tests := []struct {
+		err      error
+		exitCode int
+		hint     bool
+	}{
+		{errs.New(errs.ErrCancelled, "cancelled"), ExitUserDeclined, true},
+		{errs.New(errs.ErrNeedsRoot, "no sudo"), ExitNeedsRoot, true},
+		{errs.New(errs.ErrKioskRestricted, "uninstalling is disabled"), ExitNeedsRoot, true},
+		{errs.New(errs.ErrAppNotFound, "app 'x' does not exist"), 1, true},
+		{errs.New(errs.ErrAlreadyInstalled, "installed"), 1, true},
+		{errs.New(errs.ErrNotInstalled, "not installed"), 1, true},
+		{errs.New(errs.ErrUnsupportedArch, "no install-32"), ExitUnsupportedSystem, true},
+		{errs.New(errs.ErrUnhealthy, "the health check of Zoom failed"), 1, true},
+		{errs.New(errs.ErrAptLocked, "locked"), ExitPackageFailed, true},
+		{errs.New(errs.ErrNetwork, "timeout"), ExitDownloadFailed, true},
+		{&errs.ErrScriptFailed{App: "Zoom", Action: "install", ExitCode: ExitDownloadFailed}, ExitDownloadFailed, true},
+		{&errs.ErrScriptFailed{App: "Zoom", Action: "install", ExitCode: 2}, 2, true},
+		{&errs.ErrScriptFailed{ExitCode: 3}, 3, false},
+		{errors.New("something else"), 1, false},
+	}
+	for _, tt := range tests {
+		err := fmt.Errorf("failed to install Zoom: %w", tt.err)
+		rendered := RenderError(err)
+		if rendered.Message != err.Error() {
+			t.Errorf("RenderError(%v).Message = %q", err, rendered.Message)
+		}
+		if rendered.ExitCode != tt.exitCode {
+			t.Errorf("RenderError(%v).ExitCode = %d, want %d", err, rendered.ExitCode, tt.exitCode)
+		}
+		if (rendered.Hint != "") != tt.hint {
+			t.Errorf("RenderError(%v).Hint = %q", err, rendered.Hint)
+		}
+	}
+	if RenderError(nil) != (RenderedError{}) {
+		t.Error("RenderError(nil) is not empty")
+	}
+}
+
+func TestPackageManagerError(t *testing.T) {
+	for _, output := range []string{
+		"E: Could not get lock /var/lib/dpkg/lock-frontend. It is held by process 1234 (apt)",
+		"error: failed to init transaction (unable to lock database)",
+		"ERROR: Unable to lock database: temporary error",
+	} {
+		if err := packageManagerError("apt", output); !errors.Is(err, errs.ErrAptLocked) {
+			t.Errorf("packageManagerError(%q) = %v, want ErrAptLocked", output, err)
+		}
+	}
+	err := packageManagerError("apt", "E: Unable to locate package zoom")
+	if errs.Kind(err) != nil || err.Error() != "apt reported errors: E: Unable to locate package zoom" {
+		t.Errorf("packageManagerError = %v, want a plain error", err)
+	}
+}
+
+// TestErrorKindsThroughCallChains checks that the core flows return errors their callers can branch on
+func TestErrorKindsThroughCallChains(t *testing.T) {
+	dir := newTestPiAppsDir(t, "Installed", "Failing")
+	writeTestFile(t, filepath.Join(dir, "apps", "Installed", "install"), "#!/bin/bash\n")
+	writeTestFile(t, filepath.Join(dir, "apps", "Installed", "uninstall"), "#!/bin/bash\n")
+	writeTestFile(t, filepath.Join(dir, "apps", "Failing", "install"), "#!/bin/bash\n")
+	writeTestFile(t, filepath.Join(dir, "apps", "Failing", "uninstall"), "#!/bin/bash\nexit 40\n")
+	writeTestFile(t, filepath.Join(dir, "data", "status", "Installed"), "installed")
+	writeTestFile(t, filepath.Join(dir, "data", "status", "Failing"), "installed")
+	t.Setenv("HOME", t.TempDir())
+
+	if err := InstallApp("Does Not Exist"); !errors.Is(err, errs.ErrAppNotFound) {
+		t.Errorf("InstallApp of a missing app = %v, want ErrAppNotFound", err)
+	}
+	if err := InstallApp("Installed"); !errors.Is(err, errs.ErrAlreadyInstalled) {
+		t.Errorf("InstallApp of an installed app = %v, want ErrAlreadyInstalled", err)
+	}
+	if err := UninstallApp("Does Not Exist"); !errors.Is(err, errs.ErrAppNotFound) {
+		t.Errorf("UninstallApp of a missing app = %v, want ErrAppNotFound", err)
+	}
+	writeTestFile(t, filepath.Join(dir, "data", "status", "Installed"), "uninstalled")
+	if err := UninstallApp("Installed"); !errors.Is(err, errs.ErrNotInstalled) {
+		t.Errorf("UninstallApp of an uninstalled app = %v, want ErrNotInstalled", err)
+	}
+
+	err := UninstallApp("Failing")
+	scriptErr := errs.ScriptFailed(err)
+	if scriptErr == nil || scriptErr.App != "Failing" || scriptErr.Action != "uninstall" || scriptErr.ExitCode != ExitPackageFailed {
+		t.Fatalf("UninstallApp of a failing script = %v, want ErrScriptFailed with exit code %d", err, ExitPackageFailed)
+	}
+	if RenderError(err).ExitCode != ExitPackageFailed {
+		t.Errorf("RenderError exit code = %d, want %d", RenderError(err).ExitCode, ExitPackageFailed)
+	}
+}
+
+func TestDownloadFileNetworkErrors(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	destination := filepath.Join(t.TempDir(), "file")
+
+	if err := DownloadFile(server.URL+"/missing", destination); !errors.Is(err, errs.ErrNetwork) {
+		t.Errorf("DownloadFile of a missing file = %v, want ErrNetwork", err)
+	}
+	server.Close()
+	err := DownloadFile(server.URL+"/file", destination)
+	if !errors.Is(err, errs.ErrNetwork) {
+		t.Errorf("DownloadFile from a closed server = %v, want ErrNetwork", err)
+	}
+	if RenderError(fmt.Errorf("failed to install Zoom: %w", err)).ExitCode != ExitDownloadFailed {
+		t.Error("a failed download doesn't exit with the download exit code")
+	}
+	if _, statErr := os.Stat(destination); statErr == nil {
+		t.Error("a failed download left a file behind")
+	}
+}
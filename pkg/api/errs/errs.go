@@ -0,0 +1,147 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: errs.go
+// Description: Kinds of errors the core flows return, so callers branch on errors.Is and errors.As instead of the message.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+// Package errs defines the kinds of errors installing, uninstalling and updating apps can fail with.
+//
+// The core flows keep their own messages and mark them with a kind using New or Wrap:
+//
+//	return errs.New(errs.ErrAlreadyInstalled, "app '%s' is already installed", app)
+//
+// Callers then check the kind with errors.Is(err, errs.ErrAlreadyInstalled), and api.RenderError
+// turns it into a message, a hint and an exit code. This package only uses the standard library,
+// so every Pi-Apps package can import it.
+package errs
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Kinds of errors. Use errors.Is to check for them, the wrapped messages say what exactly failed.
+var (
+	ErrAppNotFound      = errors.New("app not found")
+	ErrAlreadyInstalled = errors.New("app already installed")
+	ErrNotInstalled     = errors.New("app not installed")
+	ErrUnsupportedArch  = errors.New("unsupported architecture")
+	ErrNetwork          = errors.New("network error")
+	ErrAptLocked        = errors.New("package manager locked")
+	ErrCancelled        = errors.New("cancelled")
+	ErrNeedsRoot        = errors.New("administrative privileges needed")
+	ErrNoExec           = errors.New("scripts can't run from the Pi-Apps directory")
+	ErrUnhealthy        = errors.New("health check failed")
+	ErrKioskRestricted  = errors.New("not allowed in kiosk mode")
+)
+
+// kinds lists the sentinel kinds in the order Kind checks them
+var kinds = []error{
+	ErrCancelled,
+	ErrNeedsRoot,
+	ErrNoExec,
+	ErrKioskRestricted,
+	ErrAppNotFound,
+	ErrAlreadyInstalled,
+	ErrNotInstalled,
+	ErrUnsupportedArch,
+	ErrUnhealthy,
+	ErrAptLocked,
+	ErrNetwork,
+}
+
+// ErrScriptFailed is returned when an app script exits with a non-zero exit code. Use errors.As to get it.
+type ErrScriptFailed struct {
+	App      string
+	Action   string
+	ExitCode int
+}
+
+func (e *ErrScriptFailed) Error() string {
+	return fmt.Sprintf("command failed: exit code %d", e.ExitCode)
+}
+
+// kindError is an error with its own message that errors.Is matches against a kind
+type kindError struct {
+	kind error
+	err  error
+}
+
+func (e *kindError) Error() string {
+	return e.err.Error()
+}
+
+func (e *kindError) Unwrap() []error {
+	return []error{e.kind, e.err}
+}
+
+// New returns an error of the given kind with a formatted message. The format may use %w to wrap a cause.
+func New(kind error, format string, args ...any) error {
+	return &kindError{kind: kind, err: fmt.Errorf(format, args...)}
+}
+
+// Wrap marks err with a kind, keeping its message and what it wraps. It returns nil if err is nil.
+func Wrap(kind error, err error) error {
+	if err == nil || errors.Is(err, kind) {
+		return err
+	}
+	return &kindError{kind: kind, err: err}
+}
+
+// Kind returns the kind of an error, nil if it has none. An error that is marked with
+// several kinds, like a cancelled download, gets the first one of ErrCancelled, ErrNeedsRoot, ErrNoExec,
+// ErrKioskRestricted, ErrAppNotFound, ErrAlreadyInstalled, ErrNotInstalled, ErrUnsupportedArch, ErrUnhealthy, ErrAptLocked and ErrNetwork.
+func Kind(err error) error {
+	for _, kind := range kinds {
+		if errors.Is(err, kind) {
+			return kind
+		}
+	}
+	return nil
+}
+
+// ScriptFailed returns the failed script an error comes from, nil if it doesn't come from one
+func ScriptFailed(err error) *ErrScriptFailed {
+	var scriptErr *ErrScriptFailed
+	if errors.As(err, &scriptErr) {
+		return scriptErr
+	}
+	return nil
+}
+
+// Retryable reports whether trying the same action again can succeed without the user changing anything first
+func Retryable(err error) bool {
+	switch Kind(err) {
+	case ErrAppNotFound, ErrAlreadyInstalled, ErrNotInstalled, ErrUnsupportedArch, ErrCancelled, ErrNoExec, ErrKioskRestricted:
+		return false
+	}
+	return true
+}
+
+// HasLog reports whether a failure happened while a script or package manager ran, so its log is worth diagnosing.
+// Errors found before anything ran, like an app that doesn't exist, leave no log behind.
+func HasLog(err error) bool {
+	if ScriptFailed(err) != nil {
+		return true
+	}
+	switch Kind(err) {
+	case ErrAppNotFound, ErrAlreadyInstalled, ErrNotInstalled, ErrUnsupportedArch, ErrCancelled, ErrNeedsRoot, ErrNoExec,
+		ErrKioskRestricted:
+		return false
+	}
+	return true
+}
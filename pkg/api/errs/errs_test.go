@@ -0,0 +1,114 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package errs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestNewAndWrap(t *testing.T) {
+	cause := errors.New("connection refused")
+	err := New(ErrNetwork, "download failed: %w", cause)
+	if err.Error() != "download failed: connection refused" {
+		t.Errorf("New kept the message %q", err.Error())
+	}
+	if !errors.Is(err, ErrNetwork) || !errors.Is(err, cause) {
+		t.Error("New lost the kind or the cause")
+	}
+
+	// Wrapping further up the call chain keeps both
+	wrapped := fmt.Errorf("failed to install Zoom: %w", err)
+	if Kind(wrapped) != ErrNetwork || !errors.Is(wrapped, cause) {
+		t.Errorf("Kind(%v) = %v, want ErrNetwork", wrapped, Kind(wrapped))
+	}
+
+	if Wrap(ErrAptLocked, nil) != nil {
+		t.Error("Wrap(nil) is not nil")
+	}
+	if got := Wrap(ErrNetwork, err); got != err {
+		t.Error("Wrap marked an error that already has the kind again")
+	}
+	locked := Wrap(ErrAptLocked, cause)
+	if locked.Error() != cause.Error() || !errors.Is(locked, ErrAptLocked) || !errors.Is(locked, cause) {
+		t.Errorf("Wrap = %v, want the message and cause kept", locked)
+	}
+
+	if Kind(errors.New("plain")) != nil || Kind(nil) != nil {
+		t.Error("an error without a kind has one")
+	}
+}
+
+func TestKindPrecedence(t *testing.T) {
+	// A download cancelled by the user is a cancellation, not a network error
+	err := New(ErrNetwork, "download failed: %w", New(ErrCancelled, "cancelled: %w", context.Canceled))
+	if Kind(err) != ErrCancelled {
+		t.Errorf("Kind = %v, want ErrCancelled", Kind(err))
+	}
+	err = Wrap(ErrAptLocked, New(ErrNeedsRoot, "no sudo"))
+	if Kind(err) != ErrNeedsRoot {
+		t.Errorf("Kind = %v, want ErrNeedsRoot", Kind(err))
+	}
+}
+
+func TestScriptFailed(t *testing.T) {
+	scriptErr := &ErrScriptFailed{App: "Zoom", Action: "install", ExitCode: 30}
+	err := fmt.Errorf("failed to install Zoom: %w", scriptErr)
+	if got := ScriptFailed(err); got != scriptErr {
+		t.Errorf("ScriptFailed = %v, want the script error", got)
+	}
+	if err.Error() != "failed to install Zoom: command failed: exit code 30" {
+		t.Errorf("message = %q", err.Error())
+	}
+	if ScriptFailed(errors.New("plain")) != nil {
+		t.Error("ScriptFailed found a script in a plain error")
+	}
+	if Kind(err) != nil || !Retryable(err) || !HasLog(err) {
+		t.Error("a failed script should be retryable and have a log")
+	}
+}
+
+func TestRetryableAndHasLog(t *testing.T) {
+	tests := []struct {
+		err       error
+		retryable bool
+		hasLog    bool
+	}{
+		{New(ErrAppNotFound, "app 'x' does not exist"), false, false},
+		{New(ErrAlreadyInstalled, "installed"), false, false},
+		{New(ErrNotInstalled, "not installed"), false, false},
+		{New(ErrUnsupportedArch, "no install-32"), false, false},
+		{New(ErrCancelled, "cancelled"), false, false},
+		{New(ErrNoExec, "noexec"), false, false},
+		{New(ErrNeedsRoot, "no sudo"), true, false},
+		{New(ErrKioskRestricted, "uninstalling is disabled"), false, false},
+		{New(ErrNetwork, "timeout"), true, true},
+		{New(ErrAptLocked, "locked"), true, true},
+		{New(ErrUnhealthy, "exit code 1"), true, true},
+		{errors.New("something else"), true, true},
+	}
+	for _, tt := range tests {
+		if got := Retryable(tt.err); got != tt.retryable {
+			t.Errorf("Retryable(%v) = %v, want %v", tt.err, got, tt.retryable)
+		}
+		if got := HasLog(tt.err); got != tt.hasLog {
+			t.Errorf("HasLog(%v) = %v, want %v", tt.err, got, tt.hasLog)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: exit_codes.go
+// Description: Exit code convention for app scripts, so diagnosis doesn't have to guess the reason of a failure from the log.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package api
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+
+	"github.com/pi-apps-go/pi-apps/pkg/api/errs"
+)
+
+// Exit codes app scripts can use to tell Pi-Apps why they failed.
+// Scripts get them as environment variables, e.g. `exit $PI_APPS_EXIT_DOWNLOAD`:
+//
+//	PI_APPS_EXIT_DECLINED=10     the user declined a prompt, like a license agreement
+//	PI_APPS_EXIT_UNSUPPORTED=20  the app doesn't support this system
+//	PI_APPS_EXIT_DOWNLOAD=30     a download failed
+//	PI_APPS_EXIT_PACKAGE=40      installing or removing packages failed
+//
+// Any other non-zero exit code is diagnosed from the log only.
+const (
+	ExitUserDeclined      = 10
+	ExitUnsupportedSystem = 20
+	ExitDownloadFailed    = 30
+	ExitPackageFailed     = 40
+)
+
+// ScriptExitEnv returns the environment variables that export the exit code convention to app scripts
+func ScriptExitEnv() []string {
+	return []string{
+		"PI_APPS_EXIT_DECLINED=" + strconv.Itoa(ExitUserDeclined),
+		"PI_APPS_EXIT_UNSUPPORTED=" + strconv.Itoa(ExitUnsupportedSystem),
+		"PI_APPS_EXIT_DOWNLOAD=" + strconv.Itoa(ExitDownloadFailed),
+		"PI_APPS_EXIT_PACKAGE=" + strconv.Itoa(ExitPackageFailed),
+	}
+}
+
+// ScriptExitError is returned when an app script exits with a non-zero exit code
+type ScriptExitError = errs.ErrScriptFailed
+
+// ScriptExitCode returns the exit code of a failed app script, or 0 if the error didn't come from one
+func ScriptExitCode(err error) int {
+	if scriptErr := errs.ScriptFailed(err); scriptErr != nil {
+		return scriptErr.ExitCode
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	return 0
+}
+
+// FailureExitCode returns the exit code of a failed operation: the one of its app script, or the one RenderError
+// picks for the kind of error when no script failed
+func FailureExitCode(err error) int {
+	if code := ScriptExitCode(err); code != 0 {
+		return code
+	}
+	return RenderError(err).ExitCode
+}
+
+// exitCodeLogPattern matches the line recording the exit code of a script in its log file
+var exitCodeLogPattern = regexp.MustCompile(`(?m)^Script exit code: (\d+)$`)
+
+// logExitCode records the exit code of a failed script in its log file, so diagnosis in another process can read it
+func logExitCode(logFile *os.File, err error) {
+	if code := ScriptExitCode(err); code != 0 {
+		fmt.Fprintf(logFile, "Script exit code: %d\n", code)
+	}
+}
+
+// LogExitCode returns the script exit code recorded in a log, or 0 if none was recorded
+func LogExitCode(log string) int {
+	match := exitCodeLogPattern.FindStringSubmatch(log)
+	if match == nil {
+		return 0
+	}
+	code, _ := strconv.Atoi(match[1])
+	return code
+}
+
+// ExitCodeReason returns a translated explanation of a recognized script exit code, or "" for other codes
+func ExitCodeReason(code int) string {
+	switch code {
+	case ExitUserDeclined:
+		return T("You declined a prompt of the script, so it stopped.")
+	case ExitUnsupportedSystem:
+		return T("The script stopped because this app does not support your system.")
+	case ExitDownloadFailed:
+		return T("The script failed to download a file it needs.")
+	case ExitPackageFailed:
+		return T("The script failed to install or remove the packages it needs.")
+	}
+	return ""
+}
+
+// exitCodeErrorType returns the error type a recognized script exit code stands for
+func exitCodeErrorType(code int) string {
+	switch code {
+	case ExitUserDeclined:
+		return "user"
+	case ExitUnsupportedSystem:
+		return "system"
+	case ExitDownloadFailed:
+		return "internet"
+	case ExitPackageFailed:
+		return "package"
+	}
+	return ""
+}
+
+// applyExitCodeHint refines a log diagnosis with the exit code the script recorded in the log.
+//
+// A declined prompt always wins, since nothing failed. For the other codes the text-based
+// classification is kept when it found something, and the exit code only fills in an unknown type.
+func applyExitCodeHint(diagnosis *ErrorDiagnosis, log string) {
+	code := LogExitCode(log)
+	errorType := exitCodeErrorType(code)
+	if errorType == "" {
+		return
+	}
+
+	if code == ExitUserDeclined {
+		diagnosis.ErrorType = errorType
+		diagnosis.Captions = append([]string{ExitCodeReason(code)}, diagnosis.Captions...)
+		return
+	}
+	if diagnosis.ErrorType == "" || diagnosis.ErrorType == "unknown" {
+		diagnosis.ErrorType = errorType
+	}
+	if len(diagnosis.Captions) == 0 {
+		diagnosis.Captions = append(diagnosis.Captions, ExitCodeReason(code))
+	}
+}
@@ -0,0 +1,214 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: fetch_script.go
+// Description: Downloads shell scripts that app scripts run and checks them against a pinned sha256, instead of piping them into a shell.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package api
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pi-apps-go/pi-apps/pkg/api/errs"
+)
+
+// ScriptHashMismatchError is returned when a downloaded script is not the one an app script pinned.
+// The upstream script changed, so the app needs an update; nothing is wrong with the user's system.
+type ScriptHashMismatchError struct {
+	URL      string
+	Expected string
+	Actual   string
+}
+
+func (e *ScriptHashMismatchError) Error() string {
+	return fmt.Sprintf("the script at %s changed upstream: expected sha256 %s, got %s", e.URL, e.Expected, e.Actual)
+}
+
+// sha256Regex matches a sha256 in hexadecimal
+var sha256Regex = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// scriptChangedRegex matches the message of a ScriptHashMismatchError in a log
+var scriptChangedRegex = regexp.MustCompile(`the script at (\S+) changed upstream: expected sha256 [0-9a-f]{64}, got [0-9a-f]{64}`)
+
+// FetchScriptVerified downloads a script to a temporary file and returns its path once its sha256 matches
+// expectedSHA256, so app scripts run a known script instead of piping whatever a URL returns into a shell.
+// The download is recorded in the download ledger and the caller removes the file after running it.
+//
+//	string - path of the verified script, executable
+//	error - *ScriptHashMismatchError if the script is not the pinned one, an errs.ErrNetwork error if the download failed
+func FetchScriptVerified(url, expectedSHA256 string) (string, error) {
+	expected := strings.ToLower(strings.TrimSpace(expectedSHA256))
+	if !sha256Regex.MatchString(expected) {
+		return "", fmt.Errorf("invalid sha256 %q, expected 64 hexadecimal characters", expectedSHA256)
+	}
+
+	var body io.ReadCloser
+	if staged, ok := stagedAsset(url); ok {
+		// Installing from an app bundle, the script is in it
+		f, err := os.Open(staged)
+		if err != nil {
+			return "", err
+		}
+		body = f
+	} else {
+		resp, err := http.Get(url)
+		if err != nil {
+			return "", errs.New(errs.ErrNetwork, "failed to download %s: %w", url, err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return "", errs.New(errs.ErrNetwork, "failed to download %s: HTTP %d", url, resp.StatusCode)
+		}
+		body = resp.Body
+	}
+	defer body.Close()
+
+	file, err := os.CreateTemp("", "pi-apps-script-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	path := file.Name()
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(file, hash), body)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		return "", errs.New(errs.ErrNetwork, "failed to download %s: %w", url, err)
+	}
+
+	sum := hash.Sum(nil)
+	if actual := hex.EncodeToString(sum); actual != expected {
+		os.Remove(path)
+		return "", &ScriptHashMismatchError{URL: url, Expected: expected, Actual: actual}
+	}
+	if err := os.Chmod(path, 0755); err != nil {
+		os.Remove(path)
+		return "", fmt.Errorf("failed to make %s executable: %w", path, err)
+	}
+	recordDownload(url, path, size, sum)
+	return path, nil
+}
+
+// applyScriptChangedHint explains a log where a pinned script did not match, which is up to the app
+// maintainers to fix, instead of the errors of the script that wasn't run
+func applyScriptChangedHint(diagnosis *ErrorDiagnosis, log string) {
+	match := scriptChangedRegex.FindStringSubmatch(log)
+	if match == nil {
+		return
+	}
+	caption := Tf("The app downloads a script from %s, which changed since the app was last updated, so Pi-Apps refused to run it.", match[1]) +
+		"\n" + T("This is not a problem with your system. The app needs an update, try again in a few days after updating Pi-Apps, or report it so the maintainers know.")
+	diagnosis.Captions = append([]string{caption}, diagnosis.Captions...)
+}
+
+// UnverifiedScriptPipe is a line of an app script that runs a downloaded script without checking it first,
+// like curl | bash, and should use fetch_script instead
+type UnverifiedScriptPipe struct {
+	App    string `json:"app"`
+	Script string `json:"script"`
+	Line   int    `json:"line"`
+	Text   string `json:"text"`
+}
+
+// Downloads piped into a shell: curl -fsSL URL | sudo -E bash -
+var scriptPipeRegex = regexp.MustCompile(`\b(curl|wget)\b[^#;&]*[^|]\|\s*(sudo\s+(-\S+\s+)*)?(ba|z|da)?sh\b`)
+
+// Downloads run by a shell directly: bash <(curl URL), sh -c "$(wget -O- URL)"
+var scriptSubstitutionRegex = regexp.MustCompile(`\b(ba|z|da)?sh\s+(-\S+\s+)*["']?(<\(|\$\()\s*(curl|wget)\b`)
+
+// UnverifiedScriptPipes lists the lines of app scripts that pipe a downloaded script into a shell,
+// sorted by app, so maintainers can move them to fetch_script
+func UnverifiedScriptPipes() ([]UnverifiedScriptPipe, error) {
+	directory := GetPiAppsDir()
+	if directory == "" {
+		return nil, fmt.Errorf("PI_APPS_DIR environment variable not set")
+	}
+	apps, err := ListApps("local")
+	if err != nil {
+		return nil, fmt.Errorf("error listing apps: %w", err)
+	}
+	sort.Strings(apps)
+
+	var pipes []UnverifiedScriptPipe
+	for _, app := range apps {
+		for _, script := range []string{"install", "install-32", "install-64", "uninstall", "update"} {
+			found, err := unverifiedScriptPipes(filepath.Join(directory, "apps", app, script))
+			if err != nil {
+				return nil, err
+			}
+			for _, pipe := range found {
+				pipe.App, pipe.Script = app, script
+				pipes = append(pipes, pipe)
+			}
+		}
+	}
+	return pipes, nil
+}
+
+// unverifiedScriptPipes returns the lines of a script that pipe a download into a shell, numbered by the
+// first line of commands continued with a backslash. A missing script has none.
+func unverifiedScriptPipes(path string) ([]UnverifiedScriptPipe, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var pipes []UnverifiedScriptPipe
+	var command strings.Builder
+	start := 0
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if command.Len() == 0 {
+			if strings.HasPrefix(line, "#") {
+				continue
+			}
+			start = lineNumber
+		}
+		if continued, found := strings.CutSuffix(line, "\\"); found {
+			command.WriteString(continued + " ")
+			continue
+		}
+		command.WriteString(line)
+
+		text := command.String()
+		command.Reset()
+		if scriptPipeRegex.MatchString(text) || scriptSubstitutionRegex.MatchString(text) {
+			pipes = append(pipes, UnverifiedScriptPipe{Line: start, Text: text})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return pipes, nil
+}
@@ -0,0 +1,144 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pi-apps-go/pi-apps/pkg/api/errs"
+)
+
+func TestFetchScriptVerified(t *testing.T) {
+	newTestPiAppsDir(t)
+	tmp := t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+	script := "#!/bin/bash\necho installing\n"
+	sum := sha256.Sum256([]byte(script))
+	pinned := hex.EncodeToString(sum[:])
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/install.sh" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(script))
+	}))
+	defer server.Close()
+	url := server.URL + "/install.sh"
+
+	path, err := FetchScriptVerified(url, strings.ToUpper(pinned))
+	if err != nil {
+		t.Fatalf("FetchScriptVerified of the pinned script: %v", err)
+	}
+	if content, err := os.ReadFile(path); err != nil || string(content) != script {
+		t.Errorf("verified script = %q, %v, want %q", content, err, script)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm()&0100 == 0 {
+		t.Errorf("verified script is not executable: %v", err)
+	}
+	FlushDownloadLedger()
+	entries, err := DownloadLedger(DownloadLedgerFilter{})
+	if err != nil || len(entries) != 1 || entries[0].URL != url || entries[0].SHA256 != pinned {
+		t.Errorf("download ledger = %+v, %v, want the verified script", entries, err)
+	}
+	os.Remove(path)
+
+	changed := strings.Repeat("0", 64)
+	_, err = FetchScriptVerified(url, changed)
+	var mismatch *ScriptHashMismatchError
+	if !errors.As(err, &mismatch) || mismatch.Expected != changed || mismatch.Actual != pinned {
+		t.Fatalf("FetchScriptVerified of a changed script = %v, want a ScriptHashMismatchError", err)
+	}
+	if !strings.Contains(err.Error(), changed) || !strings.Contains(err.Error(), pinned) {
+		t.Errorf("mismatch error %q does not name both hashes", err)
+	}
+	if leftovers, _ := os.ReadDir(tmp); len(leftovers) != 0 {
+		t.Errorf("a rejected script was left in %s", tmp)
+	}
+
+	if _, err := FetchScriptVerified(server.URL+"/missing.sh", pinned); !errors.Is(err, errs.ErrNetwork) {
+		t.Errorf("FetchScriptVerified of a missing script = %v, want a network error", err)
+	}
+	if _, err := FetchScriptVerified(url, "abc"); err == nil {
+		t.Errorf("FetchScriptVerified accepted an invalid sha256")
+	}
+}
+
+func TestApplyScriptChangedHint(t *testing.T) {
+	err := &ScriptHashMismatchError{URL: "https://example.com/install.sh", Expected: strings.Repeat("a", 64), Actual: strings.Repeat("b", 64)}
+	diagnosis := &ErrorDiagnosis{Captions: []string{"other"}}
+	applyScriptChangedHint(diagnosis, "Installing Zoom...\nError: "+err.Error()+"\n")
+	if len(diagnosis.Captions) != 2 || !strings.Contains(diagnosis.Captions[0], "https://example.com/install.sh") {
+		t.Errorf("captions = %q, want the changed script first", diagnosis.Captions)
+	}
+
+	diagnosis = &ErrorDiagnosis{}
+	applyScriptChangedHint(diagnosis, "curl: (6) Could not resolve host\n")
+	if len(diagnosis.Captions) != 0 {
+		t.Errorf("captions of an unrelated log = %q", diagnosis.Captions)
+	}
+}
+
+func TestUnverifiedScriptPipes(t *testing.T) {
+	directory := newTestPiAppsDir(t, "Docker", "Node", "Clean")
+	writeTestFile(t, filepath.Join(directory, "apps", "Docker", "install"), `#!/bin/bash
+# curl -fsSL https://get.docker.com | sh
+curl -fsSL https://get.docker.com | sudo -E bash - || error "Failed to install Docker!"
+curl -fsSL https://download.docker.com/linux/debian/gpg | sudo tee /etc/apt/keyrings/docker.asc
+wget -qO- https://example.com/list || bash fallback.sh
+`)
+	writeTestFile(t, filepath.Join(directory, "apps", "Node", "install-64"), `#!/bin/bash
+bash <(curl -s https://raw.githubusercontent.com/nvm-sh/nvm/master/install.sh)
+sh -c "$(wget -qO- https://example.com/setup.sh)"
+wget -qO- \
+  https://deb.nodesource.com/setup_lts.x \
+  | sudo bash
+`)
+	writeTestFile(t, filepath.Join(directory, "apps", "Clean", "install"), `#!/bin/bash
+script="$(fetch_script https://example.com/install.sh 0123)" || error "Failed!"
+bash "$script"
+`)
+
+	pipes, err := UnverifiedScriptPipes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		app, script string
+		line        int
+	}{
+		{"Docker", "install", 3},
+		{"Node", "install-64", 2},
+		{"Node", "install-64", 3},
+		{"Node", "install-64", 4},
+	}
+	if len(pipes) != len(want) {
+		t.Fatalf("UnverifiedScriptPipes = %+v, want %d lines", pipes, len(want))
+	}
+	for i, w := range want {
+		if pipes[i].App != w.app || pipes[i].Script != w.script || pipes[i].Line != w.line {
+			t.Errorf("pipe %d = %+v, want %s %s:%d", i, pipes[i], w.app, w.script, w.line)
+		}
+	}
+}
@@ -18,6 +18,8 @@
 // Description: Provides functions for viewing files.
 // SPDX-License-Identifier: GPL-3.0-or-later
 
+//go:build !nogui
+
 package api
 
 import (
@@ -28,9 +30,11 @@ import (
 	"github.com/gotk3/gotk3/gdk"
 	"github.com/gotk3/gotk3/glib"
 	"github.com/gotk3/gotk3/gtk"
+	"github.com/gotk3/gotk3/pango"
 )
 
-// ViewFile displays any text file in a GTK3 window
+// ViewFile displays any text file in a GTK3 window, with line numbers and highlighting for scripts.
+// Binary files are shown as a hex dump of their start.
 // This replicates the functionality of the original bash script's view_file function
 func ViewFile(filePath string) error {
 
@@ -101,7 +105,34 @@ func ViewFile(filePath string) error {
 
 	headerLabel.SetMarkup(headerText)
 	headerLabel.SetHAlign(gtk.ALIGN_START)
-	vbox.PackStart(headerLabel, false, false, 0)
+	headerLabel.SetEllipsize(pango.ELLIPSIZE_MIDDLE)
+	headerBox, err := gtk.BoxNew(gtk.ORIENTATION_HORIZONTAL, 8)
+	if err != nil {
+		return fmt.Errorf("unable to create box: %v", err)
+	}
+	headerBox.PackStart(headerLabel, true, true, 0)
+
+	// The viewer never changes the file, say so next to its name
+	readOnlyLabel, err := gtk.LabelNew("")
+	if err != nil {
+		return fmt.Errorf("unable to create label: %v", err)
+	}
+	readOnlyLabel.SetMarkup("<small><span background='#deddda' foreground='#3d3846'> " + glib.MarkupEscapeText(T("Read-only")) + " </span></small>")
+	readOnlyLabel.SetTooltipText(T("Files can't be changed in this viewer"))
+	headerBox.PackEnd(readOnlyLabel, false, false, 0)
+	vbox.PackStart(headerBox, false, false, 0)
+
+	// Binary files like .deb packages or images are shown as a hex dump of their start instead of garbage
+	binary, _ := IsBinaryFile(filePath)
+	if binary {
+		noticeLabel, err := gtk.LabelNew(Tf("This looks like a binary file, not text. Only its first %d bytes are shown, as hexadecimal.", binaryPreviewSize))
+		if err != nil {
+			return fmt.Errorf("unable to create label: %v", err)
+		}
+		noticeLabel.SetHAlign(gtk.ALIGN_START)
+		noticeLabel.SetLineWrap(true)
+		vbox.PackStart(noticeLabel, false, false, 0)
+	}
 
 	// Create a scrolled window
 	scrolledWindow, err := gtk.ScrolledWindowNew(nil, nil)
@@ -110,7 +141,6 @@ func ViewFile(filePath string) error {
 	}
 	scrolledWindow.SetPolicy(gtk.POLICY_AUTOMATIC, gtk.POLICY_AUTOMATIC)
 	scrolledWindow.SetShadowType(gtk.SHADOW_IN)
-	vbox.PackStart(scrolledWindow, true, true, 0)
 
 	// Create a text view for displaying the log
 	textView, err := gtk.TextViewNew()
@@ -127,12 +157,43 @@ func ViewFile(filePath string) error {
 		return fmt.Errorf("unable to get text buffer: %v", err)
 	}
 
-	// Read the file
-	content, err := os.ReadFile(filePath)
+	// Search bar, line numbers, and for logs the error tools and the gutter next to the text
+	tools, err := newFileViewerTools(textView, buffer, filePath, isLogFile(filePath) && !binary)
+	if err != nil {
+		return fmt.Errorf("unable to create search bar: %v", err)
+	}
+	vbox.PackStart(tools.searchBar, false, false, 0)
+	if tools.logBar != nil {
+		vbox.PackStart(tools.logBar, false, false, 0)
+	}
+	textBox, err := gtk.BoxNew(gtk.ORIENTATION_HORIZONTAL, 2)
 	if err != nil {
+		return fmt.Errorf("unable to create box: %v", err)
+	}
+	if !binary {
+		textBox.PackStart(tools.lineNumbers, false, false, 0)
+	}
+	textBox.PackStart(scrolledWindow, true, true, 0)
+	if tools.gutter != nil {
+		textBox.PackStart(tools.gutter, false, false, 0)
+	}
+	vbox.PackStart(textBox, true, true, 0)
+	vbox.PackStart(tools.statusLabel, false, false, 0)
+	win.Connect("key-press-event", func(win *gtk.Window, event *gdk.Event) bool {
+		return tools.handleKey(event)
+	})
+	tools.watchScroll(scrolledWindow.GetVAdjustment())
+
+	// Read the file in chunks once the window is shown
+	if binary {
+		preview, err := binaryPreview(filePath)
+		if err != nil {
+			preview = fmt.Sprintf("Error reading file: %v", err)
+		}
+		textView.SetMonospace(true)
+		buffer.SetText(preview)
+	} else if err := tools.load(); err != nil {
 		buffer.SetText(fmt.Sprintf("Error reading file: %v", err))
-	} else {
-		buffer.SetText(string(content))
 	}
 
 	// Create a button box
@@ -0,0 +1,175 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: fileviewer_syntax.go
+// Description: A small tokenizer that highlights comments, strings and keywords of scripts and config files in the file viewer.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+//go:build !nogui
+
+package api
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// syntaxLanguage is a kind of file the file viewer highlights
+type syntaxLanguage int
+
+const (
+	syntaxNone    syntaxLanguage = iota
+	syntaxShell                  // .sh and the install, uninstall and update scripts of apps
+	syntaxPython                 // .py
+	syntaxDesktop                // .desktop, [Section] headers and Key=value lines
+	syntaxSources                // deb822 .sources, Key: value lines
+)
+
+// Tags the file viewer creates for the tokens
+const (
+	syntaxTagComment = "syntax-comment"
+	syntaxTagString  = "syntax-string"
+	syntaxTagKeyword = "syntax-keyword"
+	syntaxTagKey     = "syntax-key"
+)
+
+// shellKeywords and pythonKeywords are the words highlighted as keywords
+var (
+	shellKeywords = map[string]bool{
+		"if": true, "then": true, "else": true, "elif": true, "fi": true, "for": true, "while": true, "until": true,
+		"do": true, "done": true, "case": true, "esac": true, "in": true, "function": true, "return": true, "exit": true,
+		"local": true, "export": true, "source": true, "shift": true, "break": true, "continue": true,
+	}
+	pythonKeywords = map[string]bool{
+		"def": true, "class": true, "import": true, "from": true, "as": true, "return": true, "if": true, "elif": true,
+		"else": true, "for": true, "while": true, "in": true, "not": true, "and": true, "or": true, "is": true,
+		"try": true, "except": true, "finally": true, "with": true, "raise": true, "pass": true, "break": true,
+		"continue": true, "lambda": true, "yield": true, "None": true, "True": true, "False": true, "global": true,
+	}
+)
+
+// syntaxToken is a highlighted part of a line, start and end are byte indexes in the line
+type syntaxToken struct {
+	start, end int
+	tag        string
+}
+
+// detectSyntax returns the language of a file from its name, or from the shebang on its first line
+func detectSyntax(filePath, firstLine string) syntaxLanguage {
+	switch filepath.Ext(filePath) {
+	case ".sh", ".bash":
+		return syntaxShell
+	case ".py":
+		return syntaxPython
+	case ".desktop":
+		return syntaxDesktop
+	case ".sources":
+		return syntaxSources
+	}
+	switch filepath.Base(filePath) {
+	case "install", "install-32", "install-64", "uninstall", "update":
+		return syntaxShell
+	}
+	if shebang, ok := strings.CutPrefix(firstLine, "#!"); ok {
+		switch {
+		case strings.Contains(shebang, "python"):
+			return syntaxPython
+		case strings.Contains(shebang, "sh"):
+			return syntaxShell
+		}
+	}
+	return syntaxNone
+}
+
+// tokenizeLine returns the highlighted parts of a line. Every line is tokenized on its own, so strings
+// spanning several lines are only highlighted on their first line.
+func tokenizeLine(language syntaxLanguage, line string) []syntaxToken {
+	switch language {
+	case syntaxShell, syntaxPython:
+		return tokenizeScriptLine(language, line)
+	case syntaxDesktop:
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, ";"):
+			return []syntaxToken{{0, len(line), syntaxTagComment}}
+		case strings.HasPrefix(trimmed, "["):
+			return []syntaxToken{{0, len(line), syntaxTagKeyword}}
+		}
+		if key, _, found := strings.Cut(line, "="); found {
+			return []syntaxToken{{0, len(key), syntaxTagKey}}
+		}
+	case syntaxSources:
+		if strings.HasPrefix(strings.TrimSpace(line), "#") {
+			return []syntaxToken{{0, len(line), syntaxTagComment}}
+		}
+		// Indented lines continue the value of the previous field
+		if key, _, found := strings.Cut(line, ":"); found && key != "" && !strings.ContainsAny(key, " \t") {
+			return []syntaxToken{{0, len(key), syntaxTagKey}}
+		}
+	}
+	return nil
+}
+
+// tokenizeScriptLine finds the comments, quoted strings and keywords of a line of a shell or Python script
+func tokenizeScriptLine(language syntaxLanguage, line string) []syntaxToken {
+	keywords := shellKeywords
+	if language == syntaxPython {
+		keywords = pythonKeywords
+	}
+
+	var tokens []syntaxToken
+	for i := 0; i < len(line); {
+		c := line[i]
+		switch {
+		case c == '#' && (language == syntaxPython || i == 0 || strings.IndexByte(" \t;(|&", line[i-1]) >= 0):
+			return append(tokens, syntaxToken{i, len(line), syntaxTagComment})
+		case c == '\'' || c == '"':
+			end := i + 1
+			for end < len(line) && line[end] != c {
+				// Backslashes escape quotes, except in single quoted shell strings
+				if line[end] == '\\' && (c == '"' || language == syntaxPython) {
+					end++
+				}
+				end++
+			}
+			end = min(end+1, len(line))
+			tokens = append(tokens, syntaxToken{i, end, syntaxTagString})
+			i = end
+		case c == '_' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z'):
+			end := i + 1
+			for end < len(line) && (line[end] == '_' || ('a' <= line[end] && line[end] <= 'z') || ('A' <= line[end] && line[end] <= 'Z') || ('0' <= line[end] && line[end] <= '9')) {
+				end++
+			}
+			// $if or --if are not keywords, and neither are parts of paths, file names or options
+			previous, next := byte(' '), byte(' ')
+			if i > 0 {
+				previous = line[i-1]
+			}
+			if end < len(line) {
+				next = line[end]
+			}
+			if keywords[line[i:end]] && strings.IndexByte("$-./=", previous) < 0 && strings.IndexByte("-./=", next) < 0 {
+				tokens = append(tokens, syntaxToken{i, end, syntaxTagKeyword})
+			}
+			i = end
+		case c == '\\':
+			i += 2
+		default:
+			i++
+		}
+	}
+	return tokens
+}
@@ -0,0 +1,593 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: fileviewer_tools.go
+// Description: Adds search, error highlighting, noise filtering, syntax highlighting and line numbers to the GTK file viewer,
+// and loads large files in chunks.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+//go:build !nogui
+
+package api
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/gotk3/gotk3/cairo"
+	"github.com/gotk3/gotk3/gdk"
+	"github.com/gotk3/gotk3/glib"
+	"github.com/gotk3/gotk3/gtk"
+	"github.com/gotk3/gotk3/pango"
+)
+
+const (
+	// fileViewerChunkLines is the number of lines added to the text view per idle callback while a file loads
+	fileViewerChunkLines = 2000
+	// fileViewerMaxHighlights limits the matches highlighted at once, the count still covers all of them
+	fileViewerMaxHighlights = 5000
+	// fileViewerLazySize is the file size above which only part of the file is loaded, more is loaded while scrolling down
+	fileViewerLazySize = 16 << 20
+	// fileViewerLazyLines is the number of lines of a large file loaded at first and every time the end is reached
+	fileViewerLazyLines = 10 * fileViewerChunkLines
+)
+
+// fileViewerTools holds the widgets and state of the search bar and the log tools of a file viewer window
+type fileViewerTools struct {
+	textView *gtk.TextView
+	buffer   *gtk.TextBuffer
+	filePath string
+	isLog    bool
+
+	searchBar    *gtk.Box
+	searchEntry  *gtk.SearchEntry
+	countLabel   *gtk.Label
+	logBar       *gtk.Box
+	errorButton  *gtk.Button
+	filterToggle *gtk.ToggleButton
+	gutter       *gtk.DrawingArea
+	lineNumbers  *gtk.DrawingArea
+	statusLabel  *gtk.Label
+
+	syntax      syntaxLanguage
+	reader      *bufio.Reader
+	file        *os.File
+	loadedLines int   // lines in the buffer so far
+	loadLimit   int   // lines to load before waiting for the user to scroll down, 0 to load the whole file
+	loading     bool  // an idle callback is loading chunks
+	errorLines  []int // buffer lines classified as errors, in order
+	loaded      bool  // the whole file is in the buffer
+	query       string
+	matchEnd    int    // offset after the current match, where the next search starts
+	countSearch int    // increased for every query, so counts of old queries are dropped
+	popupPath   string // path under the pointer when the context menu was opened
+}
+
+// newFileViewerTools creates the search bar, and for logs the error tools and the gutter with error marks
+func newFileViewerTools(textView *gtk.TextView, buffer *gtk.TextBuffer, filePath string, isLog bool) (*fileViewerTools, error) {
+	t := &fileViewerTools{textView: textView, buffer: buffer, filePath: filePath, isLog: isLog}
+
+	buffer.CreateTag("error", map[string]interface{}{"foreground": "#c01c28", "weight": 700})
+	buffer.CreateTag("warning", map[string]interface{}{"foreground": "#9c6d00"})
+	buffer.CreateTag("noise", map[string]interface{}{"invisible": false})
+	buffer.CreateTag("match", map[string]interface{}{"background": "#f8e45c"})
+	buffer.CreateTag(syntaxTagComment, map[string]interface{}{"foreground": "#6a7f86"})
+	buffer.CreateTag(syntaxTagString, map[string]interface{}{"foreground": "#26a269"})
+	buffer.CreateTag(syntaxTagKeyword, map[string]interface{}{"foreground": "#1c71d8", "weight": 700})
+	buffer.CreateTag(syntaxTagKey, map[string]interface{}{"foreground": "#813d9c"})
+
+	var err error
+	if t.searchBar, err = gtk.BoxNew(gtk.ORIENTATION_HORIZONTAL, 4); err != nil {
+		return nil, err
+	}
+	if t.searchEntry, err = gtk.SearchEntryNew(); err != nil {
+		return nil, err
+	}
+	t.searchEntry.SetPlaceholderText(T("Search"))
+	t.searchEntry.SetHExpand(true)
+	previousButton, err := gtk.ButtonNewFromIconName("go-up-symbolic", gtk.ICON_SIZE_BUTTON)
+	if err != nil {
+		return nil, err
+	}
+	previousButton.SetTooltipText(T("Previous match (Shift+F3)"))
+	nextButton, err := gtk.ButtonNewFromIconName("go-down-symbolic", gtk.ICON_SIZE_BUTTON)
+	if err != nil {
+		return nil, err
+	}
+	nextButton.SetTooltipText(T("Next match (F3)"))
+	if t.countLabel, err = gtk.LabelNew(""); err != nil {
+		return nil, err
+	}
+	t.searchBar.PackStart(t.searchEntry, true, true, 0)
+	t.searchBar.PackStart(previousButton, false, false, 0)
+	t.searchBar.PackStart(nextButton, false, false, 0)
+	t.searchBar.PackStart(t.countLabel, false, false, 4)
+	// Ctrl+F shows the search bar
+	t.searchBar.SetNoShowAll(true)
+
+	t.searchEntry.Connect("search-changed", func() {
+		text, _ := t.searchEntry.GetText()
+		t.setQuery(text)
+	})
+	t.searchEntry.Connect("activate", func() { t.findNext(true) })
+	t.searchEntry.Connect("stop-search", func() { t.hideSearch() })
+	previousButton.Connect("clicked", func() { t.findNext(false) })
+	nextButton.Connect("clicked", func() { t.findNext(true) })
+
+	// Line numbers left of the text, drawn for the visible lines only
+	if t.lineNumbers, err = gtk.DrawingAreaNew(); err != nil {
+		return nil, err
+	}
+	t.lineNumbers.Connect("draw", func(area *gtk.DrawingArea, cr *cairo.Context) bool {
+		t.drawLineNumbers(area, cr)
+		return false
+	})
+	buffer.Connect("changed", func() { t.lineNumbers.QueueDraw() })
+	if t.statusLabel, err = gtk.LabelNew(""); err != nil {
+		return nil, err
+	}
+	t.statusLabel.SetHAlign(gtk.ALIGN_START)
+
+	if !isLog {
+		return t, nil
+	}
+
+	if t.logBar, err = gtk.BoxNew(gtk.ORIENTATION_HORIZONTAL, 4); err != nil {
+		return nil, err
+	}
+	if t.errorButton, err = gtk.ButtonNewWithLabel(T("Jump to first error")); err != nil {
+		return nil, err
+	}
+	t.errorButton.SetTooltipText(T("Scroll to the first line that looks like an error, error lines are marked red next to the scrollbar"))
+	t.errorButton.SetSensitive(false)
+	t.errorButton.Connect("clicked", func() {
+		if len(t.errorLines) > 0 {
+			t.scrollToLine(t.errorLines[0])
+		}
+	})
+	if t.filterToggle, err = gtk.ToggleButtonNewWithLabel(T("Hide progress lines")); err != nil {
+		return nil, err
+	}
+	t.filterToggle.SetTooltipText(T("Hide package manager chatter and progress output"))
+	t.filterToggle.Connect("toggled", func() {
+		if table, err := t.buffer.GetTagTable(); err == nil {
+			if tag, err := table.Lookup("noise"); err == nil {
+				tag.SetProperty("invisible", t.filterToggle.GetActive())
+			}
+		}
+		t.gutter.QueueDraw()
+		t.setQuery(t.query)
+	})
+	t.logBar.PackStart(t.errorButton, false, false, 0)
+	t.logBar.PackStart(t.filterToggle, false, false, 0)
+
+	// The gutter shows where the error lines are in the whole log, a click scrolls there
+	if t.gutter, err = gtk.DrawingAreaNew(); err != nil {
+		return nil, err
+	}
+	t.gutter.SetSizeRequest(10, -1)
+	t.gutter.SetTooltipText(T("Error lines"))
+	t.gutter.AddEvents(int(gdk.BUTTON_PRESS_MASK))
+	t.gutter.Connect("draw", func(area *gtk.DrawingArea, cr *cairo.Context) bool {
+		lines := t.buffer.GetLineCount()
+		height := float64(area.GetAllocatedHeight())
+		if lines == 0 || height == 0 {
+			return false
+		}
+		cr.SetSourceRGB(0.75, 0.11, 0.16)
+		for _, line := range t.errorLines {
+			cr.Rectangle(0, float64(line)/float64(lines)*height, float64(area.GetAllocatedWidth()), 2)
+		}
+		cr.Fill()
+		return false
+	})
+	t.gutter.Connect("button-press-event", func(area *gtk.DrawingArea, event *gdk.Event) bool {
+		height := float64(area.GetAllocatedHeight())
+		if height == 0 {
+			return false
+		}
+		line := int(gdk.EventButtonNewFromEvent(event).Y() / height * float64(t.buffer.GetLineCount()))
+		t.scrollToLine(t.nearestErrorLine(line))
+		return true
+	})
+
+	// Right-clicking a path in the log offers to find the app that installed it
+	textView.Connect("button-press-event", func(tv *gtk.TextView, event *gdk.Event) bool {
+		button := gdk.EventButtonNewFromEvent(event)
+		if button.Button() == gdk.BUTTON_SECONDARY {
+			x, y := tv.WindowToBufferCoords(gtk.TEXT_WINDOW_TEXT, int(button.X()), int(button.Y()))
+			t.popupPath = t.pathAt(tv.GetIterAtLocation(x, y))
+		}
+		return false
+	})
+	textView.Connect("populate-popup", func(tv *gtk.TextView, popup interface{}) {
+		menu, ok := popup.(*gtk.Menu)
+		path := t.popupPath
+		if start, end, selected := t.buffer.GetSelectionBounds(); selected {
+			if text, err := t.buffer.GetText(start, end, false); err == nil && PathAtOffset(text, 0) != "" {
+				path = PathAtOffset(text, 0)
+			}
+		}
+		if !ok || path == "" {
+			return
+		}
+		separator, err := gtk.SeparatorMenuItemNew()
+		if err != nil {
+			return
+		}
+		item, err := gtk.MenuItemNewWithLabel(T("Find owning app"))
+		if err != nil {
+			return
+		}
+		item.SetTooltipText(path)
+		item.Connect("activate", func() { showPathOwner(path) })
+		menu.Append(separator)
+		menu.Append(item)
+		separator.Show()
+		item.Show()
+	})
+	return t, nil
+}
+
+// pathAt returns the path in the log at an iter, "" if there is none
+func (t *fileViewerTools) pathAt(iter *gtk.TextIter) string {
+	line := iter.GetLine()
+	start := t.buffer.GetIterAtLine(line)
+	end := t.buffer.GetIterAtLine(line)
+	end.ForwardToLineEnd()
+	text, err := t.buffer.GetText(start, end, true)
+	if err != nil {
+		return ""
+	}
+	return PathAtOffset(text, iter.GetLineOffset())
+}
+
+// showPathOwner tells which app installed a path
+func showPathOwner(path string) {
+	message := ""
+	app, source, err := WhichAppOwnsPath(path)
+	switch {
+	case errors.Is(err, ErrNoOwningApp):
+		message = Tf("No app installed %s.", path)
+	case err != nil:
+		showErrorDialog(Tf("Failed to find the app that installed %s: %v", path, err))
+		return
+	default:
+		message = Tf("%s was installed by %s, according to the %s.", path, app, source)
+	}
+	dialog := gtk.MessageDialogNew(nil, gtk.DIALOG_MODAL, gtk.MESSAGE_INFO, gtk.BUTTONS_OK, "%s", message)
+	defer dialog.Destroy()
+	dialog.SetTitle(T("Find owning app"))
+	dialog.Run()
+}
+
+// handleKey handles Ctrl+F, F3, Shift+F3 and Escape for the window of the viewer
+func (t *fileViewerTools) handleKey(event *gdk.Event) bool {
+	key := gdk.EventKeyNewFromEvent(event)
+	switch {
+	case key.KeyVal() == gdk.KEY_f && key.State()&uint(gdk.CONTROL_MASK) != 0:
+		t.searchBar.SetNoShowAll(false)
+		t.searchBar.ShowAll()
+		t.searchEntry.GrabFocus()
+		return true
+	case key.KeyVal() == gdk.KEY_F3:
+		t.findNext(key.State()&uint(gdk.SHIFT_MASK) == 0)
+		return true
+	case key.KeyVal() == gdk.KEY_Escape && t.searchBar.GetVisible():
+		t.hideSearch()
+		return true
+	}
+	return false
+}
+
+// hideSearch hides the search bar and removes the match highlights
+func (t *fileViewerTools) hideSearch() {
+	t.searchEntry.SetText("")
+	t.searchBar.Hide()
+	t.textView.GrabFocus()
+}
+
+// load reads the file into the buffer in chunks from idle callbacks, so the window shows right away even for
+// large logs. Log lines are classified and script lines highlighted as they are added. Files larger than
+// fileViewerLazySize are loaded fileViewerLazyLines at a time, scrolling to the end loads more.
+func (t *fileViewerTools) load() error {
+	file, err := os.Open(t.filePath)
+	if err != nil {
+		return err
+	}
+	t.file = file
+	t.reader = bufio.NewReader(file)
+
+	if !t.isLog {
+		firstLine, _ := t.reader.Peek(256)
+		line, _, _ := strings.Cut(string(firstLine), "\n")
+		t.syntax = detectSyntax(t.filePath, line)
+		if t.syntax != syntaxNone {
+			t.textView.SetMonospace(true)
+		}
+	}
+	if info, err := file.Stat(); err == nil && info.Size() > fileViewerLazySize {
+		t.loadLimit = fileViewerLazyLines
+	}
+
+	t.resumeLoading()
+	return nil
+}
+
+// resumeLoading loads chunks until the load limit or the end of the file is reached
+func (t *fileViewerTools) resumeLoading() {
+	if t.loading || t.loaded {
+		return
+	}
+	t.loading = true
+	glib.IdleAdd(func() bool {
+		if t.loadChunk() && (t.loadLimit == 0 || t.loadedLines < t.loadLimit) {
+			return true
+		}
+		t.loading = false
+		if !t.loaded {
+			t.statusLabel.SetText(Tf("Showing the first %d lines of this large file, scroll down to load more.", t.loadedLines))
+			if t.query != "" {
+				t.highlightMatches()
+			}
+		}
+		return false
+	})
+}
+
+// watchScroll loads more of a large file when the text view is scrolled near the end of what is loaded
+func (t *fileViewerTools) watchScroll(adjustment *gtk.Adjustment) {
+	adjustment.Connect("value-changed", func() {
+		t.lineNumbers.QueueDraw()
+		if t.loaded || t.loading || t.loadLimit == 0 {
+			return
+		}
+		if adjustment.GetValue()+2*adjustment.GetPageSize() >= adjustment.GetUpper() {
+			t.loadLimit = t.loadedLines + fileViewerLazyLines
+			t.resumeLoading()
+		}
+	})
+}
+
+// loadChunk adds the next fileViewerChunkLines lines of the file to the buffer
+// Returns false once the end of the file is reached.
+func (t *fileViewerTools) loadChunk() bool {
+	var chunk strings.Builder
+	var lines []string
+	var readErr error
+	for len(lines) < fileViewerChunkLines {
+		line, err := t.reader.ReadString('\n')
+		if line != "" {
+			chunk.WriteString(line)
+			lines = append(lines, strings.TrimRight(line, "\n"))
+		}
+		if err != nil {
+			readErr = err
+			break
+		}
+	}
+
+	firstLine := t.buffer.GetLineCount() - 1
+	t.buffer.Insert(t.buffer.GetEndIter(), chunk.String())
+	t.loadedLines += len(lines)
+	for i, line := range lines {
+		if t.syntax != syntaxNone {
+			for _, token := range tokenizeLine(t.syntax, line) {
+				t.buffer.ApplyTagByName(token.tag, t.buffer.GetIterAtLineIndex(firstLine+i, token.start), t.buffer.GetIterAtLineIndex(firstLine+i, token.end))
+			}
+		}
+		if !t.isLog {
+			continue
+		}
+		var tag string
+		switch ClassifyLogLine(line) {
+		case LogLineError:
+			tag = "error"
+			t.errorLines = append(t.errorLines, firstLine+i)
+		case LogLineWarning:
+			tag = "warning"
+		case LogLineNoise:
+			tag = "noise"
+		default:
+			continue
+		}
+		t.buffer.ApplyTagByName(tag, t.buffer.GetIterAtLine(firstLine+i), t.buffer.GetIterAtLine(firstLine+i+1))
+	}
+	if t.errorButton != nil && len(t.errorLines) > 0 {
+		t.errorButton.SetSensitive(true)
+	}
+	if t.gutter != nil {
+		t.gutter.QueueDraw()
+	}
+	if readErr == nil {
+		return true
+	}
+	t.file.Close()
+	if readErr != io.EOF {
+		t.buffer.Insert(t.buffer.GetEndIter(), "\n"+Tf("Error reading file: %v", readErr))
+	}
+	t.loaded = true
+	t.statusLabel.SetText("")
+	// Matches in the part loaded after the query was typed get highlighted too
+	if t.query != "" {
+		t.highlightMatches()
+	}
+	if t.errorButton != nil && len(t.errorLines) == 0 {
+		t.errorButton.SetTooltipText(T("No error lines were found in this log"))
+	}
+	return false
+}
+
+// drawLineNumbers draws the numbers of the lines visible in the text view, right aligned
+func (t *fileViewerTools) drawLineNumbers(area *gtk.DrawingArea, cr *cairo.Context) {
+	layout := pango.CairoCreateLayout(cr)
+	layout.SetFontDescription(pango.FontDescriptionFromString("Monospace 9"))
+
+	// Wide enough for the largest line number
+	layout.SetText(strconv.Itoa(max(t.buffer.GetLineCount(), 10)), -1)
+	width, _ := layout.GetSize()
+	if request := width/pango.PANGO_SCALE + 8; area.GetAllocatedWidth() != request {
+		area.SetSizeRequest(request, -1)
+	}
+
+	cr.SetSourceRGB(0.55, 0.55, 0.55)
+	visible := t.textView.GetVisibleRect()
+	iter, _ := t.textView.GetLineAtY(visible.GetY())
+	for {
+		y, _ := t.textView.GetLineYrange(iter)
+		if y > visible.GetY()+visible.GetHeight() {
+			break
+		}
+		_, windowY := t.textView.BufferToWindowCoords(gtk.TEXT_WINDOW_WIDGET, 0, y)
+		layout.SetText(strconv.Itoa(iter.GetLine()+1), -1)
+		lineWidth, _ := layout.GetSize()
+		cr.MoveTo(float64(area.GetAllocatedWidth()-lineWidth/pango.PANGO_SCALE-4), float64(windowY))
+		pango.CairoShowLayout(cr, layout)
+		if !iter.ForwardLine() {
+			break
+		}
+	}
+}
+
+// searchFlags returns the flags of the text search, hidden noise lines are skipped
+func (t *fileViewerTools) searchFlags() gtk.TextSearchFlags {
+	flags := gtk.TEXT_SEARCH_CASE_INSENSITIVE
+	if t.filterToggle != nil && t.filterToggle.GetActive() {
+		flags |= gtk.TEXT_SEARCH_VISIBLE_ONLY
+	}
+	return flags
+}
+
+// setQuery highlights the matches of a new query, jumps to the first one and counts them in the background
+func (t *fileViewerTools) setQuery(query string) {
+	t.query = query
+	t.matchEnd = 0
+	t.countSearch++
+	t.highlightMatches()
+	if query == "" {
+		t.countLabel.SetText("")
+		return
+	}
+	t.findNext(true)
+	t.countMatches(query, t.countSearch)
+}
+
+// highlightMatches marks the matches of the query in the loaded part of the file
+func (t *fileViewerTools) highlightMatches() {
+	t.buffer.RemoveTagByName("match", t.buffer.GetStartIter(), t.buffer.GetEndIter())
+	if t.query == "" {
+		return
+	}
+	iter := t.buffer.GetStartIter()
+	for range fileViewerMaxHighlights {
+		start, end, ok := iter.ForwardSearch(t.query, t.searchFlags(), nil)
+		if !ok {
+			break
+		}
+		t.buffer.ApplyTagByName("match", start, end)
+		iter = end
+	}
+}
+
+// countMatches counts the matches of the query in the whole file on a separate goroutine, streaming through
+// the file so the count doesn't wait for the text view to load it
+func (t *fileViewerTools) countMatches(query string, search int) {
+	hideNoise := t.filterToggle != nil && t.filterToggle.GetActive()
+	isLog := t.isLog
+	go func() {
+		file, err := os.Open(t.filePath)
+		if err != nil {
+			return
+		}
+		defer file.Close()
+		needle := strings.ToLower(query)
+		count := 0
+		scanner := bufio.NewScanner(file)
+		scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+		for scanner.Scan() {
+			line := scanner.Text()
+			if hideNoise && isLog && ClassifyLogLine(line) == LogLineNoise {
+				continue
+			}
+			count += strings.Count(strings.ToLower(line), needle)
+		}
+		glib.IdleAdd(func() bool {
+			if search != t.countSearch {
+				return false
+			}
+			if count == 0 {
+				t.countLabel.SetText(T("No matches"))
+			} else {
+				t.countLabel.SetText(Tf("%d matches", count))
+			}
+			return false
+		})
+	}()
+}
+
+// findNext selects the next or previous match of the query, wrapping around at the end of the file
+func (t *fileViewerTools) findNext(forward bool) {
+	if t.query == "" {
+		return
+	}
+	var start, end *gtk.TextIter
+	var ok bool
+	if forward {
+		start, end, ok = t.buffer.GetIterAtOffset(t.matchEnd).ForwardSearch(t.query, t.searchFlags(), nil)
+		if !ok {
+			start, end, ok = t.buffer.GetStartIter().ForwardSearch(t.query, t.searchFlags(), nil)
+		}
+	} else {
+		from := t.buffer.GetIterAtOffset(max(t.matchEnd-len([]rune(t.query)), 0))
+		start, end, ok = from.BackwardSearch(t.query, t.searchFlags(), nil)
+		if !ok {
+			start, end, ok = t.buffer.GetEndIter().BackwardSearch(t.query, t.searchFlags(), nil)
+		}
+	}
+	if !ok {
+		return
+	}
+	t.matchEnd = end.GetOffset()
+	t.buffer.SelectRange(start, end)
+	t.textView.ScrollToMark(t.buffer.GetInsert(), 0.1, true, 0, 0.3)
+}
+
+// nearestErrorLine returns the error line closest to a line, or the line itself if there are no error lines
+func (t *fileViewerTools) nearestErrorLine(line int) int {
+	nearest := line
+	distance := -1
+	for _, errorLine := range t.errorLines {
+		d := errorLine - line
+		if d < 0 {
+			d = -d
+		}
+		if distance == -1 || d < distance {
+			nearest, distance = errorLine, d
+		}
+	}
+	return nearest
+}
+
+// scrollToLine puts the cursor on a line and scrolls it into view
+func (t *fileViewerTools) scrollToLine(line int) {
+	iter := t.buffer.GetIterAtLine(line)
+	t.buffer.PlaceCursor(iter)
+	t.textView.ScrollToMark(t.buffer.GetInsert(), 0.1, true, 0, 0.3)
+}
@@ -0,0 +1,362 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: github_asset.go
+// Description: Picks the asset of a GitHub release that fits the architecture of this system, for install scripts that download release assets.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// githubAPIURL is the base URL of the GitHub API, a variable so tests can use a local server
+var githubAPIURL = "https://api.github.com"
+
+// githubAssetArch returns the architecture assets are picked for, a variable so tests can pick for another one
+var githubAssetArch = userlandArch
+
+// GitHubRelease is the part of a GitHub release that asset selection needs
+type GitHubRelease struct {
+	TagName string        `json:"tag_name"`
+	Assets  []GitHubAsset `json:"assets"`
+}
+
+// GitHubAsset is a file attached to a GitHub release
+type GitHubAsset struct {
+	Name               string `json:"name"`
+	BrowserDownloadURL string `json:"browser_download_url"`
+	Size               int64  `json:"size"`
+}
+
+// githubReleaseCache is a release kept in the cache, with the ETag to revalidate it
+type githubReleaseCache struct {
+	ETag      string        `json:"etag"`
+	Release   GitHubRelease `json:"release"`
+	FetchedAt time.Time     `json:"fetched_at"`
+}
+
+// archToken is a way an architecture is written in asset names. Assets with a stronger token win,
+// like armv7 builds over armv6 ones on armhf.
+type archToken struct {
+	pattern  *regexp.Regexp
+	strength int
+}
+
+// archTokenPattern matches a token between separators, "_" counts as one so "app_arm64.deb" has "arm64"
+func archTokenPattern(token string) *regexp.Regexp {
+	return regexp.MustCompile(`(?i)(^|[^a-z0-9])(` + token + `)([^a-z0-9]|$)`)
+}
+
+// archTokens lists the tokens of each architecture in Debian naming. They are checked in the order of
+// archTokenOrder, so "x86_64" is amd64 before "x86" can make it i386 and "arm64" is taken before "arm".
+var archTokens = map[string][]archToken{
+	"arm64": {
+		{archTokenPattern(`aarch64|arm64(v8)?|armv8[a-z]*`), 2},
+	},
+	"amd64": {
+		{archTokenPattern(`x86[_-]64|amd64|x64`), 2},
+		{archTokenPattern(`linux64|64bit|64-bit`), 1},
+	},
+	"armhf": {
+		{archTokenPattern(`armhf|armv7[a-z]*|arm32(v7)?`), 2},
+		{archTokenPattern(`armv6[a-z]*|armel|arm`), 1},
+	},
+	"i386": {
+		{archTokenPattern(`i[3-6]86|x86|ia32|386`), 2},
+		{archTokenPattern(`linux32|32bit|32-bit`), 1},
+	},
+}
+
+// archTokenOrder is the order archTokens are checked in
+var archTokenOrder = []string{"arm64", "amd64", "armhf", "i386"}
+
+// archAliases maps the other names of architectures that patterns may use to Debian naming
+var archAliases = map[string]string{
+	"aarch64": "arm64",
+	"x86_64":  "amd64",
+	"armv7l":  "armhf",
+	"armv7":   "armhf",
+	"arm":     "armhf",
+	"i686":    "i386",
+	"x86":     "i386",
+}
+
+// foreignAssetPattern matches assets for other operating systems, and checksums and signatures
+var foreignAssetPattern = regexp.MustCompile(`(?i)((^|[^a-z0-9])(windows|win32|win64|darwin|macos|osx|mac|freebsd|openbsd|netbsd|android|ios)([^a-z0-9]|$)|` +
+	`\.(exe|msi|dmg|pkg|apk|sha\d*|sha\d+sum|md5|asc|sig|minisig|sbom|spdx|json|txt|yml|yaml)$|` +
+	`(checksums?|sha\d+sums?)([^a-z0-9]|$))`)
+
+// assetArch returns the architecture an asset name is for, "" if it doesn't name one, and how strongly it names it
+func assetArch(name string) (string, int) {
+	for _, arch := range archTokenOrder {
+		for _, token := range archTokens[arch] {
+			if token.pattern.MatchString(name) {
+				return arch, token.strength
+			}
+		}
+	}
+	return "", 0
+}
+
+// normalizeAssetArch returns the Debian name of an architecture name, like arm64 for aarch64
+func normalizeAssetArch(arch string) string {
+	arch = strings.ToLower(strings.TrimSpace(arch))
+	if alias, ok := archAliases[arch]; ok {
+		return alias
+	}
+	return arch
+}
+
+// matchAssetPattern reports whether an asset name matches a shell glob, without regard to case
+func matchAssetPattern(pattern, name string) (bool, error) {
+	matched, err := filepath.Match(strings.ToLower(pattern), strings.ToLower(name))
+	if err != nil {
+		return false, fmt.Errorf("invalid asset pattern %q: %w", pattern, err)
+	}
+	return matched, nil
+}
+
+// selectReleaseAsset picks the asset of a release for an architecture.
+//
+// patterns maps architectures to shell globs of asset names. The glob of the architecture picks the asset on its
+// own; the glob of "*" narrows down the assets the built-in heuristics find, like "*.deb" when a release has both
+// packages and archives. Without a glob, the asset naming the architecture most clearly is picked, preferring
+// assets for Linux. Apps that only name their arm builds, like Electron apps, get the asset naming none on amd64.
+func selectReleaseAsset(release GitHubRelease, arch string, patterns map[string]string) (GitHubAsset, error) {
+	normalized := make(map[string]string, len(patterns))
+	for key, pattern := range patterns {
+		normalized[normalizeAssetArch(key)] = pattern
+	}
+
+	if pattern, ok := normalized[arch]; ok {
+		for _, asset := range release.Assets {
+			matched, err := matchAssetPattern(pattern, asset.Name)
+			if err != nil {
+				return GitHubAsset{}, err
+			}
+			if matched {
+				return asset, nil
+			}
+		}
+		return GitHubAsset{}, noMatchingAssetError(release, arch, fmt.Sprintf("matching %q", pattern))
+	}
+
+	// Narrow the assets down to the ones for Linux that match the glob for every architecture
+	var candidates []GitHubAsset
+	for _, asset := range release.Assets {
+		if foreignAssetPattern.MatchString(asset.Name) {
+			continue
+		}
+		if pattern, ok := normalized["*"]; ok {
+			matched, err := matchAssetPattern(pattern, asset.Name)
+			if err != nil {
+				return GitHubAsset{}, err
+			}
+			if !matched {
+				continue
+			}
+		}
+		candidates = append(candidates, asset)
+	}
+
+	// Keep the assets naming this architecture most strongly
+	var best []GitHubAsset
+	bestStrength := 0
+	var unnamed []GitHubAsset
+	for _, asset := range candidates {
+		assetArchName, strength := assetArch(asset.Name)
+		if assetArchName == "" {
+			unnamed = append(unnamed, asset)
+			continue
+		}
+		if assetArchName != arch || strength < bestStrength {
+			continue
+		}
+		if strength > bestStrength {
+			best, bestStrength = nil, strength
+		}
+		best = append(best, asset)
+	}
+	if len(best) == 0 && arch == "amd64" {
+		best = unnamed
+	}
+
+	// Assets that say they are for Linux win over the others, like a .tar.gz for Linux over one for any Unix
+	if len(best) > 1 {
+		var linux []GitHubAsset
+		for _, asset := range best {
+			if strings.Contains(strings.ToLower(asset.Name), "linux") {
+				linux = append(linux, asset)
+			}
+		}
+		if len(linux) > 0 {
+			best = linux
+		}
+	}
+
+	switch len(best) {
+	case 0:
+		return GitHubAsset{}, noMatchingAssetError(release, arch, "")
+	case 1:
+		return best[0], nil
+	}
+	var names []string
+	for _, asset := range best {
+		names = append(names, asset.Name)
+	}
+	return GitHubAsset{}, fmt.Errorf("release %s has several assets for %s: %s. Pick one with a pattern, like --pattern '*=*.deb'",
+		release.TagName, arch, strings.Join(names, ", "))
+}
+
+// noMatchingAssetError lists the assets of a release that has none for this architecture
+func noMatchingAssetError(release GitHubRelease, arch, condition string) error {
+	var names []string
+	for _, asset := range release.Assets {
+		names = append(names, asset.Name)
+	}
+	if condition != "" {
+		condition = " " + condition
+	}
+	if len(names) == 0 {
+		return fmt.Errorf("release %s has no assets", release.TagName)
+	}
+	return fmt.Errorf("release %s has no asset for %s%s, it has: %s", release.TagName, arch, condition, strings.Join(names, ", "))
+}
+
+// githubReleaseCachePath returns where a release of a repository is cached
+func githubReleaseCachePath(repo, tag string) string {
+	name := strings.NewReplacer("/", "_", "\\", "_").Replace(repo + "@" + tag)
+	return filepath.Join(GetDataDir(), "cache", "github-releases", name+".json")
+}
+
+// fetchGitHubRelease fetches a release of a GitHub repository, "latest" for the latest one. Releases are cached
+// and revalidated with their ETag, which doesn't count against GitHub's rate limit. The cache is used as it is
+// when GitHub can't be reached or its rate limit is reached.
+func fetchGitHubRelease(repo, tag string) (GitHubRelease, error) {
+	cachePath := githubReleaseCachePath(repo, tag)
+	var cached githubReleaseCache
+	hasCache := false
+	if data, err := os.ReadFile(cachePath); err == nil && json.Unmarshal(data, &cached) == nil {
+		hasCache = true
+	}
+
+	requestURL := githubAPIURL + "/repos/" + repo + "/releases/latest"
+	if tag != "latest" {
+		requestURL = githubAPIURL + "/repos/" + repo + "/releases/tags/" + url.PathEscape(tag)
+	}
+	headers := map[string]string{"Accept": "application/vnd.github+json"}
+	if token := os.Getenv("GITHUB_TOKEN"); token != "" {
+		headers["Authorization"] = "Bearer " + token
+	}
+	if hasCache && cached.ETag != "" {
+		headers["If-None-Match"] = cached.ETag
+	}
+
+	resp, err := upstreamGet(requestURL, headers)
+	if err != nil {
+		if hasCache {
+			WarningTf("GitHub can't be reached, using the release of %s checked on %s: %v", repo, cached.FetchedAt.Local().Format("2006-01-02"), err)
+			return cached.Release, nil
+		}
+		return GitHubRelease{}, fmt.Errorf("failed to fetch release %s of %s: %w", tag, repo, err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && hasCache:
+		return cached.Release, nil
+	case resp.StatusCode == http.StatusNotFound:
+		if tag == "latest" {
+			return GitHubRelease{}, fmt.Errorf("GitHub repository %s has no releases", repo)
+		}
+		return GitHubRelease{}, fmt.Errorf("GitHub repository %s has no release %s", repo, tag)
+	case (resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusTooManyRequests) && resp.Header.Get("X-RateLimit-Remaining") == "0":
+		if hasCache {
+			WarningTf("The GitHub API rate limit was reached, using the release of %s checked on %s", repo, cached.FetchedAt.Local().Format("2006-01-02"))
+			return cached.Release, nil
+		}
+		retryAfter := time.Now().Add(time.Hour)
+		if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			retryAfter = time.Unix(reset, 0)
+		}
+		return GitHubRelease{}, fmt.Errorf("the GitHub API rate limit was reached, try again after %s or set GITHUB_TOKEN", retryAfter.Local().Format("15:04"))
+	case resp.StatusCode != http.StatusOK:
+		return GitHubRelease{}, fmt.Errorf("GitHub API returned status %d for release %s of %s", resp.StatusCode, tag, repo)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return GitHubRelease{}, fmt.Errorf("failed to read release %s of %s: %w", tag, repo, err)
+	}
+	var release GitHubRelease
+	if err := json.Unmarshal(data, &release); err != nil {
+		return GitHubRelease{}, fmt.Errorf("failed to parse release %s of %s: %w", tag, repo, err)
+	}
+
+	entry := githubReleaseCache{ETag: resp.Header.Get("ETag"), Release: release, FetchedAt: time.Now()}
+	if data, err := json.Marshal(entry); err == nil {
+		if err := EnsureDirOwned(filepath.Dir(cachePath), InvokingUser, InvokingUser); err == nil {
+			os.WriteFile(cachePath, data, 0644)
+		}
+	}
+	return release, nil
+}
+
+// SelectGitHubReleaseAsset returns the download URL of the asset of a GitHub release that fits the userland
+// architecture of this system, and the version of the release to record with `api set_installed_version`.
+//
+//	owner, repo - the GitHub repository, like "ruffle-rs" and "ruffle"
+//	tagOrLatest - the tag of the release, or "latest" or "" for the latest release
+//	patterns - optional shell globs of asset names by architecture (arm64, armhf, amd64, i386 or their
+//	           other names like aarch64), "*" narrows down the assets the heuristics pick from
+//
+// A release without an asset for this system returns an error listing the assets it has.
+func SelectGitHubReleaseAsset(owner, repo, tagOrLatest string, patterns map[string]string) (string, string, error) {
+	if owner == "" || repo == "" {
+		return "", "", fmt.Errorf("no GitHub repository specified")
+	}
+	if tagOrLatest == "" {
+		tagOrLatest = "latest"
+	}
+	release, err := fetchGitHubRelease(owner+"/"+repo, tagOrLatest)
+	if err != nil {
+		return "", "", err
+	}
+	asset, err := selectReleaseAsset(release, githubAssetArch(), patterns)
+	if err != nil {
+		return "", "", fmt.Errorf("%s/%s: %w", owner, repo, err)
+	}
+	return asset.BrowserDownloadURL, normalizeUpstreamVersion(release.TagName), nil
+}
+
+// KnownAssetArch reports whether an architecture can be used in asset patterns
+func KnownAssetArch(arch string) bool {
+	arch = normalizeAssetArch(arch)
+	return arch == "*" || slices.Contains(archTokenOrder, arch)
+}
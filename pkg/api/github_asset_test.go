@@ -0,0 +1,151 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// readTestRelease reads a release fixture of testdata/github-releases
+func readTestRelease(t *testing.T, name string) GitHubRelease {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", "github-releases", name+".json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var release GitHubRelease
+	if err := json.Unmarshal(data, &release); err != nil {
+		t.Fatal(err)
+	}
+	return release
+}
+
+func TestSelectReleaseAsset(t *testing.T) {
+	tests := []struct {
+		release  string
+		arch     string
+		patterns map[string]string
+		want     string // name of the asset, "" if there is none
+	}{
+		{"debian-packages", "arm64", nil, "app_1.2.3_arm64.deb"},
+		{"debian-packages", "armhf", nil, "app_1.2.3_armhf.deb"},
+		{"debian-packages", "amd64", nil, "app_1.2.3_amd64.deb"},
+		{"debian-packages", "i386", nil, ""},
+
+		{"uname-archives", "arm64", nil, "tool-v0.9.0-linux-aarch64.tar.gz"}, // not the one for macOS
+		{"uname-archives", "armhf", nil, "tool-v0.9.0-linux-armv7l.tar.gz"},
+		{"uname-archives", "amd64", nil, "tool-v0.9.0-linux-x86_64.tar.gz"}, // not the zip for Windows
+		{"uname-archives", "i386", nil, "tool-v0.9.0-linux-x86.tar.gz"},     // x86 is not x86_64
+
+		{"electron-appimages", "amd64", nil, "App-2024.05.1.AppImage"}, // the build naming no architecture
+		{"electron-appimages", "arm64", nil, "App-2024.05.1-arm64.AppImage"},
+		{"electron-appimages", "armhf", nil, "App-2024.05.1-armv7l.AppImage"},
+		{"electron-appimages", "i386", nil, ""},
+
+		{"arm-variants", "armhf", nil, "prog-3.0-linux-armv7.tar.gz"}, // armv7 wins over armv6 and plain arm
+		{"arm-variants", "arm64", nil, "prog-3.0-linux-arm64.tar.gz"}, // arm64 is not arm
+		{"arm-variants", "amd64", nil, ""},                            // a .deb and a .tar.gz, ambiguous
+		{"arm-variants", "amd64", map[string]string{"*": "*.deb"}, "prog-3.0-linux-amd64.deb"},
+		{"arm-variants", "armhf", map[string]string{"arm": "*-armv6.tar.gz"}, "prog-3.0-linux-armv6.tar.gz"},
+		{"arm-variants", "arm64", map[string]string{"armhf": "*-armv6.tar.gz"}, "prog-3.0-linux-arm64.tar.gz"},
+		{"arm-variants", "arm64", map[string]string{"aarch64": "*.AppImage"}, ""},
+	}
+	for _, tt := range tests {
+		release := readTestRelease(t, tt.release)
+		asset, err := selectReleaseAsset(release, tt.arch, tt.patterns)
+		if tt.want == "" {
+			if err == nil {
+				t.Errorf("%s on %s %v = %s, want an error", tt.release, tt.arch, tt.patterns, asset.Name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s on %s %v: %v", tt.release, tt.arch, tt.patterns, err)
+		} else if asset.Name != tt.want {
+			t.Errorf("%s on %s %v = %s, want %s", tt.release, tt.arch, tt.patterns, asset.Name, tt.want)
+		}
+	}
+}
+
+func TestSelectReleaseAssetErrorListsAssets(t *testing.T) {
+	_, err := selectReleaseAsset(readTestRelease(t, "debian-packages"), "i386", nil)
+	if err == nil || !strings.Contains(err.Error(), "app_1.2.3_armhf.deb") || !strings.Contains(err.Error(), "i386") {
+		t.Errorf("error = %v, want the architecture and the assets of the release", err)
+	}
+	_, err = selectReleaseAsset(readTestRelease(t, "arm-variants"), "amd64", nil)
+	if err == nil || !strings.Contains(err.Error(), "prog-3.0-linux-amd64.deb") || !strings.Contains(err.Error(), "--pattern") {
+		t.Errorf("error = %v, want the ambiguous assets and how to pick one", err)
+	}
+}
+
+func TestSelectGitHubReleaseAsset(t *testing.T) {
+	newTestPiAppsDir(t)
+	fixture, err := os.ReadFile(filepath.Join("testdata", "github-releases", "uname-archives.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	requests, notModified := 0, 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch r.URL.Path {
+		case "/repos/owner/tool/releases/latest", "/repos/owner/tool/releases/tags/v0.9.0":
+		default:
+			http.NotFound(w, r)
+			return
+		}
+		if r.Header.Get("If-None-Match") == `"v0.9.0"` {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v0.9.0"`)
+		w.Write(fixture)
+	}))
+	defer server.Close()
+	githubAPIURL = server.URL
+	githubAssetArch = func() string { return "arm64" }
+	defer func() {
+		githubAPIURL = "https://api.github.com"
+		githubAssetArch = userlandArch
+	}()
+
+	for range 2 {
+		url, version, err := SelectGitHubReleaseAsset("owner", "tool", "", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if url != "https://github.com/o/r/releases/download/v0.9.0/tool-v0.9.0-linux-aarch64.tar.gz" || version != "0.9.0" {
+			t.Errorf("SelectGitHubReleaseAsset = %s, %s", url, version)
+		}
+	}
+	if requests != 2 || notModified != 1 {
+		t.Errorf("%d requests with %d revalidated, want the second one revalidated with the ETag", requests, notModified)
+	}
+
+	if _, _, err := SelectGitHubReleaseAsset("owner", "tool", "v0.9.0", map[string]string{"arm64": "*.deb"}); err == nil || !strings.Contains(err.Error(), "tool-v0.9.0-windows-x86_64.zip") {
+		t.Errorf("a pattern without a match = %v, want an error listing the assets", err)
+	}
+	if _, _, err := SelectGitHubReleaseAsset("owner", "missing", "latest", nil); err == nil || !strings.Contains(err.Error(), "no releases") {
+		t.Errorf("a repository without releases = %v", err)
+	}
+}
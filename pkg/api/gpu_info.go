@@ -0,0 +1,306 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: gpu_info.go
+// Description: Collects the GPU model, memory split, GL/Vulkan drivers and KMS mode for device info and app requirements.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package api
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Graphics driver modes returned by GetGPUInfo
+const (
+	DriverModeKMS     = "kms"     // full KMS driver, e.g. vc4-kms-v3d
+	DriverModeFakeKMS = "fkms"    // firmware KMS driver on older Raspberry Pi OS, vc4-fkms-v3d
+	DriverModeLegacy  = "legacy"  // firmware framebuffer without a DRM driver
+	DriverModeUnknown = "unknown" // no DRM devices or framebuffer were found
+)
+
+// gpuNotAvailable is shown for a driver when the tool to query it is not installed
+const gpuNotAvailable = "not available"
+
+// gpuCommandTimeout limits how long glxinfo, vulkaninfo and vcgencmd may take
+const gpuCommandTimeout = 5 * time.Second
+
+// configTxtPaths are the locations of the Raspberry Pi firmware config, Bookworm moved it to /boot/firmware
+var configTxtPaths = []string{"/boot/firmware/config.txt", "/boot/config.txt"}
+
+// GPUInfo describes the graphics stack of the device
+type GPUInfo struct {
+	Model        string   `json:"model"`                 // e.g. Broadcom VideoCore VI, "" if unknown
+	GPUMemMB     int      `json:"gpu_mem_mb,omitempty"`  // memory split on Raspberry Pi, 0 if unknown
+	GLDriver     string   `json:"gl_driver"`             // OpenGL renderer and version from glxinfo, "" without glxinfo
+	VulkanDriver string   `json:"vulkan_driver"`         // Vulkan driver from vulkaninfo, "" without vulkaninfo
+	VulkanTool   bool     `json:"vulkan_tool"`           // vulkaninfo is installed, so an empty VulkanDriver means no Vulkan device
+	VulkanICDs   []string `json:"vulkan_icds,omitempty"` // installed Vulkan driver manifests, e.g. broadcom_icd.aarch64.json
+	DriverMode   string   `json:"driver_mode"`           // kms, fkms, legacy or unknown
+}
+
+// GetGPUInfo collects the graphics stack of the device
+//
+// glxinfo and vulkaninfo are only run when they are installed, they are not pulled in as dependencies.
+func GetGPUInfo() GPUInfo {
+	var gpu GPUInfo
+	configTxt := readConfigTxt()
+
+	gpu.Model = deviceTreeGPUModel()
+	if gpu.Model == "" {
+		if output, err := runGPUCommand("lspci"); err == nil {
+			gpu.Model = parseLspciGPU(output)
+		}
+	}
+
+	if output, err := runGPUCommand("vcgencmd", "get_mem", "gpu"); err == nil {
+		gpu.GPUMemMB = parseVcgencmdGPUMem(output)
+	}
+	if gpu.GPUMemMB == 0 && isRaspberryPi() {
+		// vcgencmd is missing on some distros, the split is read from config.txt then
+		gpu.GPUMemMB, _ = parseConfigTxt(configTxt)
+	}
+
+	if output, err := runGPUCommand("glxinfo", "-B"); err == nil {
+		gpu.GLDriver = parseGLXInfo(output)
+	}
+	if _, err := exec.LookPath("vulkaninfo"); err == nil {
+		gpu.VulkanTool = true
+		if output, err := runGPUCommand("vulkaninfo", "--summary"); err == nil {
+			gpu.VulkanDriver = parseVulkanInfo(output)
+		}
+	}
+	for _, dir := range []string{"/usr/share/vulkan/icd.d", "/etc/vulkan/icd.d"} {
+		manifests, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+		for _, manifest := range manifests {
+			gpu.VulkanICDs = append(gpu.VulkanICDs, filepath.Base(manifest))
+		}
+	}
+
+	_, gpu.DriverMode = parseConfigTxt(configTxt)
+	if gpu.DriverMode == "" {
+		gpu.DriverMode = drmDriverMode()
+	}
+	return gpu
+}
+
+// HasVulkan reports whether a Vulkan driver is available, false if it can't be told
+func (g GPUInfo) HasVulkan() bool {
+	if g.VulkanTool {
+		return g.VulkanDriver != ""
+	}
+	return len(g.VulkanICDs) > 0
+}
+
+// DeviceInfoLines returns the lines GetDeviceInfo adds to log headers
+func (g GPUInfo) DeviceInfoLines() string {
+	var info strings.Builder
+	if g.Model != "" {
+		info.WriteString("GPU: " + g.Model + "\n")
+	}
+	if g.GPUMemMB > 0 {
+		info.WriteString("GPU memory: " + strconv.Itoa(g.GPUMemMB) + " MB\n")
+	}
+	info.WriteString("OpenGL driver: " + valueOrNotAvailable(g.GLDriver) + "\n")
+	vulkan := g.VulkanDriver
+	if vulkan == "" && g.VulkanTool {
+		vulkan = "no Vulkan device"
+	}
+	info.WriteString("Vulkan driver: " + valueOrNotAvailable(vulkan) + "\n")
+	info.WriteString("Graphics driver mode: " + g.DriverMode + "\n")
+	return info.String()
+}
+
+// valueOrNotAvailable returns value, or "not available" when it is empty
+func valueOrNotAvailable(value string) string {
+	if value == "" {
+		return gpuNotAvailable
+	}
+	return value
+}
+
+// runGPUCommand runs a command only if it is installed and returns its standard output
+func runGPUCommand(name string, args ...string) (string, error) {
+	path, err := exec.LookPath(name)
+	if err != nil {
+		return "", err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), gpuCommandTimeout)
+	defer cancel()
+	output, err := exec.CommandContext(ctx, path, args...).Output()
+	return string(output), err
+}
+
+// readConfigTxt returns the Raspberry Pi firmware config, "" if there is none
+func readConfigTxt() string {
+	for _, path := range configTxtPaths {
+		if content, err := os.ReadFile(path); err == nil {
+			return string(content)
+		}
+	}
+	return ""
+}
+
+// deviceTreeGPUModel returns the GPU named by the device tree, "" on devices without one
+func deviceTreeGPUModel() string {
+	if compatible, err := os.ReadFile("/proc/device-tree/compatible"); err == nil {
+		if model := videoCoreModel(string(compatible)); model != "" {
+			return model
+		}
+	}
+	// Other ARM boards describe their GPU in a gpu node, e.g. arm,mali-bifrost
+	for _, pattern := range []string{"/proc/device-tree/gpu*/compatible", "/proc/device-tree/soc*/gpu*/compatible"} {
+		paths, _ := filepath.Glob(pattern)
+		for _, path := range paths {
+			if compatible, err := os.ReadFile(path); err == nil {
+				if first, _, _ := strings.Cut(string(compatible), "\x00"); first != "" {
+					return first
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// videoCoreModel returns the VideoCore GPU of a Raspberry Pi SoC from the device tree compatible string
+func videoCoreModel(compatible string) string {
+	switch {
+	case strings.Contains(compatible, "bcm2712"):
+		return "Broadcom VideoCore VII"
+	case strings.Contains(compatible, "bcm2711"):
+		return "Broadcom VideoCore VI"
+	case strings.Contains(compatible, "bcm2835"), strings.Contains(compatible, "bcm2836"), strings.Contains(compatible, "bcm2837"):
+		return "Broadcom VideoCore IV"
+	}
+	return ""
+}
+
+// lspciGPURegex matches the display controllers in lspci output
+var lspciGPURegex = regexp.MustCompile(`(?m)^\S+ (?:VGA compatible controller|3D controller|Display controller): (.+)$`)
+
+// parseLspciGPU returns the first display controller lspci lists
+func parseLspciGPU(output string) string {
+	if match := lspciGPURegex.FindStringSubmatch(output); match != nil {
+		return strings.TrimSpace(match[1])
+	}
+	return ""
+}
+
+// parseVcgencmdGPUMem parses the output of "vcgencmd get_mem gpu", e.g. "gpu=76M"
+func parseVcgencmdGPUMem(output string) int {
+	value, found := strings.CutPrefix(strings.TrimSpace(output), "gpu=")
+	if !found {
+		return 0
+	}
+	mb, err := strconv.Atoi(strings.TrimSuffix(value, "M"))
+	if err != nil {
+		return 0
+	}
+	return mb
+}
+
+// parseConfigTxt returns the gpu_mem split and the graphics driver mode set in config.txt
+//
+// The last setting wins like in the firmware, sections that don't apply to every model
+// are read as well because the model filters can't be evaluated here.
+// A mode of "" means config.txt doesn't load a vc4 overlay.
+func parseConfigTxt(content string) (gpuMemMB int, mode string) {
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		key, value, found := strings.Cut(strings.TrimSpace(line), "=")
+		if !found {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+		switch key {
+		case "gpu_mem":
+			if mb, err := strconv.Atoi(value); err == nil {
+				gpuMemMB = mb
+			}
+		case "dtoverlay":
+			overlay, _, _ := strings.Cut(value, ",")
+			switch overlay {
+			case "vc4-kms-v3d", "vc4-kms-v3d-pi4", "vc4-kms-v3d-pi5":
+				mode = DriverModeKMS
+			case "vc4-fkms-v3d", "vc4-fkms-v3d-pi4":
+				mode = DriverModeFakeKMS
+			}
+		}
+	}
+	return gpuMemMB, mode
+}
+
+// drmDriverMode tells KMS from a legacy framebuffer by the devices the kernel created
+func drmDriverMode() string {
+	if cards, _ := filepath.Glob("/sys/class/drm/card[0-9]*"); len(cards) > 0 {
+		return DriverModeKMS
+	}
+	if fileExists("/dev/fb0") {
+		return DriverModeLegacy
+	}
+	return DriverModeUnknown
+}
+
+// parseGLXInfo returns the OpenGL renderer and version from "glxinfo -B", e.g. "V3D 4.2 (OpenGL 3.1 Mesa 23.2.1)"
+func parseGLXInfo(output string) string {
+	var renderer, version string
+	for line := range strings.SplitSeq(output, "\n") {
+		line = strings.TrimSpace(line)
+		if value, found := strings.CutPrefix(line, "OpenGL renderer string:"); found {
+			renderer = strings.TrimSpace(value)
+		} else if value, found := strings.CutPrefix(line, "OpenGL version string:"); found {
+			version = strings.TrimSpace(value)
+		}
+	}
+	if renderer == "" {
+		return ""
+	}
+	if version == "" {
+		return renderer
+	}
+	return renderer + " (OpenGL " + version + ")"
+}
+
+// vulkanInfoRegex matches the driver and device lines of "vulkaninfo --summary"
+var vulkanInfoRegex = regexp.MustCompile(`(?m)^\s*(driverName|deviceName)\s*=\s*(.+?)\s*$`)
+
+// parseVulkanInfo returns the first Vulkan device from "vulkaninfo --summary", e.g. "V3DV Mesa (V3D 4.2.14)"
+func parseVulkanInfo(output string) string {
+	fields := map[string]string{}
+	for _, match := range vulkanInfoRegex.FindAllStringSubmatch(output, -1) {
+		if _, seen := fields[match[1]]; !seen {
+			fields[match[1]] = match[2]
+		}
+	}
+	driver := fields["driverName"]
+	if driver == "" {
+		return ""
+	}
+	if device := fields["deviceName"]; device != "" {
+		driver += " (" + device + ")"
+	}
+	return driver
+}
@@ -0,0 +1,117 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseConfigTxt(t *testing.T) {
+	config := `# For more options and information see
+gpu_mem=64
+[pi4]
+dtoverlay=vc4-fkms-v3d
+max_framebuffers=2
+
+[all]
+gpu_mem=128 # for the camera
+dtoverlay=vc4-kms-v3d,cma-384
+`
+	gpuMem, mode := parseConfigTxt(config)
+	if gpuMem != 128 {
+		t.Errorf("gpu_mem = %d, want 128", gpuMem)
+	}
+	if mode != DriverModeKMS {
+		t.Errorf("mode = %q, want %q", mode, DriverModeKMS)
+	}
+
+	if gpuMem, mode := parseConfigTxt("arm_64bit=1\n"); gpuMem != 0 || mode != "" {
+		t.Errorf("config without gpu settings = (%d, %q), want (0, \"\")", gpuMem, mode)
+	}
+}
+
+func TestParseGPUCommands(t *testing.T) {
+	if mb := parseVcgencmdGPUMem("gpu=76M\n"); mb != 76 {
+		t.Errorf("parseVcgencmdGPUMem = %d, want 76", mb)
+	}
+	if mb := parseVcgencmdGPUMem("error=1 error_msg=\"Command not registered\"\n"); mb != 0 {
+		t.Errorf("parseVcgencmdGPUMem of an error = %d, want 0", mb)
+	}
+
+	lspci := `00:00.0 Host bridge: Intel Corporation Xeon E3-1200 v6/7th Gen Core Processor Host Bridge/DRAM Registers (rev 08)
+00:02.0 VGA compatible controller: Intel Corporation UHD Graphics 620 (rev 07)
+`
+	if model := parseLspciGPU(lspci); model != "Intel Corporation UHD Graphics 620 (rev 07)" {
+		t.Errorf("parseLspciGPU = %q", model)
+	}
+
+	glxinfo := `name of display: :0
+display: :0  screen: 0
+direct rendering: Yes
+OpenGL vendor string: Broadcom
+OpenGL renderer string: V3D 4.2
+OpenGL version string: 2.1 Mesa 23.2.1-1~bpo12+rpt3
+`
+	if driver := parseGLXInfo(glxinfo); driver != "V3D 4.2 (OpenGL 2.1 Mesa 23.2.1-1~bpo12+rpt3)" {
+		t.Errorf("parseGLXInfo = %q", driver)
+	}
+
+	vulkaninfo := `Devices:
+========
+GPU0:
+	apiVersion         = 1.2.255
+	driverVersion      = 23.2.1
+	deviceType         = PHYSICAL_DEVICE_TYPE_INTEGRATED_GPU
+	deviceName         = V3D 4.2.14
+	driverName         = V3DV Mesa
+GPU1:
+	deviceName         = llvmpipe (LLVM 15.0.6, 128 bits)
+	driverName         = llvmpipe
+`
+	if driver := parseVulkanInfo(vulkaninfo); driver != "V3DV Mesa (V3D 4.2.14)" {
+		t.Errorf("parseVulkanInfo = %q", driver)
+	}
+}
+
+func TestCheckGPURequirements(t *testing.T) {
+	requirements := AppRequirements{RequiresVulkan: true, RequiresKMS: true}
+
+	gpu := GPUInfo{VulkanTool: true, VulkanDriver: "V3DV Mesa", DriverMode: DriverModeKMS}
+	if err := checkGPURequirements("Test", requirements, gpu); err != nil {
+		t.Errorf("met requirements failed: %v", err)
+	}
+
+	gpu.VulkanDriver = ""
+	if err := checkGPURequirements("Test", requirements, gpu); err == nil || !strings.Contains(err.Error(), "Vulkan") {
+		t.Errorf("missing Vulkan device = %v, want a Vulkan error", err)
+	}
+
+	gpu = GPUInfo{VulkanICDs: []string{"broadcom_icd.aarch64.json"}, DriverMode: DriverModeFakeKMS}
+	if err := checkGPURequirements("Test", requirements, gpu); err == nil || !strings.Contains(err.Error(), "fkms") {
+		t.Errorf("fake KMS = %v, want a KMS error", err)
+	}
+}
+
+func TestGPUInfoDeviceInfoLines(t *testing.T) {
+	lines := GPUInfo{Model: "Broadcom VideoCore VI", GPUMemMB: 76, DriverMode: DriverModeKMS}.DeviceInfoLines()
+	for _, want := range []string{"GPU: Broadcom VideoCore VI\n", "GPU memory: 76 MB\n", "OpenGL driver: not available\n", "Vulkan driver: not available\n", "Graphics driver mode: kms\n"} {
+		if !strings.Contains(lines, want) {
+			t.Errorf("device info lines %q miss %q", lines, want)
+		}
+	}
+}
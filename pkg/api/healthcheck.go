@@ -0,0 +1,291 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: healthcheck.go
+// Description: Runs the healthcheck scripts apps ship to tell whether they still work after they were installed.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package api
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/pi-apps-go/pi-apps/pkg/api/errs"
+)
+
+const (
+	// healthcheckScript is the script in an app's folder that exits 0 when the installed app works,
+	// and otherwise prints what is wrong and exits with another code
+	healthcheckScript = "healthcheck"
+	// healthcheckTimeout is how long a healthcheck may run before it is stopped and counted as failed
+	healthcheckTimeout = 2 * time.Minute
+	// healthcheckOutputLimit is the number of bytes of output a result keeps, the end of it
+	healthcheckOutputLimit = 4096
+)
+
+// healthcheckWrapper sources the API like install scripts get it, then runs the healthcheck in the same shell,
+// without the arguments of the wrapper
+const healthcheckWrapper = `api="$1" script="$2"; set --; source "$api" || exit 1; source "$script"`
+
+// HealthResult is the result of running the healthcheck of an app
+type HealthResult struct {
+	App       string    `json:"app"`
+	Healthy   bool      `json:"healthy"`
+	Skipped   bool      `json:"skipped,omitempty"`   // the app has no healthcheck, so nothing ran
+	ExitCode  int       `json:"exit_code,omitempty"` // exit code of the healthcheck, -1 if it timed out or didn't start
+	TimedOut  bool      `json:"timed_out,omitempty"`
+	Output    string    `json:"output,omitempty"` // what the healthcheck printed, trimmed to its end
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// Problem returns what is wrong according to a failed healthcheck, like "exit code 1: libfoo.so.2 is missing".
+// It is "" for a healthy app.
+func (r HealthResult) Problem() string {
+	if r.Healthy {
+		return ""
+	}
+	message := lastLine(r.Output)
+	switch {
+	case r.TimedOut:
+		return Tf("timed out after %s", healthcheckTimeout)
+	case message == "":
+		return Tf("exit code %d", r.ExitCode)
+	}
+	return Tf("exit code %d: %s", r.ExitCode, message)
+}
+
+// lastLine returns the last non-empty line of a text
+func lastLine(text string) string {
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
+
+// HasHealthcheck reports whether an app ships a healthcheck script
+func HasHealthcheck(app string) bool {
+	path, err := AppPath(app, healthcheckScript)
+	return err == nil && FileExists(path)
+}
+
+// RunHealthChecks runs the healthchecks of apps and records the results in the history log.
+// Without apps, it checks every installed app that has a healthcheck. Apps without a healthcheck get a
+// skipped result. The statuses of the apps are left alone, MarkAppUnhealthy marks a failed one as corrupted.
+//
+//	[]HealthResult - the result of every app, in the order of apps
+//	error - error if an app doesn't exist or the installed apps can't be listed
+func RunHealthChecks(apps []string) ([]HealthResult, error) {
+	if len(apps) == 0 {
+		installed, err := ListApps("installed")
+		if err != nil {
+			return nil, fmt.Errorf("failed to list installed apps: %w", err)
+		}
+		for _, app := range installed {
+			if HasHealthcheck(app) {
+				apps = append(apps, app)
+			}
+		}
+	}
+	for _, app := range apps {
+		if !IsValidApp(app) {
+			return nil, errs.New(errs.ErrAppNotFound, "app '%s' does not exist", app)
+		}
+	}
+
+	results := make([]HealthResult, 0, len(apps))
+	for _, app := range apps {
+		if !HasHealthcheck(app) {
+			results = append(results, HealthResult{App: app, Healthy: true, Skipped: true, CheckedAt: time.Now()})
+			continue
+		}
+		result := runHealthcheck(app)
+		if err := recordHealthResult(result); err != nil {
+			Debug(fmt.Sprintf("Failed to record the health check of %s: %v", app, err))
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// runHealthcheck runs the healthcheck of an app the way its install script runs: with the API, as the user
+// that started Pi-Apps, from the home directory and within the install resource limits of the app
+func runHealthcheck(app string) HealthResult {
+	result := HealthResult{App: app, ExitCode: -1, CheckedAt: time.Now()}
+	script, err := AppPath(app, healthcheckScript)
+	if err != nil {
+		result.Output = err.Error()
+		return result
+	}
+	piAppsDir := GetPiAppsDir()
+
+	ctx, cancel := context.WithTimeout(context.Background(), healthcheckTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "bash", "-c", healthcheckWrapper, "healthcheck", filepath.Join(piAppsDir, "api"), script)
+
+	env := os.Environ()
+	env = append(env, "PI_APPS_DIR="+piAppsDir)
+	env = append(env, "app="+app)
+	env = append(env, "DEBIAN_FRONTEND=noninteractive")
+	env = append(env, ScriptExitEnv()...)
+	// The output is recorded rather than shown, keep escape sequences out of it
+	env = append(env, colorEnvVar+"="+string(ColorOutputNever))
+	cmd.Dir = os.Getenv("HOME")
+	if home := runAsInvokingUser(cmd); home != "" {
+		env = append(env, "HOME="+home)
+		cmd.Dir = home
+	}
+	cmd.Env = env
+
+	// The timeout stops the commands the healthcheck started too, not only bash
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Setpgid = true
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+	cmd.WaitDelay = 5 * time.Second
+	limitCommand(cmd, app, InstallResourceLimits(app))
+
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	err = cmd.Run()
+
+	result.Output = strings.TrimSpace(RemoveAnsiEscapes(output.String()))
+	if len(result.Output) > healthcheckOutputLimit {
+		result.Output = result.Output[len(result.Output)-healthcheckOutputLimit:]
+	}
+	var exitErr *exec.ExitError
+	switch {
+	case ctx.Err() == context.DeadlineExceeded:
+		result.TimedOut = true
+	case err == nil:
+		result.Healthy = true
+		result.ExitCode = 0
+	case errors.As(err, &exitErr):
+		result.ExitCode = exitErr.ExitCode()
+	default:
+		result.Output = strings.TrimSpace(result.Output + "\n" + err.Error())
+	}
+	return result
+}
+
+// recordHealthResult adds a healthcheck result to the history log
+func recordHealthResult(result HealthResult) error {
+	return recordHistory(result.App, historyHealthcheck, strconv.Itoa(result.ExitCode), result.Problem())
+}
+
+// writeHealthResult writes a healthcheck result to an app's log
+func writeHealthResult(log io.Writer, result HealthResult) {
+	if result.Healthy {
+		fmt.Fprintf(log, "\nHealth check of %s passed.\n", result.App)
+		return
+	}
+	fmt.Fprintf(log, "\n%s Health check of %s failed: %s\n", result.CheckedAt.Format("2006-01-02 15:04:05"), result.App, result.Problem())
+	if result.Output != "" {
+		fmt.Fprintf(log, "%s\n", result.Output)
+	}
+}
+
+// runInstalledHealthcheck runs and records the healthcheck of an app that was just installed.
+// It returns an errs.ErrUnhealthy error if the check failed.
+func runInstalledHealthcheck(app string) (HealthResult, error) {
+	StatusTf("Checking that %s works...", app)
+	result := runHealthcheck(app)
+	if err := recordHealthResult(result); err != nil {
+		Debug(fmt.Sprintf("Failed to record the health check of %s: %v", app, err))
+	}
+	if result.Healthy {
+		return result, nil
+	}
+	if result.Output != "" {
+		fmt.Println(result.Output)
+	}
+	return result, errs.New(errs.ErrUnhealthy, "the health check of %s failed: %s", app, result.Problem())
+}
+
+// checkInstalledHealth runs the healthcheck of a script-app that was just installed, if it has one,
+// and writes the result to its install log
+func checkInstalledHealth(app string, log io.Writer) error {
+	if !HasHealthcheck(app) {
+		return nil
+	}
+	result, err := runInstalledHealthcheck(app)
+	writeHealthResult(log, result)
+	return err
+}
+
+// checkInstalledPackageHealth runs the healthcheck of a package-app or flatpak-app that was just installed,
+// if it has one. Their installs have no log of their own, so a failed check is marked like MarkAppUnhealthy does.
+func checkInstalledPackageHealth(app string) error {
+	if !HasHealthcheck(app) {
+		return nil
+	}
+	result, err := runInstalledHealthcheck(app)
+	if err != nil {
+		if markErr := MarkAppUnhealthy(result); markErr != nil {
+			Debug(fmt.Sprintf("Failed to mark %s as corrupted: %v", app, markErr))
+		}
+	}
+	return err
+}
+
+// MarkAppUnhealthy marks an installed app whose healthcheck failed as corrupted and appends the result to
+// its newest log, or to a new healthcheck-fail log if it has none
+func MarkAppUnhealthy(result HealthResult) error {
+	if result.Healthy {
+		return nil
+	}
+	lock, err := LockState("mark " + result.App + " corrupted")
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
+	logsDir := GetLogsDir()
+	logPath := LatestAppLog(logsDir, result.App, false)
+	if logPath == "" {
+		logPath = filepath.Join(logsDir, fmt.Sprintf("healthcheck-fail-%s.log", result.App))
+	}
+	if err := EnsureDirOwned(logsDir, InvokingUser, InvokingUser); err != nil {
+		return err
+	}
+	logFile, err := os.OpenFile(logPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open the log of %s: %w", result.App, err)
+	}
+	writeHealthResult(logFile, result)
+	if err := logFile.Close(); err != nil {
+		return err
+	}
+	return SetAppStatus(result.App, "corrupted")
+}
+
+// HealthRecheckEnabled reports whether the "Recheck app health" setting is Yes, so the updater runs the
+// healthchecks of the installed apps when it checks for updates in the background
+func HealthRecheckEnabled() bool {
+	return ReadSetting("Recheck app health") == "Yes"
+}
@@ -0,0 +1,203 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pi-apps-go/pi-apps/pkg/api/errs"
+)
+
+// newTestHealthDir creates a Pi-Apps directory whose api defines a function, so healthchecks can tell it was sourced
+func newTestHealthDir(t *testing.T, apps ...string) string {
+	t.Helper()
+	dir := newTestPiAppsDir(t, apps...)
+	writeTestFile(t, filepath.Join(dir, "api"), "#!/bin/bash\napi_loaded() { true; }\n")
+	t.Setenv("HOME", t.TempDir())
+	return dir
+}
+
+func TestRunHealthChecks(t *testing.T) {
+	dir := newTestHealthDir(t, "Works", "Broken", "No Check", "Not Installed")
+	writeTestFile(t, filepath.Join(dir, "apps", "Works", "healthcheck"), "api_loaded || exit 5\n[ \"$app\" = Works ] || exit 6\n")
+	writeTestFile(t, filepath.Join(dir, "apps", "Broken", "healthcheck"), "echo starting\necho 'libfoo.so.2 is missing' >&2\nexit 3\n")
+	writeTestFile(t, filepath.Join(dir, "apps", "Not Installed", "healthcheck"), "exit 1\n")
+	for _, app := range []string{"Works", "Broken", "No Check", "Not Installed"} {
+		writeTestFile(t, filepath.Join(dir, "apps", app, "install"), "#!/bin/bash\n")
+	}
+	for _, app := range []string{"Works", "Broken", "No Check"} {
+		writeTestFile(t, filepath.Join(dir, "data", "status", app), "installed")
+	}
+
+	results, err := RunHealthChecks(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 || results[0].App != "Broken" || results[1].App != "Works" {
+		t.Fatalf("RunHealthChecks(nil) = %+v, want Broken and Works", results)
+	}
+	broken, works := results[0], results[1]
+	if broken.Healthy || broken.ExitCode != 3 || !strings.Contains(broken.Output, "starting") {
+		t.Errorf("Broken = %+v, want exit code 3 with its output", broken)
+	}
+	if got := broken.Problem(); got != "exit code 3: libfoo.so.2 is missing" {
+		t.Errorf("Broken.Problem() = %q", got)
+	}
+	if !works.Healthy || works.ExitCode != 0 || works.Problem() != "" {
+		t.Errorf("Works = %+v, want healthy", works)
+	}
+
+	// Apps named explicitly are checked whatever their status, and apps without a healthcheck are skipped
+	results, err = RunHealthChecks([]string{"No Check", "Not Installed"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 || !results[0].Skipped || !results[0].Healthy || results[1].Healthy || results[1].ExitCode != 1 {
+		t.Errorf("RunHealthChecks(No Check, Not Installed) = %+v", results)
+	}
+	if _, err := RunHealthChecks([]string{"Missing"}); !errors.Is(err, errs.ErrAppNotFound) {
+		t.Errorf("RunHealthChecks(Missing) = %v, want ErrAppNotFound", err)
+	}
+
+	// Checks that ran are in the history log, skipped ones are not
+	for app, want := range map[string][]string{
+		"Broken":        {"3", broken.Problem()},
+		"Works":         {"0", ""},
+		"Not Installed": {"1", "exit code 1"},
+		"No Check":      nil,
+	} {
+		entries := readHistory(app, historyHealthcheck)
+		if want == nil {
+			if len(entries) != 0 {
+				t.Errorf("history of %s = %+v, want nothing", app, entries)
+			}
+		} else if len(entries) != 1 || !slices.Equal(entries[0].Details, want) {
+			t.Errorf("history of %s = %+v, want %q", app, entries, want)
+		}
+	}
+
+	// The statuses are left alone
+	if status, _ := GetAppStatus("Broken"); status != "installed" {
+		t.Errorf("status of Broken = %q, want installed", status)
+	}
+}
+
+func TestHealthResultJSON(t *testing.T) {
+	result := HealthResult{App: "Zoom", ExitCode: -1, TimedOut: true, CheckedAt: time.Unix(0, 0).UTC()}
+	data, err := json.Marshal(result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"app":"Zoom","healthy":false,"exit_code":-1,"timed_out":true,"checked_at":"1970-01-01T00:00:00Z"}`
+	if string(data) != want {
+		t.Errorf("JSON = %s, want %s", data, want)
+	}
+	if !strings.Contains(result.Problem(), "timed out") {
+		t.Errorf("Problem() = %q", result.Problem())
+	}
+}
+
+func TestMarkAppUnhealthy(t *testing.T) {
+	dir := newTestHealthDir(t, "Zoom", "Scratch")
+	for _, app := range []string{"Zoom", "Scratch"} {
+		writeTestFile(t, filepath.Join(dir, "data", "status", app), "installed")
+	}
+	older := filepath.Join(dir, "logs", "install-fail-Zoom.log")
+	newest := filepath.Join(dir, "logs", "install-success-Zoom.log")
+	writeTestFile(t, older, "old\n")
+	writeTestFile(t, newest, "installed Zoom\n")
+	past := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(older, past, past); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := MarkAppUnhealthy(HealthResult{App: "Zoom", Healthy: true}); err != nil {
+		t.Fatal(err)
+	}
+	if status, _ := GetAppStatus("Zoom"); status != "installed" {
+		t.Errorf("a healthy result changed the status to %q", status)
+	}
+
+	result := HealthResult{App: "Zoom", ExitCode: 2, Output: "zoom: error while loading shared libraries", CheckedAt: time.Now()}
+	if err := MarkAppUnhealthy(result); err != nil {
+		t.Fatal(err)
+	}
+	if status, _ := GetAppStatus("Zoom"); status != "corrupted" {
+		t.Errorf("status of Zoom = %q, want corrupted", status)
+	}
+	data, _ := os.ReadFile(newest)
+	if !strings.HasPrefix(string(data), "installed Zoom\n") || !strings.Contains(string(data), "error while loading shared libraries") {
+		t.Errorf("newest log of Zoom = %q, want the output appended", data)
+	}
+	if data, _ := os.ReadFile(older); string(data) != "old\n" {
+		t.Errorf("older log of Zoom changed: %q", data)
+	}
+
+	// An app without a log gets one that is found like the log of a failed install
+	if err := MarkAppUnhealthy(HealthResult{App: "Scratch", ExitCode: 1, CheckedAt: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	logPath := filepath.Join(dir, "logs", "healthcheck-fail-Scratch.log")
+	if _, err := os.Stat(logPath); err != nil {
+		t.Errorf("no healthcheck log for Scratch: %v", err)
+	}
+	if GetLogfile("Scratch") != logPath {
+		t.Errorf("GetLogfile(Scratch) = %q, want %q", GetLogfile("Scratch"), logPath)
+	}
+}
+
+func TestInstallAppRunsHealthcheck(t *testing.T) {
+	dir := newTestHealthDir(t, "Good", "Bad")
+	for _, app := range []string{"Good", "Bad"} {
+		writeTestFile(t, filepath.Join(dir, "apps", app, "install"), "#!/bin/bash\necho installing\n")
+		writeTestFile(t, filepath.Join(dir, "apps", app, "uninstall"), "#!/bin/bash\n")
+	}
+	writeTestFile(t, filepath.Join(dir, "apps", "Good", "healthcheck"), "exit 0\n")
+	writeTestFile(t, filepath.Join(dir, "apps", "Bad", "healthcheck"), "echo 'bad: command not found'\nexit 127\n")
+
+	if err := InstallApp("Good"); err != nil {
+		t.Fatalf("InstallApp(Good) = %v", err)
+	}
+	if status, _ := GetAppStatus("Good"); status != "installed" {
+		t.Errorf("status of Good = %q, want installed", status)
+	}
+
+	err := InstallApp("Bad")
+	if !errors.Is(err, errs.ErrUnhealthy) {
+		t.Fatalf("InstallApp(Bad) = %v, want ErrUnhealthy", err)
+	}
+	if RenderError(err).Hint == "" {
+		t.Error("an unhealthy install has no hint")
+	}
+	if status, _ := GetAppStatus("Bad"); status != "corrupted" {
+		t.Errorf("status of Bad = %q, want corrupted", status)
+	}
+	data, readErr := os.ReadFile(GetLogfile("Bad"))
+	if readErr != nil {
+		t.Fatal(readErr)
+	}
+	if !strings.Contains(string(data), "installing") || !strings.Contains(string(data), "bad: command not found") {
+		t.Errorf("install log of Bad misses the install or healthcheck output:\n%s", data)
+	}
+}
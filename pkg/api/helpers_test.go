@@ -0,0 +1,55 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// newTestPiAppsDir creates a Pi-Apps directory with the given apps in a temporary directory and points PI_APPS_DIR at it
+func newTestPiAppsDir(t testing.TB, apps ...string) string {
+	t.Helper()
+	directory := t.TempDir()
+	for _, name := range []string{"api", "gui"} {
+		if err := os.WriteFile(filepath.Join(directory, name), []byte("#!/bin/bash\n"), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, dir := range []string{"apps", "data/settings", "data/status", "etc", "logs"} {
+		if err := os.MkdirAll(filepath.Join(directory, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, app := range apps {
+		writeTestFile(t, filepath.Join(directory, "apps", app, "description"), app+" description\n")
+	}
+	t.Setenv("PI_APPS_DIR", directory)
+	return directory
+}
+
+// writeTestFile writes a file, creating its directory
+func writeTestFile(t testing.TB, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: history.go
+// Description: Keeps the history log of what happened to the apps on this device, like installs and health checks.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package api
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// historyFile is the history log in data, one "<unix time>\t<app>\t<event>\t<details>..." line per event, the
+	// oldest first
+	historyFile = "history"
+	// historyLimit is the number of events the history log keeps
+	historyLimit = 5000
+)
+
+// Events of the history log and their details
+const (
+	historyInstall     = "install"     // <seconds>, a successful install and how long it took
+	historyHealthcheck = "healthcheck" // <exit code>\t<problem>, -1 for a check that timed out
+	historyUserData    = "user-data"   // <kept|deleted>, what happened to the user data of an uninstalled app
+	historyRepair      = "repair"      // <action>\t<outcome>, see RepairApp
+)
+
+var historyMutex sync.Mutex
+
+// historyEntry is a line of the history log
+type historyEntry struct {
+	Time    time.Time
+	App     string
+	Event   string
+	Details []string
+}
+
+// appendHistory adds a line of fields after the current time to a history file in data, keeping its last limit
+// lines. Tabs and newlines in the fields are replaced with spaces.
+func appendHistory(file string, limit int, fields ...string) error {
+	historyMutex.Lock()
+	defer historyMutex.Unlock()
+
+	path := filepath.Join(GetDataDir(), file)
+	var lines []string
+	if data, err := os.ReadFile(path); err == nil {
+		lines = strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	}
+	line := strconv.FormatInt(time.Now().Unix(), 10)
+	for _, field := range fields {
+		line += "\t" + strings.Join(strings.Fields(field), " ")
+	}
+	lines = append(lines, line)
+	if len(lines) > limit {
+		lines = lines[len(lines)-limit:]
+	}
+	if err := EnsureDirOwned(filepath.Dir(path), InvokingUser, InvokingUser); err != nil {
+		return err
+	}
+	return WriteFileAtomic(path, []byte(strings.Join(lines, "\n")+"\n"), 0644)
+}
+
+// recordHistory adds an event of an app to the history log
+func recordHistory(app, event string, details ...string) error {
+	return appendHistory(historyFile, historyLimit, append([]string{app, event}, details...)...)
+}
+
+// readHistory returns the events of an app in the history log, the oldest first
+func readHistory(app, event string) []historyEntry {
+	historyMutex.Lock()
+	defer historyMutex.Unlock()
+
+	file, err := os.Open(filepath.Join(GetDataDir(), historyFile))
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	var entries []historyEntry
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), "\t")
+		if len(fields) < 3 || fields[1] != app || fields[2] != event {
+			continue
+		}
+		seconds, err := strconv.ParseInt(fields[0], 10, 64)
+		if err != nil {
+			continue
+		}
+		entries = append(entries, historyEntry{Time: time.Unix(seconds, 0), App: fields[1], Event: fields[2], Details: fields[3:]})
+	}
+	return entries
+}
@@ -0,0 +1,61 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestAppendHistory(t *testing.T) {
+	newTestPiAppsDir(t)
+	os.RemoveAll(GetDataDir())
+
+	for _, seconds := range []string{"60", "120", "180"} {
+		if err := appendHistory(historyFile, 2, "Zoom", historyInstall, seconds); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := recordHistory("Zoom", historyHealthcheck, "1", "line one\nline\ttwo"); err != nil {
+		t.Fatal(err)
+	}
+
+	// The oldest line is dropped once the limit is reached
+	var seconds []string
+	for _, entry := range readHistory("Zoom", historyInstall) {
+		seconds = append(seconds, entry.Details...)
+	}
+	if !slices.Equal(seconds, []string{"120", "180"}) {
+		t.Errorf("install history = %q, want the last 2 installs", seconds)
+	}
+
+	// A field can't break the layout of the log
+	entries := readHistory("Zoom", historyHealthcheck)
+	if len(entries) != 1 || !slices.Equal(entries[0].Details, []string{"1", "line one line two"}) || entries[0].Time.IsZero() {
+		t.Errorf("healthcheck history = %+v", entries)
+	}
+	data, _ := os.ReadFile(filepath.Join(GetDataDir(), historyFile))
+	if lines := strings.Split(strings.TrimSpace(string(data)), "\n"); len(lines) != 3 {
+		t.Errorf("history log = %q, want 3 lines", data)
+	}
+	if entries := readHistory("Teams", historyInstall); len(entries) != 0 {
+		t.Errorf("history of another app = %+v", entries)
+	}
+}
@@ -80,20 +80,27 @@ func InitializeApiI18n() error {
 	return nil
 }
 
+// localeGet looks up the translation of a message ID without formatting it. Get is called through a method
+// value so vet doesn't check the message ID as a format string, Tf formats the translation itself.
+func localeGet(msgid string) string {
+	get := apiLocale.Get
+	return get(msgid)
+}
+
 // T translates a string using the API locale
 func T(msgid string) string {
 	if !i18nInitialized || apiLocale == nil {
-		return msgid
+		return pseudoLocalize(msgid)
 	}
-	return apiLocale.Get(msgid)
+	return pseudoLocalize(localeGet(msgid))
 }
 
 // Tf translates a formatted string using the API locale
 func Tf(format string, args ...interface{}) string {
 	if !i18nInitialized || apiLocale == nil {
-		return fmt.Sprintf(format, args...)
+		return fmt.Sprintf(pseudoLocalize(format), args...)
 	}
-	translated := apiLocale.Get(format)
+	translated := pseudoLocalize(localeGet(format))
 	return fmt.Sprintf(translated, args...)
 }
 
@@ -101,11 +108,11 @@ func Tf(format string, args ...interface{}) string {
 func Tn(msgid, msgidPlural string, n int) string {
 	if !i18nInitialized || apiLocale == nil {
 		if n == 1 {
-			return msgid
+			return pseudoLocalize(msgid)
 		}
-		return msgidPlural
+		return pseudoLocalize(msgidPlural)
 	}
-	return apiLocale.GetN(msgid, msgidPlural, n)
+	return pseudoLocalize(apiLocale.GetN(msgid, msgidPlural, n))
 }
 
 // Tnf translates a formatted string with plural support using the API locale
@@ -117,9 +124,9 @@ func Tnf(msgid, msgidPlural string, n int, args ...interface{}) string {
 		} else {
 			format = msgidPlural
 		}
-		return fmt.Sprintf(format, args...)
+		return fmt.Sprintf(pseudoLocalize(format), args...)
 	}
-	translated := apiLocale.GetN(msgid, msgidPlural, n)
+	translated := pseudoLocalize(apiLocale.GetN(msgid, msgidPlural, n))
 	return fmt.Sprintf(translated, args...)
 }
 
@@ -159,10 +166,10 @@ func GetAvailableApiLocales() []string {
 func StatusT(msgid string, args ...interface{}) {
 	if len(args) > 0 && fmt.Sprintf("%v", args[0]) != "" {
 		translated := T(msgid)
-		fmt.Fprintln(os.Stderr, "\033[96m"+fmt.Sprintf(translated, args...)+"\033[0m")
+		colorPrintln(os.Stderr, "\033[96m", fmt.Sprintf(translated, args...))
 	} else {
 		translated := T(msgid)
-		fmt.Fprintln(os.Stderr, "\033[96m"+translated+"\033[0m")
+		colorPrintln(os.Stderr, "\033[96m", translated)
 	}
 }
 
@@ -172,7 +179,7 @@ func StatusGreenT(msgid string, args ...interface{}) {
 	if len(args) > 0 {
 		translated = fmt.Sprintf(translated, args...)
 	}
-	fmt.Fprintln(os.Stderr, "\033[92m"+translated+"\033[0m")
+	colorPrintln(os.Stderr, "\033[92m", statusPrefix(SymbolSuccess)+translated)
 }
 
 // WarningT displays a translated warning message in yellow with a flashing icon
@@ -182,7 +189,7 @@ func WarningT(msgid string, args ...interface{}) {
 		translated = fmt.Sprintf(translated, args...)
 	}
 	warningPrefix := T("WARNING:")
-	fmt.Fprintln(os.Stderr, "\033[93m\033[5m◢◣\033[25m "+warningPrefix+" "+translated+"\033[0m")
+	colorPrintln(os.Stderr, "\033[93m", warningIcon()+" "+warningPrefix+" "+translated)
 }
 
 // ErrorT displays a translated error message in red and exits the program
@@ -191,7 +198,8 @@ func ErrorT(msgid string, args ...interface{}) {
 	if len(args) > 0 {
 		translated = fmt.Sprintf(translated, args...)
 	}
-	fmt.Fprintln(os.Stderr, "\033[91m"+translated+"\033[0m")
+	colorPrintln(os.Stderr, "\033[91m", statusPrefix(SymbolFailure)+translated)
+	FlushDownloadLedger()
 	os.Exit(1)
 }
 
@@ -201,7 +209,7 @@ func ErrorNoExitT(msgid string, args ...interface{}) {
 	if len(args) > 0 {
 		translated = fmt.Sprintf(translated, args...)
 	}
-	fmt.Fprintln(os.Stderr, "\033[91m"+translated+"\033[0m")
+	colorPrintln(os.Stderr, "\033[91m", statusPrefix(SymbolFailure)+translated)
 }
 
 // DebugT displays a translated debug message when debug mode is enabled
@@ -216,10 +224,10 @@ func DebugT(msg string) {
 func StatusTf(format string, args ...interface{}) {
 	if len(args) > 0 && fmt.Sprintf("%v", args[0]) != "" {
 		translated := T(format)
-		fmt.Fprintln(os.Stderr, "\033[96m"+fmt.Sprintf(translated, args...)+"\033[0m")
+		colorPrintln(os.Stderr, "\033[96m", fmt.Sprintf(translated, args...))
 	} else {
 		translated := T(format)
-		fmt.Fprintln(os.Stderr, "\033[96m"+translated+"\033[0m")
+		colorPrintln(os.Stderr, "\033[96m", translated)
 	}
 }
 
@@ -229,7 +237,7 @@ func StatusGreenTf(format string, args ...interface{}) {
 	if len(args) > 0 {
 		translated = fmt.Sprintf(translated, args...)
 	}
-	fmt.Fprintln(os.Stderr, "\033[92m"+translated+"\033[0m")
+	colorPrintln(os.Stderr, "\033[92m", statusPrefix(SymbolSuccess)+translated)
 }
 
 // WarningTf displays a formatted translated warning message in yellow with a flashing icon
@@ -239,7 +247,7 @@ func WarningTf(format string, args ...interface{}) {
 		translated = fmt.Sprintf(translated, args...)
 	}
 	warningPrefix := T("WARNING:")
-	fmt.Fprintln(os.Stderr, "\033[93m\033[5m◢◣\033[25m "+warningPrefix+" "+translated+"\033[0m")
+	colorPrintln(os.Stderr, "\033[93m", warningIcon()+" "+warningPrefix+" "+translated)
 }
 
 // ErrorTf displays a formatted translated error message in red and exits the program
@@ -248,7 +256,8 @@ func ErrorTf(format string, args ...interface{}) {
 	if len(args) > 0 {
 		translated = fmt.Sprintf(translated, args...)
 	}
-	fmt.Fprintln(os.Stderr, "\033[91m"+translated+"\033[0m")
+	colorPrintln(os.Stderr, "\033[91m", statusPrefix(SymbolFailure)+translated)
+	FlushDownloadLedger()
 	os.Exit(1)
 }
 
@@ -258,7 +267,7 @@ func ErrorNoExitTf(format string, args ...interface{}) {
 	if len(args) > 0 {
 		translated = fmt.Sprintf(translated, args...)
 	}
-	fmt.Fprintln(os.Stderr, "\033[91m"+translated+"\033[0m")
+	colorPrintln(os.Stderr, "\033[91m", statusPrefix(SymbolFailure)+translated)
 }
 
 // DebugTf translates a formatted debug message when debug mode is enabled
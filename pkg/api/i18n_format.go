@@ -0,0 +1,213 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: i18n_format.go
+// Description: Locale-aware date and size formatting, full translatable texts for queue actions and the pseudo-locale used to spot untranslated strings.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package api
+
+import (
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// pseudoLocalization is set with PI_APPS_PSEUDOLOC=1, every translated string is then wrapped in brackets
+// so strings that never go through T stand out in the GUI
+var pseudoLocalization = os.Getenv("PI_APPS_PSEUDOLOC") == "1"
+
+// pseudoLocalize wraps a translated string in brackets when the pseudo-locale is enabled
+func pseudoLocalize(s string) string {
+	if !pseudoLocalization || s == "" {
+		return s
+	}
+	return "[" + s + "]"
+}
+
+// shortDateLayouts are the short date layouts of locales and languages, ISO 8601 is used for the rest
+var shortDateLayouts = map[string]string{
+	"en_US": "01/02/2006",
+	"en_CA": "2006-01-02",
+	"en":    "02/01/2006",
+	"es":    "02/01/2006",
+	"fr":    "02/01/2006",
+	"it":    "02/01/2006",
+	"pt":    "02/01/2006",
+	"ar":    "02/01/2006",
+	"he":    "02/01/2006",
+	"nl":    "02-01-2006",
+	"de":    "02.01.2006",
+	"pl":    "02.01.2006",
+	"cs":    "02.01.2006",
+	"ru":    "02.01.2006",
+	"uk":    "02.01.2006",
+	"fi":    "02.01.2006",
+	"nb":    "02.01.2006",
+	"tr":    "02.01.2006",
+	"hu":    "2006. 01. 02.",
+	"ko":    "2006. 01. 02.",
+	"ja":    "2006/01/02",
+	"zh":    "2006/01/02",
+}
+
+// twelveHourLocales are the locales that use a 12-hour clock
+var twelveHourLocales = map[string]bool{
+	"en_US": true,
+	"en_CA": true,
+	"en_AU": true,
+	"en_IN": true,
+	"en_PH": true,
+}
+
+// formatLocale returns the locale used for formatting, e.g. pl_PL
+func formatLocale() string {
+	if currentLocale != "" {
+		return currentLocale
+	}
+	return detectLocale()
+}
+
+// formatLanguage returns the language tag of the formatting locale
+func formatLanguage() language.Tag {
+	tag, err := language.Parse(strings.ReplaceAll(formatLocale(), "_", "-"))
+	if err != nil {
+		return language.AmericanEnglish
+	}
+	return tag
+}
+
+// FormatDate formats a date in the short date format of the current locale (e.g. 01/02/2006 for en_US, 02.01.2006 for pl)
+func FormatDate(t time.Time) string {
+	locale := formatLocale()
+	if layout, ok := shortDateLayouts[locale]; ok {
+		return t.Format(layout)
+	}
+	lang, _, _ := strings.Cut(locale, "_")
+	if layout, ok := shortDateLayouts[lang]; ok {
+		return t.Format(layout)
+	}
+	return t.Format("2006-01-02")
+}
+
+// FormatTime formats a time of day with the 12 or 24-hour clock of the current locale
+func FormatTime(t time.Time) string {
+	if twelveHourLocales[formatLocale()] {
+		return t.Format("3:04 PM")
+	}
+	return t.Format("15:04")
+}
+
+// FormatSize formats a number of bytes in binary units with the number formatting of the current locale (e.g. 1,5 MiB for pl)
+func FormatSize(bytes uint64) string {
+	printer := message.NewPrinter(formatLanguage())
+	const unit = 1024
+	if bytes < unit {
+		return printer.Sprintf("%d B", bytes)
+	}
+	div, exp := uint64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return printer.Sprintf("%.1f %ciB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
+
+// ActionWaitingText returns the translated text of a queued action that has not started yet, e.g. "Will install"
+func ActionWaitingText(action string) string {
+	switch action {
+	case "install":
+		return T("Will install")
+	case "uninstall":
+		return T("Will uninstall")
+	case "update":
+		return T("Will update")
+	case "refresh":
+		return T("Will refresh")
+	case "update-file":
+		return T("Will update file")
+	}
+	return Tf("Will %s", action)
+}
+
+// ActionProgressText returns the translated text of an action in progress, e.g. "Installing..."
+func ActionProgressText(action string) string {
+	switch action {
+	case "install":
+		return T("Installing...")
+	case "uninstall":
+		return T("Uninstalling...")
+	case "update":
+		return T("Updating...")
+	case "refresh":
+		return T("Refreshing...")
+	case "update-file":
+		return T("Updating file...")
+	}
+	return Tf("%s...", action)
+}
+
+// ActionDoneText returns the translated text of an action that succeeded, e.g. "Installed"
+func ActionDoneText(action string) string {
+	switch action {
+	case "install":
+		return T("Installed")
+	case "uninstall":
+		return T("Uninstalled")
+	case "update":
+		return T("Updated")
+	case "refresh":
+		return T("Refreshed")
+	case "update-file":
+		return T("File updated")
+	}
+	return Tf("%s succeeded", action)
+}
+
+// ActionFailedText returns the translated text of an action that failed, e.g. "Install failed"
+func ActionFailedText(action string) string {
+	switch action {
+	case "install":
+		return T("Install failed")
+	case "uninstall":
+		return T("Uninstall failed")
+	case "update":
+		return T("Update failed")
+	case "refresh":
+		return T("Refresh failed")
+	case "update-file":
+		return T("File update failed")
+	}
+	return Tf("%s failed", action)
+}
+
+// ActionErrorTitle returns the translated title of the error shown for the failure-th of failures failed actions
+func ActionErrorTitle(action, app string, failure, failures int) string {
+	switch action {
+	case "install":
+		return Tf("Error occurred when installing %s (%d/%d)", app, failure, failures)
+	case "uninstall":
+		return Tf("Error occurred when uninstalling %s (%d/%d)", app, failure, failures)
+	case "update":
+		return Tf("Error occurred when updating %s (%d/%d)", app, failure, failures)
+	case "refresh":
+		return Tf("Error occurred when refreshing %s (%d/%d)", app, failure, failures)
+	}
+	return Tf("Error occurred when running %s on %s (%d/%d)", action, app, failure, failures)
+}
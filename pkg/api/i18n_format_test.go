@@ -0,0 +1,123 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"testing"
+	"time"
+)
+
+// setTestLocale makes the formatting helpers use locale until the test ends
+func setTestLocale(t *testing.T, locale string) {
+	t.Helper()
+	previous := currentLocale
+	currentLocale = locale
+	t.Cleanup(func() { currentLocale = previous })
+}
+
+func TestFormatDate(t *testing.T) {
+	date := time.Date(2026, time.March, 7, 15, 4, 0, 0, time.UTC)
+	tests := map[string]string{
+		"en_US": "03/07/2026",
+		"en_GB": "07/03/2026",
+		"de_DE": "07.03.2026",
+		"nl_NL": "07-03-2026",
+		"ja_JP": "2026/03/07",
+		"hu_HU": "2026. 03. 07.",
+		"ar_EG": "07/03/2026",
+		"sv_SE": "2026-03-07", // no layout, ISO 8601
+	}
+	for locale, want := range tests {
+		setTestLocale(t, locale)
+		if got := FormatDate(date); got != want {
+			t.Errorf("FormatDate in %s = %q, want %q", locale, got, want)
+		}
+	}
+}
+
+func TestFormatTime(t *testing.T) {
+	clock := time.Date(2026, time.March, 7, 15, 4, 0, 0, time.UTC)
+	setTestLocale(t, "en_US")
+	if got := FormatTime(clock); got != "3:04 PM" {
+		t.Errorf("FormatTime in en_US = %q, want 3:04 PM", got)
+	}
+	setTestLocale(t, "fr_FR")
+	if got := FormatTime(clock); got != "15:04" {
+		t.Errorf("FormatTime in fr_FR = %q, want 15:04", got)
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	tests := []struct {
+		locale string
+		bytes  uint64
+		want   string
+	}{
+		{"en_US", 512, "512 B"},
+		{"en_US", 1536, "1.5 KiB"},
+		{"en_US", 5 << 30, "5.0 GiB"},
+		{"de_DE", 1536 << 10, "1,5 MiB"},
+		{"pl_PL", 1536 << 10, "1,5 MiB"},
+		{"invalid locale", 1536, "1.5 KiB"},
+	}
+	for _, tt := range tests {
+		setTestLocale(t, tt.locale)
+		if got := FormatSize(tt.bytes); got != tt.want {
+			t.Errorf("FormatSize(%d) in %s = %q, want %q", tt.bytes, tt.locale, got, tt.want)
+		}
+	}
+}
+
+func TestPseudoLocalization(t *testing.T) {
+	previous := pseudoLocalization
+	t.Cleanup(func() { pseudoLocalization = previous })
+
+	pseudoLocalization = false
+	if got := T("Installed"); got != "Installed" {
+		t.Errorf("T without the pseudo-locale = %q", got)
+	}
+
+	pseudoLocalization = true
+	if got := T("Installed"); got != "[Installed]" {
+		t.Errorf("T with the pseudo-locale = %q, want [Installed]", got)
+	}
+	// The arguments of a template are not wrapped, only the translated template is
+	if got := Tf("Error occurred when installing %s (%d/%d)", "Zoom", 1, 2); got != "[Error occurred when installing Zoom (1/2)]" {
+		t.Errorf("Tf with the pseudo-locale = %q", got)
+	}
+	if got := pseudoLocalize(""); got != "" {
+		t.Errorf("pseudoLocalize(\"\") = %q, want an empty string", got)
+	}
+}
+
+func TestActionTexts(t *testing.T) {
+	for _, action := range []string{"install", "uninstall", "update", "refresh", "update-file"} {
+		for name, text := range map[string]string{
+			"waiting":  ActionWaitingText(action),
+			"progress": ActionProgressText(action),
+			"done":     ActionDoneText(action),
+			"failed":   ActionFailedText(action),
+		} {
+			if text == "" {
+				t.Errorf("the %s text of %s is empty", name, action)
+			}
+		}
+	}
+	if got := ActionErrorTitle("install", "Zoom", 1, 3); got != "Error occurred when installing Zoom (1/3)" {
+		t.Errorf("ActionErrorTitle = %q", got)
+	}
+}
@@ -0,0 +1,309 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: import_app_gui.go
+// Description: Provides the GTK wizard for importing apps.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+//go:build !nogui
+
+package api
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gotk3/gotk3/gdk"
+	"github.com/gotk3/gotk3/glib"
+	"github.com/gotk3/gotk3/gtk"
+)
+
+// ImportAppGUI provides a graphical interface for importing apps in Pi-Apps Go
+func ImportAppGUI() error {
+	if err := CheckKioskFeature(KioskImportApp); err != nil {
+		return err
+	}
+
+	// Set program name
+	glib.SetPrgname("Import App Wizard")
+
+	// Initialize GTK
+	gtk.Init(nil)
+
+	// Get PI_APPS_DIR environment variable
+	piAppsDir := GetPiAppsDir()
+	if piAppsDir == "" {
+		return fmt.Errorf("PI_APPS_DIR environment variable not set")
+	}
+
+	// Create the dialog window
+	window, err := gtk.WindowNew(gtk.WINDOW_TOPLEVEL)
+	if err != nil {
+		return fmt.Errorf("error creating window: %w", err)
+	}
+	window.SetTitle("App Importer")
+	window.SetDefaultSize(500, 300)
+	window.SetPosition(gtk.WIN_POS_CENTER)
+
+	// Set window icon
+	iconPath := filepath.Join(piAppsDir, "icons", "settings.png")
+	if FileExists(iconPath) {
+		if pixbuf, err := gdk.PixbufNewFromFile(iconPath); err == nil {
+			window.SetIcon(pixbuf)
+		}
+	}
+
+	// Create a vertical box to hold the widgets
+	vbox, err := gtk.BoxNew(gtk.ORIENTATION_VERTICAL, 5)
+	if err != nil {
+		return fmt.Errorf("error creating vbox: %w", err)
+	}
+	vbox.SetMarginStart(10)
+	vbox.SetMarginEnd(10)
+	vbox.SetMarginTop(10)
+	vbox.SetMarginBottom(10)
+	window.Add(vbox)
+
+	// Create a label with instructions
+	label, err := gtk.LabelNew("")
+	if err != nil {
+		return fmt.Errorf("error creating label: %w", err)
+	}
+	account, repo := GetGitUrl()
+	if account == "" || repo == "" {
+		label.SetMarkup(fmt.Sprintf("Import an app from somewhere else.\nApps are saved in <b>%s/apps</b>.\nPut something in the blank below.\nExamples:\n\n    <b>https://github.com/pi-apps-go/pi-apps-go/pull/1068</b>\n    <b>1068</b>\n    <b>https://link/to/app.zip</b>\n    <b>$HOME/my-app.zip</b>", piAppsDir))
+	} else {
+		label.SetMarkup(fmt.Sprintf("Import an app from somewhere else.\nApps are saved in <b>%s/apps</b>.\nPut something in the blank below.\nExamples:\n\n    <b>https://github.com/%s/%s/pull/1068</b>\n    <b>1068</b>\n    <b>https://link/to/app.zip</b>\n    <b>$HOME/my-app.zip</b>", piAppsDir, account, repo))
+	}
+	label.SetHAlign(gtk.ALIGN_START)
+	vbox.PackStart(label, false, false, 5)
+
+	// Create an entry for the import source
+	entry, err := gtk.EntryNew()
+	if err != nil {
+		return fmt.Errorf("error creating entry: %w", err)
+	}
+	vbox.PackStart(entry, false, false, 5)
+
+	// Create a button box
+	buttonBox, err := gtk.ButtonBoxNew(gtk.ORIENTATION_HORIZONTAL)
+	if err != nil {
+		return fmt.Errorf("error creating button box: %w", err)
+	}
+	buttonBox.SetLayout(gtk.BUTTONBOX_END)
+	buttonBox.SetSpacing(8)
+	vbox.PackEnd(buttonBox, false, false, 0)
+
+	// Add Import button
+	importButton, err := gtk.ButtonNewWithLabel("Import")
+	if err != nil {
+		return fmt.Errorf("error creating import button: %w", err)
+	}
+	buttonBox.Add(importButton)
+
+	// Add Cancel button
+	cancelButton, err := gtk.ButtonNewWithLabel("Cancel")
+	if err != nil {
+		return fmt.Errorf("error creating cancel button: %w", err)
+	}
+	buttonBox.Add(cancelButton)
+
+	// Connect signals
+	importButton.Connect("clicked", func() {
+		importSource, err := entry.GetText()
+		if err != nil {
+			DialogError("Error getting import source: " + err.Error())
+			return
+		}
+
+		if importSource == "" {
+			DialogError("Please enter an import source")
+			return
+		}
+
+		// Handle the import
+		importedApps, err := handleImport(importSource, piAppsDir)
+		if err != nil {
+			DialogError("Error importing app: " + err.Error())
+			return
+		}
+
+		if len(importedApps) == 0 {
+			DialogError("No apps were imported")
+			return
+		}
+
+		// Show success dialog with imported apps
+		showImportSuccessDialog(importedApps, piAppsDir)
+		window.Close()
+	})
+
+	cancelButton.Connect("clicked", func() {
+		window.Close()
+	})
+
+	window.Connect("destroy", func() {
+		gtk.MainQuit()
+	})
+
+	window.ShowAll()
+	gtk.Main()
+
+	return nil
+}
+
+// showImportSuccessDialog displays a dialog showing the successfully imported apps
+func showImportSuccessDialog(apps []string, piAppsDir string) {
+	// Create dialog
+	dialog, err := gtk.DialogNew()
+	if err != nil {
+		DialogError("Error creating dialog: " + err.Error())
+		return
+	}
+	defer dialog.Destroy()
+
+	dialog.SetTitle("App Importer")
+	dialog.SetDefaultSize(310, 200)
+	dialog.SetPosition(gtk.WIN_POS_CENTER)
+
+	// Set icon
+	iconPath := filepath.Join(piAppsDir, "icons", "settings.png")
+	if FileExists(iconPath) {
+		if pixbuf, err := gdk.PixbufNewFromFile(iconPath); err == nil {
+			dialog.SetIcon(pixbuf)
+		}
+	}
+
+	// Create content area
+	contentArea, err := dialog.GetContentArea()
+	if err != nil {
+		DialogError("Error getting content area: " + err.Error())
+		return
+	}
+
+	// Add text summary
+	summaryText := fmt.Sprintf("These apps have been imported:\n%s", strings.Join(apps, "\n"))
+	summaryLabel, err := gtk.LabelNew(summaryText)
+	if err != nil {
+		DialogError("Error creating summary label: " + err.Error())
+		return
+	}
+	summaryLabel.SetHAlign(gtk.ALIGN_START)
+	contentArea.Add(summaryLabel)
+
+	// Create list store for apps
+	listStore, err := gtk.ListStoreNew(gdk.PixbufGetType(), glib.TYPE_STRING)
+	if err != nil {
+		DialogError("Error creating list store: " + err.Error())
+		return
+	}
+
+	// Create tree view
+	treeView, err := gtk.TreeViewNewWithModel(listStore)
+	if err != nil {
+		DialogError("Error creating tree view: " + err.Error())
+		return
+	}
+	treeView.SetHeadersVisible(false)
+
+	// Add columns
+	iconRenderer, err := gtk.CellRendererPixbufNew()
+	if err != nil {
+		DialogError("Error creating icon renderer: " + err.Error())
+		return
+	}
+	iconColumn, err := gtk.TreeViewColumnNew()
+	if err != nil {
+		DialogError("Error creating icon column: " + err.Error())
+		return
+	}
+	iconColumn.PackStart(iconRenderer, false)
+	iconColumn.AddAttribute(iconRenderer, "pixbuf", 0)
+	treeView.AppendColumn(iconColumn)
+
+	nameRenderer, err := gtk.CellRendererTextNew()
+	if err != nil {
+		DialogError("Error creating name renderer: " + err.Error())
+		return
+	}
+	nameColumn, err := gtk.TreeViewColumnNew()
+	if err != nil {
+		DialogError("Error creating name column: " + err.Error())
+		return
+	}
+	nameColumn.PackStart(nameRenderer, true)
+	nameColumn.AddAttribute(nameRenderer, "text", 1)
+	treeView.AppendColumn(nameColumn)
+
+	// Add apps to list store
+	for _, app := range apps {
+		iter := listStore.Append()
+		var icon *gdk.Pixbuf
+		iconPath := filepath.Join(piAppsDir, "apps", app, "icon-24.png")
+		if FileExists(iconPath) {
+			icon, _ = gdk.PixbufNewFromFile(iconPath)
+		} else {
+			icon, _ = gdk.PixbufNewFromFile(filepath.Join(piAppsDir, "icons", "none.png"))
+		}
+		listStore.SetValue(iter, 0, icon)
+		listStore.SetValue(iter, 1, app)
+
+		// Add to Imported category if not already categorized
+		categoriesFile := filepath.Join(piAppsDir, "etc", "categories")
+		overridesFile := filepath.Join(DataDirOf(piAppsDir), "category-overrides")
+
+		// Check if app is already in categories
+		inCategories := false
+		if FileExists(categoriesFile) {
+			content, _ := os.ReadFile(categoriesFile)
+			inCategories = strings.Contains(string(content), app)
+		}
+
+		// Check if app is already in overrides
+		inOverrides := false
+		if FileExists(overridesFile) {
+			content, _ := os.ReadFile(overridesFile)
+			inOverrides = strings.Contains(string(content), app)
+		}
+
+		// Add to overrides if not in either file
+		if !inCategories && !inOverrides {
+			f, err := os.OpenFile(overridesFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+			if err == nil {
+				f.WriteString(fmt.Sprintf("%s|Imported\n", app))
+				f.Close()
+			}
+		}
+	}
+
+	// Create scrolled window
+	scrolled, err := gtk.ScrolledWindowNew(nil, nil)
+	if err != nil {
+		DialogError("Error creating scrolled window: " + err.Error())
+		return
+	}
+	scrolled.SetPolicy(gtk.POLICY_AUTOMATIC, gtk.POLICY_AUTOMATIC)
+	scrolled.Add(treeView)
+	contentArea.Add(scrolled)
+
+	// Add close button
+	dialog.AddButton("Close", gtk.RESPONSE_CLOSE)
+
+	dialog.ShowAll()
+	dialog.Run()
+}
@@ -29,6 +29,8 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+
+	"github.com/pi-apps-go/pi-apps/pkg/api/migrations"
 )
 
 var (
@@ -68,8 +70,13 @@ var (
 //
 // It should be called automatically in Pi-Apps Go related programs but in other cases (like when using the API in a Go program) it is required to call this function manually.
 func Init() {
+	// Keep the environment Pi-Apps was started with, to run it again as the original user if it was started with sudo
+	if startEnviron == nil {
+		startEnviron = os.Environ()
+	}
+
 	// Initialize Pi-Apps directory
-	initPiAppsDir()
+	resolution := initPiAppsDir()
 
 	// Set GTK theme for GUI components
 	initGUITheme()
@@ -95,6 +102,18 @@ func Init() {
 		WarningT("failed to initialize API i18n: %v\n", err)
 	}
 
+	// Warn when app scripts can't run from the Pi-Apps directory, like on a noexec USB drive
+	warnPiAppsDirMount()
+
+	// Warn when another copy of Pi-Apps would make programs disagree on the installed apps
+	warnDuplicatePiAppsDirs(resolution)
+
+	// Bring local data written by older versions of Pi-Apps up to date, unless GuardRoot is going to stop
+	// this process: files migrated as root would end up owned by root
+	if decideRootAction(os.Geteuid(), os.Getuid(), os.Getenv) == rootProceed {
+		runMigrations()
+	}
+
 	// Initialize lsb_release variables
 
 	lsb := LoadLSBOSRelease()
@@ -117,109 +136,51 @@ func init() {
 	Init()
 }
 
-// initPiAppsDir determines and sets the Pi-Apps directory location
-// This function implements the same safety checks as the original Bash install script
-// to prevent users from accidentally installing Pi-Apps into their HOME directory
-// or other user directories (like Downloads), which could lead to data loss.
-// See: https://github.com/Botspot/pi-apps/issues/2137
-func initPiAppsDir() {
-	// Check if PI_APPS_DIR environment variable is already set
-	piappsDir := GetPiAppsDir()
-	if piappsDir != "" {
-		PIAppsDir = piappsDir
+// runMigrations applies the pending migrations to the local data of the Pi-Apps directory.
+// A failed migration is only warned about, it runs again the next time Pi-Apps starts.
+func runMigrations() {
+	if PIAppsDir == "" || !DirExists(DataDirOf(PIAppsDir)) {
 		return
 	}
-
-	// Try to determine the directory based on the executable location
-	// This approach mimics the bash script behavior: DIRECTORY="$(readlink -f "$(dirname "$0")")"
-	exePath, err := os.Executable()
-	if err == nil {
-		exeDir := filepath.Dir(exePath)
-		// If this is being run from the pi-apps/go-rewrite directory,
-		// go up one level to get the pi-apps directory
-		// this is for development purposes only
-		if strings.HasSuffix(exeDir, "/pi-apps-go/bin") {
-			PIAppsDir = filepath.Dir(filepath.Dir(exeDir))
-		} else {
-			// Otherwise assume the current directory is the pi-apps directory
-			PIAppsDir = exeDir
-		}
-	}
-
-	// If we still don't have a valid directory, use the default
-	// The isValidPiAppsDir check will reject HOME and common user directories,
-	// ensuring we fall back to $HOME/pi-apps instead of using a dangerous location
-	if PIAppsDir == "" || !isValidPiAppsDir(PIAppsDir) {
-		homeDir, _ := os.UserHomeDir()
-		PIAppsDir = filepath.Join(homeDir, "pi-apps")
+	migrations.DataDir = DataDirOf
+	migrations.RegenerateAppIcons = func(dir string) error {
+		_, err := regenerateAppIcons(dir, AppIconSizes, false, nil)
+		return err
 	}
-
-	// Set PI_APPS_DIR environment variable
-	os.Setenv("PI_APPS_DIR", PIAppsDir)
-}
-
-// isValidPiAppsDir checks if a directory is a valid Pi-Apps directory
-// This function implements the same safety checks as the original Bash install script
-// to prevent users from accidentally installing Pi-Apps into their HOME directory
-// or other user directories (like Downloads), which could lead to data loss.
-func isValidPiAppsDir(dir string) bool {
-	if dir == "" {
-		return false
+	applied, err := migrations.Run(PIAppsDir)
+	for _, migration := range applied {
+		Debug(fmt.Sprintf("Applied migration %d: %s", migration.Version, migration.Description))
 	}
-
-	// Get the absolute path to avoid issues with symlinks and relative paths
-	absDir, err := filepath.Abs(dir)
 	if err != nil {
-		return false
+		WarningTf("Failed to migrate the Pi-Apps data: %v", err)
 	}
+}
 
-	// Get HOME directory for comparison
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		// If we can't get HOME, we can't validate, so reject for safety
-		return false
+// initPiAppsDir determines and sets the Pi-Apps directory location, see GetPiAppsDir.
+// The directory is exported as PI_APPS_DIR, so the programs and scripts Pi-Apps runs use the same one.
+func initPiAppsDir() DirResolution {
+	resolution := ResolvePiAppsDir()
+	if startPiAppsDir == nil {
+		startPiAppsDir = &resolution
 	}
-	absHomeDir, err := filepath.Abs(homeDir)
-	if err != nil {
-		return false
-	}
-
-	// CRITICAL SAFETY CHECK: Reject if the directory is exactly the HOME directory
-	// This prevents the issue described in https://github.com/Botspot/pi-apps/issues/2137
-	// where users accidentally installed Pi-Apps into their Downloads folder
-	if absDir == absHomeDir {
-		return false
+	PIAppsDir = resolution.Dir
+	os.Setenv("PI_APPS_DIR", PIAppsDir)
+	// A Pi-Apps directory shared by the users of the system keeps the data and logs of this user in UserStateDir
+	if err := initUserStateDir(PIAppsDir); err != nil {
+		WarningTf("Failed to set up the data folder of this user: %v", err)
 	}
+	return resolution
+}
 
-	// Reject common user directories that should never be used as Pi-Apps directory
-	// These are directories where users typically store personal files
-	// NOTE: This only rejects the directories themselves (e.g., /home/user/Videos),
-	// NOT subdirectories within them (e.g., /home/user/Videos/pi-apps is allowed).
-	// This matches the original Bash script's behavior of only rejecting $HOME itself.
-	commonUserDirs := []string{
-		"Downloads",
-		"Documents",
-		"Desktop",
-		"Pictures",
-		"Videos",
-		"Music",
-		"Public",
-		"Templates",
+// warnDuplicatePiAppsDirs warns loudly when Pi-Apps was found without PI_APPS_DIR or DIRECTORY and other
+// copies of it exist: programs started from another copy would use other app statuses
+func warnDuplicatePiAppsDirs(resolution DirResolution) {
+	if resolution.Source != DirSourceExecutable && resolution.Source != DirSourceHome {
+		return
 	}
-	for _, userDir := range commonUserDirs {
-		userDirPath := filepath.Join(absHomeDir, userDir)
-		// Only reject if the directory is exactly one of these user directories
-		// Subdirectories (like /home/user/Videos/pi-apps) are allowed
-		if absDir == userDirPath {
-			return false
-		}
+	for _, duplicate := range resolution.Duplicates {
+		WarningTf("Pi-Apps is installed both in %s and in %s. Programs started from one of them don't see the apps installed with the other, remove the copy you don't use.", resolution.Dir, duplicate)
 	}
-
-	// Check if the directory exists and has the expected files
-	// This matches the original Bash script's check: [ ! -f "${DIRECTORY}/api" ] || [ ! -f "${DIRECTORY}/gui" ]
-	apiFile := filepath.Join(absDir, "api")
-	guiFile := filepath.Join(absDir, "gui")
-	return DirExists(absDir) && FileExists(apiFile) && FileExists(guiFile)
 }
 
 // initGUITheme sets the GTK theme for GUI components based on the App List Style setting
@@ -229,15 +190,7 @@ func initGUITheme() {
 	}
 
 	// Read the App List Style setting
-	settingsFile := filepath.Join(PIAppsDir, "data", "settings", "App List Style")
-	guiMode := "default"
-
-	if FileExists(settingsFile) {
-		content, err := os.ReadFile(settingsFile)
-		if err == nil {
-			guiMode = strings.TrimSpace(string(content))
-		}
-	}
+	guiMode := ReadSettingIn(PIAppsDir, "App List Style")
 
 	// Set the GTK theme based on the setting
 	// This is now more generic and not YAD-specific
@@ -556,37 +509,3 @@ func addUsrLocalBinToPath() {
 		os.Setenv("PATH", newPath)
 	}
 }
-
-// GetPiAppsDir returns the Pi-Apps directory path
-func GetPiAppsDir() string {
-	// First check if the environment variable is set
-	if dir := os.Getenv("PI_APPS_DIR"); dir != "" && isValidPiAppsDir(dir) {
-		return dir
-	}
-
-	// Check the pi-apps directory path (old folder name for Bash based implementation)
-	piAppsPath := filepath.Join(os.Getenv("HOME"), "pi-apps")
-	if isValidPiAppsDir(piAppsPath) {
-		return piAppsPath
-	}
-
-	// Check the pi-apps-go directory path (new folder name for Go based implementation)
-	piAppsPath = filepath.Join(os.Getenv("HOME"), "pi-apps-go")
-	if isValidPiAppsDir(piAppsPath) {
-		return piAppsPath
-	}
-
-	// Fall back to the original path
-	return PIAppsDir
-}
-
-// SetPiAppsDir allows manually setting the Pi-Apps directory
-// This is useful for testing or when the detection mechanism doesn't work
-func SetPiAppsDir(dir string) {
-	if dir != "" && isValidPiAppsDir(dir) {
-		PIAppsDir = dir
-		os.Setenv("PI_APPS_DIR", dir)
-	} else {
-		Warning(fmt.Sprintf("Failed to set Pi-Apps directory to %s (not a valid directory)\n", dir))
-	}
-}
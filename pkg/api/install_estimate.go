@@ -0,0 +1,181 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: install_estimate.go
+// Description: Estimates how long an app takes to install on this device, from earlier installs or the bundled durations scaled to the device's speed.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package api
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Confidence of an install duration estimate
+const (
+	EstimateConfidenceHigh   = "high"   // at least 3 earlier installs of the app on this device
+	EstimateConfidenceMedium = "medium" // 1 or 2 earlier installs of the app on this device
+	EstimateConfidenceLow    = "low"    // the bundled duration, scaled to the speed of this device
+)
+
+const (
+	// installHistorySamples is the number of recent installs of an app the estimate is the median of
+	installHistorySamples = 5
+
+	// referenceCores and referenceBenchmark describe the device the bundled durations are measured on,
+	// a Raspberry Pi 4 with 4 cores that takes about this long for deviceBenchmark
+	referenceCores     = 4
+	referenceBenchmark = 250 * time.Millisecond
+)
+
+// recordInstallDuration adds a successful install of an app to the history log
+func recordInstallDuration(app string, duration time.Duration) error {
+	return recordHistory(app, historyInstall, strconv.FormatInt(int64(duration.Round(time.Second)/time.Second), 10))
+}
+
+// installHistory returns the durations of the successful installs of an app on this device, oldest first
+func installHistory(app string) []time.Duration {
+	var durations []time.Duration
+	for _, entry := range readHistory(app, historyInstall) {
+		if len(entry.Details) == 0 {
+			continue
+		}
+		if seconds, err := strconv.ParseInt(entry.Details[0], 10, 64); err == nil && seconds >= 0 {
+			durations = append(durations, time.Duration(seconds)*time.Second)
+		}
+	}
+	return durations
+}
+
+// bundledInstallDurations reads etc/install-durations, the median install durations of the apps on the reference
+// device. The file is generated from the install analytics and has "<app>\t<seconds>" lines.
+var bundledInstallDurations = sync.OnceValue(func() map[string]time.Duration {
+	durations := make(map[string]time.Duration)
+	file, err := os.Open(filepath.Join(GetPiAppsDir(), "etc", "install-durations"))
+	if err != nil {
+		return durations
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		app, seconds, ok := strings.Cut(line, "\t")
+		if !ok {
+			continue
+		}
+		if value, err := strconv.ParseInt(strings.TrimSpace(seconds), 10, 64); err == nil && value > 0 {
+			durations[app] = time.Duration(value) * time.Second
+		}
+	}
+	return durations
+})
+
+// deviceBenchmark times a fixed amount of hashing, which is quick everywhere but tells a slow ARM board from a desktop
+func deviceBenchmark() time.Duration {
+	data := make([]byte, 1<<20)
+	best := time.Duration(0)
+	// The best of a few runs is less affected by whatever else the device is doing
+	for range 3 {
+		start := time.Now()
+		for range 16 {
+			sha256.Sum256(data)
+		}
+		if elapsed := time.Since(start); best == 0 || elapsed < best {
+			best = elapsed
+		}
+	}
+	return best
+}
+
+// deviceSpeedFactor is how much longer than the reference device this device takes to install an app.
+// The benchmark runs once and is cached in data/cache/device-speed as "<nanoseconds> <cores>".
+var deviceSpeedFactor = sync.OnceValue(func() float64 {
+	cores := runtime.NumCPU()
+	cachePath := filepath.Join(GetDataDir(), "cache", "device-speed")
+
+	var benchmark time.Duration
+	if data, err := os.ReadFile(cachePath); err == nil {
+		fields := strings.Fields(string(data))
+		if len(fields) == 2 && fields[1] == strconv.Itoa(cores) {
+			if nanoseconds, err := strconv.ParseInt(fields[0], 10, 64); err == nil && nanoseconds > 0 {
+				benchmark = time.Duration(nanoseconds)
+			}
+		}
+	}
+	if benchmark == 0 {
+		benchmark = deviceBenchmark()
+		if err := EnsureDirOwned(filepath.Dir(cachePath), InvokingUser, InvokingUser); err == nil {
+			os.WriteFile(cachePath, []byte(fmt.Sprintf("%d %d\n", benchmark.Nanoseconds(), cores)), 0644)
+		}
+	}
+
+	// Downloads and single-threaded steps don't get faster with more cores, so only half of an install is
+	// assumed to scale with them
+	factor := float64(benchmark) / float64(referenceBenchmark) * (0.5 + 0.5*float64(referenceCores)/float64(cores))
+	return min(max(factor, 0.1), 10)
+})
+
+// EstimateInstallDuration estimates how long installing an app takes on this device. It prefers the median of the
+// recent installs of the app on this device and falls back to the bundled duration scaled to the device's speed.
+//
+//	time.Duration - the estimate, 0 if there is nothing to base it on
+//	string - EstimateConfidenceHigh, EstimateConfidenceMedium, EstimateConfidenceLow or "" without estimate
+func EstimateInstallDuration(app string) (time.Duration, string) {
+	if history := installHistory(app); len(history) > 0 {
+		if len(history) > installHistorySamples {
+			history = history[len(history)-installHistorySamples:]
+		}
+		slices.Sort(history)
+		confidence := EstimateConfidenceMedium
+		if len(history) >= 3 {
+			confidence = EstimateConfidenceHigh
+		}
+		return history[len(history)/2], confidence
+	}
+	if duration, ok := bundledInstallDurations()[app]; ok {
+		return time.Duration(float64(duration) * deviceSpeedFactor()).Round(time.Second), EstimateConfidenceLow
+	}
+	return 0, ""
+}
+
+// FormatApproxDuration formats an estimated duration the way it is shown to the user, like "~6 minutes"
+func FormatApproxDuration(duration time.Duration) string {
+	minutes := int(duration.Round(time.Minute) / time.Minute)
+	switch {
+	case duration < time.Minute:
+		return T("less than a minute")
+	case minutes == 1:
+		return T("~1 minute")
+	case minutes < 90:
+		return Tf("~%d minutes", minutes)
+	default:
+		return Tf("~%.1f hours", duration.Hours())
+	}
+}
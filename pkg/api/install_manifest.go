@@ -0,0 +1,275 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: install_manifest.go
+// Description: Reads and writes the install manifests recording what an app installed and how its install script ran.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package api
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+const (
+	// installLimitsPrefix starts the install manifest line recording the resource limits the install script ran with
+	installLimitsPrefix = "# limits: "
+	// installRanAsRootLine is the install manifest line recording that the install script ran as root,
+	// which leaves the files it created in the user's home owned by root
+	installRanAsRootLine = "# ran as root"
+	// installUpstreamVersionPrefix starts the install manifest line recording the installed upstream version,
+	// set by the install script with `api set_installed_version`
+	installUpstreamVersionPrefix = "# upstream version: "
+	// installComponentsPrefix starts the install manifest line recording which optional components were chosen,
+	// kept after an uninstall so the next install offers the same choice
+	installComponentsPrefix = "# components: "
+)
+
+// ReadInstalledFiles returns the files recorded in an app's install manifest (data/install-files/<app>)
+//
+//	[]string - installed files, empty if the app has no manifest
+//	error - error if the app name is not valid
+func ReadInstalledFiles(app string) ([]string, error) {
+	lines, err := readInstallManifest(app)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, line := range lines {
+		if !strings.HasPrefix(line, "#") {
+			files = append(files, line)
+		}
+	}
+	return files, nil
+}
+
+// ReadInstallLimits returns the resource limits recorded in an app's install manifest,
+// empty if the install script ran without limits
+func ReadInstallLimits(app string) (ResourceLimits, error) {
+	lines, err := readInstallManifest(app)
+	if err != nil {
+		return ResourceLimits{}, err
+	}
+	for _, line := range lines {
+		if limits, ok := strings.CutPrefix(line, installLimitsPrefix); ok {
+			return parseResourceLimits(limits), nil
+		}
+	}
+	return ResourceLimits{}, nil
+}
+
+// InstallRanAsRoot reports whether an app's install manifest records that its install script ran as root
+func InstallRanAsRoot(app string) (bool, error) {
+	lines, err := readInstallManifest(app)
+	if err != nil {
+		return false, err
+	}
+	return slices.Contains(lines, installRanAsRootLine), nil
+}
+
+// readInstallManifest returns the non-empty lines of an app's install manifest
+func readInstallManifest(app string) ([]string, error) {
+	path, err := AppDataPath("install-files", app)
+	if err != nil {
+		return nil, err
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var lines []string
+	for _, line := range strings.Split(string(content), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, nil
+}
+
+// writeInstalledFiles records the files an app installed, the resource limits its script ran with and
+// whether it ran as root in its install manifest, keeping the upstream version, chosen components, user services,
+// created directories and cached assets recorded before
+func writeInstalledFiles(app string, files []string, limits ResourceLimits, ranAsRoot bool) error {
+	path, err := AppDataPath("install-files", app)
+	if err != nil {
+		return err
+	}
+	upstreamVersion, err := ReadInstalledUpstreamVersion(app)
+	if err != nil {
+		return err
+	}
+	components, err := ReadInstalledComponents(app)
+	if err != nil {
+		return err
+	}
+	services, err := readUserServiceLines(app)
+	if err != nil {
+		return err
+	}
+	createdDirs, err := readCreatedDirLines(app)
+	if err != nil {
+		return err
+	}
+	cachedAssets, err := readCachedAssetLines(app)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 && limits.IsEmpty() && !ranAsRoot && upstreamVersion == "" && len(components) == 0 && len(services) == 0 && len(createdDirs) == 0 && len(cachedAssets) == 0 {
+		return removeInstalledFiles(app)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	var content strings.Builder
+	if upstreamVersion != "" {
+		content.WriteString(installUpstreamVersionPrefix + upstreamVersion + "\n")
+	}
+	if len(components) > 0 {
+		content.WriteString(installComponentsPrefix + formatInstalledComponents(components) + "\n")
+	}
+	if !limits.IsEmpty() {
+		content.WriteString(installLimitsPrefix + limits.String() + "\n")
+	}
+	if ranAsRoot {
+		content.WriteString(installRanAsRootLine + "\n")
+	}
+	for _, service := range services {
+		content.WriteString(service + "\n")
+	}
+	for _, dir := range createdDirs {
+		content.WriteString(dir + "\n")
+	}
+	for _, asset := range cachedAssets {
+		content.WriteString(asset + "\n")
+	}
+	for _, file := range files {
+		content.WriteString(file + "\n")
+	}
+	return os.WriteFile(path, []byte(content.String()), 0644)
+}
+
+// recordInstalledFile adds a file to an app's install manifest, keeping what the manifest records already
+func recordInstalledFile(app, file string) error {
+	lines, err := readInstallManifest(app)
+	if err != nil {
+		return err
+	}
+	if slices.Contains(lines, file) {
+		return nil
+	}
+	return writeInstallManifest(app, append(lines, file))
+}
+
+// forgetInstalledFile removes a file from an app's install manifest
+func forgetInstalledFile(app, file string) error {
+	lines, err := readInstallManifest(app)
+	if err != nil || !slices.Contains(lines, file) {
+		return err
+	}
+	return writeInstallManifest(app, slices.DeleteFunc(lines, func(line string) bool { return line == file }))
+}
+
+// writeInstallManifest replaces the lines of an app's install manifest
+func writeInstallManifest(app string, lines []string) error {
+	path, err := AppDataPath("install-files", app)
+	if err != nil {
+		return err
+	}
+	if len(lines) == 0 {
+		return removeInstalledFiles(app)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0644)
+}
+
+// removeInstalledFiles removes an app's install manifest
+func removeInstalledFiles(app string) error {
+	path, err := AppDataPath("install-files", app)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// clearInstallManifest empties an app's install manifest after it was uninstalled, only keeping the components
+// that were chosen for the next install and the created directories that were not empty yet
+func clearInstallManifest(app string) error {
+	components, err := ReadInstalledComponents(app)
+	if err != nil {
+		return err
+	}
+	lines, err := readCreatedDirLines(app)
+	if err != nil {
+		return err
+	}
+	if len(components) > 0 {
+		lines = append([]string{installComponentsPrefix + formatInstalledComponents(components)}, lines...)
+	}
+	return writeInstallManifest(app, lines)
+}
+
+// InstallManifest is what an app's install manifest records about its installation
+type InstallManifest struct {
+	UpstreamVersion string               `json:"upstream_version,omitempty"`
+	Limits          string               `json:"limits,omitempty"`
+	RanAsRoot       bool                 `json:"ran_as_root"`
+	Components      []InstalledComponent `json:"components"`
+	UserServices    []string             `json:"user_services"`
+	CreatedDirs     []string             `json:"created_dirs"`
+	CachedAssets    []string             `json:"cached_assets"`
+	Files           []string             `json:"files"`
+}
+
+// ReadInstallManifest returns what an app's install manifest (data/install-files/<app>) records, empty if the app
+// has no manifest
+func ReadInstallManifest(app string) (InstallManifest, error) {
+	lines, err := readInstallManifest(app)
+	if err != nil {
+		return InstallManifest{}, err
+	}
+	manifest := InstallManifest{Components: []InstalledComponent{}, UserServices: []string{}, CreatedDirs: []string{}, CachedAssets: []string{}, Files: []string{}}
+	for _, line := range lines {
+		if version, ok := strings.CutPrefix(line, installUpstreamVersionPrefix); ok {
+			manifest.UpstreamVersion = version
+		} else if limits, ok := strings.CutPrefix(line, installLimitsPrefix); ok {
+			manifest.Limits = parseResourceLimits(limits).String()
+		} else if line == installRanAsRootLine {
+			manifest.RanAsRoot = true
+		} else if components, ok := strings.CutPrefix(line, installComponentsPrefix); ok {
+			manifest.Components = parseInstalledComponents(components)
+		} else if service, ok := strings.CutPrefix(line, installUserServicePrefix); ok {
+			manifest.UserServices = append(manifest.UserServices, service)
+		} else if dir, ok := strings.CutPrefix(line, installCreatedDirPrefix); ok {
+			manifest.CreatedDirs = append(manifest.CreatedDirs, dir)
+		} else if asset, ok := strings.CutPrefix(line, installCachedAssetPrefix); ok {
+			manifest.CachedAssets = append(manifest.CachedAssets, asset)
+		} else if !strings.HasPrefix(line, "#") {
+			manifest.Files = append(manifest.Files, line)
+		}
+	}
+	return manifest, nil
+}
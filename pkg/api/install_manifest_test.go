@@ -0,0 +1,102 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"os"
+	"slices"
+	"testing"
+)
+
+func TestInstallManifestRoundTrip(t *testing.T) {
+	newTestPiAppsDir(t, "Zoom")
+	limits := ResourceLimits{CPUQuota: "200%", MemoryMax: "1G"}
+	if err := writeInstalledFiles("Zoom", []string{"/opt/zoom/zoom", "/usr/local/bin/zoom"}, limits, true); err != nil {
+		t.Fatal(err)
+	}
+
+	manifest, err := ReadInstallManifest("Zoom")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(manifest.Files, []string{"/opt/zoom/zoom", "/usr/local/bin/zoom"}) || manifest.Limits != limits.String() || !manifest.RanAsRoot {
+		t.Errorf("ReadInstallManifest = %+v, want the files, the limits and ran as root", manifest)
+	}
+	if files, err := ReadInstalledFiles("Zoom"); err != nil || !slices.Equal(files, manifest.Files) {
+		t.Errorf("ReadInstalledFiles = %q, %v, want only the files", files, err)
+	}
+	if got, err := ReadInstallLimits("Zoom"); err != nil || got != limits {
+		t.Errorf("ReadInstallLimits = %+v, %v, want %+v", got, err, limits)
+	}
+	if ranAsRoot, err := InstallRanAsRoot("Zoom"); err != nil || !ranAsRoot {
+		t.Errorf("InstallRanAsRoot = %v, %v, want true", ranAsRoot, err)
+	}
+
+	// Files added and removed later keep the rest of the manifest
+	if err := recordInstalledFile("Zoom", "/usr/share/applications/zoom.desktop"); err != nil {
+		t.Fatal(err)
+	}
+	if err := forgetInstalledFile("Zoom", "/usr/local/bin/zoom"); err != nil {
+		t.Fatal(err)
+	}
+	manifest, err = ReadInstallManifest("Zoom")
+	if err != nil || !slices.Equal(manifest.Files, []string{"/opt/zoom/zoom", "/usr/share/applications/zoom.desktop"}) || !manifest.RanAsRoot {
+		t.Errorf("manifest after adding and removing a file = %+v, %v", manifest, err)
+	}
+}
+
+func TestInstallManifestWithoutAnything(t *testing.T) {
+	newTestPiAppsDir(t, "Zoom")
+	manifest, err := ReadInstallManifest("Zoom")
+	if err != nil || len(manifest.Files) != 0 || manifest.Files == nil || manifest.RanAsRoot {
+		t.Errorf("ReadInstallManifest of an app without a manifest = %+v, %v, want it empty", manifest, err)
+	}
+
+	// Recording nothing removes the manifest instead of leaving an empty file
+	if err := writeInstalledFiles("Zoom", []string{"/opt/zoom/zoom"}, ResourceLimits{}, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeInstalledFiles("Zoom", nil, ResourceLimits{}, false); err != nil {
+		t.Fatal(err)
+	}
+	path, err := AppDataPath("install-files", "Zoom")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("the empty manifest was kept: %v", err)
+	}
+
+	if _, err := ReadInstallManifest("../Zoom"); err == nil {
+		t.Error("ReadInstallManifest accepted an app name with a path in it")
+	}
+}
+
+func TestWriteInstalledFilesKeepsCachedAssets(t *testing.T) {
+	newTestPiAppsDir(t, "Zoom")
+	sum := testSHA256("asset")
+	if err := recordCachedAsset("Zoom", sum); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeInstalledFiles("Zoom", []string{"/opt/zoom/zoom"}, ResourceLimits{}, false); err != nil {
+		t.Fatal(err)
+	}
+	manifest, err := ReadInstallManifest("Zoom")
+	if err != nil || !slices.Equal(manifest.CachedAssets, []string{sum}) || !slices.Equal(manifest.Files, []string{"/opt/zoom/zoom"}) {
+		t.Errorf("manifest = %+v, %v, want the cached asset kept", manifest, err)
+	}
+}
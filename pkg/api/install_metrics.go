@@ -0,0 +1,286 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: install_metrics.go
+// Description: Opt-in install statistics: how long operations take and how much they download, queued locally
+// and sent at most once a day, so install time estimates and timeouts can be based on real numbers.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/user"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// InstallMetric is everything the install statistics send about one operation. Nothing in it identifies
+// the user or the device: no hostname, user name, IP address, machine ID or serial number.
+type InstallMetric struct {
+	App             string `json:"app"`              // name of an app of the official catalog
+	Action          string `json:"action"`           // install, uninstall or update
+	Result          string `json:"result"`           // success or failure
+	DurationSeconds int64  `json:"duration_seconds"` // how long the operation took
+	DownloadedBytes int64  `json:"downloaded_bytes"` // downloads recorded in the download ledger during the operation
+	DeviceClass     string `json:"device_class"`     // like "Raspberry Pi 4", without revision, or "other arm64"
+	OSCodename      string `json:"os_codename"`      // like bookworm
+}
+
+const (
+	// installMetricsURL receives the batches of install statistics
+	installMetricsURL = "https://analytics.pi-apps.io/install-metrics"
+	// installMetricsInterval is how often the queued statistics are sent at most
+	installMetricsInterval = 24 * time.Hour
+	// installMetricsQueueLimit is the number of operations the queue keeps, the oldest are dropped beyond it
+	installMetricsQueueLimit = 500
+)
+
+var installMetricsMutex sync.Mutex
+
+// installMetricsDir holds the queued statistics in pending.jsonl and the time of the last send in last-sent
+func installMetricsDir() string {
+	return filepath.Join(GetDataDir(), "metrics-queue")
+}
+
+// installMetricsEnabled reports whether the "Share install statistics" setting is Yes. It is off unless the user turned it on.
+func installMetricsEnabled() bool {
+	return ReadSetting("Share install statistics") == "Yes"
+}
+
+// raspberryPiClass matches the model of a Raspberry Pi up to its generation, dropping "Model B Rev 1.4"
+var raspberryPiClass = regexp.MustCompile(`^Raspberry Pi (Zero 2|Zero|Compute Module \d+|\d+)`)
+
+// deviceClass returns the kind of device, like "Raspberry Pi 4", or "other" and the architecture for other devices
+func deviceClass() string {
+	model, _ := getModel()
+	if class := raspberryPiClass.FindString(model); class != "" {
+		return class
+	}
+	return "other " + runtime.GOARCH
+}
+
+// privateValuePattern matches IP addresses and hex strings as long as a hash, none of which belong in the statistics
+var privateValuePattern = regexp.MustCompile(`(?i)\b(\d{1,3}(\.\d{1,3}){3}|[0-9a-f:]*:[0-9a-f:]*:[0-9a-f:]+|[0-9a-f]{32,})\b`)
+
+// privateValues returns the hostname and user names of this device, which the statistics must never contain
+func privateValues() []string {
+	var values []string
+	if hostname, err := os.Hostname(); err == nil {
+		values = append(values, hostname)
+	}
+	if current, err := user.Current(); err == nil {
+		values = append(values, current.Username)
+	}
+	values = append(values, os.Getenv("USER"), os.Getenv("SUDO_USER"))
+	return slices.DeleteFunc(values, func(value string) bool { return len(value) < 3 })
+}
+
+// installMetricPrivate reports whether a metric contains anything that could identify the user or the device.
+// Those metrics are dropped instead of sent.
+func installMetricPrivate(metric InstallMetric, private []string) bool {
+	for _, field := range []string{metric.App, metric.Action, metric.Result, metric.DeviceClass, metric.OSCodename} {
+		if privateValuePattern.MatchString(field) {
+			return true
+		}
+		for _, value := range private {
+			if strings.Contains(strings.ToLower(field), strings.ToLower(value)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// recordInstallMetric queues the statistics of a finished operation if the user opted in. Apps that are not
+// in the official catalog are skipped, their names could say something about the user.
+func recordInstallMetric(app string, action Action, isUpdate bool, succeeded bool, started time.Time) {
+	if !installMetricsEnabled() {
+		return
+	}
+	online, err := ListApps("online")
+	if err != nil || !slices.Contains(online, app) {
+		return
+	}
+
+	metric := InstallMetric{
+		App:             app,
+		Action:          string(action),
+		Result:          "failure",
+		DurationSeconds: int64(time.Since(started).Round(time.Second) / time.Second),
+		DeviceClass:     deviceClass(),
+		OSCodename:      VERSION_CODENAME,
+	}
+	if isUpdate {
+		metric.Action = string(ActionUpdate)
+	}
+	if succeeded {
+		metric.Result = "success"
+	}
+	// The downloads of the operation are the ledger entries of the app since it started
+	FlushDownloadLedger()
+	if downloads, err := DownloadLedger(DownloadLedgerFilter{App: app, Since: started}); err == nil {
+		for _, download := range downloads {
+			metric.DownloadedBytes += download.Bytes
+		}
+	}
+
+	if err := SendInstallMetrics(metric); err != nil {
+		Debug(fmt.Sprintf("Failed to queue the install statistics of %s: %v", app, err))
+	}
+}
+
+// SendInstallMetrics queues the statistics of an operation and sends the queue if it wasn't sent in the last day.
+// Nothing is queued unless "Share install statistics" is Yes. Sending fails quietly while offline, the queue is
+// kept and sent a day later.
+func SendInstallMetrics(item InstallMetric) error {
+	if !installMetricsEnabled() {
+		return nil
+	}
+	if installMetricPrivate(item, privateValues()) {
+		return fmt.Errorf("install statistics of %s contain data that could identify this device, not sending them", item.App)
+	}
+
+	installMetricsMutex.Lock()
+	defer installMetricsMutex.Unlock()
+
+	pending, err := pendingInstallMetrics()
+	if err != nil {
+		Debug(err.Error())
+		pending = nil
+	}
+	pending = append(pending, item)
+	if len(pending) > installMetricsQueueLimit {
+		pending = pending[len(pending)-installMetricsQueueLimit:]
+	}
+	if err := writePendingInstallMetrics(pending); err != nil {
+		return err
+	}
+
+	lastSent := filepath.Join(installMetricsDir(), "last-sent")
+	if info, err := os.Stat(lastSent); err == nil && time.Since(info.ModTime()) < installMetricsInterval {
+		return nil
+	}
+	// Offline or not, the next try is a day later
+	if err := os.WriteFile(lastSent, []byte(time.Now().UTC().Format(time.RFC3339)+"\n"), 0644); err != nil {
+		return err
+	}
+	if err := postInstallMetrics(pending); err != nil {
+		Debug(fmt.Sprintf("Failed to send the install statistics, keeping them for the next try: %v", err))
+		return nil
+	}
+	return writePendingInstallMetrics(nil)
+}
+
+// PendingInstallMetrics returns the statistics waiting to be sent, oldest first
+func PendingInstallMetrics() ([]InstallMetric, error) {
+	installMetricsMutex.Lock()
+	defer installMetricsMutex.Unlock()
+	return pendingInstallMetrics()
+}
+
+// ClearInstallMetrics removes the statistics waiting to be sent
+func ClearInstallMetrics() error {
+	installMetricsMutex.Lock()
+	defer installMetricsMutex.Unlock()
+	return writePendingInstallMetrics(nil)
+}
+
+// pendingInstallMetrics reads the queue, skipping lines that aren't valid
+func pendingInstallMetrics() ([]InstallMetric, error) {
+	file, err := os.Open(filepath.Join(installMetricsDir(), "pending.jsonl"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read the install statistics queue: %w", err)
+	}
+	defer file.Close()
+
+	var pending []InstallMetric
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var metric InstallMetric
+		if json.Unmarshal(scanner.Bytes(), &metric) == nil {
+			pending = append(pending, metric)
+		}
+	}
+	return pending, scanner.Err()
+}
+
+// writePendingInstallMetrics replaces the queue
+func writePendingInstallMetrics(pending []InstallMetric) error {
+	if err := os.MkdirAll(installMetricsDir(), 0755); err != nil {
+		return err
+	}
+	var lines bytes.Buffer
+	encoder := json.NewEncoder(&lines)
+	for _, metric := range pending {
+		if err := encoder.Encode(metric); err != nil {
+			return err
+		}
+	}
+	path := filepath.Join(installMetricsDir(), "pending.jsonl")
+	if err := os.WriteFile(path+".tmp", lines.Bytes(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// postInstallMetrics sends a batch of statistics. Metrics that contain private data are dropped first, in case
+// the queue was edited.
+func postInstallMetrics(pending []InstallMetric) error {
+	private := privateValues()
+	batch := slices.DeleteFunc(slices.Clone(pending), func(metric InstallMetric) bool {
+		return installMetricPrivate(metric, private)
+	})
+	if len(batch) == 0 {
+		return nil
+	}
+	body, err := json.Marshal(struct {
+		Metrics []InstallMetric `json:"metrics"`
+	}{batch})
+	if err != nil {
+		return err
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	req, err := http.NewRequest("POST", installMetricsURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	// Unlike the user agent of ShlinkLink, this one has no device details
+	req.Header.Set("User-Agent", "Pi-Apps Go install statistics")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("server returned %s", resp.Status)
+	}
+	return nil
+}
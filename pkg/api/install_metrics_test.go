@@ -0,0 +1,159 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"encoding/json"
+	"os"
+	"os/user"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInstallMetricPrivate(t *testing.T) {
+	private := []string{"raspberrypi", "alice"}
+	base := InstallMetric{App: "Zoom", Action: "install", Result: "success", DeviceClass: "Raspberry Pi 4", OSCodename: "bookworm"}
+
+	tests := []struct {
+		name   string
+		modify func(*InstallMetric)
+		want   bool
+	}{
+		{"clean", func(*InstallMetric) {}, false},
+		{"hostname", func(m *InstallMetric) { m.DeviceClass = "RaspberryPi device" }, true},
+		{"user name", func(m *InstallMetric) { m.App = "Alice's script" }, true},
+		{"ipv4", func(m *InstallMetric) { m.App = "app 192.168.1.20" }, true},
+		{"ipv6", func(m *InstallMetric) { m.OSCodename = "fe80::1ff:fe23:4567:890a" }, true},
+		{"serial hash", func(m *InstallMetric) { m.DeviceClass = "10000000a3b2c1d0e9f8a7b6c5d4e3f2a1b0c9d8" }, true},
+		{"machine id", func(m *InstallMetric) { m.App = "0123456789abcdef0123456789abcdef" }, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			metric := base
+			test.modify(&metric)
+			if got := installMetricPrivate(metric, private); got != test.want {
+				t.Errorf("installMetricPrivate(%+v) = %v, want %v", metric, got, test.want)
+			}
+		})
+	}
+}
+
+func TestDeviceClassHasNoRevision(t *testing.T) {
+	for model, want := range map[string]string{
+		"Raspberry Pi 4 Model B Rev 1.4":        "Raspberry Pi 4",
+		"Raspberry Pi 400 Rev 1.0":              "Raspberry Pi 400",
+		"Raspberry Pi Zero 2 W Rev 1.0":         "Raspberry Pi Zero 2",
+		"Raspberry Pi Compute Module 4 Rev 1.1": "Raspberry Pi Compute Module 4",
+	} {
+		if got := raspberryPiClass.FindString(model); got != want {
+			t.Errorf("class of %q = %q, want %q", model, got, want)
+		}
+	}
+}
+
+// queueTestMetric enables the statistics, marks them as sent just now so nothing goes out, and queues a metric
+func queueTestMetric(t *testing.T, directory string, metric InstallMetric) {
+	t.Helper()
+	writeTestFile(t, filepath.Join(directory, "data", "settings", "Share install statistics"), "Yes\n")
+	writeTestFile(t, filepath.Join(directory, "data", "metrics-queue", "last-sent"), time.Now().UTC().Format(time.RFC3339))
+	if err := SendInstallMetrics(metric); err != nil {
+		t.Fatalf("SendInstallMetrics: %v", err)
+	}
+}
+
+func TestSendInstallMetricsOffByDefault(t *testing.T) {
+	directory := newTestPiAppsDir(t)
+	if err := SendInstallMetrics(InstallMetric{App: "Zoom", Action: "install", Result: "success"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(directory, "data", "metrics-queue")); !os.IsNotExist(err) {
+		t.Errorf("statistics were queued although the setting is off")
+	}
+}
+
+func TestQueuedPayloadHasNoIdentifyingData(t *testing.T) {
+	directory := newTestPiAppsDir(t)
+	queueTestMetric(t, directory, InstallMetric{App: "Zoom", Action: "install", Result: "success", DurationSeconds: 42,
+		DownloadedBytes: 1 << 20, DeviceClass: deviceClass(), OSCodename: "bookworm"})
+
+	data, err := os.ReadFile(filepath.Join(directory, "data", "metrics-queue", "pending.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		t.Fatalf("queue is not one JSON object per line: %v", err)
+	}
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	want := []string{"action", "app", "device_class", "downloaded_bytes", "duration_seconds", "os_codename", "result"}
+	if !slices.Equal(keys, want) {
+		t.Errorf("payload fields = %v, want %v", keys, want)
+	}
+
+	payload := strings.ToLower(string(data))
+	forbidden := privateValues()
+	if current, err := user.Current(); err == nil {
+		forbidden = append(forbidden, current.Uid, current.HomeDir)
+	}
+	for _, value := range forbidden {
+		if len(value) >= 3 && strings.Contains(payload, strings.ToLower(value)) {
+			t.Errorf("payload %s contains %q", data, value)
+		}
+	}
+	if privateValuePattern.Match(data) {
+		t.Errorf("payload %s contains an IP address or a hash", data)
+	}
+}
+
+func TestSendInstallMetricsRefusesPrivateData(t *testing.T) {
+	directory := newTestPiAppsDir(t)
+	hostname, err := os.Hostname()
+	if err != nil || len(hostname) < 3 {
+		t.Skip("no usable hostname")
+	}
+	writeTestFile(t, filepath.Join(directory, "data", "settings", "Share install statistics"), "Yes\n")
+	if err := SendInstallMetrics(InstallMetric{App: hostname, Action: "install", Result: "success"}); err == nil {
+		t.Errorf("a metric containing the hostname was accepted")
+	}
+	if pending, _ := PendingInstallMetrics(); len(pending) != 0 {
+		t.Errorf("queued %v", pending)
+	}
+}
+
+func TestInstallMetricsQueueIsCapped(t *testing.T) {
+	directory := newTestPiAppsDir(t)
+	for i := range installMetricsQueueLimit + 10 {
+		queueTestMetric(t, directory, InstallMetric{App: "Zoom", Action: "install", Result: "success", DurationSeconds: int64(i)})
+	}
+	pending, err := PendingInstallMetrics()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != installMetricsQueueLimit {
+		t.Fatalf("queue has %d entries, want %d", len(pending), installMetricsQueueLimit)
+	}
+	if pending[0].DurationSeconds != 10 {
+		t.Errorf("oldest kept entry is %d, the oldest ones should be dropped", pending[0].DurationSeconds)
+	}
+}
@@ -0,0 +1,387 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: kiosk.go
+// Description: Kiosk mode, a root-owned /etc/pi-apps/kiosk.conf that limits the apps shown and the actions allowed, unless unlocked with an admin PIN.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package api
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/pi-apps-go/pi-apps/pkg/api/errs"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// kioskConfigPath is the kiosk configuration of the system. It is outside of the Pi-Apps directory, which may belong to
+// the users kiosk mode restricts. A variable so tests can use their own file.
+var kioskConfigPath = "/etc/pi-apps/kiosk.conf"
+
+const (
+	// KioskPINEnvVar holds the admin PIN of a session that unlocked kiosk mode, so the programs it starts are unlocked too
+	KioskPINEnvVar = "PI_APPS_KIOSK_PIN"
+)
+
+// KioskFeature is an action kiosk mode can forbid
+type KioskFeature string
+
+// Actions kiosk mode forbids unless its configuration allows them
+const (
+	KioskUninstall KioskFeature = "uninstall"
+	KioskSettings  KioskFeature = "settings"
+	KioskCreateApp KioskFeature = "createapp"
+	KioskImportApp KioskFeature = "importapp"
+)
+
+// KioskConfig is the parsed kiosk configuration
+type KioskConfig struct {
+	AllowApps       []string              // apps shown, whatever their category
+	AllowCategories []string              // categories whose apps are shown, including their subcategories
+	HideApps        []string              // apps never shown, even if an allow list has them
+	Allowed         map[KioskFeature]bool // actions allowed, the others are forbidden
+	AdminPINHash    string                // bcrypt hash of the PIN that unlocks kiosk mode, "" if it can't be unlocked
+
+	// restrictApps is set when the file has an allow list. Without one every app that isn't hidden is shown.
+	restrictApps bool
+}
+
+// ParseKioskConfig parses a kiosk configuration. Each line is "key = value", values of lists are
+// separated by commas and lines starting with # are comments:
+//
+//	allow_categories = Games, Internet
+//	allow_apps = Zoom
+//	hide_apps = Steam
+//	uninstall = no
+//	admin_pin = $2a$10$...
+//
+// An app is shown if hide_apps doesn't list it and allow_apps lists it or allow_categories lists its
+// category. Without allow_apps and allow_categories, every app not in hide_apps is shown.
+// uninstall, settings, createapp and importapp are "yes" or "no", they default to "no".
+func ParseKioskConfig(r io.Reader) (*KioskConfig, error) {
+	config := &KioskConfig{Allowed: make(map[KioskFeature]bool)}
+	scanner := bufio.NewScanner(r)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected key = value: %s", lineNumber, line)
+		}
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "allow_apps":
+			config.AllowApps = append(config.AllowApps, splitKioskList(value)...)
+			config.restrictApps = true
+		case "allow_categories":
+			config.AllowCategories = append(config.AllowCategories, splitKioskList(value)...)
+			config.restrictApps = true
+		case "hide_apps":
+			config.HideApps = append(config.HideApps, splitKioskList(value)...)
+		case string(KioskUninstall), string(KioskSettings), string(KioskCreateApp), string(KioskImportApp):
+			switch strings.ToLower(value) {
+			case "yes":
+				config.Allowed[KioskFeature(key)] = true
+			case "no":
+				config.Allowed[KioskFeature(key)] = false
+			default:
+				return nil, fmt.Errorf("line %d: %s must be yes or no, not %q", lineNumber, key, value)
+			}
+		case "admin_pin":
+			if _, err := bcrypt.Cost([]byte(value)); err != nil {
+				return nil, fmt.Errorf("line %d: admin_pin must be a bcrypt hash, create one with 'api kiosk hash_pin'", lineNumber)
+			}
+			config.AdminPINHash = value
+		default:
+			return nil, fmt.Errorf("line %d: unknown key %q", lineNumber, key)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// splitKioskList splits a comma-separated list, dropping empty items
+func splitKioskList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// AppVisible reports whether kiosk mode shows an app of a category. hide_apps wins over allow_apps,
+// which wins over allow_categories. A category allows its subcategories, "Games" allows "Games/Emulators".
+func (c *KioskConfig) AppVisible(app, category string) bool {
+	if slices.Contains(c.HideApps, app) {
+		return false
+	}
+	if !c.restrictApps || slices.Contains(c.AllowApps, app) {
+		return true
+	}
+	for _, allowed := range c.AllowCategories {
+		if category == allowed || strings.HasPrefix(category, allowed+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// Allows reports whether kiosk mode allows an action
+func (c *KioskConfig) Allows(feature KioskFeature) bool {
+	return c.Allowed[feature]
+}
+
+// kioskOwnedByRoot reports whether a file or folder on the path to the kiosk configuration is owned by root and
+// neither its group nor other users can write it. A variable so tests can trust their own files.
+var kioskOwnedByRoot = func(info os.FileInfo) bool {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	return ok && stat.Uid == 0 && info.Mode().Perm()&0o022 == 0
+}
+
+// checkKioskConfigTrusted returns an error unless the kiosk configuration at path is a regular file that only root
+// can change: root owns it and every folder above it, and nobody else can write them, so no user can replace it
+func checkKioskConfigTrusted(path string, info os.FileInfo) error {
+	if !info.Mode().IsRegular() || !kioskOwnedByRoot(info) {
+		return fmt.Errorf("%s can't be trusted, it must be a file owned by root that only root can write", path)
+	}
+	for dir := filepath.Dir(path); ; dir = filepath.Dir(dir) {
+		info, err := os.Lstat(dir)
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() || !kioskOwnedByRoot(info) {
+			return fmt.Errorf("%s can't be trusted, %s must be a folder owned by root that only root can write", path, dir)
+		}
+		if dir == filepath.Dir(dir) {
+			return nil
+		}
+	}
+}
+
+// ReadKioskConfig reads the kiosk configuration of the system, /etc/pi-apps/kiosk.conf. It returns nil without an
+// error if there is none, so kiosk mode is off. A configuration that can't be read or trusted is an error, kiosk
+// mode then stays on with everything forbidden, see ActiveKiosk.
+func ReadKioskConfig() (*KioskConfig, error) {
+	path := kioskConfigPath
+	info, err := os.Lstat(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err := checkKioskConfigTrusted(path, info); err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	config, err := ParseKioskConfig(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return config, nil
+}
+
+// kioskInvalidWarning warns once per process about a kiosk configuration that can't be parsed
+var kioskInvalidWarning sync.Once
+
+// kioskUnlocks caches the bcrypt comparisons of the admin PIN, they are slow on purpose
+var kioskUnlocks sync.Map
+
+// unlockedBy reports whether a PIN unlocks kiosk mode
+func (c *KioskConfig) unlockedBy(pin string) bool {
+	if c.AdminPINHash == "" || pin == "" {
+		return false
+	}
+	key := c.AdminPINHash + "\x00" + pin
+	if unlocked, ok := kioskUnlocks.Load(key); ok {
+		return unlocked.(bool)
+	}
+	unlocked := bcrypt.CompareHashAndPassword([]byte(c.AdminPINHash), []byte(pin)) == nil
+	kioskUnlocks.Store(key, unlocked)
+	return unlocked
+}
+
+// ActiveKiosk returns the kiosk configuration in effect, nil if kiosk mode is off or this session unlocked it.
+// A configuration that can't be read, trusted or parsed shows no apps and allows nothing, so a typo or a replaced
+// file doesn't open the kiosk.
+func ActiveKiosk() *KioskConfig {
+	config, err := ReadKioskConfig()
+	if err != nil {
+		kioskInvalidWarning.Do(func() {
+			WarningT("Kiosk mode is locked down completely, its configuration is invalid: %v", err)
+		})
+		return &KioskConfig{Allowed: map[KioskFeature]bool{}, restrictApps: true}
+	}
+	if config == nil || config.unlockedBy(os.Getenv(KioskPINEnvVar)) {
+		return nil
+	}
+	return config
+}
+
+// KioskState returns "off", "locked" or "unlocked", the state of kiosk mode for this session
+func KioskState() string {
+	if config, err := ReadKioskConfig(); err == nil && config == nil {
+		return "off"
+	}
+	if ActiveKiosk() == nil {
+		return "unlocked"
+	}
+	return "locked"
+}
+
+// UnlockKiosk unlocks kiosk mode for this session and the programs it starts if the PIN is the admin PIN
+func UnlockKiosk(pin string) error {
+	config, err := ReadKioskConfig()
+	if err != nil {
+		return err
+	}
+	if config == nil {
+		return nil
+	}
+	if config.AdminPINHash == "" {
+		return errs.New(errs.ErrKioskRestricted, "kiosk mode has no admin PIN, it can't be unlocked")
+	}
+	if !config.unlockedBy(pin) {
+		return errs.New(errs.ErrKioskRestricted, "wrong admin PIN")
+	}
+	return os.Setenv(KioskPINEnvVar, pin)
+}
+
+// HashKioskPIN returns the bcrypt hash of a PIN for the admin_pin line of /etc/pi-apps/kiosk.conf
+func HashKioskPIN(pin string) (string, error) {
+	if pin == "" {
+		return "", fmt.Errorf("the PIN is empty")
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(pin), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// kioskAppCategories returns the category of each app that kiosk mode goes by. It ignores the category
+// overrides of the user, or moving an app to an allowed category would show it.
+func kioskAppCategories() map[string]string {
+	categories := make(map[string]string)
+	for _, assignment := range getDeviceCategoryOverrides() {
+		if assignment.AppName != "" {
+			categories[assignment.AppName] = assignment.Category
+		}
+	}
+	for _, assignment := range embeddedGlobalCategories {
+		if _, ok := categories[assignment.AppName]; assignment.AppName != "" && !ok {
+			categories[assignment.AppName] = assignment.Category
+		}
+	}
+	return categories
+}
+
+// filterAppPaths keeps the "Category/App" entries of an app list that kiosk mode shows. The Deprecated
+// category only has apps to uninstall, so it is left out if uninstalling isn't allowed.
+func (c *KioskConfig) filterAppPaths(paths []string) []string {
+	categories := kioskAppCategories()
+	var result []string
+	for _, path := range paths {
+		if path == "Deprecated" || strings.HasPrefix(path, "Deprecated/") {
+			if !c.Allows(KioskUninstall) {
+				continue
+			}
+			if path == "Deprecated/" {
+				result = append(result, path)
+				continue
+			}
+		}
+		app := path[strings.LastIndex(path, "/")+1:]
+		if c.AppVisible(app, categories[app]) {
+			result = append(result, path)
+		}
+	}
+	return result
+}
+
+// FilterKioskApps keeps the apps kiosk mode shows, all of them if it is off
+func FilterKioskApps(apps []string) []string {
+	kiosk := ActiveKiosk()
+	if kiosk == nil {
+		return apps
+	}
+	categories := kioskAppCategories()
+	var result []string
+	for _, app := range apps {
+		if kiosk.AppVisible(app, categories[app]) {
+			result = append(result, app)
+		}
+	}
+	return result
+}
+
+// CheckKioskApp refuses installing an app kiosk mode doesn't show, and uninstalling if kiosk mode forbids it.
+// Updates reinstall apps that are already there, so kiosk mode doesn't block them.
+func CheckKioskApp(action Action, app string, isUpdate bool) error {
+	kiosk := ActiveKiosk()
+	if kiosk == nil || isUpdate {
+		return nil
+	}
+	switch action {
+	case ActionInstall:
+		if !kiosk.AppVisible(app, kioskAppCategories()[app]) {
+			return errs.New(errs.ErrKioskRestricted, "installing %s is not allowed in kiosk mode", app)
+		}
+	case ActionUninstall:
+		if !kiosk.Allows(KioskUninstall) {
+			return errs.New(errs.ErrKioskRestricted, "uninstalling apps is not allowed in kiosk mode")
+		}
+	}
+	return nil
+}
+
+// CheckKioskFeature refuses an action kiosk mode forbids
+func CheckKioskFeature(feature KioskFeature) error {
+	if kiosk := ActiveKiosk(); kiosk != nil && !kiosk.Allows(feature) {
+		return errs.New(errs.ErrKioskRestricted, "%s is not allowed in kiosk mode", kioskFeatureNames[feature])
+	}
+	return nil
+}
+
+// kioskFeatureNames describes the features in the messages of CheckKioskFeature
+var kioskFeatureNames = map[KioskFeature]string{
+	KioskUninstall: "uninstalling apps",
+	KioskSettings:  "changing settings",
+	KioskCreateApp: "creating apps",
+	KioskImportApp: "importing apps",
+}
@@ -0,0 +1,251 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/pi-apps-go/pi-apps/pkg/api/errs"
+)
+
+func TestParseKioskConfig(t *testing.T) {
+	config, err := ParseKioskConfig(strings.NewReader(`# Classroom kiosk
+allow_categories = Games, Internet/Browsers
+allow_apps = Zoom,,Arduino
+allow_apps = AbiWord
+hide_apps = Steam
+uninstall = yes
+settings = No
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"Zoom", "Arduino", "AbiWord"}; !slices.Equal(config.AllowApps, want) {
+		t.Errorf("AllowApps = %q, want %q", config.AllowApps, want)
+	}
+	if want := []string{"Games", "Internet/Browsers"}; !slices.Equal(config.AllowCategories, want) {
+		t.Errorf("AllowCategories = %q, want %q", config.AllowCategories, want)
+	}
+	if want := []string{"Steam"}; !slices.Equal(config.HideApps, want) {
+		t.Errorf("HideApps = %q, want %q", config.HideApps, want)
+	}
+	if !config.Allows(KioskUninstall) || config.Allows(KioskSettings) || config.Allows(KioskCreateApp) || config.Allows(KioskImportApp) {
+		t.Errorf("Allowed = %v, want only uninstall", config.Allowed)
+	}
+
+	for _, invalid := range []string{
+		"allow_apps Zoom",
+		"uninstall = maybe",
+		"admin_pin = 1234",
+		"allow_everything = yes",
+	} {
+		if _, err := ParseKioskConfig(strings.NewReader(invalid)); err == nil {
+			t.Errorf("ParseKioskConfig(%q) succeeded, want an error", invalid)
+		}
+	}
+}
+
+func TestKioskAppVisible(t *testing.T) {
+	config, err := ParseKioskConfig(strings.NewReader(`allow_categories = Games, Internet/Browsers
+allow_apps = Zoom, Steam
+hide_apps = Steam, Minecraft Java
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		app, category string
+		visible       bool
+	}{
+		{"Zoom", "Internet/Communication", true},     // allow_apps wins over the categories
+		{"Steam", "Games", false},                    // hide_apps wins over allow_apps
+		{"Minecraft Java", "Games", false},           // hide_apps wins over allow_categories
+		{"Amiberry", "Games", true},                  // allowed category
+		{"Amiberry", "Games/Emulators", true},        // subcategory of an allowed category
+		{"Firefox", "Internet/Browsers", true},       // allowed subcategory
+		{"Discord", "Internet/Communication", false}, // only a subcategory of Internet is allowed
+		{"GamesHub", "GamesHub", false},              // a category that only starts like an allowed one
+		{"Arduino", "Programming", false},
+		{"Arduino", "", false},
+	}
+	for _, tt := range tests {
+		if got := config.AppVisible(tt.app, tt.category); got != tt.visible {
+			t.Errorf("AppVisible(%q, %q) = %v, want %v", tt.app, tt.category, got, tt.visible)
+		}
+	}
+
+	// Without an allow list only hide_apps hides apps
+	config, err = ParseKioskConfig(strings.NewReader("hide_apps = Steam\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !config.AppVisible("Arduino", "Programming") || config.AppVisible("Steam", "Games") {
+		t.Error("a config without allow lists should show every app but the hidden ones")
+	}
+}
+
+// writeKioskConfig writes the kiosk configuration and trusts it, the test files aren't owned by root
+func writeKioskConfig(t *testing.T, content string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "kiosk.conf")
+	writeTestFile(t, path, content)
+	originalPath, trusted := kioskConfigPath, kioskOwnedByRoot
+	kioskConfigPath = path
+	kioskOwnedByRoot = func(os.FileInfo) bool { return true }
+	t.Cleanup(func() { kioskConfigPath, kioskOwnedByRoot = originalPath, trusted })
+}
+
+func TestReadKioskConfigDistrustsFilesUsersCanChange(t *testing.T) {
+	newTestPiAppsDir(t)
+	t.Setenv(KioskPINEnvVar, "")
+	original := kioskConfigPath
+	t.Cleanup(func() { kioskConfigPath = original })
+
+	// Without a configuration kiosk mode is off
+	kioskConfigPath = filepath.Join(t.TempDir(), "pi-apps", "kiosk.conf")
+	if config, err := ReadKioskConfig(); err != nil || config != nil {
+		t.Errorf("ReadKioskConfig without a configuration = %v, %v, want kiosk mode off", config, err)
+	}
+	if state := KioskState(); state != "off" {
+		t.Errorf("KioskState() without a configuration = %q, want off", state)
+	}
+
+	// A file only root can write, in a folder users can write, could be replaced by any user
+	folder := filepath.Join(t.TempDir(), "pi-apps")
+	writeTestFile(t, filepath.Join(folder, "kiosk.conf"), "allow_apps = Zoom\nsettings = yes\n")
+	if err := os.Chmod(filepath.Join(folder, "kiosk.conf"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(folder, 0777); err != nil {
+		t.Fatal(err)
+	}
+	// and a file users can write could be changed by them
+	writable := filepath.Join(t.TempDir(), "kiosk.conf")
+	writeTestFile(t, writable, "allow_apps = Zoom\nsettings = yes\n")
+	if err := os.Chmod(writable, 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range []string{filepath.Join(folder, "kiosk.conf"), writable} {
+		kioskConfigPath = path
+		if config, err := ReadKioskConfig(); err == nil {
+			t.Errorf("ReadKioskConfig of %s = %v, want an error", path, config)
+		}
+		// The untrusted file keeps kiosk mode on, with everything forbidden
+		kiosk := ActiveKiosk()
+		if kiosk == nil || kiosk.AppVisible("Zoom", "Internet") || kiosk.Allows(KioskSettings) {
+			t.Errorf("ActiveKiosk with the untrusted %s = %+v, want everything forbidden", path, kiosk)
+		}
+		if state := KioskState(); state != "locked" {
+			t.Errorf("KioskState() with the untrusted %s = %q, want locked", path, state)
+		}
+	}
+}
+
+func TestKioskEnforcement(t *testing.T) {
+	directory := newTestPiAppsDir(t, "Amiberry", "Arduino", "AbiWord")
+	for _, app := range []string{"Amiberry", "Arduino", "AbiWord"} {
+		writeTestFile(t, filepath.Join(directory, "apps", app, "install"), "#!/bin/bash\n")
+		writeTestFile(t, filepath.Join(directory, "apps", app, "uninstall"), "#!/bin/bash\n")
+	}
+	writeTestFile(t, filepath.Join(directory, "data", "status", "AbiWord"), "installed")
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(KioskPINEnvVar, "")
+	hash, err := HashKioskPIN("2468")
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeKioskConfig(t, "allow_categories = Games\nallow_apps = AbiWord\nadmin_pin = "+hash+"\n")
+
+	if state := KioskState(); state != "locked" {
+		t.Fatalf("KioskState() = %q, want locked", state)
+	}
+
+	// The entry points of the CLI refuse what the GUI hides
+	if err := ManageApp(ActionInstall, "Arduino", false); !errors.Is(err, errs.ErrKioskRestricted) {
+		t.Errorf("ManageApp install of an app kiosk mode hides = %v, want ErrKioskRestricted", err)
+	}
+	if err := InstallApp("Arduino"); !errors.Is(err, errs.ErrKioskRestricted) {
+		t.Errorf("InstallApp of an app kiosk mode hides = %v, want ErrKioskRestricted", err)
+	}
+	if err := ManageApp(ActionUninstall, "AbiWord", false); !errors.Is(err, errs.ErrKioskRestricted) {
+		t.Errorf("ManageApp uninstall = %v, want ErrKioskRestricted", err)
+	}
+	if err := UninstallApp("AbiWord"); !errors.Is(err, errs.ErrKioskRestricted) {
+		t.Errorf("UninstallApp = %v, want ErrKioskRestricted", err)
+	}
+	if err := CheckKioskApp(ActionInstall, "Arduino", true); err != nil {
+		t.Errorf("updates of installed apps should not be blocked: %v", err)
+	}
+	if err := CreateApp(""); !errors.Is(err, errs.ErrKioskRestricted) {
+		t.Errorf("CreateApp = %v, want ErrKioskRestricted", err)
+	}
+	if err := ImportAppGUI(); !errors.Is(err, errs.ErrKioskRestricted) {
+		t.Errorf("ImportAppGUI = %v, want ErrKioskRestricted", err)
+	}
+	if rendered := RenderError(CheckKioskFeature(KioskSettings)); rendered.ExitCode != ExitNeedsRoot || rendered.Hint == "" {
+		t.Errorf("RenderError of a kiosk error = %+v, want a hint and exit code %d", rendered, ExitNeedsRoot)
+	}
+
+	// The app list and the search only show the allowed apps
+	paths, err := AppPrefixCategory(directory, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range paths {
+		if strings.HasSuffix(path, "/Arduino") {
+			t.Errorf("the app list shows the app kiosk mode hides: %v", paths)
+			break
+		}
+	}
+	if !slices.Contains(paths, "Games/Amiberry") || !slices.Contains(paths, "Installed/AbiWord") {
+		t.Errorf("the app list misses the allowed apps: %v", paths)
+	}
+
+	// A wrong PIN keeps kiosk mode locked, the admin PIN unlocks it for this process and the ones it starts
+	if err := UnlockKiosk("1357"); !errors.Is(err, errs.ErrKioskRestricted) {
+		t.Errorf("UnlockKiosk with a wrong PIN = %v, want ErrKioskRestricted", err)
+	}
+	if err := UnlockKiosk("2468"); err != nil {
+		t.Fatal(err)
+	}
+	if state := KioskState(); state != "unlocked" {
+		t.Errorf("KioskState() after unlocking = %q, want unlocked", state)
+	}
+	if err := CheckKioskApp(ActionUninstall, "AbiWord", false); err != nil {
+		t.Errorf("CheckKioskApp after unlocking = %v", err)
+	}
+}
+
+func TestInvalidKioskConfigLocksEverything(t *testing.T) {
+	newTestPiAppsDir(t, "Amiberry")
+	t.Setenv(KioskPINEnvVar, "")
+	writeKioskConfig(t, "allow_categories Games\n")
+
+	kiosk := ActiveKiosk()
+	if kiosk == nil {
+		t.Fatal("an invalid kiosk configuration turned kiosk mode off")
+	}
+	if kiosk.AppVisible("Amiberry", "Games") || kiosk.Allows(KioskSettings) {
+		t.Error("an invalid kiosk configuration should show no apps and allow nothing")
+	}
+}
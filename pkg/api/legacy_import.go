@@ -0,0 +1,343 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: legacy_import.go
+// Description: Finds an install of the original bash Pi-Apps next to Pi-Apps Go and imports its app statuses, settings and category overrides.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+const (
+	// legacyAppNamesFile is the table of apps renamed since the bash version shipped in etc, one "<old name>\t<new name>" per line
+	legacyAppNamesFile = "legacy-app-names"
+	// legacyImportFile is the file in data recording the bash Pi-Apps directory that was imported or declined,
+	// so the GUI offers the import only once
+	legacyImportFile = "legacy-import"
+)
+
+// legacySetting is a setting of the bash version of Pi-Apps with the values Pi-Apps Go accepts for it
+type legacySetting struct {
+	values       []string
+	defaultValue string // the default of Pi-Apps Go, which a value chosen in the bash version replaces
+}
+
+// legacySettings are the settings of the bash version of Pi-Apps that Pi-Apps Go has too. The bash version
+// no longer changes, so they are listed here rather than read from the settings package, which imports this one.
+var legacySettings = map[string]legacySetting{
+	"App List Style":        {values: []string{"yad-default", "yad-light", "yad-dark", "xlunch-dark", "xlunch-dark-3d", "xlunch-light-3d"}, defaultValue: "yad-default"},
+	"Check for updates":     {values: []string{"Daily", "Always", "Weekly", "Never"}, defaultValue: "Daily"},
+	"Enable analytics":      {values: []string{"Yes", "No"}, defaultValue: "Yes"},
+	"Preferred text editor": {values: []string{"geany", "mousepad", "leafpad", "nano", "Visual Studio Code", "VSCodium"}, defaultValue: "geany"},
+	"Show apps":             {values: []string{"All", "packages", "standard"}, defaultValue: "All"},
+	"Show Edit button":      {values: []string{"No", "Yes"}, defaultValue: "No"},
+	"Shuffle App list":      {values: []string{"No", "Yes"}, defaultValue: "No"},
+}
+
+// LegacyImportItem is an app status, setting or category override imported from the bash version of Pi-Apps
+type LegacyImportItem struct {
+	Name       string `json:"name"`                  // the app or setting in Pi-Apps Go
+	LegacyName string `json:"legacy_name,omitempty"` // the name of the app in the bash version, when it was renamed since
+	Value      string `json:"value"`                 // the status, setting value or category
+}
+
+// LegacyImportReport is what ImportLegacyState imported, or would import in a dry run. Only what Pi-Apps Go
+// doesn't have yet is imported, so a second import reports nothing.
+type LegacyImportReport struct {
+	LegacyDir         string             `json:"legacy_dir"`
+	DryRun            bool               `json:"dry_run"`
+	Statuses          []LegacyImportItem `json:"statuses"`
+	Settings          []LegacyImportItem `json:"settings"`
+	CategoryOverrides []LegacyImportItem `json:"category_overrides"`
+	Missing           []string           `json:"missing"` // apps with a status in the bash version that the catalog of Pi-Apps Go doesn't have
+}
+
+// Changes returns the number of statuses, settings and category overrides imported
+func (r LegacyImportReport) Changes() int {
+	return len(r.Statuses) + len(r.Settings) + len(r.CategoryOverrides)
+}
+
+// Summary lists the imported items and the missing apps, one group per kind
+func (r LegacyImportReport) Summary() string {
+	var text strings.Builder
+	writeGroup := func(title string, items []LegacyImportItem) {
+		if len(items) == 0 {
+			return
+		}
+		text.WriteString(title + "\n")
+		for _, item := range items {
+			if item.LegacyName != "" {
+				text.WriteString("  " + Tf("%s (was %s): %s", item.Name, item.LegacyName, item.Value) + "\n")
+			} else {
+				text.WriteString("  " + item.Name + ": " + item.Value + "\n")
+			}
+		}
+	}
+	writeGroup(T("App statuses:"), r.Statuses)
+	writeGroup(T("Settings:"), r.Settings)
+	writeGroup(T("Category overrides:"), r.CategoryOverrides)
+	if len(r.Missing) > 0 {
+		text.WriteString(T("Not in the app catalog of Pi-Apps Go:") + "\n")
+		for _, app := range r.Missing {
+			text.WriteString("  " + app + "\n")
+		}
+	}
+	return text.String()
+}
+
+// IsLegacyPiAppsDir reports whether a directory is an install of the original bash version of Pi-Apps: its api
+// is a bash script, it has the data/status folder of an install and no go.mod, and it isn't the Pi-Apps directory.
+func IsLegacyPiAppsDir(dir string) bool {
+	api, err := os.Open(filepath.Join(dir, "api"))
+	if err != nil {
+		return false
+	}
+	defer api.Close()
+	shebang, _ := bufio.NewReader(api).ReadString('\n')
+	if !strings.HasPrefix(shebang, "#!") || !strings.Contains(shebang, "bash") {
+		return false
+	}
+	if !DirExists(filepath.Join(dir, "data", "status")) || FileExists(filepath.Join(dir, "go.mod")) {
+		return false
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		return false
+	}
+	current, err := os.Stat(GetPiAppsDir())
+	return err != nil || !os.SameFile(info, current)
+}
+
+// LegacyPiAppsDir returns the install of the bash version of Pi-Apps in ~/pi-apps, "" when there is none
+func LegacyPiAppsDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	dir := filepath.Join(home, "pi-apps")
+	if !IsLegacyPiAppsDir(dir) {
+		return ""
+	}
+	return dir
+}
+
+// readLegacyAppNames reads the apps renamed since the bash version, old name to new name
+func readLegacyAppNames(directory string) map[string]string {
+	names := make(map[string]string)
+	data, err := os.ReadFile(filepath.Join(directory, "etc", legacyAppNamesFile))
+	if err != nil {
+		return names
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if oldName, newName, ok := strings.Cut(line, "\t"); ok && oldName != "" && newName != "" {
+			names[strings.TrimSpace(oldName)] = strings.TrimSpace(newName)
+		}
+	}
+	return names
+}
+
+// ImportLegacyState imports the installed and uninstalled app statuses, the settings Pi-Apps Go has too and the
+// category overrides of an install of the bash version of Pi-Apps into the data directory. Apps renamed since are
+// mapped with the etc/legacy-app-names table, apps the catalog no longer has are only reported. Statuses and
+// category overrides Pi-Apps Go already has are kept, as are settings changed from their default, so importing
+// again changes nothing. A dry run only reports what would be imported.
+func ImportLegacyState(legacyDir string, dryRun bool) (LegacyImportReport, error) {
+	report := LegacyImportReport{LegacyDir: legacyDir, DryRun: dryRun}
+	directory := GetPiAppsDir()
+	if directory == "" {
+		return report, fmt.Errorf("PI_APPS_DIR environment variable not set")
+	}
+	if !IsLegacyPiAppsDir(legacyDir) {
+		return report, fmt.Errorf("%s is not an install of the bash version of Pi-Apps", legacyDir)
+	}
+	if !dryRun {
+		lock, err := LockState("import the state of " + legacyDir)
+		if err != nil {
+			return report, err
+		}
+		defer lock.Release()
+	}
+
+	renames := readLegacyAppNames(directory)
+	rename := func(app string) (string, string) {
+		if newName, ok := renames[app]; ok {
+			return newName, app
+		}
+		return app, ""
+	}
+	inCatalog := func(app string) bool {
+		return ValidateAppName(app) == nil && DirExists(filepath.Join(directory, "apps", app))
+	}
+
+	// App statuses, the ones Pi-Apps Go tracks itself already are kept
+	entries, err := os.ReadDir(filepath.Join(legacyDir, "data", "status"))
+	if err != nil {
+		return report, fmt.Errorf("failed to read the app statuses of %s: %w", legacyDir, err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(legacyDir, "data", "status", entry.Name()))
+		if err != nil {
+			continue
+		}
+		status := strings.TrimSpace(string(content))
+		if status != "installed" && status != "uninstalled" {
+			continue
+		}
+		name, legacyName := rename(entry.Name())
+		if !inCatalog(name) {
+			report.Missing = append(report.Missing, entry.Name())
+			continue
+		}
+		statusFile, err := AppDataPath("status", name)
+		if err != nil || FileExists(statusFile) {
+			continue
+		}
+		report.Statuses = append(report.Statuses, LegacyImportItem{Name: name, LegacyName: legacyName, Value: status})
+		if dryRun {
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(statusFile), 0755); err != nil {
+			return report, fmt.Errorf("failed to create the status directory: %w", err)
+		}
+		if err := os.WriteFile(statusFile, []byte(status), 0644); err != nil {
+			return report, fmt.Errorf("failed to import the status of %s: %w", name, err)
+		}
+	}
+	if len(report.Statuses) > 0 && !dryRun {
+		invalidateStatusSnapshot()
+	}
+
+	// Settings, a value chosen in Pi-Apps Go already wins over the one of the bash version
+	settingNames := make([]string, 0, len(legacySettings))
+	for name := range legacySettings {
+		settingNames = append(settingNames, name)
+	}
+	sort.Strings(settingNames)
+	settingsDir := filepath.Join(DataDirOf(directory), "settings")
+	for _, name := range settingNames {
+		setting := legacySettings[name]
+		content, err := os.ReadFile(filepath.Join(legacyDir, "data", "settings", name))
+		if err != nil {
+			continue
+		}
+		value := strings.TrimSpace(string(content))
+		if !slices.Contains(setting.values, value) {
+			continue
+		}
+		current := setting.defaultValue
+		if content, err := os.ReadFile(filepath.Join(settingsDir, name)); err == nil {
+			current = strings.TrimSpace(string(content))
+		}
+		if current != setting.defaultValue || current == value {
+			continue
+		}
+		report.Settings = append(report.Settings, LegacyImportItem{Name: name, Value: value})
+		if dryRun {
+			continue
+		}
+		if err := os.MkdirAll(settingsDir, 0755); err != nil {
+			return report, fmt.Errorf("failed to create the settings directory: %w", err)
+		}
+		if err := os.WriteFile(filepath.Join(settingsDir, name), []byte(value), 0644); err != nil {
+			return report, fmt.Errorf("failed to import the %s setting: %w", name, err)
+		}
+	}
+
+	// Category overrides of apps Pi-Apps Go has no override for
+	legacyOverrides := make(map[string]string)
+	if err := readCategoryFile(filepath.Join(legacyDir, "data", "category-overrides"), legacyOverrides); err != nil && !os.IsNotExist(err) {
+		return report, fmt.Errorf("failed to read the category overrides of %s: %w", legacyDir, err)
+	}
+	if len(legacyOverrides) > 0 {
+		categories, err := ReadCategoryData()
+		if err != nil {
+			return report, err
+		}
+		apps := make([]string, 0, len(legacyOverrides))
+		for app := range legacyOverrides {
+			apps = append(apps, app)
+		}
+		sort.Strings(apps)
+		for _, app := range apps {
+			category := legacyOverrides[app]
+			name, legacyName := rename(app)
+			if _, ok := categories.LocalCategories[name]; ok || !inCatalog(name) || strings.ContainsAny(category, "|\r\n") {
+				continue
+			}
+			categories.LocalCategories[name] = category
+			report.CategoryOverrides = append(report.CategoryOverrides, LegacyImportItem{Name: name, LegacyName: legacyName, Value: category})
+		}
+		if len(report.CategoryOverrides) > 0 && !dryRun {
+			if err := categories.SaveLocalCategories(); err != nil {
+				return report, fmt.Errorf("failed to import the category overrides: %w", err)
+			}
+		}
+	}
+
+	sort.Strings(report.Missing)
+	if dryRun {
+		return report, nil
+	}
+	return report, DismissLegacyImport(legacyDir)
+}
+
+// PendingLegacyImport returns what importing the install of the bash version in ~/pi-apps would import, and
+// whether to offer it: there is something to import and it wasn't imported or declined before
+func PendingLegacyImport() (LegacyImportReport, bool) {
+	legacyDir := LegacyPiAppsDir()
+	if legacyDir == "" {
+		return LegacyImportReport{}, false
+	}
+	if recorded, err := os.ReadFile(filepath.Join(GetDataDir(), legacyImportFile)); err == nil && strings.TrimSpace(string(recorded)) == legacyDir {
+		return LegacyImportReport{}, false
+	}
+	report, err := ImportLegacyState(legacyDir, true)
+	if err != nil {
+		Debug(fmt.Sprintf("Failed to check %s for state to import: %v", legacyDir, err))
+		return report, false
+	}
+	return report, report.Changes() > 0
+}
+
+// DismissLegacyImport records that the state of an install of the bash version was imported or declined, so
+// PendingLegacyImport no longer offers it
+func DismissLegacyImport(legacyDir string) error {
+	path := filepath.Join(GetDataDir(), legacyImportFile)
+	content := []byte(legacyDir + "\n")
+	if recorded, err := os.ReadFile(path); err == nil && bytes.Equal(recorded, content) {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create data directory: %w", err)
+	}
+	return os.WriteFile(path, content, 0644)
+}
@@ -0,0 +1,133 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// newTestLegacyDir creates an install of the bash version of Pi-Apps in ~/pi-apps of a new home directory
+func newTestLegacyDir(t *testing.T) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	legacyDir := filepath.Join(home, "pi-apps")
+	writeTestFile(t, filepath.Join(legacyDir, "api"), "#!/bin/bash\n\nfunction error {\n  echo \"$1\"\n}\n")
+	for app, status := range map[string]string{"Zoom": "installed\n", "Old Name": "installed", "Gone": "installed", "Docs": "uninstalled", "Broken": "corrupted"} {
+		writeTestFile(t, filepath.Join(legacyDir, "data", "status", app), status)
+	}
+	writeTestFile(t, filepath.Join(legacyDir, "data", "settings", "App List Style"), "yad-dark\n")
+	writeTestFile(t, filepath.Join(legacyDir, "data", "settings", "Show Edit button"), "Yes\n")
+	writeTestFile(t, filepath.Join(legacyDir, "data", "settings", "Enable analytics"), "Maybe\n")
+	writeTestFile(t, filepath.Join(legacyDir, "data", "category-overrides"), "Zoom|Internet\nOld Name|Games\nGone|Games\n")
+	return legacyDir
+}
+
+func TestImportLegacyState(t *testing.T) {
+	legacyDir := newTestLegacyDir(t)
+	directory := newTestPiAppsDir(t, "Zoom", "New Name", "Docs", "Broken")
+	writeTestFile(t, filepath.Join(directory, "etc", legacyAppNamesFile), "# renamed apps\nOld Name\tNew Name\n")
+	// Pi-Apps Go already tracks Docs and has a list style chosen
+	writeTestFile(t, filepath.Join(directory, "data", "status", "Docs"), "installed")
+	writeTestFile(t, filepath.Join(directory, "data", "settings", "App List Style"), "yad-light")
+	writeTestFile(t, filepath.Join(directory, "data", "settings", "Show Edit button"), "No")
+
+	if LegacyPiAppsDir() != legacyDir {
+		t.Fatalf("LegacyPiAppsDir = %q, want %s", LegacyPiAppsDir(), legacyDir)
+	}
+	if IsLegacyPiAppsDir(directory) {
+		t.Error("IsLegacyPiAppsDir is true for the Pi-Apps directory")
+	}
+
+	dryRun, err := ImportLegacyState(legacyDir, true)
+	if err != nil {
+		t.Fatalf("ImportLegacyState dry run = %v", err)
+	}
+	if FileExists(filepath.Join(directory, "data", "status", "Zoom")) {
+		t.Error("the dry run imported the status of Zoom")
+	}
+	if report, pending := PendingLegacyImport(); !pending || report.Changes() != dryRun.Changes() {
+		t.Errorf("PendingLegacyImport = %d changes, %v before the import, want %d, true", report.Changes(), pending, dryRun.Changes())
+	}
+
+	report, err := ImportLegacyState(legacyDir, false)
+	if err != nil {
+		t.Fatalf("ImportLegacyState = %v", err)
+	}
+	wantStatuses := []LegacyImportItem{{Name: "New Name", LegacyName: "Old Name", Value: "installed"}, {Name: "Zoom", Value: "installed"}}
+	if !slices.Equal(report.Statuses, wantStatuses) {
+		t.Errorf("imported statuses = %+v, want %+v", report.Statuses, wantStatuses)
+	}
+	if wantSettings := []LegacyImportItem{{Name: "Show Edit button", Value: "Yes"}}; !slices.Equal(report.Settings, wantSettings) {
+		t.Errorf("imported settings = %+v, want %+v", report.Settings, wantSettings)
+	}
+	wantOverrides := []LegacyImportItem{{Name: "New Name", LegacyName: "Old Name", Value: "Games"}, {Name: "Zoom", Value: "Internet"}}
+	if !slices.Equal(report.CategoryOverrides, wantOverrides) {
+		t.Errorf("imported category overrides = %+v, want %+v", report.CategoryOverrides, wantOverrides)
+	}
+	if !slices.Equal(report.Missing, []string{"Gone"}) {
+		t.Errorf("missing apps = %q, want [Gone]", report.Missing)
+	}
+	if report.Changes() != dryRun.Changes() {
+		t.Errorf("the dry run reported %d changes, the import %d", dryRun.Changes(), report.Changes())
+	}
+
+	for path, want := range map[string]string{
+		filepath.Join(directory, "data", "status", "Zoom"):               "installed",
+		filepath.Join(directory, "data", "status", "New Name"):           "installed",
+		filepath.Join(directory, "data", "status", "Docs"):               "installed",
+		filepath.Join(directory, "data", "settings", "App List Style"):   "yad-light",
+		filepath.Join(directory, "data", "settings", "Show Edit button"): "Yes",
+		filepath.Join(directory, "data", "category-overrides"):           "New Name|Games\nZoom|Internet\n",
+	} {
+		if got, _ := os.ReadFile(path); string(got) != want {
+			t.Errorf("%s = %q, want %q", path, got, want)
+		}
+	}
+	if FileExists(filepath.Join(directory, "data", "status", "Broken")) {
+		t.Error("the corrupted status of Broken was imported")
+	}
+
+	// Importing again changes nothing, and the GUI no longer offers it
+	again, err := ImportLegacyState(legacyDir, false)
+	if err != nil || again.Changes() != 0 {
+		t.Errorf("second ImportLegacyState = %d changes, %v, want none", again.Changes(), err)
+	}
+	if _, pending := PendingLegacyImport(); pending {
+		t.Error("PendingLegacyImport still offers the import after it was done")
+	}
+}
+
+func TestIsLegacyPiAppsDir(t *testing.T) {
+	legacyDir := newTestLegacyDir(t)
+	newTestPiAppsDir(t)
+	if !IsLegacyPiAppsDir(legacyDir) {
+		t.Fatalf("IsLegacyPiAppsDir(%s) = false", legacyDir)
+	}
+
+	// A Pi-Apps Go checkout is not the bash version
+	writeTestFile(t, filepath.Join(legacyDir, "go.mod"), "module github.com/pi-apps-go/pi-apps\n")
+	if IsLegacyPiAppsDir(legacyDir) {
+		t.Error("IsLegacyPiAppsDir is true for a directory with a go.mod")
+	}
+	if _, err := ImportLegacyState(legacyDir, true); err == nil {
+		t.Error("ImportLegacyState accepted a directory that isn't the bash version")
+	}
+}
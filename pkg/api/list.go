@@ -33,7 +33,7 @@ import (
 )
 
 // ListApps lists apps based on the specified filter
-// Filters include: installed, uninstalled, corrupted, cpu_installable, hidden, visible,
+// Filters include: installed, uninstalled, corrupted, cpu_installable, available, unavailable, hidden, visible,
 // online, online_only, local, local_only, all, package, standard, have_status, missing_status, disabled
 func ListApps(filter string) ([]string, error) {
 	// Get the directory from environment variable
@@ -126,6 +126,25 @@ func ListApps(filter string) ([]string, error) {
 		// List apps that can be installed on the device's OS architecture (32-bit or 64-bit)
 		return getCPUInstallableApps(directory)
 
+	case "available", "unavailable":
+		// List apps that can (or can't) be installed on this system's architecture, OS and device
+		localApps, err := listLocalApps(directory)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list local apps: %w", err)
+		}
+		unavailable, err := UnavailableApps()
+		if err != nil {
+			return nil, err
+		}
+		var apps []string
+		for _, app := range localApps {
+			if _, ok := unavailable[app]; ok == (filter == "unavailable") {
+				apps = append(apps, app)
+			}
+		}
+		sort.Strings(apps)
+		return apps, nil
+
 	case "package":
 		// List apps that have a "packages" file
 		return getAppsWithFile(directory, "packages")
@@ -335,13 +354,16 @@ func listLocalApps(directory string) ([]string, error) {
 
 		// Only process directories directly under apps/
 		if d.IsDir() && filepath.Dir(path) == appsDir {
-			// Check if the directory contains an 'install' script, 'packages' file, or 'flatpak_packages' file
-			// This ensures that only valid applications are listed
+			// Check if the directory contains an install script for any architecture, a 'packages' file, or a
+			// 'flatpak_packages' file. This ensures that only valid applications are listed
 			installScript := filepath.Join(path, "install")
+			install32Script := filepath.Join(path, "install-32")
+			install64Script := filepath.Join(path, "install-64")
 			packagesFile := filepath.Join(path, "packages")
 			flatpakPackagesFile := filepath.Join(path, "flatpak_packages")
 
-			if checkFileExists(installScript) || checkFileExists(packagesFile) || checkFileExists(flatpakPackagesFile) {
+			if checkFileExists(installScript) || checkFileExists(install32Script) || checkFileExists(install64Script) ||
+				checkFileExists(packagesFile) || checkFileExists(flatpakPackagesFile) {
 				apps = append(apps, filepath.Base(path))
 			}
 
@@ -359,10 +381,20 @@ func listLocalApps(directory string) ([]string, error) {
 	return apps, nil
 }
 
-// listOnlineApps lists all apps available in the remote repository
+// listOnlineApps lists all apps available in the remote repository and in the extra app repositories
 func listOnlineApps(directory string) ([]string, error) {
 	updateDir := filepath.Join(directory, "update", "pi-apps", "apps")
 
+	// A sparse clone only checks out part of the apps, the others are listed from its git tree
+	if cloneDir := filepath.Dir(updateDir); IsSparseClone(cloneDir) {
+		var apps []string
+		for app := range cloneTreeApps(cloneDir) {
+			apps = append(apps, app)
+		}
+		sort.Strings(apps)
+		return append(apps, sourceOnlineApps()...), nil
+	}
+
 	// If update directory exists, use it
 	if checkFileExists(updateDir) {
 		var apps []string
@@ -390,7 +422,7 @@ func listOnlineApps(directory string) ([]string, error) {
 			return nil, err
 		}
 
-		return apps, nil
+		return append(apps, sourceOnlineApps()...), nil
 	}
 
 	// For now, return local apps as a fallback
@@ -407,13 +439,13 @@ func getAppsWithStatus(directory string, wantInstalled bool) ([]string, error) {
 	}
 
 	// Also include deprecated apps that have status files
-	deprecatedDir := filepath.Join(directory, "data", "deprecated-apps")
+	deprecatedDir := filepath.Join(DataDirOf(directory), "deprecated-apps")
 	if entries, err := os.ReadDir(deprecatedDir); err == nil {
 		for _, entry := range entries {
 			if entry.IsDir() {
 				appName := entry.Name()
 				// Check if this deprecated app has a status file
-				statusFile := filepath.Join(directory, "data", "status", appName)
+				statusFile := filepath.Join(DataDirOf(directory), "status", appName)
 				if _, err := os.Stat(statusFile); err == nil {
 					// Add to allApps if not already present
 					found := false
@@ -431,7 +463,7 @@ func getAppsWithStatus(directory string, wantInstalled bool) ([]string, error) {
 		}
 	}
 
-	statusDir := filepath.Join(directory, "data", "status")
+	statusDir := filepath.Join(DataDirOf(directory), "status")
 	if _, err := os.Stat(statusDir); os.IsNotExist(err) {
 		// If status directory doesn't exist, create it
 		if err := os.MkdirAll(statusDir, 0755); err != nil {
@@ -454,7 +486,7 @@ func getAppsWithStatus(directory string, wantInstalled bool) ([]string, error) {
 
 // getAppsWithStatusContent returns a list of apps with the specified status content
 func getAppsWithStatusContent(directory string, statusContent string) ([]string, error) {
-	statusDir := filepath.Join(directory, "data", "status")
+	statusDir := filepath.Join(DataDirOf(directory), "status")
 	if _, err := os.Stat(statusDir); os.IsNotExist(err) {
 		// If status directory doesn't exist, create it
 		if err := os.MkdirAll(statusDir, 0755); err != nil {
@@ -543,7 +575,7 @@ func getCorruptedApps(directory string) ([]string, error) {
 
 // getAppsWithStatusFiles returns a list of apps that have status files
 func getAppsWithStatusFiles(directory string) ([]string, error) {
-	statusDir := filepath.Join(directory, "data", "status")
+	statusDir := filepath.Join(DataDirOf(directory), "status")
 	if _, err := os.Stat(statusDir); os.IsNotExist(err) {
 		// If status directory doesn't exist, create it
 		if err := os.MkdirAll(statusDir, 0755); err != nil {
@@ -811,41 +843,17 @@ func getStandardApps(directory string) ([]string, error) {
 	return result, nil
 }
 
-// getCategoryApps returns a list of apps in the specified category
+// getCategoryApps lists the local apps whose category is the given one, as ReadCategoryFiles assigns them
 func getCategoryApps(directory string, category string) ([]string, error) {
-	categoryFile := filepath.Join(directory, "data", "categories", category)
-
-	// Make sure the category file exists
-	if !checkFileExists(categoryFile) {
-		// For categories that don't exist yet, create a directory
-		categoryDir := filepath.Join(directory, "data", "categories")
-		if _, err := os.Stat(categoryDir); os.IsNotExist(err) {
-			if err := os.MkdirAll(categoryDir, 0755); err != nil {
-				return nil, fmt.Errorf("failed to create categories directory: %w", err)
-			}
-		}
-
-		// Create an empty category file
-		if err := os.WriteFile(categoryFile, []byte{}, 0644); err != nil {
-			return nil, fmt.Errorf("failed to create category file: %w", err)
-		}
-
-		// Return an empty list
-		return []string{}, nil
-	}
-
-	// Read app names from category file
-	data, err := os.ReadFile(categoryFile)
+	categories, err := readCategoryFiles(directory)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read category file: %w", err)
+		return nil, err
 	}
 
-	var apps []string
-	scanner := bufio.NewScanner(strings.NewReader(string(data)))
-	for scanner.Scan() {
-		appName := scanner.Text()
-		if appName != "" {
-			apps = append(apps, appName)
+	apps := []string{}
+	for app, appCategory := range categories {
+		if appCategory == category && checkFileExists(filepath.Join(directory, "apps", app)) {
+			apps = append(apps, app)
 		}
 	}
 
@@ -853,56 +861,24 @@ func getCategoryApps(directory string, category string) ([]string, error) {
 	return apps, nil
 }
 
-// readCategoryFiles reads all app category assignments
+// readCategoryFiles returns the category of every app ReadCategoryFiles lists, "" for apps without one
 func readCategoryFiles(directory string) (map[string]string, error) {
-	categoryDir := filepath.Join(directory, "data", "categories")
-
-	// Make sure the category directory exists
-	if _, err := os.Stat(categoryDir); os.IsNotExist(err) {
-		// Create the categories directory
-		if err := os.MkdirAll(categoryDir, 0755); err != nil {
-			return nil, fmt.Errorf("failed to create categories directory: %w", err)
-		}
-
-		// Return an empty map
-		return make(map[string]string), nil
-	}
-
-	// Read all category files
-	entries, err := os.ReadDir(categoryDir)
+	entries, err := ReadCategoryFiles(directory)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read category directory: %w", err)
+		return nil, err
 	}
 
-	result := make(map[string]string)
-
+	result := make(map[string]string, len(entries))
 	for _, entry := range entries {
-		if !entry.IsDir() {
-			categoryFilePath := filepath.Join(categoryDir, entry.Name())
-			categoryName := entry.Name()
-
-			// Read app names from category file
-			data, err := os.ReadFile(categoryFilePath)
-			if err != nil {
-				return nil, fmt.Errorf("failed to read category file %s: %w", categoryName, err)
-			}
-
-			scanner := bufio.NewScanner(strings.NewReader(string(data)))
-			for scanner.Scan() {
-				appName := scanner.Text()
-				if appName != "" {
-					result[appName] = categoryName
-				}
-			}
-		}
+		app, category, _ := strings.Cut(entry, "|")
+		result[app] = category
 	}
-
 	return result, nil
 }
 
 // checkAppInstalled checks if an app is installed
 func checkAppInstalled(directory, app string) bool {
-	statusFile := filepath.Join(directory, "data", "status", app)
+	statusFile := filepath.Join(DataDirOf(directory), "status", app)
 	return checkFileExists(statusFile)
 }
 
@@ -914,64 +890,47 @@ func checkFileExists(path string) bool {
 
 // ReadCategoryFiles generates a combined categories-list from several sources:
 // category-overrides, device-specific overrides, global categories file, and unlisted apps. Format: "app|category"
+//
+// The first source assigning an app wins, so the user's overrides always win over the upstream lists. Within the
+// overrides file the last line for an app wins, like when categoryedit reads it, and an app listed in several
+// data/categories files is in the first one by name. Apps are listed in the order of the source that assigned them.
 func ReadCategoryFiles(directory string) ([]string, error) {
 	var result []string
 	seen := make(map[string]bool)
 
 	// First, clean up category-overrides file by removing apps that no longer exist
 	// (matching bash behavior: remove app category if app folder not found)
-	userOverridesFile := filepath.Join(directory, "data", "category-overrides")
-	if checkFileExists(userOverridesFile) {
-		data, err := os.ReadFile(userOverridesFile)
-		if err == nil {
-			var validLines []string
-			scanner := bufio.NewScanner(strings.NewReader(string(data)))
-			for scanner.Scan() {
-				line := strings.TrimSpace(scanner.Text())
-				if line == "" || strings.HasPrefix(line, "#") {
-					validLines = append(validLines, line)
-					continue
-				}
-
-				parts := strings.Split(line, "|")
-				if len(parts) >= 1 {
-					appName := strings.TrimSpace(parts[0])
-					appDir := filepath.Join(directory, "apps", appName)
-					// Only keep the line if the app directory exists
-					if checkFileExists(appDir) {
-						validLines = append(validLines, line)
-					}
-				}
+	userOverridesFile := filepath.Join(DataDirOf(directory), "category-overrides")
+	if data, err := os.ReadFile(userOverridesFile); err == nil {
+		var validLines []string
+		removed := false
+		scanner := bufio.NewScanner(strings.NewReader(string(data)))
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" || strings.HasPrefix(line, "#") {
+				validLines = append(validLines, line)
+				continue
 			}
-			// Write back the cleaned file if any lines were removed
-			if len(validLines) != len(strings.Split(string(data), "\n")) {
-				os.WriteFile(userOverridesFile, []byte(strings.Join(validLines, "\n")+"\n"), 0644)
+
+			appName, _, _ := strings.Cut(line, "|")
+			// Only keep the line if the app directory exists
+			if checkFileExists(filepath.Join(directory, "apps", strings.TrimSpace(appName))) {
+				validLines = append(validLines, line)
+			} else {
+				removed = true
 			}
 		}
+		// Write back the cleaned file if any lines were removed
+		if removed {
+			os.WriteFile(userOverridesFile, []byte(strings.Join(validLines, "\n")+"\n"), 0644)
+		}
 	}
 
 	// First read category-overrides file (user overrides take precedence)
-	if checkFileExists(userOverridesFile) {
-		data, err := os.ReadFile(userOverridesFile)
-		if err == nil {
-			scanner := bufio.NewScanner(strings.NewReader(string(data)))
-			for scanner.Scan() {
-				line := strings.TrimSpace(scanner.Text())
-				if line == "" || strings.HasPrefix(line, "#") {
-					continue
-				}
-
-				parts := strings.Split(line, "|")
-				if len(parts) >= 2 {
-					appName := strings.TrimSpace(parts[0])
-					categoryName := strings.TrimSpace(parts[1])
-					if appName != "" && !seen[appName] {
-						result = append(result, appName+"|"+categoryName)
-						seen[appName] = true
-					}
-				}
-			}
-		}
+	overrides, order := readCategoryOverrides(userOverridesFile)
+	for _, appName := range order {
+		result = append(result, appName+"|"+overrides[appName])
+		seen[appName] = true
 	}
 
 	// Then read device-specific category overrides (from embedded structured data)
@@ -991,37 +950,10 @@ func ReadCategoryFiles(directory string) ([]string, error) {
 	}
 
 	// Also read individual category files from data/categories directory (compatibility)
-	categoriesDir := filepath.Join(directory, "data", "categories")
-	if checkFileExists(categoriesDir) {
-		entries, err := os.ReadDir(categoriesDir)
-		if err == nil {
-			for _, entry := range entries {
-				if entry.IsDir() {
-					continue
-				}
-
-				categoryName := entry.Name()
-				categoryFile := filepath.Join(categoriesDir, categoryName)
-
-				// Read the apps in this category
-				data, err := os.ReadFile(categoryFile)
-				if err != nil {
-					continue
-				}
-
-				scanner := bufio.NewScanner(strings.NewReader(string(data)))
-				for scanner.Scan() {
-					appName := strings.TrimSpace(scanner.Text())
-					if appName == "" {
-						continue
-					}
-
-					if !seen[appName] {
-						result = append(result, appName+"|"+categoryName)
-						seen[appName] = true
-					}
-				}
-			}
+	for _, assignment := range readCategoryDir(directory) {
+		if !seen[assignment.AppName] {
+			result = append(result, assignment.AppName+"|"+assignment.Category)
+			seen[assignment.AppName] = true
 		}
 	}
 
@@ -1039,20 +971,71 @@ func ReadCategoryFiles(directory string) ([]string, error) {
 	return result, nil
 }
 
+// readCategoryOverrides reads the user's category-overrides file. The last line of an app wins, like in
+// readCategoryFile, and the apps are listed in the order they first appear.
+func readCategoryOverrides(path string) (map[string]string, []string) {
+	overrides := make(map[string]string)
+	var order []string
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return overrides, nil
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		appName, category, found := strings.Cut(line, "|")
+		appName = strings.TrimSpace(appName)
+		if !found || appName == "" {
+			continue
+		}
+		if _, exists := overrides[appName]; !exists {
+			order = append(order, appName)
+		}
+		overrides[appName] = strings.TrimSpace(category)
+	}
+	return overrides, order
+}
+
+// readCategoryDir reads the category files of data/categories, one per category listing its apps, in the order of
+// their names
+func readCategoryDir(directory string) []CategoryAssignment {
+	categoriesDir := filepath.Join(DataDirOf(directory), "categories")
+	entries, err := os.ReadDir(categoriesDir)
+	if err != nil {
+		return nil
+	}
+
+	var assignments []CategoryAssignment
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		// Read the apps in this category
+		data, err := os.ReadFile(filepath.Join(categoriesDir, entry.Name()))
+		if err != nil {
+			continue
+		}
+
+		scanner := bufio.NewScanner(strings.NewReader(string(data)))
+		for scanner.Scan() {
+			if appName := strings.TrimSpace(scanner.Text()); appName != "" {
+				assignments = append(assignments, CategoryAssignment{AppName: appName, Category: entry.Name()})
+			}
+		}
+	}
+	return assignments
+}
+
 // AppPrefixCategory lists all apps in a category with format "category/app",
 // or if category is left blank, then list the full structure of all categories
 func AppPrefixCategory(directory, category string) ([]string, error) {
 	var result []string
 
 	// Get the "Show apps" setting
-	showAppsSetting := ""
-	settingsFile := filepath.Join(directory, "data", "settings", "Show apps")
-	if checkFileExists(settingsFile) {
-		data, err := os.ReadFile(settingsFile)
-		if err == nil {
-			showAppsSetting = strings.TrimSpace(string(data))
-		}
-	}
+	showAppsSetting := ReadSettingIn(directory, "Show apps")
 
 	// Prepare filter function based on settings
 	filterApps := func(apps []string) ([]string, error) {
@@ -1100,7 +1083,7 @@ func AppPrefixCategory(directory, category string) ([]string, error) {
 	switch category {
 	case "Deprecated":
 		// Show special "Deprecated" category
-		deprecatedDir := filepath.Join(directory, "data", "deprecated-apps")
+		deprecatedDir := filepath.Join(DataDirOf(directory), "deprecated-apps")
 		var deprecatedApps []string
 		if entries, err := os.ReadDir(deprecatedDir); err == nil {
 			for _, entry := range entries {
@@ -1266,7 +1249,7 @@ func AppPrefixCategory(directory, category string) ([]string, error) {
 		result = append(result, filteredAllAppsResult...)
 
 		// Add special "Deprecated" category if there are any deprecated apps
-		deprecatedDir := filepath.Join(directory, "data", "deprecated-apps")
+		deprecatedDir := filepath.Join(DataDirOf(directory), "deprecated-apps")
 		if entries, err := os.ReadDir(deprecatedDir); err == nil {
 			hasDeprecatedApps := false
 			for _, entry := range entries {
@@ -1325,6 +1308,11 @@ func AppPrefixCategory(directory, category string) ([]string, error) {
 		result = append(result, filteredResult...)
 	}
 
+	// Kiosk mode only shows the apps its configuration allows
+	if kiosk := ActiveKiosk(); kiosk != nil {
+		result = kiosk.filterAppPaths(result)
+	}
+
 	sort.Strings(result)
 	return result, nil
 }
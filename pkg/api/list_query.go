@@ -0,0 +1,172 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: list_query.go
+// Description: Provides structured, combinable filters and pagination for listing apps.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package api
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+)
+
+// AppListQuery holds the filters of QueryApps, empty fields match every app
+type AppListQuery struct {
+	Status         string // installed, uninstalled, corrupted or disabled
+	Category       string // category as shown in the app list, "hidden" for hidden apps
+	Visibility     string // hidden or visible
+	Type           string // standard, package or flatpak_package
+	ArchCompatible bool   // only apps that have an install script or package for the current architecture
+	Available      bool   // only apps that can be installed on this system's architecture, OS and device, see UnavailableApps
+
+	Offset int // number of matching apps to skip
+	Limit  int // maximum number of apps to return, 0 for no limit
+}
+
+// AppListEntry describes an app returned by QueryApps
+type AppListEntry struct {
+	Name     string `json:"name"`
+	Status   string `json:"status"`
+	Category string `json:"category"`
+	Type     string `json:"type"`
+}
+
+// QueryApps lists the local apps matching all filters of the query, sorted by name
+//
+// Statuses, categories, architecture compatibility and availability are each read once for all apps.
+//
+//	[]AppListEntry - matching apps
+//	error - error if PI_APPS_DIR environment variable is not set or a filter value is unknown
+func QueryApps(query AppListQuery) ([]AppListEntry, error) {
+	directory := GetPiAppsDir()
+	if directory == "" {
+		return nil, fmt.Errorf("PI_APPS_DIR environment variable not set")
+	}
+
+	switch query.Status {
+	case "", "installed", "uninstalled", "corrupted", "disabled":
+	default:
+		return nil, fmt.Errorf("unknown status filter: %s", query.Status)
+	}
+	switch query.Visibility {
+	case "", "hidden", "visible":
+	default:
+		return nil, fmt.Errorf("unknown visibility filter: %s", query.Visibility)
+	}
+	switch query.Type {
+	case "", "standard", "package", "flatpak_package":
+	default:
+		return nil, fmt.Errorf("unknown type filter: %s", query.Type)
+	}
+
+	apps, err := listLocalApps(directory)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list local apps: %w", err)
+	}
+	sort.Strings(apps)
+
+	statuses, err := GetAllAppStatuses()
+	if err != nil {
+		return nil, err
+	}
+	categories, err := readCategoryFiles(directory)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read category files: %w", err)
+	}
+
+	var compatible map[string]bool
+	if query.ArchCompatible {
+		installable, err := getCPUInstallableApps(directory)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get installable apps: %w", err)
+		}
+		compatible = make(map[string]bool, len(installable))
+		for _, app := range installable {
+			compatible[app] = true
+		}
+	}
+
+	var unavailable map[string]string
+	if query.Available {
+		unavailable, err = UnavailableApps()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var entries []AppListEntry
+	skipped := 0
+	for _, app := range apps {
+		entry := AppListEntry{
+			Name:     app,
+			Status:   string(statuses[app]),
+			Category: categories[app],
+			Type:     localAppType(directory, app),
+		}
+		if entry.Status == "" {
+			entry.Status = string(AppStateUninstalled)
+		}
+
+		if query.Status != "" && entry.Status != query.Status {
+			continue
+		}
+		if query.Category != "" && entry.Category != query.Category {
+			continue
+		}
+		if query.Visibility == "hidden" && entry.Category != "hidden" {
+			continue
+		}
+		if query.Visibility == "visible" && entry.Category == "hidden" {
+			continue
+		}
+		if query.Type != "" && entry.Type != query.Type {
+			continue
+		}
+		if query.ArchCompatible && !compatible[app] {
+			continue
+		}
+		if _, ok := unavailable[app]; ok {
+			continue
+		}
+
+		if skipped < query.Offset {
+			skipped++
+			continue
+		}
+		entries = append(entries, entry)
+		if query.Limit > 0 && len(entries) >= query.Limit {
+			break
+		}
+	}
+
+	return entries, nil
+}
+
+// localAppType returns the type of a local app from the files in its directory, without AppType's error for broken apps
+func localAppType(directory, app string) string {
+	appDir := filepath.Join(directory, "apps", app)
+	switch {
+	case checkFileExists(filepath.Join(appDir, "packages")):
+		return "package"
+	case checkFileExists(filepath.Join(appDir, "flatpak_packages")):
+		return "flatpak_package"
+	default:
+		return "standard"
+	}
+}
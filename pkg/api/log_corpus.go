@@ -0,0 +1,177 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: log_corpus.go
+// Description: Replays a corpus of failure logs through LogDiagnose and compares the classifications with the expected ones.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// A log corpus is a folder of failure logs, each <name>.log paired with a <name>.json holding its expected
+// LogClassification. Logs in a corpus must be anonymized, see AnonymizeLog.
+
+// LogClassification is what LogDiagnose made of a log: its error type and the IDs of its captions, see CaptionID
+type LogClassification struct {
+	ErrorType string   `json:"error_type"`
+	Captions  []string `json:"captions"`
+}
+
+// LogCorpusResult is the replay of one log of a corpus
+type LogCorpusResult struct {
+	Name     string             // name of the log without .log
+	Expected *LogClassification // nil if the log has no expectation file yet
+	Got      LogClassification
+	Err      error // error reading the expectation or diagnosing the log
+}
+
+// captionIDWords is the number of words of a caption that make its ID
+const captionIDWords = 8
+
+var captionIDWord = regexp.MustCompile(`[a-z0-9]+`)
+
+// CaptionID returns the ID of a diagnosis caption: its first words in lowercase joined with dashes, like
+// "apt-reported-a-double-configured-repository-and-you". Rewording the end of a caption keeps its ID.
+func CaptionID(caption string) string {
+	return strings.Join(captionIDWord.FindAllString(strings.ToLower(caption), captionIDWords), "-")
+}
+
+// ClassifyDiagnosis returns the classification of a diagnosis
+func ClassifyDiagnosis(diagnosis *ErrorDiagnosis) LogClassification {
+	classification := LogClassification{ErrorType: diagnosis.ErrorType, Captions: []string{}}
+	for _, caption := range diagnosis.Captions {
+		classification.Captions = append(classification.Captions, CaptionID(caption))
+	}
+	return classification
+}
+
+// ReplayLogCorpus runs LogDiagnose over every log of a corpus folder, in the order of their names
+func ReplayLogCorpus(dir string) ([]LogCorpusResult, error) {
+	logs, err := filepath.Glob(filepath.Join(dir, "*.log"))
+	if err != nil {
+		return nil, err
+	}
+	if len(logs) == 0 {
+		return nil, fmt.Errorf("no logs in %s", dir)
+	}
+	slices.Sort(logs)
+
+	var results []LogCorpusResult
+	for _, log := range logs {
+		result := LogCorpusResult{Name: strings.TrimSuffix(filepath.Base(log), ".log")}
+		if data, err := os.ReadFile(strings.TrimSuffix(log, ".log") + ".json"); err == nil {
+			var expected LogClassification
+			if err := json.Unmarshal(data, &expected); err != nil {
+				result.Err = fmt.Errorf("failed to read the expectation of %s: %w", result.Name, err)
+			}
+			result.Expected = &expected
+		} else if !os.IsNotExist(err) {
+			result.Err = err
+		}
+
+		diagnosis, err := LogDiagnose(log, false)
+		if err != nil {
+			result.Err = fmt.Errorf("failed to diagnose %s: %w", result.Name, err)
+		} else {
+			result.Got = ClassifyDiagnosis(diagnosis)
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// Diff returns the differences between the expected and the current classification of the log, none if they match
+func (r LogCorpusResult) Diff() []string {
+	if r.Err != nil {
+		return []string{r.Err.Error()}
+	}
+	if r.Expected == nil {
+		return []string{fmt.Sprintf("no expectation file, error type %q with captions %s", r.Got.ErrorType, strings.Join(r.Got.Captions, ", "))}
+	}
+	var diff []string
+	if r.Expected.ErrorType != r.Got.ErrorType {
+		diff = append(diff, fmt.Sprintf("error type %q, expected %q", r.Got.ErrorType, r.Expected.ErrorType))
+	}
+	for _, caption := range r.Expected.Captions {
+		if !slices.Contains(r.Got.Captions, caption) {
+			diff = append(diff, "missing caption "+caption)
+		}
+	}
+	for _, caption := range r.Got.Captions {
+		if !slices.Contains(r.Expected.Captions, caption) {
+			diff = append(diff, "unexpected caption "+caption)
+		}
+	}
+	return diff
+}
+
+// WriteLogCorpusExpectation saves the current classification of a corpus log as its expectation
+func WriteLogCorpusExpectation(dir string, result LogCorpusResult) error {
+	data, err := json.MarshalIndent(result.Got, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, result.Name+".json"), append(data, '\n'), 0644)
+}
+
+var (
+	// homeDirPattern matches the home folder of a user in paths
+	homeDirPattern = regexp.MustCompile(`/home/[^/\s'"]+`)
+	// shellPromptPattern matches the user@host: of shell prompts, but not the git@github.com: of git URLs
+	shellPromptPattern = regexp.MustCompile(`\b[a-z_][a-z0-9_-]*@[A-Za-z0-9-]+:`)
+	// hashedIDPattern matches the hashed machine ID and serial number of the device info of a log
+	hashedIDPattern = regexp.MustCompile(`(?m)^((?:Machine-id|Serial-number) \(hashed\): ).*$`)
+)
+
+// AnonymizeLog removes the user name and hostname of this system from a log, and the other names given, so it can
+// be added to a log corpus. Home folders and shell prompts are anonymized whoever they belong to. Names shorter than
+// 3 letters and root are left alone, they would replace parts of words and aren't personal.
+func AnonymizeLog(content string, names ...string) string {
+	content = homeDirPattern.ReplaceAllString(content, "/home/user")
+	content = shellPromptPattern.ReplaceAllString(content, "user@host:")
+	content = hashedIDPattern.ReplaceAllString(content, "${1}0000000000")
+
+	var replacements [][2]string
+	for _, name := range names {
+		replacements = append(replacements, [2]string{name, "redacted"})
+	}
+	if current, err := user.Current(); err == nil {
+		replacements = append(replacements, [2]string{current.Username, "user"})
+	}
+	if username := os.Getenv("USER"); username != "" {
+		replacements = append(replacements, [2]string{username, "user"})
+	}
+	if hostname, err := os.Hostname(); err == nil {
+		replacements = append(replacements, [2]string{hostname, "host"})
+	}
+	for _, replacement := range replacements {
+		if len(replacement[0]) < 3 || replacement[0] == "root" {
+			continue
+		}
+		content = regexp.MustCompile(`\b`+regexp.QuoteMeta(replacement[0])+`\b`).ReplaceAllString(content, replacement[1])
+	}
+	return content
+}
@@ -0,0 +1,45 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+//go:build apt
+
+package api
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestLogCorpus runs LogDiagnose over the failure logs of testdata/log-corpus/apt, see its README for adding logs
+func TestLogCorpus(t *testing.T) {
+	newTestPiAppsDir(t)
+	// Rules that look at this system or repair it find no commands to run
+	t.Setenv("PATH", "")
+	t.Setenv("USER", "user")
+	t.Setenv("HOME", "/home/user")
+
+	results, err := ReplayLogCorpus("testdata/log-corpus/apt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, result := range results {
+		t.Run(result.Name, func(t *testing.T) {
+			if diff := result.Diff(); len(diff) > 0 {
+				t.Errorf("%s, run api diagnose_replay to compare while changing rules", strings.Join(diff, "; "))
+			}
+		})
+	}
+}
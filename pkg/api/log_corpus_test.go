@@ -0,0 +1,81 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestCaptionID(t *testing.T) {
+	for caption, want := range map[string]string{
+		"APT reported a broken package.\n\nPlease run this command: sudo apt --fix-broken install": "apt-reported-a-broken-package-please-run-this",
+		"The git command encountered this error: \"fetch-pack: unexpected disconnect\"":            "the-git-command-encountered-this-error-fetch-pack",
+		"Short caption": "short-caption",
+		"":              "",
+	} {
+		if got := CaptionID(caption); got != want {
+			t.Errorf("CaptionID(%q) = %q, want %q", caption, got, want)
+		}
+	}
+}
+
+func TestLogCorpusResultDiff(t *testing.T) {
+	result := LogCorpusResult{
+		Name:     "example",
+		Expected: &LogClassification{ErrorType: "package", Captions: []string{"kept", "dropped"}},
+		Got:      LogClassification{ErrorType: "system", Captions: []string{"kept", "added"}},
+	}
+	want := []string{`error type "system", expected "package"`, "missing caption dropped", "unexpected caption added"}
+	if got := result.Diff(); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Diff() = %q, want %q", got, want)
+	}
+
+	result.Got = *result.Expected
+	if got := result.Diff(); len(got) != 0 {
+		t.Errorf("Diff() of a matching log = %q", got)
+	}
+}
+
+func TestAnonymizeLog(t *testing.T) {
+	t.Setenv("USER", "alice")
+	hostname, err := os.Hostname()
+	if err != nil {
+		t.Fatal(err)
+	}
+	log := "Machine-id (hashed): 3f2a9c\n" +
+		"cp: cannot stat '/home/bob/Downloads/app.deb'\n" +
+		"alice wrote to /tmp/alice.log on " + hostname + "\n" +
+		"carol@raspberrypi:~ $ pi-apps\n" +
+		"git clone git@github.com:pi-apps-go/pi-apps.git for dave\n"
+	got := AnonymizeLog(log, "dave")
+
+	for _, leak := range []string{"3f2a9c", "bob", "alice", "carol", "raspberrypi", "dave"} {
+		if strings.Contains(got, leak) {
+			t.Errorf("anonymized log still contains %q:\n%s", leak, got)
+		}
+	}
+	if len(hostname) >= 3 && strings.Contains(got, hostname) {
+		t.Errorf("anonymized log still contains the hostname %q:\n%s", hostname, got)
+	}
+	for _, kept := range []string{"/home/user/Downloads/app.deb", "user@host:~ $ pi-apps", "git@github.com:pi-apps-go/pi-apps.git"} {
+		if !strings.Contains(got, kept) {
+			t.Errorf("anonymized log lacks %q:\n%s", kept, got)
+		}
+	}
+}
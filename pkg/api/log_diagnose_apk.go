@@ -592,6 +592,25 @@ func LogDiagnose(logfilePath string, allowWrite bool) (*ErrorDiagnosis, error) {
 		diagnosis.ErrorType = "system"
 	}
 
+	// check for apps that failed to open a window in the current display session
+	regexDisplaySession := regexp.MustCompile(`(?i)cannot open display|can't open display|unable to open display|could not connect to display|Failed to connect to Wayland display|failed to connect to the wayland display|Could not load the Qt platform plugin "(?:xcb|wayland)"`)
+	if regexDisplaySession.MatchString(errors) {
+		session := DisplaySessionInfo()
+		if session.IsWayland() {
+			diagnosis.Captions = append(diagnosis.Captions,
+				"A program failed to open a window because it needs an X11 session, but you are using a Wayland session"+xwaylandNote(session)+". \n\n"+
+					"You can switch to an X11 session: on Raspberry Pi OS, run 'sudo raspi-config' and choose Advanced Options -> Wayland -> X11, then reboot. "+
+					"On other distros, log out and pick an \"Xorg\" or \"X11\" session on the login screen before logging in again.")
+		} else {
+			diagnosis.Captions = append(diagnosis.Captions,
+				"A program failed to open a window on your display ("+session.String()+"). \n\n"+
+					"If this app needs a Wayland session, you can switch to one: on Raspberry Pi OS, run 'sudo raspi-config' and choose Advanced Options -> Wayland -> labwc, then reboot. "+
+					"On other distros, log out and pick a \"Wayland\" session on the login screen before logging in again. \n\n"+
+					"If you are installing over SSH, run the install from a terminal on the desktop instead.")
+		}
+		diagnosis.ErrorType = "system"
+	}
+
 	// check for "Error: Failed to read commit .* No such metadata object\|error: Failed to install org.freedesktop.Platform: Failed to read commit .* No such metadata object\|Error: Error deploying: .* No such metadata object"
 	regexFlatpak := regexp.MustCompile(`Error: Failed to read commit .* No such metadata object|error: Failed to install org\.freedesktop\.Platform: Failed to read commit .* No such metadata object|Error: Error deploying: .* No such metadata object`)
 	if regexFlatpak.MatchString(errors) {
@@ -821,6 +840,15 @@ func LogDiagnose(logfilePath string, allowWrite bool) (*ErrorDiagnosis, error) {
 		diagnosis.ErrorType = "unknown" // Allows error reporting
 	}
 
+	// A pinned script that changed upstream is up to the app maintainers, not the system
+	applyScriptChangedHint(diagnosis, errors)
+
+	// Point at the parallel job count when a compile ran out of memory
+	applyCompileJobsHint(diagnosis, errors)
+
+	// Use the exit code the script recorded as a hint for the error type
+	applyExitCodeHint(diagnosis, errors)
+
 	// If no error type was set, default to "unknown" (allows error reporting)
 	if diagnosis.ErrorType == "" {
 		diagnosis.ErrorType = "unknown"
@@ -73,37 +73,11 @@ func LogDiagnose(logfilePath string, allowWrite bool) (*ErrorDiagnosis, error) {
 	// Get OS info dynamically (don't rely on global variables which may not be initialized)
 	osID, _, versionID := getOSInfoWithVersion()
 
-	// ALWAYS try to parse OS info from log file first (not just as fallback)
-	// When analyzing a log file, the OS info in the log is authoritative
+	// The OS info in the log file is authoritative, the log may have been written on another system
 	// Log files contain "OS: Debian GNU/Linux 13 (trixie)" on the first line
 	if strings.HasPrefix(errors, "OS:") {
-		scanner := bufio.NewScanner(strings.NewReader(errors))
-		if scanner.Scan() {
-			firstLine := scanner.Text()
-			// Parse "OS: Debian GNU/Linux 13 (trixie)" format
-			// Check for Trixie explicitly in the log
-			if strings.Contains(strings.ToLower(firstLine), "trixie") {
-				if strings.Contains(firstLine, "Raspbian") {
-					osID = "Raspbian"
-				} else if strings.Contains(firstLine, "Debian") {
-					osID = "Debian"
-				}
-				// Extract version number after "GNU/Linux" or just look for "13"
-				// Pattern: "GNU/Linux 13" or just "13" followed by "(trixie)"
-				versionRegex := regexp.MustCompile(`GNU/Linux\s+(\d+)`)
-				matches := versionRegex.FindStringSubmatch(firstLine)
-				if len(matches) > 1 {
-					versionID = matches[1]
-				} else {
-					// Fallback: look for "13" before "(trixie)"
-					versionRegex2 := regexp.MustCompile(`(\d+)\s*\(trixie\)`)
-					matches2 := versionRegex2.FindStringSubmatch(firstLine)
-					if len(matches2) > 1 {
-						versionID = matches2[1]
-					}
-				}
-			}
-		}
+		firstLine, _, _ := strings.Cut(errors, "\n")
+		osID, versionID = logOSInfo(firstLine)
 	}
 
 	// Parse version ID as number for comparison (matches original bash: [ "$__os_release" -ge 13 ])
@@ -154,8 +128,16 @@ func LogDiagnose(logfilePath string, allowWrite bool) (*ErrorDiagnosis, error) {
 		diagnosis.ErrorType = "system"
 	}
 
-	// Check for 'NO_PUBKEY' or ' is no longer signed.'
-	if strings.Contains(errors, "NO_PUBKEY") ||
+	// Check for 'EXPKEYSIG', the signing key of a repository expired. The repository is then reported as no longer signed too.
+	if strings.Contains(errors, "EXPKEYSIG") {
+		diagnosis.Captions = append(diagnosis.Captions,
+			"APT reported a repository whose signing key expired. This has to be solved before APT or Pi-Apps, will work.\n\n"+
+				"If an app added the repository, Pi-Apps can download its key again from where the app got it. "+
+				"Use 'Renew repository keys' in the Maintenance page of the Pi-Apps settings, or run this command in a terminal:\n"+
+				filepath.Join(GetPiAppsDir(), "api-go")+" repo_keys --renew\n\n"+
+				"Otherwise get the new key from the website of the repository, or remove the repository from /etc/apt/sources.list.d.")
+		diagnosis.ErrorType = "system"
+	} else if strings.Contains(errors, "NO_PUBKEY") ||
 		strings.Contains(errors, " is no longer signed.") {
 		diagnosis.Captions = append(diagnosis.Captions,
 			"APT reported an unsigned repository. This has to be solved before APT or Pi-Apps, will work.\n\n"+
@@ -246,16 +228,7 @@ func LogDiagnose(logfilePath string, allowWrite bool) (*ErrorDiagnosis, error) {
 	// repo issues above, apt/dpkg issues below
 	//------------------------------------------
 
-	// check for "--fix-broken"
-	regexFixBroken := regexp.MustCompile(`--fix-broken`)
-	if regexFixBroken.MatchString(errors) {
-		diagnosis.Captions = append(diagnosis.Captions,
-			"APT reported a broken package.\n\n"+
-				"Please run this command: sudo apt --fix-broken install")
-		diagnosis.ErrorType = "package"
-	}
-
-	// Check for apt/dpkg issues
+	// Check for apt/dpkg issues, like "--fix-broken"
 	if strings.Contains(errors, "--fix-broken") ||
 		strings.Contains(errors, "needs to be reinstalled") {
 		diagnosis.Captions = append(diagnosis.Captions,
@@ -563,14 +536,14 @@ func LogDiagnose(logfilePath string, allowWrite bool) (*ErrorDiagnosis, error) {
 
 					// Check if any of the packages are on hold
 					if diagnosis.ErrorType == "" && len(matchesCase2) > 0 {
-						showOutput, _ := runCommand("apt-cache", append([]string{"show"}, matchesCase2...)...)
-						if strings.Contains(showOutput, "Status: hold ok installed") {
-							pkgList := strings.Join(matchesCase2, "\n")
+						// Holds placed through PackageHold are known to Pi-Apps and not something the user has to be told about
+						if held := unknownHeldPackages(matchesCase2); len(held) > 0 {
+							pkgList := strings.Join(held, "\n")
 							diagnosis.Captions = append(diagnosis.Captions,
 								"Packages failed to install because you manually marked at least one of the following packages as held:\n\n"+
 									pkgList+"\n\n"+
 									"You will need to unmark the packages with the following command before installation can proceed:\n"+
-									"sudo apt-mark unhold "+strings.Join(matchesCase2, " "))
+									"sudo apt-mark unhold "+strings.Join(held, " "))
 							diagnosis.ErrorType = "system"
 						}
 					}
@@ -667,14 +640,14 @@ func LogDiagnose(logfilePath string, allowWrite bool) (*ErrorDiagnosis, error) {
 
 					// Check if any of the packages are on hold
 					if diagnosis.ErrorType == "" {
-						showOutput, _ := runCommand("apt-cache", append([]string{"show"}, packagesCase3...)...)
-						if strings.Contains(showOutput, "Status: hold ok installed") {
-							pkgList := strings.Join(packagesCase3, "\n")
+						// Holds placed through PackageHold are known to Pi-Apps and not something the user has to be told about
+						if held := unknownHeldPackages(packagesCase3); len(held) > 0 {
+							pkgList := strings.Join(held, "\n")
 							diagnosis.Captions = append(diagnosis.Captions,
 								"Packages failed to install because you manually marked at least one of the following packages as held:\n\n"+
 									pkgList+"\n\n"+
 									"You will need to unmark the packages with the following command before installation can proceed:\n"+
-									"sudo apt-mark unhold "+strings.Join(packagesCase3, " "))
+									"sudo apt-mark unhold "+strings.Join(held, " "))
 							diagnosis.ErrorType = "system"
 						}
 					}
@@ -693,14 +666,14 @@ func LogDiagnose(logfilePath string, allowWrite bool) (*ErrorDiagnosis, error) {
 				// Check for held packages
 				if len(matchesCase1) > 0 {
 					// Check if any of the packages are on hold
-					showOutput, _ := runCommand("apt-cache", append([]string{"show"}, matchesCase1...)...)
-					if strings.Contains(showOutput, "Status: hold ok installed") {
-						pkgList := strings.Join(matchesCase1, "\n")
+					// Holds placed through PackageHold are known to Pi-Apps and not something the user has to be told about
+					if held := unknownHeldPackages(matchesCase1); len(held) > 0 {
+						pkgList := strings.Join(held, "\n")
 						diagnosis.Captions = append(diagnosis.Captions,
 							"Packages failed to install because you manually marked at least one of the following packages as held:\n\n"+
 								pkgList+"\n\n"+
 								"You will need to unmark the packages with the following command before installation can proceed:\n"+
-								"sudo apt-mark unhold "+strings.Join(matchesCase1, " "))
+								"sudo apt-mark unhold "+strings.Join(held, " "))
 						diagnosis.ErrorType = "system"
 					}
 				}
@@ -1795,6 +1768,25 @@ func LogDiagnose(logfilePath string, allowWrite bool) (*ErrorDiagnosis, error) {
 		diagnosis.ErrorType = "system"
 	}
 
+	// check for apps that failed to open a window in the current display session
+	regexDisplaySession := regexp.MustCompile(`(?i)cannot open display|can't open display|unable to open display|could not connect to display|Failed to connect to Wayland display|failed to connect to the wayland display|Could not load the Qt platform plugin "(?:xcb|wayland)"`)
+	if regexDisplaySession.MatchString(errors) {
+		session := DisplaySessionInfo()
+		if session.IsWayland() {
+			diagnosis.Captions = append(diagnosis.Captions,
+				"A program failed to open a window because it needs an X11 session, but you are using a Wayland session"+xwaylandNote(session)+". \n\n"+
+					"You can switch to an X11 session: on Raspberry Pi OS, run 'sudo raspi-config' and choose Advanced Options -> Wayland -> X11, then reboot. "+
+					"On other distros, log out and pick an \"Xorg\" or \"X11\" session on the login screen before logging in again.")
+		} else {
+			diagnosis.Captions = append(diagnosis.Captions,
+				"A program failed to open a window on your display ("+session.String()+"). \n\n"+
+					"If this app needs a Wayland session, you can switch to one: on Raspberry Pi OS, run 'sudo raspi-config' and choose Advanced Options -> Wayland -> labwc, then reboot. "+
+					"On other distros, log out and pick a \"Wayland\" session on the login screen before logging in again. \n\n"+
+					"If you are installing over SSH, run the install from a terminal on the desktop instead.")
+		}
+		diagnosis.ErrorType = "system"
+	}
+
 	// check for "Error: Failed to read commit .* No such metadata object\|error: Failed to install org.freedesktop.Platform: Failed to read commit .* No such metadata object\|Error: Error deploying: .* No such metadata object"
 	regexFlatpak := regexp.MustCompile(`Error: Failed to read commit .* No such metadata object\|error: Failed to install org.freedesktop.Platform: Failed to read commit .* No such metadata object\|Error: Error deploying: .* No such metadata object`)
 	if regexFlatpak.MatchString(errors) {
@@ -2080,6 +2072,15 @@ func LogDiagnose(logfilePath string, allowWrite bool) (*ErrorDiagnosis, error) {
 		}
 	}
 
+	// A pinned script that changed upstream is up to the app maintainers, not the system
+	applyScriptChangedHint(diagnosis, errors)
+
+	// Point at the parallel job count when a compile ran out of memory
+	applyCompileJobsHint(diagnosis, errors)
+
+	// Use the exit code the script recorded as a hint for the error type
+	applyExitCodeHint(diagnosis, errors)
+
 	// If no error type was set, default to "unknown" (allows error reporting)
 	if diagnosis.ErrorType == "" {
 		diagnosis.ErrorType = "unknown"
@@ -2089,6 +2090,30 @@ func LogDiagnose(logfilePath string, allowWrite bool) (*ErrorDiagnosis, error) {
 	return diagnosis, nil
 }
 
+// logOSInfo reads the OS ID and version ID from the "OS: Debian GNU/Linux 13 (trixie)" line logs start with.
+// A Trixie log without a version number is version 13.
+func logOSInfo(line string) (osID, versionID string) {
+	switch {
+	case strings.Contains(line, "Raspbian"):
+		osID = "Raspbian"
+	case strings.Contains(line, "Debian"):
+		osID = "Debian"
+	default:
+		if fields := strings.Fields(strings.TrimPrefix(line, "OS:")); len(fields) > 0 {
+			osID = fields[0]
+		}
+	}
+
+	if matches := regexp.MustCompile(`GNU/Linux\s+(\d+)`).FindStringSubmatch(line); len(matches) > 1 {
+		versionID = matches[1]
+	} else if matches := regexp.MustCompile(`(\d+)\s*\(\w+\)`).FindStringSubmatch(line); len(matches) > 1 {
+		versionID = matches[1]
+	} else if strings.Contains(strings.ToLower(line), "trixie") {
+		versionID = "13"
+	}
+	return osID, versionID
+}
+
 // Helper function to check if raspi.list contains the required repository entries
 func containsRaspiRepo(path string) bool {
 	content, err := os.ReadFile(path)
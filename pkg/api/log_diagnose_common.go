@@ -71,8 +71,12 @@ func FormatLogfile(filename string) error {
 		return err
 	}
 
-	// Remove ANSI escape sequences
-	cleanedContent := RemoveAnsiEscapes(string(content))
+	// Remove ANSI escape sequences and the status symbols that would keep diagnosis patterns from matching.
+	// Scripts writing to a log no longer color their api messages, the escape sequences left come from the
+	// commands they run themselves, their progress bars and logs written by older versions.
+	cleanedContent := removeStatusSymbols(RemoveAnsiEscapes(string(content)))
+	// Secrets of apps stored with StoreAppSecret never belong in a log that may be sent with an error report
+	cleanedContent = scrubSecrets(cleanedContent, storedSecretValues())
 
 	// Check if the file already starts with device information
 	// Look for patterns that indicate system info is already present
@@ -129,8 +133,8 @@ func GetDeviceInfo() (string, error) {
 				// commitDate should be in format YYYY-MM-DD
 				parsedTime, err := time.Parse("2006-01-02", commitDate)
 				if err == nil {
-					// Format to system default short date (as xargs date +%x would do)
-					localUpdateDate := parsedTime.Format("01/02/2006")
+					// Format to the short date of the current locale (as xargs date +%x would do)
+					localUpdateDate := FormatDate(parsedTime)
 					info.WriteString("Last updated Pi-Apps on: " + localUpdateDate + "\n")
 				} else {
 					info.WriteString("Last updated Pi-Apps on: " + commitDate + "\n")
@@ -182,7 +186,7 @@ func GetDeviceInfo() (string, error) {
 								// Parse the ISO date and format it
 								date, err := time.Parse(time.RFC3339, commits[0].Commit.Author.Date)
 								if err == nil {
-									dateStr := date.Format("01/02/2006")
+									dateStr := FormatDate(date)
 									info.WriteString("Latest Pi-Apps version: " + dateStr + "\n")
 								}
 							}
@@ -289,6 +293,22 @@ func GetDeviceInfo() (string, error) {
 		}
 	}
 
+	// Get the clock skew, a wrong clock makes downloads and package lists look invalid
+	if skew, err := CheckClockSkew(); err == nil {
+		info.WriteString("Clock: " + skew.String() + "\n")
+	}
+
+	// Get display session, apps requiring X11 fail in a different way on Wayland
+	info.WriteString("Display session: " + DisplaySessionInfo().String() + "\n")
+
+	// Get the graphics stack, graphics-related failures depend on the GPU driver and memory split
+	info.WriteString(GetGPUInfo().DeviceInfoLines())
+
+	// Get the mount of the Pi-Apps folder, scripts fail with "Permission denied" on noexec or NTFS/exFAT drives
+	if mount, err := MountInfoFor(GetPiAppsDir()); err == nil {
+		info.WriteString("Pi-Apps folder mount: " + mount.String() + "\n")
+	}
+
 	// Get Go runtime information, including experiments if present
 	goVersion := runtime.Version()
 	info.WriteString("Go runtime used: " + goVersion + "\n")
@@ -343,7 +363,7 @@ func RemoveAnsiEscapes(input string) string {
 	var filteredLines []string
 
 	for _, line := range lines {
-		if !progressBarRegex.MatchString(line) {
+		if !progressBarRegex.MatchString(line) && !isLogoLine(line) {
 			filteredLines = append(filteredLines, line)
 		}
 	}
@@ -362,8 +382,19 @@ func containsAny(s string, patterns []string) bool {
 	return false
 }
 
+// errorReportServer is the server error reports and app suggestions are sent to, localhost for development purposes
+const errorReportServer = "http://localhost:8080"
+
 // SendErrorReport sends an error report to the Pi-Apps team
 func SendErrorReport(logfilePath string) (string, error) {
+	return SendErrorReportWithAttachments(logfilePath)
+}
+
+// SendErrorReportWithAttachments sends an error report to the Pi-Apps team along with
+// additional files (such as a screenshot) that belong to the same report
+//
+// Each attachment is uploaded as a separate "attachment" multipart file next to the log file.
+func SendErrorReportWithAttachments(logfilePath string, attachments ...string) (string, error) {
 	// Validate arguments
 	if logfilePath == "" {
 		return "", fmt.Errorf("send_error_report(): requires an argument")
@@ -393,7 +424,7 @@ func SendErrorReport(logfilePath string) (string, error) {
 
 	// Get a token from the error report server
 	client := &http.Client{}
-	tokenResp, err := client.Get("http://localhost:8080/token") // localhost for development purposes
+	tokenResp, err := client.Get(errorReportServer + "/token")
 	if err != nil {
 		return "", fmt.Errorf("failed to get error report token: %w", err)
 	}
@@ -430,10 +461,28 @@ func SendErrorReport(logfilePath string) (string, error) {
 	if _, err := part.Write(fileContent); err != nil {
 		return "", fmt.Errorf("failed to write file content: %w", err)
 	}
+
+	// Add any attachments belonging to this report
+	for _, attachment := range attachments {
+		if attachment == "" {
+			continue
+		}
+		attachmentContent, err := os.ReadFile(attachment)
+		if err != nil {
+			return "", fmt.Errorf("failed to read attachment %s: %w", attachment, err)
+		}
+		part, err := writer.CreateFormFile("attachment", filepath.Base(attachment))
+		if err != nil {
+			return "", fmt.Errorf("failed to create form file: %w", err)
+		}
+		if _, err := part.Write(attachmentContent); err != nil {
+			return "", fmt.Errorf("failed to write attachment content: %w", err)
+		}
+	}
 	writer.Close()
 
 	// Create the request
-	req, err := http.NewRequest("POST", "http://localhost:8080/report", body) // localhost is for development purposes
+	req, err := http.NewRequest("POST", errorReportServer+"/report", body)
 	if err != nil {
 		return "", fmt.Errorf("failed to create request: %w", err)
 	}
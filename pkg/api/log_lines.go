@@ -0,0 +1,125 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: log_lines.go
+// Description: Classifies the lines of install logs as errors, warnings or progress noise, for the log viewer and view_log --errors-only.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package api
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// LogLineKind is what a line of an install log is about
+type LogLineKind int
+
+const (
+	LogLineNormal  LogLineKind = iota
+	LogLineError               // something failed, the lines the diagnosis looks for
+	LogLineWarning             // something might be wrong
+	LogLineNoise               // package manager chatter and progress output that LessApt hides
+)
+
+var (
+	// logErrorPattern matches the error lines of apt, dpkg, compilers, git, curl, python and the Pi-Apps scripts
+	logErrorPattern = regexp.MustCompile(`(?i)^(E: |dpkg: error|error[:\[ ]|fatal[: ]|ERROR |Traceback \(most recent call last\)|` +
+		SymbolFailure + ` )|(^|[\s:])(error|fatal error|fatal): |` +
+		`Failed to (install|uninstall|update|download|fetch|connect|read|open|create|run)|` +
+		`Segmentation fault|command not found|No space left on device|Unable to locate package|has no installation candidate|` +
+		`Errors were encountered while processing|The following packages have unmet dependencies|make(\[\d+\])?: \*\*\*`)
+	// logWarningPattern matches the warning lines
+	logWarningPattern = regexp.MustCompile(`(?i)^(W: |warning[:\[ ]|` + SymbolWarning + ` )|(^|[\s:])warning: `)
+	// logProgressPattern matches progress output that LessApt doesn't know about, like download and compile percentages
+	logProgressPattern = regexp.MustCompile(`^\s*(\[\s*\d+%\]|\d+%\s|#+\s+\d+(\.\d+)?%|(Receiving|Resolving|Counting|Compressing|Enumerating) (objects|deltas):|` +
+		`% Total\s+% Received|\d+\s+\d+[kMG]?\s+\d+\s+\d+[kMG]?\s+\d+\s+\d+\s)`)
+	// lessAptFilters reports whether LessApt filters lines in this build, it hides everything without a package manager
+	lessAptFilters = sync.OnceValue(func() bool {
+		return LessApt("Pi-Apps\n") != ""
+	})
+)
+
+// ClassifyLogLine tells whether a log line is an error, a warning, progress noise or a normal line.
+// Noise is what LessApt filters out of the package manager output, plus progress percentages.
+func ClassifyLogLine(line string) LogLineKind {
+	line = stripAnsiCodes(line)
+	switch {
+	case strings.TrimSpace(line) == "":
+		return LogLineNoise
+	case logErrorPattern.MatchString(line):
+		return LogLineError
+	case logWarningPattern.MatchString(line):
+		return LogLineWarning
+	case logProgressPattern.MatchString(line):
+		return LogLineNoise
+	case lessAptFilters() && strings.TrimSpace(LessApt(line+"\n")) == "":
+		return LogLineNoise
+	}
+	return LogLineNormal
+}
+
+// PrintLogErrors writes the error lines of a log with the given number of lines around them, like grep -C.
+// The log is read line by line, so large logs are not loaded into memory at once.
+//
+//	int - the number of error lines found
+func PrintLogErrors(w io.Writer, r io.Reader, context int) (int, error) {
+	scanner := bufio.NewScanner(r)
+	// Compiler output can have very long lines
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+
+	var before []string // the last context lines before the current one that weren't printed
+	lastPrinted := 0    // number of the last printed line
+	after := 0          // lines still to print after an error
+	errorsFound := 0
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := scanner.Text()
+		if ClassifyLogLine(line) == LogLineError {
+			errorsFound++
+			first := lineNumber - len(before)
+			if lastPrinted > 0 && first > lastPrinted+1 {
+				fmt.Fprintln(w, "--")
+			}
+			for i, previous := range before {
+				fmt.Fprintf(w, "%d-%s\n", first+i, previous)
+			}
+			before = before[:0]
+			fmt.Fprintf(w, "%d:%s\n", lineNumber, line)
+			lastPrinted = lineNumber
+			after = context
+			continue
+		}
+		if after > 0 {
+			fmt.Fprintf(w, "%d-%s\n", lineNumber, line)
+			lastPrinted = lineNumber
+			after--
+			continue
+		}
+		if context > 0 {
+			if len(before) == context {
+				before = before[1:]
+			}
+			before = append(before, line)
+		}
+	}
+	return errorsFound, scanner.Err()
+}
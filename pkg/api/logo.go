@@ -0,0 +1,277 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: logo.go
+// Description: Provides the Pi-Apps logo in several color depths, picking one the terminal can display.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package api
+
+import (
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+)
+
+// ColorMode selects how many colors the logo is drawn with
+type ColorMode int
+
+const (
+	// ColorAuto detects the color mode from the terminal stdout is connected to
+	ColorAuto ColorMode = iota
+	// ColorTrue draws the logo with 24-bit colors and Unicode 13 block characters
+	ColorTrue
+	// Color256 draws the logo with the 256-color palette
+	Color256
+	// Color16 draws the logo with the basic 16 ANSI colors
+	Color16
+	// ColorNone draws a plain ASCII art logo without any escape sequences
+	ColorNone
+)
+
+// logoWidth is the number of terminal columns the full logo takes up
+const logoWidth = 67
+
+// ansiColor16 maps the 256-color escape sequences of the logo to their closest basic ANSI color
+var ansiColor16 = strings.NewReplacer(
+	"\033[38;5;75m", "\033[94m",
+	"\033[38;5;26m", "\033[34m",
+	"\033[38;5;21m", "\033[34m",
+	"\033[38;5;93m", "\033[35m",
+	"\033[38;5;46m", "\033[92m",
+	"\033[38;5;34m", "\033[32m",
+	"\033[38;5;197m", "\033[91m",
+	"\033[48;5;26m", "\033[44m",
+)
+
+// asciiLogo is the logo used when the output can not display colors or block characters
+var asciiLogo = []string{
+	"    _____",
+	"  _|_____|_       ____  _       _",
+	" |  o o o  |     |  _ \\(_)     / \\   _ __  _ __  ___",
+	" |  o o o  |     | |_) | |___ / _ \\ | '_ \\| '_ \\/ __|",
+	" |  o o o  |     |  __/| |___/ ___ \\| |_) | |_) \\__ \\",
+	"  \\_______/      |_|   |_|  /_/   \\_\\ .__/| .__/|___/",
+	"                                     |_|   |_|",
+}
+
+// GenerateLogo displays colorized Pi-Apps logo in terminal
+//
+// The color depth is picked automatically from the terminal stdout is connected to,
+// so the logo never turns into garbage in dumb terminals or log files.
+//
+// To use this function, you must call it like this:
+//
+//	fmt.Println(api.GenerateLogo())
+func GenerateLogo() string {
+	return GenerateLogoOpts(0, ColorAuto)
+}
+
+// GenerateLogoOpts returns the Pi-Apps logo drawn with the given color mode
+//
+// If width is greater than 0, every line of the logo is cut off after width columns.
+func GenerateLogoOpts(width int, color ColorMode) string {
+	if color == ColorAuto {
+		color = DetectColorMode()
+	}
+
+	var logoStr string
+	switch color {
+	case ColorTrue:
+		logoStr = logoTrueColor()
+	case Color256:
+		logoStr = logo256()
+	case Color16:
+		logoStr = ansiColor16.Replace(logo256())
+	default:
+		logoStr = strings.Join(asciiLogo, "\n")
+	}
+
+	if width > 0 && width < logoWidth {
+		lines := strings.Split(logoStr, "\n")
+		for i, line := range lines {
+			lines[i] = truncateVisible(line, width)
+		}
+		logoStr = strings.Join(lines, "\n")
+	}
+
+	return logoStr + "\n"
+}
+
+// DetectColorMode returns the color mode the terminal stdout is connected to supports
+//
+// Output that is not a terminal (for example when it is captured to a log file) and dumb terminals get ColorNone.
+// The new logo can be disabled in favor of the 256-color one by setting PI-APPS_FORCE_OLD_LOGO to true.
+// A ColorOutputPreference of never or always overrides the terminal check.
+func DetectColorMode() ColorMode {
+	switch ColorOutputPreference() {
+	case ColorOutputNever:
+		return ColorNone
+	case ColorOutputAlways:
+		return TerminalColorMode()
+	}
+	if !term.IsTerminal(int(os.Stdout.Fd())) {
+		return ColorNone
+	}
+	return colorModeFromEnv()
+}
+
+// colorModeFromEnv returns the color mode advertised by the COLORTERM and TERM environment variables
+func colorModeFromEnv() ColorMode {
+	termName := os.Getenv("TERM")
+	if termName == "" || termName == "dumb" {
+		return ColorNone
+	}
+
+	forceOldLogo := os.Getenv("PI-APPS_FORCE_OLD_LOGO") == "true"
+
+	colorTerm := strings.ToLower(os.Getenv("COLORTERM"))
+	if colorTerm == "truecolor" || colorTerm == "24bit" || strings.Contains(termName, "direct") {
+		if forceOldLogo {
+			return Color256
+		}
+		return ColorTrue
+	}
+	if strings.Contains(termName, "256color") || forceOldLogo {
+		return Color256
+	}
+	return Color16
+}
+
+// TerminalColorMode returns the color mode of the terminal window the current process runs in
+//
+// Unlike DetectColorMode this does not fall back to ColorNone when stdout is redirected,
+// which is what the daemon terminal wants as its output is shown in a terminal and copied to a log at the same time.
+// It still returns ColorNone when colors are turned off with NO_COLOR, PI_APPS_COLOR or the "Color output" setting.
+func TerminalColorMode() ColorMode {
+	if ColorOutputPreference() == ColorOutputNever {
+		return ColorNone
+	}
+	if mode := colorModeFromEnv(); mode != ColorNone {
+		return mode
+	}
+	return Color256
+}
+
+// isLogoLine reports whether a line (with escape sequences already removed) is part of any variant of the logo
+func isLogoLine(line string) bool {
+	trimmed := strings.TrimRight(line, " ")
+	if strings.TrimSpace(trimmed) == "" {
+		return false
+	}
+	for _, logoLine := range asciiLogo {
+		if trimmed == logoLine {
+			return true
+		}
+	}
+
+	// Colored logo lines consist of nothing but block and box drawing characters
+	for _, r := range trimmed {
+		switch {
+		case r == ' ':
+		case r >= 0x2500 && r <= 0x25FF: // box drawing, block elements and geometric shapes
+		case r >= 0x1FB00 && r <= 0x1FBFF: // symbols for legacy computing
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// truncateVisible cuts a line after the given number of visible columns, keeping its escape sequences intact
+func truncateVisible(line string, width int) string {
+	var b strings.Builder
+	columns := 0
+	for i := 0; i < len(line); {
+		if line[i] == '\033' {
+			// Copy the escape sequence up to and including its final letter
+			j := i + 1
+			for j < len(line) && !((line[j] >= 'a' && line[j] <= 'z') || (line[j] >= 'A' && line[j] <= 'Z')) {
+				j++
+			}
+			if j < len(line) {
+				j++
+			}
+			b.WriteString(line[i:j])
+			i = j
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(line[i:])
+		if columns < width {
+			b.WriteRune(r)
+		}
+		columns++
+		i += size
+	}
+	if columns > width && strings.Contains(line, "\033") {
+		b.WriteString("\033[0m")
+	}
+	return b.String()
+}
+
+// logo256 returns the original logo drawn with the 256-color palette
+func logo256() string {
+	// Exact ANSI color codes from the original bash script
+	// Foreground colors
+	blue1 := "\033[38;5;75m"
+	blue2 := "\033[38;5;26m"
+	blue3 := "\033[38;5;21m"
+	blue4 := "\033[38;5;93m"
+
+	green := "\033[38;5;46m"
+	darkgreen := "\033[38;5;34m"
+	red := "\033[38;5;197m"
+	white := "\033[97m"
+	black := "\033[30m"
+	default_ := "\033[39m"
+
+	// Background colors
+	bg_default := "\033[49m"
+	bg_black := "\033[40m"
+
+	return white + bg_default + "    " + green + "▅" + darkgreen + "▅▅▅" + green + "▅" + default_ + "                                          " + darkgreen + "                " + default_ + "\n" +
+		" " + blue1 + "▂▂▂" + green + "\033[48;5;26m\033[7m▂\033[27m" + bg_default + blue2 + "▂▂▂" + blue3 + green + "\033[48;5;26m\033[7m▂\033[27m" + bg_default + blue3 + "▂▂▂" + white + default_ + "                                       " + darkgreen + "                " + default_ + "\n" +
+		" " + bg_black + blue1 + "▌  " + red + "▄ ▄ ▄" + blue3 + "  ▐" + bg_default + default_ + "   █▀▀◣ ▄    ◢▀▀◣                      " + darkgreen + "  " + black + "    " + darkgreen + "    " + black + "    " + darkgreen + "  " + default_ + "\n" +
+		" " + bg_black + blue2 + "▌  " + red + "▄ ▄ ▄" + blue3 + "  ▐" + bg_default + default_ + "   █▄▄◤ ▄ " + blue3 + "▄▄" + default_ + " █▄▄█ █▀▀◣ █▀▀◣ ◢\033[7m━━━\033[27m       " + darkgreen + "  " + black + "    " + darkgreen + "    " + black + "    " + darkgreen + "  " + default_ + "\n" +
+		" " + bg_black + blue2 + "▌  " + red + "▄ ▄ ▄" + blue4 + "  ▐" + bg_default + default_ + "   █    █    █  █ █▄▄◤ █▄▄◤ ▄▄▄◤       " + darkgreen + "      " + black + "    " + darkgreen + "      " + default_ + "\n" +
+		" " + blue3 + "◥" + bg_black + "▃▃▃▃" + blue4 + "▃▃▃▃▃" + bg_default + "◤" + default_ + "                  █    █               " + darkgreen + "    " + black + "        " + darkgreen + "    " + default_ + "\n" +
+		"\033[0m                                                   " + darkgreen + "    " + black + "        " + darkgreen + "    " + default_ + "\n" +
+		"                                                   " + darkgreen + "    " + black + "  " + darkgreen + "    " + black + "  " + darkgreen + "    " + default_
+}
+
+// logoTrueColor returns the logo drawn with 24-bit colors
+//
+// Complex logo requires Unicode 13 support (libicu66+)
+// This does not matter as Pi-Apps Go only supports systems with atleast libicu66+
+func logoTrueColor() string {
+	blue3 := "\033[38;5;21m"
+	darkgreen := "\033[38;5;34m"
+	black := "\033[30m"
+	default_ := "\033[39m"
+	bg_default := "\033[49m"
+	bg_black := "\033[48;2;10;10;10m"
+
+	return bg_default + "    \033[38;2;5;220;75m🭊\033[38;2;4;150;29m🬹🬹🬹\033[38;2;6;188;64m🬿" + default_ + "                                          " + darkgreen + "                " + default_ + "\n" +
+		" \033[38;2;83;213;255m🭈🬭\033[38;2;83;214;255m🬭\033[38;2;5;220;75m\033[48;2;83;212;255m🬎" + bg_default + "\033[38;2;84;201;251m🬭\033[38;2;84;190;248m🬭\033[38;2;85;178;244m🬭\033[38;2;6;188;64m\033[48;2;86;168;241m🬎" + bg_default + "\033[38;2;87;154;237m🬭🬭\033[38;2;87;136;231m🬽" + default_ + "                                       " + darkgreen + "                " + default_ + "\n" +
+		" \033[38;2;83;213;255m" + bg_black + "▋  \033[38;2;255;38;101m▄ \033[38;2;255;28;92m▄ \033[38;2;255;13;83m▄\033[38;2;89;114;225m  🮉" + bg_default + default_ + "   █▀▀🭍 ▄    🭋🭡🭖🭀                      " + darkgreen + "  " + black + "    " + darkgreen + "    " + black + "    " + darkgreen + "  " + default_ + "\n" +
+		" \033[38;2;85;191;249m" + bg_black + "▋  \033[38;2;255;13;85m▄ \033[38;2;255;0;75m▄ \033[38;2;246;0;73m▄\033[38;2;90;83;215m  🮉" + bg_default + default_ + "   █▄▄🭞 ▄ " + blue3 + "▄▄" + default_ + " 🭅▙▟🭐 █▀▀🭍 █▀▀🭍 🭂🬰🬰🬰       " + darkgreen + "  " + black + "    " + darkgreen + "    " + black + "    " + darkgreen + "  " + default_ + "\n" +
+		" \033[38;2;86;164;240m" + bg_black + "▋  \033[38;2;249;0;73m▄ \033[38;2;239;0;69m▄ \033[38;2;229;0;66m▄\033[38;2;92;58;207m  🮉" + bg_default + default_ + "   █    █   🭋🭡  🭖🭀█▄▄🭞 █▄▄🭞 ▄▄▄🭞       " + darkgreen + "      " + black + "    " + darkgreen + "      " + default_ + "\n" +
+		" \033[38;2;87;137;232m🭕" + bg_black + "🭏\033[38;2;89;111;224m🬭\033[38;2;89;100;220m🬭\033[38;2;90;89;217m🬭\033[38;2;91;76;213m🬭\033[38;2;92;68;211m🬭\033[38;2;92;59;208m🬭\033[38;2;92;56;207m🬭🭄" + bg_default + "🭠" + default_ + "                  █    █               " + darkgreen + "    " + black + "        " + darkgreen + "    " + default_ + "\n" +
+		"\033[0m                                                   " + darkgreen + "    " + black + "        " + darkgreen + "    " + default_ + "\n" +
+		"                                                   " + darkgreen + "    " + black + "  " + darkgreen + "    " + black + "  " + darkgreen + "    " + default_
+}
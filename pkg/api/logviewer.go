@@ -29,10 +29,6 @@ import (
 	"sort"
 	"strings"
 	"time"
-
-	"github.com/gotk3/gotk3/gdk"
-	"github.com/gotk3/gotk3/glib"
-	"github.com/gotk3/gotk3/gtk"
 )
 
 // LogEntry represents a single log file entry
@@ -51,19 +47,32 @@ type LogEntry struct {
 
 // CleanupOldLogFiles removes log files older than 6 days
 func CleanupOldLogFiles() error {
+	_, err := CleanLogFiles(false)
+	return err
+}
+
+// CleanLogFiles removes the log files older than 6 days, or all of them except the logs of operations
+// that are still running when all is true
+//
+//	int - the number of log files removed
+func CleanLogFiles(all bool) (int, error) {
 	piAppsDir := GetPiAppsDir()
 	if piAppsDir == "" {
-		return fmt.Errorf("PI_APPS_DIR environment variable not set")
+		return 0, fmt.Errorf("PI_APPS_DIR environment variable not set")
 	}
 
-	logsDir := filepath.Join(piAppsDir, "logs")
+	logsDir := LogsDirOf(piAppsDir)
 	if !DirExists(logsDir) {
-		return nil // No logs directory, nothing to clean up
+		return 0, nil // No logs directory, nothing to clean up
 	}
 
 	cutoffTime := time.Now().AddDate(0, 0, -6) // 6 days ago
+	if all {
+		cutoffTime = time.Now()
+	}
 
-	return filepath.WalkDir(logsDir, func(path string, d fs.DirEntry, err error) error {
+	removed := 0
+	err := filepath.WalkDir(logsDir, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
@@ -72,6 +81,11 @@ func CleanupOldLogFiles() error {
 			return nil
 		}
 
+		// An incomplete log may belong to an install that is running right now
+		if all && strings.Contains(d.Name(), "-incomplete-") {
+			return nil
+		}
+
 		info, err := d.Info()
 		if err != nil {
 			return err
@@ -82,11 +96,14 @@ func CleanupOldLogFiles() error {
 			if err := os.Remove(path); err != nil {
 				// Don't fail the entire cleanup if one file can't be removed
 				fmt.Fprintf(os.Stderr, "Warning: Could not remove old log file %s: %v\n", path, err)
+			} else {
+				removed++
 			}
 		}
 
 		return nil
 	})
+	return removed, err
 }
 
 // GetLogFiles returns all log files sorted by modification time (newest first)
@@ -96,7 +113,7 @@ func GetLogFiles() ([]LogEntry, error) {
 		return nil, fmt.Errorf("PI_APPS_DIR environment variable not set")
 	}
 
-	logsDir := filepath.Join(piAppsDir, "logs")
+	logsDir := LogsDirOf(piAppsDir)
 	if !DirExists(logsDir) {
 		return []LogEntry{}, nil // No logs directory, return empty slice
 	}
@@ -145,11 +162,11 @@ func GetLogFiles() ([]LogEntry, error) {
 }
 
 // parseLogFilename parses a log filename to extract app, action, and result
-// Expected format: {action}-{result}-{app}.log
-// Examples: install-success-Firefox.log, uninstall-fail-Chrome.log
+// Expected format: {action}-{result}-{app}.log, optionally with the start time before the extension
+// Examples: install-success-Firefox.log, uninstall-fail-Chrome@20261015-153012.000.log
 func parseLogFilename(filePath string, modTime time.Time) (LogEntry, error) {
 	filename := filepath.Base(filePath)
-	basename := strings.TrimSuffix(strings.ToLower(filename), ".log")
+	basename := trimLogTime(strings.TrimSuffix(strings.ToLower(filename), ".log"))
 
 	// Use regex to parse the filename components
 	// Pattern matches: {action}-{result}-{app}
@@ -196,58 +213,42 @@ func parseLogFilename(filePath string, modTime time.Time) (LogEntry, error) {
 	}, nil
 }
 
-// formatLogDate formats the log date in a human-readable format
+// formatLogDate formats the log date in a human-readable format for the current locale
 func formatLogDate(modTime time.Time) string {
 	now := time.Now()
-	today := now.Format("Monday")
-	yesterday := now.AddDate(0, 0, -1).Format("Monday")
-	logDay := modTime.Format("Monday")
-
-	// Check if we use AM/PM format by checking if time.Now() includes AM/PM
-	timeStr := now.Format("3:04 PM")
-	useAMPM := strings.Contains(timeStr, "AM") || strings.Contains(timeStr, "PM")
-
-	var dateFormat string
-	if useAMPM {
-		dateFormat = "Monday 3:04 PM"
-	} else {
-		dateFormat = "Monday 15:04"
-	}
-
-	dateStr := modTime.Format(dateFormat)
+	logDay := modTime.Format("2006-01-02")
+	timeStr := FormatTime(modTime)
 
-	// Replace day names with Today/Yesterday if applicable
 	switch logDay {
-	case today:
-		dateStr = strings.Replace(dateStr, logDay, "Today", 1)
-	case yesterday:
-		dateStr = strings.Replace(dateStr, logDay, "Yesterday", 1)
+	case now.Format("2006-01-02"):
+		return Tf("Today %s", timeStr)
+	case now.AddDate(0, 0, -1).Format("2006-01-02"):
+		return Tf("Yesterday %s", timeStr)
 	}
-
-	return dateStr
+	return Tf("%s %s", FormatDate(modTime), timeStr)
 }
 
 // generateCaption creates a human-readable caption for the log entry
 func generateCaption(action, result, app string) string {
-	var caption string
-
-	switch action {
-	case "uninstall":
-		caption = "Uninstalling " + app
-	case "install":
-		caption = "Installing " + app
+	if action == "uninstall" {
+		switch result {
+		case "success":
+			return Tf("Uninstalling %s succeeded.", app)
+		case "fail":
+			return Tf("Uninstalling %s failed.", app)
+		default: // incomplete
+			return Tf("Uninstalling %s was interrupted.", app)
+		}
 	}
 
 	switch result {
 	case "success":
-		caption += " succeeded."
+		return Tf("Installing %s succeeded.", app)
 	case "fail":
-		caption += " failed."
+		return Tf("Installing %s failed.", app)
 	default: // incomplete
-		caption += " was interrupted."
+		return Tf("Installing %s was interrupted.", app)
 	}
-
-	return caption
 }
 
 // getAppIcon returns the path to the app's icon or a default icon
@@ -290,7 +291,7 @@ func DeleteAllLogFiles() error {
 		return fmt.Errorf("PI_APPS_DIR environment variable not set")
 	}
 
-	logsDir := filepath.Join(piAppsDir, "logs")
+	logsDir := LogsDirOf(piAppsDir)
 	if !DirExists(logsDir) {
 		return nil // No logs directory, nothing to delete
 	}
@@ -302,343 +303,10 @@ func DeleteAllLogFiles() error {
 	}
 
 	// Recreate the logs directory
-	err = os.MkdirAll(logsDir, 0755)
+	err = EnsureDirOwned(logsDir, InvokingUser, InvokingUser)
 	if err != nil {
 		return fmt.Errorf("failed to recreate logs directory: %w", err)
 	}
 
 	return nil
 }
-
-// ShowLogViewer displays the log viewer GUI
-func ShowLogViewer() error {
-	// Clean up old log files first
-	if err := CleanupOldLogFiles(); err != nil {
-		Warning("Failed to clean up old log files: " + err.Error())
-	}
-
-	// Get all log files
-	logEntries, err := GetLogFiles()
-	if err != nil {
-		return fmt.Errorf("failed to get log files: %w", err)
-	}
-
-	// Show GUI
-	return showLogViewerGUI(logEntries)
-}
-
-// showLogViewerGUI displays the log viewer using GTK
-func showLogViewerGUI(logEntries []LogEntry) error {
-	piAppsDir := GetPiAppsDir()
-	if piAppsDir == "" {
-		return fmt.Errorf("PI_APPS_DIR environment variable not set")
-	}
-
-	// Initialize GTK
-	glib.SetPrgname("Log file viewer")
-	gtk.Init(nil)
-
-	// Create main window
-	win, err := gtk.WindowNew(gtk.WINDOW_TOPLEVEL)
-	if err != nil {
-		return fmt.Errorf("unable to create window: %w", err)
-	}
-
-	win.SetTitle("Log file viewer")
-	win.SetDefaultSize(500, 400)
-	win.SetPosition(gtk.WIN_POS_CENTER)
-	win.SetKeepAbove(true)
-
-	// Set window icon
-	iconPath := filepath.Join(piAppsDir, "icons", "settings.png")
-	if FileExists(iconPath) {
-		pixbuf, err := gdk.PixbufNewFromFile(iconPath)
-		if err == nil {
-			win.SetIcon(pixbuf)
-		}
-	}
-
-	// Create main vbox
-	vbox, err := gtk.BoxNew(gtk.ORIENTATION_VERTICAL, 8)
-	if err != nil {
-		return fmt.Errorf("unable to create main vbox: %w", err)
-	}
-	vbox.SetMarginTop(12)
-	vbox.SetMarginBottom(12)
-	vbox.SetMarginStart(12)
-	vbox.SetMarginEnd(12)
-	win.Add(vbox)
-
-	// Create description label
-	description := "Review the errors from installing or uninstalling apps.\nClick a line to open its log. Week-old log files will be deleted."
-	descLabel, err := gtk.LabelNew(description)
-	if err != nil {
-		return fmt.Errorf("unable to create description label: %w", err)
-	}
-	descLabel.SetHAlign(gtk.ALIGN_START)
-	descLabel.SetJustify(gtk.JUSTIFY_LEFT)
-	vbox.PackStart(descLabel, false, false, 0)
-
-	// Create scrolled window for the list
-	scrolledWindow, err := gtk.ScrolledWindowNew(nil, nil)
-	if err != nil {
-		return fmt.Errorf("unable to create scrolled window: %w", err)
-	}
-	scrolledWindow.SetPolicy(gtk.POLICY_AUTOMATIC, gtk.POLICY_AUTOMATIC)
-	scrolledWindow.SetShadowType(gtk.SHADOW_IN)
-	vbox.PackStart(scrolledWindow, true, true, 0)
-
-	// Create tree view and model
-	treeView, listStore, err := createLogTreeView()
-	if err != nil {
-		return fmt.Errorf("unable to create tree view: %w", err)
-	}
-	scrolledWindow.Add(treeView)
-
-	// Populate the list store with log entries
-	populateLogList(listStore, logEntries)
-
-	// Handle row activation (double-click or Enter)
-	treeView.Connect("row-activated", func(tv *gtk.TreeView, path *gtk.TreePath, column *gtk.TreeViewColumn) {
-		handleLogSelection(tv, path)
-	})
-
-	// Create button box
-	buttonBox, err := gtk.ButtonBoxNew(gtk.ORIENTATION_HORIZONTAL)
-	if err != nil {
-		return fmt.Errorf("unable to create button box: %w", err)
-	}
-	buttonBox.SetLayout(gtk.BUTTONBOX_END)
-	buttonBox.SetSpacing(8)
-	vbox.PackStart(buttonBox, false, false, 0)
-
-	// Create Delete All button
-	deleteAllBtn, err := gtk.ButtonNewWithLabel("Delete all")
-	if err != nil {
-		return fmt.Errorf("unable to create delete all button: %w", err)
-	}
-
-	trashIconPath := filepath.Join(piAppsDir, "icons", "trash.png")
-	if FileExists(trashIconPath) {
-		trashIcon, err := gtk.ImageNewFromFile(trashIconPath)
-		if err == nil {
-			deleteAllBtn.SetImage(trashIcon)
-		}
-	}
-
-	deleteAllBtn.SetTooltipText("Delete all log files from " + filepath.Join(piAppsDir, "logs"))
-	buttonBox.Add(deleteAllBtn)
-
-	// Create Close button
-	closeBtn, err := gtk.ButtonNewWithLabel("Close")
-	if err != nil {
-		return fmt.Errorf("unable to create close button: %w", err)
-	}
-
-	exitIconPath := filepath.Join(piAppsDir, "icons", "exit.png")
-	if FileExists(exitIconPath) {
-		exitIcon, err := gtk.ImageNewFromFile(exitIconPath)
-		if err == nil {
-			closeBtn.SetImage(exitIcon)
-		}
-	}
-
-	buttonBox.Add(closeBtn)
-
-	// Connect button signals
-	deleteAllBtn.Connect("clicked", func() {
-		if confirmDeleteAll() {
-			if err := DeleteAllLogFiles(); err != nil {
-				showErrorDialog("Failed to delete log files: " + err.Error())
-			} else {
-				// Clear the list and show success message
-				listStore.Clear()
-				Status("Deleted everything inside of " + filepath.Join(piAppsDir, "logs"))
-			}
-		}
-	})
-
-	closeBtn.Connect("clicked", func() {
-		win.Close()
-	})
-
-	// Connect window destroy signal
-	win.Connect("destroy", func() {
-		gtk.MainQuit()
-	})
-
-	// Show all widgets and start main loop
-	win.ShowAll()
-	gtk.Main()
-
-	return nil
-}
-
-// createLogTreeView creates and configures the tree view for displaying log entries
-func createLogTreeView() (*gtk.TreeView, *gtk.ListStore, error) {
-	// Create list store with columns: Date(string), ActionIcon(pixbuf), AppIcon(pixbuf), ResultIcon(pixbuf), Description(string), FilePath(string)
-	listStore, err := gtk.ListStoreNew(glib.TYPE_STRING, gdk.PixbufGetType(), gdk.PixbufGetType(), gdk.PixbufGetType(), glib.TYPE_STRING, glib.TYPE_STRING)
-	if err != nil {
-		return nil, nil, fmt.Errorf("unable to create list store: %w", err)
-	}
-
-	// Create tree view
-	treeView, err := gtk.TreeViewNewWithModel(listStore)
-	if err != nil {
-		return nil, nil, fmt.Errorf("unable to create tree view: %w", err)
-	}
-
-	// Create columns
-	// Day column
-	dayRenderer, err := gtk.CellRendererTextNew()
-	if err != nil {
-		return nil, nil, fmt.Errorf("unable to create day renderer: %w", err)
-	}
-	dayColumn, err := gtk.TreeViewColumnNewWithAttribute("Day", dayRenderer, "text", 0)
-	if err != nil {
-		return nil, nil, fmt.Errorf("unable to create day column: %w", err)
-	}
-	treeView.AppendColumn(dayColumn)
-
-	// Action icon column
-	actionRenderer, err := gtk.CellRendererPixbufNew()
-	if err != nil {
-		return nil, nil, fmt.Errorf("unable to create action renderer: %w", err)
-	}
-	actionColumn, err := gtk.TreeViewColumnNewWithAttribute("I", actionRenderer, "pixbuf", 1)
-	if err != nil {
-		return nil, nil, fmt.Errorf("unable to create action column: %w", err)
-	}
-	actionColumn.SetSizing(gtk.TREE_VIEW_COLUMN_FIXED)
-	actionColumn.SetFixedWidth(30)
-	treeView.AppendColumn(actionColumn)
-
-	// App icon column
-	appRenderer, err := gtk.CellRendererPixbufNew()
-	if err != nil {
-		return nil, nil, fmt.Errorf("unable to create app renderer: %w", err)
-	}
-	appColumn, err := gtk.TreeViewColumnNewWithAttribute("A", appRenderer, "pixbuf", 2)
-	if err != nil {
-		return nil, nil, fmt.Errorf("unable to create app column: %w", err)
-	}
-	appColumn.SetSizing(gtk.TREE_VIEW_COLUMN_FIXED)
-	appColumn.SetFixedWidth(30)
-	treeView.AppendColumn(appColumn)
-
-	// Result icon column
-	resultRenderer, err := gtk.CellRendererPixbufNew()
-	if err != nil {
-		return nil, nil, fmt.Errorf("unable to create result renderer: %w", err)
-	}
-	resultColumn, err := gtk.TreeViewColumnNewWithAttribute("R", resultRenderer, "pixbuf", 3)
-	if err != nil {
-		return nil, nil, fmt.Errorf("unable to create result column: %w", err)
-	}
-	resultColumn.SetSizing(gtk.TREE_VIEW_COLUMN_FIXED)
-	resultColumn.SetFixedWidth(30)
-	treeView.AppendColumn(resultColumn)
-
-	// Description column
-	descRenderer, err := gtk.CellRendererTextNew()
-	if err != nil {
-		return nil, nil, fmt.Errorf("unable to create description renderer: %w", err)
-	}
-	descColumn, err := gtk.TreeViewColumnNewWithAttribute("Description", descRenderer, "text", 4)
-	if err != nil {
-		return nil, nil, fmt.Errorf("unable to create description column: %w", err)
-	}
-	descColumn.SetExpand(true)
-	treeView.AppendColumn(descColumn)
-
-	return treeView, listStore, nil
-}
-
-// populateLogList adds log entries to the list store
-func populateLogList(listStore *gtk.ListStore, logEntries []LogEntry) {
-	for _, entry := range logEntries {
-		iter := listStore.Append()
-
-		// Load pixbufs for icons
-		var actionPixbuf, appPixbuf, resultPixbuf *gdk.Pixbuf
-
-		if FileExists(entry.ActionIcon) {
-			actionPixbuf, _ = gdk.PixbufNewFromFile(entry.ActionIcon)
-		}
-
-		if FileExists(entry.AppIcon) {
-			appPixbuf, _ = gdk.PixbufNewFromFile(entry.AppIcon)
-		}
-
-		if FileExists(entry.ResultIcon) {
-			resultPixbuf, _ = gdk.PixbufNewFromFile(entry.ResultIcon)
-		}
-
-		listStore.SetValue(iter, 0, entry.Date)
-		if actionPixbuf != nil {
-			listStore.SetValue(iter, 1, actionPixbuf)
-		}
-		if appPixbuf != nil {
-			listStore.SetValue(iter, 2, appPixbuf)
-		}
-		if resultPixbuf != nil {
-			listStore.SetValue(iter, 3, resultPixbuf)
-		}
-		listStore.SetValue(iter, 4, entry.Caption)
-		listStore.SetValue(iter, 5, entry.Filepath)
-	}
-}
-
-// handleLogSelection handles when a user selects a log entry
-func handleLogSelection(treeView *gtk.TreeView, path *gtk.TreePath) {
-	model, _ := treeView.GetModel()
-	listStore := model.(*gtk.ListStore)
-	iter, err := listStore.GetIter(path)
-	if err != nil {
-		return
-	}
-
-	// Get the file path from column 5
-	filepathVal, err := listStore.GetValue(iter, 5)
-	if err != nil {
-		return
-	}
-
-	filepathInterface, err := filepathVal.GoValue()
-	if err != nil {
-		return
-	}
-
-	switch filepath := filepathInterface.(type) {
-	case string:
-		// Open the log file for viewing
-		if err := ViewFile(filepath); err != nil {
-			showErrorDialog("Failed to view log file: " + err.Error())
-		}
-	default:
-		return
-	}
-}
-
-// confirmDeleteAll shows a confirmation dialog for deleting all log files
-func confirmDeleteAll() bool {
-	dialog := gtk.MessageDialogNew(nil, gtk.DIALOG_MODAL, gtk.MESSAGE_QUESTION, gtk.BUTTONS_YES_NO, "Are you sure you want to delete all log files?")
-	defer dialog.Destroy()
-
-	dialog.SetTitle("Confirm Delete")
-	response := dialog.Run()
-	return response == gtk.RESPONSE_YES
-}
-
-// showErrorDialog displays an error message to the user
-func showErrorDialog(message string) {
-	dialog := gtk.MessageDialogNew(nil, gtk.DIALOG_MODAL, gtk.MESSAGE_ERROR, gtk.BUTTONS_OK, message)
-	if dialog == nil {
-		fmt.Fprintf(os.Stderr, "Error: %s\n", message)
-		return
-	}
-	defer dialog.Destroy()
-
-	dialog.SetTitle("Error")
-	dialog.Run()
-}
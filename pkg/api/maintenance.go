@@ -0,0 +1,181 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: maintenance.go
+// Description: Provides the repair and cleanup actions of the Maintenance settings: auditing app statuses and clearing caches.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package api
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// StatusProblem is an app status that doesn't match the system, found by AuditAppStatuses
+type StatusProblem struct {
+	App     string `json:"app"`
+	Problem string `json:"problem"`
+	Fixed   bool   `json:"fixed"`
+}
+
+// AuditAppStatuses looks for app statuses that don't match the system: status files with unknown values or
+// for apps that no longer exist, package-apps whose packages were installed or removed outside of Pi-Apps,
+// and installed apps whose dummy deb was removed. With fix, the problems that can be fixed safely are fixed.
+//
+//	[]StatusProblem - the problems found, sorted by app
+//	error - error if PI_APPS_DIR environment variable is not set or the status directory can't be read
+func AuditAppStatuses(fix bool) ([]StatusProblem, error) {
+	directory := GetPiAppsDir()
+	if directory == "" {
+		return nil, fmt.Errorf("PI_APPS_DIR environment variable not set")
+	}
+
+	localApps, err := listLocalApps(directory)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list local apps: %w", err)
+	}
+
+	statusDir := filepath.Join(DataDirOf(directory), "status")
+	entries, err := os.ReadDir(statusDir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read status directory: %w", err)
+	}
+
+	var problems []StatusProblem
+	statuses := make(map[string]string)
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		app := entry.Name()
+		statusFile := filepath.Join(statusDir, app)
+		data, err := os.ReadFile(statusFile)
+		if err != nil {
+			problems = append(problems, StatusProblem{App: app, Problem: Tf("the status file can't be read: %v", err)})
+			continue
+		}
+		status := strings.TrimSpace(string(data))
+		statuses[app] = status
+
+		switch AppState(status) {
+		case AppStateInstalled, AppStateUninstalled, AppStateCorrupted, AppStateDisabled:
+		default:
+			// An unknown status makes the app look installed to some checks and uninstalled to others
+			problem := StatusProblem{App: app, Problem: Tf("unknown status %q", status)}
+			if fix {
+				problem.Fixed = os.Remove(statusFile) == nil
+			}
+			problems = append(problems, problem)
+			continue
+		}
+
+		// Deprecated apps keep their status so they can still be uninstalled
+		if !slices.Contains(localApps, app) && !IsDeprecatedApp(app) {
+			problem := StatusProblem{App: app, Problem: Tf("status %s of an app that no longer exists", status)}
+			// Only a status that claims nothing is installed is safe to forget
+			if fix && AppState(status) == AppStateUninstalled {
+				problem.Fixed = os.Remove(statusFile) == nil
+			}
+			problems = append(problems, problem)
+		}
+	}
+
+	// Package-apps are installed when their first package is, whatever installed it
+	if installed, err := InstalledPackages(); err == nil {
+		for _, app := range localApps {
+			packagesFile := filepath.Join(directory, "apps", app, "packages")
+			if !FileExists(packagesFile) {
+				continue
+			}
+			status := statuses[app]
+			if status == "" {
+				status = string(AppStateUninstalled)
+			}
+			if status != string(AppStateInstalled) && status != string(AppStateUninstalled) {
+				continue
+			}
+			actual := AppStateUninstalled
+			if pkgAppInstalled(packagesFile, installed) {
+				actual = AppStateInstalled
+			}
+			if AppState(status) == actual {
+				continue
+			}
+			problem := StatusProblem{App: app, Problem: Tf("marked %s, but its packages are %s", status, actual)}
+			if fix {
+				if actual == AppStateInstalled {
+					problem.Fixed = SetAppStatus(app, string(actual)) == nil
+				} else {
+					problem.Fixed = os.Remove(filepath.Join(statusDir, app)) == nil
+				}
+			}
+			problems = append(problems, problem)
+		}
+	} else {
+		Debug(fmt.Sprintf("failed to get installed packages: %v", err))
+	}
+
+	// Without its dummy deb, the dependencies of an app can be autoremoved
+	if missing, err := ListAppsMissingDummyDebs(); err == nil && len(missing) > 0 {
+		var rebuilt []string
+		if fix {
+			rebuilt, err = RebuildDummyDebs(missing...)
+			if err != nil {
+				WarningTf("Failed to rebuild the dummy debs: %v", err)
+			}
+		}
+		for _, app := range missing {
+			problems = append(problems, StatusProblem{App: app, Problem: T("installed, but its dummy deb was removed"), Fixed: slices.Contains(rebuilt, app)})
+		}
+	}
+
+	sort.SliceStable(problems, func(i, j int) bool {
+		return problems[i].App < problems[j].App
+	})
+	return problems, nil
+}
+
+// ClearCaches removes the caches Pi-Apps rebuilds on its own: the preloaded app lists in data/preload and
+// the app hashes and device benchmark in data/cache
+//
+//	int64 - the number of bytes freed
+func ClearCaches() (int64, error) {
+	directory := GetPiAppsDir()
+	if directory == "" {
+		return 0, fmt.Errorf("PI_APPS_DIR environment variable not set")
+	}
+
+	var freed int64
+	for _, cacheDir := range []string{filepath.Join(DataDirOf(directory), "preload"), filepath.Join(DataDirOf(directory), "cache")} {
+		filepath.WalkDir(cacheDir, func(path string, d os.DirEntry, err error) error {
+			if err == nil && !d.IsDir() {
+				if info, err := d.Info(); err == nil {
+					freed += info.Size()
+				}
+			}
+			return nil
+		})
+		if err := os.RemoveAll(cacheDir); err != nil {
+			return freed, fmt.Errorf("failed to remove %s: %w", cacheDir, err)
+		}
+	}
+	return freed, nil
+}
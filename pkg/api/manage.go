@@ -39,6 +39,8 @@ import (
 
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
+
+	"github.com/pi-apps-go/pi-apps/pkg/api/errs"
 )
 
 // Action represents the type of operation to be performed on an app
@@ -83,6 +85,12 @@ func ManageApp(action Action, appName string, isUpdate bool) error {
 		return fmt.Errorf("PI_APPS_DIR environment variable not set")
 	}
 
+	lock, err := LockState(fmt.Sprintf("%s %s", action, appName))
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
 	// Validate the app exists (check both regular apps and deprecated apps)
 	appDir := filepath.Join(piAppsDir, "apps", appName)
 	appExists := false
@@ -90,7 +98,7 @@ func ManageApp(action Action, appName string, isUpdate bool) error {
 		appExists = true
 	} else if IsDeprecatedApp(appName) {
 		// For deprecated apps, check if they have stored data
-		deprecatedDir := filepath.Join(piAppsDir, "data", "deprecated-apps", appName)
+		deprecatedDir := filepath.Join(DataDirOf(piAppsDir), "deprecated-apps", appName)
 		if _, err := os.Stat(deprecatedDir); err == nil {
 			appExists = true
 			// For deprecated apps, only allow uninstall action
@@ -100,7 +108,17 @@ func ManageApp(action Action, appName string, isUpdate bool) error {
 		}
 	}
 	if !appExists {
-		return fmt.Errorf("app %s does not exist", appName)
+		return errs.New(errs.ErrAppNotFound, "app %s does not exist", appName)
+	}
+
+	// Kiosk mode applies to the CLI too, or it could do what the GUI hides
+	if err := CheckKioskApp(action, appName, isUpdate); err != nil {
+		return err
+	}
+
+	// Scripts that can't be executed would fail halfway with "Permission denied"
+	if err := CheckPiAppsDirExec(); err != nil {
+		return err
 	}
 
 	// Check if app is disabled before installation
@@ -113,26 +131,30 @@ func ManageApp(action Action, appName string, isUpdate bool) error {
 		return nil
 	}
 
-	// Check internet connection if installing
+	// Check internet connection and the app's requirements if installing
 	if action == ActionInstall {
-		if err := CheckInternetConnection(); err != nil {
-			return fmt.Errorf("no internet connection: %w", err)
+		// Installs from an app bundle don't download anything
+		if !bundleStaged() {
+			if err := CheckInternetConnection(); err != nil {
+				return fmt.Errorf("no internet connection: %w", err)
+			}
+			WarnClockSkew()
+		}
+		// Updates reinstall an app that is already there, so they aren't blocked
+		if !isUpdate {
+			if err := PreflightCheck(appName); err != nil {
+				return err
+			}
 		}
 	}
 
 	// Set up logging
-	logDir := filepath.Join(piAppsDir, "logs")
-	os.MkdirAll(logDir, 0755)
-	logFilename := fmt.Sprintf("%s-incomplete-%s.log", action, appName)
-	logPath := filepath.Join(logDir, logFilename)
+	logDir := LogsDirOf(piAppsDir)
+	EnsureDirOwned(logDir, InvokingUser, InvokingUser)
+	// Every run gets its own log file, so output of an earlier run can't end up in it
+	logPath := newLogfilePath(logDir, string(action), appName)
 
-	// Remove any existing incomplete log file to ensure we start fresh
-	// This prevents old log content from persisting in incomplete files
-	if _, err := os.Stat(logPath); err == nil {
-		os.Remove(logPath)
-	}
-
-	// Create log file (this will create a new file or truncate existing one)
+	// Create log file
 	logFile, err := os.Create(logPath)
 	if err != nil {
 		return fmt.Errorf("failed to create log file: %w", err)
@@ -165,6 +187,17 @@ func ManageApp(action Action, appName string, isUpdate bool) error {
 		time.Sleep(5 * time.Second)
 	}
 
+	// Installs are timed for EstimateInstallDuration, updates reinstall over an existing install and take less
+	started := time.Now()
+	recordDuration := func() {
+		if action == ActionInstall && !isUpdate {
+			if err := recordInstallDuration(appName, time.Since(started)); err != nil {
+				Debug(fmt.Sprintf("Failed to record the install duration of %s: %v", appName, err))
+			}
+		}
+		recordInstallMetric(appName, action, isUpdate, true, started)
+	}
+
 	// Determine script to run or package to install/uninstall
 	var cmd *exec.Cmd
 	appType, err := AppType(appName)
@@ -175,59 +208,39 @@ func ManageApp(action Action, appName string, isUpdate bool) error {
 	isScriptApp := appType == "standard"
 	if isScriptApp {
 		// Script-based app
-		scriptName := GetScriptNameForCPU(appName)
-		if scriptName == "" {
-			return fmt.Errorf("no suitable script found for %s", appName)
+		scriptPath, err := actionScriptPath(piAppsDir, action, appName)
+		if err != nil {
+			return err
 		}
 
 		// Set up script command
-		scriptPath := filepath.Join(piAppsDir, "apps", appName, scriptName)
 		cmd = exec.Command("bash", scriptPath)
 
 		// Set environment variables
 		env := os.Environ()
 		env = append(env, "PI_APPS_DIR="+piAppsDir)
 		env = append(env, "app="+appName)
+		env = append(env, ScriptExitEnv()...)
+		// The output only goes to the log, so the api calls in the script don't need to color it
+		env = append(env, colorEnvVar+"="+string(ColorOutputNever))
 
 		if isUpdate {
 			env = append(env, "script_input=update")
 		}
 
-		// If running with elevated euid, drop to real user for script apps (match original behavior)
-		if os.Geteuid() == 0 {
-			targetUser := os.Getenv("SUDO_USER")
-			var resolvedUID uint64
-			var resolvedGID uint64
-
-			// Prefer real uid when binary is setuid root
-			if targetUser == "" {
-				if ruid := os.Getuid(); ruid != 0 {
-					if u, err := user.LookupId(strconv.Itoa(ruid)); err == nil {
-						targetUser = u.Username
-					}
-				}
-			}
-
-			if targetUser == "" {
-				targetUser = os.Getenv("USER")
+		// Let the user choose the optional components of the app before the install script runs
+		if action == ActionInstall {
+			components, err := chooseAppComponents(appName, isUpdate)
+			if err != nil {
+				return err
 			}
+			env = withComponentEnv(env, components)
+		}
 
-			if targetUser != "" {
-				if u, err := user.Lookup(targetUser); err == nil {
-					resolvedUID, _ = strconv.ParseUint(u.Uid, 10, 32)
-					resolvedGID, _ = strconv.ParseUint(u.Gid, 10, 32)
-					cmd.SysProcAttr = &syscall.SysProcAttr{
-						Credential: &syscall.Credential{
-							Uid: uint32(resolvedUID),
-							Gid: uint32(resolvedGID),
-						},
-					}
-					if u.HomeDir != "" {
-						env = append(env, "HOME="+u.HomeDir)
-						cmd.Dir = u.HomeDir
-					}
-				}
-			}
+		// If running with elevated euid, drop to real user for script apps (match original behavior)
+		if home := runAsInvokingUser(cmd); home != "" {
+			env = append(env, "HOME="+home)
+			cmd.Dir = home
 		}
 
 		cmd.Env = env
@@ -246,16 +259,20 @@ func ManageApp(action Action, appName string, isUpdate bool) error {
 
 			switch action {
 			case ActionInstall:
-				err := installPackageAppDependencies(packages)
+				err := installPackageAppDependencies(strings.Fields(packages)...)
 				if err != nil {
+					recordInstallMetric(appName, action, isUpdate, false, started)
 					return fmt.Errorf("failed to install package app: %w", err)
 				}
+				recordDuration()
 				return nil
 			case ActionUninstall:
-				err := uninstallPackageAppDependencies(packages)
+				err := uninstallPackageAppDependencies(strings.Fields(packages)...)
 				if err != nil {
+					recordInstallMetric(appName, action, isUpdate, false, started)
 					return fmt.Errorf("failed to uninstall package app: %w", err)
 				}
+				recordInstallMetric(appName, action, isUpdate, true, started)
 				return nil
 			}
 			return fmt.Errorf("unsupported action %s for package app", action)
@@ -278,26 +295,64 @@ func ManageApp(action Action, appName string, isUpdate bool) error {
 	cmd.Stdout = ansiStripLogWriter
 	cmd.Stderr = ansiStripLogWriter
 
+	// Scripts started as root keep running as root unless they were switched to the original user above
+	ranAsRoot := isScriptApp && os.Geteuid() == 0 && (cmd.SysProcAttr == nil || cmd.SysProcAttr.Credential == nil)
+	if ranAsRoot {
+		fmt.Fprintf(logFile, "Running the %s script as root\n\n", action)
+	}
+
+	// Record which files a script install creates, to detect apps overwriting each other's files
+	var filesBefore installedFilesSnapshot
+	if isScriptApp && action == ActionInstall {
+		if !isUpdate {
+			warnDetectedConflicts(appName)
+		}
+		filesBefore = takeInstalledFilesSnapshot()
+		// The install script records the upstream version it installs again
+		SetInstalledUpstreamVersion(appName, "")
+	}
+
+	// Keep heavy install scripts from freezing the system
+	var limits ResourceLimits
+	if isScriptApp && action == ActionInstall {
+		limits = InstallResourceLimits(appName)
+		if limitCommand(cmd, appName, limits) {
+			fmt.Fprintf(logFile, "Running the install script in %s with %s\n\n", InstallScopeUnit(appName), limits)
+		} else {
+			limits = ResourceLimits{}
+		}
+	}
+
 	// Run the command (script apps need bash wrapper for helper functions)
 	if isScriptApp {
-		err = RunWithScriptWrappers(cmd)
+		marker := markScriptRun(cmd)
+		err = finishScriptRun(RunWithScriptWrappers(cmd), marker, logFile)
 	} else {
 		err = cmd.Run()
 	}
 
+	// An app that installed but doesn't work according to its healthcheck is corrupted, not installed
+	var healthErr error
+	if err == nil && isScriptApp && action == ActionInstall {
+		healthErr = checkInstalledHealth(appName, ansiStripLogWriter)
+		err = healthErr
+	}
+
 	// Determine success or failure
 	if err != nil {
+		recordInstallMetric(appName, action, isUpdate, false, started)
 		// Write plain text to log file (no color codes)
 		fmt.Fprintf(logFile, "\nFailed to %s %s!\n", action, appName)
 		fmt.Fprintf(logFile, "Need help? Copy the ENTIRE terminal output or take a screenshot.\n")
 		fmt.Fprintf(logFile, "Please ask on Github: https://github.com/pi-apps-go/pi-apps/issues/new/choose\n")
 		fmt.Fprintf(logFile, "Or on Discord: https://discord.gg/RXSTvaUvuu\n")
+		logExitCode(logFile, err)
 
 		// Write colored messages to stdout (terminal) matching the original bash formatting
-		fmt.Printf("\n\033[91mFailed to %s %s!\033[39m\n", action, appName)
-		fmt.Printf("\033[40m\033[93m\033[5m◢◣\033[25m\033[39m\033[49m\033[93mNeed help? Copy the \033[1mENTIRE\033[0m\033[49m\033[93m terminal output or take a screenshot.\n")
-		fmt.Printf("Please ask on Github: \033[94m\033[4mhttps://github.com/pi-apps-go/pi-apps/issues/new/choose\033[24m\033[93m\n")
-		fmt.Printf("Or on Discord: \033[94m\033[4mhttps://discord.gg/RXSTvaUvuu\033[0m\n")
+		colorPrintf(os.Stdout, "\n\033[91mFailed to %s %s!\033[39m\n", action, appName)
+		colorPrintf(os.Stdout, "\033[40m\033[93m\033[5m◢◣\033[25m\033[39m\033[49m\033[93mNeed help? Copy the \033[1mENTIRE\033[0m\033[49m\033[93m terminal output or take a screenshot.\n")
+		colorPrintf(os.Stdout, "Please ask on Github: \033[94m\033[4mhttps://github.com/pi-apps-go/pi-apps/issues/new/choose\033[24m\033[93m\n")
+		colorPrintf(os.Stdout, "Or on Discord: \033[94m\033[4mhttps://discord.gg/RXSTvaUvuu\033[0m\n")
 
 		// Format the log file to add device information (consistent with bash version)
 		formatErr := FormatLogfile(logPath)
@@ -306,32 +361,47 @@ func ManageApp(action Action, appName string, isUpdate bool) error {
 		}
 
 		// Rename log file to indicate failure
-		newLogPath := strings.Replace(logPath, "-incomplete-", "-fail-", 1)
+		newLogPath := logPathWithResult(logPath, "fail")
 		os.Rename(logPath, newLogPath)
 
-		// If app is script-type, set status to corrupted if the error is not system, internet, or package related
-		if isScriptApp {
+		// If app is script-type, set status to corrupted if the error is not system, internet, package or user related
+		if healthErr != nil {
+			SetAppStatus(appName, "corrupted")
+			// The install script itself succeeded, so the files it created belong to the app
+			if filesBefore != nil {
+				if err := writeInstalledFiles(appName, filesBefore.changedFiles(), limits, ranAsRoot); err != nil {
+					Debug(fmt.Sprintf("Failed to write install manifest of %s: %v", appName, err))
+				}
+			}
+			return healthErr
+		} else if isScriptApp {
 			// Use log_diagnose to determine error type and set appropriate status
-			diagnosis, err := LogDiagnose(logPath, true)
-			if diagnosis.ErrorType == "system" || diagnosis.ErrorType == "internet" || diagnosis.ErrorType == "package" {
+			diagnosis, err := LogDiagnose(newLogPath, true)
+			if err != nil {
+				ErrorNoExit("Unable to detect error type, setting it as failed")
+				SetAppStatus(appName, "failed")
+			} else if diagnosis.ErrorType == "system" || diagnosis.ErrorType == "internet" || diagnosis.ErrorType == "package" || diagnosis.ErrorType == "user" {
 				SetAppStatus(appName, "failed")
 			} else {
 				SetAppStatus(appName, "corrupted")
 			}
-			if err != nil {
-				ErrorNoExit("Unable to detect error type, setting it as corrupted")
-				SetAppStatus(appName, "failed")
-			}
 		}
 
 		// Extract exit code from error if available
-		if exitError, ok := err.(*exec.ExitError); ok {
-			return fmt.Errorf("command failed: exit code %d", exitError.ExitCode())
+		if code := ScriptExitCode(err); code != 0 {
+			return &ScriptExitError{App: appName, Action: string(action), ExitCode: code}
 		}
-		return fmt.Errorf("command failed: %v", err)
+		return fmt.Errorf("command failed: %w", err)
 	}
 
 	// Success
+	recordDuration()
+
+	// Uninstall scripts often forget the menu entries and directories their install script created
+	if isScriptApp && action == ActionUninstall {
+		removeLeftoverDesktopEntries(appName, logFile)
+		removeCreatedDirs(appName, logFile)
+	}
 	fmt.Fprintf(logFile, "\n%s %sed successfully.\n", action, appName)
 	StatusGreen(fmt.Sprintf("%s %sed successfully.", action, appName))
 
@@ -342,12 +412,22 @@ func ManageApp(action Action, appName string, isUpdate bool) error {
 	}
 
 	// Rename log file to indicate success
-	newLogPath := strings.Replace(logPath, "-incomplete-", "-success-", 1)
+	newLogPath := logPathWithResult(logPath, "success")
 	os.Rename(logPath, newLogPath)
 
 	// Set app status
 	SetAppStatus(appName, string(action)+"ed")
 
+	// Update the install manifest
+	if filesBefore != nil {
+		if err := writeInstalledFiles(appName, filesBefore.changedFiles(), limits, ranAsRoot); err != nil {
+			Debug(fmt.Sprintf("Failed to write install manifest of %s: %v", appName, err))
+		}
+		recordInstalledUpstreamVersion(appName)
+	} else if isScriptApp && action == ActionUninstall {
+		clearInstallManifest(appName)
+	}
+
 	// If package-type app, refresh its status
 	if appType == "package" {
 		RefreshPackageAppStatus(appName)
@@ -361,17 +441,89 @@ func ManageApp(action Action, appName string, isUpdate bool) error {
 	return nil
 }
 
+// runAsInvokingUser makes cmd run as the user that started Pi-Apps when it runs as root, with sudo or
+// as a setuid binary, so app scripts don't run as root. It returns the home directory of that user,
+// "" if cmd was left unchanged.
+func runAsInvokingUser(cmd *exec.Cmd) string {
+	if os.Geteuid() != 0 {
+		return ""
+	}
+	targetUser := os.Getenv("SUDO_USER")
+
+	// Prefer real uid when binary is setuid root
+	if targetUser == "" {
+		if ruid := os.Getuid(); ruid != 0 {
+			if u, err := user.LookupId(strconv.Itoa(ruid)); err == nil {
+				targetUser = u.Username
+			}
+		}
+	}
+
+	if targetUser == "" {
+		targetUser = os.Getenv("USER")
+	}
+	if targetUser == "" {
+		return ""
+	}
+
+	u, err := user.Lookup(targetUser)
+	if err != nil {
+		return ""
+	}
+	uid, _ := strconv.ParseUint(u.Uid, 10, 32)
+	gid, _ := strconv.ParseUint(u.Gid, 10, 32)
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Credential = &syscall.Credential{
+		Uid: uint32(uid),
+		Gid: uint32(gid),
+	}
+	return u.HomeDir
+}
+
 // InstallApp installs the specified app
 func InstallApp(appName string) error {
+	lock, err := LockState("install " + appName)
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
 	// Validate app exists
 	if !IsValidApp(appName) {
-		return fmt.Errorf("app '%s' does not exist", appName)
+		return errs.New(errs.ErrAppNotFound, "app '%s' does not exist", appName)
+	}
+	if err := CheckKioskApp(ActionInstall, appName, false); err != nil {
+		return err
 	}
 
 	// Check if already installed
 	if IsAppInstalled(appName) {
-		return fmt.Errorf("app '%s' is already installed", appName)
+		return errs.New(errs.ErrAlreadyInstalled, "app '%s' is already installed", appName)
+	}
+
+	// Refuse to install next to an app it conflicts with
+	conflicts, err := InstalledConflicts(appName)
+	if err != nil {
+		return fmt.Errorf("failed to check conflicting apps: %w", err)
+	}
+	if len(conflicts) > 0 {
+		return &AppConflictError{App: appName, Conflicts: conflicts}
 	}
+	warnDetectedConflicts(appName)
+
+	// Check the requirements the app declares, like an X11 or Wayland session
+	if err := PreflightCheck(appName); err != nil {
+		return err
+	}
+
+	// A wrong clock makes the downloads fail, installs from an app bundle don't download anything
+	if !bundleStaged() {
+		WarnClockSkew()
+	}
+
+	mentionKeptUserData(appName)
 
 	// Get app type
 	appType, err := GetAppType(appName)
@@ -385,17 +537,22 @@ func InstallApp(appName string) error {
 		err := installPackageApp(appName)
 		if err != nil {
 			// Print help message for package-based apps (similar to script-based apps)
-			fmt.Printf("\033[40m\033[93m\033[5m◢◣\033[25m\033[39m\033[49m\033[93mNeed help? Copy the \033[1mENTIRE\033[0m\033[49m\033[93m terminal output or take a screenshot.\n")
-			fmt.Printf("Please ask on Github: \033[94m\033[4mhttps://github.com/pi-apps-go/pi-apps/issues/new/choose\033[24m\033[93m\n")
-			fmt.Printf("Or on Discord: \033[94m\033[4mhttps://discord.gg/RXSTvaUvuu\033[0m\n")
+			colorPrintf(os.Stdout, "\033[40m\033[93m\033[5m◢◣\033[25m\033[39m\033[49m\033[93mNeed help? Copy the \033[1mENTIRE\033[0m\033[49m\033[93m terminal output or take a screenshot.\n")
+			colorPrintf(os.Stdout, "Please ask on Github: \033[94m\033[4mhttps://github.com/pi-apps-go/pi-apps/issues/new/choose\033[24m\033[93m\n")
+			colorPrintf(os.Stdout, "Or on Discord: \033[94m\033[4mhttps://discord.gg/RXSTvaUvuu\033[0m\n")
+			return err
 		}
-		return err
+		return checkInstalledPackageHealth(appName)
 	case "standard":
-		err := installScriptApp(appName)
+		// The install script runs the healthcheck itself, with the output going to its log
+		err := installScriptApp(appName, false)
 		return err
 	case "flatpak_package":
 		err := installFlatpakApp(appName)
-		return err
+		if err != nil {
+			return err
+		}
+		return checkInstalledPackageHealth(appName)
 	default:
 		return fmt.Errorf("unsupported app type: %s", appType)
 	}
@@ -403,9 +560,18 @@ func InstallApp(appName string) error {
 
 // UninstallApp uninstalls the specified app
 func UninstallApp(appName string) error {
+	lock, err := LockState("uninstall " + appName)
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
 	// Validate app exists
 	if !IsValidApp(appName) {
-		return fmt.Errorf("app '%s' does not exist", appName)
+		return errs.New(errs.ErrAppNotFound, "app '%s' does not exist", appName)
+	}
+	if err := CheckKioskApp(ActionUninstall, appName, false); err != nil {
+		return err
 	}
 
 	// Check if already uninstalled (allow uninstall for corrupted apps)
@@ -414,41 +580,75 @@ func UninstallApp(appName string) error {
 		return fmt.Errorf("failed to get app status: %w", err)
 	}
 	if appStatus == "uninstalled" {
-		return fmt.Errorf("app '%s' is not installed", appName)
+		return errs.New(errs.ErrNotInstalled, "app '%s' is not installed", appName)
 	}
 	// Note: corrupted apps are allowed to be uninstalled
 
+	// Refuse to uninstall an app installed apps depend on, unless the user agreed to it
+	if !uninstallWithDependentsAllowed(appName) {
+		dependents, corrupted, err := appReverseDepends(appName)
+		if err != nil {
+			return fmt.Errorf("failed to check apps that depend on %s: %w", appName, err)
+		}
+		if len(dependents) > 0 {
+			return &AppDependentsError{App: appName, Dependents: dependents}
+		}
+		if len(corrupted) > 0 {
+			WarningTf("The corrupted app(s) %s depend on %s and may stop working.", strings.Join(corrupted, ", "), appName)
+		}
+	}
+
 	// Get app type
 	appType, err := GetAppType(appName)
 	if err != nil {
 		return fmt.Errorf("failed to determine app type: %v", err)
 	}
 
+	// Stop the services of the app before its files go away, the manifest listing them is removed with them
+	if removed, err := RemoveUserServices(appName); err != nil {
+		WarningTf("Failed to remove the services of %s: %v", appName, err)
+	} else if len(removed) > 0 {
+		StatusTf("Removed the services of %s: %s", appName, strings.Join(removed, ", "))
+	}
+
 	// Handle app uninstallation based on app type
 	switch appType {
 	case "package":
-		err := uninstallPackageApp(appName)
+		err = uninstallPackageApp(appName)
 		if err != nil {
 			// Print help message for package-based apps (similar to script-based apps)
-			fmt.Printf("\033[40m\033[93m\033[5m◢◣\033[25m\033[39m\033[49m\033[93mNeed help? Copy the \033[1mENTIRE\033[0m\033[49m\033[93m terminal output or take a screenshot.\n")
-			fmt.Printf("Please ask on Github: \033[94m\033[4mhttps://github.com/pi-apps-go/pi-apps/issues/new/choose\033[24m\033[93m\n")
-			fmt.Printf("Or on Discord: \033[94m\033[4mhttps://discord.gg/RXSTvaUvuu\033[0m\n")
+			colorPrintf(os.Stdout, "\033[40m\033[93m\033[5m◢◣\033[25m\033[39m\033[49m\033[93mNeed help? Copy the \033[1mENTIRE\033[0m\033[49m\033[93m terminal output or take a screenshot.\n")
+			colorPrintf(os.Stdout, "Please ask on Github: \033[94m\033[4mhttps://github.com/pi-apps-go/pi-apps/issues/new/choose\033[24m\033[93m\n")
+			colorPrintf(os.Stdout, "Or on Discord: \033[94m\033[4mhttps://discord.gg/RXSTvaUvuu\033[0m\n")
 		}
-		return err
 	case "standard":
-		return uninstallScriptApp(appName)
+		err = uninstallScriptApp(appName)
 	case "flatpak_package":
-		return uninstallFlatpakApp(appName)
+		err = uninstallFlatpakApp(appName)
 	default:
 		return fmt.Errorf("unsupported app type: %s", appType)
 	}
+	if err != nil {
+		return err
+	}
+
+	// The uninstall script leaves the settings and data the app declares, the user decides what happens to them
+	promptUserData(appName)
+	return nil
 }
 
-// UpdateApp updates the specified app (reinstalls it)
+// UpdateApp updates the specified app (reinstalls it), restoring its previous version if that fails, see
+// UpdateAppWithRollback
 func UpdateApp(appName string) error {
+	lock, err := LockState("update " + appName)
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
 	// Validate app exists
 	if !IsValidApp(appName) {
-		return fmt.Errorf("app '%s' does not exist", appName)
+		return errs.New(errs.ErrAppNotFound, "app '%s' does not exist", appName)
 	}
 
 	// Check if already uninstalled (allow update for corrupted apps)
@@ -457,10 +657,20 @@ func UpdateApp(appName string) error {
 		return fmt.Errorf("failed to get app status: %w", err)
 	}
 	if appStatus == "uninstalled" {
-		return fmt.Errorf("app '%s' is not installed", appName)
+		return errs.New(errs.ErrNotInstalled, "app '%s' is not installed", appName)
 	}
 	// Note: corrupted apps are allowed to be updated
 
+	return UpdateAppWithRollback(appName, func() error {
+		return updateApp(appName)
+	})
+}
+
+// updateApp updates an app that is installed, without a snapshot to roll back to
+func updateApp(appName string) error {
+	// A wrong clock makes the downloads fail
+	WarnClockSkew()
+
 	// Get app type
 	appType, err := GetAppType(appName)
 	if err != nil {
@@ -470,28 +680,41 @@ func UpdateApp(appName string) error {
 	// Handle app update based on app type
 	switch appType {
 	case "package":
+		// Held packages were pinned on purpose, so they are only updated when the user asked for it
+		if held := heldAppPackages(appName); len(held) > 0 {
+			if !heldUpdateAllowed(appName) {
+				StatusTf("Skipping the update of %s because its packages are held: %s", appName, strings.Join(held, " "))
+				StatusT("Pass -force to unhold them and update anyway.")
+				return nil
+			}
+			for _, pkg := range held {
+				if err := PackageUnhold(pkg); err != nil {
+					return fmt.Errorf("failed to unhold %s before updating %s: %w", pkg, appName, err)
+				}
+			}
+		}
 		// For package-based apps, this is essentially a reinstall
 		err = uninstallPackageApp(appName)
 		if err != nil {
 			// Print help message for package-based apps
-			fmt.Printf("\033[40m\033[93m\033[5m◢◣\033[25m\033[39m\033[49m\033[93mNeed help? Copy the \033[1mENTIRE\033[0m\033[49m\033[93m terminal output or take a screenshot.\n")
-			fmt.Printf("Please ask on Github: \033[94m\033[4mhttps://github.com/pi-apps-go/pi-apps/issues/new/choose\033[24m\033[93m\n")
-			fmt.Printf("Or on Discord: \033[94m\033[4mhttps://discord.gg/RXSTvaUvuu\033[0m\n")
+			colorPrintf(os.Stdout, "\033[40m\033[93m\033[5m◢◣\033[25m\033[39m\033[49m\033[93mNeed help? Copy the \033[1mENTIRE\033[0m\033[49m\033[93m terminal output or take a screenshot.\n")
+			colorPrintf(os.Stdout, "Please ask on Github: \033[94m\033[4mhttps://github.com/pi-apps-go/pi-apps/issues/new/choose\033[24m\033[93m\n")
+			colorPrintf(os.Stdout, "Or on Discord: \033[94m\033[4mhttps://discord.gg/RXSTvaUvuu\033[0m\n")
 			return fmt.Errorf("failed to uninstall app during update: %v", err)
 		}
 		err = installPackageApp(appName)
 		if err != nil {
 			// Print help message for package-based apps
-			fmt.Printf("\033[40m\033[93m\033[5m◢◣\033[25m\033[39m\033[49m\033[93mNeed help? Copy the \033[1mENTIRE\033[0m\033[49m\033[93m terminal output or take a screenshot.\n")
-			fmt.Printf("Please ask on Github: \033[94m\033[4mhttps://github.com/pi-apps-go/pi-apps/issues/new/choose\033[24m\033[93m\n")
-			fmt.Printf("Or on Discord: \033[94m\033[4mhttps://discord.gg/RXSTvaUvuu\033[0m\n")
+			colorPrintf(os.Stdout, "\033[40m\033[93m\033[5m◢◣\033[25m\033[39m\033[49m\033[93mNeed help? Copy the \033[1mENTIRE\033[0m\033[49m\033[93m terminal output or take a screenshot.\n")
+			colorPrintf(os.Stdout, "Please ask on Github: \033[94m\033[4mhttps://github.com/pi-apps-go/pi-apps/issues/new/choose\033[24m\033[93m\n")
+			colorPrintf(os.Stdout, "Or on Discord: \033[94m\033[4mhttps://discord.gg/RXSTvaUvuu\033[0m\n")
 		}
 		return err
 	case "standard":
 		// For script-based apps, run the update script if it exists, otherwise reinstall
 		updateScriptPath := filepath.Join(GetPiAppsDir(), "apps", appName, "update")
 		if _, err := os.Stat(updateScriptPath); err == nil {
-			return runAppScript(appName, "update")
+			return runAppScript(appName, "update", true)
 		}
 
 		// No update script, so uninstall and reinstall
@@ -499,7 +722,7 @@ func UpdateApp(appName string) error {
 		if err != nil {
 			return fmt.Errorf("failed to uninstall app during update: %v", err)
 		}
-		return installScriptApp(appName)
+		return installScriptApp(appName, true)
 	default:
 		return fmt.Errorf("unsupported app type: %s", appType)
 	}
@@ -509,7 +732,7 @@ func UpdateApp(appName string) error {
 func InstallIfNotInstalled(appName string) error {
 	// Validate app exists
 	if !IsValidApp(appName) {
-		return fmt.Errorf("app '%s' does not exist", appName)
+		return errs.New(errs.ErrAppNotFound, "app '%s' does not exist", appName)
 	}
 
 	// Check if already installed
@@ -529,11 +752,11 @@ func CheckInternetConnection() error {
 	}
 	resp, err := client.Get("https://github.com")
 	if err != nil {
-		return fmt.Errorf("github.com failed to respond: %w", err)
+		return errs.New(errs.ErrNetwork, "github.com failed to respond: %w", err)
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode < 200 || resp.StatusCode >= 400 {
-		return fmt.Errorf("github.com returned status: %s", resp.Status)
+		return errs.New(errs.ErrNetwork, "github.com returned status: %s", resp.Status)
 	}
 	return nil
 }
@@ -544,11 +767,22 @@ func SetAppStatus(appName, status string) error {
 	if piAppsDir == "" {
 		return fmt.Errorf("PI_APPS_DIR environment variable not set")
 	}
+	statusFile, err := AppDataPath("status", appName)
+	if err != nil {
+		return err
+	}
+	// A newline would turn the status file into two statuses
+	if strings.ContainsAny(status, "\r\n") {
+		return fmt.Errorf("invalid status %q", status)
+	}
 
-	statusDir := filepath.Join(piAppsDir, "data", "status")
+	statusDir := filepath.Dir(statusFile)
 	os.MkdirAll(statusDir, 0755)
 
-	statusFile := filepath.Join(statusDir, appName)
+	if status == "installed" {
+		recordInstallCodename(appName)
+	}
+
 	return os.WriteFile(statusFile, []byte(status), 0644)
 }
 
@@ -609,6 +843,29 @@ func RefreshFlatpakAppStatus(appName string) error {
 	}
 }
 
+// actionScriptPath returns the script of a script-based app that runs an action: the install script for the CPU of
+// this system when installing, the script named after the action otherwise, like uninstall
+func actionScriptPath(piAppsDir string, action Action, appName string) (string, error) {
+	if action == ActionInstall {
+		scriptName := GetScriptNameForCPU(appName)
+		if scriptName == "" {
+			return "", errs.New(errs.ErrUnsupportedArch, "no suitable script found for %s", appName)
+		}
+		return filepath.Join(piAppsDir, "apps", appName, scriptName), nil
+	}
+	scriptPath := filepath.Join(piAppsDir, "apps", appName, string(action))
+	if FileExists(scriptPath) {
+		return scriptPath, nil
+	}
+	// Deprecated apps keep their uninstall script after their folder is gone
+	if action == ActionUninstall && IsDeprecatedApp(appName) {
+		if storedScript, err := GetDeprecatedAppUninstallScript(appName); err == nil {
+			return storedScript, nil
+		}
+	}
+	return "", fmt.Errorf("%s script does not exist for app '%s'", action, appName)
+}
+
 // GetScriptNameForCPU determines which install script to use based on the current CPU architecture
 func GetScriptNameForCPU(appName string) string {
 	piAppsDir := GetPiAppsDir()
@@ -684,7 +941,16 @@ func ValidateApps(action Action, appList []string) ([]string, error) {
 			continue
 		}
 
-		// Additional validation could be added here
+		// An invalid user-data file must not keep an installed app from being uninstalled. A translated description
+		// doesn't change what managing the app does, so only ValidateApp checks those.
+		err := ValidateAppName(app)
+		if err == nil {
+			err = validateAppFiles(appDir)
+		}
+		if err != nil && action != ActionUninstall {
+			fmt.Printf("Invalid app '%s'. Cannot %s it: %v\n", app, action, err)
+			continue
+		}
 
 		validApps = append(validApps, app)
 	}
@@ -780,37 +1046,35 @@ func uninstallFlatpakApp(appName string) error {
 }
 
 // installScriptApp installs a script-based app
-func installScriptApp(appName string) error {
-	err := runAppScript(appName, "install")
+func installScriptApp(appName string, isUpdate bool) error {
+	err := runAppScript(appName, "install", isUpdate)
 	return err
 }
 
 // uninstallScriptApp uninstalls a script-based app
 func uninstallScriptApp(appName string) error {
-	return runAppScript(appName, "uninstall")
+	return runAppScript(appName, "uninstall", false)
 }
 
-// runAppScript runs a script for an app (install, uninstall, update)
-func runAppScript(appName, scriptName string) error {
+// runAppScript runs a script for an app (install, uninstall, update), isUpdate telling whether it is part of an
+// update so the components chosen before are reused
+func runAppScript(appName, scriptName string, isUpdate bool) error {
 	// Get PI_APPS_DIR environment variable
 	piAppsDir := GetPiAppsDir()
 	if piAppsDir == "" {
 		return fmt.Errorf("PI_APPS_DIR environment variable not set")
 	}
+	if err := CheckPiAppsDirExec(); err != nil {
+		return err
+	}
 
 	// Set up logging
-	logDir := filepath.Join(piAppsDir, "logs")
-	os.MkdirAll(logDir, 0755)
-	logFilename := fmt.Sprintf("%s-incomplete-%s.log", scriptName, appName)
-	logPath := filepath.Join(logDir, logFilename)
-
-	// Remove any existing incomplete log file to ensure we start fresh
-	// This prevents old log content from persisting in incomplete files
-	if _, err := os.Stat(logPath); err == nil {
-		os.Remove(logPath)
-	}
+	logDir := LogsDirOf(piAppsDir)
+	EnsureDirOwned(logDir, InvokingUser, InvokingUser)
+	// Every run gets its own log file, so output of an earlier run can't end up in it
+	logPath := newLogfilePath(logDir, scriptName, appName)
 
-	// Create log file (this will create a new file or truncate existing one)
+	// Create log file
 	logFile, err := os.Create(logPath)
 	if err != nil {
 		return fmt.Errorf("failed to create log file: %w", err)
@@ -859,17 +1123,17 @@ func runAppScript(appName, scriptName string) error {
 				if _, err := os.Stat(install32Path); err == nil {
 					scriptPath = install32Path
 				} else {
-					return fmt.Errorf("install script does not exist for app '%s' on 32-bit architecture", appName)
+					return errs.New(errs.ErrUnsupportedArch, "install script does not exist for app '%s' on 32-bit architecture", appName)
 				}
 			} else if is64Bit {
 				// 64-bit architecture
 				if _, err := os.Stat(install64Path); err == nil {
 					scriptPath = install64Path
 				} else {
-					return fmt.Errorf("install script does not exist for app '%s' on 64-bit architecture", appName)
+					return errs.New(errs.ErrUnsupportedArch, "install script does not exist for app '%s' on 64-bit architecture", appName)
 				}
 			} else {
-				return fmt.Errorf("unsupported architecture: %s", arch)
+				return errs.New(errs.ErrUnsupportedArch, "unsupported architecture: %s", arch)
 			}
 		default:
 			return fmt.Errorf("%s script does not exist for app '%s'", scriptName, appName)
@@ -878,6 +1142,7 @@ func runAppScript(appName, scriptName string) error {
 
 	fmt.Printf("Running script: %s\n", scriptPath)
 	fmt.Fprintf(logFile, "Running script: %s\n", scriptPath)
+	ReportPhase(PhaseRunningScript, -1)
 
 	// Make script executable if it's not already
 	err = os.Chmod(scriptPath, 0755)
@@ -953,6 +1218,27 @@ cd "$HOME"
 		cmd = exec.Command(tempScriptPath)
 	}
 
+	ranAsRoot := needsSudo || os.Geteuid() == 0
+	if ranAsRoot {
+		fmt.Fprintf(logFile, "Running the %s script as root\n\n", scriptName)
+	}
+
+	// The install script records the upstream version it installs again
+	if scriptName == "install" {
+		SetInstalledUpstreamVersion(appName, "")
+	}
+
+	// Keep heavy install scripts from freezing the system
+	var limits ResourceLimits
+	if scriptName == "install" && !needsSudo {
+		limits = InstallResourceLimits(appName)
+		if limitCommand(cmd, appName, limits) {
+			fmt.Fprintf(logFile, "Running the install script in %s with %s\n\n", InstallScopeUnit(appName), limits)
+		} else {
+			limits = ResourceLimits{}
+		}
+	}
+
 	// Create ANSI-stripping writer for log file to avoid escape codes in logs
 	ansiStripLogWriter := NewAnsiStripWriter(logFile)
 	// Connect command output to both log file (with ANSI stripped) and stdout (with ANSI preserved)
@@ -966,15 +1252,40 @@ cd "$HOME"
 	env = append(env, fmt.Sprintf("PI_APPS_DIR=%s", GetPiAppsDir()))
 	env = append(env, fmt.Sprintf("app=%s", appName)) // Use lowercase 'app' to match bash API
 	env = append(env, "DEBIAN_FRONTEND=noninteractive")
+	env = append(env, ScriptExitEnv()...)
+	// The script output reaches stdout through a pipe, color it as if the script wrote to stdout itself
+	env = append(env, scriptColorEnv(os.Stdout))
 
 	// Add script_input=update if this is an update operation
 	if scriptName == "update" || strings.Contains(scriptName, "update") {
 		env = append(env, "script_input=update")
 	}
 
+	// Let the user choose the optional components of the app before the install or update script runs
+	if scriptName == "install" || scriptName == "update" {
+		components, err := chooseAppComponents(appName, isUpdate)
+		if err != nil {
+			return err
+		}
+		env = withComponentEnv(env, components)
+	}
+
 	cmd.Env = env
-	// Run the command
-	err = cmd.Run()
+	marker := markScriptRun(cmd)
+	// Run the command, the progress monitor can cancel it meanwhile
+	err = cmd.Start()
+	if err == nil {
+		setRunningScript(cmd.Process)
+		err = finishScriptRun(cmd.Wait(), marker, logFile)
+	}
+	cancelled := setRunningScript(nil)
+
+	// An app that installed but doesn't work according to its healthcheck is corrupted, not installed
+	var healthErr error
+	if err == nil && scriptName == "install" {
+		healthErr = checkInstalledHealth(appName, ansiStripLogWriter)
+		err = healthErr
+	}
 
 	// Determine success or failure
 	if err != nil {
@@ -983,29 +1294,35 @@ cd "$HOME"
 		fmt.Fprintf(logFile, "Need help? Copy the ENTIRE terminal output or take a screenshot.\n")
 		fmt.Fprintf(logFile, "Please ask on Github: https://github.com/pi-apps-go/pi-apps/issues/new/choose\n")
 		fmt.Fprintf(logFile, "Or on Discord: https://discord.gg/RXSTvaUvuu\n")
+		logExitCode(logFile, err)
+		if cancelled {
+			fmt.Fprintf(logFile, "The %s script was cancelled by the user.\n", scriptName)
+		}
 
 		// Write colored messages to stdout (terminal) matching the original bash formatting
-		fmt.Printf("\n\033[91mFailed to %s %s!\033[39m\n", scriptName, appName)
-		fmt.Printf("\033[40m\033[93m\033[5m◢◣\033[25m\033[39m\033[49m\033[93mNeed help? Copy the \033[1mENTIRE\033[0m\033[49m\033[93m terminal output or take a screenshot.\n")
-		fmt.Printf("Please ask on Github: \033[94m\033[4mhttps://github.com/pi-apps-go/pi-apps/issues/new/choose\033[24m\033[93m\n")
-		fmt.Printf("Or on Discord: \033[94m\033[4mhttps://discord.gg/RXSTvaUvuu\033[0m\n")
+		colorPrintf(os.Stdout, "\n\033[91mFailed to %s %s!\033[39m\n", scriptName, appName)
+		colorPrintf(os.Stdout, "\033[40m\033[93m\033[5m◢◣\033[25m\033[39m\033[49m\033[93mNeed help? Copy the \033[1mENTIRE\033[0m\033[49m\033[93m terminal output or take a screenshot.\n")
+		colorPrintf(os.Stdout, "Please ask on Github: \033[94m\033[4mhttps://github.com/pi-apps-go/pi-apps/issues/new/choose\033[24m\033[93m\n")
+		colorPrintf(os.Stdout, "Or on Discord: \033[94m\033[4mhttps://discord.gg/RXSTvaUvuu\033[0m\n")
 
 		// Format the log file to add device information (consistent with bash version)
-		err := FormatLogfile(logPath)
-		if err != nil {
-			fmt.Printf("Warning: failed to format log file %s: %v\n", logPath, err)
+		if formatErr := FormatLogfile(logPath); formatErr != nil {
+			fmt.Printf("Warning: failed to format log file %s: %v\n", logPath, formatErr)
 		}
 
 		// Rename log file to indicate failure
-		newLogPath := strings.Replace(logPath, "-incomplete-", "-fail-", 1)
+		newLogPath := logPathWithResult(logPath, "fail")
 		os.Rename(logPath, newLogPath)
 
 		// For script-type apps, set status to corrupted if the error is not system, internet, or package related
 		appType, typeErr := GetAppType(appName)
-		if typeErr == nil && appType == "standard" {
+		if healthErr != nil {
+			SetAppStatus(appName, "corrupted")
+			return healthErr
+		} else if typeErr == nil && appType == "standard" {
 			// Use log_diagnose to determine error type and set appropriate status
 			diagnosis, diagErr := LogDiagnose(newLogPath, true)
-			if diagErr == nil && (diagnosis.ErrorType == "system" || diagnosis.ErrorType == "internet" || diagnosis.ErrorType == "package") {
+			if diagErr == nil && (diagnosis.ErrorType == "system" || diagnosis.ErrorType == "internet" || diagnosis.ErrorType == "package" || diagnosis.ErrorType == "user") {
 				SetAppStatus(appName, "failed")
 			} else {
 				SetAppStatus(appName, "corrupted")
@@ -1016,11 +1333,20 @@ cd "$HOME"
 			}
 		}
 
+		if cancelled {
+			return errs.New(errs.ErrCancelled, "the %s script of %s was cancelled", scriptName, appName)
+		}
 		// Extract exit code from error if available
-		if exitError, ok := err.(*exec.ExitError); ok {
-			return fmt.Errorf("command failed: exit code %d", exitError.ExitCode())
+		if code := ScriptExitCode(err); code != 0 {
+			return &ScriptExitError{App: appName, Action: scriptName, ExitCode: code}
 		}
-		return fmt.Errorf("command failed: %v", err)
+		return fmt.Errorf("command failed: %w", err)
+	}
+
+	// Uninstall scripts often forget the menu entries and directories their install script created
+	if scriptName == "uninstall" {
+		removeLeftoverDesktopEntries(appName, logFile)
+		removeCreatedDirs(appName, logFile)
 	}
 
 	// Success
@@ -1034,9 +1360,21 @@ cd "$HOME"
 	}
 
 	// Rename log file to indicate success
-	newLogPath := strings.Replace(logPath, "-incomplete-", "-success-", 1)
+	newLogPath := logPathWithResult(logPath, "success")
 	os.Rename(logPath, newLogPath)
 
+	// Record the resource limits the install script ran with in the install manifest
+	if scriptName == "install" {
+		files, err := ReadInstalledFiles(appName)
+		if err == nil {
+			err = writeInstalledFiles(appName, files, limits, ranAsRoot)
+		}
+		if err != nil {
+			Debug(fmt.Sprintf("Failed to write install manifest of %s: %v", appName, err))
+		}
+		recordInstalledUpstreamVersion(appName)
+	}
+
 	// Display success message consistently for both package and script apps
 	switch scriptName {
 	case "install":
@@ -1051,13 +1389,16 @@ cd "$HOME"
 // IsValidApp checks if an app exists in the Pi-Apps directory
 // This includes both regular apps and deprecated apps
 func IsValidApp(appName string) bool {
-	appDir := filepath.Join(GetPiAppsDir(), "apps", appName)
+	appDir, err := AppPath(appName)
+	if err != nil {
+		return false
+	}
 	if _, err := os.Stat(appDir); err == nil {
 		return true
 	}
 	// Check if it's a deprecated app
 	if IsDeprecatedApp(appName) {
-		deprecatedDir := filepath.Join(GetPiAppsDir(), "data", "deprecated-apps", appName)
+		deprecatedDir := filepath.Join(GetDataDir(), "deprecated-apps", appName)
 		if _, err := os.Stat(deprecatedDir); err == nil {
 			return true
 		}
@@ -1067,7 +1408,10 @@ func IsValidApp(appName string) bool {
 
 // IsAppInstalled checks if an app is installed
 func IsAppInstalled(appName string) bool {
-	statusFile := filepath.Join(GetPiAppsDir(), "data", "status", appName)
+	statusFile, err := AppDataPath("status", appName)
+	if err != nil {
+		return false
+	}
 	content, err := os.ReadFile(statusFile)
 	if err != nil {
 		return false
@@ -1082,7 +1426,7 @@ func GetAppType(appName string) (string, error) {
 	// For deprecated apps, check the deprecated directory
 	if IsDeprecatedApp(appName) {
 		// Deprecated apps only have uninstall scripts, so they're always "standard"
-		deprecatedUninstall := filepath.Join(GetPiAppsDir(), "data", "deprecated-apps", appName, "uninstall")
+		deprecatedUninstall := filepath.Join(GetDataDir(), "deprecated-apps", appName, "uninstall")
 		if _, err := os.Stat(deprecatedUninstall); err == nil {
 			return "standard", nil
 		}
@@ -1125,7 +1469,7 @@ func GetAppType(appName string) (string, error) {
 
 // markAppAsInstalled marks an app as installed in the status directory
 func markAppAsInstalled(appName string) error {
-	statusDir := filepath.Join(GetPiAppsDir(), "data", "status")
+	statusDir := filepath.Join(GetDataDir(), "status")
 
 	// Create status directory if it doesn't exist
 	if _, err := os.Stat(statusDir); os.IsNotExist(err) {
@@ -1135,13 +1479,15 @@ func markAppAsInstalled(appName string) error {
 		}
 	}
 
+	recordInstallCodename(appName)
+
 	statusFile := filepath.Join(statusDir, appName)
 	return os.WriteFile(statusFile, []byte("installed"), 0644)
 }
 
 // markAppAsUninstalled marks an app as uninstalled in the status directory
 func markAppAsUninstalled(appName string) error {
-	statusFile := filepath.Join(GetPiAppsDir(), "data", "status", appName)
+	statusFile := filepath.Join(GetDataDir(), "status", appName)
 
 	// If the status file exists, remove it
 	if _, err := os.Stat(statusFile); err == nil {
@@ -0,0 +1,95 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: entries.go
+// Description: The migrations applied to the local data of Pi-Apps directories, listed in All.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package migrations
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// normalizeCategoryOverrides rewrites data/category-overrides as one "app|category" line per app. Older versions
+// left spaces around the fields, Windows line endings, lines without a category and several lines for one app,
+// of which only the first counts.
+func normalizeCategoryOverrides(dir string) error {
+	path := filepath.Join(DataDir(dir), "category-overrides")
+	content, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var lines []string
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "#") {
+			lines = append(lines, line)
+			continue
+		}
+		fields := strings.Split(line, "|")
+		if len(fields) < 2 {
+			continue
+		}
+		app := strings.TrimSpace(fields[0])
+		if app == "" || seen[app] {
+			continue
+		}
+		seen[app] = true
+		lines = append(lines, app+"|"+strings.TrimSpace(fields[1]))
+	}
+
+	normalized := ""
+	if len(lines) > 0 {
+		normalized = strings.Join(lines, "\n") + "\n"
+	}
+	if normalized == string(content) {
+		return nil
+	}
+	return writeFileAtomic(path, normalized)
+}
+
+// clearUpdateStatus removes the update lists the updater caches in data/update-status for its fast mode. Lists
+// cached by versions that didn't check the catalog version may contain app updates this version refuses to apply.
+func clearUpdateStatus(dir string) error {
+	for _, name := range []string{"updatable-files", "updatable-apps"} {
+		if err := os.Remove(filepath.Join(DataDir(dir), "update-status", name)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+// regenerateAppIcons generates the 48 and 128 pixel icons the GUI uses on scaled screens, which older versions
+// didn't have, for all apps at once instead of letting the GUI upscale the smaller ones
+func regenerateAppIcons(dir string) error {
+	if RegenerateAppIcons == nil {
+		return nil
+	}
+	return RegenerateAppIcons(dir)
+}
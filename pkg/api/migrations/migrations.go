@@ -0,0 +1,163 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: migrations.go
+// Description: Brings the local data of a Pi-Apps directory written by older versions up to date, running each migration once.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+// Package migrations transforms the local data of a Pi-Apps directory (the data folder) when its layout changes.
+//
+// Each migration runs once per directory and is recorded in data/migrations-applied. Migrations must be idempotent:
+// several Pi-Apps programs can start at the same time, and a migration that failed halfway is run again.
+// This package only uses the standard library, so the api package can run it from Init.
+package migrations
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Migration transforms the local data of a Pi-Apps directory
+type Migration struct {
+	// Version orders the migrations, each one has a unique version
+	Version int
+	// Description tells what the migration does, it's recorded with the version once the migration is applied
+	Description string
+	// Apply transforms the Pi-Apps directory dir. Running it again on data it already transformed changes nothing.
+	Apply func(dir string) error
+}
+
+// RegenerateAppIcons generates the icon sizes the apps of a Pi-Apps directory are missing. The api package sets it,
+// as this package can't import it. Migrations needing it do nothing where it's not set, like in the tests of this package.
+var RegenerateAppIcons func(dir string) error
+
+// DataDir returns the data folder of a Pi-Apps directory. The api package sets it, as the data of a read-only
+// Pi-Apps directory is kept in the home folder of each user.
+var DataDir = func(dir string) string {
+	return filepath.Join(dir, "data")
+}
+
+// appliedFile is the file in data recording the versions of the applied migrations, one "<version> <description>" per line
+const appliedFile = "migrations-applied"
+
+// All returns the migrations in the order they are applied
+func All() []Migration {
+	return []Migration{
+		{Version: 1, Description: "Normalize the category-overrides file", Apply: normalizeCategoryOverrides},
+		{Version: 2, Description: "Clear the update lists cached before the catalog version was checked", Apply: clearUpdateStatus},
+		{Version: 3, Description: "Generate the app icon sizes for scaled screens", Apply: regenerateAppIcons},
+	}
+}
+
+// Applied returns the versions of the migrations applied to a Pi-Apps directory
+func Applied(dir string) (map[int]bool, error) {
+	applied := make(map[int]bool)
+	file, err := os.Open(filepath.Join(DataDir(dir), appliedFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return applied, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		field, _, _ := strings.Cut(strings.TrimSpace(scanner.Text()), " ")
+		if version, err := strconv.Atoi(field); err == nil {
+			applied[version] = true
+		}
+	}
+	return applied, scanner.Err()
+}
+
+// Pending returns the migrations that weren't applied to a Pi-Apps directory yet, in order
+func Pending(dir string) ([]Migration, error) {
+	applied, err := Applied(dir)
+	if err != nil {
+		return nil, err
+	}
+	var pending []Migration
+	for _, migration := range All() {
+		if !applied[migration.Version] {
+			pending = append(pending, migration)
+		}
+	}
+	return pending, nil
+}
+
+// Run applies the pending migrations to a Pi-Apps directory and returns the ones it applied.
+// It stops at the first migration that fails, as later ones may depend on it, and that one runs again next time.
+func Run(dir string) ([]Migration, error) {
+	pending, err := Pending(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the applied migrations: %w", err)
+	}
+	var applied []Migration
+	for _, migration := range pending {
+		if err := migration.Apply(dir); err != nil {
+			return applied, fmt.Errorf("migration %d (%s) failed: %w", migration.Version, migration.Description, err)
+		}
+		if err := recordApplied(dir, migration); err != nil {
+			return applied, fmt.Errorf("failed to record migration %d: %w", migration.Version, err)
+		}
+		applied = append(applied, migration)
+	}
+	return applied, nil
+}
+
+// recordApplied adds a migration to data/migrations-applied
+func recordApplied(dir string, migration Migration) error {
+	path := filepath.Join(DataDir(dir), appliedFile)
+	content, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	lines = slices.DeleteFunc(lines, func(line string) bool { return line == "" })
+	lines = append(lines, fmt.Sprintf("%d %s", migration.Version, migration.Description))
+	return writeFileAtomic(path, strings.Join(lines, "\n")+"\n")
+}
+
+// writeFileAtomic replaces a file by renaming a temporary file over it, so programs reading it never see half of it
+func writeFileAtomic(path, content string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	temp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+	if _, err := temp.WriteString(content); err != nil {
+		temp.Close()
+		return err
+	}
+	if err := temp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(temp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(temp.Name(), path)
+}
@@ -0,0 +1,210 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package migrations
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeFixture writes the files of a fixture Pi-Apps directory, keyed by path relative to it
+func writeFixture(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// readFixture returns the content of a file of a fixture directory, or "<missing>"
+func readFixture(t *testing.T, dir, name string) string {
+	t.Helper()
+	content, err := os.ReadFile(filepath.Join(dir, name))
+	if os.IsNotExist(err) {
+		return "<missing>"
+	} else if err != nil {
+		t.Fatal(err)
+	}
+	return string(content)
+}
+
+func TestVersionsAreOrderedAndUnique(t *testing.T) {
+	previous := 0
+	for _, migration := range All() {
+		if migration.Version <= previous {
+			t.Errorf("migration %d comes after migration %d", migration.Version, previous)
+		}
+		if migration.Description == "" || strings.Contains(migration.Description, "\n") || migration.Apply == nil {
+			t.Errorf("migration %d needs a one line description and an Apply function", migration.Version)
+		}
+		previous = migration.Version
+	}
+}
+
+func TestRunOnOldInstalls(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  map[string]string // expected content of files after the migrations, "<missing>" for removed files
+	}{
+		{
+			name:  "fresh install",
+			files: map[string]string{"data/settings/App List Style": "Pi-Apps\n"},
+			want: map[string]string{
+				"data/settings/App List Style": "Pi-Apps\n",
+				"data/category-overrides":      "<missing>",
+			},
+		},
+		{
+			name: "old install",
+			files: map[string]string{
+				"data/category-overrides":            "# My categories\r\n Zoom | Internet \r\nZoom|Tools\r\nBroken line\r\n|Games\r\n\r\nBox64|Tools\r\n",
+				"data/update-status/updatable-apps":  "Zoom\n",
+				"data/update-status/updatable-files": "api\n",
+				"data/status/Zoom":                   "installed\n",
+			},
+			want: map[string]string{
+				"data/category-overrides":            "# My categories\nZoom|Internet\nBox64|Tools\n",
+				"data/update-status/updatable-apps":  "<missing>",
+				"data/update-status/updatable-files": "<missing>",
+				"data/status/Zoom":                   "installed\n",
+			},
+		},
+		{
+			name: "empty overrides",
+			files: map[string]string{
+				"data/category-overrides": "\n\n",
+			},
+			want: map[string]string{
+				"data/category-overrides": "",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeFixture(t, tt.files)
+
+			applied, err := Run(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(applied) != len(All()) {
+				t.Errorf("%d migrations applied, want all %d", len(applied), len(All()))
+			}
+			for name, want := range tt.want {
+				if got := readFixture(t, dir, name); got != want {
+					t.Errorf("%s = %q, want %q", name, got, want)
+				}
+			}
+
+			// Every migration is recorded and runs only once
+			if pending, err := Pending(dir); err != nil || len(pending) != 0 {
+				t.Errorf("Pending after Run = %v, %v, want none", pending, err)
+			}
+			if applied, err := Run(dir); err != nil || len(applied) != 0 {
+				t.Errorf("second Run applied %v, %v, want nothing", applied, err)
+			}
+
+			// Migrations are idempotent, running them again on migrated data changes nothing
+			for _, migration := range All() {
+				if err := migration.Apply(dir); err != nil {
+					t.Fatalf("migration %d failed on migrated data: %v", migration.Version, err)
+				}
+			}
+			for name, want := range tt.want {
+				if got := readFixture(t, dir, name); got != want {
+					t.Errorf("%s = %q after applying the migrations again, want %q", name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestRunSkipsAppliedMigrations(t *testing.T) {
+	// The category overrides were normalized by an earlier version, whose file is left as it is
+	dir := writeFixture(t, map[string]string{
+		"data/migrations-applied":           "1 Normalize the category-overrides file\n",
+		"data/category-overrides":           " Zoom | Internet \n",
+		"data/update-status/updatable-apps": "Zoom\n",
+	})
+
+	applied, err := Run(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(applied) != len(All())-1 || applied[0].Version != 2 {
+		t.Fatalf("Run applied %v, want all migrations but 1", applied)
+	}
+	if got := readFixture(t, dir, "data/category-overrides"); got != " Zoom | Internet \n" {
+		t.Errorf("the applied migration ran again: %q", got)
+	}
+	if got := readFixture(t, dir, "data/migrations-applied"); !strings.HasPrefix(got, "1 Normalize") || !strings.Contains(got, "\n2 ") {
+		t.Errorf("data/migrations-applied = %q", got)
+	}
+}
+
+func TestRunStopsAtAFailure(t *testing.T) {
+	// A category-overrides directory can't be read, so the first migration fails
+	dir := writeFixture(t, map[string]string{
+		"data/category-overrides/file":      "",
+		"data/update-status/updatable-apps": "Zoom\n",
+	})
+
+	applied, err := Run(dir)
+	if err == nil {
+		t.Fatal("Run succeeded although a migration failed")
+	}
+	if len(applied) != 0 {
+		t.Errorf("Run applied %v", applied)
+	}
+	if got := readFixture(t, dir, "data/update-status/updatable-apps"); got != "Zoom\n" {
+		t.Error("a migration after the failed one was applied")
+	}
+	if pending, _ := Pending(dir); len(pending) != len(All()) {
+		t.Errorf("%d migrations pending, want all of them to run again", len(pending))
+	}
+}
+
+func TestRegenerateAppIconsMigration(t *testing.T) {
+	dir := writeFixture(t, map[string]string{"data/migrations-applied": "1 Normalize the category-overrides file\n2 Clear the update lists\n"})
+	var regenerated []string
+	RegenerateAppIcons = func(dir string) error {
+		regenerated = append(regenerated, dir)
+		return nil
+	}
+	t.Cleanup(func() { RegenerateAppIcons = nil })
+
+	applied, err := Run(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(applied) != 1 || applied[0].Version != 3 || len(regenerated) != 1 || regenerated[0] != dir {
+		t.Errorf("Run applied %v and regenerated the icons of %v, want the icons of %s regenerated once", applied, regenerated, dir)
+	}
+	if applied, _ := Run(dir); len(applied) != 0 || len(regenerated) != 1 {
+		t.Error("the icons were regenerated again")
+	}
+}
@@ -0,0 +1,182 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: mount_check.go
+// Description: Detects a Pi-Apps directory on a noexec mount or a filesystem without Linux permissions,
+// where app scripts can't run, and explains how to fix it before an install fails halfway.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package api
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
+
+	"github.com/pi-apps-go/pi-apps/pkg/api/errs"
+)
+
+// MountInfo is the mount a path lives on, from /proc/self/mountinfo
+type MountInfo struct {
+	MountPoint string
+	FSType     string
+	Source     string
+	Options    []string // mount and superblock options, like noexec or fmask=0133
+}
+
+// nonPOSIXFilesystems can't store Linux permissions or symlinks. fuseblk is how ntfs-3g and exfat-fuse show up.
+var nonPOSIXFilesystems = []string{"vfat", "msdos", "exfat", "ntfs", "ntfs3", "fuseblk"}
+
+// HasOption reports whether the mount has an option, like noexec
+func (m MountInfo) HasOption(option string) bool {
+	return slices.Contains(m.Options, option)
+}
+
+// NonPOSIX reports whether the filesystem can't store Linux permissions and symlinks, like NTFS or exFAT
+func (m MountInfo) NonPOSIX() bool {
+	return slices.Contains(nonPOSIXFilesystems, m.FSType)
+}
+
+func (m MountInfo) String() string {
+	return fmt.Sprintf("%s on %s (%s, %s)", m.Source, m.MountPoint, m.FSType, strings.Join(m.Options, ","))
+}
+
+// MountInfoFor returns the mount a path lives on
+func MountInfoFor(path string) (MountInfo, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return MountInfo{}, err
+	}
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+		abs = resolved
+	}
+
+	file, err := os.Open("/proc/self/mountinfo")
+	if err != nil {
+		return MountInfo{}, err
+	}
+	defer file.Close()
+
+	// The last matching mount wins, it is mounted over the earlier ones
+	var found MountInfo
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		mount, ok := parseMountInfoLine(scanner.Text())
+		if !ok {
+			continue
+		}
+		rel, err := filepath.Rel(mount.MountPoint, abs)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, "../") {
+			continue
+		}
+		if len(mount.MountPoint) >= len(found.MountPoint) {
+			found = mount
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return MountInfo{}, err
+	}
+	if found.MountPoint == "" {
+		return MountInfo{}, fmt.Errorf("no mount found for %s", abs)
+	}
+	return found, nil
+}
+
+// parseMountInfoLine parses a line of /proc/self/mountinfo:
+//
+//	36 35 98:0 /mnt1 /mnt/parent rw,noatime master:1 - ext3 /dev/root rw,errors=continue
+func parseMountInfoLine(line string) (MountInfo, bool) {
+	fields := strings.Fields(line)
+	separator := slices.Index(fields, "-")
+	if separator < 6 || separator+3 > len(fields) {
+		return MountInfo{}, false
+	}
+	mount := MountInfo{
+		MountPoint: unescapeMountPath(fields[4]),
+		FSType:     fields[separator+1],
+		Source:     unescapeMountPath(fields[separator+2]),
+		Options:    strings.Split(fields[5], ","),
+	}
+	if separator+3 < len(fields) {
+		mount.Options = append(mount.Options, strings.Split(fields[separator+3], ",")...)
+	}
+	return mount, true
+}
+
+// unescapeMountPath decodes the octal escapes mountinfo uses for spaces, tabs, newlines and backslashes
+func unescapeMountPath(path string) string {
+	for _, escape := range []struct{ from, to string }{{`\040`, " "}, {`\011`, "\t"}, {`\012`, "\n"}, {`\134`, `\`}} {
+		path = strings.ReplaceAll(path, escape.from, escape.to)
+	}
+	return path
+}
+
+// PiAppsDirMountProblem checks the mount of the Pi-Apps directory.
+//
+//	MountInfo - the mount, empty if it couldn't be read
+//	string - translated explanation with how to fix it, "" if there is no problem
+//	bool - true if scripts can't run at all, so installs have to be refused
+func PiAppsDirMountProblem() (MountInfo, string, bool) {
+	dir := GetPiAppsDir()
+	mount, err := MountInfoFor(dir)
+	if err != nil {
+		Debug(fmt.Sprintf("Failed to read the mount of %s: %v", dir, err))
+		return mount, "", false
+	}
+
+	if mount.HasOption("noexec") {
+		return mount, Tf("The Pi-Apps folder %s is on %s, which is mounted with noexec, so app scripts can't run.", dir, mount.MountPoint) + "\n" +
+			Tf("Remount it with exec: sudo mount -o remount,exec %s, and remove noexec from its line in /etc/fstab to keep it that way.", ShellQuote(mount.MountPoint)) + "\n" +
+			Tf("Or move Pi-Apps to your home folder: mv %s ~/pi-apps", ShellQuote(dir)), true
+	}
+
+	if mount.NonPOSIX() {
+		// Some of these mounts mark every file executable through fmask, that works until a script needs a symlink
+		blocking := syscall.Access(filepath.Join(dir, "api"), 1) != nil // X_OK
+		problem := Tf("The Pi-Apps folder %s is on a %s filesystem, which can't store Linux permissions and symlinks, so app scripts may fail with \"Permission denied\".", dir, mount.FSType) + "\n" +
+			T("Move Pi-Apps to a Linux filesystem like ext4, for example your home folder:") + " " + Tf("mv %s ~/pi-apps", ShellQuote(dir))
+		return mount, problem, blocking
+	}
+
+	if mount.HasOption("nosuid") {
+		return mount, Tf("The Pi-Apps folder %s is on %s, which is mounted with nosuid. Apps that install setuid helpers inside the Pi-Apps folder won't work.", dir, mount.MountPoint), false
+	}
+	return mount, "", false
+}
+
+// CheckPiAppsDirExec returns an errs.ErrNoExec error when app scripts can't run from the Pi-Apps directory
+func CheckPiAppsDirExec() error {
+	_, problem, blocking := PiAppsDirMountProblem()
+	if !blocking {
+		return nil
+	}
+	return errs.New(errs.ErrNoExec, "%s", problem)
+}
+
+// warnPiAppsDirMount warns at startup when the Pi-Apps directory can't run app scripts properly.
+// App scripts call the api for each of their commands, so the warning is left out there.
+func warnPiAppsDirMount() {
+	if os.Getenv("app") != "" {
+		return
+	}
+	if _, problem, _ := PiAppsDirMountProblem(); problem != "" {
+		Warning(problem)
+	}
+}
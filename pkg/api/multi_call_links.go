@@ -0,0 +1,232 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: multi_call_links.go
+// Description: Creates, checks and repairs the symlinks the multi-call binary runs as the Pi-Apps programs through, and the menu entries starting them.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package api
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// MultiCallBinary is the file name of the multi-call binary
+const MultiCallBinary = "multi-call-pi-apps"
+
+// MultiCallLinks are the names of the symlinks to the multi-call binary, one for every program it runs as. The api
+// program is the bash API, which starts api-go.
+var MultiCallLinks = []string{"api-go", "gui", "manage", "settings", "updater"}
+
+// LinkProblem is what is wrong with a symlink to the multi-call binary
+type LinkProblem string
+
+const (
+	LinkMissing     LinkProblem = "missing"
+	LinkBroken      LinkProblem = "broken"        // points to a file that doesn't exist
+	LinkWrongTarget LinkProblem = "wrong target"  // points to another file, like the binary of another install
+	LinkNotSymlink  LinkProblem = "not a symlink" // a file of its own, like a program of a build without multi-call
+)
+
+// MultiCallLinkIssue is a symlink to the multi-call binary that is missing or wrong, see VerifyMultiCallLinks
+type MultiCallLinkIssue struct {
+	Path    string      `json:"path"`
+	Problem LinkProblem `json:"problem"`
+	Target  string      `json:"target,omitempty"` // where the link points instead
+}
+
+func (i MultiCallLinkIssue) String() string {
+	if i.Target != "" {
+		return fmt.Sprintf("%s: %s (%s)", i.Path, i.Problem, i.Target)
+	}
+	return fmt.Sprintf("%s: %s", i.Path, i.Problem)
+}
+
+// resolveMultiCallBinary returns the real path of the multi-call binary in dir
+func resolveMultiCallBinary(dir string) (string, error) {
+	binary, err := filepath.EvalSymlinks(filepath.Join(dir, MultiCallBinary))
+	if err != nil {
+		return "", fmt.Errorf("no multi-call binary in %s: %w", dir, err)
+	}
+	info, err := os.Stat(binary)
+	if err != nil {
+		return "", err
+	}
+	if !info.Mode().IsRegular() || info.Mode().Perm()&0111 == 0 {
+		return "", fmt.Errorf("%s is not an executable file", binary)
+	}
+	return binary, nil
+}
+
+// VerifyMultiCallLinks checks that the symlinks named after the Pi-Apps programs in dir point to the multi-call
+// binary in dir. Links with another target work as long as they end up at the binary.
+//
+//	[]MultiCallLinkIssue - the missing and wrong links, nil if all of them are right
+//	error - error if dir has no multi-call binary
+func VerifyMultiCallLinks(dir string) ([]MultiCallLinkIssue, error) {
+	binary, err := resolveMultiCallBinary(dir)
+	if err != nil {
+		return nil, err
+	}
+	var issues []MultiCallLinkIssue
+	for _, name := range MultiCallLinks {
+		if issue, ok := checkMultiCallLink(filepath.Join(dir, name), binary); !ok {
+			issues = append(issues, issue)
+		}
+	}
+	return issues, nil
+}
+
+// checkMultiCallLink checks a symlink to the multi-call binary, binary being its real path
+func checkMultiCallLink(path, binary string) (MultiCallLinkIssue, bool) {
+	info, err := os.Lstat(path)
+	if os.IsNotExist(err) {
+		return MultiCallLinkIssue{Path: path, Problem: LinkMissing}, false
+	} else if err != nil {
+		return MultiCallLinkIssue{Path: path, Problem: LinkBroken, Target: err.Error()}, false
+	}
+	if info.Mode()&os.ModeSymlink == 0 {
+		return MultiCallLinkIssue{Path: path, Problem: LinkNotSymlink}, false
+	}
+	target, err := os.Readlink(path)
+	if err != nil {
+		return MultiCallLinkIssue{Path: path, Problem: LinkBroken, Target: err.Error()}, false
+	}
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return MultiCallLinkIssue{Path: path, Problem: LinkBroken, Target: target}, false
+	}
+	if resolved != binary {
+		return MultiCallLinkIssue{Path: path, Problem: LinkWrongTarget, Target: target}, false
+	}
+	return MultiCallLinkIssue{}, true
+}
+
+// InstallMultiCallLinks creates the symlinks to the multi-call binary in dir and repairs the wrong ones, so running
+// it again changes nothing. A link is replaced atomically, a program starting meanwhile finds the old or new one.
+// Programs of a build without multi-call with the same names are replaced too, directories are left alone.
+//
+//	[]string - the links that were created or replaced
+//	error - error if dir has no multi-call binary or a link can't be written
+func InstallMultiCallLinks(dir string) ([]string, error) {
+	issues, err := VerifyMultiCallLinks(dir)
+	if err != nil {
+		return nil, err
+	}
+	var installed []string
+	var errs []error
+	for _, issue := range issues {
+		if info, err := os.Lstat(issue.Path); err == nil && info.IsDir() {
+			errs = append(errs, fmt.Errorf("%s is a directory, not replacing it with a link to %s", issue.Path, MultiCallBinary))
+			continue
+		}
+		tmp := filepath.Join(dir, "."+filepath.Base(issue.Path)+".new-"+strconv.Itoa(os.Getpid()))
+		os.Remove(tmp)
+		// Relative, so the folder can be moved with its links
+		if err := os.Symlink(MultiCallBinary, tmp); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := os.Rename(tmp, issue.Path); err != nil {
+			os.Remove(tmp)
+			errs = append(errs, err)
+			continue
+		}
+		installed = append(installed, issue.Path)
+	}
+	return installed, errors.Join(errs...)
+}
+
+// piAppsDesktopFiles returns the menu, desktop and autostart entries Pi-Apps created for itself
+func piAppsDesktopFiles() []string {
+	home := os.Getenv("HOME")
+	var files []string
+	for _, dir := range []string{userApplicationsDir(), filepath.Join(home, "Desktop"), filepath.Join(home, ".config", "autostart")} {
+		matches, _ := filepath.Glob(filepath.Join(dir, "pi-apps*.desktop"))
+		files = append(files, matches...)
+	}
+	return files
+}
+
+// UpdateMultiCallDesktopEntries points the Exec lines of the menu, desktop and autostart entries of Pi-Apps at the
+// links in dir: an entry starting gui from another folder, or the multi-call binary with gui as its mode, gets
+// dir/gui. Entries that already start the links in dir are left alone.
+//
+//	[]string - the entries that were changed
+//	error - error if an entry can't be read or written
+func UpdateMultiCallDesktopEntries(dir string) ([]string, error) {
+	var updated []string
+	var errs []error
+	for _, path := range piAppsDesktopFiles() {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		lines := strings.Split(string(content), "\n")
+		changed := false
+		for i, line := range lines {
+			value, ok := strings.CutPrefix(line, "Exec=")
+			if !ok {
+				continue
+			}
+			if exec, ok := multiCallExecLine(dir, value); ok {
+				lines[i] = "Exec=" + exec
+				changed = true
+			}
+		}
+		if !changed {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err == nil {
+			err = WriteFileAtomic(path, []byte(strings.Join(lines, "\n")), info.Mode().Perm())
+		}
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		updated = append(updated, path)
+	}
+	return updated, errors.Join(errs...)
+}
+
+// multiCallExecLine returns the Exec value starting a program of the multi-call binary through its link in dir, and
+// false if value starts another program or the link in dir already
+func multiCallExecLine(dir, value string) (string, bool) {
+	args, err := parseExecLine(unescapeDesktopString(value))
+	if err != nil || len(args) == 0 {
+		return "", false
+	}
+	name := filepath.Base(args[0])
+	rest := args[1:]
+	if name == MultiCallBinary && len(rest) > 0 && slices.Contains(MultiCallLinks, rest[0]) {
+		name, rest = rest[0], rest[1:]
+	} else if !slices.Contains(MultiCallLinks, name) {
+		return "", false
+	}
+	link := filepath.Join(dir, name)
+	if args[0] == link {
+		return "", false
+	}
+	return escapeDesktopString(execLine(append([]string{link}, rest...))), true
+}
@@ -0,0 +1,177 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// newMultiCallBinDir returns a folder with a multi-call binary and no links
+func newMultiCallBinDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, MultiCallBinary), []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestInstallMultiCallLinks(t *testing.T) {
+	dir := newMultiCallBinDir(t)
+	issues, err := VerifyMultiCallLinks(dir)
+	if err != nil || len(issues) != len(MultiCallLinks) {
+		t.Fatalf("VerifyMultiCallLinks without links = %v, %v, want every link missing", issues, err)
+	}
+	for _, issue := range issues {
+		if issue.Problem != LinkMissing {
+			t.Errorf("%s", issue)
+		}
+	}
+
+	installed, err := InstallMultiCallLinks(dir)
+	if err != nil || len(installed) != len(MultiCallLinks) {
+		t.Fatalf("InstallMultiCallLinks = %q, %v", installed, err)
+	}
+	for _, name := range MultiCallLinks {
+		if target, err := os.Readlink(filepath.Join(dir, name)); err != nil || target != MultiCallBinary {
+			t.Errorf("%s points to %q, %v, want %s", name, target, err, MultiCallBinary)
+		}
+	}
+	if issues, err := VerifyMultiCallLinks(dir); err != nil || len(issues) != 0 {
+		t.Errorf("VerifyMultiCallLinks after installing = %v, %v", issues, err)
+	}
+
+	// Installing again changes nothing
+	if installed, err := InstallMultiCallLinks(dir); err != nil || len(installed) != 0 {
+		t.Errorf("second InstallMultiCallLinks = %q, %v, want nothing to do", installed, err)
+	}
+}
+
+func TestRepairMultiCallLinks(t *testing.T) {
+	dir := newMultiCallBinDir(t)
+	if _, err := InstallMultiCallLinks(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	// An absolute link to the binary is right, the other ones are broken in their own way
+	other := newMultiCallBinDir(t)
+	for name, target := range map[string]string{
+		"api-go":   filepath.Join(dir, MultiCallBinary),
+		"gui":      filepath.Join(other, MultiCallBinary),
+		"settings": "multi-call-pi-apps.old",
+	} {
+		os.Remove(filepath.Join(dir, name))
+		if err := os.Symlink(target, filepath.Join(dir, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	os.Remove(filepath.Join(dir, "manage"))
+	os.Remove(filepath.Join(dir, "updater"))
+	writeTestFile(t, filepath.Join(dir, "updater"), "separate updater binary")
+
+	issues, err := VerifyMultiCallLinks(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []MultiCallLinkIssue{
+		{Path: filepath.Join(dir, "gui"), Problem: LinkWrongTarget, Target: filepath.Join(other, MultiCallBinary)},
+		{Path: filepath.Join(dir, "manage"), Problem: LinkMissing},
+		{Path: filepath.Join(dir, "settings"), Problem: LinkBroken, Target: "multi-call-pi-apps.old"},
+		{Path: filepath.Join(dir, "updater"), Problem: LinkNotSymlink},
+	}
+	if !slices.Equal(issues, want) {
+		t.Errorf("VerifyMultiCallLinks = %v, want %v", issues, want)
+	}
+
+	installed, err := InstallMultiCallLinks(dir)
+	if err != nil || len(installed) != len(want) {
+		t.Errorf("InstallMultiCallLinks repaired %q, %v, want %d links", installed, err, len(want))
+	}
+	if issues, err := VerifyMultiCallLinks(dir); err != nil || len(issues) != 0 {
+		t.Errorf("VerifyMultiCallLinks after the repair = %v, %v", issues, err)
+	}
+	if target, _ := os.Readlink(filepath.Join(dir, "api-go")); target != filepath.Join(dir, MultiCallBinary) {
+		t.Errorf("a right absolute link was replaced with %q", target)
+	}
+	if leftovers, _ := filepath.Glob(filepath.Join(dir, ".*")); len(leftovers) != 0 {
+		t.Errorf("temporary links left behind: %q", leftovers)
+	}
+}
+
+func TestMultiCallLinksNeedTheBinary(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := VerifyMultiCallLinks(dir); err == nil {
+		t.Error("VerifyMultiCallLinks of a folder without the binary succeeded")
+	}
+	if _, err := InstallMultiCallLinks(dir); err == nil {
+		t.Error("InstallMultiCallLinks linked to a binary that doesn't exist")
+	}
+	if _, err := os.Lstat(filepath.Join(dir, "gui")); !os.IsNotExist(err) {
+		t.Errorf("a link was created without the binary: %v", err)
+	}
+
+	dir = newMultiCallBinDir(t)
+	if err := os.Mkdir(filepath.Join(dir, "gui"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := InstallMultiCallLinks(dir); err == nil {
+		t.Error("InstallMultiCallLinks replaced a directory")
+	}
+	if info, err := os.Lstat(filepath.Join(dir, "gui")); err != nil || !info.IsDir() {
+		t.Errorf("the gui directory is gone: %v", err)
+	}
+}
+
+func TestUpdateMultiCallDesktopEntries(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	dir := filepath.Join(home, "pi-apps go")
+	menu := filepath.Join(home, ".local", "share", "applications", "pi-apps-go.desktop")
+	autostart := filepath.Join(home, ".config", "autostart", "pi-apps-go-updater.desktop")
+	settings := filepath.Join(home, ".local", "share", "applications", "pi-apps-go-settings.desktop")
+	other := filepath.Join(home, ".local", "share", "applications", "pi-apps-go-docs.desktop")
+	writeTestFile(t, menu, "[Desktop Entry]\nName=Pi-Apps Go\nExec=/opt/old/gui\nTerminal=false\n")
+	writeTestFile(t, autostart, "[Desktop Entry]\nExec=/opt/old/multi-call-pi-apps updater onboot\n")
+	writeTestFile(t, settings, "[Desktop Entry]\nExec=\""+filepath.Join(dir, "settings")+"\"\n")
+	writeTestFile(t, other, "[Desktop Entry]\nExec=xdg-open https://pi-apps.io\n")
+
+	updated, err := UpdateMultiCallDesktopEntries(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	slices.Sort(updated)
+	if want := []string{autostart, menu}; !slices.Equal(updated, want) {
+		t.Errorf("updated entries = %q, want %q", updated, want)
+	}
+	wantContent := map[string]string{
+		menu:      "[Desktop Entry]\nName=Pi-Apps Go\nExec=\"" + filepath.Join(dir, "gui") + "\"\nTerminal=false\n",
+		autostart: "[Desktop Entry]\nExec=\"" + filepath.Join(dir, "updater") + "\" onboot\n",
+		other:     "[Desktop Entry]\nExec=xdg-open https://pi-apps.io\n",
+	}
+	for path, want := range wantContent {
+		if data, _ := os.ReadFile(path); string(data) != want {
+			t.Errorf("%s = %q, want %q", filepath.Base(path), data, want)
+		}
+	}
+
+	if updated, err := UpdateMultiCallDesktopEntries(dir); err != nil || len(updated) != 0 {
+		t.Errorf("second UpdateMultiCallDesktopEntries = %q, %v, want nothing to do", updated, err)
+	}
+}
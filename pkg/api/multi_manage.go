@@ -18,6 +18,8 @@
 // Description: Provides functions for multiple concurent app installations.
 // SPDX-License-Identifier: GPL-3.0-or-later
 
+//go:build !nogui
+
 package api
 
 import (
@@ -252,7 +254,7 @@ func MultiInstallGUI() error {
 
 		// Get first line of description for tooltip
 		description := ""
-		descriptionBytes, err := os.ReadFile(filepath.Join(piAppsDir, "apps", app, "description"))
+		descriptionBytes, err := os.ReadFile(LocalizedDescriptionPath(filepath.Join(piAppsDir, "apps", app)))
 		if err == nil && len(descriptionBytes) > 0 {
 			descLines := strings.Split(string(descriptionBytes), "\n")
 			if len(descLines) > 0 {
@@ -594,7 +596,7 @@ func MultiUninstallGUI() error {
 
 		// Get first line of description for tooltip
 		description := ""
-		descriptionBytes, err := os.ReadFile(filepath.Join(piAppsDir, "apps", app, "description"))
+		descriptionBytes, err := os.ReadFile(LocalizedDescriptionPath(filepath.Join(piAppsDir, "apps", app)))
 		if err == nil && len(descriptionBytes) > 0 {
 			descLines := strings.Split(string(descriptionBytes), "\n")
 			if len(descLines) > 0 {
@@ -0,0 +1,234 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: nogui.go
+// Description: Provides terminal replacements for the GTK interfaces when built with the nogui tag, for headless servers.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+//go:build nogui
+
+package api
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// GUISupported reports whether this build includes the GTK interfaces
+const GUISupported = false
+
+// gtkUserInput is never reached without GUI support, UserInputFunc uses the terminal instead
+func gtkUserInput(text string, options ...string) (string, error) {
+	return "", ErrNoGUI
+}
+
+// gtkUserMultiSelect is never reached without GUI support, UserMultiSelect uses the terminal instead
+func gtkUserMultiSelect(text string, options []string, selected []bool) ([]bool, error) {
+	return nil, ErrNoGUI
+}
+
+// gtkPromptSecret is never reached without GUI support, PromptSecret reads from the terminal instead
+func gtkPromptSecret(prompt string) (string, error) {
+	return "", ErrNoGUI
+}
+
+// ViewFile shows a text file in a pager, or prints it when the output is not a terminal.
+// Binary files are shown as a hex dump of their start.
+func ViewFile(filePath string) error {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return fmt.Errorf("unable to open file: %w", err)
+	}
+	defer file.Close()
+
+	// Binary files would fill the terminal with garbage, show the start of them as a hex dump instead
+	if binary, err := IsBinaryFile(filePath); err == nil && binary {
+		preview, err := binaryPreview(filePath)
+		if err != nil {
+			return err
+		}
+		WarningTf("%s looks like a binary file, not text. Its first %d bytes are:", filePath, binaryPreviewSize)
+		fmt.Print(preview)
+		return nil
+	}
+
+	if term.IsTerminal(int(os.Stdout.Fd())) {
+		if pager, err := exec.LookPath("less"); err == nil {
+			cmd := exec.Command(pager, "-R")
+			cmd.Stdin = file
+			cmd.Stdout = os.Stdout
+			cmd.Stderr = os.Stderr
+			return cmd.Run()
+		}
+	}
+
+	_, err = io.Copy(os.Stdout, file)
+	return err
+}
+
+// readTerminalLine asks a question on stderr and reads the answer from stdin
+func readTerminalLine(prompt string) string {
+	fmt.Fprint(os.Stderr, prompt)
+	line, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	return strings.TrimSpace(line)
+}
+
+// AppSearchGUI asks for a search query in the terminal and lets the user pick one of the matching apps
+//
+//	"" - no app was found or chosen
+//	app - the chosen app
+func AppSearchGUI() (string, error) {
+	query := readTerminalLine(T("Search for apps (not case-sensitive): "))
+	if query == "" {
+		return "", nil
+	}
+
+	results, err := AppSearch(query)
+	if err != nil {
+		return "", err
+	}
+	if len(results) == 0 {
+		fmt.Fprintln(os.Stderr, Tf("No results found for \"%s\".", query))
+		return "", nil
+	}
+	return cliUserInput(T("Choose an app:"), results...)
+}
+
+// DialogError prints an error message, as there is no GUI to show a dialog in
+func DialogError(message string) {
+	ErrorNoExit(message)
+}
+
+// ShowCategoryEditor is not available without GUI support
+func ShowCategoryEditor() error {
+	return fmt.Errorf("%w: use 'api categoryedit <app> <category>' to change the category of an app", ErrNoGUI)
+}
+
+// ShowLogViewer lists the installation logs in the terminal, newest first
+func ShowLogViewer() error {
+	// Clean up old log files first
+	if err := CleanupOldLogFiles(); err != nil {
+		Warning("Failed to clean up old log files: " + err.Error())
+	}
+
+	logEntries, err := GetLogFiles()
+	if err != nil {
+		return fmt.Errorf("failed to get log files: %w", err)
+	}
+	if len(logEntries) == 0 {
+		StatusT("No log files found.")
+		return nil
+	}
+
+	for _, entry := range logEntries {
+		fmt.Printf("%s\t%s\t%s\n", entry.Date, entry.Caption, entry.Filepath)
+	}
+	return nil
+}
+
+// ImportAppGUI asks for an import source in the terminal and imports the apps from it
+func ImportAppGUI() error {
+	if err := CheckKioskFeature(KioskImportApp); err != nil {
+		return err
+	}
+
+	piAppsDir := GetPiAppsDir()
+	if piAppsDir == "" {
+		return fmt.Errorf("PI_APPS_DIR environment variable not set")
+	}
+
+	source := readTerminalLine(T("Enter a zip file, folder, zip URL or pull request URL to import apps from: "))
+	if source == "" {
+		return fmt.Errorf("no import source entered")
+	}
+
+	importedApps, err := handleImport(source, piAppsDir)
+	if err != nil {
+		return fmt.Errorf("error importing app: %w", err)
+	}
+	if len(importedApps) == 0 {
+		return fmt.Errorf("no apps were imported")
+	}
+
+	for _, app := range importedApps {
+		StatusGreenT("Imported %s", app)
+	}
+	return nil
+}
+
+// DiagnoseApps explains each failed action in the terminal and asks what to do about it
+// failureList format: "action;app" entries separated by newlines
+func DiagnoseApps(failureList string) []DiagnoseResult {
+	diagnoses, err := diagnoseFailures(failureList, true)
+	if err != nil {
+		Warning(err.Error())
+	}
+
+	var results []DiagnoseResult
+	for i, diagnosis := range diagnoses {
+		printDiagnosis(diagnosis, i+1, len(diagnoses))
+
+		sendOption := T("Send report")
+		retryOption := T("Retry")
+		nextOption := T("Next")
+		if i == len(diagnoses)-1 {
+			nextOption = T("Close")
+		}
+		// The first option is the default for invalid input, so keep it harmless
+		options := []string{nextOption, retryOption}
+		if diagnosis.CanReport {
+			options = append(options, sendOption)
+		}
+
+		choice, _ := cliUserInput(T("What do you want to do?"), options...)
+		result := DiagnoseResult{AppName: diagnosis.App, ActionStr: diagnosis.ActionStr()}
+		switch choice {
+		case sendOption:
+			result.Action = "send"
+		case retryOption:
+			result.Action = "retry"
+		default:
+			result.Action = "next"
+		}
+		results = append(results, result)
+	}
+
+	return results
+}
+
+// CreateApp is not available without GUI support
+func CreateApp(appName string) error {
+	if err := CheckKioskFeature(KioskCreateApp); err != nil {
+		return err
+	}
+	return fmt.Errorf("%w: create the app folder in %s/apps by hand instead", ErrNoGUI, GetPiAppsDir())
+}
+
+// MultiInstallGUI is not available without GUI support
+func MultiInstallGUI() error {
+	return fmt.Errorf("%w: use 'manage -install <app> <app>...' instead", ErrNoGUI)
+}
+
+// MultiUninstallGUI is not available without GUI support
+func MultiUninstallGUI() error {
+	return fmt.Errorf("%w: use 'manage -uninstall <app> <app>...' instead", ErrNoGUI)
+}
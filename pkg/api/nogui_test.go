@@ -0,0 +1,101 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+//go:build nogui
+
+package api
+
+import (
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNoGUIStubs(t *testing.T) {
+	newTestPiAppsDir(t)
+	if GUISupported {
+		t.Fatal("GUISupported is true in a nogui build")
+	}
+
+	stubs := map[string]func() error{
+		"ShowCategoryEditor": ShowCategoryEditor,
+		"CreateApp":          func() error { return CreateApp("Zoom") },
+		"MultiInstallGUI":    MultiInstallGUI,
+		"MultiUninstallGUI":  MultiUninstallGUI,
+	}
+	for name, stub := range stubs {
+		if err := stub(); !errors.Is(err, ErrNoGUI) {
+			t.Errorf("%s() = %v, want ErrNoGUI", name, err)
+		}
+	}
+}
+
+func TestNoGUIViewFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log")
+	writeTestFile(t, path, "line 1\nline 2\n")
+
+	// Without a terminal the file is printed instead of opened in a pager
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	err = ViewFile(path)
+	os.Stdout = stdout
+	w.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	output, _ := io.ReadAll(r)
+	if string(output) != "line 1\nline 2\n" {
+		t.Errorf("ViewFile printed %q", output)
+	}
+}
+
+// TestBuildVariants checks that the headless binaries don't depend on GTK while the GUI ones still do,
+// the same variants "make check-variants" builds
+func TestBuildVariants(t *testing.T) {
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go is not installed")
+	}
+	if testing.Short() {
+		t.Skip("lists the dependencies of every binary")
+	}
+
+	for _, pkg := range []string{"../../cmd/api", "../../cmd/manage", "../../cmd/multi-call-pi-apps"} {
+		for _, variant := range []struct {
+			tags    string
+			wantGTK bool
+		}{
+			{"apt nogui", false},
+			{"apt", true},
+		} {
+			output, err := exec.Command(goBin, "list", "-deps", "-tags", variant.tags, pkg).Output()
+			if err != nil {
+				t.Fatalf("go list -tags %q %s: %v", variant.tags, pkg, err)
+			}
+			if hasGTK := strings.Contains(string(output), "github.com/gotk3/gotk3"); hasGTK != variant.wantGTK {
+				t.Errorf("%s built with %q depends on GTK: %v, want %v", pkg, variant.tags, hasGTK, variant.wantGTK)
+			}
+		}
+	}
+}
@@ -41,7 +41,7 @@ func OpenFile(filePath string) error {
 	}
 
 	// Read the preferred text editor setting
-	settingsFile := filepath.Join(piAppsDir, "data", "settings", "Preferred text editor")
+	settingsFile := filepath.Join(DataDirOf(piAppsDir), "settings", "Preferred text editor")
 	preferredEditor := ""
 
 	if data, err := os.ReadFile(settingsFile); err == nil {
@@ -106,7 +106,7 @@ func openWithNano(filePath string) error {
 	// Spawn nano in a separate goroutine to allow multiple sessions
 	go func() {
 		// Quote the file path to handle spaces properly
-		TerminalRun(fmt.Sprintf("nano %s", filePath), "Editing "+filepath.Base(filePath))
+		TerminalRun("nano "+ShellQuote(filePath), "Editing "+filepath.Base(filePath))
 	}()
 
 	return nil
@@ -0,0 +1,314 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: os_upgrade.go
+// Description: Provides functions for detecting OS release upgrades (e.g. Bullseye to Bookworm) and refreshing the apps they break.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package api
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// aptSourcesDir is the directory external repositories are added to, a variable so tests can use a fixture
+var aptSourcesDir = "/etc/apt/sources.list.d"
+
+// OSUpgradeReport describes the installed apps affected by an OS release upgrade
+type OSUpgradeReport struct {
+	OldCodename string
+	NewCodename string
+
+	// NeedsReinstall lists apps whose scripts reference the codename of the release they were installed on,
+	// or that added repos for it
+	NeedsReinstall []string
+	// ProbablyFine lists apps that show no sign of depending on the old release
+	ProbablyFine []string
+	// Unsupported lists apps that can no longer be installed on the new release
+	Unsupported []string
+	// StaleRepos lists repo files that still point at the old codename or at the release an app was installed on
+	StaleRepos []string
+}
+
+// installInfoFile returns the path of the file recording how an app was installed
+func installInfoFile(app string) string {
+	return filepath.Join(GetDataDir(), "install-info", app)
+}
+
+// ReadInstallInfo returns a value recorded in an app's install info file, or "" if it was never recorded
+func ReadInstallInfo(app, key string) string {
+	data, err := os.ReadFile(installInfoFile(app))
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if value, found := strings.CutPrefix(line, key+"="); found {
+			return value
+		}
+	}
+	return ""
+}
+
+// WriteInstallInfo records a value in an app's install info file, replacing any previous value of the key
+func WriteInstallInfo(app, key, value string) error {
+	file := installInfoFile(app)
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return fmt.Errorf("failed to create install info directory: %w", err)
+	}
+
+	var lines []string
+	if data, err := os.ReadFile(file); err == nil {
+		for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+			if line != "" && !strings.HasPrefix(line, key+"=") {
+				lines = append(lines, line)
+			}
+		}
+	}
+	lines = append(lines, key+"="+value)
+
+	return os.WriteFile(file, []byte(strings.Join(lines, "\n")+"\n"), 0644)
+}
+
+// recordInstallCodename records the OS codename an app was installed on
+func recordInstallCodename(app string) {
+	codename := LoadLSBOSRelease().VERSION_CODENAME
+	if codename == "" {
+		return
+	}
+	if err := WriteInstallInfo(app, "os_codename", codename); err != nil {
+		Debug(fmt.Sprintf("failed to record the OS codename of %s: %v", app, err))
+	}
+}
+
+// lastOSCodenameFile returns the path of the file recording the OS codename Pi-Apps last ran on
+func lastOSCodenameFile() string {
+	return filepath.Join(GetDataDir(), "last-os-codename")
+}
+
+// AcknowledgeOSUpgrade records the current OS codename so DetectOSUpgrade stops reporting the upgrade
+func AcknowledgeOSUpgrade() error {
+	codename := LoadLSBOSRelease().VERSION_CODENAME
+	if codename == "" {
+		return nil
+	}
+	file := lastOSCodenameFile()
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return fmt.Errorf("failed to create data directory: %w", err)
+	}
+	return os.WriteFile(file, []byte(codename+"\n"), 0644)
+}
+
+// DetectOSUpgrade checks whether the OS release changed since Pi-Apps last ran and which installed apps are affected
+//
+// On the first run the current codename is only recorded.
+//
+//	*OSUpgradeReport - report of the affected apps, nil if the release did not change
+//	error - error if PI_APPS_DIR environment variable is not set or the apps cannot be listed
+func DetectOSUpgrade() (*OSUpgradeReport, error) {
+	directory := GetPiAppsDir()
+	if directory == "" {
+		return nil, fmt.Errorf("PI_APPS_DIR environment variable not set")
+	}
+
+	newCodename := LoadLSBOSRelease().VERSION_CODENAME
+	if newCodename == "" {
+		// Rolling releases and most non-Debian distributions have no codename to compare
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(lastOSCodenameFile())
+	if os.IsNotExist(err) {
+		return nil, AcknowledgeOSUpgrade()
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read last OS codename: %w", err)
+	}
+	oldCodename := strings.TrimSpace(string(data))
+	if oldCodename == "" || oldCodename == newCodename {
+		return nil, nil
+	}
+
+	report := &OSUpgradeReport{
+		OldCodename: oldCodename,
+		NewCodename: newCodename,
+	}
+
+	installedApps, err := ListApps("installed")
+	if err != nil {
+		return nil, fmt.Errorf("error listing installed apps: %w", err)
+	}
+	installableApps, err := ListApps("cpu_installable")
+	if err != nil {
+		return nil, fmt.Errorf("error listing installable apps: %w", err)
+	}
+	installable := make(map[string]bool, len(installableApps))
+	for _, app := range installableApps {
+		installable[app] = true
+	}
+
+	// The repos of every release the apps were installed on, looked up once per release
+	staleRepos := make(map[string][]string)
+	reposOf := func(codename string) []string {
+		if _, found := staleRepos[codename]; !found {
+			staleRepos[codename] = reposForCodename(codename)
+			report.StaleRepos = append(report.StaleRepos, staleRepos[codename]...)
+		}
+		return staleRepos[codename]
+	}
+	reposOf(oldCodename)
+
+	for _, app := range installedApps {
+		appDir := filepath.Join(directory, "apps", app)
+		// Apps installed before the codename was recorded were installed on the release Pi-Apps last ran on
+		installedOn := ReadInstallInfo(app, "os_codename")
+		if installedOn == "" {
+			installedOn = oldCodename
+		}
+		switch {
+		case !installable[app]:
+			report.Unsupported = append(report.Unsupported, app)
+		case installedOn == newCodename:
+			// Installed or reinstalled on the new release already
+			report.ProbablyFine = append(report.ProbablyFine, app)
+		case FileExists(filepath.Join(appDir, "packages")):
+			// Package-apps follow the distribution, unless their packages are gone
+			if packages, err := PkgAppPackagesRequired(app); err == nil && packages == "" {
+				report.Unsupported = append(report.Unsupported, app)
+			} else {
+				report.ProbablyFine = append(report.ProbablyFine, app)
+			}
+		case appReferencesRelease(appDir, installedOn, reposOf(installedOn)):
+			report.NeedsReinstall = append(report.NeedsReinstall, app)
+		default:
+			report.ProbablyFine = append(report.ProbablyFine, app)
+		}
+	}
+
+	sort.Strings(report.NeedsReinstall)
+	sort.Strings(report.ProbablyFine)
+	sort.Strings(report.Unsupported)
+	sort.Strings(report.StaleRepos)
+	return report, nil
+}
+
+// appReferencesRelease reports whether an app's scripts mention the codename of the release it was installed on
+// or add one of the repos that still point at it
+func appReferencesRelease(appDir, codename string, staleRepos []string) bool {
+	codenameRegex := regexp.MustCompile(`\b` + regexp.QuoteMeta(codename) + `\b`)
+	for _, script := range []string{"install", "install-32", "install-64", "uninstall"} {
+		data, err := os.ReadFile(filepath.Join(appDir, script))
+		if err != nil {
+			continue
+		}
+		content := string(data)
+		if codenameRegex.MatchString(content) {
+			return true
+		}
+		for _, repo := range staleRepos {
+			name := strings.TrimSuffix(strings.TrimSuffix(filepath.Base(repo), ".list"), ".sources")
+			if strings.Contains(content, name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// reposForCodename returns the repo files in sources.list.d that use the given codename as a suite
+func reposForCodename(codename string) []string {
+	files, _ := filepath.Glob(filepath.Join(aptSourcesDir, "*"))
+
+	var repos []string
+	for _, file := range files {
+		if filepath.Ext(file) != ".list" && filepath.Ext(file) != ".sources" {
+			continue
+		}
+		if repoUsesSuite(file, codename) {
+			repos = append(repos, file)
+		}
+	}
+	return repos
+}
+
+// repoUsesSuite reports whether a .list or .sources file has an entry for the given suite (or one of its pockets, like bullseye-updates)
+func repoUsesSuite(file, codename string) bool {
+	f, err := os.Open(file)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	matchesSuite := func(suite string) bool {
+		return suite == codename || strings.HasPrefix(suite, codename+"-") || strings.HasPrefix(suite, codename+"/")
+	}
+
+	aptOptionsRegex := regexp.MustCompile(`\[.*?\]`)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		// deb822 format: Suites: bullseye bullseye-updates
+		if suites, found := strings.CutPrefix(line, "Suites:"); found {
+			for _, suite := range strings.Fields(suites) {
+				if matchesSuite(suite) {
+					return true
+				}
+			}
+			continue
+		}
+
+		// one-line format: deb [options] uri suite components...
+		if strings.HasPrefix(line, "deb ") || strings.HasPrefix(line, "deb-src ") {
+			fields := strings.Fields(aptOptionsRegex.ReplaceAllString(line, ""))
+			if len(fields) >= 3 && matchesSuite(fields[2]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// RemoveStaleOSRepos removes the repos of a report that still point at the old codename, if nothing is installed from them
+func RemoveStaleOSRepos(report *OSUpgradeReport) error {
+	var failures []string
+	for _, repo := range report.StaleRepos {
+		if err := RemoveRepofileIfUnused(repo, "", ""); err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", repo, err))
+		}
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("failed to remove %d stale repos:\n%s", len(failures), strings.Join(failures, "\n"))
+	}
+	return nil
+}
+
+// OSUpgradeQueue returns the manage daemon queue that reinstalls the apps of a report: each app is uninstalled, then
+// installed again, since updating it would skip apps whose scripts did not change
+func OSUpgradeQueue(report *OSUpgradeReport) string {
+	var queue strings.Builder
+	for _, app := range report.NeedsReinstall {
+		queue.WriteString(RepairReinstall.queue(app) + "\n")
+	}
+	return strings.TrimSpace(queue.String())
+}
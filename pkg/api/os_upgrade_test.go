@@ -0,0 +1,86 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestDetectOSUpgradeComparesTheReleaseOfEachApp(t *testing.T) {
+	directory := newTestPiAppsDir(t, "Reinstalled", "Old", "Older", "Unrecorded", "Unrelated")
+	osRelease := filepath.Join(t.TempDir(), "os-release")
+	writeTestFile(t, osRelease, "ID=debian\nVERSION_CODENAME=bookworm\n")
+	t.Setenv("LSB_OS_RELEASE", osRelease)
+	originalSourcesDir := aptSourcesDir
+	aptSourcesDir = t.TempDir()
+	t.Cleanup(func() { aptSourcesDir = originalSourcesDir })
+	writeTestFile(t, filepath.Join(aptSourcesDir, "old-thing.list"), "deb https://example.com/debian buster main\n")
+
+	scripts := map[string]string{
+		"Reinstalled": "echo deb https://example.com bullseye main\n",
+		"Old":         "echo deb https://example.com bullseye main\n",
+		"Older":       "add_external_repo old-thing\n",
+		"Unrecorded":  "echo deb https://example.com bullseye main\n",
+		"Unrelated":   "echo hello\n",
+	}
+	for app, script := range scripts {
+		writeTestFile(t, filepath.Join(directory, "apps", app, "install"), "#!/bin/bash\n"+script)
+		writeTestFile(t, filepath.Join(GetDataDir(), "status", app), "installed")
+	}
+	for app, codename := range map[string]string{"Reinstalled": "bookworm", "Old": "bullseye", "Older": "buster", "Unrelated": "bullseye"} {
+		if err := WriteInstallInfo(app, "os_codename", codename); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeTestFile(t, filepath.Join(GetDataDir(), "last-os-codename"), "bullseye\n")
+
+	report, err := DetectOSUpgrade()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report == nil {
+		t.Fatal("DetectOSUpgrade found no upgrade from bullseye to bookworm")
+	}
+	if want := []string{"Old", "Older", "Unrecorded"}; !slices.Equal(report.NeedsReinstall, want) {
+		t.Errorf("NeedsReinstall = %q, want %q", report.NeedsReinstall, want)
+	}
+	if want := []string{"Reinstalled", "Unrelated"}; !slices.Equal(report.ProbablyFine, want) {
+		t.Errorf("ProbablyFine = %q, want %q", report.ProbablyFine, want)
+	}
+	if want := []string{filepath.Join(aptSourcesDir, "old-thing.list")}; !slices.Equal(report.StaleRepos, want) {
+		t.Errorf("StaleRepos = %q, want the buster repo Older added", report.StaleRepos)
+	}
+
+	want := "uninstall;Old\ninstall;Old\nuninstall;Older\ninstall;Older\nuninstall;Unrecorded\ninstall;Unrecorded"
+	if queue := OSUpgradeQueue(report); queue != want {
+		t.Errorf("OSUpgradeQueue = %q, want %q", queue, want)
+	}
+
+	// Once acknowledged, the upgrade is not reported again
+	if err := AcknowledgeOSUpgrade(); err != nil {
+		t.Fatal(err)
+	}
+	if report, err := DetectOSUpgrade(); err != nil || report != nil {
+		t.Errorf("DetectOSUpgrade after acknowledging it = %+v, %v, want nothing", report, err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(GetDataDir(), "last-os-codename")); string(data) != "bookworm\n" {
+		t.Errorf("last-os-codename = %q, want bookworm", data)
+	}
+}
@@ -0,0 +1,168 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: package_hold.go
+// Description: Keeps track of held packages, so package-apps the user pinned to a version are shown as held and not updated behind their back.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package api
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// StatusInstalledHeld is the status shown for an installed package-app whose package is held.
+// The status file still says installed, so everything that checks for installed apps keeps working.
+const StatusInstalledHeld = "installed (held)"
+
+// packageHoldsFile lists the packages held through PackageHold in data, one per line
+const packageHoldsFile = "package-holds"
+
+// packageHoldsMutex serializes changes to the package holds file
+var packageHoldsMutex sync.Mutex
+
+// knownPackageHolds returns the packages held through PackageHold
+func knownPackageHolds() []string {
+	file, err := os.Open(filepath.Join(GetDataDir(), packageHoldsFile))
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+	var packages []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if pkg := strings.TrimSpace(scanner.Text()); pkg != "" {
+			packages = append(packages, pkg)
+		}
+	}
+	return packages
+}
+
+// recordPackageHold adds a package to or removes it from the packages held through PackageHold
+func recordPackageHold(pkg string, held bool) error {
+	packageHoldsMutex.Lock()
+	defer packageHoldsMutex.Unlock()
+	packages := knownPackageHolds()
+	index := slices.Index(packages, pkg)
+	switch {
+	case held && index == -1:
+		packages = append(packages, pkg)
+	case !held && index != -1:
+		packages = slices.Delete(packages, index, index+1)
+	default:
+		return nil
+	}
+	path := filepath.Join(GetDataDir(), packageHoldsFile)
+	if len(packages) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(strings.Join(packages, "\n")+"\n"), 0644)
+}
+
+// unknownHeldPackages returns the packages that are held without Pi-Apps having held them, which is what the
+// diagnosis has to tell the user about
+func unknownHeldPackages(packages []string) []string {
+	known := knownPackageHolds()
+	var held []string
+	for _, pkg := range packages {
+		if !slices.Contains(known, pkg) && PackageIsHeld(pkg) {
+			held = append(held, pkg)
+		}
+	}
+	return held
+}
+
+// setAppHeld records whether an installed package-app's package is held
+func setAppHeld(app string, held bool) {
+	path, err := AppDataPath("held", app)
+	if err != nil {
+		return
+	}
+	if !held {
+		os.Remove(path)
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		Debug("Failed to record the hold of " + app + ": " + err.Error())
+		return
+	}
+	if err := os.WriteFile(path, nil, 0644); err != nil {
+		Debug("Failed to record the hold of " + app + ": " + err.Error())
+	}
+}
+
+// AppHeld reports whether the package of an installed package-app was held when its status was last refreshed
+func AppHeld(app string) bool {
+	path, err := AppDataPath("held", app)
+	return err == nil && FileExists(path)
+}
+
+// AppDisplayStatus returns the status of an app as shown to the user, which is StatusInstalledHeld for installed
+// package-apps whose package is held and the same as GetAppStatus otherwise
+func AppDisplayStatus(app string) (string, error) {
+	status, err := GetAppStatus(app)
+	if err == nil && status == "installed" && AppHeld(app) {
+		return StatusInstalledHeld, nil
+	}
+	return status, err
+}
+
+// heldAppPackages returns the required packages of a package-app that are held
+func heldAppPackages(app string) []string {
+	packages, err := PkgAppPackagesRequired(app)
+	if err != nil {
+		return nil
+	}
+	var held []string
+	for _, pkg := range strings.Fields(packages) {
+		if PackageIsHeld(pkg) {
+			held = append(held, pkg)
+		}
+	}
+	return held
+}
+
+// updateHeldAllowed holds the package-apps the user wants updated even though their packages are held
+var (
+	updateHeldAllowed      = make(map[string]bool)
+	updateHeldAllowedMutex sync.Mutex
+)
+
+// AllowUpdateHeld lets UpdateApp update a package-app in this process even if its packages are held,
+// unholding them first
+func AllowUpdateHeld(app string) {
+	updateHeldAllowedMutex.Lock()
+	defer updateHeldAllowedMutex.Unlock()
+	updateHeldAllowed[app] = true
+}
+
+// heldUpdateAllowed reports whether AllowUpdateHeld was called for an app
+func heldUpdateAllowed(app string) bool {
+	updateHeldAllowedMutex.Lock()
+	defer updateHeldAllowedMutex.Unlock()
+	return updateHeldAllowed[app]
+}
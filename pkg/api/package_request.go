@@ -0,0 +1,116 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: package_request.go
+// Description: Parses the package arguments of install_packages that pin a version, target a release or pick an architecture.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package api
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// PackageRequest is a package argument of InstallPackages with its constraints. The syntax follows apt's command line:
+//
+//	name                    any version
+//	name=version            exactly this version
+//	name/target-release     the newest version of this release, e.g. vlc/bookworm-backports
+//	name:arch               the package of another architecture, e.g. libc6:armhf
+//
+// The architecture can be combined with a version or a release, like libc6:armhf=2.36-9.
+type PackageRequest struct {
+	Name    string
+	Arch    string // architecture, "" for the native one
+	Version string // exact version, "" for any
+	Release string // target release the version comes from, "" for any
+}
+
+var (
+	// packageNamePattern matches Debian package names
+	packageNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9+.-]+$`)
+	// packageArchPattern matches Debian architecture names
+	packageArchPattern = regexp.MustCompile(`^[a-z0-9-]+$`)
+	// packageVersionPattern matches Debian versions, with an optional epoch
+	packageVersionPattern = regexp.MustCompile(`^[0-9][A-Za-z0-9.+~:-]*$`)
+)
+
+// IsPackageRequest reports whether a package argument uses the syntax of ParsePackageRequest,
+// unlike local files, URLs, wildcards and dependency strings like "foo (>= 1.0)", "foo | bar" or "foo, bar"
+func IsPackageRequest(arg string) bool {
+	arg = strings.TrimSpace(arg)
+	return arg != "" && !strings.HasPrefix(arg, "/") && !strings.Contains(arg, "://") &&
+		!strings.ContainsAny(arg, "* (|,")
+}
+
+// ParsePackageRequest parses a package argument like name, name=version, name/release or name:arch,
+// ignoring surrounding whitespace
+func ParsePackageRequest(arg string) (PackageRequest, error) {
+	var request PackageRequest
+	rest := strings.TrimSpace(arg)
+	if name, version, found := strings.Cut(rest, "="); found {
+		rest, request.Version = name, version
+		if !packageVersionPattern.MatchString(version) {
+			return PackageRequest{}, fmt.Errorf("package request %q has an invalid version", arg)
+		}
+	}
+	if name, release, found := strings.Cut(rest, "/"); found {
+		rest, request.Release = name, release
+		if release == "" || strings.Contains(release, "/") {
+			return PackageRequest{}, fmt.Errorf("package request %q has an invalid release", arg)
+		}
+	}
+	if request.Version != "" && request.Release != "" {
+		return PackageRequest{}, fmt.Errorf("package request %q asks for a version and a release, use one of them", arg)
+	}
+	if name, arch, found := strings.Cut(rest, ":"); found {
+		rest, request.Arch = name, arch
+		if !packageArchPattern.MatchString(arch) {
+			return PackageRequest{}, fmt.Errorf("package request %q has an invalid architecture", arg)
+		}
+	}
+	if !packageNamePattern.MatchString(rest) {
+		return PackageRequest{}, fmt.Errorf("package request %q has an invalid package name", arg)
+	}
+	request.Name = rest
+	return request, nil
+}
+
+// Constrained reports whether the request asks for more than any version of the native package
+func (r PackageRequest) Constrained() bool {
+	return r.Arch != "" || r.Version != "" || r.Release != ""
+}
+
+// Package returns the package name with its architecture, like apt and dpkg write it
+func (r PackageRequest) Package() string {
+	if r.Arch != "" {
+		return r.Name + ":" + r.Arch
+	}
+	return r.Name
+}
+
+// String returns the request in the syntax ParsePackageRequest parses, which apt-get install accepts too
+func (r PackageRequest) String() string {
+	switch {
+	case r.Version != "":
+		return r.Package() + "=" + r.Version
+	case r.Release != "":
+		return r.Package() + "/" + r.Release
+	}
+	return r.Package()
+}
@@ -0,0 +1,96 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package api
+
+import "testing"
+
+func TestParsePackageRequest(t *testing.T) {
+	tests := []struct {
+		arg     string
+		want    PackageRequest
+		wantErr bool
+	}{
+		{arg: "vlc", want: PackageRequest{Name: "vlc"}},
+		{arg: "vlc=3.0.20-1", want: PackageRequest{Name: "vlc", Version: "3.0.20-1"}},
+		{arg: "vlc=1:3.0.20+dfsg~1", want: PackageRequest{Name: "vlc", Version: "1:3.0.20+dfsg~1"}},
+		{arg: "vlc/bookworm-backports", want: PackageRequest{Name: "vlc", Release: "bookworm-backports"}},
+		{arg: "libc6:armhf", want: PackageRequest{Name: "libc6", Arch: "armhf"}},
+		{arg: "libc6:armhf=2.36-9", want: PackageRequest{Name: "libc6", Arch: "armhf", Version: "2.36-9"}},
+		{arg: "libc6:armhf/bookworm", want: PackageRequest{Name: "libc6", Arch: "armhf", Release: "bookworm"}},
+		{arg: "  vlc=3.0.20-1\t", want: PackageRequest{Name: "vlc", Version: "3.0.20-1"}},
+		{arg: "", wantErr: true},
+		{arg: "   ", wantErr: true},
+		{arg: "Vlc", wantErr: true},
+		{arg: "vlc=", wantErr: true},
+		{arg: "vlc=latest", wantErr: true},
+		{arg: "vlc/", wantErr: true},
+		{arg: "vlc/a/b", wantErr: true},
+		{arg: "vlc=1.0/bookworm", wantErr: true},
+		{arg: "vlc:", wantErr: true},
+		{arg: "vlc:ARM", wantErr: true},
+		{arg: "vlc | mpv", wantErr: true},
+		{arg: "vlc|mpv=1.0", wantErr: true},
+		{arg: "vlc, mpv", wantErr: true},
+		{arg: "vlc,mpv=1.0", wantErr: true},
+		{arg: "vlc = 1.0", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParsePackageRequest(tt.arg)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParsePackageRequest(%q) = %+v, want an error", tt.arg, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParsePackageRequest(%q): %v", tt.arg, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParsePackageRequest(%q) = %+v, want %+v", tt.arg, got, tt.want)
+		}
+		// String must give back something ParsePackageRequest parses to the same request
+		if again, err := ParsePackageRequest(got.String()); err != nil || again != got {
+			t.Errorf("ParsePackageRequest(%q) = %+v, %v, want %+v", got.String(), again, err, got)
+		}
+	}
+}
+
+func TestIsPackageRequest(t *testing.T) {
+	tests := []struct {
+		arg  string
+		want bool
+	}{
+		{"vlc", true},
+		{"vlc=3.0.20-1", true},
+		{" libc6:armhf ", true},
+		{"", false},
+		{" ", false},
+		{"/tmp/vlc.deb", false},
+		{"https://example.com/vlc.deb", false},
+		{"vlc-*", false},
+		{"vlc (>= 3.0)", false},
+		{"vlc | mpv", false},
+		{"vlc|mpv", false},
+		{"vlc, mpv", false},
+	}
+	for _, tt := range tests {
+		if got := IsPackageRequest(tt.arg); got != tt.want {
+			t.Errorf("IsPackageRequest(%q) = %v, want %v", tt.arg, got, tt.want)
+		}
+	}
+}
@@ -40,6 +40,7 @@ import (
 
 	"github.com/ProtonMail/gopenpgp/v3/armor"
 	"github.com/ProtonMail/gopenpgp/v3/crypto"
+	"github.com/pi-apps-go/pi-apps/pkg/api/errs"
 )
 
 // RepoAdd adds local package files to the /var/cache/pi-apps/pi-apps-local-packages repository
@@ -178,7 +179,11 @@ func AptLockWait() error {
 	go func() {
 		select {
 		case <-time.After(5 * time.Second):
-			fmt.Print(T("Waiting until pacman locks are released... "))
+			if holders := lockHolders("pacman", "yay", "paru", "pamac-daemon"); holders != "" {
+				fmt.Print(Tf("Waiting until pacman locks held by %s are released... ", holders))
+			} else {
+				fmt.Print(T("Waiting until pacman locks are released... "))
+			}
 			notificationShown <- true
 		case <-notificationDone:
 			return
@@ -196,7 +201,7 @@ func AptLockWait() error {
 		cmd.Stdin = os.Stdin
 		if err := cmd.Run(); err != nil {
 			close(notificationDone)
-			return fmt.Errorf("failed to get sudo permissions: %w", err)
+			return errs.New(errs.ErrNeedsRoot, "failed to get sudo permissions: %w", err)
 		}
 	}
 
@@ -419,7 +424,7 @@ func AptUpdate(args ...string) error {
 
 	// Use cyan color with reverse video styling to match the original implementation
 	// \033[96m for cyan, \033[7m for reverse video, \033[27m to end reverse, \033[0m to reset all formatting
-	fmt.Fprintf(os.Stderr, "\033[96m%s \033[7msudo pacman -Sy\033[27m...\033[0m\n", T("Running"))
+	colorPrintf(os.Stderr, "\033[96m%s \033[7msudo pacman -Sy\033[27m...\033[0m\n", T("Running"))
 
 	// Prepare the pacman -Sy command
 	cmdArgs := []string{"pacman", "-Sy", "--noconfirm"}
@@ -493,7 +498,7 @@ func AptUpdate(args ...string) error {
 	completeOutput := outputBuffer.String()
 
 	// Show completion message in cyan to match the original
-	fmt.Fprintf(os.Stderr, "\033[96m%s\033[0m\n", T("pacman -Sy complete."))
+	colorPrintf(os.Stderr, "\033[96m%s\033[0m\n", T("pacman -Sy complete."))
 
 	// Handle errors
 	if err != nil {
@@ -508,9 +513,9 @@ func AptUpdate(args ...string) error {
 		}
 
 		errorMessage := strings.Join(errorLines, "\n")
-		fmt.Fprintf(os.Stderr, "\033[91m%s \033[4msudo pacman -Sy\033[0m\033[39m!\n", T("Failed to run"))
+		colorPrintf(os.Stderr, "\033[91m%s \033[4msudo pacman -Sy\033[0m\033[39m!\n", T("Failed to run"))
 		if errorMessage != "" {
-			fmt.Fprintf(os.Stderr, "%s\n\033[91m%s\033[39m\n", T("Pacman reported these errors:"), errorMessage)
+			colorPrintf(os.Stderr, "%s\n\033[91m%s\033[39m\n", T("Pacman reported these errors:"), errorMessage)
 		}
 
 		// Print the full output for diagnosis
@@ -579,6 +584,9 @@ func AppToPkgName(app string) (string, error) {
 //	"" - error if app is not specified
 //	error - error if app is not specified
 func InstallPackages(app string, args ...string) error {
+	// The progress monitor shows the packages being installed until the script goes on
+	defer startPhase(PhaseInstallingPackages, -1)()
+
 	if app == "" {
 		return fmt.Errorf("install_packages function can only be used by apps to install packages (the app variable was not set)")
 	}
@@ -804,24 +812,24 @@ func InstallPackages(app string, args ...string) error {
 		errorStr := strings.Join(errorLines, "\n")
 
 		if len(errorLines) > 0 {
-			fmt.Printf("\033[91m%s\033[39m\n", T("Failed to install the packages!"))
-			fmt.Printf("%s\n\033[91m%s\033[39m\n", T("Pacman reported these errors:"), errorStr)
+			colorPrintf(os.Stdout, "\033[91m%s\033[39m\n", T("Failed to install the packages!"))
+			colorPrintf(os.Stdout, "%s\n\033[91m%s\033[39m\n", T("Pacman reported these errors:"), errorStr)
 		} else {
-			fmt.Printf("\033[91m%s\033[39m\n", T("Failed to install the packages!"))
+			colorPrintf(os.Stdout, "\033[91m%s\033[39m\n", T("Failed to install the packages!"))
 			fmt.Printf(T("Pacman exited with error code %d\n"), cmd.ProcessState.ExitCode())
 		}
 
 		fmt.Println(combinedOutput)
 
 		if len(errorLines) > 0 {
-			return fmt.Errorf("pacman reported errors: %s", errorStr)
+			return packageManagerError("pacman", errorStr)
 		}
 		return fmt.Errorf("pacman install failed with exit code %d", cmd.ProcessState.ExitCode())
 	}
 
 	// Create a tracking file to remember which packages were installed for this app
 	piAppsDir := GetPiAppsDir()
-	trackingDir := filepath.Join(piAppsDir, "data", "installed-packages")
+	trackingDir := filepath.Join(DataDirOf(piAppsDir), "installed-packages")
 	if err := os.MkdirAll(trackingDir, 0755); err != nil {
 		WarningTf("Failed to create tracking directory: %v", err)
 	} else {
@@ -930,7 +938,7 @@ func PurgePackages(app string, isUpdate bool) error {
 	piAppsDir := GetPiAppsDir()
 
 	// Check for tracking file
-	trackingFile := filepath.Join(piAppsDir, "data", "installed-packages", app)
+	trackingFile := filepath.Join(DataDirOf(piAppsDir), "installed-packages", app)
 
 	if !FileExists(trackingFile) {
 		// No tracking file, nothing to purge
@@ -1050,17 +1058,17 @@ func PurgePackages(app string, isUpdate bool) error {
 		errorStr := strings.Join(errorLines, "\n")
 
 		if len(errorLines) > 0 {
-			fmt.Printf("\033[91m%s\033[39m\n", T("Failed to uninstall the packages!"))
-			fmt.Printf("%s\n\033[91m%s\033[39m\n", T("Pacman reported these errors:"), errorStr)
+			colorPrintf(os.Stdout, "\033[91m%s\033[39m\n", T("Failed to uninstall the packages!"))
+			colorPrintf(os.Stdout, "%s\n\033[91m%s\033[39m\n", T("Pacman reported these errors:"), errorStr)
 		} else {
-			fmt.Printf("\033[91m%s\033[39m\n", T("Failed to uninstall the packages!"))
+			colorPrintf(os.Stdout, "\033[91m%s\033[39m\n", T("Failed to uninstall the packages!"))
 			fmt.Printf(T("Pacman exited with error code %d\n"), cmd.ProcessState.ExitCode())
 		}
 
 		fmt.Println(combinedOutput)
 
 		if len(errorLines) > 0 {
-			return fmt.Errorf("pacman reported errors: %s", errorStr)
+			return packageManagerError("pacman", errorStr)
 		}
 		return fmt.Errorf("pacman remove failed with exit code %d", cmd.ProcessState.ExitCode())
 	}
@@ -1590,6 +1598,11 @@ func RmExternalRepo(reponame string, force bool) error {
 		if err := RemoveRepofileIfUnused(reponame, "", ""); err != nil {
 			return fmt.Errorf("rm_external_repo: %w", err)
 		}
+
+		// Tell the user what keeps the repository from being removed
+		if packages, err := PackagesInstalledFromRepo(reponame, "", ""); err == nil {
+			printBlockingPackages(reponame, packages)
+		}
 	}
 
 	return nil
@@ -1679,6 +1692,40 @@ func PackageInstalled(packageName string) bool {
 	return err == nil
 }
 
+// PackageHold holds a package so upgrades leave it at its installed version
+func PackageHold(packageName string) error {
+	// pacman holds packages with IgnorePkg in /etc/pacman.conf, which Pi-Apps doesn't edit
+	return fmt.Errorf("holding packages is not supported with pacman")
+}
+
+// PackageUnhold removes the hold of a package
+func PackageUnhold(packageName string) error {
+	// pacman holds packages with IgnorePkg in /etc/pacman.conf, which Pi-Apps doesn't edit
+	return fmt.Errorf("holding packages is not supported with pacman")
+}
+
+// PackageIsHeld checks if a package is installed and held
+func PackageIsHeld(packageName string) bool {
+	// pacman has no holds Pi-Apps manages
+	return false
+}
+
+// InstalledPackages returns the names of all installed packages with a single pacman call
+func InstalledPackages() (map[string]bool, error) {
+	cmd := exec.Command("pacman", "-Qq")
+	cmd.Env = append(os.Environ(), "LANG=en_US.UTF-8", "LC_ALL=en_US.UTF-8")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list installed packages: %w", err)
+	}
+
+	packages := make(map[string]bool)
+	for _, name := range strings.Fields(string(output)) {
+		packages[name] = true
+	}
+	return packages, nil
+}
+
 // PackageAvailable determines if the specified package exists in a repository
 func PackageAvailable(packageName string, dpkgArch string) bool {
 	// Special handling for "init" package check
@@ -1985,7 +2032,7 @@ func refreshPackageAppStatusWithCache(appName, aptCacheOutput, dpkgStatus, direc
 		// If the package is installed, mark the app as installed
 		if status != "installed" {
 			Debug(fmt.Sprintf("Marking %s as installed", appName))
-			statusDir := filepath.Join(directory, "data", "status")
+			statusDir := filepath.Join(DataDirOf(directory), "status")
 			if err := os.MkdirAll(statusDir, 0755); err != nil {
 				return fmt.Errorf("error creating status directory: %w", err)
 			}
@@ -2006,7 +2053,7 @@ func refreshPackageAppStatusWithCache(appName, aptCacheOutput, dpkgStatus, direc
 		if status != "uninstalled" {
 			Debug(fmt.Sprintf("Marking %s as uninstalled", appName))
 			// Remove the status file to mark it as uninstalled
-			statusFile := filepath.Join(directory, "data", "status", appName)
+			statusFile := filepath.Join(DataDirOf(directory), "status", appName)
 			_ = os.Remove(statusFile) // Ignore error if file doesn't exist
 
 			// Send analytics
@@ -2017,7 +2064,7 @@ func refreshPackageAppStatusWithCache(appName, aptCacheOutput, dpkgStatus, direc
 	}
 
 	// Check if the app is currently hidden but should be unhidden
-	categoryOverridesFile := filepath.Join(directory, "data", "category-overrides")
+	categoryOverridesFile := filepath.Join(DataDirOf(directory), "category-overrides")
 	if FileExists(categoryOverridesFile) {
 		// Check if app is marked as hidden in the category-overrides file
 		file, err := os.Open(categoryOverridesFile)
@@ -0,0 +1,70 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: pacman_disk_usage.go
+// Description: Reads the package sizes of the disk usage attribution from the pacman database.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+//go:build pacman
+
+package api
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// pacmanLocalDB is the directory of the pacman database of installed packages
+const pacmanLocalDB = "/var/lib/pacman/local"
+
+// appPackageNames returns the packages recorded when the app was installed
+func appPackageNames(app string) []string {
+	return trackedPackageNames(app)
+}
+
+// installedPackageSizes returns the installed size of every installed package in bytes, read from the
+// %NAME% and %SIZE% fields of the desc files of the pacman database
+func installedPackageSizes() (map[string]int64, error) {
+	entries, err := os.ReadDir(pacmanLocalDB)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the pacman database: %w", err)
+	}
+	sizes := make(map[string]int64)
+	for _, entry := range entries {
+		data, err := os.ReadFile(filepath.Join(pacmanLocalDB, entry.Name(), "desc"))
+		if err != nil {
+			continue
+		}
+		var name string
+		var size int64
+		lines := strings.Split(string(data), "\n")
+		for i := 0; i+1 < len(lines); i++ {
+			switch lines[i] {
+			case "%NAME%":
+				name = strings.TrimSpace(lines[i+1])
+			case "%SIZE%":
+				size, _ = strconv.ParseInt(strings.TrimSpace(lines[i+1]), 10, 64)
+			}
+		}
+		if name != "" {
+			sizes[name] = size
+		}
+	}
+	return sizes, nil
+}
@@ -29,9 +29,6 @@ import (
 	"path/filepath"
 	"strings"
 	"time"
-
-	"github.com/gotk3/gotk3/glib"
-	"github.com/gotk3/gotk3/gtk"
 )
 
 // variables for pacman related messages
@@ -46,33 +43,16 @@ var (
 	UbuntuPPAInstallerMessage  = T("Install AUR package (equivalent to Ubuntu PPA)")
 	DebianPPAInstallerMessage  = T("Install AUR package (equivalent to Debian PPA, arguments beyond the package name are ignored)")
 	PatchDebSedMessage         = T("Modify the control file of a deb file to fix the dependencies following a sed pattern - ignored, not supported by pacman")
+	RebuildDummyDebsMessage    = T("Rebuild missing dummy debs of installed apps - ignored, not supported by pacman")
 )
 
-// checkShellcheck checks if shellcheck is installed and installs it if it isn't
-func checkShellcheck() error {
-	// Initialize application name
-	glib.SetPrgname("Pi-Apps-Settings")
-	glib.SetApplicationName("Pi-Apps Settings (app creation wizard)")
-
-	// Initialize GTK
-	gtk.Init(nil)
-
-	// Check if shellcheck is installed
-	if !commandExists("shellcheck") {
-		// Ask if they want to install shellcheck
-		dialog := gtk.MessageDialogNew(nil, gtk.DIALOG_MODAL, gtk.MESSAGE_QUESTION, gtk.BUTTONS_YES_NO,
-			"Shellcheck is not installed, but it's useful for finding errors in shell scripts. Install it now?")
-		response := dialog.Run()
-		dialog.Destroy()
-
-		if response == gtk.RESPONSE_YES {
-			cmd := exec.Command("sudo", "pacman", "-S", "--noconfirm", "shellcheck")
-			cmd.Stdout = os.Stdout
-			cmd.Stderr = os.Stderr
-			if err := cmd.Run(); err != nil {
-				return fmt.Errorf("failed to install shellcheck: %v", err)
-			}
-		}
+// installShellcheck installs shellcheck with the package manager
+func installShellcheck() error {
+	cmd := exec.Command("sudo", "pacman", "-S", "--noconfirm", "shellcheck")
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to install shellcheck: %v", err)
 	}
 	return nil
 }
@@ -472,8 +452,8 @@ func installPackageApp(appName string) error {
 	piAppsDir := GetPiAppsDir()
 
 	// Set up logging
-	logDir := filepath.Join(piAppsDir, "logs")
-	os.MkdirAll(logDir, 0755)
+	logDir := LogsDirOf(piAppsDir)
+	EnsureDirOwned(logDir, InvokingUser, InvokingUser)
 	logFilename := fmt.Sprintf("install-%s-incomplete-%d.log", appName, time.Now().Unix())
 	logPath := filepath.Join(logDir, logFilename)
 
@@ -524,7 +504,7 @@ func installPackageApp(appName string) error {
 		FormatLogfile(logPath)
 
 		// Rename log file to indicate failure
-		newLogPath := strings.Replace(logPath, "-incomplete-", "-fail-", 1)
+		newLogPath := logPathWithResult(logPath, "fail")
 		os.Rename(logPath, newLogPath)
 
 		return fmt.Errorf("failed to install packages: %v", err)
@@ -538,7 +518,7 @@ func installPackageApp(appName string) error {
 	FormatLogfile(logPath)
 
 	// Rename log file to indicate success
-	newLogPath := strings.Replace(logPath, "-incomplete-", "-success-", 1)
+	newLogPath := logPathWithResult(logPath, "success")
 	os.Rename(logPath, newLogPath)
 
 	// Mark app as installed
@@ -550,8 +530,8 @@ func uninstallPackageApp(appName string) error {
 	piAppsDir := GetPiAppsDir()
 
 	// Set up logging
-	logDir := filepath.Join(piAppsDir, "logs")
-	os.MkdirAll(logDir, 0755)
+	logDir := LogsDirOf(piAppsDir)
+	EnsureDirOwned(logDir, InvokingUser, InvokingUser)
 	logFilename := fmt.Sprintf("uninstall-%s-incomplete-%d.log", appName, time.Now().Unix())
 	logPath := filepath.Join(logDir, logFilename)
 
@@ -583,7 +563,7 @@ func uninstallPackageApp(appName string) error {
 		FormatLogfile(logPath)
 
 		// Rename log file to indicate failure
-		newLogPath := strings.Replace(logPath, "-incomplete-", "-fail-", 1)
+		newLogPath := logPathWithResult(logPath, "fail")
 		os.Rename(logPath, newLogPath)
 
 		return fmt.Errorf("failed to uninstall packages: %v", err)
@@ -597,7 +577,7 @@ func uninstallPackageApp(appName string) error {
 	FormatLogfile(logPath)
 
 	// Rename log file to indicate success
-	newLogPath := strings.Replace(logPath, "-incomplete-", "-success-", 1)
+	newLogPath := logPathWithResult(logPath, "success")
 	os.Rename(logPath, newLogPath)
 
 	// Mark app as uninstalled
@@ -638,3 +618,13 @@ func uninstallPackageAppDependencies(dependencies ...string) error {
 func PatchDebSed(debFile, sedString string) error {
 	return fmt.Errorf("only supported on Debian-based systems")
 }
+
+// RebuildDummyDeb regenerates and installs the dummy deb of an app whose dummy deb was removed
+func RebuildDummyDeb(app string) error {
+	return fmt.Errorf("only supported on Debian-based systems")
+}
+
+// RebuildDummyDebs rebuilds the dummy debs of the given apps, or of every app reported by ListAppsMissingDummyDebs
+func RebuildDummyDebs(apps ...string) ([]string, error) {
+	return nil, fmt.Errorf("only supported on Debian-based systems")
+}
@@ -0,0 +1,37 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: pacman_path_owner.go
+// Description: Asks pacman which package installed a path, for finding the app that owns it.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+//go:build pacman
+
+package api
+
+import (
+	"os/exec"
+	"strings"
+)
+
+// packagesOwningPath returns the package pacman installed a path with, none if no package did
+func packagesOwningPath(path string) []string {
+	output, err := exec.Command("pacman", "-Qqo", "--", path).Output()
+	if err != nil {
+		return nil
+	}
+	return strings.Fields(string(output))
+}
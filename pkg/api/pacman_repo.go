@@ -24,11 +24,13 @@ package api
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
 )
 
@@ -48,15 +50,33 @@ func AnythingInstalledFromURISuiteComponent(uri, suite, component string) (bool,
 		return false, fmt.Errorf("repository URI must be specified")
 	}
 
+	packages, err := PackagesInstalledFromRepo(uri, suite, component)
+	if err != nil {
+		return false, err
+	}
+	return len(packages) > 0, nil
+}
+
+// PackagesInstalledFromRepo lists the installed packages that come from a specific repository.
+//
+// For pacman, URI typically maps to a repository name in /etc/pacman.conf
+// suite and component are ignored as pacman doesn't use these concepts
+//
+//	[]InstalledPackage - installed packages from the repository, sorted by name
+//	error - error if repository URI is not specified or the installed packages cannot be listed
+func PackagesInstalledFromRepo(uri, suite, component string) ([]InstalledPackage, error) {
+	if uri == "" {
+		return nil, fmt.Errorf("repository URI must be specified")
+	}
+
 	// Get all installed packages
 	installedPackages, err := getInstalledPackages()
 	if err != nil {
-		return false, fmt.Errorf("failed to get installed packages: %w", err)
+		return nil, fmt.Errorf("failed to get installed packages: %w", err)
 	}
 
-	// Check if any installed packages are from this repository
 	// Note: suite and component are ignored for pacman
-	return checkIfPackagesInstalledFromRepo(installedPackages, uri, suite, component)
+	return packagesInstalledFromRepo(installedPackages, uri)
 }
 
 // RemoveRepofileIfUnused removes a pacman repository configuration if nothing from that repository is currently installed.
@@ -433,8 +453,17 @@ func getPackagesInRepo(repoName string) ([]string, error) {
 
 // Helper function to check if any packages are installed from a specific repo
 func checkIfPackagesInstalledFromRepo(packages []string, uri, suite, component string) (bool, error) {
+	installed, err := packagesInstalledFromRepo(packages, uri)
+	if err != nil {
+		return false, err
+	}
+	return len(installed) > 0, nil
+}
+
+// Helper function to list which of the given packages are installed from a specific repo
+func packagesInstalledFromRepo(packages []string, uri string) ([]InstalledPackage, error) {
 	if len(packages) == 0 {
-		return false, nil
+		return nil, nil
 	}
 
 	// Clean URI for comparison
@@ -460,6 +489,7 @@ func checkIfPackagesInstalledFromRepo(packages []string, uri, suite, component s
 
 	// For pacman, we check the repository of each installed package
 	// using pacman -Qi to get repository information
+	var result []InstalledPackage
 	for _, pkg := range packages {
 		// Use pacman -Qi to get package info (for installed packages)
 		cmd := exec.Command("pacman", "-Qi", pkg)
@@ -470,30 +500,66 @@ func checkIfPackagesInstalledFromRepo(packages []string, uri, suite, component s
 			continue
 		}
 
-		// Parse output to find Repository line
+		// Parse output to find the Repository, Version and Install Reason lines
+		// Format: "Repository      : core" or "Install Reason  : Installed as a dependency for another package"
+		fromRepo := false
+		installed := InstalledPackage{Name: pkg}
 		lines := strings.Split(string(output), "\n")
 		for _, line := range lines {
-			if strings.HasPrefix(line, "Repository") {
-				// Format: "Repository      : core" or "Repository      : extra"
-				parts := strings.SplitN(line, ":", 2)
-				if len(parts) >= 2 {
-					repo := strings.ToLower(strings.TrimSpace(parts[1]))
-
-					// Check if the repository matches
-					// Match by repository name (e.g., "core", "extra", "community")
-					if repo == cleanURI || repo == repoNameFromURI {
-						return true, nil
-					}
-
-					// Also check if URI contains the repo name or vice versa
-					if strings.Contains(repo, cleanURI) || strings.Contains(cleanURI, repo) {
-						return true, nil
-					}
+			parts := strings.SplitN(line, ":", 2)
+			if len(parts) < 2 {
+				continue
+			}
+			value := strings.TrimSpace(parts[1])
+			switch strings.TrimSpace(parts[0]) {
+			case "Repository":
+				repo := strings.ToLower(value)
+
+				// Check if the repository matches
+				// Match by repository name (e.g., "core", "extra", "community")
+				// Also check if URI contains the repo name or vice versa
+				if repo == cleanURI || repo == repoNameFromURI ||
+					strings.Contains(repo, cleanURI) || strings.Contains(cleanURI, repo) {
+					fromRepo = true
 				}
-				break
+			case "Version":
+				installed.Version = value
+			case "Install Reason":
+				installed.Automatic = strings.Contains(value, "dependency")
 			}
 		}
+		if fromRepo {
+			result = append(result, installed)
+		}
 	}
+	slices.SortFunc(result, func(a, b InstalledPackage) int {
+		return strings.Compare(a.Name, b.Name)
+	})
 
-	return false, nil
+	return result, nil
+}
+
+// CheckAptSources checks the configured APT repositories. It finds none, as Pacman repositories are not checked.
+func CheckAptSources(ctx context.Context) ([]SourceHealth, error) {
+	return nil, ctx.Err()
+}
+
+// DisableAptSource disables a broken APT repository, there are none without apt
+func DisableAptSource(source SourceHealth) error {
+	return fmt.Errorf("%s is not an APT repository file", source.File)
+}
+
+// RestoreAptSources enables the APT repositories DisableAptSource disabled again, there are none without apt
+func RestoreAptSources() (int, error) {
+	return 0, nil
+}
+
+// ListExternalRepoKeys lists the signing keys of the APT repositories. It finds none, as Pacman repositories are signed by the keys of pacman-key.
+func ListExternalRepoKeys() ([]RepoKey, error) {
+	return nil, nil
+}
+
+// RenewExternalRepoKey downloads the key of an APT repository again, there are none without apt
+func RenewExternalRepoKey(repo string) error {
+	return fmt.Errorf("%s is not an APT repository", repo)
 }
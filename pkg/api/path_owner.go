@@ -0,0 +1,236 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: path_owner.go
+// Description: Finds the app that installed a path, from the install manifests, the package database and the download ledger.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package api
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Sources WhichAppOwnsPath names as the record proving which app owns a path
+const (
+	OwnerSourceManifest     = "install manifest"
+	OwnerSourcePackage      = "package"
+	OwnerSourceDesktopEntry = "desktop entry"
+	OwnerSourceUserService  = "user service"
+	OwnerSourceDownload     = "download ledger"
+)
+
+// ErrNoOwningApp is returned by WhichAppOwnsPath when no record links the path to an app
+var ErrNoOwningApp = errors.New("no app owns this path")
+
+// pathOwner is the app a path was recorded for, and the record it was found in
+type pathOwner struct {
+	app    string
+	source string
+}
+
+// PathOwnerIndex finds the apps that installed paths. The install manifests and the download ledger are read
+// once when the index is created and the package database is asked at most once per path, so looking up
+// all the paths of a log stays fast.
+type PathOwnerIndex struct {
+	files     map[string]pathOwner // recorded files, and the targets of the recorded symlinks
+	downloads map[string]pathOwner // download destinations, files or cloned directories
+	packages  map[string]string    // package -> installed app depending on it, read on the first package lookup
+}
+
+// NewPathOwnerIndex reads the install manifests of all apps and the download ledger
+//
+//	*PathOwnerIndex - the index
+//	error - error if PI_APPS_DIR is not set or the manifests can't be read
+func NewPathOwnerIndex() (*PathOwnerIndex, error) {
+	directory := GetPiAppsDir()
+	if directory == "" {
+		return nil, fmt.Errorf("PI_APPS_DIR environment variable not set")
+	}
+	index := &PathOwnerIndex{files: make(map[string]pathOwner), downloads: make(map[string]pathOwner)}
+
+	entries, err := os.ReadDir(filepath.Join(DataDirOf(directory), "install-files"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read the install manifests: %w", err)
+	}
+	for _, entry := range entries {
+		app := entry.Name()
+		if entry.IsDir() || ValidateAppName(app) != nil {
+			continue
+		}
+		lines, err := readInstallManifest(app)
+		if err != nil {
+			Debug(fmt.Sprintf("Failed to read the install manifest of %s: %v", app, err))
+			continue
+		}
+		for _, line := range lines {
+			source := OwnerSourceManifest
+			path := line
+			if unit, ok := strings.CutPrefix(line, installUserServicePrefix); ok {
+				source, path = OwnerSourceUserService, unit
+			} else if strings.HasPrefix(line, "#") {
+				continue
+			} else if strings.HasSuffix(path, ".desktop") && desktopEntryOwner(path) == app {
+				source = OwnerSourceDesktopEntry
+			}
+			index.add(path, pathOwner{app: app, source: source})
+		}
+	}
+
+	ledger, err := DownloadLedger(DownloadLedgerFilter{})
+	if err != nil {
+		Debug(fmt.Sprintf("Failed to read the download ledger: %v", err))
+	}
+	// The newest download to a destination wins
+	for _, download := range ledger {
+		if download.App != "" && filepath.IsAbs(download.Destination) {
+			index.downloads[filepath.Clean(download.Destination)] = pathOwner{app: download.App, source: OwnerSourceDownload}
+		}
+	}
+	return index, nil
+}
+
+// add records the owner of a file, and of the file a recorded symlink points to
+func (index *PathOwnerIndex) add(path string, owner pathOwner) {
+	path = filepath.Clean(path)
+	if _, ok := index.files[path]; !ok {
+		index.files[path] = owner
+	}
+	if target, err := filepath.EvalSymlinks(path); err == nil && target != path {
+		if _, ok := index.files[target]; !ok {
+			index.files[target] = owner
+		}
+	}
+}
+
+// Owner returns the app that installed a path and the record proving it. Symlinks are resolved, a path
+// matches a record through its own name or the file it points to. The records are checked in order:
+// installed files of the install manifests, packages installed for an app, the desktop entries and user
+// services created for an app, and the destinations of its downloads, which include everything inside
+// a downloaded directory.
+//
+//	string - the app
+//	string - the record proving it: OwnerSourceManifest, OwnerSourcePackage followed by the package name,
+//	OwnerSourceDesktopEntry, OwnerSourceUserService or OwnerSourceDownload
+//	error - ErrNoOwningApp if no record links the path to an app
+func (index *PathOwnerIndex) Owner(path string) (string, string, error) {
+	candidates := ownerCandidates(path)
+	if len(candidates) == 0 {
+		return "", "", fmt.Errorf("%q is not a path", path)
+	}
+
+	for _, candidate := range candidates {
+		if owner, ok := index.files[candidate]; ok && owner.source == OwnerSourceManifest {
+			return owner.app, owner.source, nil
+		}
+	}
+	for _, candidate := range candidates {
+		for _, pkg := range packagesOwningPath(candidate) {
+			if app := index.packageApp(pkg); app != "" {
+				return app, OwnerSourcePackage + " " + pkg, nil
+			}
+		}
+	}
+	for _, candidate := range candidates {
+		if owner, ok := index.files[candidate]; ok {
+			return owner.app, owner.source, nil
+		}
+	}
+	for _, candidate := range candidates {
+		for dir := candidate; dir != "/" && dir != "."; dir = filepath.Dir(dir) {
+			if owner, ok := index.downloads[dir]; ok {
+				return owner.app, owner.source, nil
+			}
+		}
+	}
+	return "", "", ErrNoOwningApp
+}
+
+// ownerCandidates returns the absolute path, and the path with its symlinks resolved if that is different
+func ownerCandidates(path string) []string {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return nil
+	}
+	if strings.HasPrefix(path, "~/") {
+		path = filepath.Join(os.Getenv("HOME"), path[2:])
+	}
+	absolute, err := filepath.Abs(path)
+	if err != nil {
+		return nil
+	}
+	candidates := []string{absolute}
+	if resolved, err := filepath.EvalSymlinks(absolute); err == nil && resolved != absolute {
+		candidates = append(candidates, resolved)
+	}
+	return candidates
+}
+
+// packageApp returns the installed app a package was installed for, "" if there is none
+func (index *PathOwnerIndex) packageApp(pkg string) string {
+	if index.packages == nil {
+		index.packages = make(map[string]string)
+		installed, err := ListApps("installed")
+		if err != nil {
+			Debug(fmt.Sprintf("Failed to list the installed apps: %v", err))
+		}
+		slices.Sort(installed)
+		for _, app := range installed {
+			for _, name := range appPackageNames(app) {
+				if _, ok := index.packages[name]; !ok {
+					index.packages[name] = app
+				}
+			}
+		}
+	}
+	name, _, _ := strings.Cut(pkg, ":")
+	return index.packages[name]
+}
+
+// WhichAppOwnsPath returns the app that installed a path and the record proving it, see PathOwnerIndex.Owner.
+// Use a PathOwnerIndex to look up several paths.
+func WhichAppOwnsPath(path string) (app string, source string, err error) {
+	index, err := NewPathOwnerIndex()
+	if err != nil {
+		return "", "", err
+	}
+	return index.Owner(path)
+}
+
+// logPathPattern matches absolute paths and paths in the home directory in a line of a log
+var logPathPattern = regexp.MustCompile(`(?:~|\B)/[^\s'"` + "`" + `:;,()<>\[\]{}]+`)
+
+// PathAtOffset returns the path in a line of text that contains the character offset, "" if there is none.
+// Trailing dots are dropped, they usually end a sentence, and URLs are not paths.
+func PathAtOffset(line string, offset int) string {
+	runes := []rune(line)
+	if offset < 0 || offset > len(runes) {
+		return ""
+	}
+	byteOffset := len(string(runes[:offset]))
+	for _, match := range logPathPattern.FindAllStringIndex(line, -1) {
+		if byteOffset >= match[0] && byteOffset <= match[1] && !strings.HasPrefix(line[match[0]:], "//") {
+			return strings.TrimRight(line[match[0]:match[1]], ".")
+		}
+	}
+	return ""
+}
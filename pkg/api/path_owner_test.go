@@ -0,0 +1,107 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWhichAppOwnsPath(t *testing.T) {
+	directory := newTestPiAppsDir(t, "Foo", "Bar")
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	binary := filepath.Join(home, "opt", "foo", "foo")
+	writeTestFile(t, binary, "#!/bin/sh\n")
+	link := filepath.Join(home, "bin", "foo")
+	if err := os.MkdirAll(filepath.Dir(link), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(binary, link); err != nil {
+		t.Fatal(err)
+	}
+	entry := filepath.Join(home, ".local", "share", "applications", "Foo.desktop")
+	writeTestFile(t, entry, "[Desktop Entry]\nType=Application\nName=Foo\nExec=foo\nX-Pi-Apps-App=Foo\n")
+	unit := filepath.Join(home, ".config", "systemd", "user", "foo.service")
+	writeTestFile(t, unit, "[Unit]\nX-Pi-Apps-App=Foo\n")
+	writeTestFile(t, filepath.Join(directory, "data", "install-files", "Foo"),
+		"# upstream version: 1.0\n"+installUserServicePrefix+unit+"\n"+link+"\n"+entry+"\n")
+
+	clone := filepath.Join(home, "bar")
+	writeTestFile(t, filepath.Join(clone, "src", "main.c"), "int main() {}\n")
+	writeTestFile(t, filepath.Join(directory, "data", "download-ledger.jsonl"),
+		`{"time":"2026-01-01T00:00:00Z","app":"Foo","url":"https://example.com/bar.git","destination":"`+clone+`"}`+"\n"+
+			`{"time":"2026-02-01T00:00:00Z","app":"Bar","url":"https://example.com/bar.git","destination":"`+clone+`"}`+"\n")
+
+	tests := []struct {
+		path, app, source string
+	}{
+		{link, "Foo", OwnerSourceManifest},
+		{binary, "Foo", OwnerSourceManifest}, // the target of a recorded symlink
+		{filepath.Join(home, "bin", ".", "foo"), "Foo", OwnerSourceManifest},
+		{entry, "Foo", OwnerSourceDesktopEntry},
+		{unit, "Foo", OwnerSourceUserService},
+		{filepath.Join(clone, "src", "main.c"), "Bar", OwnerSourceDownload}, // inside the newest download
+		{clone, "Bar", OwnerSourceDownload},
+	}
+	index, err := NewPathOwnerIndex()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range tests {
+		app, source, err := index.Owner(test.path)
+		if err != nil || app != test.app || source != test.source {
+			t.Errorf("Owner(%s) = %q, %q, %v, want %q, %q", test.path, app, source, err, test.app, test.source)
+		}
+	}
+
+	// A symlink to a recorded file that is not recorded itself resolves to it
+	other := filepath.Join(home, "foo-link")
+	if err := os.Symlink(binary, other); err != nil {
+		t.Fatal(err)
+	}
+	if app, _, err := WhichAppOwnsPath(other); err != nil || app != "Foo" {
+		t.Errorf("WhichAppOwnsPath(%s) = %q, %v, want Foo", other, app, err)
+	}
+	if _, _, err := WhichAppOwnsPath(filepath.Join(home, "unknown")); !errors.Is(err, ErrNoOwningApp) {
+		t.Errorf("WhichAppOwnsPath of an unrecorded path = %v, want ErrNoOwningApp", err)
+	}
+}
+
+func TestPathAtOffset(t *testing.T) {
+	tests := []struct {
+		line   string
+		offset int
+		want   string
+	}{
+		{"cp: cannot stat '/usr/local/bin/foo': No such file", 20, "/usr/local/bin/foo"},
+		{"/opt/foo/run.sh: line 3: bar: not found", 3, "/opt/foo/run.sh"},
+		{"Installed to ~/foo/bin.", 18, "~/foo/bin"},
+		{"Installed to ~/foo/bin.", 5, ""},
+		{"Downloading https://example.com/a/b.deb", 30, ""},
+		{"relative/path/file", 10, ""},
+		{"Écrit dans /home/pi/ça", 14, "/home/pi/ça"},
+	}
+	for _, test := range tests {
+		if got := PathAtOffset(test.line, test.offset); got != test.want {
+			t.Errorf("PathAtOffset(%q, %d) = %q, want %q", test.line, test.offset, got, test.want)
+		}
+	}
+}
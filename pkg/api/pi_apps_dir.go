@@ -0,0 +1,355 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: pi_apps_dir.go
+// Description: Finds the Pi-Apps directory the same way in every Pi-Apps program, so they all use the same copy, and where its data and logs are kept.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package api
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+)
+
+// Sources of the Pi-Apps directory, in the order GetPiAppsDir tries them
+const (
+	DirSourceFlag       = "flag"        // set with SetPiAppsDir, like the --directory flag of the GUI
+	DirSourceEnv        = "PI_APPS_DIR" // environment variable the Go programs export to the scripts they run
+	DirSourceDirectory  = "DIRECTORY"   // environment variable of the Bash implementation
+	DirSourceExecutable = "executable"  // directory of the running program, after resolving symlinks
+	DirSourceHome       = "home"        // ~/pi-apps, then ~/pi-apps-go
+)
+
+// DirCandidate is a directory GetPiAppsDir considered
+type DirCandidate struct {
+	Source  string
+	Path    string
+	Problem string // why it can't be used, "" if it is a valid Pi-Apps directory
+}
+
+// DirResolution is how the Pi-Apps directory was found
+type DirResolution struct {
+	Dir        string         // the Pi-Apps directory
+	Source     string         // where Dir comes from, "" if no candidate was valid and Dir is the default ~/pi-apps
+	Candidates []DirCandidate // every candidate in order of precedence
+	Duplicates []string       // other valid Pi-Apps directories on the system
+}
+
+var (
+	// piAppsDirFlag is the directory set with SetPiAppsDir, it takes precedence over everything else
+	piAppsDirFlag string
+
+	// startPiAppsDir is how the Pi-Apps directory was found when Pi-Apps started, before Init exported PI_APPS_DIR
+	startPiAppsDir *DirResolution
+
+	// piAppsDirCache keeps the last resolution, it is only used while the inputs it came from stay the same
+	piAppsDirMutex    sync.Mutex
+	piAppsDirCacheKey string
+	piAppsDirCache    DirResolution
+)
+
+// GetPiAppsDir returns the Pi-Apps directory. It is the first valid one of, in this order:
+//
+//  1. the directory set with SetPiAppsDir (the --directory flag)
+//  2. $PI_APPS_DIR
+//  3. $DIRECTORY
+//  4. the directory containing the running program after resolving symlinks, or its parent
+//  5. ~/pi-apps, then ~/pi-apps-go
+//
+// When none of them is valid, ~/pi-apps is returned. See ResolvePiAppsDir for how it was chosen.
+func GetPiAppsDir() string {
+	return ResolvePiAppsDir().Dir
+}
+
+// SetPiAppsDir makes dir the Pi-Apps directory of this process and of the programs it runs.
+// This is what an explicit --directory flag should call. An invalid directory is warned about and ignored.
+func SetPiAppsDir(dir string) {
+	if err := ValidatePiAppsDir(dir); err != nil {
+		Warning(fmt.Sprintf("Failed to set Pi-Apps directory to %s: %v\n", dir, err))
+		return
+	}
+	piAppsDirMutex.Lock()
+	piAppsDirFlag = dir
+	piAppsDirMutex.Unlock()
+	PIAppsDir = dir
+	os.Setenv("PI_APPS_DIR", dir)
+	if err := initUserStateDir(dir); err != nil {
+		WarningTf("Failed to set up the data folder of this user: %v", err)
+	}
+}
+
+// ResolvePiAppsDir returns the Pi-Apps directory with every candidate that was considered, for `api doctor`.
+// The result is cached until SetPiAppsDir is called or PI_APPS_DIR, DIRECTORY or HOME change.
+func ResolvePiAppsDir() DirResolution {
+	piAppsDirMutex.Lock()
+	defer piAppsDirMutex.Unlock()
+
+	key := piAppsDirFlag + "\x00" + os.Getenv("PI_APPS_DIR") + "\x00" + os.Getenv("DIRECTORY") + "\x00" + os.Getenv("HOME")
+	if key == piAppsDirCacheKey {
+		return piAppsDirCache
+	}
+	resolution := resolvePiAppsDir(piAppsDirCandidates())
+	// A failed resolution is tried again, the directory may be created in the meantime
+	if resolution.Source != "" {
+		piAppsDirCacheKey, piAppsDirCache = key, resolution
+	}
+	return resolution
+}
+
+// StartPiAppsDirResolution returns how the Pi-Apps directory was found when this program started.
+// Init exports the directory as PI_APPS_DIR, so ResolvePiAppsDir only finds that afterwards.
+func StartPiAppsDirResolution() DirResolution {
+	if startPiAppsDir == nil {
+		return ResolvePiAppsDir()
+	}
+	return *startPiAppsDir
+}
+
+// piAppsDirCandidates returns the candidate directories in order of precedence, without checking them
+func piAppsDirCandidates() []DirCandidate {
+	candidates := []DirCandidate{
+		{Source: DirSourceFlag, Path: piAppsDirFlag},
+		{Source: DirSourceEnv, Path: os.Getenv("PI_APPS_DIR")},
+		{Source: DirSourceDirectory, Path: os.Getenv("DIRECTORY")},
+	}
+
+	// The bash implementation used DIRECTORY="$(readlink -f "$(dirname "$0")")"; programs built into
+	// a bin directory, like when developing Pi-Apps Go, are one level deeper
+	if executable, err := os.Executable(); err == nil {
+		if resolved, err := filepath.EvalSymlinks(executable); err == nil {
+			executable = resolved
+		}
+		candidates = append(candidates,
+			DirCandidate{Source: DirSourceExecutable, Path: filepath.Dir(executable)},
+			DirCandidate{Source: DirSourceExecutable, Path: filepath.Dir(filepath.Dir(executable))},
+		)
+	}
+
+	if home, err := os.UserHomeDir(); err == nil {
+		candidates = append(candidates,
+			DirCandidate{Source: DirSourceHome, Path: filepath.Join(home, "pi-apps")},
+			DirCandidate{Source: DirSourceHome, Path: filepath.Join(home, "pi-apps-go")},
+		)
+	}
+	return candidates
+}
+
+// resolvePiAppsDir checks the candidates, picks the first valid one and finds the other valid copies of Pi-Apps
+func resolvePiAppsDir(candidates []DirCandidate) DirResolution {
+	var resolution DirResolution
+	seen := make(map[string]bool)
+	for _, candidate := range candidates {
+		if candidate.Path == "" {
+			candidate.Problem = "not set"
+		} else if err := ValidatePiAppsDir(candidate.Path); err != nil {
+			candidate.Problem = err.Error()
+		}
+		resolution.Candidates = append(resolution.Candidates, candidate)
+		if candidate.Problem != "" {
+			continue
+		}
+
+		// The same directory reached through a symlink or another source is not a duplicate
+		real, err := filepath.EvalSymlinks(candidate.Path)
+		if err != nil {
+			real = candidate.Path
+		}
+		real, _ = filepath.Abs(real)
+		if seen[real] {
+			continue
+		}
+		seen[real] = true
+
+		if resolution.Source == "" {
+			resolution.Dir = candidate.Path
+			resolution.Source = candidate.Source
+		} else {
+			resolution.Duplicates = append(resolution.Duplicates, candidate.Path)
+		}
+	}
+
+	if resolution.Source == "" {
+		home, _ := os.UserHomeDir()
+		resolution.Dir = filepath.Join(home, "pi-apps")
+	}
+	return resolution
+}
+
+// ValidatePiAppsDir returns why dir is not a Pi-Apps directory, nil if it is one.
+//
+// It implements the same safety checks as the original Bash install script to prevent users from accidentally
+// installing Pi-Apps into their HOME directory or other user directories (like Downloads), which could lead to
+// data loss, see https://github.com/Botspot/pi-apps/issues/2137. Subdirectories like ~/Videos/pi-apps are allowed.
+func ValidatePiAppsDir(dir string) error {
+	if dir == "" {
+		return errors.New("no directory given")
+	}
+
+	// Get the absolute path to avoid issues with symlinks and relative paths
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+
+	// If we can't get HOME, we can't validate, so reject for safety
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return fmt.Errorf("can't check the directory without a home directory: %w", err)
+	}
+	absHomeDir, err := filepath.Abs(homeDir)
+	if err != nil {
+		return err
+	}
+	if absDir == absHomeDir {
+		return errors.New("the home directory can't be the Pi-Apps directory")
+	}
+	for _, userDir := range []string{"Downloads", "Documents", "Desktop", "Pictures", "Videos", "Music", "Public", "Templates"} {
+		if absDir == filepath.Join(absHomeDir, userDir) {
+			return fmt.Errorf("the %s directory can't be the Pi-Apps directory", userDir)
+		}
+	}
+
+	if !DirExists(absDir) {
+		return errors.New("does not exist")
+	}
+	// The original Bash script checked [ ! -f "${DIRECTORY}/api" ] || [ ! -f "${DIRECTORY}/gui" ]
+	for _, file := range []string{"api", "gui"} {
+		if !FileExists(filepath.Join(absDir, file)) {
+			return fmt.Errorf("missing %s file", file)
+		}
+	}
+	for _, subdir := range []string{"apps", "data", "etc"} {
+		if !DirExists(filepath.Join(absDir, subdir)) {
+			return fmt.Errorf("missing %s directory", subdir)
+		}
+	}
+	return nil
+}
+
+// isValidPiAppsDir reports whether dir is a Pi-Apps directory, see ValidatePiAppsDir
+func isValidPiAppsDir(dir string) bool {
+	return ValidatePiAppsDir(dir) == nil
+}
+
+// PiAppsCommit returns the commit the Pi-Apps directory is at, or "" if it is not a git clone
+func PiAppsCommit() string {
+	return gitHeadCommit(GetPiAppsDir())
+}
+
+// ErrReadOnlyPiAppsDir is returned for changes to the Pi-Apps directory itself, like updating the apps catalog,
+// when it is shared by the users of the system and only its administrator can change it
+var ErrReadOnlyPiAppsDir = errors.New("the Pi-Apps directory is read-only")
+
+// checkWritable returns an error when this user can't write to path. Tests replace it, as root can write anywhere.
+var checkWritable = func(path string) error {
+	return syscall.Access(path, 2) // W_OK
+}
+
+// readOnlyDirs caches whether Pi-Apps directories are read-only for this user, by path
+var (
+	readOnlyDirsMutex sync.Mutex
+	readOnlyDirs      = make(map[string]bool)
+)
+
+// IsReadOnlyPiAppsDir reports whether a Pi-Apps directory is installed for all users, like under /opt, and this
+// user can't write to it. Its data and logs are then kept in UserStateDir, see DataDirOf, while the apps catalog
+// is still read from the shared directory.
+func IsReadOnlyPiAppsDir(directory string) bool {
+	if directory == "" {
+		return false
+	}
+	readOnlyDirsMutex.Lock()
+	defer readOnlyDirsMutex.Unlock()
+	if readOnly, ok := readOnlyDirs[directory]; ok {
+		return readOnly
+	}
+
+	// The data folder is what gets written, a directory without one yet is written to when it's created
+	path := filepath.Join(directory, "data")
+	if !DirExists(path) {
+		path = directory
+	}
+	err := checkWritable(path)
+	// A directory that doesn't exist isn't shared, it just isn't there yet
+	readOnly := errors.Is(err, syscall.EACCES) || errors.Is(err, syscall.EROFS) || errors.Is(err, syscall.EPERM)
+	readOnlyDirs[directory] = readOnly
+	return readOnly
+}
+
+// initUserStateDir creates the folders Pi-Apps expects to exist in UserStateDir when directory is read-only for
+// this user, so the state of this user starts out empty
+func initUserStateDir(directory string) error {
+	if !IsReadOnlyPiAppsDir(directory) {
+		return nil
+	}
+	state := UserStateDir()
+	for _, dir := range []string{"data/status", "data/settings", "logs"} {
+		if err := EnsureDirOwned(filepath.Join(state, dir), InvokingUser, InvokingUser); err != nil {
+			return fmt.Errorf("failed to create %s: %w", filepath.Join(state, dir), err)
+		}
+	}
+	return nil
+}
+
+// IsSplitDirMode reports whether the Pi-Apps directory is read-only, so the data and logs are in UserStateDir
+func IsSplitDirMode() bool {
+	return IsReadOnlyPiAppsDir(GetPiAppsDir())
+}
+
+// UserStateDir returns where the data and logs of a read-only Pi-Apps directory are kept for this user,
+// $XDG_DATA_HOME/pi-apps, by default ~/.local/share/pi-apps. It has the data and logs folders of a Pi-Apps directory.
+func UserStateDir() string {
+	if dataHome := os.Getenv("XDG_DATA_HOME"); filepath.IsAbs(dataHome) {
+		return filepath.Join(dataHome, "pi-apps")
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".local", "share", "pi-apps")
+}
+
+// stateDirOf returns the directory with the data and logs folders of a Pi-Apps directory
+func stateDirOf(directory string) string {
+	if IsReadOnlyPiAppsDir(directory) {
+		return UserStateDir()
+	}
+	return directory
+}
+
+// DataDirOf returns the folder with the statuses, settings, install manifests and caches of a Pi-Apps directory:
+// its data folder, or the one in UserStateDir when this user can't write to it
+func DataDirOf(directory string) string {
+	return filepath.Join(stateDirOf(directory), "data")
+}
+
+// LogsDirOf returns the folder with the logs of a Pi-Apps directory: its logs folder, or the one in UserStateDir
+// when this user can't write to it
+func LogsDirOf(directory string) string {
+	return filepath.Join(stateDirOf(directory), "logs")
+}
+
+// GetDataDir returns the data folder of the Pi-Apps directory, see DataDirOf
+func GetDataDir() string {
+	return DataDirOf(GetPiAppsDir())
+}
+
+// GetLogsDir returns the logs folder of the Pi-Apps directory, see LogsDirOf
+func GetLogsDir() string {
+	return LogsDirOf(GetPiAppsDir())
+}
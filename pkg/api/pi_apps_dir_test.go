@@ -0,0 +1,281 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+// makePiAppsDir creates a minimal valid Pi-Apps directory at path
+func makePiAppsDir(t *testing.T, path string) string {
+	t.Helper()
+	for _, dir := range []string{"apps", "data", "etc"} {
+		if err := os.MkdirAll(filepath.Join(path, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeTestFile(t, filepath.Join(path, "api"), "#!/bin/bash\n")
+	writeTestFile(t, filepath.Join(path, "gui"), "#!/bin/bash\n")
+	return path
+}
+
+// isolatePiAppsDir gives the test its own home directory, unsets the directory variables and the flag
+func isolatePiAppsDir(t *testing.T) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("PI_APPS_DIR", "")
+	t.Setenv("DIRECTORY", "")
+	piAppsDirFlag = ""
+	t.Cleanup(func() { piAppsDirFlag = "" })
+	return home
+}
+
+func TestValidatePiAppsDir(t *testing.T) {
+	home := isolatePiAppsDir(t)
+	valid := makePiAppsDir(t, filepath.Join(home, "pi-apps"))
+	if err := ValidatePiAppsDir(valid); err != nil {
+		t.Errorf("ValidatePiAppsDir(%s) = %v", valid, err)
+	}
+	// Subdirectories of user directories are fine, only the user directories themselves are not
+	if err := ValidatePiAppsDir(makePiAppsDir(t, filepath.Join(home, "Videos", "pi-apps"))); err != nil {
+		t.Errorf("ValidatePiAppsDir of ~/Videos/pi-apps = %v", err)
+	}
+
+	missingEtc := makePiAppsDir(t, filepath.Join(home, "no-etc"))
+	os.Remove(filepath.Join(missingEtc, "etc"))
+	missingGUI := makePiAppsDir(t, filepath.Join(home, "no-gui"))
+	os.Remove(filepath.Join(missingGUI, "gui"))
+
+	tests := map[string]string{
+		"":                     "no directory",
+		makePiAppsDir(t, home): "home directory",
+		makePiAppsDir(t, filepath.Join(home, "Downloads")): "Downloads",
+		filepath.Join(home, "missing"):                     "does not exist",
+		missingEtc:                                         "missing etc directory",
+		missingGUI:                                         "missing gui file",
+	}
+	for dir, want := range tests {
+		err := ValidatePiAppsDir(dir)
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("ValidatePiAppsDir(%q) = %v, want an error about %q", dir, err, want)
+		}
+	}
+}
+
+func TestResolvePiAppsDirPrecedence(t *testing.T) {
+	home := isolatePiAppsDir(t)
+	base := t.TempDir()
+	homeDir := makePiAppsDir(t, filepath.Join(home, "pi-apps"))
+	directoryDir := makePiAppsDir(t, filepath.Join(base, "directory"))
+	envDir := makePiAppsDir(t, filepath.Join(base, "env"))
+	flagDir := makePiAppsDir(t, filepath.Join(base, "flag"))
+
+	check := func(wantDir, wantSource string) {
+		t.Helper()
+		resolution := ResolvePiAppsDir()
+		if resolution.Dir != wantDir || resolution.Source != wantSource {
+			t.Errorf("ResolvePiAppsDir = %s from %q, want %s from %q", resolution.Dir, resolution.Source, wantDir, wantSource)
+		}
+		if GetPiAppsDir() != wantDir {
+			t.Errorf("GetPiAppsDir = %s, want %s", GetPiAppsDir(), wantDir)
+		}
+	}
+
+	check(homeDir, DirSourceHome)
+	t.Setenv("DIRECTORY", directoryDir)
+	check(directoryDir, DirSourceDirectory)
+	t.Setenv("PI_APPS_DIR", envDir)
+	check(envDir, DirSourceEnv)
+
+	// An invalid directory is skipped and the reason recorded
+	t.Setenv("PI_APPS_DIR", filepath.Join(base, "missing"))
+	check(directoryDir, DirSourceDirectory)
+	resolution := ResolvePiAppsDir()
+	if i := slices.IndexFunc(resolution.Candidates, func(c DirCandidate) bool { return c.Source == DirSourceEnv }); i < 0 || resolution.Candidates[i].Problem != "does not exist" {
+		t.Errorf("candidates = %+v, want PI_APPS_DIR recorded as missing", resolution.Candidates)
+	}
+
+	SetPiAppsDir(flagDir)
+	check(flagDir, DirSourceFlag)
+	if os.Getenv("PI_APPS_DIR") != flagDir {
+		t.Errorf("SetPiAppsDir didn't export PI_APPS_DIR, it is %s", os.Getenv("PI_APPS_DIR"))
+	}
+	// An invalid flag is ignored
+	SetPiAppsDir(filepath.Join(base, "missing"))
+	check(flagDir, DirSourceFlag)
+}
+
+func TestResolvePiAppsDirFallback(t *testing.T) {
+	home := isolatePiAppsDir(t)
+	resolution := ResolvePiAppsDir()
+	if resolution.Dir != filepath.Join(home, "pi-apps") || resolution.Source != "" {
+		t.Errorf("ResolvePiAppsDir without any Pi-Apps = %s from %q, want the default ~/pi-apps", resolution.Dir, resolution.Source)
+	}
+	// A failed resolution isn't cached
+	makePiAppsDir(t, filepath.Join(home, "pi-apps-go"))
+	if resolution := ResolvePiAppsDir(); resolution.Dir != filepath.Join(home, "pi-apps-go") || resolution.Source != DirSourceHome {
+		t.Errorf("ResolvePiAppsDir = %s from %q, want ~/pi-apps-go", resolution.Dir, resolution.Source)
+	}
+}
+
+func TestResolvePiAppsDirDuplicates(t *testing.T) {
+	home := isolatePiAppsDir(t)
+	primary := makePiAppsDir(t, filepath.Join(home, "pi-apps"))
+
+	// The same directory reached twice is not a duplicate
+	if err := os.Symlink(primary, filepath.Join(home, "pi-apps-go")); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PI_APPS_DIR", primary)
+	if resolution := ResolvePiAppsDir(); len(resolution.Duplicates) > 0 {
+		t.Errorf("Duplicates = %q, want none", resolution.Duplicates)
+	}
+
+	// A second clone is
+	os.Remove(filepath.Join(home, "pi-apps-go"))
+	clone := makePiAppsDir(t, filepath.Join(home, "pi-apps-go"))
+	t.Setenv("PI_APPS_DIR", "")
+	resolution := ResolvePiAppsDir()
+	if resolution.Dir != primary || !slices.Equal(resolution.Duplicates, []string{clone}) {
+		t.Errorf("ResolvePiAppsDir = %s with duplicates %q, want %s with %s", resolution.Dir, resolution.Duplicates, primary, clone)
+	}
+}
+
+func TestResolvePiAppsDirCache(t *testing.T) {
+	home := isolatePiAppsDir(t)
+	dir := makePiAppsDir(t, filepath.Join(home, "pi-apps"))
+	if GetPiAppsDir() != dir {
+		t.Fatalf("GetPiAppsDir = %s, want %s", GetPiAppsDir(), dir)
+	}
+
+	// The cached directory is used while the environment stays the same
+	os.RemoveAll(filepath.Join(dir, "etc"))
+	if GetPiAppsDir() != dir {
+		t.Errorf("GetPiAppsDir = %s, want the cached %s", GetPiAppsDir(), dir)
+	}
+
+	// and resolved again when it changes
+	other := makePiAppsDir(t, filepath.Join(t.TempDir(), "pi-apps"))
+	t.Setenv("DIRECTORY", other)
+	if GetPiAppsDir() != other {
+		t.Errorf("GetPiAppsDir after changing DIRECTORY = %s, want %s", GetPiAppsDir(), other)
+	}
+}
+
+// newReadOnlyPiAppsDir returns a Pi-Apps directory shared by the users of the system, which this user can't write
+// to, and gives the test its own XDG_DATA_HOME. Root can write anywhere, so the permissions are checked by mode.
+func newReadOnlyPiAppsDir(t *testing.T, apps ...string) string {
+	t.Helper()
+	directory := newTestPiAppsDir(t, apps...)
+	writeTestFile(t, filepath.Join(directory, "data", "status", "Shared App"), "installed")
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	readOnly := []string{directory, filepath.Join(directory, "data"), filepath.Join(directory, "data", "status"), filepath.Join(directory, "logs")}
+	for _, dir := range readOnly {
+		if err := os.Chmod(dir, 0555); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Cleanup(func() {
+		for _, dir := range readOnly {
+			os.Chmod(dir, 0755)
+		}
+	})
+
+	original := checkWritable
+	checkWritable = func(path string) error {
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		if info.Mode().Perm()&0222 == 0 {
+			return syscall.EACCES
+		}
+		return nil
+	}
+	t.Cleanup(func() { checkWritable = original })
+	return directory
+}
+
+func TestReadOnlyPiAppsDir(t *testing.T) {
+	directory := newReadOnlyPiAppsDir(t, "Zoom")
+	state := filepath.Join(os.Getenv("XDG_DATA_HOME"), "pi-apps")
+
+	if !IsSplitDirMode() {
+		t.Fatal("IsSplitDirMode = false for a read-only Pi-Apps directory")
+	}
+	if got := GetDataDir(); got != filepath.Join(state, "data") {
+		t.Errorf("GetDataDir = %s, want %s", got, filepath.Join(state, "data"))
+	}
+	if got := GetLogsDir(); got != filepath.Join(state, "logs") {
+		t.Errorf("GetLogsDir = %s, want %s", got, filepath.Join(state, "logs"))
+	}
+	// Checking the directory doesn't create anything, setting it up does
+	if DirExists(state) {
+		t.Errorf("IsSplitDirMode created %s", state)
+	}
+	if err := initUserStateDir(directory); err != nil {
+		t.Fatalf("initUserStateDir = %v", err)
+	}
+	for _, dir := range []string{"data/status", "data/settings", "logs"} {
+		if !DirExists(filepath.Join(state, dir)) {
+			t.Errorf("%s was not created in %s", dir, state)
+		}
+	}
+
+	// Installing an app for this user records it in the home folder, the shared statuses are not this user's
+	if err := SetAppStatus("Zoom", "installed"); err != nil {
+		t.Fatalf("SetAppStatus = %v", err)
+	}
+	if !FileExists(filepath.Join(state, "data", "status", "Zoom")) {
+		t.Error("the status of Zoom was not written to the data directory of the user")
+	}
+	if FileExists(filepath.Join(directory, "data", "status", "Zoom")) {
+		t.Error("the status of Zoom was written to the read-only Pi-Apps directory")
+	}
+	if status, err := GetAppStatus("Zoom"); err != nil || status != "installed" {
+		t.Errorf("GetAppStatus(Zoom) = %q, %v, want installed", status, err)
+	}
+	if status, _ := GetAppStatus("Shared App"); status == "installed" {
+		t.Error("GetAppStatus read the status of an app from the read-only Pi-Apps directory")
+	}
+}
+
+func TestWritablePiAppsDirKeepsItsData(t *testing.T) {
+	directory := newTestPiAppsDir(t)
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	if IsSplitDirMode() {
+		t.Error("IsSplitDirMode = true for a writable Pi-Apps directory")
+	}
+	if got := GetDataDir(); got != filepath.Join(directory, "data") {
+		t.Errorf("GetDataDir = %s, want %s", got, filepath.Join(directory, "data"))
+	}
+	if got := LogsDirOf(directory); got != filepath.Join(directory, "logs") {
+		t.Errorf("LogsDirOf = %s, want %s", got, filepath.Join(directory, "logs"))
+	}
+	// A directory that doesn't exist yet is not shared
+	missing := filepath.Join(t.TempDir(), "pi-apps")
+	if IsReadOnlyPiAppsDir(missing) {
+		t.Errorf("IsReadOnlyPiAppsDir(%s) = true for a directory that doesn't exist", missing)
+	}
+}
@@ -0,0 +1,242 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: process.go
+// Description: Inspects running processes through /proc and keeps PID files that survive PID reuse.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package api
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// procDir is where the process information is read from
+var procDir = "/proc"
+
+// ProcInfo is the information about a running process read from /proc
+type ProcInfo struct {
+	PID       int
+	Name      string   // name of the executable, truncated to 15 characters by the kernel
+	Cmdline   []string // command line arguments, empty for kernel threads
+	UID       int      // real user ID
+	State     string   // R (running), S (sleeping), Z (zombie), ...
+	StartTime uint64   // start time in clock ticks since boot, it tells a process apart from a later one with the same PID
+	ParentPID int
+}
+
+// ProcessInfo reads the information about a process from /proc.
+// Thread IDs are rejected, /proc has entries for them too but they aren't processes.
+func ProcessInfo(pid int) (*ProcInfo, error) {
+	if pid <= 0 {
+		return nil, fmt.Errorf("invalid PID %d", pid)
+	}
+	dir := filepath.Join(procDir, strconv.Itoa(pid))
+
+	stat, err := os.ReadFile(filepath.Join(dir, "stat"))
+	if err != nil {
+		return nil, err
+	}
+	// The name is in parentheses and may contain spaces and parentheses itself, so the fields after it
+	// start after the last closing parenthesis
+	open := strings.IndexByte(string(stat), '(')
+	end := strings.LastIndexByte(string(stat), ')')
+	if open == -1 || end < open {
+		return nil, fmt.Errorf("malformed %s", filepath.Join(dir, "stat"))
+	}
+	fields := strings.Fields(string(stat[end+1:]))
+	if len(fields) < 20 {
+		return nil, fmt.Errorf("malformed %s", filepath.Join(dir, "stat"))
+	}
+	info := &ProcInfo{PID: pid, Name: string(stat[open+1 : end]), State: fields[0]}
+	if info.ParentPID, err = strconv.Atoi(fields[1]); err != nil {
+		return nil, fmt.Errorf("malformed %s: %w", filepath.Join(dir, "stat"), err)
+	}
+	if info.StartTime, err = strconv.ParseUint(fields[19], 10, 64); err != nil {
+		return nil, fmt.Errorf("malformed %s: %w", filepath.Join(dir, "stat"), err)
+	}
+
+	status, err := os.Open(filepath.Join(dir, "status"))
+	if err != nil {
+		return nil, err
+	}
+	defer status.Close()
+	scanner := bufio.NewScanner(status)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		values := strings.Fields(value)
+		if len(values) == 0 {
+			continue
+		}
+		switch key {
+		case "Tgid":
+			if values[0] != strconv.Itoa(pid) {
+				return nil, fmt.Errorf("%d is a thread of process %s", pid, values[0])
+			}
+		case "Uid":
+			info.UID, _ = strconv.Atoi(values[0])
+		}
+	}
+
+	if cmdline, err := os.ReadFile(filepath.Join(dir, "cmdline")); err == nil {
+		if trimmed := strings.TrimRight(string(cmdline), "\x00"); trimmed != "" {
+			info.Cmdline = strings.Split(trimmed, "\x00")
+		}
+	}
+	return info, nil
+}
+
+// running reports whether the process is still running, zombies have exited and only wait to be reaped
+func (p *ProcInfo) running() bool {
+	return p.State != "Z" && p.State != "X"
+}
+
+// HasName reports whether the process runs a program called name, compared with the kernel's name of the
+// process and the program in its command line, so names longer than 15 characters and scripts match too
+func (p *ProcInfo) HasName(name string) bool {
+	if p.Name == name {
+		return true
+	}
+	// The kernel's name is truncated, only trust a truncated match when there is no command line to check
+	if len(name) > 15 && p.Name == name[:15] && len(p.Cmdline) == 0 {
+		return true
+	}
+	for i, arg := range p.Cmdline {
+		// Interpreters run scripts as their first argument
+		if i > 1 {
+			break
+		}
+		if filepath.Base(arg) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ProcessExists checks if a process with the given PID is running
+func ProcessExists(pid int) bool {
+	info, err := ProcessInfo(pid)
+	return err == nil && info.running()
+}
+
+// ProcessExistsWithName checks if a process with the given PID is running and is the named program,
+// so a PID that was reused by an unrelated process doesn't count
+func ProcessExistsWithName(pid int, name string) bool {
+	info, err := ProcessInfo(pid)
+	return err == nil && info.running() && info.HasName(name)
+}
+
+// FindProcesses returns the running processes match accepts, sorted by PID
+func FindProcesses(match func(*ProcInfo) bool) ([]*ProcInfo, error) {
+	entries, err := os.ReadDir(procDir)
+	if err != nil {
+		return nil, err
+	}
+	var processes []*ProcInfo
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+		// Processes can exit while /proc is scanned
+		info, err := ProcessInfo(pid)
+		if err != nil || !info.running() {
+			continue
+		}
+		if match(info) {
+			processes = append(processes, info)
+		}
+	}
+	return processes, nil
+}
+
+// WritePIDFile writes a PID file for a process, together with the start time of the process so
+// PIDFileRunning can tell the process apart from a later one that got the same PID
+func WritePIDFile(path string, pid int) error {
+	content := strconv.Itoa(pid)
+	if info, err := ProcessInfo(pid); err == nil {
+		content += " " + strconv.FormatUint(info.StartTime, 10)
+	}
+	return os.WriteFile(path, []byte(content+"\n"), 0644)
+}
+
+// ReadPIDFile reads a PID file written by WritePIDFile. PID files that only hold a PID, like the ones written by
+// shell scripts, give a start time of 0.
+func ReadPIDFile(path string) (pid int, startTime uint64, err error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	fields := strings.Fields(string(content))
+	if len(fields) == 0 {
+		return 0, 0, fmt.Errorf("%s is empty", path)
+	}
+	if pid, err = strconv.Atoi(fields[0]); err != nil {
+		return 0, 0, fmt.Errorf("invalid PID in %s: %w", path, err)
+	}
+	if len(fields) > 1 {
+		if startTime, err = strconv.ParseUint(fields[1], 10, 64); err != nil {
+			return 0, 0, fmt.Errorf("invalid start time in %s: %w", path, err)
+		}
+	}
+	return pid, startTime, nil
+}
+
+// PIDFileRunning reports whether the process of a PID file is still running. If the PID file recorded the start
+// time of the process, a process that got the same PID after a reboot or PID wrap-around doesn't count.
+func PIDFileRunning(path string) bool {
+	pid, startTime, err := ReadPIDFile(path)
+	if err != nil {
+		return false
+	}
+	info, err := ProcessInfo(pid)
+	if err != nil || !info.running() {
+		return false
+	}
+	return startTime == 0 || info.StartTime == startTime
+}
+
+// lockHolders describes the running processes with one of the given names, like "apt-get (1234), dpkg (1240)",
+// to tell the user what a package manager lock is waiting for
+func lockHolders(names ...string) string {
+	processes, err := FindProcesses(func(info *ProcInfo) bool {
+		for _, name := range names {
+			if info.HasName(name) {
+				return true
+			}
+		}
+		return false
+	})
+	if err != nil {
+		return ""
+	}
+	var holders []string
+	for _, process := range processes {
+		if process.PID == os.Getpid() {
+			continue
+		}
+		holders = append(holders, fmt.Sprintf("%s (%d)", process.Name, process.PID))
+	}
+	return strings.Join(holders, ", ")
+}
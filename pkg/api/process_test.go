@@ -0,0 +1,189 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// fakeProcess is a process of a fake /proc tree
+type fakeProcess struct {
+	pid       int
+	name      string
+	state     string
+	ppid      int
+	startTime uint64
+	uid       int
+	tgid      int // thread group ID, the PID for processes
+	cmdline   []string
+}
+
+// newFakeProc points procDir at a fake /proc tree with the given processes
+func newFakeProc(t *testing.T, processes ...fakeProcess) {
+	t.Helper()
+	dir := t.TempDir()
+	previous := procDir
+	procDir = dir
+	t.Cleanup(func() { procDir = previous })
+
+	for _, p := range processes {
+		if p.tgid == 0 {
+			p.tgid = p.pid
+		}
+		pidDir := filepath.Join(dir, fmt.Sprint(p.pid))
+		// pid (comm) state ppid pgrp session tty_nr tpgid flags minflt cminflt majflt cmajflt utime stime cutime cstime
+		// priority nice num_threads itrealvalue starttime ...
+		writeTestFile(t, filepath.Join(pidDir, "stat"), fmt.Sprintf("%d (%s) %s %d %d %d 0 -1 4194560 100 0 0 0 1 2 0 0 20 0 1 0 %d 1000 200\n",
+			p.pid, p.name, p.state, p.ppid, p.pid, p.pid, p.startTime))
+		writeTestFile(t, filepath.Join(pidDir, "status"), fmt.Sprintf("Name:\t%s\nState:\t%s\nTgid:\t%d\nPid:\t%d\nPPid:\t%d\nUid:\t%d\t%d\t%d\t%d\n",
+			p.name, p.state, p.tgid, p.pid, p.ppid, p.uid, p.uid, p.uid, p.uid))
+		cmdline := strings.Join(p.cmdline, "\x00")
+		if cmdline != "" {
+			cmdline += "\x00"
+		}
+		writeTestFile(t, filepath.Join(pidDir, "cmdline"), cmdline)
+	}
+	writeTestFile(t, filepath.Join(dir, "self", "stat"), "not a process directory")
+}
+
+func testProcesses() []fakeProcess {
+	return []fakeProcess{
+		{pid: 1, name: "systemd", state: "S", startTime: 1, cmdline: []string{"/sbin/init"}},
+		{pid: 100, name: "apt-get", state: "S", ppid: 1, startTime: 5000, cmdline: []string{"apt-get", "install", "-y", "vlc"}},
+		{pid: 101, name: "dpkg", state: "R", ppid: 100, startTime: 5100, cmdline: []string{"/usr/bin/dpkg", "--configure", "-a"}},
+		{pid: 200, name: "bash", state: "S", ppid: 1, startTime: 6000, uid: 1000, cmdline: []string{"/bin/bash", "/home/pi/pi-apps/updater", "onboot"}},
+		{pid: 300, name: "multi-call-pi-a", state: "S", ppid: 1, startTime: 7000, uid: 1000, cmdline: []string{"/usr/bin/multi-call-pi-apps", "gui"}},
+		{pid: 400, name: "weird ) name (", state: "S", ppid: 1, startTime: 8000},
+		{pid: 500, name: "apt-get", state: "Z", ppid: 1, startTime: 9000},
+		{pid: 301, name: "multi-call-pi-a", state: "S", ppid: 1, startTime: 7000, tgid: 300},
+	}
+}
+
+func TestProcessInfo(t *testing.T) {
+	newFakeProc(t, testProcesses()...)
+
+	info, err := ProcessInfo(101)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := ProcInfo{PID: 101, Name: "dpkg", Cmdline: []string{"/usr/bin/dpkg", "--configure", "-a"}, State: "R", StartTime: 5100, ParentPID: 100}
+	if info.PID != want.PID || info.Name != want.Name || !slices.Equal(info.Cmdline, want.Cmdline) || info.State != want.State ||
+		info.StartTime != want.StartTime || info.ParentPID != want.ParentPID || info.UID != 0 {
+		t.Errorf("ProcessInfo(101) = %+v, want %+v", info, want)
+	}
+
+	if info, err := ProcessInfo(200); err != nil || info.UID != 1000 {
+		t.Errorf("ProcessInfo(200) = %+v, %v, want UID 1000", info, err)
+	}
+	if info, err := ProcessInfo(400); err != nil || info.Name != "weird ) name (" || info.StartTime != 8000 {
+		t.Errorf("ProcessInfo(400) = %+v, %v, want the name with parentheses", info, err)
+	}
+	for _, pid := range []int{0, -1, 999, 301} {
+		if info, err := ProcessInfo(pid); err == nil {
+			t.Errorf("ProcessInfo(%d) = %+v, want an error", pid, info)
+		}
+	}
+}
+
+func TestProcessExistsWithName(t *testing.T) {
+	newFakeProc(t, testProcesses()...)
+
+	tests := []struct {
+		pid  int
+		name string
+		want bool
+	}{
+		{100, "apt-get", true},
+		{101, "dpkg", true},
+		{101, "apt-get", false},
+		{200, "updater", true},                 // script run by an interpreter
+		{200, "bash", true},                    // the interpreter itself
+		{300, "multi-call-pi-apps", true},      // longer than the 15 characters the kernel keeps
+		{500, "apt-get", false},                // zombie
+		{999, "apt-get", false},                // no such process
+		{301, "multi-call-pi-apps", false},     // thread
+		{300, "multi-call-pi-apps-old", false}, // same first 15 characters, different command
+	}
+	for _, tt := range tests {
+		if got := ProcessExistsWithName(tt.pid, tt.name); got != tt.want {
+			t.Errorf("ProcessExistsWithName(%d, %q) = %v, want %v", tt.pid, tt.name, got, tt.want)
+		}
+	}
+	if !ProcessExists(100) || ProcessExists(500) || ProcessExists(999) {
+		t.Error("ProcessExists does not match the fake /proc tree")
+	}
+}
+
+func TestFindProcesses(t *testing.T) {
+	newFakeProc(t, testProcesses()...)
+
+	processes, err := FindProcesses(func(info *ProcInfo) bool { return info.HasName("apt-get") || info.HasName("dpkg") })
+	if err != nil {
+		t.Fatal(err)
+	}
+	var pids []int
+	for _, process := range processes {
+		pids = append(pids, process.PID)
+	}
+	if !slices.Equal(pids, []int{100, 101}) {
+		t.Errorf("FindProcesses = %v, want [100 101] without the zombie", pids)
+	}
+
+	if holders := lockHolders("apt-get", "dpkg"); holders != "apt-get (100), dpkg (101)" {
+		t.Errorf("lockHolders = %q", holders)
+	}
+}
+
+func TestPIDFile(t *testing.T) {
+	newFakeProc(t, testProcesses()...)
+	path := filepath.Join(t.TempDir(), "daemon.pid")
+
+	if err := WritePIDFile(path, 200); err != nil {
+		t.Fatal(err)
+	}
+	if pid, startTime, err := ReadPIDFile(path); err != nil || pid != 200 || startTime != 6000 {
+		t.Fatalf("ReadPIDFile = %d, %d, %v, want 200 started at 6000", pid, startTime, err)
+	}
+	if !PIDFileRunning(path) {
+		t.Error("PIDFileRunning = false for a running process")
+	}
+
+	// The PID was reused by a process that started later
+	newFakeProc(t, fakeProcess{pid: 200, name: "firefox", state: "S", startTime: 9999})
+	if PIDFileRunning(path) {
+		t.Error("PIDFileRunning = true for a reused PID")
+	}
+
+	// PID files of shell scripts only hold the PID
+	writeTestFile(t, path, "200\n")
+	if !PIDFileRunning(path) {
+		t.Error("PIDFileRunning = false for a PID file without a start time")
+	}
+	for _, content := range []string{"", "abc\n", "200 abc\n"} {
+		writeTestFile(t, path, content)
+		if _, _, err := ReadPIDFile(path); err == nil {
+			t.Errorf("ReadPIDFile accepted %q", content)
+		}
+		if PIDFileRunning(path) {
+			t.Errorf("PIDFileRunning = true for %q", content)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: queue_phase.go
+// Description: Reports what a running app script is doing, like downloading or installing packages, to the manage daemon.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package api
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// PhaseFileEnv names the file the phase of the running queue item is reported to. The manage daemon sets it, so the
+// app script and the programs it starts, like api-go install_packages, all report to the same file.
+const PhaseFileEnv = "PI_APPS_PHASE_FILE"
+
+// Phases of a running queue item
+const (
+	PhaseRunningScript      = "running-script"
+	PhaseDownloading        = "downloading"
+	PhaseInstallingPackages = "installing-packages"
+)
+
+// QueuePhase is what a running queue item is doing
+type QueuePhase struct {
+	Name    string // one of the Phase constants
+	Percent int    // how much of the phase is done, -1 when unknown
+}
+
+// phaseMutex serializes the writes of the phase file within the process, a download reports from its copy loop
+var phaseMutex sync.Mutex
+
+// ReportPhase writes the phase to the file named by PhaseFileEnv, doing nothing outside of the manage daemon.
+// Reporting the same phase again doesn't write the file.
+func ReportPhase(name string, percent int) {
+	path := os.Getenv(PhaseFileEnv)
+	if path == "" {
+		return
+	}
+	phaseMutex.Lock()
+	defer phaseMutex.Unlock()
+	phase := QueuePhase{Name: name, Percent: percent}
+	if current, ok := ReadPhase(path); ok && current == phase {
+		return
+	}
+	if err := WriteFileAtomic(path, []byte(fmt.Sprintf("%s %d\n", name, percent)), 0644); err != nil {
+		Debug("Failed to report the phase: " + err.Error())
+	}
+}
+
+// ReadPhase reads the phase file written by ReportPhase
+func ReadPhase(path string) (QueuePhase, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return QueuePhase{}, false
+	}
+	name, percent, ok := strings.Cut(strings.TrimSpace(string(data)), " ")
+	if !ok || name == "" {
+		return QueuePhase{}, false
+	}
+	value, err := strconv.Atoi(percent)
+	if err != nil || value > 100 {
+		return QueuePhase{}, false
+	}
+	return QueuePhase{Name: name, Percent: max(value, -1)}, true
+}
+
+// WatchPhase calls onChange every time the phase file at path changes, until the returned function is called
+func WatchPhase(path string, interval time.Duration, onChange func(QueuePhase)) func() {
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		var last QueuePhase
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				if phase, ok := ReadPhase(path); ok && phase != last {
+					last = phase
+					onChange(phase)
+				}
+			}
+		}
+	}()
+	return func() {
+		close(stop)
+		<-done
+	}
+}
+
+// startPhase reports a phase and returns a function reporting the phase before it again, so a download in an app
+// script goes back to "running script" once it is done
+func startPhase(name string, percent int) func() {
+	path := os.Getenv(PhaseFileEnv)
+	if path == "" {
+		return func() {}
+	}
+	previous, ok := ReadPhase(path)
+	ReportPhase(name, percent)
+	return func() {
+		if ok {
+			ReportPhase(previous.Name, previous.Percent)
+		}
+	}
+}
+
+// PhaseText describes a phase, like "Downloading 45%"
+func PhaseText(phase QueuePhase) string {
+	var text string
+	switch phase.Name {
+	case PhaseDownloading:
+		text = T("Downloading")
+	case PhaseInstallingPackages:
+		text = T("Installing packages")
+	case PhaseRunningScript:
+		text = T("Running script")
+	default:
+		return ""
+	}
+	if phase.Percent >= 0 {
+		text += fmt.Sprintf(" %d%%", phase.Percent)
+	}
+	return text
+}
+
+// phaseWriter reports the percentage of a download as the downloading phase every time it changes
+type phaseWriter struct {
+	total   int64
+	written int64
+	percent int
+}
+
+// startDownloadPhase reports a download of total bytes, 0 or less when the size is unknown. It returns the writer
+// the download is copied to as well, reporting its percentage, and the function ending the phase.
+func startDownloadPhase(total int64) (io.Writer, func()) {
+	if os.Getenv(PhaseFileEnv) == "" {
+		return io.Discard, func() {}
+	}
+	if total <= 0 {
+		return io.Discard, startPhase(PhaseDownloading, -1)
+	}
+	return &phaseWriter{total: total, percent: -1}, startPhase(PhaseDownloading, 0)
+}
+
+// Write implements io.Writer
+func (w *phaseWriter) Write(p []byte) (int, error) {
+	w.written += int64(len(p))
+	if percent := int(min(w.written*100/w.total, 100)); percent != w.percent {
+		w.percent = percent
+		ReportPhase(PhaseDownloading, percent)
+	}
+	return len(p), nil
+}
@@ -0,0 +1,84 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestReportPhase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "phase")
+
+	// Outside of the manage daemon nothing is reported
+	t.Setenv(PhaseFileEnv, "")
+	ReportPhase(PhaseRunningScript, -1)
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("a phase was reported without %s", PhaseFileEnv)
+	}
+
+	t.Setenv(PhaseFileEnv, path)
+	ReportPhase(PhaseRunningScript, -1)
+	if phase, ok := ReadPhase(path); !ok || phase != (QueuePhase{PhaseRunningScript, -1}) {
+		t.Errorf("ReadPhase = %+v, %t", phase, ok)
+	}
+
+	// A download reports its percentage and goes back to the phase before it
+	var mutex sync.Mutex
+	var seen []QueuePhase
+	stop := WatchPhase(path, time.Millisecond, func(phase QueuePhase) {
+		mutex.Lock()
+		seen = append(seen, phase)
+		mutex.Unlock()
+	})
+	writer, endPhase := startDownloadPhase(400)
+	for range 4 {
+		writer.Write([]byte(strings.Repeat("x", 100)))
+		time.Sleep(20 * time.Millisecond)
+	}
+	if phase, _ := ReadPhase(path); phase != (QueuePhase{PhaseDownloading, 100}) {
+		t.Errorf("phase at the end of the download = %+v", phase)
+	}
+	endPhase()
+	time.Sleep(20 * time.Millisecond)
+	stop()
+	if phase, _ := ReadPhase(path); phase != (QueuePhase{PhaseRunningScript, -1}) {
+		t.Errorf("phase after the download = %+v, want the script running again", phase)
+	}
+	mutex.Lock()
+	defer mutex.Unlock()
+	if len(seen) < 3 || seen[len(seen)-1].Name != PhaseRunningScript {
+		t.Errorf("watched phases = %+v", seen)
+	}
+
+	for phase, want := range map[QueuePhase]string{
+		{PhaseDownloading, 45}:         "Downloading 45%",
+		{PhaseInstallingPackages, -1}:  "Installing packages",
+		{PhaseRunningScript, -1}:       "Running script",
+		{"compiling", 10}:              "",
+		{PhaseDownloading, -1}:         "Downloading",
+		{PhaseInstallingPackages, 100}: "Installing packages 100%",
+	} {
+		if got := PhaseText(phase); got != want {
+			t.Errorf("PhaseText(%+v) = %q, want %q", phase, got, want)
+		}
+	}
+}
@@ -0,0 +1,550 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: remote_host.go
+// Description: Connects to the Pi-Apps of another machine over SSH and mirrors its app catalog, for the remote mode of the GUI.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// remoteConnectTimeout is how long connecting to a remote Pi-Apps may take, including the SSH login
+	remoteConnectTimeout = 60 * time.Second
+	// remoteRequestTimeout is how long a request to a remote Pi-Apps may take
+	remoteRequestTimeout = 30 * time.Second
+	// remoteIconSizes are the icon sizes mirrored from a remote Pi-Apps
+	remoteIconSizes = "24 64"
+)
+
+// remoteMirrorSkip are the entries of the local Pi-Apps folder that are not linked into a mirror,
+// the mirror has its own
+var remoteMirrorSkip = []string{"apps", "data", "logs", "update", ".git"}
+
+// remoteMirrorSharedData are the entries of the local data folder linked into a mirror, so the GUI looks the same
+var remoteMirrorSharedData = []string{"settings"}
+
+// RemoteTarget is the Pi-Apps of another machine, reached over SSH as user@host[:path]
+type RemoteTarget struct {
+	Destination string // user@host or a host alias of ~/.ssh/config, as passed to ssh
+	Path        string // Pi-Apps folder on the remote machine, relative to the home folder unless absolute
+}
+
+// ParseRemoteTarget parses user@host[:path], the path defaults to pi-apps in the remote home folder
+func ParseRemoteTarget(target string) (RemoteTarget, error) {
+	destination, path, _ := strings.Cut(strings.TrimSpace(target), ":")
+	if destination == "" || strings.HasPrefix(destination, "-") || strings.ContainsAny(destination, " \t\n'\";&|$`") {
+		return RemoteTarget{}, fmt.Errorf("invalid remote %q, expected user@host[:path]", target)
+	}
+	if user, host, ok := strings.Cut(destination, "@"); ok && (user == "" || host == "") {
+		return RemoteTarget{}, fmt.Errorf("invalid remote %q, expected user@host[:path]", target)
+	}
+	if strings.ContainsAny(path, "\n\x00") {
+		return RemoteTarget{}, fmt.Errorf("invalid path in remote %q", target)
+	}
+	if path == "" {
+		path = "pi-apps"
+	}
+	return RemoteTarget{Destination: destination, Path: path}, nil
+}
+
+// String returns the target as user@host:path
+func (t RemoteTarget) String() string {
+	return t.Destination + ":" + t.Path
+}
+
+// RemoteMirrorDir returns the local folder the catalog of a remote Pi-Apps is mirrored to
+func RemoteMirrorDir(target RemoteTarget) (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	name := strings.Map(func(r rune) rune {
+		if r == '/' || r == ':' || r == '~' {
+			return '_'
+		}
+		return r
+	}, target.String())
+	return filepath.Join(cacheDir, "pi-apps", "remote", name), nil
+}
+
+// RemoteSession is a connection to the Pi-Apps of another machine. The catalog server of the remote Pi-Apps
+// (api serve) is started over SSH and reached through a forwarded port, actions are passed on to the manage
+// daemon of the remote machine. The remote machine only needs api-go, a nogui build is enough.
+type RemoteSession struct {
+	Target   RemoteTarget
+	localDir string // local Pi-Apps folder, whose queue must not run at the same time
+	token    string
+	client   *http.Client
+
+	mu      sync.Mutex
+	tunnel  *exec.Cmd
+	baseURL string
+	exited  chan struct{} // closed when the tunnel exits
+}
+
+// ConnectRemote connects to a remote Pi-Apps. localDir is the local Pi-Apps folder.
+func ConnectRemote(target RemoteTarget, localDir string) (*RemoteSession, error) {
+	token, err := newCatalogToken()
+	if err != nil {
+		return nil, err
+	}
+	s := &RemoteSession{
+		Target:   target,
+		localDir: localDir,
+		token:    token,
+		client:   &http.Client{Timeout: remoteRequestTimeout},
+	}
+	if err := s.connect(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// connect starts the catalog server on the remote machine through an SSH tunnel and waits until it answers
+func (s *RemoteSession) connect() error {
+	localPort, err := freeLocalPort()
+	if err != nil {
+		return fmt.Errorf("failed to find a free local port: %w", err)
+	}
+	remotePort := 20000 + rand.IntN(40000)
+
+	// The path is quoted, but ~ has to stay outside the quotes to be expanded
+	dir := ShellQuote(s.Target.Path)
+	if rest, ok := strings.CutPrefix(s.Target.Path, "~/"); ok {
+		dir = `"$HOME"/` + ShellQuote(rest)
+	}
+	remoteCommand := fmt.Sprintf("cd %s && PI_APPS_DIR=\"$PWD\" exec ./api-go serve --addr 127.0.0.1:%d --allow-actions --token-stdin", dir, remotePort)
+
+	cmd := exec.Command("ssh",
+		"-o", "ExitOnForwardFailure=yes",
+		"-o", "ServerAliveInterval=15",
+		"-o", "ServerAliveCountMax=2",
+		"-L", fmt.Sprintf("127.0.0.1:%d:127.0.0.1:%d", localPort, remotePort),
+		s.Target.Destination, remoteCommand)
+	var stderr lockedBuffer
+	cmd.Stderr = &stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to run ssh: %w", err)
+	}
+	// The token goes through the encrypted connection instead of the command line, which other users can see
+	io.WriteString(stdin, s.token+"\n")
+	stdin.Close()
+
+	exited := make(chan struct{})
+	go func() {
+		cmd.Wait()
+		close(exited)
+	}()
+
+	s.mu.Lock()
+	s.tunnel = cmd
+	s.baseURL = fmt.Sprintf("http://127.0.0.1:%d", localPort)
+	s.exited = exited
+	s.mu.Unlock()
+
+	deadline := time.Now().Add(remoteConnectTimeout)
+	for {
+		select {
+		case <-exited:
+			message := strings.TrimSpace(stderr.String())
+			if message == "" {
+				message = T("ssh exited")
+			}
+			return fmt.Errorf("failed to connect to %s: %s", s.Target, lastLines(message, 5))
+		case <-time.After(500 * time.Millisecond):
+		}
+		if err := s.Ping(); err == nil {
+			return nil
+		} else if time.Now().After(deadline) {
+			s.Close()
+			return fmt.Errorf("failed to connect to %s: %w", s.Target, err)
+		}
+	}
+}
+
+// Reconnect closes the connection and connects again
+func (s *RemoteSession) Reconnect() error {
+	s.Close()
+	return s.connect()
+}
+
+// Connected reports whether the SSH tunnel is still running
+func (s *RemoteSession) Connected() bool {
+	s.mu.Lock()
+	exited := s.exited
+	s.mu.Unlock()
+	if exited == nil {
+		return false
+	}
+	select {
+	case <-exited:
+		return false
+	default:
+		return true
+	}
+}
+
+// Close stops the SSH tunnel, which stops the catalog server on the remote machine
+func (s *RemoteSession) Close() error {
+	s.mu.Lock()
+	tunnel, exited := s.tunnel, s.exited
+	s.tunnel = nil
+	s.mu.Unlock()
+	if tunnel == nil || tunnel.Process == nil {
+		return nil
+	}
+	tunnel.Process.Kill()
+	<-exited
+	return nil
+}
+
+// Ping checks that the remote catalog server answers
+func (s *RemoteSession) Ping() error {
+	resp, err := s.request("GET", "/categories", nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// Apps returns the metadata of the apps of the remote Pi-Apps. The icon paths are paths on the remote machine.
+func (s *RemoteSession) Apps() ([]*AppMetadata, error) {
+	var apps []*AppMetadata
+	return apps, s.getJSON("/apps", &apps)
+}
+
+// Statuses returns the status of every app of the remote Pi-Apps
+func (s *RemoteSession) Statuses() (map[string]AppState, error) {
+	var statuses map[string]AppState
+	return statuses, s.getJSON("/status", &statuses)
+}
+
+// Icon returns an icon of an app of the remote Pi-Apps
+func (s *RemoteSession) Icon(app string, size int) ([]byte, error) {
+	resp, err := s.request("GET", "/apps/"+app+"/icon?size="+strconv.Itoa(size), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return io.ReadAll(resp.Body)
+}
+
+// Queue adds an install or uninstall action to the queue of the remote manage daemon.
+// It is refused while the local queue is running, a queue only runs on one machine.
+func (s *RemoteSession) Queue(action, app string) error {
+	if action != "install" && action != "uninstall" {
+		return fmt.Errorf("action must be install or uninstall, not %s", action)
+	}
+	if s.localDir != "" && localQueueRunning(s.localDir) {
+		return fmt.Errorf("the local queue is still running, wait for it to finish before managing apps on %s", s.Target.Destination)
+	}
+	body, err := json.Marshal(QueueRequest{Action: action, App: app})
+	if err != nil {
+		return err
+	}
+	resp, err := s.request("POST", "/queue", body)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// request sends a request to the remote catalog server, failing on error responses
+func (s *RemoteSession) request(method, path string, body []byte) (*http.Response, error) {
+	s.mu.Lock()
+	baseURL := s.baseURL
+	s.mu.Unlock()
+	if baseURL == "" {
+		return nil, fmt.Errorf("not connected to %s", s.Target)
+	}
+
+	req, err := http.NewRequest(method, baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if method == "POST" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		var catalogError struct {
+			Error string `json:"error"`
+		}
+		if json.NewDecoder(io.LimitReader(resp.Body, 4096)).Decode(&catalogError) == nil && catalogError.Error != "" {
+			return nil, fmt.Errorf("%s: %s", s.Target.Destination, catalogError.Error)
+		}
+		return nil, fmt.Errorf("%s: %s %s returned status %d", s.Target.Destination, method, path, resp.StatusCode)
+	}
+	return resp, nil
+}
+
+// getJSON decodes the JSON response to a GET request
+func (s *RemoteSession) getJSON(path string, v any) error {
+	resp, err := s.request("GET", path, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// Mirror copies the catalog of the remote Pi-Apps into a local folder the GUI can run on, and returns it.
+// The files of the local Pi-Apps folder are linked into it, the apps and their statuses come from the
+// remote machine. App folders only hold the description, website, credits and icons, and an empty
+// marker file telling the app type.
+func (s *RemoteSession) Mirror() (string, error) {
+	mirrorDir, err := RemoteMirrorDir(s.Target)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Join(mirrorDir, "data", "status"), 0755); err != nil {
+		return "", err
+	}
+
+	// Link the programs, icons and settings of the local Pi-Apps
+	entries, err := os.ReadDir(s.localDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to read the local Pi-Apps folder: %w", err)
+	}
+	for _, entry := range entries {
+		if !slices.Contains(remoteMirrorSkip, entry.Name()) {
+			linkIntoMirror(filepath.Join(s.localDir, entry.Name()), filepath.Join(mirrorDir, entry.Name()))
+		}
+	}
+	for _, name := range remoteMirrorSharedData {
+		linkIntoMirror(filepath.Join(DataDirOf(s.localDir), name), filepath.Join(mirrorDir, "data", name))
+	}
+
+	apps, err := s.Apps()
+	if err != nil {
+		return "", fmt.Errorf("failed to get the apps of %s: %w", s.Target, err)
+	}
+
+	var overrides strings.Builder
+	overrides.WriteString("# Categories of the apps of " + s.Target.String() + "\n")
+	names := make([]string, 0, len(apps))
+	for _, metadata := range apps {
+		if ValidateAppName(metadata.Name) != nil {
+			continue
+		}
+		names = append(names, metadata.Name)
+		if err := s.mirrorApp(mirrorDir, metadata); err != nil {
+			return "", err
+		}
+		if metadata.Category != "" {
+			overrides.WriteString(metadata.Name + "|" + metadata.Category + "\n")
+		}
+	}
+	if err := os.WriteFile(filepath.Join(mirrorDir, "data", "category-overrides"), []byte(overrides.String()), 0644); err != nil {
+		return "", err
+	}
+
+	// Apps that were removed on the remote machine
+	if entries, err := os.ReadDir(filepath.Join(mirrorDir, "apps")); err == nil {
+		for _, entry := range entries {
+			if !slices.Contains(names, entry.Name()) {
+				os.RemoveAll(filepath.Join(mirrorDir, "apps", entry.Name()))
+				os.Remove(filepath.Join(mirrorDir, "data", "status", entry.Name()))
+			}
+		}
+	}
+
+	statuses := make(map[string]AppState, len(apps))
+	for _, metadata := range apps {
+		statuses[metadata.Name] = AppState(metadata.Status)
+	}
+	if err := WriteMirrorStatuses(mirrorDir, statuses); err != nil {
+		return "", err
+	}
+	return mirrorDir, nil
+}
+
+// mirrorApp writes the app folder of an app of the remote Pi-Apps into a mirror
+func (s *RemoteSession) mirrorApp(mirrorDir string, metadata *AppMetadata) error {
+	appDir := filepath.Join(mirrorDir, "apps", metadata.Name)
+	if err := os.MkdirAll(appDir, 0755); err != nil {
+		return err
+	}
+	for name, content := range map[string]string{
+		"description": metadata.LongDescription,
+		"website":     metadata.Website,
+		"credits":     metadata.Credits,
+	} {
+		path := filepath.Join(appDir, name)
+		if content == "" {
+			os.Remove(path)
+		} else if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			return err
+		}
+	}
+
+	// Empty marker files tell the app type, the scripts themselves stay on the remote machine
+	markers := map[string][]string{
+		"package":         {"packages"},
+		"flatpak_package": {"flatpak_packages"},
+	}
+	wanted := markers[metadata.Type]
+	if wanted == nil {
+		for _, arch := range metadata.Architectures {
+			wanted = append(wanted, "install-"+arch)
+		}
+		if len(wanted) == 0 {
+			wanted = []string{"install"}
+		}
+	}
+	for _, name := range []string{"packages", "flatpak_packages", "install", "install-32", "install-64"} {
+		path := filepath.Join(appDir, name)
+		if slices.Contains(wanted, name) {
+			if !FileExists(path) {
+				os.WriteFile(path, nil, 0644)
+			}
+		} else {
+			os.Remove(path)
+		}
+	}
+
+	// Icons rarely change, so they are only downloaded once
+	for _, field := range strings.Fields(remoteIconSizes) {
+		size, _ := strconv.Atoi(field)
+		path := filepath.Join(appDir, fmt.Sprintf("icon-%d.png", size))
+		if _, ok := metadata.IconPaths[size]; !ok || FileExists(path) {
+			continue
+		}
+		icon, err := s.Icon(metadata.Name, size)
+		if err != nil {
+			Debug(fmt.Sprintf("Failed to get the %dpx icon of %s: %v", size, metadata.Name, err))
+			continue
+		}
+		if err := os.WriteFile(path, icon, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteMirrorStatuses writes the statuses of the apps of a remote Pi-Apps into its mirror,
+// leaving the status files that didn't change alone so the GUI only sees real changes
+func WriteMirrorStatuses(mirrorDir string, statuses map[string]AppState) error {
+	statusDir := filepath.Join(mirrorDir, "data", "status")
+	if err := os.MkdirAll(statusDir, 0755); err != nil {
+		return err
+	}
+	for app, status := range statuses {
+		if ValidateAppName(app) != nil {
+			continue
+		}
+		path := filepath.Join(statusDir, app)
+		current, err := os.ReadFile(path)
+		switch {
+		case status == "" || status == AppStateUninstalled:
+			if err == nil {
+				os.Remove(path)
+			}
+		case err != nil || strings.TrimSpace(string(current)) != string(status):
+			if err := os.WriteFile(path, []byte(string(status)+"\n"), 0644); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// linkIntoMirror links a file of the local Pi-Apps folder into a mirror, replacing an outdated link
+func linkIntoMirror(target, link string) {
+	if current, err := os.Readlink(link); err == nil {
+		if current == target {
+			return
+		}
+		os.Remove(link)
+	} else if fileOrDirExists(link) {
+		return
+	}
+	if err := os.Symlink(target, link); err != nil {
+		Debug(fmt.Sprintf("Failed to link %s into the mirror: %v", target, err))
+	}
+}
+
+// localQueueRunning reports whether the manage daemon of a Pi-Apps folder is running
+func localQueueRunning(directory string) bool {
+	pidFile := filepath.Join(DataDirOf(directory), "manage-daemon", "pid")
+	return FileExists(pidFile) && PIDFileRunning(pidFile)
+}
+
+// freeLocalPort returns a TCP port on localhost nothing listens on
+func freeLocalPort() (int, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer listener.Close()
+	return listener.Addr().(*net.TCPAddr).Port, nil
+}
+
+// lastLines returns the last n lines of a text
+func lastLines(text string, n int) string {
+	lines := strings.Split(text, "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// lockedBuffer is a bytes.Buffer safe for a writer and a reader in different goroutines
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
@@ -0,0 +1,78 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: repo.go
+// Description: Provides the types shared by the repository functions of every package manager.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package api
+
+import (
+	"fmt"
+	"os"
+)
+
+// InstalledPackage is an installed package, as returned by PackagesInstalledFromRepo
+type InstalledPackage struct {
+	Name      string `json:"name"`
+	Version   string `json:"version"`
+	Automatic bool   `json:"automatic"` // installed as a dependency rather than manually
+}
+
+// String returns the package as "name version (installed manually)" or "name version (installed as a dependency)"
+func (p InstalledPackage) String() string {
+	if p.Automatic {
+		return fmt.Sprintf("%s %s (%s)", p.Name, p.Version, T("installed as a dependency"))
+	}
+	return fmt.Sprintf("%s %s (%s)", p.Name, p.Version, T("installed manually"))
+}
+
+// printBlockingPackages tells the user which installed packages keep a repository from being removed
+func printBlockingPackages(repo string, packages []InstalledPackage) {
+	if len(packages) == 0 {
+		return
+	}
+	WarningTf("Not removing %s, these packages are still installed from it:", repo)
+	for _, pkg := range packages {
+		fmt.Fprintln(os.Stderr, "  - "+pkg.String())
+	}
+}
+
+// Health of a package source, as reported by CheckAptSources
+const (
+	SourceOK          = "ok"
+	SourceUnreachable = "unreachable" // the server can't be reached or doesn't have the suite
+	SourceExpired     = "expired"     // the Release file is past its Valid-Until date
+	SourceForbidden   = "forbidden"   // the server refuses access, e.g. a repository that needs a login
+)
+
+// SourceHealth is the result of checking one repository of a sources file
+type SourceHealth struct {
+	File   string `json:"file"`
+	URI    string `json:"uri"`
+	Suite  string `json:"suite"`
+	URL    string `json:"url"` // the InRelease file that was checked
+	Status string `json:"status"`
+	Detail string `json:"detail,omitempty"` // what failed, e.g. the HTTP status or the network error
+}
+
+// String returns the source as "uri suite (file): status"
+func (s SourceHealth) String() string {
+	if s.Detail != "" {
+		return fmt.Sprintf("%s %s (%s): %s, %s", s.URI, s.Suite, s.File, s.Status, s.Detail)
+	}
+	return fmt.Sprintf("%s %s (%s): %s", s.URI, s.Suite, s.File, s.Status)
+}
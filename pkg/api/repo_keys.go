@@ -0,0 +1,223 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: repo_keys.go
+// Description: Reads the signing keys of package repositories from OpenPGP keyrings to find the ones that expire.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package api
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+)
+
+// RepoKeyWarnDays is how many days before a repository key expires it is warned about
+const RepoKeyWarnDays = 30
+
+// RepoKey is a signing key of a package repository, as listed by ListExternalRepoKeys
+type RepoKey struct {
+	Repo        string    `json:"repo"`    // sources file of the repository without its directory and extension
+	Keyring     string    `json:"keyring"` // keyring file, or the sources file if the key is embedded in it
+	Fingerprint string    `json:"fingerprint"`
+	Created     time.Time `json:"created"`
+	Expires     time.Time `json:"expires"`              // zero if the key never expires
+	DaysLeft    int       `json:"days_left"`            // whole days until the key expires, negative once it expired, 0 if it never expires
+	PubkeyURL   string    `json:"pubkey_url,omitempty"` // where AddExternalRepo downloaded the key from, "" if it wasn't recorded
+}
+
+// Expired reports whether the key expired
+func (k RepoKey) Expired(now time.Time) bool {
+	return !k.Expires.IsZero() && !now.Before(k.Expires)
+}
+
+// Expiring reports whether the key expired or expires within RepoKeyWarnDays
+func (k RepoKey) Expiring(now time.Time) bool {
+	return !k.Expires.IsZero() && now.AddDate(0, 0, RepoKeyWarnDays).After(k.Expires)
+}
+
+// String returns the key as "repo (fingerprint): expires on 2026-11-02, in 18 days"
+func (k RepoKey) String() string {
+	switch {
+	case k.Expires.IsZero():
+		return Tf("%s (%s): never expires", k.Repo, k.Fingerprint)
+	case k.DaysLeft < 0:
+		return Tf("%s (%s): expired on %s, %d days ago", k.Repo, k.Fingerprint, k.Expires.Format(time.DateOnly), -k.DaysLeft)
+	default:
+		return Tf("%s (%s): expires on %s, in %d days", k.Repo, k.Fingerprint, k.Expires.Format(time.DateOnly), k.DaysLeft)
+	}
+}
+
+// ExpiringRepoKeys returns the keyrings that expired or expire within RepoKeyWarnDays, each as its key that expires last.
+// A keyring often keeps the old key of a repository next to the new one, only once every key expires is the repository
+// rejected by apt.
+func ExpiringRepoKeys(keys []RepoKey, now time.Time) []RepoKey {
+	latest := make(map[string]RepoKey)
+	var order []string
+	for _, key := range keys {
+		id := key.Repo + "\x00" + key.Keyring
+		current, seen := latest[id]
+		if !seen {
+			order = append(order, id)
+		}
+		if !seen || (!current.Expires.IsZero() && (key.Expires.IsZero() || key.Expires.After(current.Expires))) {
+			latest[id] = key
+		}
+	}
+
+	var expiring []RepoKey
+	for _, id := range order {
+		if key := latest[id]; key.Expiring(now) {
+			expiring = append(expiring, key)
+		}
+	}
+	return expiring
+}
+
+// parseRepoKeys reads the primary keys of a binary or armored OpenPGP keyring, with the time each one expires.
+// The signatures are not verified, the keyring is trusted by apt already and only its expiry dates are read.
+//
+// A key signs with its newest signing subkey if it has one, so it expires when the primary key or the last of its
+// signing subkeys expires, whichever comes first.
+func parseRepoKeys(data []byte) ([]RepoKey, error) {
+	if block, err := armor.Decode(bytes.NewReader(data)); err == nil {
+		if data, err = io.ReadAll(block.Body); err != nil {
+			return nil, fmt.Errorf("failed to read the armored key: %w", err)
+		}
+	}
+
+	var (
+		keys []RepoKey
+
+		// The key being read, with the expiry of its newest self-signature
+		primary                       *packet.PublicKey
+		primaryExpires, newestSelfSig time.Time
+
+		// The subkey being read with its newest binding signature, and the last expiry of the signing subkeys read so far
+		subkey                            *packet.PublicKey
+		subkeySig                         *packet.Signature
+		subkeyExpires                     time.Time
+		signingSubkey, subkeyNeverExpires bool
+	)
+
+	// finishSubkey takes the newest binding signature of the subkey that was read into account
+	finishSubkey := func() {
+		if subkey != nil && subkeySig != nil && (!subkeySig.FlagsValid || subkeySig.FlagSign) {
+			signingSubkey = true
+			expires := keyExpiry(subkey, subkeySig)
+			if expires.IsZero() {
+				subkeyNeverExpires = true
+			} else if expires.After(subkeyExpires) {
+				subkeyExpires = expires
+			}
+		}
+		subkey, subkeySig = nil, nil
+	}
+	finishKey := func() {
+		finishSubkey()
+		if primary == nil {
+			return
+		}
+		expires := primaryExpires
+		if signingSubkey && !subkeyNeverExpires && (expires.IsZero() || subkeyExpires.Before(expires)) {
+			expires = subkeyExpires
+		}
+		keys = append(keys, RepoKey{
+			Fingerprint: strings.ToUpper(hex.EncodeToString(primary.Fingerprint)),
+			Created:     primary.CreationTime,
+			Expires:     expires,
+		})
+		primary = nil
+	}
+
+	reader := packet.NewReader(bytes.NewReader(data))
+	for {
+		p, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return keys, fmt.Errorf("failed to read the keyring: %w", err)
+		}
+
+		switch p := p.(type) {
+		case *packet.PublicKey:
+			if !p.IsSubkey {
+				finishKey()
+				primary, primaryExpires, newestSelfSig = p, time.Time{}, time.Time{}
+				signingSubkey, subkeyNeverExpires, subkeyExpires = false, false, time.Time{}
+				continue
+			}
+			finishSubkey()
+			subkey = p
+		case *packet.Signature:
+			if primary == nil || p.IssuerKeyId == nil || *p.IssuerKeyId != primary.KeyId {
+				continue
+			}
+			switch {
+			case subkey != nil && p.SigType == packet.SigTypeSubkeyBinding:
+				if subkeySig == nil || p.CreationTime.After(subkeySig.CreationTime) {
+					subkeySig = p
+				}
+			case subkey == nil && isSelfCertification(p.SigType):
+				if p.CreationTime.After(newestSelfSig) {
+					newestSelfSig = p.CreationTime
+					primaryExpires = keyExpiry(primary, p)
+				}
+			}
+		}
+	}
+	finishKey()
+
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("the keyring has no keys")
+	}
+	return keys, nil
+}
+
+// keyExpiry returns when a key expires according to its self-signature, zero if it never expires
+func keyExpiry(key *packet.PublicKey, sig *packet.Signature) time.Time {
+	if sig.KeyLifetimeSecs == nil || *sig.KeyLifetimeSecs == 0 {
+		return time.Time{}
+	}
+	return key.CreationTime.Add(time.Duration(*sig.KeyLifetimeSecs) * time.Second)
+}
+
+// isSelfCertification reports whether a signature type binds a user ID to the primary key or is a direct key signature
+func isSelfCertification(sigType packet.SignatureType) bool {
+	switch sigType {
+	case packet.SigTypeGenericCert, packet.SigTypePersonaCert, packet.SigTypeCasualCert, packet.SigTypePositiveCert, packet.SigTypeDirectSignature:
+		return true
+	}
+	return false
+}
+
+// repoKeyDaysLeft returns the whole days until a key expires, negative once it expired and 0 if it never expires
+func repoKeyDaysLeft(expires, now time.Time) int {
+	if expires.IsZero() {
+		return 0
+	}
+	return int(math.Floor(expires.Sub(now).Hours() / 24))
+}
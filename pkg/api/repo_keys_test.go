@@ -0,0 +1,158 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+)
+
+// testRepoKey returns a public key created at created that expires after lifetime, never if lifetime is 0
+func testRepoKey(t *testing.T, created time.Time, lifetime time.Duration) *openpgp.Entity {
+	t.Helper()
+	config := &packet.Config{
+		Algorithm:       packet.PubKeyAlgoEdDSA,
+		Time:            func() time.Time { return created },
+		KeyLifetimeSecs: uint32(lifetime.Seconds()),
+	}
+	entity, err := openpgp.NewEntity("Test Repository", "", "repo@example.com", config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return entity
+}
+
+func serializeRepoKeys(t *testing.T, entities ...*openpgp.Entity) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	for _, entity := range entities {
+		if err := entity.Serialize(&buf); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return buf.Bytes()
+}
+
+func TestParseRepoKeys(t *testing.T) {
+	created := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	expiring := testRepoKey(t, created, 10*24*time.Hour)
+	forever := testRepoKey(t, created, 0)
+
+	keys, err := parseRepoKeys(serializeRepoKeys(t, expiring, forever))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 2 {
+		t.Fatalf("got %d keys, want 2", len(keys))
+	}
+	if want := created.Add(10 * 24 * time.Hour); !keys[0].Expires.Equal(want) {
+		t.Errorf("expires = %v, want %v", keys[0].Expires, want)
+	}
+	if want := strings.ToUpper(hex.EncodeToString(expiring.PrimaryKey.Fingerprint)); keys[0].Fingerprint != want {
+		t.Errorf("fingerprint = %s, want %s", keys[0].Fingerprint, want)
+	}
+	if !keys[1].Expires.IsZero() {
+		t.Errorf("key without lifetime expires on %v", keys[1].Expires)
+	}
+
+	// Armored keyrings, as downloaded from a pubkeyurl, are read as well
+	var armored bytes.Buffer
+	writer, err := armor.Encode(&armored, openpgp.PublicKeyType, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writer.Write(serializeRepoKeys(t, expiring))
+	writer.Close()
+	if keys, err := parseRepoKeys(armored.Bytes()); err != nil || len(keys) != 1 {
+		t.Errorf("armored keyring: %d keys, error %v", len(keys), err)
+	}
+
+	if _, err := parseRepoKeys([]byte("not a key")); err == nil {
+		t.Error("no error for a file without keys")
+	}
+}
+
+func TestParseRepoKeysSigningSubkey(t *testing.T) {
+	created := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	entity := testRepoKey(t, created, 0)
+	err := entity.AddSigningSubkey(&packet.Config{
+		Algorithm:       packet.PubKeyAlgoEdDSA,
+		Time:            func() time.Time { return created },
+		KeyLifetimeSecs: uint32((5 * 24 * time.Hour).Seconds()),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keys, err := parseRepoKeys(serializeRepoKeys(t, entity))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The repository is signed with the subkey, so the key is of no use once the subkey expired
+	if want := created.Add(5 * 24 * time.Hour); len(keys) != 1 || !keys[0].Expires.Equal(want) {
+		t.Errorf("keys = %+v, want one that expires on %v", keys, want)
+	}
+}
+
+func TestExpiringRepoKeys(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	keys := []RepoKey{
+		// The old key of a keyring expired, but its new one is valid for long
+		{Repo: "rotated", Keyring: "/usr/share/keyrings/rotated.gpg", Fingerprint: "OLD", Expires: now.AddDate(0, 0, -100)},
+		{Repo: "rotated", Keyring: "/usr/share/keyrings/rotated.gpg", Fingerprint: "NEW", Expires: now.AddDate(1, 0, 0)},
+		{Repo: "soon", Keyring: "/usr/share/keyrings/soon.gpg", Fingerprint: "SOON", Expires: now.AddDate(0, 0, 12)},
+		{Repo: "expired", Keyring: "/usr/share/keyrings/expired.gpg", Fingerprint: "OLDER", Expires: now.AddDate(0, 0, -10)},
+		{Repo: "expired", Keyring: "/usr/share/keyrings/expired.gpg", Fingerprint: "OLD", Expires: now.AddDate(0, 0, -2)},
+		{Repo: "forever", Keyring: "/usr/share/keyrings/forever.gpg", Fingerprint: "OLD", Expires: now.AddDate(0, 0, -2)},
+		{Repo: "forever", Keyring: "/usr/share/keyrings/forever.gpg", Fingerprint: "FOREVER"},
+	}
+
+	expiring := ExpiringRepoKeys(keys, now)
+	var got []string
+	for _, key := range expiring {
+		got = append(got, key.Repo+"/"+key.Fingerprint)
+	}
+	if want := []string{"soon/SOON", "expired/OLD"}; len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("expiring = %v, want %v", got, want)
+	}
+}
+
+func TestRepoKeyDaysLeft(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		expires time.Time
+		want    int
+	}{
+		{time.Time{}, 0},
+		{now.Add(36 * time.Hour), 1},
+		{now.Add(6 * time.Hour), 0},
+		{now.Add(-6 * time.Hour), -1},
+		{now.AddDate(0, 0, -3), -3},
+	}
+	for _, tt := range tests {
+		if got := repoKeyDaysLeft(tt.expires, now); got != tt.want {
+			t.Errorf("repoKeyDaysLeft(%v) = %d, want %d", tt.expires, got, tt.want)
+		}
+	}
+}
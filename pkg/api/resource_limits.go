@@ -0,0 +1,173 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: resource_limits.go
+// Description: Runs install scripts in a transient systemd user scope with CPU, memory and IO limits, so heavy compiles don't freeze the system.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package api
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// ResourceLimits are the systemd resource control properties an install script runs with
+type ResourceLimits struct {
+	CPUQuota  string `json:"cpu_quota,omitempty"`  // CPUQuota, e.g. 200% for two cores
+	MemoryMax string `json:"memory_max,omitempty"` // MemoryMax, e.g. 1G or 75% of the RAM
+	IOWeight  string `json:"io_weight,omitempty"`  // IOWeight, 1-10000 with 100 as the default
+}
+
+// IsEmpty reports whether no limit is set
+func (l ResourceLimits) IsEmpty() bool {
+	return l.CPUQuota == "" && l.MemoryMax == "" && l.IOWeight == ""
+}
+
+// String returns the limits as systemd properties, e.g. "CPUQuota=200% MemoryMax=1G"
+func (l ResourceLimits) String() string {
+	var properties []string
+	if l.CPUQuota != "" {
+		properties = append(properties, "CPUQuota="+l.CPUQuota)
+	}
+	if l.MemoryMax != "" {
+		properties = append(properties, "MemoryMax="+l.MemoryMax)
+	}
+	if l.IOWeight != "" {
+		properties = append(properties, "IOWeight="+l.IOWeight)
+	}
+	return strings.Join(properties, " ")
+}
+
+// parseResourceLimits parses limits written as systemd properties, unknown properties are ignored
+func parseResourceLimits(s string) ResourceLimits {
+	var limits ResourceLimits
+	for _, property := range strings.Fields(s) {
+		key, value, _ := strings.Cut(property, "=")
+		switch key {
+		case "CPUQuota":
+			limits.CPUQuota = value
+		case "MemoryMax":
+			limits.MemoryMax = value
+		case "IOWeight":
+			limits.IOWeight = value
+		}
+	}
+	return limits
+}
+
+// InstallResourceLimits returns the limits an app's install script runs with
+//
+// The "Install resource limits" setting is No to never limit scripts, "App defaults" to only use the limits
+// apps declare in their requirements file, or systemd properties applied to every script.
+// Limits an app declares override the ones from the setting.
+func InstallResourceLimits(app string) ResourceLimits {
+	setting := ReadSetting("Install resource limits")
+	if setting == "No" {
+		return ResourceLimits{}
+	}
+
+	var limits ResourceLimits
+	if setting != "App defaults" {
+		limits = parseResourceLimits(setting)
+	}
+
+	requirements, err := ReadAppRequirements(app)
+	if err != nil {
+		Debug(fmt.Sprintf("Failed to read requirements of %s: %v", app, err))
+		return limits
+	}
+	if requirements.CPUQuota != "" {
+		limits.CPUQuota = requirements.CPUQuota
+	}
+	if requirements.MemoryMax != "" {
+		limits.MemoryMax = requirements.MemoryMax
+	}
+	if requirements.IOWeight != "" {
+		limits.IOWeight = requirements.IOWeight
+	}
+	return limits
+}
+
+// systemdUserScopesAvailable reports whether transient scopes can be started in the user's systemd instance
+func systemdUserScopesAvailable() bool {
+	// Root has no user session to put the scope in, and scripts drop to the real user anyway
+	if os.Geteuid() == 0 {
+		return false
+	}
+	if _, err := exec.LookPath("systemd-run"); err != nil {
+		return false
+	}
+	runtimeDir := os.Getenv("XDG_RUNTIME_DIR")
+	if runtimeDir == "" {
+		return false
+	}
+	// The user manager listens on this socket while the user session is running
+	if _, err := os.Stat(filepath.Join(runtimeDir, "systemd", "private")); err != nil {
+		return false
+	}
+	return exec.Command("systemctl", "--user", "show", "--property=Version").Run() == nil
+}
+
+// InstallScopeUnit returns the name of the scope unit an app's script runs in, e.g. pi-apps-install-Box64.scope
+func InstallScopeUnit(app string) string {
+	var name strings.Builder
+	for _, r := range app {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '-' || r == '_' || r == '.' {
+			name.WriteRune(r)
+		} else {
+			fmt.Fprintf(&name, "\\x%02x", r)
+		}
+	}
+	return "pi-apps-install-" + name.String() + ".scope"
+}
+
+// StopInstallScope kills every process of the scope an app's install script runs in
+func StopInstallScope(app string) error {
+	output, err := exec.Command("systemctl", "--user", "stop", InstallScopeUnit(app)).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to stop %s: %w: %s", InstallScopeUnit(app), err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// limitCommand makes cmd run its program in a transient systemd user scope with the limits.
+// The scope keeps the environment, working directory and output of cmd.
+//
+// It returns false and leaves cmd unchanged when there are no limits or systemd user sessions aren't available.
+func limitCommand(cmd *exec.Cmd, app string, limits ResourceLimits) bool {
+	if limits.IsEmpty() || !systemdUserScopesAvailable() {
+		return false
+	}
+
+	args := []string{"systemd-run", "--user", "--scope", "--quiet", "--collect", "--unit=" + InstallScopeUnit(app)}
+	for _, property := range strings.Fields(limits.String()) {
+		args = append(args, "--property="+property)
+	}
+	args = append(args, "--")
+	args = append(args, cmd.Args...)
+
+	path, err := exec.LookPath("systemd-run")
+	if err != nil {
+		return false
+	}
+	cmd.Path = path
+	cmd.Args = args
+	return true
+}
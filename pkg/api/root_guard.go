@@ -0,0 +1,228 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: root_guard.go
+// Description: Keeps Pi-Apps from running as root, which leaves files in the user's home owned by root,
+// and runs the command again as the original user when it was started with sudo.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package api
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/pi-apps-go/pi-apps/pkg/api/statelock"
+	"golang.org/x/term"
+)
+
+const (
+	// allowRootEnv lets Pi-Apps run as root on systems that only have a root user, like containers and CI images
+	allowRootEnv = "PI_APPS_ALLOW_ROOT"
+	// rootChildEnv holds the PID of the Pi-Apps process that started this one, like a script running api-go through
+	// sudo -E on purpose. It only counts while that process is an ancestor running a Pi-Apps program, see piAppsAncestor.
+	rootChildEnv = "__pi_apps_child"
+)
+
+// piAppsPrograms are the file names of the Pi-Apps programs
+var piAppsPrograms = []string{"api", "api-go", "gui", "manage", "pi-apps", "settings", "updater", MultiCallBinary}
+
+// rootAction is what Pi-Apps does when it starts
+type rootAction int
+
+const (
+	rootProceed rootAction = iota // not running as root, or running as root is allowed
+	rootReexec                    // started with sudo or pkexec: run the command again as the original user
+	rootRefuse                    // running as root with no user to switch to
+)
+
+// decideRootAction decides what Pi-Apps does when it starts with the given effective and real user IDs.
+// getenv reads the environment, so the decision doesn't depend on the process it runs in.
+func decideRootAction(euid, uid int, getenv func(string) string) rootAction {
+	if euid != 0 {
+		return rootProceed
+	}
+	// A setuid binary started by a regular user: scripts are run as the real user
+	if uid != 0 {
+		return rootProceed
+	}
+	if getenv(allowRootEnv) == "1" {
+		return rootProceed
+	}
+	if pid, err := strconv.Atoi(getenv(rootChildEnv)); err == nil && piAppsAncestor(pid) {
+		return rootProceed
+	}
+	if originalUser(getenv) != "" {
+		return rootReexec
+	}
+	return rootRefuse
+}
+
+// piAppsAncestor reports whether a process is an ancestor of this one running a Pi-Apps program: the executable of
+// this process, or one of the Pi-Apps programs next to it. The environment alone can't be trusted, anyone can run
+// sudo __pi_apps_child=1. A variable so tests can fake the processes.
+var piAppsAncestor = func(pid int) bool {
+	if !statelock.IsAncestor(pid) {
+		return false
+	}
+	ancestor, err := os.Readlink(fmt.Sprintf("/proc/%d/exe", pid))
+	if err != nil {
+		return false
+	}
+	self, err := os.Executable()
+	if err != nil {
+		return false
+	}
+	if resolved, err := filepath.EvalSymlinks(self); err == nil {
+		self = resolved
+	}
+	return ancestor == self || filepath.Dir(ancestor) == filepath.Dir(self) && slices.Contains(piAppsPrograms, filepath.Base(ancestor))
+}
+
+// originalUser returns the user who started Pi-Apps with sudo or pkexec, "" if there is none
+func originalUser(getenv func(string) string) string {
+	if name := getenv("SUDO_USER"); name != "" && name != "root" {
+		return name
+	}
+	if uid := getenv("PKEXEC_UID"); uid != "" && uid != "0" {
+		if u, err := user.LookupId(uid); err == nil {
+			return u.Username
+		}
+	}
+	return ""
+}
+
+// startEnviron is the environment Pi-Apps was started with, before Init changed it
+var startEnviron []string
+
+// GuardRoot stops Pi-Apps from running as root. When it was started with sudo, it offers to run the command
+// again as the original user and exits with its exit code.
+//
+// The Pi-Apps programs call it first thing in main. It isn't part of Init, so programs using the API as a
+// library decide for themselves whether they may run as root.
+func GuardRoot() {
+	action := decideRootAction(os.Geteuid(), os.Getuid(), os.Getenv)
+	if action == rootProceed {
+		// Let the scripts and helpers Pi-Apps starts through sudo -E pass the guard
+		os.Setenv(rootChildEnv, strconv.Itoa(os.Getpid()))
+		return
+	}
+
+	ErrorNoExitT("Pi-Apps is not designed to be run as root!")
+	fmt.Fprintln(os.Stderr, T("Running it with sudo makes the files it creates in ~/.config and ~/.cache owned by root, which breaks apps for your user."))
+
+	if action == rootReexec {
+		name := originalUser(os.Getenv)
+		if confirm, err := confirmReexec(name); err != nil {
+			ErrorNoExitTf("Not running Pi-Apps again as %s: %v", name, err)
+		} else if confirm {
+			code, err := reexecAsUser(name, startEnviron)
+			if err == nil {
+				os.Exit(code)
+			}
+			ErrorNoExitTf("Failed to run Pi-Apps as %s: %v", name, err)
+		}
+	}
+
+	fmt.Fprintln(os.Stderr, T("Run it again as a regular user, without sudo. Pi-Apps asks for your password when it needs administrator rights."))
+	fmt.Fprintln(os.Stderr, Tf("If this system only has a root user (like a container or CI image), set %s=1 to run it anyway.", allowRootEnv))
+	os.Exit(1)
+}
+
+// confirmReexec asks whether to run the command again as the given user. Without a terminal there is nobody
+// to ask, and switching users behind the back of a script or a launcher is refused with an error.
+func confirmReexec(name string) (bool, error) {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return false, errors.New(T("there is no terminal to confirm it on"))
+	}
+	fmt.Fprint(os.Stderr, Tf("Run it again as %s instead? [Y/n] ", name))
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return false, nil
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "" || answer == "y" || answer == "yes", nil
+}
+
+// reexecAsUser runs the current command again as the given user, with the environment it was started with
+// minus the sudo and pkexec variables, and returns its exit code
+func reexecAsUser(name string, environ []string) (int, error) {
+	u, err := user.Lookup(name)
+	if err != nil {
+		return 0, err
+	}
+	uid, err := strconv.ParseUint(u.Uid, 10, 32)
+	if err != nil {
+		return 0, err
+	}
+	gid, err := strconv.ParseUint(u.Gid, 10, 32)
+	if err != nil {
+		return 0, err
+	}
+	var groups []uint32
+	if groupIDs, err := u.GroupIds(); err == nil {
+		for _, id := range groupIDs {
+			if group, err := strconv.ParseUint(id, 10, 32); err == nil {
+				groups = append(groups, uint32(group))
+			}
+		}
+	}
+	executable, err := os.Executable()
+	if err != nil {
+		return 0, err
+	}
+
+	var env []string
+	for _, variable := range environ {
+		key, _, _ := strings.Cut(variable, "=")
+		switch {
+		case strings.HasPrefix(key, "SUDO_"), key == "PKEXEC_UID", key == "HOME", key == "USER", key == "LOGNAME":
+			continue
+		}
+		env = append(env, variable)
+	}
+	env = append(env, "HOME="+u.HomeDir, "USER="+u.Username, "LOGNAME="+u.Username)
+
+	// Keep argv[0], the multi-call binary picks the program by it
+	cmd := &exec.Cmd{
+		Path:   executable,
+		Args:   os.Args,
+		Env:    env,
+		Stdin:  os.Stdin,
+		Stdout: os.Stdout,
+		Stderr: os.Stderr,
+		SysProcAttr: &syscall.SysProcAttr{
+			Credential: &syscall.Credential{Uid: uint32(uid), Gid: uint32(gid), Groups: groups},
+		},
+	}
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return exitErr.ExitCode(), nil
+		}
+		return 0, err
+	}
+	return 0, nil
+}
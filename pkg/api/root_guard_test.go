@@ -0,0 +1,83 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"os"
+	"testing"
+
+	"golang.org/x/term"
+)
+
+func TestDecideRootAction(t *testing.T) {
+	tests := []struct {
+		name      string
+		euid, uid int
+		env       map[string]string
+		want      rootAction
+	}{
+		{"regular user", 1000, 1000, nil, rootProceed},
+		{"regular user with a stale SUDO_USER", 1000, 1000, map[string]string{"SUDO_USER": "pi"}, rootProceed},
+		{"setuid binary", 0, 1000, nil, rootProceed},
+		{"sudo", 0, 0, map[string]string{"SUDO_USER": "pi"}, rootReexec},
+		{"sudo from root", 0, 0, map[string]string{"SUDO_USER": "root"}, rootRefuse},
+		{"pkexec from root", 0, 0, map[string]string{"PKEXEC_UID": "0"}, rootRefuse},
+		{"root login", 0, 0, nil, rootRefuse},
+		{"allowed root", 0, 0, map[string]string{allowRootEnv: "1"}, rootProceed},
+		{"allowed root with sudo", 0, 0, map[string]string{allowRootEnv: "1", "SUDO_USER": "pi"}, rootProceed},
+		{"not quite allowed root", 0, 0, map[string]string{allowRootEnv: "yes"}, rootRefuse},
+		{"child of Pi-Apps", 0, 0, map[string]string{rootChildEnv: "4242", "SUDO_USER": "pi"}, rootProceed},
+		{"child of another program", 0, 0, map[string]string{rootChildEnv: "4343", "SUDO_USER": "pi"}, rootReexec},
+		{"marker set by hand", 0, 0, map[string]string{rootChildEnv: "1", "SUDO_USER": "pi"}, rootReexec},
+		{"marker set by hand as root", 0, 0, map[string]string{rootChildEnv: "1"}, rootRefuse},
+	}
+	// Only process 4242 is an ancestor running a Pi-Apps program
+	original := piAppsAncestor
+	piAppsAncestor = func(pid int) bool { return pid == 4242 }
+	t.Cleanup(func() { piAppsAncestor = original })
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			getenv := func(key string) string { return tt.env[key] }
+			if got := decideRootAction(tt.euid, tt.uid, getenv); got != tt.want {
+				t.Errorf("decideRootAction(%d, %d) = %d, want %d", tt.euid, tt.uid, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfirmReexecRefusesWithoutTerminal(t *testing.T) {
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		t.Skip("stdin is a terminal")
+	}
+	confirm, err := confirmReexec("pi")
+	if err == nil || confirm {
+		t.Errorf("confirmReexec without a terminal = %v, %v, want a refusal", confirm, err)
+	}
+}
+
+func TestPiAppsAncestor(t *testing.T) {
+	// The parent of the test is go test, not a Pi-Apps program, and this process is not its own ancestor
+	if piAppsAncestor(os.Getppid()) {
+		t.Error("piAppsAncestor trusted the parent of the test")
+	}
+	if piAppsAncestor(os.Getpid()) {
+		t.Error("piAppsAncestor trusted this process")
+	}
+	if piAppsAncestor(1) {
+		t.Error("piAppsAncestor trusted init")
+	}
+}
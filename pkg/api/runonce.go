@@ -0,0 +1,341 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: runonce.go
+// Description: Runs one-time migrations, bash scripts and Go functions, until they succeed once, and keeps the
+// index of those that completed or failed.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package api
+
+import (
+	"crypto/sha1"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// runonceIndexFile records the runonce scripts and functions that completed or failed, in data
+	runonceIndexFile = "runonce.json"
+	// legacyRunonceHashesFile is the index of older versions, one hash per line of the scripts that ran. It is
+	// migrated to runonceIndexFile the first time the index is read.
+	legacyRunonceHashesFile = "runonce_hashes"
+	// runonceIndexVersion is the version of the format of runonceIndexFile
+	runonceIndexVersion = 2
+
+	// DefaultRunonceAttempts is how often a failing runonce script or function is run before it is given up on,
+	// PI_APPS_RUNONCE_ATTEMPTS changes it
+	DefaultRunonceAttempts = 3
+	// runonceOutputLines is the number of last output lines a failure record keeps
+	runonceOutputLines = 20
+	// runonceDefaultPath is the PATH of runonce scripts when the caller has none
+	runonceDefaultPath = "/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin"
+)
+
+// ErrRunonceGaveUp is returned for a runonce script or function that failed as often as it may be run
+var ErrRunonceGaveUp = errors.New("runonce gave up")
+
+// runonceBaseEnv are the environment variables every runonce script gets. Anything else it needs must be
+// whitelisted with RunonceOptions.Env, so a script behaves the same whoever runs it.
+var runonceBaseEnv = []string{"PI_APPS_DIR", "HOME", "PATH"}
+
+// runonceMutex serializes the changes of the index within this process
+var runonceMutex sync.Mutex
+
+// RunonceOptions changes how RunonceWithOptions runs a script
+type RunonceOptions struct {
+	// MaxAttempts is how often a failing script is run before it is given up on, 0 for PI_APPS_RUNONCE_ATTEMPTS
+	// or DefaultRunonceAttempts
+	MaxAttempts int
+	// Env names the environment variables passed to the script besides PI_APPS_DIR, HOME and PATH
+	Env []string
+}
+
+// RunonceFailure records the failed attempts of a runonce script or function that never succeeded
+type RunonceFailure struct {
+	Attempts   int       `json:"attempts"`
+	ExitCode   int       `json:"exit_code"`             // exit code of the last attempt, 1 for a function
+	LastOutput []string  `json:"last_output,omitempty"` // last lines the last attempt wrote, or the error of a function
+	Time       time.Time `json:"time"`                  // when the last attempt failed
+	Log        string    `json:"log,omitempty"`         // the output of the last attempt of a script
+}
+
+// runonceIndex is the content of runonceIndexFile. Entries are keyed by the SHA1 hash of the script, or of the
+// version identifier of a function.
+type runonceIndex struct {
+	Version   int                       `json:"version"`
+	Completed map[string]time.Time      `json:"completed"`
+	Failed    map[string]RunonceFailure `json:"failed,omitempty"`
+}
+
+// runonceHash returns the key of a script or version identifier in the index
+func runonceHash(text string) string {
+	return fmt.Sprintf("%x", sha1.Sum([]byte(text)))
+}
+
+// runonceMaxAttempts returns the attempt cap of options, PI_APPS_RUNONCE_ATTEMPTS or the default
+func runonceMaxAttempts(maxAttempts int) int {
+	if maxAttempts > 0 {
+		return maxAttempts
+	}
+	if value, err := strconv.Atoi(os.Getenv("PI_APPS_RUNONCE_ATTEMPTS")); err == nil && value > 0 {
+		return value
+	}
+	return DefaultRunonceAttempts
+}
+
+// loadRunonceIndex reads the index, migrating the legacy hashes file when there is no index yet
+func loadRunonceIndex(directory string) (*runonceIndex, error) {
+	index := &runonceIndex{Version: runonceIndexVersion, Completed: map[string]time.Time{}, Failed: map[string]RunonceFailure{}}
+	path := filepath.Join(DataDirOf(directory), runonceIndexFile)
+	data, err := os.ReadFile(path)
+	if err == nil {
+		if err := json.Unmarshal(data, index); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		if index.Completed == nil {
+			index.Completed = map[string]time.Time{}
+		}
+		if index.Failed == nil {
+			index.Failed = map[string]RunonceFailure{}
+		}
+		return index, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	legacy := filepath.Join(DataDirOf(directory), legacyRunonceHashesFile)
+	data, err = os.ReadFile(legacy)
+	if errors.Is(err, os.ErrNotExist) {
+		return index, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	// The legacy file only has the hashes, they ran some time before it was last changed
+	var ran time.Time
+	if info, err := os.Stat(legacy); err == nil {
+		ran = info.ModTime().UTC()
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if hash := strings.TrimSpace(line); hash != "" {
+			index.Completed[hash] = ran
+		}
+	}
+	if err := index.save(directory); err != nil {
+		return nil, fmt.Errorf("failed to migrate %s: %w", legacy, err)
+	}
+	os.Remove(legacy)
+	return index, nil
+}
+
+// save writes the index, replacing the file so a crash never leaves half of it
+func (index *runonceIndex) save(directory string) error {
+	index.Version = runonceIndexVersion
+	data, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(DataDirOf(directory), runonceIndexFile)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	temp := path + ".tmp"
+	if err := os.WriteFile(temp, append(data, '\n'), 0644); err != nil {
+		return err
+	}
+	return os.Rename(temp, path)
+}
+
+// runonceAttempt is the outcome of running a runonce script or function once
+type runonceAttempt struct {
+	exitCode int
+	output   string // the last lines of the output, or the error of a function
+	log      string
+	err      error
+}
+
+// runonceRecorded runs a script or function unless it completed before or failed maxAttempts times, and records
+// the outcome: completed when it succeeded, a failure with its exit code and last output lines otherwise
+func runonceRecorded(hash string, maxAttempts int, run func(attempt int) runonceAttempt) error {
+	directory := GetPiAppsDir()
+	if directory == "" {
+		return fmt.Errorf("PI_APPS_DIR environment variable not set")
+	}
+
+	runonceMutex.Lock()
+	index, err := loadRunonceIndex(directory)
+	runonceMutex.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to read the runonce index: %w", err)
+	}
+	if _, completed := index.Completed[hash]; completed {
+		return nil
+	}
+	failure := index.Failed[hash]
+	if failure.Attempts >= maxAttempts {
+		return fmt.Errorf("%w after %d failed attempts, the last one exited with code %d", ErrRunonceGaveUp, failure.Attempts, failure.ExitCode)
+	}
+
+	attempt := run(failure.Attempts + 1)
+
+	runonceMutex.Lock()
+	defer runonceMutex.Unlock()
+	// Read the index again, another runonce may have finished in the meantime
+	index, err = loadRunonceIndex(directory)
+	if err != nil {
+		return fmt.Errorf("failed to read the runonce index: %w", err)
+	}
+	if attempt.err == nil {
+		index.Completed[hash] = time.Now().UTC()
+		delete(index.Failed, hash)
+	} else {
+		failure := index.Failed[hash]
+		failure.Attempts++
+		failure.ExitCode = attempt.exitCode
+		failure.LastOutput = nil
+		if output := strings.TrimRight(attempt.output, "\n"); output != "" {
+			failure.LastOutput = strings.Split(output, "\n")
+		}
+		failure.Time = time.Now().UTC()
+		failure.Log = attempt.log
+		index.Failed[hash] = failure
+	}
+	if err := index.save(directory); err != nil {
+		return fmt.Errorf("failed to write the runonce index: %w", err)
+	}
+	return attempt.err
+}
+
+// runonceEnv returns the environment of a runonce script: PI_APPS_DIR, HOME, PATH and the whitelisted variables
+// that are set
+func runonceEnv(directory string, whitelist []string) []string {
+	env := []string{"PI_APPS_DIR=" + directory}
+	var names []string
+	for _, name := range append(slices.Clone(runonceBaseEnv), whitelist...) {
+		if name != "PI_APPS_DIR" && name != "" && !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	for _, name := range names {
+		if value, ok := os.LookupEnv(name); ok {
+			env = append(env, name+"="+value)
+		} else if name == "PATH" {
+			env = append(env, "PATH="+runonceDefaultPath)
+		}
+	}
+	return env
+}
+
+// Runonce runs a script only if it has never completed before, with the default options of
+// RunonceWithOptions.
+//
+// Deprecated: In our goals to remove bash scripts for anything other then apps,
+// this function will be removed soon. Use api.RunonceFunc instead for Go native runonce functions.
+func Runonce(script string) error {
+	return RunonceWithOptions(script, RunonceOptions{})
+}
+
+// RunonceWithOptions runs a script only if it has never completed before, which is useful for one-time
+// migrations or setting changes. A script is identified by its SHA1 hash and completes when it exits with 0.
+// A failing script is run again the next time, until it failed options.MaxAttempts times.
+//
+// The script runs in bash with only PI_APPS_DIR, HOME, PATH and the variables named in options.Env set. Its
+// output goes to the caller's stdout and to logs/runonce/<hash>.log.
+//
+//	error - error wrapping ErrRunonceGaveUp if the script is not run anymore, or the error of the script
+func RunonceWithOptions(script string, options RunonceOptions) error {
+	directory := GetPiAppsDir()
+	if directory == "" {
+		return fmt.Errorf("PI_APPS_DIR environment variable not set")
+	}
+	hash := runonceHash(script)
+
+	err := runonceRecorded(hash, runonceMaxAttempts(options.MaxAttempts), func(attempt int) runonceAttempt {
+		logPath := filepath.Join(LogsDirOf(directory), "runonce", hash+".log")
+		if err := EnsureDirOwned(filepath.Dir(logPath), InvokingUser, InvokingUser); err != nil {
+			return runonceAttempt{exitCode: -1, output: err.Error(), err: err}
+		}
+		logFile, err := os.Create(logPath)
+		if err != nil {
+			return runonceAttempt{exitCode: -1, output: err.Error(), err: err}
+		}
+		defer logFile.Close()
+		header := fmt.Sprintf("Runonce attempt %d started on %s\n\n", attempt, time.Now().Format(time.RFC1123))
+		logFile.WriteString(header)
+
+		cmd := exec.Command("bash", "-c", script)
+		cmd.Dir = directory
+		cmd.Env = runonceEnv(directory, options.Env)
+		cmd.Stdout = io.MultiWriter(os.Stdout, logFile)
+		cmd.Stderr = io.MultiWriter(os.Stderr, logFile)
+		runErr := cmd.Run()
+		if runErr == nil {
+			return runonceAttempt{log: logPath}
+		}
+
+		exitCode := -1
+		var exitErr *exec.ExitError
+		if errors.As(runErr, &exitErr) {
+			exitCode = exitErr.ExitCode()
+		}
+		output, _ := os.ReadFile(logPath)
+		output = output[min(len(header), len(output)):]
+		return runonceAttempt{
+			exitCode: exitCode,
+			output:   lastLines(RemoveAnsiEscapes(strings.TrimRight(string(output), "\n")), runonceOutputLines),
+			log:      logPath,
+			err:      fmt.Errorf("runonce(): script failed: %w, see %s", runErr, logPath),
+		}
+	})
+	if errors.Is(err, ErrRunonceGaveUp) {
+		return fmt.Errorf("runonce(): %w, see %s", err, filepath.Join(LogsDirOf(directory), "runonce", hash+".log"))
+	}
+	return err
+}
+
+// RunonceFunc runs a function only if it has never been run before with the given version.
+// It takes a function and a version identifier (e.g., "addUserDirs-v1").
+// If the version identifier hasn't completed before, the function is executed, until it failed as often as
+// PI_APPS_RUNONCE_ATTEMPTS allows.
+// This is useful for one-time migrations or setting changes using Go functions instead of bash scripts.
+func RunonceFunc(version string, fn func() error) error {
+	if fn == nil {
+		return fmt.Errorf("runonceFunc(): function is nil")
+	}
+
+	err := runonceRecorded(runonceHash(version), runonceMaxAttempts(0), func(int) runonceAttempt {
+		if err := fn(); err != nil {
+			return runonceAttempt{exitCode: 1, output: err.Error(), err: fmt.Errorf("runonceFunc(): function failed: %w", err)}
+		}
+		return runonceAttempt{}
+	})
+	if errors.Is(err, ErrRunonceGaveUp) {
+		return fmt.Errorf("runonceFunc(): %s: %w", version, err)
+	}
+	return err
+}
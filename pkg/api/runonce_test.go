@@ -0,0 +1,183 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// readRunonceIndex reads the runonce index of a test directory
+func readRunonceIndex(t *testing.T, directory string) runonceIndex {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(directory, "data", runonceIndexFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var index runonceIndex
+	if err := json.Unmarshal(data, &index); err != nil {
+		t.Fatal(err)
+	}
+	return index
+}
+
+// runCount returns how often a test script appended to its counter file
+func runCount(t *testing.T, path string) int {
+	t.Helper()
+	data, _ := os.ReadFile(path)
+	return len(data)
+}
+
+func TestRunonceSuccess(t *testing.T) {
+	directory := newTestPiAppsDir(t)
+	counter := filepath.Join(t.TempDir(), "runs")
+	script := "printf x >> '" + counter + "'\necho migrated\n"
+
+	for range 2 {
+		if err := Runonce(script); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if runs := runCount(t, counter); runs != 1 {
+		t.Errorf("the script ran %d times, want once", runs)
+	}
+	index := readRunonceIndex(t, directory)
+	if _, ok := index.Completed[runonceHash(script)]; !ok || len(index.Failed) != 0 {
+		t.Errorf("index = %+v, want the script completed", index)
+	}
+	log, err := os.ReadFile(filepath.Join(directory, "logs", "runonce", runonceHash(script)+".log"))
+	if err != nil || !strings.Contains(string(log), "migrated") {
+		t.Errorf("the output was not logged: %q, %v", log, err)
+	}
+}
+
+func TestRunonceFailureRetry(t *testing.T) {
+	directory := newTestPiAppsDir(t)
+	counter := filepath.Join(t.TempDir(), "runs")
+	flag := filepath.Join(t.TempDir(), "fixed")
+	script := "printf x >> '" + counter + "'\necho checking\n[ -f '" + flag + "' ] || { echo 'not fixed yet' >&2; exit 3; }\n"
+	hash := runonceHash(script)
+
+	if err := Runonce(script); err == nil {
+		t.Fatal("a failing script returned no error")
+	}
+	index := readRunonceIndex(t, directory)
+	failure := index.Failed[hash]
+	if _, completed := index.Completed[hash]; completed || failure.Attempts != 1 || failure.ExitCode != 3 {
+		t.Fatalf("failure = %+v, want one attempt with exit code 3 and not completed", failure)
+	}
+	// stdout and stderr are read separately, so their lines may come in any order
+	lastOutput := slices.Sorted(slices.Values(failure.LastOutput))
+	if !slices.Equal(lastOutput, []string{"checking", "not fixed yet"}) {
+		t.Errorf("last output = %q", failure.LastOutput)
+	}
+
+	// The next invocation tries again, and the failure is cleared once it succeeds
+	writeTestFile(t, flag, "")
+	if err := Runonce(script); err != nil {
+		t.Fatal(err)
+	}
+	if runs := runCount(t, counter); runs != 2 {
+		t.Errorf("the script ran %d times, want twice", runs)
+	}
+	index = readRunonceIndex(t, directory)
+	if _, completed := index.Completed[hash]; !completed || len(index.Failed) != 0 {
+		t.Errorf("index = %+v, want the script completed and its failure removed", index)
+	}
+}
+
+func TestRunonceAttemptCap(t *testing.T) {
+	newTestPiAppsDir(t)
+	counter := filepath.Join(t.TempDir(), "runs")
+	script := "printf x >> '" + counter + "'\nexit 1\n"
+
+	for range 2 {
+		if err := RunonceWithOptions(script, RunonceOptions{MaxAttempts: 2}); err == nil || errors.Is(err, ErrRunonceGaveUp) {
+			t.Fatalf("attempt returned %v, want the error of the script", err)
+		}
+	}
+	if err := RunonceWithOptions(script, RunonceOptions{MaxAttempts: 2}); !errors.Is(err, ErrRunonceGaveUp) {
+		t.Errorf("third attempt returned %v, want ErrRunonceGaveUp", err)
+	}
+	if runs := runCount(t, counter); runs != 2 {
+		t.Errorf("the script ran %d times, want 2", runs)
+	}
+
+	// The cap also comes from PI_APPS_RUNONCE_ATTEMPTS
+	t.Setenv("PI_APPS_RUNONCE_ATTEMPTS", "3")
+	if err := Runonce(script); errors.Is(err, ErrRunonceGaveUp) {
+		t.Errorf("PI_APPS_RUNONCE_ATTEMPTS=3 gave up after 2 attempts: %v", err)
+	}
+	if err := Runonce(script); !errors.Is(err, ErrRunonceGaveUp) {
+		t.Errorf("fourth attempt returned %v, want ErrRunonceGaveUp", err)
+	}
+}
+
+func TestRunonceEnvironment(t *testing.T) {
+	directory := newTestPiAppsDir(t)
+	output := filepath.Join(t.TempDir(), "env")
+	t.Setenv("RUNONCE_TEST_SECRET", "leaked")
+	t.Setenv("RUNONCE_TEST_ALLOWED", "passed")
+	script := "env > '" + output + "'\n"
+
+	if err := RunonceWithOptions(script, RunonceOptions{Env: []string{"RUNONCE_TEST_ALLOWED"}}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	env := string(data)
+	for _, want := range []string{"PI_APPS_DIR=" + directory + "\n", "RUNONCE_TEST_ALLOWED=passed\n", "HOME=", "PATH="} {
+		if !strings.Contains(env, want) {
+			t.Errorf("the environment has no %q:\n%s", want, env)
+		}
+	}
+	if strings.Contains(env, "RUNONCE_TEST_SECRET") {
+		t.Errorf("a variable that was not whitelisted was passed:\n%s", env)
+	}
+}
+
+func TestRunonceLegacyIndex(t *testing.T) {
+	directory := newTestPiAppsDir(t)
+	counter := filepath.Join(t.TempDir(), "runs")
+	script := "printf x >> '" + counter + "'\n"
+	legacy := filepath.Join(directory, "data", legacyRunonceHashesFile)
+	writeTestFile(t, legacy, runonceHash("addUserDirs-v1")+"\n"+runonceHash(script)+"\n")
+
+	if err := Runonce(script); err != nil {
+		t.Fatal(err)
+	}
+	if runs := runCount(t, counter); runs != 0 {
+		t.Errorf("a script the legacy index records ran %d times", runs)
+	}
+	called := false
+	if err := RunonceFunc("addUserDirs-v1", func() error { called = true; return nil }); err != nil || called {
+		t.Errorf("RunonceFunc of a migrated version = %v, called %v", err, called)
+	}
+	if _, err := os.Stat(legacy); !os.IsNotExist(err) {
+		t.Errorf("the legacy index was not removed: %v", err)
+	}
+	if index := readRunonceIndex(t, directory); len(index.Completed) != 2 || index.Version != runonceIndexVersion {
+		t.Errorf("migrated index = %+v", index)
+	}
+}
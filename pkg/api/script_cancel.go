@@ -0,0 +1,175 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: script_cancel.go
+// Description: Lets the progress monitor stop the app script the manage daemon is running, and stops the commands
+// a script left running in the background once it exited.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package api
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// The app script runAppScript is waiting for, so CancelRunningScript can stop it
+var (
+	runningScriptMutex sync.Mutex
+	runningScript      *os.Process
+	scriptCancelled    bool
+)
+
+// setRunningScript records the process of the running app script, nil once it exited.
+// It returns whether the script that exited was cancelled.
+func setRunningScript(process *os.Process) bool {
+	runningScriptMutex.Lock()
+	defer runningScriptMutex.Unlock()
+	cancelled := scriptCancelled
+	runningScript, scriptCancelled = process, false
+	return cancelled
+}
+
+// CancelRunningScript stops the app script that runs in this process and the commands it started.
+// The script then fails with an errs.ErrCancelled error.
+//
+//	false - no app script is running
+//	true - the script was sent SIGTERM
+func CancelRunningScript() bool {
+	runningScriptMutex.Lock()
+	defer runningScriptMutex.Unlock()
+	if runningScript == nil {
+		return false
+	}
+	scriptCancelled = true
+
+	// Children first, so the script doesn't go on with the next command when one of them is stopped
+	pids := processDescendants(runningScript.Pid)
+	for i := len(pids) - 1; i >= 0; i-- {
+		syscall.Kill(pids[i], syscall.SIGTERM)
+	}
+	runningScript.Signal(syscall.SIGTERM)
+	return true
+}
+
+// processDescendants returns the children of a process and their children, parents before their children
+func processDescendants(pid int) []int {
+	children := make(map[int][]int)
+	entries, _ := os.ReadDir("/proc")
+	for _, entry := range entries {
+		child, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join("/proc", entry.Name(), "stat"))
+		if err != nil {
+			continue
+		}
+		// The command name in parentheses may contain spaces and parentheses, the parent PID is the second field after it
+		end := strings.LastIndex(string(data), ")")
+		if end == -1 {
+			continue
+		}
+		fields := strings.Fields(string(data)[end+1:])
+		if len(fields) < 2 {
+			continue
+		}
+		if parent, err := strconv.Atoi(fields[1]); err == nil {
+			children[parent] = append(children[parent], child)
+		}
+	}
+
+	var descendants []int
+	queue := []int{pid}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		descendants = append(descendants, children[current]...)
+		queue = append(queue, children[current]...)
+	}
+	return descendants
+}
+
+// scriptRunEnvVar marks the processes of one app script run, the commands the script starts inherit it.
+// Commands left running in the background are orphaned when the script exits, so they can't be found as its descendants.
+const scriptRunEnvVar = "PI_APPS_SCRIPT_RUN"
+
+// lingeringOutputDelay is how long the output of a script that exited is still read. A command the script left running
+// in the background keeps the output open, without a limit it would block the script from finishing or write
+// into the log of the next app in the queue.
+var lingeringOutputDelay = 2 * time.Second
+
+// markScriptRun marks the processes cmd starts as one script run and returns the marker for finishScriptRun.
+// It must be called after cmd.Env is set.
+func markScriptRun(cmd *exec.Cmd) string {
+	marker := fmt.Sprintf("%d-%d", os.Getpid(), time.Now().UnixNano())
+	if cmd.Env == nil {
+		cmd.Env = os.Environ()
+	}
+	cmd.Env = append(cmd.Env, scriptRunEnvVar+"="+marker)
+	cmd.WaitDelay = lingeringOutputDelay
+	return marker
+}
+
+// finishScriptRun stops the commands a script run marked with markScriptRun left running and notes them in its log.
+// err is the error of waiting for the script, a script that succeeded but left a command holding its output open succeeded.
+func finishScriptRun(err error, marker string, logFile io.Writer) error {
+	if errors.Is(err, exec.ErrWaitDelay) {
+		err = nil
+	}
+	if pids := stopMarkedProcesses(marker); len(pids) > 0 {
+		fmt.Fprintf(logFile, "\nStopped %d processes the script left running in the background: %v\n", len(pids), pids)
+		Debug(fmt.Sprintf("Stopped processes %v the script left running in the background", pids))
+	}
+	return err
+}
+
+// stopMarkedProcesses sends SIGTERM to the processes that carry marker in their environment and returns their PIDs.
+// Processes of other users can't be inspected and are left alone.
+func stopMarkedProcesses(marker string) []int {
+	entry := []byte(scriptRunEnvVar + "=" + marker)
+	entries, _ := os.ReadDir("/proc")
+	var pids []int
+	for _, procEntry := range entries {
+		pid, err := strconv.Atoi(procEntry.Name())
+		if err != nil || pid == os.Getpid() {
+			continue
+		}
+		environ, err := os.ReadFile(filepath.Join("/proc", procEntry.Name(), "environ"))
+		if err != nil {
+			continue
+		}
+		for _, variable := range bytes.Split(environ, []byte{0}) {
+			if bytes.Equal(variable, entry) {
+				if syscall.Kill(pid, syscall.SIGTERM) == nil {
+					pids = append(pids, pid)
+				}
+				break
+			}
+		}
+	}
+	return pids
+}
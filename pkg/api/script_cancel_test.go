@@ -0,0 +1,74 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInstallAppStopsBackgroundWriters(t *testing.T) {
+	previous := lingeringOutputDelay
+	lingeringOutputDelay = 200 * time.Millisecond
+	t.Cleanup(func() { lingeringOutputDelay = previous })
+
+	dir := newTestHealthDir(t, "Forks", "Next")
+	// The background writer keeps the output of the script open and writes after the script exited
+	writeTestFile(t, filepath.Join(dir, "apps", "Forks", "install"), "#!/bin/bash\necho installing\n(sleep 1; echo LATE OUTPUT) &\necho installed\n")
+	writeTestFile(t, filepath.Join(dir, "apps", "Next", "install"), "#!/bin/bash\necho next\n")
+	for _, app := range []string{"Forks", "Next"} {
+		writeTestFile(t, filepath.Join(dir, "apps", app, "uninstall"), "#!/bin/bash\n")
+	}
+
+	started := time.Now()
+	if err := InstallApp("Forks"); err != nil {
+		t.Fatalf("InstallApp(Forks) = %v", err)
+	}
+	if elapsed := time.Since(started); elapsed >= time.Second {
+		t.Errorf("InstallApp(Forks) waited %s for the background writer", elapsed)
+	}
+	forksLog := AppLogfileSince("Forks", started)
+
+	nextStarted := time.Now()
+	if err := InstallApp("Next"); err != nil {
+		t.Fatalf("InstallApp(Next) = %v", err)
+	}
+	nextLog := AppLogfileSince("Next", nextStarted)
+	// Long enough for the background writer to have written, had it not been stopped
+	time.Sleep(1500 * time.Millisecond)
+
+	data, err := os.ReadFile(forksLog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "installed") || !strings.Contains(string(data), "left running in the background") {
+		t.Errorf("log of Forks misses its output or the stopped background writer:\n%s", data)
+	}
+	if strings.Contains(string(data), "LATE OUTPUT") {
+		t.Errorf("log of Forks has output written after its script exited:\n%s", data)
+	}
+	data, err = os.ReadFile(nextLog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "next") || strings.Contains(string(data), "LATE OUTPUT") {
+		t.Errorf("log of Next is not its own:\n%s", data)
+	}
+}
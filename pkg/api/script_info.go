@@ -39,7 +39,7 @@ func UserCount(app string) (string, error) {
 		return "", fmt.Errorf("PI_APPS_DIR environment variable not set")
 	}
 
-	clicklistPath := filepath.Join(directory, "data", "clicklist")
+	clicklistPath := filepath.Join(DataDirOf(directory), "clicklist")
 
 	// Check if clicklist file is missing or older than a day
 	needsUpdate := false
@@ -63,8 +63,8 @@ func UserCount(app string) (string, error) {
 		StatusT("Downloading latest clicklist data...")
 
 		// Ensure the data directory exists
-		dataDir := filepath.Join(directory, "data")
-		if err := os.MkdirAll(dataDir, 0755); err != nil {
+		dataDir := DataDirOf(directory)
+		if err := EnsureDirOwned(dataDir, InvokingUser, InvokingUser); err != nil {
 			return "", fmt.Errorf("failed to create data directory: %w", err)
 		}
 
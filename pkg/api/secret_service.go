@@ -0,0 +1,281 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: secret_service.go
+// Description: Stores app secrets in the keyring of the desktop through the freedesktop Secret Service D-Bus API,
+// which GNOME Keyring, KWallet and KeePassXC implement.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package api
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	secretServiceName       = "org.freedesktop.secrets"
+	secretServicePath       = dbus.ObjectPath("/org/freedesktop/secrets")
+	secretDefaultCollection = dbus.ObjectPath("/org/freedesktop/secrets/aliases/default")
+	secretServiceInterface  = "org.freedesktop.Secret.Service"
+	secretItemInterface     = "org.freedesktop.Secret.Item"
+	secretPromptInterface   = "org.freedesktop.Secret.Prompt"
+
+	// secretApplication is the application attribute of the items Pi-Apps stores, next to app and key
+	secretApplication = "pi-apps"
+	// secretPromptTimeout is how long to wait for the user to unlock the keyring
+	secretPromptTimeout = 2 * time.Minute
+)
+
+// secretServiceSecret is the Secret struct of the Secret Service API
+type secretServiceSecret struct {
+	Session     dbus.ObjectPath
+	Parameters  []byte
+	Value       []byte
+	ContentType string
+}
+
+// secretService is a session with the keyring daemon, using the plain algorithm as the private session bus
+// connection already keeps the secrets to this user
+type secretService struct {
+	conn    *dbus.Conn
+	session dbus.ObjectPath
+}
+
+// storedSecret is a secret found in the keyring
+type storedSecret struct {
+	app   string
+	key   string
+	value string
+}
+
+// connectSecretService is replaced by tests to keep the keyring of the user running them out of them
+var connectSecretService = openSecretService
+
+// openSecretService opens a session with the keyring daemon, nil when none is running. A daemon that only
+// starts through D-Bus activation is left alone, as it would open a keyring the user doesn't use.
+func openSecretService() *secretService {
+	conn, err := dbus.ConnectSessionBus()
+	if err != nil {
+		return nil
+	}
+	var running bool
+	err = conn.BusObject().Call("org.freedesktop.DBus.NameHasOwner", 0, secretServiceName).Store(&running)
+	if err != nil || !running {
+		conn.Close()
+		return nil
+	}
+	var output dbus.Variant
+	var session dbus.ObjectPath
+	err = conn.Object(secretServiceName, secretServicePath).Call(secretServiceInterface+".OpenSession", 0, "plain", dbus.MakeVariant("")).Store(&output, &session)
+	if err != nil {
+		Debug(fmt.Sprintf("Failed to open a Secret Service session: %v", err))
+		conn.Close()
+		return nil
+	}
+	return &secretService{conn: conn, session: session}
+}
+
+// Close ends the session and the connection
+func (s *secretService) Close() {
+	s.conn.Object(secretServiceName, s.session).Call("org.freedesktop.Secret.Session.Close", 0)
+	s.conn.Close()
+}
+
+// secretAttributes returns the lookup attributes of a secret, or of all secrets of an app when key is ""
+func secretAttributes(app, key string) map[string]string {
+	attributes := map[string]string{"application": secretApplication}
+	if app != "" {
+		attributes["app"] = app
+	}
+	if key != "" {
+		attributes["key"] = key
+	}
+	return attributes
+}
+
+// search returns the items matching attributes. Locked items are unlocked first when unlock is true, which may
+// ask the user for the keyring password, and left out otherwise.
+func (s *secretService) search(attributes map[string]string, unlock bool) ([]dbus.ObjectPath, error) {
+	var unlocked, locked []dbus.ObjectPath
+	err := s.conn.Object(secretServiceName, secretServicePath).Call(secretServiceInterface+".SearchItems", 0, attributes).Store(&unlocked, &locked)
+	if err != nil {
+		return nil, err
+	}
+	if len(locked) > 0 && unlock {
+		if err := s.unlock(locked); err != nil {
+			return nil, err
+		}
+		unlocked = append(unlocked, locked...)
+	}
+	return unlocked, nil
+}
+
+// unlock unlocks items or collections, asking the user for the keyring password when the daemon needs it
+func (s *secretService) unlock(objects []dbus.ObjectPath) error {
+	var unlocked []dbus.ObjectPath
+	var prompt dbus.ObjectPath
+	err := s.conn.Object(secretServiceName, secretServicePath).Call(secretServiceInterface+".Unlock", 0, objects).Store(&unlocked, &prompt)
+	if err != nil {
+		return err
+	}
+	return s.prompt(prompt)
+}
+
+// prompt shows a prompt of the daemon and waits for the user to complete it. "/" means no prompt is needed.
+func (s *secretService) prompt(prompt dbus.ObjectPath) error {
+	if prompt == "" || prompt == "/" {
+		return nil
+	}
+	match := []dbus.MatchOption{dbus.WithMatchObjectPath(prompt), dbus.WithMatchInterface(secretPromptInterface), dbus.WithMatchMember("Completed")}
+	if err := s.conn.AddMatchSignal(match...); err != nil {
+		return err
+	}
+	defer s.conn.RemoveMatchSignal(match...)
+	signals := make(chan *dbus.Signal, 1)
+	s.conn.Signal(signals)
+	defer s.conn.RemoveSignal(signals)
+
+	if err := s.conn.Object(secretServiceName, prompt).Call(secretPromptInterface+".Prompt", 0, "").Err; err != nil {
+		return err
+	}
+	timeout := time.After(secretPromptTimeout)
+	for {
+		select {
+		case signal := <-signals:
+			if signal.Path != prompt || len(signal.Body) == 0 {
+				continue
+			}
+			if dismissed, _ := signal.Body[0].(bool); dismissed {
+				return fmt.Errorf("the keyring was not unlocked")
+			}
+			return nil
+		case <-timeout:
+			return fmt.Errorf("timed out waiting for the keyring to be unlocked")
+		}
+	}
+}
+
+// get returns the value of a secret, false if the keyring has none
+func (s *secretService) get(app, key string) (string, bool, error) {
+	items, err := s.search(secretAttributes(app, key), true)
+	if err != nil || len(items) == 0 {
+		return "", false, err
+	}
+	value, err := s.secret(items[0])
+	return value, err == nil, err
+}
+
+// secret returns the value of an unlocked item
+func (s *secretService) secret(item dbus.ObjectPath) (string, error) {
+	var secret secretServiceSecret
+	if err := s.conn.Object(secretServiceName, item).Call(secretItemInterface+".GetSecret", 0, s.session).Store(&secret); err != nil {
+		return "", err
+	}
+	return string(secret.Value), nil
+}
+
+// set stores a secret in the default collection, replacing the one with the same app and key
+func (s *secretService) set(app, key, value string) error {
+	collection := s.conn.Object(secretServiceName, secretDefaultCollection)
+	if locked, err := collection.GetProperty("org.freedesktop.Secret.Collection.Locked"); err == nil {
+		if isLocked, _ := locked.Value().(bool); isLocked {
+			if err := s.unlock([]dbus.ObjectPath{secretDefaultCollection}); err != nil {
+				return err
+			}
+		}
+	}
+	properties := map[string]dbus.Variant{
+		"org.freedesktop.Secret.Item.Label":      dbus.MakeVariant(fmt.Sprintf("Pi-Apps: %s %s", app, key)),
+		"org.freedesktop.Secret.Item.Attributes": dbus.MakeVariant(secretAttributes(app, key)),
+	}
+	secret := secretServiceSecret{Session: s.session, Value: []byte(value), ContentType: "text/plain"}
+	var item, prompt dbus.ObjectPath
+	if err := collection.Call("org.freedesktop.Secret.Collection.CreateItem", 0, properties, secret, true).Store(&item, &prompt); err != nil {
+		return err
+	}
+	return s.prompt(prompt)
+}
+
+// delete removes the secrets of an app from the keyring
+func (s *secretService) delete(app string) error {
+	items, err := s.search(secretAttributes(app, ""), true)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		var prompt dbus.ObjectPath
+		if err := s.conn.Object(secretServiceName, item).Call(secretItemInterface+".Delete", 0).Store(&prompt); err != nil {
+			return err
+		}
+		if err := s.prompt(prompt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// keys returns the keys of the secrets of an app, including those of locked items as their attributes are not
+// secret
+func (s *secretService) keys(app string) ([]string, error) {
+	var unlocked, locked []dbus.ObjectPath
+	err := s.conn.Object(secretServiceName, secretServicePath).Call(secretServiceInterface+".SearchItems", 0, secretAttributes(app, "")).Store(&unlocked, &locked)
+	if err != nil {
+		return nil, err
+	}
+	var keys []string
+	for _, item := range append(unlocked, locked...) {
+		if attributes, err := s.attributes(item); err == nil && attributes["key"] != "" {
+			keys = append(keys, attributes["key"])
+		}
+	}
+	return keys, nil
+}
+
+// attributes returns the lookup attributes of an item
+func (s *secretService) attributes(item dbus.ObjectPath) (map[string]string, error) {
+	variant, err := s.conn.Object(secretServiceName, item).GetProperty(secretItemInterface + ".Attributes")
+	if err != nil {
+		return nil, err
+	}
+	attributes, ok := variant.Value().(map[string]string)
+	if !ok {
+		return nil, fmt.Errorf("unexpected attributes of %s", item)
+	}
+	return attributes, nil
+}
+
+// unlockedSecrets returns the secrets of all apps the keyring has unlocked, without asking the user to unlock
+// the others
+func (s *secretService) unlockedSecrets() []storedSecret {
+	items, err := s.search(secretAttributes("", ""), false)
+	if err != nil {
+		return nil
+	}
+	var secrets []storedSecret
+	for _, item := range items {
+		attributes, err := s.attributes(item)
+		if err != nil {
+			continue
+		}
+		if value, err := s.secret(item); err == nil {
+			secrets = append(secrets, storedSecret{app: attributes["app"], key: attributes["key"], value: value})
+		}
+	}
+	return secrets
+}
@@ -0,0 +1,278 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: settings_file.go
+// Description: Declares the values every setting accepts, reads the settings files defensively and writes them atomically.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package api
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// SettingKind is the kind of values a setting accepts
+type SettingKind string
+
+const (
+	// SettingChoice accepts one of the values of the setting
+	SettingChoice SettingKind = "choice"
+	// SettingInteger accepts a whole number from Min to Max, the values of the setting are suggestions
+	SettingInteger SettingKind = "integer"
+	// SettingFree accepts what the Valid function of the setting accepts, the values of the setting are suggestions
+	SettingFree SettingKind = "free"
+)
+
+// SettingSpec declares the values a setting in data/settings accepts and the one it falls back to
+type SettingSpec struct {
+	Name     string
+	Kind     SettingKind
+	Values   []string
+	Default  string
+	Min, Max int64             // range of a SettingInteger
+	Valid    func(string) bool // check of a SettingFree
+}
+
+// onCompletePattern matches the values of the "Manage terminal on completion" setting, see gui.ParseOnCompletePolicy
+var onCompletePattern = regexp.MustCompile(`^(keep|close|close-on-success|timeout:[0-9]+)$`)
+
+// settingSpecs are the settings of Pi-Apps. The settings window lists them with descriptions of its own, its
+// definitions have to use the same defaults.
+var settingSpecs = []SettingSpec{
+	{Name: addEnglishLocaleSetting, Kind: SettingChoice, Values: []string{"Yes", "No"}, Default: "Yes"},
+	// The themes installed on the system are valid too, so any name of a theme folder is accepted
+	{Name: "App List Style", Kind: SettingFree, Values: []string{"yad-default", "yad-light", "yad-dark", "xlunch-dark", "xlunch-dark-3d", "xlunch-light-3d"}, Default: "yad-default", Valid: validThemeName},
+	{Name: "Check for updates", Kind: SettingChoice, Values: []string{"Daily", "Always", "Weekly", "Never"}, Default: "Daily"},
+	{Name: "Color output", Kind: SettingChoice, Values: []string{"Auto", "Always", "Never"}, Default: "Auto"},
+	// In MB, 0 turns the asset cache off
+	{Name: assetCacheSizeSetting, Kind: SettingInteger, Values: []string{"2048", "0", "512", "1024", "5120", "10240"}, Default: "2048", Min: 0, Max: 1 << 20},
+	// In KB/s, 0 is unlimited. 1 GB/s is faster than any connection a Pi-Apps device has.
+	{Name: downloadLimitSetting, Kind: SettingInteger, Values: []string{"0", "256", "512", "1024", "2048", "5120"}, Default: "0", Min: 0, Max: 1 << 20},
+	{Name: "Enable analytics", Kind: SettingChoice, Values: []string{"Yes", "No"}, Default: "Yes"},
+	{Name: "Enable download ledger", Kind: SettingChoice, Values: []string{"Yes", "No"}, Default: "Yes"},
+	{Name: "Enable update rollback", Kind: SettingChoice, Values: []string{"Yes", "No"}, Default: "Yes"},
+	{Name: "High contrast theme", Kind: SettingChoice, Values: []string{"No", "Yes"}, Default: "No"},
+	{Name: "Install resource limits", Kind: SettingFree, Values: []string{"App defaults", "No", "CPUQuota=300% MemoryMax=80% IOWeight=50", "CPUQuota=200% MemoryMax=60% IOWeight=25"}, Default: "App defaults", Valid: validResourceLimitsSetting},
+	{Name: "Manage terminal on completion", Kind: SettingFree, Values: []string{"keep", "close", "close-on-success", "timeout:10", "timeout:30", "timeout:60"}, Default: "keep", Valid: onCompletePattern.MatchString},
+	{Name: "Preferred text editor", Kind: SettingChoice, Values: []string{"geany", "mousepad", "leafpad", "nano", "Visual Studio Code", "VSCodium"}, Default: "geany"},
+	{Name: "Recheck app health", Kind: SettingChoice, Values: []string{"No", "Yes"}, Default: "No"},
+	{Name: "Share install statistics", Kind: SettingChoice, Values: []string{"No", "Yes"}, Default: "No"},
+	{Name: "Show apps", Kind: SettingChoice, Values: []string{"All", "packages", "standard"}, Default: "All"},
+	{Name: "Show Edit button", Kind: SettingChoice, Values: []string{"No", "Yes"}, Default: "No"},
+	{Name: "Show progress in tray", Kind: SettingChoice, Values: []string{"No", "Yes"}, Default: "No"},
+	{Name: "Shuffle App list", Kind: SettingChoice, Values: []string{"No", "Yes"}, Default: "No"},
+	{Name: "Status symbols", Kind: SettingChoice, Values: []string{"Auto", "Yes", "No"}, Default: "Auto"},
+	{Name: "Sync only usable apps", Kind: SettingChoice, Values: []string{"No", "Yes"}, Default: "No"},
+	{Name: unavailableAppsSetting, Kind: SettingChoice, Values: []string{"Hide", "Show"}, Default: "Hide"},
+}
+
+// validThemeName reports whether a value of the "App List Style" setting can be the name of a theme folder
+func validThemeName(value string) bool {
+	return !strings.ContainsAny(value, "/\\") && value != "." && value != ".."
+}
+
+// validResourceLimitsSetting reports whether a value of the "Install resource limits" setting is No, App defaults
+// or systemd properties parseResourceLimits knows
+func validResourceLimitsSetting(value string) bool {
+	if value == "No" || value == "App defaults" {
+		return true
+	}
+	for _, property := range strings.Fields(value) {
+		key, limit, _ := strings.Cut(property, "=")
+		if limit == "" || !slices.Contains([]string{"CPUQuota", "MemoryMax", "IOWeight"}, key) {
+			return false
+		}
+	}
+	return true
+}
+
+// SettingSpecs returns the declarations of all settings
+func SettingSpecs() []SettingSpec {
+	return slices.Clone(settingSpecs)
+}
+
+// LookupSetting returns the declaration of a setting
+func LookupSetting(name string) (SettingSpec, bool) {
+	index := slices.IndexFunc(settingSpecs, func(spec SettingSpec) bool { return spec.Name == name })
+	if index < 0 {
+		return SettingSpec{}, false
+	}
+	return settingSpecs[index], true
+}
+
+// Validate returns an error if a setting doesn't accept a value. Surrounding spaces are ignored like when reading it.
+func (s SettingSpec) Validate(value string) error {
+	value = strings.TrimSpace(value)
+	switch {
+	case value == "":
+		return fmt.Errorf("%s: empty value", s.Name)
+	case strings.ContainsAny(value, "\r\n"):
+		return fmt.Errorf("%s: value spans several lines", s.Name)
+	}
+	switch s.Kind {
+	case SettingChoice:
+		if !slices.Contains(s.Values, value) {
+			return fmt.Errorf("%s: %q is not one of %s", s.Name, value, strings.Join(s.Values, ", "))
+		}
+	case SettingInteger:
+		number, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || number < s.Min || number > s.Max {
+			return fmt.Errorf("%s: %q is not a whole number from %d to %d", s.Name, value, s.Min, s.Max)
+		}
+	case SettingFree:
+		if s.Valid != nil && !s.Valid(value) {
+			return fmt.Errorf("%s: invalid value %q", s.Name, value)
+		}
+	}
+	return nil
+}
+
+// invalidSettingsWarned are the settings ReadSetting warned about, so a setting read for every message warns once
+var invalidSettingsWarned sync.Map
+
+// ReadSetting returns the value of a setting of the Pi-Apps directory, see ReadSettingIn
+func ReadSetting(name string) string {
+	return ReadSettingIn(GetPiAppsDir(), name)
+}
+
+// ReadSettingIn returns the value of a setting in data/settings of a Pi-Apps directory. A missing settings file gives
+// the default of the setting, and so does a file that can't be read or holds a value the setting doesn't accept, like
+// an empty file after a power cut, with a warning on stderr. Settings that aren't declared are returned as they are.
+func ReadSettingIn(directory, name string) string {
+	spec, declared := LookupSetting(name)
+	if directory == "" {
+		return spec.Default
+	}
+	data, err := os.ReadFile(filepath.Join(DataDirOf(directory), "settings", name))
+	if os.IsNotExist(err) {
+		return spec.Default
+	}
+	value := strings.TrimSpace(string(data))
+	if err == nil && declared {
+		err = spec.Validate(value)
+	}
+	if err == nil {
+		return value
+	}
+
+	// The messages functions read settings themselves, so the warning is written directly
+	if _, warned := invalidSettingsWarned.LoadOrStore(name, true); !warned {
+		fmt.Fprintln(os.Stderr, Tf("Warning: ignoring the %s setting, using %q: %v", name, spec.Default, err))
+	}
+	return spec.Default
+}
+
+// WriteSetting saves the value of a setting of the Pi-Apps directory, see WriteSettingIn
+func WriteSetting(name, value string) error {
+	return WriteSettingIn(GetPiAppsDir(), name, value)
+}
+
+// WriteSettingIn saves the value of a setting in data/settings of a Pi-Apps directory. Declared settings only take
+// values they accept. The file is replaced atomically, so a crash leaves the old or the new value, never a part of it.
+func WriteSettingIn(directory, name, value string) error {
+	if directory == "" {
+		return fmt.Errorf("PI_APPS_DIR environment variable not set")
+	}
+	if spec, ok := LookupSetting(name); ok {
+		if err := spec.Validate(value); err != nil {
+			return err
+		}
+	}
+	settingsDir := filepath.Join(DataDirOf(directory), "settings")
+	if err := os.MkdirAll(settingsDir, 0755); err != nil {
+		return fmt.Errorf("failed to create the settings directory: %w", err)
+	}
+	return WriteFileAtomic(filepath.Join(settingsDir, name), []byte(strings.TrimSpace(value)), 0644)
+}
+
+// WriteFileAtomic writes a file like os.WriteFile, but through a temporary file in the same directory that is
+// synced to disk and renamed over the file, so readers and crashes see either the old or the new content
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	temp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	// After the rename, this fails harmlessly
+	defer os.Remove(temp.Name())
+
+	if _, err := temp.Write(data); err != nil {
+		temp.Close()
+		return err
+	}
+	if err := temp.Chmod(perm); err != nil {
+		temp.Close()
+		return err
+	}
+	if err := temp.Sync(); err != nil {
+		temp.Close()
+		return err
+	}
+	if err := temp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(temp.Name(), path); err != nil {
+		return err
+	}
+
+	// The rename itself is only on disk once the directory is
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+	return nil
+}
+
+// SettingFix is a settings file RepairSettings reset to the default of its setting
+type SettingFix struct {
+	Name    string `json:"name"`
+	Value   string `json:"value"` // the invalid value, as it was in the file
+	Default string `json:"default"`
+}
+
+// RepairSettings checks the settings files of a Pi-Apps directory and rewrites those with a value their setting
+// doesn't accept with its default. Missing settings files are left for the settings refresh to create.
+//
+//	[]SettingFix - the settings that were reset, in the order of SettingSpecs
+//	error - error if a settings file couldn't be rewritten
+func RepairSettings(directory string) ([]SettingFix, error) {
+	if directory == "" {
+		return nil, fmt.Errorf("PI_APPS_DIR environment variable not set")
+	}
+	settingsDir := filepath.Join(DataDirOf(directory), "settings")
+	var fixes []SettingFix
+	for _, spec := range settingSpecs {
+		data, err := os.ReadFile(filepath.Join(settingsDir, spec.Name))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err == nil && spec.Validate(string(data)) == nil {
+			continue
+		}
+		if err := WriteSettingIn(directory, spec.Name, spec.Default); err != nil {
+			return fixes, fmt.Errorf("failed to reset the %s setting: %w", spec.Name, err)
+		}
+		fixes = append(fixes, SettingFix{Name: spec.Name, Value: strings.TrimSpace(string(data)), Default: spec.Default})
+	}
+	return fixes, nil
+}
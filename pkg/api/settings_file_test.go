@@ -0,0 +1,185 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSettingSpecsAcceptTheirValues(t *testing.T) {
+	for _, spec := range SettingSpecs() {
+		if err := spec.Validate(spec.Default); err != nil {
+			t.Errorf("default of %s: %v", spec.Name, err)
+		}
+		for _, value := range spec.Values {
+			if err := spec.Validate(value); err != nil {
+				t.Errorf("value of %s: %v", spec.Name, err)
+			}
+		}
+	}
+}
+
+// invalidSettingValues returns values a setting doesn't accept: empty, truncated and out of range ones
+func invalidSettingValues(spec SettingSpec) []string {
+	values := []string{"", "   ", "\n", spec.Default + "\n" + spec.Default}
+	switch spec.Kind {
+	case SettingChoice:
+		// A value cut short by a power cut
+		for _, value := range spec.Values {
+			if truncated := value[:len(value)-1]; !slices.Contains(spec.Values, truncated) {
+				values = append(values, truncated)
+			}
+		}
+		values = append(values, "Maybe")
+	case SettingInteger:
+		values = append(values, "-1", "12a", "1.5", "99999999999999999999")
+	case SettingFree:
+		values = append(values, "../../etc", "timeout:")
+	}
+	return values
+}
+
+func TestReadSettingFallsBackToDefault(t *testing.T) {
+	directory := newTestPiAppsDir(t)
+	settingsDir := filepath.Join(directory, "data", "settings")
+	for _, spec := range SettingSpecs() {
+		if got := ReadSettingIn(directory, spec.Name); got != spec.Default {
+			t.Errorf("%s without its file = %q, want %q", spec.Name, got, spec.Default)
+		}
+		for _, value := range spec.Values {
+			writeTestFile(t, filepath.Join(settingsDir, spec.Name), value+"\n")
+			if got := ReadSettingIn(directory, spec.Name); got != value {
+				t.Errorf("%s set to %q = %q", spec.Name, value, got)
+			}
+		}
+		for _, value := range invalidSettingValues(spec) {
+			if spec.Validate(value) == nil {
+				continue
+			}
+			writeTestFile(t, filepath.Join(settingsDir, spec.Name), value)
+			if got := ReadSettingIn(directory, spec.Name); got != spec.Default {
+				t.Errorf("%s set to %q = %q, want the default %q", spec.Name, value, got, spec.Default)
+			}
+		}
+	}
+}
+
+func TestSettingSpecRanges(t *testing.T) {
+	spec, ok := LookupSetting(downloadLimitSetting)
+	if !ok {
+		t.Fatal("the download limit setting is not declared")
+	}
+	for value, valid := range map[string]bool{"0": true, " 300 ": true, "1048576": true, "1048577": false, "-5": false} {
+		if err := spec.Validate(value); (err == nil) != valid {
+			t.Errorf("Validate(%q) = %v, want valid %v", value, err, valid)
+		}
+	}
+
+	spec, _ = LookupSetting("Manage terminal on completion")
+	for value, valid := range map[string]bool{"timeout:5": true, "close": true, "timeout:": false, "Close": false} {
+		if err := spec.Validate(value); (err == nil) != valid {
+			t.Errorf("Validate(%q) = %v, want valid %v", value, err, valid)
+		}
+	}
+}
+
+func TestWriteSettingValidatesAndReplacesAtomically(t *testing.T) {
+	directory := newTestPiAppsDir(t)
+	path := filepath.Join(directory, "data", "settings", "Show apps")
+	if err := WriteSettingIn(directory, "Show apps", "packages"); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteSettingIn(directory, "Show apps", "some"); err == nil {
+		t.Error("WriteSettingIn saved a value the setting doesn't accept")
+	}
+	if data, _ := os.ReadFile(path); string(data) != "packages" {
+		t.Errorf("setting file = %q, want packages", data)
+	}
+
+	// Settings that aren't declared take any value
+	if err := WriteSettingIn(directory, "Custom setting", "anything"); err != nil {
+		t.Error(err)
+	}
+
+	// No temporary files are left behind
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") {
+			t.Errorf("temporary file %s left in the settings directory", entry.Name())
+		}
+	}
+}
+
+func TestWriteFileAtomicKeepsMode(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file")
+	writeTestFile(t, path, "old content that is longer")
+	if err := WriteFileAtomic(path, []byte("new"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "new" || info.Mode().Perm() != 0600 {
+		t.Errorf("file = %q with mode %v, want \"new\" with 0600", data, info.Mode().Perm())
+	}
+}
+
+func TestRepairSettings(t *testing.T) {
+	directory := newTestPiAppsDir(t)
+	settingsDir := filepath.Join(directory, "data", "settings")
+	writeTestFile(t, filepath.Join(settingsDir, "App List Style"), "")
+	writeTestFile(t, filepath.Join(settingsDir, "Check for updates"), "Dai")
+	writeTestFile(t, filepath.Join(settingsDir, downloadLimitSetting), "-20\n")
+	writeTestFile(t, filepath.Join(settingsDir, "Show apps"), "packages\n")
+
+	fixes, err := RepairSettings(directory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []SettingFix{
+		{Name: "App List Style", Value: "", Default: "yad-default"},
+		{Name: "Check for updates", Value: "Dai", Default: "Daily"},
+		{Name: downloadLimitSetting, Value: "-20", Default: "0"},
+	}
+	if !slices.Equal(fixes, want) {
+		t.Errorf("RepairSettings fixed %v, want %v", fixes, want)
+	}
+	for _, fix := range want {
+		if data, _ := os.ReadFile(filepath.Join(settingsDir, fix.Name)); string(data) != fix.Default {
+			t.Errorf("%s after the repair = %q, want %q", fix.Name, data, fix.Default)
+		}
+	}
+
+	// Valid and missing settings are left alone
+	if data, _ := os.ReadFile(filepath.Join(settingsDir, "Show apps")); string(data) != "packages\n" {
+		t.Errorf("valid setting rewritten to %q", data)
+	}
+	if _, err := os.Stat(filepath.Join(settingsDir, "Color output")); !os.IsNotExist(err) {
+		t.Errorf("missing setting created: %v", err)
+	}
+	if fixes, err := RepairSettings(directory); len(fixes) != 0 || err != nil {
+		t.Errorf("second RepairSettings = %v, %v, want nothing to fix", fixes, err)
+	}
+}
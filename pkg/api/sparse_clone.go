@@ -0,0 +1,84 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: sparse_clone.go
+// Description: Lists the apps of an update clone that only checks out the apps usable on this system.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package api
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// IsSparseClone reports whether a clone only checks out part of its apps, see the "Sync only usable apps" setting.
+// The updater removes the sparse-checkout file when it turns a clone back into a full one.
+func IsSparseClone(cloneDir string) bool {
+	return FileExists(filepath.Join(cloneDir, ".git", "info", "sparse-checkout"))
+}
+
+// sparseTreeApps caches the apps in the git tree of a sparse clone, until its index changes
+var sparseTreeApps struct {
+	sync.Mutex
+	dir     string
+	indexed time.Time
+	apps    map[string]bool
+}
+
+// cloneTreeApps returns the apps in the git tree of a clone, checked out or not. Only the trees of the clone are
+// read, so the files of apps left out of a sparse clone aren't downloaded.
+func cloneTreeApps(cloneDir string) map[string]bool {
+	info, err := os.Stat(filepath.Join(cloneDir, ".git", "index"))
+	if err != nil {
+		return nil
+	}
+	sparseTreeApps.Lock()
+	defer sparseTreeApps.Unlock()
+	if sparseTreeApps.dir == cloneDir && sparseTreeApps.indexed.Equal(info.ModTime()) {
+		return sparseTreeApps.apps
+	}
+
+	output, err := exec.Command("git", "-C", cloneDir, "ls-tree", "-d", "-z", "--name-only", "HEAD", "apps/").Output()
+	if err != nil {
+		Debug("Failed to list the apps of " + cloneDir + ": " + err.Error())
+		return nil
+	}
+	apps := make(map[string]bool)
+	for _, path := range strings.Split(string(output), "\x00") {
+		if app, ok := strings.CutPrefix(path, "apps/"); ok && app != "" {
+			apps[app] = true
+		}
+	}
+	sparseTreeApps.dir = cloneDir
+	sparseTreeApps.indexed = info.ModTime()
+	sparseTreeApps.apps = apps
+	return apps
+}
+
+// officialAppExists reports whether an app is in the update clone of the official repository. Apps a sparse
+// clone didn't check out still exist there.
+func officialAppExists(directory, app string) bool {
+	cloneDir := filepath.Join(directory, "update", "pi-apps")
+	if DirExists(filepath.Join(cloneDir, "apps", app)) {
+		return true
+	}
+	return IsSparseClone(cloneDir) && cloneTreeApps(cloneDir)[app]
+}
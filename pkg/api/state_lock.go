@@ -0,0 +1,51 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: state_lock.go
+// Description: Takes the lock of pkg/api/statelock around the operations that change apps, so Pi-Apps processes don't interleave.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package api
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pi-apps-go/pi-apps/pkg/api/statelock"
+)
+
+// stateLockTimeout is how long an operation waits for another Pi-Apps process, long enough for most app installs
+const stateLockTimeout = 30 * time.Minute
+
+func init() {
+	statelock.DataDir = DataDirOf
+}
+
+// LockState waits until no other Pi-Apps process changes the state of the Pi-Apps directory and locks it
+// for an operation, like "install Zoom". Release the returned lock once the operation is done.
+func LockState(operation string) (*statelock.Lock, error) {
+	directory := GetPiAppsDir()
+	if directory == "" {
+		return nil, fmt.Errorf("PI_APPS_DIR environment variable not set")
+	}
+	lock, err := statelock.Acquire(directory, operation, stateLockTimeout, func(owner statelock.Owner) {
+		StatusTf("Waiting for another Pi-Apps operation to finish: %s", owner)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock the Pi-Apps directory for %s: %w", operation, err)
+	}
+	return lock, nil
+}
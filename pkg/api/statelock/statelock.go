@@ -0,0 +1,271 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: statelock.go
+// Description: A lock on the Pi-Apps directory, so two Pi-Apps processes never change apps, settings or files at the same time.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+// Package statelock keeps Pi-Apps processes from changing the state of a Pi-Apps directory at the same time,
+// like a manage command installing an app while the daemon of the GUI runs apt for another one.
+//
+// Operations that change state hold the lock while they run:
+//
+//	lock, err := statelock.Acquire(directory, "install Zoom", time.Hour, nil)
+//	if err != nil {
+//		return err
+//	}
+//	defer lock.Release()
+//
+// The lock is an advisory flock on data/.lock, which the kernel releases when its process exits,
+// so a crashed process never leaves a stale lock behind. Who holds it is written next to it in data/.lock.owner.
+// The lock belongs to the process: nested operations of the process holding it, and the scripts and commands
+// it starts while they run as its descendants, don't wait for it. Processes it starts to keep running on their
+// own get an environment without EnvHolder from DetachedEnv. Reading the state needs no lock. This package only uses the standard library,
+// so every Pi-Apps package can import it.
+package statelock
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// EnvHolder is the environment variable telling the processes started by the holder of the lock
+// which process holds it, so they run under its lock instead of waiting for it. It is only trusted
+// while the holder is an ancestor of the process.
+const EnvHolder = "PI_APPS_STATE_LOCK"
+
+// pollInterval is how often Acquire tries again while another process holds the lock
+const pollInterval = 250 * time.Millisecond
+
+// Owner is the process holding the lock
+type Owner struct {
+	PID       int
+	Operation string
+	Since     time.Time
+}
+
+// String describes the owner for messages, like "install Zoom (PID 1234)"
+func (o Owner) String() string {
+	if o.Operation == "" {
+		return fmt.Sprintf("PID %d", o.PID)
+	}
+	return fmt.Sprintf("%s (PID %d)", o.Operation, o.PID)
+}
+
+// TimeoutError is returned by Acquire when another process held the lock for longer than the timeout
+type TimeoutError struct {
+	Owner Owner
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("another Pi-Apps operation is still running: %s", e.Owner)
+}
+
+// Lock is an acquired lock, release it with Release
+type Lock struct {
+	released bool
+}
+
+var (
+	// mutex guards the lock of this process
+	mutex sync.Mutex
+	// file is the locked file while this process holds the lock
+	file *os.File
+	// depth counts the Acquire calls of this process that are not released yet
+	depth int
+	// lockDir is the data directory of the lock this process holds
+	lockDir string
+)
+
+// DataDir returns the data directory of a Pi-Apps directory, which holds the lock. The api package sets it,
+// as the data of a read-only Pi-Apps directory is kept in the home folder of each user.
+var DataDir = func(directory string) string {
+	return filepath.Join(directory, "data")
+}
+
+// lockPath returns the file that is locked
+func lockPath(directory string) string {
+	return filepath.Join(DataDir(directory), ".lock")
+}
+
+// ownerPath returns the file recording who holds the lock
+func ownerPath(directory string) string {
+	return filepath.Join(DataDir(directory), ".lock.owner")
+}
+
+// Acquire locks the state of a Pi-Apps directory for an operation, like "install Zoom". While another process
+// holds the lock, waiting is called once with its owner and Acquire tries again until timeout has passed.
+// A timeout of 0 doesn't wait at all.
+func Acquire(directory, operation string, timeout time.Duration, waiting func(Owner)) (*Lock, error) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	if depth > 0 {
+		if lockDir != directory {
+			return nil, fmt.Errorf("this process already holds the lock of %s", lockDir)
+		}
+		depth++
+		return &Lock{}, nil
+	}
+
+	if err := os.MkdirAll(DataDir(directory), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create the data directory: %w", err)
+	}
+	f, err := os.OpenFile(lockPath(directory), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the Pi-Apps lock: %w", err)
+	}
+
+	deadline := time.Now().Add(timeout)
+	announced := false
+	for {
+		err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, syscall.EWOULDBLOCK) {
+			f.Close()
+			return nil, fmt.Errorf("failed to lock the Pi-Apps directory: %w", err)
+		}
+
+		owner, _ := CurrentOwner(directory)
+		// A parent process holding the lock runs this one as part of its operation
+		if holder, err := strconv.Atoi(os.Getenv(EnvHolder)); err == nil && holder == owner.PID && holder != os.Getpid() && IsAncestor(holder) {
+			f.Close()
+			return &Lock{released: true}, nil
+		}
+		if time.Now().After(deadline) {
+			f.Close()
+			return nil, &TimeoutError{Owner: owner}
+		}
+		if !announced && waiting != nil {
+			waiting(owner)
+		}
+		announced = true
+		time.Sleep(pollInterval)
+	}
+
+	owner := fmt.Sprintf("%d\n%s\n%d\n", os.Getpid(), strings.ReplaceAll(operation, "\n", " "), time.Now().Unix())
+	if err := os.WriteFile(ownerPath(directory), []byte(owner), 0644); err != nil {
+		syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+		f.Close()
+		return nil, fmt.Errorf("failed to record the owner of the Pi-Apps lock: %w", err)
+	}
+	os.Setenv(EnvHolder, strconv.Itoa(os.Getpid()))
+	file, depth, lockDir = f, 1, directory
+	return &Lock{}, nil
+}
+
+// Release releases the lock once every Acquire of this process is released. Releasing twice does nothing.
+func (l *Lock) Release() error {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	if l == nil || l.released {
+		return nil
+	}
+	l.released = true
+	if depth--; depth > 0 {
+		return nil
+	}
+
+	os.Unsetenv(EnvHolder)
+	os.Remove(ownerPath(lockDir))
+	err := syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+	file.Close()
+	file, lockDir = nil, ""
+	return err
+}
+
+// CurrentOwner returns who holds the lock of a Pi-Apps directory, ok is false if nobody does
+func CurrentOwner(directory string) (owner Owner, ok bool) {
+	f, err := os.Open(lockPath(directory))
+	if err != nil {
+		return Owner{}, false
+	}
+	defer f.Close()
+	// The owner file of a crashed process stays behind, only a held lock has an owner
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_SH|syscall.LOCK_NB); err == nil {
+		syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+		return Owner{}, false
+	}
+
+	data, err := os.ReadFile(ownerPath(directory))
+	if err != nil {
+		return Owner{}, true
+	}
+	lines := strings.SplitN(string(data), "\n", 4)
+	owner.PID, _ = strconv.Atoi(strings.TrimSpace(lines[0]))
+	if len(lines) > 1 {
+		owner.Operation = strings.TrimSpace(lines[1])
+	}
+	if len(lines) > 2 {
+		if since, err := strconv.ParseInt(strings.TrimSpace(lines[2]), 10, 64); err == nil {
+			owner.Since = time.Unix(since, 0)
+		}
+	}
+	return owner, true
+}
+
+// DetachedEnv returns an environment without EnvHolder, for processes the holder of the lock starts to keep
+// running after its operation, like a restarted program or a daemon
+func DetachedEnv(env []string) []string {
+	detached := make([]string, 0, len(env))
+	for _, variable := range env {
+		if !strings.HasPrefix(variable, EnvHolder+"=") {
+			detached = append(detached, variable)
+		}
+	}
+	return detached
+}
+
+// IsAncestor reports whether a process is the parent of this process or one of its ancestors. A process that
+// was started by the holder but left behind, and reparented when the holder exited, isn't its descendant anymore.
+func IsAncestor(pid int) bool {
+	for current := os.Getppid(); current > 1; current = parentPID(current) {
+		if current == pid {
+			return true
+		}
+	}
+	return false
+}
+
+// parentPID returns the parent of a process from /proc, 0 if it can't be read
+func parentPID(pid int) int {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return 0
+	}
+	// The command name in parentheses may contain spaces and parentheses, the state and the parent follow it
+	end := bytes.LastIndexByte(data, ')')
+	if end < 0 {
+		return 0
+	}
+	fields := strings.Fields(string(data[end+1:]))
+	if len(fields) < 2 {
+		return 0
+	}
+	ppid, _ := strconv.Atoi(fields[1])
+	return ppid
+}
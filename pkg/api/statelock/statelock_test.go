@@ -0,0 +1,201 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package statelock
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// helperEnv makes the test binary run TestHelperProcess as a separate process instead of the tests
+const helperEnv = "STATELOCK_TEST_HELPER"
+
+// TestHelperProcess is the other Pi-Apps process of the tests. In "hold" mode it takes the lock, starts
+// a child in "try" mode, prints whether the child got the lock and holds it until its stdin is closed.
+// In "try" mode it exits with 0 if it gets the lock right away and with 3 if it doesn't.
+func TestHelperProcess(t *testing.T) {
+	mode, directory, found := strings.Cut(os.Getenv(helperEnv), ":")
+	if !found {
+		t.Skip("only runs as a helper process")
+	}
+	lock, err := Acquire(directory, "helper "+mode, 0, nil)
+	if mode == "try" {
+		var timeout *TimeoutError
+		if errors.As(err, &timeout) {
+			os.Exit(3)
+		} else if err != nil {
+			os.Exit(1)
+		}
+		lock.Release()
+		os.Exit(0)
+	}
+	if err != nil {
+		os.Exit(1)
+	}
+	child := helperCommand("try", directory)
+	child.Run()
+	os.Stdout.WriteString("locked " + strconv.Itoa(child.ProcessState.ExitCode()) + "\n")
+	io.Copy(io.Discard, os.Stdin)
+	os.Exit(0)
+}
+
+// helperCommand returns the command running TestHelperProcess in a mode
+func helperCommand(mode, directory string) *exec.Cmd {
+	cmd := exec.Command(os.Args[0], "-test.run=^TestHelperProcess$")
+	cmd.Env = append(os.Environ(), helperEnv+"="+mode+":"+directory)
+	return cmd
+}
+
+// startHolder starts a helper process holding the lock of directory, and returns it with the exit code of the
+// child it started, which tried to take the lock while it was held
+func startHolder(t *testing.T, directory string) (*exec.Cmd, io.WriteCloser, int) {
+	t.Helper()
+	holder := helperCommand("hold", directory)
+	stdin, err := holder.StdinPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout, err := holder.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := holder.Start(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		holder.Process.Kill()
+		holder.Wait()
+	})
+	line, err := bufio.NewReader(stdout).ReadString('\n')
+	if err != nil {
+		t.Fatalf("the helper process didn't take the lock: %v", err)
+	}
+	code, _ := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "locked ")))
+	return holder, stdin, code
+}
+
+func TestAcquireExcludesOtherProcesses(t *testing.T) {
+	directory := t.TempDir()
+	holder, stdin, childCode := startHolder(t, directory)
+	if childCode != 0 {
+		t.Errorf("a child of the holder couldn't take the lock, exit code %d", childCode)
+	}
+
+	owner, ok := CurrentOwner(directory)
+	if !ok || owner.PID != holder.Process.Pid || owner.Operation != "helper hold" {
+		t.Fatalf("CurrentOwner = %+v, %v, want the helper process", owner, ok)
+	}
+
+	var waitedFor []Owner
+	_, err := Acquire(directory, "test", 2*pollInterval, func(owner Owner) { waitedFor = append(waitedFor, owner) })
+	var timeout *TimeoutError
+	if !errors.As(err, &timeout) || timeout.Owner.PID != holder.Process.Pid {
+		t.Fatalf("Acquire while another process holds the lock = %v, want a timeout naming it", err)
+	}
+	if len(waitedFor) != 1 {
+		t.Errorf("waiting was called %d times, want once", len(waitedFor))
+	}
+
+	// The holder releases the lock when it finishes
+	stdin.Close()
+	holder.Wait()
+	lock, err := Acquire(directory, "test", time.Second, nil)
+	if err != nil {
+		t.Fatalf("Acquire after the holder finished failed: %v", err)
+	}
+	if err := lock.Release(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAcquireIgnoresHolderThatIsNotAnAncestor(t *testing.T) {
+	directory := t.TempDir()
+	holder, _, _ := startHolder(t, directory)
+
+	// A process that inherited the variable but isn't a descendant of the holder still waits
+	t.Setenv(EnvHolder, strconv.Itoa(holder.Process.Pid))
+	var timeout *TimeoutError
+	if _, err := Acquire(directory, "test", 0, nil); !errors.As(err, &timeout) {
+		t.Fatalf("Acquire with the holder in %s but not an ancestor = %v, want a timeout", EnvHolder, err)
+	}
+}
+
+func TestAcquireRecoversStaleLock(t *testing.T) {
+	directory := t.TempDir()
+	holder, _, _ := startHolder(t, directory)
+
+	// A holder that crashes leaves its owner file behind, but the kernel releases its lock
+	holder.Process.Kill()
+	holder.Wait()
+	if _, err := os.Stat(ownerPath(directory)); err != nil {
+		t.Fatalf("the owner file of the crashed holder is gone: %v", err)
+	}
+	if owner, ok := CurrentOwner(directory); ok {
+		t.Errorf("CurrentOwner after the holder crashed = %+v, want nobody", owner)
+	}
+
+	lock, err := Acquire(directory, "test", 0, nil)
+	if err != nil {
+		t.Fatalf("Acquire after the holder crashed failed: %v", err)
+	}
+	if owner, ok := CurrentOwner(directory); !ok || owner.PID != os.Getpid() {
+		t.Errorf("CurrentOwner = %+v, %v, want this process", owner, ok)
+	}
+	if os.Getenv(EnvHolder) != strconv.Itoa(os.Getpid()) {
+		t.Errorf("%s = %q while holding the lock, want this process", EnvHolder, os.Getenv(EnvHolder))
+	}
+	lock.Release()
+	if _, ok := CurrentOwner(directory); ok {
+		t.Error("the lock is still held after Release")
+	}
+}
+
+func TestAcquireNested(t *testing.T) {
+	directory := t.TempDir()
+	outer, err := Acquire(directory, "outer", 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inner, err := Acquire(directory, "inner", 0, nil)
+	if err != nil {
+		t.Fatalf("a nested Acquire failed: %v", err)
+	}
+	inner.Release()
+	inner.Release()
+	if _, ok := CurrentOwner(directory); !ok {
+		t.Error("releasing the nested lock released the outer one")
+	}
+	outer.Release()
+	if _, ok := CurrentOwner(directory); ok {
+		t.Error("the lock is still held after releasing the outer lock")
+	}
+}
+
+func TestDetachedEnv(t *testing.T) {
+	env := []string{"HOME=/home/pi", EnvHolder + "=1234", EnvHolder + "_OTHER=1"}
+	if got, want := DetachedEnv(env), []string{"HOME=/home/pi", EnvHolder + "_OTHER=1"}; !slices.Equal(got, want) {
+		t.Errorf("DetachedEnv = %q, want %q", got, want)
+	}
+}
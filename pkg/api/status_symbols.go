@@ -0,0 +1,96 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: status_symbols.go
+// Description: Decides whether status messages carry symbols next to their colors, for users who can't tell the colors apart.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package api
+
+import (
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Symbols put in front of status messages and app statuses when StatusSymbolsEnabled
+const (
+	SymbolSuccess = "✓"
+	SymbolFailure = "✗"
+	SymbolWarning = "⚠"
+)
+
+var statusSymbolsEnabled = sync.OnceValue(func() bool {
+	directory := GetPiAppsDir()
+	if directory != "" {
+		switch ReadSettingIn(directory, "Status symbols") {
+		case "Yes":
+			return true
+		case "No":
+			return false
+		}
+	}
+	return lowContrastTerminal(os.Getenv("COLORFGBG"))
+})
+
+// StatusSymbolsEnabled reports whether status messages get a ✓, ✗ or ⚠ in front of them. It follows the
+// "Status symbols" setting, which on Auto turns them on when the terminal colors suggest low contrast.
+func StatusSymbolsEnabled() bool {
+	return statusSymbolsEnabled()
+}
+
+// lowContrastTerminal guesses from COLORFGBG, set by terminals like konsole and rxvt as "<fg>;<bg>" or
+// "<fg>;<default>;<bg>", whether the bright status colors are hard to read. On a light background the yellow,
+// cyan and green messages fade and only red stands out.
+func lowContrastTerminal(colorfgbg string) bool {
+	fields := strings.Split(colorfgbg, ";")
+	if len(fields) < 2 {
+		return false
+	}
+	bg, err := strconv.Atoi(fields[len(fields)-1])
+	if err != nil {
+		return false
+	}
+	return bg == 7 || bg >= 9 && bg <= 15
+}
+
+// statusPrefix returns the symbol and space to put in front of a status message, or "" when symbols are off
+func statusPrefix(symbol string) string {
+	if !StatusSymbolsEnabled() {
+		return ""
+	}
+	return symbol + " "
+}
+
+// warningIcon returns the icon in front of warnings, the blinking triangle unless symbols are on
+func warningIcon() string {
+	if StatusSymbolsEnabled() {
+		return SymbolWarning
+	}
+	return "\033[5m◢◣\033[25m"
+}
+
+// statusSymbolPrefixPattern matches the failure and warning symbols at the start of log lines, which would keep
+// anchored diagnosis patterns like ^User error: from matching
+var statusSymbolPrefixPattern = regexp.MustCompile(`(?m)^(` + SymbolFailure + `|` + SymbolWarning + `) `)
+
+// removeStatusSymbols removes the failure and warning symbols status messages start with from a log.
+// Success symbols and symbols in the middle of lines don't get in the way of the diagnosis and are kept.
+func removeStatusSymbols(input string) string {
+	return statusSymbolPrefixPattern.ReplaceAllString(input, "")
+}
@@ -0,0 +1,93 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLowContrastTerminal(t *testing.T) {
+	tests := map[string]bool{
+		"":             false,
+		"15":           false,
+		"15;0":         false, // white on black
+		"0;15":         true,  // black on bright white
+		"0;7":          true,  // black on white
+		"0;default":    false,
+		"12;8":         false,
+		"0;default;15": true,
+		"0;9":          true,
+		"abc;def":      false,
+	}
+	for colorfgbg, want := range tests {
+		if got := lowContrastTerminal(colorfgbg); got != want {
+			t.Errorf("lowContrastTerminal(%q) = %v, want %v", colorfgbg, got, want)
+		}
+	}
+}
+
+func TestRemoveStatusSymbols(t *testing.T) {
+	input := SymbolFailure + " User error: the disk is full\n" +
+		SymbolWarning + " WARNING: low memory\n" +
+		SymbolSuccess + " Installed Zoom\n" +
+		"Copying " + SymbolFailure + " and " + SymbolWarning + " as text\n"
+	want := "User error: the disk is full\n" +
+		"WARNING: low memory\n" +
+		SymbolSuccess + " Installed Zoom\n" +
+		"Copying " + SymbolFailure + " and " + SymbolWarning + " as text\n"
+	if got := removeStatusSymbols(input); got != want {
+		t.Errorf("removeStatusSymbols =\n%s\nwant\n%s", got, want)
+	}
+}
+
+// TestDiagnosisWithStatusSymbols checks that a log written with status symbols on is still diagnosed
+// once FormatLogfile cleaned it, like the log of an install that failed
+func TestDiagnosisWithStatusSymbols(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "install-fail-Zoom.log")
+	log := "OS: Debian GNU/Linux 12 (bookworm)\n\n" +
+		"\033[92m" + SymbolSuccess + " Downloading Zoom...\033[0m\n" +
+		"\033[93m" + SymbolWarning + " WARNING: the download is slow\033[0m\n" +
+		"\033[91m" + SymbolFailure + " User error: Zoom needs a 64-bit operating system.\033[0m\n" +
+		"\n" +
+		"\033[91m" + SymbolFailure + " Failed to install Zoom!\033[0m\n"
+	writeTestFile(t, path, log)
+
+	if err := FormatLogfile(path); err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), "\nUser error: Zoom needs a 64-bit operating system.\n") {
+		t.Fatalf("the failure symbol was kept in front of the user error:\n%s", content)
+	}
+	if !strings.Contains(string(content), SymbolSuccess+" Downloading Zoom...") {
+		t.Errorf("the success symbol was removed:\n%s", content)
+	}
+
+	diagnosis, err := LogDiagnose(path, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diagnosis.ErrorType != "system" || len(diagnosis.Captions) == 0 || diagnosis.Captions[0] != "Zoom needs a 64-bit operating system." {
+		t.Errorf("LogDiagnose = %+v, want the user error as a system error", diagnosis)
+	}
+}
@@ -23,16 +23,15 @@ package api
 import (
 	"archive/zip"
 	"bufio"
+	"crypto/sha256"
 	"encoding/json"
 	"fmt"
 	"io"
-	"math"
 	"net/http"
 	"net/url"
 	"os"
 	"os/exec"
 	"path/filepath"
-	"regexp"
 	"runtime"
 	"slices"
 	"strconv"
@@ -85,7 +84,7 @@ func IsSystemSupported() (*SystemSupportStatus, error) {
 	status.OSInfo = osInfo
 
 	// Check if running as root
-	if os.Geteuid() == 0 {
+	if os.Geteuid() == 0 && os.Getenv(allowRootEnv) != "1" {
 		status.IsSupported = false
 		status.Message = "Pi-Apps is not designed to be run as root user."
 		return status, nil
@@ -636,14 +635,6 @@ func isMuslSystem() bool {
 	return false
 }
 
-// ProcessExists checks if a process with the given PID is running
-func ProcessExists(pid int) bool {
-	// In Linux, check if the /proc/{pid}/status file exists
-	pidStatusPath := fmt.Sprintf("/proc/%d/status", pid)
-	_, err := os.Stat(pidStatusPath)
-	return err == nil
-}
-
 // GitClone clones a git repository and displays output if an error occurs
 // It mimics the behavior of the original bash git_clone function
 func GitClone(args ...string) error {
@@ -698,13 +689,23 @@ func GitClone(args ...string) error {
 		}
 	}
 
-	// Clone the repository (run from home directory)
-	gitCmd := exec.Command("git", "clone", repoURL, repoName)
+	// Clone the repository (run from home directory), from its copy if installing from an app bundle
+	source := repoURL
+	staged, isStaged := stagedAsset(repoURL)
+	if isStaged {
+		source = staged
+	}
+	gitCmd := exec.Command("git", "clone", source, repoName)
 	gitCmd.Dir = baseDir // Set working directory to chosen base directory
 	output, err := gitCmd.CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("\nFailed to download %s repository.\nErrors: %s", repoName, string(output))
 	}
+	if isStaged {
+		// Pulling later should reach the real repository
+		exec.Command("git", "-C", folder, "remote", "set-url", "origin", repoURL).Run()
+	}
+	recordGitClone(repoURL, folder)
 
 	StatusGreen("Done")
 	return nil
@@ -852,79 +853,6 @@ func extractZipFile(file *zip.File, destDir string, junkPaths bool, overwrite bo
 	return nil
 }
 
-// Nproc returns the optimal number of processor threads to use based on available memory
-// It mimics the behavior of the original bash nproc function
-func Nproc() (int, error) {
-	// Get the total number of processors
-	totalProcs := runtime.NumCPU()
-
-	// Check if running in GitHub Actions
-	if os.Getenv("GITHUB_ACTIONS") == "true" {
-		return totalProcs, nil
-	}
-
-	// Get available memory
-	var memInfo syscall.Sysinfo_t
-	if err := syscall.Sysinfo(&memInfo); err != nil {
-		return 0, fmt.Errorf("failed to get system info: %w", err)
-	}
-
-	// Convert available memory to MB (from bytes)
-	// Note: Sysinfo.Freeram can be 32-bit on some platforms, so promote to uint64 before multiplying
-	availableBytes := uint64(memInfo.Freeram) * uint64(memInfo.Unit)
-	availableMB := int(availableBytes / (1024 * 1024))
-
-	// Alternatively, read from /proc/meminfo
-	if availableMB == 0 {
-		availableMB, _ = getAvailableMemoryMB()
-	}
-
-	// Determine number of threads based on available memory
-	if availableMB > 2000 {
-		// Available memory > 2000MB, use normal number of threads
-		return totalProcs, nil
-	} else if availableMB > 1500 {
-		// 1500MB < available memory <= 2000MB, use 3 threads
-		Warning("Your system has less than 2000MB of available RAM, so this will compile with only 3 threads.")
-		return int(math.Min(float64(totalProcs), 3)), nil
-	} else if availableMB > 1000 {
-		// 1000MB < available memory <= 1500MB, use 2 threads
-		Warning("Your system has less than 1500MB of available RAM, so this will compile with only 2 threads.")
-		return int(math.Min(float64(totalProcs), 2)), nil
-	} else {
-		// Available memory <= 1000MB, use 1 thread
-		Warning("Your system has less than 1000MB of available RAM, so this will compile with only 1 thread.")
-		return 1, nil
-	}
-}
-
-// getAvailableMemoryMB reads available memory from /proc/meminfo
-func getAvailableMemoryMB() (int, error) {
-	file, err := os.Open("/proc/meminfo")
-	if err != nil {
-		return 0, err
-	}
-	defer file.Close()
-
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := scanner.Text()
-		if strings.HasPrefix(line, "MemAvailable:") {
-			fields := strings.Fields(line)
-			if len(fields) >= 2 {
-				// Convert from kB to MB
-				valuekB, err := strconv.Atoi(fields[1])
-				if err != nil {
-					return 0, err
-				}
-				return valuekB / 1024, nil
-			}
-		}
-	}
-
-	return 0, fmt.Errorf("couldn't find MemAvailable in /proc/meminfo")
-}
-
 // Wget downloads a file from a URL and displays progress
 // It mimics the behavior of the original bash wget function
 func Wget(args []string) error {
@@ -943,6 +871,10 @@ func Wget(args []string) error {
 			// Long options
 			if arg == "--quiet" {
 				quiet = true
+			} else if rate, ok := strings.CutPrefix(arg, "--limit-rate="); ok {
+				if err := SetDownloadLimitOverride(rate); err != nil {
+					return err
+				}
 			} else if strings.HasPrefix(arg, "--header=") {
 				headerParts := strings.SplitN(arg[9:], ":", 2)
 				if len(headerParts) == 2 {
@@ -1025,6 +957,14 @@ func Wget(args []string) error {
 		}
 	}
 
+	// Installing from an app bundle, the file is in it
+	if staged, ok := stagedAsset(url); ok {
+		if writeToStdout {
+			outputFile = ""
+		}
+		return copyStagedAsset(staged, outputFile)
+	}
+
 	// Create the request
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
@@ -1072,8 +1012,17 @@ func Wget(args []string) error {
 		output = file
 	}
 
+	// Hash the file for the download ledger while it is written, and report how much of it is done
+	hash := sha256.New()
+	phase, endPhase := startDownloadPhase(resp.ContentLength)
+	defer endPhase()
+	output = io.MultiWriter(output, hash, phase)
+	var size int64
+
 	// Get the total size for progress reporting
 	contentLength := resp.ContentLength
+	limit := DownloadLimit()
+	body := newRateLimitedReader(resp.Body, limit)
 
 	// Copy the data with progress reporting
 	if !quiet && !writeToStdout && contentLength > 0 {
@@ -1082,6 +1031,7 @@ func Wget(args []string) error {
 			Total:   uint64(contentLength),
 			Current: 0,
 			Quiet:   quiet,
+			Limit:   limit,
 		}
 
 		// Start progress goroutine
@@ -1089,7 +1039,7 @@ func Wget(args []string) error {
 		go progress.showProgress(done)
 
 		// Copy the data
-		_, err = io.Copy(output, io.TeeReader(resp.Body, progress))
+		size, err = io.Copy(output, io.TeeReader(body, progress))
 
 		// Signal the progress goroutine to stop
 		close(done)
@@ -1101,12 +1051,15 @@ func Wget(args []string) error {
 		}
 	} else {
 		// No progress reporting
-		_, err = io.Copy(output, resp.Body)
+		size, err = io.Copy(output, body)
 	}
 
 	if err != nil {
 		return fmt.Errorf("download failed: %w", err)
 	}
+	if !writeToStdout {
+		recordDownload(url, outputFile, size, hash.Sum(nil))
+	}
 
 	return nil
 }
@@ -1116,6 +1069,7 @@ type progressWriter struct {
 	Total   uint64
 	Current uint64
 	Quiet   bool
+	Limit   int64 // the download speed limit in bytes per second, 0 for none
 }
 
 // Write implements io.Writer
@@ -1145,11 +1099,14 @@ func (pw *progressWriter) showProgress(done chan bool) {
 		case <-ticker.C:
 			if pw.Total > 0 {
 				percent := float64(pw.Current) / float64(pw.Total) * 100
-				bytesRead := formatBytes(pw.Current)
-				totalBytes := formatBytes(pw.Total)
+				bytesRead := FormatSize(pw.Current)
+				totalBytes := FormatSize(pw.Total)
 
 				// Calculate the progress bar width
 				statsLine := fmt.Sprintf("%s/%s ", bytesRead, totalBytes)
+				if pw.Limit > 0 {
+					statsLine = fmt.Sprintf("%s/%s (%s) ", bytesRead, totalBytes, Tf("limited to %s", formatRateLimit(pw.Limit)))
+				}
 				statsLineLen := len(statsLine)
 				availableWidth := termWidth - statsLineLen
 				if availableWidth <= 0 {
@@ -1177,20 +1134,6 @@ func (pw *progressWriter) showProgress(done chan bool) {
 	}
 }
 
-// formatBytes converts bytes to a human-readable format
-func formatBytes(bytes uint64) string {
-	const unit = 1024
-	if bytes < unit {
-		return fmt.Sprintf("%d B", bytes)
-	}
-	div, exp := uint64(unit), 0
-	for n := bytes / unit; n >= unit; n /= unit {
-		div *= unit
-		exp++
-	}
-	return fmt.Sprintf("%.1f %ciB", float64(bytes)/float64(div), "KMGTPE"[exp])
-}
-
 // parseURL parses a URL string and handles special cases
 func parseURL(rawURL string) (*url.URL, error) {
 	parsedURL, err := url.Parse(rawURL)
@@ -1245,169 +1188,6 @@ func GetPiAppIcon(appName string) (string, error) {
 	return iconPath, nil
 }
 
-// ChmodWithArgs wraps Chmod to handle command-line style arguments
-func ChmodWithArgs(args ...string) error {
-	// Need at least two arguments (mode and filename)
-	if len(args) < 2 {
-		return fmt.Errorf("chmod: missing operand")
-	}
-
-	// Parse the mode
-	modeStr := args[0]
-	mode, err := parseChmodMode(modeStr)
-	if err != nil {
-		return fmt.Errorf("chmod: invalid mode: %s", modeStr)
-	}
-
-	// Apply chmod to each file
-	for _, path := range args[1:] {
-		if err := Chmod(mode, path); err != nil {
-			return err
-		}
-	}
-
-	return nil
-}
-
-// parseChmodMode converts a string mode (like "755") to os.FileMode
-func parseChmodMode(modeStr string) (os.FileMode, error) {
-	// Check if it's an octal number
-	if regexp.MustCompile(`^[0-7]+$`).MatchString(modeStr) {
-		// Parse octal
-		modeInt, err := strconv.ParseInt(modeStr, 8, 32)
-		if err != nil {
-			return 0, err
-		}
-		return os.FileMode(modeInt), nil
-	}
-
-	// Handle symbolic mode
-	return parseSymbolicMode(modeStr)
-}
-
-// parseSymbolicMode handles symbolic chmod modes like "u+x", "go-w", "a=r", etc.
-func parseSymbolicMode(modeStr string) (os.FileMode, error) {
-	// Default starting mode (644 - rw-r--r--)
-	mode := os.FileMode(0644)
-
-	// Split by comma for multiple operations
-	operations := strings.Split(modeStr, ",")
-
-	for _, op := range operations {
-		op = strings.TrimSpace(op)
-		if op == "" {
-			continue
-		}
-
-		// Parse who, operator, and permissions
-		var who, operator, perms string
-
-		// Find the operator (+, -, =)
-		opIndex := -1
-		for i, char := range op {
-			if char == '+' || char == '-' || char == '=' {
-				who = op[:i]
-				operator = string(char)
-				perms = op[i+1:]
-				opIndex = i
-				break
-			}
-		}
-
-		if opIndex == -1 {
-			return 0, fmt.Errorf("invalid symbolic mode: %s", op)
-		}
-
-		// Default to 'a' (all) if no who is specified
-		if who == "" {
-			who = "a"
-		}
-
-		// Calculate permission bits
-		permBits := os.FileMode(0)
-		for _, perm := range perms {
-			switch perm {
-			case 'r':
-				permBits |= 0444 // read for all
-			case 'w':
-				permBits |= 0222 // write for all
-			case 'x':
-				permBits |= 0111 // execute for all
-			default:
-				return 0, fmt.Errorf("invalid permission: %c", perm)
-			}
-		}
-
-		// Apply to specific users
-		var targetBits os.FileMode
-		for _, user := range who {
-			switch user {
-			case 'u': // user/owner
-				if permBits&0444 != 0 {
-					targetBits |= 0400
-				}
-				if permBits&0222 != 0 {
-					targetBits |= 0200
-				}
-				if permBits&0111 != 0 {
-					targetBits |= 0100
-				}
-			case 'g': // group
-				if permBits&0444 != 0 {
-					targetBits |= 0040
-				}
-				if permBits&0222 != 0 {
-					targetBits |= 0020
-				}
-				if permBits&0111 != 0 {
-					targetBits |= 0010
-				}
-			case 'o': // others
-				if permBits&0444 != 0 {
-					targetBits |= 0004
-				}
-				if permBits&0222 != 0 {
-					targetBits |= 0002
-				}
-				if permBits&0111 != 0 {
-					targetBits |= 0001
-				}
-			case 'a': // all (user, group, others)
-				targetBits = permBits
-			default:
-				return 0, fmt.Errorf("invalid user specification: %c", user)
-			}
-		}
-
-		// Apply the operation
-		switch operator {
-		case "+":
-			mode |= targetBits
-		case "-":
-			mode &^= targetBits
-		case "=":
-			// For '=', we need to clear existing bits for the specified users first
-			var clearBits os.FileMode
-			for _, user := range who {
-				switch user {
-				case 'u':
-					clearBits |= 0700
-				case 'g':
-					clearBits |= 0070
-				case 'o':
-					clearBits |= 0007
-				case 'a':
-					clearBits |= 0777
-				}
-			}
-			mode &^= clearBits
-			mode |= targetBits
-		}
-	}
-
-	return mode, nil
-}
-
 // SudoPopup executes a command with sudo if available without password, otherwise with pkexec
 // It mimics the behavior of the original bash sudo_popup function, which avoids displaying
 // a password prompt to an invisible terminal
@@ -1434,3 +1214,27 @@ func SudoPopup(command string, args ...string) error {
 		return cmd.Run()
 	}
 }
+
+// commandExists checks if a command is available in the system PATH
+func commandExists(cmd string) bool {
+	_, err := exec.LookPath(cmd)
+	return err == nil
+}
+
+// copyFile copies a file from src to dst
+func copyFile(src, dst string) error {
+	sourceFile, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer sourceFile.Close()
+
+	destFile, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer destFile.Close()
+
+	_, err = io.Copy(destFile, sourceFile)
+	return err
+}
@@ -27,12 +27,40 @@ import (
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strconv"
 	"strings"
 	"syscall"
 	"time"
+	"unicode"
+	"unicode/utf8"
 )
 
+// maxTerminalTitleLength is the longest terminal title that is set, in characters
+const maxTerminalTitleLength = 80
+
+// ShellQuote quotes a string for a POSIX shell, so app names and paths with spaces, quotes or $ stay one word
+func ShellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// TerminalTitle returns a title that is safe to put in the escape sequence setting the title of a terminal.
+// Control characters, which would end the sequence early, are removed, and a long title is shortened
+// without cutting a multibyte character in half.
+func TerminalTitle(title string) string {
+	title = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || r == utf8.RuneError {
+			return -1
+		}
+		return r
+	}, title)
+	if utf8.RuneCountInString(title) <= maxTerminalTitleLength {
+		return title
+	}
+	runes := []rune(title)
+	return string(runes[:maxTerminalTitleLength-1]) + "…"
+}
+
 // Run starts a new terminal window on the host OS, sets its title,
 // executes the provided command, and blocks until the terminal exits.
 func TerminalRun(cmd string, title string) error {
@@ -68,7 +96,22 @@ var terminalsLinux = []string{
 	"kitty",
 }
 
+// x11OnlyTerminals can't open a window in a Wayland session without XWayland
+var x11OnlyTerminals = []string{
+	"lxterm",
+	"uxterm",
+	"xterm",
+	"urxvt",
+}
+
+// usableTerminal reports whether a terminal can open a window in the display session
+func usableTerminal(name string, session DisplaySession) bool {
+	return session.CanRunX11() || !slices.Contains(x11OnlyTerminals, name)
+}
+
 func findTerminalLinux() (binaryPath string, terminalName string, err error) {
+	session := DisplaySessionInfo()
+
 	// Try x-terminal-emulator first
 	if p, err := exec.LookPath("x-terminal-emulator"); err == nil {
 		resolved, err := filepath.EvalSymlinks(p)
@@ -76,7 +119,7 @@ func findTerminalLinux() (binaryPath string, terminalName string, err error) {
 			realName := filepath.Base(resolved)
 
 			for _, t := range terminalsLinux {
-				if t == realName {
+				if t == realName && usableTerminal(t, session) {
 					binaryPath = resolved
 					terminalName = realName
 
@@ -96,6 +139,9 @@ func findTerminalLinux() (binaryPath string, terminalName string, err error) {
 
 	// Fallback search
 	for _, t := range terminalsLinux {
+		if !usableTerminal(t, session) {
+			continue
+		}
 		if path, err := exec.LookPath(t); err == nil {
 			// If it's the wrapper, replace with real GNOME terminal
 			if t == "gnome-terminal.wrapper" {
@@ -174,7 +220,7 @@ func runLinux(userCmd, title string) error {
 
 	// Inject PID tracking and title setting (matching shell script behavior exactly)
 	// The shell script does: echo $$ > $temp_pid_file followed by title setting
-	injected := fmt.Sprintf("echo $$ > %s; echo -ne '\\e]0;%s\\a'; %s", tempPidPath, title, userCmd)
+	injected := fmt.Sprintf("echo $$ > %s; printf '\\e]0;%%s\\a' %s; %s", ShellQuote(tempPidPath), ShellQuote(TerminalTitle(title)), userCmd)
 
 	var args []string
 	var scriptFile string // For terminals that need a script file
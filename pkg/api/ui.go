@@ -21,15 +21,20 @@
 package api
 
 import (
+	"bufio"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"strconv"
 	"strings"
 
-	"github.com/gotk3/gotk3/glib"
-	"github.com/gotk3/gotk3/gtk"
+	"github.com/pi-apps-go/pi-apps/pkg/api/errs"
 )
 
+// ErrNoGUI is returned by the GUI functions of builds without GUI support (the nogui build tag)
+var ErrNoGUI = errors.New("this build of Pi-Apps has no GUI support")
+
 // UserInputFunc displays a dialog to the user and returns their selection
 // This is a Go implementation of the original bash userinput_func
 func UserInputFunc(text string, options ...string) (string, error) {
@@ -41,35 +46,14 @@ func UserInputFunc(text string, options ...string) (string, error) {
 	}
 
 	// Check if we can use GTK
-	if !canUseGTK() {
+	if !GUISupported || !canUseGTK() {
 		fmt.Fprintf(os.Stderr, "Using CLI for dialog\n")
 		return cliUserInput(text, options...)
 	}
 
 	fmt.Fprintf(os.Stderr, "Using GTK for dialog\n")
 
-	// Initialize application name
-	glib.SetPrgname("Pi-Apps")
-	glib.SetApplicationName("Pi-Apps (user input dialog)")
-
-	// Initialize GTK
-	gtk.Init(nil)
-
-	var selection string
-	var err error
-
-	// Create the appropriate dialog based on the number of options
-	if len(options) == 1 {
-		// Simple OK dialog
-		selection, err = createSimpleDialog(text, options[0])
-	} else if len(options) == 2 {
-		// Yes/No type dialog
-		selection, err = createYesNoDialog(text, options[0], options[1])
-	} else {
-		// List selection dialog
-		selection, err = createListDialog(text, options)
-	}
-
+	selection, err := gtkUserInput(text, options...)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "GTK dialog error: %v, falling back to CLI\n", err)
 		return cliUserInput(text, options...)
@@ -78,254 +62,74 @@ func UserInputFunc(text string, options ...string) (string, error) {
 	return selection, nil
 }
 
-// createSimpleDialog creates a simple dialog with a single button
-func createSimpleDialog(text, buttonLabel string) (string, error) {
-	// Create the dialog
-	dialog, err := gtk.DialogNew()
-	if err != nil {
-		return "", fmt.Errorf("failed to create dialog: %w", err)
-	}
-
-	dialog.SetTitle("Pi-Apps")
-	dialog.SetPosition(gtk.WIN_POS_CENTER)
-	dialog.SetModal(true)
-	dialog.SetDecorated(false)
-	dialog.SetResizable(false)
-	dialog.SetBorderWidth(20)
-
-	// Add content area
-	contentArea, err := dialog.GetContentArea()
-	if err != nil {
-		dialog.Destroy()
-		return "", fmt.Errorf("failed to get dialog content area: %w", err)
-	}
-
-	// Create a horizontal box to hold the icon and text
-	hbox, err := gtk.BoxNew(gtk.ORIENTATION_HORIZONTAL, 10)
-	if err != nil {
-		dialog.Destroy()
-		return "", fmt.Errorf("failed to create horizontal box: %w", err)
-	}
-	contentArea.Add(hbox)
-
-	// Add information icon
-	icon, err := gtk.ImageNewFromIconName("dialog-information", gtk.ICON_SIZE_DIALOG)
-	if err != nil {
-		dialog.Destroy()
-		return "", fmt.Errorf("failed to create information icon: %w", err)
+// UserMultiSelect asks the user to check any number of options, the ones in selected checked to begin with, and
+// returns which are checked. Cancelling the dialog returns errs.ErrCancelled.
+func UserMultiSelect(text string, options []string, selected []bool) ([]bool, error) {
+	if text == "" {
+		return nil, fmt.Errorf("user_multi_select(): requires a description")
 	}
-	hbox.PackStart(icon, false, false, 0)
-
-	// Add text label in a vertical box
-	vbox, err := gtk.BoxNew(gtk.ORIENTATION_VERTICAL, 0)
-	if err != nil {
-		dialog.Destroy()
-		return "", fmt.Errorf("failed to create vertical box: %w", err)
+	if len(options) == 0 || len(selected) != len(options) {
+		return nil, fmt.Errorf("user_multi_select(): requires options with whether they are selected")
 	}
-	hbox.PackStart(vbox, true, true, 0)
 
-	// Add text label
-	label, err := gtk.LabelNew(text)
-	if err != nil {
-		dialog.Destroy()
-		return "", fmt.Errorf("failed to create text label: %w", err)
+	if GUISupported && canUseGTK() {
+		checked, err := gtkUserMultiSelect(text, options, selected)
+		if err == nil || errors.Is(err, errs.ErrCancelled) {
+			return checked, err
+		}
+		fmt.Fprintf(os.Stderr, "GTK dialog error: %v, falling back to CLI\n", err)
 	}
-	label.SetLineWrap(true)
-	label.SetSelectable(false)
-	label.SetJustify(gtk.JUSTIFY_LEFT)
-	vbox.PackStart(label, true, true, 0)
-
-	// Add button
-	dialog.AddButton(buttonLabel, gtk.RESPONSE_OK)
-	dialog.ShowAll()
-	dialog.Run()
-	dialog.Destroy()
-
-	return buttonLabel, nil
+	return cliUserMultiSelect(text, options, selected)
 }
 
-// createYesNoDialog creates a Yes/No dialog
-func createYesNoDialog(text, yesLabel, noLabel string) (string, error) {
-	// Create the dialog
-	dialog, err := gtk.DialogNew()
-	if err != nil {
-		return "", fmt.Errorf("failed to create dialog: %w", err)
-	}
-
-	dialog.SetTitle("Pi-Apps")
-	dialog.SetPosition(gtk.WIN_POS_CENTER)
-	dialog.SetModal(true)
-	dialog.SetDecorated(false)
-	dialog.SetResizable(false)
-	dialog.SetBorderWidth(20)
-
-	// Add content area
-	contentArea, err := dialog.GetContentArea()
-	if err != nil {
-		dialog.Destroy()
-		return "", fmt.Errorf("failed to get dialog content area: %w", err)
-	}
-
-	// Create a horizontal box to hold the icon and text
-	hbox, err := gtk.BoxNew(gtk.ORIENTATION_HORIZONTAL, 10)
-	if err != nil {
-		dialog.Destroy()
-		return "", fmt.Errorf("failed to create horizontal box: %w", err)
+// cliUserMultiSelect provides the terminal version of UserMultiSelect: the numbers of the options to check are
+// entered on one line, an empty line keeps the checked ones and "none" unchecks all of them
+func cliUserMultiSelect(text string, options []string, selected []bool) ([]bool, error) {
+	fmt.Fprintln(os.Stderr, text)
+	fmt.Fprintln(os.Stderr)
+	for i, opt := range options {
+		mark := " "
+		if selected[i] {
+			mark = "x"
+		}
+		fmt.Fprintf(os.Stderr, "[%s] %d. %s\n", mark, i+1, opt)
 	}
-	contentArea.Add(hbox)
+	fmt.Fprintf(os.Stderr, "\nEnter the numbers to install separated by spaces, \"none\" for none, or press Enter to keep the checked ones: ")
 
-	// Add question icon
-	icon, err := gtk.ImageNewFromIconName("dialog-question", gtk.ICON_SIZE_DIALOG)
-	if err != nil {
-		dialog.Destroy()
-		return "", fmt.Errorf("failed to create question icon: %w", err)
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
 	}
-	hbox.PackStart(icon, false, false, 0)
-
-	// Add text label in a vertical box
-	vbox, err := gtk.BoxNew(gtk.ORIENTATION_VERTICAL, 0)
-	if err != nil {
-		dialog.Destroy()
-		return "", fmt.Errorf("failed to create vertical box: %w", err)
+	checked, ok := parseMultiSelection(line, len(options))
+	if !ok {
+		fmt.Fprintln(os.Stderr, "Invalid choice. Keeping the checked ones.")
+		return selected, nil
 	}
-	hbox.PackStart(vbox, true, true, 0)
-
-	// Add text label
-	label, err := gtk.LabelNew(text)
-	if err != nil {
-		dialog.Destroy()
-		return "", fmt.Errorf("failed to create text label: %w", err)
-	}
-	label.SetLineWrap(true)
-	label.SetSelectable(false)
-	label.SetJustify(gtk.JUSTIFY_LEFT)
-	vbox.PackStart(label, true, true, 0)
-
-	// Add buttons
-	dialog.AddButton(yesLabel, gtk.RESPONSE_YES)
-	dialog.AddButton(noLabel, gtk.RESPONSE_NO)
-	dialog.ShowAll()
-
-	response := dialog.Run()
-	dialog.Destroy()
-
-	// Process the response
-	if response == gtk.RESPONSE_YES {
-		return yesLabel, nil
-	} else {
-		return noLabel, nil
+	if checked == nil {
+		return selected, nil
 	}
+	return checked, nil
 }
 
-// createListDialog creates a dialog with a list of options
-func createListDialog(text string, options []string) (string, error) {
-	// Create the dialog
-	dialog, err := gtk.DialogNew()
-	if err != nil {
-		return "", fmt.Errorf("failed to create dialog: %w", err)
-	}
-
-	dialog.SetTitle("Pi-Apps")
-	dialog.SetPosition(gtk.WIN_POS_CENTER)
-	dialog.SetModal(true)
-	dialog.SetDecorated(false)
-	dialog.SetResizable(false)
-	dialog.SetBorderWidth(20)
-
-	// Add content area
-	contentArea, err := dialog.GetContentArea()
-	if err != nil {
-		dialog.Destroy()
-		return "", fmt.Errorf("failed to get dialog content area: %w", err)
-	}
-
-	// Create a horizontal box to hold the icon and content
-	hbox, err := gtk.BoxNew(gtk.ORIENTATION_HORIZONTAL, 10)
-	if err != nil {
-		dialog.Destroy()
-		return "", fmt.Errorf("failed to create horizontal box: %w", err)
-	}
-	contentArea.Add(hbox)
-
-	// Add information icon
-	icon, err := gtk.ImageNewFromIconName("dialog-information", gtk.ICON_SIZE_DIALOG)
-	if err != nil {
-		dialog.Destroy()
-		return "", fmt.Errorf("failed to create information icon: %w", err)
-	}
-	hbox.PackStart(icon, false, false, 0)
-
-	// Add content in a vertical box
-	vbox, err := gtk.BoxNew(gtk.ORIENTATION_VERTICAL, 10)
-	if err != nil {
-		dialog.Destroy()
-		return "", fmt.Errorf("failed to create vertical box: %w", err)
-	}
-	hbox.PackStart(vbox, true, true, 0)
-
-	// Add text label
-	if text != "" {
-		label, err := gtk.LabelNew(text)
-		if err != nil {
-			dialog.Destroy()
-			return "", fmt.Errorf("failed to create text label: %w", err)
+// parseMultiSelection parses the numbers entered for cliUserMultiSelect, separated by spaces or commas. It returns
+// nil for an empty answer and false if the answer is invalid.
+func parseMultiSelection(answer string, count int) ([]bool, bool) {
+	answer = strings.TrimSpace(answer)
+	if answer == "" {
+		return nil, true
+	}
+	checked := make([]bool, count)
+	if strings.EqualFold(answer, "none") {
+		return checked, true
+	}
+	for _, field := range strings.FieldsFunc(answer, func(r rune) bool { return r == ',' || r == ' ' }) {
+		number, err := strconv.Atoi(field)
+		if err != nil || number < 1 || number > count {
+			return nil, false
 		}
-		label.SetLineWrap(true)
-		label.SetSelectable(false)
-		label.SetJustify(gtk.JUSTIFY_LEFT)
-		vbox.PackStart(label, false, false, 0)
+		checked[number-1] = true
 	}
-
-	// Create a box for the radio buttons
-	radioBox, err := gtk.BoxNew(gtk.ORIENTATION_VERTICAL, 5)
-	if err != nil {
-		dialog.Destroy()
-		return "", fmt.Errorf("failed to create radio button box: %w", err)
-	}
-	vbox.PackStart(radioBox, true, true, 0)
-
-	// Create radio buttons
-	var radioButtons []*gtk.RadioButton
-	for i, opt := range options {
-		var rb *gtk.RadioButton
-		if i == 0 {
-			rb, err = gtk.RadioButtonNewWithLabel(nil, opt)
-		} else {
-			rb, err = gtk.RadioButtonNewWithLabelFromWidget(radioButtons[0], opt)
-		}
-
-		if err != nil {
-			dialog.Destroy()
-			return "", fmt.Errorf("failed to create radio button: %w", err)
-		}
-
-		radioButtons = append(radioButtons, rb)
-		radioBox.PackStart(rb, false, false, 0)
-
-		// Set the first option as active by default
-		if i == 0 {
-			rb.SetActive(true)
-		}
-	}
-
-	// Add OK button
-	dialog.AddButton("OK", gtk.RESPONSE_OK)
-	dialog.ShowAll()
-
-	// Run dialog and wait for response
-	dialog.Run()
-
-	// Find which radio button is active
-	selection := options[0] // Default to first option
-	for i, rb := range radioButtons {
-		if rb.GetActive() {
-			selection = options[i]
-			break
-		}
-	}
-
-	dialog.Destroy()
-	return selection, nil
+	return checked, true
 }
 
 // canUseGTK checks if GTK can be used (display available)
@@ -354,6 +158,12 @@ func canUseGTK() bool {
 	return true
 }
 
+// stdinIsTerminal reports whether someone can answer a prompt on stdin
+func stdinIsTerminal() bool {
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
 // cliUserInput provides a fallback CLI-based user input when GTK is not available
 func cliUserInput(text string, options ...string) (string, error) {
 	// Write the prompts to stderr so they're visible during command substitution
@@ -0,0 +1,465 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: ui_gui.go
+// Description: Provides the GTK dialogs of UserInputFunc.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+//go:build !nogui
+
+package api
+
+import (
+	"fmt"
+
+	"github.com/gotk3/gotk3/glib"
+	"github.com/gotk3/gotk3/gtk"
+	"github.com/pi-apps-go/pi-apps/pkg/api/errs"
+)
+
+// GUISupported reports whether this build includes the GTK interfaces
+const GUISupported = true
+
+// gtkUserInput shows the dialog of UserInputFunc that fits the number of options
+func gtkUserInput(text string, options ...string) (string, error) {
+	// Initialize application name
+	glib.SetPrgname("Pi-Apps")
+	glib.SetApplicationName("Pi-Apps (user input dialog)")
+
+	// Initialize GTK
+	gtk.Init(nil)
+
+	// Create the appropriate dialog based on the number of options
+	if len(options) == 1 {
+		// Simple OK dialog
+		return createSimpleDialog(text, options[0])
+	} else if len(options) == 2 {
+		// Yes/No type dialog
+		return createYesNoDialog(text, options[0], options[1])
+	}
+	// List selection dialog
+	return createListDialog(text, options)
+}
+
+// createSimpleDialog creates a simple dialog with a single button
+func createSimpleDialog(text, buttonLabel string) (string, error) {
+	// Create the dialog
+	dialog, err := gtk.DialogNew()
+	if err != nil {
+		return "", fmt.Errorf("failed to create dialog: %w", err)
+	}
+
+	dialog.SetTitle("Pi-Apps")
+	dialog.SetPosition(gtk.WIN_POS_CENTER)
+	dialog.SetModal(true)
+	dialog.SetDecorated(false)
+	dialog.SetResizable(false)
+	dialog.SetBorderWidth(20)
+
+	// Add content area
+	contentArea, err := dialog.GetContentArea()
+	if err != nil {
+		dialog.Destroy()
+		return "", fmt.Errorf("failed to get dialog content area: %w", err)
+	}
+
+	// Create a horizontal box to hold the icon and text
+	hbox, err := gtk.BoxNew(gtk.ORIENTATION_HORIZONTAL, 10)
+	if err != nil {
+		dialog.Destroy()
+		return "", fmt.Errorf("failed to create horizontal box: %w", err)
+	}
+	contentArea.Add(hbox)
+
+	// Add information icon
+	icon, err := gtk.ImageNewFromIconName("dialog-information", gtk.ICON_SIZE_DIALOG)
+	if err != nil {
+		dialog.Destroy()
+		return "", fmt.Errorf("failed to create information icon: %w", err)
+	}
+	hbox.PackStart(icon, false, false, 0)
+
+	// Add text label in a vertical box
+	vbox, err := gtk.BoxNew(gtk.ORIENTATION_VERTICAL, 0)
+	if err != nil {
+		dialog.Destroy()
+		return "", fmt.Errorf("failed to create vertical box: %w", err)
+	}
+	hbox.PackStart(vbox, true, true, 0)
+
+	// Add text label
+	label, err := gtk.LabelNew(text)
+	if err != nil {
+		dialog.Destroy()
+		return "", fmt.Errorf("failed to create text label: %w", err)
+	}
+	label.SetLineWrap(true)
+	label.SetSelectable(false)
+	label.SetJustify(gtk.JUSTIFY_LEFT)
+	vbox.PackStart(label, true, true, 0)
+
+	// Add button
+	dialog.AddButton(buttonLabel, gtk.RESPONSE_OK)
+	dialog.ShowAll()
+	dialog.Run()
+	dialog.Destroy()
+
+	return buttonLabel, nil
+}
+
+// createYesNoDialog creates a Yes/No dialog
+func createYesNoDialog(text, yesLabel, noLabel string) (string, error) {
+	// Create the dialog
+	dialog, err := gtk.DialogNew()
+	if err != nil {
+		return "", fmt.Errorf("failed to create dialog: %w", err)
+	}
+
+	dialog.SetTitle("Pi-Apps")
+	dialog.SetPosition(gtk.WIN_POS_CENTER)
+	dialog.SetModal(true)
+	dialog.SetDecorated(false)
+	dialog.SetResizable(false)
+	dialog.SetBorderWidth(20)
+
+	// Add content area
+	contentArea, err := dialog.GetContentArea()
+	if err != nil {
+		dialog.Destroy()
+		return "", fmt.Errorf("failed to get dialog content area: %w", err)
+	}
+
+	// Create a horizontal box to hold the icon and text
+	hbox, err := gtk.BoxNew(gtk.ORIENTATION_HORIZONTAL, 10)
+	if err != nil {
+		dialog.Destroy()
+		return "", fmt.Errorf("failed to create horizontal box: %w", err)
+	}
+	contentArea.Add(hbox)
+
+	// Add question icon
+	icon, err := gtk.ImageNewFromIconName("dialog-question", gtk.ICON_SIZE_DIALOG)
+	if err != nil {
+		dialog.Destroy()
+		return "", fmt.Errorf("failed to create question icon: %w", err)
+	}
+	hbox.PackStart(icon, false, false, 0)
+
+	// Add text label in a vertical box
+	vbox, err := gtk.BoxNew(gtk.ORIENTATION_VERTICAL, 0)
+	if err != nil {
+		dialog.Destroy()
+		return "", fmt.Errorf("failed to create vertical box: %w", err)
+	}
+	hbox.PackStart(vbox, true, true, 0)
+
+	// Add text label
+	label, err := gtk.LabelNew(text)
+	if err != nil {
+		dialog.Destroy()
+		return "", fmt.Errorf("failed to create text label: %w", err)
+	}
+	label.SetLineWrap(true)
+	label.SetSelectable(false)
+	label.SetJustify(gtk.JUSTIFY_LEFT)
+	vbox.PackStart(label, true, true, 0)
+
+	// Add buttons
+	dialog.AddButton(yesLabel, gtk.RESPONSE_YES)
+	dialog.AddButton(noLabel, gtk.RESPONSE_NO)
+	dialog.ShowAll()
+
+	response := dialog.Run()
+	dialog.Destroy()
+
+	// Process the response
+	if response == gtk.RESPONSE_YES {
+		return yesLabel, nil
+	} else {
+		return noLabel, nil
+	}
+}
+
+// createListDialog creates a dialog with a list of options
+func createListDialog(text string, options []string) (string, error) {
+	// Create the dialog
+	dialog, err := gtk.DialogNew()
+	if err != nil {
+		return "", fmt.Errorf("failed to create dialog: %w", err)
+	}
+
+	dialog.SetTitle("Pi-Apps")
+	dialog.SetPosition(gtk.WIN_POS_CENTER)
+	dialog.SetModal(true)
+	dialog.SetDecorated(false)
+	dialog.SetResizable(false)
+	dialog.SetBorderWidth(20)
+
+	// Add content area
+	contentArea, err := dialog.GetContentArea()
+	if err != nil {
+		dialog.Destroy()
+		return "", fmt.Errorf("failed to get dialog content area: %w", err)
+	}
+
+	// Create a horizontal box to hold the icon and content
+	hbox, err := gtk.BoxNew(gtk.ORIENTATION_HORIZONTAL, 10)
+	if err != nil {
+		dialog.Destroy()
+		return "", fmt.Errorf("failed to create horizontal box: %w", err)
+	}
+	contentArea.Add(hbox)
+
+	// Add information icon
+	icon, err := gtk.ImageNewFromIconName("dialog-information", gtk.ICON_SIZE_DIALOG)
+	if err != nil {
+		dialog.Destroy()
+		return "", fmt.Errorf("failed to create information icon: %w", err)
+	}
+	hbox.PackStart(icon, false, false, 0)
+
+	// Add content in a vertical box
+	vbox, err := gtk.BoxNew(gtk.ORIENTATION_VERTICAL, 10)
+	if err != nil {
+		dialog.Destroy()
+		return "", fmt.Errorf("failed to create vertical box: %w", err)
+	}
+	hbox.PackStart(vbox, true, true, 0)
+
+	// Add text label
+	if text != "" {
+		label, err := gtk.LabelNew(text)
+		if err != nil {
+			dialog.Destroy()
+			return "", fmt.Errorf("failed to create text label: %w", err)
+		}
+		label.SetLineWrap(true)
+		label.SetSelectable(false)
+		label.SetJustify(gtk.JUSTIFY_LEFT)
+		vbox.PackStart(label, false, false, 0)
+	}
+
+	// Create a box for the radio buttons
+	radioBox, err := gtk.BoxNew(gtk.ORIENTATION_VERTICAL, 5)
+	if err != nil {
+		dialog.Destroy()
+		return "", fmt.Errorf("failed to create radio button box: %w", err)
+	}
+	vbox.PackStart(radioBox, true, true, 0)
+
+	// Create radio buttons
+	var radioButtons []*gtk.RadioButton
+	for i, opt := range options {
+		var rb *gtk.RadioButton
+		if i == 0 {
+			rb, err = gtk.RadioButtonNewWithLabel(nil, opt)
+		} else {
+			rb, err = gtk.RadioButtonNewWithLabelFromWidget(radioButtons[0], opt)
+		}
+
+		if err != nil {
+			dialog.Destroy()
+			return "", fmt.Errorf("failed to create radio button: %w", err)
+		}
+
+		radioButtons = append(radioButtons, rb)
+		radioBox.PackStart(rb, false, false, 0)
+
+		// Set the first option as active by default
+		if i == 0 {
+			rb.SetActive(true)
+		}
+	}
+
+	// Add OK button
+	dialog.AddButton("OK", gtk.RESPONSE_OK)
+	dialog.ShowAll()
+
+	// Run dialog and wait for response
+	dialog.Run()
+
+	// Find which radio button is active
+	selection := options[0] // Default to first option
+	for i, rb := range radioButtons {
+		if rb.GetActive() {
+			selection = options[i]
+			break
+		}
+	}
+
+	dialog.Destroy()
+	return selection, nil
+}
+
+// gtkUserMultiSelect shows the dialog of UserMultiSelect, with a check button for every option
+func gtkUserMultiSelect(text string, options []string, selected []bool) ([]bool, error) {
+	glib.SetPrgname("Pi-Apps")
+	glib.SetApplicationName("Pi-Apps (user input dialog)")
+	gtk.Init(nil)
+
+	dialog, err := gtk.DialogNew()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dialog: %w", err)
+	}
+	defer dialog.Destroy()
+
+	dialog.SetTitle("Pi-Apps")
+	dialog.SetPosition(gtk.WIN_POS_CENTER)
+	dialog.SetModal(true)
+	dialog.SetDecorated(false)
+	dialog.SetResizable(false)
+	dialog.SetBorderWidth(20)
+
+	contentArea, err := dialog.GetContentArea()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dialog content area: %w", err)
+	}
+
+	// Create a horizontal box to hold the icon, the text and the check buttons
+	hbox, err := gtk.BoxNew(gtk.ORIENTATION_HORIZONTAL, 10)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create horizontal box: %w", err)
+	}
+	contentArea.Add(hbox)
+
+	icon, err := gtk.ImageNewFromIconName("dialog-question", gtk.ICON_SIZE_DIALOG)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create question icon: %w", err)
+	}
+	hbox.PackStart(icon, false, false, 0)
+
+	vbox, err := gtk.BoxNew(gtk.ORIENTATION_VERTICAL, 10)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create vertical box: %w", err)
+	}
+	hbox.PackStart(vbox, true, true, 0)
+
+	label, err := gtk.LabelNew(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create text label: %w", err)
+	}
+	label.SetLineWrap(true)
+	label.SetSelectable(false)
+	label.SetJustify(gtk.JUSTIFY_LEFT)
+	vbox.PackStart(label, false, false, 0)
+
+	checkBox, err := gtk.BoxNew(gtk.ORIENTATION_VERTICAL, 5)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create check button box: %w", err)
+	}
+	vbox.PackStart(checkBox, true, true, 0)
+
+	checkButtons := make([]*gtk.CheckButton, len(options))
+	for i, opt := range options {
+		checkButtons[i], err = gtk.CheckButtonNewWithLabel(opt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create check button: %w", err)
+		}
+		checkButtons[i].SetActive(selected[i])
+		checkBox.PackStart(checkButtons[i], false, false, 0)
+	}
+
+	dialog.AddButton(T("Cancel"), gtk.RESPONSE_CANCEL)
+	dialog.AddButton("OK", gtk.RESPONSE_OK)
+	dialog.SetDefaultResponse(gtk.RESPONSE_OK)
+	dialog.ShowAll()
+
+	if dialog.Run() != gtk.RESPONSE_OK {
+		return nil, errs.New(errs.ErrCancelled, "no options were chosen for: %s", text)
+	}
+	checked := make([]bool, len(options))
+	for i, checkButton := range checkButtons {
+		checked[i] = checkButton.GetActive()
+	}
+	return checked, nil
+}
+
+// gtkPromptSecret shows the dialog of PromptSecret, with an entry that masks what the user types
+func gtkPromptSecret(prompt string) (string, error) {
+	glib.SetPrgname("Pi-Apps")
+	glib.SetApplicationName("Pi-Apps (secret prompt)")
+	gtk.Init(nil)
+
+	dialog, err := gtk.DialogNew()
+	if err != nil {
+		return "", fmt.Errorf("failed to create dialog: %w", err)
+	}
+	defer dialog.Destroy()
+
+	dialog.SetTitle("Pi-Apps")
+	dialog.SetPosition(gtk.WIN_POS_CENTER)
+	dialog.SetModal(true)
+	dialog.SetDecorated(false)
+	dialog.SetResizable(false)
+	dialog.SetBorderWidth(20)
+
+	contentArea, err := dialog.GetContentArea()
+	if err != nil {
+		return "", fmt.Errorf("failed to get dialog content area: %w", err)
+	}
+
+	// Create a horizontal box to hold the icon, the text and the entry
+	hbox, err := gtk.BoxNew(gtk.ORIENTATION_HORIZONTAL, 10)
+	if err != nil {
+		return "", fmt.Errorf("failed to create horizontal box: %w", err)
+	}
+	contentArea.Add(hbox)
+
+	icon, err := gtk.ImageNewFromIconName("dialog-password", gtk.ICON_SIZE_DIALOG)
+	if err != nil {
+		return "", fmt.Errorf("failed to create password icon: %w", err)
+	}
+	hbox.PackStart(icon, false, false, 0)
+
+	vbox, err := gtk.BoxNew(gtk.ORIENTATION_VERTICAL, 10)
+	if err != nil {
+		return "", fmt.Errorf("failed to create vertical box: %w", err)
+	}
+	hbox.PackStart(vbox, true, true, 0)
+
+	label, err := gtk.LabelNew(prompt)
+	if err != nil {
+		return "", fmt.Errorf("failed to create text label: %w", err)
+	}
+	label.SetLineWrap(true)
+	label.SetSelectable(false)
+	label.SetJustify(gtk.JUSTIFY_LEFT)
+	vbox.PackStart(label, true, true, 0)
+
+	entry, err := gtk.EntryNew()
+	if err != nil {
+		return "", fmt.Errorf("failed to create entry: %w", err)
+	}
+	entry.SetVisibility(false)
+	entry.SetActivatesDefault(true)
+	vbox.PackStart(entry, false, false, 0)
+
+	// Add buttons, Enter in the entry confirms
+	dialog.AddButton(T("Cancel"), gtk.RESPONSE_CANCEL)
+	dialog.AddButton("OK", gtk.RESPONSE_OK)
+	dialog.SetDefaultResponse(gtk.RESPONSE_OK)
+	dialog.ShowAll()
+
+	response := dialog.Run()
+	secret, err := entry.GetText()
+	if err != nil {
+		return "", fmt.Errorf("failed to read the entry: %w", err)
+	}
+	if response != gtk.RESPONSE_OK || secret == "" {
+		return "", errs.New(errs.ErrCancelled, "no secret was entered for: %s", prompt)
+	}
+	return secret, nil
+}
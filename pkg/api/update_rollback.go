@@ -0,0 +1,279 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: update_rollback.go
+// Description: Provides snapshots of apps before an update reinstalls them, and restores the previous version if the update fails.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package api
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// UpdateRolledBackError is returned when an update failed and the previous version of the app was restored
+type UpdateRolledBackError struct {
+	App string
+	Err error // why the update failed
+}
+
+func (e *UpdateRolledBackError) Error() string {
+	return fmt.Sprintf("update of %s failed, rolled back to previous version: %v", e.App, e.Err)
+}
+
+func (e *UpdateRolledBackError) Unwrap() error {
+	return e.Err
+}
+
+// updateRollbackEnabled reports whether the "Enable update rollback" setting is not set to No
+func updateRollbackEnabled() bool {
+	directory := GetPiAppsDir()
+	if directory == "" {
+		return false
+	}
+	return ReadSettingIn(directory, "Enable update rollback") != "No"
+}
+
+// appSnapshotDir returns the folder the snapshot of an app is kept in while it is being updated
+func appSnapshotDir(app string) (string, error) {
+	return AppDataPath("update-rollback", app)
+}
+
+// snapshotFiles maps the files of an app's install manifest to their names in the snapshot
+func snapshotFiles(app string) (map[string]string, error) {
+	statusFile, err := AppDataPath("status", app)
+	if err != nil {
+		return nil, err
+	}
+	// Recorded by InstallPackages on apt based systems only, like the pinned packages
+	dummyDebFile, err := AppDataPath("dummy-debs", app)
+	if err != nil {
+		return nil, err
+	}
+	pinnedPackagesFile, err := AppDataPath("pinned-packages", app)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{
+		"status":          statusFile,
+		"install-info":    installInfoFile(app),
+		"dummy-deb":       dummyDebFile,
+		"pinned-packages": pinnedPackagesFile,
+	}, nil
+}
+
+// SnapshotApp saves the app folder and its install manifest (status, install info and
+// dummy deb dependencies) so RollbackAppUpdate can restore them if the update fails
+//
+//	string - folder the snapshot was saved in
+//	error - error if the app name is invalid or the snapshot could not be saved
+func SnapshotApp(app string) (string, error) {
+	snapshot, err := appSnapshotDir(app)
+	if err != nil {
+		return "", err
+	}
+	files, err := snapshotFiles(app)
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.RemoveAll(snapshot); err != nil {
+		return "", fmt.Errorf("failed to remove old snapshot of %s: %w", app, err)
+	}
+	if err := copyDir(filepath.Join(GetPiAppsDir(), "apps", app), filepath.Join(snapshot, "app")); err != nil {
+		os.RemoveAll(snapshot)
+		return "", fmt.Errorf("failed to snapshot app folder of %s: %w", app, err)
+	}
+	for name, source := range files {
+		if !FileExists(source) {
+			continue
+		}
+		if err := CopyFile(source, filepath.Join(snapshot, name)); err != nil {
+			os.RemoveAll(snapshot)
+			return "", fmt.Errorf("failed to snapshot %s of %s: %w", name, app, err)
+		}
+	}
+	return snapshot, nil
+}
+
+// DiscardAppSnapshot removes the snapshot of an app once its update succeeded
+func DiscardAppSnapshot(app string) error {
+	snapshot, err := appSnapshotDir(app)
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(snapshot)
+}
+
+// RollbackAppUpdate restores the version of an app saved by SnapshotApp after its update failed
+//
+// The failed new version is uninstalled first to clean up after it, then the old app folder and
+// install manifest are restored and the old install script is run again. If that fails too but
+// the packages in the old manifest are still installed, the app is marked installed again.
+//
+//	*UpdateRolledBackError - the previous version was restored
+//	error - the rollback failed as well, the app is left corrupted
+func RollbackAppUpdate(app string, cause error) error {
+	snapshot, err := appSnapshotDir(app)
+	if err != nil {
+		return err
+	}
+	if !DirExists(filepath.Join(snapshot, "app")) {
+		return fmt.Errorf("%w (no snapshot to roll back to)", cause)
+	}
+	files, err := snapshotFiles(app)
+	if err != nil {
+		return err
+	}
+
+	logRollback(app, fmt.Sprintf("Update failed: %v\nRolling back to the previous version of %s...", cause, app))
+	StatusTf("Update of %s failed, rolling back to the previous version...", app)
+
+	// Clean up whatever the failed install left behind, the old version is reinstalled below
+	if err := ManageApp(ActionUninstall, app, true); err != nil {
+		WarningTf("Failed to uninstall the new version of %s, restoring the previous version anyway: %v", app, err)
+	}
+
+	appDir := filepath.Join(GetPiAppsDir(), "apps", app)
+	defer InvalidateAppHash(app)
+	if err := os.RemoveAll(appDir); err != nil {
+		return fmt.Errorf("%w; rollback failed: could not remove new app folder: %v", cause, err)
+	}
+	if err := copyDir(filepath.Join(snapshot, "app"), appDir); err != nil {
+		return fmt.Errorf("%w; rollback failed: could not restore app folder: %v", cause, err)
+	}
+	for name, destination := range files {
+		os.Remove(destination)
+		if FileExists(filepath.Join(snapshot, name)) {
+			os.MkdirAll(filepath.Dir(destination), 0755)
+			if err := CopyFile(filepath.Join(snapshot, name), destination); err != nil {
+				return fmt.Errorf("%w; rollback failed: could not restore %s: %v", cause, name, err)
+			}
+		}
+	}
+
+	// Only reinstall the previous version if it was installed before the update
+	oldStatus := readSnapshotStatus(snapshot)
+	if oldStatus == "installed" {
+		if err := ManageApp(ActionInstall, app, true); err != nil {
+			if !oldInstallPresent(app, snapshot) {
+				logRollback(app, fmt.Sprintf("Rollback failed: reinstalling the previous version of %s failed: %v", app, err))
+				return fmt.Errorf("%w; rollback failed: could not reinstall previous version: %v", cause, err)
+			}
+			WarningTf("Reinstalling the previous version of %s failed, but its packages are still installed: %v", app, err)
+			if err := SetAppStatus(app, "installed"); err != nil {
+				return fmt.Errorf("%w; rollback failed: could not restore app status: %v", cause, err)
+			}
+			// The failed reinstall recorded a new install info file, the snapshot is more accurate
+			if FileExists(filepath.Join(snapshot, "install-info")) {
+				CopyFile(filepath.Join(snapshot, "install-info"), files["install-info"])
+			}
+		}
+	}
+
+	logRollback(app, fmt.Sprintf("Rolled back %s to the previous version.", app))
+	StatusGreenTf("Rolled back %s to the previous version.", app)
+	os.RemoveAll(snapshot)
+	return &UpdateRolledBackError{App: app, Err: cause}
+}
+
+// UpdateAppWithRollback runs update, which replaces and reinstalls an app, after taking a snapshot of the app
+// and rolls the app back to the snapshot if update fails. Rollback is skipped if the "Enable update rollback"
+// setting is set to No or the snapshot could not be taken.
+func UpdateAppWithRollback(app string, update func() error) error {
+	// The snapshot, the update and the rollback happen under one lock
+	lock, err := LockState("update " + app)
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
+	if !updateRollbackEnabled() {
+		return update()
+	}
+	if _, err := SnapshotApp(app); err != nil {
+		WarningTf("Automatic rollback is unavailable for %s: %v", app, err)
+		return update()
+	}
+
+	if err := update(); err != nil {
+		return RollbackAppUpdate(app, err)
+	}
+	if err := DiscardAppSnapshot(app); err != nil {
+		Debug(fmt.Sprintf("failed to remove snapshot of %s: %v", app, err))
+	}
+	return nil
+}
+
+// readSnapshotStatus returns the status an app had when its snapshot was taken
+func readSnapshotStatus(snapshot string) string {
+	data, err := os.ReadFile(filepath.Join(snapshot, "status"))
+	if err != nil {
+		return "uninstalled"
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// oldInstallPresent reports whether the packages recorded in the snapshot's manifest are all still installed
+func oldInstallPresent(app, snapshot string) bool {
+	var depends string
+	if appType, err := AppType(app); err == nil && appType == "package" {
+		packages, err := PkgAppPackagesRequired(app)
+		if err != nil {
+			return false
+		}
+		depends = strings.ReplaceAll(packages, " ", ",")
+	} else {
+		data, err := os.ReadFile(filepath.Join(snapshot, "dummy-deb"))
+		if err != nil {
+			return false
+		}
+		depends = string(data)
+	}
+
+	found := false
+	for _, dependency := range strings.Split(depends, ",") {
+		// Only the first alternative of "a | b" and the name of "a (>= 1)" is checked
+		fields := strings.Fields(strings.SplitN(dependency, "|", 2)[0])
+		if len(fields) == 0 {
+			continue
+		}
+		if !PackageInstalled(fields[0]) {
+			return false
+		}
+		found = true
+	}
+	return found
+}
+
+// logRollback appends a message about the rollback to the latest log file of an app
+func logRollback(app, message string) {
+	logFile := GetLogfile(app)
+	if logFile == "" {
+		return
+	}
+	f, err := os.OpenFile(logFile, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		Debug(fmt.Sprintf("failed to write rollback note to %s: %v", logFile, err))
+		return
+	}
+	defer f.Close()
+	fmt.Fprintf(f, "\n%s %s\n", time.Now().Format("2006-01-02 15:04:05"), message)
+}
@@ -0,0 +1,90 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeScriptRecorder gives an app scripts that append their name to $HOME/ran, and returns that file
+func writeScriptRecorder(t *testing.T, directory, app string, scripts ...string) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	for _, script := range scripts {
+		writeTestFile(t, filepath.Join(directory, "apps", app, script), "#!/bin/bash\necho "+script+" >> \"$HOME/ran\"\n")
+	}
+	return filepath.Join(home, "ran")
+}
+
+func TestManageAppRunsTheScriptOfTheAction(t *testing.T) {
+	directory := newTestPiAppsDir(t, "Zoom")
+	ran := writeScriptRecorder(t, directory, "Zoom", "install", "uninstall")
+	writeTestFile(t, filepath.Join(GetDataDir(), "status", "Zoom"), "installed")
+
+	if err := ManageApp(ActionUninstall, "Zoom", false); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(ran); string(data) != "uninstall\n" {
+		t.Errorf("scripts run to uninstall = %q, want the uninstall script", data)
+	}
+}
+
+func TestRollbackAppUpdateUninstallsTheNewVersion(t *testing.T) {
+	directory := newTestPiAppsDir(t, "Zoom")
+	ran := writeScriptRecorder(t, directory, "Zoom", "install", "uninstall")
+	// The app was corrupted before the update, so the previous version isn't installed again
+	writeTestFile(t, filepath.Join(GetDataDir(), "status", "Zoom"), "corrupted")
+	if _, err := SnapshotApp("Zoom"); err != nil {
+		t.Fatal(err)
+	}
+
+	cause := errors.New("the install script failed")
+	err := RollbackAppUpdate("Zoom", cause)
+	var rolledBack *UpdateRolledBackError
+	if !errors.As(err, &rolledBack) || !errors.Is(err, cause) {
+		t.Fatalf("RollbackAppUpdate = %v, want an UpdateRolledBackError", err)
+	}
+	if data, _ := os.ReadFile(ran); string(data) != "uninstall\n" {
+		t.Errorf("scripts run by the rollback = %q, want the uninstall script", data)
+	}
+	if status, _ := GetAppStatus("Zoom"); status != "corrupted" {
+		t.Errorf("status after the rollback = %q, want the one of the snapshot", status)
+	}
+}
+
+func TestUpdateAppRollsBack(t *testing.T) {
+	directory := newTestPiAppsDir(t, "Zoom")
+	ran := writeScriptRecorder(t, directory, "Zoom", "install", "uninstall")
+	writeTestFile(t, filepath.Join(directory, "apps", "Zoom", "update"), "#!/bin/bash\necho update >> \"$HOME/ran\"\nexit 1\n")
+	writeTestFile(t, filepath.Join(GetDataDir(), "status", "Zoom"), "corrupted")
+
+	err := UpdateApp("Zoom")
+	var rolledBack *UpdateRolledBackError
+	if !errors.As(err, &rolledBack) {
+		t.Fatalf("UpdateApp with a failing update script = %v, want an UpdateRolledBackError", err)
+	}
+	if data, _ := os.ReadFile(ran); string(data) != "update\nuninstall\n" {
+		t.Errorf("scripts run by the update = %q, want the update script and the uninstall script of the rollback", data)
+	}
+	if snapshot, _ := appSnapshotDir("Zoom"); DirExists(snapshot) {
+		t.Error("the snapshot of the update was left behind")
+	}
+}
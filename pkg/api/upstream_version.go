@@ -0,0 +1,471 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: upstream_version.go
+// Description: Checks whether the upstream software of installed apps has a newer release than the one installed.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// UpstreamCheck is how to find the latest upstream release of an app, declared in apps/<app>/upstream-check
+//
+// The file has one "key=value" per line and # starts a comment. Exactly one source is used:
+//
+//	github=owner/repo          the tag of the latest GitHub release
+//	url=https://example.com/   a web page, with regex=app-([0-9.]+)\.deb matching the version in it
+//	package=name               the newest version of a package in the package manager's repositories
+//
+// A leading "v" is stripped from versions, like the one of GitHub tags.
+type UpstreamCheck struct {
+	GitHub  string `json:"github,omitempty"`
+	URL     string `json:"url,omitempty"`
+	Regex   string `json:"regex,omitempty"` // the version is the first group of the first match, or the whole match
+	Package string `json:"package,omitempty"`
+}
+
+// UpstreamVersion is the installed and latest upstream version of an app
+type UpstreamVersion struct {
+	App       string    `json:"app"`
+	Installed string    `json:"installed,omitempty"` // "" if it isn't known
+	Latest    string    `json:"latest"`
+	Outdated  bool      `json:"outdated"` // the latest version is newer than the installed one
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+const (
+	// upstreamCacheMaxAge is how long a checked upstream version is used before checking again
+	upstreamCacheMaxAge = 24 * time.Hour
+	// upstreamPageMaxSize is how much of a web page is searched for the version
+	upstreamPageMaxSize = 2 << 20
+)
+
+// upstreamLimiter spaces out the requests of upstream checks, so checking every app doesn't hammer GitHub
+var upstreamLimiter = rate.NewLimiter(rate.Every(time.Second), 3)
+
+// upstreamClient is the HTTP client of upstream checks
+var upstreamClient = &http.Client{Timeout: 30 * time.Second}
+
+// upstreamCacheMutex serializes reads and writes of the upstream version cache within a process
+var upstreamCacheMutex sync.Mutex
+
+// upstreamCache is data/cache/upstream-versions, the latest versions found by upstream checks
+type upstreamCache struct {
+	Versions map[string]upstreamCacheEntry `json:"versions"`
+	// GitHubRetryAfter is when GitHub accepts requests again after its rate limit was reached
+	GitHubRetryAfter time.Time `json:"github_retry_after,omitzero"`
+}
+
+// upstreamCacheEntry is the latest version of an app, valid for the check it was found with
+type upstreamCacheEntry struct {
+	Check     UpstreamCheck `json:"check"`
+	Latest    string        `json:"latest"`
+	CheckedAt time.Time     `json:"checked_at"`
+}
+
+// upstreamCachePath returns the path of the upstream version cache file
+func upstreamCachePath() string {
+	return filepath.Join(GetDataDir(), "cache", "upstream-versions")
+}
+
+// readUpstreamCache reads the upstream version cache, empty if there is none
+func readUpstreamCache() upstreamCache {
+	cache := upstreamCache{Versions: make(map[string]upstreamCacheEntry)}
+	if data, err := os.ReadFile(upstreamCachePath()); err == nil {
+		json.Unmarshal(data, &cache)
+	}
+	if cache.Versions == nil {
+		cache.Versions = make(map[string]upstreamCacheEntry)
+	}
+	return cache
+}
+
+// writeUpstreamCache writes the upstream version cache
+func writeUpstreamCache(cache upstreamCache) error {
+	data, err := json.Marshal(cache)
+	if err != nil {
+		return err
+	}
+	if err := EnsureDirOwned(filepath.Dir(upstreamCachePath()), InvokingUser, InvokingUser); err != nil {
+		return err
+	}
+	return os.WriteFile(upstreamCachePath(), data, 0644)
+}
+
+// ReadUpstreamCheck reads the upstream-check file of an app
+//
+//	UpstreamCheck - how to find the latest upstream release
+//	bool - false if the app has no upstream-check file
+//	error - error if the app name is not valid or the file can't be read or declares no source
+func ReadUpstreamCheck(app string) (UpstreamCheck, bool, error) {
+	var check UpstreamCheck
+	path, err := AppPath(app, "upstream-check")
+	if err != nil {
+		return check, false, err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return check, false, nil
+		}
+		return check, false, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, _ := strings.Cut(line, "=")
+		value = strings.TrimSpace(value)
+		switch strings.TrimSpace(key) {
+		case "github":
+			check.GitHub = strings.Trim(strings.TrimPrefix(value, "https://github.com/"), "/")
+		case "url":
+			check.URL = value
+		case "regex":
+			check.Regex = value
+		case "package", "apt":
+			check.Package = value
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return check, false, err
+	}
+
+	switch {
+	case check.GitHub != "":
+		if owner, repo, ok := strings.Cut(check.GitHub, "/"); !ok || owner == "" || repo == "" || strings.Contains(repo, "/") {
+			return check, true, fmt.Errorf("invalid GitHub repository %q in the upstream-check file of %s", check.GitHub, app)
+		}
+	case check.URL != "":
+		if check.Regex == "" {
+			return check, true, fmt.Errorf("the upstream-check file of %s has a url but no regex", app)
+		}
+		if _, err := regexp.Compile(check.Regex); err != nil {
+			return check, true, fmt.Errorf("invalid regex in the upstream-check file of %s: %w", app, err)
+		}
+	case check.Package == "":
+		return check, true, fmt.Errorf("the upstream-check file of %s declares no github, url or package", app)
+	}
+	return check, true, nil
+}
+
+// CheckUpstreamVersion returns the installed and latest upstream version of an app with an upstream-check file.
+// The latest version is checked at most once a day, results are cached in data/cache/upstream-versions.
+//
+// The installed version is the one recorded in the install manifest, or the installed version of the package
+// for package checks.
+func CheckUpstreamVersion(app string) (UpstreamVersion, error) {
+	result := UpstreamVersion{App: app}
+	check, ok, err := ReadUpstreamCheck(app)
+	if err != nil {
+		return result, err
+	}
+	if !ok {
+		return result, fmt.Errorf("app %s has no upstream-check file", app)
+	}
+
+	result.Latest, result.CheckedAt, err = latestUpstreamVersion(app, check)
+	if err != nil {
+		return result, err
+	}
+
+	result.Installed, err = ReadInstalledUpstreamVersion(app)
+	if err != nil {
+		return result, err
+	}
+	if result.Installed == "" && check.Package != "" {
+		if version, err := PackageInstalledVersion(check.Package); err == nil {
+			result.Installed = normalizeUpstreamVersion(version)
+		}
+	}
+	result.Outdated = result.Installed != "" && compareVersions(result.Latest, result.Installed) > 0
+	return result, nil
+}
+
+// latestUpstreamVersion returns the latest upstream version of an app and when it was checked, from the cache
+// if it was checked in the last day
+func latestUpstreamVersion(app string, check UpstreamCheck) (string, time.Time, error) {
+	upstreamCacheMutex.Lock()
+	defer upstreamCacheMutex.Unlock()
+
+	cache := readUpstreamCache()
+	if entry, ok := cache.Versions[app]; ok && entry.Check == check && time.Since(entry.CheckedAt) < upstreamCacheMaxAge {
+		return entry.Latest, entry.CheckedAt, nil
+	}
+
+	var latest string
+	var err error
+	switch {
+	case check.GitHub != "":
+		if time.Now().Before(cache.GitHubRetryAfter) {
+			return "", time.Time{}, fmt.Errorf("the GitHub API rate limit was reached, try again after %s", cache.GitHubRetryAfter.Local().Format("15:04"))
+		}
+		var retryAfter time.Time
+		latest, retryAfter, err = githubLatestRelease(check.GitHub)
+		if !retryAfter.IsZero() {
+			cache.GitHubRetryAfter = retryAfter
+			writeUpstreamCache(cache)
+		}
+	case check.URL != "":
+		latest, err = scrapeUpstreamVersion(check.URL, check.Regex)
+	default:
+		latest, err = PackageLatestVersion(check.Package)
+		if err == nil && latest == "" {
+			err = fmt.Errorf("package %s is not available", check.Package)
+		}
+	}
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to check the upstream version of %s: %w", app, err)
+	}
+
+	latest = normalizeUpstreamVersion(latest)
+	checkedAt := time.Now()
+	cache.Versions[app] = upstreamCacheEntry{Check: check, Latest: latest, CheckedAt: checkedAt}
+	if err := writeUpstreamCache(cache); err != nil {
+		Debug(fmt.Sprintf("Failed to write the upstream version cache: %v", err))
+	}
+	return latest, checkedAt, nil
+}
+
+// upstreamGet sends a rate limited GET request for an upstream check
+func upstreamGet(url string, headers map[string]string) (*http.Response, error) {
+	if err := upstreamLimiter.Wait(context.Background()); err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "Pi-Apps/1.0")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	return upstreamClient.Do(req)
+}
+
+// githubLatestRelease returns the tag of the latest release of a GitHub repository. When GitHub's rate limit
+// is reached, it also returns when requests are accepted again. A GITHUB_TOKEN raises the rate limit.
+func githubLatestRelease(repo string) (string, time.Time, error) {
+	headers := map[string]string{"Accept": "application/vnd.github+json"}
+	if token := os.Getenv("GITHUB_TOKEN"); token != "" {
+		headers["Authorization"] = "Bearer " + token
+	}
+	resp, err := upstreamGet("https://api.github.com/repos/"+repo+"/releases/latest", headers)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return "", time.Time{}, fmt.Errorf("GitHub repository %s has no releases", repo)
+	case (resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusTooManyRequests) && resp.Header.Get("X-RateLimit-Remaining") == "0":
+		retryAfter := time.Now().Add(time.Hour)
+		if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			retryAfter = time.Unix(reset, 0)
+		}
+		return "", retryAfter, fmt.Errorf("the GitHub API rate limit was reached, try again after %s", retryAfter.Local().Format("15:04"))
+	case resp.StatusCode != http.StatusOK:
+		return "", time.Time{}, fmt.Errorf("GitHub API returned status %d", resp.StatusCode)
+	}
+
+	var release struct {
+		TagName string `json:"tag_name"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to parse the latest release of %s: %w", repo, err)
+	}
+	if release.TagName == "" {
+		return "", time.Time{}, fmt.Errorf("the latest release of %s has no tag", repo)
+	}
+	return release.TagName, time.Time{}, nil
+}
+
+// scrapeUpstreamVersion returns the first group of the first match of a regex in a web page, or the whole match
+func scrapeUpstreamVersion(url, pattern string) (string, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return "", err
+	}
+	resp, err := upstreamGet(url, nil)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%s returned status %d", url, resp.StatusCode)
+	}
+	page, err := io.ReadAll(io.LimitReader(resp.Body, upstreamPageMaxSize))
+	if err != nil {
+		return "", err
+	}
+
+	match := re.FindSubmatch(page)
+	switch {
+	case match == nil:
+		return "", fmt.Errorf("no version matching %s found on %s", pattern, url)
+	case len(match) > 1:
+		return string(match[1]), nil
+	}
+	return string(match[0]), nil
+}
+
+// normalizeUpstreamVersion strips the "v" of tags like v1.2.0 and surrounding space
+func normalizeUpstreamVersion(version string) string {
+	version = strings.TrimSpace(version)
+	if len(version) > 1 && (version[0] == 'v' || version[0] == 'V') && version[1] >= '0' && version[1] <= '9' {
+		return version[1:]
+	}
+	return version
+}
+
+// ReadInstalledUpstreamVersion returns the upstream version recorded in an app's install manifest, "" if there is none
+func ReadInstalledUpstreamVersion(app string) (string, error) {
+	lines, err := readInstallManifest(app)
+	if err != nil {
+		return "", err
+	}
+	for _, line := range lines {
+		if version, ok := strings.CutPrefix(line, installUpstreamVersionPrefix); ok {
+			return strings.TrimSpace(version), nil
+		}
+	}
+	return "", nil
+}
+
+// SetInstalledUpstreamVersion records the installed upstream version of an app in its install manifest,
+// an empty version removes it. Install scripts set it with `api set_installed_version <version>`.
+func SetInstalledUpstreamVersion(app, version string) error {
+	version = normalizeUpstreamVersion(version)
+	if strings.ContainsAny(version, "\n\r") {
+		return fmt.Errorf("invalid version %q", version)
+	}
+	lines, err := readInstallManifest(app)
+	if err != nil {
+		return err
+	}
+	var updated []string
+	if version != "" {
+		updated = append(updated, installUpstreamVersionPrefix+version)
+	}
+	for _, line := range lines {
+		if !strings.HasPrefix(line, installUpstreamVersionPrefix) {
+			updated = append(updated, line)
+		}
+	}
+	return writeInstallManifest(app, updated)
+}
+
+// recordInstalledUpstreamVersion records the upstream version an install script installed, unless the script
+// recorded it itself: the installed package version for package checks, otherwise the latest upstream version
+func recordInstalledUpstreamVersion(app string) {
+	check, ok, err := ReadUpstreamCheck(app)
+	if err != nil || !ok {
+		return
+	}
+	if version, err := ReadInstalledUpstreamVersion(app); err != nil || version != "" {
+		return
+	}
+
+	var version string
+	if check.Package != "" {
+		version, err = PackageInstalledVersion(check.Package)
+	} else {
+		version, _, err = latestUpstreamVersion(app, check)
+	}
+	if err != nil || version == "" {
+		Debug(fmt.Sprintf("Failed to find the installed upstream version of %s: %v", app, err))
+		return
+	}
+	if err := SetInstalledUpstreamVersion(app, version); err != nil {
+		Debug(fmt.Sprintf("Failed to record the installed upstream version of %s: %v", app, err))
+	}
+}
+
+// OutdatedApps returns the installed apps whose upstream software has a newer release, although their
+// scripts didn't change. Apps Pi-Apps has an update for are left out, updating them may install the new release.
+//
+//	[]UpstreamVersion - the outdated apps, sorted by name
+//	error - the checks that failed, the other apps are still returned
+func OutdatedApps() ([]UpstreamVersion, error) {
+	installed, err := ListApps("installed")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list installed apps: %w", err)
+	}
+	pending := PendingAppUpdates()
+
+	var outdated []UpstreamVersion
+	var errs []error
+	for _, app := range installed {
+		if pending[app] {
+			continue
+		}
+		if _, ok, err := ReadUpstreamCheck(app); err != nil {
+			errs = append(errs, err)
+			continue
+		} else if !ok {
+			continue
+		}
+		version, err := CheckUpstreamVersion(app)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if version.Outdated {
+			outdated = append(outdated, version)
+		}
+	}
+	sort.Slice(outdated, func(i, j int) bool { return outdated[i].App < outdated[j].App })
+	return outdated, errors.Join(errs...)
+}
+
+// PendingAppUpdates returns the apps the updater last found updates for, from data/update-status/updatable-apps
+func PendingAppUpdates() map[string]bool {
+	pending := make(map[string]bool)
+	data, err := os.ReadFile(filepath.Join(GetDataDir(), "update-status", "updatable-apps"))
+	if err != nil {
+		return pending
+	}
+	for _, app := range strings.Split(string(data), "\n") {
+		if app = strings.TrimSpace(app); app != "" {
+			pending[app] = true
+		}
+	}
+	return pending
+}
@@ -0,0 +1,306 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: user_data.go
+// Description: Reads the configuration and data paths apps declare in their user-data file, and asks whether to keep
+// or delete them after an app was uninstalled.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package api
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+const (
+	// What happened to the user data of an uninstalled app, as recorded in the history log
+	userDataKept    = "kept"
+	userDataDeleted = "deleted"
+)
+
+// UserDataPath is a configuration or data path an app declares in its user-data file
+type UserDataPath struct {
+	Declared string `json:"declared"` // the line of the user-data file
+	Path     string `json:"path"`     // the path with $HOME expanded
+	Exists   bool   `json:"exists"`
+	Size     int64  `json:"size"` // bytes, of all files below it for a folder
+}
+
+// expandUserDataPath expands ~, $HOME and ${HOME} in a declared path
+func expandUserDataPath(declared, home string) string {
+	path := declared
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		path = home + path[1:]
+	}
+	path = strings.ReplaceAll(path, "${HOME}", home)
+	return strings.ReplaceAll(path, "$HOME", home)
+}
+
+// sharedUserDataDirs are folders below $HOME and /etc that hold the files of many programs. A user-data path can be
+// below them, but never one of them, deleting it would delete the data of every program in it.
+var sharedUserDataDirs = []string{
+	"$HOME/.config", "$HOME/.config/autostart", "$HOME/.config/systemd", "$HOME/.config/systemd/user",
+	"$HOME/.local", "$HOME/.local/bin", "$HOME/.local/lib", "$HOME/.local/share", "$HOME/.local/share/applications",
+	"$HOME/.local/share/icons", "$HOME/.local/share/fonts", "$HOME/.local/share/flatpak", "$HOME/.local/state",
+	"$HOME/.cache", "$HOME/.var", "$HOME/.var/app", "$HOME/.ssh", "$HOME/.gnupg", "$HOME/.themes",
+	"$HOME/.icons", "$HOME/Desktop", "$HOME/Documents", "$HOME/Downloads", "$HOME/Music", "$HOME/Pictures",
+	"$HOME/Public", "$HOME/Templates", "$HOME/Videos",
+	"/etc/apt", "/etc/apt/sources.list.d", "/etc/apt/preferences.d", "/etc/apt/trusted.gpg.d", "/etc/apt/keyrings",
+	"/etc/systemd", "/etc/systemd/system", "/etc/systemd/user", "/etc/xdg", "/etc/xdg/autostart", "/etc/X11",
+	"/etc/X11/xorg.conf.d", "/etc/default", "/etc/profile.d", "/etc/sudoers.d", "/etc/udev", "/etc/udev/rules.d",
+	"/etc/modprobe.d", "/etc/security", "/etc/ssl", "/etc/ld.so.conf.d", "/etc/cron.d", "/etc/polkit-1",
+}
+
+// validateUserDataPath expands a declared path and checks it can only ever name the app's own data: an absolute
+// path at least two levels below $HOME or /etc, like $HOME/.config/zoom, that isn't a shared folder and has no
+// wildcards, other variables or .. that could make it match unrelated files
+func validateUserDataPath(declared, home string) (string, error) {
+	path := expandUserDataPath(declared, home)
+	if strings.ContainsAny(path, "$`") {
+		return "", fmt.Errorf("%s: only $HOME can be used", declared)
+	}
+	if strings.ContainsAny(path, "*?[]{}") {
+		return "", fmt.Errorf("%s: wildcards are not allowed", declared)
+	}
+	if !filepath.IsAbs(path) {
+		return "", fmt.Errorf("%s: not an absolute path", declared)
+	}
+	if slices.Contains(strings.Split(path, "/"), "..") {
+		return "", fmt.Errorf("%s: .. is not allowed", declared)
+	}
+	path = filepath.Clean(path)
+	for _, shared := range sharedUserDataDirs {
+		if path == filepath.Clean(expandUserDataPath(shared, home)) {
+			return "", fmt.Errorf("%s: %s holds the files of other programs too", declared, shared)
+		}
+	}
+	for _, parent := range []string{home, "/etc"} {
+		if parent == "" || parent == "/" {
+			continue
+		}
+		relative, found := strings.CutPrefix(path, filepath.Clean(parent)+"/")
+		if !found {
+			continue
+		}
+		if !strings.Contains(relative, "/") {
+			return "", fmt.Errorf("%s: must be at least two levels below $HOME or /etc, like $HOME/.config/<app>", declared)
+		}
+		return path, nil
+	}
+	return "", fmt.Errorf("%s: not below $HOME or /etc", declared)
+}
+
+// parseUserData reads the paths of a user-data file, one per line, ignoring comments. It returns the valid paths
+// and an error naming every invalid line.
+func parseUserData(content, home string) ([]UserDataPath, error) {
+	var paths []UserDataPath
+	var problems []error
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for line := 1; scanner.Scan(); line++ {
+		declared := strings.TrimSpace(scanner.Text())
+		if declared == "" || strings.HasPrefix(declared, "#") {
+			continue
+		}
+		path, err := validateUserDataPath(declared, home)
+		if err != nil {
+			problems = append(problems, fmt.Errorf("user-data line %d: %w", line, err))
+			continue
+		}
+		if !slices.ContainsFunc(paths, func(other UserDataPath) bool { return other.Path == path }) {
+			paths = append(paths, UserDataPath{Declared: declared, Path: path})
+		}
+	}
+	return paths, errors.Join(problems...)
+}
+
+// validateUserDataFile checks the user-data file of an app folder, if it has one
+func validateUserDataFile(appDir string) error {
+	content, err := os.ReadFile(filepath.Join(appDir, "user-data"))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	_, err = parseUserData(string(content), os.Getenv("HOME"))
+	return err
+}
+
+// AppUserData returns the configuration and data paths an app declares in apps/<app>/user-data, with whether they
+// exist and their size. An app without the file declares none.
+//
+//	[]UserDataPath - the valid paths in the order of the file
+//	error - error if the app name is not valid, the file can't be read or has invalid lines, the valid paths are
+//	returned along with it
+func AppUserData(app string) ([]UserDataPath, error) {
+	path, err := AppPath(app, "user-data")
+	if err != nil {
+		return nil, err
+	}
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	paths, err := parseUserData(string(content), os.Getenv("HOME"))
+	for i := range paths {
+		info, statErr := os.Lstat(paths[i].Path)
+		if statErr != nil {
+			continue
+		}
+		paths[i].Exists = true
+		if info.IsDir() {
+			paths[i].Size = dirSize(paths[i].Path)
+		} else {
+			paths[i].Size = info.Size()
+		}
+	}
+	return paths, err
+}
+
+// ValidateApp checks the files of an app that Pi-Apps acts on: the paths of its user-data file, the components
+// of its components file and its translated descriptions
+func ValidateApp(app string) error {
+	if !IsValidApp(app) {
+		return fmt.Errorf("app '%s' does not exist", app)
+	}
+	appDir := filepath.Join(GetPiAppsDir(), "apps", app)
+	return errors.Join(validateAppFiles(appDir), validateDescriptionTranslations(appDir))
+}
+
+// validateAppFiles checks the files of an app folder that change what managing the app does: the paths of its
+// user-data file and the components of its components file
+func validateAppFiles(appDir string) error {
+	return errors.Join(validateUserDataFile(appDir), validateComponentsFile(appDir))
+}
+
+// promptUserData asks whether to keep or delete the user data and the stored secrets of an app that was just
+// uninstalled, and records the answer. Without a display or a terminal to ask on, they are kept.
+func promptUserData(app string) {
+	paths, err := AppUserData(app)
+	if err != nil {
+		WarningTf("Ignoring invalid lines of the user-data file of %s: %v", app, err)
+	}
+	var existing []UserDataPath
+	var list strings.Builder
+	for _, path := range paths {
+		if path.Exists {
+			existing = append(existing, path)
+			list.WriteString(fmt.Sprintf("\n  - %s (%s)", path.Path, FormatSize(uint64(path.Size))))
+		}
+	}
+	secrets, err := AppSecretKeys(app)
+	if err != nil {
+		WarningTf("Failed to list the secrets of %s: %v", app, err)
+	}
+	if len(secrets) > 0 {
+		list.WriteString("\n  - " + Tf("Stored secrets: %s", strings.Join(secrets, ", ")))
+	}
+	if len(existing) == 0 && len(secrets) == 0 {
+		return
+	}
+
+	keep := T("Keep my data")
+	answer := keep
+	if canUseGTK() || stdinIsTerminal() {
+		answer, err = UserInputFunc(Tf("%s was uninstalled, but its settings and data are still there:", app)+list.String()+"\n\n"+
+			T("Keep them to get them back when you install it again, or delete them?"), keep, T("Delete them"))
+		if err != nil {
+			answer = keep
+		}
+	}
+
+	choice := userDataKept
+	if answer == keep {
+		StatusTf("Kept the settings and data of %s:%s", app, list.String())
+	} else {
+		choice = deleteUserData(app, existing, len(secrets) > 0)
+	}
+	if err := recordUserDataChoice(app, choice); err != nil {
+		WarningTf("Failed to record what happened to the data of %s: %v", app, err)
+	}
+}
+
+// deleteUserData deletes the existing user data paths of an app, and its stored secrets if it has any. It returns
+// the choice to record, kept when anything could not be deleted.
+func deleteUserData(app string, existing []UserDataPath, secrets bool) string {
+	choice := userDataDeleted
+	for _, path := range existing {
+		if err := removeUserDataPath(path.Path); err != nil {
+			WarningTf("Failed to delete %s: %v", path.Path, err)
+			choice = userDataKept
+		}
+	}
+	if secrets {
+		if err := DeleteAppSecrets(app); err != nil {
+			WarningTf("Failed to delete the secrets of %s: %v", app, err)
+			choice = userDataKept
+		}
+	}
+	if choice == userDataDeleted {
+		StatusGreenTf("Deleted the settings and data of %s", app)
+	}
+	return choice
+}
+
+// removeUserDataPath deletes a user data path, those in /etc as root
+func removeUserDataPath(path string) error {
+	if strings.HasPrefix(path, "/etc/") {
+		return SudoPopup("rm", "-rf", "--", path)
+	}
+	return os.RemoveAll(path)
+}
+
+// recordUserDataChoice adds what happened to the data of an uninstalled app to the history log
+func recordUserDataChoice(app, choice string) error {
+	return recordHistory(app, historyUserData, choice)
+}
+
+// lastUserDataChoice returns what happened to the data of an app the last time it was uninstalled, "" if nothing
+// was recorded
+func lastUserDataChoice(app string) string {
+	entries := readHistory(app, historyUserData)
+	if len(entries) == 0 || len(entries[len(entries)-1].Details) == 0 {
+		return ""
+	}
+	return entries[len(entries)-1].Details[0]
+}
+
+// mentionKeptUserData tells the user that an app being installed again finds the data kept when it was
+// uninstalled
+func mentionKeptUserData(app string) {
+	if lastUserDataChoice(app) != userDataKept {
+		return
+	}
+	paths, _ := AppUserData(app)
+	var kept []string
+	for _, path := range paths {
+		if path.Exists {
+			kept = append(kept, path.Path)
+		}
+	}
+	if len(kept) > 0 {
+		StatusTf("The settings and data of %s kept when it was uninstalled are used again: %s", app, strings.Join(kept, ", "))
+	}
+}
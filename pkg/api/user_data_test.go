@@ -0,0 +1,161 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidateUserDataPath(t *testing.T) {
+	home := "/home/pi"
+	for declared, want := range map[string]string{
+		"$HOME/.config/zoom":        "/home/pi/.config/zoom",
+		"${HOME}/.local/share/zoom": "/home/pi/.local/share/zoom",
+		"~/.zoom/settings/":         "/home/pi/.zoom/settings",
+		"/etc/zoom/zoom.conf":       "/etc/zoom/zoom.conf",
+		"/home/pi/.config/Zoom":     "/home/pi/.config/Zoom",
+	} {
+		if path, err := validateUserDataPath(declared, home); err != nil || path != want {
+			t.Errorf("validateUserDataPath(%q) = %q, %v, want %q", declared, path, err, want)
+		}
+	}
+	for _, declared := range []string{
+		"$HOME",
+		"~",
+		"/etc",
+		"$HOME/.config/*",
+		"$HOME/.config/zoom?",
+		"$HOME/.config/[zZ]oom",
+		"$HOME/../other/.config",
+		"$HOME/.config/../../other",
+		"$XDG_CONFIG_HOME/zoom",
+		"/usr/share/zoom",
+		"/etcetera/zoom",
+		".config/zoom",
+		// Only one level below $HOME or /etc
+		"~/.zoom",
+		"/etc/zoom.conf",
+	} {
+		if path, err := validateUserDataPath(declared, home); err == nil {
+			t.Errorf("validateUserDataPath(%q) = %q, want an error", declared, path)
+		}
+	}
+
+	// Folders other programs keep their files in can't be deleted with an app
+	for _, declared := range []string{
+		"$HOME/.config",
+		"~/.config/",
+		"$HOME/.local",
+		"$HOME/.local/share",
+		"${HOME}/.local/share/applications",
+		"$HOME/.cache",
+		"$HOME/Desktop",
+		"$HOME/Documents",
+		"$HOME/.config/autostart",
+		"/etc/apt",
+		"/etc/apt/sources.list.d",
+		"/etc/systemd",
+		"/etc/systemd/system/",
+		"/etc/xdg/autostart",
+	} {
+		if path, err := validateUserDataPath(declared, home); err == nil {
+			t.Errorf("validateUserDataPath(%q) = %q, want an error for a shared folder", declared, path)
+		}
+	}
+}
+
+func TestAppUserData(t *testing.T) {
+	directory := newTestPiAppsDir(t, "Zoom", "Plain")
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	writeTestFile(t, filepath.Join(directory, "apps", "Zoom", "user-data"),
+		"# Settings and recordings\n$HOME/.config/zoom\n\n~/.zoom/zoom.conf\n$HOME/.cache/zoom\n$HOME/*\n~/.zoom/zoom.conf\n")
+	writeTestFile(t, filepath.Join(home, ".config", "zoom", "a"), "12345")
+	writeTestFile(t, filepath.Join(home, ".config", "zoom", "b", "c"), "123")
+	writeTestFile(t, filepath.Join(home, ".zoom", "zoom.conf"), "1")
+
+	paths, err := AppUserData("Zoom")
+	if err == nil || !strings.Contains(err.Error(), "line 6") {
+		t.Errorf("AppUserData error = %v, want the wildcard on line 6", err)
+	}
+	want := []UserDataPath{
+		{Declared: "$HOME/.config/zoom", Path: filepath.Join(home, ".config", "zoom"), Exists: true, Size: 8},
+		{Declared: "~/.zoom/zoom.conf", Path: filepath.Join(home, ".zoom", "zoom.conf"), Exists: true, Size: 1},
+		{Declared: "$HOME/.cache/zoom", Path: filepath.Join(home, ".cache", "zoom")},
+	}
+	if len(paths) != len(want) {
+		t.Fatalf("AppUserData = %+v, want %+v", paths, want)
+	}
+	for i := range want {
+		if paths[i] != want[i] {
+			t.Errorf("AppUserData[%d] = %+v, want %+v", i, paths[i], want[i])
+		}
+	}
+
+	if paths, err := AppUserData("Plain"); err != nil || len(paths) != 0 {
+		t.Errorf("AppUserData of an app without user-data = %v, %v, want nothing", paths, err)
+	}
+	if err := ValidateApp("Zoom"); err == nil {
+		t.Error("ValidateApp accepted a user-data file with a wildcard")
+	}
+	if err := ValidateApp("Plain"); err != nil {
+		t.Errorf("ValidateApp(Plain) = %v", err)
+	}
+}
+
+func TestPromptUserDataKeepsWithoutPrompt(t *testing.T) {
+	directory := newTestPiAppsDir(t, "Zoom")
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("DISPLAY", "")
+	t.Setenv("WAYLAND_DISPLAY", "")
+	writeTestFile(t, filepath.Join(directory, "apps", "Zoom", "user-data"), "$HOME/.config/zoom\n")
+	settings := filepath.Join(home, ".config", "zoom", "settings.ini")
+	writeTestFile(t, settings, "volume=3\n")
+
+	// Without a display or a terminal on stdin nobody can be asked
+	stdin := os.Stdin
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	writer.Close()
+	defer reader.Close()
+	os.Stdin = reader
+	defer func() { os.Stdin = stdin }()
+
+	if lastUserDataChoice("Zoom") != "" {
+		t.Fatal("a choice was recorded before the app was uninstalled")
+	}
+	promptUserData("Zoom")
+	if _, err := os.Stat(settings); err != nil {
+		t.Errorf("the user data was not kept: %v", err)
+	}
+	if choice := lastUserDataChoice("Zoom"); choice != userDataKept {
+		t.Errorf("recorded choice = %q, want %q", choice, userDataKept)
+	}
+
+	if err := recordUserDataChoice("Zoom", userDataDeleted); err != nil {
+		t.Fatal(err)
+	}
+	if choice := lastUserDataChoice("Zoom"); choice != userDataDeleted {
+		t.Errorf("recorded choice after deleting = %q, want the latest one", choice)
+	}
+}
@@ -0,0 +1,455 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: user_service.go
+// Description: Creates the systemd user services of apps and stops and removes them when the app is uninstalled.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package api
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// UserServiceSpec is a background service of an app, written as a systemd user unit by CreateUserService
+type UserServiceSpec struct {
+	Name             string   // name of the unit without .service, required
+	Description      string   // shown by systemctl status, defaults to the app name
+	ExecStart        []string // command and its arguments, unescaped. The command must be an absolute path to an executable.
+	WorkingDirectory string   // absolute working directory of the command
+	Environment      []string // NAME=value variables of the command
+	Restart          string   // when systemd restarts the service: no, on-failure, on-abnormal or always. Defaults to on-failure.
+	WantedBy         string   // user target that starts the service, defaults to default.target (the login of the user)
+	OnCalendar       string   // run the service on this schedule with a <name>.timer instead of keeping it running
+	Enable           bool     // start the service, or its timer, at every login
+	Start            bool     // start the service, or its timer, now
+}
+
+// userServiceAppKey marks the unit files Pi-Apps created for an app, so uninstalling it only removes those
+const userServiceAppKey = "X-Pi-Apps-App"
+
+// installUserServicePrefix starts the install manifest lines recording the unit files created by CreateUserService.
+// They are kept apart from the installed files because the install snapshot doesn't cover ~/.config/systemd.
+const installUserServicePrefix = "# user service: "
+
+var (
+	// userServiceNamePattern matches the unit names CreateUserService accepts, templates like foo@.service excluded
+	userServiceNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.:-]*$`)
+	// userServiceTargetPattern matches the names of systemd targets
+	userServiceTargetPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.:-]*\.target$`)
+	// environmentNamePattern matches the names of environment variables
+	environmentNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+)
+
+// userServiceRestartValues are the Restart= values a user service may use
+var userServiceRestartValues = []string{"no", "on-failure", "on-abnormal", "always"}
+
+// systemTargets only exist in the system instance of systemd, a user service wanted by them never starts
+var systemTargets = []string{"multi-user.target", "graphical.target", "network-online.target", "sysinit.target", "basic.target"}
+
+// systemctlUser runs systemctl --user, replaced by tests
+var systemctlUser = func(args ...string) error {
+	output, err := exec.Command("systemctl", append([]string{"--user"}, args...)...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("systemctl --user %s: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// userUnitDir returns where the systemd user units of the user are written
+func userUnitDir() string {
+	return filepath.Join(os.Getenv("HOME"), ".config", "systemd", "user")
+}
+
+// Validate returns an error if the service can't be written as a working user unit
+func (spec UserServiceSpec) Validate() error {
+	if !userServiceNamePattern.MatchString(spec.Name) {
+		return fmt.Errorf("service name %q may only contain letters, digits, '-', '_', '.' and ':'", spec.Name)
+	}
+	values := append([]string{spec.Description, spec.WorkingDirectory, spec.OnCalendar}, spec.ExecStart...)
+	for _, value := range append(values, spec.Environment...) {
+		if strings.ContainsAny(value, "\n\r") {
+			return fmt.Errorf("service %s: values can't contain line breaks", spec.Name)
+		}
+	}
+
+	if len(spec.ExecStart) == 0 || spec.ExecStart[0] == "" {
+		return fmt.Errorf("service %s has no command to run", spec.Name)
+	}
+	command := spec.ExecStart[0]
+	if !filepath.IsAbs(command) {
+		return fmt.Errorf("command %q of service %s is not an absolute path", command, spec.Name)
+	}
+	info, err := os.Stat(command)
+	if err != nil {
+		return fmt.Errorf("command %s of service %s does not exist", command, spec.Name)
+	}
+	if info.IsDir() || info.Mode().Perm()&0111 == 0 {
+		return fmt.Errorf("command %s of service %s is not executable", command, spec.Name)
+	}
+
+	if spec.WorkingDirectory != "" {
+		if !filepath.IsAbs(spec.WorkingDirectory) {
+			return fmt.Errorf("working directory %q is not absolute", spec.WorkingDirectory)
+		}
+		if !isDir(spec.WorkingDirectory) {
+			return fmt.Errorf("working directory %s does not exist", spec.WorkingDirectory)
+		}
+	}
+	for _, variable := range spec.Environment {
+		name, _, ok := strings.Cut(variable, "=")
+		if !ok || !environmentNamePattern.MatchString(name) {
+			return fmt.Errorf("environment variable %q is not a NAME=value pair", variable)
+		}
+	}
+	if spec.Restart != "" && !slices.Contains(userServiceRestartValues, spec.Restart) {
+		return fmt.Errorf("restart %q is not one of %s", spec.Restart, strings.Join(userServiceRestartValues, ", "))
+	}
+	if spec.WantedBy != "" {
+		if !userServiceTargetPattern.MatchString(spec.WantedBy) {
+			return fmt.Errorf("wanted_by %q is not a systemd target", spec.WantedBy)
+		}
+		if slices.Contains(systemTargets, spec.WantedBy) {
+			return fmt.Errorf("%s is a system target, user services start with default.target", spec.WantedBy)
+		}
+	}
+	if spec.OnCalendar != "" && strings.TrimSpace(spec.OnCalendar) == "" {
+		return fmt.Errorf("service %s has an empty schedule", spec.Name)
+	}
+	return nil
+}
+
+// unitName returns the file name of the service unit
+func (spec UserServiceSpec) unitName() string {
+	return spec.Name + ".service"
+}
+
+// timerName returns the file name of the service's timer unit
+func (spec UserServiceSpec) timerName() string {
+	return spec.Name + ".timer"
+}
+
+// Render returns the content of the service's unit file, and of its timer if it runs on a schedule, marked as created for an app
+func (spec UserServiceSpec) Render(app string) (service string, timer string, err error) {
+	if err := spec.Validate(); err != nil {
+		return "", "", err
+	}
+	description := spec.Description
+	if description == "" {
+		description = app
+	}
+
+	var content strings.Builder
+	content.WriteString("[Unit]\n")
+	content.WriteString("Description=" + escapeUnitSpecifiers(description) + "\n")
+	content.WriteString(userServiceAppKey + "=" + app + "\n")
+	content.WriteString("\n[Service]\n")
+	if spec.OnCalendar != "" {
+		content.WriteString("Type=oneshot\n")
+	} else {
+		content.WriteString("Type=simple\n")
+	}
+	content.WriteString("ExecStart=" + unitExecLine(spec.ExecStart) + "\n")
+	if spec.WorkingDirectory != "" {
+		content.WriteString("WorkingDirectory=" + escapeUnitSpecifiers(spec.WorkingDirectory) + "\n")
+	}
+	for _, variable := range spec.Environment {
+		content.WriteString("Environment=" + quoteUnitArg(variable, false) + "\n")
+	}
+	if spec.OnCalendar == "" {
+		restart := spec.Restart
+		if restart == "" {
+			restart = "on-failure"
+		}
+		content.WriteString("Restart=" + restart + "\n")
+
+		wantedBy := spec.WantedBy
+		if wantedBy == "" {
+			wantedBy = "default.target"
+		}
+		content.WriteString("\n[Install]\n")
+		content.WriteString("WantedBy=" + wantedBy + "\n")
+		return content.String(), "", nil
+	}
+
+	var timerContent strings.Builder
+	timerContent.WriteString("[Unit]\n")
+	timerContent.WriteString("Description=" + escapeUnitSpecifiers(description) + " (schedule)\n")
+	timerContent.WriteString(userServiceAppKey + "=" + app + "\n")
+	timerContent.WriteString("\n[Timer]\n")
+	timerContent.WriteString("OnCalendar=" + strings.TrimSpace(spec.OnCalendar) + "\n")
+	timerContent.WriteString("Persistent=true\n")
+	timerContent.WriteString("Unit=" + spec.unitName() + "\n")
+	timerContent.WriteString("\n[Install]\n")
+	timerContent.WriteString("WantedBy=timers.target\n")
+	return content.String(), timerContent.String(), nil
+}
+
+// unitExecLine quotes the arguments of a command for ExecStart, so systemd runs it with exactly these arguments
+func unitExecLine(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = quoteUnitArg(arg, true)
+	}
+	return strings.Join(quoted, " ")
+}
+
+// quoteUnitArg escapes the specifiers of a unit file value, and the $ variable expansion of command lines,
+// and puts it in double quotes if it contains whitespace, quotes or backslashes
+func quoteUnitArg(arg string, command bool) string {
+	arg = escapeUnitSpecifiers(arg)
+	if command {
+		arg = strings.ReplaceAll(arg, "$", "$$")
+	}
+	if arg != "" && !strings.ContainsAny(arg, " \t\"'\\;") {
+		return arg
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(arg) + `"`
+}
+
+// escapeUnitSpecifiers escapes the % specifiers systemd expands in unit file values
+func escapeUnitSpecifiers(value string) string {
+	return strings.ReplaceAll(value, "%", "%%")
+}
+
+// CreateUserService writes a systemd user service of an app to ~/.config/systemd/user, with a timer if it
+// runs on a schedule, reloads systemd, enables and starts it as requested and records it in the app's install
+// manifest so it is stopped and removed when the app is uninstalled. System services need administrator rights
+// and are not created here, scripts that need one write it with sudo themselves.
+//
+//	string - path of the written .service file
+//	error - error if the service is not valid, belongs to another app or systemd fails
+func CreateUserService(app string, spec UserServiceSpec) (string, error) {
+	if err := ValidateAppName(app); err != nil {
+		return "", err
+	}
+	if os.Geteuid() == 0 && os.Getenv("SUDO_USER") != "" {
+		return "", fmt.Errorf("refusing to create a user service as root, it would belong to root instead of %s", os.Getenv("SUDO_USER"))
+	}
+	spec.Name = strings.TrimSuffix(spec.Name, ".service")
+	service, timer, err := spec.Render(app)
+	if err != nil {
+		return "", err
+	}
+
+	dir := userUnitDir()
+	path := filepath.Join(dir, spec.unitName())
+	files := []string{path}
+	contents := []string{service}
+	if timer != "" {
+		files = append(files, filepath.Join(dir, spec.timerName()))
+		contents = append(contents, timer)
+	}
+	for _, file := range files {
+		if owner := unitFileOwner(file); owner != app && (owner != "" || FileExists(file)) {
+			if owner == "" {
+				return "", fmt.Errorf("%s already exists and was not created by Pi-Apps", file)
+			}
+			return "", fmt.Errorf("%s belongs to the app %s", file, owner)
+		}
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	for i, file := range files {
+		if err := os.WriteFile(file, []byte(contents[i]), 0644); err != nil {
+			return "", err
+		}
+		if err := recordInstalledFile(app, installUserServicePrefix+file); err != nil {
+			return path, fmt.Errorf("failed to record %s in the install manifest: %w", file, err)
+		}
+	}
+
+	if err := systemctlUser("daemon-reload"); err != nil {
+		return path, err
+	}
+	unit := spec.unitName()
+	if timer != "" {
+		unit = spec.timerName()
+	}
+	if spec.Enable {
+		if err := systemctlUser("enable", unit); err != nil {
+			return path, err
+		}
+	}
+	if spec.Start {
+		if err := systemctlUser("restart", unit); err != nil {
+			return path, err
+		}
+	}
+	return path, nil
+}
+
+// RemoveUserServices stops, disables and removes the user services CreateUserService created for an app.
+// Timers are stopped before their services, and all units are stopped before any file is removed.
+//
+//	[]string - paths of the removed unit files
+//	error - error if the app name is not valid or a unit file can't be removed
+func RemoveUserServices(app string) ([]string, error) {
+	lines, err := readUserServiceLines(app)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, line := range lines {
+		files = append(files, strings.TrimPrefix(line, installUserServicePrefix))
+	}
+	if len(files) == 0 {
+		return nil, nil
+	}
+	// Timers first, or they start the service again right after it is stopped
+	slices.SortStableFunc(files, func(a, b string) int {
+		return strings.Compare(unitKindOrder(a), unitKindOrder(b))
+	})
+
+	var units []string
+	for _, file := range files {
+		if unitFileOwner(file) == app {
+			units = append(units, filepath.Base(file))
+		}
+	}
+	for _, unit := range units {
+		if err := systemctlUser("stop", unit); err != nil {
+			Debug(fmt.Sprintf("Failed to stop %s: %v", unit, err))
+		}
+	}
+	for _, unit := range units {
+		if err := systemctlUser("disable", unit); err != nil {
+			Debug(fmt.Sprintf("Failed to disable %s: %v", unit, err))
+		}
+	}
+
+	var removed []string
+	for _, file := range files {
+		if FileExists(file) && unitFileOwner(file) != app {
+			// Replaced by another app or by the user since, not ours to remove anymore
+			Debug(fmt.Sprintf("Not removing %s, it no longer belongs to %s", file, app))
+		} else if err := os.Remove(file); err != nil && FileExists(file) {
+			return removed, fmt.Errorf("failed to remove %s: %w", file, err)
+		} else if err == nil {
+			removed = append(removed, file)
+		}
+		if err := forgetInstalledFile(app, installUserServicePrefix+file); err != nil {
+			Debug(fmt.Sprintf("Failed to remove %s from the install manifest of %s: %v", file, app, err))
+		}
+	}
+	if len(removed) > 0 {
+		if err := systemctlUser("daemon-reload"); err != nil {
+			Debug(fmt.Sprintf("Failed to reload systemd: %v", err))
+		}
+	}
+	return removed, nil
+}
+
+// unitKindOrder sorts timer units before the services they start
+func unitKindOrder(file string) string {
+	if strings.HasSuffix(file, ".timer") {
+		return "0"
+	}
+	return "1"
+}
+
+// readUserServiceLines returns the user service lines of an app's install manifest
+func readUserServiceLines(app string) ([]string, error) {
+	lines, err := readInstallManifest(app)
+	if err != nil {
+		return nil, err
+	}
+	var services []string
+	for _, line := range lines {
+		if strings.HasPrefix(line, installUserServicePrefix) {
+			services = append(services, line)
+		}
+	}
+	return services, nil
+}
+
+// unitFileOwner returns the app a unit file was created for by CreateUserService, "" if there is none
+func unitFileOwner(path string) string {
+	file, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if app, ok := strings.CutPrefix(scanner.Text(), userServiceAppKey+"="); ok {
+			return strings.TrimSpace(app)
+		}
+	}
+	return ""
+}
+
+// ParseUserServicePairs reads a user service from key=value pairs, like the stdin lines of `api create_user_service`.
+// Keys are name, description, exec, working_directory, environment (repeatable), restart, wanted_by, on_calendar,
+// enable, start and app. exec is split into arguments like a shell does.
+//
+//	UserServiceSpec - the service
+//	string - the app the service is for, "" if no app key was given
+//	error - error if a pair is not valid, or asks for a system service
+func ParseUserServicePairs(pairs []string) (UserServiceSpec, string, error) {
+	var spec UserServiceSpec
+	var app string
+	for _, pair := range pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return spec, "", fmt.Errorf("%q is not a key=value pair", pair)
+		}
+		var err error
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "app":
+			app = value
+		case "name":
+			spec.Name = value
+		case "description":
+			spec.Description = value
+		case "exec", "exec_start", "execstart":
+			spec.ExecStart, err = splitCommandLine(value)
+		case "working_directory", "workingdirectory":
+			spec.WorkingDirectory = value
+		case "environment", "env":
+			spec.Environment = append(spec.Environment, value)
+		case "restart":
+			spec.Restart = value
+		case "wanted_by", "wantedby":
+			spec.WantedBy = value
+		case "on_calendar", "oncalendar", "timer":
+			spec.OnCalendar = value
+		case "enable":
+			spec.Enable, err = strconv.ParseBool(value)
+		case "start":
+			spec.Start, err = strconv.ParseBool(value)
+		case "system", "user":
+			return spec, "", fmt.Errorf("only user services can be created, system services need sudo and are written by the script itself")
+		default:
+			return spec, "", fmt.Errorf("unknown key %q", key)
+		}
+		if err != nil {
+			return spec, "", fmt.Errorf("invalid %s: %w", key, err)
+		}
+	}
+	return spec, app, nil
+}
@@ -0,0 +1,238 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// useFakeSystemctl replaces systemctl --user with a recorder of its calls
+func useFakeSystemctl(t *testing.T) *[]string {
+	t.Helper()
+	var calls []string
+	original := systemctlUser
+	systemctlUser = func(args ...string) error {
+		calls = append(calls, strings.Join(args, " "))
+		return nil
+	}
+	t.Cleanup(func() { systemctlUser = original })
+	t.Setenv("SUDO_USER", "")
+	return &calls
+}
+
+// newTestExecutable writes an executable script and returns its path
+func newTestExecutable(t *testing.T, name string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	writeTestFile(t, path, "#!/bin/sh\n")
+	if err := os.Chmod(path, 0755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestUserServiceRender(t *testing.T) {
+	command := newTestExecutable(t, "sync tool")
+	spec := UserServiceSpec{
+		Name:        "foo-sync",
+		ExecStart:   []string{command, "--rate=100%", "$HOME", `say "hi"`},
+		Environment: []string{"GDK_SCALE=2", "GREETING=hello world"},
+	}
+	service, timer, err := spec.Render("Foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if timer != "" {
+		t.Errorf("a service without a schedule got a timer:\n%s", timer)
+	}
+	for _, want := range []string{
+		"Description=Foo\n",
+		"X-Pi-Apps-App=Foo\n",
+		"Type=simple\n",
+		`ExecStart="` + command + `" --rate=100%% $$HOME "say \"hi\""` + "\n",
+		"Environment=GDK_SCALE=2\n",
+		`Environment="GREETING=hello world"` + "\n",
+		"Restart=on-failure\n",
+		"[Install]\nWantedBy=default.target\n",
+	} {
+		if !strings.Contains(service, want) {
+			t.Errorf("service unit doesn't contain %q:\n%s", want, service)
+		}
+	}
+
+	spec.OnCalendar = "daily"
+	service, timer, err = spec.Render("Foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(service, "Type=oneshot\n") || strings.Contains(service, "[Install]") {
+		t.Errorf("scheduled service unit should be a oneshot started by its timer:\n%s", service)
+	}
+	for _, want := range []string{"X-Pi-Apps-App=Foo\n", "OnCalendar=daily\n", "Unit=foo-sync.service\n", "WantedBy=timers.target\n"} {
+		if !strings.Contains(timer, want) {
+			t.Errorf("timer unit doesn't contain %q:\n%s", want, timer)
+		}
+	}
+}
+
+func TestUserServiceValidate(t *testing.T) {
+	command := newTestExecutable(t, "tool")
+	notExecutable := filepath.Join(t.TempDir(), "data")
+	writeTestFile(t, notExecutable, "data")
+
+	tests := map[string]UserServiceSpec{
+		"no name":                {ExecStart: []string{command}},
+		"template name":          {Name: "foo@", ExecStart: []string{command}},
+		"path in name":           {Name: "../foo", ExecStart: []string{command}},
+		"no command":             {Name: "foo"},
+		"relative command":       {Name: "foo", ExecStart: []string{"tool"}},
+		"missing command":        {Name: "foo", ExecStart: []string{"/nonexistent/tool"}},
+		"not executable":         {Name: "foo", ExecStart: []string{notExecutable}},
+		"line break":             {Name: "foo", ExecStart: []string{command, "a\nExecStartPre=/bin/evil"}},
+		"bad environment":        {Name: "foo", ExecStart: []string{command}, Environment: []string{"1A=b"}},
+		"bad restart":            {Name: "foo", ExecStart: []string{command}, Restart: "sometimes"},
+		"system target":          {Name: "foo", ExecStart: []string{command}, WantedBy: "multi-user.target"},
+		"missing directory":      {Name: "foo", ExecStart: []string{command}, WorkingDirectory: "/nonexistent"},
+		"relative directory":     {Name: "foo", ExecStart: []string{command}, WorkingDirectory: "foo"},
+		"description line break": {Name: "foo", ExecStart: []string{command}, Description: "a\r\nb"},
+	}
+	for name, spec := range tests {
+		if err := spec.Validate(); err == nil {
+			t.Errorf("%s: Validate() = nil, want an error", name)
+		}
+	}
+	if err := (UserServiceSpec{Name: "foo", ExecStart: []string{command}, WantedBy: "graphical-session.target"}).Validate(); err != nil {
+		t.Errorf("valid service: %v", err)
+	}
+}
+
+func TestParseUserServicePairs(t *testing.T) {
+	spec, app, err := ParseUserServicePairs([]string{
+		"app=Foo",
+		"name=foo-sync.service",
+		`exec="/opt/Foo App/foo" --sync`,
+		"environment=A=1",
+		"env=B=2",
+		"on_calendar=*-*-* 03:00",
+		"enable=true",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if app != "Foo" || spec.Name != "foo-sync.service" || !spec.Enable || spec.Start {
+		t.Errorf("ParseUserServicePairs = %+v for %q", spec, app)
+	}
+	if !slices.Equal(spec.ExecStart, []string{"/opt/Foo App/foo", "--sync"}) {
+		t.Errorf("exec = %q", spec.ExecStart)
+	}
+	if !slices.Equal(spec.Environment, []string{"A=1", "B=2"}) || spec.OnCalendar != "*-*-* 03:00" {
+		t.Errorf("environment = %q, schedule = %q", spec.Environment, spec.OnCalendar)
+	}
+
+	for _, pairs := range [][]string{{"system=true"}, {"name"}, {"unknown=1"}, {"start=maybe"}} {
+		if _, _, err := ParseUserServicePairs(pairs); err == nil {
+			t.Errorf("ParseUserServicePairs(%q) = nil error", pairs)
+		}
+	}
+}
+
+func TestCreateAndRemoveUserServices(t *testing.T) {
+	newTestPiAppsDir(t, "Foo", "Other")
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	calls := useFakeSystemctl(t)
+	command := newTestExecutable(t, "foo")
+	unitDir := filepath.Join(home, ".config", "systemd", "user")
+
+	path, err := CreateUserService("Foo", UserServiceSpec{Name: "foo-daemon.service", ExecStart: []string{command}, Enable: true, Start: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(unitDir, "foo-daemon.service"); path != want {
+		t.Errorf("CreateUserService wrote %s, want %s", path, want)
+	}
+	if _, err := CreateUserService("Foo", UserServiceSpec{Name: "foo-sync", ExecStart: []string{command}, OnCalendar: "daily", Enable: true}); err != nil {
+		t.Fatal(err)
+	}
+	wantCalls := []string{"daemon-reload", "enable foo-daemon.service", "restart foo-daemon.service", "daemon-reload", "enable foo-sync.timer"}
+	if !slices.Equal(*calls, wantCalls) {
+		t.Errorf("systemctl calls = %q, want %q", *calls, wantCalls)
+	}
+
+	lines, _ := readInstallManifest("Foo")
+	for _, unit := range []string{"foo-daemon.service", "foo-sync.service", "foo-sync.timer"} {
+		if !slices.Contains(lines, installUserServicePrefix+filepath.Join(unitDir, unit)) {
+			t.Errorf("the install manifest of Foo = %q, want it to record %s", lines, unit)
+		}
+	}
+	if files, _ := ReadInstalledFiles("Foo"); len(files) != 0 {
+		t.Errorf("user services are listed as installed files: %q", files)
+	}
+
+	// The manifest written at the end of the install keeps the services created during it
+	if err := writeInstalledFiles("Foo", []string{"/opt/foo"}, ResourceLimits{}, false); err != nil {
+		t.Fatal(err)
+	}
+	if services, _ := readUserServiceLines("Foo"); len(services) != 3 {
+		t.Errorf("writeInstalledFiles kept %q, want the 3 unit files", services)
+	}
+
+	// Another app can't take over the service, and files not created by Pi-Apps are left alone
+	if _, err := CreateUserService("Other", UserServiceSpec{Name: "foo-daemon", ExecStart: []string{command}}); err == nil {
+		t.Error("CreateUserService overwrote the service of another app")
+	}
+	writeTestFile(t, filepath.Join(unitDir, "manual.service"), "[Service]\nExecStart=/bin/true\n")
+	if _, err := CreateUserService("Other", UserServiceSpec{Name: "manual", ExecStart: []string{command}}); err == nil {
+		t.Error("CreateUserService overwrote a unit the user wrote")
+	}
+
+	*calls = nil
+	removed, err := RemoveUserServices("Foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(removed) != 3 {
+		t.Errorf("RemoveUserServices removed %q, want the 3 unit files", removed)
+	}
+	// Timers stop before services, everything stops before files go, systemd reloads last
+	wantCalls = []string{
+		"stop foo-sync.timer", "stop foo-daemon.service", "stop foo-sync.service",
+		"disable foo-sync.timer", "disable foo-daemon.service", "disable foo-sync.service",
+		"daemon-reload",
+	}
+	if !slices.Equal(*calls, wantCalls) {
+		t.Errorf("systemctl calls = %q, want %q", *calls, wantCalls)
+	}
+	for _, file := range removed {
+		if FileExists(file) {
+			t.Errorf("%s was not removed", file)
+		}
+	}
+	if services, _ := readUserServiceLines("Foo"); len(services) != 0 {
+		t.Errorf("the install manifest still records %q", services)
+	}
+	if files, _ := ReadInstalledFiles("Foo"); !slices.Equal(files, []string{"/opt/foo"}) {
+		t.Errorf("the installed files of Foo = %q, want them kept", files)
+	}
+	if !FileExists(filepath.Join(unitDir, "manual.service")) {
+		t.Error("RemoveUserServices removed a unit of the user")
+	}
+}
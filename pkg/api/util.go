@@ -15,175 +15,20 @@
 // along with this program.  If not, see <https://www.gnu.org/licenses/>.
 
 // Module: util.go
-// Description: Provides functions for miscellaneous operations (like runonce entries or preferred text editor options)
+// Description: Provides functions for miscellaneous operations (like the preferred text editor options)
 // SPDX-License-Identifier: GPL-3.0-or-later
 
 package api
 
 import (
-	"bufio"
-	"crypto/sha1"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
-)
-
-// Runonce runs a command only if it has never been run before.
-// It takes a script as a string and executes it only if its hash
-// doesn't exist in the runonce hashes file.
-// This is useful for one-time migrations or setting changes.
-//
-// Deprecated: In our goals to remove bash scripts for anything other then apps,
-// this function will be removed soon. Use api.RunonceFunc instead for Go native runonce functions.
-func Runonce(script string) error {
-	// Get the PI_APPS_DIR environment variable
-	directory := GetPiAppsDir()
-	if directory == "" {
-		return fmt.Errorf("PI_APPS_DIR environment variable not set")
-	}
-
-	// Calculate SHA1 hash of the script
-	hasher := sha1.New()
-	hasher.Write([]byte(script))
-	hash := fmt.Sprintf("%x", hasher.Sum(nil))
-
-	// Check if hash exists in the runonce_hashes file
-	hashesFile := filepath.Join(directory, "data", "runonce_hashes")
-
-	// Create the file if it doesn't exist
-	if !FileExists(hashesFile) {
-		// Ensure directory exists
-		if err := os.MkdirAll(filepath.Dir(hashesFile), 0755); err != nil {
-			return fmt.Errorf("failed to create directory for runonce_hashes: %w", err)
-		}
-
-		// Create empty file
-		if _, err := os.Create(hashesFile); err != nil {
-			return fmt.Errorf("failed to create runonce_hashes file: %w", err)
-		}
-	}
-
-	// Check if the hash already exists in the file
-	hashExists, err := hashExistsInFile(hashesFile, hash)
-	if err != nil {
-		return fmt.Errorf("failed to check hash existence: %w", err)
-	}
-
-	if hashExists {
-		// Hash found, command already run before - do nothing
-		return nil
-	}
-
-	// Hash not found, run the script
-	cmd := exec.Command("bash", "-c", script)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-
-	err = cmd.Run()
-	if err != nil {
-		return fmt.Errorf("runonce(): script failed: %w", err)
-	}
-
-	// If script succeeds, add the hash to the list
-	hashFile, err := os.OpenFile(hashesFile, os.O_APPEND|os.O_WRONLY, 0644)
-	if err != nil {
-		return fmt.Errorf("failed to open runonce_hashes file: %w", err)
-	}
-	defer hashFile.Close()
-
-	if _, err := hashFile.WriteString(hash + "\n"); err != nil {
-		return fmt.Errorf("failed to write hash to file: %w", err)
-	}
-
-	return nil
-}
-
-// hashExistsInFile checks if a hash exists in the specified file
-func hashExistsInFile(filePath, hash string) (bool, error) {
-	file, err := os.Open(filePath)
-	if err != nil {
-		return false, err
-	}
-	defer file.Close()
-
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if line == hash {
-			return true, nil
-		}
-	}
 
-	return false, scanner.Err()
-}
-
-// RunonceFunc runs a function only if it has never been run before with the given version.
-// It takes a function and a version identifier (e.g., "addUserDirs-v1").
-// If the version identifier doesn't exist in the runonce hashes file, the function is executed.
-// This is useful for one-time migrations or setting changes using Go functions instead of bash scripts.
-func RunonceFunc(version string, fn func() error) error {
-	if fn == nil {
-		return fmt.Errorf("runonceFunc(): function is nil")
-	}
-
-	// Get the PI_APPS_DIR environment variable
-	directory := GetPiAppsDir()
-	if directory == "" {
-		return fmt.Errorf("PI_APPS_DIR environment variable not set")
-	}
-
-	// Calculate SHA1 hash of the version identifier
-	hasher := sha1.New()
-	hasher.Write([]byte(version))
-	hash := fmt.Sprintf("%x", hasher.Sum(nil))
-
-	// Check if hash exists in the runonce_hashes file
-	hashesFile := filepath.Join(directory, "data", "runonce_hashes")
-
-	// Create the file if it doesn't exist
-	if !FileExists(hashesFile) {
-		// Ensure directory exists
-		if err := os.MkdirAll(filepath.Dir(hashesFile), 0755); err != nil {
-			return fmt.Errorf("failed to create directory for runonce_hashes: %w", err)
-		}
-
-		// Create empty file
-		if _, err := os.Create(hashesFile); err != nil {
-			return fmt.Errorf("failed to create runonce_hashes file: %w", err)
-		}
-	}
-
-	// Check if the hash already exists in the file
-	hashExists, err := hashExistsInFile(hashesFile, hash)
-	if err != nil {
-		return fmt.Errorf("failed to check hash existence: %w", err)
-	}
-
-	if hashExists {
-		// Hash found, function already run before - do nothing
-		return nil
-	}
-
-	// Hash not found, run the function
-	if err := fn(); err != nil {
-		return fmt.Errorf("runonceFunc(): function failed: %w", err)
-	}
-
-	// If function succeeds, add the hash to the list
-	hashFile, err := os.OpenFile(hashesFile, os.O_APPEND|os.O_WRONLY, 0644)
-	if err != nil {
-		return fmt.Errorf("failed to open runonce_hashes file: %w", err)
-	}
-	defer hashFile.Close()
-
-	if _, err := hashFile.WriteString(hash + "\n"); err != nil {
-		return fmt.Errorf("failed to write hash to file: %w", err)
-	}
-
-	return nil
-}
+	"github.com/pi-apps-go/pi-apps/pkg/api/errs"
+)
 
 // TextEditor opens the user's preferred text editor for the specified file
 func TextEditor(filePath string) error {
@@ -194,7 +39,7 @@ func TextEditor(filePath string) error {
 	}
 
 	// Read preferred editor setting
-	settingsFile := filepath.Join(directory, "data", "settings", "Preferred text editor")
+	settingsFile := filepath.Join(DataDirOf(directory), "settings", "Preferred text editor")
 
 	var preferredEditor string
 	if FileExists(settingsFile) {
@@ -234,6 +79,18 @@ func TextEditor(filePath string) error {
 		return fmt.Errorf("text_editor(): no suitable text editor found")
 	}
 
+	// Editors mangle binary files when they are saved, so ask first
+	if binary, err := IsBinaryFile(filePath); err == nil && binary {
+		answer, err := UserInputFunc(Tf("%s looks like a binary file, not text. A text editor shows it as garbage and can break it when saving.\n\nOpen it anyway?", filepath.Base(filePath)),
+			T("Cancel"), T("Open anyway"))
+		if err != nil {
+			return fmt.Errorf("text_editor(): failed to get user input: %w", err)
+		}
+		if answer != T("Open anyway") {
+			return errs.New(errs.ErrCancelled, "text_editor(): %s is a binary file", filePath)
+		}
+	}
+
 	// For terminal-based editors like nano, use terminal-run script
 	if preferredEditor == "nano" {
 		terminalRunPath := filepath.Join(directory, "etc", "terminal-run")
@@ -0,0 +1,195 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: verified_copy.go
+// Description: Copies the files of app refreshes and file updates with read-back verification, so a bad write on an SD card can't leave a truncated script behind.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package api
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"golang.org/x/sys/unix"
+)
+
+// FileProgress is the progress of copying the files of an app refresh or file update
+type FileProgress struct {
+	Done  int    // files copied so far, including File
+	Total int    // files to copy
+	File  string // path of the file that was just copied
+}
+
+var (
+	fileProgressHandler      func(FileProgress)
+	fileProgressHandlerMutex sync.Mutex
+)
+
+// writeTempFile writes the copies of copyFileVerified, a variable so tests can inject failing writes
+var writeTempFile = writeSyncedTemp
+
+// SetFileProgressHandler sets the function RefreshApp and UpdateFile report every copied file to, nil stops reporting
+func SetFileProgressHandler(handler func(FileProgress)) {
+	fileProgressHandlerMutex.Lock()
+	defer fileProgressHandlerMutex.Unlock()
+	fileProgressHandler = handler
+}
+
+// reportFileProgress passes the progress to the handler set with SetFileProgressHandler, if any
+func reportFileProgress(progress FileProgress) {
+	fileProgressHandlerMutex.Lock()
+	handler := fileProgressHandler
+	fileProgressHandlerMutex.Unlock()
+	if handler != nil {
+		handler(progress)
+	}
+}
+
+// copyTreeVerified copies the files of src into dst with copyFileVerified, reporting the progress of every file.
+// Files in dst that aren't in src are left alone.
+func copyTreeVerified(src, dst string) error {
+	var files []string
+	err := filepath.WalkDir(src, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			info, err := entry.Info()
+			if err != nil {
+				return err
+			}
+			if err := os.MkdirAll(filepath.Join(dst, rel), info.Mode().Perm()); err != nil {
+				return fmt.Errorf("failed to create directory %s: %w", filepath.Join(dst, rel), err)
+			}
+			return nil
+		}
+		files = append(files, rel)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for i, rel := range files {
+		if err := copyFileVerified(filepath.Join(src, rel), filepath.Join(dst, rel)); err != nil {
+			return err
+		}
+		reportFileProgress(FileProgress{Done: i + 1, Total: len(files), File: filepath.Join(dst, rel)})
+	}
+	return nil
+}
+
+// copyFileVerified copies src to dst so that dst is either left as it was or replaced by a complete copy of src.
+//
+// The copy is written to a temporary file next to dst, synced and read back from the disk to compare its checksum
+// with the source. A mismatch is retried once before giving up. Only a verified copy is renamed over dst, and the
+// directory is synced afterwards so the rename survives a power loss.
+func copyFileVerified(src, dst string) error {
+	content, err := os.ReadFile(src)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", src, err)
+	}
+	info, err := os.Stat(src)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", src, err)
+	}
+	sum := sha256.Sum256(content)
+
+	var tmp string
+	for attempt := 1; ; attempt++ {
+		tmp, err = writeTempFile(dst, content, info.Mode().Perm())
+		if err != nil {
+			return fmt.Errorf("failed to write %s: %w", dst, err)
+		}
+		written, err := readUncached(tmp)
+		if err == nil && sha256.Sum256(written) == sum {
+			break
+		}
+		os.Remove(tmp)
+		if attempt == 2 {
+			if err != nil {
+				return fmt.Errorf("failed to verify %s: %w", dst, err)
+			}
+			return fmt.Errorf("failed to verify %s: the written file does not match %s, the storage may be failing", dst, src)
+		}
+		WarningTf("The copy of %s did not match the original, retrying...", dst)
+	}
+
+	if err := os.Rename(tmp, dst); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to replace %s: %w", dst, err)
+	}
+	if err := syncDir(filepath.Dir(dst)); err != nil {
+		return fmt.Errorf("failed to sync %s: %w", filepath.Dir(dst), err)
+	}
+	return nil
+}
+
+// writeSyncedTemp writes content to a new temporary file next to path and syncs it to the disk
+func writeSyncedTemp(path string, content []byte, perm os.FileMode) (string, error) {
+	file, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return "", err
+	}
+	tmp := file.Name()
+	_, err = file.Write(content)
+	if err == nil {
+		err = file.Chmod(perm)
+	}
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return "", err
+	}
+	return tmp, nil
+}
+
+// readUncached reads a synced file after dropping it from the page cache, so the content comes from the disk
+// and not from the memory the write went through
+func readUncached(path string) ([]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	// Not every filesystem supports dropping the cache, the read is still a useful check then
+	_ = unix.Fadvise(int(file.Fd()), 0, 0, unix.FADV_DONTNEED)
+	file.Close()
+	return os.ReadFile(path)
+}
+
+// syncDir syncs a directory so renames and new files in it are on the disk
+func syncDir(dir string) error {
+	file, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return file.Sync()
+}
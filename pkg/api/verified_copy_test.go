@@ -0,0 +1,156 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// corruptWrites makes the next n writes of copyFileVerified write a truncated file, like a failing SD card
+func corruptWrites(t *testing.T, n int) *int {
+	t.Helper()
+	writes := 0
+	t.Cleanup(func() { writeTempFile = writeSyncedTemp })
+	writeTempFile = func(path string, content []byte, perm os.FileMode) (string, error) {
+		writes++
+		if writes <= n && len(content) > 0 {
+			content = content[:len(content)/2]
+		}
+		return writeSyncedTemp(path, content, perm)
+	}
+	return &writes
+}
+
+// leftoverTemps returns the temporary files copyFileVerified left in dir
+func leftoverTemps(t *testing.T, dir string) []string {
+	t.Helper()
+	matches, err := filepath.Glob(filepath.Join(dir, ".*.tmp-*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return matches
+}
+
+func TestCopyFileVerified(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src")
+	dst := filepath.Join(dir, "dst")
+	writeTestFile(t, src, "#!/bin/bash\necho installed\n")
+	if err := os.Chmod(src, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := copyFileVerified(src, dst); err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(dst)
+	if err != nil || string(content) != "#!/bin/bash\necho installed\n" {
+		t.Fatalf("dst = %q, %v", content, err)
+	}
+	if info, _ := os.Stat(dst); info.Mode().Perm() != 0755 {
+		t.Errorf("dst mode = %v, want the mode of src", info.Mode().Perm())
+	}
+	if temps := leftoverTemps(t, dir); len(temps) != 0 {
+		t.Errorf("temporary files were left behind: %v", temps)
+	}
+}
+
+func TestCopyFileVerifiedRetriesACorruptWrite(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src")
+	dst := filepath.Join(dir, "dst")
+	writeTestFile(t, src, "new script\n")
+	writes := corruptWrites(t, 1)
+
+	if err := copyFileVerified(src, dst); err != nil {
+		t.Fatalf("a single corrupt write was not retried: %v", err)
+	}
+	if *writes != 2 {
+		t.Errorf("%d writes, want 2", *writes)
+	}
+	if content, _ := os.ReadFile(dst); string(content) != "new script\n" {
+		t.Errorf("dst = %q after the retry", content)
+	}
+	if temps := leftoverTemps(t, dir); len(temps) != 0 {
+		t.Errorf("temporary files were left behind: %v", temps)
+	}
+}
+
+func TestCopyFileVerifiedKeepsTheOriginal(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src")
+	dst := filepath.Join(dir, "install")
+	writeTestFile(t, src, "new script\n")
+	writeTestFile(t, dst, "old script\n")
+	writes := corruptWrites(t, 2)
+
+	err := copyFileVerified(src, dst)
+	if err == nil {
+		t.Fatal("copyFileVerified succeeded although every write was corrupt")
+	}
+	if !strings.Contains(err.Error(), dst) {
+		t.Errorf("the error %q does not name %s", err, dst)
+	}
+	if *writes != 2 {
+		t.Errorf("%d writes, want 2", *writes)
+	}
+	if content, _ := os.ReadFile(dst); string(content) != "old script\n" {
+		t.Errorf("the original was replaced by %q", content)
+	}
+	if temps := leftoverTemps(t, dir); len(temps) != 0 {
+		t.Errorf("temporary files were left behind: %v", temps)
+	}
+}
+
+func TestCopyTreeVerifiedProgress(t *testing.T) {
+	src := t.TempDir()
+	dst := t.TempDir()
+	for _, name := range []string{"install", "description", "resources/icon-64.png"} {
+		writeTestFile(t, filepath.Join(src, name), name+"\n")
+	}
+	writeTestFile(t, filepath.Join(dst, "extra"), "kept\n")
+
+	var progress []FileProgress
+	SetFileProgressHandler(func(p FileProgress) { progress = append(progress, p) })
+	defer SetFileProgressHandler(nil)
+
+	if err := copyTreeVerified(src, dst); err != nil {
+		t.Fatal(err)
+	}
+	if len(progress) != 3 {
+		t.Fatalf("%d progress reports, want 3: %+v", len(progress), progress)
+	}
+	var files []string
+	for i, p := range progress {
+		if p.Done != i+1 || p.Total != 3 {
+			t.Errorf("progress %d = %d/%d, want %d/3", i, p.Done, p.Total, i+1)
+		}
+		files = append(files, p.File)
+	}
+	if !slices.Contains(files, filepath.Join(dst, "resources", "icon-64.png")) {
+		t.Errorf("progress files = %v, want the copied paths", files)
+	}
+	for _, name := range []string{"install", "resources/icon-64.png", "extra"} {
+		if _, err := os.Stat(filepath.Join(dst, name)); err != nil {
+			t.Errorf("%s is missing: %v", name, err)
+		}
+	}
+}
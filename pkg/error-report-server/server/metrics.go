@@ -0,0 +1,135 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: metrics.go
+// Description: Provides the healthcheck and Prometheus metrics endpoints of the error report server.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Reasons a report can be rejected for, used as the reason label of the rejected reports counter
+const (
+	rejectBadHeader = "bad_header"
+	rejectTooLarge  = "too_large"
+	rejectInvalid   = "invalid"
+)
+
+// metrics holds the counters exposed on the /metrics endpoint
+type metrics struct {
+	reportsReceived     atomic.Uint64
+	suggestionsReceived atomic.Uint64
+	tokenCleanupRuns    atomic.Uint64
+	rejectedMutex       sync.Mutex
+	reportsRejected     map[string]uint64
+	fingerprintsMutex   sync.Mutex
+	uniqueFingerprints  map[string]struct{}
+	storageBytes        atomic.Int64
+}
+
+// newMetrics creates an empty set of metrics
+func newMetrics() *metrics {
+	return &metrics{
+		reportsRejected: map[string]uint64{
+			rejectBadHeader: 0,
+			rejectTooLarge:  0,
+			rejectInvalid:   0,
+		},
+		uniqueFingerprints: make(map[string]struct{}),
+	}
+}
+
+// reject counts a rejected report
+func (m *metrics) reject(reason string) {
+	m.rejectedMutex.Lock()
+	m.reportsRejected[reason]++
+	m.rejectedMutex.Unlock()
+}
+
+// addFingerprint records a report fingerprint so unique reports can be counted
+func (m *metrics) addFingerprint(fingerprint string) {
+	m.fingerprintsMutex.Lock()
+	m.uniqueFingerprints[fingerprint] = struct{}{}
+	m.fingerprintsMutex.Unlock()
+}
+
+// HealthResponse represents the response of the /healthz endpoint
+type HealthResponse struct {
+	Status          string `json:"status"`
+	Uptime          string `json:"uptime"`
+	UptimeSeconds   int64  `json:"uptime_seconds"`
+	StorageEnabled  bool   `json:"storage_enabled"`
+	StorageWritable bool   `json:"storage_writable"`
+}
+
+// handleHealthz reports whether the server is up and its storage is usable
+func (s *Server) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	uptime := time.Since(s.startTime).Truncate(time.Second)
+
+	response := HealthResponse{
+		Status:          "ok",
+		Uptime:          uptime.String(),
+		UptimeSeconds:   int64(uptime.Seconds()),
+		StorageEnabled:  s.storageEnabled(),
+		StorageWritable: s.storageWritable(),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// handleMetrics exposes the server counters in the Prometheus text exposition format
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+
+	fmt.Fprintln(w, "# HELP pi_apps_error_reports_received_total Error reports accepted by the server.")
+	fmt.Fprintln(w, "# TYPE pi_apps_error_reports_received_total counter")
+	fmt.Fprintf(w, "pi_apps_error_reports_received_total %d\n", s.metrics.reportsReceived.Load())
+
+	fmt.Fprintln(w, "# HELP pi_apps_error_reports_rejected_total Error reports rejected by the server.")
+	fmt.Fprintln(w, "# TYPE pi_apps_error_reports_rejected_total counter")
+	s.metrics.rejectedMutex.Lock()
+	for _, reason := range []string{rejectBadHeader, rejectTooLarge, rejectInvalid} {
+		fmt.Fprintf(w, "pi_apps_error_reports_rejected_total{reason=%q} %d\n", reason, s.metrics.reportsRejected[reason])
+	}
+	s.metrics.rejectedMutex.Unlock()
+
+	fmt.Fprintln(w, "# HELP pi_apps_app_suggestions_received_total App suggestions accepted by the server.")
+	fmt.Fprintln(w, "# TYPE pi_apps_app_suggestions_received_total counter")
+	fmt.Fprintf(w, "pi_apps_app_suggestions_received_total %d\n", s.metrics.suggestionsReceived.Load())
+
+	fmt.Fprintln(w, "# HELP pi_apps_error_reports_unique_fingerprints Distinct error report fingerprints seen.")
+	fmt.Fprintln(w, "# TYPE pi_apps_error_reports_unique_fingerprints gauge")
+	s.metrics.fingerprintsMutex.Lock()
+	fmt.Fprintf(w, "pi_apps_error_reports_unique_fingerprints %d\n", len(s.metrics.uniqueFingerprints))
+	s.metrics.fingerprintsMutex.Unlock()
+
+	fmt.Fprintln(w, "# HELP pi_apps_error_reports_storage_bytes Bytes used by stored error reports.")
+	fmt.Fprintln(w, "# TYPE pi_apps_error_reports_storage_bytes gauge")
+	fmt.Fprintf(w, "pi_apps_error_reports_storage_bytes %d\n", s.metrics.storageBytes.Load())
+
+	fmt.Fprintln(w, "# HELP pi_apps_error_reports_token_cleanup_runs_total Runs of the expired token cleanup.")
+	fmt.Fprintln(w, "# TYPE pi_apps_error_reports_token_cleanup_runs_total counter")
+	fmt.Fprintf(w, "pi_apps_error_reports_token_cleanup_runs_total %d\n", s.metrics.tokenCleanupRuns.Load())
+}
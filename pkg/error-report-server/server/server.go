@@ -22,16 +22,27 @@
 // To use this module, you will need to provide your own webhook URL as a .env file in the root of the project.
 // The .env file should contain the following:
 // DISCORD_WEBHOOK_URL=your_webhook_url_here
+// Received reports are not stored unless REPORT_STORAGE_DIR=path is set. Stored files are only readable by the server
+// and are removed after REPORT_STORAGE_RETENTION (default: 720h), or earlier once they use more than
+// REPORT_STORAGE_QUOTA bytes (default: 1 GiB).
 package server
 
 import (
+	"bytes"
+	"context"
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"mime/multipart"
 	"net/http"
 	"os"
+	"path/filepath"
 	"sync"
 	"time"
 
@@ -47,15 +58,42 @@ const (
 	RateLimitRequests = 10
 	// RateLimitPeriod is the time window for rate limiting
 	RateLimitPeriod = 1 * time.Hour
+	// MaxFilesPerReport is the maximum number of files (log plus attachments) accepted in one report
+	MaxFilesPerReport = 4
+	// MaxFileSize is the maximum size of a single file in a report
+	MaxFileSize = 8 << 20
+	// MaxReportSize is the maximum size of a whole report request
+	MaxReportSize = MaxFilesPerReport*MaxFileSize + 1<<20
+	// DefaultDrainTimeout is how long Shutdown waits for in-flight requests by default
+	DefaultDrainTimeout = 10 * time.Second
 )
 
+// errReportTooLarge is returned when a report or one of its files exceeds the size limits
+var errReportTooLarge = errors.New("report too large")
+
+// reportFile is a single file belonging to an error report
+type reportFile struct {
+	name    string
+	content []byte
+}
+
 // Server represents the error report server
 type Server struct {
-	router      *mux.Router
-	webhookURL  string
-	tokens      map[string]time.Time
-	tokensMutex sync.RWMutex
-	limiter     *rate.Limiter
+	router       *mux.Router
+	webhookURL   string
+	tokens       map[string]time.Time
+	tokensMutex  sync.RWMutex
+	limiter      *rate.Limiter
+	storage      storageConfig
+	storageMutex sync.Mutex // keeps two prunes of the storage from running at the same time
+	metrics      *metrics
+	startTime    time.Time
+	httpServer   *http.Server
+	done         chan struct{}
+	stopOnce     sync.Once
+
+	// DrainTimeout is how long Shutdown waits for in-flight requests to finish
+	DrainTimeout time.Duration
 }
 
 // TokenResponse represents the response when requesting a token
@@ -71,11 +109,25 @@ func NewServer(webhookURL string) *Server {
 		log.Fatal("Error loading .env file")
 	}
 
+	return newServer(os.Getenv("DISCORD_WEBHOOK_URL"), storageConfigFromEnv())
+}
+
+// newServer creates a server forwarding to a webhook and storing reports as configured
+func newServer(webhookURL string, storage storageConfig) *Server {
 	s := &Server{
-		router:     mux.NewRouter(),
-		webhookURL: os.Getenv("DISCORD_WEBHOOK_URL"),
-		tokens:     make(map[string]time.Time),
-		limiter:    rate.NewLimiter(rate.Every(RateLimitPeriod/RateLimitRequests), RateLimitRequests),
+		router:       mux.NewRouter(),
+		webhookURL:   webhookURL,
+		tokens:       make(map[string]time.Time),
+		limiter:      rate.NewLimiter(rate.Every(RateLimitPeriod/RateLimitRequests), RateLimitRequests),
+		storage:      storage,
+		metrics:      newMetrics(),
+		startTime:    time.Now(),
+		done:         make(chan struct{}),
+		DrainTimeout: DefaultDrainTimeout,
+	}
+
+	if err := s.loadStorage(); err != nil {
+		log.Printf("Warning: report storage is unavailable: %v", err)
 	}
 
 	s.setupRoutes()
@@ -86,6 +138,9 @@ func NewServer(webhookURL string) *Server {
 func (s *Server) setupRoutes() {
 	s.router.HandleFunc("/token", s.handleTokenRequest).Methods("GET")
 	s.router.HandleFunc("/report", s.handleErrorReport).Methods("POST")
+	s.router.HandleFunc("/suggestions", s.handleSuggestion).Methods("POST")
+	s.router.HandleFunc("/healthz", s.handleHealthz).Methods("GET")
+	s.router.HandleFunc("/metrics", s.handleMetrics).Methods("GET")
 }
 
 // generateToken creates a new random token
@@ -119,12 +174,14 @@ func (s *Server) handleTokenRequest(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(response)
 }
 
-// handleErrorReport processes an error report submission
-func (s *Server) handleErrorReport(w http.ResponseWriter, r *http.Request) {
+// consumeToken checks the token of a request and removes it, so it can only be used once.
+// If the token is missing or invalid, it writes the error response and returns false.
+func (s *Server) consumeToken(w http.ResponseWriter, r *http.Request) bool {
 	token := r.Header.Get("X-Error-Report-Token")
 	if token == "" {
+		s.metrics.reject(rejectBadHeader)
 		http.Error(w, "Missing token", http.StatusUnauthorized)
-		return
+		return false
 	}
 
 	s.tokensMutex.RLock()
@@ -132,8 +189,9 @@ func (s *Server) handleErrorReport(w http.ResponseWriter, r *http.Request) {
 	s.tokensMutex.RUnlock()
 
 	if !valid || time.Now().After(expiry) {
+		s.metrics.reject(rejectBadHeader)
 		http.Error(w, "Invalid or expired token", http.StatusUnauthorized)
-		return
+		return false
 	}
 
 	// Remove the used token
@@ -141,8 +199,38 @@ func (s *Server) handleErrorReport(w http.ResponseWriter, r *http.Request) {
 	delete(s.tokens, token)
 	s.tokensMutex.Unlock()
 
+	return true
+}
+
+// handleErrorReport processes an error report submission
+func (s *Server) handleErrorReport(w http.ResponseWriter, r *http.Request) {
+	if !s.consumeToken(w, r) {
+		return
+	}
+
+	files, err := readReportFiles(w, r)
+	if err != nil {
+		if errors.Is(err, errReportTooLarge) {
+			s.metrics.reject(rejectTooLarge)
+			http.Error(w, "Report too large", http.StatusRequestEntityTooLarge)
+		} else {
+			s.metrics.reject(rejectInvalid)
+			http.Error(w, "Invalid report: "+err.Error(), http.StatusBadRequest)
+		}
+		return
+	}
+
+	// All files of a report are associated with the fingerprint of its log file
+	fingerprint := reportFingerprint(files[0].content)
+	s.metrics.reportsReceived.Add(1)
+
+	if err := s.storeReport(fingerprint, files); err != nil {
+		log.Printf("Failed to store report %s: %v", fingerprint, err)
+	}
+
 	// Forward the report to Discord webhook
-	if err := s.forwardToDiscord(r); err != nil {
+	if err := s.forwardToDiscord(fingerprint, files); err != nil {
+		log.Printf("Failed to forward report %s: %v", fingerprint, err)
 		http.Error(w, "Failed to process report", http.StatusInternalServerError)
 		return
 	}
@@ -150,16 +238,109 @@ func (s *Server) handleErrorReport(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 }
 
-// forwardToDiscord forwards the error report to Discord
-func (s *Server) forwardToDiscord(r *http.Request) error {
-	// Create a new request to forward to Discord
-	req, err := http.NewRequest("POST", s.webhookURL, r.Body)
+// readReportFiles reads the log file and its attachments from a multipart report request
+//
+// The log file is sent in the "file" field and is always returned first, attachments are sent in "attachment" fields.
+func readReportFiles(w http.ResponseWriter, r *http.Request) ([]reportFile, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, MaxReportSize)
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return nil, err
+	}
+
+	var logFile *reportFile
+	var attachments []reportFile
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				return nil, errReportTooLarge
+			}
+			return nil, err
+		}
+
+		field := part.FormName()
+		if field != "file" && field != "attachment" {
+			part.Close()
+			continue
+		}
+		if len(attachments)+1 >= MaxFilesPerReport {
+			return nil, fmt.Errorf("too many files, at most %d are allowed", MaxFilesPerReport)
+		}
+
+		content, err := io.ReadAll(io.LimitReader(part, MaxFileSize+1))
+		part.Close()
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				return nil, errReportTooLarge
+			}
+			return nil, err
+		}
+		if len(content) > MaxFileSize {
+			return nil, errReportTooLarge
+		}
+
+		file := reportFile{name: filepath.Base(part.FileName()), content: content}
+		if field == "file" {
+			if logFile != nil {
+				return nil, errors.New("more than one log file in report")
+			}
+			logFile = &file
+		} else {
+			attachments = append(attachments, file)
+		}
+	}
+
+	if logFile == nil {
+		return nil, errors.New("missing log file")
+	}
+
+	return append([]reportFile{*logFile}, attachments...), nil
+}
+
+// reportFingerprint returns a short identifier derived from the contents of a log file
+func reportFingerprint(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])[:16]
+}
+
+// forwardToDiscord forwards the error report with all of its files to Discord
+func (s *Server) forwardToDiscord(fingerprint string, files []reportFile) error {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+
+	payload, err := json.Marshal(map[string]string{"content": "Error report " + fingerprint})
 	if err != nil {
+		return fmt.Errorf("failed to encode the Discord payload: %w", err)
+	}
+	if err := writer.WriteField("payload_json", string(payload)); err != nil {
+		return fmt.Errorf("failed to write the Discord payload: %w", err)
+	}
+
+	for i, file := range files {
+		part, err := writer.CreateFormFile(fmt.Sprintf("files[%d]", i), file.name)
+		if err != nil {
+			return err
+		}
+		if _, err := part.Write(file.content); err != nil {
+			return err
+		}
+	}
+	if err := writer.Close(); err != nil {
 		return err
 	}
 
-	// Copy relevant headers
-	req.Header.Set("Content-Type", r.Header.Get("Content-Type"))
+	// Create a new request to forward to Discord
+	req, err := http.NewRequest("POST", s.webhookURL, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
 
 	// Send the request
 	client := &http.Client{}
@@ -177,22 +358,58 @@ func (s *Server) forwardToDiscord(r *http.Request) error {
 }
 
 // Start starts the server on the specified address
+//
+// It blocks until the server fails or Shutdown is called, in which case it returns nil.
 func (s *Server) Start(addr string) error {
 	log.Printf("Starting error report server on %s", addr)
-	return http.ListenAndServe(addr, s.router)
+	s.httpServer = &http.Server{
+		Addr:    addr,
+		Handler: s.router,
+	}
+	if err := s.httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Shutdown stops accepting new requests and waits up to DrainTimeout for in-flight requests to finish
+func (s *Server) Shutdown() error {
+	s.stopOnce.Do(func() {
+		close(s.done)
+	})
+	if s.httpServer == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.DrainTimeout)
+	defer cancel()
+	return s.httpServer.Shutdown(ctx)
 }
 
-// CleanupExpiredTokens periodically removes expired tokens
+// CleanupExpiredTokens periodically removes expired tokens and prunes the stored reports until the server is shut down
 func (s *Server) CleanupExpiredTokens() {
 	ticker := time.NewTicker(1 * time.Hour)
-	for range ticker.C {
-		s.tokensMutex.Lock()
-		now := time.Now()
-		for token, expiry := range s.tokens {
-			if now.After(expiry) {
-				delete(s.tokens, token)
-			}
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+			s.cleanupExpiredTokens()
+			s.pruneStorage()
+		}
+	}
+}
+
+// cleanupExpiredTokens removes all expired tokens once
+func (s *Server) cleanupExpiredTokens() {
+	s.tokensMutex.Lock()
+	now := time.Now()
+	for token, expiry := range s.tokens {
+		if now.After(expiry) {
+			delete(s.tokens, token)
 		}
-		s.tokensMutex.Unlock()
 	}
+	s.tokensMutex.Unlock()
+	s.metrics.tokenCleanupRuns.Add(1)
 }
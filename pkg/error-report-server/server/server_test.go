@@ -0,0 +1,249 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestServer starts a server forwarding to a fake Discord webhook, which counts the requests it gets
+func newTestServer(t *testing.T, storage storageConfig) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var forwarded atomic.Int32
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		forwarded.Add(1)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(webhook.Close)
+
+	s := newServer(webhook.URL, storage)
+	server := httptest.NewServer(s.router)
+	t.Cleanup(server.Close)
+	return server, &forwarded
+}
+
+// getToken requests a token from the server
+func getToken(t *testing.T, server *httptest.Server) string {
+	t.Helper()
+	resp, err := http.Get(server.URL + "/token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var token TokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil || token.Token == "" {
+		t.Fatalf("no token in the response: %v", err)
+	}
+	return token.Token
+}
+
+// postReport sends a report with a log file and the given attachments, returning the status code
+func postReport(t *testing.T, server *httptest.Server, token string, log string, attachments ...string) int {
+	t.Helper()
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	if log != "" {
+		part, _ := writer.CreateFormFile("file", "install-fail-Zoom.log")
+		part.Write([]byte(log))
+	}
+	for i, attachment := range attachments {
+		part, _ := writer.CreateFormFile("attachment", "screenshot-"+string(rune('a'+i))+".png")
+		part.Write([]byte(attachment))
+	}
+	writer.Close()
+
+	req, _ := http.NewRequest("POST", server.URL+"/report", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	if token != "" {
+		req.Header.Set("X-Error-Report-Token", token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+// storedFiles returns the regular files below a directory
+func storedFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	var files []string
+	filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err == nil && info.Mode().IsRegular() {
+			files = append(files, path)
+		}
+		return nil
+	})
+	return files
+}
+
+func TestReportIsForwardedButNotStoredByDefault(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	server, forwarded := newTestServer(t, storageConfig{})
+
+	if code := postReport(t, server, getToken(t, server), "install failed\n"); code != http.StatusOK {
+		t.Fatalf("report got status %d, want 200", code)
+	}
+	if forwarded.Load() != 1 {
+		t.Errorf("the report was forwarded %d times, want once", forwarded.Load())
+	}
+	if files := storedFiles(t, dir); len(files) != 0 {
+		t.Errorf("reports were stored without a storage directory: %v", files)
+	}
+}
+
+func TestReportStorageIsPrivate(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "reports")
+	server, _ := newTestServer(t, storageConfig{dir: dir, retention: time.Hour, quota: 1 << 20})
+
+	if code := postReport(t, server, getToken(t, server), "install failed\n", "image"); code != http.StatusOK {
+		t.Fatalf("report got status %d, want 200", code)
+	}
+	if code := postSuggestion(t, server, getToken(t, server), `{"name":"Zoom","url":"https://zoom.us"}`); code != http.StatusOK {
+		t.Fatalf("suggestion got status %d, want 200", code)
+	}
+
+	files := storedFiles(t, dir)
+	if len(files) != 3 {
+		t.Fatalf("stored %d files, want the log, the attachment and the suggestion: %v", len(files), files)
+	}
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if perm := info.Mode().Perm(); perm != 0600 {
+			t.Errorf("%s has mode %04o, want 0600", file, perm)
+		}
+		dirInfo, err := os.Stat(filepath.Dir(file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if perm := dirInfo.Mode().Perm(); perm != 0700 {
+			t.Errorf("%s has mode %04o, want 0700", filepath.Dir(file), perm)
+		}
+	}
+}
+
+func TestReportRejected(t *testing.T) {
+	server, forwarded := newTestServer(t, storageConfig{})
+
+	if code := postReport(t, server, "", "log"); code != http.StatusUnauthorized {
+		t.Errorf("report without a token got status %d, want 401", code)
+	}
+	if code := postReport(t, server, "made-up", "log"); code != http.StatusUnauthorized {
+		t.Errorf("report with an unknown token got status %d, want 401", code)
+	}
+
+	token := getToken(t, server)
+	if code := postReport(t, server, token, "", "image"); code != http.StatusBadRequest {
+		t.Errorf("report without a log file got status %d, want 400", code)
+	}
+	// A token is used up by the first request, even a rejected one
+	if code := postReport(t, server, token, "log"); code != http.StatusUnauthorized {
+		t.Errorf("report with a used token got status %d, want 401", code)
+	}
+
+	if code := postReport(t, server, getToken(t, server), "log", "a", "b", "c", "d"); code != http.StatusBadRequest {
+		t.Errorf("report with too many files got status %d, want 400", code)
+	}
+	if code := postSuggestion(t, server, getToken(t, server), `{"name":"Zoom","url":"javascript:alert(1)"}`); code != http.StatusBadRequest {
+		t.Errorf("suggestion without a web address got status %d, want 400", code)
+	}
+	if forwarded.Load() != 0 {
+		t.Errorf("%d rejected requests were forwarded", forwarded.Load())
+	}
+}
+
+// postSuggestion sends an app suggestion, returning the status code
+func postSuggestion(t *testing.T, server *httptest.Server, token, suggestion string) int {
+	t.Helper()
+	req, _ := http.NewRequest("POST", server.URL+"/suggestions", strings.NewReader(suggestion))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Error-Report-Token", token)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+func TestPruneStorage(t *testing.T) {
+	dir := t.TempDir()
+	s := newServer("", storageConfig{dir: dir, retention: 24 * time.Hour, quota: 10})
+
+	write := func(name string, size int, age time.Duration) string {
+		path := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(path), 0700)
+		if err := os.WriteFile(path, bytes.Repeat([]byte("x"), size), 0600); err != nil {
+			t.Fatal(err)
+		}
+		modTime := time.Now().Add(-age)
+		os.Chtimes(path, modTime, modTime)
+		return path
+	}
+	expired := write("aaaa/expired.log", 1, 48*time.Hour)
+	oldest := write("bbbb/oldest.log", 6, 3*time.Hour)
+	newer := write("bbbb/newer.log", 4, 2*time.Hour)
+	newest := write("suggestions/newest.json", 4, time.Hour)
+
+	s.pruneStorage()
+
+	for path, want := range map[string]bool{expired: false, oldest: false, newer: true, newest: true} {
+		if _, err := os.Stat(path); (err == nil) != want {
+			t.Errorf("%s kept: %v, want %v", path, err == nil, want)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "aaaa")); err == nil {
+		t.Error("the directory of a fingerprint without files was kept")
+	}
+	if got := s.metrics.storageBytes.Load(); got != 8 {
+		t.Errorf("storage bytes = %d, want 8", got)
+	}
+}
+
+func TestHealthz(t *testing.T) {
+	server, _ := newTestServer(t, storageConfig{dir: t.TempDir(), retention: time.Hour, quota: 1 << 20})
+	resp, err := http.Get(server.URL + "/healthz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var health HealthResponse
+	if err := json.NewDecoder(resp.Body).Decode(&health); err != nil {
+		t.Fatal(err)
+	}
+	if health.Status != "ok" || !health.StorageEnabled || !health.StorageWritable {
+		t.Errorf("healthz = %+v, want ok with writable storage", health)
+	}
+}
@@ -0,0 +1,203 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: storage.go
+// Description: Provides the opt-in on-disk storage of received error reports, grouped by fingerprint,
+// with a retention period and a quota.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package server
+
+import (
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const (
+	// DefaultStorageRetention is how long stored reports are kept if REPORT_STORAGE_RETENTION is not set
+	DefaultStorageRetention = 30 * 24 * time.Hour
+	// DefaultStorageQuota is how many bytes stored reports may use if REPORT_STORAGE_QUOTA is not set
+	DefaultStorageQuota = 1 << 30
+)
+
+// storageConfig is where and for how long reports are stored. Logs contain user names, paths and other
+// details of the machines they come from, so nothing is stored unless a directory is configured.
+type storageConfig struct {
+	dir       string        // directory of the stored reports, "" stores nothing
+	retention time.Duration // stored files older than this are removed
+	quota     int64         // the oldest stored files are removed while all of them use more bytes than this
+}
+
+// storageConfigFromEnv reads the storage configuration from REPORT_STORAGE_DIR, REPORT_STORAGE_RETENTION
+// (a duration like 720h) and REPORT_STORAGE_QUOTA (bytes)
+func storageConfigFromEnv() storageConfig {
+	config := storageConfig{
+		dir:       os.Getenv("REPORT_STORAGE_DIR"),
+		retention: DefaultStorageRetention,
+		quota:     DefaultStorageQuota,
+	}
+	if value := os.Getenv("REPORT_STORAGE_RETENTION"); value != "" {
+		if retention, err := time.ParseDuration(value); err == nil && retention > 0 {
+			config.retention = retention
+		} else {
+			log.Printf("Warning: ignoring invalid REPORT_STORAGE_RETENTION %q", value)
+		}
+	}
+	if value := os.Getenv("REPORT_STORAGE_QUOTA"); value != "" {
+		if quota, err := strconv.ParseInt(value, 10, 64); err == nil && quota > 0 {
+			config.quota = quota
+		} else {
+			log.Printf("Warning: ignoring invalid REPORT_STORAGE_QUOTA %q", value)
+		}
+	}
+	return config
+}
+
+// storageEnabled reports whether received reports are stored
+func (s *Server) storageEnabled() bool {
+	return s.storage.dir != ""
+}
+
+// loadStorage creates the storage directory if needed, removes what is past the retention period or the quota
+// and initializes the storage metrics from what is left
+func (s *Server) loadStorage() error {
+	if !s.storageEnabled() {
+		return nil
+	}
+	if err := os.MkdirAll(s.storage.dir, 0700); err != nil {
+		return fmt.Errorf("failed to create storage directory: %w", err)
+	}
+
+	entries, err := os.ReadDir(s.storage.dir)
+	if err != nil {
+		return fmt.Errorf("failed to read storage directory: %w", err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() && entry.Name() != suggestionsDir {
+			s.metrics.addFingerprint(entry.Name())
+		}
+	}
+
+	s.pruneStorage()
+	return nil
+}
+
+// storedFile is a file in the storage directory
+type storedFile struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+// pruneStorage removes the stored files past the retention period, then the oldest ones while the rest is over
+// the quota, and updates the storage metrics
+func (s *Server) pruneStorage() {
+	if !s.storageEnabled() {
+		return
+	}
+	s.storageMutex.Lock()
+	defer s.storageMutex.Unlock()
+
+	var files []storedFile
+	filepath.WalkDir(s.storage.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			files = append(files, storedFile{path: path, size: info.Size(), modTime: info.ModTime()})
+		}
+		return nil
+	})
+	sort.Slice(files, func(i, j int) bool { return files[i].modTime.Before(files[j].modTime) })
+
+	var size int64
+	for _, file := range files {
+		size += file.size
+	}
+	cutoff := time.Now().Add(-s.storage.retention)
+	for _, file := range files {
+		if !file.modTime.Before(cutoff) && size <= s.storage.quota {
+			break
+		}
+		if err := os.Remove(file.path); err != nil {
+			log.Printf("Failed to remove stored file %s: %v", file.path, err)
+			continue
+		}
+		size -= file.size
+		// Removes the directory of a fingerprint once its last file is gone
+		if dir := filepath.Dir(file.path); dir != s.storage.dir {
+			os.Remove(dir)
+		}
+	}
+	s.metrics.storageBytes.Store(size)
+}
+
+// writeStoredFile writes a file only the server can read into a directory of the storage
+func (s *Server) writeStoredFile(dir, name string, content []byte) error {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, name), content, 0600); err != nil {
+		return err
+	}
+	s.metrics.storageBytes.Add(int64(len(content)))
+	return nil
+}
+
+// storeReport saves all files of a report in the directory of its fingerprint, if storage is enabled
+func (s *Server) storeReport(fingerprint string, files []reportFile) error {
+	if !s.storageEnabled() {
+		return nil
+	}
+	dir := filepath.Join(s.storage.dir, fingerprint)
+	prefix := time.Now().UTC().Format("20060102T150405.000000000")
+	for i, file := range files {
+		name := file.name
+		if name == "" || name == "." || name == string(filepath.Separator) {
+			name = fmt.Sprintf("file-%d", i)
+		}
+		if err := s.writeStoredFile(dir, fmt.Sprintf("%s-%d-%s", prefix, i, name), file.content); err != nil {
+			return err
+		}
+	}
+
+	s.metrics.addFingerprint(fingerprint)
+	if s.metrics.storageBytes.Load() > s.storage.quota {
+		s.pruneStorage()
+	}
+	return nil
+}
+
+// storageWritable reports whether a file can be created in the storage directory, false if storage is disabled
+func (s *Server) storageWritable() bool {
+	if !s.storageEnabled() {
+		return false
+	}
+	file, err := os.CreateTemp(s.storage.dir, ".healthz-*")
+	if err != nil {
+		return false
+	}
+	name := file.Name()
+	file.Close()
+	os.Remove(name)
+	return true
+}
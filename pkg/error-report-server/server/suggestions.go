@@ -0,0 +1,159 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: suggestions.go
+// Description: Receives the apps users suggest from Pi-Apps, optionally stores them and forwards them to Discord.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// MaxSuggestionSize is the maximum size of a suggestion request
+	MaxSuggestionSize = 16 << 10
+	// suggestionsDir is the directory of the storage suggestions are kept in
+	suggestionsDir = "suggestions"
+)
+
+// Suggestion is an app a user would like to see in Pi-Apps
+type Suggestion struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+	Why  string `json:"why"`
+}
+
+// validate checks that a suggestion names an app and links to a web page
+func (suggestion Suggestion) validate() error {
+	if strings.TrimSpace(suggestion.Name) == "" {
+		return errors.New("missing app name")
+	}
+	link, err := url.Parse(suggestion.URL)
+	if err != nil || (link.Scheme != "http" && link.Scheme != "https") || link.Host == "" {
+		return errors.New("url is not a web address")
+	}
+	return nil
+}
+
+// handleSuggestion processes an app suggestion, a JSON encoded Suggestion
+func (s *Server) handleSuggestion(w http.ResponseWriter, r *http.Request) {
+	if !s.consumeToken(w, r) {
+		return
+	}
+
+	var suggestion Suggestion
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, MaxSuggestionSize))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&suggestion); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			s.metrics.reject(rejectTooLarge)
+			http.Error(w, "Suggestion too large", http.StatusRequestEntityTooLarge)
+		} else {
+			s.metrics.reject(rejectInvalid)
+			http.Error(w, "Invalid suggestion: "+err.Error(), http.StatusBadRequest)
+		}
+		return
+	}
+	if err := suggestion.validate(); err != nil {
+		s.metrics.reject(rejectInvalid)
+		http.Error(w, "Invalid suggestion: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.metrics.suggestionsReceived.Add(1)
+
+	if err := s.storeSuggestion(suggestion); err != nil {
+		log.Printf("Failed to store suggestion of %s: %v", suggestion.Name, err)
+	}
+
+	if err := s.forwardSuggestionToDiscord(suggestion); err != nil {
+		log.Printf("Failed to forward suggestion of %s: %v", suggestion.Name, err)
+		http.Error(w, "Failed to process suggestion", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// storeSuggestion saves a suggestion as a JSON file in the suggestions directory, if storage is enabled.
+// It is kept and pruned like the stored reports.
+func (s *Server) storeSuggestion(suggestion Suggestion) error {
+	if !s.storageEnabled() {
+		return nil
+	}
+	content, err := json.MarshalIndent(suggestion, "", "  ")
+	if err != nil {
+		return err
+	}
+	name := time.Now().UTC().Format("20060102T150405.000000000") + ".json"
+	if err := s.writeStoredFile(filepath.Join(s.storage.dir, suggestionsDir), name, content); err != nil {
+		return err
+	}
+	if s.metrics.storageBytes.Load() > s.storage.quota {
+		s.pruneStorage()
+	}
+	return nil
+}
+
+// forwardSuggestionToDiscord posts a suggestion as a message to the Discord webhook
+func (s *Server) forwardSuggestionToDiscord(suggestion Suggestion) error {
+	message := fmt.Sprintf("App suggestion: **%s**\n<%s>", suggestion.Name, suggestion.URL)
+	if why := strings.TrimSpace(suggestion.Why); why != "" {
+		message += "\n" + why
+	}
+	// Discord rejects messages longer than 2000 characters
+	if runes := []rune(message); len(runes) > 2000 {
+		message = string(runes[:2000])
+	}
+
+	payload, err := json.Marshal(map[string]any{
+		"content":          message,
+		"allowed_mentions": map[string][]string{"parse": {}},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode the Discord payload: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", s.webhookURL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("discord webhook returned status: %d", resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,298 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: daemon_queue.go
+// Description: Works through the queue of the manage daemon, the same way in the manage program and in the multi-call binary.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package gui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pi-apps-go/pi-apps/pkg/api"
+	"github.com/pi-apps-go/pi-apps/pkg/api/errs"
+	"github.com/pi-apps-go/pi-apps/pkg/terminal"
+)
+
+// ProcessQueue runs the waiting items of the queue one after another, asking what to do about the failed ones
+// once none are left, and returns the exit code for the queue. The queue is only accessed with queueMutex held,
+// as the queue listener of the daemon terminal adds, moves and removes waiting items meanwhile. The status file and
+// the run report are kept up to date with the queue.
+func ProcessQueue(queue *[]QueueItem, queueMutex *sync.Mutex, statusFile string, policy OnCompletePolicy, report *RunReporter) int {
+	// Failures found before anything ran, like an app that is already installed, leave no log to diagnose
+	noLog := make(map[string]bool)
+
+	// Process the queue with retry loop for failed apps
+	for {
+		queueMutex.Lock()
+		// Find next unprocessed item
+		currentIndex := -1
+		for i := range *queue {
+			if (*queue)[i].Status == "waiting" {
+				currentIndex = i
+				break
+			}
+		}
+
+		// Check if all items are processed
+		if currentIndex == -1 {
+			// Check for failed apps and run diagnosis
+			var failedApps []string
+			for _, item := range *queue {
+				if item.Status == "failure" && !noLog[item.Action+";"+item.AppName] {
+					failedApps = append(failedApps, api.FailureLine(item.Action, item.AppName, item.LogFile))
+				}
+			}
+			queueMutex.Unlock()
+
+			// No failed apps, or no retries requested, we're done
+			if len(failedApps) == 0 || !retryFailedActions(queue, queueMutex, statusFile, report, failedApps) {
+				break
+			}
+			continue
+		}
+
+		// Update status to in-progress, from now on the item can't be moved or removed
+		(*queue)[currentIndex].Status = "in-progress"
+		(*queue)[currentIndex].Started = time.Now()
+		item := (*queue)[currentIndex]
+		err := WriteDaemonStatus(statusFile, report, *queue)
+		queueMutex.Unlock()
+		if err != nil {
+			fmt.Printf("Warning: failed to write status: %v\n", err)
+		}
+
+		// updateRunning changes the running item and writes the status, for the progress monitor
+		updateRunning := func(update func(*QueueItem)) {
+			queueMutex.Lock()
+			for i := range *queue {
+				if (*queue)[i].ID == item.ID {
+					update(&(*queue)[i])
+					break
+				}
+			}
+			err := WriteDaemonStatus(statusFile, report, *queue)
+			queueMutex.Unlock()
+			if err != nil {
+				fmt.Printf("Warning: failed to write status: %v\n", err)
+			}
+		}
+		phaseFile := filepath.Join(filepath.Dir(statusFile), "phase")
+		item, actionErr := runQueueItem(item, phaseFile, func(progress api.FileProgress) {
+			// Show which file a refresh or file update is at in the progress monitor
+			updateRunning(func(running *QueueItem) {
+				running.Progress = fmt.Sprintf("%d/%d", progress.Done, progress.Total)
+			})
+		}, func(phase api.QueuePhase) {
+			// Show what the script is doing, like downloading or installing packages
+			updateRunning(func(running *QueueItem) {
+				running.Phase, running.Percent = phase.Name, phase.Percent
+			})
+		})
+		if actionErr != nil {
+			noLog[item.Action+";"+item.AppName] = !errs.HasLog(actionErr)
+		}
+
+		// Update only this app's entry in the app list instead of regenerating the whole list
+		RefreshAfterQueueItem(item)
+
+		// Write updated status, looking the item up by ID since waiting items may have moved meanwhile
+		queueMutex.Lock()
+		for i := range *queue {
+			if (*queue)[i].ID == item.ID {
+				(*queue)[i] = item
+				break
+			}
+		}
+		err = WriteDaemonStatus(statusFile, report, *queue)
+		queueMutex.Unlock()
+		if err != nil {
+			fmt.Printf("Warning: failed to write status: %v\n", err)
+		}
+	}
+
+	queueMutex.Lock()
+	finishedQueue := slices.Clone(*queue)
+	queueMutex.Unlock()
+	RefreshAfterQueue(finishedQueue)
+	exitCode := report.Finish(finishedQueue)
+	FinishDaemonTerminal(policy, finishedQueue, statusFile)
+
+	// Signal the progress monitor that daemon processing is complete
+	// Add a special completion marker to the queue
+	queueMutex.Lock()
+	*queue = append(*queue, QueueItem{
+		Action:   "daemon",
+		AppName:  "completed",
+		Status:   "daemon-complete",
+		IconPath: "",
+	})
+	err := WriteDaemonStatus(statusFile, report, *queue)
+	queueMutex.Unlock()
+	if err != nil {
+		fmt.Printf("Warning: failed to write completion status: %v\n", err)
+	}
+
+	return exitCode
+}
+
+// runQueueItem runs the action of a queue item under a banner, showing the elapsed time in the title of the
+// terminal, and returns the item with its result. The script and the programs it starts report their phase to
+// phaseFile, which is passed on to onPhase.
+func runQueueItem(item QueueItem, phaseFile string, onProgress func(api.FileProgress), onPhase func(api.QueuePhase)) (QueueItem, error) {
+	terminal.Banner(item.AppName, item.Action)
+	ticker := terminal.StartElapsedTicker(terminal.ActionTitle(item.Action, item.AppName))
+	api.SetFileProgressHandler(onProgress)
+	os.Remove(phaseFile)
+	os.Setenv(api.PhaseFileEnv, phaseFile)
+	stopWatching := api.WatchPhase(phaseFile, 500*time.Millisecond, onPhase)
+
+	// Execute the action - let API functions handle their own status messaging
+	var actionErr error
+	switch item.Action {
+	case "install":
+		actionErr = api.InstallApp(item.AppName)
+	case "uninstall":
+		actionErr = api.UninstallApp(item.AppName)
+	case "update":
+		actionErr = api.UpdateApp(item.AppName)
+	case "refresh":
+		actionErr = api.RefreshApp(item.AppName)
+	case "update-file":
+		actionErr = api.UpdateFile(item.AppName)
+	}
+	api.SetFileProgressHandler(nil)
+	stopWatching()
+	os.Unsetenv(api.PhaseFileEnv)
+	os.Remove(phaseFile)
+	ticker.Stop()
+	item.Progress = ""
+	item.Phase, item.Percent = "", 0
+	item.Finished = time.Now()
+	// Every item writes its own log file, even when the app was queued before
+	item.LogFile = api.AppLogfileSince(item.AppName, item.Started)
+
+	// Update status based on result
+	if actionErr != nil {
+		item.Status = "failure"
+		item.ErrorMessage = actionErr.Error()
+		item.ExitCode = api.FailureExitCode(actionErr)
+	} else {
+		item.Status = "success"
+	}
+
+	// Format the log file to add device information (consistent with bash version)
+	if api.FileExists(item.LogFile) {
+		if err := api.FormatLogfile(item.LogFile); err != nil {
+			fmt.Printf("Warning: failed to format log file %s: %v\n", item.LogFile, err)
+		}
+	}
+	return item, actionErr
+}
+
+// retryFailedActions explains the failed actions and queues the ones the user wants to retry, marking the failed
+// items as diagnosed so they are not diagnosed again. It reports whether anything was queued.
+func retryFailedActions(queue *[]QueueItem, queueMutex *sync.Mutex, statusFile string, report *RunReporter, failedApps []string) bool {
+	// Run diagnosis on failed apps
+	fmt.Println("\nDiagnosing failed operations...")
+	results := diagnoseFailedActions(strings.Join(failedApps, "\n"))
+
+	// Process the diagnosis results
+	var retryApps []string
+	for _, result := range results {
+		if result.Action == "retry" {
+			retryApps = append(retryApps, result.ActionStr)
+		}
+	}
+	if len(retryApps) == 0 {
+		return false
+	}
+
+	queueMutex.Lock()
+	// Mark failed apps as "diagnosed" to avoid repeated diagnosis
+	for i := range *queue {
+		if (*queue)[i].Status == "failure" {
+			(*queue)[i].Status = "diagnosed"
+		}
+	}
+
+	// Queue the retries, prioritizing updates and refreshes among them
+	*queue = AddToQueue(*queue, ParseQueue(strings.Join(retryApps, "\n")))
+
+	// Write status update to show diagnosed items
+	err := WriteDaemonStatus(statusFile, report, *queue)
+	queueMutex.Unlock()
+	if err != nil {
+		fmt.Printf("Warning: failed to write updated status: %v\n", err)
+	}
+
+	// Add a small delay before starting retries (like in original implementation)
+	fmt.Println("Preparing to retry operations...")
+	time.Sleep(2 * time.Second)
+	return true
+}
+
+// WriteDaemonStatus writes the queue status of the manage daemon to a file for IPC, with an icon for every item, and
+// updates the run report of the queue. A status file of "" only updates the report.
+func WriteDaemonStatus(statusFile string, report *RunReporter, queue []QueueItem) error {
+	report.Update(queue)
+	if statusFile == "" {
+		return nil
+	}
+
+	queue = slices.Clone(queue)
+	for i, item := range queue {
+		// Ensure icon path is valid (not empty or a directory)
+		iconPath := item.IconPath
+		if iconPath == "" || iconPath == api.GetPiAppsDir() {
+			// Fix invalid icon paths - check for deprecated apps first
+			if api.IsDeprecatedApp(item.AppName) {
+				iconPath = api.GetDeprecatedAppIcon(item.AppName)
+				if iconPath == "" {
+					iconPath = filepath.Join(api.GetPiAppsDir(), "icons", "none-64.png")
+				}
+			} else {
+				iconPath = filepath.Join(api.GetPiAppsDir(), "apps", item.AppName, "icon-64.png")
+				if _, err := os.Stat(iconPath); os.IsNotExist(err) {
+					iconPath = filepath.Join(api.GetPiAppsDir(), "icons", "none-64.png")
+				}
+			}
+		}
+
+		queue[i].IconPath = iconPath
+	}
+
+	return WriteQueueStatus(statusFile, queue)
+}
+
+// diagnoseRetryTimeout is how long a run without a display waits for the answer whether to retry failed actions
+const diagnoseRetryTimeout = 60 * time.Second
+
+// diagnoseFailedActions explains the failed actions and asks what to do about them, in dialogs when there is a
+// display and otherwise in the terminal, where nothing is retried unless the user answers in time
+func diagnoseFailedActions(failureList string) []api.DiagnoseResult {
+	if api.GUISupported && api.DisplaySessionInfo().HasDisplay() {
+		return api.DiagnoseApps(failureList)
+	}
+	return api.DiagnoseAppsInTerminal(failureList, diagnoseRetryTimeout)
+}
@@ -0,0 +1,193 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: daemon_terminal.go
+// Description: Provides the end-of-queue behaviour of the manage daemon terminal and the state it shares with the GUI.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package gui
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pi-apps-go/pi-apps/pkg/api"
+	"golang.org/x/term"
+)
+
+// Values of the --on-complete flag and the "Manage terminal on completion" setting
+const (
+	OnCompleteKeep           = "keep"             // wait for Enter
+	OnCompleteClose          = "close"            // exit immediately
+	OnCompleteCloseOnSuccess = "close-on-success" // wait for Enter only if an operation failed
+	onCompleteTimeoutPrefix  = "timeout:"         // wait for Enter or the given number of seconds
+)
+
+// OnCompletePolicy is what the daemon terminal does once the queue is finished
+type OnCompletePolicy struct {
+	Mode    string        // keep, close, close-on-success or timeout
+	Timeout time.Duration // only used by the timeout mode
+}
+
+// String returns the policy in the form accepted by ParseOnCompletePolicy
+func (p OnCompletePolicy) String() string {
+	if p.Mode == "timeout" {
+		return onCompleteTimeoutPrefix + strconv.Itoa(int(p.Timeout/time.Second))
+	}
+	return p.Mode
+}
+
+// ParseOnCompletePolicy parses keep, close, close-on-success or timeout:<seconds>
+func ParseOnCompletePolicy(value string) (OnCompletePolicy, error) {
+	value = strings.TrimSpace(value)
+	switch value {
+	case OnCompleteKeep, OnCompleteClose, OnCompleteCloseOnSuccess:
+		return OnCompletePolicy{Mode: value}, nil
+	}
+	if seconds, found := strings.CutPrefix(value, onCompleteTimeoutPrefix); found {
+		if n, err := strconv.Atoi(seconds); err == nil && n >= 0 {
+			return OnCompletePolicy{Mode: "timeout", Timeout: time.Duration(n) * time.Second}, nil
+		}
+	}
+	return OnCompletePolicy{}, fmt.Errorf("invalid on-complete value %q, expected keep, close, close-on-success or timeout:<seconds>", value)
+}
+
+// ResolveOnCompletePolicy returns the policy given by the --on-complete flag, or else
+// the "Manage terminal on completion" setting, or else keep
+func ResolveOnCompletePolicy(flagValue string) (OnCompletePolicy, error) {
+	if flagValue != "" {
+		return ParseOnCompletePolicy(flagValue)
+	}
+	// An invalid setting is read as keep, with a warning
+	if policy, err := ParseOnCompletePolicy(api.ReadSetting("Manage terminal on completion")); err == nil {
+		return policy, nil
+	}
+	return OnCompletePolicy{Mode: OnCompleteKeep}, nil
+}
+
+// staysOpen reports whether the terminal waits before closing with this policy
+func (p OnCompletePolicy) staysOpen(failed int) bool {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		// Nobody can press Enter, never block unattended runs
+		return false
+	}
+	switch p.Mode {
+	case OnCompleteClose:
+		return false
+	case OnCompleteCloseOnSuccess:
+		return failed > 0
+	case "timeout":
+		return p.Timeout > 0
+	default:
+		return true
+	}
+}
+
+// DaemonTerminalState is written as JSON next to the daemon status file,
+// so the GUI knows how the terminal ends and whether it is still open
+type DaemonTerminalState struct {
+	OnComplete   string    `json:"on_complete"`
+	Completed    bool      `json:"completed"` // every operation of the queue is finished
+	Failed       int       `json:"failed"`
+	TerminalOpen bool      `json:"terminal_open"`
+	Updated      time.Time `json:"updated"`
+}
+
+// daemonTerminalStateFile returns the path of the JSON state that belongs to a daemon status file
+func daemonTerminalStateFile(statusFile string) string {
+	return statusFile + ".json"
+}
+
+// WriteDaemonTerminalState records the state of the daemon terminal next to its status file
+func WriteDaemonTerminalState(statusFile string, state DaemonTerminalState) error {
+	if statusFile == "" {
+		return nil
+	}
+	state.Updated = time.Now()
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	return writeFileReplacing(daemonTerminalStateFile(statusFile), data)
+}
+
+// ReadDaemonTerminalState reads the state written by WriteDaemonTerminalState
+func ReadDaemonTerminalState(statusFile string) (DaemonTerminalState, error) {
+	var state DaemonTerminalState
+	data, err := os.ReadFile(daemonTerminalStateFile(statusFile))
+	if err != nil {
+		return state, err
+	}
+	err = json.Unmarshal(data, &state)
+	return state, err
+}
+
+// RemoveDaemonTerminalState removes the JSON state of a daemon status file
+func RemoveDaemonTerminalState(statusFile string) {
+	os.Remove(daemonTerminalStateFile(statusFile))
+}
+
+// FinishDaemonTerminal ends a processed queue according to the policy: it prints a summary line,
+// records whether the terminal stays open in the JSON state, and waits if the policy asks for it
+func FinishDaemonTerminal(policy OnCompletePolicy, queue []QueueItem, statusFile string) {
+	failed := 0
+	for _, item := range queue {
+		if item.Status == "failure" {
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		fmt.Println()
+		api.ErrorNoExitTf("%d of %d operations failed.", failed, len(queue))
+	}
+
+	open := policy.staysOpen(failed)
+	state := DaemonTerminalState{OnComplete: policy.String(), Completed: true, Failed: failed, TerminalOpen: open}
+	if err := WriteDaemonTerminalState(statusFile, state); err != nil {
+		fmt.Printf("Warning: failed to write terminal state: %v\n", err)
+	}
+	if !open {
+		return
+	}
+
+	enter := make(chan struct{})
+	go func() {
+		bufio.NewReader(os.Stdin).ReadString('\n')
+		close(enter)
+	}()
+
+	if policy.Mode == "timeout" {
+		fmt.Println("\n" + api.Tf("All operations completed. Closing in %d seconds, press Enter to close now...", int(policy.Timeout/time.Second)))
+		select {
+		case <-enter:
+		case <-time.After(policy.Timeout):
+		}
+	} else {
+		fmt.Println("\n" + api.T("All operations completed. Press Enter to close..."))
+		<-enter
+	}
+
+	state.TerminalOpen = false
+	if err := WriteDaemonTerminalState(statusFile, state); err != nil {
+		fmt.Printf("Warning: failed to write terminal state: %v\n", err)
+	}
+}
@@ -19,11 +19,15 @@
 // This replaces the bash gui script functionality with native Go and GTK3.
 // SPDX-License-Identifier: GPL-3.0-or-later
 
+//go:build !nogui
+
 // GUI entrypoint for Pi-Apps Go
 package gui
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
@@ -32,6 +36,8 @@ import (
 	"runtime"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"charm.land/log/v2"
@@ -58,14 +64,29 @@ type GUI struct {
 	daemon           *PreloadDaemon
 	ctx              context.Context
 	cancel           context.CancelFunc
-	currentApps      []AppListItem // Store current apps by index for reliable access
-	widgetCount      int           // Track number of widgets created for memory management
+	currentApps      []AppListItem         // Store current apps by index for reliable access
+	appNameLabels    map[string]*gtk.Label // Name labels of the app rows shown, so status changes can update them in place
+	widgetCount      int                   // Track number of widgets created for memory management
+	state            *GUIStateStore        // Window size and last viewed category and app, restored on the next launch
+	categoryNames    []string              // Categories shown in the category list
+	remote           *api.RemoteSession    // Pi-Apps of another machine the GUI runs against, nil for the local one
+	remoteOnline     atomic.Bool           // the connection to the remote machine works
+	actionButtons    []*gtk.Button         // install and uninstall buttons of the details window, disabled while offline
+	app              *gtk.Application      // owns the main window and the actions of the keyboard shortcuts
+	viewList         *gtk.ListBox          // list of the view shown, the keyboard shortcuts act on its selected app
+	shortcuts        []Shortcut            // keyboard shortcuts with the keymap file applied
+
+	icons            *iconLoader                  // loads the icons of the list rows off the main thread
+	iconImages       map[iconRequest][]*gtk.Image // images of the rows shown that wait for their icon
+	iconPixbufs      map[iconRequest]*gdk.Pixbuf  // icons loaded before, rows shown again get them right away
+	placeholderIcons map[int]*gdk.Pixbuf          // none-24.png in each size, shown until the icon is loaded
 }
 
 // GUIConfig holds configuration for the GUI
 type GUIConfig struct {
 	Directory string
 	GuiMode   string
+	Remote    *api.RemoteSession // in remote mode, Directory is the mirror of the remote catalog
 }
 
 // WindowGeometry holds window position and size information
@@ -76,6 +97,13 @@ type WindowGeometry struct {
 	YOffset int
 }
 
+// processStart is when the GUI process started, the time to the first paint is measured from it
+var processStart = time.Now()
+
+// FirstPaintEnv makes the GUI print the milliseconds from the start to the first paint of the main window and
+// quit, to measure the startup time
+const FirstPaintEnv = "PI_APPS_GUI_FIRST_PAINT"
+
 var logger = log.NewWithOptions(os.Stderr, log.Options{
 	ReportCaller:    true,
 	ReportTimestamp: true,
@@ -110,22 +138,33 @@ func NewGUI(config GUIConfig) (*GUI, error) {
 		currentPrefix: "",
 		ctx:           ctx,
 		cancel:        cancel,
+		appNameLabels: make(map[string]*gtk.Label),
+		remote:        config.Remote,
 	}
+	gui.remoteOnline.Store(config.Remote != nil)
+	gui.icons = newIconLoader(scaledIconCacheDir(config.Directory), iconLoaderWorkers(), gui.iconLoaded)
+	gui.iconImages = make(map[iconRequest][]*gtk.Image)
+	gui.iconPixbufs = make(map[iconRequest]*gdk.Pixbuf)
+	gui.placeholderIcons = make(map[int]*gdk.Pixbuf)
 
 	return gui, nil
 }
 
 // Initialize sets up the GUI environment and dependencies
 func (g *GUI) Initialize() error {
-	// Check if running as root
-	if os.Getuid() == 0 {
-		return fmt.Errorf("Pi-Apps is not designed to be run as root! Please try again as a regular user")
-	}
-
 	// Set GUI format version
 	os.Setenv("GUI_FORMAT_VERSION", "2")
 	os.Setenv("PI_APPS_DIR", g.directory)
 
+	// Settings files with values their setting doesn't accept are reset before anything reads them
+	fixes, err := api.RepairSettings(g.directory)
+	for _, fix := range fixes {
+		logger.Warn(api.Tf("Reset the %s setting to %q, %q is not one of its values", fix.Name, fix.Default, fix.Value))
+	}
+	if err != nil {
+		logger.Warn(err.Error())
+	}
+
 	// Do not initialize GTK and app name for non-native modes
 	if g.guiMode == "native" || g.guiMode == "gtk" || g.guiMode == "default" || g.guiMode == "yad-default" {
 		// Initialize app name
@@ -133,6 +172,7 @@ func (g *GUI) Initialize() error {
 
 		// Initialize GTK
 		gtk.Init(nil)
+		applyHighContrastTheme()
 
 		// Get screen dimensions
 		if err := g.getScreenDimensions(); err != nil {
@@ -199,6 +239,9 @@ func (g *GUI) Cleanup() {
 	if g.daemon != nil {
 		g.daemon.Stop()
 	}
+	if g.icons != nil {
+		g.icons.Stop()
+	}
 	if g.window != nil {
 		g.window.Destroy()
 	}
@@ -209,6 +252,13 @@ func (g *GUI) Cleanup() {
 
 // getScreenDimensions gets the current screen dimensions using screenshot library with fallbacks
 func (g *GUI) getScreenDimensions() error {
+	// The screenshot library and xrandr only see the XWayland screen on Wayland, ask GTK first
+	if api.DisplaySessionInfo().IsWayland() {
+		if err := g.getScreenDimensionsGTK(); err == nil {
+			return nil
+		}
+	}
+
 	// Try to get screen dimensions using screenshot library first
 	bounds := screenshot.GetDisplayBounds(0)
 	if bounds.Dx() > 0 && bounds.Dy() > 0 {
@@ -271,6 +321,11 @@ func (g *GUI) getScreenDimensions() error {
 	}
 
 	// Final fallback to GTK method if both screenshot and xrandr fail
+	return g.getScreenDimensionsGTK()
+}
+
+// getScreenDimensionsGTK gets the screen dimensions of the primary monitor from GTK
+func (g *GUI) getScreenDimensionsGTK() error {
 	display, err := gdk.DisplayGetDefault()
 	if err != nil {
 		logger.Error("failed to get default display: %w", err)
@@ -293,15 +348,15 @@ func (g *GUI) getScreenDimensions() error {
 // createDirectories creates necessary directories
 func (g *GUI) createDirectories() error {
 	dirs := []string{
-		"data/status",
-		"data/update-status",
-		"data/preload",
-		"data/settings",
-		"data/categories",
+		"status",
+		"update-status",
+		"preload",
+		"settings",
+		"categories",
 	}
 
 	for _, dir := range dirs {
-		path := filepath.Join(g.directory, dir)
+		path := filepath.Join(api.DataDirOf(g.directory), dir)
 		if err := os.MkdirAll(path, 0755); err != nil {
 			logger.Error("failed to create directory %s: %w", path, err)
 			return fmt.Errorf("failed to create directory %s: %w", path, err)
@@ -313,11 +368,19 @@ func (g *GUI) createDirectories() error {
 
 // startBackgroundTasks starts background operations
 func (g *GUI) startBackgroundTasks() {
-	// Start updater status check
-	go func() {
-		cmd := exec.Command(filepath.Join(g.directory, "updater"), "set-status")
-		cmd.Run() // Ignore errors, this is background
-	}()
+	// The remote machine checks for its own updates, the GUI only follows its apps
+	if g.remote != nil {
+		go g.watchRemote()
+	} else {
+		// Start updater status check
+		go func() {
+			cmd := exec.Command(filepath.Join(g.directory, "updater"), "set-status")
+			cmd.Run() // Ignore errors, this is background
+		}()
+	}
+
+	// Update status badges live when the daemon changes the status of an app
+	go g.watchAppStatuses()
 
 	// Usage tracking
 	go func() {
@@ -327,6 +390,103 @@ func (g *GUI) startBackgroundTasks() {
 	}()
 }
 
+// watchAppStatuses updates the rows of the apps whose status changed
+func (g *GUI) watchAppStatuses() {
+	events := api.WatchAppStatuses(g.ctx)
+
+	// Status changes come in bursts (e.g. a queue of apps), so only update once they settle down
+	var mu sync.Mutex
+	changed := make(map[string]string)
+	var refresh *time.Timer
+	for event := range events {
+		logger.Debug(fmt.Sprintf("Status of %s changed to %s", event.App, event.Status))
+		mu.Lock()
+		changed[event.App] = string(event.Status)
+		mu.Unlock()
+		if refresh != nil {
+			refresh.Stop()
+		}
+		refresh = time.AfterFunc(500*time.Millisecond, func() {
+			mu.Lock()
+			statuses := changed
+			changed = make(map[string]string)
+			mu.Unlock()
+			glib.IdleAdd(func() {
+				if g.window != nil {
+					g.updateAppRows(statuses)
+				}
+			})
+		})
+	}
+}
+
+// updateAppRows updates the status shown in the rows of the given apps in place
+//
+// The Installed category is refreshed instead, since its apps come and go with their status.
+func (g *GUI) updateAppRows(statuses map[string]string) {
+	if g.currentPrefix == "Installed" {
+		g.refreshCurrentView()
+		return
+	}
+	for app, status := range statuses {
+		label, ok := g.appNameLabels[app]
+		if !ok {
+			continue
+		}
+		label.SetMarkup(appNameMarkup(app, status))
+		for i := range g.currentApps {
+			if g.currentApps[i].Name == app {
+				g.currentApps[i].Status = status
+			}
+		}
+	}
+}
+
+// appNameMarkup returns the name of an app colored by its status, with the status appended unless it is uninstalled
+func appNameMarkup(name, status string) string {
+	var color, symbol string
+	switch status {
+	case "installed":
+		color = "#00AA00" // Green
+		symbol = api.SymbolSuccess
+	case "uninstalled":
+		color = "#CC3333" // Red
+	case "corrupted":
+		color = "#888800" // Yellow
+		symbol = api.SymbolWarning
+	case "disabled":
+		color = "#FF0000" // Bright red
+		symbol = api.SymbolFailure
+	default:
+		color = "#FFFFFF" // Default white
+	}
+
+	nameText := name
+	if status != "" && status != "uninstalled" {
+		nameText = fmt.Sprintf("%s (%s)", name, status)
+	}
+	nameText = withStatusSymbol(symbol, nameText)
+	// Package-apps with a held package keep the installed color, the padlock tells they won't be updated
+	if status == "installed" && api.AppHeld(name) {
+		nameText = fmt.Sprintf("%s (%s) 🔒", withStatusSymbol(symbol, name), api.StatusInstalledHeld)
+	}
+
+	// The HighContrast theme has a light background, so darker colors in bold are used and the theme's own
+	// text color for apps without status
+	if highContrastEnabled() {
+		switch status {
+		case "installed":
+			return fmt.Sprintf("<span foreground='#005000' weight='bold'>%s</span>", nameText)
+		case "uninstalled", "disabled":
+			return fmt.Sprintf("<span foreground='#A00000'>%s</span>", nameText)
+		case "corrupted":
+			return fmt.Sprintf("<span foreground='#604000' weight='bold'>%s</span>", nameText)
+		}
+		return nameText
+	}
+	return fmt.Sprintf("<span foreground='%s'>%s</span>", color, nameText)
+}
+
 // runPreloadDaemonMode runs the preload daemon mode
 func (g *GUI) runPreloadDaemonMode() error {
 	logger.Info("Starting preload daemon mode")
@@ -366,6 +526,30 @@ func (g *GUI) runPreloadDaemonOnceMode() error {
 func (g *GUI) runNativeMode() error {
 	logger.Debug("runNativeMode: Starting GTK3 interface")
 
+	// A GtkApplication owns the main window, so the keyboard shortcuts can be declared as its actions
+	app, err := gtk.ApplicationNew(applicationID, glib.APPLICATION_NON_UNIQUE)
+	if err != nil {
+		return fmt.Errorf("failed to create application: %w", err)
+	}
+	g.app = app
+
+	var windowErr error
+	app.Connect("activate", func() {
+		if windowErr = g.createMainWindow(); windowErr != nil {
+			app.Quit()
+		}
+	})
+
+	// Start GTK main loop, it returns when the main window is closed
+	logger.Debug("runNativeMode: Starting GTK main loop")
+	app.Run(nil)
+
+	logger.Debug("runNativeMode: GTK main loop exited")
+	return windowErr
+}
+
+// createMainWindow creates the main window of the application and shows it
+func (g *GUI) createMainWindow() error {
 	// Create main window
 	window, err := gtk.WindowNew(gtk.WINDOW_TOPLEVEL)
 	if err != nil {
@@ -375,7 +559,7 @@ func (g *GUI) runNativeMode() error {
 	g.window = window
 	logger.Debug("runNativeMode: Window created successfully")
 
-	window.SetTitle("Pi-Apps")
+	window.SetTitle(g.windowTitle())
 
 	// Set window size based on screen resolution (matching bash logic)
 	// Bash uses: small (<=1000 || <=600) = 250x400, large = 320x600 for the main list window
@@ -395,8 +579,22 @@ func (g *GUI) runNativeMode() error {
 			g.screenWidth, g.screenHeight, windowWidth, windowHeight))
 	}
 
+	// Reopen the window the way the last session left it
+	g.state = NewGUIStateStore(g.directory)
+	restore := g.state.State()
+	if restore.Width > 0 && restore.Height > 0 {
+		windowWidth, windowHeight = restore.Width, restore.Height
+		if g.screenWidth > 0 && g.screenHeight > 0 {
+			windowWidth = min(windowWidth, g.screenWidth)
+			windowHeight = min(windowHeight, g.screenHeight)
+		}
+	}
+
 	window.SetDefaultSize(windowWidth, windowHeight)
 	window.SetPosition(gtk.WIN_POS_CENTER)
+	if restore.Maximized {
+		window.Maximize()
+	}
 	window.SetResizable(true)
 	logger.Debug(fmt.Sprintf("runNativeMode: Window size set to %dx%d\n", windowWidth, windowHeight))
 
@@ -450,21 +648,68 @@ func (g *GUI) runNativeMode() error {
 	window.Add(vbox)
 
 	// Connect signals
+	window.Connect("configure-event", func() bool {
+		// The size of a maximized window is the screen size, keep the size to unmaximize to
+		if !window.IsMaximized() {
+			width, height := window.GetSize()
+			g.state.Update(func(state *GUIState) {
+				state.Width, state.Height = width, height
+			})
+		}
+		return false
+	})
+	window.Connect("window-state-event", func(_ *gtk.Window, event *gdk.Event) bool {
+		maximized := gdk.EventWindowStateNewFromEvent(event).NewWindowState()&gdk.WINDOW_STATE_MAXIMIZED != 0
+		g.state.Update(func(state *GUIState) {
+			state.Maximized = maximized
+		})
+		return false
+	})
 	window.Connect("destroy", func() {
 		logger.Debug("runNativeMode: Window destroy signal received")
+		g.state.Close()
 		g.Cleanup()
-		gtk.MainQuit()
+		g.app.Quit()
+	})
+	g.app.AddWindow(window)
+	g.setupShortcuts()
+
+	// Report the time to the first paint, icons still loading don't hold it up
+	painted := false
+	window.Connect("draw", func() bool {
+		if painted {
+			return false
+		}
+		painted = true
+		elapsed := time.Since(processStart)
+		logger.Debug(fmt.Sprintf("runNativeMode: First paint %v after start", elapsed.Round(time.Millisecond)))
+		if os.Getenv(FirstPaintEnv) != "" {
+			fmt.Println(elapsed.Milliseconds())
+			glib.IdleAdd(window.Destroy)
+		}
+		return false
 	})
 
 	// Show window
 	logger.Debug("runNativeMode: Showing window...")
 	window.ShowAll()
 
-	// Start GTK main loop
-	logger.Debug("runNativeMode: Starting GTK main loop")
-	gtk.Main()
+	// Open the category and app the last session ended on, unless they were removed meanwhile
+	restore = restore.withoutMissing(g.categoryNames, g.getSubcategories(restore.Category), api.IsValidApp)
+	glib.IdleAdd(func() {
+		g.restoreView(restore)
+	})
+
+	// Offer to refresh apps broken by an OS release upgrade once the main window is up
+	glib.IdleAdd(func() {
+		g.checkOSUpgrade()
+	})
+
+	// Offer to import the state of the bash version of Pi-Apps when it's installed next to this one
+	glib.IdleAdd(func() {
+		g.checkLegacyImport()
+	})
 
-	logger.Debug("runNativeMode: GTK main loop exited")
 	return nil
 }
 
@@ -529,6 +774,7 @@ func (g *GUI) createAppInfoHeader(parent *gtk.Box) error {
 func (g *GUI) showCategoryListView() error {
 	// Clear existing content
 	g.clearContentContainer()
+	g.rememberView("", "")
 
 	// Create scrolled window for the list
 	scrolled, err := gtk.ScrolledWindowNew(nil, nil)
@@ -553,8 +799,8 @@ func (g *GUI) showCategoryListView() error {
 	}
 
 	// Check if updates are available (matching bash logic)
-	updatableFilesPath := filepath.Join(g.directory, "data", "update-status", "updatable-files")
-	updatableAppsPath := filepath.Join(g.directory, "data", "update-status", "updatable-apps")
+	updatableFilesPath := filepath.Join(api.DataDirOf(g.directory), "update-status", "updatable-files")
+	updatableAppsPath := filepath.Join(api.DataDirOf(g.directory), "update-status", "updatable-apps")
 
 	updatesAvailable := false
 	if stat, err := os.Stat(updatableFilesPath); err == nil && stat.Size() > 0 {
@@ -574,7 +820,7 @@ func (g *GUI) showCategoryListView() error {
 	}
 
 	// Check if there are any deprecated apps
-	deprecatedDir := filepath.Join(g.directory, "data", "deprecated-apps")
+	deprecatedDir := filepath.Join(api.DataDirOf(g.directory), "deprecated-apps")
 	hasDeprecatedApps := false
 	if entries, err := os.ReadDir(deprecatedDir); err == nil {
 		for _, entry := range entries {
@@ -621,7 +867,20 @@ func (g *GUI) showCategoryListView() error {
 		}{"Deprecated", "Deprecated.png", "Apps that have been deprecated but can still be uninstalled."})
 	}
 
+	// Kiosk mode leaves out the categories without an app it shows
+	if visible := g.kioskVisibleCategories(); visible != nil {
+		kept := categories[:0]
+		for _, category := range categories {
+			if category.name == "Updates" || visible[category.name] {
+				kept = append(kept, category)
+			}
+		}
+		categories = kept
+	}
+
+	g.categoryNames = g.categoryNames[:0]
 	for _, category := range categories {
+		g.categoryNames = append(g.categoryNames, category.name)
 		row, err := g.createCategoryRow(category.name, category.icon, category.description)
 		if err != nil {
 			continue // Skip on error
@@ -643,6 +902,7 @@ func (g *GUI) showCategoryListView() error {
 
 	// Show the new content
 	g.contentContainer.ShowAll()
+	g.focusViewList(listBox)
 
 	return nil
 }
@@ -665,9 +925,13 @@ func (g *GUI) clearContentContainer() {
 	})
 
 	// Clear our app row data map since rows are being destroyed
+	g.viewList = nil
 	if g.currentApps != nil {
 		g.currentApps = []AppListItem{}
 	}
+	g.appNameLabels = make(map[string]*gtk.Label)
+	g.iconImages = make(map[iconRequest][]*gtk.Image)
+	g.icons.Cancel()
 
 	// Process pending GTK events to ensure widgets are fully cleaned up
 	for gtk.EventsPending() {
@@ -780,6 +1044,8 @@ func (g *GUI) showCategoryAppsView(category string) error {
 		gtk.MainIterationDo(false)
 	}
 
+	g.rememberView(category, "")
+
 	// Check if this category has subcategories
 	subcategories := g.getSubcategories(category)
 
@@ -878,14 +1144,39 @@ func (g *GUI) showCategoryAppsView(category string) error {
 	}
 
 	scrolled.Add(listBox)
+	g.prioritizeVisibleIcons(scrolled, listBox)
 	g.contentContainer.PackStart(scrolled, true, true, 0)
 
 	// Show the new content
 	g.contentContainer.ShowAll()
+	g.focusViewList(listBox)
 
 	return nil
 }
 
+// rememberView records the category and subcategory shown, so the next launch opens them again
+func (g *GUI) rememberView(category, subcategory string) {
+	if g.state == nil {
+		return
+	}
+	g.state.Update(func(state *GUIState) {
+		state.Category, state.Subcategory = category, subcategory
+	})
+}
+
+// restoreView opens the category, subcategory and app details of a saved GUI state
+func (g *GUI) restoreView(state GUIState) {
+	if state.Category != "" {
+		g.onCategorySelected(state.Category)
+		if state.Subcategory != "" {
+			g.showSubcategoryAppsView(state.Category, state.Subcategory)
+		}
+	}
+	if state.App != "" {
+		g.showAppDetails(state.App)
+	}
+}
+
 // onAppSelectionChanged handles app selection changes
 func (g *GUI) onAppSelectionChanged() {
 	// This could be used to show app info in a side panel
@@ -896,7 +1187,7 @@ func (g *GUI) onSearchClicked() {
 	logger.Info("Search button clicked, creating custom search dialog")
 
 	// Load the last search query if available
-	lastSearchFile := filepath.Join(g.directory, "data", "last-search")
+	lastSearchFile := filepath.Join(api.DataDirOf(g.directory), "last-search")
 	lastSearch := ""
 	if data, err := os.ReadFile(lastSearchFile); err == nil {
 		lastSearch = strings.TrimSpace(string(data))
@@ -917,6 +1208,7 @@ func (g *GUI) onSearchClicked() {
 	dialog.SetDefaultSize(310, 200)
 
 	// Add buttons manually to ensure both appear
+	dialog.AddButton(api.T("Suggest an app"), responseSuggestApp)
 	dialog.AddButton("_Cancel", gtk.RESPONSE_CANCEL)
 	dialog.AddButton("_Search", gtk.RESPONSE_OK)
 	dialog.SetDefaultResponse(gtk.RESPONSE_OK) // Make Search the default button
@@ -977,6 +1269,7 @@ func (g *GUI) onSearchClicked() {
 
 	contentArea.PackStart(mainBox, true, true, 0)
 	dialog.ShowAll()
+	searchEntry.GrabFocus()
 
 	// Handle Enter key in search entry
 	searchEntry.Connect("activate", func() {
@@ -984,6 +1277,12 @@ func (g *GUI) onSearchClicked() {
 	})
 
 	response := dialog.Run()
+	if response == responseSuggestApp {
+		searchText, _ := searchEntry.GetText()
+		dialog.Hide()
+		g.showSuggestAppDialog(strings.TrimSpace(searchText))
+		return
+	}
 	if response == gtk.RESPONSE_OK {
 		searchText, err := searchEntry.GetText()
 		if err == nil && searchText != "" {
@@ -1010,6 +1309,10 @@ func (g *GUI) onSearchClicked() {
 
 // onSettingsClicked handles settings button clicks
 func (g *GUI) onSettingsClicked() {
+	if !g.kioskAllows(api.KioskSettings) {
+		return
+	}
+
 	// Hide the main window while settings is open
 	g.window.Hide()
 
@@ -1111,6 +1414,12 @@ func (g *GUI) showAppDetails(appPath string) {
 		return
 	}
 
+	// Missing optional files leave fields empty, deprecated apps have no app folder and no metadata at all
+	metadata, err := api.GetAppMetadata(appName)
+	if err != nil {
+		metadata = &api.AppMetadata{Name: appName, IconPaths: map[int]string{}}
+	}
+
 	// Create details window (for non-xlunch modes)
 	window, err := gtk.WindowNew(gtk.WINDOW_TOPLEVEL)
 	if err != nil {
@@ -1120,7 +1429,25 @@ func (g *GUI) showAppDetails(appPath string) {
 	g.detailsWindow = window
 	logger.Debug("GTK details window created successfully")
 
+	// Remember the app while its details are open, so the next launch shows them again
+	if g.state != nil {
+		g.state.Update(func(state *GUIState) {
+			state.App = appName
+		})
+		window.Connect("destroy", func() {
+			g.state.Update(func(state *GUIState) {
+				if state.App == appName {
+					state.App = ""
+				}
+			})
+		})
+	}
+
 	window.SetTitle(fmt.Sprintf("Details of %s", appName))
+	g.actionButtons = nil
+	window.Connect("destroy", func() {
+		g.actionButtons = nil
+	})
 	window.SetDefaultSize(500, 400)
 
 	// Only set parent window if we're not in xlunch mode
@@ -1167,7 +1494,7 @@ func (g *GUI) showAppDetails(appPath string) {
 				}
 			}
 		} else {
-			iconPath = filepath.Join(g.directory, "apps", appName, "icon-64.png")
+			iconPath = metadata.IconPaths[64]
 		}
 		if iconPath != "" {
 			if _, err := os.Stat(iconPath); err == nil {
@@ -1310,12 +1637,12 @@ func (g *GUI) showAppDetails(appPath string) {
 			}
 
 			// Website link
-			if website := g.getAppWebsite(appName); website != "" {
+			creditsFile := filepath.Join(g.directory, "apps", appName, "credits")
+			if website := metadata.Website; website != "" {
 				websiteLabel, err := gtk.LabelNew("")
 				if err == nil {
-					// Check if credits file exists to add it on the same line
-					creditsFile := filepath.Join(g.directory, "apps", appName, "credits")
-					if _, err := os.Stat(creditsFile); err == nil {
+					// Add the credits on the same line if the app has any
+					if metadata.Credits != "" {
 						// Website + Credits on same line
 						websiteLabel.SetMarkup(fmt.Sprintf("- Website: <a href='%s'>%s</a> | <a href='file://%s'>Credits</a>", website, website, creditsFile))
 					} else {
@@ -1327,8 +1654,7 @@ func (g *GUI) showAppDetails(appPath string) {
 				}
 			} else {
 				// No website, but check for standalone credits link
-				creditsFile := filepath.Join(g.directory, "apps", appName, "credits")
-				if _, err := os.Stat(creditsFile); err == nil {
+				if metadata.Credits != "" {
 					creditsLabel, err := gtk.LabelNew("")
 					if err == nil {
 						creditsLabel.SetMarkup(fmt.Sprintf("- <a href='file://%s'>Credits</a>", creditsFile))
@@ -1434,48 +1760,45 @@ func (g *GUI) showAppDetails(appPath string) {
 
 		// Edit button (if "Show Edit button" setting is enabled and app is not deprecated)
 		// Deprecated apps cannot be edited since they're no longer in the repository
-		if !api.IsDeprecatedApp(appName) {
-			editSettingFile := filepath.Join(g.directory, "data", "settings", "Show Edit button")
-			if editSetting, err := os.ReadFile(editSettingFile); err == nil {
-				if strings.TrimSpace(string(editSetting)) == "Yes" {
-					editBtn, err := gtk.ButtonNewWithLabel("Edit")
-					if err == nil {
-						// Add edit icon to button
-						editIcon := filepath.Join(g.directory, "icons", "edit.png")
-						if pixbuf, err := gdk.PixbufNewFromFileAtSize(editIcon, 18, 18); err == nil {
-							if img, err := gtk.ImageNewFromPixbuf(pixbuf); err == nil {
-								editBtn.SetImage(img)
-								editBtn.SetAlwaysShowImage(true)
-							}
+		if !api.IsDeprecatedApp(appName) && g.kioskAllows(api.KioskCreateApp) {
+			if api.ReadSettingIn(g.directory, "Show Edit button") == "Yes" {
+				editBtn, err := gtk.ButtonNewWithLabel("Edit")
+				if err == nil {
+					// Add edit icon to button
+					editIcon := filepath.Join(g.directory, "icons", "edit.png")
+					if pixbuf, err := gdk.PixbufNewFromFileAtSize(editIcon, 18, 18); err == nil {
+						if img, err := gtk.ImageNewFromPixbuf(pixbuf); err == nil {
+							editBtn.SetImage(img)
+							editBtn.SetAlwaysShowImage(true)
 						}
-						editBtn.SetTooltipText("Make changes to the app")
-						editBtn.Connect("clicked", func() {
-							window.Destroy() // Close details window
-							g.detailsWindow = nil
-							// Run api createapp with the app name to edit it
-							go func() {
-								// Check for multi-call binary first, then fall back to api binary
-								var apiScript string
-								var args []string
-								if multiCallBinary := os.Getenv("PI_APPS_MULTI_CALL_BINARY"); multiCallBinary != "" {
-									apiScript = multiCallBinary
-									args = []string{"api", "createapp", appName}
-								} else {
-									apiScript = filepath.Join(g.directory, "api")
-									args = []string{"createapp", appName}
-								}
-								cmd := exec.Command(apiScript, args...)
-								cmd.Dir = g.directory
-								cmd.Env = append(os.Environ(), "PI_APPS_DIR="+g.directory)
-								cmd.Run()
-								// After createapp exits, refresh the view
-								glib.IdleAdd(func() {
-									g.refreshCurrentView()
-								})
-							}()
-						})
-						buttonBox.PackStart(editBtn, false, false, 0)
 					}
+					editBtn.SetTooltipText("Make changes to the app")
+					editBtn.Connect("clicked", func() {
+						window.Destroy() // Close details window
+						g.detailsWindow = nil
+						// Run api createapp with the app name to edit it
+						go func() {
+							// Check for multi-call binary first, then fall back to api binary
+							var apiScript string
+							var args []string
+							if multiCallBinary := os.Getenv("PI_APPS_MULTI_CALL_BINARY"); multiCallBinary != "" {
+								apiScript = multiCallBinary
+								args = []string{"api", "createapp", appName}
+							} else {
+								apiScript = filepath.Join(g.directory, "api")
+								args = []string{"createapp", appName}
+							}
+							cmd := exec.Command(apiScript, args...)
+							cmd.Dir = g.directory
+							cmd.Env = append(os.Environ(), "PI_APPS_DIR="+g.directory)
+							cmd.Run()
+							// After createapp exits, refresh the view
+							glib.IdleAdd(func() {
+								g.refreshCurrentView()
+							})
+						}()
+					})
+					buttonBox.PackStart(editBtn, false, false, 0)
 				}
 			}
 		}
@@ -1484,28 +1807,31 @@ func (g *GUI) showAppDetails(appPath string) {
 		switch status {
 		case "installed":
 			// Only uninstall button for installed apps
-			uninstallBtn, err := gtk.ButtonNewWithLabel("Uninstall")
-			if err == nil {
-				// Add uninstall icon to button
-				uninstallIcon := filepath.Join(g.directory, "icons", "uninstall.png")
-				if pixbuf, err := gdk.PixbufNewFromFileAtSize(uninstallIcon, 18, 18); err == nil {
-					if img, err := gtk.ImageNewFromPixbuf(pixbuf); err == nil {
-						uninstallBtn.SetImage(img)
-						uninstallBtn.SetAlwaysShowImage(true)
+			if g.kioskAllows(api.KioskUninstall) {
+				uninstallBtn, err := gtk.ButtonNewWithLabel("Uninstall")
+				if err == nil {
+					// Add uninstall icon to button
+					uninstallIcon := filepath.Join(g.directory, "icons", "uninstall.png")
+					if pixbuf, err := gdk.PixbufNewFromFileAtSize(uninstallIcon, 18, 18); err == nil {
+						if img, err := gtk.ImageNewFromPixbuf(pixbuf); err == nil {
+							uninstallBtn.SetImage(img)
+							uninstallBtn.SetAlwaysShowImage(true)
+						}
 					}
+					uninstallBtn.Connect("clicked", func() {
+						window.Destroy() // Close details window immediately
+						g.detailsWindow = nil
+						go func() {
+							g.performAppAction(appName, "uninstall")
+							// After action completes, refresh main view
+							glib.IdleAdd(func() {
+								g.refreshCurrentView() // Refresh main app list to show updated status
+							})
+						}()
+					})
+					g.trackActionButton(uninstallBtn)
+					buttonBox.PackStart(uninstallBtn, false, false, 0)
 				}
-				uninstallBtn.Connect("clicked", func() {
-					window.Destroy() // Close details window immediately
-					g.detailsWindow = nil
-					go func() {
-						g.performAppAction(appName, "uninstall")
-						// After action completes, refresh main view
-						glib.IdleAdd(func() {
-							g.refreshCurrentView() // Refresh main app list to show updated status
-						})
-					}()
-				})
-				buttonBox.PackStart(uninstallBtn, false, false, 0)
 			}
 		case "uninstalled":
 			// Only install button for uninstalled apps
@@ -1530,6 +1856,7 @@ func (g *GUI) showAppDetails(appPath string) {
 						})
 					}()
 				})
+				g.trackActionButton(installBtn)
 				buttonBox.PackStart(installBtn, false, false, 0)
 			}
 		case "disabled":
@@ -1568,31 +1895,53 @@ func (g *GUI) showAppDetails(appPath string) {
 					})
 					buttonBox.PackStart(errorsBtn, false, false, 0)
 				}
+
+				// The repair assistant may uninstall the app, and the remote catalog server only queues installs and uninstalls
+				if g.remote == nil && g.kioskAllows(api.KioskUninstall) {
+					if repairBtn, err := gtk.ButtonNewWithLabel(api.T("Repair")); err == nil {
+						repairBtn.SetTooltipText(api.T("Find out what is wrong with this app and repair it"))
+						repairBtn.Connect("clicked", func() {
+							window.Destroy() // Close details window immediately
+							g.detailsWindow = nil
+							go func() {
+								g.repairApp(appName)
+								glib.IdleAdd(func() {
+									g.refreshCurrentView()
+								})
+							}()
+						})
+						g.trackActionButton(repairBtn)
+						buttonBox.PackStart(repairBtn, false, false, 0)
+					}
+				}
 			}
 
 			// Uninstall button
-			uninstallBtn, err := gtk.ButtonNewWithLabel("Uninstall")
-			if err == nil {
-				// Add uninstall icon to button
-				uninstallIcon := filepath.Join(g.directory, "icons", "uninstall.png")
-				if pixbuf, err := gdk.PixbufNewFromFileAtSize(uninstallIcon, 18, 18); err == nil {
-					if img, err := gtk.ImageNewFromPixbuf(pixbuf); err == nil {
-						uninstallBtn.SetImage(img)
-						uninstallBtn.SetAlwaysShowImage(true)
+			if g.kioskAllows(api.KioskUninstall) {
+				uninstallBtn, err := gtk.ButtonNewWithLabel("Uninstall")
+				if err == nil {
+					// Add uninstall icon to button
+					uninstallIcon := filepath.Join(g.directory, "icons", "uninstall.png")
+					if pixbuf, err := gdk.PixbufNewFromFileAtSize(uninstallIcon, 18, 18); err == nil {
+						if img, err := gtk.ImageNewFromPixbuf(pixbuf); err == nil {
+							uninstallBtn.SetImage(img)
+							uninstallBtn.SetAlwaysShowImage(true)
+						}
 					}
+					uninstallBtn.Connect("clicked", func() {
+						window.Destroy() // Close details window immediately
+						g.detailsWindow = nil
+						go func() {
+							g.performAppAction(appName, "uninstall")
+							// After action completes, refresh main view
+							glib.IdleAdd(func() {
+								g.refreshCurrentView() // Refresh main app list to show updated status
+							})
+						}()
+					})
+					g.trackActionButton(uninstallBtn)
+					buttonBox.PackStart(uninstallBtn, false, false, 0)
 				}
-				uninstallBtn.Connect("clicked", func() {
-					window.Destroy() // Close details window immediately
-					g.detailsWindow = nil
-					go func() {
-						g.performAppAction(appName, "uninstall")
-						// After action completes, refresh main view
-						glib.IdleAdd(func() {
-							g.refreshCurrentView() // Refresh main app list to show updated status
-						})
-					}()
-				})
-				buttonBox.PackStart(uninstallBtn, false, false, 0)
 			}
 
 			// Install button
@@ -1617,10 +1966,56 @@ func (g *GUI) showAppDetails(appPath string) {
 						})
 					}()
 				})
+				g.trackActionButton(installBtn)
 				buttonBox.PackStart(installBtn, false, false, 0)
 			}
 		}
 
+		// Apps that can be installed show how long that typically takes
+		if status != "installed" && status != "disabled" && !api.IsDeprecatedApp(appName) {
+			if estimate, confidence := api.EstimateInstallDuration(appName); confidence != "" {
+				if estimateLabel, err := gtk.LabelNew(api.Tf("Typically takes %s on this device (approximate)", api.FormatApproxDuration(estimate))); err == nil {
+					if confidence == api.EstimateConfidenceLow {
+						estimateLabel.SetTooltipText(api.T("Based on installs on other devices, scaled to the speed of this device"))
+					} else {
+						estimateLabel.SetTooltipText(api.T("Based on earlier installs of this app on this device"))
+					}
+					estimateLabel.SetHAlign(gtk.ALIGN_END)
+					vbox.PackStart(estimateLabel, false, false, 0)
+				}
+			}
+		}
+
+		// Installed apps declaring where their software is released show whether a newer release is out,
+		// checking it can take a moment so the label is filled in when the check is done
+		if status == "installed" {
+			if _, ok, err := api.ReadUpstreamCheck(appName); err == nil && ok {
+				if versionLabel, err := gtk.LabelNew(""); err == nil {
+					versionLabel.SetHAlign(gtk.ALIGN_END)
+					vbox.PackStart(versionLabel, false, false, 0)
+					go func() {
+						version, err := api.CheckUpstreamVersion(appName)
+						if err != nil {
+							logger.Debug(fmt.Sprintf("Failed to check the upstream version of %s: %v", appName, err))
+							return
+						}
+						var text string
+						switch {
+						case version.Outdated:
+							text = api.Tf("Installed %s → %s available", version.Installed, version.Latest)
+						case version.Installed != "":
+							text = api.Tf("Installed %s, the latest release", version.Installed)
+						default:
+							text = api.Tf("Latest release: %s", version.Latest)
+						}
+						glib.IdleAdd(func() {
+							versionLabel.SetText(text)
+						})
+					}()
+				}
+			}
+		}
+
 		vbox.PackStart(buttonBox, false, false, 0)
 	}
 
@@ -1666,7 +2061,7 @@ func (g *GUI) getAppStatus(appName string) string {
 func (g *GUI) getAppDescription(appName string) string {
 	// Check if it's a deprecated app first
 	if api.IsDeprecatedApp(appName) {
-		deprecatedDir := filepath.Join(g.directory, "data", "deprecated-apps", appName)
+		deprecatedDir := filepath.Join(api.DataDirOf(g.directory), "deprecated-apps", appName)
 		metadataFile := filepath.Join(deprecatedDir, "metadata")
 		if metadataData, err := os.ReadFile(metadataFile); err == nil {
 			// Parse metadata to get message if available
@@ -1683,9 +2078,8 @@ func (g *GUI) getAppDescription(appName string) string {
 		return "This app has been deprecated and removed from Pi-Apps."
 	}
 
-	descFile := filepath.Join(g.directory, "apps", appName, "description")
-	if data, err := os.ReadFile(descFile); err == nil {
-		return string(data)
+	if metadata, err := api.GetAppMetadata(appName); err == nil && metadata.LongDescription != "" {
+		return metadata.LongDescription
 	}
 	return "Description unavailable"
 }
@@ -1694,16 +2088,18 @@ func (g *GUI) getAppDescription(appName string) string {
 func (g *GUI) createAppInfoLabel(appName string) *gtk.Label {
 	var info []string
 
+	metadata, err := api.GetAppMetadata(appName)
+	if err != nil {
+		return nil
+	}
+
 	// Check for website
-	websiteFile := filepath.Join(g.directory, "apps", appName, "website")
-	if data, err := os.ReadFile(websiteFile); err == nil {
-		website := strings.TrimSpace(string(data))
-		info = append(info, fmt.Sprintf("Website: %s", website))
+	if metadata.Website != "" {
+		info = append(info, fmt.Sprintf("Website: %s", metadata.Website))
 	}
 
 	// Check if it's a package app
-	packagesFile := filepath.Join(g.directory, "apps", appName, "packages")
-	if _, err := os.Stat(packagesFile); err == nil {
+	if metadata.Type == "package" {
 		info = append(info, "This app installs system packages")
 	}
 
@@ -1723,35 +2119,13 @@ func (g *GUI) createAppInfoLabel(appName string) *gtk.Label {
 func (g *GUI) performAppAction(appName, action string) {
 	logger.Info(api.Tf("Performing %s action for app: %s\n", action, appName))
 
-	// Check if we're using the multi-call binary
-	var apiScript string
-	var args []string
-
-	if multiCallBinary := os.Getenv("PI_APPS_MULTI_CALL_BINARY"); multiCallBinary != "" {
-		// Use multi-call binary: multi-call-pi-apps api terminal_manage action app
-		apiScript = multiCallBinary
-		args = []string{"api", "terminal_manage", action, appName}
-		logger.Info(api.Tf("Executing: %s api terminal_manage %s %s\n", apiScript, action, appName))
-	} else {
-		// Use separate binary: api terminal_manage action app
-		apiScript = filepath.Join(g.directory, "api")
-		args = []string{"terminal_manage", action, appName}
-		logger.Info(api.Tf("Executing: %s terminal_manage %s %s\n", apiScript, action, appName))
+	if g.remote != nil {
+		g.performRemoteAction(appName, action)
+		return
 	}
 
-	cmd := exec.Command(apiScript, args...)
-
-	// Set environment variables that might be needed
-	cmd.Env = append(os.Environ(),
-		"DIRECTORY="+g.directory,
-		"PI_APPS_DIR="+g.directory,
-		"GUI_FORMAT_VERSION=2",
-	)
-
-	// Ensure the multi-call binary environment variable is passed through
-	if multiCallBinary := os.Getenv("PI_APPS_MULTI_CALL_BINARY"); multiCallBinary != "" {
-		cmd.Env = append(cmd.Env, "PI_APPS_MULTI_CALL_BINARY="+multiCallBinary)
-	}
+	cmd := g.apiCommand("terminal_manage", action, appName)
+	logger.Info(api.Tf("Executing: %s\n", strings.Join(cmd.Args, " ")))
 
 	if err := cmd.Run(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error running %s for %s: %v\n", action, appName, err)
@@ -1772,9 +2146,49 @@ func (g *GUI) performAppAction(appName, action string) {
 	}
 }
 
+// apiCommand returns the command running the api with args, through the multi-call binary if Pi-Apps uses it
+func (g *GUI) apiCommand(args ...string) *exec.Cmd {
+	var cmd *exec.Cmd
+	if multiCallBinary := os.Getenv("PI_APPS_MULTI_CALL_BINARY"); multiCallBinary != "" {
+		cmd = exec.Command(multiCallBinary, append([]string{"api"}, args...)...)
+	} else {
+		cmd = exec.Command(filepath.Join(g.directory, "api"), args...)
+	}
+
+	// Set environment variables that might be needed
+	cmd.Env = append(os.Environ(),
+		"DIRECTORY="+g.directory,
+		"PI_APPS_DIR="+g.directory,
+		"GUI_FORMAT_VERSION=2",
+	)
+	return cmd
+}
+
+// repairApp runs the repair assistant of a broken app, which asks how to repair it in a dialog of its own
+func (g *GUI) repairApp(appName string) {
+	logger.Info(api.Tf("Repairing %s\n", appName))
+	cmd := g.apiCommand("repair", appName)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error repairing %s: %v\n", appName, err)
+		// The api prints why it failed last
+		message := err.Error()
+		if output := strings.TrimSpace(api.RemoveAnsiEscapes(stderr.String())); output != "" {
+			message = output[strings.LastIndex(output, "\n")+1:]
+		}
+		glib.IdleAdd(func() {
+			dialog := gtk.MessageDialogNew(g.window, gtk.DIALOG_MODAL, gtk.MESSAGE_ERROR, gtk.BUTTONS_OK,
+				"%s", api.Tf("Failed to repair %s: %s", appName, message))
+			dialog.Run()
+			dialog.Destroy()
+		})
+	}
+}
+
 // GetMessageOfTheDay gets the current message of the day
 func (g *GUI) GetMessageOfTheDay() string {
-	announcementsFile := filepath.Join(g.directory, "data", "announcements")
+	announcementsFile := filepath.Join(api.DataDirOf(g.directory), "announcements")
 
 	// Check if file exists and is recent
 	if stat, err := os.Stat(announcementsFile); err != nil || time.Since(stat.ModTime()) > 24*time.Hour {
@@ -1814,7 +2228,7 @@ func (g *GUI) downloadAnnouncements() {
 		return
 	}
 
-	announcementsFile := filepath.Join(g.directory, "data", "announcements")
+	announcementsFile := filepath.Join(api.DataDirOf(g.directory), "announcements")
 	os.WriteFile(announcementsFile, output, 0644)
 }
 
@@ -1856,6 +2270,7 @@ func ShowMessageDialog(title, message string, dialogType int) {
 
 	// Initialize GTK if not already initialized
 	gtk.Init(nil)
+	applyHighContrastTheme()
 
 	// Create dialog
 	var msgType gtk.MessageType
@@ -1952,10 +2367,57 @@ func (g *GUI) createBottomButtons(parent *gtk.Box) error {
 
 	settingsBtn.Connect("clicked", g.onSettingsClicked)
 
+	// Toggle for apps that can't be installed on this system, it changes the "Unavailable apps" setting
+	unavailableSep, err := gtk.SeparatorNew(gtk.ORIENTATION_VERTICAL)
+	if err != nil {
+		return err
+	}
+	unavailableCheck, err := gtk.CheckButtonNewWithLabel(api.T("Show unavailable apps"))
+	if err != nil {
+		return err
+	}
+	unavailableCheck.SetActive(api.ShowUnavailableApps())
+	unavailableCheck.SetTooltipText(api.T("List apps made for another architecture, OS or device with an \"unavailable\" badge"))
+	unavailableCheck.SetMarginStart(8)
+	unavailableCheck.SetMarginEnd(8)
+	unavailableCheck.Connect("toggled", func() {
+		if err := api.SetShowUnavailableApps(unavailableCheck.GetActive()); err != nil {
+			logger.Error(fmt.Sprintf("Failed to save the Unavailable apps setting: %v\n", err))
+			return
+		}
+		g.refreshCurrentView()
+	})
+
+	// Kiosk mode hides the settings, and offers to unlock them when it has an admin PIN
+	if !g.kioskAllows(api.KioskSettings) {
+		settingsBtn.SetNoShowAll(true)
+		vertSep.SetNoShowAll(true)
+	}
+	unlockBtn, err := gtk.ButtonNewWithLabel(api.T("Unlock"))
+	if err != nil {
+		return err
+	}
+	unlockBtn.SetTooltipText(api.T("Lift the kiosk restrictions with the admin PIN"))
+	unlockBtn.SetNoShowAll(!g.kioskUnlockable())
+	unlockBtn.Connect("clicked", func() {
+		if !g.showKioskUnlockDialog() {
+			return
+		}
+		unlockBtn.Hide()
+		settingsBtn.SetNoShowAll(false)
+		vertSep.SetNoShowAll(false)
+		settingsBtn.Show()
+		vertSep.Show()
+		g.refreshCurrentView()
+	})
+
 	// Pack buttons with separator
 	buttonArea.PackStart(searchBtn, true, true, 0)
 	buttonArea.PackStart(vertSep, false, false, 0)
 	buttonArea.PackStart(settingsBtn, true, true, 0)
+	buttonArea.PackStart(unlockBtn, false, false, 0)
+	buttonArea.PackStart(unavailableSep, false, false, 0)
+	buttonArea.PackStart(unavailableCheck, false, false, 0)
 
 	// Add button area to parent
 	parent.PackStart(buttonArea, false, false, 0)
@@ -2042,53 +2504,155 @@ func (g *GUI) createAppRow(app AppListItem) (*gtk.ListBoxRow, error) {
 	hbox.SetMarginStart(8)
 	hbox.SetMarginEnd(8)
 
-	// Add app icon
-	iconPath := app.IconPath
-	if iconPath == "" || iconPath == "none-24.png" {
-		iconPath = filepath.Join(g.directory, "icons", "none-24.png")
-	}
-
-	// Load and scale the app icon
-	if pixbuf, err := gdk.PixbufNewFromFile(iconPath); err == nil {
-		if scaledPixbuf, err := pixbuf.ScaleSimple(24, 24, gdk.INTERP_BILINEAR); err == nil {
-			if image, err := gtk.ImageNewFromPixbuf(scaledPixbuf); err == nil {
-				hbox.PackStart(image, false, false, 0)
-			}
-		}
+	// Add app icon, loaded in the background
+	if image, err := g.newRowIcon(g.appIconPath(app), 24); err == nil {
+		hbox.PackStart(image, false, false, 0)
 	}
 
 	// App name label with status color (no description - shown on hover via tooltip)
 	nameLabel, err := gtk.LabelNew("")
 	if err == nil {
-		// Set text color based on status
-		var color string
-		switch app.Status {
-		case "installed":
-			color = "#00AA00" // Green
-		case "uninstalled":
-			color = "#CC3333" // Red
-		case "corrupted":
-			color = "#888800" // Yellow
-		case "disabled":
-			color = "#FF0000" // Bright red
-		default:
-			color = "#FFFFFF" // Default white
-		}
+		nameLabel.SetMarkup(appNameMarkup(app.Name, app.Status))
+		nameLabel.SetHAlign(gtk.ALIGN_START)
+		hbox.PackStart(nameLabel, true, true, 0)
+		g.appNameLabels[app.Name] = nameLabel
+	}
 
-		nameText := app.Name
-		if app.Status != "" && app.Status != "uninstalled" {
-			nameText = fmt.Sprintf("%s (%s)", app.Name, app.Status)
+	// Badge with the extra app repository the app comes from
+	if source := api.AppSource(app.Name); source != "" {
+		if sourceLabel, err := gtk.LabelNew(""); err == nil {
+			sourceLabel.SetMarkup(fmt.Sprintf("<small><span background='#d0d0d0' foreground='#303030'> %s </span></small>", glib.MarkupEscapeText(source)))
+			sourceLabel.SetTooltipText(api.Tf("From the app repository %s", source))
+			hbox.PackEnd(sourceLabel, false, false, 0)
 		}
+	}
 
-		nameLabel.SetMarkup(fmt.Sprintf("<span foreground='%s'>%s</span>", color, nameText))
-		nameLabel.SetHAlign(gtk.ALIGN_START)
-		hbox.PackStart(nameLabel, true, true, 0)
+	// Badge for apps that can't be installed on this system, shown when the user lists them
+	if app.Unavailable != "" {
+		if unavailableLabel, err := gtk.LabelNew(""); err == nil {
+			unavailableLabel.SetMarkup(fmt.Sprintf("<small><span background='#f0d0d0' foreground='#602020'> %s </span></small>", glib.MarkupEscapeText(api.T("unavailable"))))
+			unavailableLabel.SetTooltipText(api.Tf("Can't be installed on this system: %s", app.Unavailable))
+			hbox.PackEnd(unavailableLabel, false, false, 0)
+		}
 	}
 
 	row.Add(hbox)
 	return row, nil
 }
 
+// appIconPath returns the icon shown in the row of an app, none-24.png for apps without one
+func (g *GUI) appIconPath(app AppListItem) string {
+	if app.IconPath == "" || app.IconPath == "none-24.png" {
+		return filepath.Join(g.directory, "icons", "none-24.png")
+	}
+	return app.IconPath
+}
+
+// newRowIcon returns the image of a list row icon. Icons loaded before are shown right away, others start as the
+// placeholder and are loaded by the icon loader, so the list shows without waiting for them.
+func (g *GUI) newRowIcon(path string, size int) (*gtk.Image, error) {
+	request := iconRequest{Path: path, Size: size}
+	if pixbuf, ok := g.iconPixbufs[request]; ok {
+		return gtk.ImageNewFromPixbuf(pixbuf)
+	}
+	image, err := gtk.ImageNew()
+	if err != nil {
+		return nil, err
+	}
+	if placeholder := g.placeholderIcon(size); placeholder != nil {
+		image.SetFromPixbuf(placeholder)
+	}
+	g.iconImages[request] = append(g.iconImages[request], image)
+	g.icons.Load(request)
+	return image, nil
+}
+
+// placeholderIcon returns none-24.png scaled to the given size, nil when it can't be loaded
+func (g *GUI) placeholderIcon(size int) *gdk.Pixbuf {
+	if pixbuf, ok := g.placeholderIcons[size]; ok {
+		return pixbuf
+	}
+	pixbuf, err := gdk.PixbufNewFromFileAtScale(filepath.Join(g.directory, "icons", "none-24.png"), size, size, false)
+	if err != nil {
+		logger.Warn(fmt.Sprintf("failed to load the placeholder icon: %v", err))
+		pixbuf = nil
+	}
+	g.placeholderIcons[size] = pixbuf
+	return pixbuf
+}
+
+// iconLoaded is called by the icon loader workers, it hands the icon over to the main thread
+func (g *GUI) iconLoaded(request iconRequest, icon scaledIcon, err error) {
+	if errors.Is(err, errIconLoaderStopped) {
+		return
+	}
+	glib.IdleAdd(func() {
+		g.showLoadedIcon(request, icon, err)
+	})
+}
+
+// showLoadedIcon replaces the placeholder of the rows waiting for an icon. Icons the loader can't decode are left
+// to GdkPixbuf, which knows more formats.
+func (g *GUI) showLoadedIcon(request iconRequest, icon scaledIcon, err error) {
+	var pixbuf *gdk.Pixbuf
+	if err == nil {
+		pixbuf, err = pixbufFromScaledIcon(icon)
+	} else {
+		pixbuf, err = gdk.PixbufNewFromFileAtScale(request.Path, request.Size, request.Size, false)
+	}
+	if err != nil {
+		logger.Debug(fmt.Sprintf("failed to load icon %s: %v", request.Path, err))
+		pixbuf = g.placeholderIcon(request.Size)
+	}
+	if pixbuf == nil {
+		return
+	}
+	g.iconPixbufs[request] = pixbuf
+	for _, image := range g.iconImages[request] {
+		image.SetFromPixbuf(pixbuf)
+	}
+	delete(g.iconImages, request)
+}
+
+// pixbufFromScaledIcon copies a scaled icon into a pixbuf, whose rows can be longer than the icon's
+func pixbufFromScaledIcon(icon scaledIcon) (*gdk.Pixbuf, error) {
+	pixbuf, err := gdk.PixbufNew(gdk.COLORSPACE_RGB, true, 8, icon.Width, icon.Height)
+	if err != nil {
+		return nil, err
+	}
+	pixels := pixbuf.GetPixels()
+	rowstride := pixbuf.GetRowstride()
+	rowLength := icon.Width * 4
+	for y := 0; y < icon.Height; y++ {
+		copy(pixels[y*rowstride:y*rowstride+rowLength], icon.Pixels[y*rowLength:(y+1)*rowLength])
+	}
+	return pixbuf, nil
+}
+
+// prioritizeVisibleIcons loads the icons of the app rows in view first, and again whenever the list scrolls or
+// is resized
+func (g *GUI) prioritizeVisibleIcons(scrolled *gtk.ScrolledWindow, listBox *gtk.ListBox) {
+	adjustment := scrolled.GetVAdjustment()
+	prioritize := func() {
+		top := adjustment.GetValue()
+		first := listBox.GetRowAtY(int(top))
+		if first == nil {
+			return
+		}
+		last := len(g.currentApps) - 1
+		if row := listBox.GetRowAtY(int(top + adjustment.GetPageSize())); row != nil {
+			last = row.GetIndex()
+		}
+		var visible []iconRequest
+		for index := max(first.GetIndex(), 0); index <= last && index < len(g.currentApps); index++ {
+			visible = append(visible, iconRequest{Path: g.appIconPath(g.currentApps[index]), Size: 24})
+		}
+		g.icons.Prioritize(visible)
+	}
+	adjustment.Connect("value-changed", prioritize)
+	adjustment.Connect("changed", prioritize)
+}
+
 // getAppNameFromRow retrieves the app name from a row using index
 func (g *GUI) getAppNameFromRow(row *gtk.ListBoxRow) string {
 	if g.currentApps != nil {
@@ -2100,15 +2664,6 @@ func (g *GUI) getAppNameFromRow(row *gtk.ListBoxRow) string {
 	return ""
 }
 
-// getAppWebsite gets the website URL for an app
-func (g *GUI) getAppWebsite(appName string) string {
-	websiteFile := filepath.Join(g.directory, "apps", appName, "website")
-	if data, err := os.ReadFile(websiteFile); err == nil {
-		return strings.TrimSpace(string(data))
-	}
-	return ""
-}
-
 // hasInstallScript checks if an app has install scripts (only for standard script-based apps)
 func (g *GUI) hasInstallScript(appName string) bool {
 	// Don't show scripts button for package-based or flatpak-based apps
@@ -2207,6 +2762,7 @@ func (g *GUI) showSubcategoryAppsView(category, subcategory string) {
 
 	// Clear existing content first
 	g.clearContentContainer()
+	g.rememberView(category, subcategory)
 
 	// Force garbage collection
 	g.widgetCount++
@@ -2305,10 +2861,12 @@ func (g *GUI) showSubcategoryAppsView(category, subcategory string) {
 	})
 
 	scrolled.Add(listBox)
+	g.prioritizeVisibleIcons(scrolled, listBox)
 	g.contentContainer.PackStart(scrolled, true, true, 0)
 
 	// Show the new content
 	g.contentContainer.ShowAll()
+	g.focusViewList(listBox)
 }
 
 // createSubcategoryRow creates a row for a subcategory
@@ -2467,7 +3025,7 @@ func (g *GUI) openAppScripts(appName string) {
 
 // enableApp enables a disabled app by removing its status file
 func (g *GUI) enableApp(appName string) {
-	statusFile := filepath.Join(g.directory, "data", "status", appName)
+	statusFile := filepath.Join(api.DataDirOf(g.directory), "status", appName)
 	if err := os.Remove(statusFile); err != nil {
 		logger.Error(fmt.Sprintf("Failed to enable app %s: %v\n", appName, err))
 	} else {
@@ -2478,58 +3036,41 @@ func (g *GUI) enableApp(appName string) {
 // viewAppErrors shows the error log for a failed app
 func (g *GUI) viewAppErrors(appName string) {
 	// Find the most recent error log for this app (matching original bash logic)
-	logsDir := filepath.Join(g.directory, "logs")
-	if entries, err := os.ReadDir(logsDir); err == nil {
-		var latestLog string
-		var latestTime time.Time
-
-		for _, entry := range entries {
-			fileName := entry.Name()
-			// Look for logs that contain the app name and are error logs (fail or incomplete)
-			// Pattern: {action}-{fail|incomplete}-{appName}.log
-			if strings.Contains(fileName, appName) &&
-				(strings.Contains(fileName, "-fail-") || strings.Contains(fileName, "-incomplete-")) &&
-				strings.HasSuffix(fileName, ".log") {
-				if info, err := entry.Info(); err == nil {
-					if info.ModTime().After(latestTime) {
-						latestTime = info.ModTime()
-						latestLog = filepath.Join(logsDir, entry.Name())
-					}
-				}
-			}
-		}
+	latestLog := api.LatestAppLog(api.LogsDirOf(g.directory), appName, true)
+	if latestLog == "" {
+		// Show message if no log found
+		dialog := gtk.MessageDialogNew(
+			g.detailsWindow,
+			gtk.DIALOG_MODAL,
+			gtk.MESSAGE_INFO,
+			gtk.BUTTONS_OK,
+			"No error log found for %s",
+			appName,
+		)
+		defer dialog.Destroy()
+		dialog.Run()
+		return
+	}
+	g.openLogViewer(latestLog)
+	logger.Info(fmt.Sprintf("Viewing error log for %s: %s\n", appName, latestLog))
+}
 
-		if latestLog != "" {
-			// Open log file with logviewer command like original
-			var cmd *exec.Cmd
-			if multiCallBinary := os.Getenv("PI_APPS_MULTI_CALL_BINARY"); multiCallBinary != "" {
-				// Use multi-call binary
-				cmd = exec.Command(multiCallBinary, "api", "logviewer", latestLog)
-			} else {
-				// Use separate binary
-				cmd = exec.Command(filepath.Join(g.directory, "api-go"), "logviewer", latestLog)
-			}
+// openLogViewer opens a log file with the logviewer command like the original
+func (g *GUI) openLogViewer(logFile string) {
+	var cmd *exec.Cmd
+	if multiCallBinary := os.Getenv("PI_APPS_MULTI_CALL_BINARY"); multiCallBinary != "" {
+		// Use multi-call binary
+		cmd = exec.Command(multiCallBinary, "api", "logviewer", logFile)
+	} else {
+		// Use separate binary
+		cmd = exec.Command(filepath.Join(g.directory, "api-go"), "logviewer", logFile)
+	}
 
-			if err := cmd.Start(); err != nil {
-				logger.Error(fmt.Sprintf("Failed to open log viewer: %v\n", err))
-				// Fallback: open in text editor
-				fallbackCmd := exec.Command("xdg-open", latestLog)
-				fallbackCmd.Start()
-			}
-			logger.Info(fmt.Sprintf("Viewing error log for %s: %s\n", appName, latestLog))
-		} else {
-			// Show message if no log found
-			dialog := gtk.MessageDialogNew(
-				g.detailsWindow,
-				gtk.DIALOG_MODAL,
-				gtk.MESSAGE_INFO,
-				gtk.BUTTONS_OK,
-				"No error log found for %s",
-				appName,
-			)
-			defer dialog.Destroy()
-			dialog.Run()
-		}
+	if err := cmd.Start(); err != nil {
+		logger.Error(fmt.Sprintf("Failed to open log viewer: %v\n", err))
+		// Fallback: open in text editor
+		fallbackCmd := exec.Command("xdg-open", logFile)
+		fallbackCmd.Start()
 	}
 }
 
@@ -2618,10 +3159,12 @@ func (g *GUI) showSearchResults(query string, results []string) {
 	})
 
 	scrolled.Add(listBox)
+	g.prioritizeVisibleIcons(scrolled, listBox)
 	g.contentContainer.PackStart(scrolled, true, true, 0)
 
 	// Show the new content
 	g.contentContainer.ShowAll()
+	g.focusViewList(listBox)
 }
 
 // populateSearchResults populates the search results list
@@ -2632,6 +3175,12 @@ func (g *GUI) populateSearchResults(listBox *gtk.ListBox, results []string) {
 		logger.Error(fmt.Sprintf("Failed to read category files: %v", err))
 	}
 
+	// Read the status of every app at once instead of once per result
+	statuses, err := api.GetAllAppStatuses()
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to get app statuses: %v", err))
+	}
+
 	// Convert search results to AppListItem format
 	var searchApps []AppListItem
 	for _, appName := range results {
@@ -2655,13 +3204,13 @@ func (g *GUI) populateSearchResults(listBox *gtk.ListBox, results []string) {
 		}
 
 		// Get app status
-		status, err := api.GetAppStatus(appName)
-		if err != nil {
+		status := string(statuses[appName])
+		if status == "" {
 			status = "uninstalled"
 		}
 
 		// Get app description (first line only, like the original)
-		descFile := filepath.Join(g.directory, "apps", appName, "description")
+		descFile := api.LocalizedDescriptionPath(filepath.Join(g.directory, "apps", appName))
 		description := "Description unavailable"
 		if descData, err := os.ReadFile(descFile); err == nil {
 			lines := strings.Split(string(descData), "\n")
@@ -2732,47 +3281,18 @@ func (g *GUI) createSearchResultRow(app AppListItem, appName string, categoryEnt
 	hbox.SetMarginStart(8)
 	hbox.SetMarginEnd(8)
 
-	// Add app icon
-	iconPath := app.IconPath
-	if iconPath == "" || iconPath == "none-24.png" {
-		iconPath = filepath.Join(g.directory, "icons", "none-24.png")
-	}
-
-	// Load and scale the app icon
-	if pixbuf, err := gdk.PixbufNewFromFile(iconPath); err == nil {
-		if scaledPixbuf, err := pixbuf.ScaleSimple(24, 24, gdk.INTERP_BILINEAR); err == nil {
-			if image, err := gtk.ImageNewFromPixbuf(scaledPixbuf); err == nil {
-				hbox.PackStart(image, false, false, 0)
-			}
-		}
+	// Add app icon, loaded in the background
+	if image, err := g.newRowIcon(g.appIconPath(app), 24); err == nil {
+		hbox.PackStart(image, false, false, 0)
 	}
 
 	// App name label with status color (no description - shown on hover via tooltip)
 	nameLabel, err := gtk.LabelNew("")
 	if err == nil {
-		// Set text color based on status
-		var color string
-		switch app.Status {
-		case "installed":
-			color = "#00AA00" // Green
-		case "uninstalled":
-			color = "#CC3333" // Red
-		case "corrupted":
-			color = "#888800" // Yellow
-		case "disabled":
-			color = "#FF0000" // Bright red
-		default:
-			color = "#FFFFFF" // Default white
-		}
-
-		nameText := app.Name
-		if app.Status != "" && app.Status != "uninstalled" {
-			nameText = fmt.Sprintf("%s (%s)", app.Name, app.Status)
-		}
-
-		nameLabel.SetMarkup(fmt.Sprintf("<span foreground='%s'>%s</span>", color, nameText))
+		nameLabel.SetMarkup(appNameMarkup(app.Name, app.Status))
 		nameLabel.SetHAlign(gtk.ALIGN_START)
 		hbox.PackStart(nameLabel, true, true, 0)
+		g.appNameLabels[app.Name] = nameLabel
 	}
 
 	// Add spacer
@@ -2813,12 +3333,8 @@ func (g *GUI) createSearchResultRow(app AppListItem, appName string, categoryEnt
 		}
 	}
 
-	if pixbuf, err := gdk.PixbufNewFromFile(categoryIconPath); err == nil {
-		if scaledPixbuf, err := pixbuf.ScaleSimple(16, 16, gdk.INTERP_BILINEAR); err == nil {
-			if categoryIcon, err := gtk.ImageNewFromPixbuf(scaledPixbuf); err == nil {
-				hbox.PackStart(categoryIcon, false, false, 0)
-			}
-		}
+	if categoryIcon, err := g.newRowIcon(categoryIconPath, 16); err == nil {
+		hbox.PackStart(categoryIcon, false, false, 0)
 	}
 
 	// Add category name - ensure it's always visible
@@ -2858,23 +3374,31 @@ func (g *GUI) performAdvancedSearch(query string, searchFiles []string) {
 	}
 
 	// Save the search query for next time (like the original bash version)
-	lastSearchFile := filepath.Join(g.directory, "data", "last-search")
+	lastSearchFile := filepath.Join(api.DataDirOf(g.directory), "last-search")
 	os.MkdirAll(filepath.Dir(lastSearchFile), 0755)
 	os.WriteFile(lastSearchFile, []byte(query), 0644)
 
 	// Handle search results
 	if len(results) == 0 {
-		// No results found
+		// No results found, offer to suggest the app unless this user already did
 		dialog := gtk.MessageDialogNew(
 			g.window,
 			gtk.DIALOG_MODAL,
 			gtk.MESSAGE_INFO,
-			gtk.BUTTONS_OK,
+			gtk.BUTTONS_NONE,
 			"No results found for \"%s\".",
 			query,
 		)
 		defer dialog.Destroy()
-		dialog.Run()
+		if _, suggested := api.SuggestedBefore(query); !suggested {
+			dialog.FormatSecondaryText("%s", api.T("Would you like Pi-Apps to have it? Suggest the app to the Pi-Apps team."))
+			dialog.AddButton(api.T("Suggest an app"), responseSuggestApp)
+		}
+		dialog.AddButton("_OK", gtk.RESPONSE_OK)
+		if dialog.Run() == responseSuggestApp {
+			dialog.Hide()
+			g.showSuggestAppDialog(query)
+		}
 		return
 	}
 
@@ -0,0 +1,157 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: gui_state.go
+// Description: Remembers the window size and the last viewed category and app of the app browser between launches.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package gui
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/pi-apps-go/pi-apps/pkg/api"
+)
+
+// guiStateFile is the name of the GUI state file in data/settings
+const guiStateFile = "gui-state.json"
+
+// guiStateSaveDelay is how long the state store waits for more changes before writing them
+const guiStateSaveDelay = 500 * time.Millisecond
+
+// GUIState is the state of the app browser that is restored on the next launch
+type GUIState struct {
+	Width       int    `json:"width,omitempty"`
+	Height      int    `json:"height,omitempty"`
+	Maximized   bool   `json:"maximized,omitempty"`
+	Category    string `json:"category,omitempty"`    // category whose apps are shown, empty for the category list
+	Subcategory string `json:"subcategory,omitempty"` // subcategory of Category whose apps are shown
+	App         string `json:"app,omitempty"`         // app whose details are shown
+}
+
+// parseGUIState parses the content of a GUI state file
+func parseGUIState(data []byte) (GUIState, error) {
+	var state GUIState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return GUIState{}, err
+	}
+	if state.Width < 0 || state.Height < 0 {
+		state.Width, state.Height = 0, 0
+	}
+	return state, nil
+}
+
+// withoutMissing drops the parts of the state that point to categories or apps that don't exist anymore
+func (state GUIState) withoutMissing(categories []string, subcategories []string, appExists func(string) bool) GUIState {
+	if state.Category != "" && !slices.Contains(categories, state.Category) {
+		state.Category = ""
+	}
+	if state.Category == "" || !slices.Contains(subcategories, state.Subcategory) {
+		state.Subcategory = ""
+	}
+	if state.App != "" && !appExists(state.App) {
+		state.App = ""
+	}
+	return state
+}
+
+// GUIStateStore keeps the GUI state and writes it to data/settings/gui-state.json shortly after it changes
+type GUIStateStore struct {
+	path   string
+	mu     sync.Mutex
+	state  GUIState
+	timer  *time.Timer
+	dirty  bool // the state changed since it was last written
+	closed bool
+}
+
+// NewGUIStateStore loads the GUI state of a Pi-Apps directory. A missing or corrupt state file gives an empty state.
+func NewGUIStateStore(directory string) *GUIStateStore {
+	store := &GUIStateStore{path: filepath.Join(api.DataDirOf(directory), "settings", guiStateFile)}
+	if data, err := os.ReadFile(store.path); err == nil {
+		if state, err := parseGUIState(data); err == nil {
+			store.state = state
+		}
+	}
+	return store
+}
+
+// State returns the current GUI state
+func (s *GUIStateStore) State() GUIState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state
+}
+
+// Update changes the GUI state and schedules writing it, so a burst of changes like resizing the window is written once
+func (s *GUIStateStore) Update(change func(state *GUIState)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	previous := s.state
+	change(&s.state)
+	if s.state == previous {
+		return
+	}
+	s.dirty = true
+	if s.timer != nil {
+		s.timer.Stop()
+	}
+	s.timer = time.AfterFunc(guiStateSaveDelay, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if !s.closed && s.dirty {
+			s.save()
+		}
+	})
+}
+
+// Close writes pending changes and ignores changes made afterwards, like the ones from windows destroyed on shutdown
+func (s *GUIStateStore) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	s.closed = true
+	if s.timer != nil {
+		s.timer.Stop()
+	}
+	if s.dirty {
+		s.save()
+	}
+}
+
+// save writes the state file, errors are only logged since the state is a convenience
+func (s *GUIStateStore) save() {
+	s.dirty = false
+	data, err := json.MarshalIndent(s.state, "", "  ")
+	if err != nil {
+		return
+	}
+	// Written in place instead of renamed over, so the settings directory keeps its modification time
+	// and the preloaded app lists stay valid
+	if err := os.WriteFile(s.path, append(data, '\n'), 0644); err != nil {
+		api.Debug("Failed to save GUI state: " + err.Error())
+	}
+}
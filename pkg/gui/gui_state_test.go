@@ -0,0 +1,157 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package gui
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newTestStateDir creates a Pi-Apps directory with a settings directory, optionally with a GUI state file
+func newTestStateDir(t *testing.T, content string) string {
+	t.Helper()
+	directory := t.TempDir()
+	settings := filepath.Join(directory, "data", "settings")
+	if err := os.MkdirAll(settings, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if content != "" {
+		if err := os.WriteFile(filepath.Join(settings, guiStateFile), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return directory
+}
+
+func TestGUIStateSerialization(t *testing.T) {
+	state := GUIState{Width: 900, Height: 600, Maximized: true, Category: "Games", Subcategory: "Emulation", App: "Box64 (x86_64)"}
+	data, err := json.Marshal(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := parseGUIState(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed != state {
+		t.Errorf("parseGUIState(json.Marshal(state)) = %+v, want %+v", parsed, state)
+	}
+
+	// Empty fields are left out, so a default state is an empty object
+	if data, _ := json.Marshal(GUIState{}); string(data) != "{}" {
+		t.Errorf("default state = %s, want {}", data)
+	}
+
+	if parsed, err := parseGUIState([]byte(`{"width": -5, "height": 300}`)); err != nil || parsed.Width != 0 || parsed.Height != 0 {
+		t.Errorf("negative size = %+v, %v, want the default size", parsed, err)
+	}
+	if _, err := parseGUIState([]byte(`{"width": `)); err == nil {
+		t.Error("parseGUIState accepted a truncated file")
+	}
+}
+
+func TestGUIStateWithoutMissing(t *testing.T) {
+	categories := []string{"Games", "Tools"}
+	subcategories := []string{"Emulation"}
+	appExists := func(app string) bool { return app == "Zoom" }
+
+	tests := []struct {
+		name  string
+		state GUIState
+		want  GUIState
+	}{
+		{
+			"everything exists",
+			GUIState{Width: 800, Category: "Games", Subcategory: "Emulation", App: "Zoom"},
+			GUIState{Width: 800, Category: "Games", Subcategory: "Emulation", App: "Zoom"},
+		},
+		{
+			"removed category",
+			GUIState{Category: "Old", Subcategory: "Emulation", App: "Zoom"},
+			GUIState{App: "Zoom"},
+		},
+		{
+			"removed subcategory",
+			GUIState{Category: "Games", Subcategory: "Old"},
+			GUIState{Category: "Games"},
+		},
+		{
+			"removed app",
+			GUIState{Height: 600, Category: "Tools", App: "Deleted App"},
+			GUIState{Height: 600, Category: "Tools"},
+		},
+	}
+	for _, tt := range tests {
+		if got := tt.state.withoutMissing(categories, subcategories, appExists); got != tt.want {
+			t.Errorf("%s: withoutMissing = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestGUIStateStoreIgnoresBadFiles(t *testing.T) {
+	for name, content := range map[string]string{
+		"missing": "",
+		"corrupt": "{\"width\": 8",
+		"binary":  "\x00\xff\x10",
+	} {
+		store := NewGUIStateStore(newTestStateDir(t, content))
+		if state := store.State(); state != (GUIState{}) {
+			t.Errorf("%s state file gave %+v, want the default state", name, state)
+		}
+		store.Close()
+	}
+}
+
+func TestGUIStateStoreSaves(t *testing.T) {
+	directory := newTestStateDir(t, `{"width": 640, "height": 480}`)
+	path := filepath.Join(directory, "data", "settings", guiStateFile)
+
+	store := NewGUIStateStore(directory)
+	if state := store.State(); state.Width != 640 || state.Height != 480 {
+		t.Fatalf("loaded state = %+v, want 640x480", state)
+	}
+
+	// A burst of changes is written once after the delay
+	for width := 700; width <= 900; width += 100 {
+		store.Update(func(state *GUIState) { state.Width = width })
+	}
+	if data, _ := os.ReadFile(path); string(data) != `{"width": 640, "height": 480}` {
+		t.Errorf("the state was written before the delay: %s", data)
+	}
+	deadline := time.Now().Add(10 * guiStateSaveDelay)
+	for {
+		data, _ := os.ReadFile(path)
+		if state, err := parseGUIState(data); err == nil && state.Width == 900 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("the state was not written after the delay: %s", data)
+		}
+		time.Sleep(guiStateSaveDelay / 5)
+	}
+
+	// Close writes pending changes right away and ignores later ones
+	store.Update(func(state *GUIState) { state.App = "Zoom" })
+	store.Close()
+	store.Update(func(state *GUIState) { state.App = "Other" })
+	if state := NewGUIStateStore(directory).State(); state.App != "Zoom" || state.Width != 900 {
+		t.Errorf("state after Close = %+v, want Zoom at width 900", state)
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: high_contrast.go
+// Description: Switches the Pi-Apps windows to a high contrast theme and picks status colors and symbols that don't rely on telling colors apart.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+//go:build !nogui
+
+package gui
+
+import (
+	"sync"
+
+	"github.com/gotk3/gotk3/gdk"
+	"github.com/gotk3/gotk3/gtk"
+	"github.com/pi-apps-go/pi-apps/pkg/api"
+)
+
+// highContrastCSS makes the focus and selection of rows visible without relying on their color
+const highContrastCSS = `
+list row:selected, treeview:selected {
+	outline: 2px solid;
+	outline-offset: -2px;
+}
+*:focus {
+	outline-width: 2px;
+}
+`
+
+// highContrastEnabled reports whether the "High contrast theme" setting is Yes
+var highContrastEnabled = sync.OnceValue(func() bool {
+	return api.ReadSetting("High contrast theme") == "Yes"
+})
+
+// applyHighContrastTheme switches GTK to its built-in HighContrast theme and adds the Pi-Apps stylesheet for it
+// if the "High contrast theme" setting is Yes. It has to be called after gtk.Init.
+func applyHighContrastTheme() {
+	if !highContrastEnabled() {
+		return
+	}
+	if settings, err := gtk.SettingsGetDefault(); err == nil {
+		settings.SetProperty("gtk-theme-name", "HighContrast")
+	}
+	screen, err := gdk.ScreenGetDefault()
+	if err != nil {
+		return
+	}
+	provider, err := gtk.CssProviderNew()
+	if err != nil {
+		return
+	}
+	if err := provider.LoadFromData(highContrastCSS); err != nil {
+		api.Debug("Failed to load the high contrast stylesheet: " + err.Error())
+		return
+	}
+	gtk.AddProviderForScreen(screen, provider, gtk.STYLE_PROVIDER_PRIORITY_APPLICATION)
+}
+
+// statusSymbolsShown reports whether app and queue statuses get a symbol next to their color
+func statusSymbolsShown() bool {
+	return highContrastEnabled() || api.StatusSymbolsEnabled()
+}
+
+// withStatusSymbol puts a status symbol in front of a text if status symbols are shown
+func withStatusSymbol(symbol, text string) string {
+	if !statusSymbolsShown() || symbol == "" {
+		return text
+	}
+	return symbol + " " + text
+}
@@ -0,0 +1,261 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: icon_loader.go
+// Description: Loads the icons of the app list in a pool of workers off the GTK main thread, through an on-disk
+// cache of icons already scaled to the size shown.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package gui
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
+	"sync"
+
+	"github.com/pi-apps-go/pi-apps/pkg/api"
+	"golang.org/x/image/draw"
+)
+
+// scaledIconMagic starts every file of the scaled icon cache, the version changes with the file layout
+const scaledIconMagic = "piicon01"
+
+// scaledIconHeaderSize is the magic, the modification time and size of the source and the width and height
+const scaledIconHeaderSize = len(scaledIconMagic) + 8 + 8 + 4 + 4
+
+// iconRequest is an icon to show in the given size, in pixels on both sides
+type iconRequest struct {
+	Path string
+	Size int
+}
+
+// scaledIcon is an icon scaled to the size it is shown in, as non-premultiplied RGBA rows without padding
+type scaledIcon struct {
+	Width  int
+	Height int
+	Pixels []byte
+}
+
+// scaledIconCacheDir is the folder of the scaled icon cache, one folder per size
+func scaledIconCacheDir(directory string) string {
+	return filepath.Join(api.DataDirOf(directory), "cache", "scaled-icons")
+}
+
+// scaledIconCachePath is where the scaled icon of a source file is cached. It is named after the source path, the
+// modification time and size of the source in the file tell whether it is still current.
+func scaledIconCachePath(cacheDir string, request iconRequest) string {
+	sum := sha256.Sum256([]byte(request.Path))
+	return filepath.Join(cacheDir, strconv.Itoa(request.Size), hex.EncodeToString(sum[:16]))
+}
+
+// loadScaledIcon returns the icon scaled to the requested size. An icon cached since the source last changed is
+// read as is, any other is decoded, scaled and written to the cache for the next start.
+func loadScaledIcon(cacheDir string, request iconRequest) (scaledIcon, error) {
+	info, err := os.Stat(request.Path)
+	if err != nil {
+		return scaledIcon{}, err
+	}
+	cachePath := scaledIconCachePath(cacheDir, request)
+	if icon, ok := readScaledIcon(cachePath, info); ok {
+		return icon, nil
+	}
+
+	icon, err := decodeScaledIcon(request)
+	if err != nil {
+		return scaledIcon{}, err
+	}
+	// An icon that can't be cached only costs the next start decoding it again
+	_ = writeScaledIcon(cachePath, info, icon)
+	return icon, nil
+}
+
+// decodeScaledIcon decodes an icon and scales it to the requested size, the way the app list loaded icons before
+// the cache
+func decodeScaledIcon(request iconRequest) (scaledIcon, error) {
+	file, err := os.Open(request.Path)
+	if err != nil {
+		return scaledIcon{}, err
+	}
+	defer file.Close()
+	source, _, err := image.Decode(file)
+	if err != nil {
+		return scaledIcon{}, fmt.Errorf("failed to decode %s: %w", request.Path, err)
+	}
+	scaled := image.NewNRGBA(image.Rect(0, 0, request.Size, request.Size))
+	draw.BiLinear.Scale(scaled, scaled.Bounds(), source, source.Bounds(), draw.Src, nil)
+	return scaledIcon{Width: request.Size, Height: request.Size, Pixels: scaled.Pix}, nil
+}
+
+// readScaledIcon reads a cached icon, ok is false when there is none or it was scaled from an older source
+func readScaledIcon(cachePath string, source os.FileInfo) (icon scaledIcon, ok bool) {
+	content, err := os.ReadFile(cachePath)
+	if err != nil || len(content) < scaledIconHeaderSize || string(content[:len(scaledIconMagic)]) != scaledIconMagic {
+		return scaledIcon{}, false
+	}
+	header := content[len(scaledIconMagic):]
+	if int64(binary.LittleEndian.Uint64(header[0:])) != source.ModTime().UnixNano() ||
+		int64(binary.LittleEndian.Uint64(header[8:])) != source.Size() {
+		return scaledIcon{}, false
+	}
+	icon.Width = int(binary.LittleEndian.Uint32(header[16:]))
+	icon.Height = int(binary.LittleEndian.Uint32(header[20:]))
+	icon.Pixels = content[scaledIconHeaderSize:]
+	if len(icon.Pixels) != icon.Width*icon.Height*4 {
+		return scaledIcon{}, false
+	}
+	return icon, true
+}
+
+// writeScaledIcon caches an icon along with the modification time and size of the source it was scaled from
+func writeScaledIcon(cachePath string, source os.FileInfo, icon scaledIcon) error {
+	if err := api.EnsureDirOwned(filepath.Dir(cachePath), api.InvokingUser, api.InvokingUser); err != nil {
+		return err
+	}
+	var content bytes.Buffer
+	content.Grow(scaledIconHeaderSize + len(icon.Pixels))
+	content.WriteString(scaledIconMagic)
+	content.Write(binary.LittleEndian.AppendUint64(nil, uint64(source.ModTime().UnixNano())))
+	content.Write(binary.LittleEndian.AppendUint64(nil, uint64(source.Size())))
+	content.Write(binary.LittleEndian.AppendUint32(nil, uint32(icon.Width)))
+	content.Write(binary.LittleEndian.AppendUint32(nil, uint32(icon.Height)))
+	content.Write(icon.Pixels)
+	return writeFileReplacing(cachePath, content.Bytes())
+}
+
+// errIconLoaderStopped is delivered for the icons still waiting when the loader is stopped
+var errIconLoaderStopped = errors.New("icon loader stopped")
+
+// iconLoader loads icons in a pool of workers, in the order they were asked for unless some are prioritized.
+// deliver is called from the workers, the GUI hands the icons over to the main thread with glib.IdleAdd.
+type iconLoader struct {
+	cacheDir string
+	deliver  func(request iconRequest, icon scaledIcon, err error)
+
+	mutex   sync.Mutex
+	wake    *sync.Cond
+	pending []iconRequest        // icons waiting for a worker, the first is loaded next
+	known   map[iconRequest]bool // icons pending or being loaded, so asking twice loads them once
+	stopped bool
+	workers sync.WaitGroup
+}
+
+// iconLoaderWorkers leaves a core to the GTK main thread, and stops at 4 as the disk is the limit beyond that
+func iconLoaderWorkers() int {
+	return max(1, min(runtime.NumCPU()-1, 4))
+}
+
+// newIconLoader starts the workers of an icon loader caching scaled icons in cacheDir
+func newIconLoader(cacheDir string, workers int, deliver func(request iconRequest, icon scaledIcon, err error)) *iconLoader {
+	loader := &iconLoader{
+		cacheDir: cacheDir,
+		deliver:  deliver,
+		known:    make(map[iconRequest]bool),
+	}
+	loader.wake = sync.NewCond(&loader.mutex)
+	for range max(1, workers) {
+		loader.workers.Add(1)
+		go loader.work()
+	}
+	return loader
+}
+
+// Load queues an icon behind the ones asked for before
+func (l *iconLoader) Load(request iconRequest) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.stopped || l.known[request] {
+		return
+	}
+	l.known[request] = true
+	l.pending = append(l.pending, request)
+	l.wake.Signal()
+}
+
+// Prioritize moves the given icons that are still waiting to the front of the queue, in the order given. The GUI
+// calls it with the icons of the rows in view whenever the list scrolls.
+func (l *iconLoader) Prioritize(requests []iconRequest) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	first := make([]iconRequest, 0, len(requests))
+	for _, request := range requests {
+		if index := slices.Index(l.pending, request); index >= 0 {
+			first = append(first, request)
+			l.pending = slices.Delete(l.pending, index, index+1)
+		}
+	}
+	l.pending = append(first, l.pending...)
+}
+
+// Cancel drops the icons still waiting, those of a view the GUI left
+func (l *iconLoader) Cancel() {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	for _, request := range l.pending {
+		delete(l.known, request)
+	}
+	l.pending = nil
+}
+
+// Stop drops the icons still waiting and returns once the workers finished the icons they were loading
+func (l *iconLoader) Stop() {
+	l.mutex.Lock()
+	l.stopped = true
+	l.pending = nil
+	l.wake.Broadcast()
+	l.mutex.Unlock()
+	l.workers.Wait()
+}
+
+// work loads icons until the loader is stopped
+func (l *iconLoader) work() {
+	defer l.workers.Done()
+	for {
+		l.mutex.Lock()
+		for len(l.pending) == 0 && !l.stopped {
+			l.wake.Wait()
+		}
+		if l.stopped {
+			l.mutex.Unlock()
+			return
+		}
+		request := l.pending[0]
+		l.pending = l.pending[1:]
+		l.mutex.Unlock()
+
+		icon, err := loadScaledIcon(l.cacheDir, request)
+
+		l.mutex.Lock()
+		delete(l.known, request)
+		stopped := l.stopped
+		l.mutex.Unlock()
+		if stopped {
+			err = errIconLoaderStopped
+		}
+		l.deliver(request, icon, err)
+	}
+}
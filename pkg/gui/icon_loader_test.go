@@ -0,0 +1,238 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package gui
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+// writeTestIcon writes a square PNG icon of the given size and color
+func writeTestIcon(t testing.TB, path string, size int, fill color.NRGBA) {
+	t.Helper()
+	icon := image.NewNRGBA(image.Rect(0, 0, size, size))
+	for i := 0; i < len(icon.Pix); i += 4 {
+		icon.Pix[i], icon.Pix[i+1], icon.Pix[i+2], icon.Pix[i+3] = fill.R, fill.G, fill.B, fill.A
+	}
+	var content bytes.Buffer
+	if err := png.Encode(&content, icon); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, content.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadScaledIcon(t *testing.T) {
+	directory := t.TempDir()
+	cacheDir := scaledIconCacheDir(directory)
+	source := filepath.Join(directory, "apps", "Alpha", "icon-64.png")
+	writeTestIcon(t, source, 64, color.NRGBA{R: 200, A: 255})
+	request := iconRequest{Path: source, Size: 24}
+
+	icon, err := loadScaledIcon(cacheDir, request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if icon.Width != 24 || icon.Height != 24 || len(icon.Pixels) != 24*24*4 {
+		t.Fatalf("icon is %dx%d with %d bytes, want 24x24 with %d", icon.Width, icon.Height, len(icon.Pixels), 24*24*4)
+	}
+	if !bytes.Equal(icon.Pixels[:4], []byte{200, 0, 0, 255}) {
+		t.Errorf("first pixel = %v, want the color of the source", icon.Pixels[:4])
+	}
+
+	// Mark the cached pixels, a cache hit returns them without decoding the source
+	cachePath := scaledIconCachePath(cacheDir, request)
+	cached, err := os.ReadFile(cachePath)
+	if err != nil {
+		t.Fatalf("the scaled icon was not cached: %v", err)
+	}
+	cached[scaledIconHeaderSize] = 1
+	if err := os.WriteFile(cachePath, cached, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if icon, err := loadScaledIcon(cacheDir, request); err != nil || icon.Pixels[0] != 1 {
+		t.Errorf("second load decoded the source again instead of reading the cache (err %v)", err)
+	}
+
+	// A source changed since is scaled again
+	writeTestIcon(t, source, 64, color.NRGBA{B: 200, A: 255})
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(source, later, later); err != nil {
+		t.Fatal(err)
+	}
+	icon, err = loadScaledIcon(cacheDir, request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(icon.Pixels[:4], []byte{0, 0, 200, 255}) {
+		t.Errorf("first pixel after the source changed = %v, want the new color", icon.Pixels[:4])
+	}
+
+	if _, err := loadScaledIcon(cacheDir, iconRequest{Path: filepath.Join(directory, "missing.png"), Size: 24}); err == nil {
+		t.Error("loading a missing icon did not fail")
+	}
+}
+
+func TestIconLoaderPrioritize(t *testing.T) {
+	directory := t.TempDir()
+	var requests []iconRequest
+	for _, name := range []string{"a", "b", "c", "d"} {
+		path := filepath.Join(directory, name+".png")
+		writeTestIcon(t, path, 8, color.NRGBA{A: 255})
+		requests = append(requests, iconRequest{Path: path, Size: 4})
+	}
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var order []string
+	loader := newIconLoader(scaledIconCacheDir(directory), 1, func(request iconRequest, icon scaledIcon, err error) {
+		if err != nil {
+			t.Errorf("loading %s: %v", request.Path, err)
+		}
+		order = append(order, filepath.Base(request.Path))
+		if len(order) == 1 {
+			close(started)
+			<-release
+		}
+	})
+
+	// The only worker holds a while b, c and d wait, then the rows of c and d scroll into view
+	loader.Load(requests[0])
+	<-started
+	for _, request := range requests[1:] {
+		loader.Load(request)
+	}
+	loader.Load(requests[1])
+	loader.Prioritize([]iconRequest{requests[3], requests[2]})
+	close(release)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		loader.mutex.Lock()
+		done := len(loader.known) == 0
+		loader.mutex.Unlock()
+		if done || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	loader.Stop()
+	if want := []string{"a.png", "d.png", "c.png", "b.png"}; !slices.Equal(order, want) {
+		t.Errorf("icons loaded in the order %v, want %v", order, want)
+	}
+}
+
+func TestIconLoaderCancel(t *testing.T) {
+	directory := t.TempDir()
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var loaded []string
+	loader := newIconLoader(scaledIconCacheDir(directory), 1, func(request iconRequest, icon scaledIcon, err error) {
+		loaded = append(loaded, filepath.Base(request.Path))
+		if len(loaded) == 1 {
+			close(started)
+			<-release
+		}
+	})
+	loader.Load(iconRequest{Path: filepath.Join(directory, "shown.png"), Size: 24})
+	<-started
+	loader.Load(iconRequest{Path: filepath.Join(directory, "left.png"), Size: 24})
+	loader.Cancel()
+	close(release)
+	loader.Stop()
+	if !slices.Equal(loaded, []string{"shown.png"}) {
+		t.Errorf("loaded %v after the view was left, want only the icon being loaded", loaded)
+	}
+}
+
+// newBenchmarkIcons creates 400 64 pixel app icons, about the size of the Pi-Apps catalog
+func newBenchmarkIcons(b *testing.B) (cacheDir string, requests []iconRequest) {
+	b.Helper()
+	directory := b.TempDir()
+	for i := range 400 {
+		path := filepath.Join(directory, "apps", fmt.Sprintf("App %d", i), "icon-64.png")
+		writeTestIcon(b, path, 64, color.NRGBA{R: uint8(i), G: 128, B: 64, A: 255})
+		requests = append(requests, iconRequest{Path: path, Size: 24})
+	}
+	return scaledIconCacheDir(directory), requests
+}
+
+// loadAllIcons loads the icons with a loader and returns once all were delivered
+func loadAllIcons(cacheDir string, requests []iconRequest) {
+	done := make(chan struct{}, len(requests))
+	loader := newIconLoader(cacheDir, iconLoaderWorkers(), func(iconRequest, scaledIcon, error) {
+		done <- struct{}{}
+	})
+	for _, request := range requests {
+		loader.Load(request)
+	}
+	for range requests {
+		<-done
+	}
+	loader.Stop()
+}
+
+// BenchmarkAppListIconsOnMainThread measures what the app list waited for before it was shown when icons were
+// decoded and scaled on the main thread: every icon of the list, one after another, on every start.
+func BenchmarkAppListIconsOnMainThread(b *testing.B) {
+	_, requests := newBenchmarkIcons(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, request := range requests {
+			if _, err := decodeScaledIcon(request); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+// BenchmarkAppListIconsCold measures the worker pool filling the list on the first start, with an empty cache.
+// The list is shown before, so this only decides how long the placeholders stay.
+func BenchmarkAppListIconsCold(b *testing.B) {
+	cacheDir, requests := newBenchmarkIcons(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		os.RemoveAll(cacheDir)
+		b.StartTimer()
+		loadAllIcons(cacheDir, requests)
+	}
+}
+
+// BenchmarkAppListIconsCached measures the worker pool filling the list on later starts, where every icon is read
+// from the scaled icon cache. Together with showing the window it should stay well under the 2 second budget of
+// the first paint on a Pi Zero 2.
+func BenchmarkAppListIconsCached(b *testing.B) {
+	cacheDir, requests := newBenchmarkIcons(b)
+	loadAllIcons(cacheDir, requests)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		loadAllIcons(cacheDir, requests)
+	}
+}
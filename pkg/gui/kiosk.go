@@ -0,0 +1,118 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: kiosk.go
+// Description: Hides what kiosk mode forbids from the GUI and lets an administrator unlock it with the admin PIN.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+//go:build !nogui
+
+package gui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gotk3/gotk3/gtk"
+	"github.com/pi-apps-go/pi-apps/pkg/api"
+)
+
+// kioskAllows reports whether kiosk mode allows an action. The api refuses it anyway, the GUI only
+// hides what would fail.
+func (g *GUI) kioskAllows(feature api.KioskFeature) bool {
+	return api.CheckKioskFeature(feature) == nil
+}
+
+// kioskUnlockable reports whether kiosk mode is on and has an admin PIN to unlock it
+func (g *GUI) kioskUnlockable() bool {
+	kiosk := api.ActiveKiosk()
+	return kiosk != nil && kiosk.AdminPINHash != ""
+}
+
+// kioskVisibleCategories returns the top-level categories that have apps kiosk mode shows, nil if kiosk mode is off
+func (g *GUI) kioskVisibleCategories() map[string]bool {
+	if api.ActiveKiosk() == nil {
+		return nil
+	}
+	visible := make(map[string]bool)
+	paths, err := api.AppPrefixCategory(g.directory, "")
+	if err != nil {
+		logger.Warn(fmt.Sprintf("failed to list the apps kiosk mode shows: %v", err))
+		return visible
+	}
+	for _, path := range paths {
+		if category, _, found := strings.Cut(path, "/"); found {
+			visible[category] = true
+		}
+	}
+	return visible
+}
+
+// showKioskUnlockDialog asks for the admin PIN and unlocks kiosk mode for this session if it is right.
+// It reports whether kiosk mode was unlocked.
+func (g *GUI) showKioskUnlockDialog() bool {
+	dialog, err := gtk.DialogNew()
+	if err != nil {
+		logger.Error(fmt.Sprintf("failed to create kiosk unlock dialog: %v", err))
+		return false
+	}
+	defer dialog.Destroy()
+
+	dialog.SetTitle(api.T("Unlock kiosk mode"))
+	if g.window != nil {
+		dialog.SetTransientFor(g.window)
+	}
+	dialog.SetModal(true)
+	dialog.AddButton(api.T("Cancel"), gtk.RESPONSE_CANCEL)
+	dialog.AddButton(api.T("Unlock"), gtk.RESPONSE_OK)
+	dialog.SetDefaultResponse(gtk.RESPONSE_OK)
+
+	contentArea, err := dialog.GetContentArea()
+	if err != nil {
+		return false
+	}
+	contentArea.SetSpacing(6)
+	contentArea.SetMarginStart(10)
+	contentArea.SetMarginEnd(10)
+
+	label, err := gtk.LabelNew(api.T("Enter the admin PIN to lift the kiosk restrictions until Pi-Apps is closed:"))
+	if err != nil {
+		return false
+	}
+	label.SetLineWrap(true)
+	contentArea.Add(label)
+
+	pinEntry, err := gtk.EntryNew()
+	if err != nil {
+		return false
+	}
+	pinEntry.SetVisibility(false)
+	pinEntry.SetActivatesDefault(true)
+	contentArea.Add(pinEntry)
+	dialog.ShowAll()
+
+	for dialog.Run() == gtk.RESPONSE_OK {
+		pin, _ := pinEntry.GetText()
+		err := api.UnlockKiosk(pin)
+		if err == nil {
+			logger.Info("Kiosk mode unlocked for this session")
+			return true
+		}
+		label.SetText(api.Tf("%v, try again:", err))
+		pinEntry.SetText("")
+	}
+	return false
+}
@@ -0,0 +1,131 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: legacy_import.go
+// Description: Offers to import the state of an install of the bash version of Pi-Apps the first time the GUI finds one.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+//go:build !nogui
+
+package gui
+
+import (
+	"fmt"
+	"html"
+	"path/filepath"
+
+	"github.com/gotk3/gotk3/gdk"
+	"github.com/gotk3/gotk3/gtk"
+	"github.com/pi-apps-go/pi-apps/pkg/api"
+)
+
+// checkLegacyImport offers to import the app statuses, settings and category overrides of the bash version
+// of Pi-Apps in ~/pi-apps, once per install of it
+func (g *GUI) checkLegacyImport() {
+	if g.remote != nil {
+		return
+	}
+	report, pending := api.PendingLegacyImport()
+	if !pending {
+		return
+	}
+
+	switch g.showLegacyImportAssistant(report) {
+	case gtk.RESPONSE_OK:
+		imported, err := api.ImportLegacyState(report.LegacyDir, false)
+		if err != nil {
+			logger.Error(fmt.Sprintf("failed to import %s: %v", report.LegacyDir, err))
+			dialog := gtk.MessageDialogNew(g.window, gtk.DIALOG_MODAL, gtk.MESSAGE_ERROR, gtk.BUTTONS_OK, "%s", api.Tf("Failed to import %s: %v", report.LegacyDir, err))
+			defer dialog.Destroy()
+			dialog.Run()
+			return
+		}
+		logger.Info(fmt.Sprintf("Imported %d changes from %s", imported.Changes(), report.LegacyDir))
+		g.refreshAppList()
+	case responseIgnore:
+		if err := api.DismissLegacyImport(report.LegacyDir); err != nil {
+			logger.Warn(fmt.Sprintf("failed to record the declined import: %v", err))
+		}
+	}
+	// Remind me later: nothing is recorded so the assistant shows up again next time
+}
+
+// showLegacyImportAssistant lists what importing the bash version of Pi-Apps would change and asks whether to
+func (g *GUI) showLegacyImportAssistant(report api.LegacyImportReport) gtk.ResponseType {
+	dialog, err := gtk.DialogNew()
+	if err != nil {
+		logger.Error(fmt.Sprintf("failed to create legacy import dialog: %v", err))
+		return responseRemindLater
+	}
+	defer dialog.Destroy()
+
+	dialog.SetTitle(api.T("Import from Pi-Apps"))
+	if g.window != nil {
+		dialog.SetTransientFor(g.window)
+	}
+	dialog.SetModal(true)
+	dialog.SetDefaultSize(450, 400)
+	dialog.SetPosition(gtk.WIN_POS_CENTER)
+	if icon, err := gdk.PixbufNewFromFile(filepath.Join(g.directory, "icons", "logo.png")); err == nil {
+		dialog.SetIcon(icon)
+	}
+
+	dialog.AddButton(api.T("Don't import"), responseIgnore)
+	dialog.AddButton(api.T("Remind me later"), responseRemindLater)
+	dialog.AddButton(api.T("Import"), gtk.RESPONSE_OK)
+	dialog.SetDefaultResponse(gtk.RESPONSE_OK)
+
+	contentArea, err := dialog.GetContentArea()
+	if err != nil {
+		return responseRemindLater
+	}
+	contentArea.SetSpacing(6)
+	contentArea.SetMarginStart(10)
+	contentArea.SetMarginEnd(10)
+	contentArea.SetMarginTop(10)
+
+	label, err := gtk.LabelNew("")
+	if err != nil {
+		return responseRemindLater
+	}
+	label.SetMarkup(api.Tf("The original Pi-Apps is installed in <b>%s</b>.\nImport which apps you installed with it, your settings and your category changes?",
+		html.EscapeString(report.LegacyDir)))
+	label.SetLineWrap(true)
+	label.SetXAlign(0)
+	contentArea.PackStart(label, false, false, 0)
+
+	textView, err := gtk.TextViewNew()
+	if err != nil {
+		return responseRemindLater
+	}
+	textView.SetEditable(false)
+	textView.SetCursorVisible(false)
+	if buffer, err := textView.GetBuffer(); err == nil {
+		buffer.SetText(report.Summary())
+	}
+
+	scrolled, err := gtk.ScrolledWindowNew(nil, nil)
+	if err != nil {
+		return responseRemindLater
+	}
+	scrolled.SetPolicy(gtk.POLICY_AUTOMATIC, gtk.POLICY_AUTOMATIC)
+	scrolled.SetVExpand(true)
+	scrolled.Add(textView)
+	contentArea.PackStart(scrolled, true, true, 0)
+
+	dialog.ShowAll()
+	return dialog.Run()
+}
@@ -18,14 +18,18 @@
 // Description: Provides functions for managing apps on Pi-Apps Go via the command line.
 // SPDX-License-Identifier: GPL-3.0-or-later
 
+//go:build !nogui
+
 package gui
 
 import (
-	"bufio"
 	"fmt"
+	"html"
 	"os"
 	"path/filepath"
 	"runtime"
+	"slices"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -35,39 +39,8 @@ import (
 	"github.com/gotk3/gotk3/gtk"
 	"github.com/pi-apps-go/pi-apps/pkg/api"
 	"github.com/toqueteos/webbrowser"
-	"golang.org/x/term"
 )
 
-// QueueItem represents an item in the installation/uninstallation queue
-type QueueItem struct {
-	Action         string // install, uninstall, update, refresh
-	AppName        string
-	Status         string // waiting, in-progress, success, failure
-	IconPath       string
-	ErrorMessage   string // Error message if the operation failed
-	ForceReinstall bool
-}
-
-// StatusIconMapping maps status to icon paths
-var StatusIconMapping = map[string]string{
-	"waiting":         "icons/wait.png",
-	"in-progress":     "icons/prompt.png",
-	"success":         "icons/success.png",
-	"failure":         "icons/failure.png",
-	"diagnosed":       "icons/failure.png", // Use failure icon for diagnosed items
-	"daemon-complete": "icons/success.png", // Use success icon for daemon completion
-}
-
-// ActionIconMapping maps actions to icon paths
-var ActionIconMapping = map[string]string{
-	"install":     "icons/install.png",
-	"uninstall":   "icons/uninstall.png",
-	"update":      "icons/update.png",
-	"refresh":     "icons/refresh.png",
-	"update-file": "icons/update.png",
-	"daemon":      "icons/none-24.png", // Special daemon completion marker
-}
-
 // GTK initialization state
 var (
 	gtkInitialized bool
@@ -91,6 +64,7 @@ func ensureGTKInitialized() bool {
 		glib.SetApplicationName(api.T("Pi-Apps (user dialog for managing apps)"))
 		// Initialize GTK
 		gtk.Init(nil)
+		applyHighContrastTheme()
 		gtkInitialized = true
 	}
 	return true
@@ -179,7 +153,7 @@ func ValidateAppsGUI(queue []QueueItem) ([]QueueItem, error) {
 		var appDirPath string
 		if item.Action == "update" || item.Action == "refresh" {
 			// For updates, check in the update directory
-			appDirPath = filepath.Join(piAppsDir, "update", "pi-apps", "apps", item.AppName)
+			appDirPath = api.AppUpdateDir(item.AppName)
 		} else {
 			// For install/uninstall, check in the apps directory
 			appDirPath = filepath.Join(piAppsDir, "apps", item.AppName)
@@ -191,6 +165,12 @@ func ValidateAppsGUI(queue []QueueItem) ([]QueueItem, error) {
 			continue
 		}
 
+		// Kiosk mode would refuse the action once the queue runs
+		if err := api.CheckKioskApp(api.Action(item.Action), item.AppName, false); err != nil {
+			showErrorDialog(html.EscapeString(err.Error()))
+			continue
+		}
+
 		// Check for redundant operations
 		appStatus := getAppStatus(item.AppName)
 		switch {
@@ -207,11 +187,54 @@ func ValidateAppsGUI(queue []QueueItem) ([]QueueItem, error) {
 		}
 		// Note: corrupted apps are allowed to be both installed and uninstalled
 
+		// Uninstall the installed apps that depend on an app before it, asking first unless they are queued already
+		if item.Action == "uninstall" {
+			queued := func(app string, items []QueueItem) bool {
+				return slices.ContainsFunc(items, func(other QueueItem) bool {
+					return other.Action == "uninstall" && other.AppName == app
+				})
+			}
+			if queued(item.AppName, validatedQueue) {
+				continue
+			}
+			installed, err := api.AppReverseDepends(item.AppName)
+			if err != nil {
+				api.WarningTf("Failed to check the apps that depend on %s: %v", item.AppName, err)
+			}
+			var dependents []string
+			for _, dependent := range installed {
+				if !queued(dependent, validatedQueue) {
+					dependents = append(dependents, dependent)
+				}
+			}
+
+			answer := DependentsCascade
+			if slices.ContainsFunc(dependents, func(dependent string) bool { return !queued(dependent, queue) }) {
+				corrupted, _ := api.CorruptedReverseDepends(item.AppName)
+				answer = ShowDependentsDialog(item.AppName, dependents, corrupted)
+			}
+			switch answer {
+			case DependentsSkip:
+				continue
+			case DependentsForce:
+				api.AllowUninstallWithDependents(item.AppName)
+			case DependentsCascade:
+				for _, dependent := range dependents {
+					validatedQueue = append(validatedQueue, QueueItem{
+						Action:   "uninstall",
+						AppName:  dependent,
+						Status:   "waiting",
+						IconPath: getAppIconPath(dependent),
+					})
+				}
+			}
+		}
+
 		// Check if update is available (for install action)
 		if item.Action == "install" {
 			scriptName := getInstallScriptName(item.AppName)
 			if scriptName != "" {
-				updateScriptPath := filepath.Join(piAppsDir, "update", "pi-apps", "apps", item.AppName, scriptName)
+				updateScriptPath := filepath.Join(api.AppUpdateDir(item.AppName), scriptName)
 				currentScriptPath := filepath.Join(piAppsDir, "apps", item.AppName, scriptName)
 
 				if fileExists(updateScriptPath) && !filesMatch(updateScriptPath, currentScriptPath) {
@@ -291,11 +314,15 @@ func ProgressMonitorWithOptions(queue []QueueItem, daemonMode bool) error {
 
 	// Create a list store for the queue
 	listStore, err := gtk.ListStoreNew(
-		glib.TYPE_OBJECT, // Status icon pixbuf
-		glib.TYPE_OBJECT, // Action icon pixbuf
-		glib.TYPE_STRING, // Action text
-		glib.TYPE_OBJECT, // App icon pixbuf
-		glib.TYPE_STRING, // App name
+		glib.TYPE_OBJECT,  // Status icon pixbuf
+		glib.TYPE_OBJECT,  // Action icon pixbuf
+		glib.TYPE_STRING,  // Action text
+		glib.TYPE_OBJECT,  // App icon pixbuf
+		glib.TYPE_STRING,  // App name
+		glib.TYPE_INT,     // Percentage of the phase of the item
+		glib.TYPE_STRING,  // Text on the progress bar
+		glib.TYPE_BOOLEAN, // Whether the progress bar is shown
+		glib.TYPE_INT,     // Pulse of the progress bar, -1 when the percentage is known
 	)
 	if err != nil {
 		return err
@@ -393,6 +420,27 @@ func ProgressMonitorWithOptions(queue []QueueItem, daemonMode bool) error {
 	column.AddAttribute(appNameRenderer, "markup", 4) // Use markup attribute for rich text
 	treeView.AppendColumn(column)
 
+	// Progress of the phase of the running item, pulsing while its percentage is unknown
+	phaseRenderer, err := gtk.CellRendererProgressNew()
+	if err != nil {
+		return err
+	}
+	phaseRenderer.SetProperty("xpad", 4)
+	phaseRenderer.SetProperty("ypad", 8)
+	phaseRenderer.SetProperty("width", 110)
+
+	column, err = gtk.TreeViewColumnNew()
+	if err != nil {
+		return err
+	}
+	column.SetSpacing(0)
+	column.PackStart(phaseRenderer, false)
+	column.AddAttribute(phaseRenderer, "value", 5)
+	column.AddAttribute(phaseRenderer, "text", 6)
+	column.AddAttribute(phaseRenderer, "visible", 7)
+	column.AddAttribute(phaseRenderer, "pulse", 8)
+	treeView.AppendColumn(column)
+
 	// Create a scrolled window for the tree view
 	scrolledWindow, err := gtk.ScrolledWindowNew(nil, nil)
 	if err != nil {
@@ -404,16 +452,189 @@ func ProgressMonitorWithOptions(queue []QueueItem, daemonMode bool) error {
 	scrolledWindow.SetShadowType(gtk.SHADOW_ETCHED_IN) // Add a subtle border
 	box.PackStart(scrolledWindow, true, true, 0)
 
-	// Update the list store with queue items
-	for _, item := range queue {
-		addQueueItemToPixbufListStore(listStore, item, false)
+	// Overall progress of the queue, weighted by the estimated durations of the items
+	queueBar, err := gtk.ProgressBarNew()
+	if err != nil {
+		return err
 	}
+	queueBar.SetShowText(true)
+	queueBar.SetNoShowAll(true)
+	box.PackStart(queueBar, false, false, 0)
 
-	// Show all widgets
-	win.ShowAll()
+	// Rough overall ETA of the queue, hidden while nothing in the queue has an estimate
+	etaLabel, err := gtk.LabelNew("")
+	if err != nil {
+		return err
+	}
+	etaLabel.SetHAlign(gtk.ALIGN_START)
+	etaLabel.SetNoShowAll(true)
+	box.PackStart(etaLabel, false, false, 0)
+	installStarted := make(map[string]time.Time)
+	updateETA := func(items []QueueItem) {
+		items = slices.Clone(items)
+		active := false
+		for i, item := range items {
+			if item.Status == "waiting" {
+				active = true
+			}
+			if item.Status != "in-progress" {
+				continue
+			}
+			active = true
+			if _, ok := installStarted[item.AppName]; !ok {
+				installStarted[item.AppName] = time.Now()
+			}
+			// A daemon of an older version doesn't write when the item started
+			if item.Started.IsZero() {
+				items[i].Started = installStarted[item.AppName]
+			}
+		}
+		progress := EstimateQueueProgress(items, time.Now())
+		if active {
+			queueBar.SetFraction(progress.Percent / 100)
+			queueBar.SetText(api.Tf("%d%% done", int(progress.Percent)))
+			queueBar.Show()
+		} else {
+			queueBar.Hide()
+		}
+		if !active || !progress.Known {
+			etaLabel.Hide()
+			return
+		}
+		etaLabel.SetText(api.Tf("About %s left (approximate)", api.FormatApproxDuration(progress.Remaining)))
+		etaLabel.Show()
+	}
 
+	// rowItems are the queue items shown in the rows of the list store, in order
+	var rowItems []QueueItem
 	// Variable to track if we should close the window
 	shouldClose := false
+	selection, err := treeView.GetSelection()
+	if err != nil {
+		return err
+	}
+
+	// In daemon mode, waiting items can be moved or removed while the queue is processed
+	var queueButtons []*gtk.Button
+	updateQueueButtons := func() {
+		waiting := false
+		if _, iter, ok := selection.GetSelected(); ok {
+			if path, err := listStore.GetPath(iter); err == nil {
+				row := path.GetIndices()[0]
+				waiting = row < len(rowItems) && rowItems[row].Status == "waiting"
+			}
+		}
+		for _, button := range queueButtons {
+			button.SetSensitive(waiting)
+		}
+	}
+	if daemonMode {
+		buttonBox, err := gtk.BoxNew(gtk.ORIENTATION_HORIZONTAL, 2)
+		if err != nil {
+			return err
+		}
+		for _, action := range []struct {
+			label   string
+			tooltip string
+			verb    string
+			dir     string
+		}{
+			{api.T("Move to top"), api.T("Run this operation next"), "move", "top"},
+			{api.T("Move up"), api.T("Run this operation earlier"), "move", "up"},
+			{api.T("Move down"), api.T("Run this operation later"), "move", "down"},
+			{api.T("Remove"), api.T("Remove this operation from the queue"), "remove", ""},
+		} {
+			button, err := gtk.ButtonNewWithLabel(action.label)
+			if err != nil {
+				return err
+			}
+			button.SetTooltipText(action.tooltip)
+			verb, direction := action.verb, action.dir
+			button.Connect("clicked", func() {
+				_, iter, ok := selection.GetSelected()
+				if !ok {
+					return
+				}
+				path, err := listStore.GetPath(iter)
+				if err != nil || path.GetIndices()[0] >= len(rowItems) {
+					return
+				}
+				command := QueueCommand{Verb: verb, ID: rowItems[path.GetIndices()[0]].ID, Direction: direction}
+				// The pipe write doesn't block, the status file shows the result on the next update
+				if err := SendQueueCommand(command); err != nil {
+					api.WarningTf("Failed to change the queue: %v", err)
+				}
+			})
+			buttonBox.PackStart(button, false, false, 0)
+			queueButtons = append(queueButtons, button)
+		}
+		box.PackStart(buttonBox, false, false, 0)
+		selection.Connect("changed", updateQueueButtons)
+	}
+
+	// pulse moves the progress bars of the phases without a percentage
+	pulse := 0
+	_ = glib.TimeoutAdd(150, func() bool {
+		if shouldClose {
+			return false
+		}
+		pulse++
+		for row, item := range rowItems {
+			if item.Status == "in-progress" && item.Phase != "" && item.Percent < 0 {
+				if iter, err := listStore.GetIterFromString(strconv.Itoa(row)); err == nil {
+					listStore.SetValue(iter, 8, pulse)
+				}
+			}
+		}
+		return true
+	})
+
+	// fillListStore shows the queue items, keeping the selected item selected
+	fillListStore := func(items []QueueItem) {
+		selectedID := -1
+		if _, iter, ok := selection.GetSelected(); ok {
+			if path, err := listStore.GetPath(iter); err == nil && path.GetIndices()[0] < len(rowItems) {
+				selectedID = rowItems[path.GetIndices()[0]].ID
+			}
+		}
+
+		listStore.Clear()
+		rowItems = rowItems[:0]
+		for _, item := range items {
+			if item.Status == "daemon-complete" {
+				continue
+			}
+			addQueueItemToPixbufListStore(listStore, item, false)
+			if iter, err := listStore.GetIterFromString(strconv.Itoa(len(rowItems))); err == nil {
+				setPhaseProgress(listStore, iter, item, pulse)
+			}
+			rowItems = append(rowItems, item)
+		}
+
+		for row, item := range rowItems {
+			if daemonMode && item.ID == selectedID {
+				if path, err := gtk.TreePathNewFromString(strconv.Itoa(row)); err == nil {
+					selection.SelectPath(path)
+				}
+				break
+			}
+		}
+		updateQueueButtons()
+	}
+
+	// Update the list store with queue items
+	fillListStore(queue)
+	updateETA(queue)
+
+	// Show all widgets
+	win.ShowAll()
+
+	// Show the progress in the tray and on the launcher while the daemon works through the queue
+	var tray *progressTray
+	if daemonMode {
+		tray = newProgressTray(win)
+		tray.Update(queue, installStarted)
+	}
 
 	// Track timeout for stuck operations
 	startTime := time.Now()
@@ -432,9 +653,14 @@ func ProgressMonitorWithOptions(queue []QueueItem, daemonMode bool) error {
 		if daemonMode {
 			// Try to read from a well-known status file location
 			piAppsDir := api.GetPiAppsDir()
-			statusFile := filepath.Join(piAppsDir, "data", "manage-daemon", "status")
-			if updatedQueue, err := readQueueFromStatusFile(statusFile); err == nil && len(updatedQueue) > 0 {
+			statusFile := filepath.Join(api.DataDirOf(piAppsDir), "manage-daemon", "status")
+			if updatedQueue, err := ReadQueueStatus(statusFile); err == nil && len(updatedQueue) > 0 {
 				currentQueue = updatedQueue
+
+				// The queue is finished but the terminal stays open until the user closes it
+				if state, err := ReadDaemonTerminalState(statusFile); err == nil && state.Completed && state.TerminalOpen {
+					win.SetTitle(api.T("Monitor Progress") + " - " + api.T("Waiting for the terminal to be closed"))
+				}
 			} else {
 				// If status file can't be read and enough time has passed, assume failure
 				// This handles cases where the installation process crashes before writing status
@@ -451,10 +677,9 @@ func ProgressMonitorWithOptions(queue []QueueItem, daemonMode bool) error {
 		}
 
 		// Update list store with current status
-		listStore.Clear()
-		for _, item := range currentQueue {
-			addQueueItemToPixbufListStore(listStore, item, false)
-		}
+		fillListStore(currentQueue)
+		updateETA(currentQueue)
+		tray.Update(currentQueue, installStarted)
 
 		// Check if all operations are complete (success or failure)
 		allComplete := true
@@ -468,7 +693,7 @@ func ProgressMonitorWithOptions(queue []QueueItem, daemonMode bool) error {
 			if item.Status == "failure" {
 				hasFailures = true
 			}
-			if item.Status != "success" && item.Status != "failure" && item.Status != "daemon-complete" && item.Status != "diagnosed" {
+			if item.Status == "waiting" || item.Status == "in-progress" {
 				allComplete = false
 			}
 		}
@@ -500,6 +725,7 @@ func ProgressMonitorWithOptions(queue []QueueItem, daemonMode bool) error {
 	// Connect signals
 	win.Connect("destroy", func() {
 		shouldClose = true
+		tray.Close()
 		gtk.MainQuit()
 	})
 
@@ -972,17 +1198,32 @@ func addQueueItemToPixbufListStore(listStore *gtk.ListStore, item QueueItem, use
 	var actionText string
 	switch item.Status {
 	case "waiting":
-		actionText = api.Tf("Will %s", item.Action)
+		actionText = glib.MarkupEscapeText(api.ActionWaitingText(item.Action))
 	case "in-progress":
-		actionText = api.Tf("%sing...", capitalize(item.Action))
+		actionText = glib.MarkupEscapeText(api.ActionProgressText(item.Action))
+		if done, total, ok := strings.Cut(item.Progress, "/"); ok {
+			actionText += "\n<small>" + glib.MarkupEscapeText(api.Tf("Updating file %s/%s", done, total)) + "</small>"
+		}
+		if phase := api.PhaseText(api.QueuePhase{Name: item.Phase, Percent: item.Percent}); phase != "" {
+			actionText += "\n<small>" + glib.MarkupEscapeText(phase) + "</small>"
+		}
 	case "success":
-		actionText = api.Tf("%sed", capitalize(item.Action))
+		actionText = glib.MarkupEscapeText(withStatusSymbol(api.SymbolSuccess, api.ActionDoneText(item.Action)))
 	case "failure":
 		// For failures, show the action that failed
-		actionText = api.Tf("<span foreground='red'>%s failed</span>", capitalize(item.Action))
+		actionText = "<span foreground='red'>" + glib.MarkupEscapeText(withStatusSymbol(api.SymbolFailure, api.ActionFailedText(item.Action))) + "</span>"
+		if reason := api.ExitCodeReason(item.ExitCode); reason != "" {
+			actionText += "\n<small>" + glib.MarkupEscapeText(reason) + "</small>"
+		}
+		if item.LogFile != "" {
+			actionText += "\n<small>" + glib.MarkupEscapeText(api.Tf("Log file: %s", filepath.Base(item.LogFile))) + "</small>"
+		}
 	case "diagnosed":
 		// For diagnosed items, show that they were diagnosed
-		actionText = api.Tf("<span foreground='orange'>%s failed (diagnosed)</span>", capitalize(item.Action))
+		actionText = "<span foreground='orange'>" + glib.MarkupEscapeText(withStatusSymbol(api.SymbolWarning, api.Tf("%s (diagnosed)", api.ActionFailedText(item.Action)))) + "</span>"
+	case StatusSkipped, StatusSuperseded:
+		// The item never ran because of another item in the queue, show why in gray
+		actionText = "<span foreground='gray'>" + glib.MarkupEscapeText(skippedText(item)) + "</span>"
 	case "daemon-complete":
 		// For daemon completion, don't add this item to the display
 		return
@@ -991,10 +1232,6 @@ func addQueueItemToPixbufListStore(listStore *gtk.ListStore, item QueueItem, use
 		actionText = fmt.Sprintf("%s (%s)", capitalize(item.Action), item.Status)
 	}
 
-	// Fix "updateed" text
-	actionText = strings.Replace(actionText, "Updateed", "Updated", 1)
-	actionText = strings.Replace(actionText, "Updateing", "Updating", 1)
-
 	// Prepare the app name display
 	appNameDisplay := item.AppName
 
@@ -1010,6 +1247,20 @@ func addQueueItemToPixbufListStore(listStore *gtk.ListStore, item QueueItem, use
 	)
 }
 
+// setPhaseProgress shows the progress bar of the phase of a running item in the progress monitor, hiding it for
+// other items and for items of a daemon that doesn't report phases
+func setPhaseProgress(listStore *gtk.ListStore, iter *gtk.TreeIter, item QueueItem, pulse int) {
+	phase := api.QueuePhase{Name: item.Phase, Percent: item.Percent}
+	visible := item.Status == "in-progress" && api.PhaseText(phase) != ""
+	value, text := 0, ""
+	if visible && phase.Percent >= 0 {
+		value, text, pulse = phase.Percent, fmt.Sprintf("%d%%", phase.Percent), -1
+	} else if !visible {
+		pulse = -1
+	}
+	listStore.Set(iter, []int{5, 6, 7, 8}, []interface{}{value, text, visible, pulse})
+}
+
 // addDonationItemsToPixbufListStore adds donation items to the list store using pixbufs
 func addDonationItemsToPixbufListStore(listStore *gtk.ListStore) {
 	const targetAppHeight = 64 // Define target height for donation icons (was 24, now matches large app icon)
@@ -1180,6 +1431,71 @@ func showErrorDialog(message string) {
 	dialog.Destroy()
 }
 
+// ShowConflictDialog asks whether to uninstall the installed apps an app conflicts with before installing it
+// Returns true if the user agreed
+func ShowConflictDialog(app string, conflicts []string) bool {
+	if !canUseGTK() {
+		return showConflictDialogCLI(app, conflicts)
+	}
+	quoted := make([]string, len(conflicts))
+	for i, conflict := range conflicts {
+		quoted[i] = "<b>" + glib.MarkupEscapeText(conflict) + "</b>"
+	}
+	appMarkup := "<b>" + glib.MarkupEscapeText(app) + "</b>"
+	conflictsMarkup := strings.Join(quoted, ", ")
+	return showConfirmDialog(api.Tf("%s conflicts with %s, which is installed.\nBoth apps can't be installed at the same time.\n\nUninstall %s first and then install %s?",
+		appMarkup, conflictsMarkup, conflictsMarkup, appMarkup))
+}
+
+// ShowDependentsDialog asks whether to uninstall the installed apps that depend on an app before uninstalling it
+// Returns DependentsCascade, DependentsForce or DependentsSkip
+func ShowDependentsDialog(app string, dependents, corrupted []string) string {
+	if !canUseGTK() || !ensureGTKInitialized() {
+		return showDependentsDialogCLI(app, dependents, corrupted)
+	}
+
+	dialog, err := gtk.DialogNew()
+	if err != nil {
+		return DependentsSkip
+	}
+	dialog.SetTitle(api.T("Uninstall"))
+
+	dialog.AddButton(api.T("Cancel"), gtk.RESPONSE_CANCEL)
+	dialog.AddButton(api.Tf("Uninstall only %s", app), gtk.RESPONSE_NO)
+	dialog.AddButton(api.T("Uninstall all"), gtk.RESPONSE_YES)
+
+	contentArea, err := dialog.GetContentArea()
+	if err != nil {
+		dialog.Destroy()
+		return DependentsSkip
+	}
+	label, err := gtk.LabelNew("")
+	if err != nil {
+		dialog.Destroy()
+		return DependentsSkip
+	}
+	message := glib.MarkupEscapeText(dependentsNote(app, dependents, corrupted))
+	if len(dependents) > 0 {
+		message += "\n\n" + api.Tf("Uninstall them first, or only uninstall <b>%s</b> and leave them broken?", glib.MarkupEscapeText(app))
+	}
+	label.SetMarkup(message)
+	contentArea.Add(label)
+
+	response, err := runGtkDialog(dialog)
+	dialog.Destroy()
+	if err != nil {
+		return DependentsSkip
+	}
+
+	switch response {
+	case gtk.RESPONSE_YES:
+		return DependentsCascade
+	case gtk.RESPONSE_NO:
+		return DependentsForce
+	}
+	return DependentsSkip
+}
+
 // ShowErrorDialogWithRetry shows an error dialog with retry option
 // Returns true if user chose to retry, false if they chose to skip
 func ShowErrorDialogWithRetry(appName, action, message string) bool {
@@ -1446,17 +1762,9 @@ func getAppIconPath(appName string) string {
 	return filepath.Join(piAppsDir, "icons", "none-24.png")
 }
 
-// capitalize capitalizes the first letter of a string
-func capitalize(s string) string {
-	if len(s) == 0 {
-		return s
-	}
-	return strings.ToUpper(s[:1]) + s[1:]
-}
-
 // getAppStatus returns the current status of an app (installed, uninstalled, corrupted, etc.)
 func getAppStatus(appName string) string {
-	statusFile := filepath.Join(api.GetPiAppsDir(), "data", "status", appName)
+	statusFile := filepath.Join(api.GetDataDir(), "status", appName)
 	content, err := os.ReadFile(statusFile)
 	if err != nil {
 		return "uninstalled" // Default to uninstalled if status file doesn't exist
@@ -1541,73 +1849,6 @@ func openURL(url string) error {
 
 // CLI fallback functions
 
-// progressMonitorCLI provides a simple CLI-based progress monitor
-func progressMonitorCLI(queue []QueueItem) error {
-	fmt.Println(api.T("\n=== Progress Monitor ==="))
-	fmt.Println(api.T("The following operations will be performed:"))
-
-	for _, item := range queue {
-		fmt.Printf("%s %s: %s\n",
-			strings.ToUpper(item.Action),
-			item.AppName,
-			strings.ToUpper(item.Status))
-	}
-
-	fmt.Println(api.T("\nPress Ctrl+C to cancel"))
-	return nil
-}
-
-// showSummaryDialogCLI shows a summary of completed actions in CLI
-func showSummaryDialogCLI(completedQueue []QueueItem) error {
-	fmt.Println(api.T("\n=== Operations Complete ==="))
-	fmt.Println(api.T("Thank you for using Pi-Apps! The following actions completed:"))
-
-	for _, item := range completedQueue {
-		var actionText string
-		switch item.Status {
-		case "success":
-			actionText = api.Tf("%sed successfully", capitalize(item.Action))
-		case "failure":
-			actionText = api.Tf("%s failed", capitalize(item.Action))
-		default:
-			actionText = api.Tf("%s status: %s", capitalize(item.Action), item.Status)
-		}
-
-		// Fix "updateed" text
-		actionText = strings.Replace(actionText, "Updateed", "Updated", 1)
-
-		fmt.Printf("%s: %s\n", item.AppName, actionText)
-	}
-
-	fmt.Println(api.T("\nDonations:"))
-	fmt.Println(api.Tf("- Botspot (Pi-Apps founder): https://github.com/sponsors/botspot"))
-	fmt.Println(api.Tf("- theofficialgman (Pi-Apps contributor): https://github.com/sponsors/theofficialgman"))
-	fmt.Println(api.Tf("- Pi-Apps Go developers: https://github.com/sponsors/matu6968"))
-
-	return nil
-}
-
-// showBrokenPackagesDialogCLI asks for sudo password in CLI
-func showBrokenPackagesDialogCLI() (string, error) {
-	fmt.Println(api.T("\n=== Broken Local Packages Repo Detected ==="))
-	fmt.Println(api.T("Please enter your user password to repair:"))
-	fmt.Println(api.T("Password will not be visible as you type."))
-
-	// Use secure password input
-	passwordBytes, err := term.ReadPassword(int(os.Stdin.Fd()))
-	if err != nil {
-		return "", fmt.Errorf("failed to read password: %v", err)
-	}
-	fmt.Println() // Add newline after password input
-
-	password := string(passwordBytes)
-	if password == "" {
-		return "", fmt.Errorf("canceled by user")
-	}
-
-	return password, nil
-}
-
 // DisplayUnsupportedSystemWarning shows a formatted warning message for unsupported systems
 func DisplayUnsupportedSystemWarning(message string, useGUI bool) {
 	// Add ANSI color codes to match the original Bash implementation
@@ -1622,7 +1863,11 @@ func DisplayUnsupportedSystemWarning(message string, useGUI bool) {
 	waitingMsg := fmt.Sprintf("\033[103m\033[30m%s\033[39m\033[49m\n", waitingSecondsMsg)
 
 	// Write colored messages to stdout (terminal)
-	fmt.Printf("%s%s%s%s", warningPrefix, formattedMessage, disabledMsg, waitingMsg)
+	output := warningPrefix + formattedMessage + disabledMsg + waitingMsg
+	if !api.ColorEnabled(os.Stdout) {
+		output = api.StripColors(output)
+	}
+	fmt.Print(output)
 
 	// Only show GUI dialog if explicitly requested
 	if useGUI && canUseGTK() && ensureGTKInitialized() {
@@ -1635,41 +1880,3 @@ func DisplayUnsupportedSystemWarning(message string, useGUI bool) {
 	// Wait 10 seconds as in the original implementation
 	time.Sleep(10 * time.Second)
 }
-
-// readQueueFromStatusFile reads queue status from a file (helper for progress monitor)
-func readQueueFromStatusFile(statusFile string) ([]QueueItem, error) {
-	if statusFile == "" {
-		return nil, fmt.Errorf("no status file specified")
-	}
-
-	file, err := os.Open(statusFile)
-	if err != nil {
-		return nil, err
-	}
-	defer file.Close()
-
-	var queue []QueueItem
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if line == "" {
-			continue
-		}
-
-		parts := strings.SplitN(line, ";", 5)
-		if len(parts) >= 4 {
-			item := QueueItem{
-				Action:   parts[0],
-				AppName:  parts[1],
-				Status:   parts[2],
-				IconPath: parts[3],
-			}
-			if len(parts) >= 5 {
-				item.ErrorMessage = parts[4]
-			}
-			queue = append(queue, item)
-		}
-	}
-
-	return queue, scanner.Err()
-}
@@ -0,0 +1,94 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: nogui.go
+// Description: Provides the terminal versions of the manage dialogs when built with the nogui tag.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+//go:build nogui
+
+package gui
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/pi-apps-go/pi-apps/pkg/api"
+)
+
+// ValidateAppsGUI returns the queue unchanged, there is no GUI to review it in
+func ValidateAppsGUI(queue []QueueItem) ([]QueueItem, error) {
+	return queue, nil
+}
+
+// ProgressMonitor lists the operations of the queue in the terminal
+func ProgressMonitor(queue []QueueItem) error {
+	return progressMonitorCLI(queue)
+}
+
+// ProgressMonitorDaemon lists the operations of the queue in the terminal
+func ProgressMonitorDaemon(queue []QueueItem) error {
+	return progressMonitorCLI(queue)
+}
+
+// ShowSummaryDialog prints a summary of the completed operations in the terminal
+func ShowSummaryDialog(completedQueue []QueueItem) error {
+	return showSummaryDialogCLI(completedQueue)
+}
+
+// ShowBrokenPackagesDialog asks for the password to repair the local packages repo in the terminal
+func ShowBrokenPackagesDialog() (string, error) {
+	return showBrokenPackagesDialogCLI()
+}
+
+// ShowErrorDialogWithRetry prints the error, retrying is only offered by the GUI
+func ShowErrorDialogWithRetry(appName, action, message string) bool {
+	api.ErrorNoExitTf("ERROR: %s", message)
+	return false
+}
+
+// ShowConflictDialog asks in the terminal whether to uninstall the conflicting apps first
+func ShowConflictDialog(app string, conflicts []string) bool {
+	return showConflictDialogCLI(app, conflicts)
+}
+
+// ShowDependentsDialog asks in the terminal whether to uninstall the apps that depend on an app first
+func ShowDependentsDialog(app string, dependents, corrupted []string) string {
+	return showDependentsDialogCLI(app, dependents, corrupted)
+}
+
+// ShowMessageDialog prints a message and waits for Enter
+func ShowMessageDialog(title, message string, dialogType int) {
+	fmt.Printf("\n[%s] %s\n", title, message)
+	fmt.Println("Press Enter to continue...")
+	fmt.Scanln()
+}
+
+// DisplayUnsupportedSystemWarning shows a formatted warning message for unsupported systems
+func DisplayUnsupportedSystemWarning(message string, useGUI bool) {
+	warningPrefix := fmt.Sprintf("\033[93m\033[5m◢◣\033[25m\033[0m \033[93m%s\033[0m \033[93m%s\033[0m\n", api.T("WARNING:"), api.T("YOUR SYSTEM IS UNSUPPORTED:"))
+	formattedMessage := fmt.Sprintf("\033[93m%s\033[0m\n", message)
+	disabledMsg := fmt.Sprintf("\033[103m\033[30m%s\033[39m\033[49m\n", api.T("The ability to send error reports has been disabled."))
+	waitingMsg := fmt.Sprintf("\033[103m\033[30m%s\033[39m\033[49m\n", api.T("Waiting 10 seconds... (To cancel, press Ctrl+C or close this terminal)"))
+	output := warningPrefix + formattedMessage + disabledMsg + waitingMsg
+	if !api.ColorEnabled(os.Stdout) {
+		output = api.StripColors(output)
+	}
+	fmt.Print(output)
+
+	time.Sleep(10 * time.Second)
+}
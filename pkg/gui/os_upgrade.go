@@ -0,0 +1,162 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: os_upgrade.go
+// Description: Provides the migration assistant shown after the OS was upgraded to a new release.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+//go:build !nogui
+
+package gui
+
+import (
+	"fmt"
+	"html"
+	"path/filepath"
+	"strings"
+
+	"github.com/gotk3/gotk3/gdk"
+	"github.com/gotk3/gotk3/gtk"
+	"github.com/pi-apps-go/pi-apps/pkg/api"
+)
+
+// Responses of the migration assistant besides reinstalling
+const (
+	responseRemindLater gtk.ResponseType = 1
+	responseIgnore      gtk.ResponseType = 2
+)
+
+// checkOSUpgrade shows the migration assistant if the OS release changed since Pi-Apps last ran
+func (g *GUI) checkOSUpgrade() {
+	report, err := api.DetectOSUpgrade()
+	if err != nil {
+		logger.Warn(fmt.Sprintf("failed to check for an OS upgrade: %v", err))
+		return
+	}
+	if report == nil {
+		return
+	}
+
+	switch g.showOSUpgradeAssistant(report) {
+	case gtk.RESPONSE_OK:
+		go func() {
+			if err := api.RemoveStaleOSRepos(report); err != nil {
+				logger.Warn(err.Error())
+			}
+			if queue := api.OSUpgradeQueue(report); queue != "" {
+				if err := api.TerminalManageMulti(queue); err != nil {
+					logger.Error(fmt.Sprintf("failed to queue reinstalls: %v", err))
+					return
+				}
+			}
+			if err := api.AcknowledgeOSUpgrade(); err != nil {
+				logger.Warn(fmt.Sprintf("failed to record the OS codename: %v", err))
+			}
+		}()
+	case responseIgnore:
+		if err := api.AcknowledgeOSUpgrade(); err != nil {
+			logger.Warn(fmt.Sprintf("failed to record the OS codename: %v", err))
+		}
+	}
+	// Remind me later: keep the old codename so the assistant shows up again next time
+}
+
+// showOSUpgradeAssistant lists the installed apps affected by an OS upgrade and asks what to do with them
+func (g *GUI) showOSUpgradeAssistant(report *api.OSUpgradeReport) gtk.ResponseType {
+	dialog, err := gtk.DialogNew()
+	if err != nil {
+		logger.Error(fmt.Sprintf("failed to create OS upgrade dialog: %v", err))
+		return responseRemindLater
+	}
+	defer dialog.Destroy()
+
+	dialog.SetTitle(api.T("OS upgrade detected"))
+	if g.window != nil {
+		dialog.SetTransientFor(g.window)
+	}
+	dialog.SetModal(true)
+	dialog.SetDefaultSize(450, 400)
+	dialog.SetPosition(gtk.WIN_POS_CENTER)
+	if icon, err := gdk.PixbufNewFromFile(filepath.Join(g.directory, "icons", "logo.png")); err == nil {
+		dialog.SetIcon(icon)
+	}
+
+	dialog.AddButton(api.T("Ignore"), responseIgnore)
+	dialog.AddButton(api.T("Remind me later"), responseRemindLater)
+	if len(report.NeedsReinstall) > 0 || len(report.StaleRepos) > 0 {
+		dialog.AddButton(api.T("Reinstall apps"), gtk.RESPONSE_OK)
+		dialog.SetDefaultResponse(gtk.RESPONSE_OK)
+	}
+
+	contentArea, err := dialog.GetContentArea()
+	if err != nil {
+		return responseRemindLater
+	}
+	contentArea.SetSpacing(6)
+	contentArea.SetMarginStart(10)
+	contentArea.SetMarginEnd(10)
+	contentArea.SetMarginTop(10)
+
+	label, err := gtk.LabelNew("")
+	if err != nil {
+		return responseRemindLater
+	}
+	label.SetMarkup(api.Tf("Your OS was upgraded from <b>%s</b> to <b>%s</b>.\nSome apps were installed for the old release and may need to be reinstalled.",
+		html.EscapeString(report.OldCodename), html.EscapeString(report.NewCodename)))
+	label.SetLineWrap(true)
+	label.SetXAlign(0)
+	contentArea.PackStart(label, false, false, 0)
+
+	var text strings.Builder
+	writeGroup := func(title string, items []string) {
+		text.WriteString(title + "\n")
+		if len(items) == 0 {
+			text.WriteString("  " + api.T("(none)") + "\n")
+		}
+		for _, item := range items {
+			text.WriteString("  " + item + "\n")
+		}
+		text.WriteString("\n")
+	}
+	writeGroup(api.T("Needs reinstall:"), report.NeedsReinstall)
+	writeGroup(api.T("Probably fine:"), report.ProbablyFine)
+	writeGroup(api.T("No longer supported on this release:"), report.Unsupported)
+	if len(report.StaleRepos) > 0 {
+		writeGroup(api.T("Repos still pointing at an old release (removed if unused):"), report.StaleRepos)
+	}
+
+	textView, err := gtk.TextViewNew()
+	if err != nil {
+		return responseRemindLater
+	}
+	textView.SetEditable(false)
+	textView.SetCursorVisible(false)
+	if buffer, err := textView.GetBuffer(); err == nil {
+		buffer.SetText(strings.TrimSpace(text.String()))
+	}
+
+	scrolled, err := gtk.ScrolledWindowNew(nil, nil)
+	if err != nil {
+		return responseRemindLater
+	}
+	scrolled.SetPolicy(gtk.POLICY_AUTOMATIC, gtk.POLICY_AUTOMATIC)
+	scrolled.SetVExpand(true)
+	scrolled.Add(textView)
+	contentArea.PackStart(scrolled, true, true, 0)
+
+	dialog.ShowAll()
+	return dialog.Run()
+}
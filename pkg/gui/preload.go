@@ -19,6 +19,8 @@
 // This replaces the bash preload script functionality for the Go rewrite.
 // SPDX-License-Identifier: GPL-3.0-or-later
 
+//go:build !nogui
+
 package gui
 
 import (
@@ -44,6 +46,7 @@ type AppListItem struct {
 	IconPath    string
 	Status      string // "installed", "uninstalled", "corrupted", "disabled", ""
 	IsUpdates   bool   // Special Updates category
+	Unavailable string // why the app can't be installed on this system, "" if it can
 }
 
 // PreloadedList contains the preloaded app list data
@@ -58,6 +61,9 @@ type AppListConfig struct {
 	Directory string
 	Prefix    string
 	Format    string // "gtk" (GTK3 native instead of yad/xlunch)
+
+	statuses    map[string]api.AppState // statuses of all apps, read once per list generation
+	unavailable map[string]string       // apps that can't be installed on this system and why, read once per list generation
 }
 
 // DirectoryInfo holds information about directories to check for changes
@@ -79,13 +85,13 @@ func NewTimeStampChecker(directory string) *TimeStampChecker {
 		Directory: directory,
 		CheckedDirs: []DirectoryInfo{
 			{Path: filepath.Join(directory, "apps")},
-			{Path: filepath.Join(directory, "data", "settings")},
-			{Path: filepath.Join(directory, "data", "status")},
+			{Path: filepath.Join(api.DataDirOf(directory), "settings")},
+			{Path: filepath.Join(api.DataDirOf(directory), "status")},
 			{Path: filepath.Join(directory, "etc")},
 			{Path: filepath.Join(directory, "icons", "categories")},
 			{Path: filepath.Join(directory, "preload")},
 			{Path: filepath.Join(directory, "api")},
-			{Path: filepath.Join(directory, "data", "category-overrides")},
+			{Path: filepath.Join(api.DataDirOf(directory), "category-overrides")},
 		},
 	}
 }
@@ -112,7 +118,8 @@ func (tc *TimeStampChecker) GetTimestamps() string {
 				if err != nil {
 					return nil // Continue on errors
 				}
-				if !d.IsDir() {
+				// The GUI state changes while browsing and doesn't affect the app list
+				if !d.IsDir() && d.Name() != guiStateFile {
 					info, err := d.Info()
 					if err == nil && info.ModTime().Unix() > latestTime {
 						latestTime = info.ModTime().Unix()
@@ -132,12 +139,17 @@ func (tc *TimeStampChecker) GetTimestamps() string {
 		}
 	}
 
+	// Unlocking kiosk mode shows more apps without changing any file
+	result.WriteString(fmt.Sprintf("kiosk %s\n", api.KioskState()))
+	// The descriptions are translated, switching the language needs new lists
+	result.WriteString(fmt.Sprintf("locale %s\n", api.DescriptionLocale()))
+
 	return result.String()
 }
 
 // HasChanged checks if any monitored directory has changed since the last check
 func (tc *TimeStampChecker) HasChanged(prefix string) (bool, error) {
-	timestampFile := filepath.Join(tc.Directory, "data", "preload",
+	timestampFile := filepath.Join(api.DataDirOf(tc.Directory), "preload",
 		fmt.Sprintf("timestamps-%s", sanitizePath(prefix)))
 
 	currentTimestamps := tc.GetTimestamps()
@@ -156,7 +168,7 @@ func (tc *TimeStampChecker) HasChanged(prefix string) (bool, error) {
 
 // SaveTimestamps saves current timestamps to file
 func (tc *TimeStampChecker) SaveTimestamps(prefix string) error {
-	preloadDir := filepath.Join(tc.Directory, "data", "preload")
+	preloadDir := filepath.Join(api.DataDirOf(tc.Directory), "preload")
 	if err := os.MkdirAll(preloadDir, 0755); err != nil {
 		logger.Error(api.Tf("failed to create preload directory: %v\n", err))
 		return fmt.Errorf("failed to create preload directory: %w", err)
@@ -211,6 +223,7 @@ func PreloadAppList(directory, prefix string) (*PreloadedList, error) {
 	// Load API functions
 	os.Setenv("PI_APPS_DIR", directory)
 
+	start := time.Now()
 	list, err := generateAppList(config)
 	if err != nil {
 		logger.Error(api.Tf("failed to generate app list: %v\n", err))
@@ -226,10 +239,96 @@ func PreloadAppList(directory, prefix string) (*PreloadedList, error) {
 		logger.Warn(api.Tf("failed to save timestamps: %v\n", err))
 	}
 
-	logger.Debug(api.Tf("Finished preload for '%s'\n", prefix))
+	logger.Debug(api.Tf("Finished preload for '%s' in %s\n", prefix, time.Since(start).Round(time.Millisecond)))
 	return list, nil
 }
 
+func init() {
+	api.RegisterAppEntryRefresher(RefreshPreloadedAppEntry)
+}
+
+// RefreshPreloadedAppEntry updates the entry of an app in every cached app list after its status changed,
+// so the lists do not have to be regenerated. Lists that are stale for other reasons are left alone,
+// they are regenerated the next time they are shown.
+func RefreshPreloadedAppEntry(app string) error {
+	directory := api.GetPiAppsDir()
+	if directory == "" {
+		return fmt.Errorf("PI_APPS_DIR environment variable not set")
+	}
+	preloadDir := filepath.Join(api.DataDirOf(directory), "preload")
+	listFiles, err := filepath.Glob(filepath.Join(preloadDir, "LIST-*"))
+	if err != nil {
+		return err
+	}
+
+	tc := NewTimeStampChecker(directory)
+	timestamps := tc.GetTimestamps()
+
+	for _, listFile := range listFiles {
+		if strings.HasSuffix(listFile, "-tmp") {
+			continue
+		}
+		// The file name holds the sanitized prefix, which is all the cache functions need
+		prefix := strings.TrimPrefix(filepath.Base(listFile), "LIST-")
+		timestampFile := filepath.Join(preloadDir, "timestamps-"+prefix)
+
+		saved, err := os.ReadFile(timestampFile)
+		if err != nil || !onlyStatusChanged(string(saved), timestamps, filepath.Join(api.DataDirOf(directory), "status")) {
+			continue
+		}
+
+		// Which apps the main page and the Installed category show depends on their status
+		if prefix == "" || prefix == "Installed" {
+			os.Remove(listFile)
+			os.Remove(timestampFile)
+			continue
+		}
+
+		config := &AppListConfig{Directory: directory, Prefix: prefix}
+		list, err := loadCachedList(config)
+		if err != nil {
+			continue
+		}
+		changed := false
+		for i, item := range list.Items {
+			if item.Type != "app" || item.Name != app {
+				continue
+			}
+			fresh, err := createAppItem(app, config)
+			if err != nil {
+				return err
+			}
+			fresh.Path = item.Path
+			list.Items[i] = fresh
+			changed = true
+		}
+		if changed {
+			if err := saveCachedList(config, list); err != nil {
+				return fmt.Errorf("failed to save cached list: %w", err)
+			}
+		}
+		if err := tc.SaveTimestamps(prefix); err != nil {
+			return fmt.Errorf("failed to save timestamps: %w", err)
+		}
+	}
+	return nil
+}
+
+// onlyStatusChanged reports whether two outputs of GetTimestamps differ in the status directory only
+func onlyStatusChanged(saved, current, statusDir string) bool {
+	savedLines := strings.Split(saved, "\n")
+	currentLines := strings.Split(current, "\n")
+	if len(savedLines) != len(currentLines) {
+		return false
+	}
+	for i := range savedLines {
+		if savedLines[i] != currentLines[i] && !strings.HasPrefix(currentLines[i], statusDir+" ") {
+			return false
+		}
+	}
+	return true
+}
+
 // shouldReloadList determines if the app list needs to be regenerated
 func shouldReloadList(config *AppListConfig, tc *TimeStampChecker) (bool, error) {
 	// Check if timestamps have changed
@@ -268,6 +367,13 @@ func generateAppList(config *AppListConfig) (*PreloadedList, error) {
 		Generated: time.Now(),
 	}
 
+	// Read the status of every app at once instead of once per app
+	if statuses, err := api.GetAllAppStatuses(); err == nil {
+		config.statuses = statuses
+	} else {
+		logger.Warn(fmt.Sprintf("failed to get app statuses: %v\n", err))
+	}
+
 	// Check for Updates category first (if on main page)
 	if config.Prefix == "" && hasUpdatesAvailable(config.Directory) {
 		updatesItem := AppListItem{
@@ -311,7 +417,7 @@ func generateAppList(config *AppListConfig) (*PreloadedList, error) {
 	}
 
 	// Separate apps and directories
-	apps, dirs := separateAppsAndDirs(vfiles, config.Directory)
+	apps, dirs := separateAppsAndDirs(vfiles, config)
 
 	// Shuffle if enabled
 	if shouldShuffleList(config.Directory) {
@@ -392,7 +498,7 @@ func getVirtualFileSystem(config *AppListConfig) ([]string, error) {
 }
 
 // separateAppsAndDirs separates apps from directories
-func separateAppsAndDirs(vfiles []string, directory string) (apps []string, dirs []string) {
+func separateAppsAndDirs(vfiles []string, config *AppListConfig) (apps []string, dirs []string) {
 	// Remove apps within categories - show this layer only
 	var processed []string
 	for _, vfile := range vfiles {
@@ -410,12 +516,13 @@ func separateAppsAndDirs(vfiles []string, directory string) (apps []string, dirs
 	// Remove duplicates
 	processed = removeDuplicates(processed)
 
-	// Get CPU installable apps for filtering - this ensures architecture compatibility
-	cpuInstallableApps, err := api.ListApps("cpu_installable")
+	// Get the apps that can't be installed on this architecture, OS or device
+	unavailable, err := api.UnavailableApps()
 	if err != nil {
-		logger.Warn(api.Tf("failed to get CPU installable apps: %v\n", err))
-		cpuInstallableApps = []string{}
+		logger.Warn(api.Tf("failed to get unavailable apps: %v\n", err))
 	}
+	config.unavailable = unavailable
+	showUnavailable := api.ShowUnavailableApps()
 
 	// Separate apps and directories
 	for _, item := range processed {
@@ -423,11 +530,15 @@ func separateAppsAndDirs(vfiles []string, directory string) (apps []string, dirs
 			// It's a directory
 			dirs = append(dirs, strings.TrimSuffix(item, "/"))
 		} else {
-			// It's an app, check if it's CPU installable (compatible with current architecture)
-			if contains(cpuInstallableApps, item) {
-				apps = append(apps, item)
+			// It's an app, unavailable apps are hidden unless the user wants to see them.
+			// Installed ones stay so they can still be uninstalled after the system changed.
+			if _, ok := unavailable[item]; ok && !showUnavailable {
+				status := config.appStatus(item)
+				if status != "installed" && status != "corrupted" {
+					continue
+				}
 			}
-			// If not in cpuInstallableApps, the app is not compatible with current architecture and will be hidden
+			apps = append(apps, item)
 		}
 	}
 
@@ -464,7 +575,7 @@ func createDirectoryItem(dir string, config *AppListConfig) (AppListItem, error)
 
 // getDeprecatedApps returns a list of all deprecated apps
 func getDeprecatedApps(directory string) ([]string, error) {
-	deprecatedDir := filepath.Join(directory, "data", "deprecated-apps")
+	deprecatedDir := filepath.Join(api.DataDirOf(directory), "deprecated-apps")
 	if _, err := os.Stat(deprecatedDir); os.IsNotExist(err) {
 		return []string{}, nil
 	}
@@ -488,16 +599,25 @@ func getDeprecatedApps(directory string) ([]string, error) {
 	return deprecatedApps, nil
 }
 
-// createDeprecatedAppItem creates an AppListItem for a deprecated app
-func createDeprecatedAppItem(app string, config *AppListConfig) (AppListItem, error) {
-	// Get app status (deprecated apps can still be installed)
+// appStatus returns the status of an app, preferring the statuses read for the whole list
+func (config *AppListConfig) appStatus(app string) string {
+	if status, ok := config.statuses[app]; ok {
+		return string(status)
+	}
 	status, err := api.GetAppStatus(app)
 	if err != nil {
-		status = ""
+		return ""
 	}
+	return status
+}
+
+// createDeprecatedAppItem creates an AppListItem for a deprecated app
+func createDeprecatedAppItem(app string, config *AppListConfig) (AppListItem, error) {
+	// Get app status (deprecated apps can still be installed)
+	status := config.appStatus(app)
 
 	// Get description from metadata or use default
-	deprecatedDir := filepath.Join(config.Directory, "data", "deprecated-apps", app)
+	deprecatedDir := filepath.Join(api.DataDirOf(config.Directory), "deprecated-apps", app)
 	metadataFile := filepath.Join(deprecatedDir, "metadata")
 	description := api.T("This app has been deprecated and removed from Pi-Apps.")
 
@@ -556,13 +676,10 @@ func createAppItem(app string, config *AppListConfig) (AppListItem, error) {
 	}
 
 	// Get app status
-	status, err := api.GetAppStatus(app)
-	if err != nil {
-		status = ""
-	}
+	status := config.appStatus(app)
 
 	// Get app description (first line only, like the original bash script)
-	descFile := filepath.Join(config.Directory, "apps", app, "description")
+	descFile := api.LocalizedDescriptionPath(filepath.Join(config.Directory, "apps", app))
 	description := api.T("Description unavailable")
 	if descData, err := os.ReadFile(descFile); err == nil {
 		// Split into lines and take only the first line (matching bash read -r behavior)
@@ -592,6 +709,7 @@ func createAppItem(app string, config *AppListConfig) (AppListItem, error) {
 		Description: description,
 		IconPath:    iconPath,
 		Status:      status,
+		Unavailable: config.unavailable[app],
 	}, nil
 }
 
@@ -602,24 +720,20 @@ func sanitizePath(path string) string {
 }
 
 func getListFilePath(config *AppListConfig) string {
-	return filepath.Join(config.Directory, "data", "preload",
+	return filepath.Join(api.DataDirOf(config.Directory), "preload",
 		fmt.Sprintf("LIST-%s", sanitizePath(config.Prefix)))
 }
 
 func hasUpdatesAvailable(directory string) bool {
-	updatableFiles := filepath.Join(directory, "data", "update-status", "updatable-files")
-	updatableApps := filepath.Join(directory, "data", "update-status", "updatable-apps")
+	updatableFiles := filepath.Join(api.DataDirOf(directory), "update-status", "updatable-files")
+	updatableApps := filepath.Join(api.DataDirOf(directory), "update-status", "updatable-apps")
 
 	return (appListFileExists(updatableFiles) && fileSize(updatableFiles) > 0) ||
 		(appListFileExists(updatableApps) && fileSize(updatableApps) > 0)
 }
 
 func shouldShuffleList(directory string) bool {
-	shuffleFile := filepath.Join(directory, "data", "settings", "Shuffle App list")
-	if data, err := os.ReadFile(shuffleFile); err == nil {
-		return strings.TrimSpace(string(data)) == "Yes"
-	}
-	return false
+	return api.ReadSettingIn(directory, "Shuffle App list") == "Yes"
 }
 
 func getParentPath(path string) string {
@@ -663,7 +777,7 @@ func getCategoryDescription(category string) string {
 
 // loadCachedList loads a previously cached app list
 func loadCachedList(config *AppListConfig) (*PreloadedList, error) {
-	preloadDir := filepath.Join(config.Directory, "data", "preload")
+	preloadDir := filepath.Join(api.DataDirOf(config.Directory), "preload")
 	listFile := filepath.Join(preloadDir, fmt.Sprintf("LIST-%s", sanitizePath(config.Prefix)))
 
 	// Check if the cached list file exists
@@ -677,7 +791,8 @@ func loadCachedList(config *AppListConfig) (*PreloadedList, error) {
 		return nil, fmt.Errorf("failed to read cached list file: %w", err)
 	}
 
-	// Parse the pipe-delimited format: "Type|Name|Path|Description|IconPath|Status"
+	// Parse the pipe-delimited format: "Type|Name|Path|Description|IconPath|Status|Unavailable",
+	// lists cached by older versions don't have the last field
 	lines := strings.Split(string(data), "\n")
 	var items []AppListItem
 
@@ -700,6 +815,9 @@ func loadCachedList(config *AppListConfig) (*PreloadedList, error) {
 			IconPath:    parts[4],
 			Status:      parts[5],
 		}
+		if len(parts) > 6 {
+			item.Unavailable = parts[6]
+		}
 
 		// Handle Updates category
 		if item.Name == "Updates" {
@@ -735,8 +853,8 @@ func saveCachedList(config *AppListConfig, list *PreloadedList) error {
 	// Write list items (this would be implementation-specific)
 	// For GTK3, we might serialize differently than YAD format
 	for _, item := range list.Items {
-		line := fmt.Sprintf("%s|%s|%s|%s|%s|%s\n",
-			item.Type, item.Name, item.Path, item.Description, item.IconPath, item.Status)
+		line := fmt.Sprintf("%s|%s|%s|%s|%s|%s|%s\n",
+			item.Type, item.Name, item.Path, item.Description, item.IconPath, item.Status, item.Unavailable)
 		if _, err := file.WriteString(line); err != nil {
 			return err
 		}
@@ -760,15 +878,6 @@ func appListFileExists(path string) bool {
 	return err == nil
 }
 
-func contains(slice []string, item string) bool {
-	for _, s := range slice {
-		if s == item {
-			return true
-		}
-	}
-	return false
-}
-
 func removeDuplicates(slice []string) []string {
 	keys := make(map[string]bool)
 	var result []string
@@ -19,7 +19,7 @@
 // This replaces the bash preload-daemon script functionality for the Go rewrite.
 // SPDX-License-Identifier: GPL-3.0-or-later
 
-//go:build pacman
+//go:build pacman && !nogui
 
 package gui
 
@@ -147,7 +147,7 @@ func (d *PreloadDaemon) refreshAll() {
 	timestamps := tc.GetTimestamps()
 
 	// Check if anything has changed since last daemon run
-	daemonTimestampFile := filepath.Join(d.directory, "data", "preload", "timestamps-preload-daemon")
+	daemonTimestampFile := filepath.Join(api.DataDirOf(d.directory), "preload", "timestamps-preload-daemon")
 	savedTimestamps, err := os.ReadFile(daemonTimestampFile)
 	if err == nil && string(savedTimestamps) == timestamps {
 		logger.Info(api.T("Preload-daemon skipped; nothing was changed"))
@@ -171,8 +171,8 @@ func (d *PreloadDaemon) refreshAll() {
 	}
 
 	// Save the current timestamps
-	preloadDir := filepath.Join(d.directory, "data", "preload")
-	if err := os.MkdirAll(preloadDir, 0755); err != nil {
+	preloadDir := filepath.Join(api.DataDirOf(d.directory), "preload")
+	if err := api.EnsureDirOwned(preloadDir, api.InvokingUser, api.InvokingUser); err != nil {
 		logger.Warn(api.Tf("failed to create preload directory: %v\n", err))
 	} else {
 		if err := os.WriteFile(daemonTimestampFile, []byte(timestamps), 0644); err != nil {
@@ -186,8 +186,8 @@ func (d *PreloadDaemon) refreshAll() {
 // refreshPackageAppStatus refreshes the status of all package-based apps
 // This is Pacman-specific and monitors Pacman database changes
 func (d *PreloadDaemon) refreshPackageAppStatus() error {
-	preloadDir := filepath.Join(d.directory, "data", "preload")
-	if err := os.MkdirAll(preloadDir, 0755); err != nil {
+	preloadDir := filepath.Join(api.DataDirOf(d.directory), "preload")
+	if err := api.EnsureDirOwned(preloadDir, api.InvokingUser, api.InvokingUser); err != nil {
 		logger.Error(api.Tf("failed to create preload directory: %v\n", err))
 		return fmt.Errorf("failed to create preload directory: %w", err)
 	}
@@ -19,7 +19,7 @@
 // This replaces the bash preload-daemon script functionality for the Go rewrite.
 // SPDX-License-Identifier: GPL-3.0-or-later
 
-//go:build dummy
+//go:build dummy && !nogui
 
 package gui
 
@@ -147,7 +147,7 @@ func (d *PreloadDaemon) refreshAll() {
 	timestamps := tc.GetTimestamps()
 
 	// Check if anything has changed since last daemon run
-	daemonTimestampFile := filepath.Join(d.directory, "data", "preload", "timestamps-preload-daemon")
+	daemonTimestampFile := filepath.Join(api.DataDirOf(d.directory), "preload", "timestamps-preload-daemon")
 	savedTimestamps, err := os.ReadFile(daemonTimestampFile)
 	if err == nil && string(savedTimestamps) == timestamps {
 		logger.Info(api.T("Preload-daemon skipped; nothing was changed"))
@@ -171,8 +171,8 @@ func (d *PreloadDaemon) refreshAll() {
 	}
 
 	// Save the current timestamps
-	preloadDir := filepath.Join(d.directory, "data", "preload")
-	if err := os.MkdirAll(preloadDir, 0755); err != nil {
+	preloadDir := filepath.Join(api.DataDirOf(d.directory), "preload")
+	if err := api.EnsureDirOwned(preloadDir, api.InvokingUser, api.InvokingUser); err != nil {
 		logger.Warn(api.Tf("failed to create preload directory: %v\n", err))
 	} else {
 		if err := os.WriteFile(daemonTimestampFile, []byte(timestamps), 0644); err != nil {
@@ -186,9 +186,9 @@ func (d *PreloadDaemon) refreshAll() {
 // refreshPackageAppStatus (stub): returns a stub status file for testing/non-APT builds
 func (d *PreloadDaemon) refreshPackageAppStatus() error {
 	// Write a stub file indicating this function was called
-	preloadDir := filepath.Join(d.directory, "data", "preload")
+	preloadDir := filepath.Join(api.DataDirOf(d.directory), "preload")
 	stubFile := filepath.Join(preloadDir, "timestamps-dpkg-status")
-	if err := os.MkdirAll(preloadDir, 0755); err != nil {
+	if err := api.EnsureDirOwned(preloadDir, api.InvokingUser, api.InvokingUser); err != nil {
 		logger.Error(fmt.Sprintf("failed to create preload directory for stub: %v\n", err))
 		return fmt.Errorf("failed to create preload directory for stub: %w", err)
 	}
@@ -0,0 +1,318 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: progress_tray.go
+// Description: Shows the progress of the manage daemon in the system tray and on the Pi-Apps launcher of docks,
+// so long installs stay visible while the terminal and the progress window are behind other windows.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+//go:build !nogui
+
+package gui
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"fyne.io/systray"
+	"github.com/godbus/dbus/v5"
+	"github.com/gotk3/gotk3/glib"
+	"github.com/gotk3/gotk3/gtk"
+	"github.com/pi-apps-go/pi-apps/pkg/api"
+)
+
+// launcherEntryURI is the desktop file the Pi-Apps install script creates, docks match the progress to its launcher
+const launcherEntryURI = "application://pi-apps-go.desktop"
+
+// trayProgressEnabled reports whether the "Show progress in tray" setting is Yes
+var trayProgressEnabled = sync.OnceValue(func() bool {
+	return api.ReadSetting("Show progress in tray") == "Yes"
+})
+
+// progressTray is the tray icon and launcher progress of the progress monitor. It uses a StatusNotifierItem
+// where the desktop has a StatusNotifierWatcher, and the legacy GtkStatusIcon for XEmbed trays otherwise.
+// On desktops without either, nothing shows up and nothing complains. A nil progressTray does nothing.
+type progressTray struct {
+	window   *gtk.Window
+	launcher *dbus.Conn // private session bus connection for the launcher progress, nil without a session bus
+
+	sniEnd     func()        // stops the StatusNotifierItem, nil when the legacy icon is used
+	legacyIcon *glib.Object  // the GtkStatusIcon, nil when the StatusNotifierItem is used
+	legacyMenu *gtk.Menu     // menu of the legacy icon
+	cancelItem *gtk.MenuItem // cancel entry of the legacy menu
+
+	mutex      sync.Mutex
+	sniCancel  *systray.MenuItem // cancel entry of the StatusNotifierItem menu, nil until it is built
+	current    QueueItem         // item in progress, without an AppName when none is
+	iconPath   string            // icon shown for the current item
+	visible    bool
+	lastUpdate string // tooltip of the last update, to only send changes over D-Bus
+}
+
+// newProgressTray shows the progress of the daemon queue in the tray if the "Show progress in tray" setting is Yes.
+// Its menu brings window to the front and cancels the item in progress.
+func newProgressTray(window *gtk.Window) *progressTray {
+	if !trayProgressEnabled() {
+		return nil
+	}
+	tray := &progressTray{window: window}
+
+	conn, err := dbus.ConnectSessionBus()
+	if err != nil {
+		api.Debug(fmt.Sprintf("No session bus for the tray icon and launcher progress: %v", err))
+	} else {
+		tray.launcher = conn
+	}
+
+	if tray.launcher != nil && statusNotifierWatcherRunning(tray.launcher) {
+		start, end := systray.RunWithExternalLoop(tray.buildSNIMenu, nil)
+		start()
+		tray.sniEnd = end
+	} else {
+		tray.buildLegacyIcon()
+	}
+	return tray
+}
+
+// statusNotifierWatcherRunning reports whether the desktop shows StatusNotifierItems, like KDE, LXQt,
+// wf-panel-pi and GNOME with the AppIndicator extension
+func statusNotifierWatcherRunning(conn *dbus.Conn) bool {
+	var running bool
+	err := conn.BusObject().Call("org.freedesktop.DBus.NameHasOwner", 0, "org.kde.StatusNotifierWatcher").Store(&running)
+	return err == nil && running
+}
+
+// buildSNIMenu builds the menu of the StatusNotifierItem, systray calls it once it is registered
+func (t *progressTray) buildSNIMenu() {
+	systray.SetTitle("Pi-Apps")
+	show := systray.AddMenuItem(api.T("Show progress"), api.T("Bring the progress window to the front"))
+	cancel := systray.AddMenuItem(api.T("Cancel"), api.T("Stop the operation in progress"))
+	cancel.Disable()
+	systray.SetOnTapped(func() { glib.IdleAdd(t.showWindow) })
+
+	t.mutex.Lock()
+	t.sniCancel = cancel
+	t.mutex.Unlock()
+
+	go func() {
+		for {
+			select {
+			case _, ok := <-show.ClickedCh:
+				if !ok {
+					return
+				}
+				glib.IdleAdd(t.showWindow)
+			case _, ok := <-cancel.ClickedCh:
+				if !ok {
+					return
+				}
+				glib.IdleAdd(t.confirmCancel)
+			}
+		}
+	}()
+}
+
+// buildLegacyIcon creates the GtkStatusIcon and its menu, hidden until something is in progress
+func (t *progressTray) buildLegacyIcon() {
+	t.legacyIcon = newLegacyStatusIcon()
+	if t.legacyIcon == nil {
+		return
+	}
+	t.legacyIcon.SetProperty("visible", false)
+	t.legacyIcon.SetProperty("title", "Pi-Apps")
+
+	menu, err := gtk.MenuNew()
+	if err != nil {
+		return
+	}
+	show, err := gtk.MenuItemNewWithLabel(api.T("Show progress"))
+	if err != nil {
+		return
+	}
+	show.Connect("activate", t.showWindow)
+	menu.Append(show)
+	t.cancelItem, err = gtk.MenuItemNewWithLabel(api.T("Cancel"))
+	if err != nil {
+		return
+	}
+	t.cancelItem.Connect("activate", t.confirmCancel)
+	t.cancelItem.SetSensitive(false)
+	menu.Append(t.cancelItem)
+	menu.ShowAll()
+	t.legacyMenu = menu
+
+	t.legacyIcon.Connect("activate", t.showWindow)
+	t.legacyIcon.Connect("popup-menu", func() {
+		t.legacyMenu.PopupAtPointer(nil)
+	})
+}
+
+// showWindow brings the progress window to the front
+func (t *progressTray) showWindow() {
+	t.window.Deiconify()
+	t.window.Present()
+}
+
+// confirmCancel asks whether to cancel the item in progress and tells the daemon to stop it
+func (t *progressTray) confirmCancel() {
+	t.mutex.Lock()
+	current := t.current
+	t.mutex.Unlock()
+	if current.AppName == "" {
+		return
+	}
+	if !showConfirmDialog(glib.MarkupEscapeText(api.Tf("Stop %s? It may be left half installed.", current.AppName))) {
+		return
+	}
+	if err := SendQueueCommand(QueueCommand{Verb: "cancel", ID: current.ID}); err != nil {
+		api.WarningTf("Failed to cancel %s: %v", current.AppName, err)
+	}
+}
+
+// Update shows the progress of the queue. started holds when each install in progress started, for the ETA.
+func (t *progressTray) Update(queue []QueueItem, started map[string]time.Time) {
+	if t == nil {
+		return
+	}
+	progress, current, active := queueProgress(queue)
+
+	tooltip := ""
+	if active {
+		tooltip = api.Tf("%d%% done", int(progress*100))
+		if current.AppName != "" {
+			tooltip = current.AppName + "\n" + api.ActionProgressText(current.Action) + "\n" + tooltip
+		}
+		if remaining, known := QueueRemainingEstimate(queue, started); known {
+			tooltip += ", " + api.Tf("about %s left", api.FormatApproxDuration(remaining))
+		}
+	}
+
+	t.mutex.Lock()
+	changed := tooltip != t.lastUpdate || active != t.visible
+	t.lastUpdate, t.visible = tooltip, active
+	t.current = current
+	iconChanged := active && current.AppName != "" && getAppIconPath(current.AppName) != t.iconPath
+	if iconChanged {
+		t.iconPath = getAppIconPath(current.AppName)
+	}
+	sniCancel := t.sniCancel
+	t.mutex.Unlock()
+	if !changed && !iconChanged {
+		return
+	}
+
+	t.setLauncherProgress(progress, active)
+
+	if t.sniEnd != nil {
+		if iconChanged {
+			if err := systray.SetIconFromFilePath(t.iconPath); err != nil {
+				api.Debug("Failed to set the tray icon: " + err.Error())
+			}
+		}
+		systray.SetTooltip(tooltip)
+		if sniCancel != nil {
+			if current.AppName != "" {
+				sniCancel.SetTitle(api.Tf("Cancel %s", current.AppName))
+				sniCancel.Enable()
+			} else {
+				sniCancel.SetTitle(api.T("Cancel"))
+				sniCancel.Disable()
+			}
+		}
+		return
+	}
+
+	if t.legacyIcon != nil {
+		if iconChanged {
+			t.legacyIcon.SetProperty("file", t.iconPath)
+		}
+		t.legacyIcon.SetProperty("tooltip-text", tooltip)
+		t.legacyIcon.SetProperty("visible", active)
+		if t.cancelItem != nil {
+			if current.AppName != "" {
+				t.cancelItem.SetLabel(api.Tf("Cancel %s", current.AppName))
+			}
+			t.cancelItem.SetSensitive(current.AppName != "")
+		}
+	}
+}
+
+// setLauncherProgress shows the progress on the Pi-Apps launcher of docks that support the Unity LauncherEntry API,
+// like Plank, Dash to Dock and the KDE task manager
+func (t *progressTray) setLauncherProgress(progress float64, visible bool) {
+	if t.launcher == nil {
+		return
+	}
+	properties := map[string]dbus.Variant{
+		"progress":         dbus.MakeVariant(progress),
+		"progress-visible": dbus.MakeVariant(visible),
+	}
+	if err := t.launcher.Emit("/com/canonical/unity/launcherentry/pi_apps_go", "com.canonical.Unity.LauncherEntry.Update", launcherEntryURI, properties); err != nil {
+		api.Debug("Failed to update the launcher progress: " + err.Error())
+	}
+}
+
+// Close removes the tray icon and the launcher progress
+func (t *progressTray) Close() {
+	if t == nil {
+		return
+	}
+	t.setLauncherProgress(0, false)
+	if t.sniEnd != nil {
+		t.sniEnd()
+	}
+	if t.legacyIcon != nil {
+		t.legacyIcon.SetProperty("visible", false)
+	}
+	if t.launcher != nil {
+		t.launcher.Close()
+	}
+}
+
+// queueProgress returns how far the queue is, from 0 to 1, and the item in progress. A refresh or file
+// update in progress counts with the part of its files that is done.
+//
+//	float64 - the part of the queue that is done
+//	QueueItem - the item in progress
+//	bool - false if nothing is waiting or in progress
+func queueProgress(queue []QueueItem) (float64, QueueItem, bool) {
+	var current QueueItem
+	total, done := 0, 0.0
+	active := false
+	for _, item := range queue {
+		switch item.Status {
+		case "daemon-complete":
+			continue
+		case "waiting":
+			active = true
+		case "in-progress":
+			active = true
+			current = item
+			var filesDone, filesTotal int
+			if _, err := fmt.Sscanf(item.Progress, "%d/%d", &filesDone, &filesTotal); err == nil && filesTotal > 0 {
+				done += float64(filesDone) / float64(filesTotal)
+			}
+		default:
+			done++
+		}
+		total++
+	}
+	if total == 0 {
+		return 0, current, false
+	}
+	return done / float64(total), current, active
+}
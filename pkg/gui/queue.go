@@ -0,0 +1,391 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: queue.go
+// Description: Provides the manage queue types, the app list refresh after queue items and the terminal output used when no GUI is available.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package gui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pi-apps-go/pi-apps/pkg/api"
+	"golang.org/x/term"
+)
+
+// QueueItem represents an item in the installation/uninstallation queue
+type QueueItem struct {
+	ID             int    // stable ID of the item in the daemon queue, also when the same app is queued twice
+	Action         string // install, uninstall, update, refresh
+	AppName        string
+	Status         string // waiting, in-progress, success, failure
+	IconPath       string
+	ErrorMessage   string    // Error message if the operation failed
+	ExitCode       int       // Exit code of the failed operation, see api.FailureExitCode and api.ExitCodeReason
+	Progress       string    // Files copied so far by a running refresh or file update, e.g. 3/17
+	Phase          string    // what the running operation is doing, see api.QueuePhase, empty if it didn't say
+	Percent        int       // how much of Phase is done, -1 when unknown
+	Started        time.Time // when the operation started, zero while it waits
+	Finished       time.Time // when the operation finished, zero until then
+	LogFile        string    // log file the operation wrote, empty if it wrote none
+	ForceReinstall bool
+}
+
+// StatusIconMapping maps status to icon paths
+var StatusIconMapping = map[string]string{
+	"waiting":         "icons/wait.png",
+	"in-progress":     "icons/prompt.png",
+	"success":         "icons/success.png",
+	"failure":         "icons/failure.png",
+	"diagnosed":       "icons/failure.png", // Use failure icon for diagnosed items
+	StatusSkipped:     "icons/info.png",
+	StatusSuperseded:  "icons/info.png",
+	"daemon-complete": "icons/success.png", // Use success icon for daemon completion
+}
+
+// ActionIconMapping maps actions to icon paths
+var ActionIconMapping = map[string]string{
+	"install":     "icons/install.png",
+	"uninstall":   "icons/uninstall.png",
+	"update":      "icons/update.png",
+	"refresh":     "icons/refresh.png",
+	"update-file": "icons/update.png",
+	"daemon":      "icons/none-24.png", // Special daemon completion marker
+}
+
+// capitalize capitalizes the first letter of a string
+func capitalize(s string) string {
+	if len(s) == 0 {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+// progressMonitorCLI provides a simple CLI-based progress monitor
+func progressMonitorCLI(queue []QueueItem) error {
+	fmt.Println(api.T("\n=== Progress Monitor ==="))
+	fmt.Println(api.T("The following operations will be performed:"))
+
+	for _, item := range queue {
+		fmt.Printf("%s %s: %s\n",
+			strings.ToUpper(item.Action),
+			item.AppName,
+			strings.ToUpper(item.Status))
+	}
+
+	fmt.Println(api.T("\nPress Ctrl+C to cancel"))
+	return nil
+}
+
+// showSummaryDialogCLI shows a summary of completed actions in CLI
+func showSummaryDialogCLI(completedQueue []QueueItem) error {
+	fmt.Println(api.T("\n=== Operations Complete ==="))
+	fmt.Println(api.T("Thank you for using Pi-Apps! The following actions completed:"))
+
+	for _, item := range completedQueue {
+		var actionText string
+		switch item.Status {
+		case "success":
+			actionText = api.ActionDoneText(item.Action)
+		case "failure":
+			actionText = api.ActionFailedText(item.Action)
+			if reason := api.ExitCodeReason(item.ExitCode); reason != "" {
+				actionText += "\n  " + reason
+			}
+			if item.LogFile != "" {
+				actionText += "\n  " + api.Tf("Log file: %s", item.LogFile)
+			}
+		case StatusSkipped, StatusSuperseded:
+			actionText = skippedText(item)
+		default:
+			actionText = api.Tf("%s status: %s", capitalize(item.Action), item.Status)
+		}
+
+		if api.StatusSymbolsEnabled() {
+			switch item.Status {
+			case "success":
+				actionText = api.SymbolSuccess + " " + actionText
+			case "failure":
+				actionText = api.SymbolFailure + " " + actionText
+			}
+		}
+
+		fmt.Printf("%s: %s\n", item.AppName, actionText)
+	}
+
+	fmt.Println(api.T("\nDonations:"))
+	fmt.Println(api.Tf("- Botspot (Pi-Apps founder): https://github.com/sponsors/botspot"))
+	fmt.Println(api.Tf("- theofficialgman (Pi-Apps contributor): https://github.com/sponsors/theofficialgman"))
+	fmt.Println(api.Tf("- Pi-Apps Go developers: https://github.com/sponsors/matu6968"))
+
+	return nil
+}
+
+// showBrokenPackagesDialogCLI asks for sudo password in CLI
+func showBrokenPackagesDialogCLI() (string, error) {
+	fmt.Println(api.T("\n=== Broken Local Packages Repo Detected ==="))
+	fmt.Println(api.T("Please enter your user password to repair:"))
+	fmt.Println(api.T("Password will not be visible as you type."))
+
+	// Use secure password input
+	passwordBytes, err := term.ReadPassword(int(os.Stdin.Fd()))
+	if err != nil {
+		return "", fmt.Errorf("failed to read password: %v", err)
+	}
+	fmt.Println() // Add newline after password input
+
+	password := string(passwordBytes)
+	if password == "" {
+		return "", fmt.Errorf("canceled by user")
+	}
+
+	return password, nil
+}
+
+// showConflictDialogCLI asks in the terminal whether to uninstall the apps an app conflicts with before installing it.
+// Without a terminal to answer, the install is skipped.
+func showConflictDialogCLI(app string, conflicts []string) bool {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return false
+	}
+	fmt.Print(api.Tf("\n%s conflicts with the installed app(s) %s. Uninstall them first and then install %s? (y/n): ", app, strings.Join(conflicts, ", "), app))
+	var response string
+	fmt.Scanln(&response)
+	response = strings.ToLower(response)
+	return response == "y" || response == "yes"
+}
+
+// ConfirmConflictUninstall asks whether to uninstall the installed apps an app conflicts with before installing it,
+// with a dialog if useGUI is set and in the terminal otherwise
+func ConfirmConflictUninstall(app string, conflicts []string, useGUI bool) bool {
+	if useGUI {
+		return ShowConflictDialog(app, conflicts)
+	}
+	return showConflictDialogCLI(app, conflicts)
+}
+
+// Answers to the question whether to uninstall an app installed apps depend on
+const (
+	DependentsSkip    = "skip"    // keep the app
+	DependentsCascade = "cascade" // uninstall the apps that depend on it first
+	DependentsForce   = "force"   // uninstall only the app, leaving the apps that depend on it broken
+)
+
+// dependentsNote returns the message about the apps that depend on an app about to be uninstalled
+func dependentsNote(app string, dependents, corrupted []string) string {
+	var message string
+	if len(dependents) > 0 {
+		message = api.Tf("%s is needed by the installed app(s) %s.", app, strings.Join(dependents, ", "))
+	}
+	if len(corrupted) > 0 {
+		if message != "" {
+			message += "\n"
+		}
+		message += api.Tf("The corrupted app(s) %s also depend on %s and may stop working.", strings.Join(corrupted, ", "), app)
+	}
+	return message
+}
+
+// showDependentsDialogCLI asks in the terminal whether to uninstall the apps that depend on an app before uninstalling it.
+// Without a terminal to answer, the uninstall is skipped.
+func showDependentsDialogCLI(app string, dependents, corrupted []string) string {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return DependentsSkip
+	}
+	fmt.Println("\n" + dependentsNote(app, dependents, corrupted))
+	fmt.Print(api.Tf("Uninstall (a)ll of them, only %s (f)orce, or (n)othing? (a/f/n): ", app))
+	var response string
+	fmt.Scanln(&response)
+	switch strings.ToLower(response) {
+	case "a", "all":
+		return DependentsCascade
+	case "f", "force":
+		return DependentsForce
+	}
+	return DependentsSkip
+}
+
+// ConfirmDependentsUninstall asks whether to uninstall an app that installed apps depend on,
+// with a dialog if useGUI is set and in the terminal otherwise
+func ConfirmDependentsUninstall(app string, dependents, corrupted []string, useGUI bool) string {
+	if useGUI {
+		return ShowDependentsDialog(app, dependents, corrupted)
+	}
+	return showDependentsDialogCLI(app, dependents, corrupted)
+}
+
+// RefreshAfterQueueItem updates the app list entry of the app a finished queue item changed and logs how long it took.
+// File updates can add or remove apps and change categories, so they are left to RefreshAfterQueue.
+func RefreshAfterQueueItem(item QueueItem) {
+	if item.Action == "update-file" {
+		return
+	}
+	start := time.Now()
+	if err := api.RefreshAppEntry(item.AppName); err != nil {
+		fmt.Printf("Warning: failed to refresh app list entry of %s: %v\n", item.AppName, err)
+		return
+	}
+	fmt.Println(api.Tf("Refreshed app list entry of %s in %s", item.AppName, time.Since(start).Round(time.Millisecond)))
+}
+
+// RefreshAfterQueue regenerates the whole app list once the queue is finished if a file update succeeded
+func RefreshAfterQueue(queue []QueueItem) {
+	for _, item := range queue {
+		if item.Action != "update-file" || item.Status != "success" {
+			continue
+		}
+		start := time.Now()
+		if err := api.RefreshAppList(); err != nil {
+			fmt.Printf("Warning: failed to refresh app list: %v\n", err)
+			return
+		}
+		fmt.Println(api.Tf("Refreshed app list in %s", time.Since(start).Round(time.Millisecond)))
+		return
+	}
+}
+
+// QueueRemainingEstimate estimates how long the install operations of a queue still take. An install in progress
+// counts with the part of its estimate that is left since it started, as recorded in started by app name.
+//
+//	time.Duration - the estimated time left
+//	bool - false if none of the unfinished installs has an estimate
+func QueueRemainingEstimate(queue []QueueItem, started map[string]time.Time) (time.Duration, bool) {
+	var remaining time.Duration
+	known := false
+	for _, item := range queue {
+		if item.Action != "install" || (item.Status != "waiting" && item.Status != "in-progress") {
+			continue
+		}
+		estimate, confidence := api.EstimateInstallDuration(item.AppName)
+		if confidence == "" {
+			continue
+		}
+		known = true
+		if start, ok := started[item.AppName]; ok && item.Status == "in-progress" {
+			estimate = max(estimate-time.Since(start), 0)
+		}
+		remaining += estimate
+	}
+	return remaining, known
+}
+
+// QueueEstimateSummary describes how long the installs of a queue take, or returns "" unless the queue installs
+// more than one app and some of them have an estimate
+func QueueEstimateSummary(queue []QueueItem) string {
+	installs := 0
+	for _, item := range queue {
+		if item.Action == "install" {
+			installs++
+		}
+	}
+	if installs < 2 {
+		return ""
+	}
+	total, known := QueueRemainingEstimate(queue, nil)
+	if !known {
+		return ""
+	}
+	return api.Tf("Installing these %d apps typically takes %s on this device (approximate)", installs, api.FormatApproxDuration(total))
+}
+
+// unestimatedItemDuration is how long a queue item without an estimate is assumed to take, like an uninstall
+const unestimatedItemDuration = 30 * time.Second
+
+// queueItemEstimate estimates how long a queue item takes, a variable so tests can use their own estimates
+var queueItemEstimate = func(item QueueItem) (time.Duration, bool) {
+	if item.Action != "install" {
+		return 0, false
+	}
+	estimate, confidence := api.EstimateInstallDuration(item.AppName)
+	return estimate, confidence != "" && estimate > 0
+}
+
+// QueueProgress is the overall progress of a queue, see EstimateQueueProgress
+type QueueProgress struct {
+	Percent   float64       // how much of the estimated duration of the queue is done, 0 to 100
+	Remaining time.Duration // how long the unfinished items still take
+	Known     bool          // false if none of the unfinished items has an estimate, Remaining is a guess then
+	Pace      float64       // how much longer than estimated the items took so far, 1 until one finished
+}
+
+// EstimateQueueProgress computes the overall progress of a queue at now, every item weighted by its estimated
+// duration. Items that took longer or shorter than estimated change the pace, which the items still waiting are
+// scaled by, so the remaining time follows how fast the queue actually goes.
+func EstimateQueueProgress(queue []QueueItem, now time.Time) QueueProgress {
+	type weightedItem struct {
+		item     QueueItem
+		estimate time.Duration
+		elapsed  time.Duration
+	}
+	var items []weightedItem
+	progress := QueueProgress{Pace: 1}
+	var actual, estimated time.Duration
+	for _, item := range queue {
+		if item.Status == "daemon-complete" || item.Status == StatusSkipped || item.Status == StatusSuperseded {
+			continue
+		}
+		estimate, ok := queueItemEstimate(item)
+		if !ok {
+			estimate = unestimatedItemDuration
+		}
+		unfinished := item.Status == "waiting" || item.Status == "in-progress"
+		if ok && unfinished {
+			progress.Known = true
+		}
+
+		var elapsed time.Duration
+		switch {
+		case item.Started.IsZero():
+		case item.Status == "in-progress":
+			elapsed = max(now.Sub(item.Started), 0)
+		case !unfinished && !item.Finished.IsZero():
+			elapsed = item.Finished.Sub(item.Started)
+		}
+		// Finished items tell how fast the queue goes, so does an item that is already taking longer than estimated
+		if ok && elapsed > 0 && (!unfinished || elapsed > estimate) {
+			actual += elapsed
+			estimated += estimate
+		}
+		items = append(items, weightedItem{item, estimate, elapsed})
+	}
+	if estimated > 0 {
+		progress.Pace = min(max(float64(actual)/float64(estimated), 0.2), 5)
+	}
+
+	var total, done float64
+	for _, weighted := range items {
+		total += float64(weighted.estimate)
+		expected := time.Duration(float64(weighted.estimate) * progress.Pace)
+		switch weighted.item.Status {
+		case "waiting":
+			progress.Remaining += expected
+		case "in-progress":
+			progress.Remaining += max(expected-weighted.elapsed, 0)
+			// An item isn't done before it says so
+			done += float64(weighted.estimate) * min(float64(weighted.elapsed)/float64(expected), 0.99)
+		default:
+			done += float64(weighted.estimate)
+		}
+	}
+	if total > 0 {
+		progress.Percent = done / total * 100
+	}
+	return progress
+}
@@ -0,0 +1,231 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: queue_edit.go
+// Description: Provides the version 1 daemon status file format and the commands that reorder or remove waiting items of the daemon queue.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package gui
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/pi-apps-go/pi-apps/pkg/api"
+)
+
+// QueueCommand changes the waiting items of the daemon queue, or cancels the item in progress.
+// Commands are sent through the daemon queue pipe as "move <id> up|down|top", "remove <id>" or "cancel <id>".
+type QueueCommand struct {
+	Verb      string // move, remove or cancel
+	ID        int    // ID of the queue item
+	Direction string // up, down or top, only for move
+}
+
+// String returns the command as it is written to the daemon queue pipe
+func (c QueueCommand) String() string {
+	if c.Verb == "move" {
+		return fmt.Sprintf("move %d %s", c.ID, c.Direction)
+	}
+	return fmt.Sprintf("%s %d", c.Verb, c.ID)
+}
+
+// ParseQueueCommand parses a line read from the daemon queue pipe
+//
+//	QueueCommand - the parsed command
+//	bool - false if the line is not a command but queue items to add
+//	error - error if the line is a malformed command
+func ParseQueueCommand(line string) (QueueCommand, bool, error) {
+	fields := strings.Fields(line)
+	if len(fields) < 2 || (fields[0] != "move" && fields[0] != "remove" && fields[0] != "cancel") {
+		return QueueCommand{}, false, nil
+	}
+
+	id, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return QueueCommand{}, true, fmt.Errorf("invalid queue item ID %q", fields[1])
+	}
+	command := QueueCommand{Verb: fields[0], ID: id}
+	switch command.Verb {
+	case "move":
+		if len(fields) != 3 || (fields[2] != "up" && fields[2] != "down" && fields[2] != "top") {
+			return QueueCommand{}, true, fmt.Errorf("usage: move <id> up|down|top")
+		}
+		command.Direction = fields[2]
+	case "remove", "cancel":
+		if len(fields) != 2 {
+			return QueueCommand{}, true, fmt.Errorf("usage: %s <id>", command.Verb)
+		}
+	}
+	return command, true, nil
+}
+
+// ApplyQueueCommand applies a command to the queue. Only waiting items can be moved or removed,
+// and they only change places with other waiting items.
+func ApplyQueueCommand(queue []QueueItem, command QueueCommand) ([]QueueItem, error) {
+	index := -1
+	for i, item := range queue {
+		if item.ID == command.ID {
+			index = i
+			break
+		}
+	}
+	if index == -1 {
+		return queue, fmt.Errorf("no queue item with ID %d", command.ID)
+	}
+	if queue[index].Status != "waiting" {
+		return queue, fmt.Errorf("%s %s has already started", queue[index].Action, queue[index].AppName)
+	}
+
+	// Indexes of the other waiting items, in queue order
+	var waiting []int
+	position := 0
+	for i, item := range queue {
+		if item.Status != "waiting" {
+			continue
+		}
+		if i == index {
+			position = len(waiting)
+		}
+		waiting = append(waiting, i)
+	}
+
+	switch {
+	case command.Verb == "remove":
+		return append(queue[:index:index], queue[index+1:]...), nil
+	case command.Direction == "up" && position > 0:
+		other := waiting[position-1]
+		queue[index], queue[other] = queue[other], queue[index]
+	case command.Direction == "down" && position < len(waiting)-1:
+		other := waiting[position+1]
+		queue[index], queue[other] = queue[other], queue[index]
+	case command.Direction == "top" && position > 0:
+		// Shift the waiting items before it down by one, keeping the other items in place
+		item := queue[index]
+		for i := position; i > 0; i-- {
+			queue[waiting[i]] = queue[waiting[i-1]]
+		}
+		queue[waiting[0]] = item
+	}
+	return queue, nil
+}
+
+// CancelQueueItem stops the item of the queue with an ID if it is in progress. Its script fails with
+// errs.ErrCancelled, so the daemon goes on with the next item.
+func CancelQueueItem(queue []QueueItem, id int) error {
+	for _, item := range queue {
+		if item.ID != id {
+			continue
+		}
+		if item.Status != "in-progress" {
+			return fmt.Errorf("%s %s is not in progress", item.Action, item.AppName)
+		}
+		if !api.CancelRunningScript() {
+			return fmt.Errorf("%s %s has no script running that can be cancelled", item.Action, item.AppName)
+		}
+		return nil
+	}
+	return fmt.Errorf("no queue item with ID %d", id)
+}
+
+// NextQueueID returns an ID that no item of the queue uses yet
+func NextQueueID(queue []QueueItem) int {
+	next := 1
+	for _, item := range queue {
+		if item.ID >= next {
+			next = item.ID + 1
+		}
+	}
+	return next
+}
+
+// SendQueueCommand sends a command to the running manage daemon
+func SendQueueCommand(command QueueCommand) error {
+	queuePipe := filepath.Join(api.GetDataDir(), "manage-daemon", "queue")
+	info, err := os.Stat(queuePipe)
+	if err != nil || info.Mode()&os.ModeNamedPipe == 0 {
+		return fmt.Errorf("the manage daemon is not running")
+	}
+
+	// Don't block when nobody reads the pipe anymore
+	file, err := os.OpenFile(queuePipe, os.O_WRONLY|syscall.O_NONBLOCK, 0)
+	if err != nil {
+		if errors.Is(err, syscall.ENXIO) {
+			return fmt.Errorf("the manage daemon is not running")
+		}
+		return fmt.Errorf("failed to open queue pipe: %w", err)
+	}
+	defer file.Close()
+
+	// A daemon from the previous release only understands the old format, and can't cancel items
+	message := command.String() + "\n"
+	protocol := DaemonProtocolVersion(filepath.Join(filepath.Dir(queuePipe), "status"))
+	if command.Verb == "cancel" && protocol < 2 {
+		return fmt.Errorf("the running manage daemon can't cancel operations")
+	}
+	if protocol >= 2 {
+		if message, err = formatQueueCommandMessage(command); err != nil {
+			return err
+		}
+	}
+	// Messages shorter than PIPE_BUF are written to the pipe in one piece
+	if _, err := file.WriteString(message); err != nil {
+		return fmt.Errorf("failed to write to queue pipe: %w", err)
+	}
+	return nil
+}
+
+// FormatQueueStatusLine formats a queue item as a line of the version 1 daemon status file:
+// id;action;app;status;icon;exit code;progress;error
+// The format has no escaping, newlines in the error message are replaced so they don't start a new record.
+func FormatQueueStatusLine(item QueueItem) string {
+	errorMessage := strings.ReplaceAll(item.ErrorMessage, "\n", " ")
+	return fmt.Sprintf("%d;%s;%s;%s;%s;%d;%s;%s", item.ID, item.Action, item.AppName, item.Status, item.IconPath, item.ExitCode, item.Progress, errorMessage)
+}
+
+// ParseQueueStatusLine parses a line of the version 1 daemon status file, ok is false if the line is malformed
+func ParseQueueStatusLine(line string) (QueueItem, bool) {
+	parts := strings.SplitN(line, ";", 8)
+	if len(parts) < 5 {
+		return QueueItem{}, false
+	}
+	id, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return QueueItem{}, false
+	}
+	item := QueueItem{
+		ID:       id,
+		Action:   parts[1],
+		AppName:  parts[2],
+		Status:   parts[3],
+		IconPath: parts[4],
+	}
+	if len(parts) >= 6 {
+		item.ExitCode, _ = strconv.Atoi(parts[5])
+	}
+	if len(parts) >= 7 {
+		item.Progress = parts[6]
+	}
+	if len(parts) == 8 {
+		item.ErrorMessage = parts[7]
+	}
+	return item, true
+}
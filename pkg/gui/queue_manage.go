@@ -0,0 +1,442 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: queue_manage.go
+// Description: Parses, validates, normalizes and orders the queue of the manage daemon.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package gui
+
+import (
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/pi-apps-go/pi-apps/pkg/api"
+)
+
+// Statuses of queue items that never run because of another item in the queue, the reason is in ErrorMessage
+const (
+	StatusSkipped    = "skipped"    // the same action on the app is already waiting or in progress
+	StatusSuperseded = "superseded" // an install and an uninstall of the same app cancelled each other out
+)
+
+// ParseQueue parses a queue string with one operation per line into waiting queue items
+func ParseQueue(queueStr string) []QueueItem {
+	if queueStr == "" {
+		return nil
+	}
+
+	var queue []QueueItem
+	lines := strings.Split(strings.TrimSpace(queueStr), "\n")
+
+	for _, line := range lines {
+		if line == "" {
+			continue
+		}
+
+		// Both the "action;app" and the "action app" form are accepted, see api.ParseQueueLine
+		action, appName, _ := api.ParseQueueLine(line)
+		if item, ok := ParseQueueEntry(action, appName); ok {
+			queue = append(queue, item)
+		}
+	}
+
+	return queue
+}
+
+// QueueLines returns a queue in the "action;app" form, which parses the same way again
+func QueueLines(queue []QueueItem) string {
+	lines := make([]string, len(queue))
+	for i, item := range queue {
+		lines[i] = api.QueueLine(item.Action, item.AppName)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ParseQueueEntry returns the waiting queue item of an action and app, ok is false if either is missing or the app name is invalid
+func ParseQueueEntry(action, appName string) (QueueItem, bool) {
+	if action == "" || appName == "" {
+		return QueueItem{}, false
+	}
+
+	// update-file takes file names instead of an app name
+	if action != "update-file" {
+		if err := api.ValidateAppName(appName); err != nil {
+			api.WarningTf("Skipping queue entry '%s': %v", action+" "+appName, err)
+			return QueueItem{}, false
+		}
+	}
+	return newQueueItem(action, appName), true
+}
+
+// newQueueItem returns a waiting queue item with the icon of the app
+func newQueueItem(action, appName string) QueueItem {
+	// Get icon path - check for deprecated apps first
+	var iconPath string
+	if api.IsDeprecatedApp(appName) {
+		iconPath = api.GetDeprecatedAppIcon(appName)
+		if iconPath == "" {
+			iconPath = filepath.Join(api.GetPiAppsDir(), "icons", "none-64.png")
+		}
+	} else {
+		iconPath = filepath.Join(api.GetPiAppsDir(), "apps", appName, "icon-64.png")
+		if _, err := os.Stat(iconPath); os.IsNotExist(err) {
+			iconPath = filepath.Join(api.GetPiAppsDir(), "icons", "none-64.png")
+		}
+	}
+
+	return QueueItem{
+		Action:   action,
+		AppName:  appName,
+		Status:   "waiting",
+		IconPath: iconPath,
+	}
+}
+
+// ValidateQueue drops the waiting items that can't run, showing dialogs for errors if useGUI is true.
+// Items that are not waiting, like the ones NormalizeQueue skipped, are kept as they are.
+func ValidateQueue(queue []QueueItem, useGUI bool) []QueueItem {
+	piAppsDir := api.GetPiAppsDir()
+	var validQueue []QueueItem
+
+	for _, item := range queue {
+		if item.Status != "waiting" {
+			validQueue = append(validQueue, item)
+			continue
+		}
+
+		// Check if action is valid
+		validActions := []string{"install", "uninstall", "update", "refresh", "update-file"}
+		if !slices.Contains(validActions, item.Action) {
+			errorMsg := fmt.Sprintf("Invalid action '%s' for app '%s', skipping", item.Action, item.AppName)
+			if useGUI {
+				ShowMessageDialog("Error", fmt.Sprintf("Invalid action: <b>%s</b>", item.Action), 3)
+			} else {
+				fmt.Println(errorMsg)
+			}
+			continue
+		}
+
+		// For update-file actions, skip app validation
+		if item.Action == "update-file" {
+			validQueue = append(validQueue, item)
+			continue
+		}
+
+		// Check if app exists
+		var appDir string
+		appExists := false
+		if item.Action == "update" || item.Action == "refresh" {
+			appDir = api.AppUpdateDir(item.AppName)
+			if _, err := os.Stat(appDir); err == nil {
+				appExists = true
+			}
+		} else {
+			appDir = filepath.Join(piAppsDir, "apps", item.AppName)
+			if _, err := os.Stat(appDir); err == nil {
+				appExists = true
+			} else if api.IsDeprecatedApp(item.AppName) {
+				// For deprecated apps, check if they have stored data
+				deprecatedDir := filepath.Join(api.DataDirOf(piAppsDir), "deprecated-apps", item.AppName)
+				if _, err := os.Stat(deprecatedDir); err == nil {
+					appExists = true
+					// For deprecated apps, only allow uninstall action
+					if item.Action != "uninstall" {
+						errorMsg := fmt.Sprintf("App '%s' is deprecated and can only be uninstalled, skipping", item.AppName)
+						if useGUI {
+							ShowMessageDialog("Error", fmt.Sprintf("App \"<b>%s</b>\" is deprecated and can only be uninstalled.", item.AppName), 3)
+						} else {
+							fmt.Println(errorMsg)
+						}
+						continue
+					}
+				}
+			}
+		}
+
+		if !appExists {
+			errorMsg := fmt.Sprintf("App '%s' does not exist, skipping", item.AppName)
+			if useGUI {
+				ShowMessageDialog("Error", fmt.Sprintf("Invalid app \"<b>%s</b>\". Cannot %s it.", item.AppName, item.Action), 3)
+			} else {
+				fmt.Println(errorMsg)
+			}
+			continue
+		}
+
+		// Kiosk mode would refuse the action once the queue runs, so it is dropped right away
+		if err := api.CheckKioskApp(api.Action(item.Action), item.AppName, false); err != nil {
+			if useGUI {
+				ShowMessageDialog("Error", html.EscapeString(err.Error()), 3)
+			} else {
+				fmt.Printf("%v, skipping\n", err)
+			}
+			continue
+		}
+
+		// Check for redundant operations
+		appStatus, err := api.GetAppStatus(item.AppName)
+		if err != nil {
+			// If we can't get status, continue with the operation
+			validQueue = append(validQueue, item)
+			continue
+		}
+
+		// An install after an uninstall of the queue reinstalls the app
+		reinstall := item.Action == "install" && queuedForUninstall(item.AppName, validQueue)
+		if (item.Action == "install" && appStatus == "installed" && !reinstall) ||
+			(item.Action == "uninstall" && appStatus == "uninstalled") {
+			// In GUI mode, this would typically be handled by ValidateAppsGUI, so just inform
+			fmt.Printf("App '%s' is already %sed, skipping\n", item.AppName, item.Action)
+			continue
+		}
+		// Note: corrupted apps are allowed to be both installed and uninstalled
+
+		// Refuse to install an app next to an app it conflicts with, unless the user agrees to uninstall that app first
+		if item.Action == "install" {
+			conflicts := pendingConflicts(item.AppName, validQueue)
+			if len(conflicts) > 0 {
+				if !ConfirmConflictUninstall(item.AppName, conflicts, useGUI) {
+					fmt.Println((&api.AppConflictError{App: item.AppName, Conflicts: conflicts}).Error() + ", skipping")
+					continue
+				}
+				for _, conflict := range conflicts {
+					validQueue = append(validQueue, newQueueItem("uninstall", conflict))
+				}
+			}
+		}
+
+		// Uninstall the installed apps that depend on an app before it, asking first unless they are queued already
+		if item.Action == "uninstall" {
+			if queuedForUninstall(item.AppName, validQueue) {
+				continue
+			}
+			dependents := pendingDependents(item.AppName, validQueue)
+			answer := DependentsCascade
+			if slices.ContainsFunc(dependents, func(dependent string) bool { return !queuedForUninstall(dependent, queue) }) {
+				corrupted, err := api.CorruptedReverseDepends(item.AppName)
+				if err != nil {
+					api.WarningTf("Failed to check the apps that depend on %s: %v", item.AppName, err)
+				}
+				answer = ConfirmDependentsUninstall(item.AppName, dependents, corrupted, useGUI)
+			}
+			switch answer {
+			case DependentsSkip:
+				fmt.Println((&api.AppDependentsError{App: item.AppName, Dependents: dependents}).Error() + ", skipping")
+				continue
+			case DependentsForce:
+				api.AllowUninstallWithDependents(item.AppName)
+			case DependentsCascade:
+				for _, dependent := range dependents {
+					validQueue = append(validQueue, newQueueItem("uninstall", dependent))
+				}
+			}
+		}
+
+		validQueue = append(validQueue, item)
+	}
+
+	return validQueue
+}
+
+// queuedForUninstall reports whether the queue uninstalls an app
+func queuedForUninstall(app string, queue []QueueItem) bool {
+	return slices.ContainsFunc(queue, func(item QueueItem) bool {
+		return item.Action == "uninstall" && item.AppName == app
+	})
+}
+
+// pendingDependents returns the installed apps that depend on an app that the queue doesn't uninstall yet,
+// in the order to uninstall them in
+func pendingDependents(app string, queue []QueueItem) []string {
+	installed, err := api.AppReverseDepends(app)
+	if err != nil {
+		api.WarningTf("Failed to check the apps that depend on %s: %v", app, err)
+		return nil
+	}
+	var dependents []string
+	for _, dependent := range installed {
+		if !queuedForUninstall(dependent, queue) {
+			dependents = append(dependents, dependent)
+		}
+	}
+	return dependents
+}
+
+// pendingConflicts returns the installed apps an app conflicts with that the queue doesn't uninstall yet
+func pendingConflicts(app string, queue []QueueItem) []string {
+	installed, err := api.InstalledConflicts(app)
+	if err != nil {
+		api.WarningTf("Failed to check the apps %s conflicts with: %v", app, err)
+		return nil
+	}
+	var conflicts []string
+	for _, conflict := range installed {
+		if !queuedForUninstall(conflict, queue) {
+			conflicts = append(conflicts, conflict)
+		}
+	}
+	return conflicts
+}
+
+// NormalizeQueue compares added items with the queue and with the added items before them, so the same
+// operation doesn't run twice in a row. Only the latest waiting or in-progress install or uninstall of an app counts:
+//   - an item with the same action as a waiting item is skipped, the waiting item runs once
+//   - an item with the same action as the item in progress is skipped, e.g. "Already being installed"
+//   - an install and an uninstall of the same app that would both wait cancel each other out, both are superseded
+//
+// Skipped and superseded items stay in the queue with the reason in ErrorMessage, so the progress monitor
+// and the summary show what happened to them.
+//
+//	[]QueueItem - the queue, with the waiting items an added item cancelled out marked as superseded
+//	[]QueueItem - the added items, the ones that won't run marked as skipped or superseded
+func NormalizeQueue(queue, added []QueueItem) ([]QueueItem, []QueueItem) {
+	queue = slices.Clone(queue)
+	added = slices.Clone(added)
+	for i := range added {
+		item := &added[i]
+		if item.Status != "waiting" {
+			continue
+		}
+		// The added items before this one are newer than the queue, look at the newest item first
+		var match *QueueItem
+		for j := i - 1; j >= 0 && match == nil; j-- {
+			match = pendingMatch(&added[j], *item)
+		}
+		// An uninstall followed by an install sent together reinstalls the app, like a repair does
+		reinstall := match != nil && match.Action == "uninstall" && item.Action == "install"
+		for j := len(queue) - 1; j >= 0 && match == nil; j-- {
+			match = pendingMatch(&queue[j], *item)
+		}
+
+		switch {
+		case match == nil, reinstall:
+		case match.Action != item.Action:
+			match.Status = StatusSuperseded
+			match.ErrorMessage = api.Tf("Cancelled out by a later %s", item.Action)
+			item.Status = StatusSuperseded
+			item.ErrorMessage = api.Tf("Cancelled out the waiting %s", match.Action)
+		case match.Status == "in-progress":
+			item.Status = StatusSkipped
+			item.ErrorMessage = alreadyRunningText(item.Action)
+		default:
+			item.Status = StatusSkipped
+			item.ErrorMessage = api.T("Already in the queue")
+		}
+	}
+	return queue, added
+}
+
+// pendingMatch returns other if it waits or is in progress and decides what happens to item: it has the same
+// action on the same app, or it waits and is the opposite install or uninstall
+func pendingMatch(other *QueueItem, item QueueItem) *QueueItem {
+	if other.AppName != item.AppName {
+		return nil
+	}
+	switch {
+	case other.Status != "waiting" && other.Status != "in-progress":
+		return nil
+	case other.Action == item.Action:
+		return other
+	case other.Status == "waiting" && oppositeActions(other.Action, item.Action):
+		return other
+	}
+	return nil
+}
+
+// oppositeActions reports whether one action undoes the other
+func oppositeActions(a, b string) bool {
+	return (a == "install" && b == "uninstall") || (a == "uninstall" && b == "install")
+}
+
+// skippedText describes a skipped or superseded item in the summary and the progress monitor,
+// e.g. "Install skipped: Already being installed"
+func skippedText(item QueueItem) string {
+	text := api.Tf("%s skipped", capitalize(item.Action))
+	if item.Status == StatusSuperseded {
+		text = api.Tf("%s superseded", capitalize(item.Action))
+	}
+	if item.ErrorMessage == "" {
+		return text
+	}
+	return text + ": " + item.ErrorMessage
+}
+
+// alreadyRunningText returns the reason an item is skipped while the same action runs
+func alreadyRunningText(action string) string {
+	switch action {
+	case "install":
+		return api.T("Already being installed")
+	case "uninstall":
+		return api.T("Already being uninstalled")
+	case "update", "update-file":
+		return api.T("Already being updated")
+	case "refresh":
+		return api.T("Already being refreshed")
+	}
+	return api.T("Already in progress")
+}
+
+// AddToQueue adds new items to the queue, putting new file updates and app refreshes ahead of the waiting items
+// and the other new items at the end. Items already in the queue keep their order, so reordering them from the
+// progress monitor is not undone by items added later.
+func AddToQueue(queue []QueueItem, added []QueueItem) []QueueItem {
+	// Split the new items by type, giving each a queue ID
+	var addedFileUpdates []QueueItem
+	var addedRefreshes []QueueItem
+	var addedOther []QueueItem
+
+	nextID := NextQueueID(queue)
+	for _, item := range added {
+		item.ID = nextID
+		nextID++
+		switch item.Action {
+		case "refresh":
+			addedRefreshes = append(addedRefreshes, item)
+		case "update-file":
+			addedFileUpdates = append(addedFileUpdates, item)
+		default:
+			addedOther = append(addedOther, item)
+		}
+	}
+
+	firstWaiting := len(queue)
+	for i, item := range queue {
+		if item.Status == "waiting" {
+			firstWaiting = i
+			break
+		}
+	}
+
+	// Reconstruct queue in priority order:
+	// 1. Items that already started or finished (unchanged)
+	// 2. New file updates
+	// 3. New app refreshes
+	// 4. Waiting items (unchanged)
+	// 5. Other new operations (installs/uninstalls)
+	reorderedQueue := make([]QueueItem, 0, len(queue)+len(added))
+	reorderedQueue = append(reorderedQueue, queue[:firstWaiting]...)
+	reorderedQueue = append(reorderedQueue, addedFileUpdates...)
+	reorderedQueue = append(reorderedQueue, addedRefreshes...)
+	reorderedQueue = append(reorderedQueue, queue[firstWaiting:]...)
+	reorderedQueue = append(reorderedQueue, addedOther...)
+
+	return reorderedQueue
+}
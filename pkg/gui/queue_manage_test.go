@@ -0,0 +1,224 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package gui
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// testAppNames are app names that used to be mangled somewhere between the queue and the script
+var testAppNames = []string{"Better Chromium", "Box64 (x86_64)", "Rock 'n' Roll", "Café", "日本語入力"}
+
+// newTestQueueDir creates a Pi-Apps directory with the test apps and points PI_APPS_DIR at it
+func newTestQueueDir(t *testing.T) string {
+	t.Helper()
+	directory, _ := newTestReportDir(t)
+	for _, app := range testAppNames {
+		if err := os.MkdirAll(filepath.Join(directory, "apps", app), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(directory, "apps", app, "description"), []byte(app+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return directory
+}
+
+// queueSummary returns the action, app and status of every queue item
+func queueSummary(queue []QueueItem) []string {
+	var summary []string
+	for _, item := range queue {
+		summary = append(summary, item.Action+"|"+item.AppName+"|"+item.Status)
+	}
+	return summary
+}
+
+func TestParseQueue(t *testing.T) {
+	newTestQueueDir(t)
+	var lines []string
+	for i, app := range testAppNames {
+		// Both forms of queue lines, the one QueueLine writes and the one users type
+		if i%2 == 0 {
+			lines = append(lines, "install;"+app)
+		} else {
+			lines = append(lines, "uninstall "+app)
+		}
+	}
+	lines = append(lines, "install;../../etc", "install;a/b", "install")
+
+	queue := ParseQueue(strings.Join(lines, "\n"))
+	var got []string
+	for _, item := range queue {
+		got = append(got, item.Action+"|"+item.AppName)
+	}
+	want := []string{"install|Better Chromium", "uninstall|Box64 (x86_64)", "install|Rock 'n' Roll", "uninstall|Café", "install|日本語入力"}
+	if !slices.Equal(got, want) {
+		t.Errorf("parseQueue = %q, want %q", got, want)
+	}
+
+	// The queue written for the daemon parses back to the same queue
+	if again := ParseQueue(QueueLines(queue)); !slices.Equal(again, queue) {
+		t.Errorf("ParseQueue(QueueLines(queue)) = %v, want %v", again, queue)
+	}
+}
+
+func TestPendingDependents(t *testing.T) {
+	directory := newTestQueueDir(t)
+	depends := map[string]string{
+		"Box64 (x86_64)": "",
+		"Café":           "Box64 (x86_64)\n",
+		"日本語入力":          "Café\n",
+	}
+	for app, content := range depends {
+		for name, data := range map[string]string{"install": "#!/bin/bash\n", "depends": content} {
+			if err := os.WriteFile(filepath.Join(directory, "apps", app, name), []byte(data), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := os.MkdirAll(filepath.Join(directory, "data", "status"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(directory, "data", "status", app), []byte("installed\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Dependents of dependents come first, so nothing is uninstalled while an installed app still needs it
+	if got := pendingDependents("Box64 (x86_64)", nil); !slices.Equal(got, []string{"日本語入力", "Café"}) {
+		t.Errorf("pendingDependents = %q, want [日本語入力 Café]", got)
+	}
+
+	queue := []QueueItem{{Action: "install", AppName: "日本語入力"}, {Action: "uninstall", AppName: "Café"}}
+	if !queuedForUninstall("Café", queue) || queuedForUninstall("日本語入力", queue) {
+		t.Error("queuedForUninstall confused the install and uninstall actions")
+	}
+	if got := pendingDependents("Box64 (x86_64)", queue); !slices.Equal(got, []string{"日本語入力"}) {
+		t.Errorf("pendingDependents with Café queued for uninstall = %q, want [日本語入力]", got)
+	}
+}
+
+func TestNormalizeQueue(t *testing.T) {
+	newTestQueueDir(t)
+
+	// The initial queue: the second install of an app is skipped, an install and an uninstall that both wait cancel out
+	_, queue := NormalizeQueue(nil, ParseQueue("install;Café\ninstall;Café\ninstall;Box64 (x86_64)\nuninstall;Box64 (x86_64)\nupdate;Café"))
+	want := []string{
+		"install|Café|waiting",
+		"install|Café|skipped",
+		"install|Box64 (x86_64)|superseded",
+		"uninstall|Box64 (x86_64)|superseded",
+		"update|Café|waiting",
+	}
+	if got := queueSummary(queue); !slices.Equal(got, want) {
+		t.Fatalf("initial queue = %q, want %q", got, want)
+	}
+	if queue[1].ErrorMessage != "Already in the queue" {
+		t.Errorf("note of the duplicate = %q", queue[1].ErrorMessage)
+	}
+	for i := range queue {
+		queue[i].ID = i + 1
+	}
+
+	// Items sent later are compared with the running queue
+	queue[0].Status = "in-progress"
+	queue, added := NormalizeQueue(queue, ParseQueue("install;Café\nupdate;Café\ninstall;日本語入力\nuninstall;日本語入力\nuninstall;Café"))
+	want = []string{
+		"install|Café|skipped",
+		"update|Café|skipped",
+		"install|日本語入力|superseded",
+		"uninstall|日本語入力|superseded",
+		"uninstall|Café|waiting",
+	}
+	if got := queueSummary(added); !slices.Equal(got, want) {
+		t.Fatalf("added items = %q, want %q", got, want)
+	}
+	if added[0].ErrorMessage != "Already being installed" {
+		t.Errorf("note of the install that runs already = %q", added[0].ErrorMessage)
+	}
+
+	// Installing the app again after the queued uninstall cancels out the uninstall, not the install in progress
+	queue = AddToQueue(queue, added)
+	queue, added = NormalizeQueue(queue, ParseQueue("install;Café"))
+	if got := queueSummary(added); !slices.Equal(got, []string{"install|Café|superseded"}) {
+		t.Errorf("install after the uninstall = %q, want it superseded", got)
+	}
+	queue = AddToQueue(queue, added)
+	for _, item := range queue {
+		if item.Action == "uninstall" && item.AppName == "Café" && item.Status != StatusSuperseded {
+			t.Errorf("the waiting uninstall of Café is %s, want superseded", item.Status)
+		}
+	}
+
+	// Skipped items are no failures, and the status file keeps their note
+	queue[0].Status = "success"
+	queue[4].Status = "success"
+	if success, exitCode := QueueResult(queue); !success || exitCode != 0 {
+		t.Errorf("QueueResult = %v, %d, want a success", success, exitCode)
+	}
+	statusFile := filepath.Join(t.TempDir(), "status")
+	if err := WriteQueueStatus(statusFile, queue); err != nil {
+		t.Fatal(err)
+	}
+	read, err := ReadQueueStatus(statusFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(queueSummary(read), queueSummary(queue)) || read[5].ErrorMessage != queue[5].ErrorMessage {
+		t.Errorf("status file = %q, want %q", queueSummary(read), queueSummary(queue))
+	}
+}
+
+func TestQueueKeepsReinstalls(t *testing.T) {
+	directory := newTestQueueDir(t)
+	if err := os.MkdirAll(filepath.Join(directory, "data", "status"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(directory, "data", "status", "Café"), []byte("installed\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// An uninstall followed by an install sent together is a reinstall, not a change of mind
+	_, queue := NormalizeQueue(nil, ParseQueue("uninstall;Café\ninstall;Café"))
+	want := []string{"uninstall|Café|waiting", "install|Café|waiting"}
+	if got := queueSummary(queue); !slices.Equal(got, want) {
+		t.Fatalf("reinstall queue = %q, want %q", got, want)
+	}
+
+	// The install is kept although Café is installed, since the queue uninstalls it first
+	if got := queueSummary(ValidateQueue(queue, false)); !slices.Equal(got, want) {
+		t.Errorf("validated reinstall queue = %q, want %q", got, want)
+	}
+}
+
+func TestValidateQueueKeepsSkippedItems(t *testing.T) {
+	newTestQueueDir(t)
+	queue := []QueueItem{
+		{Action: "install", AppName: "Missing", Status: "waiting"},
+		{Action: "install", AppName: "Café", Status: StatusSkipped, ErrorMessage: "Already in the queue"},
+	}
+	got := ValidateQueue(queue, false)
+	if len(got) != 1 || got[0].Status != StatusSkipped {
+		t.Errorf("ValidateQueue = %+v, want only the skipped item", got)
+	}
+	if text := skippedText(got[0]); !strings.Contains(text, "Already in the queue") {
+		t.Errorf("skippedText = %q, want the note", text)
+	}
+}
@@ -0,0 +1,168 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package gui
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pi-apps-go/pi-apps/pkg/api"
+)
+
+// withQueueEstimates makes the queue items of the apps take the given minutes
+func withQueueEstimates(t *testing.T, minutes map[string]int) {
+	t.Helper()
+	original := queueItemEstimate
+	queueItemEstimate = func(item QueueItem) (time.Duration, bool) {
+		estimate, ok := minutes[item.AppName]
+		return time.Duration(estimate) * time.Minute, ok
+	}
+	t.Cleanup(func() { queueItemEstimate = original })
+}
+
+func TestEstimateQueueProgress(t *testing.T) {
+	withQueueEstimates(t, map[string]int{"Zoom": 10, "Arduino": 20, "Scratch": 10})
+	start := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	at := func(minutes int) int64 { return start.Add(time.Duration(minutes) * time.Minute).UnixMilli() }
+
+	// The status files the daemon writes while it works through the queue, and when the monitor reads them
+	steps := []struct {
+		now       int
+		records   []string
+		percent   float64
+		remaining time.Duration
+		known     bool
+	}{
+		{
+			now: 0,
+			records: []string{
+				`{"type":"item","id":1,"action":"install","app":"Zoom","status":"waiting"}`,
+				`{"type":"item","id":2,"action":"install","app":"Arduino","status":"waiting"}`,
+				`{"type":"item","id":3,"action":"install","app":"Scratch","status":"waiting"}`,
+			},
+			percent: 0, remaining: 40 * time.Minute, known: true,
+		},
+		{
+			// Halfway through the estimate of Zoom
+			now: 5,
+			records: []string{
+				fmt.Sprintf(`{"type":"item","id":1,"action":"install","app":"Zoom","status":"in-progress","phase":"downloading","percent":45,"started":%d}`, at(0)),
+				`{"type":"item","id":2,"action":"install","app":"Arduino","status":"waiting"}`,
+				`{"type":"item","id":3,"action":"install","app":"Scratch","status":"waiting"}`,
+			},
+			percent: 12.5, remaining: 35 * time.Minute, known: true,
+		},
+		{
+			// Zoom took half its estimate, so the rest of the queue is expected to go twice as fast
+			now: 10,
+			records: []string{
+				fmt.Sprintf(`{"type":"item","id":1,"action":"install","app":"Zoom","status":"success","started":%d,"finished":%d}`, at(0), at(5)),
+				fmt.Sprintf(`{"type":"item","id":2,"action":"install","app":"Arduino","status":"in-progress","phase":"installing-packages","started":%d}`, at(5)),
+				`{"type":"item","id":3,"action":"install","app":"Scratch","status":"waiting"}`,
+			},
+			percent: 50, remaining: 10 * time.Minute, known: true,
+		},
+		{
+			// Arduino took 30 minutes, the queue took 35 minutes so far instead of 30
+			now: 40,
+			records: []string{
+				fmt.Sprintf(`{"type":"item","id":1,"action":"install","app":"Zoom","status":"success","started":%d,"finished":%d}`, at(0), at(5)),
+				fmt.Sprintf(`{"type":"item","id":2,"action":"install","app":"Arduino","status":"success","started":%d,"finished":%d}`, at(5), at(35)),
+				fmt.Sprintf(`{"type":"item","id":3,"action":"install","app":"Scratch","status":"in-progress","phase":"running-script","started":%d}`, at(35)),
+			},
+			percent: 30.0/40*100 + 10*(5/(10*35.0/30))/40*100, remaining: time.Duration(10*35.0/30*float64(time.Minute)) - 5*time.Minute, known: true,
+		},
+		{
+			// Scratch takes longer than estimated, it isn't done before it says so
+			now: 60,
+			records: []string{
+				fmt.Sprintf(`{"type":"item","id":1,"action":"install","app":"Zoom","status":"success","started":%d,"finished":%d}`, at(0), at(5)),
+				fmt.Sprintf(`{"type":"item","id":2,"action":"install","app":"Arduino","status":"success","started":%d,"finished":%d}`, at(5), at(35)),
+				fmt.Sprintf(`{"type":"item","id":3,"action":"install","app":"Scratch","status":"in-progress","phase":"downloading","percent":0,"started":%d}`, at(35)),
+			},
+			percent: 99.75, remaining: 0, known: true,
+		},
+		{
+			now: 61,
+			records: []string{
+				fmt.Sprintf(`{"type":"item","id":1,"action":"install","app":"Zoom","status":"success","started":%d,"finished":%d}`, at(0), at(5)),
+				fmt.Sprintf(`{"type":"item","id":2,"action":"install","app":"Arduino","status":"success","started":%d,"finished":%d}`, at(5), at(35)),
+				fmt.Sprintf(`{"type":"item","id":3,"action":"install","app":"Scratch","status":"failure","exit_code":1,"started":%d,"finished":%d}`, at(35), at(61)),
+				`{"type":"item","action":"daemon","app":"completed","status":"daemon-complete"}`,
+			},
+			percent: 100, remaining: 0, known: false,
+		},
+	}
+
+	for i, step := range steps {
+		status := `{"type":"header","protocol":2}` + "\n" + strings.Join(step.records, "\n") + "\n"
+		queue, err := ParseQueueStatus(strings.NewReader(status), "status")
+		if err != nil {
+			t.Fatalf("step %d: %v", i, err)
+		}
+		progress := EstimateQueueProgress(queue, start.Add(time.Duration(step.now)*time.Minute))
+		if math.Abs(progress.Percent-step.percent) > 0.01 {
+			t.Errorf("step %d: percent = %.2f, want %.2f", i, progress.Percent, step.percent)
+		}
+		if (progress.Remaining - step.remaining).Abs() > time.Second {
+			t.Errorf("step %d: remaining = %s, want %s", i, progress.Remaining, step.remaining)
+		}
+		if progress.Known != step.known {
+			t.Errorf("step %d: known = %t, want %t", i, progress.Known, step.known)
+		}
+	}
+}
+
+func TestQueueStatusPhase(t *testing.T) {
+	started := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	status := strings.Join([]string{
+		`{"type":"header","protocol":2}`,
+		fmt.Sprintf(`{"type":"item","id":1,"action":"install","app":"Zoom","status":"in-progress","phase":"downloading","percent":45,"started":%d}`, started.UnixMilli()),
+		`{"type":"item","id":2,"action":"install","app":"Arduino","status":"in-progress","phase":"installing-packages"}`,
+		// A daemon of an older version doesn't report phases
+		`{"type":"item","id":3,"action":"refresh","app":"Scratch","status":"in-progress","progress":"3/17"}`,
+	}, "\n")
+	queue, err := ParseQueueStatus(strings.NewReader(status), "status")
+	if err != nil || len(queue) != 3 {
+		t.Fatalf("ParseQueueStatus = %+v, %v", queue, err)
+	}
+	want := []string{"Downloading 45%", "Installing packages", ""}
+	for i, item := range queue {
+		if got := api.PhaseText(api.QueuePhase{Name: item.Phase, Percent: item.Percent}); got != want[i] {
+			t.Errorf("phase of %s = %q, want %q", item.AppName, got, want[i])
+		}
+	}
+	if !queue[0].Started.Equal(started) || !queue[1].Started.IsZero() {
+		t.Errorf("started = %s and %s, want %s and none", queue[0].Started, queue[1].Started, started)
+	}
+
+	// The fields survive writing the status again, a phase without percentage stays without one
+	var records []string
+	for _, item := range queue {
+		record, err := formatRecords([]queueRecord{itemRecord(recordItem, item)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, record)
+	}
+	if !strings.Contains(records[0], `"percent":45`) || strings.Contains(records[1], `"percent"`) || strings.Contains(records[2], `"phase"`) {
+		t.Errorf("records = %q", records)
+	}
+}
@@ -0,0 +1,381 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: queue_protocol.go
+// Description: Provides the versioned JSON-lines protocol of the manage daemon queue pipe and status file, and readers for the old semicolon format.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package gui
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pi-apps-go/pi-apps/pkg/api"
+)
+
+// QueueProtocolVersion is the version of the daemon protocol written by this build.
+// Version 1 is the old format of semicolon separated fields, version 2 has one JSON record per line.
+const QueueProtocolVersion = 2
+
+// Types of the records of the daemon protocol
+const (
+	recordHeader = "header" // first record of the status file and of every message sent through the pipe
+	recordItem   = "item"   // a queue item in the status file
+	recordAdd    = "add"    // an item to add to the queue, sent through the pipe
+	recordMove   = "move"   // moves a waiting item, sent through the pipe
+	recordRemove = "remove" // removes a waiting item, sent through the pipe
+	recordCancel = "cancel" // stops the item in progress, sent through the pipe
+)
+
+// queueRecord is a line of the daemon protocol. JSON takes care of app names and error messages with
+// semicolons or newlines in them.
+type queueRecord struct {
+	Type           string `json:"type"`
+	Protocol       int    `json:"protocol,omitempty"`
+	ID             int    `json:"id,omitempty"`
+	Action         string `json:"action,omitempty"`
+	App            string `json:"app,omitempty"`
+	Status         string `json:"status,omitempty"`
+	Icon           string `json:"icon,omitempty"`
+	ExitCode       int    `json:"exit_code,omitempty"`
+	Progress       string `json:"progress,omitempty"`
+	Phase          string `json:"phase,omitempty"`
+	Percent        *int   `json:"percent,omitempty"`  // nil when the phase has no percentage
+	Started        int64  `json:"started,omitempty"`  // Unix time in milliseconds
+	Finished       int64  `json:"finished,omitempty"` // Unix time in milliseconds
+	Error          string `json:"error,omitempty"`
+	Log            string `json:"log,omitempty"`
+	Direction      string `json:"direction,omitempty"`
+	ForceReinstall bool   `json:"force_reinstall,omitempty"`
+}
+
+// recordTime converts the time of a record, 0 being the zero time
+func recordTime(milliseconds int64) time.Time {
+	if milliseconds == 0 {
+		return time.Time{}
+	}
+	return time.UnixMilli(milliseconds)
+}
+
+// recordMilliseconds converts a time for a record, the zero time being 0
+func recordMilliseconds(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixMilli()
+}
+
+// item returns the queue item of an item or add record
+func (r queueRecord) item() QueueItem {
+	percent := -1
+	if r.Percent != nil {
+		percent = *r.Percent
+	}
+	return QueueItem{
+		ID:             r.ID,
+		Action:         r.Action,
+		AppName:        r.App,
+		Status:         r.Status,
+		IconPath:       r.Icon,
+		ExitCode:       r.ExitCode,
+		Progress:       r.Progress,
+		Phase:          r.Phase,
+		Percent:        percent,
+		Started:        recordTime(r.Started),
+		Finished:       recordTime(r.Finished),
+		ErrorMessage:   r.Error,
+		LogFile:        r.Log,
+		ForceReinstall: r.ForceReinstall,
+	}
+}
+
+// itemRecord returns the record of a queue item
+func itemRecord(recordType string, item QueueItem) queueRecord {
+	var percent *int
+	if item.Phase != "" && item.Percent >= 0 {
+		percent = &item.Percent
+	}
+	return queueRecord{
+		Type:           recordType,
+		ID:             item.ID,
+		Action:         item.Action,
+		App:            item.AppName,
+		Status:         item.Status,
+		Icon:           item.IconPath,
+		ExitCode:       item.ExitCode,
+		Progress:       item.Progress,
+		Phase:          item.Phase,
+		Percent:        percent,
+		Started:        recordMilliseconds(item.Started),
+		Finished:       recordMilliseconds(item.Finished),
+		Error:          item.ErrorMessage,
+		Log:            item.LogFile,
+		ForceReinstall: item.ForceReinstall,
+	}
+}
+
+// formatRecords formats records as JSON lines after a header record
+func formatRecords(records []queueRecord) (string, error) {
+	var builder strings.Builder
+	encoder := json.NewEncoder(&builder)
+	// Icon paths and app names are not HTML
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(queueRecord{Type: recordHeader, Protocol: QueueProtocolVersion}); err != nil {
+		return "", err
+	}
+	for _, record := range records {
+		if err := encoder.Encode(record); err != nil {
+			return "", err
+		}
+	}
+	return builder.String(), nil
+}
+
+// parseRecord parses a JSON line of the daemon protocol
+func parseRecord(line string) (queueRecord, error) {
+	var record queueRecord
+	if err := json.Unmarshal([]byte(line), &record); err != nil {
+		return record, err
+	}
+	if record.Type == "" {
+		return record, fmt.Errorf("record without type")
+	}
+	return record, nil
+}
+
+// isJSONRecord reports whether a line is in the JSON format, lines of version 1 never start with {
+func isJSONRecord(line string) bool {
+	return strings.HasPrefix(line, "{")
+}
+
+// QueueStatusJSONFile returns the path of the JSON-lines status file that belongs to a daemon status file.
+// The status file itself keeps the version 1 format for GUI binaries from the previous release.
+func QueueStatusJSONFile(statusFile string) string {
+	return statusFile + ".jsonl"
+}
+
+// writeFileReplacing writes a file through a temporary file and a rename, so readers see the old or the new
+// content but never a partly written file
+func writeFileReplacing(path string, content []byte) error {
+	file, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmp := file.Name()
+	_, err = file.Write(content)
+	if err == nil {
+		err = file.Chmod(0644)
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		os.Remove(tmp)
+	}
+	return err
+}
+
+// WriteQueueStatus writes the daemon status file in both formats, the version 1 file first so the
+// JSON-lines file is never older than it while the daemon runs
+// TODO: stop writing the version 1 status file in the release after the one that introduced version 2
+func WriteQueueStatus(statusFile string, queue []QueueItem) error {
+	if statusFile == "" {
+		return nil
+	}
+	var legacy strings.Builder
+	records := make([]queueRecord, 0, len(queue))
+	for _, item := range queue {
+		legacy.WriteString(FormatQueueStatusLine(item) + "\n")
+		records = append(records, itemRecord(recordItem, item))
+	}
+	if err := writeFileReplacing(statusFile, []byte(legacy.String())); err != nil {
+		return err
+	}
+	content, err := formatRecords(records)
+	if err != nil {
+		return err
+	}
+	return writeFileReplacing(QueueStatusJSONFile(statusFile), []byte(content))
+}
+
+// RemoveQueueStatus removes the daemon status file in both formats
+func RemoveQueueStatus(statusFile string) {
+	os.Remove(statusFile)
+	os.Remove(QueueStatusJSONFile(statusFile))
+}
+
+// currentQueueStatusFile returns the status file written last by the running daemon: the JSON-lines file if it is
+// at least as new as the version 1 file, which a daemon from the previous release writes alone
+func currentQueueStatusFile(statusFile string) string {
+	jsonFile := QueueStatusJSONFile(statusFile)
+	jsonInfo, err := os.Stat(jsonFile)
+	if err != nil {
+		return statusFile
+	}
+	if legacyInfo, err := os.Stat(statusFile); err == nil && legacyInfo.ModTime().After(jsonInfo.ModTime()) {
+		return statusFile
+	}
+	return jsonFile
+}
+
+// ReadQueueStatus reads the queue from the daemon status file, in whichever format the running daemon wrote last
+func ReadQueueStatus(statusFile string) ([]QueueItem, error) {
+	if statusFile == "" {
+		return nil, fmt.Errorf("no status file specified")
+	}
+	path := currentQueueStatusFile(statusFile)
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return ParseQueueStatus(file, path)
+}
+
+// ParseQueueStatus parses a daemon status file of either format. Malformed lines are skipped and logged,
+// so one bad record doesn't hide the rest of the queue.
+func ParseQueueStatus(r io.Reader, name string) ([]QueueItem, error) {
+	var queue []QueueItem
+	scanner := bufio.NewScanner(r)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if !isJSONRecord(line) {
+			if item, ok := ParseQueueStatusLine(line); ok {
+				queue = append(queue, item)
+			} else {
+				api.Debug(fmt.Sprintf("Skipping malformed line %d of %s: %s", lineNumber, name, line))
+			}
+			continue
+		}
+		record, err := parseRecord(line)
+		if err != nil {
+			api.Debug(fmt.Sprintf("Skipping malformed line %d of %s: %v", lineNumber, name, err))
+			continue
+		}
+		switch record.Type {
+		case recordHeader:
+			if record.Protocol > QueueProtocolVersion {
+				api.Debug(fmt.Sprintf("%s uses protocol version %d, newer than %d, reading the fields this version knows", name, record.Protocol, QueueProtocolVersion))
+			}
+		case recordItem:
+			queue = append(queue, record.item())
+		default:
+			api.Debug(fmt.Sprintf("Skipping %s record in line %d of %s", record.Type, lineNumber, name))
+		}
+	}
+	return queue, scanner.Err()
+}
+
+// DaemonProtocolVersion returns the protocol version of the running daemon from the header of its status file,
+// so messages to a daemon from the previous release are sent in the format it understands
+func DaemonProtocolVersion(statusFile string) int {
+	path := currentQueueStatusFile(statusFile)
+	if path == statusFile {
+		return 1
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return 1
+	}
+	defer file.Close()
+	line, err := bufio.NewReader(file).ReadString('\n')
+	if err != nil && line == "" {
+		return 1
+	}
+	record, err := parseRecord(strings.TrimSpace(line))
+	if err != nil || record.Type != recordHeader || record.Protocol < 1 {
+		return 1
+	}
+	return record.Protocol
+}
+
+// QueueRequest is a request read from the daemon queue pipe
+type QueueRequest struct {
+	Command *QueueCommand // reorders or removes a waiting item, or cancels the item in progress
+	Item    *QueueItem    // item to add to the queue, with only its action, app and ForceReinstall set
+}
+
+// FormatQueueAddMessage formats queue items to add as a message for the daemon queue pipe
+func FormatQueueAddMessage(items []QueueItem) (string, error) {
+	records := make([]queueRecord, 0, len(items))
+	for _, item := range items {
+		records = append(records, queueRecord{Type: recordAdd, Action: item.Action, App: item.AppName, ForceReinstall: item.ForceReinstall})
+	}
+	return formatRecords(records)
+}
+
+// formatQueueCommandMessage formats a queue command as a message for the daemon queue pipe
+func formatQueueCommandMessage(command QueueCommand) (string, error) {
+	record := queueRecord{Type: command.Verb, ID: command.ID, Direction: command.Direction}
+	return formatRecords([]queueRecord{record})
+}
+
+// ParseQueuePipeLine parses a line read from the daemon queue pipe.
+//
+//	QueueRequest - the request, empty for header records
+//	bool - true if the line is an old-format queue string to add, which the caller parses itself
+//	error - error if the line is a malformed record or command
+func ParseQueuePipeLine(line string) (QueueRequest, bool, error) {
+	if !isJSONRecord(line) {
+		command, ok, err := ParseQueueCommand(line)
+		if !ok {
+			return QueueRequest{}, true, nil
+		}
+		if err != nil {
+			return QueueRequest{}, false, err
+		}
+		return QueueRequest{Command: &command}, false, nil
+	}
+
+	record, err := parseRecord(line)
+	if err != nil {
+		return QueueRequest{}, false, err
+	}
+	switch record.Type {
+	case recordHeader:
+		return QueueRequest{}, false, nil
+	case recordAdd:
+		if record.Action == "" || record.App == "" {
+			return QueueRequest{}, false, fmt.Errorf("add record without action or app")
+		}
+		item := QueueItem{Action: record.Action, AppName: record.App, ForceReinstall: record.ForceReinstall}
+		return QueueRequest{Item: &item}, false, nil
+	case recordMove, recordRemove, recordCancel:
+		// The command is checked like one in the old format
+		command, _, err := ParseQueueCommand(QueueCommand{Verb: record.Type, ID: record.ID, Direction: record.Direction}.String())
+		if err != nil {
+			return QueueRequest{}, false, err
+		}
+		return QueueRequest{Command: &command}, false, nil
+	default:
+		return QueueRequest{}, false, fmt.Errorf("unknown record type %q", record.Type)
+	}
+}
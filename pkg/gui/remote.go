@@ -0,0 +1,139 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: remote.go
+// Description: Runs the GUI against the Pi-Apps of another machine: marks the remote in the title bar, sends
+// actions to the remote queue and disables them while the connection is lost.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+//go:build !nogui
+
+package gui
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gotk3/gotk3/glib"
+	"github.com/gotk3/gotk3/gtk"
+	"github.com/pi-apps-go/pi-apps/pkg/api"
+)
+
+const (
+	// remotePollInterval is how often the statuses of the remote apps are fetched
+	remotePollInterval = 10 * time.Second
+	// remoteRetryMax is the longest wait between two attempts to reconnect
+	remoteRetryMax = time.Minute
+)
+
+// windowTitle returns the title of the main window, naming the remote machine in remote mode
+func (g *GUI) windowTitle() string {
+	if g.remote == nil {
+		return "Pi-Apps"
+	}
+	if !g.remoteOnline.Load() {
+		return api.Tf("Pi-Apps on %s (disconnected, reconnecting…)", g.remote.Target.Destination)
+	}
+	return api.Tf("Pi-Apps on %s", g.remote.Target.Destination)
+}
+
+// trackActionButton registers an install or uninstall button of the details window, which is disabled
+// while the connection to the remote machine is lost
+func (g *GUI) trackActionButton(button *gtk.Button) {
+	if g.remote == nil {
+		return
+	}
+	g.actionButtons = append(g.actionButtons, button)
+	button.SetSensitive(g.remoteOnline.Load())
+}
+
+// setRemoteOnline updates the title bar and the action buttons after the connection was lost or restored.
+// It must be called in the GTK main loop.
+func (g *GUI) setRemoteOnline(online bool) {
+	g.remoteOnline.Store(online)
+	if g.window != nil {
+		g.window.SetTitle(g.windowTitle())
+	}
+	for _, button := range g.actionButtons {
+		button.SetSensitive(online)
+	}
+}
+
+// watchRemote keeps the statuses of the remote apps up to date in the mirror, which updates the app list,
+// and reconnects with an increasing delay when the connection is lost
+func (g *GUI) watchRemote() {
+	ticker := time.NewTicker(remotePollInterval)
+	defer ticker.Stop()
+	retry := 5 * time.Second
+	for {
+		select {
+		case <-g.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		statuses, err := g.remote.Statuses()
+		if err == nil {
+			if err := api.WriteMirrorStatuses(g.directory, statuses); err != nil {
+				logger.Warn(fmt.Sprintf("Failed to update the statuses of %s: %v", g.remote.Target, err))
+			}
+			if !g.remoteOnline.Load() {
+				glib.IdleAdd(func() { g.setRemoteOnline(true) })
+			}
+			retry = 5 * time.Second
+			continue
+		}
+
+		logger.Warn(fmt.Sprintf("Lost the connection to %s: %v", g.remote.Target, err))
+		if g.remoteOnline.Load() {
+			glib.IdleAdd(func() { g.setRemoteOnline(false) })
+		}
+		for {
+			select {
+			case <-g.ctx.Done():
+				return
+			case <-time.After(retry):
+			}
+			if err := g.remote.Reconnect(); err != nil {
+				logger.Warn(fmt.Sprintf("Failed to reconnect to %s: %v", g.remote.Target, err))
+				retry = min(retry*2, remoteRetryMax)
+				continue
+			}
+			logger.Info(fmt.Sprintf("Reconnected to %s", g.remote.Target))
+			break
+		}
+	}
+}
+
+// performRemoteAction adds an install or uninstall action to the queue of the remote machine
+func (g *GUI) performRemoteAction(appName, action string) {
+	err := fmt.Errorf("%s", api.Tf("Not connected to %s", g.remote.Target.Destination))
+	if g.remoteOnline.Load() {
+		err = g.remote.Queue(action, appName)
+	}
+	if err == nil {
+		logger.Info(fmt.Sprintf("Queued %s %s on %s", action, appName, g.remote.Target))
+		return
+	}
+
+	logger.Error(fmt.Sprintf("Failed to queue %s %s on %s: %v", action, appName, g.remote.Target, err))
+	glib.IdleAdd(func() {
+		dialog := gtk.MessageDialogNew(g.window, gtk.DIALOG_MODAL, gtk.MESSAGE_ERROR, gtk.BUTTONS_OK,
+			"%s", api.Tf("Failed to %s %s on %s: %v", action, appName, g.remote.Target.Destination, err))
+		dialog.Run()
+		dialog.Destroy()
+	})
+}
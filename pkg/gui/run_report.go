@@ -0,0 +1,265 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: run_report.go
+// Description: Writes the JSON report of what a manage run did, for scripts that provision systems unattended.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package gui
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/pi-apps-go/pi-apps/pkg/api"
+)
+
+// RunReportSchemaVersion is the version of the run report format. New fields don't change it, it only changes
+// when a field is removed or changes its meaning.
+const RunReportSchemaVersion = 1
+
+// RunReportEnv is the environment variable with the path of the run report, the -report-file flag of manage sets it
+const RunReportEnv = "PI_APPS_REPORT_FILE"
+
+// Modes of a manage run
+const (
+	RunModeDirect = "direct" // the operations ran in the manage process itself
+	RunModeDaemon = "daemon" // the operations ran in the daemon terminal
+)
+
+// RunReport is what a manage run did
+type RunReport struct {
+	SchemaVersion int             `json:"schema_version"`
+	Mode          string          `json:"mode"`                   // RunModeDirect or RunModeDaemon
+	Commit        string          `json:"commit"`                 // commit of the Pi-Apps directory, "" if it is not a git clone
+	BuildCommit   string          `json:"build_commit,omitempty"` // commit manage was built from
+	Started       time.Time       `json:"started"`
+	Finished      *time.Time      `json:"finished,omitempty"`    // unset while the run goes on, or if it was killed before it could finish the report
+	Interrupted   string          `json:"interrupted,omitempty"` // the signal that stopped the run
+	Success       bool            `json:"success"`               // whether every operation succeeded, false until the run finished
+	ExitCode      int             `json:"exit_code"`             // exit code of manage, see QueueResult
+	Queue         []RunReportItem `json:"queue"`
+}
+
+// RunReportItem is an operation of the queue of a manage run
+type RunReportItem struct {
+	Action   string     `json:"action"`
+	App      string     `json:"app"`
+	Status   string     `json:"status"`    // waiting, in-progress, success, failure, diagnosed (a failure that was queued again), interrupted, skipped or superseded
+	ExitCode int        `json:"exit_code"` // 0 unless the operation failed, see api.FailureExitCode
+	Error    string     `json:"error,omitempty"`
+	Started  *time.Time `json:"started,omitempty"`
+	Duration float64    `json:"duration_seconds"` // 0 for operations that didn't start
+	LogFile  string     `json:"log_file,omitempty"`
+}
+
+// RunReportFile returns the path of the run report: $PI_APPS_REPORT_FILE, or manage-report.json in the data
+// directory of Pi-Apps
+func RunReportFile() string {
+	path := os.Getenv(RunReportEnv)
+	if path == "" {
+		return filepath.Join(api.GetDataDir(), "manage-report.json")
+	}
+	// The daemon terminal runs in another working directory
+	if absPath, err := filepath.Abs(path); err == nil {
+		return absPath
+	}
+	return path
+}
+
+// QueueResult returns whether every operation of a finished queue succeeded, and the exit code of manage for it:
+// 0, or the exit code of the first operation that failed. A failure that succeeded when it was queued again
+// counts as a success, and skipped or superseded operations don't count, they never ran.
+func QueueResult(queue []QueueItem) (success bool, exitCode int) {
+	succeeded := make(map[string]bool)
+	for _, item := range queue {
+		if item.Status == "success" {
+			succeeded[api.QueueLine(item.Action, item.AppName)] = true
+		}
+	}
+	for _, item := range queue {
+		if item.Action == "daemon" || item.Status == StatusSkipped || item.Status == StatusSuperseded || succeeded[api.QueueLine(item.Action, item.AppName)] {
+			continue
+		}
+		return false, itemExitCode(item)
+	}
+	return true, 0
+}
+
+// itemExitCode returns the exit code of a queue item, 1 for one that didn't succeed without a recorded exit code
+func itemExitCode(item QueueItem) int {
+	if item.Status == "success" || item.ExitCode != 0 {
+		return item.ExitCode
+	}
+	return 1
+}
+
+// RunReporter writes the report of a manage run. Every update rewrites the whole report, so a run that is killed
+// leaves the report of its queue so far.
+type RunReporter struct {
+	mutex    sync.Mutex
+	path     string
+	report   RunReport
+	queue    []QueueItem // the queue of the last update, for Interrupt
+	finished bool
+}
+
+// NewRunReporter starts the report of a manage run at path and writes it with an empty queue
+func NewRunReporter(path, mode, buildCommit string) *RunReporter {
+	reporter := &RunReporter{
+		path: path,
+		report: RunReport{
+			SchemaVersion: RunReportSchemaVersion,
+			Mode:          mode,
+			Commit:        api.PiAppsCommit(),
+			BuildCommit:   buildCommit,
+			Started:       time.Now(),
+			Queue:         []RunReportItem{},
+		},
+	}
+	if err := reporter.write(); err != nil {
+		api.WarningTf("Failed to write the run report %s: %v", path, err)
+	}
+	return reporter
+}
+
+// Update rewrites the report with the queue as it is now. It does nothing on a nil RunReporter and after the
+// report is finished.
+func (r *RunReporter) Update(queue []QueueItem) {
+	if r == nil {
+		return
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.finished {
+		return
+	}
+	r.queue = slices.Clone(queue)
+	r.report.Queue = reportItems(queue, time.Now())
+	if err := r.write(); err != nil {
+		api.Debug(fmt.Sprintf("Failed to write the run report %s: %v", r.path, err))
+	}
+}
+
+// Finish writes the final report of a finished queue and returns the exit code of manage for it, see QueueResult
+func (r *RunReporter) Finish(queue []QueueItem) int {
+	success, exitCode := QueueResult(queue)
+	if r == nil {
+		return exitCode
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if !r.finished {
+		r.finish(queue, success, exitCode, "")
+	}
+	return exitCode
+}
+
+// Interrupt writes the final report of a run stopped by a signal, with the operation that was running as
+// interrupted, and returns the exit code of a process killed by the signal
+func (r *RunReporter) Interrupt(sig os.Signal) int {
+	exitCode := 1
+	if number, ok := sig.(syscall.Signal); ok {
+		exitCode = 128 + int(number)
+	}
+	if r == nil {
+		return exitCode
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.finished {
+		return exitCode
+	}
+	now := time.Now()
+	queue := slices.Clone(r.queue)
+	for i := range queue {
+		if queue[i].Status == "in-progress" {
+			queue[i].Status = "interrupted"
+			queue[i].ExitCode = exitCode
+			queue[i].Finished = now
+		}
+	}
+	r.finish(queue, false, exitCode, sig.String())
+	return exitCode
+}
+
+// finish writes the final report, the caller holds the mutex
+func (r *RunReporter) finish(queue []QueueItem, success bool, exitCode int, interrupted string) {
+	now := time.Now()
+	r.finished = true
+	r.report.Queue = reportItems(queue, now)
+	r.report.Finished = &now
+	r.report.Interrupted = interrupted
+	r.report.Success = success
+	r.report.ExitCode = exitCode
+	if err := r.write(); err != nil {
+		api.WarningTf("Failed to write the run report %s: %v", r.path, err)
+	}
+}
+
+// write replaces the report file, so a reader never sees half of it
+func (r *RunReporter) write() error {
+	if err := os.MkdirAll(filepath.Dir(r.path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(r.report, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileReplacing(r.path, append(data, '\n'))
+}
+
+// reportItems returns the report of each operation of a queue, operations that are still running take until now
+func reportItems(queue []QueueItem, now time.Time) []RunReportItem {
+	items := make([]RunReportItem, 0, len(queue))
+	for _, item := range queue {
+		// The completion marker of the daemon is no operation
+		if item.Action == "daemon" {
+			continue
+		}
+		reportItem := RunReportItem{
+			Action: item.Action,
+			App:    item.AppName,
+			Status: item.Status,
+			Error:  item.ErrorMessage,
+		}
+		if item.Status != "waiting" && item.Status != "in-progress" {
+			reportItem.ExitCode = itemExitCode(item)
+		}
+		if !item.Started.IsZero() {
+			started := item.Started
+			reportItem.Started = &started
+			finished := item.Finished
+			if finished.IsZero() {
+				finished = now
+			}
+			reportItem.Duration = math.Round(finished.Sub(started).Seconds()*1000) / 1000
+			reportItem.LogFile = item.LogFile
+			if reportItem.LogFile == "" {
+				reportItem.LogFile = api.AppLogfileSince(item.AppName, started)
+			}
+		}
+		items = append(items, reportItem)
+	}
+	return items
+}
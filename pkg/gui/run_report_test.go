@@ -0,0 +1,179 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package gui
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// newTestReportDir creates a Pi-Apps directory with a logs directory and returns the path of its run report
+func newTestReportDir(t *testing.T) (directory, reportFile string) {
+	t.Helper()
+	directory = t.TempDir()
+	for _, name := range []string{"api", "gui"} {
+		if err := os.WriteFile(filepath.Join(directory, name), []byte("#!/bin/bash\n"), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, dir := range []string{"apps", "data", "etc", "logs"} {
+		if err := os.MkdirAll(filepath.Join(directory, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PI_APPS_DIR", directory)
+	t.Setenv(RunReportEnv, "")
+	return directory, RunReportFile()
+}
+
+// provisioningReport is the part of the run report a provisioning script reads, declared the way such a script
+// would, so a change of the format that breaks them fails here
+type provisioningReport struct {
+	SchemaVersion int     `json:"schema_version"`
+	Success       bool    `json:"success"`
+	ExitCode      int     `json:"exit_code"`
+	Finished      *string `json:"finished"`
+	Interrupted   string  `json:"interrupted"`
+	Queue         []struct {
+		Action   string  `json:"action"`
+		App      string  `json:"app"`
+		Status   string  `json:"status"`
+		ExitCode int     `json:"exit_code"`
+		Duration float64 `json:"duration_seconds"`
+		LogFile  string  `json:"log_file"`
+	} `json:"queue"`
+}
+
+// readProvisioningReport parses a run report like a provisioning script does
+func readProvisioningReport(t *testing.T, path string) provisioningReport {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var report provisioningReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("the run report is no JSON: %v\n%s", err, data)
+	}
+	return report
+}
+
+func TestRunReportConsumer(t *testing.T) {
+	directory, reportFile := newTestReportDir(t)
+	if reportFile != filepath.Join(directory, "data", "manage-report.json") {
+		t.Errorf("RunReportFile() = %q, want data/manage-report.json of the Pi-Apps directory", reportFile)
+	}
+
+	reporter := NewRunReporter(reportFile, RunModeDirect, "abc123")
+	started := time.Now().Add(-3 * time.Second)
+	queue := []QueueItem{
+		{Action: "install", AppName: "Zoom", Status: "in-progress", Started: started},
+		{Action: "install", AppName: "Café", Status: "waiting"},
+	}
+	reporter.Update(queue)
+
+	// The report of the running queue can be read at any time, it is not finished yet
+	report := readProvisioningReport(t, reportFile)
+	if report.Finished != nil || report.Success || len(report.Queue) != 2 {
+		t.Errorf("report while the queue runs = %+v, want an unfinished report of 2 operations", report)
+	}
+
+	logFile := filepath.Join(directory, "logs", "install-fail-Café.log")
+	if err := os.WriteFile(logFile, []byte("Script exit code: 30\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	queue[0].Status, queue[0].Finished = "success", started.Add(2*time.Second)
+	queue[1].Status, queue[1].ExitCode, queue[1].Started, queue[1].Finished = "failure", 30, time.Now(), time.Now()
+	queue = append(queue, QueueItem{Action: "daemon", AppName: "completed", Status: "daemon-complete"})
+	if exitCode := reporter.Finish(queue); exitCode != 30 {
+		t.Errorf("Finish() = %d, want the exit code 30 of the failed install", exitCode)
+	}
+	// Updates after the end, like the completion marker of the daemon, leave the report alone
+	reporter.Update(nil)
+
+	report = readProvisioningReport(t, reportFile)
+	if report.SchemaVersion != RunReportSchemaVersion || report.Finished == nil || report.Success || report.ExitCode != 30 {
+		t.Errorf("final report = %+v, want a finished, failed report with exit code 30", report)
+	}
+	if len(report.Queue) != 2 {
+		t.Fatalf("final report has %d operations, want 2 without the completion marker of the daemon", len(report.Queue))
+	}
+	zoom, cafe := report.Queue[0], report.Queue[1]
+	if zoom.App != "Zoom" || zoom.Status != "success" || zoom.ExitCode != 0 || zoom.Duration != 2 {
+		t.Errorf("report of Zoom = %+v, want a success that took 2 seconds", zoom)
+	}
+	if cafe.App != "Café" || cafe.Status != "failure" || cafe.ExitCode != 30 || cafe.LogFile != logFile {
+		t.Errorf("report of Café = %+v, want a failure with exit code 30 and log %s", cafe, logFile)
+	}
+}
+
+func TestRunReportInterrupt(t *testing.T) {
+	_, reportFile := newTestReportDir(t)
+	reporter := NewRunReporter(reportFile, RunModeDaemon, "")
+	reporter.Update([]QueueItem{
+		{Action: "install", AppName: "Zoom", Status: "success", Started: time.Now(), Finished: time.Now()},
+		{Action: "install", AppName: "Café", Status: "in-progress", Started: time.Now()},
+		{Action: "uninstall", AppName: "Box64", Status: "waiting"},
+	})
+
+	if exitCode := reporter.Interrupt(syscall.SIGTERM); exitCode != 143 {
+		t.Errorf("Interrupt(SIGTERM) = %d, want 143 like a process killed by it", exitCode)
+	}
+	report := readProvisioningReport(t, reportFile)
+	if report.Finished == nil || report.Success || report.ExitCode != 143 || report.Interrupted != syscall.SIGTERM.String() {
+		t.Errorf("interrupted report = %+v, want a finished, failed report with exit code 143", report)
+	}
+	want := []string{"success", "interrupted", "waiting"}
+	for i, item := range report.Queue {
+		if item.Status != want[i] {
+			t.Errorf("status of %s = %q, want %q", item.App, item.Status, want[i])
+		}
+	}
+}
+
+func TestQueueResult(t *testing.T) {
+	tests := []struct {
+		name     string
+		queue    []QueueItem
+		success  bool
+		exitCode int
+	}{
+		{"empty queue", nil, true, 0},
+		{"all succeeded", []QueueItem{{Action: "install", AppName: "Zoom", Status: "success"}}, true, 0},
+		{"retried failure", []QueueItem{
+			{Action: "install", AppName: "Zoom", Status: "diagnosed", ExitCode: 30},
+			{Action: "install", AppName: "Zoom", Status: "success"},
+		}, true, 0},
+		{"first failure decides", []QueueItem{
+			{Action: "install", AppName: "Zoom", Status: "failure", ExitCode: 77},
+			{Action: "install", AppName: "Café", Status: "failure", ExitCode: 30},
+		}, false, 77},
+		{"failure without exit code", []QueueItem{{Action: "uninstall", AppName: "Zoom", Status: "failure"}}, false, 1},
+		{"operation that never ran", []QueueItem{{Action: "install", AppName: "Zoom", Status: "waiting"}}, false, 1},
+		{"completion marker", []QueueItem{{Action: "daemon", AppName: "completed", Status: "daemon-complete"}}, true, 0},
+	}
+	for _, test := range tests {
+		success, exitCode := QueueResult(test.queue)
+		if success != test.success || exitCode != test.exitCode {
+			t.Errorf("%s: QueueResult() = %v, %d, want %v, %d", test.name, success, exitCode, test.success, test.exitCode)
+		}
+	}
+}
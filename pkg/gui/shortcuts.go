@@ -0,0 +1,245 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: shortcuts.go
+// Description: Keyboard shortcuts of the app browser, their keymap file and the table of what they run.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package gui
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/pi-apps-go/pi-apps/pkg/api"
+)
+
+// keymapFile is the name of the file in data/settings that remaps the keyboard shortcuts
+const keymapFile = "keymap"
+
+// Actions of the keyboard shortcuts. They are GtkApplication actions, "app." followed by the name.
+const (
+	ShortcutSearch        = "search"         // open the search box
+	ShortcutOpenDetails   = "open-details"   // open the details of the selected app
+	ShortcutToggleInstall = "toggle-install" // install or uninstall the selected app, depending on its status
+	ShortcutViewLog       = "view-log"       // open the latest log of the selected app
+	ShortcutRefresh       = "refresh"        // regenerate the app list, like `api refresh_app_list`
+	ShortcutHelp          = "shortcuts"      // list the keyboard shortcuts
+)
+
+// Shortcut is an action with the accelerators that trigger it, in GTK syntax like "<Control>Return"
+type Shortcut struct {
+	Action      string
+	Description string // untranslated, pass it through api.T to show it
+	Accels      []string
+}
+
+// DefaultShortcuts returns the keyboard shortcuts used when the keymap file doesn't change them.
+// Arrow keys move through the app list without a shortcut, it is what the list does when it has the focus.
+func DefaultShortcuts() []Shortcut {
+	return []Shortcut{
+		{ShortcutSearch, "Search apps", []string{"slash"}},
+		{ShortcutOpenDetails, "Open the details of the selected app", []string{"Return"}},
+		{ShortcutToggleInstall, "Install or uninstall the selected app", []string{"<Control>Return"}},
+		{ShortcutViewLog, "View the log of the selected app", []string{"<Control>l"}},
+		{ShortcutRefresh, "Refresh the app list", []string{"F5"}},
+		{ShortcutHelp, "Show the keyboard shortcuts", []string{"question"}},
+	}
+}
+
+// accelModifiers maps the modifier names GTK accepts to the one a normalized accelerator uses
+var accelModifiers = map[string]string{
+	"control": "Control", "ctrl": "Control", "ctl": "Control", "primary": "Control",
+	"shift": "Shift", "shft": "Shift",
+	"alt": "Alt", "mod1": "Alt",
+	"super": "Super", "hyper": "Hyper", "meta": "Meta",
+}
+
+// accelModifierOrder is the order of the modifiers in a normalized accelerator
+var accelModifierOrder = []string{"Control", "Shift", "Alt", "Super", "Hyper", "Meta"}
+
+// accelPattern matches an accelerator: modifiers in angle brackets followed by a key name
+var accelPattern = regexp.MustCompile(`^((?:<[A-Za-z0-9]+>)*)([A-Za-z0-9_]+)$`)
+
+// normalizeAccel returns an accelerator in the form GTK prints it, so the same keys always compare equal:
+// "<ctrl><Shift>L" and "<Shift><Primary>l" both become "<Control><Shift>l"
+func normalizeAccel(accel string) (string, error) {
+	match := accelPattern.FindStringSubmatch(strings.TrimSpace(accel))
+	if match == nil {
+		return "", fmt.Errorf("invalid shortcut %q", accel)
+	}
+	var modifiers []string
+	for _, modifier := range strings.Split(strings.Trim(match[1], "<>"), "><") {
+		if modifier == "" {
+			continue
+		}
+		name, ok := accelModifiers[strings.ToLower(modifier)]
+		if !ok {
+			return "", fmt.Errorf("invalid shortcut %q: unknown modifier %s", accel, modifier)
+		}
+		if !slices.Contains(modifiers, name) {
+			modifiers = append(modifiers, name)
+		}
+	}
+	slices.SortFunc(modifiers, func(a, b string) int {
+		return slices.Index(accelModifierOrder, a) - slices.Index(accelModifierOrder, b)
+	})
+
+	// GTK matches letters without case, a shifted letter is written with <Shift>
+	key := match[2]
+	if len(key) == 1 {
+		key = strings.ToLower(key)
+	}
+	var normalized strings.Builder
+	for _, modifier := range modifiers {
+		normalized.WriteString("<" + modifier + ">")
+	}
+	normalized.WriteString(key)
+	return normalized.String(), nil
+}
+
+// ParseKeymap parses a keymap file. Every line is an action, "=" and the accelerators for it separated by spaces,
+// like "refresh = F5 <Control>r". An action without accelerators has no shortcut. Lines starting with # are comments.
+// Invalid lines are skipped and returned joined in the error.
+func ParseKeymap(data []byte) (map[string][]string, error) {
+	known := make(map[string]bool)
+	for _, shortcut := range DefaultShortcuts() {
+		known[shortcut.Action] = true
+	}
+
+	keymap := make(map[string][]string)
+	var problems []error
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for number := 1; scanner.Scan(); number++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		action, value, found := strings.Cut(line, "=")
+		action = strings.TrimSpace(action)
+		if !found {
+			problems = append(problems, fmt.Errorf("line %d: expected action = shortcuts", number))
+			continue
+		}
+		if !known[action] {
+			problems = append(problems, fmt.Errorf("line %d: unknown action %q", number, action))
+			continue
+		}
+
+		accels := []string{}
+		valid := true
+		for _, field := range strings.Fields(value) {
+			accel, err := normalizeAccel(field)
+			if err != nil {
+				problems = append(problems, fmt.Errorf("line %d: %w", number, err))
+				valid = false
+				break
+			}
+			if !slices.Contains(accels, accel) {
+				accels = append(accels, accel)
+			}
+		}
+		if valid {
+			keymap[action] = accels
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		problems = append(problems, err)
+	}
+	return keymap, errors.Join(problems...)
+}
+
+// applyKeymap returns the default shortcuts changed by a keymap. An accelerator the keymap gives to an action
+// is taken away from the other actions, so a remapped key never triggers two actions.
+func applyKeymap(shortcuts []Shortcut, keymap map[string][]string) []Shortcut {
+	taken := make(map[string]bool)
+	for _, accels := range keymap {
+		for _, accel := range accels {
+			taken[accel] = true
+		}
+	}
+
+	applied := make([]Shortcut, 0, len(shortcuts))
+	for _, shortcut := range shortcuts {
+		if accels, ok := keymap[shortcut.Action]; ok {
+			shortcut.Accels = slices.Clone(accels)
+		} else {
+			shortcut.Accels = slices.DeleteFunc(slices.Clone(shortcut.Accels), func(accel string) bool {
+				normalized, err := normalizeAccel(accel)
+				return err == nil && taken[normalized]
+			})
+		}
+		applied = append(applied, shortcut)
+	}
+	return applied
+}
+
+// LoadShortcuts returns the keyboard shortcuts of the app browser with the keymap file of directory applied.
+// Problems in the keymap file are returned as the error, together with the shortcuts of its valid lines.
+func LoadShortcuts(directory string) ([]Shortcut, error) {
+	data, err := os.ReadFile(filepath.Join(api.DataDirOf(directory), "settings", keymapFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return DefaultShortcuts(), nil
+	} else if err != nil {
+		return DefaultShortcuts(), err
+	}
+	keymap, err := ParseKeymap(data)
+	if err != nil {
+		err = fmt.Errorf("%s: %w", keymapFile, err)
+	}
+	return applyKeymap(DefaultShortcuts(), keymap), err
+}
+
+// shortcutHandlers are the functions the keyboard shortcuts run
+type shortcutHandlers struct {
+	search        func()
+	openDetails   func()
+	toggleInstall func()
+	viewLog       func()
+	refresh       func()
+	showHelp      func()
+}
+
+// dispatchTable maps every shortcut action to the function it runs
+func (h shortcutHandlers) dispatchTable() map[string]func() {
+	return map[string]func(){
+		ShortcutSearch:        h.search,
+		ShortcutOpenDetails:   h.openDetails,
+		ShortcutToggleInstall: h.toggleInstall,
+		ShortcutViewLog:       h.viewLog,
+		ShortcutRefresh:       h.refresh,
+		ShortcutHelp:          h.showHelp,
+	}
+}
+
+// toggleInstallAction returns the action the toggle-install shortcut queues for an app with the given status,
+// "" if there is none: disabled apps have to be enabled in their details first
+func toggleInstallAction(status string) string {
+	switch status {
+	case "installed", "corrupted":
+		return "uninstall"
+	case "disabled":
+		return ""
+	}
+	return "install"
+}
@@ -0,0 +1,234 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: shortcuts_gui.go
+// Description: Declares the keyboard shortcuts of the app browser as GtkApplication actions and runs them.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+//go:build !nogui
+
+package gui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/gotk3/gotk3/gdk"
+	"github.com/gotk3/gotk3/glib"
+	"github.com/gotk3/gotk3/gtk"
+	"github.com/pi-apps-go/pi-apps/pkg/api"
+)
+
+// applicationID is the ID of the GtkApplication of the app browser
+const applicationID = "io.github.pi_apps_go.PiApps"
+
+// setupShortcuts declares the keyboard shortcuts as actions of the application, with the accelerators
+// of the keymap file. It must be called once the main window belongs to the application.
+func (g *GUI) setupShortcuts() {
+	shortcuts, err := LoadShortcuts(g.directory)
+	if err != nil {
+		logger.Warn(api.Tf("Invalid keyboard shortcuts in data/settings/%s were left out: %v", keymapFile, err))
+	}
+	// Accelerators with a key name GTK doesn't know are left out too
+	for i := range shortcuts {
+		shortcuts[i].Accels = slices.DeleteFunc(shortcuts[i].Accels, func(accel string) bool {
+			if key, _ := gtk.AcceleratorParse(accel); key == 0 {
+				logger.Warn(api.Tf("Unknown key in the keyboard shortcut %s of %s", accel, shortcuts[i].Action))
+				return true
+			}
+			return false
+		})
+	}
+	g.shortcuts = shortcuts
+
+	dispatch := shortcutHandlers{
+		search:        g.onSearchClicked,
+		openDetails:   g.openSelectedApp,
+		toggleInstall: g.toggleSelectedApp,
+		viewLog:       g.viewSelectedAppLog,
+		refresh:       g.refreshAppList,
+		showHelp:      g.showShortcutsHelp,
+	}.dispatchTable()
+	for _, shortcut := range shortcuts {
+		run := dispatch[shortcut.Action]
+		action := glib.SimpleActionNew(shortcut.Action, nil)
+		action.Connect("activate", func() {
+			logger.Debug(fmt.Sprintf("Keyboard shortcut: %s", shortcut.Action))
+			run()
+		})
+		g.app.AddAction(action)
+		g.app.SetAccelsForAction("app."+shortcut.Action, shortcut.Accels)
+	}
+
+	// GTK tries the accelerators before the focused widget, so a / typed into a text entry would open the search.
+	// Keys without Control, Alt or Super go to the focused widget first and only trigger a shortcut if it
+	// doesn't use them, which also keeps Enter pressing the focused button.
+	g.window.Connect("key-press-event", func(window *gtk.Window, event *gdk.Event) bool {
+		key := gdk.EventKeyNewFromEvent(event)
+		if gdk.ModifierType(key.State())&(gdk.CONTROL_MASK|gdk.MOD1_MASK|gdk.SUPER_MASK) != 0 {
+			return false
+		}
+		return window.PropagateKeyEvent(key)
+	})
+}
+
+// focusViewList makes listBox the list the keyboard shortcuts act on and gives it the keyboard focus,
+// so the arrow keys move through it right away
+func (g *GUI) focusViewList(listBox *gtk.ListBox) {
+	g.viewList = listBox
+	if row := listBox.GetRowAtIndex(0); row != nil {
+		row.GrabFocus()
+	}
+}
+
+// selectedApp returns the app selected in the list shown, "" if the list doesn't show apps or none is selected
+func (g *GUI) selectedApp() string {
+	if g.viewList == nil {
+		return ""
+	}
+	row := g.viewList.GetSelectedRow()
+	if row == nil {
+		return ""
+	}
+	return g.getAppNameFromRow(row)
+}
+
+// openSelectedApp opens the selected row of the list shown, the details of an app or the apps of a category
+func (g *GUI) openSelectedApp() {
+	if g.viewList == nil {
+		return
+	}
+	if row := g.viewList.GetSelectedRow(); row != nil {
+		row.Activate()
+	}
+}
+
+// toggleSelectedApp queues the selected app to be installed or uninstalled, depending on its status
+func (g *GUI) toggleSelectedApp() {
+	app := g.selectedApp()
+	if app == "" {
+		return
+	}
+	if g.remote != nil && !g.remoteOnline.Load() {
+		return
+	}
+	action := toggleInstallAction(g.getAppStatus(app))
+	if action == "" {
+		return
+	}
+	go func() {
+		g.performAppAction(app, action)
+		// After action completes, refresh main view
+		glib.IdleAdd(func() {
+			g.refreshCurrentView()
+		})
+	}()
+}
+
+// viewSelectedAppLog opens the newest log of the selected app in the log viewer
+func (g *GUI) viewSelectedAppLog() {
+	app := g.selectedApp()
+	if app == "" {
+		return
+	}
+	if logFile := api.LatestAppLog(api.LogsDirOf(g.directory), app, false); logFile != "" {
+		g.openLogViewer(logFile)
+		return
+	}
+	dialog := gtk.MessageDialogNew(g.window, gtk.DIALOG_MODAL, gtk.MESSAGE_INFO, gtk.BUTTONS_OK, "%s", api.Tf("No log found for %s", app))
+	defer dialog.Destroy()
+	dialog.Run()
+}
+
+// refreshAppList regenerates the app list like `api refresh_app_list`, then shows the view again
+func (g *GUI) refreshAppList() {
+	if g.remote != nil {
+		g.refreshCurrentView()
+		return
+	}
+	go func() {
+		if err := api.RefreshAppList(); err != nil {
+			logger.Warn(api.Tf("Failed to refresh the app list: %v", err))
+		}
+		glib.IdleAdd(func() {
+			g.refreshCurrentView()
+		})
+	}()
+}
+
+// showShortcutsHelp shows the keyboard shortcuts in a dialog
+func (g *GUI) showShortcutsHelp() {
+	dialog, err := gtk.DialogNew()
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error creating shortcuts dialog: %v", err))
+		return
+	}
+	defer dialog.Destroy()
+	dialog.SetTitle(api.T("Keyboard shortcuts"))
+	dialog.SetTransientFor(g.window)
+	dialog.SetModal(true)
+	dialog.AddButton("_Close", gtk.RESPONSE_CLOSE)
+
+	contentArea, err := dialog.GetContentArea()
+	if err != nil {
+		return
+	}
+	grid, err := gtk.GridNew()
+	if err != nil {
+		return
+	}
+	grid.SetRowSpacing(6)
+	grid.SetColumnSpacing(18)
+	grid.SetMarginTop(10)
+	grid.SetMarginBottom(10)
+	grid.SetMarginStart(10)
+	grid.SetMarginEnd(10)
+
+	addRow := func(row int, keys, description string) {
+		keysLabel, err := gtk.LabelNew("")
+		if err != nil {
+			return
+		}
+		keysLabel.SetMarkup("<b>" + glib.MarkupEscapeText(keys) + "</b>")
+		keysLabel.SetXAlign(0)
+		descriptionLabel, err := gtk.LabelNew(description)
+		if err != nil {
+			return
+		}
+		descriptionLabel.SetXAlign(0)
+		grid.Attach(keysLabel, 0, row, 1, 1)
+		grid.Attach(descriptionLabel, 1, row, 1, 1)
+	}
+
+	addRow(0, api.T("Arrow keys"), api.T("Move through the list"))
+	row := 1
+	for _, shortcut := range g.shortcuts {
+		if len(shortcut.Accels) == 0 {
+			continue
+		}
+		var keys []string
+		for _, accel := range shortcut.Accels {
+			keys = append(keys, gtk.AcceleratorGetLabel(gtk.AcceleratorParse(accel)))
+		}
+		addRow(row, strings.Join(keys, ", "), api.T(shortcut.Description))
+		row++
+	}
+
+	contentArea.PackStart(grid, true, true, 0)
+	dialog.ShowAll()
+	dialog.Run()
+}
@@ -0,0 +1,203 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package gui
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeAccel(t *testing.T) {
+	tests := []struct {
+		accel, want string
+		wantErr     bool
+	}{
+		{accel: "F5", want: "F5"},
+		{accel: "slash", want: "slash"},
+		{accel: "L", want: "l"},
+		{accel: "<ctrl><Shift>L", want: "<Control><Shift>l"},
+		{accel: "<Shift><Primary>l", want: "<Control><Shift>l"},
+		{accel: "<Alt><Control><Control>Return", want: "<Control><Alt>Return"},
+		{accel: "<mod1>x", want: "<Alt>x"},
+		{accel: "<Foo>x", wantErr: true},
+		{accel: "<Control>", wantErr: true},
+		{accel: "Control+l", wantErr: true},
+		{accel: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := normalizeAccel(tt.accel)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("normalizeAccel(%q) error = %v, wantErr %v", tt.accel, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("normalizeAccel(%q) = %q, want %q", tt.accel, got, tt.want)
+		}
+	}
+}
+
+func TestParseKeymap(t *testing.T) {
+	data := `# keyboard shortcuts
+refresh = F5 <ctrl>R
+  view-log=<Control>l <Primary>L
+
+toggle-install =
+search slash
+open-details = <Bogus>o
+bogus = F2
+`
+	keymap, err := ParseKeymap([]byte(data))
+	want := map[string][]string{
+		ShortcutRefresh:       {"F5", "<Control>r"},
+		ShortcutViewLog:       {"<Control>l"},
+		ShortcutToggleInstall: {},
+	}
+	if !reflect.DeepEqual(keymap, want) {
+		t.Errorf("ParseKeymap() = %v, want %v", keymap, want)
+	}
+	if err == nil {
+		t.Fatal("ParseKeymap() returned no error for invalid lines")
+	}
+	for _, problem := range []string{"line 6:", "line 7:", "line 8:"} {
+		if !strings.Contains(err.Error(), problem) {
+			t.Errorf("ParseKeymap() error %q doesn't mention %s", err, problem)
+		}
+	}
+
+	if keymap, err := ParseKeymap([]byte("# nothing\n")); err != nil || len(keymap) != 0 {
+		t.Errorf("ParseKeymap() of comments = %v, %v", keymap, err)
+	}
+}
+
+func TestApplyKeymap(t *testing.T) {
+	shortcuts := applyKeymap(DefaultShortcuts(), map[string][]string{
+		ShortcutRefresh: {"<Control>l", "F5"},
+		ShortcutHelp:    {},
+	})
+	accels := make(map[string][]string)
+	for _, shortcut := range shortcuts {
+		accels[shortcut.Action] = shortcut.Accels
+	}
+
+	if got := accels[ShortcutRefresh]; !reflect.DeepEqual(got, []string{"<Control>l", "F5"}) {
+		t.Errorf("refresh accels = %v", got)
+	}
+	// <Control>l was taken by refresh, so view-log is left without a shortcut
+	if got := accels[ShortcutViewLog]; len(got) != 0 {
+		t.Errorf("view-log accels = %v, want none", got)
+	}
+	if got := accels[ShortcutHelp]; len(got) != 0 {
+		t.Errorf("shortcuts accels = %v, want none", got)
+	}
+	if got := accels[ShortcutSearch]; !reflect.DeepEqual(got, []string{"slash"}) {
+		t.Errorf("search accels = %v", got)
+	}
+	if len(shortcuts) != len(DefaultShortcuts()) {
+		t.Errorf("applyKeymap() returned %d shortcuts, want %d", len(shortcuts), len(DefaultShortcuts()))
+	}
+
+	// The defaults are not changed
+	for _, shortcut := range DefaultShortcuts() {
+		if shortcut.Action == ShortcutViewLog && !reflect.DeepEqual(shortcut.Accels, []string{"<Control>l"}) {
+			t.Errorf("DefaultShortcuts() changed: %v", shortcut.Accels)
+		}
+	}
+}
+
+func TestLoadShortcuts(t *testing.T) {
+	directory := newTestStateDir(t, "")
+	shortcuts, err := LoadShortcuts(directory)
+	if err != nil {
+		t.Fatalf("LoadShortcuts() without a keymap: %v", err)
+	}
+	if !reflect.DeepEqual(shortcuts, DefaultShortcuts()) {
+		t.Errorf("LoadShortcuts() without a keymap = %v, want the defaults", shortcuts)
+	}
+
+	keymap := "search = <Control>f\nrefresh = nonsense+key\n"
+	if err := os.WriteFile(filepath.Join(directory, "data", "settings", keymapFile), []byte(keymap), 0644); err != nil {
+		t.Fatal(err)
+	}
+	shortcuts, err = LoadShortcuts(directory)
+	if err == nil || !strings.Contains(err.Error(), keymapFile) {
+		t.Errorf("LoadShortcuts() error = %v, want one naming %s", err, keymapFile)
+	}
+	for _, shortcut := range shortcuts {
+		switch shortcut.Action {
+		case ShortcutSearch:
+			if !reflect.DeepEqual(shortcut.Accels, []string{"<Control>f"}) {
+				t.Errorf("search accels = %v", shortcut.Accels)
+			}
+		case ShortcutRefresh:
+			// The invalid line keeps the default
+			if !reflect.DeepEqual(shortcut.Accels, []string{"F5"}) {
+				t.Errorf("refresh accels = %v", shortcut.Accels)
+			}
+		}
+	}
+}
+
+func TestDispatchTableCoversShortcuts(t *testing.T) {
+	var ran []string
+	handler := func(name string) func() {
+		return func() { ran = append(ran, name) }
+	}
+	dispatch := shortcutHandlers{
+		search:        handler(ShortcutSearch),
+		openDetails:   handler(ShortcutOpenDetails),
+		toggleInstall: handler(ShortcutToggleInstall),
+		viewLog:       handler(ShortcutViewLog),
+		refresh:       handler(ShortcutRefresh),
+		showHelp:      handler(ShortcutHelp),
+	}.dispatchTable()
+
+	for _, shortcut := range DefaultShortcuts() {
+		run, ok := dispatch[shortcut.Action]
+		if !ok || run == nil {
+			t.Errorf("no handler for shortcut %s", shortcut.Action)
+			continue
+		}
+		ran = nil
+		run()
+		if !reflect.DeepEqual(ran, []string{shortcut.Action}) {
+			t.Errorf("shortcut %s ran %v", shortcut.Action, ran)
+		}
+		if shortcut.Description == "" || len(shortcut.Accels) == 0 {
+			t.Errorf("shortcut %s has no description or accelerator", shortcut.Action)
+		}
+	}
+	if len(dispatch) != len(DefaultShortcuts()) {
+		t.Errorf("dispatch table has %d actions, DefaultShortcuts() %d", len(dispatch), len(DefaultShortcuts()))
+	}
+}
+
+func TestToggleInstallAction(t *testing.T) {
+	for status, want := range map[string]string{
+		"installed":   "uninstall",
+		"corrupted":   "uninstall",
+		"uninstalled": "install",
+		"":            "install",
+		"disabled":    "",
+	} {
+		if got := toggleInstallAction(status); got != want {
+			t.Errorf("toggleInstallAction(%q) = %q, want %q", status, got, want)
+		}
+	}
+}
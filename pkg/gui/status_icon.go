@@ -0,0 +1,44 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: status_icon.go
+// Description: Creates the legacy GtkStatusIcon, which gotk3 doesn't wrap since it is deprecated.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+//go:build !nogui
+
+package gui
+
+// #cgo pkg-config: gtk+-3.0
+// #define GDK_DISABLE_DEPRECATION_WARNINGS
+// #include <gtk/gtk.h>
+import "C"
+
+import (
+	"unsafe"
+
+	"github.com/gotk3/gotk3/glib"
+)
+
+// newLegacyStatusIcon creates a GtkStatusIcon for the system trays that only implement the XEmbed protocol,
+// like lxpanel. It is set up through its properties and signals. On Wayland it never shows up.
+func newLegacyStatusIcon() *glib.Object {
+	icon := C.gtk_status_icon_new()
+	if icon == nil {
+		return nil
+	}
+	return glib.Take(unsafe.Pointer(icon))
+}
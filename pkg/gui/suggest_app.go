@@ -0,0 +1,189 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: suggest_app.go
+// Description: The form to suggest an app for Pi-Apps, from the search dialog or after a search found nothing.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+//go:build !nogui
+
+package gui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gotk3/gotk3/glib"
+	"github.com/gotk3/gotk3/gtk"
+	"github.com/pi-apps-go/pi-apps/pkg/api"
+)
+
+const (
+	// responseSuggestApp is the response of the search dialogs that opens the suggestion form
+	responseSuggestApp = gtk.ResponseType(100)
+
+	// Responses of the suggestion form besides cancel, one per way of submitting it
+	responseOpenIssue = gtk.ResponseType(1)
+	responseSendTeam  = gtk.ResponseType(2)
+)
+
+// showSuggestAppDialog shows the form to suggest an app. search is the search that found nothing, "" if there is none.
+func (g *GUI) showSuggestAppDialog(search string) {
+	dialog, err := gtk.DialogNew()
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error creating suggestion dialog: %v", err))
+		return
+	}
+	defer dialog.Destroy()
+
+	dialog.SetTitle(api.T("Suggest an app"))
+	dialog.SetTransientFor(g.window)
+	dialog.SetModal(true)
+	dialog.SetDefaultSize(420, 320)
+
+	dialog.AddButton(api.T("_Cancel"), gtk.RESPONSE_CANCEL)
+	sendButton, _ := dialog.AddButton(api.T("Send to the Pi-Apps team"), responseSendTeam)
+	dialog.AddButton(api.T("_Open a GitHub issue"), responseOpenIssue)
+	dialog.SetDefaultResponse(responseOpenIssue)
+	if sendButton != nil {
+		sendButton.SetTooltipText(api.T("Sends the name, address and reason below to the Pi-Apps team, nothing else is sent"))
+	}
+
+	contentArea, err := dialog.GetContentArea()
+	if err != nil {
+		return
+	}
+	grid, err := gtk.GridNew()
+	if err != nil {
+		return
+	}
+	grid.SetRowSpacing(6)
+	grid.SetColumnSpacing(10)
+	grid.SetMarginTop(10)
+	grid.SetMarginBottom(10)
+	grid.SetMarginStart(10)
+	grid.SetMarginEnd(10)
+
+	header, err := gtk.LabelNew(api.T("Which app would you like to see in Pi-Apps?"))
+	if err != nil {
+		return
+	}
+	header.SetHAlign(gtk.ALIGN_START)
+	grid.Attach(header, 0, 0, 2, 1)
+
+	nameEntry, err := gtk.EntryNew()
+	if err != nil {
+		return
+	}
+	nameEntry.SetText(search)
+	nameEntry.SetHExpand(true)
+	urlEntry, err := gtk.EntryNew()
+	if err != nil {
+		return
+	}
+	urlEntry.SetPlaceholderText("https://github.com/owner/app")
+	whyView, err := gtk.TextViewNew()
+	if err != nil {
+		return
+	}
+	whyView.SetWrapMode(gtk.WRAP_WORD_CHAR)
+	whyScroll, err := gtk.ScrolledWindowNew(nil, nil)
+	if err != nil {
+		return
+	}
+	whyScroll.SetPolicy(gtk.POLICY_NEVER, gtk.POLICY_AUTOMATIC)
+	whyScroll.SetShadowType(gtk.SHADOW_IN)
+	whyScroll.SetVExpand(true)
+	whyScroll.Add(whyView)
+
+	for row, field := range []struct {
+		label  string
+		widget gtk.IWidget
+	}{
+		{api.T("App name:"), nameEntry},
+		{api.T("Website or repository:"), urlEntry},
+		{api.T("What it does and why:"), whyScroll},
+	} {
+		label, err := gtk.LabelNew(field.label)
+		if err != nil {
+			return
+		}
+		label.SetHAlign(gtk.ALIGN_START)
+		label.SetVAlign(gtk.ALIGN_START)
+		grid.Attach(label, 0, row+1, 1, 1)
+		grid.Attach(field.widget, 1, row+1, 1, 1)
+	}
+
+	errorLabel, err := gtk.LabelNew("")
+	if err != nil {
+		return
+	}
+	errorLabel.SetHAlign(gtk.ALIGN_START)
+	errorLabel.SetLineWrap(true)
+	grid.Attach(errorLabel, 0, 4, 2, 1)
+
+	contentArea.PackStart(grid, true, true, 0)
+	dialog.ShowAll()
+	errorLabel.Hide()
+
+	for {
+		response := dialog.Run()
+		if response != responseOpenIssue && response != responseSendTeam {
+			return
+		}
+
+		suggestion := api.AppSuggestion{Search: search}
+		suggestion.Name, _ = nameEntry.GetText()
+		suggestion.URL, _ = urlEntry.GetText()
+		if buffer, err := whyView.GetBuffer(); err == nil {
+			start, end := buffer.GetBounds()
+			suggestion.Why, _ = buffer.GetText(start, end, false)
+		}
+		suggestion.URL = strings.TrimSpace(suggestion.URL)
+		if err := api.ValidateAppSuggestion(suggestion); err != nil {
+			errorLabel.SetMarkup("<span foreground='#c01c28'>" + glib.MarkupEscapeText(err.Error()) + "</span>")
+			errorLabel.Show()
+			continue
+		}
+
+		if similar, err := api.SimilarApps(suggestion.Name); err != nil {
+			logger.Warn(fmt.Sprintf("failed to look for apps like %s: %v", suggestion.Name, err))
+		} else if len(similar) > 0 {
+			escaped := make([]string, len(similar))
+			for i, name := range similar {
+				escaped[i] = "<b>" + glib.MarkupEscapeText(name) + "</b>"
+			}
+			if !showConfirmDialog(api.Tf("These look like %s and are already in Pi-Apps or suggested:\n%s\n\nSuggest it anyway?",
+				glib.MarkupEscapeText(suggestion.Name), strings.Join(escaped, "\n"))) {
+				continue
+			}
+		}
+
+		send := response == responseSendTeam
+		if _, err := api.SubmitAppSuggestion(suggestion, send); err != nil {
+			errorLabel.SetMarkup("<span foreground='#c01c28'>" + glib.MarkupEscapeText(api.Tf("Failed to submit the suggestion: %v", err)) + "</span>")
+			errorLabel.Show()
+			continue
+		}
+		if send {
+			sent := gtk.MessageDialogNew(dialog, gtk.DIALOG_MODAL, gtk.MESSAGE_INFO, gtk.BUTTONS_OK,
+				"%s", api.Tf("Sent the suggestion of %s to the Pi-Apps team, thank you!", suggestion.Name))
+			sent.Run()
+			sent.Destroy()
+		}
+		return
+	}
+}
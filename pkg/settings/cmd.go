@@ -26,6 +26,8 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/pi-apps-go/pi-apps/pkg/api"
 )
 
 // Main entry point for settings, equivalent to the original bash script
@@ -46,6 +48,13 @@ func Main() error {
 	// Parse command line arguments
 	args := os.Args[1:] // Skip program name
 
+	// Creating the default settings keeps working in kiosk mode, changing them doesn't
+	if len(args) == 0 || args[0] != "refresh" {
+		if err := api.CheckKioskFeature(api.KioskSettings); err != nil {
+			return err
+		}
+	}
+
 	// Handle special commands
 	if len(args) > 0 {
 		switch args[0] {
@@ -77,7 +86,8 @@ func Main() error {
 	return nil
 }
 
-// RefreshSettings creates default settings files if they don't exist
+// RefreshSettings creates default settings files if they don't exist and resets those holding a value
+// their setting doesn't accept
 // Uses embedded setting-params data instead of reading from files
 func RefreshSettings() error {
 	directory := GetPiAppsDir()
@@ -86,7 +96,7 @@ func RefreshSettings() error {
 		return nil
 	}
 
-	settingsDir := filepath.Join(directory, "data", "settings")
+	settingsDir := filepath.Join(api.DataDirOf(directory), "settings")
 
 	// Ensure settings directory exists
 	if err := os.MkdirAll(settingsDir, 0755); err != nil {
@@ -100,13 +110,17 @@ func RefreshSettings() error {
 		// Only create if doesn't exist or is empty
 		if !fileExists(settingPath) || isEmpty(settingPath) {
 			// Write default value
-			if err := os.WriteFile(settingPath, []byte(def.DefaultValue), 0644); err != nil {
+			if err := api.WriteFileAtomic(settingPath, []byte(def.DefaultValue), 0644); err != nil {
 				fmt.Println(Tf("Warning: failed to write default for %s: %v", def.Name, err))
 			}
 		}
 	}
 
-	return nil
+	fixes, err := api.RepairSettings(directory)
+	for _, fix := range fixes {
+		fmt.Println(Tf("Reset the %s setting to %q, %q is not one of its values", fix.Name, fix.Default, fix.Value))
+	}
+	return err
 }
 
 // RevertSettings overwrites all settings with defaults
@@ -118,19 +132,25 @@ func RevertSettings() error {
 		return nil
 	}
 
-	settingsDir := filepath.Join(directory, "data", "settings")
+	settingsDir := filepath.Join(api.DataDirOf(directory), "settings")
 
 	// Ensure settings directory exists
 	if err := os.MkdirAll(settingsDir, 0755); err != nil {
 		return fmt.Errorf("failed to create settings directory: %w", err)
 	}
 
+	lock, err := lockSettings(directory)
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
 	// Read all setting definitions from embedded data
 	for _, def := range embeddedSettingDefinitions {
 		settingPath := filepath.Join(settingsDir, def.Name)
 
 		// Write default value (overwrite existing)
-		if err := os.WriteFile(settingPath, []byte(def.DefaultValue), 0644); err != nil {
+		if err := api.WriteFileAtomic(settingPath, []byte(def.DefaultValue), 0644); err != nil {
 			fmt.Println(Tf("Warning: failed to revert setting %s: %v", def.Name, err))
 		}
 	}
@@ -0,0 +1,324 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: disk_usage.go
+// Description: The disk usage table of the Maintenance group, showing the space each installed app takes with a shortcut to uninstall it
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+//go:build cgo && !nogui
+
+package settings
+
+import (
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/gotk3/gotk3/glib"
+	"github.com/gotk3/gotk3/gtk"
+)
+
+// appDiskUsage is the disk usage of an app as printed by api-go disk_usage --json, in bytes
+type appDiskUsage struct {
+	App       string   `json:"app"`
+	Packages  int64    `json:"packages"`
+	Shared    []string `json:"shared"`
+	Files     int64    `json:"files"`
+	Logs      int64    `json:"logs"`
+	Downloads int64    `json:"downloads"`
+	Total     int64    `json:"total"`
+}
+
+// Columns of the disk usage list store. The sizes are shown formatted and sorted by the byte counts.
+const (
+	diskUsageColumnApp = iota
+	diskUsageColumnPackages
+	diskUsageColumnFiles
+	diskUsageColumnLogs
+	diskUsageColumnDownloads
+	diskUsageColumnTotal
+	diskUsageColumnPackagesBytes
+	diskUsageColumnFilesBytes
+	diskUsageColumnLogsBytes
+	diskUsageColumnDownloadsBytes
+	diskUsageColumnTotalBytes
+	diskUsageColumnName // app name without the shared packages marker
+)
+
+// readDiskUsage runs api-go disk_usage and returns the disk usage of the installed apps, the largest first
+func (sw *SettingsWindow) readDiskUsage() ([]appDiskUsage, error) {
+	output, err := exec.Command(filepath.Join(sw.directory, "api-go"), "disk_usage", "--json").Output()
+	if err != nil {
+		return nil, err
+	}
+	var usages []appDiskUsage
+	if err := json.Unmarshal(output, &usages); err != nil {
+		return nil, fmt.Errorf("failed to read the disk usage: %w", err)
+	}
+	return usages, nil
+}
+
+// formatSize formats a number of bytes in binary units, like 1.5 MiB
+func formatSize(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
+
+// createDiskUsageRow creates the row of the Maintenance group that opens the disk usage table
+func (sw *SettingsWindow) createDiskUsageRow() (*gtk.Box, error) {
+	rowBox, err := gtk.BoxNew(gtk.ORIENTATION_VERTICAL, 4)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create row box: %w", err)
+	}
+	hbox, err := gtk.BoxNew(gtk.ORIENTATION_HORIZONTAL, 15)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create horizontal box: %w", err)
+	}
+
+	textBox, err := createTextBox(T("Disk usage of apps"), T("See how much space the packages, files, logs and downloads of each installed app take, and uninstall the apps you don't need."))
+	if err != nil {
+		return nil, err
+	}
+
+	spinner, err := gtk.SpinnerNew()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create spinner: %w", err)
+	}
+	spinner.SetNoShowAll(true)
+
+	button, err := gtk.ButtonNewWithLabel(T("Show"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create button: %w", err)
+	}
+	button.SetVAlign(gtk.ALIGN_CENTER)
+	button.SetSizeRequest(100, 35)
+
+	result, err := gtk.LabelNew("")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create label: %w", err)
+	}
+	result.SetHAlign(gtk.ALIGN_START)
+	result.SetXAlign(0)
+	result.SetLineWrap(true)
+	result.SetSelectable(true)
+	result.SetNoShowAll(true)
+
+	button.Connect("clicked", func() {
+		button.SetSensitive(false)
+		spinner.Show()
+		spinner.Start()
+		result.Hide()
+
+		go func() {
+			usages, err := sw.readDiskUsage()
+			glib.IdleAdd(func() bool {
+				spinner.Stop()
+				spinner.Hide()
+				button.SetSensitive(true)
+				if err == nil {
+					err = sw.showDiskUsage(usages)
+				}
+				if err != nil {
+					result.SetText(Tf("Failed: %v", err))
+					result.Show()
+				}
+				return false
+			})
+		}()
+	})
+
+	hbox.PackStart(textBox, true, true, 0)
+	hbox.PackEnd(button, false, false, 0)
+	hbox.PackEnd(spinner, false, false, 0)
+	rowBox.PackStart(hbox, false, false, 0)
+	rowBox.PackStart(result, false, false, 0)
+	return rowBox, nil
+}
+
+// fillDiskUsage replaces the rows of the disk usage list store and shows the total
+func fillDiskUsage(store *gtk.ListStore, totalLabel *gtk.Label, usages []appDiskUsage) {
+	store.Clear()
+	var total int64
+	for _, usage := range usages {
+		name := usage.App
+		if len(usage.Shared) > 0 {
+			name += " *"
+		}
+		store.Set(store.Append(),
+			[]int{
+				diskUsageColumnApp, diskUsageColumnPackages, diskUsageColumnFiles, diskUsageColumnLogs, diskUsageColumnDownloads, diskUsageColumnTotal,
+				diskUsageColumnPackagesBytes, diskUsageColumnFilesBytes, diskUsageColumnLogsBytes, diskUsageColumnDownloadsBytes, diskUsageColumnTotalBytes,
+				diskUsageColumnName,
+			},
+			[]interface{}{
+				name, formatSize(usage.Packages), formatSize(usage.Files), formatSize(usage.Logs), formatSize(usage.Downloads), formatSize(usage.Total),
+				usage.Packages, usage.Files, usage.Logs, usage.Downloads, usage.Total,
+				usage.App,
+			})
+		total += usage.Total
+	}
+	totalLabel.SetText(Tf("Total: %s", formatSize(total)) + "\n" + T("* Shares packages with other apps, their size is divided between the apps using them."))
+}
+
+// showDiskUsage opens the disk usage table. Columns are sorted by clicking their header,
+// and the selected app can be uninstalled, after which the table is read again.
+func (sw *SettingsWindow) showDiskUsage(usages []appDiskUsage) error {
+	dialog, err := gtk.DialogNew()
+	if err != nil {
+		return fmt.Errorf("failed to create dialog: %w", err)
+	}
+	dialog.SetTitle(T("Disk usage of apps"))
+	dialog.SetTransientFor(sw.window)
+	dialog.SetDefaultSize(700, 450)
+
+	contentArea, err := dialog.GetContentArea()
+	if err != nil {
+		return fmt.Errorf("failed to get content area: %w", err)
+	}
+
+	store, err := gtk.ListStoreNew(
+		glib.TYPE_STRING, glib.TYPE_STRING, glib.TYPE_STRING, glib.TYPE_STRING, glib.TYPE_STRING, glib.TYPE_STRING,
+		glib.TYPE_INT64, glib.TYPE_INT64, glib.TYPE_INT64, glib.TYPE_INT64, glib.TYPE_INT64,
+		glib.TYPE_STRING)
+	if err != nil {
+		return fmt.Errorf("failed to create list store: %w", err)
+	}
+	treeView, err := gtk.TreeViewNewWithModel(store)
+	if err != nil {
+		return fmt.Errorf("failed to create tree view: %w", err)
+	}
+
+	columns := []struct {
+		title      string
+		text, sort int
+	}{
+		{T("App"), diskUsageColumnApp, diskUsageColumnName},
+		{T("Packages"), diskUsageColumnPackages, diskUsageColumnPackagesBytes},
+		{T("Files"), diskUsageColumnFiles, diskUsageColumnFilesBytes},
+		{T("Logs"), diskUsageColumnLogs, diskUsageColumnLogsBytes},
+		{T("Downloads"), diskUsageColumnDownloads, diskUsageColumnDownloadsBytes},
+		{T("Total"), diskUsageColumnTotal, diskUsageColumnTotalBytes},
+	}
+	for i, column := range columns {
+		renderer, err := gtk.CellRendererTextNew()
+		if err != nil {
+			return fmt.Errorf("failed to create renderer: %w", err)
+		}
+		if i > 0 {
+			renderer.SetProperty("xalign", 1.0)
+		}
+		treeColumn, err := gtk.TreeViewColumnNewWithAttribute(column.title, renderer, "text", column.text)
+		if err != nil {
+			return fmt.Errorf("failed to create column: %w", err)
+		}
+		treeColumn.SetSortColumnID(column.sort)
+		treeColumn.SetResizable(true)
+		if i == 0 {
+			treeColumn.SetExpand(true)
+		}
+		treeView.AppendColumn(treeColumn)
+	}
+	store.SetSortColumnId(diskUsageColumnTotalBytes, gtk.SORT_DESCENDING)
+
+	scrolled, err := gtk.ScrolledWindowNew(nil, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create scrolled window: %w", err)
+	}
+	scrolled.SetPolicy(gtk.POLICY_AUTOMATIC, gtk.POLICY_AUTOMATIC)
+	scrolled.SetShadowType(gtk.SHADOW_IN)
+	scrolled.Add(treeView)
+	contentArea.PackStart(scrolled, true, true, 0)
+
+	totalLabel, err := gtk.LabelNew("")
+	if err != nil {
+		return fmt.Errorf("failed to create label: %w", err)
+	}
+	totalLabel.SetHAlign(gtk.ALIGN_START)
+	totalLabel.SetXAlign(0)
+	totalLabel.SetMarginTop(6)
+	contentArea.PackStart(totalLabel, false, false, 0)
+	fillDiskUsage(store, totalLabel, usages)
+
+	uninstallButton, err := dialog.AddButton(T("Uninstall"), gtk.RESPONSE_APPLY)
+	if err != nil {
+		return fmt.Errorf("failed to create button: %w", err)
+	}
+	uninstallButton.SetSensitive(false)
+	if _, err := dialog.AddButton(T("Close"), gtk.RESPONSE_CLOSE); err != nil {
+		return fmt.Errorf("failed to create button: %w", err)
+	}
+
+	selection, err := treeView.GetSelection()
+	if err != nil {
+		return fmt.Errorf("failed to get selection: %w", err)
+	}
+	selectedApp := func() string {
+		model, iter, ok := selection.GetSelected()
+		if !ok {
+			return ""
+		}
+		value, err := model.ToTreeModel().GetValue(iter, diskUsageColumnName)
+		if err != nil {
+			return ""
+		}
+		app, _ := value.GetString()
+		return app
+	}
+	selection.Connect("changed", func() {
+		uninstallButton.SetSensitive(selectedApp() != "")
+	})
+
+	dialog.Connect("response", func(_ *gtk.Dialog, response gtk.ResponseType) {
+		if response != gtk.RESPONSE_APPLY {
+			dialog.Destroy()
+			return
+		}
+		app := selectedApp()
+		if app == "" {
+			return
+		}
+		// The uninstall runs in a terminal, the table is read again once it is closed
+		cmd := exec.Command(filepath.Join(sw.directory, "api-go"), "terminal_manage", "uninstall", app)
+		uninstallButton.SetSensitive(false)
+		go func() {
+			if err := cmd.Run(); err != nil {
+				fmt.Println(Tf("Failed to uninstall %s: %v", app, err))
+			}
+			usages, err := sw.readDiskUsage()
+			glib.IdleAdd(func() bool {
+				if err != nil {
+					totalLabel.SetText(strings.TrimSpace(Tf("Failed: %v", err)))
+					return false
+				}
+				fillDiskUsage(store, totalLabel, usages)
+				return false
+			})
+		}()
+	})
+
+	dialog.ShowAll()
+	return nil
+}
@@ -31,6 +31,9 @@ import (
 // Locale is the global locale instance for the settings package
 var Locale *gotext.Locale
 
+// pseudoLocalization wraps every translated string in brackets with PI_APPS_PSEUDOLOC=1, like the api package does
+var pseudoLocalization = os.Getenv("PI_APPS_PSEUDOLOC") == "1"
+
 // InitializeI18n initializes the internationalization system
 func InitializeI18n() error {
 	// Get the base directory for Pi-Apps
@@ -106,6 +109,11 @@ func setSystemLocale(locale string) {
 
 // T is a shorthand function for translation
 func T(msgid string, args ...interface{}) string {
+	return pseudoLocalize(translate(msgid, args...))
+}
+
+// translate translates a string with the settings locale
+func translate(msgid string, args ...interface{}) string {
 	if Locale == nil {
 		// Fallback to original string if locale is not initialized
 		if len(args) > 0 {
@@ -117,7 +125,17 @@ func T(msgid string, args ...interface{}) string {
 	if len(args) > 0 {
 		return Locale.Get(msgid, args...)
 	}
-	return Locale.Get(msgid)
+	return translateID(msgid)
+}
+
+// translateID translates a message ID that is not a format string, like a setting name read from a file.
+// Get is called through a method value so vet doesn't check msgid as a format string.
+func translateID(msgid string) string {
+	if Locale == nil {
+		return msgid
+	}
+	get := Locale.Get
+	return get(msgid)
 }
 
 // Tf is a shorthand function for translation with formatting
@@ -127,6 +145,11 @@ func Tf(msgid string, args ...interface{}) string {
 
 // Tn is a shorthand function for plural translation
 func Tn(msgid, msgidPlural string, n int, args ...interface{}) string {
+	return pseudoLocalize(translateN(msgid, msgidPlural, n, args...))
+}
+
+// translateN translates a string with plural support with the settings locale
+func translateN(msgid, msgidPlural string, n int, args ...interface{}) string {
 	if Locale == nil {
 		// Fallback to simple plural logic
 		if n == 1 {
@@ -147,6 +170,14 @@ func Tn(msgid, msgidPlural string, n int, args ...interface{}) string {
 	return Locale.GetN(msgid, msgidPlural, n)
 }
 
+// pseudoLocalize wraps a translated string in brackets when the pseudo-locale is enabled
+func pseudoLocalize(s string) string {
+	if !pseudoLocalization || s == "" {
+		return s
+	}
+	return "[" + s + "]"
+}
+
 // GetAvailableLocales returns a list of available locales
 func GetAvailableLocales() []string {
 	// Use the same directory resolution logic as InitializeI18n
@@ -206,21 +237,33 @@ func GetCurrentLocale() string {
 func translateSettingName(settingName string) string {
 	// Map of setting file names to translatable strings
 	settingNameMap := map[string]string{
-		"App List Style":        "App List Style",
-		"Check for updates":     "Check for updates",
-		"Enable analytics":      "Enable analytics",
-		"Preferred text editor": "Preferred text editor",
-		"Show Edit button":      "Show Edit button",
-		"Show apps":             "Show apps",
-		"Shuffle App list":      "Shuffle App list",
+		"Add English locale":            "Add English locale",
+		"App List Style":                "App List Style",
+		"Check for updates":             "Check for updates",
+		"Color output":                  "Color output",
+		"Enable analytics":              "Enable analytics",
+		"Enable download ledger":        "Enable download ledger",
+		"Enable update rollback":        "Enable update rollback",
+		"High contrast theme":           "High contrast theme",
+		"Install resource limits":       "Install resource limits",
+		"Manage terminal on completion": "Manage terminal on completion",
+		"Preferred text editor":         "Preferred text editor",
+		"Recheck app health":            "Recheck app health",
+		"Share install statistics":      "Share install statistics",
+		"Show Edit button":              "Show Edit button",
+		"Show apps":                     "Show apps",
+		"Show progress in tray":         "Show progress in tray",
+		"Shuffle App list":              "Shuffle App list",
+		"Status symbols":                "Status symbols",
+		"Unavailable apps":              "Unavailable apps",
 	}
 
 	if translatable, exists := settingNameMap[settingName]; exists {
-		return T(translatable)
+		return pseudoLocalize(translateID(translatable))
 	}
 
 	// If not in map, try to translate directly
-	return T(settingName)
+	return pseudoLocalize(translateID(settingName))
 }
 
 // TranslateSettingName exports the translation function for use in other parts of the package
@@ -246,17 +289,19 @@ func translateSettingValue(value string) string {
 		"All":      "All",
 		"packages": "packages",
 		"standard": "standard",
+		"Hide":     "Hide",
+		"Show":     "Show",
 
 		// Theme values
 		"default": "default",
 	}
 
 	if translatable, exists := valueMap[value]; exists {
-		return T(translatable)
+		return pseudoLocalize(translateID(translatable))
 	}
 
 	// If not in map, try to translate directly
-	return T(value)
+	return pseudoLocalize(translateID(value))
 }
 
 // TranslateSettingValue exports the translation function for use in other parts of the package
@@ -282,7 +327,7 @@ func TranslateTooltip(tooltip string) string {
 		}
 
 		// Try to translate the line
-		translated := T(line)
+		translated := pseudoLocalize(translateID(line))
 		translatedLines = append(translatedLines, translated)
 	}
 
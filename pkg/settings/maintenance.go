@@ -0,0 +1,168 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: maintenance.go
+// Description: Declares the repair and cleanup actions of the Maintenance group, shared by the settings window and the TUI
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package settings
+
+import (
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pi-apps-go/pi-apps/pkg/api"
+)
+
+// maintenanceAction is a repair or cleanup action of the Maintenance group, run through api-go
+type maintenanceAction struct {
+	ID          string        // action name for runSettingsAction
+	Name        string        // translated name
+	Description string        // translated description
+	Button      string        // translated button label
+	Title       string        // translated title of the terminal the action runs in
+	Args        []string      // api-go arguments
+	PreviewArgs []string      // api-go arguments of a dry run, whose output is confirmed before the action runs
+	Confirm     string        // translated question asked under the output of the dry run
+	NeedsRoot   bool          // the action may ask for the sudo password, so the settings window runs it in a terminal too
+	Warning     func() string // describes the problem the action fixes when there is one, shown before the action runs
+}
+
+// maintenanceActions returns the actions of the Maintenance group in the order they are listed
+func maintenanceActions() []maintenanceAction {
+	return []maintenanceAction{
+		{
+			ID:          "audit_status",
+			Name:        T("Check app statuses"),
+			Description: T("Find app statuses that don't match the system, like package-apps installed or removed outside of Pi-Apps, or installed apps whose dummy package was removed."),
+			Button:      T("Check"),
+			Title:       T("Checking app statuses"),
+			Args:        []string{"audit_status"},
+		},
+		{
+			ID:          "repair_status",
+			Name:        T("Repair app statuses"),
+			Description: T("Fix the app statuses that don't match the system and rebuild missing dummy packages."),
+			Button:      T("Repair"),
+			Title:       T("Repairing app statuses"),
+			Args:        []string{"audit_status", "--fix"},
+			NeedsRoot:   true,
+		},
+		{
+			ID:          "healthcheck",
+			Name:        T("Check app health"),
+			Description: T("Run the health checks of the installed apps that ship one. Apps that stopped working, for example after an OS upgrade removed a library they need, are marked as corrupted so they can be reinstalled."),
+			Button:      T("Check"),
+			Title:       T("Checking app health"),
+			Args:        []string{"healthcheck", "--mark"},
+		},
+		{
+			ID:          "rebuild_dummy_debs",
+			Name:        T("Repair missing dummy packages"),
+			Description: T("Installed apps keep their dependencies installed through a dummy package. If it was removed (for example by apt autoremove), this rebuilds it so the dependencies are not removed."),
+			Button:      T("Repair"),
+			Title:       T("Repairing missing dummy packages"),
+			Args:        []string{"rebuild_dummy_debs"},
+			NeedsRoot:   true,
+		},
+		{
+			ID:          "renew_repo_keys",
+			Name:        T("Renew repository keys"),
+			Description: T("Repositories added by apps stop working once their signing key expires. This downloads the keys that expire within 30 days again from where the apps got them."),
+			Button:      T("Renew"),
+			Title:       T("Renewing repository keys"),
+			Args:        []string{"repo_keys", "--renew"},
+			NeedsRoot:   true,
+			Warning:     repoKeyWarning,
+		},
+		{
+			ID:          "clean_logs",
+			Name:        T("Clean log files"),
+			Description: T("Remove the install and uninstall logs. Logs older than 6 days are removed automatically."),
+			Button:      T("Clean"),
+			Title:       T("Cleaning log files"),
+			Args:        []string{"clean_logs", "--all"},
+		},
+		{
+			ID:          "clear_caches",
+			Name:        T("Clear caches"),
+			Description: T("Remove the cached app lists and app hashes. Pi-Apps rebuilds them when they are needed, so the next start takes a little longer."),
+			Button:      T("Clear"),
+			Title:       T("Clearing caches"),
+			Args:        []string{"clear_caches"},
+		},
+		{
+			ID:          "clean_data",
+			Name:        T("Clean up data folder"),
+			Description: T("Remove status files of apps that no longer exist, stale update status, outdated caches, leftovers of the manage daemon, empty log files and files of the bash version of Pi-Apps. Files that are not recognized are never touched."),
+			Button:      T("Clean"),
+			Title:       T("Cleaning up the data folder"),
+			Args:        []string{"clean"},
+			PreviewArgs: []string{"clean", "--dry-run"},
+			Confirm:     T("Remove these files?"),
+		},
+		{
+			ID:          "clear_download_ledger",
+			Name:        T("Clear download ledger"),
+			Description: T("Remove the record of the URLs Pi-Apps downloaded content from. New downloads are recorded again unless the download ledger is disabled."),
+			Button:      T("Clear"),
+			Title:       T("Clearing the download ledger"),
+			Args:        []string{"clear_download_ledger"},
+		},
+	}
+}
+
+// repoKeyWarning lists the repository keys that expired or expire within api.RepoKeyWarnDays, "" if there are none
+func repoKeyWarning() string {
+	keys, err := api.ListExternalRepoKeys()
+	if err != nil {
+		return ""
+	}
+	var lines []string
+	for _, key := range api.ExpiringRepoKeys(keys, time.Now()) {
+		lines = append(lines, "⚠ "+key.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// findMaintenanceAction returns the maintenance action with the given ID
+func findMaintenanceAction(id string) (maintenanceAction, bool) {
+	for _, action := range maintenanceActions() {
+		if action.ID == id {
+			return action, true
+		}
+	}
+	return maintenanceAction{}, false
+}
+
+// maintenanceTerminalCommand returns the command that runs a maintenance action in a terminal.
+// Actions with a dry run show it first and only run when the question is answered with y.
+func maintenanceTerminalCommand(directory string, action maintenanceAction) *exec.Cmd {
+	apiPath := filepath.Join(directory, "api-go")
+	command := apiPath + " " + strings.Join(action.Args, " ")
+	if len(action.PreviewArgs) > 0 {
+		command = apiPath + " " + strings.Join(action.PreviewArgs, " ") +
+			" && read -rp " + shellQuote(action.Confirm+" [y/N] ") + " answer && [ \"$answer\" = y ] && " + command
+	}
+	return exec.Command(apiPath, "terminal-run", command, action.Title)
+}
+
+// shellQuote quotes a string for bash
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
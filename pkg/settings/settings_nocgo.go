@@ -15,12 +15,12 @@
 // along with this program.  If not, see <https://www.gnu.org/licenses/>.
 
 // Module: settings_nocgo.go
-// Description: Provides a TUI settings interface for Pi-Apps using Charm stack terminal UI if cgo is disabled
+// Description: Provides a TUI settings interface for Pi-Apps using Charm stack terminal UI if cgo or GUI support is disabled
 // SPDX-License-Identifier: GPL-3.0-or-later
 
 // general TODO: add plugin section as we are going to allow users to add plugins to Pi-Apps Go thanks to the plugin package
 
-//go:build !cgo
+//go:build !cgo || nogui
 
 package settings
 
@@ -44,6 +44,8 @@ type Setting struct {
 	Values      []string
 	Current     string
 	Tooltip     string
+	Default     string // value the setting is reverted to
+	Group       string // group of the settings window, see settingGroups
 }
 
 // SettingDefinition defines the structure of a setting with its metadata
@@ -52,52 +54,165 @@ type SettingDefinition struct {
 	Description    string   // Single-line or multi-line description
 	AcceptedValues []string // List of valid values for this setting
 	DefaultValue   string   // Default value (typically first in AcceptedValues)
+	Group          string   // Group of the settings window the setting is listed in, see settingGroups
 }
 
 // Embedded setting definitions - structured Go-native configuration
 var (
 	embeddedSettingDefinitions = []SettingDefinition{
+		{
+			Name:           "Add English locale",
+			Description:    "Pi-Apps generates the en_US.UTF-8 locale before installing packages, so it can read the output of the package manager in its logs.\nSet this to No if you don't want Pi-Apps to change the locales of your system.",
+			AcceptedValues: []string{"Yes", "No"},
+			DefaultValue:   "Yes",
+			Group:          groupAdvanced,
+		},
 		{
 			Name:           "App List Style",
 			Description:    "Pi-Apps can display the apps as a compact list (GTK 3 via gotk3), or as a group of larger icons. (xlunch like interface)",
 			AcceptedValues: []string{"yad-default", "yad-light", "yad-dark", "xlunch-dark", "xlunch-dark-3d", "xlunch-light-3d"},
 			DefaultValue:   "yad-default",
+			Group:          groupAppearance,
 		},
 		{
 			Name:           "Check for updates",
 			Description:    "How often should Pi-Apps check for app updates and refresh Pi-Apps on boot?",
 			AcceptedValues: []string{"Daily", "Always", "Weekly", "Never"},
 			DefaultValue:   "Daily",
+			Group:          groupUpdates,
+		},
+		{
+			Name:           "Download cache size",
+			Description:    "The most disk space in MB the cache of the files apps download may use. A file several apps download is only downloaded once, and installing an app again takes its files from the cache unless the server has a newer version.\nFiles of installed apps stay in the cache, so it can grow past this size. 0 turns the cache off.",
+			AcceptedValues: []string{"2048", "0", "512", "1024", "5120", "10240"},
+			DefaultValue:   "2048",
+			Group:          groupAdvanced,
+		},
+		{
+			Name:           "Download speed limit",
+			Description:    "Limit the download speed of Pi-Apps in KB/s, so installing many apps doesn't use up a metered or shared connection. 0 downloads as fast as possible.\nThe limit applies to the files Pi-Apps downloads and to apt. It takes effect with the next download, git clones are not limited.",
+			AcceptedValues: []string{"0", "256", "512", "1024", "2048", "5120"},
+			DefaultValue:   "0",
+			Group:          groupAdvanced,
 		},
 		{
 			Name:           "Enable analytics",
 			Description:    "Analytics are used to count the number of installs for each app.\nEach app is associated with a shlink link. During an install, that link is \"clicked\". The total number of clicks is used to calculate how many users each app has.\nThis information cannot possibly be used to identify you, or any personal information about you.",
 			AcceptedValues: []string{"Yes", "No"},
 			DefaultValue:   "Yes",
+			Group:          groupPrivacy,
+		},
+		{
+			Name:           "Enable download ledger",
+			Description:    "Record every URL Pi-Apps downloads content from, together with the size and sha256 of the download, in data/download-ledger.jsonl.\nUse 'api downloads' to review the ledger when auditing where an installation got its software from.",
+			AcceptedValues: []string{"Yes", "No"},
+			DefaultValue:   "Yes",
+			Group:          groupPrivacy,
+		},
+		{
+			Name:           "Enable update rollback",
+			Description:    "When an app update reinstalls an app and the new version fails to install, restore the previous version of the app and reinstall it.\nSet this to No to keep the failed installation around for debugging.",
+			AcceptedValues: []string{"Yes", "No"},
+			DefaultValue:   "Yes",
+			Group:          groupUpdates,
+		},
+		{
+			Name:           "High contrast theme",
+			Description:    "Use the GTK HighContrast theme and stronger status colors in the Pi-Apps windows.\nThis takes effect the next time a Pi-Apps window is opened.",
+			AcceptedValues: []string{"No", "Yes"},
+			DefaultValue:   "No",
+			Group:          groupAppearance,
+		},
+		{
+			Name:           "Install resource limits",
+			Description:    "Run install scripts in a systemd scope with CPU, memory and disk IO limits, so compiling big apps doesn't freeze the system.\nApp defaults only limits apps that declare limits in their requirements file, the other values limit every install script. Limits an app declares always win.\nThis needs a systemd user session, scripts run without limits otherwise.",
+			AcceptedValues: []string{"App defaults", "No", "CPUQuota=300% MemoryMax=80% IOWeight=50", "CPUQuota=200% MemoryMax=60% IOWeight=25"},
+			DefaultValue:   "App defaults",
+			Group:          groupAdvanced,
+		},
+		{
+			Name:           "Manage terminal on completion",
+			Description:    "What the terminal that installs and uninstalls apps does once every operation is finished.\nkeep waits for Enter, close exits right away, close-on-success only stays open if something failed, and timeout:30 waits 30 seconds. Terminals without a keyboard never wait for Enter.",
+			AcceptedValues: []string{"keep", "close", "close-on-success", "timeout:10", "timeout:30", "timeout:60"},
+			DefaultValue:   "keep",
+			Group:          groupAdvanced,
 		},
 		{
 			Name:           "Preferred text editor",
 			Description:    "Specify which text editor to use when editing install scripts",
 			AcceptedValues: []string{"geany", "mousepad", "leafpad", "nano", "Visual Studio Code", "VSCodium"},
 			DefaultValue:   "geany",
+			Group:          groupAdvanced,
+		},
+		{
+			Name:           "Recheck app health",
+			Description:    "When Pi-Apps checks for updates in the background, also run the health checks of the installed apps that ship one.\nApps whose health check fails are marked as corrupted, with the output of the check added to their log.",
+			AcceptedValues: []string{"No", "Yes"},
+			DefaultValue:   "No",
+			Group:          groupUpdates,
+		},
+		{
+			Name:           "Share install statistics",
+			Description:    "Send anonymous statistics about each install, uninstall and update, so Pi-Apps can estimate install times, sizes and timeouts from real numbers. Off unless you turn it on, separate from Enable analytics.\nExactly this is sent: the app name, the action, whether it succeeded, how long it took, how many bytes it downloaded, the kind of device (like Raspberry Pi 4) and the OS codename (like bookworm). Never your hostname, user name, IP address, machine ID or serial number.\nStatistics wait in data/metrics-queue and are sent at most once a day. Only apps of the official catalog are counted.",
+			AcceptedValues: []string{"No", "Yes"},
+			DefaultValue:   "No",
+			Group:          groupPrivacy,
 		},
 		{
 			Name:           "Show apps",
 			Description:    "Most apps use scripts to install software from places like Github or Sourceforge.\nBut other apps can already be easily installed from Add/Remove Software. These apps are simply a shortcut to install apt-packages.\nThis option allows you to selectively show one type of app or the other, or both types.",
 			AcceptedValues: []string{"All", "packages", "standard"},
 			DefaultValue:   "All",
+			Group:          groupAppearance,
 		},
 		{
 			Name:           "Show Edit button",
 			Description:    "When viewing an App's details, display an Edit button to tweak it. Beware that updating that app later will undo your changes.",
 			AcceptedValues: []string{"No", "Yes"},
 			DefaultValue:   "No",
+			Group:          groupAdvanced,
+		},
+		{
+			Name:           "Show progress in tray",
+			Description:    "While apps install, show an icon of the app being installed in the system tray and the progress on the Pi-Apps launcher of your dock. The tray menu can bring the progress window back or cancel the app being installed.",
+			AcceptedValues: []string{"No", "Yes"},
+			DefaultValue:   "No",
+			Group:          groupAppearance,
 		},
 		{
 			Name:           "Shuffle App list",
 			Description:    "Tired of Apps being sorted alphabetically? Randomizing the order will keep things fresh.",
 			AcceptedValues: []string{"No", "Yes"},
 			DefaultValue:   "No",
+			Group:          groupAppearance,
+		},
+		{
+			Name:           "Status symbols",
+			Description:    "Show ✓, ✗ and ⚠ next to the colors of status messages in the terminal and of app statuses in the app list, so they can be told apart without seeing the colors.\nAuto shows them when the terminal reports a light background, where the status colors are hard to read.",
+			AcceptedValues: []string{"Auto", "Yes", "No"},
+			DefaultValue:   "Auto",
+			Group:          groupAppearance,
+		},
+		{
+			Name:           "Sync only usable apps",
+			Description:    "Only download the apps that can be installed on this system, the apps you installed and new apps when checking for updates, which saves space and bandwidth on small devices.\nThe other apps keep the version you have and are downloaded again when they change. Turning this on or off converts the update folder with the next update check.",
+			AcceptedValues: []string{"No", "Yes"},
+			DefaultValue:   "No",
+			Group:          groupUpdates,
+		},
+		{
+			Name:           "Color output",
+			Description:    "Color status, warning and error messages in the terminal.\nAuto colors them only when they are shown in a terminal, so output piped to another command or saved to a file stays plain. NO_COLOR and PI_APPS_COLOR in the environment take precedence over this setting.",
+			AcceptedValues: []string{"Auto", "Always", "Never"},
+			DefaultValue:   "Auto",
+			Group:          groupAppearance,
+		},
+		{
+			Name:           "Unavailable apps",
+			Description:    "Some apps can't be installed on this system, because they are made for another architecture, OS or device.\nHide leaves them out of the app list, Show lists them with an \"unavailable\" badge that tells why.",
+			AcceptedValues: []string{"Hide", "Show"},
+			DefaultValue:   "Hide",
+			Group:          groupAppearance,
 		},
 	}
 )
@@ -127,8 +242,12 @@ func runSettingsAction(directory, action, appListTheme string) {
 	case "multi_uninstall":
 		cmd = exec.Command(apiPath, "multi_uninstall_gui")
 	default:
-		fmt.Println(Tf("Unknown action: %s", action))
-		return
+		maintenance, ok := findMaintenanceAction(action)
+		if !ok {
+			fmt.Println(Tf("Unknown action: %s", action))
+			return
+		}
+		cmd = maintenanceTerminalCommand(directory, maintenance)
 	}
 
 	cmd.Env = GetThemeEnvironmentForLaunch(appListTheme)
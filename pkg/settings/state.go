@@ -10,13 +10,39 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
-	"strings"
+	"time"
+
+	"github.com/pi-apps-go/pi-apps/pkg/api"
+	"github.com/pi-apps-go/pi-apps/pkg/api/statelock"
+)
+
+// Groups of the settings window, listed in the order of settingGroups
+const (
+	groupAppearance  = "Appearance"
+	groupUpdates     = "Updates"
+	groupPrivacy     = "Privacy"
+	groupAdvanced    = "Advanced"
+	groupMaintenance = "Maintenance" // repair and cleanup actions, it has no settings
 )
 
+// settingGroups are the groups of the settings window in the order they are listed.
+// A setting without a known group is listed under Advanced, so new settings always show up.
+var settingGroups = []string{groupAppearance, groupUpdates, groupPrivacy, groupAdvanced, groupMaintenance}
+
+// settingGroupOf returns the group of the settings window a setting definition is listed in
+func settingGroupOf(def SettingDefinition) string {
+	for _, group := range settingGroups {
+		if def.Group == group && group != groupMaintenance {
+			return group
+		}
+	}
+	return groupAdvanced
+}
+
 // loadSettingsState loads all settings from embedded definitions and data/settings files.
 // It matches the behavior of the former SettingsWindow.loadSettings used by GTK.
 func loadSettingsState(directory string) (map[string]*Setting, error) {
-	settingsDir := filepath.Join(directory, "data", "settings")
+	settingsDir := filepath.Join(api.DataDirOf(directory), "settings")
 	if err := os.MkdirAll(settingsDir, 0755); err != nil {
 		return nil, fmt.Errorf("failed to create settings directory: %w", err)
 	}
@@ -29,19 +55,17 @@ func loadSettingsState(directory string) (map[string]*Setting, error) {
 			Description: def.Description,
 			Values:      append([]string(nil), def.AcceptedValues...),
 			Tooltip:     def.Description,
+			Default:     def.DefaultValue,
+			Group:       settingGroupOf(def),
 		}
 
-		currentPath := filepath.Join(settingsDir, def.Name)
-		if fileExists(currentPath) {
-			currentBytes, err := os.ReadFile(currentPath)
-			if err == nil {
-				setting.Current = strings.TrimSpace(string(currentBytes))
-			}
-		}
-
+		// An invalid value is read as the default, so a damaged file never reaches the widgets
+		setting.Current = api.ReadSettingIn(directory, def.Name)
 		if setting.Current == "" {
 			setting.Current = def.DefaultValue
-			if err := os.WriteFile(currentPath, []byte(setting.Current), 0644); err != nil {
+		}
+		if !fileExists(filepath.Join(settingsDir, def.Name)) {
+			if err := api.WriteSettingIn(directory, def.Name, setting.Current); err != nil {
 				return nil, fmt.Errorf("failed to write default setting: %w", err)
 			}
 		}
@@ -76,16 +100,32 @@ func canonicalValueFromTranslatedSelect(setting *Setting, display string) string
 	return display
 }
 
+// settingsLockTimeout is how long saving settings waits for another Pi-Apps process changing the Pi-Apps directory.
+const settingsLockTimeout = 10 * time.Second
+
+// lockSettings locks the Pi-Apps directory while settings are written, so an app being installed by another
+// Pi-Apps process never reads half of the new settings.
+func lockSettings(directory string) (*statelock.Lock, error) {
+	lock, err := statelock.Acquire(directory, "save settings", settingsLockTimeout, func(owner statelock.Owner) {
+		fmt.Println(Tf("Waiting for another Pi-Apps operation to finish: %s", owner))
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock the Pi-Apps directory: %w", err)
+	}
+	return lock, nil
+}
+
 // writeCanonicalSettings writes canonical setting values to data/settings/<name>.
 func writeCanonicalSettings(directory string, values map[string]string) error {
-	settingsDir := filepath.Join(directory, "data", "settings")
-	if err := os.MkdirAll(settingsDir, 0755); err != nil {
-		return fmt.Errorf("failed to create settings directory: %w", err)
+	lock, err := lockSettings(directory)
+	if err != nil {
+		return err
 	}
+	defer lock.Release()
+
 	for name, val := range values {
-		p := filepath.Join(settingsDir, name)
-		if err := os.WriteFile(p, []byte(val), 0644); err != nil {
-			return fmt.Errorf("write %s: %w", name, err)
+		if err := api.WriteSettingIn(directory, name, val); err != nil {
+			return err
 		}
 	}
 	return nil
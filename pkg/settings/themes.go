@@ -30,6 +30,8 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/pi-apps-go/pi-apps/pkg/api"
 )
 
 // generateThemeOptions generates the available theme options for App List Style.
@@ -185,22 +187,13 @@ func GetCurrentAppListStyle() (string, error) {
 		return "", fmt.Errorf("PI_APPS_DIR environment variable not set")
 	}
 
-	settingPath := filepath.Join(directory, "data", "settings", "App List Style")
+	settingPath := filepath.Join(api.DataDirOf(directory), "settings", "App List Style")
 	if !fileExists(settingPath) {
 		return "default", nil // Return default if no setting exists
 	}
 
-	content, err := os.ReadFile(settingPath)
-	if err != nil {
-		return "default", err
-	}
-
-	theme := strings.TrimSpace(string(content))
-	if theme == "" {
-		return "default", nil
-	}
-
-	return theme, nil
+	// A damaged setting is read as the default theme
+	return api.ReadSettingIn(directory, "App List Style"), nil
 }
 
 // GetCurrentThemeEnvironment returns environment variables for the current theme
@@ -23,12 +23,12 @@ package settings
 import (
 	"fmt"
 	"os"
-	"path/filepath"
 	"strings"
 
 	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/pi-apps-go/pi-apps/pkg/api"
 )
 
 // RunSettingsTUI runs the experimental Charm stack terminal UI (Bubble Tea, Lip Gloss, bubbles list) for Pi-Apps settings.
@@ -151,6 +151,19 @@ func newSettingsTUIModel(directory string, settings map[string]*Setting) (*setti
 			actionID:    "multi_uninstall",
 		},
 	}
+	for _, action := range maintenanceActions() {
+		description := action.Description
+		if action.Warning != nil {
+			if warning := action.Warning(); warning != "" {
+				description = warning
+			}
+		}
+		items = append(items, actionListItem{
+			title:       action.Name,
+			description: description,
+			actionID:    action.ID,
+		})
+	}
 
 	delegate := list.NewDefaultDelegate()
 	delegate.SetHeight(3)
@@ -198,6 +211,13 @@ func (m *settingsTUIModel) Init() tea.Cmd {
 }
 
 func (m *settingsTUIModel) applyResetDefaults() {
+	lock, err := lockSettings(m.directory)
+	if err != nil {
+		m.lastErr = err.Error()
+		return
+	}
+	defer lock.Release()
+
 	for name, setting := range m.settings {
 		if len(setting.Values) == 0 {
 			continue
@@ -207,8 +227,7 @@ func (m *settingsTUIModel) applyResetDefaults() {
 		if ptr, ok := m.fieldPtrs[name]; ok {
 			*ptr = def
 		}
-		sp := filepath.Join(m.directory, "data", "settings", name)
-		if err := os.WriteFile(sp, []byte(def), 0644); err != nil {
+		if err := api.WriteSettingIn(m.directory, name, def); err != nil {
 			m.lastErr = fmt.Sprintf("%s: %v", name, err)
 		}
 	}
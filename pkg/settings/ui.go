@@ -15,192 +15,384 @@
 // along with this program.  If not, see <https://www.gnu.org/licenses/>.
 
 // Module: ui.go
-// Description: UI components and tab creation for the settings window
+// Description: UI components of the settings window: the group list, the search and the rows of settings and actions
 // SPDX-License-Identifier: GPL-3.0-or-later
 
-//go:build cgo
+//go:build cgo && !nogui
 
 package settings
 
 import (
+	"errors"
 	"fmt"
-	"os"
+	"html"
 	"os/exec"
 	"path/filepath"
-	"sort"
+	"regexp"
+	"slices"
+	"strings"
 
+	"github.com/gotk3/gotk3/glib"
 	"github.com/gotk3/gotk3/gtk"
+	"github.com/pi-apps-go/pi-apps/pkg/api"
 )
 
-// createSettingsTab creates the main settings tab with configuration options
-func (sw *SettingsWindow) createSettingsTab() error {
-	// Create scrolled window for settings
-	scrolled, err := gtk.ScrolledWindowNew(nil, nil)
-	if err != nil {
-		return fmt.Errorf("failed to create scrolled window: %w", err)
+// groupActions lists the launchers of the other Pi-Apps tools, after the setting groups
+const groupActions = "Actions"
+
+// settingsRow is a row of the right pane that the search and the group list show or hide
+type settingsRow struct {
+	group  string
+	text   string // lower case name and description, in English and translated, for the search
+	widget *gtk.Box
+}
+
+// groupTitle returns the translated title of a group of the settings window
+func groupTitle(group string) string {
+	switch group {
+	case groupAppearance:
+		return T("Appearance")
+	case groupUpdates:
+		return T("Updates")
+	case groupPrivacy:
+		return T("Privacy")
+	case groupAdvanced:
+		return T("Advanced")
+	case groupMaintenance:
+		return T("Maintenance")
+	case groupActions:
+		return T("Actions")
 	}
-	scrolled.SetPolicy(gtk.POLICY_AUTOMATIC, gtk.POLICY_AUTOMATIC)
+	return group
+}
 
-	// Create main container to center content
-	mainContainer, err := gtk.BoxNew(gtk.ORIENTATION_HORIZONTAL, 0)
+// searchText returns the text a row is found by, the search matches both the English and the translated words
+func searchText(texts ...string) string {
+	return strings.ToLower(strings.Join(texts, "\n"))
+}
+
+// createContentPane creates the list of groups with the search box on the left and the rows of the selected
+// group on the right. Rows are created from the setting definitions, the maintenance actions and the tools,
+// so a new setting shows up in its group without changes here.
+func (sw *SettingsWindow) createContentPane(parent *gtk.Box) error {
+	paneBox, err := gtk.BoxNew(gtk.ORIENTATION_HORIZONTAL, 0)
 	if err != nil {
-		return fmt.Errorf("failed to create main container: %w", err)
+		return fmt.Errorf("failed to create pane box: %w", err)
 	}
-	mainContainer.SetHAlign(gtk.ALIGN_CENTER)
 
-	// Create main box for settings with better width management
-	settingsBox, err := gtk.BoxNew(gtk.ORIENTATION_VERTICAL, 15)
+	// Left pane: search box and groups
+	leftBox, err := gtk.BoxNew(gtk.ORIENTATION_VERTICAL, 8)
 	if err != nil {
-		return fmt.Errorf("failed to create settings box: %w", err)
+		return fmt.Errorf("failed to create group box: %w", err)
 	}
-	settingsBox.SetMarginTop(25)
-	settingsBox.SetMarginBottom(25)
-	settingsBox.SetMarginStart(25)
-	settingsBox.SetMarginEnd(25)
-
-	// Set a minimum width for the settings box to better utilize space
-	settingsBox.SetSizeRequest(450, -1)
+	leftBox.SetSizeRequest(190, -1)
+	leftBox.SetMarginEnd(10)
 
-	// Sort settings by name for consistent display
-	var settingNames []string
-	for name := range sw.settings {
-		settingNames = append(settingNames, name)
+	sw.searchEntry, err = gtk.SearchEntryNew()
+	if err != nil {
+		return fmt.Errorf("failed to create search entry: %w", err)
 	}
-	sort.Strings(settingNames)
-
-	// Create UI for each setting
-	for _, settingName := range settingNames {
-		setting := sw.settings[settingName]
+	sw.searchEntry.SetPlaceholderText(T("Search settings"))
+	sw.searchEntry.Connect("search-changed", func() {
+		sw.applyFilter()
+	})
 
-		// Skip if no values available
-		if len(setting.Values) == 0 {
-			continue
-		}
+	sw.groupList, err = gtk.ListBoxNew()
+	if err != nil {
+		return fmt.Errorf("failed to create group list: %w", err)
+	}
+	sw.groupList.SetSelectionMode(gtk.SELECTION_BROWSE)
 
-		// Create horizontal box for this setting
-		hbox, err := gtk.BoxNew(gtk.ORIENTATION_HORIZONTAL, 15)
+	groups := append(append([]string(nil), settingGroups...), groupActions)
+	for _, group := range groups {
+		row, err := gtk.ListBoxRowNew()
 		if err != nil {
-			return fmt.Errorf("failed to create horizontal box: %w", err)
+			return fmt.Errorf("failed to create group row: %w", err)
 		}
-		hbox.SetHAlign(gtk.ALIGN_FILL)
-
-		// Create label with translated setting name
-		translatedName := TranslateSettingName(settingName)
-		label, err := gtk.LabelNew(translatedName)
+		label, err := gtk.LabelNew(groupTitle(group))
 		if err != nil {
 			return fmt.Errorf("failed to create label: %w", err)
 		}
 		label.SetHAlign(gtk.ALIGN_START)
-		label.SetVAlign(gtk.ALIGN_CENTER)
-		label.SetSizeRequest(180, -1)
-
-		// Enable text wrapping for long setting names
-		label.SetLineWrap(true)
-		label.SetLineWrapMode(2) // PANGO_WRAP_WORD = 2
-		label.SetMaxWidthChars(25)
-
-		// Set tooltip if available (translate it)
-		if setting.Tooltip != "" {
-			translatedTooltip := TranslateTooltip(setting.Tooltip)
-			label.SetTooltipText(translatedTooltip)
+		label.SetMarginTop(8)
+		label.SetMarginBottom(8)
+		label.SetMarginStart(10)
+		row.Add(label)
+		sw.groupList.Add(row)
+	}
+	sw.groupList.Connect("row-activated", func(_ *gtk.ListBox, row *gtk.ListBoxRow) {
+		if row == nil {
+			return
+		}
+		if index := row.GetIndex(); index >= 0 && index < len(groups) {
+			sw.selectedGroup = groups[index]
+			sw.applyFilter()
 		}
+	})
+	sw.selectedGroup = groups[0]
+	sw.groupList.SelectRow(sw.groupList.GetRowAtIndex(0))
+
+	leftBox.PackStart(sw.searchEntry, false, false, 0)
+	leftBox.PackStart(sw.groupList, true, true, 0)
+
+	// Right pane: the rows of every group, the filter decides which are visible
+	scrolled, err := gtk.ScrolledWindowNew(nil, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create scrolled window: %w", err)
+	}
+	scrolled.SetPolicy(gtk.POLICY_NEVER, gtk.POLICY_AUTOMATIC)
 
-		// Create combo box
-		combo, err := gtk.ComboBoxTextNew()
+	contentBox, err := gtk.BoxNew(gtk.ORIENTATION_VERTICAL, 15)
+	if err != nil {
+		return fmt.Errorf("failed to create content box: %w", err)
+	}
+	contentBox.SetMarginStart(15)
+	contentBox.SetMarginEnd(15)
+	contentBox.SetMarginBottom(15)
+
+	sw.groupHeaders = make(map[string]*gtk.Label)
+	for _, group := range groups {
+		header, err := gtk.LabelNew("")
 		if err != nil {
-			return fmt.Errorf("failed to create combo box: %w", err)
+			return fmt.Errorf("failed to create label: %w", err)
 		}
-		combo.SetSizeRequest(240, -1)
-
-		// Populate combo box with translated values
-		activeIndex := 0
-		for i, value := range setting.Values {
-			translatedValue := TranslateSettingValue(value)
-			combo.AppendText(translatedValue)
-			if value == setting.Current {
-				activeIndex = i
+		header.SetMarkup("<big><b>" + html.EscapeString(groupTitle(group)) + "</b></big>")
+		header.SetHAlign(gtk.ALIGN_START)
+		header.SetMarginTop(10)
+		sw.groupHeaders[group] = header
+		contentBox.PackStart(header, false, false, 0)
+
+		switch group {
+		case groupMaintenance:
+			for _, action := range maintenanceActions() {
+				row, err := sw.createMaintenanceRow(action)
+				if err != nil {
+					return err
+				}
+				sw.addRow(contentBox, group, row, action.Name, action.Description)
+			}
+			row, err := sw.createDiskUsageRow()
+			if err != nil {
+				return err
+			}
+			sw.addRow(contentBox, group, row, T("Disk usage of apps"), T("See how much space the packages, files, logs and downloads of each installed app take, and uninstall the apps you don't need."))
+		case groupActions:
+			for _, action := range toolActions() {
+				row, err := sw.createToolRow(action)
+				if err != nil {
+					return err
+				}
+				sw.addRow(contentBox, group, row, action.name, action.tooltip)
+			}
+		default:
+			for _, name := range sortedSettingNames(sw.settings) {
+				setting := sw.settings[name]
+				// Skip if no values available
+				if setting.Group != group || len(setting.Values) == 0 {
+					continue
+				}
+				row, err := sw.createSettingRow(setting)
+				if err != nil {
+					return err
+				}
+				sw.addRow(contentBox, group, row, name, setting.Description, TranslateSettingName(name), TranslateTooltip(setting.Description))
 			}
 		}
-		combo.SetActive(activeIndex)
+	}
 
-		// Store reference for saving later
-		sw.comboBoxes[settingName] = combo
+	sw.noResults, err = gtk.LabelNew(T("No settings match the search."))
+	if err != nil {
+		return fmt.Errorf("failed to create label: %w", err)
+	}
+	sw.noResults.SetMarginTop(30)
+	contentBox.PackStart(sw.noResults, false, false, 0)
 
-		// Set tooltip for combo box too (translate it)
-		if setting.Tooltip != "" {
-			translatedTooltip := TranslateTooltip(setting.Tooltip)
-			combo.SetTooltipText(translatedTooltip)
-		}
+	scrolled.Add(contentBox)
 
-		// Special handling for App List Style to apply theme changes immediately
-		if settingName == "App List Style" {
-			combo.Connect("changed", func() {
-				activeText := combo.GetActiveText()
-				if activeText != "" {
-					sw.applyThemeToCurrentWindow(activeText)
-				}
-			})
-		}
+	separator, err := gtk.SeparatorNew(gtk.ORIENTATION_VERTICAL)
+	if err != nil {
+		return fmt.Errorf("failed to create separator: %w", err)
+	}
+
+	paneBox.PackStart(leftBox, false, false, 0)
+	paneBox.PackStart(separator, false, false, 0)
+	paneBox.PackStart(scrolled, true, true, 0)
+	parent.PackStart(paneBox, true, true, 0)
+
+	return nil
+}
 
-		// Pack into horizontal box
-		hbox.PackStart(label, false, false, 0)
-		hbox.PackEnd(combo, false, false, 0)
+// addRow adds a row to the right pane and remembers it for the filter
+func (sw *SettingsWindow) addRow(contentBox *gtk.Box, group string, widget *gtk.Box, texts ...string) {
+	contentBox.PackStart(widget, false, false, 0)
+	sw.rows = append(sw.rows, &settingsRow{group: group, text: searchText(texts...), widget: widget})
+}
 
-		// Add to settings box
-		settingsBox.PackStart(hbox, false, false, 0)
+// applyFilter shows the rows of the selected group, or the rows of every group that match the search
+func (sw *SettingsWindow) applyFilter() {
+	if sw.searchEntry == nil {
+		return
+	}
+	query, _ := sw.searchEntry.GetText()
+	query = strings.ToLower(strings.TrimSpace(query))
+
+	matches := make(map[string]bool)
+	for _, row := range sw.rows {
+		visible := row.group == sw.selectedGroup
+		if query != "" {
+			visible = strings.Contains(row.text, query)
+		}
+		row.widget.SetVisible(visible)
+		if visible {
+			matches[row.group] = true
+		}
 	}
+	for group, header := range sw.groupHeaders {
+		header.SetVisible(matches[group] || (query == "" && group == sw.selectedGroup))
+	}
+	sw.noResults.SetVisible(query != "" && len(matches) == 0)
+}
 
-	// Pack settings box into main container
-	mainContainer.PackStart(settingsBox, true, false, 0)
+// defaultValueIndex returns the index of the default value of a setting, the first value if the default
+// is not one of the values (the App List Style values depend on the installed themes)
+func defaultValueIndex(setting *Setting) int {
+	for i, value := range setting.Values {
+		if value == setting.Default {
+			return i
+		}
+	}
+	return 0
+}
 
-	// Add main container to scrolled window
-	scrolled.Add(mainContainer)
+// createSettingRow creates the row of a setting: its name, the modified indicator with the revert button,
+// the combo box and the description under them
+func (sw *SettingsWindow) createSettingRow(setting *Setting) (*gtk.Box, error) {
+	settingName := setting.Name
 
-	// Create tab label
-	tabLabel, err := gtk.LabelNew(T("Settings"))
+	rowBox, err := gtk.BoxNew(gtk.ORIENTATION_VERTICAL, 4)
 	if err != nil {
-		return fmt.Errorf("failed to create tab label: %w", err)
+		return nil, fmt.Errorf("failed to create row box: %w", err)
 	}
 
-	sw.notebook.AppendPage(scrolled, tabLabel)
-
-	return nil
-}
+	// Create horizontal box for this setting
+	hbox, err := gtk.BoxNew(gtk.ORIENTATION_HORIZONTAL, 10)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create horizontal box: %w", err)
+	}
 
-// createActionsTab creates the tab with action buttons (categories, logs, etc.)
-func (sw *SettingsWindow) createActionsTab() error {
-	// Create scrolled window for actions
-	scrolled, err := gtk.ScrolledWindowNew(nil, nil)
+	// Create label with translated setting name
+	label, err := gtk.LabelNew("")
 	if err != nil {
-		return fmt.Errorf("failed to create scrolled window: %w", err)
+		return nil, fmt.Errorf("failed to create label: %w", err)
 	}
-	scrolled.SetPolicy(gtk.POLICY_AUTOMATIC, gtk.POLICY_AUTOMATIC)
+	label.SetMarkup("<b>" + html.EscapeString(TranslateSettingName(settingName)) + "</b>")
+	label.SetHAlign(gtk.ALIGN_START)
+	label.SetVAlign(gtk.ALIGN_CENTER)
+	label.SetXAlign(0)
+	label.SetLineWrap(true)
+	label.SetLineWrapMode(2) // PANGO_WRAP_WORD = 2
+	label.SetMaxWidthChars(25)
+
+	// Modified indicator and revert button, only visible when the value is not the default
+	modifiedLabel, err := gtk.LabelNew("")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create label: %w", err)
+	}
+	modifiedLabel.SetMarkup("<small><i>" + html.EscapeString(T("modified")) + "</i></small>")
+	modifiedLabel.SetNoShowAll(true)
 
-	// Create main container box to center content
-	mainBox, err := gtk.BoxNew(gtk.ORIENTATION_VERTICAL, 0)
+	revertButton, err := gtk.ButtonNewFromIconName("edit-undo", gtk.ICON_SIZE_BUTTON)
 	if err != nil {
-		return fmt.Errorf("failed to create main box: %w", err)
+		return nil, fmt.Errorf("failed to create revert button: %w", err)
+	}
+	revertButton.SetRelief(gtk.RELIEF_NONE)
+	revertButton.SetNoShowAll(true)
+	if defaultIndex := defaultValueIndex(setting); defaultIndex < len(setting.Values) {
+		revertButton.SetTooltipText(Tf("Revert to the default value: %s", TranslateSettingValue(setting.Values[defaultIndex])))
 	}
-	mainBox.SetHAlign(gtk.ALIGN_CENTER)
-	mainBox.SetVAlign(gtk.ALIGN_CENTER)
 
-	// Create grid for actions
-	grid, err := gtk.GridNew()
+	// Create combo box
+	combo, err := gtk.ComboBoxTextNew()
 	if err != nil {
-		return fmt.Errorf("failed to create grid: %w", err)
-	}
-	grid.SetRowSpacing(15)
-	grid.SetColumnSpacing(15)
-	grid.SetHAlign(gtk.ALIGN_CENTER)
-	grid.SetVAlign(gtk.ALIGN_CENTER)
-
-	// Define action buttons with their properties
-	actions := []struct {
-		name    string
-		icon    string
-		tooltip string
-		action  string
-	}{
+		return nil, fmt.Errorf("failed to create combo box: %w", err)
+	}
+	combo.SetSizeRequest(220, -1)
+
+	// Populate combo box with translated values
+	activeIndex := 0
+	for i, value := range setting.Values {
+		combo.AppendText(TranslateSettingValue(value))
+		if value == setting.Current {
+			activeIndex = i
+		}
+	}
+	combo.SetActive(activeIndex)
+
+	// Store reference for saving later
+	sw.comboBoxes[settingName] = combo
+
+	updateModified := func() {
+		value := canonicalValueFromTranslatedSelect(setting, combo.GetActiveText())
+		modified := value != setting.Values[defaultValueIndex(setting)]
+		modifiedLabel.SetVisible(modified)
+		revertButton.SetVisible(modified)
+	}
+	updateModified()
+
+	combo.Connect("changed", func() {
+		// Install statistics are only shared after the user agreed to what is sent
+		if settingName == "Share install statistics" && setting.Current != "Yes" &&
+			canonicalValueFromTranslatedSelect(setting, combo.GetActiveText()) == "Yes" && !sw.confirmInstallStatistics() {
+			combo.SetActive(slices.Index(setting.Values, "No"))
+			return
+		}
+		updateModified()
+		// Apply App List Style theme changes immediately
+		if settingName == "App List Style" {
+			if activeText := combo.GetActiveText(); activeText != "" {
+				sw.applyThemeToCurrentWindow(activeText)
+			}
+		}
+	})
+	revertButton.Connect("clicked", func() {
+		combo.SetActive(defaultValueIndex(setting))
+	})
+
+	hbox.PackStart(label, false, false, 0)
+	hbox.PackStart(modifiedLabel, false, false, 0)
+	hbox.PackEnd(combo, false, false, 0)
+	hbox.PackEnd(revertButton, false, false, 0)
+	rowBox.PackStart(hbox, false, false, 0)
+
+	// Inline description under the control
+	if setting.Description != "" {
+		descriptionLabel, err := gtk.LabelNew("")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create label: %w", err)
+		}
+		descriptionLabel.SetMarkup("<small>" + html.EscapeString(TranslateTooltip(setting.Description)) + "</small>")
+		descriptionLabel.SetHAlign(gtk.ALIGN_START)
+		descriptionLabel.SetXAlign(0)
+		descriptionLabel.SetLineWrap(true)
+		descriptionLabel.SetLineWrapMode(2) // PANGO_WRAP_WORD = 2
+		descriptionLabel.SetMaxWidthChars(60)
+		rowBox.PackStart(descriptionLabel, false, false, 0)
+	}
+
+	return rowBox, nil
+}
+
+// toolAction is a launcher of another Pi-Apps tool in the Actions group
+type toolAction struct {
+	name    string
+	icon    string
+	tooltip string
+	action  string
+}
+
+// toolActions returns the launchers of the Actions group
+func toolActions() []toolAction {
+	return []toolAction{
 		{
 			name:    T("Categories"),
 			icon:    "categories.png",
@@ -238,86 +430,278 @@ func (sw *SettingsWindow) createActionsTab() error {
 			action:  "multi_uninstall",
 		},
 	}
+}
 
-	// Create buttons for actions in a 3x2 grid
-	for i, action := range actions {
-		button, err := gtk.ButtonNew()
-		if err != nil {
-			return fmt.Errorf("failed to create button: %w", err)
-		}
+// createTextBox creates the bold name with the description under it, used by action rows
+func createTextBox(name, description string) (*gtk.Box, error) {
+	textBox, err := gtk.BoxNew(gtk.ORIENTATION_VERTICAL, 4)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create text box: %w", err)
+	}
 
-		// Create button content box
-		buttonBox, err := gtk.BoxNew(gtk.ORIENTATION_VERTICAL, 8)
-		if err != nil {
-			return fmt.Errorf("failed to create button box: %w", err)
-		}
-		buttonBox.SetHAlign(gtk.ALIGN_CENTER)
-		buttonBox.SetVAlign(gtk.ALIGN_CENTER)
+	nameLabel, err := gtk.LabelNew("")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create label: %w", err)
+	}
+	nameLabel.SetMarkup("<b>" + html.EscapeString(name) + "</b>")
+	nameLabel.SetHAlign(gtk.ALIGN_START)
 
-		// Add icon if it exists
-		iconPath := filepath.Join(sw.directory, "icons", action.icon)
-		if fileExists(iconPath) {
-			image, err := gtk.ImageNewFromFile(iconPath)
-			if err == nil {
-				// Scale icon to consistent size
-				if pixbuf := image.GetPixbuf(); pixbuf != nil {
-					scaledPixbuf, err := pixbuf.ScaleSimple(32, 32, 2) // GDK_INTERP_BILINEAR = 2
-					if err == nil {
-						image.SetFromPixbuf(scaledPixbuf)
-					}
+	descriptionLabel, err := gtk.LabelNew("")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create label: %w", err)
+	}
+	descriptionLabel.SetMarkup("<small>" + html.EscapeString(description) + "</small>")
+	descriptionLabel.SetHAlign(gtk.ALIGN_START)
+	descriptionLabel.SetXAlign(0)
+	descriptionLabel.SetLineWrap(true)
+	descriptionLabel.SetLineWrapMode(2) // PANGO_WRAP_WORD = 2
+	descriptionLabel.SetMaxWidthChars(50)
+
+	textBox.PackStart(nameLabel, false, false, 0)
+	textBox.PackStart(descriptionLabel, false, false, 0)
+	return textBox, nil
+}
+
+// confirmInstallStatistics shows exactly what the install statistics send and asks to share them
+func (sw *SettingsWindow) confirmInstallStatistics() bool {
+	dialog := gtk.MessageDialogNew(sw.window, gtk.DIALOG_MODAL, gtk.MESSAGE_QUESTION, gtk.BUTTONS_YES_NO, "%s",
+		T("Share anonymous install statistics with the Pi-Apps team?"))
+	dialog.FormatSecondaryText("%s", T("After every install, uninstall and update, exactly this is sent:")+"\n\n"+
+		"  • "+T("the app name and the action, like install")+"\n"+
+		"  • "+T("whether it succeeded and how long it took")+"\n"+
+		"  • "+T("how many bytes were downloaded")+"\n"+
+		"  • "+T("the kind of device, like Raspberry Pi 4")+"\n"+
+		"  • "+T("the OS codename, like bookworm")+"\n\n"+
+		T("Your hostname, user name, IP address, machine ID and serial number are never sent. Only apps of the official catalog are counted. The statistics wait in data/metrics-queue and are sent at most once a day, you can turn this off again at any time."))
+	dialog.SetTitle(TranslateSettingName("Share install statistics"))
+	response := dialog.Run()
+	dialog.Destroy()
+	return response == gtk.RESPONSE_YES
+}
+
+// createToolRow creates the row of a launcher in the Actions group
+func (sw *SettingsWindow) createToolRow(action toolAction) (*gtk.Box, error) {
+	hbox, err := gtk.BoxNew(gtk.ORIENTATION_HORIZONTAL, 15)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create horizontal box: %w", err)
+	}
+
+	// Add icon if it exists
+	iconPath := filepath.Join(sw.directory, "icons", action.icon)
+	if fileExists(iconPath) {
+		image, err := gtk.ImageNewFromFile(iconPath)
+		if err == nil {
+			// Scale icon to consistent size
+			if pixbuf := image.GetPixbuf(); pixbuf != nil {
+				scaledPixbuf, err := pixbuf.ScaleSimple(32, 32, 2) // GDK_INTERP_BILINEAR = 2
+				if err == nil {
+					image.SetFromPixbuf(scaledPixbuf)
 				}
-				buttonBox.PackStart(image, false, false, 0)
 			}
+			hbox.PackStart(image, false, false, 0)
 		}
+	}
 
-		// Add label
-		label, err := gtk.LabelNew(action.name)
-		if err != nil {
-			return fmt.Errorf("failed to create button label: %w", err)
-		}
-		label.SetHAlign(gtk.ALIGN_CENTER)
-		// Enable text wrapping for longer names
-		label.SetLineWrap(true)
-		label.SetLineWrapMode(2) // PANGO_WRAP_WORD = 2
-		label.SetMaxWidthChars(12)
-		buttonBox.PackStart(label, false, false, 0)
-
-		button.Add(buttonBox)
-		button.SetTooltipText(action.tooltip)
-		button.SetSizeRequest(140, 100)
-
-		// Connect button click
-		script := action.action
-		button.Connect("clicked", func() {
-			sw.runAction(script)
-		})
+	textBox, err := createTextBox(action.name, action.tooltip)
+	if err != nil {
+		return nil, err
+	}
 
-		// Calculate grid position (3 columns)
-		row := i / 3
-		col := i % 3
+	button, err := gtk.ButtonNewWithLabel(T("Open"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create button: %w", err)
+	}
+	button.SetVAlign(gtk.ALIGN_CENTER)
+	button.SetSizeRequest(100, 35)
 
-		// Add to grid
-		grid.Attach(button, col, row, 1, 1)
+	// Connect button click
+	script := action.action
+	button.Connect("clicked", func() {
+		sw.runAction(script)
+	})
+
+	hbox.PackStart(textBox, true, true, 0)
+	hbox.PackEnd(button, false, false, 0)
+	return hbox, nil
+}
+
+// createMaintenanceRow creates the row of a maintenance action, with a spinner and the result of the last run
+func (sw *SettingsWindow) createMaintenanceRow(action maintenanceAction) (*gtk.Box, error) {
+	rowBox, err := gtk.BoxNew(gtk.ORIENTATION_VERTICAL, 4)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create row box: %w", err)
 	}
 
-	// Pack grid into main box with some padding
-	mainBox.SetMarginTop(30)
-	mainBox.SetMarginBottom(30)
-	mainBox.SetMarginStart(30)
-	mainBox.SetMarginEnd(30)
-	mainBox.PackStart(grid, true, true, 0)
+	hbox, err := gtk.BoxNew(gtk.ORIENTATION_HORIZONTAL, 15)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create horizontal box: %w", err)
+	}
 
-	scrolled.Add(mainBox)
+	textBox, err := createTextBox(action.Name, action.Description)
+	if err != nil {
+		return nil, err
+	}
 
-	// Create tab label
-	tabLabel, err := gtk.LabelNew(T("Actions"))
+	spinner, err := gtk.SpinnerNew()
 	if err != nil {
-		return fmt.Errorf("failed to create tab label: %w", err)
+		return nil, fmt.Errorf("failed to create spinner: %w", err)
 	}
+	spinner.SetNoShowAll(true)
 
-	sw.notebook.AppendPage(scrolled, tabLabel)
+	button, err := gtk.ButtonNewWithLabel(action.Button)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create button: %w", err)
+	}
+	button.SetVAlign(gtk.ALIGN_CENTER)
+	button.SetSizeRequest(100, 35)
 
-	return nil
+	// Output of the last run, shown under the description
+	result, err := gtk.LabelNew("")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create label: %w", err)
+	}
+	result.SetHAlign(gtk.ALIGN_START)
+	result.SetXAlign(0)
+	result.SetLineWrap(true)
+	result.SetLineWrapMode(2) // PANGO_WRAP_WORD = 2
+	result.SetMaxWidthChars(60)
+	result.SetSelectable(true)
+	result.SetNoShowAll(true)
+
+	button.Connect("clicked", func() {
+		sw.runMaintenanceAction(action, button, spinner, result)
+	})
+
+	// Show the problem the action fixes, like repository keys that expire soon, until it is run
+	if action.Warning != nil {
+		go func() {
+			if warning := action.Warning(); warning != "" {
+				glib.IdleAdd(func() bool {
+					if !result.GetVisible() {
+						result.SetText(warning)
+						result.Show()
+					}
+					return false
+				})
+			}
+		}()
+	}
+
+	hbox.PackStart(textBox, true, true, 0)
+	hbox.PackEnd(button, false, false, 0)
+	hbox.PackEnd(spinner, false, false, 0)
+	rowBox.PackStart(hbox, false, false, 0)
+	rowBox.PackStart(result, false, false, 0)
+	return rowBox, nil
+}
+
+// ansiEscapePattern matches the color codes in the output of api-go
+var ansiEscapePattern = regexp.MustCompile(`\x1b\[[0-9;]*[A-Za-z]`)
+
+// maintenanceResultLines is the number of output lines shown under a maintenance action
+const maintenanceResultLines = 10
+
+// maintenancePreviewLines is the number of dry run output lines shown when confirming a maintenance action
+const maintenancePreviewLines = 30
+
+// runMaintenanceAction runs a maintenance action through api-go. Actions that may ask for the sudo password
+// run in a terminal, the others run in the background with a spinner and their output shown in the row.
+func (sw *SettingsWindow) runMaintenanceAction(action maintenanceAction, button *gtk.Button, spinner *gtk.Spinner, result *gtk.Label) {
+	if action.NeedsRoot {
+		sw.runAction(action.ID)
+		result.SetText(T("Running in a terminal window."))
+		result.Show()
+		return
+	}
+
+	var theme string
+	if appListSetting, exists := sw.settings["App List Style"]; exists {
+		theme = appListSetting.Current
+	}
+	if len(action.PreviewArgs) > 0 {
+		sw.previewMaintenanceAction(action, theme, button, spinner, result)
+		return
+	}
+	sw.startMaintenanceAction(action, theme, button, spinner, result)
+}
+
+// previewMaintenanceAction runs the dry run of a maintenance action in the background and asks to confirm
+// its output before running the action
+func (sw *SettingsWindow) previewMaintenanceAction(action maintenanceAction, theme string, button *gtk.Button, spinner *gtk.Spinner, result *gtk.Label) {
+	cmd := exec.Command(filepath.Join(sw.directory, "api-go"), action.PreviewArgs...)
+	cmd.Env = GetThemeEnvironmentForLaunch(theme)
+
+	button.SetSensitive(false)
+	spinner.Show()
+	spinner.Start()
+	result.Hide()
+
+	go func() {
+		output, err := cmd.CombinedOutput()
+		lines := strings.Split(strings.TrimSpace(ansiEscapePattern.ReplaceAllString(string(output), "")), "\n")
+		if len(lines) > maintenancePreviewLines {
+			lines = append(lines[:maintenancePreviewLines], Tf("… %d more lines", len(lines)-maintenancePreviewLines))
+		}
+		text := strings.Join(lines, "\n")
+
+		glib.IdleAdd(func() bool {
+			spinner.Stop()
+			spinner.Hide()
+			button.SetSensitive(true)
+			if err != nil {
+				result.SetText(strings.TrimSpace(Tf("Failed: %v", err) + "\n" + text))
+				result.Show()
+				return false
+			}
+
+			dialog := gtk.MessageDialogNew(sw.window, gtk.DIALOG_MODAL, gtk.MESSAGE_QUESTION, gtk.BUTTONS_YES_NO, "%s", action.Confirm)
+			dialog.FormatSecondaryText("%s", text)
+			dialog.SetTitle(action.Name)
+			response := dialog.Run()
+			dialog.Destroy()
+			if response == gtk.RESPONSE_YES {
+				sw.startMaintenanceAction(action, theme, button, spinner, result)
+			} else {
+				result.SetText(T("Cancelled."))
+				result.Show()
+			}
+			return false
+		})
+	}()
+}
+
+// startMaintenanceAction runs a maintenance action in the background, with a spinner and its output shown in the row
+func (sw *SettingsWindow) startMaintenanceAction(action maintenanceAction, theme string, button *gtk.Button, spinner *gtk.Spinner, result *gtk.Label) {
+	cmd := exec.Command(filepath.Join(sw.directory, "api-go"), action.Args...)
+	cmd.Env = GetThemeEnvironmentForLaunch(theme)
+
+	button.SetSensitive(false)
+	spinner.Show()
+	spinner.Start()
+	result.SetText(action.Title + "…")
+	result.Show()
+
+	go func() {
+		output, err := cmd.CombinedOutput()
+		lines := strings.Split(strings.TrimSpace(ansiEscapePattern.ReplaceAllString(string(output), "")), "\n")
+		if len(lines) > maintenanceResultLines {
+			lines = append([]string{Tf("… %d more lines", len(lines)-maintenanceResultLines)}, lines[len(lines)-maintenanceResultLines:]...)
+		}
+		text := strings.TrimSpace(strings.Join(lines, "\n"))
+		if err != nil {
+			text = strings.TrimSpace(Tf("Failed: %v", err) + "\n" + text)
+		} else if text == "" {
+			text = T("Done.")
+		}
+
+		glib.IdleAdd(func() bool {
+			spinner.Stop()
+			spinner.Hide()
+			button.SetSensitive(true)
+			result.SetText(text)
+			return false
+		})
+	}()
 }
 
 // createButtons creates the main action buttons (Save, Cancel, Reset)
@@ -351,7 +735,6 @@ func (sw *SettingsWindow) createButtons(buttonBox *gtk.Box) error {
 	saveButton.SetSizeRequest(80, 35)
 	saveButton.Connect("clicked", func() {
 		sw.saveSettings()
-		sw.window.Close()
 	})
 
 	// Pack buttons with consistent spacing
@@ -391,8 +774,12 @@ func runSettingsAction(directory, action, appListTheme string) {
 	case "multi_uninstall":
 		cmd = exec.Command(apiPath, "multi_uninstall_gui")
 	default:
-		fmt.Println(Tf("Unknown action: %s", action))
-		return
+		maintenance, ok := findMaintenanceAction(action)
+		if !ok {
+			fmt.Println(Tf("Unknown action: %s", action))
+			return
+		}
+		cmd = maintenanceTerminalCommand(directory, maintenance)
 	}
 
 	cmd.Env = GetThemeEnvironmentForLaunch(appListTheme)
@@ -416,46 +803,88 @@ func (sw *SettingsWindow) resetSettings() {
 		return
 	}
 
-	// Reset each setting
-	for settingName, setting := range sw.settings {
-		if len(setting.Values) > 0 {
-			// Set to first value (default)
-			defaultValue := setting.Values[0]
-			setting.Current = defaultValue
-
-			// Update combo box
-			if combo, exists := sw.comboBoxes[settingName]; exists {
-				combo.SetActive(0)
-			}
+	sw.withSettingsLock(T("Failed to reset settings"), func() error {
+		var errs []error
+		for settingName, setting := range sw.settings {
+			if len(setting.Values) > 0 {
+				defaultIndex := defaultValueIndex(setting)
+				defaultValue := setting.Values[defaultIndex]
+				setting.Current = defaultValue
+
+				// Update combo box
+				if combo, exists := sw.comboBoxes[settingName]; exists {
+					combo.SetActive(defaultIndex)
+				}
 
-			// Save to file
-			settingPath := filepath.Join(sw.directory, "data", "settings", settingName)
-			if err := os.WriteFile(settingPath, []byte(defaultValue), 0644); err != nil {
-				fmt.Println(Tf("Failed to reset setting %s: %v", settingName, err))
+				// Save to file
+				if err := api.WriteSettingIn(sw.directory, settingName, defaultValue); err != nil {
+					errs = append(errs, fmt.Errorf("%s: %w", TranslateSettingName(settingName), err))
+				}
 			}
 		}
-	}
+		return errors.Join(errs...)
+	}, nil)
 }
 
-// saveSettings saves current settings to files using canonical values (not translated labels).
+// saveSettings saves current settings to files using canonical values (not translated labels), then closes the window.
+// The window stays open when they could not be saved, so the changes aren't lost.
 func (sw *SettingsWindow) saveSettings() {
-	for settingName, combo := range sw.comboBoxes {
-		activeText := combo.GetActiveText()
-		if activeText == "" {
-			continue
-		}
+	sw.withSettingsLock(T("Failed to save settings"), func() error {
+		var errs []error
+		for settingName, combo := range sw.comboBoxes {
+			activeText := combo.GetActiveText()
+			if activeText == "" {
+				continue
+			}
 
-		setting, exists := sw.settings[settingName]
-		if !exists {
-			continue
-		}
+			setting, exists := sw.settings[settingName]
+			if !exists {
+				continue
+			}
 
-		canonical := canonicalValueFromTranslatedSelect(setting, activeText)
-		setting.Current = canonical
+			canonical := canonicalValueFromTranslatedSelect(setting, activeText)
+			setting.Current = canonical
 
-		settingPath := filepath.Join(sw.directory, "data", "settings", settingName)
-		if err := os.WriteFile(settingPath, []byte(canonical), 0644); err != nil {
-			fmt.Println(Tf("Failed to save setting %s: %v", settingName, err))
+			if err := api.WriteSettingIn(sw.directory, settingName, canonical); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", TranslateSettingName(settingName), err))
+			}
 		}
-	}
+		return errors.Join(errs...)
+	}, sw.window.Close)
+}
+
+// withSettingsLock waits for the settings lock in the background, so another Pi-Apps process holding the
+// Pi-Apps directory doesn't freeze the window, and then runs write on the main thread while holding it.
+// The window can't be used while waiting. When the lock can't be taken or write fails, an error dialog
+// titled failure is shown, otherwise done is called if it isn't nil.
+func (sw *SettingsWindow) withSettingsLock(failure string, write func() error, done func()) {
+	sw.window.SetSensitive(false)
+	go func() {
+		lock, err := lockSettings(sw.directory)
+		glib.IdleAdd(func() bool {
+			sw.window.SetSensitive(true)
+			if err == nil {
+				err = write()
+				lock.Release()
+			}
+			if err != nil {
+				sw.showError(failure, err)
+				return false
+			}
+			if done != nil {
+				done()
+			}
+			return false
+		})
+	}()
+}
+
+// showError shows an error in a dialog over the settings window
+func (sw *SettingsWindow) showError(title string, err error) {
+	fmt.Println(title + ": " + err.Error())
+	dialog := gtk.MessageDialogNew(sw.window, gtk.DIALOG_MODAL, gtk.MESSAGE_ERROR, gtk.BUTTONS_OK, "%s", title)
+	dialog.FormatSecondaryText("%s", err.Error())
+	dialog.SetTitle(title)
+	dialog.Run()
+	dialog.Destroy()
 }
@@ -24,6 +24,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+
+	"github.com/pi-apps-go/pi-apps/pkg/api"
 )
 
 var (
@@ -33,6 +35,10 @@ var (
 
 // ErrorNoExit displays an error message in red but does not exit the program
 func ErrorNoExit(msg string) {
+	if !api.ColorEnabled(os.Stderr) {
+		fmt.Fprintln(os.Stderr, msg)
+		return
+	}
 	// Use the exact same ANSI sequence as the original bash script
 	fmt.Fprintln(os.Stderr, "\033[91m"+msg+"\033[0m")
 }
@@ -0,0 +1,155 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: terminal.go
+// Description: Sets the title of the terminal window and prints the headers shown between the items of a queue.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package terminal
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/pi-apps-go/pi-apps/pkg/api"
+)
+
+// noTitleTerms are the TERM values of terminals that print the title escape sequence instead of setting a title,
+// like the Linux console
+var noTitleTerms = []string{"", "dumb", "linux"}
+
+// openTitleOutput opens what the title is written to: the controlling terminal, which still is the terminal window
+// when the output of Pi-Apps is piped, like in the daemon terminal that copies its output to a log
+var openTitleOutput = func() (io.WriteCloser, error) {
+	return os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+}
+
+// titleSupported reports whether the terminal Pi-Apps runs in can have its title set
+func titleSupported() bool {
+	return runtime.GOOS != "windows" && !slices.Contains(noTitleTerms, os.Getenv("TERM"))
+}
+
+// titleSequence returns the escape sequence setting the title of a terminal, with the control characters of the
+// title removed so an app name can't end the sequence early
+func titleSequence(title string) string {
+	return "\033]0;" + api.TerminalTitle(title) + "\007"
+}
+
+// SetTitle sets the title of the terminal Pi-Apps runs in. It does nothing in a terminal that doesn't support
+// titles or when there is no terminal at all.
+func SetTitle(title string) {
+	if !titleSupported() {
+		return
+	}
+	output, err := openTitleOutput()
+	if err != nil {
+		return
+	}
+	defer output.Close()
+	io.WriteString(output, titleSequence(title))
+}
+
+// ActionTitle describes an action of the queue on an app, like "Installing Zoom"
+func ActionTitle(action, app string) string {
+	switch action {
+	case "install":
+		return api.Tf("Installing %s", app)
+	case "uninstall":
+		return api.Tf("Uninstalling %s", app)
+	case "update":
+		return api.Tf("Updating %s", app)
+	case "refresh":
+		return api.Tf("Refreshing %s", app)
+	case "update-file":
+		return api.Tf("Updating %s", app)
+	}
+	return action + " " + app
+}
+
+// logoOnce prints the logo above the first banner of the session only
+var logoOnce sync.Once
+
+// Banner prints the header shown before an item of the queue runs: a box with the action and the app, the
+// Pi-Apps logo above the first one
+func Banner(app, action string) {
+	logoOnce.Do(func() {
+		fmt.Print(api.GenerateLogo())
+	})
+	fmt.Print(banner(ActionTitle(action, app)))
+}
+
+// banner returns the box Banner prints around a title
+func banner(title string) string {
+	box := lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).Padding(0, 1)
+	return "\n" + box.Render(api.TerminalTitle(title)) + "\n"
+}
+
+// ElapsedTicker shows the time the current item of the queue has been running in the title of the terminal
+type ElapsedTicker struct {
+	stop chan struct{}
+	done chan struct{}
+}
+
+// StartElapsedTicker sets the title of the terminal to title, followed by the elapsed time every second
+// until the ticker is stopped
+func StartElapsedTicker(title string) *ElapsedTicker {
+	ticker := &ElapsedTicker{stop: make(chan struct{}), done: make(chan struct{})}
+	if !titleSupported() {
+		close(ticker.done)
+		return ticker
+	}
+	started := time.Now()
+	SetTitle(title)
+	go func() {
+		defer close(ticker.done)
+		tick := time.NewTicker(time.Second)
+		defer tick.Stop()
+		for {
+			select {
+			case <-ticker.stop:
+				return
+			case now := <-tick.C:
+				SetTitle(elapsedTitle(title, now.Sub(started)))
+			}
+		}
+	}()
+	return ticker
+}
+
+// Stop stops updating the title, it stays at the last elapsed time
+func (t *ElapsedTicker) Stop() {
+	select {
+	case <-t.stop:
+	default:
+		close(t.stop)
+	}
+	<-t.done
+}
+
+// elapsedTitle returns title followed by the elapsed time, like "Installing Zoom (1:05)"
+func elapsedTitle(title string, elapsed time.Duration) string {
+	seconds := int(elapsed.Round(time.Second) / time.Second)
+	if seconds >= 3600 {
+		return fmt.Sprintf("%s (%d:%02d:%02d)", title, seconds/3600, seconds/60%60, seconds%60)
+	}
+	return fmt.Sprintf("%s (%d:%02d)", title, seconds/60, seconds%60)
+}
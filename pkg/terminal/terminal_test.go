@@ -0,0 +1,106 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package terminal
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+// titleRecorder records what SetTitle writes instead of writing it to the terminal
+type titleRecorder struct {
+	strings.Builder
+}
+
+func (r *titleRecorder) Close() error { return nil }
+
+func recordTitles(t *testing.T) *titleRecorder {
+	t.Helper()
+	recorder := &titleRecorder{}
+	original := openTitleOutput
+	openTitleOutput = func() (io.WriteCloser, error) { return recorder, nil }
+	t.Cleanup(func() { openTitleOutput = original })
+	return recorder
+}
+
+func TestTitleSequenceEscaping(t *testing.T) {
+	for app, want := range map[string]string{
+		"Zoom":                     "\033]0;Installing Zoom\007",
+		"Evil\007\033]0;pwned":     "\033]0;Installing Evil]0;pwned\007",
+		"Two\nLines\r":             "\033]0;Installing TwoLines\007",
+		"C1\u009cterminator\u009d": "\033]0;Installing C1terminator\007",
+		"Tab\tbed":                 "\033]0;Installing Tabbed\007",
+		"Ünïcödé 🥧":                "\033]0;Installing Ünïcödé 🥧\007",
+	} {
+		if got := titleSequence(ActionTitle("install", app)); got != want {
+			t.Errorf("titleSequence for %q = %q, want %q", app, got, want)
+		}
+	}
+}
+
+func TestSetTitle(t *testing.T) {
+	recorder := recordTitles(t)
+	for _, term := range []string{"", "dumb", "linux"} {
+		t.Setenv("TERM", term)
+		SetTitle("Installing Zoom")
+	}
+	if recorder.Len() != 0 {
+		t.Errorf("SetTitle wrote %q to a terminal without titles", recorder.String())
+	}
+
+	t.Setenv("TERM", "xterm-256color")
+	SetTitle("Updating \033[31mZoom")
+	if got, want := recorder.String(), "\033]0;Updating [31mZoom\007"; got != want {
+		t.Errorf("SetTitle wrote %q, want %q", got, want)
+	}
+}
+
+func TestBanner(t *testing.T) {
+	box := banner(ActionTitle("uninstall", "Bad\033[2JApp"))
+	if strings.Contains(box, "\033") {
+		t.Errorf("banner kept the escape sequence of the app name: %q", box)
+	}
+	lines := strings.Split(strings.Trim(box, "\n"), "\n")
+	if len(lines) != 3 || !strings.Contains(lines[1], "Uninstalling Bad[2JApp") {
+		t.Errorf("banner = %q, want a box around the title", box)
+	}
+}
+
+func TestElapsedTitle(t *testing.T) {
+	for elapsed, want := range map[time.Duration]string{
+		0:                "Installing Zoom (0:00)",
+		65 * time.Second: "Installing Zoom (1:05)",
+		time.Hour + 2*time.Minute + 3*time.Second: "Installing Zoom (1:02:03)",
+	} {
+		if got := elapsedTitle("Installing Zoom", elapsed); got != want {
+			t.Errorf("elapsedTitle(%v) = %q, want %q", elapsed, got, want)
+		}
+	}
+}
+
+func TestElapsedTicker(t *testing.T) {
+	recorder := recordTitles(t)
+	t.Setenv("TERM", "xterm")
+	ticker := StartElapsedTicker("Refreshing Zoom")
+	ticker.Stop()
+	ticker.Stop()
+	if !strings.HasPrefix(recorder.String(), "\033]0;Refreshing Zoom\007") {
+		t.Errorf("StartElapsedTicker wrote %q, want the title first", recorder.String())
+	}
+}
@@ -0,0 +1,421 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: harness.go
+// Description: Runs the install and uninstall scripts of an app in a podman or docker container and reports the result.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+// Package testharness smoke-tests app scripts in a throwaway container, so maintainers can check an app
+// update starts correctly on each supported system before merging it.
+//
+// The Pi-Apps directory is mounted read-only, with a fresh writable data and logs directory over it.
+// Scripts run as root in the container, sudo only drops its options. In quick mode sudo, apt and the
+// other privileged commands stop the script instead, which checks the part before them in seconds.
+package testharness
+
+import (
+	"bytes"
+	"debug/elf"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pi-apps-go/pi-apps/pkg/api"
+)
+
+// Paths inside the container
+const (
+	containerPiAppsDir  = "/pi-apps"
+	containerHarnessDir = "/pi-apps-harness"
+)
+
+// quickStopFile is written by the quick mode shims with the command the script stopped at
+const quickStopFile = "quick-stop"
+
+// setupPackages are the tools a Raspberry Pi OS install has and app scripts or the api expect
+var setupPackages = []string{"ca-certificates", "wget", "curl", "git", "gnupg", "lsb-release", "apt-utils", "psmisc", "file", "unzip", "xz-utils"}
+
+// quickStopCommands stop a script in quick mode, since they change the system
+var quickStopCommands = []string{"sudo", "pkexec", "apt", "apt-get", "dpkg", "flatpak", "systemctl", "reboot"}
+
+// Options configures a test run
+type Options struct {
+	Target        Target
+	Quick         bool      // stop each script at its first privileged or destructive command
+	SkipUninstall bool      // only run the install script
+	APIBinary     string    // statically linked api-go for the target, the running executable when empty
+	Runtime       string    // podman or docker, found in PATH when empty
+	OutputDir     string    // where the logs are written, a new temporary directory when empty
+	Output        io.Writer // receives the script output as it runs, nil for none
+}
+
+// StepResult is the result of running one script
+type StepResult struct {
+	Script    string        `json:"script"`
+	Passed    bool          `json:"passed"`
+	ExitCode  int           `json:"exit_code"`
+	Duration  time.Duration `json:"duration"`
+	LogPath   string        `json:"log_path"`
+	StoppedAt string        `json:"stopped_at,omitempty"` // the command quick mode stopped at
+	ErrorType string        `json:"error_type,omitempty"` // diagnosis of a failure: system, package, internet or unknown
+	Captions  []string      `json:"captions,omitempty"`
+}
+
+// Result is the result of testing an app on a target
+type Result struct {
+	App    string       `json:"app"`
+	Target string       `json:"target"`
+	Quick  bool         `json:"quick"`
+	Passed bool         `json:"passed"`
+	Steps  []StepResult `json:"steps"`
+}
+
+// Run tests the install and uninstall scripts of an app in a container of the target system
+func Run(app string, options Options) (*Result, error) {
+	if err := api.ValidateAppName(app); err != nil {
+		return nil, err
+	}
+	if options.Target.Image == "" {
+		options.Target = DefaultTarget()
+	}
+
+	install := installScriptName(app, options.Target)
+	if install == "" {
+		return nil, fmt.Errorf("%s has no install script for %s, package apps have nothing to test", app, options.Target.Arch())
+	}
+	scripts := []string{install}
+	if !options.SkipUninstall {
+		scripts = append(scripts, "uninstall")
+	}
+
+	runtimeBin, err := findRuntime(options.Runtime)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkEmulation(options.Target); err != nil {
+		return nil, err
+	}
+	apiBinary, err := staticAPIBinary(options.APIBinary)
+	if err != nil {
+		return nil, err
+	}
+
+	if options.OutputDir == "" {
+		if options.OutputDir, err = os.MkdirTemp("", "pi-apps-test-"+app+"-"); err != nil {
+			return nil, err
+		}
+	} else if err := os.MkdirAll(options.OutputDir, 0755); err != nil {
+		return nil, err
+	}
+	harnessDir, err := prepareHarnessDir(app, apiBinary, options.Quick)
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(harnessDir)
+
+	container, err := startContainer(runtimeBin, app, options.Target, harnessDir)
+	if err != nil {
+		return nil, err
+	}
+	defer exec.Command(runtimeBin, "rm", "-f", container).Run()
+
+	result := &Result{App: app, Target: options.Target.Name, Quick: options.Quick, Passed: true}
+
+	setupLog := filepath.Join(options.OutputDir, "setup.log")
+	if err := runInContainer(runtimeBin, container, setupLog, options.Output, "bash", "-c", setupCommand()); err != nil {
+		return nil, fmt.Errorf("failed to prepare the container, see %s: %w", setupLog, err)
+	}
+
+	for _, script := range scripts {
+		step, err := runStep(runtimeBin, container, app, script, harnessDir, options)
+		if err != nil {
+			return result, err
+		}
+		result.Steps = append(result.Steps, step)
+		if !step.Passed {
+			result.Passed = false
+			// Uninstalling an app that failed to install tells nothing
+			break
+		}
+	}
+	return result, nil
+}
+
+// installScriptName returns the install script of an app for the target, "" if it has none
+func installScriptName(app string, target Target) string {
+	candidates := []string{"install", "install-32"}
+	if target.Is64Bit() {
+		candidates = []string{"install", "install-64"}
+	}
+	for _, name := range candidates {
+		if path, err := api.AppPath(app, name); err == nil && api.FileExists(path) {
+			return name
+		}
+	}
+	return ""
+}
+
+// findRuntime returns the container runtime to use, podman first since it doesn't need a daemon
+func findRuntime(preferred string) (string, error) {
+	candidates := []string{"podman", "docker"}
+	if preferred != "" {
+		candidates = []string{preferred}
+	}
+	for _, candidate := range candidates {
+		if path, err := exec.LookPath(candidate); err == nil {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("no container runtime found, install podman or docker (tried %s)", strings.Join(candidates, ", "))
+}
+
+// checkEmulation makes sure a target of another architecture can run through qemu-user-static
+func checkEmulation(target Target) error {
+	host, arch := HostArch(), target.Arch()
+	// 64-bit ARM boards run 32-bit ARM programs natively
+	if arch == host || (host == "arm64" && arch == "armhf") {
+		return nil
+	}
+	qemuNames := map[string]string{"arm64": "aarch64", "armhf": "arm", "amd64": "x86_64", "i386": "i386", "riscv64": "riscv64"}
+	qemu, ok := qemuNames[arch]
+	if !ok {
+		return fmt.Errorf("don't know how to emulate %s", arch)
+	}
+	if _, err := os.Stat("/proc/sys/fs/binfmt_misc/qemu-" + qemu); err != nil {
+		return fmt.Errorf("testing on %s needs emulation of %s, install the qemu-user-static and binfmt-support packages", target.Name, arch)
+	}
+	return nil
+}
+
+// staticAPIBinary returns the api-go binary to mount in the container. It has to be statically linked,
+// the container doesn't have the libraries of this system. Linux runs it natively even in a container of
+// another architecture.
+func staticAPIBinary(path string) (string, error) {
+	if path == "" {
+		executable, err := os.Executable()
+		if err != nil {
+			return "", err
+		}
+		path = executable
+	}
+	file, err := elf.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	defer file.Close()
+	for _, program := range file.Progs {
+		if program.Type == elf.PT_INTERP {
+			return "", fmt.Errorf("%s is dynamically linked, build a static one with CGO_ENABLED=0 go build -tags \"apt nogui\" -o api-go-static ./cmd/api and pass it with --api-bin", path)
+		}
+	}
+	return path, nil
+}
+
+// prepareHarnessDir writes the api-go binary, the step wrapper and the command shims to a directory mounted in the container
+func prepareHarnessDir(app, apiBinary string, quick bool) (string, error) {
+	dir, err := os.MkdirTemp("", "pi-apps-harness-")
+	if err != nil {
+		return "", err
+	}
+	// The container user needs to read it
+	if err := os.Chmod(dir, 0755); err != nil {
+		return dir, err
+	}
+	if err := copyExecutable(apiBinary, filepath.Join(dir, "api-go")); err != nil {
+		return dir, err
+	}
+
+	shims := filepath.Join(dir, "shims")
+	if err := os.MkdirAll(shims, 0755); err != nil {
+		return dir, err
+	}
+	// The scripts run as root, so sudo and pkexec only have to drop their options
+	passthrough := `#!/bin/sh
+while [ $# -gt 0 ]; do
+  case "$1" in
+    -u|-g|-p|-C|-D|-h|-r|-t|-U|--user) shift 2 ;;
+    --) shift; break ;;
+    -*) shift ;;
+    *) break ;;
+  esac
+done
+exec "$@"
+`
+	stop := `#!/bin/sh
+[ -e ` + containerHarnessDir + `/out/` + quickStopFile + ` ] || echo "$(basename "$0") $*" > ` + containerHarnessDir + `/out/` + quickStopFile + `
+echo "Quick mode: stopping at the first privileged command: $(basename "$0") $*" >&2
+exit 99
+`
+	for _, name := range []string{"sudo", "pkexec"} {
+		if err := os.WriteFile(filepath.Join(shims, name), []byte(passthrough), 0755); err != nil {
+			return dir, err
+		}
+	}
+	if quick {
+		for _, name := range quickStopCommands {
+			if err := os.WriteFile(filepath.Join(shims, name), []byte(stop), 0755); err != nil {
+				return dir, err
+			}
+		}
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "out"), 0777); err != nil {
+		return dir, err
+	}
+	return dir, os.Chmod(filepath.Join(dir, "out"), 0777)
+}
+
+// copyExecutable copies a binary, keeping it executable
+func copyExecutable(src, dst string) error {
+	content, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(dst, content, 0755)
+}
+
+// startContainer starts the container the scripts run in, returning its name
+func startContainer(runtimeBin, app string, target Target, harnessDir string) (string, error) {
+	name := fmt.Sprintf("pi-apps-test-%s-%d", strings.ToLower(strings.ReplaceAll(app, " ", "-")), time.Now().UnixNano())
+	piAppsDir := api.GetPiAppsDir()
+
+	args := []string{"run", "-d", "--name", name, "--platform", target.Platform,
+		"-v", piAppsDir + ":" + containerPiAppsDir + ":ro",
+		"-v", harnessDir + ":" + containerHarnessDir,
+	}
+	// A fresh data and logs directory over the read-only Pi-Apps directory
+	for _, sub := range []string{"data", "logs"} {
+		if !api.DirExists(filepath.Join(piAppsDir, sub)) {
+			continue
+		}
+		writable := filepath.Join(harnessDir, sub)
+		if err := os.MkdirAll(writable, 0777); err != nil {
+			return "", err
+		}
+		args = append(args, "-v", writable+":"+containerPiAppsDir+"/"+sub)
+	}
+	args = append(args, target.Image, "sleep", "infinity")
+
+	output, err := exec.Command(runtimeBin, args...).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("failed to start a %s container: %w\n%s", target.Image, err, strings.TrimSpace(string(output)))
+	}
+	return name, nil
+}
+
+// setupCommand installs the tools app scripts expect when the image has apt
+func setupCommand() string {
+	return "if command -v apt-get >/dev/null; then export DEBIAN_FRONTEND=noninteractive; " +
+		"apt-get update && apt-get install -y --no-install-recommends " + strings.Join(setupPackages, " ") + "; fi"
+}
+
+// runInContainer runs a command in the container, writing its output to a log file and to output
+func runInContainer(runtimeBin, container, logPath string, output io.Writer, command ...string) error {
+	logFile, err := os.Create(logPath)
+	if err != nil {
+		return err
+	}
+	defer logFile.Close()
+
+	var writer io.Writer = api.NewAnsiStripWriter(logFile)
+	if output != nil {
+		writer = io.MultiWriter(writer, output)
+	}
+
+	args := append([]string{"exec",
+		"-e", "PATH=" + containerHarnessDir + "/shims:/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin",
+		"-e", "HOME=/root",
+		"-e", "PI_APPS_DIR=" + containerPiAppsDir,
+		"-e", "PI_APPS_ALLOW_ROOT=1",
+		"-e", "DEBIAN_FRONTEND=noninteractive",
+	}, exitEnvArgs()...)
+	args = append(args, container)
+	args = append(args, command...)
+
+	cmd := exec.Command(runtimeBin, args...)
+	cmd.Stdout = writer
+	cmd.Stderr = writer
+	return cmd.Run()
+}
+
+// exitEnvArgs passes the exit code convention of app scripts to the container
+func exitEnvArgs() []string {
+	var args []string
+	for _, env := range api.ScriptExitEnv() {
+		args = append(args, "-e", env)
+	}
+	return args
+}
+
+// runStep runs one script of the app and diagnoses its log if it failed
+func runStep(runtimeBin, container, app, script, harnessDir string, options Options) (StepResult, error) {
+	step := StepResult{Script: script, LogPath: filepath.Join(options.OutputDir, script+".log")}
+
+	scriptPath, err := api.AppPath(app, script)
+	if err != nil {
+		return step, err
+	}
+	content, err := os.ReadFile(scriptPath)
+	if err != nil {
+		return step, err
+	}
+	// Like runAppScript, the api is sourced from next to api-go so its functions find the binary
+	wrapper := fmt.Sprintf(`#!/bin/bash
+set -e
+export GO_API_BIN=%[1]s/api-go
+export PI_APPS_DIR=%[2]s
+export app=%[3]s
+source %[2]s/api
+cd "$HOME"
+%[4]s`, containerHarnessDir, containerPiAppsDir, strconv.Quote(app), content)
+	if err := os.WriteFile(filepath.Join(harnessDir, "step.sh"), []byte(wrapper), 0755); err != nil {
+		return step, err
+	}
+	stopFile := filepath.Join(harnessDir, "out", quickStopFile)
+	os.Remove(stopFile)
+
+	start := time.Now()
+	err = runInContainer(runtimeBin, container, step.LogPath, options.Output, containerHarnessDir+"/step.sh")
+	step.Duration = time.Since(start).Round(time.Second)
+
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		return step, fmt.Errorf("failed to run the %s script: %w", script, err)
+	}
+	if exitErr != nil {
+		step.ExitCode = exitErr.ExitCode()
+	}
+
+	if stopped, err := os.ReadFile(stopFile); err == nil {
+		// Everything before the first privileged command ran
+		step.StoppedAt = string(bytes.TrimSpace(stopped))
+		step.Passed = true
+		return step, nil
+	}
+	step.Passed = step.ExitCode == 0
+	if !step.Passed {
+		if diagnosis, err := api.LogDiagnose(step.LogPath, false); err == nil {
+			step.ErrorType = diagnosis.ErrorType
+			step.Captions = diagnosis.Captions
+		}
+	}
+	return step, nil
+}
@@ -0,0 +1,137 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: targets.go
+// Description: The operating systems app scripts can be tested on, as container images per architecture.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package testharness
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/pi-apps-go/pi-apps/pkg/api"
+)
+
+// Target is an operating system to test app scripts on
+type Target struct {
+	Name     string // e.g. bookworm-arm64
+	Image    string // container image, e.g. docker.io/library/debian:bookworm
+	Platform string // container platform, e.g. linux/arm64
+}
+
+// builtinTargets are the systems Pi-Apps supports. More can be added in data/test-targets.
+var builtinTargets = []Target{
+	{Name: "bookworm-arm64", Image: "docker.io/library/debian:bookworm", Platform: "linux/arm64"},
+	{Name: "bookworm-armhf", Image: "docker.io/library/debian:bookworm", Platform: "linux/arm/v7"},
+	{Name: "bookworm-amd64", Image: "docker.io/library/debian:bookworm", Platform: "linux/amd64"},
+	{Name: "trixie-arm64", Image: "docker.io/library/debian:trixie", Platform: "linux/arm64"},
+	{Name: "trixie-armhf", Image: "docker.io/library/debian:trixie", Platform: "linux/arm/v7"},
+	{Name: "jammy-arm64", Image: "docker.io/library/ubuntu:22.04", Platform: "linux/arm64"},
+	{Name: "noble-arm64", Image: "docker.io/library/ubuntu:24.04", Platform: "linux/arm64"},
+	{Name: "raspios-bookworm-arm64", Image: "docker.io/balenalib/raspberrypi4-64-debian:bookworm", Platform: "linux/arm64"},
+	{Name: "raspios-bookworm-armhf", Image: "docker.io/balenalib/raspberrypi3-debian:bookworm", Platform: "linux/arm/v7"},
+}
+
+// Arch returns the Debian architecture of the target, like arm64 or armhf
+func (t Target) Arch() string {
+	return debianArch(strings.TrimPrefix(t.Platform, "linux/"))
+}
+
+// Is64Bit reports whether the target runs install-64 scripts
+func (t Target) Is64Bit() bool {
+	switch t.Arch() {
+	case "arm64", "amd64", "riscv64":
+		return true
+	}
+	return false
+}
+
+// debianArch converts a Go or container architecture to the Debian name of it
+func debianArch(arch string) string {
+	switch {
+	case arch == "arm64" || arch == "aarch64":
+		return "arm64"
+	case strings.HasPrefix(arch, "arm"):
+		return "armhf"
+	case arch == "amd64" || arch == "x86_64":
+		return "amd64"
+	case arch == "386":
+		return "i386"
+	}
+	return arch
+}
+
+// HostArch returns the Debian architecture of this system
+func HostArch() string {
+	return debianArch(runtime.GOARCH)
+}
+
+// DefaultTarget returns the bookworm target of this system's architecture
+func DefaultTarget() Target {
+	target, err := FindTarget("bookworm-" + HostArch())
+	if err != nil {
+		return builtinTargets[0]
+	}
+	return target
+}
+
+// Targets returns the built-in targets and the ones in data/test-targets, which has one
+// "name image platform" line per target and replaces a built-in target with the same name
+func Targets() []Target {
+	targets := slices.Clone(builtinTargets)
+
+	file, err := os.Open(filepath.Join(api.GetDataDir(), "test-targets"))
+	if err != nil {
+		return targets
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			continue
+		}
+		target := Target{Name: fields[0], Image: fields[1], Platform: fields[2]}
+		if i := slices.IndexFunc(targets, func(t Target) bool { return t.Name == target.Name }); i >= 0 {
+			targets[i] = target
+		} else {
+			targets = append(targets, target)
+		}
+	}
+	return targets
+}
+
+// FindTarget returns the target with a name
+func FindTarget(name string) (Target, error) {
+	targets := Targets()
+	if i := slices.IndexFunc(targets, func(t Target) bool { return t.Name == name }); i >= 0 {
+		return targets[i], nil
+	}
+	names := make([]string, 0, len(targets))
+	for _, target := range targets {
+		names = append(names, target.Name)
+	}
+	return Target{}, fmt.Errorf("unknown target %q, available targets: %s", name, strings.Join(names, ", "))
+}
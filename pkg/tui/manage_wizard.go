@@ -0,0 +1,162 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: manage_wizard.go
+// Description: Guided terminal flow of the manage command: pick an operation and the apps it applies to.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package tui
+
+import (
+	"github.com/pi-apps-go/pi-apps/pkg/api"
+)
+
+// Operations offered by the manage wizard
+const (
+	WizardInstall    = "install"
+	WizardUninstall  = "uninstall"
+	WizardUpdate     = "update"
+	WizardUpdateSelf = "update-self"
+	WizardSearch     = "search"
+)
+
+// WizardEntry is an app the manage wizard queued
+type WizardEntry struct {
+	Action  string // install, uninstall or update
+	AppName string
+}
+
+// WizardPlan is what the user picked in the manage wizard
+type WizardPlan struct {
+	UpdateSelf bool          // update Pi-Apps itself instead of apps
+	Queue      []WizardEntry // apps in the order they are listed
+}
+
+func wizardChoices() []Choice {
+	return []Choice{
+		{ID: WizardInstall, Name: api.T("Install"), Details: api.T("Install apps that are not installed yet")},
+		{ID: WizardUninstall, Name: api.T("Uninstall"), Details: api.T("Uninstall installed apps")},
+		{ID: WizardUpdate, Name: api.T("Update"), Details: api.T("Reinstall installed apps that have an update")},
+		{ID: WizardUpdateSelf, Name: api.T("Update Pi-Apps"), Details: api.T("Update Pi-Apps itself with the updater")},
+		{ID: WizardSearch, Name: api.T("Search"), Details: api.T("Search the descriptions of all apps, then install or uninstall the results")},
+	}
+}
+
+// RunManageWizard asks the user what to do and which apps to do it with, ok is false if the user cancelled
+// or there is no app to pick
+func RunManageWizard() (plan WizardPlan, ok bool, err error) {
+	choice, ok, err := RunMenu(api.T("What do you want to do?"), wizardChoices())
+	if err != nil || !ok {
+		return WizardPlan{}, false, err
+	}
+	if choice.ID == WizardUpdateSelf {
+		return WizardPlan{UpdateSelf: true}, true, nil
+	}
+
+	var matches []string
+	if choice.ID == WizardSearch {
+		query, ok, err := RunPrompt(api.T("Search for apps"), api.T("Name or description"))
+		if err != nil || !ok || query == "" {
+			return WizardPlan{}, false, err
+		}
+		if matches, err = api.AppSearch(query); err != nil {
+			return WizardPlan{}, false, err
+		}
+	}
+
+	entries, err := api.QueryApps(api.AppListQuery{})
+	if err != nil {
+		return WizardPlan{}, false, err
+	}
+	items := wizardApps(choice.ID, entries, matches, api.PendingAppUpdates())
+	if len(items) == 0 {
+		api.Status(api.T("There are no apps to choose from."))
+		return WizardPlan{}, false, nil
+	}
+	for i := range items {
+		items[i].Summary = api.AppDescription(items[i].Name)
+	}
+
+	picked, err := RunAppPicker(choice.Name, items)
+	if err != nil || len(picked) == 0 {
+		return WizardPlan{}, false, err
+	}
+	return WizardPlan{Queue: wizardQueue(choice.ID, picked)}, true, nil
+}
+
+// wizardApps returns the apps an operation can be applied to. Apps with a pending update are preselected for updates,
+// the search only offers the matches.
+func wizardApps(operation string, entries []api.AppListEntry, matches []string, pending map[string]bool) []AppItem {
+	matched := make(map[string]bool, len(matches))
+	for _, app := range matches {
+		matched[app] = true
+	}
+
+	var items []AppItem
+	for _, entry := range entries {
+		installed := entry.Status == string(api.AppStateInstalled)
+		item := AppItem{Name: entry.Name}
+		switch operation {
+		case WizardInstall:
+			// Hidden apps are not installed by hand, corrupted ones can be installed again
+			if installed || entry.Status == string(api.AppStateDisabled) || entry.Category == "hidden" {
+				continue
+			}
+			if entry.Status == string(api.AppStateCorrupted) {
+				item.Status = entry.Status
+			}
+		case WizardUninstall:
+			if !installed && entry.Status != string(api.AppStateCorrupted) {
+				continue
+			}
+			item.Status = entry.Status
+		case WizardUpdate:
+			if !installed {
+				continue
+			}
+			if pending[entry.Name] {
+				item.Status = "update available"
+				item.Selected = true
+			}
+		case WizardSearch:
+			if !matched[entry.Name] || entry.Status == string(api.AppStateDisabled) {
+				continue
+			}
+			item.Status = entry.Status
+		default:
+			continue
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// wizardQueue returns the queue of the picked apps. Search results are installed, or uninstalled if they are installed,
+// like the button of the app details in the GUI.
+func wizardQueue(operation string, picked []AppItem) []WizardEntry {
+	queue := make([]WizardEntry, len(picked))
+	for i, app := range picked {
+		action := operation
+		if operation == WizardSearch {
+			action = WizardInstall
+			if app.Status == string(api.AppStateInstalled) {
+				action = WizardUninstall
+			}
+		}
+		queue[i] = WizardEntry{Action: action, AppName: app.Name}
+	}
+	return queue
+}
@@ -0,0 +1,150 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: menu.go
+// Description: Single choice menu and text prompt for the terminal, used with the app picker.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package tui
+
+import (
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/pi-apps-go/pi-apps/pkg/api"
+)
+
+// Choice is an entry of a menu
+type Choice struct {
+	ID      string
+	Name    string
+	Details string
+}
+
+func (c Choice) FilterValue() string { return c.Name }
+func (c Choice) Title() string       { return c.Name }
+func (c Choice) Description() string { return c.Details }
+
+type menuModel struct {
+	list   list.Model
+	chosen *Choice
+}
+
+func (m *menuModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m *menuModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.list.SetSize(msg.Width, msg.Height)
+		return m, nil
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "enter":
+			if choice, ok := m.list.SelectedItem().(Choice); ok {
+				m.chosen = &choice
+			}
+			return m, tea.Quit
+		case "ctrl+c", "q", "esc":
+			return m, tea.Quit
+		}
+	}
+	var cmd tea.Cmd
+	m.list, cmd = m.list.Update(msg)
+	return m, cmd
+}
+
+func (m *menuModel) View() string {
+	if m.chosen != nil {
+		return ""
+	}
+	return m.list.View()
+}
+
+// RunMenu lets the user pick one of the choices, ok is false if the user cancelled
+func RunMenu(title string, choices []Choice) (choice Choice, ok bool, err error) {
+	items := make([]list.Item, len(choices))
+	for i, c := range choices {
+		items[i] = c
+	}
+	lst := list.New(items, list.NewDefaultDelegate(), 0, 0)
+	lst.Title = title
+	lst.SetFilteringEnabled(false)
+	lst.SetShowStatusBar(false)
+
+	m := &menuModel{list: lst}
+	if _, err := runProgram(m); err != nil {
+		return Choice{}, false, err
+	}
+	if m.chosen == nil {
+		return Choice{}, false, nil
+	}
+	return *m.chosen, true, nil
+}
+
+type promptModel struct {
+	title     string
+	input     textinput.Model
+	done      bool
+	cancelled bool
+}
+
+func (m *promptModel) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (m *promptModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.String() {
+		case "enter":
+			m.done = true
+			return m, tea.Quit
+		case "ctrl+c", "esc":
+			m.cancelled = true
+			return m, tea.Quit
+		}
+	}
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+func (m *promptModel) View() string {
+	if m.done || m.cancelled {
+		return ""
+	}
+	title := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("6")).Render(m.title)
+	help := lipgloss.NewStyle().Foreground(lipgloss.Color("241")).Render("enter " + api.T("done") + "  esc " + api.T("Cancel"))
+	return lipgloss.JoinVertical(lipgloss.Left, title, "", m.input.View(), "", help)
+}
+
+// RunPrompt asks the user for a line of text, ok is false if the user cancelled
+func RunPrompt(title, placeholder string) (text string, ok bool, err error) {
+	input := textinput.New()
+	input.Placeholder = placeholder
+	input.Focus()
+
+	m := &promptModel{title: title, input: input}
+	if _, err := runProgram(m); err != nil {
+		return "", false, err
+	}
+	if m.cancelled {
+		return "", false, nil
+	}
+	return m.input.Value(), true, nil
+}
@@ -0,0 +1,174 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: picker.go
+// Description: Searchable, paginated app picker for the terminal, shared by the terminal front ends of Pi-Apps.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package tui
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/pi-apps-go/pi-apps/pkg/api"
+)
+
+// AppItem is an app shown by the AppPicker
+type AppItem struct {
+	Name     string
+	Summary  string // first line of the app's description
+	Status   string // shown next to the name, e.g. installed or "update available"
+	Selected bool
+}
+
+func (a AppItem) FilterValue() string { return a.Name }
+func (a AppItem) Description() string { return a.Summary }
+func (a AppItem) Title() string {
+	box := "☐"
+	if a.Selected {
+		box = "☑"
+	}
+	if a.Status == "" {
+		return box + " " + a.Name
+	}
+	return fmt.Sprintf("%s %s (%s)", box, a.Name, api.T(a.Status))
+}
+
+var (
+	toggleKey  = key.NewBinding(key.WithKeys(" "), key.WithHelp("space", api.T("select")))
+	confirmKey = key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", api.T("done")))
+)
+
+// AppPicker is a Bubble Tea model that lets the user select any number of apps. Typing / searches the app names,
+// space selects the highlighted app and enter finishes, selecting the highlighted app if nothing else is selected.
+type AppPicker struct {
+	list      list.Model
+	done      bool
+	cancelled bool
+}
+
+// NewAppPicker returns a picker for the apps, items that are already Selected start out selected
+func NewAppPicker(title string, apps []AppItem) *AppPicker {
+	items := make([]list.Item, len(apps))
+	for i, app := range apps {
+		items[i] = app
+	}
+
+	lst := list.New(items, list.NewDefaultDelegate(), 0, 0)
+	lst.Title = title
+	lst.SetStatusBarItemName(api.T("app"), api.T("apps"))
+	lst.AdditionalShortHelpKeys = func() []key.Binding { return []key.Binding{toggleKey, confirmKey} }
+	lst.AdditionalFullHelpKeys = lst.AdditionalShortHelpKeys
+	return &AppPicker{list: lst}
+}
+
+// Selected returns the selected apps in the order they are listed
+func (p *AppPicker) Selected() []AppItem {
+	var selected []AppItem
+	for _, item := range p.list.Items() {
+		if app := item.(AppItem); app.Selected {
+			selected = append(selected, app)
+		}
+	}
+	return selected
+}
+
+// Cancelled reports whether the user left the picker without finishing
+func (p *AppPicker) Cancelled() bool {
+	return p.cancelled
+}
+
+// toggle selects the highlighted app, or unselects it if it is selected
+func (p *AppPicker) toggle() {
+	app, ok := p.list.SelectedItem().(AppItem)
+	if !ok {
+		return
+	}
+	app.Selected = !app.Selected
+	p.list.SetItem(p.list.GlobalIndex(), app)
+}
+
+func (p *AppPicker) Init() tea.Cmd {
+	return nil
+}
+
+func (p *AppPicker) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		p.list.SetSize(msg.Width, msg.Height-1)
+		return p, nil
+
+	case tea.KeyMsg:
+		if msg.String() == "ctrl+c" {
+			p.cancelled = true
+			return p, tea.Quit
+		}
+		// While the search is typed every other key belongs to it
+		if p.list.FilterState() == list.Filtering {
+			break
+		}
+		switch {
+		case key.Matches(msg, toggleKey):
+			p.toggle()
+			return p, nil
+		case key.Matches(msg, confirmKey):
+			if len(p.Selected()) == 0 {
+				p.toggle()
+			}
+			p.done = true
+			return p, tea.Quit
+		case msg.String() == "esc" && p.list.FilterState() == list.FilterApplied:
+			// The list clears the search, esc only cancels without one
+		case msg.String() == "q" || msg.String() == "esc":
+			p.cancelled = true
+			return p, tea.Quit
+		}
+	}
+
+	var cmd tea.Cmd
+	p.list, cmd = p.list.Update(msg)
+	return p, cmd
+}
+
+func (p *AppPicker) View() string {
+	if p.done || p.cancelled {
+		return ""
+	}
+	count := lipgloss.NewStyle().Foreground(lipgloss.Color("241")).Render(api.Tf("%d selected", len(p.Selected())))
+	return lipgloss.JoinVertical(lipgloss.Left, p.list.View(), count)
+}
+
+// RunAppPicker lets the user pick apps in the terminal, it returns nil if the user cancelled
+func RunAppPicker(title string, apps []AppItem) ([]AppItem, error) {
+	picker := NewAppPicker(title, apps)
+	if _, err := runProgram(picker); err != nil {
+		return nil, err
+	}
+	if picker.Cancelled() {
+		return nil, nil
+	}
+	return picker.Selected(), nil
+}
+
+// runProgram runs a model full screen, on stderr like the settings TUI so stdout stays free for the output of the command
+func runProgram(m tea.Model) (tea.Model, error) {
+	return tea.NewProgram(m, tea.WithAltScreen(), tea.WithOutput(os.Stderr)).Run()
+}
@@ -0,0 +1,139 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package tui
+
+import (
+	"slices"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/pi-apps-go/pi-apps/pkg/api"
+)
+
+func sendKeys(p *AppPicker, keys ...tea.KeyMsg) tea.Cmd {
+	var cmd tea.Cmd
+	for _, k := range keys {
+		_, cmd = p.Update(k)
+	}
+	return cmd
+}
+
+func pickerNames(items []AppItem) []string {
+	var names []string
+	for _, item := range items {
+		names = append(names, item.Name)
+	}
+	return names
+}
+
+var (
+	spaceKey = tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}}
+	downKey  = tea.KeyMsg{Type: tea.KeyDown}
+	enterKey = tea.KeyMsg{Type: tea.KeyEnter}
+)
+
+func newTestPicker(apps ...AppItem) *AppPicker {
+	p := NewAppPicker("Install", apps)
+	p.Update(tea.WindowSizeMsg{Width: 80, Height: 24})
+	return p
+}
+
+func TestAppPickerSelection(t *testing.T) {
+	p := newTestPicker(AppItem{Name: "Firefox"}, AppItem{Name: "Zoom"}, AppItem{Name: "LibreOffice", Selected: true})
+
+	sendKeys(p, spaceKey, downKey, downKey, spaceKey)
+	if got := pickerNames(p.Selected()); !slices.Equal(got, []string{"Firefox"}) {
+		t.Fatalf("selected = %v, want [Firefox]", got)
+	}
+
+	sendKeys(p, tea.KeyMsg{Type: tea.KeyUp}, spaceKey)
+	if cmd := sendKeys(p, enterKey); cmd == nil {
+		t.Fatal("enter did not quit the picker")
+	}
+	if got := pickerNames(p.Selected()); !slices.Equal(got, []string{"Firefox", "Zoom"}) {
+		t.Errorf("selected = %v, want [Firefox Zoom]", got)
+	}
+	if p.Cancelled() {
+		t.Error("picker is cancelled after enter")
+	}
+}
+
+func TestAppPickerEnterSelectsHighlighted(t *testing.T) {
+	p := newTestPicker(AppItem{Name: "Firefox"}, AppItem{Name: "Zoom"})
+
+	sendKeys(p, downKey, enterKey)
+	if got := pickerNames(p.Selected()); !slices.Equal(got, []string{"Zoom"}) {
+		t.Errorf("selected = %v, want [Zoom]", got)
+	}
+}
+
+func TestAppPickerCancel(t *testing.T) {
+	p := newTestPicker(AppItem{Name: "Firefox"})
+
+	sendKeys(p, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'q'}})
+	if !p.Cancelled() {
+		t.Error("q did not cancel the picker")
+	}
+}
+
+func TestAppPickerSearchTakesKeys(t *testing.T) {
+	p := newTestPicker(AppItem{Name: "Firefox"}, AppItem{Name: "Zoom"})
+
+	// Space and q are part of the search while it is typed
+	sendKeys(p, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'/'}}, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'q'}}, spaceKey)
+	if p.Cancelled() || len(p.Selected()) != 0 {
+		t.Errorf("keys typed into the search changed the picker: cancelled %v, selected %v", p.Cancelled(), p.Selected())
+	}
+}
+
+func TestWizardApps(t *testing.T) {
+	entries := []api.AppListEntry{
+		{Name: "Broken", Status: "corrupted"},
+		{Name: "Firefox", Status: "installed"},
+		{Name: "Hidden", Status: "uninstalled", Category: "hidden"},
+		{Name: "Off", Status: "disabled"},
+		{Name: "Zoom", Status: "uninstalled"},
+	}
+	pending := map[string]bool{"Firefox": true}
+
+	tests := []struct {
+		operation string
+		matches   []string
+		want      []AppItem
+	}{
+		{WizardInstall, nil, []AppItem{{Name: "Broken", Status: "corrupted"}, {Name: "Zoom"}}},
+		{WizardUninstall, nil, []AppItem{{Name: "Broken", Status: "corrupted"}, {Name: "Firefox", Status: "installed"}}},
+		{WizardUpdate, nil, []AppItem{{Name: "Firefox", Status: "update available", Selected: true}}},
+		{WizardSearch, []string{"Firefox", "Off", "Zoom"}, []AppItem{{Name: "Firefox", Status: "installed"}, {Name: "Zoom", Status: "uninstalled"}}},
+	}
+	for _, tt := range tests {
+		if got := wizardApps(tt.operation, entries, tt.matches, pending); !slices.Equal(got, tt.want) {
+			t.Errorf("wizardApps(%s) = %v, want %v", tt.operation, got, tt.want)
+		}
+	}
+}
+
+func TestWizardQueue(t *testing.T) {
+	picked := []AppItem{{Name: "Firefox", Status: "installed"}, {Name: "Zoom", Status: "uninstalled"}}
+
+	if got, want := wizardQueue(WizardSearch, picked), []WizardEntry{{"uninstall", "Firefox"}, {"install", "Zoom"}}; !slices.Equal(got, want) {
+		t.Errorf("search queue = %v, want %v", got, want)
+	}
+	if got, want := wizardQueue(WizardUpdate, picked), []WizardEntry{{"update", "Firefox"}, {"update", "Zoom"}}; !slices.Equal(got, want) {
+		t.Errorf("update queue = %v, want %v", got, want)
+	}
+}
@@ -23,6 +23,8 @@ package updater
 import (
 	"bufio"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
@@ -360,22 +362,17 @@ func (c *UpdaterCLI) performUpdate(files []FileChange, apps []string) error {
 	result := c.updater.PerformUpdate(files, apps)
 
 	if result.Success {
-		fmt.Printf("\n✅ %s", result.Message)
-		if result.Recompiled {
-			fmt.Print(" (Recompilation completed)")
-		}
-		fmt.Println()
-
-		// Update status files
-		if err := c.updateStatusFiles(); err != nil {
-			fmt.Printf("⚠️  Warning: Failed to update status files: %v\n", err)
-		}
-
+		c.reportSuccess(result)
 		return nil
 	}
 
 	// Handle failure
-	fmt.Printf("\n❌ Update failed: %s\n", result.Message)
+	if len(result.RolledBackApps) > 0 {
+		fmt.Printf("\n↩️  Update failed, rolled back to previous version: %s\n", strings.Join(result.RolledBackApps, ", "))
+		fmt.Printf("   %s\n", result.Message)
+	} else {
+		fmt.Printf("\n❌ Update failed: %s\n", result.Message)
+	}
 
 	if result.RollbackData != nil {
 		fmt.Print("\nWould you like to rollback the changes? (y/N): ")
@@ -405,9 +402,114 @@ func (c *UpdaterCLI) performUpdate(files []FileChange, apps []string) error {
 	return fmt.Errorf("update failed: %s", result.Message)
 }
 
+// reportSuccess shows the result of a successful update and updates the status files
+func (c *UpdaterCLI) reportSuccess(result *UpdateResult) {
+	fmt.Printf("\n✅ %s", result.Message)
+	if result.Recompiled {
+		fmt.Print(" (Recompilation completed)")
+	}
+	fmt.Println()
+
+	// Update status files
+	if err := c.updateStatusFiles(); err != nil {
+		fmt.Printf("⚠️  Warning: Failed to update status files: %v\n", err)
+	}
+
+	if len(result.StalePrograms) > 0 {
+		fmt.Println("\n🔄 These Pi-Apps programs still run the previous version until they are restarted:")
+		for _, program := range result.StalePrograms {
+			fmt.Printf("   %s (PID %d)\n", program.Name, program.PID)
+		}
+	}
+}
+
+// RunPlan prints what an update would change without changing anything, as JSON if jsonOutput is set
+func (c *UpdaterCLI) RunPlan(jsonOutput bool) error {
+	plan, err := c.updater.Plan(context.Background())
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(plan)
+	}
+
+	if len(plan.Files) == 0 && len(plan.Apps) == 0 {
+		fmt.Println("\n✓ Nothing to update.")
+	}
+	if len(plan.Files) > 0 {
+		fmt.Println("\n📄 Files to update:")
+		for _, file := range plan.Files {
+			note := ""
+			if file.IsModuleFile {
+				note = " (module update and recompilation required)"
+			} else if file.RequiresRecompile {
+				note = " (requires recompile)"
+			}
+			fmt.Printf("  • %s%s\n", file.Path, note)
+		}
+	}
+	if len(plan.Excluded) > 0 {
+		fmt.Println("\n🚫 Files left alone because of data/update-exclusion:")
+		for _, file := range plan.Excluded {
+			fmt.Printf("  • %s\n", file.Path)
+		}
+	}
+	if len(plan.Apps) > 0 {
+		fmt.Println("\n📱 Apps to update:")
+		for _, app := range plan.Apps {
+			note := ""
+			switch {
+			case app.Held:
+				note = " (packages are held, not reinstalled)"
+			case app.Action == PlanActionReinstall:
+				note = fmt.Sprintf(" (will reinstall because %s)", app.Reason)
+			case app.Action == PlanActionNew:
+				note = " (new app)"
+			}
+			fmt.Printf("  • %s%s\n", app.Name, note)
+		}
+	}
+	if plan.DownloadBytes > 0 {
+		fmt.Printf("\nThe reinstalls are expected to download about %s.\n", api.FormatSize(uint64(plan.DownloadBytes)))
+	}
+	if plan.Commit != "" {
+		fmt.Printf("\nPlanned from commit %s\n", plan.Commit)
+	}
+	return nil
+}
+
+// RunFromPlan applies a plan written by `updater plan --json` without asking anything
+func (c *UpdaterCLI) RunFromPlan(path string, allowDrift bool) error {
+	plan, err := ReadPlan(path)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("🚀 Applying update plan...")
+	result, err := c.updater.Apply(plan, allowDrift)
+	if err != nil {
+		var drift *PlanDriftError
+		if errors.As(err, &drift) {
+			return fmt.Errorf("%w, make a new plan or pass --allow-drift to apply it anyway", err)
+		}
+		return err
+	}
+	if !result.Success {
+		if len(result.RolledBackApps) > 0 {
+			fmt.Printf("\n↩️  Update failed, rolled back to previous version: %s\n", strings.Join(result.RolledBackApps, ", "))
+		}
+		return fmt.Errorf("update failed: %s", result.Message)
+	}
+	c.reportSuccess(result)
+	return nil
+}
+
 // updateStatusFiles updates the status tracking files
 func (c *UpdaterCLI) updateStatusFiles() error {
-	statusDir := c.updater.directory + "/data/update-status"
+	statusDir := filepath.Join(api.DataDirOf(c.updater.directory), "update-status")
 	if err := os.MkdirAll(statusDir, 0755); err != nil {
 		return err
 	}
@@ -448,7 +550,7 @@ func (c *UpdaterCLI) updateStatusFiles() error {
 
 // GetUpdateStatus checks if there are any updates available
 func (c *UpdaterCLI) GetUpdateStatus() error {
-	statusDir := c.updater.directory + "/data/update-status"
+	statusDir := filepath.Join(api.DataDirOf(c.updater.directory), "update-status")
 
 	filesStatus := statusDir + "/updatable-files"
 	appsStatus := statusDir + "/updatable-apps"
@@ -494,7 +596,7 @@ func (c *UpdaterCLI) SetUpdateStatus() error {
 	}
 
 	// Write status files
-	statusDir := c.updater.directory + "/data/update-status"
+	statusDir := filepath.Join(api.DataDirOf(c.updater.directory), "update-status")
 	if err := os.MkdirAll(statusDir, 0755); err != nil {
 		return err
 	}
@@ -18,12 +18,15 @@
 // Description: GUI interface for the updater.
 // SPDX-License-Identifier: GPL-3.0-or-later
 
+//go:build !nogui
+
 package updater
 
 import (
 	"context"
 	"fmt"
 	"log"
+	"slices"
 	"strings"
 	"time"
 
@@ -157,7 +160,7 @@ func (g *UpdaterGUI) createHeader(parent *gtk.Box) error {
 	if err != nil {
 		return err
 	}
-	titleLabel.SetMarkup("<span size='large' weight='bold'>Pi-Apps Updates</span>")
+	titleLabel.SetMarkup("<span size='large' weight='bold'>" + glib.MarkupEscapeText(api.T("Pi-Apps Updates")) + "</span>")
 	titleLabel.SetHAlign(gtk.ALIGN_START)
 
 	// Status label
@@ -361,7 +364,7 @@ func (g *UpdaterGUI) refreshUpdatesList() {
 	go func() {
 		// Update status
 		glib.IdleAdd(func() {
-			g.statusLabel.SetText("Checking for updates...")
+			g.statusLabel.SetText(api.T("Checking for updates..."))
 			g.progressBar.SetVisible(true)
 			g.progressBar.SetPulseStep(0.1)
 			g.progressBar.Pulse()
@@ -384,7 +387,7 @@ func (g *UpdaterGUI) refreshUpdatesList() {
 		// Check repository
 		if err := g.updater.CheckRepo(ctx); err != nil {
 			glib.IdleAdd(func() {
-				g.statusLabel.SetMarkup(fmt.Sprintf("<span color='red'>Failed to check repository: %v</span>", err))
+				g.statusLabel.SetMarkup("<span color='red'>" + glib.MarkupEscapeText(api.Tf("Failed to check repository: %v", err)) + "</span>")
 				g.progressBar.SetVisible(false)
 			})
 			return
@@ -394,7 +397,7 @@ func (g *UpdaterGUI) refreshUpdatesList() {
 		files, err := g.updater.GetUpdatableFiles()
 		if err != nil {
 			glib.IdleAdd(func() {
-				g.statusLabel.SetMarkup(fmt.Sprintf("<span color='red'>Failed to get updatable files: %v</span>", err))
+				g.statusLabel.SetMarkup("<span color='red'>" + glib.MarkupEscapeText(api.Tf("Failed to get updatable files: %v", err)) + "</span>")
 				g.progressBar.SetVisible(false)
 			})
 			return
@@ -404,7 +407,7 @@ func (g *UpdaterGUI) refreshUpdatesList() {
 		apps, err := g.updater.GetUpdatableApps()
 		if err != nil {
 			glib.IdleAdd(func() {
-				g.statusLabel.SetMarkup(fmt.Sprintf("<span color='red'>Failed to get updatable apps: %v</span>", err))
+				g.statusLabel.SetMarkup("<span color='red'>" + glib.MarkupEscapeText(api.Tf("Failed to get updatable apps: %v", err)) + "</span>")
 				g.progressBar.SetVisible(false)
 			})
 			return
@@ -416,10 +419,10 @@ func (g *UpdaterGUI) refreshUpdatesList() {
 			g.progressBar.SetVisible(false)
 
 			if len(files) == 0 && len(apps) == 0 {
-				g.statusLabel.SetMarkup("<span color='green'>Everything is up to date!</span>")
+				g.statusLabel.SetMarkup("<span color='green'>" + glib.MarkupEscapeText(api.T("Everything is up to date!")) + "</span>")
 				g.updateButton.SetSensitive(false)
 			} else {
-				g.statusLabel.SetText(fmt.Sprintf("Found %d file updates and %d app updates", len(files), len(apps)))
+				g.statusLabel.SetText(api.Tf("Found %d file updates and %d app updates", len(files), len(apps)))
 				g.updateButton.SetSensitive(true)
 			}
 		})
@@ -550,21 +553,21 @@ func (g *UpdaterGUI) onRollbackClicked() {
 	if g.lastResult != nil && g.lastResult.RollbackData != nil {
 		go func() {
 			glib.IdleAdd(func() {
-				g.statusLabel.SetText("Rolling back changes...")
+				g.statusLabel.SetText(api.T("Rolling back changes..."))
 				g.progressBar.SetVisible(true)
 				g.progressBar.Pulse()
 			})
 
 			if err := g.updater.rollback(g.lastResult.RollbackData); err != nil {
 				glib.IdleAdd(func() {
-					g.statusLabel.SetMarkup(fmt.Sprintf("<span color='red'>Rollback failed: %v</span>", err))
+					g.statusLabel.SetMarkup("<span color='red'>" + glib.MarkupEscapeText(api.Tf("Rollback failed: %v", err)) + "</span>")
 					g.progressBar.SetVisible(false)
 				})
 				return
 			}
 
 			glib.IdleAdd(func() {
-				g.statusLabel.SetMarkup("<span color='green'>Rollback completed successfully</span>")
+				g.statusLabel.SetMarkup("<span color='green'>" + glib.MarkupEscapeText(api.T("Rollback completed successfully")) + "</span>")
 				g.progressBar.SetVisible(false)
 				g.rollbackButton.SetVisible(false)
 				g.refreshUpdatesList()
@@ -709,7 +712,7 @@ func (g *UpdaterGUI) showMessage(message string) {
 func (g *UpdaterGUI) startUpdate() {
 	go func() {
 		glib.IdleAdd(func() {
-			g.statusLabel.SetText("Updating...")
+			g.statusLabel.SetText(api.T("Updating..."))
 			g.progressBar.SetVisible(true)
 			g.progressBar.SetPulseStep(0.1)
 			g.progressBar.Pulse()
@@ -738,9 +741,11 @@ func (g *UpdaterGUI) startUpdate() {
 			if result.Success {
 				message := result.Message
 				if result.Recompiled {
-					message += " (Recompilation completed)"
+					message = api.Tf("%s (Recompilation completed)", message)
 				}
-				g.statusLabel.SetMarkup(fmt.Sprintf("<span color='green'>%s</span>", message))
+				g.statusLabel.SetMarkup("<span color='green'>" + glib.MarkupEscapeText(message) + "</span>")
+
+				g.offerRestart(result.StalePrograms)
 
 				// Don't refresh immediately after an update to avoid re-detecting module files
 				// Only refresh after a delay to allow file system to settle
@@ -748,7 +753,11 @@ func (g *UpdaterGUI) startUpdate() {
 					g.refreshUpdatesList()
 				})
 			} else {
-				g.statusLabel.SetMarkup(fmt.Sprintf("<span color='red'>Update failed: %s</span>", result.Message))
+				if len(result.RolledBackApps) > 0 {
+					g.statusLabel.SetMarkup("<span color='orange'>" + glib.MarkupEscapeText(api.Tf("Update failed, rolled back to previous version: %s", strings.Join(result.RolledBackApps, ", "))) + "</span>")
+				} else {
+					g.statusLabel.SetMarkup("<span color='red'>" + glib.MarkupEscapeText(api.Tf("Update failed: %s", result.Message)) + "</span>")
+				}
 				g.retryButton.SetVisible(true)
 				if result.RollbackData != nil {
 					g.rollbackButton.SetVisible(true)
@@ -758,6 +767,35 @@ func (g *UpdaterGUI) startUpdate() {
 	}()
 }
 
+// offerRestart asks to restart the Pi-Apps windows still running the version the update replaced
+func (g *UpdaterGUI) offerRestart(programs []StaleProgram) {
+	var names []string
+	for _, program := range programs {
+		if program.Restartable() && !slices.Contains(names, program.Name) {
+			names = append(names, program.Name)
+		}
+	}
+	if len(names) == 0 {
+		return
+	}
+
+	dialog := gtk.MessageDialogNew(g.window, gtk.DIALOG_MODAL, gtk.MESSAGE_QUESTION, gtk.BUTTONS_YES_NO, "%s", api.T("Pi-Apps was updated, restart now?"))
+	dialog.FormatSecondaryText("%s", api.Tf("These windows still run the previous version: %s", strings.Join(names, ", ")))
+	response := dialog.Run()
+	dialog.Destroy()
+	if response != gtk.RESPONSE_YES {
+		return
+	}
+
+	go func() {
+		if err := g.updater.RestartPrograms(programs); err != nil {
+			glib.IdleAdd(func() {
+				g.showMessage(api.Tf("Error: %v", err))
+			})
+		}
+	}()
+}
+
 func (g *UpdaterGUI) getFileIcon(fileType string) string {
 	iconDir := g.updater.directory + "/icons/updater"
 
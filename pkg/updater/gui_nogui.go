@@ -0,0 +1,40 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: gui_nogui.go
+// Description: Replaces the updater GUI when built with the nogui tag.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+//go:build nogui
+
+package updater
+
+import (
+	"fmt"
+
+	"github.com/pi-apps-go/pi-apps/pkg/api"
+)
+
+// UpdaterGUI is not available without GUI support
+type UpdaterGUI struct{}
+
+// NewUpdaterGUI always fails without GUI support, use the cli mode of the updater instead
+func NewUpdaterGUI(updater *Updater) (*UpdaterGUI, error) {
+	return nil, fmt.Errorf("%w: run the updater in cli mode instead", api.ErrNoGUI)
+}
+
+// Run does nothing without GUI support
+func (g *UpdaterGUI) Run() {}
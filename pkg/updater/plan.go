@@ -0,0 +1,225 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: plan.go
+// Description: Splits an update into a plan of what would change and applying exactly that plan, for CI image builds.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package updater
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pi-apps-go/pi-apps/pkg/api"
+)
+
+// PlanVersion is the version of the plan format, raised when its fields change meaning
+const PlanVersion = 1
+
+// Actions of the apps in a plan
+const (
+	PlanActionNew       = "new"       // the app is new in the catalog, its folder is added
+	PlanActionRefresh   = "refresh"   // the app folder is replaced, nothing is run
+	PlanActionReinstall = "reinstall" // the installed app is reinstalled with its new scripts
+)
+
+// downloadSessionGap is the longest pause between two downloads of one install in the download ledger
+const downloadSessionGap = 30 * time.Minute
+
+// Plan is what an update would change, computed without changing anything. Apply carries out exactly this plan.
+type Plan struct {
+	Version       int        `json:"version"`
+	Created       time.Time  `json:"created"`
+	Commit        string     `json:"commit"`         // HEAD of the update clone the plan was computed from, "" if unknown
+	Fast          bool       `json:"fast"`           // computed from the cached update status instead of checking the repository
+	Files         []PlanFile `json:"files"`          // files that are updated
+	Excluded      []PlanFile `json:"excluded"`       // changed files left alone because they are listed in data/update-exclusion
+	Apps          []PlanApp  `json:"apps"`           // apps that are updated
+	DownloadBytes int64      `json:"download_bytes"` // estimated downloads of the reinstalls, see PlanApp.DownloadBytes
+}
+
+// PlanFile is a file of the Pi-Apps folder in a plan
+type PlanFile struct {
+	Path              string `json:"path"`
+	Type              string `json:"type"`
+	RequiresRecompile bool   `json:"requires_recompile"`
+	IsModuleFile      bool   `json:"module_file"`
+	Bytes             int64  `json:"bytes"` // size of the new version in the update clone
+}
+
+// PlanApp is an app in a plan
+type PlanApp struct {
+	Name   string `json:"name"`
+	Action string `json:"action"`           // one of the PlanAction constants
+	Reason string `json:"reason,omitempty"` // why a reinstall is needed
+	Held   bool   `json:"held,omitempty"`   // package-app with held packages, so the reinstall skips it
+	// DownloadBytes estimates the downloads of a reinstall with what the last install of the app downloaded,
+	// according to the download ledger. It is 0 for apps that aren't reinstalled or never downloaded anything.
+	DownloadBytes int64 `json:"download_bytes"`
+}
+
+// PlanDriftError is returned by Apply when the update clone is no longer at the commit a plan was computed from
+type PlanDriftError struct {
+	Planned string
+	Current string
+}
+
+func (e *PlanDriftError) Error() string {
+	return fmt.Sprintf("the repository moved from %s to %s since the plan was made", e.Planned, e.Current)
+}
+
+// Plan checks the repository, respecting fast mode, and returns what an update would change without changing it
+func (u *Updater) Plan(ctx context.Context) (*Plan, error) {
+	if err := u.CheckRepo(ctx); err != nil {
+		return nil, fmt.Errorf("failed to check repository: %w", err)
+	}
+
+	files, excluded, err := u.updatableFiles()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get updatable files: %w", err)
+	}
+	apps, err := u.GetUpdatableApps()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get updatable apps: %w", err)
+	}
+
+	plan := &Plan{
+		Version:  PlanVersion,
+		Created:  time.Now().UTC().Truncate(time.Second),
+		Commit:   u.cloneCommit(),
+		Fast:     u.speed == SpeedFast,
+		Files:    u.planFiles(files),
+		Excluded: u.planFiles(excluded),
+		Apps:     []PlanApp{},
+	}
+
+	downloads := lastInstallDownloads()
+	for _, app := range apps {
+		planApp := PlanApp{Name: app, Action: PlanActionRefresh}
+		if !dirExists(filepath.Join(u.directory, "apps", app)) {
+			planApp.Action = PlanActionNew
+		} else if reason, err := api.ReinstallReason(app); err != nil {
+			return nil, fmt.Errorf("failed to check if %s will be reinstalled: %w", app, err)
+		} else if reason != "" {
+			planApp.Action = PlanActionReinstall
+			planApp.Reason = reason
+			planApp.Held = api.AppHeld(app)
+			planApp.DownloadBytes = downloads[app]
+			plan.DownloadBytes += planApp.DownloadBytes
+		}
+		plan.Apps = append(plan.Apps, planApp)
+	}
+	return plan, nil
+}
+
+// Apply carries out a plan. It refuses with a *PlanDriftError if the update clone moved since the plan was made,
+// unless allowDrift is set, and then updates the planned files and apps with what the clone has now.
+func (u *Updater) Apply(plan *Plan, allowDrift bool) (*UpdateResult, error) {
+	if plan.Version != PlanVersion {
+		return nil, fmt.Errorf("the plan has version %d, this updater only applies version %d", plan.Version, PlanVersion)
+	}
+	if current := u.cloneCommit(); current != plan.Commit && !allowDrift {
+		return nil, &PlanDriftError{Planned: plan.Commit, Current: current}
+	}
+
+	files := make([]FileChange, 0, len(plan.Files))
+	for _, file := range plan.Files {
+		files = append(files, FileChange{
+			Path:              file.Path,
+			Type:              file.Type,
+			RequiresRecompile: file.RequiresRecompile,
+			IsModuleFile:      file.IsModuleFile,
+		})
+	}
+	apps := make([]string, 0, len(plan.Apps))
+	for _, app := range plan.Apps {
+		apps = append(apps, app.Name)
+	}
+	if len(files) == 0 && len(apps) == 0 {
+		return &UpdateResult{Success: true, Message: "Nothing to update"}, nil
+	}
+	return u.PerformUpdate(files, apps), nil
+}
+
+// ReadPlan reads a plan written by `updater plan --json`
+func ReadPlan(path string) (*Plan, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plan: %w", err)
+	}
+	var plan Plan
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return nil, fmt.Errorf("failed to parse plan %s: %w", path, err)
+	}
+	return &plan, nil
+}
+
+// planFiles returns the files of a plan with their size in the update clone
+func (u *Updater) planFiles(files []FileChange) []PlanFile {
+	planned := make([]PlanFile, 0, len(files))
+	for _, file := range files {
+		planFile := PlanFile{
+			Path:              file.Path,
+			Type:              file.Type,
+			RequiresRecompile: file.RequiresRecompile,
+			IsModuleFile:      file.IsModuleFile,
+		}
+		if info, err := os.Stat(filepath.Join(u.directory, "update", "pi-apps", file.Path)); err == nil {
+			planFile.Bytes = info.Size()
+		}
+		planned = append(planned, planFile)
+	}
+	return planned
+}
+
+// cloneCommit returns the commit the update clone is at, or "" if there is no clone
+func (u *Updater) cloneCommit() string {
+	output, err := exec.Command("git", "-C", filepath.Join(u.directory, "update", "pi-apps"), "rev-parse", "HEAD").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// lastInstallDownloads returns how many bytes the last install of each app downloaded according to the download
+// ledger: the newest downloads of the app that are less than downloadSessionGap apart
+func lastInstallDownloads() map[string]int64 {
+	downloads := make(map[string]int64)
+	entries, err := api.DownloadLedger(api.DownloadLedgerFilter{})
+	if err != nil {
+		api.Debug(fmt.Sprintf("Failed to read the download ledger: %v", err))
+		return downloads
+	}
+	last := make(map[string]time.Time)
+	for _, entry := range entries {
+		if entry.App == "" {
+			continue
+		}
+		if entry.Time.Sub(last[entry.App]) > downloadSessionGap {
+			downloads[entry.App] = 0
+		}
+		downloads[entry.App] += entry.Bytes
+		last[entry.App] = entry.Time
+	}
+	return downloads
+}
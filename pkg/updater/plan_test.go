@@ -0,0 +1,169 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package updater
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pi-apps-go/pi-apps/pkg/api"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// newPlanTestDir returns a Pi-Apps folder with cached update results for fast mode: a changed file, a new app,
+// an app that is only refreshed and an installed app whose install script changed
+func newPlanTestDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("PI_APPS_DIR", dir)
+	// The update clone is a Pi-Apps folder too
+	for _, root := range []string{dir, filepath.Join(dir, "update", "pi-apps")} {
+		writeFile(t, filepath.Join(root, "api"), "#!/bin/bash\n", 0755)
+		writeFile(t, filepath.Join(root, "gui"), "#!/bin/bash\n", 0755)
+		for _, subdir := range []string{"apps", "data", "etc"} {
+			if err := os.MkdirAll(filepath.Join(root, subdir), 0755); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	writeFile(t, filepath.Join(dir, "data", "update-status", "updatable-files"), "README.md\npkg/api/api.go\n", 0644)
+	writeFile(t, filepath.Join(dir, "data", "update-status", "updatable-apps"), "New App\nDocs\nZoom\n", 0644)
+	writeFile(t, filepath.Join(dir, "update", "pi-apps", "README.md"), "# Pi-Apps Go\n", 0644)
+	writeFile(t, filepath.Join(dir, "update", "pi-apps", "pkg", "api", "api.go"), "package api\n", 0644)
+
+	writeFile(t, filepath.Join(dir, "apps", "Docs", "description"), "Docs\n", 0644)
+	writeFile(t, filepath.Join(dir, "apps", "Zoom", "install"), "#!/bin/bash\necho 1\n", 0755)
+	writeFile(t, filepath.Join(dir, "update", "pi-apps", "apps", "Zoom", "install"), "#!/bin/bash\necho 2\n", 0755)
+	writeFile(t, filepath.Join(dir, "data", "status", "Zoom"), "installed\n", 0644)
+
+	// The last install of Zoom downloaded 300 bytes, an older one 5000
+	now := time.Now().UTC()
+	var ledger []byte
+	for _, entry := range []struct {
+		age   time.Duration
+		bytes int64
+	}{{48 * time.Hour, 5000}, {time.Hour, 100}, {time.Hour - time.Minute, 200}} {
+		line, err := json.Marshal(map[string]any{"time": now.Add(-entry.age), "app": "Zoom", "url": "https://example.com", "destination": "/tmp/x", "bytes": entry.bytes})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ledger = append(append(ledger, line...), '\n')
+	}
+	writeFile(t, filepath.Join(dir, "data", "download-ledger.jsonl"), string(ledger), 0644)
+	return dir
+}
+
+func TestPlanGolden(t *testing.T) {
+	dir := newPlanTestDir(t)
+	u, err := New(dir, ModePlan, SpeedFast)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plan, err := u.Plan(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	plan.Created = time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	got, err := json.MarshalIndent(plan, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got = append(got, '\n')
+
+	golden := filepath.Join("testdata", "plan.golden")
+	if *updateGolden {
+		if err := os.WriteFile(golden, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("plan JSON changed, run go test -update if that is intended\ngot:\n%s\nwant:\n%s", got, want)
+	}
+
+	// The schema has to read back into the same plan
+	var read Plan
+	if err := json.Unmarshal(want, &read); err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := json.MarshalIndent(read, "", "  "); string(again)+"\n" != string(want) {
+		t.Errorf("plan does not survive a round trip:\n%s", again)
+	}
+}
+
+func TestApplyRefusesDrift(t *testing.T) {
+	dir := newPlanTestDir(t)
+	u, err := New(dir, ModeCLIYes, SpeedFast)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = u.Apply(&Plan{Version: PlanVersion, Commit: "0123456789abcdef"}, false)
+	var drift *PlanDriftError
+	if !errors.As(err, &drift) || drift.Planned != "0123456789abcdef" || drift.Current != "" {
+		t.Errorf("Apply of a plan from another commit = %v, want a PlanDriftError", err)
+	}
+
+	result, err := u.Apply(&Plan{Version: PlanVersion, Commit: "0123456789abcdef"}, true)
+	if err != nil || !result.Success {
+		t.Errorf("Apply of an empty plan with allowDrift = %+v, %v, want nothing to do", result, err)
+	}
+
+	if _, err := u.Apply(&Plan{Version: PlanVersion + 1}, true); err == nil {
+		t.Errorf("Apply accepted a plan of a newer version")
+	}
+}
+
+func TestReadOnlyPiAppsDirIsNotUpdated(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("root can write to a read-only Pi-Apps directory")
+	}
+	dir := newPlanTestDir(t)
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	for _, path := range []string{filepath.Join(dir, "data"), dir} {
+		if err := os.Chmod(path, 0555); err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { os.Chmod(path, 0755) })
+	}
+
+	u, err := New(dir, ModeCLIYes, SpeedNormal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := u.CheckRepo(context.Background()); !errors.Is(err, api.ErrReadOnlyPiAppsDir) {
+		t.Errorf("CheckRepo of a read-only Pi-Apps directory = %v, want ErrReadOnlyPiAppsDir", err)
+	}
+	result := u.PerformUpdate([]FileChange{{Path: "README.md"}}, []string{"Zoom"})
+	if result.Success || !strings.Contains(result.Message, "administrator") {
+		t.Errorf("PerformUpdate of a read-only Pi-Apps directory = %+v, want it refused", result)
+	}
+	if got := readFile(t, filepath.Join(dir, "apps", "Zoom", "install")); got != "#!/bin/bash\necho 1\n" {
+		t.Errorf("the install script of Zoom was updated to %q", got)
+	}
+}
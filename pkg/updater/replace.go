@@ -0,0 +1,196 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: replace.go
+// Description: Replaces files atomically so running programs and scripts never see a half written file,
+// and finds the Pi-Apps programs that still run a replaced version.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package updater
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/pi-apps-go/pi-apps/pkg/api/statelock"
+)
+
+// restartablePrograms are the programs RestartPrograms restarts. The manage daemon and api-go are left alone,
+// they may be in the middle of installing an app and run the new version the next time they start.
+var restartablePrograms = []string{"gui", "settings"}
+
+// StaleProgram is a running Pi-Apps program whose executable was replaced by an update
+type StaleProgram struct {
+	PID  int
+	Name string   // name it was started as, like gui for a multi-call binary started through the gui symlink
+	Args []string // command line, the executable first
+}
+
+// replaceFile replaces dst with a copy of src through a temporary file in the same directory, which is renamed
+// over dst. The rename is atomic, so a running executable or script keeps its old version and the next start
+// gets the new one, where writing into the file fails with "text file busy" or leaves it half written.
+// The copy keeps the executable bits of both files. A symlink in src is copied as a symlink, and a symlink
+// in dst, like a multi-call name pointing to multi-call-pi-apps, stays and gets the file it points to replaced.
+func replaceFile(src, dst string) error {
+	srcInfo, err := os.Lstat(src)
+	if err != nil {
+		return err
+	}
+
+	if srcInfo.Mode()&os.ModeSymlink != 0 {
+		target, err := os.Readlink(src)
+		if err != nil {
+			return err
+		}
+		tmp := filepath.Join(filepath.Dir(dst), "."+filepath.Base(dst)+".new-"+strconv.Itoa(os.Getpid()))
+		os.Remove(tmp)
+		if err := os.Symlink(target, tmp); err != nil {
+			return err
+		}
+		if err := os.Rename(tmp, dst); err != nil {
+			os.Remove(tmp)
+			return err
+		}
+		return nil
+	}
+
+	mode := srcInfo.Mode().Perm()
+	if dstInfo, err := os.Lstat(dst); err == nil {
+		if dstInfo.Mode()&os.ModeSymlink != 0 {
+			if resolved, err := filepath.EvalSymlinks(dst); err == nil {
+				dst = resolved
+				dstInfo, err = os.Stat(dst)
+				if err != nil {
+					return err
+				}
+			}
+		}
+		mode |= dstInfo.Mode().Perm() & 0111
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	tmp, err := os.CreateTemp(filepath.Dir(dst), "."+filepath.Base(dst)+".new-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, in); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(mode); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), dst)
+}
+
+// StalePrograms returns the Pi-Apps programs of this user that still run an executable the update replaced,
+// other than this process. The kernel marks the executable of a process as deleted once it was renamed over.
+func (u *Updater) StalePrograms() []StaleProgram {
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil
+	}
+	uid := os.Getuid()
+
+	var programs []StaleProgram
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil || pid == os.Getpid() {
+			continue
+		}
+		if info, err := os.Stat(filepath.Join("/proc", entry.Name())); err != nil || int(info.Sys().(*syscall.Stat_t).Uid) != uid {
+			continue
+		}
+		exe, err := os.Readlink(filepath.Join("/proc", entry.Name(), "exe"))
+		if err != nil {
+			continue
+		}
+		exe, deleted := strings.CutSuffix(exe, " (deleted)")
+		if !deleted || filepath.Dir(exe) != filepath.Clean(u.directory) {
+			continue
+		}
+		cmdline, err := os.ReadFile(filepath.Join("/proc", entry.Name(), "cmdline"))
+		if err != nil || len(cmdline) == 0 {
+			continue
+		}
+		args := strings.Split(strings.TrimSuffix(string(cmdline), "\x00"), "\x00")
+		programs = append(programs, StaleProgram{PID: pid, Name: filepath.Base(args[0]), Args: args})
+	}
+	return programs
+}
+
+// Restartable reports whether RestartPrograms restarts a program
+func (p StaleProgram) Restartable() bool {
+	return slices.Contains(restartablePrograms, p.Name)
+}
+
+// RestartPrograms stops the restartable programs and starts their new version with the same arguments
+func (u *Updater) RestartPrograms(programs []StaleProgram) error {
+	var failed []string
+	for _, program := range programs {
+		if !program.Restartable() {
+			continue
+		}
+		process, err := os.FindProcess(program.PID)
+		if err != nil {
+			continue
+		}
+		if err := process.Signal(syscall.SIGTERM); err != nil {
+			// It exited already
+			continue
+		}
+		for i := 0; i < 50 && process.Signal(syscall.Signal(0)) == nil; i++ {
+			time.Sleep(100 * time.Millisecond)
+		}
+
+		cmd := exec.Command(filepath.Join(u.directory, program.Name), program.Args[1:]...)
+		cmd.Dir = u.directory
+		cmd.Env = statelock.DetachedEnv(os.Environ())
+		cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+		if err := cmd.Start(); err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", program.Name, err))
+			continue
+		}
+		cmd.Process.Release()
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to restart %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,231 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package updater
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// writeFile writes a file with a mode, creating its directory
+func writeFile(t *testing.T, path, content string, mode os.FileMode) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), mode); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(path, mode); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// readFile returns the content of a file
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(content)
+}
+
+// leftoverTempFiles returns the temporary files replaceFile left in a directory
+func leftoverTempFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	matches, err := filepath.Glob(filepath.Join(dir, ".*.new-*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return matches
+}
+
+func TestReplaceFileIsAtomic(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "update", "api")
+	dst := filepath.Join(dir, "api")
+	writeFile(t, src, "new", 0644)
+	writeFile(t, dst, "old", 0755)
+
+	// A program running the old file keeps reading the old version
+	running, err := os.Open(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer running.Close()
+
+	if err := replaceFile(src, dst); err != nil {
+		t.Fatal(err)
+	}
+	if got := readFile(t, dst); got != "new" {
+		t.Errorf("replaced file = %q, want %q", got, "new")
+	}
+	info, err := os.Stat(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0755 {
+		t.Errorf("replaced file has mode %04o, want the executable bits kept: 0755", perm)
+	}
+	old := make([]byte, 3)
+	if _, err := running.ReadAt(old, 0); err != nil || string(old) != "old" {
+		t.Errorf("the open file reads %q, %v, want the old version", old, err)
+	}
+	if leftovers := leftoverTempFiles(t, dir); len(leftovers) != 0 {
+		t.Errorf("temporary files left behind: %v", leftovers)
+	}
+}
+
+func TestReplaceFileKeepsSymlinks(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "update", "multi-call-pi-apps")
+	writeFile(t, src, "new", 0755)
+	target := filepath.Join(dir, "multi-call-pi-apps")
+	writeFile(t, target, "old", 0755)
+	link := filepath.Join(dir, "gui")
+	if err := os.Symlink("multi-call-pi-apps", link); err != nil {
+		t.Fatal(err)
+	}
+
+	// A symlink in the Pi-Apps directory stays, the file it points to is replaced
+	if err := replaceFile(src, link); err != nil {
+		t.Fatal(err)
+	}
+	if linked, err := os.Readlink(link); err != nil || linked != "multi-call-pi-apps" {
+		t.Errorf("gui = %q, %v, want it to stay a symlink to multi-call-pi-apps", linked, err)
+	}
+	if got := readFile(t, target); got != "new" {
+		t.Errorf("symlink target = %q, want %q", got, "new")
+	}
+
+	// A symlink in the update is copied as a symlink
+	srcLink := filepath.Join(dir, "update", "settings")
+	if err := os.Symlink("multi-call-pi-apps", srcLink); err != nil {
+		t.Fatal(err)
+	}
+	dstLink := filepath.Join(dir, "settings")
+	if err := replaceFile(srcLink, dstLink); err != nil {
+		t.Fatal(err)
+	}
+	if linked, err := os.Readlink(dstLink); err != nil || linked != "multi-call-pi-apps" {
+		t.Errorf("settings = %q, %v, want a symlink to multi-call-pi-apps", linked, err)
+	}
+}
+
+func TestReplaceFileFailureLeavesOriginal(t *testing.T) {
+	dir := t.TempDir()
+	dst := filepath.Join(dir, "api")
+	writeFile(t, dst, "old", 0755)
+
+	if err := replaceFile(filepath.Join(dir, "update", "missing"), dst); err == nil {
+		t.Fatal("replacing with a missing file succeeded")
+	}
+	if got := readFile(t, dst); got != "old" {
+		t.Errorf("file after a failed replace = %q, want %q", got, "old")
+	}
+	if leftovers := leftoverTempFiles(t, dir); len(leftovers) != 0 {
+		t.Errorf("temporary files left behind: %v", leftovers)
+	}
+}
+
+func TestRollbackAfterFailedUpdate(t *testing.T) {
+	dir := t.TempDir()
+	u := &Updater{directory: dir}
+	writeFile(t, filepath.Join(dir, "api"), "old api", 0755)
+	writeFile(t, filepath.Join(dir, "etc", "categories"), "old categories", 0644)
+	writeFile(t, filepath.Join(dir, "update", "pi-apps", "api"), "new api", 0755)
+	// etc/categories is missing from the update, so updating it fails after api was replaced
+
+	files := []FileChange{{Path: "api", Type: "script"}, {Path: "etc/categories", Type: "file"}}
+	backup, err := u.createBackup(files, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := u.UpdateFiles(files); err == nil {
+		t.Fatal("UpdateFiles succeeded with a file missing from the update")
+	}
+	if got := readFile(t, filepath.Join(dir, "api")); got != "new api" {
+		t.Fatalf("api before the rollback = %q, want the new version", got)
+	}
+
+	if err := u.rollback(&RollbackData{BackupPath: backup}); err != nil {
+		t.Fatal(err)
+	}
+	for path, want := range map[string]string{"api": "old api", "etc/categories": "old categories"} {
+		if got := readFile(t, filepath.Join(dir, path)); got != want {
+			t.Errorf("%s after the rollback = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestStalePrograms(t *testing.T) {
+	sleep, err := exec.LookPath("sleep")
+	if err != nil {
+		t.Skip("sleep is not installed")
+	}
+	if _, err := os.Stat("/proc/self/exe"); err != nil {
+		t.Skip("/proc is not available")
+	}
+	dir := t.TempDir()
+	u := &Updater{directory: dir}
+	program := filepath.Join(dir, "gui")
+	content, err := os.ReadFile(sleep)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, program, string(content), 0755)
+
+	cmd := exec.Command(program, "60")
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	})
+	// Wait for the process to run the program instead of the test binary it was forked from
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if exe, _ := os.Readlink(filepath.Join("/proc", strconv.Itoa(cmd.Process.Pid), "exe")); exe == program {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if stale := u.StalePrograms(); slices.ContainsFunc(stale, func(p StaleProgram) bool { return p.PID == cmd.Process.Pid }) {
+		t.Fatalf("StalePrograms before the update = %+v, want the program left out", stale)
+	}
+
+	writeFile(t, filepath.Join(dir, "update", "gui"), string(content), 0755)
+	if err := replaceFile(filepath.Join(dir, "update", "gui"), program); err != nil {
+		t.Fatal(err)
+	}
+	stale := u.StalePrograms()
+	index := slices.IndexFunc(stale, func(p StaleProgram) bool { return p.PID == cmd.Process.Pid })
+	if index < 0 {
+		t.Fatalf("StalePrograms after the update = %+v, want the running gui", stale)
+	}
+	if found := stale[index]; found.Name != "gui" || !found.Restartable() || !slices.Equal(found.Args, []string{program, "60"}) {
+		t.Errorf("stale program = %+v, want a restartable gui started with its arguments", found)
+	}
+}
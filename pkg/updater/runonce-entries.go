@@ -112,7 +112,7 @@ func generateSettingsEntry() error {
 	if directory == "" {
 		return fmt.Errorf("PI_APPS_DIR environment variable not set")
 	}
-	settingsDir := filepath.Join(directory, "data", "settings")
+	settingsDir := filepath.Join(api.DataDirOf(directory), "settings")
 	entries, err := os.ReadDir(settingsDir)
 	if err != nil && !os.IsNotExist(err) {
 		return fmt.Errorf("failed to read settings directory: %v", err)
@@ -0,0 +1,228 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Module: sparse_sync.go
+// Description: Sparse sync of the update clone, which only checks out the apps usable on this system.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package updater
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pi-apps-go/pi-apps/pkg/api"
+)
+
+// sparseSyncSetting makes the update clone a partial clone that only checks out the apps usable on this system
+const sparseSyncSetting = "Sync only usable apps"
+
+// sparseSyncGitVersion is the first git version with partial clones and cone mode sparse checkouts that
+// work together
+var sparseSyncGitVersion = [2]int{2, 27}
+
+// gitVersion returns the output of git version, tests replace it
+var gitVersion = func() string {
+	output, _ := exec.Command("git", "version").Output()
+	return string(output)
+}
+
+// sparseSyncEnabled reports whether the update clone is synced sparsely. Without a recent enough git, all apps
+// are synced.
+func (u *Updater) sparseSyncEnabled() bool {
+	if api.ReadSettingIn(u.directory, sparseSyncSetting) != "Yes" {
+		return false
+	}
+	if !gitSupportsSparseSync(gitVersion()) {
+		api.Warning(fmt.Sprintf("%s needs git %d.%d or newer, all apps are synced", sparseSyncSetting, sparseSyncGitVersion[0], sparseSyncGitVersion[1]))
+		return false
+	}
+	return true
+}
+
+// gitSupportsSparseSync reports whether the output of git version names a version with sparse sync support
+func gitSupportsSparseSync(version string) bool {
+	fields := strings.Fields(version)
+	if len(fields) < 3 {
+		return false
+	}
+	parts := strings.SplitN(fields[2], ".", 3)
+	if len(parts) < 2 {
+		return false
+	}
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return false
+	}
+	minor, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return false
+	}
+	return major > sparseSyncGitVersion[0] || major == sparseSyncGitVersion[0] && minor >= sparseSyncGitVersion[1]
+}
+
+// cloneArgs returns the arguments of the git command cloning the repository. A sparse clone downloads the files
+// of the apps it checks out when they are checked out, and starts with only the top level files.
+func cloneArgs(gitURL string, sparse bool) []string {
+	args := []string{"clone", "--depth=1"}
+	if sparse {
+		args = append(args, "--filter=blob:none", "--sparse")
+	}
+	return append(args, gitURL)
+}
+
+// applySyncMode makes the update clone match the sync mode after a clone or pull: a sparse clone checks out the
+// apps it needs, switching the setting converts the existing clone in place. Failures are only warned about, the
+// clone still works for the apps it checked out.
+func (u *Updater) applySyncMode(ctx context.Context, repoDir string, sparse bool) {
+	if !sparse {
+		if !api.IsSparseClone(repoDir) {
+			return
+		}
+		// The files of the apps left out are downloaded while they are checked out
+		if err := runGit(ctx, repoDir, "", "sparse-checkout", "disable"); err != nil {
+			api.Warning(fmt.Sprintf("Failed to check out all apps of the update folder: %v", err))
+			return
+		}
+		os.Remove(filepath.Join(repoDir, ".git", "info", "sparse-checkout"))
+		return
+	}
+
+	apps, skipped, saved, err := u.sparseApps(repoDir)
+	if err != nil {
+		api.Warning(fmt.Sprintf("Failed to choose the apps to check out: %v", err))
+		return
+	}
+	paths, err := sparsePaths(repoDir, apps)
+	if err != nil {
+		api.Warning(fmt.Sprintf("Failed to choose the apps to check out: %v", err))
+		return
+	}
+
+	if !api.IsSparseClone(repoDir) {
+		// A full clone from before the setting was turned on, its later fetches leave out the files of apps that
+		// aren't checked out
+		for _, args := range [][]string{
+			{"config", "remote.origin.promisor", "true"},
+			{"config", "remote.origin.partialclonefilter", "blob:none"},
+			{"sparse-checkout", "init", "--cone"},
+		} {
+			if err := runGit(ctx, repoDir, "", args...); err != nil {
+				api.Warning(fmt.Sprintf("Failed to convert the update folder to a sparse clone: %v", err))
+				return
+			}
+		}
+	}
+	if err := runGit(ctx, repoDir, strings.Join(paths, "\n")+"\n", "sparse-checkout", "set", "--stdin"); err != nil {
+		api.Warning(fmt.Sprintf("Failed to check out the apps of the update folder: %v", err))
+		return
+	}
+	api.Debug("Apps left out of the update folder: " + strings.Join(skipped, ", "))
+	fmt.Fprintf(os.Stderr, "Left out %d apps that can't be used on this system, saving %s\n", len(skipped), api.FormatSize(uint64(saved)))
+}
+
+// sparseApps returns the apps of the clone to check out, and the apps left out with the size of their files.
+// Apps are left out when they can't be used on this system, aren't installed and are the same as their local
+// copy, so everything GetUpdatableApps could return stays checked out. Apps installed before they became
+// unusable stay checked out for their uninstall scripts, and new apps until they are local and can be checked.
+func (u *Updater) sparseApps(repoDir string) (apps, skipped []string, saved int64, err error) {
+	cloneHashes, err := gitAppHashes(repoDir)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	unavailable, err := api.UnavailableApps()
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	var all, local []string
+	for app := range cloneHashes {
+		all = append(all, app)
+		if _, ok := unavailable[app]; ok {
+			local = append(local, app)
+		}
+	}
+	sort.Strings(all)
+	localHashes := api.LocalAppHashes(local)
+
+	for _, app := range all {
+		if _, ok := unavailable[app]; ok && localHashes[app] == cloneHashes[app] && !neededForUninstall(app) {
+			skipped = append(skipped, app)
+			saved += folderSize(filepath.Join(u.directory, "apps", app))
+			continue
+		}
+		apps = append(apps, app)
+	}
+	return apps, skipped, saved, nil
+}
+
+// neededForUninstall reports whether an app has to keep its scripts because it is installed
+func neededForUninstall(app string) bool {
+	status, err := api.GetAppStatus(app)
+	return err == nil && (status == "installed" || status == "corrupted")
+}
+
+// sparsePaths returns the folders a sparse clone checks out: every top level folder but apps, which only has the
+// given apps
+func sparsePaths(repoDir string, apps []string) ([]string, error) {
+	output, err := exec.Command("git", "-C", repoDir, "ls-tree", "-d", "-z", "--name-only", "HEAD").Output()
+	if err != nil {
+		return nil, fmt.Errorf("git ls-tree failed: %w", err)
+	}
+	var paths []string
+	for _, dir := range strings.Split(string(output), "\x00") {
+		if dir != "" && dir != "apps" {
+			paths = append(paths, dir)
+		}
+	}
+	for _, app := range apps {
+		paths = append(paths, "apps/"+app)
+	}
+	return paths, nil
+}
+
+// folderSize returns the total size of the regular files in a folder
+func folderSize(path string) int64 {
+	var size int64
+	filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err == nil && d.Type().IsRegular() {
+			if info, err := d.Info(); err == nil {
+				size += info.Size()
+			}
+		}
+		return nil
+	})
+	return size
+}
+
+// runGit runs a git command in a clone, with input on its standard input if it isn't empty
+func runGit(ctx context.Context, repoDir, input string, args ...string) error {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", repoDir}, args...)...)
+	if input != "" {
+		cmd.Stdin = strings.NewReader(input)
+	}
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
@@ -0,0 +1,223 @@
+// Copyright (C) 2026 pi-apps-go contributors
+// This file is part of Pi-Apps Go - a modern, cross-architecture/cross-platform, and modular Pi-Apps implementation in Go.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package updater
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/pi-apps-go/pi-apps/pkg/api"
+)
+
+// git runs a git command in a folder for a test
+func git(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=Test", "-c", "user.email=test@example.com"}, args...)...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, output)
+	}
+	return string(output)
+}
+
+// newSparseTestDir returns a Pi-Apps folder with a repository to clone from. Usable can be installed here, the
+// other apps can't: Unusable is the same as the local copy, Installed is installed and Changed changed upstream.
+func newSparseTestDir(t *testing.T) (dir, remote string) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	if !gitSupportsSparseSync(gitVersion()) {
+		t.Skip("git is too old for sparse sync")
+	}
+
+	dir = t.TempDir()
+	t.Setenv("PI_APPS_DIR", dir)
+	remote = t.TempDir()
+	files := map[string]string{
+		"api":                     "#!/bin/bash\n",
+		"gui":                     "#!/bin/bash\n",
+		"updater":                 "#!/bin/bash\n",
+		"etc/categories":          "Usable|Tools\n",
+		"apps/Usable/install":     "#!/bin/bash\necho usable\n",
+		"apps/Unusable/install":   "#!/bin/bash\necho unusable\n",
+		"apps/Installed/install":  "#!/bin/bash\necho installed\n",
+		"apps/Changed/install":    "#!/bin/bash\necho changed\n",
+		"apps/Unusable/uninstall": "#!/bin/bash\n",
+	}
+	for _, app := range []string{"Unusable", "Installed", "Changed"} {
+		files["apps/"+app+"/requirements"] = "arch=no-such-arch\n"
+	}
+	for path, content := range files {
+		writeFile(t, filepath.Join(remote, path), content, 0755)
+		if path != "apps/Changed/install" {
+			writeFile(t, filepath.Join(dir, path), content, 0755)
+		}
+	}
+	writeFile(t, filepath.Join(dir, "apps", "Changed", "install"), "#!/bin/bash\necho old\n", 0755)
+	writeFile(t, filepath.Join(dir, "data", "status", "Installed"), "installed\n", 0644)
+
+	git(t, remote, "init", "-q")
+	git(t, remote, "config", "uploadpack.allowFilter", "true")
+	git(t, remote, "add", "-A")
+	git(t, remote, "commit", "-q", "-m", "apps")
+	return dir, remote
+}
+
+// cloneForTest clones the test repository into the update folder like CheckRepo does
+func cloneForTest(t *testing.T, dir, remote string, sparse bool) string {
+	t.Helper()
+	updateDir := filepath.Join(dir, "update")
+	if err := os.MkdirAll(updateDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	git(t, updateDir, cloneArgs("file://"+remote, sparse)...)
+	if err := os.Rename(filepath.Join(updateDir, filepath.Base(remote)), filepath.Join(updateDir, "pi-apps")); err != nil {
+		t.Fatal(err)
+	}
+	return filepath.Join(updateDir, "pi-apps")
+}
+
+func checkedOutApps(repoDir string) []string {
+	var apps []string
+	for _, app := range []string{"Changed", "Installed", "Unusable", "Usable"} {
+		if dirExists(filepath.Join(repoDir, "apps", app)) {
+			apps = append(apps, app)
+		}
+	}
+	return apps
+}
+
+func TestSparseSync(t *testing.T) {
+	dir, remote := newSparseTestDir(t)
+	u, err := New(dir, ModeCLI, SpeedNormal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	// The apps GetUpdatableApps finds in a full clone
+	repoDir := cloneForTest(t, dir, remote, false)
+	wantUpdatable, err := u.GetUpdatableApps()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(wantUpdatable, []string{"Changed"}) {
+		t.Fatalf("full clone: updatable apps = %v, want [Changed]", wantUpdatable)
+	}
+	os.RemoveAll(filepath.Join(dir, "update"))
+
+	repoDir = cloneForTest(t, dir, remote, true)
+	u.applySyncMode(ctx, repoDir, true)
+	if !api.IsSparseClone(repoDir) {
+		t.Fatal("clone is not sparse")
+	}
+	if got, want := checkedOutApps(repoDir), []string{"Changed", "Installed", "Usable"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("sparse clone checked out %v, want %v", got, want)
+	}
+	if !fileExists(filepath.Join(repoDir, "etc", "categories")) || !fileExists(filepath.Join(repoDir, "updater")) {
+		t.Error("sparse clone left out files outside of apps")
+	}
+
+	// The apps left out are still online and not removed
+	online, err := api.ListApps("online")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Contains(online, "Unusable") {
+		t.Errorf("online apps %v miss Unusable", online)
+	}
+	if removed, err := u.GetRemovedApps(); err != nil || len(removed) != 0 {
+		t.Errorf("removed apps = %v, %v, want none", removed, err)
+	}
+	if got, err := u.GetUpdatableApps(); err != nil || !reflect.DeepEqual(got, wantUpdatable) {
+		t.Errorf("sparse clone: updatable apps = %v, %v, want %v", got, err, wantUpdatable)
+	}
+
+	// Turning the setting off checks out all apps
+	u.applySyncMode(ctx, repoDir, false)
+	if api.IsSparseClone(repoDir) {
+		t.Error("clone is still sparse after turning sparse sync off")
+	}
+	if got, want := checkedOutApps(repoDir), []string{"Changed", "Installed", "Unusable", "Usable"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("full clone checked out %v, want %v", got, want)
+	}
+	if got, err := u.GetUpdatableApps(); err != nil || !reflect.DeepEqual(got, wantUpdatable) {
+		t.Errorf("converted clone: updatable apps = %v, %v, want %v", got, err, wantUpdatable)
+	}
+}
+
+func TestSparseSyncConvertsFullClone(t *testing.T) {
+	dir, remote := newSparseTestDir(t)
+	u, err := New(dir, ModeCLI, SpeedNormal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repoDir := cloneForTest(t, dir, remote, false)
+	u.applySyncMode(context.Background(), repoDir, true)
+
+	if got, want := checkedOutApps(repoDir), []string{"Changed", "Installed", "Usable"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("converted clone checked out %v, want %v", got, want)
+	}
+	if filter := git(t, repoDir, "config", "remote.origin.partialclonefilter"); filter != "blob:none\n" {
+		t.Errorf("partial clone filter = %q, want blob:none", filter)
+	}
+}
+
+func TestSparseSyncSetting(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("PI_APPS_DIR", dir)
+	u, err := New(dir, ModeCLI, SpeedNormal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.sparseSyncEnabled() {
+		t.Error("sparse sync is on by default")
+	}
+	writeFile(t, filepath.Join(dir, "data", "settings", sparseSyncSetting), "Yes\n", 0644)
+
+	oldVersion := gitVersion
+	defer func() { gitVersion = oldVersion }()
+	gitVersion = func() string { return "git version 2.39.5\n" }
+	if !u.sparseSyncEnabled() {
+		t.Error("sparse sync is off with the setting on")
+	}
+	gitVersion = func() string { return "git version 2.20.1\n" }
+	if u.sparseSyncEnabled() {
+		t.Error("sparse sync is on with a git that doesn't support it")
+	}
+}
+
+func TestGitSupportsSparseSync(t *testing.T) {
+	for version, want := range map[string]bool{
+		"git version 2.27.0\n":               true,
+		"git version 3.0.1\n":                true,
+		"git version 2.39.5 (Apple Git-154)": true,
+		"git version 2.26.2\n":               false,
+		"git version 1.9\n":                  false,
+		"":                                   false,
+	} {
+		if got := gitSupportsSparseSync(version); got != want {
+			t.Errorf("gitSupportsSparseSync(%q) = %v, want %v", version, got, want)
+		}
+	}
+}
@@ -24,6 +24,7 @@ package updater
 import (
 	"bufio"
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
@@ -35,6 +36,7 @@ import (
 
 	"github.com/gen2brain/beeep"
 	"github.com/pi-apps-go/pi-apps/pkg/api"
+	"github.com/pi-apps-go/pi-apps/pkg/api/errs"
 )
 
 // UpdateMode represents different modes of running the updater
@@ -48,6 +50,7 @@ const (
 	ModeGUIYes      UpdateMode = "gui-yes"
 	ModeCLI         UpdateMode = "cli"
 	ModeCLIYes      UpdateMode = "cli-yes"
+	ModePlan        UpdateMode = "plan"
 )
 
 // UpdateSpeed represents update checking speed
@@ -79,12 +82,15 @@ type FileChange struct {
 
 // UpdateResult represents the result of an update operation
 type UpdateResult struct {
-	Success      bool
-	Message      string
-	FailedApps   []string
-	FailedFiles  []string
-	Recompiled   bool
-	RollbackData *RollbackData
+	Success        bool
+	Message        string
+	FailedApps     []string
+	RolledBackApps []string // apps whose update failed and were restored to their previous version
+	SkippedApps    []string // apps not updated because the apps catalog needs a newer version of Pi-Apps
+	FailedFiles    []string
+	Recompiled     bool
+	StalePrograms  []StaleProgram // Pi-Apps programs still running the version the update replaced
+	RollbackData   *RollbackData
 }
 
 // RollbackData stores information needed for rollback
@@ -125,7 +131,7 @@ func New(directory string, mode UpdateMode, speed UpdateSpeed) (*Updater, error)
 	}
 
 	// Create update-status directory
-	statusDir := filepath.Join(directory, "data", "update-status")
+	statusDir := filepath.Join(api.DataDirOf(directory), "update-status")
 	if err := os.MkdirAll(statusDir, 0755); err != nil {
 		return nil, fmt.Errorf("failed to create update-status directory: %w", err)
 	}
@@ -144,7 +150,7 @@ func New(directory string, mode UpdateMode, speed UpdateSpeed) (*Updater, error)
 
 // CheckUpdateInterval checks if updates should be checked based on the interval setting
 func (u *Updater) CheckUpdateInterval() error {
-	lastUpdateFile := filepath.Join(u.directory, "data", "last-update-check")
+	lastUpdateFile := filepath.Join(api.DataDirOf(u.directory), "last-update-check")
 
 	// Read last update check date
 	var lastUpdate int64
@@ -161,13 +167,7 @@ func (u *Updater) CheckUpdateInterval() error {
 	}
 
 	// Read update interval setting
-	intervalFile := filepath.Join(u.directory, "data", "settings", "Check for updates")
-	interval := "Weekly" // default
-	if data, err := os.ReadFile(intervalFile); err == nil {
-		interval = strings.TrimSpace(string(data))
-	}
-
-	switch interval {
+	switch api.ReadSettingIn(u.directory, "Check for updates") {
 	case "Never":
 		return fmt.Errorf("update checking is disabled")
 	case "Daily":
@@ -188,17 +188,33 @@ func (u *Updater) CheckUpdateInterval() error {
 	return nil
 }
 
+// checkWritable refuses changes to a Pi-Apps directory shared by the users of the system, which only
+// its administrator can update
+func (u *Updater) checkWritable() error {
+	if api.IsReadOnlyPiAppsDir(u.directory) {
+		return fmt.Errorf("%w: %s is shared by the users of this system, ask its administrator to update Pi-Apps", api.ErrReadOnlyPiAppsDir, u.directory)
+	}
+	return nil
+}
+
 // CheckRepo downloads/updates the repository in the update folder
 func (u *Updater) CheckRepo(ctx context.Context) error {
+	if err := u.checkWritable(); err != nil {
+		return err
+	}
 	if u.speed == SpeedFast {
 		return nil
 	}
 
+	// A wrong clock makes git reject the certificate of the repository
+	api.WarnClockSkew()
+
 	fmt.Fprint(os.Stderr, "Checking for online changes... ")
 
 	updateDir := filepath.Join(u.directory, "update")
 	repoDir := filepath.Join(updateDir, "pi-apps")
 	updaterScript := filepath.Join(repoDir, "updater")
+	sparse := u.sparseSyncEnabled()
 
 	// If updater exists in update folder, try git pull first
 	if fileExists(updaterScript) {
@@ -209,6 +225,8 @@ func (u *Updater) CheckRepo(ctx context.Context) error {
 			os.RemoveAll(updateDir)
 		} else {
 			fmt.Fprintln(os.Stderr, "Done")
+			u.applySyncMode(ctx, repoDir, sparse)
+			u.syncAppRepos(ctx)
 			return nil
 		}
 	}
@@ -221,9 +239,12 @@ func (u *Updater) CheckRepo(ctx context.Context) error {
 				return fmt.Errorf("failed to create update directory: %w", err)
 			}
 
-			cmd := exec.CommandContext(ctx, "git", "clone", "--depth=1", u.gitURL)
+			cmd := exec.CommandContext(ctx, "git", cloneArgs(u.gitURL, sparse)...)
 			cmd.Dir = updateDir
 			if err := cmd.Run(); err != nil {
+				if ctx.Err() != nil {
+					return errs.New(errs.ErrCancelled, "downloading the Pi-Apps repository was cancelled: %w", ctx.Err())
+				}
 				//fmt.Fprintf(os.Stderr, "\nFailed to download Pi-Apps repository! Retrying in 60 seconds.\n")
 				output, err := cmd.CombinedOutput()
 				if err != nil {
@@ -240,25 +261,56 @@ func (u *Updater) CheckRepo(ctx context.Context) error {
 	}
 
 	fmt.Fprintln(os.Stderr, "Done")
+	u.applySyncMode(ctx, repoDir, sparse)
+	u.syncAppRepos(ctx)
 	return nil
 }
 
+// syncAppRepos clones or pulls the extra app repositories next to the main one. Their problems, like apps named
+// after official ones, are only warned about so the official apps can still be updated.
+func (u *Updater) syncAppRepos(ctx context.Context) {
+	repos, err := api.ReadAppRepos()
+	if err != nil {
+		api.WarningTf("Failed to read the extra app repositories: %v", err)
+		return
+	}
+	if len(repos) == 0 {
+		return
+	}
+	fmt.Fprint(os.Stderr, "Checking extra app repositories... ")
+	if err := api.SyncAppRepos(ctx); err != nil {
+		fmt.Fprintln(os.Stderr, "Failed")
+		for _, line := range strings.Split(err.Error(), "\n") {
+			api.Warning(line)
+		}
+		return
+	}
+	fmt.Fprintln(os.Stderr, "Done")
+}
+
 // GetUpdatableFiles returns a list of files that need updating
 func (u *Updater) GetUpdatableFiles() ([]FileChange, error) {
-	statusFile := filepath.Join(u.directory, "data", "update-status", "updatable-files")
+	updatable, _, err := u.updatableFiles()
+	return updatable, err
+}
+
+// updatableFiles returns the files that need updating and the changed files left alone because they are listed in
+// data/update-exclusion. The cached results of fast mode were filtered when they were saved, so none are excluded then.
+func (u *Updater) updatableFiles() (updatable, excluded []FileChange, err error) {
+	statusFile := filepath.Join(api.DataDirOf(u.directory), "update-status", "updatable-files")
 
 	if u.speed == SpeedFast && fileExists(statusFile) {
 		// Use cached results for fast mode
-		return u.loadCachedFiles(statusFile)
+		updatable, err = u.loadCachedFiles(statusFile)
+		return updatable, nil, err
 	}
 
 	// Compare files between update and main directory
 	files, err := u.listAllFiles()
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	var updatable []FileChange
 	for _, file := range files {
 		localPath := filepath.Join(u.directory, file)
 		updatePath := filepath.Join(u.directory, "update", "pi-apps", file)
@@ -284,7 +336,7 @@ func (u *Updater) GetUpdatableFiles() ([]FileChange, error) {
 
 		// Compare file contents
 		if match, err := u.filesMatch(localPath, updatePath); err != nil {
-			return nil, err
+			return nil, nil, err
 		} else if !match {
 			fc := FileChange{
 				Path:              file,
@@ -297,14 +349,14 @@ func (u *Updater) GetUpdatableFiles() ([]FileChange, error) {
 	}
 
 	// Filter out excluded files
-	updatable = u.filterExcludedFiles(updatable)
+	updatable, excluded = u.filterExcludedFiles(updatable)
 
-	return updatable, nil
+	return updatable, excluded, nil
 }
 
 // GetUpdatableApps returns a list of apps that need updating
 func (u *Updater) GetUpdatableApps() ([]string, error) {
-	statusFile := filepath.Join(u.directory, "data", "update-status", "updatable-apps")
+	statusFile := filepath.Join(api.DataDirOf(u.directory), "update-status", "updatable-apps")
 
 	if u.speed == SpeedFast && fileExists(statusFile) {
 		return u.loadCachedApps(statusFile)
@@ -316,10 +368,30 @@ func (u *Updater) GetUpdatableApps() ([]string, error) {
 		return nil, err
 	}
 
+	// Hash the clone's app folders from its git tree and the local ones from the hash cache,
+	// only apps whose hashes can't be compared fall back to comparing the folders file by file
+	cloneHashes, err := u.cloneAppHashes()
+	if err != nil {
+		api.Debug(fmt.Sprintf("Failed to read app hashes from the update clone: %v", err))
+	}
+	var localApps []string
+	for _, app := range onlineApps {
+		if dirExists(filepath.Join(u.directory, "apps", app)) {
+			localApps = append(localApps, app)
+		}
+	}
+	localHashes := api.LocalAppHashes(localApps)
+	sparse := api.IsSparseClone(filepath.Join(u.directory, "update", "pi-apps"))
+
 	var updatable []string
 	for _, app := range onlineApps {
 		localPath := filepath.Join(u.directory, "apps", app)
-		updatePath := filepath.Join(u.directory, "update", "pi-apps", "apps", app)
+		updatePath := api.AppUpdateDir(app)
+
+		// A sparse clone leaves out apps that were the same as their local copy when it was synced
+		if sparse && !dirExists(updatePath) {
+			continue
+		}
 
 		// If app doesn't exist locally, it's new
 		if !dirExists(localPath) {
@@ -327,6 +399,15 @@ func (u *Updater) GetUpdatableApps() ([]string, error) {
 			continue
 		}
 
+		localHash, hasLocal := localHashes[app]
+		cloneHash, hasClone := cloneHashes[app]
+		if hasLocal && hasClone {
+			if localHash != cloneHash {
+				updatable = append(updatable, app)
+			}
+			continue
+		}
+
 		// Compare app directories
 		if match, err := u.directoriesMatch(localPath, updatePath); err != nil {
 			return nil, err
@@ -338,6 +419,71 @@ func (u *Updater) GetUpdatableApps() ([]string, error) {
 	return updatable, nil
 }
 
+// cloneAppHashes returns the folder hashes of the apps in the update clone and the clones of the extra app
+// repositories, keyed by app name. Apps of extra app repositories get the hash from their own clone.
+func (u *Updater) cloneAppHashes() (map[string]string, error) {
+	hashes, err := gitAppHashes(filepath.Join(u.directory, "update", "pi-apps"))
+	if err != nil {
+		return nil, err
+	}
+	repos, err := api.ReadAppRepos()
+	if err != nil {
+		return hashes, nil
+	}
+	for _, repo := range repos {
+		repoHashes, err := gitAppHashes(api.AppRepoDir(repo.Name))
+		if err != nil {
+			continue
+		}
+		for app, hash := range repoHashes {
+			if api.AppSource(app) == repo.Name {
+				hashes[app] = hash
+			}
+		}
+	}
+	return hashes, nil
+}
+
+// gitAppHashes returns the folder hashes of the apps in a clone, keyed by app name.
+// They are computed from the blob hashes in the clone's git tree, so no app file has to be read.
+func gitAppHashes(cloneDir string) (map[string]string, error) {
+	output, err := exec.Command("git", "-C", cloneDir, "ls-tree", "-r", "-z", "HEAD", "--", "apps").Output()
+	if err != nil {
+		return nil, fmt.Errorf("git ls-tree failed: %w", err)
+	}
+
+	// Entries are formatted as "<mode> <type> <hash>\t<path>"
+	blobs := make(map[string]map[string]string)
+	for _, entry := range strings.Split(string(output), "\x00") {
+		meta, path, ok := strings.Cut(entry, "\t")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(meta)
+		if len(fields) != 3 || fields[1] != "blob" {
+			continue
+		}
+		rest, ok := strings.CutPrefix(path, "apps/")
+		if !ok {
+			continue
+		}
+		app, file, ok := strings.Cut(rest, "/")
+		if !ok {
+			continue
+		}
+		if blobs[app] == nil {
+			blobs[app] = make(map[string]string)
+		}
+		blobs[app][file] = fields[2]
+	}
+
+	hashes := make(map[string]string, len(blobs))
+	for app, files := range blobs {
+		hashes[app] = api.AppTreeHash(files)
+	}
+	return hashes, nil
+}
+
 // GetRemovedApps returns a list of apps that exist locally but not in the online repository
 // and checks if they are deprecated apps that should be handled
 func (u *Updater) GetRemovedApps() ([]string, error) {
@@ -448,6 +594,37 @@ func (u *Updater) PerformUpdate(files []FileChange, apps []string) *UpdateResult
 		},
 	}
 
+	if err := u.checkWritable(); err != nil {
+		result.Success = false
+		result.Message = err.Error()
+		result.RollbackData = nil
+		return result
+	}
+
+	// Nothing else may change apps or files while they are replaced
+	lock, err := api.LockState("update Pi-Apps")
+	if err != nil {
+		result.Success = false
+		result.Message = err.Error()
+		result.RollbackData = nil
+		return result
+	}
+	defer lock.Release()
+
+	// Apps from a catalog needing a newer version of Pi-Apps are left for the build the updated files compile to
+	var catalogErr error
+	if len(apps) > 0 {
+		if catalogErr = u.CheckCatalogVersion(); catalogErr != nil {
+			result.SkippedApps, apps = apps, nil
+			if len(files) == 0 {
+				result.Success = false
+				result.Message = u.skippedAppsMessage(catalogErr, false)
+				result.RollbackData = nil
+				return result
+			}
+		}
+	}
+
 	// Create backup
 	backupDir, err := u.createBackup(files, apps)
 	if err != nil {
@@ -473,6 +650,10 @@ func (u *Updater) PerformUpdate(files []FileChange, apps []string) *UpdateResult
 	if err := u.UpdateApps(apps); err != nil {
 		result.Success = false
 		result.Message = fmt.Sprintf("Failed to update apps: %v", err)
+		var rolledBack *api.UpdateRolledBackError
+		if errors.As(err, &rolledBack) {
+			result.RolledBackApps = append(result.RolledBackApps, rolledBack.App)
+		}
 		u.rollback(result.RollbackData)
 		return result
 	}
@@ -524,11 +705,29 @@ func (u *Updater) PerformUpdate(files []FileChange, apps []string) *UpdateResult
 	} else if needsRecompile {
 		message += " (Recompilation completed)"
 	}
+	if catalogErr != nil {
+		message += ". " + u.skippedAppsMessage(catalogErr, true)
+	}
 
 	result.Message = message
+	result.StalePrograms = u.StalePrograms()
 	return result
 }
 
+// CheckCatalogVersion returns a *api.CatalogVersionError if the apps in the update clone need a newer version of Pi-Apps
+func (u *Updater) CheckCatalogVersion() error {
+	return api.CheckCatalogVersion(filepath.Join(u.directory, "update", "pi-apps"))
+}
+
+// skippedAppsMessage tells why apps weren't updated and how to update them, selfUpdated tells whether
+// Pi-Apps itself was updated in the same run
+func (u *Updater) skippedAppsMessage(catalogErr error, selfUpdated bool) string {
+	if selfUpdated {
+		return fmt.Sprintf("Apps were not updated because %v. Pi-Apps itself was updated, run the updater again to update them.", catalogErr)
+	}
+	return fmt.Sprintf("Apps were not updated because %v. Update Pi-Apps itself first with '%s -update-self', then update them.", catalogErr, filepath.Join(u.directory, "manage"))
+}
+
 // Helper functions
 
 func (u *Updater) getFileType(path string) string {
@@ -628,6 +827,11 @@ func (u *Updater) recompile() error {
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("make %s failed: %w", makeTarget, err)
 	}
+	if u.isMultiCallMode() {
+		if err := u.verifyMultiCallLinks(); err != nil {
+			return err
+		}
+	}
 
 	fmt.Println("Recompilation completed successfully")
 	return nil
@@ -710,10 +914,23 @@ func (u *Updater) updateFile(filePath string) error {
 		return err
 	}
 
-	return copyFile(src, dst)
+	if err := copyFile(src, dst); err != nil {
+		return err
+	}
+	if filePath == api.MultiCallBinary {
+		return u.verifyMultiCallLinks()
+	}
+	return nil
 }
 
+// updateApp reinstalls an app with its new version, restoring the previous version if that fails
 func (u *Updater) updateApp(app string) error {
+	return api.UpdateAppWithRollback(app, func() error {
+		return u.reinstallApp(app)
+	})
+}
+
+func (u *Updater) reinstallApp(app string) error {
 	// Uninstall app first
 	status, err := api.GetAppStatus(app)
 	if err != nil {
@@ -741,12 +958,13 @@ func (u *Updater) updateApp(app string) error {
 
 func (u *Updater) refreshApp(app string) error {
 	appDir := filepath.Join(u.directory, "apps", app)
-	updateAppDir := filepath.Join(u.directory, "update", "pi-apps", "apps", app)
+	updateAppDir := api.AppUpdateDir(app)
 
 	// Remove existing app directory
 	if err := os.RemoveAll(appDir); err != nil {
 		return err
 	}
+	defer api.InvalidateAppHash(app)
 
 	// Copy new version
 	return copyDir(updateAppDir, appDir)
@@ -878,10 +1096,10 @@ func (u *Updater) directoriesMatch(dir1, dir2 string) (bool, error) {
 	return err == nil, nil
 }
 
-func (u *Updater) filterExcludedFiles(files []FileChange) []FileChange {
-	exclusionFile := filepath.Join(u.directory, "data", "update-exclusion")
+func (u *Updater) filterExcludedFiles(files []FileChange) (kept, excludedFiles []FileChange) {
+	exclusionFile := filepath.Join(api.DataDirOf(u.directory), "update-exclusion")
 	if !fileExists(exclusionFile) {
-		return files
+		return files, nil
 	}
 
 	excluded := make(map[string]bool)
@@ -896,14 +1114,15 @@ func (u *Updater) filterExcludedFiles(files []FileChange) []FileChange {
 		}
 	}
 
-	var filtered []FileChange
 	for _, file := range files {
-		if !excluded[file.Path] {
-			filtered = append(filtered, file)
+		if excluded[file.Path] {
+			excludedFiles = append(excludedFiles, file)
+		} else {
+			kept = append(kept, file)
 		}
 	}
 
-	return filtered
+	return kept, excludedFiles
 }
 
 func (u *Updater) loadCachedFiles(statusFile string) ([]FileChange, error) {
@@ -983,12 +1202,9 @@ func dirExists(path string) bool {
 	return err == nil && info.IsDir()
 }
 
+// copyFile replaces dst with src atomically, see replaceFile
 func copyFile(src, dst string) error {
-	data, err := os.ReadFile(src)
-	if err != nil {
-		return err
-	}
-	return os.WriteFile(dst, data, 0644)
+	return replaceFile(src, dst)
 }
 
 func copyDir(src, dst string) error {
@@ -1068,12 +1284,12 @@ func (u *Updater) CheckInternetConnection() error {
 		}
 	}
 
-	return fmt.Errorf("internet connection not available after %d attempts", maxAttempts)
+	return errs.New(errs.ErrNetwork, "internet connection not available after %d attempts", maxAttempts)
 }
 
 // HasInstalledApps checks if at least one app has been installed
 func (u *Updater) HasInstalledApps() bool {
-	statusDir := filepath.Join(u.directory, "data", "status")
+	statusDir := filepath.Join(api.DataDirOf(u.directory), "status")
 	entries, err := os.ReadDir(statusDir)
 	if err != nil {
 		return false
@@ -1081,10 +1297,32 @@ func (u *Updater) HasInstalledApps() bool {
 	return len(entries) > 0
 }
 
+// RecheckAppHealth runs the healthchecks of the installed apps when the "Recheck app health" setting is Yes,
+// and marks the apps whose check fails as corrupted. Problems are only printed, the update check goes on.
+func (u *Updater) RecheckAppHealth() {
+	if !api.HealthRecheckEnabled() {
+		return
+	}
+	results, err := api.RunHealthChecks(nil)
+	if err != nil {
+		fmt.Printf("Warning: failed to run the health checks: %v\n", err)
+		return
+	}
+	for _, result := range results {
+		if result.Healthy {
+			continue
+		}
+		fmt.Printf("%s failed its health check: %s\n", result.App, result.Problem())
+		if err := api.MarkAppUnhealthy(result); err != nil {
+			fmt.Printf("Warning: failed to mark %s as corrupted: %v\n", result.App, err)
+		}
+	}
+}
+
 // GetStatus checks if updates are available (for get-status mode)
 func (u *Updater) GetStatus() error {
-	updatableFiles := filepath.Join(u.directory, "data", "update-status", "updatable-files")
-	updatableApps := filepath.Join(u.directory, "data", "update-status", "updatable-apps")
+	updatableFiles := filepath.Join(api.DataDirOf(u.directory), "update-status", "updatable-files")
+	updatableApps := filepath.Join(api.DataDirOf(u.directory), "update-status", "updatable-apps")
 
 	// Check if either file exists and has content
 	if u.hasContent(updatableFiles) || u.hasContent(updatableApps) {
@@ -1096,8 +1334,11 @@ func (u *Updater) GetStatus() error {
 
 // SetStatus checks for updates and writes status files (for set-status mode)
 func (u *Updater) SetStatus(ctx context.Context) error {
-	// Check repository
-	if err := u.CheckRepo(ctx); err != nil {
+	// Check repository, there are no updates to report to the users of a shared Pi-Apps directory
+	if err := u.CheckRepo(ctx); errors.Is(err, api.ErrReadOnlyPiAppsDir) {
+		api.Debug(err.Error())
+		return nil
+	} else if err != nil {
 		return fmt.Errorf("failed to check repository: %w", err)
 	}
 
@@ -1161,6 +1402,9 @@ func (u *Updater) executeAutostarted(ctx context.Context) error {
 		return nil
 	}
 
+	// Apps can stop working without an update, like when an OS upgrade removed a library they need
+	u.RecheckAppHealth()
+
 	// Wait for internet connection
 	if err := u.CheckInternetConnection(); err != nil {
 		return err
@@ -1366,6 +1610,12 @@ func (u *Updater) updateBackgroundSafe(files []FileChange, apps []string) error
 		}
 	}
 
+	// Apps from a catalog needing a newer version of Pi-Apps are left for the user to see
+	if err := u.CheckCatalogVersion(); err != nil {
+		fmt.Printf("Not updating apps in the background: %v\n", err)
+		apps = nil
+	}
+
 	for _, app := range apps {
 		// Check if it's a new app
 		appDir := filepath.Join(u.directory, "apps", app)
@@ -1415,7 +1665,7 @@ func (u *Updater) updateBackgroundSafe(files []FileChange, apps []string) error
 // writeStatusFiles writes the current update status to files
 func (u *Updater) writeStatusFiles(files []FileChange, apps []string) error {
 	// Write updatable files
-	filesPath := filepath.Join(u.directory, "data", "update-status", "updatable-files")
+	filesPath := filepath.Join(api.DataDirOf(u.directory), "update-status", "updatable-files")
 	var fileLines []string
 	for _, file := range files {
 		fileLines = append(fileLines, file.Path)
@@ -1431,7 +1681,7 @@ func (u *Updater) writeStatusFiles(files []FileChange, apps []string) error {
 	}
 
 	// Write updatable apps
-	appsPath := filepath.Join(u.directory, "data", "update-status", "updatable-apps")
+	appsPath := filepath.Join(api.DataDirOf(u.directory), "update-status", "updatable-apps")
 	if len(apps) > 0 {
 		if err := os.WriteFile(appsPath, []byte(strings.Join(apps, "\n")+"\n"), 0644); err != nil {
 			return err
@@ -1467,6 +1717,34 @@ func (u *Updater) hasContent(filePath string) bool {
 	return false
 }
 
+// verifyMultiCallLinks runs verify-links of the multi-call binary that replaced the old one, which checks that the
+// new binary starts and that the links of the programs point to it, and repairs the links with install-links when
+// they don't
+func (u *Updater) verifyMultiCallLinks() error {
+	binary := filepath.Join(u.directory, api.MultiCallBinary)
+	if !fileExists(binary) {
+		return nil
+	}
+	output, err := exec.Command(binary, "verify-links", u.directory).CombinedOutput()
+	if err == nil {
+		return nil
+	}
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != 1 {
+		return fmt.Errorf("the new multi-call binary failed to verify its links: %w\n%s", err, output)
+	}
+
+	fmt.Print(string(output))
+	fmt.Println("Repairing the links to the multi-call binary...")
+	cmd := exec.Command(binary, "install-links", u.directory)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to repair the links to the multi-call binary: %w", err)
+	}
+	return nil
+}
+
 // isMultiCallMode checks if we're running from a multi-call binary
 func (u *Updater) isMultiCallMode() bool {
 	// Check if PI_APPS_MULTI_CALL_BINARY environment variable is set